@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/urfave/cli/v2"
 )
@@ -22,13 +23,34 @@ const (
 	// TODO: RSDK-6683.
 	quietFlag = "quiet"
 
-	logsFlagErrors = "errors"
-	logsFlagTail   = "tail"
-	logsFlagCount  = "count"
+	logsFlagErrors   = "errors"
+	logsFlagTail     = "tail"
+	logsFlagCount    = "count"
+	logsFlagResource = "resource"
+	logsFlagLevel    = "level"
+	logsFlagRegex    = "regex"
+	logsFlagJSON     = "json"
+
+	partConfigFlagFile   = "file"
+	partConfigFlagDryRun = "dry-run"
 
 	runFlagData   = "data"
 	runFlagStream = "stream"
 
+	discoverFlagTimeout = "timeout"
+
+	benchmarkVisionFlagService = "vision-service"
+	benchmarkVisionFlagCamera  = "camera"
+	benchmarkVisionFlagMethod  = "method"
+	benchmarkVisionFlagFrames  = "frames"
+	benchmarkVisionFlagWarmup  = "warmup"
+
+	selfTestFlagResource = "resource"
+	selfTestFlagActuate  = "actuate"
+
+	logLevelFlagResource = "resource"
+	logLevelFlagLevel    = "level"
+
 	loginFlagDisableBrowser = "disable-browser-open"
 	loginFlagKeyID          = "key-id"
 	loginFlagKey            = "key"
@@ -49,6 +71,10 @@ const (
 	moduleFlagForce           = "force"
 	moduleFlagBinary          = "binary"
 
+	moduleGenerateFlagAPI      = "api"
+	moduleGenerateFlagModel    = "model"
+	moduleGenerateFlagGoModule = "go-module"
+
 	moduleBuildFlagPath      = "module"
 	moduleBuildFlagRef       = "ref"
 	moduleBuildFlagCount     = "count"
@@ -60,6 +86,13 @@ const (
 	moduleBuildRestartOnly   = "restart-only"
 	moduleBuildFlagNoBuild   = "no-build"
 
+	moduleReloadFlagWatch      = "watch"
+	moduleReloadFlagFragment   = "fragment"
+	moduleReloadFlagPartFilter = "part-filter"
+	moduleReloadFlagParallel   = "parallel"
+	moduleReloadFlagRollback   = "rollback"
+	moduleReloadFlagVersion    = "version"
+
 	mlTrainingFlagPath        = "path"
 	mlTrainingFlagName        = "script-name"
 	mlTrainingFlagVersion     = "version"
@@ -89,6 +122,8 @@ const (
 	dataFlagDeleteTabularDataOlderThanDays = "delete-older-than-days"
 	dataFlagDatabasePassword               = "password"
 	dataFlagFilterTags                     = "filter-tags"
+	dataFlagFormat                         = "format"
+	dataFlagResume                         = "resume"
 
 	packageFlagName        = "name"
 	packageFlagVersion     = "version"
@@ -104,6 +139,12 @@ const (
 
 	cpFlagRecursive = "recursive"
 	cpFlagPreserve  = "preserve"
+
+	syncFlagExclude = "exclude"
+
+	orientationFlagFrom  = "from"
+	orientationFlagValue = "value"
+	orientationFlagTo    = "to"
 )
 
 var commonFilterFlags = []cli.Flag{
@@ -205,6 +246,81 @@ var dataTagByFilterFlags = append([]cli.Flag{
 },
 	commonFilterFlags...)
 
+var logsFilterFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  logsFlagResource,
+		Usage: "show only logs whose logger name contains this, such as the name of a resource",
+	},
+	&cli.StringFlag{
+		Name:  logsFlagLevel,
+		Usage: "show only logs at or above this level (debug, info, warn, error)",
+	},
+	&cli.StringFlag{
+		Name:  logsFlagRegex,
+		Usage: "show only logs whose message matches this regular expression",
+	},
+	&cli.BoolFlag{
+		Name:  logsFlagJSON,
+		Usage: "print each log as a line of JSON",
+	},
+}
+
+var partConfigFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:        organizationFlag,
+		DefaultText: "first organization alphabetically",
+	},
+	&cli.StringFlag{
+		Name:        locationFlag,
+		DefaultText: "first location alphabetically",
+	},
+	&AliasStringFlag{
+		cli.StringFlag{
+			Name:     machineFlag,
+			Aliases:  []string{aliasRobotFlag},
+			Required: true,
+		},
+	},
+	&cli.StringFlag{
+		Name:     partFlag,
+		Required: true,
+	},
+	&cli.PathFlag{
+		Name:     partConfigFlagFile,
+		Usage:    "path to a JSON config file, in the same format as the config tab's raw JSON",
+		Required: true,
+	},
+}
+
+var syncFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name: organizationFlag,
+	},
+	&cli.StringFlag{
+		Name: locationFlag,
+	},
+	&AliasStringFlag{
+		cli.StringFlag{
+			Name:     machineFlag,
+			Aliases:  []string{aliasRobotFlag},
+			Required: true,
+		},
+	},
+	&cli.StringFlag{
+		Name:     partFlag,
+		Required: true,
+	},
+	&cli.StringSliceFlag{
+		Name:  syncFlagExclude,
+		Usage: "glob of file or directory names, or relative paths, to leave out. can be repeated",
+	},
+	&cli.BoolFlag{
+		Name:    cpFlagPreserve,
+		Aliases: []string{"p"},
+		Usage:   "preserve modification times and file mode bits from the source files",
+	},
+}
+
 // createUsageText is a helper for formatting UsageTexts. The created UsageText
 // contains "viam", the command, requiredFlags, [other options] if otherOptions
 // is true, and all passed-in arguments in that order.
@@ -405,6 +521,15 @@ var app = &cli.App{
 							Name:  dataFlagDataType,
 							Usage: "type of data to download. can be binary or tabular",
 						},
+						&cli.StringFlag{
+							Name:  dataFlagFormat,
+							Usage: "file format of downloaded tabular data. can be ndjson, or csv for a file per component and method",
+							Value: dataFormatNDJSON,
+						},
+						&cli.BoolFlag{
+							Name:  dataFlagResume,
+							Usage: "continue an interrupted tabular data download to the same destination, with the same filters",
+						},
 					},
 						commonFilterFlags...),
 					Action: DataExportAction,
@@ -1044,6 +1169,18 @@ var app = &cli.App{
 					},
 					Action: ListRobotsAction,
 				},
+				{
+					Name:  "discover",
+					Usage: "list machines on the local network",
+					Flags: []cli.Flag{
+						&cli.DurationFlag{
+							Name:  discoverFlagTimeout,
+							Usage: "how long to listen for machines",
+							Value: 3 * time.Second,
+						},
+					},
+					Action: DiscoverRobotsAction,
+				},
 				{
 					Name:  "api-key",
 					Usage: "work with a machine's api keys",
@@ -1103,7 +1240,7 @@ var app = &cli.App{
 					Aliases:   []string{"log"},
 					Usage:     "display machine logs",
 					UsageText: createUsageText("machines logs", []string{machineFlag}, true),
-					Flags: []cli.Flag{
+					Flags: append([]cli.Flag{
 						&cli.StringFlag{
 							Name:        organizationFlag,
 							DefaultText: "first organization alphabetically",
@@ -1123,12 +1260,17 @@ var app = &cli.App{
 							Name:  logsFlagErrors,
 							Usage: "show only errors",
 						},
+						&cli.BoolFlag{
+							Name:    logsFlagTail,
+							Aliases: []string{"f", "follow"},
+							Usage:   "follow logs of every part",
+						},
 						&cli.IntFlag{
 							Name:        logsFlagCount,
 							Usage:       fmt.Sprintf("number of logs to fetch (max %v)", maxNumLogs),
 							DefaultText: fmt.Sprintf("%v", defaultNumLogs),
 						},
-					},
+					}, logsFilterFlags...),
 					Action: RobotsLogsAction,
 				},
 				{
@@ -1168,7 +1310,7 @@ var app = &cli.App{
 							Aliases:   []string{"log"},
 							Usage:     "display part logs",
 							UsageText: createUsageText("machines part logs", []string{machineFlag, partFlag}, true),
-							Flags: []cli.Flag{
+							Flags: append([]cli.Flag{
 								&cli.StringFlag{
 									Name:        organizationFlag,
 									DefaultText: "first organization alphabetically",
@@ -1194,7 +1336,7 @@ var app = &cli.App{
 								},
 								&cli.BoolFlag{
 									Name:    logsFlagTail,
-									Aliases: []string{"f"},
+									Aliases: []string{"f", "follow"},
 									Usage:   "follow logs",
 								},
 								&cli.IntFlag{
@@ -1202,9 +1344,37 @@ var app = &cli.App{
 									Usage:       fmt.Sprintf("number of logs to fetch (max %v)", maxNumLogs),
 									DefaultText: fmt.Sprintf("%v", defaultNumLogs),
 								},
-							},
+							}, logsFilterFlags...),
 							Action: RobotsPartLogsAction,
 						},
+						{
+							Name:            "config",
+							Usage:           "work with a machine part's config",
+							HideHelpCommand: true,
+							Subcommands: []*cli.Command{
+								{
+									Name:  "diff",
+									Usage: "show the differences between a part's config and a local JSON config file",
+									UsageText: createUsageText("machines part config diff",
+										[]string{machineFlag, partFlag, partConfigFlagFile}, true),
+									Flags:  partConfigFlags,
+									Action: RobotsPartConfigDiffAction,
+								},
+								{
+									Name:  "apply",
+									Usage: "replace a part's config with a local JSON config file, showing the differences first",
+									UsageText: createUsageText("machines part config apply",
+										[]string{machineFlag, partFlag, partConfigFlagFile}, true),
+									Flags: append([]cli.Flag{
+										&cli.BoolFlag{
+											Name:  partConfigFlagDryRun,
+											Usage: "only show what would change, without updating the part",
+										},
+									}, partConfigFlags...),
+									Action: RobotsPartConfigApplyAction,
+								},
+							},
+						},
 						{
 							Name:  "run",
 							Usage: "run a command on a machine part",
@@ -1242,6 +1412,179 @@ var app = &cli.App{
 							},
 							Action: RobotsPartRunAction,
 						},
+						{
+							Name:  "benchmark-vision",
+							Usage: "benchmark a vision service on a machine part",
+							Description: `Runs a vision service on frames from a camera on the machine part, and reports its latency
+percentiles along with the CPU, memory and, for NVIDIA GPUs, GPU use of viam-server while it ran. Latencies
+include getting each frame from the camera.`,
+							UsageText: createUsageText("machines part benchmark-vision", []string{
+								organizationFlag, locationFlag, machineFlag, partFlag, benchmarkVisionFlagService, benchmarkVisionFlagCamera,
+							}, true),
+							Flags: []cli.Flag{
+								&cli.StringFlag{
+									Name:     organizationFlag,
+									Required: true,
+								},
+								&cli.StringFlag{
+									Name:     locationFlag,
+									Required: true,
+								},
+								&AliasStringFlag{
+									cli.StringFlag{
+										Name:     machineFlag,
+										Aliases:  []string{aliasRobotFlag},
+										Required: true,
+									},
+								},
+								&cli.StringFlag{
+									Name:     partFlag,
+									Required: true,
+								},
+								&cli.StringFlag{
+									Name:     benchmarkVisionFlagService,
+									Usage:    "name of the vision service to benchmark",
+									Required: true,
+								},
+								&cli.StringFlag{
+									Name:     benchmarkVisionFlagCamera,
+									Usage:    "name of the camera to get frames from",
+									Required: true,
+								},
+								&cli.StringFlag{
+									Name:  benchmarkVisionFlagMethod,
+									Usage: "method to benchmark: detections, classifications or object_point_clouds",
+									Value: "detections",
+								},
+								&cli.IntFlag{
+									Name:  benchmarkVisionFlagFrames,
+									Usage: "number of frames to measure",
+									Value: 50,
+								},
+								&cli.IntFlag{
+									Name:  benchmarkVisionFlagWarmup,
+									Usage: "number of frames to run before measuring",
+									Value: 5,
+								},
+							},
+							Action: RobotsPartBenchmarkVisionAction,
+						},
+						{
+							Name:  "self-test",
+							Usage: "run the self-tests of a machine part's resources",
+							Description: `Runs the self-tests of the given resources, or else of those in the machine's self-test config, and
+reports which passed. Self-tests which move components, such as twitching motors and opening grippers, are
+skipped unless --actuate is given, so make sure the machine is clear of obstacles before giving it.
+Running self-tests requires the owner role.`,
+							UsageText: createUsageText("machines part self-test", []string{
+								organizationFlag, locationFlag, machineFlag, partFlag,
+							}, true),
+							Flags: []cli.Flag{
+								&cli.StringFlag{
+									Name:     organizationFlag,
+									Required: true,
+								},
+								&cli.StringFlag{
+									Name:     locationFlag,
+									Required: true,
+								},
+								&AliasStringFlag{
+									cli.StringFlag{
+										Name:     machineFlag,
+										Aliases:  []string{aliasRobotFlag},
+										Required: true,
+									},
+								},
+								&cli.StringFlag{
+									Name:     partFlag,
+									Required: true,
+								},
+								&cli.StringSliceFlag{
+									Name:  selfTestFlagResource,
+									Usage: "resource to test, such as rdk:component:motor/left, which may be given more than once",
+								},
+								&cli.BoolFlag{
+									Name:  selfTestFlagActuate,
+									Usage: "also run self-tests which move components",
+								},
+							},
+							Action: RobotsPartSelfTestAction,
+						},
+						{
+							Name:  "log-level",
+							Usage: "show or change the level each resource of a machine part logs at",
+							Description: `Lists the level each resource of the machine part logs at. Given --resource and --level, first changes
+the level that resource logs at without reconfiguring it, until it is changed again. A level of "config"
+restores the level in the resource's config. Changing a level requires the owner role.
+
+Log a motor's debug messages:
+'viam machines part log-level --organization "o1" --location "l1" --machine "m1" --part "m1-main" \
+  --resource rdk:component:motor/left --level debug'`,
+							UsageText: createUsageText("machines part log-level", []string{
+								organizationFlag, locationFlag, machineFlag, partFlag,
+							}, true),
+							Flags: []cli.Flag{
+								&cli.StringFlag{
+									Name:     organizationFlag,
+									Required: true,
+								},
+								&cli.StringFlag{
+									Name:     locationFlag,
+									Required: true,
+								},
+								&AliasStringFlag{
+									cli.StringFlag{
+										Name:     machineFlag,
+										Aliases:  []string{aliasRobotFlag},
+										Required: true,
+									},
+								},
+								&cli.StringFlag{
+									Name:     partFlag,
+									Required: true,
+								},
+								&cli.StringFlag{
+									Name:  logLevelFlagResource,
+									Usage: "resource to change the level of, such as rdk:component:motor/left",
+								},
+								&cli.StringFlag{
+									Name:  logLevelFlagLevel,
+									Usage: "level to log at: debug, info, warn, error, or config",
+								},
+							},
+							Action: RobotsPartLogLevelAction,
+						},
+						{
+							Name:  "diagnose-webrtc",
+							Usage: "connect to a machine part over WebRTC and show how a path to it was found",
+							Description: `Lists the ICE candidates gathered for the CLI and the machine part, the candidate pairs which
+were tried between them and the one selected, to debug connections which fail or are relayed.`,
+							UsageText: createUsageText("machines part diagnose-webrtc", []string{
+								organizationFlag, locationFlag, machineFlag, partFlag,
+							}, false),
+							Flags: []cli.Flag{
+								&cli.StringFlag{
+									Name:     organizationFlag,
+									Required: true,
+								},
+								&cli.StringFlag{
+									Name:     locationFlag,
+									Required: true,
+								},
+								&AliasStringFlag{
+									cli.StringFlag{
+										Name:     machineFlag,
+										Aliases:  []string{aliasRobotFlag},
+										Required: true,
+									},
+								},
+								&cli.StringFlag{
+									Name:     partFlag,
+									Required: true,
+								},
+							},
+							Action: RobotsPartDiagnoseWebRTCAction,
+						},
 						{
 							Name:        "shell",
 							Usage:       "start a shell on a machine part",
@@ -1266,6 +1609,41 @@ var app = &cli.App{
 							},
 							Action: RobotsPartShellAction,
 						},
+						{
+							Name:  "control",
+							Usage: "control the components of a machine part interactively",
+							Description: `
+Connects to a machine part and reads commands, one per line, to move motors, bases, and servos,
+print sensor readings, and save camera images. Type help for the list of commands.
+Everything moved during the session is stopped when it ends.
+
+Run a motor at half power, then stop it:
+'viam machines part control --machine "m1" --part "m1-main"'
+> motor set-power left 0.5
+> stop
+> exit`,
+							UsageText: createUsageText("machines part control", []string{machineFlag, partFlag}, true),
+							Flags: []cli.Flag{
+								&cli.StringFlag{
+									Name: organizationFlag,
+								},
+								&cli.StringFlag{
+									Name: locationFlag,
+								},
+								&AliasStringFlag{
+									cli.StringFlag{
+										Name:     machineFlag,
+										Aliases:  []string{aliasRobotFlag},
+										Required: true,
+									},
+								},
+								&cli.StringFlag{
+									Name:     partFlag,
+									Required: true,
+								},
+							},
+							Action: MachinesPartControlAction,
+						},
 						{
 							Name:  "cp",
 							Usage: "copy files to and from a machine part",
@@ -1332,6 +1710,39 @@ Copy multiple files from the machine to a local destination with recursion and k
 							},
 							Action: MachinesPartCopyFilesAction,
 						},
+						{
+							Name:  "sync",
+							Usage: "sync directories to and from a machine part",
+							Description: `
+In order to use the sync command, the machine must have a valid shell type service, and a shell with sha256sum.
+Only files that are missing or have different contents on the receiving side are copied. Files are never deleted.
+
+Sync a local directory to the machine, relative to the home directory of the user running the machine:
+'viam machine part sync push --machine "m1" --part "m1-main" --exclude "*.tmp" my_assets assets'
+
+Sync a directory on the machine to a local directory:
+'viam machine part sync pull --machine "m1" --part "m1-main" /var/log/my_module ./logs'
+`,
+							HideHelpCommand: true,
+							Subcommands: []*cli.Command{
+								{
+									Name:  "push",
+									Usage: "copy the files in a local directory that are missing or changed on a machine part",
+									UsageText: createUsageText("machines part sync push", []string{machineFlag, partFlag}, true,
+										"<local directory> <machine directory>"),
+									Flags:  syncFlags,
+									Action: MachinesPartSyncPushAction,
+								},
+								{
+									Name:  "pull",
+									Usage: "copy the files in a machine part's directory that are missing or changed locally",
+									UsageText: createUsageText("machines part sync pull", []string{machineFlag, partFlag}, true,
+										"<machine directory> <local directory>"),
+									Flags:  syncFlags,
+									Action: MachinesPartSyncPullAction,
+								},
+							},
+						},
 					},
 				},
 			},
@@ -1371,6 +1782,43 @@ After creation, use 'viam module update' to push your new module to app.viam.com
 					},
 					Action: CreateModuleAction,
 				},
+				{
+					Name:  "generate",
+					Usage: "generate a new Go module serving a model of an API",
+					Description: `Scaffolds a Go module with a stub of a model, its config and tests, a meta.json and a Makefile.
+Ex: 'viam module generate --name my-motor --api motor --public-namespace my-namespace'
+Will create the module in a new my-motor directory, with a my-namespace:my-motor:my-motor model of the motor API.
+
+Every method of the model returns an unimplemented error until filled in. The module is not registered
+on app.viam.com; use 'viam module create' for that.`,
+					UsageText: createUsageText("module generate", []string{moduleFlagName, moduleGenerateFlagAPI}, true),
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:     moduleFlagName,
+							Usage:    "name of the module, and of the directory it is generated in",
+							Required: true,
+						},
+						&cli.StringFlag{
+							Name:     moduleGenerateFlagAPI,
+							Usage:    "API the model implements: " + strings.Join(generatableAPINames(), ", "),
+							Required: true,
+						},
+						&cli.StringFlag{
+							Name:  moduleFlagPublicNamespace,
+							Usage: "the public namespace of the module, which names the model if --model is not given",
+						},
+						&cli.StringFlag{
+							Name:  moduleGenerateFlagModel,
+							Usage: "full name of the model, such as my-namespace:my-family:my-model",
+						},
+						&cli.StringFlag{
+							Name:        moduleGenerateFlagGoModule,
+							Usage:       "path of the generated Go module, such as github.com/my-org/my-module",
+							DefaultText: "the name of the module",
+						},
+					},
+					Action: GenerateModuleAction,
+				},
 				{
 					Name:  "update",
 					Usage: "update a module's metadata on app.viam.com",
@@ -1603,6 +2051,39 @@ Example:
 							Name:  moduleBuildFlagNoBuild,
 							Usage: "don't do build step",
 						},
+						&cli.BoolFlag{
+							Name:  moduleReloadFlagWatch,
+							Usage: "keep running, and rebuild & restart the module whenever a file next to the meta.json changes",
+						},
+						&cli.StringFlag{
+							Name:  organizationFlag,
+							Usage: "organization of the machines to reload on, when reloading on many parts at once",
+						},
+						&cli.StringFlag{
+							Name:  locationFlag,
+							Usage: "reload on every machine part in this location instead of a single --part",
+						},
+						&cli.StringFlag{
+							Name:  moduleReloadFlagFragment,
+							Usage: "only reload on machine parts that use this fragment ID",
+						},
+						&cli.StringFlag{
+							Name:  moduleReloadFlagPartFilter,
+							Usage: "only reload on machine parts whose name, or machine/part name, matches this glob",
+						},
+						&cli.IntFlag{
+							Name:  moduleReloadFlagParallel,
+							Usage: "number of machine parts to reload on at once",
+							Value: 4,
+						},
+						&cli.BoolFlag{
+							Name:  moduleReloadFlagRollback,
+							Usage: "if reloading fails on any machine part, restore the previous config on the parts that were changed",
+						},
+						&cli.StringFlag{
+							Name:  moduleReloadFlagVersion,
+							Usage: "version of the module, already uploaded to the registry, to pin on each part when reloading on many parts",
+						},
 					},
 					Action: ReloadModuleAction,
 				},
@@ -1873,6 +2354,37 @@ Example:
 				},
 			},
 		},
+		{
+			Name:            "orientation",
+			Usage:           "work with orientations",
+			HideHelpCommand: true,
+			Subcommands: []*cli.Command{
+				{
+					Name: "convert",
+					Usage: "check an orientation and convert it to other representations. " +
+						"types are " + orientationTypesUsage(),
+					UsageText: createUsageText("orientation convert",
+						[]string{orientationFlagFrom, orientationFlagValue}, true),
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:     orientationFlagFrom,
+							Required: true,
+							Usage:    "type of the orientation to convert",
+						},
+						&cli.StringFlag{
+							Name:     orientationFlagValue,
+							Required: true,
+							Usage:    `orientation value as JSON, e.g. '{"x": 0, "y": 0, "z": 1, "th": 90}'`,
+						},
+						&cli.StringFlag{
+							Name:  orientationFlagTo,
+							Usage: "type to convert to. if omitted, the orientation is converted to every type",
+						},
+					},
+					Action: OrientationConvertAction,
+				},
+			},
+		},
 		{
 			Name:   "version",
 			Usage:  "print version info for this program",
@@ -12,8 +12,11 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"regexp"
 	"runtime/debug"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
@@ -44,7 +47,10 @@ import (
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot/client"
+	"go.viam.com/rdk/robot/diagnostics"
+	"go.viam.com/rdk/robot/mdns"
 	"go.viam.com/rdk/services/shell"
+	"go.viam.com/rdk/services/vision"
 )
 
 const (
@@ -167,6 +173,31 @@ func ListRobotsAction(c *cli.Context) error {
 	return nil
 }
 
+// DiscoverRobotsAction is the corresponding Action for 'machines discover'.
+func DiscoverRobotsAction(c *cli.Context) error {
+	logger := logging.FromZapCompatible(zap.NewNop().Sugar())
+	if c.Bool(debugFlag) {
+		logger = logging.NewDebugLogger("cli")
+	}
+	machines, err := mdns.Discover(c.Context, c.Duration(discoverFlagTimeout), logger)
+	if err != nil {
+		return errors.Wrap(err, "could not discover machines")
+	}
+	if len(machines) == 0 {
+		infof(c.App.Writer, "no machines found on the local network")
+		return nil
+	}
+	for _, machine := range machines {
+		addresses := make([]string, 0, len(machine.Addresses))
+		for _, addr := range machine.Addresses {
+			addresses = append(addresses, net.JoinHostPort(addr.String(), fmt.Sprint(machine.Port)))
+		}
+		printf(c.App.Writer, "%s (host: %s, addresses: %s)",
+			strings.Join(machine.Names, ", "), machine.Host, strings.Join(addresses, ", "))
+	}
+	return nil
+}
+
 // RobotsStatusAction is the corresponding Action for 'machines status'.
 func RobotsStatusAction(c *cli.Context) error {
 	client, err := newViamClient(c)
@@ -237,6 +268,61 @@ func getNumLogs(c *cli.Context) (int, error) {
 	return numLogs, nil
 }
 
+// logsOptions are how logs are filtered and printed, beyond the errors-only filtering app does.
+type logsOptions struct {
+	// resource is part of the logger name of logs to print, such as the name of the resource which logged them.
+	resource string
+	minLevel logging.Level
+	// pattern, if set, must match the message of logs to print.
+	pattern *regexp.Regexp
+	asJSON  bool
+}
+
+// jsonLogEntry is how a log is printed in JSON output, one per line.
+type jsonLogEntry struct {
+	Host       string                 `json:"host"`
+	Time       time.Time              `json:"time"`
+	Level      string                 `json:"level"`
+	LoggerName string                 `json:"logger_name"`
+	Message    string                 `json:"message"`
+	Fields     map[string]interface{} `json:"fields,omitempty"`
+}
+
+func getLogsOptions(c *cli.Context) (*logsOptions, error) {
+	opts := &logsOptions{
+		resource: c.String(logsFlagResource),
+		minLevel: logging.DEBUG,
+		asJSON:   c.Bool(logsFlagJSON),
+	}
+	if levelStr := c.String(logsFlagLevel); levelStr != "" {
+		level, err := logging.LevelFromString(levelStr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %q value", logsFlagLevel)
+		}
+		opts.minLevel = level
+	}
+	if patternStr := c.String(logsFlagRegex); patternStr != "" {
+		pattern, err := regexp.Compile(patternStr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %q value", logsFlagRegex)
+		}
+		opts.pattern = pattern
+	}
+	return opts, nil
+}
+
+// matches returns whether a log passes the filters. Logs whose level cannot be parsed are kept, so that nothing is
+// hidden by mistake.
+func (opts *logsOptions) matches(log *commonpb.LogEntry) bool {
+	if opts.resource != "" && !strings.Contains(log.LoggerName, opts.resource) {
+		return false
+	}
+	if level, err := logging.LevelFromString(log.Level); err == nil && level < opts.minLevel {
+		return false
+	}
+	return opts.pattern == nil || opts.pattern.MatchString(log.Message)
+}
+
 // RobotsLogsAction is the corresponding Action for 'machines logs'.
 func RobotsLogsAction(c *cli.Context) error {
 	client, err := newViamClient(c)
@@ -257,8 +343,16 @@ func RobotsLogsAction(c *cli.Context) error {
 		return errors.Wrap(err, "could not get machine parts")
 	}
 
+	opts, err := getLogsOptions(c)
+	if err != nil {
+		return err
+	}
+	if c.Bool(logsFlagTail) {
+		return client.tailRobotLogs(orgStr, locStr, robotStr, parts, c.Bool(logsFlagErrors), opts)
+	}
+
 	for i, part := range parts {
-		if i != 0 {
+		if i != 0 && !opts.asJSON {
 			printf(c.App.Writer, "")
 		}
 
@@ -278,6 +372,7 @@ func RobotsLogsAction(c *cli.Context) error {
 			"\t",
 			header,
 			numLogs,
+			opts,
 		); err != nil {
 			return errors.Wrap(err, "could not print machine logs")
 		}
@@ -349,12 +444,17 @@ func (c *viamClient) robotsPartLogsAction(cCtx *cli.Context) error {
 	if orgStr == "" || locStr == "" || robotStr == "" {
 		header = fmt.Sprintf("%s -> %s -> %s", c.selectedOrg.Name, c.selectedLoc.Name, robot.Name)
 	}
+	opts, err := getLogsOptions(cCtx)
+	if err != nil {
+		return err
+	}
 	if cCtx.Bool(logsFlagTail) {
 		return c.tailRobotPartLogs(
 			orgStr, locStr, robotStr, cCtx.String(partFlag),
 			cCtx.Bool(logsFlagErrors),
 			"",
 			header,
+			opts,
 		)
 	}
 	numLogs, err := getNumLogs(cCtx)
@@ -367,6 +467,7 @@ func (c *viamClient) robotsPartLogsAction(cCtx *cli.Context) error {
 		"",
 		header,
 		numLogs,
+		opts,
 	)
 }
 
@@ -426,6 +527,118 @@ func RobotsPartShellAction(c *cli.Context) error {
 	)
 }
 
+// RobotsPartBenchmarkVisionAction is the corresponding Action for 'machines part benchmark-vision'.
+func RobotsPartBenchmarkVisionAction(c *cli.Context) error {
+	client, err := newViamClient(c)
+	if err != nil {
+		return err
+	}
+
+	// Create logger based on presence of debugFlag.
+	logger := logging.FromZapCompatible(zap.NewNop().Sugar())
+	if c.Bool(debugFlag) {
+		logger = logging.NewDebugLogger("cli")
+	}
+
+	return client.benchmarkVision(
+		c.String(organizationFlag),
+		c.String(locationFlag),
+		c.String(machineFlag),
+		c.String(partFlag),
+		c.String(benchmarkVisionFlagService),
+		vision.BenchmarkOptions{
+			CameraName: c.String(benchmarkVisionFlagCamera),
+			Method:     c.String(benchmarkVisionFlagMethod),
+			Frames:     c.Int(benchmarkVisionFlagFrames),
+			Warmup:     c.Int(benchmarkVisionFlagWarmup),
+		},
+		c.Bool(debugFlag),
+		logger,
+	)
+}
+
+// RobotsPartSelfTestAction is the corresponding Action for 'machines part self-test'.
+func RobotsPartSelfTestAction(c *cli.Context) error {
+	client, err := newViamClient(c)
+	if err != nil {
+		return err
+	}
+
+	// Create logger based on presence of debugFlag.
+	logger := logging.FromZapCompatible(zap.NewNop().Sugar())
+	if c.Bool(debugFlag) {
+		logger = logging.NewDebugLogger("cli")
+	}
+
+	return client.selfTest(
+		c.String(organizationFlag),
+		c.String(locationFlag),
+		c.String(machineFlag),
+		c.String(partFlag),
+		&diagnostics.RunSelfTestRequest{
+			Resources: c.StringSlice(selfTestFlagResource),
+			Actuate:   c.Bool(selfTestFlagActuate),
+		},
+		c.Bool(debugFlag),
+		logger,
+	)
+}
+
+// RobotsPartLogLevelAction is the corresponding Action for 'machines part log-level'.
+func RobotsPartLogLevelAction(c *cli.Context) error {
+	client, err := newViamClient(c)
+	if err != nil {
+		return err
+	}
+
+	// Create logger based on presence of debugFlag.
+	logger := logging.FromZapCompatible(zap.NewNop().Sugar())
+	if c.Bool(debugFlag) {
+		logger = logging.NewDebugLogger("cli")
+	}
+
+	var set *diagnostics.SetLogLevelRequest
+	if c.IsSet(logLevelFlagResource) || c.IsSet(logLevelFlagLevel) {
+		if c.String(logLevelFlagResource) == "" || c.String(logLevelFlagLevel) == "" {
+			return fmt.Errorf("--%s and --%s must be given together", logLevelFlagResource, logLevelFlagLevel)
+		}
+		set = &diagnostics.SetLogLevelRequest{Resource: c.String(logLevelFlagResource), Level: c.String(logLevelFlagLevel)}
+	}
+
+	return client.logLevels(
+		c.String(organizationFlag),
+		c.String(locationFlag),
+		c.String(machineFlag),
+		c.String(partFlag),
+		set,
+		c.Bool(debugFlag),
+		logger,
+	)
+}
+
+// RobotsPartDiagnoseWebRTCAction is the corresponding Action for 'machines part diagnose-webrtc'.
+func RobotsPartDiagnoseWebRTCAction(c *cli.Context) error {
+	client, err := newViamClient(c)
+	if err != nil {
+		return err
+	}
+
+	// Create logger based on presence of debugFlag.
+	logger := logging.FromZapCompatible(zap.NewNop().Sugar())
+	if c.Bool(debugFlag) {
+		logger = logging.NewDebugLogger("cli")
+	}
+
+	return client.diagnoseWebRTC(
+		c.String(organizationFlag),
+		c.String(locationFlag),
+		c.String(machineFlag),
+		c.String(partFlag),
+		c.Bool(debugFlag),
+		logger,
+	)
+}
+
 var (
 	errNoFiles                         = errors.New("must provide files to copy")
 	errLastArgOfFromMissing            = errors.New("expected last argument to be <copy to path>")
@@ -1142,11 +1355,18 @@ func (c *viamClient) robotParts(orgStr, locStr, robotStr string) ([]*apppb.Robot
 	return resp.Parts, nil
 }
 
-func (c *viamClient) printRobotPartLogsInner(logs []*commonpb.LogEntry, indent string) {
+func (c *viamClient) printRobotPartLogsInner(logs []*commonpb.LogEntry, indent string, opts *logsOptions) {
 	// Iterate over logs in reverse because they are returned in
 	// order of latest to oldest but we should print from oldest -> newest
 	for i := len(logs) - 1; i >= 0; i-- {
 		log := logs[i]
+		if !opts.matches(log) {
+			continue
+		}
+		if opts.asJSON {
+			c.printRobotPartLogJSON(log)
+			continue
+		}
 		fieldsString, err := logEntryFieldsToString(log.Fields)
 		if err != nil {
 			warningf(c.c.App.ErrWriter, "%v", err)
@@ -1165,14 +1385,47 @@ func (c *viamClient) printRobotPartLogsInner(logs []*commonpb.LogEntry, indent s
 	}
 }
 
+// printRobotPartLogJSON prints a log as a line of JSON, so that output can be piped to tools such as jq.
+func (c *viamClient) printRobotPartLogJSON(log *commonpb.LogEntry) {
+	entry := jsonLogEntry{
+		Host:       log.Host,
+		Time:       log.Time.AsTime(),
+		Level:      log.Level,
+		LoggerName: log.LoggerName,
+		Message:    log.Message,
+	}
+	for _, field := range log.Fields {
+		key, value, err := logging.FieldKeyAndValueFromProto(field)
+		if err != nil {
+			warningf(c.c.App.ErrWriter, "%v", err)
+			continue
+		}
+		if entry.Fields == nil {
+			entry.Fields = map[string]interface{}{}
+		}
+		entry.Fields[key] = value
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		warningf(c.c.App.ErrWriter, "%v", err)
+		return
+	}
+	printf(c.c.App.Writer, "%s", line)
+}
+
 func (c *viamClient) printRobotPartLogs(orgStr, locStr, robotStr, partStr string,
-	errorsOnly bool, indent, header string, numLogs int,
+	errorsOnly bool, indent, header string, numLogs int, opts *logsOptions,
 ) error {
 	logs, err := c.robotPartLogs(orgStr, locStr, robotStr, partStr, errorsOnly, numLogs)
 	if err != nil {
 		return err
 	}
 
+	// JSON output is left as only logs, so that it can be parsed
+	if opts.asJSON {
+		c.printRobotPartLogsInner(logs, "", opts)
+		return nil
+	}
 	if header != "" {
 		printf(c.c.App.Writer, header)
 	}
@@ -1180,12 +1433,14 @@ func (c *viamClient) printRobotPartLogs(orgStr, locStr, robotStr, partStr string
 		printf(c.c.App.Writer, "%sNo recent logs", indent)
 		return nil
 	}
-	c.printRobotPartLogsInner(logs, indent)
+	c.printRobotPartLogsInner(logs, indent, opts)
 	return nil
 }
 
 // tailRobotPartLogs tails and prints logs for the given robot part.
-func (c *viamClient) tailRobotPartLogs(orgStr, locStr, robotStr, partStr string, errorsOnly bool, indent, header string) error {
+func (c *viamClient) tailRobotPartLogs(orgStr, locStr, robotStr, partStr string, errorsOnly bool, indent, header string,
+	opts *logsOptions,
+) error {
 	part, err := c.robotPart(orgStr, locStr, robotStr, partStr)
 	if err != nil {
 		return err
@@ -1198,7 +1453,7 @@ func (c *viamClient) tailRobotPartLogs(orgStr, locStr, robotStr, partStr string,
 		return err
 	}
 
-	if header != "" {
+	if header != "" && !opts.asJSON {
 		printf(c.c.App.Writer, header)
 	}
 
@@ -1210,10 +1465,55 @@ func (c *viamClient) tailRobotPartLogs(orgStr, locStr, robotStr, partStr string,
 			}
 			return err
 		}
-		c.printRobotPartLogsInner(resp.Logs, indent)
+		c.printRobotPartLogsInner(resp.Logs, indent, opts)
 	}
 }
 
+// tailRobotLogs tails and prints logs for all of the given parts of a robot at once, until any of them fails.
+// Each log is prefixed with the name of the part it came from, unless printed as JSON.
+func (c *viamClient) tailRobotLogs(orgStr, locStr, robotStr string, parts []*apppb.RobotPart, errorsOnly bool,
+	opts *logsOptions,
+) error {
+	if len(parts) == 1 {
+		return c.tailRobotPartLogs(orgStr, locStr, robotStr, parts[0].Id, errorsOnly, "", "", opts)
+	}
+
+	// the writers are shared between parts, so lines are printed whole, one at a time
+	var printMu sync.Mutex
+	errs := make(chan error, len(parts))
+	for _, part := range parts {
+		tailClient, err := c.client.TailRobotPartLogs(c.c.Context, &apppb.TailRobotPartLogsRequest{
+			Id:         part.Id,
+			ErrorsOnly: errorsOnly,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "could not tail logs of part %q", part.Name)
+		}
+		indent := part.Name + "\t"
+		utils.PanicCapturingGo(func() {
+			for {
+				resp, err := tailClient.Recv()
+				if err != nil {
+					if errors.Is(err, io.EOF) {
+						err = nil
+					}
+					errs <- err
+					return
+				}
+				printMu.Lock()
+				c.printRobotPartLogsInner(resp.Logs, indent, opts)
+				printMu.Unlock()
+			}
+		})
+	}
+	for range parts {
+		if err := <-errs; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *viamClient) runRobotPartCommand(
 	orgStr, locStr, robotStr, partStr string,
 	svcMethod, data string,
@@ -1311,13 +1611,14 @@ func (c *viamClient) runRobotPartCommand(
 	}
 }
 
-func (c *viamClient) connectToShellService(orgStr, locStr, robotStr, partStr string,
+// connectToRobot dials a machine part and returns a client for it.
+func (c *viamClient) connectToRobot(orgStr, locStr, robotStr, partStr string,
 	debug bool,
 	logger logging.Logger,
-) (shell.Service, func(ctx context.Context) error, error) {
+) (*client.RobotClient, error) {
 	dialCtx, fqdn, rpcOpts, err := c.prepareDial(orgStr, locStr, robotStr, partStr, debug)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if debug {
@@ -1325,7 +1626,18 @@ func (c *viamClient) connectToShellService(orgStr, locStr, robotStr, partStr str
 	}
 	robotClient, err := client.New(dialCtx, fqdn, logger, client.WithDialOptions(rpcOpts...))
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "could not connect to machine part")
+		return nil, errors.Wrap(err, "could not connect to machine part")
+	}
+	return robotClient, nil
+}
+
+func (c *viamClient) connectToShellService(orgStr, locStr, robotStr, partStr string,
+	debug bool,
+	logger logging.Logger,
+) (shell.Service, func(ctx context.Context) error, error) {
+	robotClient, err := c.connectToRobot(orgStr, locStr, robotStr, partStr, debug, logger)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	var successful bool
@@ -1361,6 +1673,206 @@ func (c *viamClient) connectToShellService(orgStr, locStr, robotStr, partStr str
 	return shellSvc, robotClient.Close, nil
 }
 
+// diagnoseWebRTC connects to the machine part over WebRTC only and prints how the connection found a path to it.
+func (c *viamClient) diagnoseWebRTC(
+	orgStr, locStr, robotStr, partStr string,
+	debug bool,
+	logger logging.Logger,
+) error {
+	dialCtx, fqdn, rpcOpts, err := c.prepareDial(orgStr, locStr, robotStr, partStr, debug)
+	if err != nil {
+		return err
+	}
+	rpcOpts = append(rpcOpts,
+		rpc.WithDisableDirectGRPC(),
+		rpc.WithDialMulticastDNSOptions(rpc.DialMulticastDNSOptions{Disable: true}))
+
+	if debug {
+		printf(c.c.App.Writer, "Establishing connection...")
+	}
+	robotClient, err := client.New(dialCtx, fqdn, logger, client.WithDialOptions(rpcOpts...))
+	if err != nil {
+		return errors.Wrap(err, "could not connect to machine part over WebRTC")
+	}
+	defer func() {
+		utils.UncheckedError(robotClient.Close(c.c.Context))
+	}()
+
+	diagnostics, err := robotClient.WebRTCDiagnostics()
+	if err != nil {
+		return err
+	}
+	formatCandidate := func(candidate grpc.ICECandidate) string {
+		formatted := fmt.Sprintf("%s %s %s", candidate.Type, candidate.Protocol, net.JoinHostPort(candidate.Address, fmt.Sprint(candidate.Port)))
+		if candidate.URL != "" {
+			formatted += " via " + candidate.URL
+		}
+		return formatted
+	}
+
+	printf(c.c.App.Writer, "Connection state: %s", diagnostics.ConnectionState)
+	printf(c.c.App.Writer, "Local candidates:")
+	for _, candidate := range diagnostics.LocalCandidates {
+		printf(c.c.App.Writer, "\t%s", formatCandidate(candidate))
+	}
+	printf(c.c.App.Writer, "Remote candidates:")
+	for _, candidate := range diagnostics.RemoteCandidates {
+		printf(c.c.App.Writer, "\t%s", formatCandidate(candidate))
+	}
+	printf(c.c.App.Writer, "Candidate pairs tried:")
+	for _, pair := range diagnostics.Pairs {
+		printf(c.c.App.Writer, "\t%s -> %s: %s", formatCandidate(pair.Local), formatCandidate(pair.Remote), pair.State)
+	}
+	if diagnostics.Selected == nil {
+		warningf(c.c.App.ErrWriter, "no path was selected")
+		return nil
+	}
+	printf(c.c.App.Writer, "Selected path: %s -> %s, round trip time %s",
+		formatCandidate(diagnostics.Selected.Local), formatCandidate(diagnostics.Selected.Remote), diagnostics.Selected.RoundTripTime)
+	if diagnostics.Selected.Local.Type == "relay" || diagnostics.Selected.Remote.Type == "relay" {
+		infof(c.c.App.Writer, "The connection is relayed through a TURN server")
+	}
+	return nil
+}
+
+// benchmarkVision has the machine part benchmark one of its vision services, so that it is measured on the part's
+// own hardware, and prints the results.
+func (c *viamClient) benchmarkVision(
+	orgStr, locStr, robotStr, partStr, visionName string,
+	opts vision.BenchmarkOptions,
+	debug bool,
+	logger logging.Logger,
+) error {
+	dialCtx, fqdn, rpcOpts, err := c.prepareDial(orgStr, locStr, robotStr, partStr, debug)
+	if err != nil {
+		return err
+	}
+
+	if debug {
+		printf(c.c.App.Writer, "Establishing connection...")
+	}
+	robotClient, err := client.New(dialCtx, fqdn, logger, client.WithDialOptions(rpcOpts...))
+	if err != nil {
+		return errors.Wrap(err, "could not connect to machine part")
+	}
+	defer func() {
+		utils.UncheckedError(robotClient.Close(c.c.Context))
+	}()
+
+	visionSvc, err := vision.FromRobot(robotClient, visionName)
+	if err != nil {
+		return err
+	}
+
+	infof(c.c.App.Writer, "Benchmarking %s on %d frames from %s...", visionName, opts.Frames, opts.CameraName)
+	result, err := visionSvc.DoCommand(c.c.Context, map[string]interface{}{
+		"command":     vision.BenchmarkCommand,
+		"camera_name": opts.CameraName,
+		"method":      opts.Method,
+		"frames":      opts.Frames,
+		"warmup":      opts.Warmup,
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not benchmark vision service")
+	}
+
+	printf(c.c.App.Writer, "Latency (ms): min %.1f, mean %.1f, p50 %.1f, p90 %.1f, p99 %.1f, max %.1f",
+		result["latency_min_ms"], result["latency_mean_ms"], result["latency_p50_ms"],
+		result["latency_p90_ms"], result["latency_p99_ms"], result["latency_max_ms"])
+	printf(c.c.App.Writer, "Throughput: %.1f frames per second", result["frames_per_second"])
+	if cpu, ok := result["cpu_percent"]; ok {
+		printf(c.c.App.Writer, "CPU: %.0f%% of one core", cpu)
+	}
+	printf(c.c.App.Writer, "Memory: %.1f MiB allocated per frame, %.1f MiB peak heap",
+		bytesToMiB(result["allocated_bytes_per_frame"]), bytesToMiB(result["peak_heap_bytes"]))
+	if gpu, ok := result["gpu_percent"]; ok {
+		printf(c.c.App.Writer, "GPU: %.0f%% utilization, %.1f MiB peak memory", gpu, bytesToMiB(result["gpu_memory_bytes"]))
+	}
+	return nil
+}
+
+func (c *viamClient) selfTest(
+	orgStr, locStr, robotStr, partStr string,
+	req *diagnostics.RunSelfTestRequest,
+	debug bool,
+	logger logging.Logger,
+) error {
+	robotClient, err := c.connectToRobot(orgStr, locStr, robotStr, partStr, debug, logger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		utils.UncheckedError(robotClient.Close(c.c.Context))
+	}()
+
+	report, err := robotClient.Diagnostics().RunSelfTest(c.c.Context, req)
+	if err != nil {
+		return errors.Wrap(err, "could not run self-tests")
+	}
+
+	names := make([]string, 0, len(report.Resources))
+	for name := range report.Resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		result := report.Resources[name]
+		switch {
+		case result.Skipped:
+			printf(c.c.App.Writer, "%s: skipped, since it moves the resource (rerun with --%s)", name, selfTestFlagActuate)
+		case result.Passed:
+			printf(c.c.App.Writer, "%s: passed in %.1fs", name, result.DurationSeconds)
+		default:
+			printf(c.c.App.Writer, "%s: failed in %.1fs: %s", name, result.DurationSeconds, result.Error)
+		}
+	}
+	if !report.Passed {
+		return errors.New("self-test failed")
+	}
+	return nil
+}
+
+func (c *viamClient) logLevels(
+	orgStr, locStr, robotStr, partStr string,
+	set *diagnostics.SetLogLevelRequest,
+	debug bool,
+	logger logging.Logger,
+) error {
+	robotClient, err := c.connectToRobot(orgStr, locStr, robotStr, partStr, debug, logger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		utils.UncheckedError(robotClient.Close(c.c.Context))
+	}()
+
+	var levels *diagnostics.LogLevels
+	if set == nil {
+		levels, err = robotClient.Diagnostics().GetLogLevels(c.c.Context)
+	} else {
+		levels, err = robotClient.Diagnostics().SetLogLevel(c.c.Context, set)
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not get log levels")
+	}
+
+	names := make([]string, 0, len(levels.Levels))
+	for name := range levels.Levels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		printf(c.c.App.Writer, "%s: %s", name, levels.Levels[name])
+	}
+	return nil
+}
+
+// bytesToMiB converts a number of bytes from a DoCommand response to MiB.
+func bytesToMiB(bytes interface{}) float64 {
+	b, _ := bytes.(float64)
+	return b / (1 << 20)
+}
+
 func (c *viamClient) startRobotPartShell(
 	orgStr, locStr, robotStr, partStr string,
 	debug bool,
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -500,6 +501,65 @@ func TestGetRobotPartLogs(t *testing.T) {
 		test.That(t, err, test.ShouldNotBeNil)
 		test.That(t, err, test.ShouldBeError, errors.New(`provided too high of a "count" value. Maximum is 10000`))
 	})
+	t.Run("regex", func(t *testing.T) {
+		flags := map[string]any{logsFlagRegex: "^1[0-9]$"}
+		cCtx, ac, out, errOut := setup(asc, nil, nil, nil, flags, "")
+
+		test.That(t, ac.robotsPartLogsAction(cCtx), test.ShouldBeNil)
+		test.That(t, len(errOut.messages), test.ShouldEqual, 0)
+
+		// Only "19"->"10" should be printed after the header.
+		test.That(t, len(out.messages), test.ShouldEqual, 11)
+		expectedLogNum := 19
+		for i := 1; i <= 10; i++ {
+			test.That(t, out.messages[i], test.ShouldContainSubstring,
+				fmt.Sprintf("%d", expectedLogNum))
+			expectedLogNum--
+		}
+	})
+	t.Run("invalid regex", func(t *testing.T) {
+		flags := map[string]any{logsFlagRegex: "("}
+		cCtx, ac, _, _ := setup(asc, nil, nil, nil, flags, "")
+
+		err := ac.robotsPartLogsAction(cCtx)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, `invalid "regex" value`)
+	})
+	t.Run("json", func(t *testing.T) {
+		flags := map[string]any{logsFlagRegex: "^42$", logsFlagJSON: true}
+		cCtx, ac, out, errOut := setup(asc, nil, nil, nil, flags, "")
+
+		test.That(t, ac.robotsPartLogsAction(cCtx), test.ShouldBeNil)
+		test.That(t, len(errOut.messages), test.ShouldEqual, 0)
+
+		// There should be no header, only the log as JSON.
+		test.That(t, len(out.messages), test.ShouldEqual, 1)
+		var entry jsonLogEntry
+		test.That(t, json.Unmarshal([]byte(out.messages[0]), &entry), test.ShouldBeNil)
+		test.That(t, entry.Message, test.ShouldEqual, "42")
+	})
+}
+
+func TestLogsOptionsMatches(t *testing.T) {
+	opts := &logsOptions{resource: "motor/left", minLevel: logging.WARN}
+	test.That(t, opts.matches(&commonpb.LogEntry{
+		LoggerName: "rdk.resource_manager.rdk:component:motor/left",
+		Level:      "error",
+	}), test.ShouldBeTrue)
+	test.That(t, opts.matches(&commonpb.LogEntry{
+		LoggerName: "rdk.resource_manager.rdk:component:motor/left",
+		Level:      "info",
+	}), test.ShouldBeFalse)
+	test.That(t, opts.matches(&commonpb.LogEntry{
+		LoggerName: "rdk.resource_manager.rdk:component:motor/right",
+		Level:      "error",
+	}), test.ShouldBeFalse)
+
+	// logs with unknown levels are kept
+	test.That(t, opts.matches(&commonpb.LogEntry{
+		LoggerName: "rdk.resource_manager.rdk:component:motor/left",
+		Level:      "fatal",
+	}), test.ShouldBeTrue)
 }
 
 func TestShellFileCopy(t *testing.T) {
@@ -0,0 +1,408 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/servo"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/robot"
+	rutils "go.viam.com/rdk/utils"
+)
+
+// MachinesPartControlAction is the corresponding Action for 'machines part control'.
+func MachinesPartControlAction(c *cli.Context) error {
+	client, err := newViamClient(c)
+	if err != nil {
+		return err
+	}
+
+	// Create logger based on presence of debugFlag.
+	logger := logging.FromZapCompatible(zap.NewNop().Sugar())
+	if c.Bool(debugFlag) {
+		logger = logging.NewDebugLogger("cli")
+	}
+
+	robotClient, err := client.connectToRobot(
+		c.String(organizationFlag),
+		c.String(locationFlag),
+		c.String(machineFlag),
+		c.String(partFlag),
+		c.Bool(debugFlag),
+		logger,
+	)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		utils.UncheckedError(robotClient.Close(c.Context))
+	}()
+
+	infof(c.App.Writer, "Connected. Type help for a list of commands, or exit to stop everything moved and quit")
+	return newControlSession(robotClient, c.App.Writer).run(c.Context, c.App.Reader)
+}
+
+// A controlCommand is one command of the interactive control session.
+type controlCommand struct {
+	name  string
+	args  []string
+	usage string
+	run   func(ctx context.Context, s *controlSession, args []string) error
+}
+
+// controlCommands are the commands of the interactive control session. Each command's name is one or two
+// words, followed by its args.
+var controlCommands = []controlCommand{
+	{
+		name:  "list",
+		usage: "list the components of the machine",
+		run: func(ctx context.Context, s *controlSession, args []string) error {
+			var names []string
+			for _, name := range s.robot.ResourceNames() {
+				if name.API.IsComponent() {
+					names = append(names, fmt.Sprintf("%s (%s)", name.ShortName(), name.API.SubtypeName))
+				}
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				printf(s.w, "%s", name)
+			}
+			return nil
+		},
+	},
+	{
+		name:  "motor set-power",
+		args:  []string{"name", "power"},
+		usage: "run a motor at a power between -1 and 1 until it's stopped",
+		run: func(ctx context.Context, s *controlSession, args []string) error {
+			powerPct, err := parseControlFloat("power", args[1])
+			if err != nil {
+				return err
+			}
+			m, err := motor.FromRobot(s.robot, args[0])
+			if err != nil {
+				return err
+			}
+			s.moved(m)
+			return m.SetPower(ctx, powerPct, nil)
+		},
+	},
+	{
+		name:  "motor go-for",
+		args:  []string{"name", "rpm", "revolutions"},
+		usage: "turn a motor some revolutions at a speed, and wait until it's done",
+		run: func(ctx context.Context, s *controlSession, args []string) error {
+			rpm, err := parseControlFloat("rpm", args[1])
+			if err != nil {
+				return err
+			}
+			revolutions, err := parseControlFloat("revolutions", args[2])
+			if err != nil {
+				return err
+			}
+			m, err := motor.FromRobot(s.robot, args[0])
+			if err != nil {
+				return err
+			}
+			s.moved(m)
+			return m.GoFor(ctx, rpm, revolutions, nil)
+		},
+	},
+	{
+		name:  "motor position",
+		args:  []string{"name"},
+		usage: "print a motor's position in revolutions",
+		run: func(ctx context.Context, s *controlSession, args []string) error {
+			m, err := motor.FromRobot(s.robot, args[0])
+			if err != nil {
+				return err
+			}
+			position, err := m.Position(ctx, nil)
+			if err != nil {
+				return err
+			}
+			printf(s.w, "%v", position)
+			return nil
+		},
+	},
+	{
+		name:  "base move-straight",
+		args:  []string{"name", "mm", "mm-per-sec"},
+		usage: "drive a base forward, or backward for negative mm, and wait until it's done",
+		run: func(ctx context.Context, s *controlSession, args []string) error {
+			distanceMm, err := parseControlFloat("mm", args[1])
+			if err != nil {
+				return err
+			}
+			mmPerSec, err := parseControlFloat("mm-per-sec", args[2])
+			if err != nil {
+				return err
+			}
+			b, err := base.FromRobot(s.robot, args[0])
+			if err != nil {
+				return err
+			}
+			s.moved(b)
+			return b.MoveStraight(ctx, int(distanceMm), mmPerSec, nil)
+		},
+	},
+	{
+		name:  "base spin",
+		args:  []string{"name", "degrees", "degrees-per-sec"},
+		usage: "turn a base in place, counterclockwise for positive degrees, and wait until it's done",
+		run: func(ctx context.Context, s *controlSession, args []string) error {
+			angleDeg, err := parseControlFloat("degrees", args[1])
+			if err != nil {
+				return err
+			}
+			degsPerSec, err := parseControlFloat("degrees-per-sec", args[2])
+			if err != nil {
+				return err
+			}
+			b, err := base.FromRobot(s.robot, args[0])
+			if err != nil {
+				return err
+			}
+			s.moved(b)
+			return b.Spin(ctx, angleDeg, degsPerSec, nil)
+		},
+	},
+	{
+		name:  "servo move",
+		args:  []string{"name", "degrees"},
+		usage: "move a servo to an angle",
+		run: func(ctx context.Context, s *controlSession, args []string) error {
+			angleDeg, err := strconv.ParseUint(args[1], 10, 32)
+			if err != nil {
+				return errors.Errorf("degrees must be a whole number of degrees, got %q", args[1])
+			}
+			srv, err := servo.FromRobot(s.robot, args[0])
+			if err != nil {
+				return err
+			}
+			s.moved(srv)
+			return srv.Move(ctx, uint32(angleDeg), nil)
+		},
+	},
+	{
+		name:  "servo position",
+		args:  []string{"name"},
+		usage: "print a servo's angle in degrees",
+		run: func(ctx context.Context, s *controlSession, args []string) error {
+			srv, err := servo.FromRobot(s.robot, args[0])
+			if err != nil {
+				return err
+			}
+			angleDeg, err := srv.Position(ctx, nil)
+			if err != nil {
+				return err
+			}
+			printf(s.w, "%d", angleDeg)
+			return nil
+		},
+	},
+	{
+		name:  "sensor read",
+		args:  []string{"name"},
+		usage: "print the readings of any component with readings, such as a sensor or movement sensor",
+		run: func(ctx context.Context, s *controlSession, args []string) error {
+			name, err := s.componentNamed(args[0])
+			if err != nil {
+				return err
+			}
+			res, err := s.robot.ResourceByName(name)
+			if err != nil {
+				return err
+			}
+			sensor, ok := res.(resource.Sensor)
+			if !ok {
+				return errors.Errorf("%q is a %s, which has no readings", args[0], name.API.SubtypeName)
+			}
+			readings, err := sensor.Readings(ctx, nil)
+			if err != nil {
+				return err
+			}
+			keys := make([]string, 0, len(readings))
+			for key := range readings {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				printf(s.w, "%s: %v", key, readings[key])
+			}
+			return nil
+		},
+	},
+	{
+		name:  "camera snapshot",
+		args:  []string{"name", "file"},
+		usage: "save an image from a camera to a .jpg or .png file",
+		run: func(ctx context.Context, s *controlSession, args []string) error {
+			mimeType := rutils.MimeTypeJPEG
+			switch strings.ToLower(filepath.Ext(args[1])) {
+			case ".png":
+				mimeType = rutils.MimeTypePNG
+			case ".jpg", ".jpeg":
+			default:
+				return errors.Errorf("file must end in .jpg or .png, got %q", args[1])
+			}
+			cam, err := camera.FromRobot(s.robot, args[0])
+			if err != nil {
+				return err
+			}
+			img, release, err := camera.ReadImage(ctx, cam)
+			if err != nil {
+				return err
+			}
+			defer release()
+			imgBytes, err := rimage.EncodeImage(ctx, img, mimeType)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(args[1], imgBytes, 0o600); err != nil {
+				return err
+			}
+			printf(s.w, "Saved a %dx%d image to %s", img.Bounds().Dx(), img.Bounds().Dy(), args[1])
+			return nil
+		},
+	},
+	{
+		name:  "stop",
+		usage: "stop everything moved in this session",
+		run: func(ctx context.Context, s *controlSession, args []string) error {
+			return s.stop(ctx)
+		},
+	},
+}
+
+// A controlSession runs control commands against a machine, one line at a time.
+type controlSession struct {
+	robot robot.Robot
+	w     io.Writer
+	// actuators are everything moved in this session, which are stopped when it ends.
+	actuators map[resource.Name]resource.Actuator
+}
+
+func newControlSession(r robot.Robot, w io.Writer) *controlSession {
+	return &controlSession{robot: r, w: w, actuators: map[resource.Name]resource.Actuator{}}
+}
+
+type namedActuator interface {
+	resource.Resource
+	resource.Actuator
+}
+
+func (s *controlSession) moved(a namedActuator) {
+	s.actuators[a.Name()] = a
+}
+
+// run reads and runs commands from in until it ends or exit is entered, then stops everything moved.
+// A command that fails is reported, and doesn't end the session.
+func (s *controlSession) run(ctx context.Context, in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(s.w, "> ") // no newline
+		if !scanner.Scan() {
+			printf(s.w, "")
+			break
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "exit" || line == "quit" {
+			break
+		}
+		if err := s.runLine(ctx, line); err != nil {
+			printf(s.w, "Error: %v", err)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	// stopping shouldn't be skipped because the session was canceled
+	return multierr.Combine(scanner.Err(), s.stop(context.Background()))
+}
+
+// runLine runs a single command line.
+func (s *controlSession) runLine(ctx context.Context, line string) error {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+	if fields[0] == "help" {
+		s.printHelp()
+		return nil
+	}
+	for _, cmd := range controlCommands {
+		words := strings.Fields(cmd.name)
+		if len(fields) < len(words) || strings.Join(fields[:len(words)], " ") != cmd.name {
+			continue
+		}
+		args := fields[len(words):]
+		if len(args) != len(cmd.args) {
+			return errors.Errorf("usage: %s", cmd.synopsis())
+		}
+		return cmd.run(ctx, s, args)
+	}
+	return errors.Errorf("unknown command %q, type help for a list of commands", line)
+}
+
+func (cmd controlCommand) synopsis() string {
+	synopsis := cmd.name
+	for _, arg := range cmd.args {
+		synopsis += " <" + arg + ">"
+	}
+	return synopsis
+}
+
+func (s *controlSession) printHelp() {
+	for _, cmd := range controlCommands {
+		printf(s.w, "%-45s %s", cmd.synopsis(), cmd.usage)
+	}
+	printf(s.w, "%-45s %s", "exit", "stop everything moved in this session and quit")
+}
+
+// componentNamed returns the full name of the component with the given short name.
+func (s *controlSession) componentNamed(shortName string) (resource.Name, error) {
+	for _, name := range s.robot.ResourceNames() {
+		if name.API.IsComponent() && name.ShortName() == shortName {
+			return name, nil
+		}
+	}
+	return resource.Name{}, errors.Errorf("no component named %q", shortName)
+}
+
+// stop stops everything moved in this session.
+func (s *controlSession) stop(ctx context.Context) error {
+	var err error
+	for name, actuator := range s.actuators {
+		if stopErr := actuator.Stop(ctx, nil); stopErr != nil {
+			err = multierr.Combine(err, errors.Wrapf(stopErr, "could not stop %s", name.ShortName()))
+		}
+	}
+	return err
+}
+
+func parseControlFloat(argName, arg string) (float64, error) {
+	f, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return 0, errors.Errorf("%s must be a number, got %q", argName, arg)
+	}
+	return f, nil
+}
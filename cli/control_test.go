@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestControlSession(t *testing.T) {
+	var power float64
+	var stopped int
+	m := inject.NewMotor("left")
+	m.SetPowerFunc = func(ctx context.Context, powerPct float64, extra map[string]interface{}) error {
+		power = powerPct
+		return nil
+	}
+	m.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		stopped++
+		return nil
+	}
+	m.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (float64, error) {
+		return 2.5, nil
+	}
+	s := inject.NewSensor("temp")
+	s.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"celsius": 21.5, "humidity": 40}, nil
+	}
+	r := &inject.Robot{}
+	r.ResourceNamesFunc = func() []resource.Name {
+		return []resource.Name{m.Name(), s.Name()}
+	}
+	r.ResourceByNameFunc = func(name resource.Name) (resource.Resource, error) {
+		switch name {
+		case m.Name():
+			return m, nil
+		case s.Name():
+			return s, nil
+		default:
+			return nil, resource.NewNotFoundError(name)
+		}
+	}
+
+	t.Run("commands", func(t *testing.T) {
+		power, stopped = 0, 0
+		out := &testWriter{}
+		input := strings.Join([]string{
+			"list",
+			"motor set-power left 0.5",
+			"motor position left",
+			"sensor read temp",
+			"exit",
+			"motor set-power left 1",
+		}, "\n")
+		err := newControlSession(r, out).run(context.Background(), strings.NewReader(input))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, power, test.ShouldEqual, 0.5)
+		// everything moved is stopped when the session ends
+		test.That(t, stopped, test.ShouldEqual, 1)
+
+		output := strings.Join(out.messages, "")
+		test.That(t, output, test.ShouldContainSubstring, "left (motor)\n")
+		test.That(t, output, test.ShouldContainSubstring, "temp (sensor)\n")
+		test.That(t, output, test.ShouldContainSubstring, "2.5\n")
+		test.That(t, output, test.ShouldContainSubstring, "celsius: 21.5\nhumidity: 40\n")
+	})
+
+	t.Run("errors don't end the session", func(t *testing.T) {
+		power, stopped = 0, 0
+		out := &testWriter{}
+		input := strings.Join([]string{
+			"motor set-power left fast",
+			"motor set-power left",
+			"motor set-power right 0.5",
+			"sensor read left",
+			"dance",
+			"motor set-power left 0.25",
+			"stop",
+		}, "\n")
+		err := newControlSession(r, out).run(context.Background(), strings.NewReader(input))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, power, test.ShouldEqual, 0.25)
+		// once by the stop command, and again when the input ends
+		test.That(t, stopped, test.ShouldEqual, 2)
+
+		output := strings.Join(out.messages, "")
+		test.That(t, output, test.ShouldContainSubstring, `power must be a number, got "fast"`)
+		test.That(t, output, test.ShouldContainSubstring, "usage: motor set-power <name> <power>")
+		test.That(t, output, test.ShouldContainSubstring, `not found`)
+		test.That(t, output, test.ShouldContainSubstring, `"left" is a motor, which has no readings`)
+		test.That(t, output, test.ShouldContainSubstring, `unknown command "dance"`)
+	})
+
+	t.Run("stop errors are returned", func(t *testing.T) {
+		failing := inject.NewMotor("left")
+		failing.SetPowerFunc = func(ctx context.Context, powerPct float64, extra map[string]interface{}) error {
+			return nil
+		}
+		failing.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+			return errors.New("stuck")
+		}
+		fr := &inject.Robot{}
+		fr.ResourceByNameFunc = func(name resource.Name) (resource.Resource, error) {
+			return failing, nil
+		}
+		err := newControlSession(fr, &testWriter{}).run(context.Background(), strings.NewReader("motor set-power left 1"))
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "could not stop left: stuck")
+	})
+}
@@ -1,11 +1,9 @@
 package cli
 
 import (
-	"bufio"
 	"bytes"
 	"compress/gzip"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -19,6 +17,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/urfave/cli/v2"
+	"go.uber.org/multierr"
 	datapb "go.viam.com/api/app/data/v1"
 	"go.viam.com/utils"
 	"go.viam.com/utils/rpc"
@@ -72,7 +71,8 @@ func (c *viamClient) dataExportAction(cCtx *cli.Context) error {
 			return err
 		}
 	case dataTypeTabular:
-		if err := c.tabularData(cCtx.Path(dataFlagDestination), filter); err != nil {
+		if err := c.tabularData(cCtx.Path(dataFlagDestination), filter,
+			cCtx.String(dataFlagFormat), cCtx.Bool(dataFlagResume)); err != nil {
 			return err
 		}
 	default:
@@ -583,8 +583,9 @@ func filenameForDownload(meta *datapb.BinaryMetadata) string {
 	return fileName
 }
 
-// tabularData downloads binary data matching filter to dst.
-func (c *viamClient) tabularData(dst string, filter *datapb.Filter) error {
+// tabularData downloads tabular data matching filter to dst in the given format. If resume is set, an
+// interrupted export to dst with the same filter and format is continued from where it stopped.
+func (c *viamClient) tabularData(dst string, filter *datapb.Filter, format string, resume bool) error {
 	if err := c.ensureLoggedIn(); err != nil {
 		return err
 	}
@@ -593,27 +594,31 @@ func (c *viamClient) tabularData(dst string, filter *datapb.Filter) error {
 		return errors.Wrapf(err, "could not create destination directories")
 	}
 
-	var err error
-	var resp *datapb.TabularDataByFilterResponse
-	// TODO(DATA-640): Support export in additional formats.
-	//nolint:gosec
-	dataFile, err := os.Create(filepath.Join(dst, dataDir, "data.ndjson"))
+	if format == "" {
+		format = dataFormatNDJSON
+	}
+	writeRow, ok := tabularRowWriters[format]
+	if !ok {
+		return errors.Errorf("%s must be %s or %s, got %q", dataFlagFormat, dataFormatNDJSON, dataFormatCSV, format)
+	}
+	state, err := loadTabularExportState(dst, filter, format, resume)
 	if err != nil {
-		return errors.Wrapf(err, "could not create data file")
+		return err
 	}
-	w := bufio.NewWriter(dataFile)
+	if resume {
+		infof(c.c.App.Writer, "Resuming export to %s", dst)
+	}
+	files := newTabularExportFiles(filepath.Join(dst, dataDir), state)
 
+	var resp *datapb.TabularDataByFilterResponse
 	fmt.Fprintf(c.c.App.Writer, "Downloading..") // no newline
-	var last string
-	mdIndexes := make(map[string]int)
-	mdIndex := 0
 	for {
 		for count := 0; count < maxRetryCount; count++ {
 			resp, err = c.dataClient.TabularDataByFilter(context.Background(), &datapb.TabularDataByFilterRequest{
 				DataRequest: &datapb.DataRequest{
 					Filter: filter,
 					Limit:  maxLimit,
-					Last:   last,
+					Last:   state.Last,
 				},
 				CountOnly: false,
 			})
@@ -623,10 +628,9 @@ func (c *viamClient) tabularData(dst string, filter *datapb.Filter) error {
 			}
 		}
 		if err != nil {
-			return err
+			return multierr.Combine(err, files.close())
 		}
 
-		last = resp.GetLast()
 		mds := resp.GetMetadata()
 		if len(mds) == 0 {
 			break
@@ -634,60 +638,61 @@ func (c *viamClient) tabularData(dst string, filter *datapb.Filter) error {
 		// Map the current response's metadata indexes to those combined across all responses.
 		localToGlobalMDIndex := make(map[int]int)
 		for i, md := range mds {
-			currMDIndex, ok := mdIndexes[md.String()]
+			currMDIndex, ok := state.MetadataIndexes[md.String()]
 			if ok {
 				localToGlobalMDIndex[i] = currMDIndex
 				continue // Already have this metadata file, so skip creating it again.
 			}
-			mdIndexes[md.String()] = mdIndex
+			mdIndex := len(state.MetadataIndexes)
+			state.MetadataIndexes[md.String()] = mdIndex
 			localToGlobalMDIndex[i] = mdIndex
 
 			mdJSONBytes, err := protojson.Marshal(md)
 			if err != nil {
-				return errors.Wrap(err, "could not marshal metadata")
+				return multierr.Combine(errors.Wrap(err, "could not marshal metadata"), files.close())
 			}
 			//nolint:gosec
 			mdFile, err := os.Create(filepath.Join(dst, metadataDir, strconv.Itoa(mdIndex)+".json"))
 			if err != nil {
-				return errors.Wrapf(err, fmt.Sprintf("could not create metadata file for metadata index %d", mdIndex))
+				return multierr.Combine(
+					errors.Wrapf(err, fmt.Sprintf("could not create metadata file for metadata index %d", mdIndex)), files.close())
 			}
 			if _, err := mdFile.Write(mdJSONBytes); err != nil {
-				return errors.Wrapf(err, "could not write to metadata file %s", mdFile.Name())
+				return multierr.Combine(errors.Wrapf(err, "could not write to metadata file %s", mdFile.Name()), files.close())
 			}
 			if err := mdFile.Close(); err != nil {
-				return errors.Wrapf(err, "could not close metadata file %s", mdFile.Name())
+				return multierr.Combine(errors.Wrapf(err, "could not close metadata file %s", mdFile.Name()), files.close())
 			}
-			mdIndex++
 		}
 
 		data := resp.GetData()
 		for _, datum := range data {
-			// Write everything as json for now.
-			d := datum.GetData()
-			if d == nil {
+			if datum.GetData() == nil {
 				continue
 			}
-			m := d.AsMap()
-			m["TimeRequested"] = datum.GetTimeRequested()
-			m["TimeReceived"] = datum.GetTimeReceived()
-			m["MetadataIndex"] = localToGlobalMDIndex[int(datum.GetMetadataIndex())]
-			j, err := json.Marshal(m)
-			if err != nil {
-				return errors.Wrap(err, "could not marshal JSON response")
-			}
-			_, err = w.Write(append(j, []byte("\n")...))
-			if err != nil {
-				return errors.Wrapf(err, "could not write to file %s", dataFile.Name())
+			if err := writeRow(files, datum, localToGlobalMDIndex[int(datum.GetMetadataIndex())]); err != nil {
+				return multierr.Combine(err, files.close())
 			}
 		}
+
+		// only once the whole page is on disk is it safe to resume after it
+		state.Last = resp.GetLast()
+		if err := files.checkpoint(); err != nil {
+			return multierr.Combine(err, files.close())
+		}
+		if err := state.save(dst); err != nil {
+			return multierr.Combine(err, files.close())
+		}
 	}
 
 	printf(c.c.App.Writer, "") // newline
-	if err := w.Flush(); err != nil {
-		return errors.Wrapf(err, "could not flush writer for %s", dataFile.Name())
+	for _, warning := range files.warnings() {
+		warningf(c.c.App.Writer, "%s", warning)
 	}
-
-	return nil
+	if err := files.close(); err != nil {
+		return err
+	}
+	return removeTabularExportState(dst)
 }
 
 func makeDestinationDirs(dst string) error {
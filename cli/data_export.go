@@ -0,0 +1,327 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	datapb "go.viam.com/api/app/data/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	dataFormatNDJSON = "ndjson"
+	dataFormatCSV    = "csv"
+
+	tabularExportStateFilename = ".tabular-export.json"
+)
+
+// tabularRowWriters write one row of tabular data in each export format.
+var tabularRowWriters = map[string]func(files *tabularExportFiles, datum *datapb.TabularData, mdIndex int) error{
+	dataFormatNDJSON: writeTabularRowNDJSON,
+	dataFormatCSV:    writeTabularRowCSV,
+}
+
+// tabularExportState is the progress of a tabular export. It's saved next to the export after every page
+// of data so that an interrupted export can be resumed without downloading everything again.
+type tabularExportState struct {
+	Filter string `json:"filter"`
+	Format string `json:"format"`
+	// Last is the pagination token of the last page fully written to disk.
+	Last            string         `json:"last"`
+	MetadataIndexes map[string]int `json:"metadata_indexes"`
+	// FileSizes are the sizes of the data files as of Last. Anything written after that is from a page
+	// that will be downloaded again, so it's cut off when resuming.
+	FileSizes map[string]int64 `json:"file_sizes"`
+	// Columns are the header of each CSV file, which later rows are written to match.
+	Columns map[string][]string `json:"columns,omitempty"`
+}
+
+// loadTabularExportState returns the state to start an export to dst from. Unless resuming, that's a new export.
+func loadTabularExportState(dst string, filter *datapb.Filter, format string, resume bool) (*tabularExportState, error) {
+	filterJSON, err := protojson.Marshal(filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal filter")
+	}
+	state := &tabularExportState{
+		Filter:          string(filterJSON),
+		Format:          format,
+		MetadataIndexes: map[string]int{},
+		FileSizes:       map[string]int64{},
+		Columns:         map[string][]string{},
+	}
+	if !resume {
+		return state, nil
+	}
+
+	//nolint:gosec
+	stateBytes, err := os.ReadFile(filepath.Join(dst, tabularExportStateFilename))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, errors.Errorf("there is no interrupted export in %q to resume", dst)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var saved tabularExportState
+	if err := json.Unmarshal(stateBytes, &saved); err != nil {
+		return nil, errors.Wrapf(err, "could not read the progress of the export in %q", dst)
+	}
+	var savedFilter datapb.Filter
+	if err := protojson.Unmarshal([]byte(saved.Filter), &savedFilter); err != nil {
+		return nil, errors.Wrapf(err, "could not read the progress of the export in %q", dst)
+	}
+	if saved.Format != format || !proto.Equal(&savedFilter, filter) {
+		return nil, errors.Errorf("the export in %q was started with a different filter or format", dst)
+	}
+	if saved.MetadataIndexes == nil {
+		saved.MetadataIndexes = map[string]int{}
+	}
+	if saved.FileSizes == nil {
+		saved.FileSizes = map[string]int64{}
+	}
+	if saved.Columns == nil {
+		saved.Columns = map[string][]string{}
+	}
+	return &saved, nil
+}
+
+// save writes the state to dst, replacing the previous state whole so an interruption can't leave it half written.
+func (s *tabularExportState) save(dst string) error {
+	stateBytes, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	statePath := filepath.Join(dst, tabularExportStateFilename)
+	if err := os.WriteFile(statePath+".tmp", stateBytes, 0o600); err != nil {
+		return errors.Wrap(err, "could not save export progress")
+	}
+	return errors.Wrap(os.Rename(statePath+".tmp", statePath), "could not save export progress")
+}
+
+// removeTabularExportState removes the state of a finished export from dst.
+func removeTabularExportState(dst string) error {
+	if err := os.Remove(filepath.Join(dst, tabularExportStateFilename)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// tabularExportFiles are the data files of a tabular export, opened the first time they are written to.
+type tabularExportFiles struct {
+	dir     string
+	state   *tabularExportState
+	files   map[string]*os.File
+	writers map[string]*bufio.Writer
+	// dropped are the fields left out of each CSV file because they weren't in its header.
+	dropped map[string]map[string]bool
+}
+
+func newTabularExportFiles(dir string, state *tabularExportState) *tabularExportFiles {
+	return &tabularExportFiles{
+		dir:     dir,
+		state:   state,
+		files:   map[string]*os.File{},
+		writers: map[string]*bufio.Writer{},
+		dropped: map[string]map[string]bool{},
+	}
+}
+
+// writer returns a writer to the end of the named file, and whether the file was just created.
+// A file from a resumed export is first cut back to its size when its progress was saved.
+func (f *tabularExportFiles) writer(name string) (*bufio.Writer, bool, error) {
+	if w, ok := f.writers[name]; ok {
+		return w, false, nil
+	}
+	filePath := filepath.Join(f.dir, name)
+	size, resumed := f.state.FileSizes[name]
+	var file *os.File
+	var err error
+	if resumed {
+		//nolint:gosec
+		file, err = os.OpenFile(filePath, os.O_WRONLY, 0o600)
+		if err == nil {
+			err = file.Truncate(size)
+		}
+		if err == nil {
+			_, err = file.Seek(size, io.SeekStart)
+		}
+	} else {
+		//nolint:gosec
+		file, err = os.Create(filePath)
+	}
+	if err != nil {
+		if file != nil {
+			//nolint:errcheck,gosec
+			file.Close()
+		}
+		return nil, false, errors.Wrapf(err, "could not open data file %s", filePath)
+	}
+	f.files[name] = file
+	f.writers[name] = bufio.NewWriter(file)
+	return f.writers[name], !resumed, nil
+}
+
+// checkpoint flushes every file and records its size in the export state.
+func (f *tabularExportFiles) checkpoint() error {
+	for name, w := range f.writers {
+		if err := w.Flush(); err != nil {
+			return errors.Wrapf(err, "could not flush writer for %s", f.files[name].Name())
+		}
+		size, err := f.files[name].Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		f.state.FileSizes[name] = size
+	}
+	return nil
+}
+
+// warnings describes the fields that were left out of the export.
+func (f *tabularExportFiles) warnings() []string {
+	names := make([]string, 0, len(f.dropped))
+	for name := range f.dropped {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	warnings := make([]string, 0, len(names))
+	for _, name := range names {
+		columns := make([]string, 0, len(f.dropped[name]))
+		for column := range f.dropped[name] {
+			columns = append(columns, column)
+		}
+		sort.Strings(columns)
+		warnings = append(warnings,
+			fmt.Sprintf("some rows of %s have fields not in its first row, which were left out: %s", name, strings.Join(columns, ", ")))
+	}
+	return warnings
+}
+
+func (f *tabularExportFiles) close() error {
+	var err error
+	for name, file := range f.files {
+		err = multierr.Combine(err, f.writers[name].Flush(), file.Close())
+	}
+	f.files = map[string]*os.File{}
+	f.writers = map[string]*bufio.Writer{}
+	return err
+}
+
+// writeTabularRowNDJSON writes all data to a single data.ndjson file, one JSON object per line.
+func writeTabularRowNDJSON(files *tabularExportFiles, datum *datapb.TabularData, mdIndex int) error {
+	w, _, err := files.writer("data.ndjson")
+	if err != nil {
+		return err
+	}
+	m := datum.GetData().AsMap()
+	m["TimeRequested"] = datum.GetTimeRequested()
+	m["TimeReceived"] = datum.GetTimeReceived()
+	m["MetadataIndex"] = mdIndex
+	j, err := json.Marshal(m)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal JSON response")
+	}
+	if _, err := w.Write(append(j, []byte("\n")...)); err != nil {
+		return errors.Wrap(err, "could not write to data.ndjson")
+	}
+	return nil
+}
+
+// writeTabularRowCSV writes the data of each metadata index, which is a single component and method,
+// to its own <metadata index>.csv file. Nested fields are flattened into dot separated columns, and the
+// columns of a file are those of its first row.
+func writeTabularRowCSV(files *tabularExportFiles, datum *datapb.TabularData, mdIndex int) error {
+	name := strconv.Itoa(mdIndex) + ".csv"
+	w, created, err := files.writer(name)
+	if err != nil {
+		return err
+	}
+	fields := map[string]string{}
+	flattenTabularData("", datum.GetData().AsMap(), fields)
+	fields["TimeRequested"] = formatTabularTime(datum.GetTimeRequested())
+	fields["TimeReceived"] = formatTabularTime(datum.GetTimeReceived())
+
+	csvWriter := csv.NewWriter(w)
+	if created {
+		columns := make([]string, 0, len(fields))
+		for column := range fields {
+			if column != "TimeRequested" && column != "TimeReceived" {
+				columns = append(columns, column)
+			}
+		}
+		sort.Strings(columns)
+		columns = append([]string{"TimeRequested", "TimeReceived"}, columns...)
+		files.state.Columns[name] = columns
+		if err := csvWriter.Write(columns); err != nil {
+			return errors.Wrapf(err, "could not write to %s", name)
+		}
+	}
+
+	columns := files.state.Columns[name]
+	row := make([]string, len(columns))
+	for i, column := range columns {
+		row[i] = fields[column]
+		delete(fields, column)
+	}
+	for column := range fields {
+		if files.dropped[name] == nil {
+			files.dropped[name] = map[string]bool{}
+		}
+		files.dropped[name][column] = true
+	}
+	if err := csvWriter.Write(row); err != nil {
+		return errors.Wrapf(err, "could not write to %s", name)
+	}
+	csvWriter.Flush()
+	return errors.Wrapf(csvWriter.Error(), "could not write to %s", name)
+}
+
+// flattenTabularData adds the fields of value to out, with nested field names joined by dots.
+// Lists are kept whole as JSON, since their lengths vary between rows.
+func flattenTabularData(prefix string, value any, out map[string]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flattenTabularData(key, field, out)
+		}
+	case []any:
+		listJSON, err := json.Marshal(v)
+		if err != nil {
+			out[prefix] = fmt.Sprint(v)
+			return
+		}
+		out[prefix] = string(listJSON)
+	case nil:
+		out[prefix] = ""
+	case string:
+		out[prefix] = v
+	case float64:
+		out[prefix] = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		out[prefix] = strconv.FormatBool(v)
+	default:
+		out[prefix] = fmt.Sprint(v)
+	}
+}
+
+func formatTabularTime(t *timestamppb.Timestamp) string {
+	if t == nil {
+		return ""
+	}
+	return t.AsTime().Format(time.RFC3339Nano)
+}
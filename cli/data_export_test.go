@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	datapb "go.viam.com/api/app/data/v1"
+	"go.viam.com/test"
+	"go.viam.com/utils/protoutils"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestFlattenTabularData(t *testing.T) {
+	out := map[string]string{}
+	flattenTabularData("", map[string]any{
+		"readings": map[string]any{
+			"temp":   21.5,
+			"ok":     true,
+			"unit":   "C",
+			"values": []any{1.0, 2.0},
+			"none":   nil,
+		},
+	}, out)
+	test.That(t, out, test.ShouldResemble, map[string]string{
+		"readings.temp":   "21.5",
+		"readings.ok":     "true",
+		"readings.unit":   "C",
+		"readings.values": "[1,2]",
+		"readings.none":   "",
+	})
+}
+
+func TestTabularDataCSVResume(t *testing.T) {
+	row := func(temp float64) *datapb.TabularData {
+		data, err := protoutils.StructToStructPb(map[string]any{"readings": map[string]any{"temp": temp}})
+		test.That(t, err, test.ShouldBeNil)
+		return &datapb.TabularData{Data: data, TimeRequested: timestamppb.New(time.Unix(int64(temp), 0).UTC())}
+	}
+	pages := map[string]*datapb.TabularDataByFilterResponse{
+		"": {
+			Data:     []*datapb.TabularData{row(1), row(2)},
+			Metadata: []*datapb.CaptureMetadata{{ComponentName: "sensor1"}},
+			Last:     "page1",
+		},
+		"page1": {
+			Data:     []*datapb.TabularData{row(3)},
+			Metadata: []*datapb.CaptureMetadata{{ComponentName: "sensor1"}},
+			Last:     "page2",
+		},
+		"page2": {},
+	}
+	failAfterFirstPage := true
+	dsc := &inject.DataServiceClient{
+		TabularDataByFilterFunc: func(ctx context.Context, in *datapb.TabularDataByFilterRequest, opts ...grpc.CallOption,
+		) (*datapb.TabularDataByFilterResponse, error) {
+			if failAfterFirstPage && in.DataRequest.Last != "" {
+				return nil, errors.New("connection lost")
+			}
+			return pages[in.DataRequest.Last], nil
+		},
+	}
+	_, ac, _, _ := setup(&inject.AppServiceClient{}, dsc, nil, nil, nil, "token")
+	dst := t.TempDir()
+	filter := &datapb.Filter{ComponentName: "sensor1"}
+
+	test.That(t, ac.tabularData(dst, filter, dataFormatCSV, true), test.ShouldNotBeNil)
+	test.That(t, ac.tabularData(dst, filter, dataFormatCSV, false), test.ShouldNotBeNil)
+	_, err := os.Stat(filepath.Join(dst, tabularExportStateFilename))
+	test.That(t, err, test.ShouldBeNil)
+
+	// an export can only be resumed with what it was started with
+	test.That(t, ac.tabularData(dst, filter, dataFormatNDJSON, true), test.ShouldNotBeNil)
+	test.That(t, ac.tabularData(dst, &datapb.Filter{ComponentName: "sensor2"}, dataFormatCSV, true), test.ShouldNotBeNil)
+
+	failAfterFirstPage = false
+	test.That(t, ac.tabularData(dst, filter, dataFormatCSV, true), test.ShouldBeNil)
+	contents, err := os.ReadFile(filepath.Join(dst, dataDir, "0.csv"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(contents), test.ShouldEqual, "TimeRequested,TimeReceived,readings.temp\n"+
+		"1970-01-01T00:00:01Z,,1\n"+
+		"1970-01-01T00:00:02Z,,2\n"+
+		"1970-01-01T00:00:03Z,,3\n")
+	_, err = os.Stat(filepath.Join(dst, tabularExportStateFilename))
+	test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 	"text/tabwriter"
@@ -406,10 +407,39 @@ func reloadModuleAction(c *cli.Context, vc *viamClient) error {
 		warningf(c.App.Writer,
 			"You have passed in a part ID -- if it's for a remote device and your module isn't in the expected path, this will fail")
 	}
-	partID, err := resolvePartID(c.Context, c.String(partFlag), "/etc/viam.json")
+	var reload func() error
+	if isFleetReload(c) {
+		reload = func() error { return reloadModuleFleet(c, vc) }
+	} else {
+		partID, err := resolvePartID(c.Context, c.String(partFlag), "/etc/viam.json")
+		if err != nil {
+			return err
+		}
+		reload = func() error { return reloadModule(c, vc, partID) }
+	}
+	if !c.Bool(moduleReloadFlagWatch) {
+		return reload()
+	}
+	// a failed build or restart shouldn't end a watch, the next change may fix it
+	if err := reload(); err != nil {
+		warningf(c.App.Writer, "Reload failed: %v", err)
+	}
+	manifest, err := loadManifestOrNil(c.String(moduleFlagPath))
 	if err != nil {
 		return err
 	}
+	return watchModuleSource(c.Context, c.App.Writer, filepath.Dir(c.String(moduleFlagPath)), manifest, func() {
+		if err := reload(); err != nil {
+			warningf(c.App.Writer, "Reload failed: %v", err)
+			return
+		}
+		infof(c.App.Writer, "Reload complete")
+	})
+}
+
+// reloadModule runs a single build, configure and restart of the module on the given part.
+// The manifest and part are re-read each time so that a watched reload sees the latest of both.
+func reloadModule(c *cli.Context, vc *viamClient, partID string) error {
 	manifest, err := loadManifestOrNil(c.String(moduleFlagPath))
 	if err != nil {
 		return err
@@ -421,6 +451,11 @@ func reloadModuleAction(c *cli.Context, vc *viamClient) error {
 	if part.Part == nil {
 		return fmt.Errorf("part with id=%s not found", partID)
 	}
+	// checked before building so that a part that can't run the module fails fast
+	pins, err := vc.checkModuleRequirements(c.App.Writer, manifest, part.Part)
+	if err != nil {
+		return err
+	}
 	// note: configureModule and restartModule signal the robot via different channels.
 	// Running this command in rapid succession can cause an extra restart because the
 	// CLI will see configuration changes before the robot, and skip to the needsRestart
@@ -437,7 +472,7 @@ func reloadModuleAction(c *cli.Context, vc *viamClient) error {
 				return err
 			}
 		}
-		needsRestart, err = configureModule(c, vc, manifest, part.Part)
+		needsRestart, err = configureModule(c, vc, manifest, part.Part, pins)
 		if err != nil {
 			return err
 		}
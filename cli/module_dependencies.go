@@ -0,0 +1,265 @@
+package cli
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+	apppb "go.viam.com/api/app/v1"
+
+	rdkConfig "go.viam.com/rdk/config"
+)
+
+// uploadPlatformAny is the platform of a module upload that runs anywhere.
+const uploadPlatformAny = "any"
+
+// partInfo returns a field of the agent info the part last reported, ex: its version or platform.
+func partInfo(part *apppb.RobotPart, key string) string {
+	if part.UserSuppliedInfo == nil {
+		return ""
+	}
+	value, _ := part.UserSuppliedInfo.AsMap()[key].(string)
+	return value
+}
+
+// checkModuleRequirements checks that the part can run the module in the manifest, and returns the version
+// of each of the module's dependencies to pin in the part's config.
+func (c *viamClient) checkModuleRequirements(w io.Writer, manifest *moduleManifest, part *apppb.RobotPart) (map[string]string, error) {
+	if manifest == nil {
+		//nolint:nilnil
+		return nil, nil
+	}
+	if err := checkRDKVersion(w, manifest, part); err != nil {
+		return nil, err
+	}
+	return c.resolveModuleDependencies(manifest, part)
+}
+
+// checkRDKVersion returns an error if the part's RDK version doesn't satisfy the manifest's rdk_version.
+// A part that hasn't reported a release version, ex: a dev build, is only warned about.
+func checkRDKVersion(w io.Writer, manifest *moduleManifest, part *apppb.RobotPart) error {
+	if manifest.RDKVersion == "" {
+		return nil
+	}
+	constraint, err := semver.NewConstraint(manifest.RDKVersion)
+	if err != nil {
+		return errors.Wrapf(err, "invalid rdk_version %q in meta.json", manifest.RDKVersion)
+	}
+	partVersion := partInfo(part, "version")
+	version, err := semver.NewVersion(partVersion)
+	if err != nil {
+		warningf(w, "Could not tell the RDK version of part %q, so it wasn't checked against rdk_version %q",
+			part.Name, manifest.RDKVersion)
+		return nil
+	}
+	if !constraint.Check(version) {
+		return errors.Errorf("part %q runs RDK %s, but %s requires rdk_version %q. Update the RDK on the machine to reload this module",
+			part.Name, partVersion, manifest.ModuleID, manifest.RDKVersion)
+	}
+	return nil
+}
+
+// resolveModuleDependencies chooses a registry version of each dependency in the manifest for the part.
+// A dependency the part's config already pins to a version is kept at that version if it's compatible,
+// otherwise the newest compatible version is chosen. Returns a map of module ID to version.
+func (c *viamClient) resolveModuleDependencies(manifest *moduleManifest, part *apppb.RobotPart) (map[string]string, error) {
+	if len(manifest.Dependencies) == 0 {
+		//nolint:nilnil
+		return nil, nil
+	}
+	var configured []any
+	if part.RobotConfig != nil {
+		configured, _ = part.RobotConfig.AsMap()["modules"].([]any)
+	}
+	platform := partInfo(part, "platform")
+
+	pins := map[string]string{}
+	for _, dep := range manifest.Dependencies {
+		if dep.ModuleID == manifest.ModuleID {
+			return nil, errors.Errorf("%s can't depend on itself", dep.ModuleID)
+		}
+		modID, err := parseModuleID(dep.ModuleID)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid dependency in meta.json")
+		}
+		constraintStr := dep.Version
+		if constraintStr == "" {
+			constraintStr = "*"
+		}
+		constraint, err := semver.NewConstraint(constraintStr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid version %q for dependency %s in meta.json", dep.Version, dep.ModuleID)
+		}
+
+		var pinned string
+		for _, raw := range configured {
+			mod, ok := raw.(map[string]any)
+			if !ok || mod["module_id"] != dep.ModuleID {
+				continue
+			}
+			if getMapString(mod, "type") == string(rdkConfig.ModuleTypeLocal) {
+				// a local copy of the dependency is the developer's to keep compatible
+				pinned = string(rdkConfig.ModuleTypeLocal)
+				break
+			}
+			// "latest" and "latest-with-prerelease" aren't pins, and are replaced with one
+			if _, err := semver.StrictNewVersion(getMapString(mod, "version")); err == nil {
+				pinned = getMapString(mod, "version")
+			}
+			break
+		}
+		if pinned == string(rdkConfig.ModuleTypeLocal) {
+			continue
+		}
+
+		resp, err := c.getModule(modID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not get dependency %s from the registry", dep.ModuleID)
+		}
+		versions := resp.GetModule().GetVersions()
+
+		if pinned != "" {
+			pinnedVersion, err := semver.StrictNewVersion(pinned)
+			if err != nil {
+				return nil, err
+			}
+			if !constraint.Check(pinnedVersion) {
+				return nil, errors.Errorf("part %q pins %s to version %s, but %s requires version %q",
+					part.Name, dep.ModuleID, pinned, manifest.ModuleID, constraintStr)
+			}
+			for _, v := range versions {
+				if v.Version != pinned {
+					continue
+				}
+				if missing := missingModels(v, dep.Models); len(missing) > 0 {
+					return nil, errors.Errorf("part %q pins %s to version %s, which doesn't provide %s",
+						part.Name, dep.ModuleID, pinned, strings.Join(missing, ", "))
+				}
+			}
+			pins[dep.ModuleID] = pinned
+			continue
+		}
+
+		resolved, err := newestCompatibleVersion(versions, constraint, dep.Models, platform)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not resolve dependency %s %q of %s", dep.ModuleID, constraintStr, manifest.ModuleID)
+		}
+		pins[dep.ModuleID] = resolved
+	}
+	return pins, nil
+}
+
+// newestCompatibleVersion returns the newest of the registry versions of a module that satisfies the
+// constraint, provides all the given models, and has an upload for the platform if it's known.
+func newestCompatibleVersion(
+	versions []*apppb.VersionHistory,
+	constraint *semver.Constraints,
+	models []ModuleComponent,
+	platform string,
+) (string, error) {
+	type candidate struct {
+		version *semver.Version
+		history *apppb.VersionHistory
+	}
+	var candidates []candidate
+	for _, v := range versions {
+		version, err := semver.NewVersion(v.Version)
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, candidate{version, v})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].version.GreaterThan(candidates[j].version)
+	})
+
+	var satisfied bool
+	var missing []string
+	for _, cand := range candidates {
+		if !constraint.Check(cand.version) {
+			continue
+		}
+		satisfied = true
+		if platform != "" && !hasUploadForPlatform(cand.history, platform) {
+			continue
+		}
+		if m := missingModels(cand.history, models); len(m) > 0 {
+			if missing == nil {
+				missing = m
+			}
+			continue
+		}
+		return cand.history.Version, nil
+	}
+	switch {
+	case !satisfied:
+		return "", errors.New("no version in the registry satisfies the constraint")
+	case missing != nil:
+		return "", errors.Errorf("no satisfying version provides %s", strings.Join(missing, ", "))
+	default:
+		return "", errors.Errorf("no satisfying version has been uploaded for %s", platform)
+	}
+}
+
+func hasUploadForPlatform(version *apppb.VersionHistory, platform string) bool {
+	for _, upload := range version.Files {
+		if upload.Platform == platform || upload.Platform == uploadPlatformAny {
+			return true
+		}
+	}
+	return false
+}
+
+// missingModels returns the models, as "api model", that a registry version of a module doesn't provide.
+func missingModels(version *apppb.VersionHistory, models []ModuleComponent) []string {
+	var missing []string
+	for _, model := range models {
+		var found bool
+		for _, provided := range version.Models {
+			if provided.Api == model.API && provided.Model == model.Model {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, model.API+" "+model.Model)
+		}
+	}
+	return missing
+}
+
+// pinModuleDependencies sets the version of each pinned module in the modules list, adding the
+// registry modules that aren't configured yet. Returns the new list and whether it changed.
+func pinModuleDependencies(modules []ModuleMap, pins map[string]string) ([]ModuleMap, bool) {
+	moduleIDs := make([]string, 0, len(pins))
+	for id := range pins {
+		moduleIDs = append(moduleIDs, id)
+	}
+	sort.Strings(moduleIDs)
+
+	var dirty bool
+	for _, id := range moduleIDs {
+		var foundMod ModuleMap
+		for _, mod := range modules {
+			if mod["module_id"] == id {
+				foundMod = mod
+				break
+			}
+		}
+		if foundMod == nil {
+			dirty = true
+			modules = append(modules, ModuleMap(map[string]any{
+				"name":      strings.ReplaceAll(id, ":", "_"),
+				"module_id": id,
+				"type":      string(rdkConfig.ModuleTypeRegistry),
+				"version":   pins[id],
+			}))
+		} else if getMapString(foundMod, "version") != pins[id] {
+			dirty = true
+			foundMod["version"] = pins[id]
+		}
+	}
+	return modules, dirty
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	apppb "go.viam.com/api/app/v1"
+	"go.viam.com/test"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func testPart(t *testing.T, info map[string]any, modules ...any) *apppb.RobotPart {
+	t.Helper()
+	conf, err := structpb.NewStruct(map[string]any{"modules": modules})
+	test.That(t, err, test.ShouldBeNil)
+	part := &apppb.RobotPart{Name: "main", RobotConfig: conf}
+	if info != nil {
+		part.UserSuppliedInfo, err = structpb.NewStruct(info)
+		test.That(t, err, test.ShouldBeNil)
+	}
+	return part
+}
+
+func TestCheckRDKVersion(t *testing.T) {
+	manifest := &moduleManifest{ModuleID: "acme:arm", RDKVersion: ">=0.24.0"}
+
+	out := &testWriter{}
+	err := checkRDKVersion(out, manifest, testPart(t, map[string]any{"version": "v0.25.1"}))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out.messages, test.ShouldBeEmpty)
+
+	err = checkRDKVersion(out, manifest, testPart(t, map[string]any{"version": "v0.23.0"}))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `part "main" runs RDK v0.23.0, but acme:arm requires rdk_version ">=0.24.0"`)
+
+	// unknown versions are warned about, not failed
+	err = checkRDKVersion(out, manifest, testPart(t, map[string]any{"version": "dev"}))
+	test.That(t, err, test.ShouldBeNil)
+	err = checkRDKVersion(out, manifest, testPart(t, nil))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out.messages, test.ShouldHaveLength, 2)
+
+	err = checkRDKVersion(out, &moduleManifest{RDKVersion: "not a version"}, testPart(t, nil))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "invalid rdk_version")
+}
+
+func TestResolveModuleDependencies(t *testing.T) {
+	gripperModel := &apppb.Model{Api: "rdk:component:gripper", Model: "acme:gripper:claw"}
+	versions := []*apppb.VersionHistory{
+		{Version: "1.1.0", Models: []*apppb.Model{gripperModel}, Files: []*apppb.Uploads{{Platform: "linux/arm64"}}},
+		{Version: "1.2.0", Models: []*apppb.Model{gripperModel}, Files: []*apppb.Uploads{{Platform: "linux/arm64"}}},
+		{Version: "1.3.0", Models: []*apppb.Model{gripperModel}, Files: []*apppb.Uploads{{Platform: "linux/amd64"}}},
+		{Version: "1.4.0-rc1", Models: []*apppb.Model{gripperModel}, Files: []*apppb.Uploads{{Platform: uploadPlatformAny}}},
+		{Version: "2.0.0", Files: []*apppb.Uploads{{Platform: uploadPlatformAny}}},
+	}
+	var requested []string
+	asc := &inject.AppServiceClient{
+		GetModuleFunc: func(ctx context.Context, in *apppb.GetModuleRequest,
+			opts ...grpc.CallOption,
+		) (*apppb.GetModuleResponse, error) {
+			requested = append(requested, in.ModuleId)
+			return &apppb.GetModuleResponse{Module: &apppb.Module{ModuleId: in.ModuleId, Versions: versions}}, nil
+		},
+	}
+	_, ac, _, _ := setup(asc, nil, nil, nil, map[string]any{}, "token")
+
+	manifest := func(version string, models ...ModuleComponent) *moduleManifest {
+		return &moduleManifest{
+			ModuleID:     "acme:arm",
+			Dependencies: []moduleDependency{{ModuleID: "acme:gripper", Version: version, Models: models}},
+		}
+	}
+	claw := ModuleComponent{API: "rdk:component:gripper", Model: "acme:gripper:claw"}
+	arm64 := map[string]any{"platform": "linux/arm64"}
+
+	t.Run("newest compatible", func(t *testing.T) {
+		pins, err := ac.resolveModuleDependencies(manifest("^1.0.0", claw), testPart(t, arm64))
+		test.That(t, err, test.ShouldBeNil)
+		// 1.3.0 has no arm64 upload, and prereleases aren't chosen
+		test.That(t, pins, test.ShouldResemble, map[string]string{"acme:gripper": "1.2.0"})
+
+		pins, err = ac.resolveModuleDependencies(manifest("^1.0.0", claw), testPart(t, nil))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pins, test.ShouldResemble, map[string]string{"acme:gripper": "1.3.0"})
+
+		pins, err = ac.resolveModuleDependencies(manifest(""), testPart(t, nil))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pins, test.ShouldResemble, map[string]string{"acme:gripper": "2.0.0"})
+	})
+
+	t.Run("unpinned config", func(t *testing.T) {
+		part := testPart(t, arm64, map[string]any{"module_id": "acme:gripper", "type": "registry", "version": "latest-with-prerelease"})
+		pins, err := ac.resolveModuleDependencies(manifest("~1.1.0"), part)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pins, test.ShouldResemble, map[string]string{"acme:gripper": "1.1.0"})
+	})
+
+	t.Run("pinned config", func(t *testing.T) {
+		part := testPart(t, arm64, map[string]any{"module_id": "acme:gripper", "type": "registry", "version": "1.1.0"})
+		pins, err := ac.resolveModuleDependencies(manifest("^1.0.0", claw), part)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pins, test.ShouldResemble, map[string]string{"acme:gripper": "1.1.0"})
+
+		_, err = ac.resolveModuleDependencies(manifest("^1.2.0"), part)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring,
+			`part "main" pins acme:gripper to version 1.1.0, but acme:arm requires version "^1.2.0"`)
+	})
+
+	t.Run("local dependency", func(t *testing.T) {
+		requested = nil
+		part := testPart(t, arm64, map[string]any{"module_id": "acme:gripper", "type": "local"})
+		pins, err := ac.resolveModuleDependencies(manifest("^5.0.0"), part)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pins, test.ShouldBeEmpty)
+		test.That(t, requested, test.ShouldBeEmpty)
+	})
+
+	t.Run("unresolvable", func(t *testing.T) {
+		_, err := ac.resolveModuleDependencies(manifest("^3.0.0"), testPart(t, nil))
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "no version in the registry satisfies the constraint")
+
+		_, err = ac.resolveModuleDependencies(manifest(">=2.0.0", claw), testPart(t, nil))
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "no satisfying version provides rdk:component:gripper acme:gripper:claw")
+
+		_, err = ac.resolveModuleDependencies(manifest("~1.3.0"), testPart(t, arm64))
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "no satisfying version has been uploaded for linux/arm64")
+
+		_, err = ac.resolveModuleDependencies(manifest("one"), testPart(t, nil))
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "invalid version")
+	})
+}
+
+func TestPinModuleDependencies(t *testing.T) {
+	modules := []ModuleMap{
+		{"name": "acme_gripper", "module_id": "acme:gripper", "type": "registry", "version": "latest"},
+		{"name": "acme_camera", "module_id": "acme:camera", "type": "registry", "version": "0.1.0"},
+	}
+	modules, dirty := pinModuleDependencies(modules, map[string]string{"acme:gripper": "1.2.0", "acme:camera": "0.1.0", "acme:base": "2.0.0"})
+	test.That(t, dirty, test.ShouldBeTrue)
+	test.That(t, modules, test.ShouldHaveLength, 3)
+	test.That(t, modules[0]["version"], test.ShouldEqual, "1.2.0")
+	test.That(t, modules[1]["version"], test.ShouldEqual, "0.1.0")
+	test.That(t, modules[2], test.ShouldResemble, ModuleMap{
+		"name": "acme_base", "module_id": "acme:base", "type": "registry", "version": "2.0.0",
+	})
+
+	_, dirty = pinModuleDependencies(modules, map[string]string{"acme:gripper": "1.2.0"})
+	test.That(t, dirty, test.ShouldBeFalse)
+	_, dirty = pinModuleDependencies(modules, nil)
+	test.That(t, dirty, test.ShouldBeFalse)
+}
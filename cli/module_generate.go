@@ -0,0 +1,644 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/encoder"
+	"go.viam.com/rdk/components/gantry"
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/components/gripper"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/components/powersensor"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/components/servo"
+	modconfig "go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+	genericservice "go.viam.com/rdk/services/generic"
+)
+
+// generatableAPI is an API which modules can be generated for.
+type generatableAPI struct {
+	api resource.API
+	// pkgPath is the import path of the package which defines the API.
+	pkgPath string
+	// iface is the interface models of the API implement.
+	iface reflect.Type
+}
+
+// generatableAPIs are the APIs `module generate` can scaffold models of, keyed by the name given on the command line.
+var generatableAPIs = map[string]generatableAPI{
+	"arm":             {arm.API, "go.viam.com/rdk/components/arm", reflect.TypeOf((*arm.Arm)(nil)).Elem()},
+	"base":            {base.API, "go.viam.com/rdk/components/base", reflect.TypeOf((*base.Base)(nil)).Elem()},
+	"camera":          {camera.API, "go.viam.com/rdk/components/camera", reflect.TypeOf((*camera.Camera)(nil)).Elem()},
+	"encoder":         {encoder.API, "go.viam.com/rdk/components/encoder", reflect.TypeOf((*encoder.Encoder)(nil)).Elem()},
+	"gantry":          {gantry.API, "go.viam.com/rdk/components/gantry", reflect.TypeOf((*gantry.Gantry)(nil)).Elem()},
+	"generic":         {generic.API, "go.viam.com/rdk/components/generic", reflect.TypeOf((*resource.Resource)(nil)).Elem()},
+	"generic-service": {genericservice.API, "go.viam.com/rdk/services/generic", reflect.TypeOf((*resource.Resource)(nil)).Elem()},
+	"gripper":         {gripper.API, "go.viam.com/rdk/components/gripper", reflect.TypeOf((*gripper.Gripper)(nil)).Elem()},
+	"motor":           {motor.API, "go.viam.com/rdk/components/motor", reflect.TypeOf((*motor.Motor)(nil)).Elem()},
+	"movement_sensor": {
+		movementsensor.API, "go.viam.com/rdk/components/movementsensor",
+		reflect.TypeOf((*movementsensor.MovementSensor)(nil)).Elem(),
+	},
+	"power_sensor": {
+		powersensor.API, "go.viam.com/rdk/components/powersensor",
+		reflect.TypeOf((*powersensor.PowerSensor)(nil)).Elem(),
+	},
+	"sensor": {sensor.API, "go.viam.com/rdk/components/sensor", reflect.TypeOf((*sensor.Sensor)(nil)).Elem()},
+	"servo":  {servo.API, "go.viam.com/rdk/components/servo", reflect.TypeOf((*servo.Servo)(nil)).Elem()},
+}
+
+func generatableAPINames() []string {
+	names := make([]string, 0, len(generatableAPIs))
+	for name := range generatableAPIs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GenerateModuleAction is the corresponding Action for 'module generate'.
+func GenerateModuleAction(c *cli.Context) error {
+	moduleName := c.String(moduleFlagName)
+	apiName := c.String(moduleGenerateFlagAPI)
+	api, ok := generatableAPIs[apiName]
+	if !ok {
+		return errors.Errorf("cannot generate modules for %q, only for one of %s",
+			apiName, strings.Join(generatableAPINames(), ", "))
+	}
+
+	var model resource.Model
+	switch {
+	case c.String(moduleGenerateFlagModel) != "":
+		var err error
+		model, err = resource.NewModelFromString(c.String(moduleGenerateFlagModel))
+		if err != nil {
+			return err
+		}
+	case c.String(moduleFlagPublicNamespace) != "":
+		model = resource.NewModel(c.String(moduleFlagPublicNamespace), moduleName, moduleName)
+		if err := model.Validate(); err != nil {
+			return err
+		}
+	default:
+		return errors.Errorf("either %q or %q is required to name the model", moduleGenerateFlagModel, moduleFlagPublicNamespace)
+	}
+
+	goModule := c.String(moduleGenerateFlagGoModule)
+	if goModule == "" {
+		goModule = moduleName
+	}
+	moduleID := moduleName
+	if namespace := c.String(moduleFlagPublicNamespace); namespace != "" {
+		moduleID = namespace + ":" + moduleName
+	}
+
+	files, err := generateModule(moduleName, moduleID, goModule, api, model)
+	if err != nil {
+		return err
+	}
+	if err := writeGeneratedModule(moduleName, files); err != nil {
+		return err
+	}
+
+	printf(c.App.Writer, "Generated module %q with a %s model %q in ./%s", moduleID, apiName, model, moduleName)
+	printf(c.App.Writer, "Next, fill in the model's methods and config, then run 'make setup test module.tar.gz' in ./%s", moduleName)
+	return nil
+}
+
+// writeGeneratedModule writes the generated files into a new directory, which must not already exist so that
+// nothing is overwritten.
+func writeGeneratedModule(dir string, files map[string][]byte) error {
+	if _, err := os.Stat(dir); err == nil {
+		return errors.Errorf("%s already exists", dir)
+	}
+	for name, contents := range files {
+		filePath := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0o750); err != nil {
+			return errors.Wrapf(err, "failed to create %s", filepath.Dir(filePath))
+		}
+		if err := os.WriteFile(filePath, contents, 0o600); err != nil {
+			return errors.Wrapf(err, "failed to write %s", filePath)
+		}
+	}
+	return nil
+}
+
+// generatedModule is what the module templates are filled in with.
+type generatedModule struct {
+	Name     string
+	GoModule string
+	API      resource.API
+	// APIPkg is the name the package defining the API is imported as, by the import spec APIImport.
+	APIPkg    string
+	APIImport string
+	Interface string
+	Model     resource.Model
+	// Pkg is the name of the package the model is implemented in, and TypeName the name of its type.
+	Pkg      string
+	TypeName string
+	Imports  string
+	Methods  string
+}
+
+// generateModule returns the files of a new Go module serving one model of api, keyed by their paths in the module.
+func generateModule(name, moduleID, goModule string, api generatableAPI, model resource.Model) (map[string][]byte, error) {
+	imports := newGoImports()
+	imports.add("context")
+	imports.add("errors")
+	imports.add("go.viam.com/rdk/logging")
+	imports.add("go.viam.com/rdk/resource")
+	apiPkg := imports.add(api.pkgPath)
+	mod := generatedModule{
+		Name:      name,
+		GoModule:  goModule,
+		API:       api.api,
+		APIPkg:    apiPkg,
+		APIImport: imports.spec(api.pkgPath),
+		Interface: imports.typeString(api.iface),
+		Model:     model,
+		Pkg:       goIdentifier(model.Name, false),
+		TypeName:  goIdentifier(model.Name, true),
+	}
+	// main.go imports the model's package alongside these, so it cannot share their names
+	if mod.Pkg == "main" || mod.Pkg == apiPkg || mainImports[mod.Pkg] {
+		mod.Pkg += "model"
+	}
+	if token.IsKeyword(mod.TypeName) || types.Universe.Lookup(mod.TypeName) != nil {
+		mod.TypeName += "Model"
+	}
+	methods, err := generateMethodStubs(mod.TypeName, api.iface, imports)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot generate %s models", api.api)
+	}
+	mod.Methods = methods
+	mod.Imports = imports.String()
+
+	files := map[string][]byte{}
+	for fileName, tmpl := range map[string]*template.Template{
+		"go.mod":                              goModTemplate,
+		"Makefile":                            makefileTemplate,
+		"main.go":                             mainTemplate,
+		filepath.Join(mod.Pkg, mod.Pkg+".go"): modelTemplate,
+		filepath.Join(mod.Pkg, mod.Pkg+"_test.go"): modelTestTemplate,
+	} {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, mod); err != nil {
+			return nil, errors.Wrapf(err, "failed to generate %s", fileName)
+		}
+		contents := buf.Bytes()
+		if strings.HasSuffix(fileName, ".go") {
+			if contents, err = format.Source(contents); err != nil {
+				return nil, errors.Wrapf(err, "generated invalid %s", fileName)
+			}
+		}
+		files[fileName] = contents
+	}
+
+	manifest := moduleManifest{
+		ModuleID:   moduleID,
+		Visibility: moduleVisibilityPrivate,
+		Models:     []ModuleComponent{{API: api.api.String(), Model: model.String()}},
+		JSONManifest: modconfig.JSONManifest{
+			Entrypoint: "bin/" + name,
+		},
+		Build: &manifestBuildInfo{
+			Build: defaultBuildInfo.Build,
+			Setup: "make setup",
+			Path:  defaultBuildInfo.Path,
+			Arch:  defaultBuildInfo.Arch,
+		},
+	}
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	files[defaultManifestFilename] = manifestBytes
+	return files, nil
+}
+
+// methodsProvidedByTemplate are the methods of resource.Resource which the model template implements, rather than
+// being stubbed out.
+var methodsProvidedByTemplate = map[string]bool{"Name": true, "Reconfigure": true, "Close": true}
+
+// generateMethodStubs returns Go source for methods on typeName implementing iface, each of which returns
+// errUnimplemented.
+func generateMethodStubs(typeName string, iface reflect.Type, imports *goImports) (string, error) {
+	var buf strings.Builder
+	for i := 0; i < iface.NumMethod(); i++ {
+		method := iface.Method(i)
+		if methodsProvidedByTemplate[method.Name] {
+			continue
+		}
+		if method.PkgPath != "" {
+			return "", errors.Errorf("unexported method %s cannot be implemented outside of its package", method.Name)
+		}
+		fn := method.Type
+
+		params := make([]string, 0, fn.NumIn())
+		for j := 0; j < fn.NumIn(); j++ {
+			paramType := imports.typeString(fn.In(j))
+			if fn.IsVariadic() && j == fn.NumIn()-1 {
+				paramType = "..." + imports.typeString(fn.In(j).Elem())
+			}
+			params = append(params, stubParamName(method.Name, fn, j)+" "+paramType)
+		}
+
+		results := make([]string, 0, fn.NumOut())
+		zeros := make([]string, 0, fn.NumOut())
+		for j := 0; j < fn.NumOut(); j++ {
+			resultType := imports.typeString(fn.Out(j))
+			results = append(results, resultType)
+			if fn.Out(j) == errorType {
+				zeros = append(zeros, "errUnimplemented")
+				continue
+			}
+			zeros = append(zeros, zeroValue(fn.Out(j), resultType))
+		}
+		resultsStr := strings.Join(results, ", ")
+		if len(results) > 1 {
+			resultsStr = "(" + resultsStr + ")"
+		}
+
+		fmt.Fprintf(&buf, "\n// %s is not implemented yet.\n", method.Name)
+		fmt.Fprintf(&buf, "func (res *%s) %s(%s) %s {\n", typeName, method.Name, strings.Join(params, ", "), resultsStr)
+		if len(zeros) != 0 {
+			fmt.Fprintf(&buf, "\treturn %s\n", strings.Join(zeros, ", "))
+		}
+		buf.WriteString("}\n")
+	}
+	return buf.String(), nil
+}
+
+var (
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+	extraType = reflect.TypeOf(map[string]interface{}{})
+)
+
+// stubParamName names the j-th parameter of a stubbed method, after the conventions of resource APIs where it can.
+func stubParamName(methodName string, fn reflect.Type, j int) string {
+	param := fn.In(j)
+	switch {
+	case param.PkgPath() == "context" && param.Name() == "Context":
+		return "ctx"
+	case param == extraType && methodName == "DoCommand":
+		return "cmd"
+	case param == extraType && j == fn.NumIn()-1:
+		return "extra"
+	default:
+		return fmt.Sprintf("arg%d", j)
+	}
+}
+
+// zeroValue returns Go source for the zero value of t, which is written as typeStr.
+func zeroValue(t reflect.Type, typeStr string) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "false"
+	case reflect.String:
+		return `""`
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return "0"
+	case reflect.Struct, reflect.Array:
+		return typeStr + "{}"
+	case reflect.Invalid, reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer,
+		reflect.Slice, reflect.UnsafePointer:
+		return "nil"
+	}
+	return "nil"
+}
+
+// goImports tracks the packages generated Go source imports, and the names they are imported as.
+type goImports struct {
+	names map[string]string
+	paths map[string]string
+}
+
+func newGoImports() *goImports {
+	return &goImports{names: map[string]string{}, paths: map[string]string{}}
+}
+
+// add imports the package at path, returning the name it is imported as, which is its last path element unless
+// another package already has that name.
+func (imps *goImports) add(path string) string {
+	return imps.addNamed(path, path[strings.LastIndex(path, "/")+1:])
+}
+
+func (imps *goImports) addNamed(path, name string) string {
+	if existing, ok := imps.names[path]; ok {
+		return existing
+	}
+	alias := name
+	for i := 2; imps.paths[alias] != ""; i++ {
+		alias = fmt.Sprintf("%s%d", name, i)
+	}
+	imps.names[path] = alias
+	imps.paths[alias] = path
+	return alias
+}
+
+// typeString returns Go source for t, importing the packages of any named types in it.
+func (imps *goImports) typeString(t reflect.Type) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name()
+		}
+		// the package name, which may differ from the last element of its path, prefixes the type's string
+		pkgName, _, _ := strings.Cut(t.String(), ".")
+		return imps.addNamed(t.PkgPath(), pkgName) + "." + t.Name()
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return "*" + imps.typeString(t.Elem())
+	case reflect.Slice:
+		return "[]" + imps.typeString(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), imps.typeString(t.Elem()))
+	case reflect.Map:
+		return fmt.Sprintf("map[%s]%s", imps.typeString(t.Key()), imps.typeString(t.Elem()))
+	case reflect.Chan:
+		switch t.ChanDir() {
+		case reflect.RecvDir:
+			return "<-chan " + imps.typeString(t.Elem())
+		case reflect.SendDir:
+			return "chan<- " + imps.typeString(t.Elem())
+		case reflect.BothDir:
+		}
+		return "chan " + imps.typeString(t.Elem())
+	case reflect.Func:
+		params := make([]string, 0, t.NumIn())
+		for i := 0; i < t.NumIn(); i++ {
+			if t.IsVariadic() && i == t.NumIn()-1 {
+				params = append(params, "..."+imps.typeString(t.In(i).Elem()))
+				continue
+			}
+			params = append(params, imps.typeString(t.In(i)))
+		}
+		results := make([]string, 0, t.NumOut())
+		for i := 0; i < t.NumOut(); i++ {
+			results = append(results, imps.typeString(t.Out(i)))
+		}
+		str := "func(" + strings.Join(params, ", ") + ")"
+		switch len(results) {
+		case 0:
+			return str
+		case 1:
+			return str + " " + results[0]
+		default:
+			return str + " (" + strings.Join(results, ", ") + ")"
+		}
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "interface{}"
+		}
+	case reflect.Struct:
+		if t.NumField() == 0 {
+			return "struct{}"
+		}
+	default:
+	}
+	// other unnamed types, such as interfaces with methods, do not appear in resource APIs
+	return t.String()
+}
+
+// spec returns the import spec of the package at path, naming it if it is imported as other than its last path
+// element.
+func (imps *goImports) spec(path string) string {
+	if alias := imps.names[path]; alias != path[strings.LastIndex(path, "/")+1:] {
+		return fmt.Sprintf("%s %q", alias, path)
+	}
+	return fmt.Sprintf("%q", path)
+}
+
+// String returns the import declarations, with the standard library in a group before everything else.
+func (imps *goImports) String() string {
+	var std, other []string
+	for path := range imps.names {
+		spec := imps.spec(path)
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			other = append(other, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	return strings.Join(std, "\n") + "\n\n" + strings.Join(other, "\n")
+}
+
+// goIdentifier turns a model name such as "my-camera" into a Go package name like "mycamera", or an unexported
+// type name like "myCamera".
+func goIdentifier(name string, camelCase bool) string {
+	var ident strings.Builder
+	upperNext := false
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upperNext = camelCase && ident.Len() != 0
+			continue
+		}
+		if ident.Len() == 0 && unicode.IsDigit(r) {
+			ident.WriteString("model")
+		}
+		if upperNext {
+			r = unicode.ToUpper(r)
+			upperNext = false
+		} else if ident.Len() == 0 || !camelCase {
+			r = unicode.ToLower(r)
+		}
+		ident.WriteRune(r)
+	}
+	if ident.Len() == 0 {
+		return "model"
+	}
+	return ident.String()
+}
+
+var goModTemplate = template.Must(template.New("go.mod").Parse(`module {{.GoModule}}
+
+go 1.21
+`))
+
+var makefileTemplate = template.Must(template.New("Makefile").Parse(`BIN := bin/{{.Name}}
+
+.PHONY: setup test
+
+$(BIN): go.mod *.go {{.Pkg}}/*.go
+	go build -o $(BIN) .
+
+# setup resolves the module's dependencies, including the latest RDK.
+setup:
+	go mod tidy
+
+test:
+	go test ./...
+
+module.tar.gz: $(BIN) meta.json
+	tar czf $@ meta.json $(BIN)
+`))
+
+// mainImports are the names of the packages main.go imports, other than the API's and the model's.
+var mainImports = map[string]bool{"context": true, "utils": true, "logging": true, "module": true}
+
+var mainTemplate = template.Must(template.New("main.go").Parse(`// Package main is a module serving {{.Model}}, a model of the {{.API}} API.
+package main
+
+import (
+	"context"
+
+	"go.viam.com/utils"
+
+	"{{.GoModule}}/{{.Pkg}}"
+	{{.APIImport}}
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/module"
+)
+
+func main() {
+	utils.ContextualMain(mainWithArgs, module.NewLoggerFromArgs("{{.Name}}"))
+}
+
+func mainWithArgs(ctx context.Context, args []string, logger logging.Logger) error {
+	mod, err := module.NewModuleFromArgs(ctx, logger)
+	if err != nil {
+		return err
+	}
+
+	// {{.Pkg}} registers its model in its init(), so that it can be added to the module here.
+	if err := mod.AddModelFromRegistry(ctx, {{.APIPkg}}.API, {{.Pkg}}.Model); err != nil {
+		return err
+	}
+
+	err = mod.Start(ctx)
+	defer mod.Close(ctx)
+	if err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return nil
+}
+`))
+
+var modelTemplate = template.Must(template.New("model.go").Parse(`// Package {{.Pkg}} implements the {{.Model}} model of the {{.API}} API.
+package {{.Pkg}}
+
+import (
+{{.Imports}}
+)
+
+// Model is the full name of the model.
+var Model = resource.NewModel("{{.Model.Family.Namespace}}", "{{.Model.Family.Name}}", "{{.Model.Name}}")
+
+var errUnimplemented = errors.New("unimplemented")
+
+func init() {
+	resource.Register{{if .API.IsComponent}}Component{{else}}Service{{end}}({{.APIPkg}}.API, Model,
+		resource.Registration[{{.Interface}}, *Config]{Constructor: new{{.TypeName}}})
+}
+
+// Config is the model's config, which is parsed from the attributes of the resource's config.
+// Add fields here with json tags naming their attributes.
+type Config struct{}
+
+// Validate checks the config, returning the names of any resources the model depends on so that
+// they are built first.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	return nil, nil
+}
+
+type {{.TypeName}} struct {
+	resource.Named
+
+	logger logging.Logger
+	cfg    *Config
+}
+
+func new{{.TypeName}}(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) ({{.Interface}}, error) {
+	res := &{{.TypeName}}{
+		Named:  conf.ResourceName().AsNamed(),
+		logger: logger,
+	}
+	if err := res.Reconfigure(ctx, deps, conf); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Reconfigure applies a new config, such as when the resource's attributes change.
+func (res *{{.TypeName}}) Reconfigure(ctx context.Context, deps resource.Dependencies, conf resource.Config) error {
+	cfg, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return err
+	}
+	res.cfg = cfg
+	return nil
+}
+
+// Close releases anything the resource holds, such as hardware or background goroutines.
+func (res *{{.TypeName}}) Close(ctx context.Context) error {
+	return nil
+}
+{{.Methods}}`))
+
+var modelTestTemplate = template.Must(template.New("model_test.go").Parse(`package {{.Pkg}}
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+func TestValidate(t *testing.T) {
+	deps, err := (&Config{}).Validate("attributes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deps) != 0 {
+		t.Fatalf("expected no dependencies, got %v", deps)
+	}
+}
+
+func TestNew(t *testing.T) {
+	ctx := context.Background()
+	conf := resource.Config{
+		Name:                "test",
+		Model:               Model,
+		ConvertedAttributes: &Config{},
+	}
+	res, err := new{{.TypeName}}(ctx, resource.Dependencies{}, conf, logging.NewTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := res.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+`))
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/resource"
+)
+
+func TestGenerateModule(t *testing.T) {
+	model := resource.NewModel("acme", "my-module", "my-model")
+
+	// every API's generated Go source must at least parse and format
+	for _, apiName := range generatableAPINames() {
+		files, err := generateModule("my-module", "acme:my-module", "github.com/acme/my-module", generatableAPIs[apiName], model)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, files, test.ShouldContainKey, "main.go")
+		test.That(t, files, test.ShouldContainKey, filepath.Join("mymodel", "mymodel.go"))
+		test.That(t, files, test.ShouldContainKey, filepath.Join("mymodel", "mymodel_test.go"))
+		test.That(t, string(files["go.mod"]), test.ShouldStartWith, "module github.com/acme/my-module\n")
+		test.That(t, string(files["main.go"]), test.ShouldContainSubstring, `"github.com/acme/my-module/mymodel"`)
+
+		var manifest moduleManifest
+		test.That(t, json.Unmarshal(files[defaultManifestFilename], &manifest), test.ShouldBeNil)
+		test.That(t, manifest.ModuleID, test.ShouldEqual, "acme:my-module")
+		test.That(t, manifest.Entrypoint, test.ShouldEqual, "bin/my-module")
+		test.That(t, manifest.Models, test.ShouldResemble, []ModuleComponent{
+			{API: generatableAPIs[apiName].api.String(), Model: "acme:my-module:my-model"},
+		})
+	}
+
+	files, err := generateModule("my-module", "my-module", "my-module", generatableAPIs["motor"], model)
+	test.That(t, err, test.ShouldBeNil)
+	motorSource := string(files[filepath.Join("mymodel", "mymodel.go")])
+	test.That(t, motorSource, test.ShouldContainSubstring, "resource.RegisterComponent(motor.API, Model")
+	test.That(t, motorSource, test.ShouldContainSubstring,
+		"func (res *myModel) SetPower(ctx context.Context, arg1 float64, extra map[string]interface{}) error {")
+	test.That(t, motorSource, test.ShouldContainSubstring, "return 0, errUnimplemented")
+
+	// a model named after its API's package cannot share its name in main.go
+	files, err = generateModule("my-module", "my-module", "my-module", generatableAPIs["camera"],
+		resource.NewModel("acme", "my-module", "camera"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, files, test.ShouldContainKey, filepath.Join("cameramodel", "cameramodel.go"))
+}
+
+func TestGoIdentifier(t *testing.T) {
+	test.That(t, goIdentifier("my-camera", false), test.ShouldEqual, "mycamera")
+	test.That(t, goIdentifier("my-camera", true), test.ShouldEqual, "myCamera")
+	test.That(t, goIdentifier("My_Fancy_Motor", true), test.ShouldEqual, "myFancyMotor")
+	test.That(t, goIdentifier("2d-lidar", false), test.ShouldEqual, "model2dlidar")
+	test.That(t, goIdentifier("---", true), test.ShouldEqual, "model")
+}
+
+func TestWriteGeneratedModule(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "my-module")
+	files := map[string][]byte{
+		"main.go":                        []byte("package main\n"),
+		filepath.Join("mymodel", "a.go"): []byte("package mymodel\n"),
+	}
+	test.That(t, writeGeneratedModule(dir, files), test.ShouldBeNil)
+	contents, err := os.ReadFile(filepath.Join(dir, "mymodel", "a.go"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(contents), test.ShouldEqual, "package mymodel\n")
+
+	// an existing module is never overwritten
+	test.That(t, writeGeneratedModule(dir, files), test.ShouldNotBeNil)
+}
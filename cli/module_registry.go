@@ -78,6 +78,19 @@ type moduleManifest struct {
 	// JsonManifest provides fields shared with RDK proper.
 	modconfig.JSONManifest
 	Build *manifestBuildInfo `json:"build,omitempty"`
+	// RDKVersion is a semver constraint, ex: ">=0.24", on the RDK version of machines the module can run on.
+	RDKVersion string `json:"rdk_version,omitempty"`
+	// Dependencies are the registry modules this module needs on the same machine.
+	Dependencies []moduleDependency `json:"dependencies,omitempty"`
+}
+
+// moduleDependency is an entry in the "dependencies" section of meta.json.
+type moduleDependency struct {
+	ModuleID string `json:"module_id"`
+	// Version is a semver constraint, ex: "^1.2.0". Empty allows any release, but not prereleases.
+	Version string `json:"version,omitempty"`
+	// Models are the models this module uses from the dependency, which the chosen version must provide.
+	Models []ModuleComponent `json:"models,omitempty"`
 }
 
 const (
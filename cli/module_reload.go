@@ -2,11 +2,17 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"path"
 	"path/filepath"
 	"strings"
+	"sync"
+	"text/tabwriter"
 
+	"github.com/pkg/errors"
 	"github.com/urfave/cli/v2"
 	apppb "go.viam.com/api/app/v1"
+	"go.viam.com/utils"
 
 	rdkConfig "go.viam.com/rdk/config"
 	rutils "go.viam.com/rdk/utils"
@@ -17,11 +23,40 @@ import (
 // Using maps directly also saves a lot of high-maintenance ser/des work.
 type ModuleMap map[string]any
 
-// configureModule is the configuration step of module reloading. Returns (needsRestart, error).
-func configureModule(c *cli.Context, vc *viamClient, manifest *moduleManifest, part *apppb.RobotPart) (bool, error) {
+// configureModule is the configuration step of module reloading. It also pins the module's dependencies to the
+// versions in pins, from checkModuleRequirements. Returns (needsRestart, error).
+func configureModule(
+	c *cli.Context,
+	vc *viamClient,
+	manifest *moduleManifest,
+	part *apppb.RobotPart,
+	pins map[string]string,
+) (bool, error) {
 	if manifest == nil {
 		return false, fmt.Errorf("reconfiguration requires valid manifest json passed to --%s", moduleFlagPath)
 	}
+	return configurePartModules(c, vc, part, func(modules []ModuleMap) ([]ModuleMap, bool, error) {
+		modules, dirty, err := mutateModuleConfig(c, modules, *manifest)
+		if err != nil {
+			return nil, false, err
+		}
+		modules, pinned := pinModuleDependencies(modules, pins)
+		if pinned {
+			debugf(c.App.Writer, c.Bool(debugFlag), "pinning dependency versions")
+			dirty = true
+		}
+		return modules, dirty, nil
+	})
+}
+
+// configurePartModules applies mutate to the modules list in the part's config, writing the config back if
+// mutate changed it. Returns (needsRestart, error).
+func configurePartModules(
+	c *cli.Context,
+	vc *viamClient,
+	part *apppb.RobotPart,
+	mutate func([]ModuleMap) ([]ModuleMap, bool, error),
+) (bool, error) {
 	partMap := part.RobotConfig.AsMap()
 	if _, ok := partMap["modules"]; !ok {
 		partMap["modules"] = make([]any, 0)
@@ -34,7 +69,7 @@ func configureModule(c *cli.Context, vc *viamClient, manifest *moduleManifest, p
 		return false, err
 	}
 
-	modules, dirty, err := mutateModuleConfig(c, modules, *manifest)
+	modules, dirty, err := mutate(modules)
 	if err != nil {
 		return false, err
 	}
@@ -108,3 +143,282 @@ func mutateModuleConfig(c *cli.Context, modules []ModuleMap, manifest moduleMani
 	}
 	return modules, dirty, nil
 }
+
+// isFleetReload returns whether the reload targets the parts selected by the fleet flags rather than a single part.
+func isFleetReload(c *cli.Context) bool {
+	return c.IsSet(locationFlag) || c.IsSet(moduleReloadFlagFragment) || c.IsSet(moduleReloadFlagPartFilter)
+}
+
+// partMatchesFleetFilter returns whether a part of the named machine is selected by a fragment ID and a
+// glob matched against either the part name or "machine/part". Empty values select everything.
+func partMatchesFleetFilter(machineName string, part *apppb.RobotPart, fragmentID, filter string) (bool, error) {
+	if filter != "" {
+		matchesPart, err := path.Match(filter, part.Name)
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid --%s", moduleReloadFlagPartFilter)
+		}
+		matchesMachine, err := path.Match(filter, machineName+"/"+part.Name)
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid --%s", moduleReloadFlagPartFilter)
+		}
+		if !matchesPart && !matchesMachine {
+			return false, nil
+		}
+	}
+	if fragmentID == "" {
+		return true, nil
+	}
+	fragments, ok := part.RobotConfig.AsMap()["fragments"].([]any)
+	if !ok {
+		return false, nil
+	}
+	for _, fragment := range fragments {
+		// fragments are listed either by ID alone, or as objects with an ID and overwrites
+		switch f := fragment.(type) {
+		case string:
+			if f == fragmentID {
+				return true, nil
+			}
+		case map[string]any:
+			if f["id"] == fragmentID {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// fleetPart is a machine part selected for a fleet reload.
+type fleetPart struct {
+	machineName string
+	part        *apppb.RobotPart
+}
+
+// selectFleetParts returns the parts in the location that match the fleet flags.
+func selectFleetParts(c *cli.Context, vc *viamClient) ([]fleetPart, error) {
+	robots, err := vc.listRobots(c.String(organizationFlag), c.String(locationFlag))
+	if err != nil {
+		return nil, err
+	}
+	var selected []fleetPart
+	for _, robot := range robots {
+		resp, err := vc.client.GetRobotParts(c.Context, &apppb.GetRobotPartsRequest{RobotId: robot.Id})
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not get parts of machine %q", robot.Name)
+		}
+		for _, part := range resp.Parts {
+			ok, err := partMatchesFleetFilter(robot.Name, part, c.String(moduleReloadFlagFragment), c.String(moduleReloadFlagPartFilter))
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				selected = append(selected, fleetPart{machineName: robot.Name, part: part})
+			}
+		}
+	}
+	return selected, nil
+}
+
+// fleetReloadResult is the outcome of reloading the module on one part of a fleet.
+type fleetReloadResult struct {
+	fleetPart
+	// configured is true when the part's config was written, and so can be rolled back.
+	configured bool
+	rolledBack bool
+	err        error
+}
+
+func (r fleetReloadResult) status() string {
+	switch {
+	case r.rolledBack:
+		return "rolled back"
+	case r.err != nil:
+		return "failed"
+	case r.configured:
+		return "configured"
+	default:
+		return "restarted"
+	}
+}
+
+// reloadModuleFleet pins the module to a version uploaded to the registry, and restarts it, on every selected part
+// in parallel. The parts are remote, so they can't run a local build the way a single part on this machine can.
+// With --rollback, parts whose config was changed are restored to their previous config if any part fails.
+func reloadModuleFleet(c *cli.Context, vc *viamClient) error {
+	if c.IsSet(partFlag) {
+		return fmt.Errorf("--%s cannot be used with --%s, --%s or --%s",
+			partFlag, locationFlag, moduleReloadFlagFragment, moduleReloadFlagPartFilter)
+	}
+	if c.Bool(moduleReloadFlagWatch) {
+		return fmt.Errorf("--%s can only be used to reload on a single part", moduleReloadFlagWatch)
+	}
+	if err := vc.ensureLoggedIn(); err != nil {
+		return err
+	}
+	manifest, err := loadManifestOrNil(c.String(moduleFlagPath))
+	if err != nil {
+		return err
+	}
+	var version *apppb.VersionHistory
+	if !c.Bool(moduleBuildRestartOnly) {
+		if manifest == nil {
+			return fmt.Errorf(`manifest not found at "%s". manifest required to reload on many parts`, c.String(moduleFlagPath))
+		}
+		if version, err = vc.uploadedModuleVersion(manifest, c.String(moduleReloadFlagVersion)); err != nil {
+			return err
+		}
+	}
+	parts, err := selectFleetParts(c, vc)
+	if err != nil {
+		return err
+	}
+	if len(parts) == 0 {
+		return errors.New("no machine parts match the given location, fragment and filter")
+	}
+	infof(c.App.Writer, "Reloading module on %d machine parts", len(parts))
+
+	results := make([]fleetReloadResult, len(parts))
+	parallel := c.Int(moduleReloadFlagParallel)
+	if parallel < 1 {
+		parallel = 1
+	}
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, part := range parts {
+		i, part := i, part
+		wg.Add(1)
+		sem <- struct{}{}
+		utils.PanicCapturingGo(func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = reloadFleetPart(c, vc, manifest, version, part)
+		})
+	}
+	wg.Wait()
+
+	var failed int
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+		}
+	}
+	if failed > 0 && c.Bool(moduleReloadFlagRollback) {
+		for i, result := range results {
+			if !result.configured {
+				continue
+			}
+			if err := vc.updateRobotPart(result.part, result.part.RobotConfig.AsMap()); err != nil {
+				warningf(c.App.Writer, "could not roll back %s/%s: %v", result.machineName, result.part.Name, err)
+				continue
+			}
+			results[i].rolledBack = true
+		}
+	}
+	printFleetReloadResults(c.App.Writer, results)
+	if failed > 0 {
+		return fmt.Errorf("reload failed on %d of %d machine parts", failed, len(results))
+	}
+	return nil
+}
+
+// uploadedModuleVersion returns the registry version of the module which a fleet reload pins each part to.
+func (c *viamClient) uploadedModuleVersion(manifest *moduleManifest, version string) (*apppb.VersionHistory, error) {
+	if version == "" {
+		return nil, fmt.Errorf("reloading on many parts requires --%s, a version of the module uploaded with 'viam module upload'",
+			moduleReloadFlagVersion)
+	}
+	modID, err := parseModuleID(manifest.ModuleID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.getModule(modID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get %s from the registry", manifest.ModuleID)
+	}
+	for _, v := range resp.GetModule().GetVersions() {
+		if v.Version == version {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("version %s of %s has not been uploaded to the registry. Upload it with 'viam module upload' first",
+		version, manifest.ModuleID)
+}
+
+// reloadFleetPart pins the module to the uploaded version on one part of a fleet reload, or restarts it if the
+// part already runs that version.
+func reloadFleetPart(
+	c *cli.Context,
+	vc *viamClient,
+	manifest *moduleManifest,
+	version *apppb.VersionHistory,
+	part fleetPart,
+) fleetReloadResult {
+	result := fleetReloadResult{fleetPart: part}
+	pins, err := vc.checkModuleRequirements(c.App.Writer, manifest, part.part)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	needsRestart := true
+	if !c.Bool(moduleBuildRestartOnly) {
+		if platform := partInfo(part.part, "platform"); platform != "" && !hasUploadForPlatform(version, platform) {
+			result.err = fmt.Errorf("version %s has not been uploaded for %s", version.Version, platform)
+			return result
+		}
+		needsRestart, result.err = configurePartModules(c, vc, part.part, func(modules []ModuleMap) ([]ModuleMap, bool, error) {
+			modules, dirty := pinFleetModule(modules, manifest.ModuleID, version.Version)
+			modules, pinned := pinModuleDependencies(modules, pins)
+			return modules, dirty || pinned, nil
+		})
+		if result.err != nil {
+			return result
+		}
+		result.configured = !needsRestart
+	}
+	if needsRestart {
+		result.err = restartModule(c, vc, part.part, manifest)
+	}
+	return result
+}
+
+// pinFleetModule pins the module to a registry version in the modules list, adding it if it isn't configured
+// yet. A copy of the module a single part reload made local is turned back into the registry module, since a
+// local executable path means nothing on other machines. Returns the new list and whether it changed.
+func pinFleetModule(modules []ModuleMap, moduleID, version string) ([]ModuleMap, bool) {
+	var dirty bool
+	localName := localizeModuleID(moduleID)
+	for _, mod := range modules {
+		if mod["module_id"] != moduleID && mod["name"] != localName {
+			continue
+		}
+		if getMapString(mod, "type") != string(rdkConfig.ModuleTypeRegistry) || mod["module_id"] != moduleID {
+			dirty = true
+			mod["type"] = string(rdkConfig.ModuleTypeRegistry)
+			mod["module_id"] = moduleID
+			delete(mod, "executable_path")
+		}
+		break
+	}
+	modules, pinned := pinModuleDependencies(modules, map[string]string{moduleID: version})
+	return modules, dirty || pinned
+}
+
+func printFleetReloadResults(w io.Writer, results []fleetReloadResult) {
+	// table format rules:
+	// minwidth, tabwidth, padding int, padchar byte, flags uint
+	tw := tabwriter.NewWriter(w, 5, 4, 1, ' ', 0)
+	tableFormat := "%s\t%s\t%s\t%s\n"
+	fmt.Fprintf(tw, tableFormat, "MACHINE", "PART", "STATUS", "ERROR")
+	for _, result := range results {
+		var errMsg string
+		if result.err != nil {
+			errMsg = result.err.Error()
+		}
+		fmt.Fprintf(tw, tableFormat, result.machineName, result.part.Name, result.status(), errMsg)
+	}
+	// the table is not printed to stdout until the tabwriter is flushed
+	//nolint: errcheck,gosec
+	tw.Flush()
+}
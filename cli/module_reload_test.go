@@ -4,8 +4,12 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/pkg/errors"
 	v1 "go.viam.com/api/app/build/v1"
 	apppb "go.viam.com/api/app/v1"
 	"go.viam.com/test"
@@ -133,3 +137,224 @@ func TestMutateModuleConfig(t *testing.T) {
 	mutateModuleConfig(c, modules, manifest)
 	test.That(t, modules[0]["name"], test.ShouldEqual, localizeModuleID(manifest.ModuleID))
 }
+
+func TestModuleWatchIgnorer(t *testing.T) {
+	root := t.TempDir()
+	ignored := moduleWatchIgnorer(root, &moduleManifest{
+		JSONManifest: rdkConfig.JSONManifest{Entrypoint: "bin/module"},
+	})
+	test.That(t, ignored(root), test.ShouldBeFalse)
+	test.That(t, ignored(filepath.Join(root, "main.go")), test.ShouldBeFalse)
+	test.That(t, ignored(filepath.Join(root, "src", "model.go")), test.ShouldBeFalse)
+	test.That(t, ignored(filepath.Join(root, "bin")), test.ShouldBeFalse)
+	test.That(t, ignored(filepath.Join(root, "bin", "module")), test.ShouldBeTrue)
+	test.That(t, ignored(filepath.Join(root, "module.tar.gz")), test.ShouldBeTrue)
+	test.That(t, ignored(filepath.Join(root, ".git", "index")), test.ShouldBeTrue)
+	test.That(t, ignored(filepath.Join(root, ".main.go.swp")), test.ShouldBeTrue)
+	test.That(t, ignored(filepath.Join(root, "src", "__pycache__", "model.pyc")), test.ShouldBeTrue)
+}
+
+func TestWatchModuleSource(t *testing.T) {
+	root := t.TempDir()
+	test.That(t, os.Mkdir(filepath.Join(root, "src"), 0o700), test.ShouldBeNil)
+	ctx, cancel := context.WithCancel(context.Background())
+	reloads := make(chan struct{}, 10)
+	done := make(chan error)
+	go func() {
+		done <- watchModuleSource(ctx, &testWriter{}, root, nil, func() { reloads <- struct{}{} })
+	}()
+	// give the watcher a moment to start before changing anything
+	time.Sleep(100 * time.Millisecond)
+
+	// a burst of changes results in a single reload
+	for i := 0; i < 3; i++ {
+		test.That(t, os.WriteFile(filepath.Join(root, "src", "model.go"), []byte("package src"), 0o600), test.ShouldBeNil)
+	}
+	select {
+	case <-reloads:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reload")
+	}
+	select {
+	case <-reloads:
+		t.Fatal("expected a single reload")
+	case <-time.After(2 * moduleWatchDebounce):
+	}
+
+	// changes to ignored files don't reload
+	test.That(t, os.WriteFile(filepath.Join(root, ".model.go.swp"), nil, 0o600), test.ShouldBeNil)
+	select {
+	case <-reloads:
+		t.Fatal("expected no reload")
+	case <-time.After(2 * moduleWatchDebounce):
+	}
+
+	cancel()
+	test.That(t, <-done, test.ShouldBeNil)
+}
+
+func TestPartMatchesFleetFilter(t *testing.T) {
+	partWithFragments := func(name string, fragments ...any) *apppb.RobotPart {
+		conf, err := structpb.NewStruct(map[string]any{"fragments": fragments})
+		test.That(t, err, test.ShouldBeNil)
+		return &apppb.RobotPart{Name: name, RobotConfig: conf}
+	}
+
+	for _, tc := range []struct {
+		part     *apppb.RobotPart
+		fragment string
+		filter   string
+		matches  bool
+	}{
+		{partWithFragments("rover-main"), "", "", true},
+		{partWithFragments("rover-main"), "", "rover-*", true},
+		{partWithFragments("rover-main"), "", "fleet-a/*", true},
+		{partWithFragments("rover-main"), "", "fleet-b/*", false},
+		{partWithFragments("rover-main", "frag1"), "frag1", "", true},
+		{partWithFragments("rover-main", map[string]any{"id": "frag1"}), "frag1", "", true},
+		{partWithFragments("rover-main", "frag2"), "frag1", "", false},
+		{partWithFragments("rover-main"), "frag1", "", false},
+		{partWithFragments("rover-main", "frag1"), "frag1", "arm-*", false},
+	} {
+		matches, err := partMatchesFleetFilter("fleet-a", tc.part, tc.fragment, tc.filter)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, matches, test.ShouldEqual, tc.matches)
+	}
+
+	_, err := partMatchesFleetFilter("fleet-a", partWithFragments("rover-main"), "", "[")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestFleetReload(t *testing.T) {
+	manifestPath := createTestManifest(t, "")
+	partConfig := func(fragments ...any) *structpb.Struct {
+		conf, err := structpb.NewStruct(map[string]any{"modules": []any{}, "fragments": fragments})
+		test.That(t, err, test.ShouldBeNil)
+		return conf
+	}
+	parts := map[string][]*apppb.RobotPart{
+		"r1": {
+			{Id: "good", Name: "good", Robot: "r1", RobotConfig: partConfig("frag1")},
+			{Id: "skipped", Name: "skipped", Robot: "r1", RobotConfig: partConfig()},
+		},
+		"r2": {
+			{Id: "bad", Name: "bad", Robot: "r2", RobotConfig: partConfig(map[string]any{"id": "frag1"})},
+		},
+	}
+
+	var mu sync.Mutex
+	updates := map[string][]*structpb.Struct{}
+	asc := &inject.AppServiceClient{
+		ListOrganizationsFunc: func(ctx context.Context, in *apppb.ListOrganizationsRequest,
+			opts ...grpc.CallOption,
+		) (*apppb.ListOrganizationsResponse, error) {
+			return &apppb.ListOrganizationsResponse{Organizations: []*apppb.Organization{{Name: "jedi", Id: "123"}}}, nil
+		},
+		ListLocationsFunc: func(ctx context.Context, in *apppb.ListLocationsRequest,
+			opts ...grpc.CallOption,
+		) (*apppb.ListLocationsResponse, error) {
+			return &apppb.ListLocationsResponse{Locations: []*apppb.Location{{Name: "naboo", Id: "loc"}}}, nil
+		},
+		ListRobotsFunc: func(ctx context.Context, in *apppb.ListRobotsRequest,
+			opts ...grpc.CallOption,
+		) (*apppb.ListRobotsResponse, error) {
+			return &apppb.ListRobotsResponse{Robots: []*apppb.Robot{{Id: "r1", Name: "r1"}, {Id: "r2", Name: "r2"}}}, nil
+		},
+		GetRobotPartsFunc: func(ctx context.Context, in *apppb.GetRobotPartsRequest,
+			opts ...grpc.CallOption,
+		) (*apppb.GetRobotPartsResponse, error) {
+			return &apppb.GetRobotPartsResponse{Parts: parts[in.RobotId]}, nil
+		},
+		GetModuleFunc: func(ctx context.Context, in *apppb.GetModuleRequest,
+			opts ...grpc.CallOption,
+		) (*apppb.GetModuleResponse, error) {
+			return &apppb.GetModuleResponse{Module: &apppb.Module{
+				ModuleId: "test:test",
+				Versions: []*apppb.VersionHistory{{Version: "1.0.0"}},
+			}}, nil
+		},
+		UpdateRobotPartFunc: func(ctx context.Context, req *apppb.UpdateRobotPartRequest,
+			opts ...grpc.CallOption,
+		) (*apppb.UpdateRobotPartResponse, error) {
+			if req.Id == "bad" {
+				return nil, errors.New("part is offline")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			updates[req.Id] = append(updates[req.Id], req.RobotConfig)
+			return &apppb.UpdateRobotPartResponse{Part: &apppb.RobotPart{}}, nil
+		},
+	}
+	flags := map[string]any{
+		moduleBuildFlagPath:      manifestPath,
+		moduleBuildFlagNoBuild:   true,
+		moduleReloadFlagParallel: 4,
+		locationFlag:             "",
+		moduleReloadFlagFragment: "",
+		moduleReloadFlagRollback: false,
+		moduleReloadFlagVersion:  "",
+	}
+
+	// the parts are remote, so they need a version uploaded to the registry
+	cCtx, vc, _, _ := setup(asc, nil, nil, nil, flags, "token", "--location=naboo", "--fragment=frag1")
+	err := reloadModuleAction(cCtx, vc)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "--version")
+	cCtx, vc, _, _ = setup(asc, nil, nil, nil, flags, "token", "--location=naboo", "--fragment=frag1", "--version=2.0.0")
+	err = reloadModuleAction(cCtx, vc)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "has not been uploaded")
+	test.That(t, updates, test.ShouldBeEmpty)
+
+	cCtx, vc, out, _ := setup(asc, nil, nil, nil, flags, "token", "--location=naboo", "--fragment=frag1", "--version=1.0.0")
+	err = reloadModuleAction(cCtx, vc)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "reload failed on 1 of 2 machine parts")
+	test.That(t, updates["good"], test.ShouldHaveLength, 1)
+	test.That(t, updates["good"][0].AsMap()["modules"], test.ShouldResemble, []any{map[string]any{
+		"name":      "test_test",
+		"module_id": "test:test",
+		"type":      string(rdkConfig.ModuleTypeRegistry),
+		"version":   "1.0.0",
+	}})
+	test.That(t, updates, test.ShouldNotContainKey, "skipped")
+	summary := strings.Join(out.messages, "")
+	test.That(t, summary, test.ShouldContainSubstring, "configured")
+	test.That(t, summary, test.ShouldContainSubstring, "part is offline")
+
+	// with rollback, the part that was changed gets its previous config back
+	updates = map[string][]*structpb.Struct{}
+	cCtx, vc, out, _ = setup(asc, nil, nil, nil, flags, "token", "--location=naboo", "--fragment=frag1", "--version=1.0.0", "--rollback")
+	err = reloadModuleAction(cCtx, vc)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, updates["good"], test.ShouldHaveLength, 2)
+	test.That(t, updates["good"][1].AsMap(), test.ShouldResemble, parts["r1"][0].RobotConfig.AsMap())
+	test.That(t, strings.Join(out.messages, ""), test.ShouldContainSubstring, "rolled back")
+
+	// a single part can't be combined with selecting many
+	flags[partFlag] = ""
+	cCtx, vc, _, _ = setup(asc, nil, nil, nil, flags, "token", "--location=naboo", "--part=good", "--version=1.0.0")
+	test.That(t, reloadModuleAction(cCtx, vc), test.ShouldNotBeNil)
+}
+
+func TestPinFleetModule(t *testing.T) {
+	// a module a single part reload made local is turned back into the registry module
+	modules := []ModuleMap{{
+		"name":            localizeModuleID("viam-labs:test-module"),
+		"executable_path": "/home/dev/bin/mod",
+		"type":            string(rdkConfig.ModuleTypeLocal),
+	}}
+	modules, dirty := pinFleetModule(modules, "viam-labs:test-module", "1.2.3")
+	test.That(t, dirty, test.ShouldBeTrue)
+	test.That(t, modules, test.ShouldHaveLength, 1)
+	test.That(t, modules[0], test.ShouldResemble, ModuleMap{
+		"name":      localizeModuleID("viam-labs:test-module"),
+		"module_id": "viam-labs:test-module",
+		"type":      string(rdkConfig.ModuleTypeRegistry),
+		"version":   "1.2.3",
+	})
+
+	// already pinned
+	_, dirty = pinFleetModule(modules, "viam-labs:test-module", "1.2.3")
+	test.That(t, dirty, test.ShouldBeFalse)
+}
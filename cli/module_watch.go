@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// moduleWatchDebounce is how long the module source must be quiet before a change triggers a reload,
+// so that saving many files at once (or a checkout) results in a single rebuild.
+const moduleWatchDebounce = 500 * time.Millisecond
+
+// moduleWatchIgnoredDirs are directories that are never part of a module's source but are
+// often written to by its setup or build steps.
+var moduleWatchIgnoredDirs = map[string]bool{
+	"node_modules": true,
+	"__pycache__":  true,
+	"venv":         true,
+}
+
+// moduleWatchIgnorer returns a function reporting whether a changed path under root should be ignored.
+// Hidden files and directories are ignored, as are the build outputs named in the manifest, since
+// otherwise every build would trigger the next one.
+func moduleWatchIgnorer(root string, manifest *moduleManifest) func(string) bool {
+	outputs := map[string]bool{}
+	if manifest != nil {
+		if manifest.Entrypoint != "" {
+			outputs[filepath.Clean(manifest.Entrypoint)] = true
+		}
+		buildPath := defaultBuildInfo.Path
+		if manifest.Build != nil && manifest.Build.Path != "" {
+			buildPath = manifest.Build.Path
+		}
+		outputs[filepath.Clean(buildPath)] = true
+	}
+	return func(name string) bool {
+		rel, err := filepath.Rel(root, name)
+		if err != nil || rel == "." {
+			return false
+		}
+		if outputs[rel] {
+			return true
+		}
+		for _, part := range strings.Split(rel, string(filepath.Separator)) {
+			if (strings.HasPrefix(part, ".") && part != "..") || moduleWatchIgnoredDirs[part] {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// watchModuleSource calls reload whenever the module source tree under root changes, until ctx is done.
+// Changes are debounced by moduleWatchDebounce and reload is never called concurrently; changes made
+// during a reload trigger another one once it finishes.
+func watchModuleSource(ctx context.Context, w io.Writer, root string, manifest *moduleManifest, reload func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer watcher.Close()
+
+	ignored := moduleWatchIgnorer(root, manifest)
+	// fsnotify is not recursive, so every directory in the tree is watched, including new ones
+	addTree := func(dir string) error {
+		return filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				return nil
+			}
+			if ignored(name) {
+				return filepath.SkipDir
+			}
+			return watcher.Add(name)
+		})
+	}
+	if err := addTree(root); err != nil {
+		return err
+	}
+	infof(w, "Watching %q for changes. Press Ctrl-C to stop", root)
+
+	var pending <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod || ignored(event.Name) {
+				continue
+			}
+			if event.Op&fsnotify.Create == fsnotify.Create {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := addTree(event.Name); err != nil {
+						warningf(w, "Failed to watch %q: %v", event.Name, err)
+					}
+				}
+			}
+			pending = time.After(moduleWatchDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			warningf(w, "Error watching module source: %v", err)
+		case <-pending:
+			pending = nil
+			infof(w, "Module source changed, reloading")
+			reload()
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+
+	"go.viam.com/rdk/spatialmath"
+)
+
+func orientationTypesUsage() string {
+	types := make([]string, 0, len(spatialmath.OrientationTypes))
+	for _, oType := range spatialmath.OrientationTypes {
+		types = append(types, string(oType))
+	}
+	return strings.Join(types, ", ")
+}
+
+// OrientationConvertAction is the corresponding action for 'orientation convert'.
+func OrientationConvertAction(c *cli.Context) error {
+	value := map[string]any{}
+	if err := json.Unmarshal([]byte(c.String(orientationFlagValue)), &value); err != nil {
+		return errors.Wrapf(err, "could not parse --%s as a JSON object", orientationFlagValue)
+	}
+	from := &spatialmath.OrientationConfig{
+		Type:  spatialmath.OrientationType(c.String(orientationFlagFrom)),
+		Value: value,
+	}
+	o, err := from.ParseConfigStrict()
+	if err != nil {
+		return errors.Wrapf(err, "invalid %s orientation", from.Type)
+	}
+
+	toTypes := spatialmath.OrientationTypes
+	if to := c.String(orientationFlagTo); to != "" {
+		toTypes = []spatialmath.OrientationType{spatialmath.OrientationType(to)}
+	}
+	for _, oType := range toTypes {
+		converted, err := spatialmath.ConvertOrientation(o, oType)
+		if err != nil {
+			return err
+		}
+		// Print each one as it would appear in a frame config, so it can be copied straight in.
+		out, err := json.Marshal(converted)
+		if err != nil {
+			return err
+		}
+		printf(c.App.Writer, "%s", out)
+	}
+	return nil
+}
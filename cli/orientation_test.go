@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestOrientationConvertAction(t *testing.T) {
+	c := newTestContext(t, map[string]any{
+		orientationFlagFrom:  "ov_degrees",
+		orientationFlagValue: `{"x": 0, "y": 0, "z": 1, "th": 90}`,
+		orientationFlagTo:    "euler_angles",
+	})
+	test.That(t, OrientationConvertAction(c), test.ShouldBeNil)
+	out := c.App.Writer.(*testWriter)
+	test.That(t, out.messages, test.ShouldHaveLength, 1)
+	test.That(t, out.messages[0], test.ShouldContainSubstring, `"type":"euler_angles"`)
+	test.That(t, out.messages[0], test.ShouldContainSubstring, `"yaw":1.57`)
+
+	// with no --to, every representation is printed
+	c = newTestContext(t, map[string]any{
+		orientationFlagFrom:  "ov_degrees",
+		orientationFlagValue: `{"x": 0, "y": 0, "z": 1, "th": 90}`,
+	})
+	test.That(t, OrientationConvertAction(c), test.ShouldBeNil)
+	test.That(t, c.App.Writer.(*testWriter).messages, test.ShouldHaveLength, 5)
+
+	// misnamed fields are caught
+	c = newTestContext(t, map[string]any{
+		orientationFlagFrom:  "ov_degrees",
+		orientationFlagValue: `{"x": 0, "y": 0, "z": 1, "theta": 90}`,
+	})
+	err := OrientationConvertAction(c)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "invalid ov_degrees orientation")
+}
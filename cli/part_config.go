@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/urfave/cli/v2"
+)
+
+// partConfigDiffContext is the number of unchanged lines shown around each change in a config diff.
+const partConfigDiffContext = 3
+
+// RobotsPartConfigDiffAction is the corresponding Action for 'machines part config diff'.
+func RobotsPartConfigDiffAction(c *cli.Context) error {
+	client, err := newViamClient(c)
+	if err != nil {
+		return err
+	}
+	return client.robotsPartConfigAction(c, false)
+}
+
+// RobotsPartConfigApplyAction is the corresponding Action for 'machines part config apply'.
+func RobotsPartConfigApplyAction(c *cli.Context) error {
+	client, err := newViamClient(c)
+	if err != nil {
+		return err
+	}
+	return client.robotsPartConfigAction(c, true)
+}
+
+// robotsPartConfigAction prints the difference between a part's config in the cloud and a local file,
+// and if apply is set, replaces the part's config with the file unless it's a dry run.
+func (c *viamClient) robotsPartConfigAction(cCtx *cli.Context, apply bool) error {
+	desired, err := readPartConfigFile(cCtx.String(partConfigFlagFile))
+	if err != nil {
+		return err
+	}
+	part, err := c.robotPart(cCtx.String(organizationFlag), cCtx.String(locationFlag),
+		cCtx.String(machineFlag), cCtx.String(partFlag))
+	if err != nil {
+		return errors.Wrap(err, "could not get machine part")
+	}
+	current := map[string]any{}
+	if part.RobotConfig != nil {
+		current = part.RobotConfig.AsMap()
+	}
+
+	diff, differs, err := partConfigDiff(current, desired)
+	if err != nil {
+		return err
+	}
+	if !differs {
+		printf(cCtx.App.Writer, "No differences between part %q and %q", part.Name, cCtx.String(partConfigFlagFile))
+		return nil
+	}
+	printf(cCtx.App.Writer, "--- %s (current)\n+++ %s\n%s", part.Name, cCtx.String(partConfigFlagFile), diff)
+
+	if !apply {
+		return nil
+	}
+	if cCtx.Bool(partConfigFlagDryRun) {
+		infof(cCtx.App.Writer, "Dry run, the config of part %q was not changed", part.Name)
+		return nil
+	}
+	if err := c.updateRobotPart(part, desired); err != nil {
+		return errors.Wrap(err, "could not update machine part config")
+	}
+	infof(cCtx.App.Writer, "Updated the config of part %q", part.Name)
+	return nil
+}
+
+// readPartConfigFile reads a machine part config from a local JSON file.
+func readPartConfigFile(path string) (map[string]any, error) {
+	//nolint:gosec
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var conf map[string]any
+	if err := json.Unmarshal(contents, &conf); err != nil {
+		return nil, errors.Wrapf(err, "%q is not a valid JSON config", path)
+	}
+	if conf == nil {
+		return nil, errors.Errorf("%q is not a valid JSON config", path)
+	}
+	return conf, nil
+}
+
+// partConfigDiff returns a line diff of two configs and whether they differ. Both are rendered as indented
+// JSON with sorted keys first, so the diff is of their contents only, not of key order or formatting.
+func partConfigDiff(current, desired map[string]any) (string, bool, error) {
+	currentJSON, err := json.MarshalIndent(current, "", "  ")
+	if err != nil {
+		return "", false, err
+	}
+	desiredJSON, err := json.MarshalIndent(desired, "", "  ")
+	if err != nil {
+		return "", false, err
+	}
+	if string(currentJSON) == string(desiredJSON) {
+		return "", false, nil
+	}
+
+	dmp := diffmatchpatch.New()
+	currentChars, desiredChars, lines := dmp.DiffLinesToChars(string(currentJSON)+"\n", string(desiredJSON)+"\n")
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(currentChars, desiredChars, false), lines)
+
+	type diffLine struct {
+		prefix string
+		text   string
+	}
+	var diffLines []diffLine
+	for _, d := range diffs {
+		prefix := " "
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			prefix = "-"
+		case diffmatchpatch.DiffInsert:
+			prefix = "+"
+		case diffmatchpatch.DiffEqual:
+		}
+		for _, line := range strings.SplitAfter(d.Text, "\n") {
+			if line != "" {
+				diffLines = append(diffLines, diffLine{prefix, line})
+			}
+		}
+	}
+
+	// only unchanged lines near a change are shown, and runs of hidden lines are marked
+	show := make([]bool, len(diffLines))
+	for i, line := range diffLines {
+		if line.prefix == " " {
+			continue
+		}
+		for j := i - partConfigDiffContext; j <= i+partConfigDiffContext; j++ {
+			if j >= 0 && j < len(diffLines) {
+				show[j] = true
+			}
+		}
+	}
+	var out strings.Builder
+	for i, line := range diffLines {
+		if !show[i] {
+			if i == 0 || show[i-1] {
+				out.WriteString("...\n")
+			}
+			continue
+		}
+		fmt.Fprintf(&out, "%s %s", line.prefix, line.text)
+	}
+	return strings.TrimSuffix(out.String(), "\n"), true, nil
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	apppb "go.viam.com/api/app/v1"
+	"go.viam.com/test"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestPartConfigDiff(t *testing.T) {
+	current := map[string]any{
+		"components": []any{
+			map[string]any{"name": "arm1", "type": "arm", "model": "fake"},
+		},
+		"network": map[string]any{"bind_address": ":8080"},
+	}
+
+	// key order and formatting don't count as differences
+	_, differs, err := partConfigDiff(current, map[string]any{
+		"network":    map[string]any{"bind_address": ":8080"},
+		"components": []any{map[string]any{"type": "arm", "model": "fake", "name": "arm1"}},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, differs, test.ShouldBeFalse)
+
+	diff, differs, err := partConfigDiff(current, map[string]any{
+		"components": []any{
+			map[string]any{"name": "arm1", "type": "arm", "model": "ur5e"},
+		},
+		"network": map[string]any{"bind_address": ":8080"},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, differs, test.ShouldBeTrue)
+	test.That(t, diff, test.ShouldContainSubstring, `-       "model": "fake",`)
+	test.That(t, diff, test.ShouldContainSubstring, `+       "model": "ur5e",`)
+	test.That(t, diff, test.ShouldContainSubstring, `        "name": "arm1",`)
+}
+
+func TestRobotsPartConfigAction(t *testing.T) {
+	conf, err := structpb.NewStruct(map[string]any{"components": []any{}})
+	test.That(t, err, test.ShouldBeNil)
+	var updates []*apppb.UpdateRobotPartRequest
+	asc := &inject.AppServiceClient{
+		ListOrganizationsFunc: func(ctx context.Context, in *apppb.ListOrganizationsRequest,
+			opts ...grpc.CallOption,
+		) (*apppb.ListOrganizationsResponse, error) {
+			return &apppb.ListOrganizationsResponse{Organizations: []*apppb.Organization{{Name: "jedi", Id: "123"}}}, nil
+		},
+		ListLocationsFunc: func(ctx context.Context, in *apppb.ListLocationsRequest,
+			opts ...grpc.CallOption,
+		) (*apppb.ListLocationsResponse, error) {
+			return &apppb.ListLocationsResponse{Locations: []*apppb.Location{{Name: "naboo"}}}, nil
+		},
+		ListRobotsFunc: func(ctx context.Context, in *apppb.ListRobotsRequest,
+			opts ...grpc.CallOption,
+		) (*apppb.ListRobotsResponse, error) {
+			return &apppb.ListRobotsResponse{Robots: []*apppb.Robot{{Name: "r2d2", Id: "r2d2"}}}, nil
+		},
+		GetRobotPartsFunc: func(ctx context.Context, in *apppb.GetRobotPartsRequest,
+			opts ...grpc.CallOption,
+		) (*apppb.GetRobotPartsResponse, error) {
+			return &apppb.GetRobotPartsResponse{Parts: []*apppb.RobotPart{{Name: "main", Id: "main", RobotConfig: conf}}}, nil
+		},
+		UpdateRobotPartFunc: func(ctx context.Context, in *apppb.UpdateRobotPartRequest,
+			opts ...grpc.CallOption,
+		) (*apppb.UpdateRobotPartResponse, error) {
+			updates = append(updates, in)
+			return &apppb.UpdateRobotPartResponse{Part: &apppb.RobotPart{}}, nil
+		},
+	}
+
+	confPath := filepath.Join(t.TempDir(), "config.json")
+	test.That(t, os.WriteFile(confPath, []byte(`{"components": [{"name": "arm1", "type": "arm", "model": "fake"}]}`), 0o600),
+		test.ShouldBeNil)
+	flags := map[string]any{machineFlag: "r2d2", partFlag: "main", partConfigFlagFile: confPath, partConfigFlagDryRun: false}
+
+	cCtx, ac, out, _ := setup(asc, nil, nil, nil, flags, "token")
+	test.That(t, ac.robotsPartConfigAction(cCtx, false), test.ShouldBeNil)
+	test.That(t, strings.Join(out.messages, ""), test.ShouldContainSubstring, `+       "name": "arm1",`)
+	test.That(t, updates, test.ShouldBeEmpty)
+
+	// a dry run shows the diff but doesn't update the part
+	cCtx, ac, _, _ = setup(asc, nil, nil, nil, flags, "token", "--dry-run")
+	test.That(t, ac.robotsPartConfigAction(cCtx, true), test.ShouldBeNil)
+	test.That(t, updates, test.ShouldBeEmpty)
+
+	cCtx, ac, _, _ = setup(asc, nil, nil, nil, flags, "token")
+	test.That(t, ac.robotsPartConfigAction(cCtx, true), test.ShouldBeNil)
+	test.That(t, updates, test.ShouldHaveLength, 1)
+	test.That(t, updates[0].Id, test.ShouldEqual, "main")
+	test.That(t, updates[0].RobotConfig.AsMap()["components"], test.ShouldHaveLength, 1)
+}
@@ -0,0 +1,370 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/services/shell"
+)
+
+// syncMarker brackets the output of the commands that syncing runs through the shell service. It's printed
+// as two words joined by printf so that the terminal echoing the command back never contains it.
+const syncMarker = "VIAMSYNC"
+
+// MachinesPartSyncPushAction is the corresponding Action for 'machines part sync push'.
+func MachinesPartSyncPushAction(c *cli.Context) error {
+	return machinesPartSyncAction(c, true)
+}
+
+// MachinesPartSyncPullAction is the corresponding Action for 'machines part sync pull'.
+func MachinesPartSyncPullAction(c *cli.Context) error {
+	return machinesPartSyncAction(c, false)
+}
+
+func machinesPartSyncAction(c *cli.Context, push bool) error {
+	if c.Args().Len() != 2 {
+		if push {
+			return errors.New("expected arguments <local directory> <machine directory>")
+		}
+		return errors.New("expected arguments <machine directory> <local directory>")
+	}
+	client, err := newViamClient(c)
+	if err != nil {
+		return err
+	}
+
+	// Create logger based on presence of debugFlag.
+	logger := logging.FromZapCompatible(zap.NewNop().Sugar())
+	if c.Bool(debugFlag) {
+		logger = logging.NewDebugLogger("cli")
+	}
+
+	shellSvc, closeClient, err := client.connectToShellService(
+		c.String(organizationFlag),
+		c.String(locationFlag),
+		c.String(machineFlag),
+		c.String(partFlag),
+		c.Bool(debugFlag),
+		logger,
+	)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		utils.UncheckedError(closeClient(c.Context))
+	}()
+
+	syncer := &fileSyncer{
+		shellSvc: shellSvc,
+		excludes: c.StringSlice(syncFlagExclude),
+		preserve: c.Bool(cpFlagPreserve),
+		w:        c.App.Writer,
+	}
+	if push {
+		return syncer.push(c.Context, c.Args().Get(0), c.Args().Get(1))
+	}
+	return syncer.pull(c.Context, c.Args().Get(0), c.Args().Get(1))
+}
+
+// A fileSyncer copies a directory tree to or from a machine over its shell service, skipping the files
+// whose contents are already the same on both sides.
+type fileSyncer struct {
+	shellSvc shell.Service
+	excludes []string
+	preserve bool
+	w        io.Writer
+}
+
+// push copies the files under localDir that are missing or different under remoteDir on the machine.
+// remoteDir is created if it doesn't exist, and a relative remoteDir is relative to the home directory.
+func (s *fileSyncer) push(ctx context.Context, localDir, remoteDir string) error {
+	localHashes, err := localFileHashes(localDir, s.excludes)
+	if err != nil {
+		return err
+	}
+	remoteHashes, err := s.remoteFileHashes(ctx, remoteDir, true)
+	if err != nil {
+		return err
+	}
+	changed := changedFiles(localHashes, remoteHashes)
+	if len(changed) == 0 {
+		printf(s.w, "All %d files are up to date", len(localHashes))
+		return nil
+	}
+
+	copier, err := s.shellSvc.CopyFilesToMachine(ctx, shell.CopyFilesSourceTypeMultipleFiles, remoteDir, s.preserve, nil)
+	if err != nil {
+		return err
+	}
+	for i, name := range changed {
+		//nolint:gosec
+		file, err := os.Open(filepath.Join(localDir, filepath.FromSlash(name)))
+		if err != nil {
+			return multierr.Combine(err, copier.Close(ctx))
+		}
+		printf(s.w, "[%d/%d] %s", i+1, len(changed), name)
+		// the copier closes the file
+		if err := copier.Copy(ctx, shell.File{RelativeName: name, Data: file}); err != nil {
+			return multierr.Combine(errors.Wrapf(err, "could not copy %q", name), copier.Close(ctx))
+		}
+	}
+	if err := copier.Close(ctx); err != nil {
+		return err
+	}
+	printf(s.w, "Copied %d files, %d were already up to date", len(changed), len(localHashes)-len(changed))
+	return nil
+}
+
+// pull copies the files under remoteDir on the machine that are missing or different under localDir.
+// localDir is created if it doesn't exist.
+func (s *fileSyncer) pull(ctx context.Context, remoteDir, localDir string) error {
+	if remoteDir == "" {
+		return errors.New("expected a directory on the machine to copy from")
+	}
+	remoteHashes, err := s.remoteFileHashes(ctx, remoteDir, false)
+	if err != nil {
+		return err
+	}
+	if len(remoteHashes) == 0 {
+		return errors.Errorf("%q does not exist on the machine or has no files", remoteDir)
+	}
+	for name := range remoteHashes {
+		if excludedFromSync(name, s.excludes) {
+			delete(remoteHashes, name)
+		}
+	}
+	if err := os.MkdirAll(localDir, 0o750); err != nil {
+		return err
+	}
+	localHashes, err := localFileHashes(localDir, s.excludes)
+	if err != nil {
+		return err
+	}
+	changed := changedFiles(remoteHashes, localHashes)
+	if len(changed) == 0 {
+		printf(s.w, "All %d files are up to date", len(remoteHashes))
+		return nil
+	}
+
+	// files are copied from the machine into a single directory, so they're copied a directory at a time
+	byDir := map[string][]string{}
+	var dirs []string
+	for _, name := range changed {
+		dir := path.Dir(name)
+		if _, ok := byDir[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		byDir[dir] = append(byDir[dir], name)
+	}
+	var copied int
+	for _, dir := range dirs {
+		dst := filepath.Join(localDir, filepath.FromSlash(dir))
+		if err := os.MkdirAll(dst, 0o750); err != nil {
+			return err
+		}
+		factory, err := shell.NewLocalFileCopyFactory(dst, s.preserve, false)
+		if err != nil {
+			return err
+		}
+		remotePaths := make([]string, 0, len(byDir[dir]))
+		for _, name := range byDir[dir] {
+			remotePaths = append(remotePaths, path.Join(remoteDir, name))
+		}
+		progress := &progressFileCopyFactory{FileCopyFactory: factory, onCopy: func(fileName string) {
+			copied++
+			printf(s.w, "[%d/%d] %s", copied, len(changed), path.Join(dir, fileName))
+		}}
+		if err := s.shellSvc.CopyFilesFromMachine(ctx, remotePaths, false, s.preserve, progress, nil); err != nil {
+			return errors.Wrapf(err, "could not copy files in %q", dir)
+		}
+	}
+	printf(s.w, "Copied %d files, %d were already up to date", len(changed), len(remoteHashes)-len(changed))
+	return nil
+}
+
+// remoteFileHashes returns the SHA-256 of every file under dir on the machine, keyed by slash separated path
+// relative to dir, by running sha256sum through the shell service. A dir that doesn't exist has no files,
+// unless create is set, in which case it is made. A relative dir is relative to the home directory when
+// creating it, which is where files are copied to, and relative to the machine's working directory otherwise,
+// which is where files are copied from.
+func (s *fileSyncer) remoteFileHashes(ctx context.Context, dir string, create bool) (map[string]string, error) {
+	cdCommand := "cd " + shellQuotePath(dir)
+	if create {
+		cdCommand = "cd ~ && mkdir -p " + shellQuotePath(dir) + " && " + cdCommand
+	}
+	command := fmt.Sprintf(
+		"printf '%%s_%%s\\n' %[1]s BEGIN; %[2]s 2>/dev/null && find . -type f -exec sha256sum {} + 2>/dev/null; "+
+			"printf '%%s_%%s\\n' %[1]s END; exit\n",
+		syncMarker, cdCommand)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	input, _, output, err := s.shellSvc.Shell(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not start a shell on the machine")
+	}
+	select {
+	case input <- command:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var out strings.Builder
+	for !strings.Contains(out.String(), syncMarker+"_END") {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case o, ok := <-output:
+			if !ok || o.EOF {
+				return parseRemoteFileHashes(out.String())
+			}
+			out.WriteString(o.Output)
+			out.WriteString(o.Error)
+		}
+	}
+	return parseRemoteFileHashes(out.String())
+}
+
+// parseRemoteFileHashes parses the sha256sum output between the sync markers in shell output.
+func parseRemoteFileHashes(out string) (map[string]string, error) {
+	begin := strings.Index(out, syncMarker+"_BEGIN")
+	end := strings.Index(out, syncMarker+"_END")
+	if begin == -1 || end == -1 || end < begin {
+		return nil, errors.New("could not list the files on the machine, does it have a shell with sha256sum?")
+	}
+	hashes := map[string]string{}
+	for _, line := range strings.Split(out[begin+len(syncMarker+"_BEGIN"):end], "\n") {
+		line = strings.TrimRight(line, "\r")
+		// sha256sum marks lines whose names it had to escape with a leading \, which leaves the
+		// hash the wrong length. Those files are left out, so they're always copied.
+		hash, name, ok := strings.Cut(line, "  ")
+		if !ok || len(hash) != sha256.Size*2 {
+			continue
+		}
+		hashes[strings.TrimPrefix(name, "./")] = hash
+	}
+	return hashes, nil
+}
+
+// localFileHashes returns the SHA-256 of every file under dir that isn't excluded, keyed by slash
+// separated path relative to dir.
+func localFileHashes(dir string, excludes []string) (map[string]string, error) {
+	hashes := map[string]string{}
+	err := filepath.WalkDir(dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel != "." && excludedFromSync(rel, excludes) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		//nolint:gosec
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer utils.UncheckedErrorFunc(file.Close)
+		hash := sha256.New()
+		if _, err := io.Copy(hash, file); err != nil {
+			return err
+		}
+		hashes[rel] = hex.EncodeToString(hash.Sum(nil))
+		return nil
+	})
+	return hashes, err
+}
+
+// excludedFromSync returns whether a slash separated relative path, or any directory it's in, matches one
+// of the exclude globs. Globs are matched against both whole relative paths and single names.
+func excludedFromSync(name string, excludes []string) bool {
+	parts := strings.Split(name, "/")
+	for i := range parts {
+		prefix := strings.Join(parts[:i+1], "/")
+		for _, exclude := range excludes {
+			if matched, _ := path.Match(exclude, prefix); matched {
+				return true
+			}
+			if matched, _ := path.Match(exclude, parts[i]); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// changedFiles returns the sorted names of the files in src that are missing from dst or have a different hash.
+func changedFiles(src, dst map[string]string) []string {
+	var changed []string
+	for name, hash := range src {
+		if dst[name] != hash {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// shellQuotePath quotes a path for a POSIX shell, leaving a leading ~ unquoted so it is still expanded.
+func shellQuotePath(p string) string {
+	quote := func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	}
+	switch {
+	case p == "" || p == "~":
+		return "~"
+	case strings.HasPrefix(p, "~/"):
+		return "~/" + quote(strings.TrimPrefix(p, "~/"))
+	default:
+		return quote(p)
+	}
+}
+
+// progressFileCopyFactory calls onCopy with the name of each file its copiers copy.
+type progressFileCopyFactory struct {
+	shell.FileCopyFactory
+	onCopy func(name string)
+}
+
+func (f *progressFileCopyFactory) MakeFileCopier(ctx context.Context, sourceType shell.CopyFilesSourceType) (shell.FileCopier, error) {
+	copier, err := f.FileCopyFactory.MakeFileCopier(ctx, sourceType)
+	if err != nil {
+		return nil, err
+	}
+	return &progressFileCopier{FileCopier: copier, onCopy: f.onCopy}, nil
+}
+
+type progressFileCopier struct {
+	shell.FileCopier
+	onCopy func(name string)
+}
+
+func (c *progressFileCopier) Copy(ctx context.Context, file shell.File) error {
+	c.onCopy(file.RelativeName)
+	return c.FileCopier.Copy(ctx, file)
+}
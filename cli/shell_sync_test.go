@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/services/shell"
+)
+
+// syncShellService is a shell service for a "machine" that is the local filesystem. Its shell only understands
+// the command that lists file hashes, and answers it like a terminal would, echo and all.
+type syncShellService struct {
+	shell.Service
+}
+
+func (s *syncShellService) Shell(ctx context.Context, extra map[string]interface{}) (
+	chan<- string, chan<- map[string]interface{}, <-chan shell.Output, error,
+) {
+	input := make(chan string, 1)
+	output := make(chan shell.Output, 4)
+	go func() {
+		command := <-input
+		output <- shell.Output{Output: "$ " + strings.ReplaceAll(command, "\n", "\r\n")}
+		dir := command[strings.Index(command, "cd '")+4:]
+		dir = dir[:strings.Index(dir, "'")]
+		if strings.Contains(command, "mkdir -p") {
+			if err := os.MkdirAll(dir, 0o750); err != nil {
+				output <- shell.Output{Output: err.Error()}
+			}
+		}
+		hashes, err := localFileHashes(dir, nil)
+		if err != nil {
+			hashes = nil
+		}
+		names := make([]string, 0, len(hashes))
+		for name := range hashes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var out strings.Builder
+		out.WriteString(syncMarker + "_BEGIN\r\n")
+		for _, name := range names {
+			fmt.Fprintf(&out, "%s  ./%s\r\n", hashes[name], name)
+		}
+		out.WriteString(syncMarker + "_END\r\n")
+		output <- shell.Output{Output: out.String()}
+		output <- shell.Output{EOF: true}
+	}()
+	return input, nil, output, nil
+}
+
+func (s *syncShellService) CopyFilesToMachine(
+	ctx context.Context,
+	sourceType shell.CopyFilesSourceType,
+	destination string,
+	preserve bool,
+	extra map[string]interface{},
+) (shell.FileCopier, error) {
+	factory, err := shell.NewLocalFileCopyFactory(destination, preserve, true)
+	if err != nil {
+		return nil, err
+	}
+	return factory.MakeFileCopier(ctx, sourceType)
+}
+
+func (s *syncShellService) CopyFilesFromMachine(
+	ctx context.Context,
+	paths []string,
+	allowRecursion bool,
+	preserve bool,
+	copyFactory shell.FileCopyFactory,
+	extra map[string]interface{},
+) error {
+	reader, err := shell.NewLocalFileReadCopier(paths, allowRecursion, false, copyFactory)
+	if err != nil {
+		return err
+	}
+	if err := reader.ReadAll(ctx); err != nil {
+		return err
+	}
+	return reader.Close(ctx)
+}
+
+func writeSyncFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, contents := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		test.That(t, os.MkdirAll(filepath.Dir(filePath), 0o750), test.ShouldBeNil)
+		test.That(t, os.WriteFile(filePath, []byte(contents), 0o600), test.ShouldBeNil)
+	}
+}
+
+func readSyncFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	contents, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	test.That(t, err, test.ShouldBeNil)
+	return string(contents)
+}
+
+func TestFileSyncer(t *testing.T) {
+	ctx := context.Background()
+	localDir := t.TempDir()
+	remoteDir := filepath.Join(t.TempDir(), "assets")
+	writeSyncFiles(t, localDir, map[string]string{
+		"a.txt":         "a",
+		"models/b.bin":  "bbbb",
+		"models/c.tmp":  "c",
+		"cache/big.bin": "big",
+	})
+
+	out := &testWriter{}
+	syncer := &fileSyncer{shellSvc: &syncShellService{}, excludes: []string{"*.tmp", "cache"}, w: out}
+	test.That(t, syncer.push(ctx, localDir, remoteDir), test.ShouldBeNil)
+	test.That(t, readSyncFile(t, remoteDir, "a.txt"), test.ShouldEqual, "a")
+	test.That(t, readSyncFile(t, remoteDir, "models/b.bin"), test.ShouldEqual, "bbbb")
+	_, err := os.Stat(filepath.Join(remoteDir, "models", "c.tmp"))
+	test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+	_, err = os.Stat(filepath.Join(remoteDir, "cache"))
+	test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+	test.That(t, out.messages[len(out.messages)-1], test.ShouldEqual, "Copied 2 files, 0 were already up to date\n")
+
+	// only the changed file is copied, and it replaces the old one whole
+	writeSyncFiles(t, localDir, map[string]string{"models/b.bin": "b"})
+	out.messages = nil
+	test.That(t, syncer.push(ctx, localDir, remoteDir), test.ShouldBeNil)
+	test.That(t, out.messages, test.ShouldResemble, []string{
+		"[1/1] models/b.bin\n",
+		"Copied 1 files, 1 were already up to date\n",
+	})
+	test.That(t, readSyncFile(t, remoteDir, "models/b.bin"), test.ShouldEqual, "b")
+
+	// pulling brings back changes made on the machine
+	writeSyncFiles(t, remoteDir, map[string]string{"a.txt": "changed", "logs/new.log": "new"})
+	out.messages = nil
+	test.That(t, syncer.pull(ctx, remoteDir, localDir), test.ShouldBeNil)
+	test.That(t, out.messages, test.ShouldResemble, []string{
+		"[1/2] a.txt\n",
+		"[2/2] logs/new.log\n",
+		"Copied 2 files, 1 were already up to date\n",
+	})
+	test.That(t, readSyncFile(t, localDir, "a.txt"), test.ShouldEqual, "changed")
+	test.That(t, readSyncFile(t, localDir, "logs/new.log"), test.ShouldEqual, "new")
+
+	out.messages = nil
+	test.That(t, syncer.pull(ctx, remoteDir, localDir), test.ShouldBeNil)
+	test.That(t, out.messages, test.ShouldResemble, []string{"All 3 files are up to date\n"})
+}
+
+func TestParseRemoteFileHashes(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+	hashes, err := parseRemoteFileHashes("$ printf '%s_%s\\n' VIAMSYNC BEGIN; cd 'x'\r\n" +
+		"VIAMSYNC_BEGIN\r\n" +
+		hash + "  ./a.txt\r\n" +
+		hash + "  ./dir/with space.txt\r\n" +
+		"\\" + hash + "  ./new\\nline\r\n" +
+		"VIAMSYNC_END\r\n")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, hashes, test.ShouldResemble, map[string]string{"a.txt": hash, "dir/with space.txt": hash})
+
+	_, err = parseRemoteFileHashes("bash: printf: command not found\r\n")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestExcludedFromSync(t *testing.T) {
+	excludes := []string{"*.tmp", "build", "docs/*.md"}
+	test.That(t, excludedFromSync("a.tmp", excludes), test.ShouldBeTrue)
+	test.That(t, excludedFromSync("src/a.tmp", excludes), test.ShouldBeTrue)
+	test.That(t, excludedFromSync("build/out.bin", excludes), test.ShouldBeTrue)
+	test.That(t, excludedFromSync("docs/README.md", excludes), test.ShouldBeTrue)
+	test.That(t, excludedFromSync("README.md", excludes), test.ShouldBeFalse)
+	test.That(t, excludedFromSync("src/main.go", excludes), test.ShouldBeFalse)
+}
+
+func TestShellQuotePath(t *testing.T) {
+	test.That(t, shellQuotePath(""), test.ShouldEqual, "~")
+	test.That(t, shellQuotePath("~/my dir"), test.ShouldEqual, "~/'my dir'")
+	test.That(t, shellQuotePath("/it's"), test.ShouldEqual, `'/it'\''s'`)
+}
@@ -3,7 +3,9 @@ package arm_test
 import (
 	"context"
 	"errors"
+	"math"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/go-viper/mapstructure/v2"
@@ -15,6 +17,7 @@ import (
 	"go.viam.com/rdk/components/arm"
 	"go.viam.com/rdk/components/arm/fake"
 	ur "go.viam.com/rdk/components/arm/universalrobots"
+	"go.viam.com/rdk/components/arm/xarm"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/motionplan"
 	"go.viam.com/rdk/referenceframe"
@@ -586,3 +589,273 @@ func locationCheckTestHelper(geomList []spatialmath.Geometry, checkMap map[strin
 	}
 	return true
 }
+
+func TestPayload(t *testing.T) {
+	test.That(t, arm.PayloadSpeedScale(arm.Payload{MassKg: 2}, 0), test.ShouldEqual, 1)
+	test.That(t, arm.PayloadSpeedScale(arm.Payload{}, 5), test.ShouldEqual, 1)
+	test.That(t, arm.PayloadSpeedScale(arm.Payload{MassKg: 2.5}, 5), test.ShouldAlmostEqual, 0.75)
+	test.That(t, arm.PayloadSpeedScale(arm.Payload{MassKg: 10}, 5), test.ShouldAlmostEqual, 0.5)
+
+	payload, err := arm.PayloadFromCommand(map[string]interface{}{
+		"command":              arm.SetPayloadCommand,
+		"mass_kg":              1.5,
+		"center_of_gravity_mm": map[string]interface{}{"x": 1., "z": 30.},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, payload, test.ShouldResemble, arm.Payload{MassKg: 1.5, CenterOfGravity: r3.Vector{X: 1, Z: 30}})
+
+	roundTrip, err := arm.PayloadFromCommand(arm.PayloadToCommandResponse(payload))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, roundTrip, test.ShouldResemble, payload)
+
+	_, err = arm.PayloadFromCommand(map[string]interface{}{"mass_kg": -1.})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = arm.PayloadFromCommand(map[string]interface{}{"mass_kg": 1., "center_of_gravity_mm": "up"})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestWorkspaceLimits(t *testing.T) {
+	floor := arm.WorkspaceLimitConfig{
+		Name:    "floor",
+		Type:    arm.WorkspaceLimitPlane,
+		PointMM: &r3.Vector{Z: -10},
+		Normal:  &r3.Vector{Z: 1},
+	}
+	wall := arm.WorkspaceLimitConfig{
+		Name:    "wall",
+		Type:    arm.WorkspaceLimitPlane,
+		PointMM: &r3.Vector{Y: 150},
+		Normal:  &r3.Vector{Y: -1},
+	}
+	cell := arm.WorkspaceLimitConfig{
+		Name:  "cell",
+		Type:  arm.WorkspaceLimitBox,
+		MinMM: &r3.Vector{X: -500, Y: -500, Z: -10},
+		MaxMM: &r3.Vector{X: 500, Y: 500, Z: 800},
+	}
+
+	t.Run("validate", func(t *testing.T) {
+		cfg := &arm.WorkspaceConfig{}
+		test.That(t, cfg.Validate("path"), test.ShouldBeError,
+			resource.NewConfigValidationFieldRequiredError("path", "limits"))
+
+		cfg.Limits = []arm.WorkspaceLimitConfig{floor, floor}
+		test.That(t, cfg.Validate("path"), test.ShouldNotBeNil)
+
+		cfg.Limits = []arm.WorkspaceLimitConfig{floor, {Name: "cube", Type: arm.WorkspaceLimitBox, MinMM: &r3.Vector{}}}
+		test.That(t, cfg.Validate("path"), test.ShouldBeError,
+			resource.NewConfigValidationFieldRequiredError("path.limits.1", "max_mm"))
+
+		cfg.Limits[1].MaxMM = &r3.Vector{X: 1, Y: 1}
+		test.That(t, cfg.Validate("path"), test.ShouldNotBeNil)
+
+		cfg.Limits[1] = arm.WorkspaceLimitConfig{Name: "ball", Type: "sphere"}
+		test.That(t, cfg.Validate("path"), test.ShouldNotBeNil)
+
+		cfg.Limits[1] = wall
+		cfg.Limits[1].Normal = &r3.Vector{}
+		test.That(t, cfg.Validate("path"), test.ShouldNotBeNil)
+
+		cfg.Limits = []arm.WorkspaceLimitConfig{floor, wall, cell}
+		test.That(t, cfg.Validate("path"), test.ShouldBeNil)
+	})
+
+	t.Run("targets", func(t *testing.T) {
+		// no limits allow everything
+		var none *arm.WorkspaceLimits
+		target := spatialmath.NewPoseFromPoint(r3.Vector{X: 1000})
+		pose, err := none.TargetPose(target)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pose, test.ShouldResemble, target)
+
+		limits, err := arm.NewWorkspaceLimits(&arm.WorkspaceConfig{Limits: []arm.WorkspaceLimitConfig{floor, wall, cell}})
+		test.That(t, err, test.ShouldBeNil)
+		pose, err = limits.TargetPose(spatialmath.NewPoseFromPoint(r3.Vector{X: 300, Z: 100}))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pose.Point(), test.ShouldResemble, r3.Vector{X: 300, Z: 100})
+
+		var limitErr *arm.WorkspaceLimitError
+		_, err = limits.TargetPose(spatialmath.NewPoseFromPoint(r3.Vector{X: 300, Z: -100}))
+		test.That(t, errors.As(err, &limitErr), test.ShouldBeTrue)
+		test.That(t, limitErr.Limit, test.ShouldEqual, "floor")
+		_, err = limits.TargetPose(spatialmath.NewPoseFromPoint(r3.Vector{X: 600, Z: 100}))
+		test.That(t, err.Error(), test.ShouldEqual, `the target position would leave workspace limit "cell"`)
+
+		// clipped targets are moved to the nearest point within every limit
+		limits, err = arm.NewWorkspaceLimits(&arm.WorkspaceConfig{
+			Limits:      []arm.WorkspaceLimitConfig{floor, wall, cell},
+			ClipTargets: true,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		target = spatialmath.NewPose(r3.Vector{X: 600, Y: 200, Z: -100}, &spatialmath.OrientationVectorDegrees{OZ: -1})
+		pose, err = limits.TargetPose(target)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, spatialmath.R3VectorAlmostEqual(pose.Point(), r3.Vector{X: 500, Y: 150, Z: -10}, 1e-6), test.ShouldBeTrue)
+		test.That(t, spatialmath.OrientationAlmostEqual(pose.Orientation(), target.Orientation()), test.ShouldBeTrue)
+	})
+
+	t.Run("joint moves", func(t *testing.T) {
+		model, err := xarm.MakeModelFrame("", xarm.ModelName6DOF)
+		test.That(t, err, test.ShouldBeNil)
+		limits, err := arm.NewWorkspaceLimits(&arm.WorkspaceConfig{Limits: []arm.WorkspaceLimitConfig{floor, wall}})
+		test.That(t, err, test.ShouldBeNil)
+
+		home := make([]referenceframe.Input, 6)
+		test.That(t, limits.CheckInputs(model, home), test.ShouldBeNil)
+
+		// turning the waist left swings the arm through the wall, and right keeps it clear
+		left := referenceframe.FloatsToInputs([]float64{math.Pi / 2, 0, 0, 0, 0, 0})
+		right := referenceframe.FloatsToInputs([]float64{-math.Pi / 2, 0, 0, 0, 0, 0})
+		err = limits.CheckJointMove(model, home, left)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, `would leave workspace limit "wall"`)
+		test.That(t, limits.CheckJointMove(model, home, right), test.ShouldBeNil)
+
+		// a box around the end effector alone leaves the rest of the arm out of it
+		ee, err := model.Transform(home)
+		test.That(t, err, test.ShouldBeNil)
+		tight, err := arm.NewWorkspaceLimits(&arm.WorkspaceConfig{Limits: []arm.WorkspaceLimitConfig{{
+			Name:  "tight",
+			Type:  arm.WorkspaceLimitBox,
+			MinMM: &r3.Vector{X: ee.Point().X - 1, Y: ee.Point().Y - 1, Z: ee.Point().Z - 1},
+			MaxMM: &r3.Vector{X: ee.Point().X + 1, Y: ee.Point().Y + 1, Z: ee.Point().Z + 1},
+		}}})
+		test.That(t, err, test.ShouldBeNil)
+		err = tight.CheckInputs(model, home)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldStartWith, "link ")
+
+		// the fake arm refuses the same move
+		notReal, err := fake.NewArm(context.Background(), nil, resource.Config{
+			Name:  arm.API.String(),
+			Model: resource.DefaultModelFamily.WithModel("fake"),
+			ConvertedAttributes: &fake.Config{
+				ArmModel:  "xArm6",
+				Workspace: &arm.WorkspaceConfig{Limits: []arm.WorkspaceLimitConfig{floor, wall}},
+			},
+		}, logging.NewTestLogger(t))
+		test.That(t, err, test.ShouldBeNil)
+		err = notReal.MoveToJointPositions(context.Background(), model.ProtobufFromInput(left), nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, notReal.MoveToJointPositions(context.Background(), model.ProtobufFromInput(right), nil), test.ShouldBeNil)
+	})
+}
+
+type effortArm struct {
+	*inject.Arm
+	efforts func() (arm.JointEfforts, error)
+}
+
+func (a *effortArm) JointEfforts(ctx context.Context) (arm.JointEfforts, error) {
+	return a.efforts()
+}
+
+func TestGuardedMove(t *testing.T) {
+	ctx := context.Background()
+	target := &pb.JointPositions{Values: []float64{10, 20, 30}}
+	limits := []float64{5, 5, 0}
+
+	_, err := arm.GuardedMove(ctx, inject.NewArm(testArmName), target, limits)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	var stopped atomic.Bool
+	injectArm := inject.NewArm(testArmName)
+	injectArm.JointPositionsFunc = func(ctx context.Context, extra map[string]interface{}) (*pb.JointPositions, error) {
+		return &pb.JointPositions{Values: []float64{4, 5, 6}}, nil
+	}
+	injectArm.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		stopped.Store(true)
+		return nil
+	}
+	// the third joint is unguarded, so only the second joint meeting something stops the arm
+	var contact atomic.Bool
+	a := &effortArm{Arm: injectArm, efforts: func() (arm.JointEfforts, error) {
+		if contact.Load() {
+			return arm.JointEfforts{Values: []float64{1, -7, 100}, Units: "Nm"}, nil
+		}
+		return arm.JointEfforts{Values: []float64{1, -2, 100}, Units: "Nm"}, nil
+	}}
+
+	t.Run("wrong limits", func(t *testing.T) {
+		_, err := arm.GuardedMove(ctx, a, target, []float64{5})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("reaches the target", func(t *testing.T) {
+		injectArm.MoveToJointPositionsFunc = func(ctx context.Context, pos *pb.JointPositions, extra map[string]interface{}) error {
+			return nil
+		}
+		result, err := arm.GuardedMove(ctx, a, target, limits)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, result.Contact, test.ShouldBeFalse)
+		test.That(t, result.Positions.Values, test.ShouldResemble, []float64{4, 5, 6})
+		test.That(t, stopped.Load(), test.ShouldBeFalse)
+	})
+
+	t.Run("stops on contact", func(t *testing.T) {
+		injectArm.MoveToJointPositionsFunc = func(ctx context.Context, pos *pb.JointPositions, extra map[string]interface{}) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		contact.Store(true)
+		result, err := arm.GuardedMove(ctx, a, target, limits)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, result.Contact, test.ShouldBeTrue)
+		test.That(t, result.Joint, test.ShouldEqual, 1)
+		test.That(t, result.Effort, test.ShouldEqual, -7)
+		test.That(t, stopped.Load(), test.ShouldBeTrue)
+
+		resp := arm.GuardResultToCommandResponse(result)
+		test.That(t, resp["contact"], test.ShouldBeTrue)
+		test.That(t, resp["joint"], test.ShouldEqual, 1)
+		test.That(t, resp["positions_degs"], test.ShouldResemble, []interface{}{4., 5., 6.})
+	})
+
+	t.Run("stops when efforts can't be read", func(t *testing.T) {
+		stopped.Store(false)
+		a.efforts = func() (arm.JointEfforts, error) {
+			return arm.JointEfforts{}, errors.New("no efforts")
+		}
+		_, err := arm.GuardedMove(ctx, a, target, limits)
+		test.That(t, err, test.ShouldBeError, errors.New("no efforts"))
+		test.That(t, stopped.Load(), test.ShouldBeTrue)
+	})
+
+	t.Run("command", func(t *testing.T) {
+		pos, maxEfforts, err := arm.GuardedMoveFromCommand(map[string]interface{}{
+			"command":        arm.GuardedMoveCommand,
+			"positions_degs": []interface{}{10., 20., 30.},
+			"max_effort":     2.5,
+		}, 3)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pos, test.ShouldResemble, target)
+		test.That(t, maxEfforts, test.ShouldResemble, []float64{2.5, 2.5, 2.5})
+
+		_, maxEfforts, err = arm.GuardedMoveFromCommand(map[string]interface{}{
+			"positions_degs": []interface{}{10., 20., 30.},
+			"max_efforts":    []interface{}{1., 0., 3.},
+		}, 3)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, maxEfforts, test.ShouldResemble, []float64{1, 0, 3})
+
+		_, _, err = arm.GuardedMoveFromCommand(map[string]interface{}{
+			"positions_degs": []interface{}{10., 20.},
+			"max_effort":     2.5,
+		}, 3)
+		test.That(t, err, test.ShouldNotBeNil)
+		_, _, err = arm.GuardedMoveFromCommand(map[string]interface{}{
+			"positions_degs": []interface{}{10., 20., 30.},
+			"max_effort":     2.5,
+			"max_efforts":    []interface{}{1., 0., 3.},
+		}, 3)
+		test.That(t, err, test.ShouldNotBeNil)
+		_, _, err = arm.GuardedMoveFromCommand(map[string]interface{}{
+			"positions_degs": []interface{}{10., 20., 30.},
+			"max_efforts":    []interface{}{1., "two", 3.},
+		}, 3)
+		test.That(t, err, test.ShouldNotBeNil)
+
+		resp := arm.JointEffortsToCommandResponse(arm.JointEfforts{Values: []float64{1, 2}, Units: "Nm"})
+		test.That(t, resp, test.ShouldResemble, map[string]interface{}{"efforts": []interface{}{1., 2.}, "units": "Nm"})
+	})
+}
@@ -0,0 +1,198 @@
+//go:build !no_cgo
+
+package arm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+	pb "go.viam.com/api/component/arm/v1"
+	goutils "go.viam.com/utils"
+)
+
+const (
+	// GetJointEffortsCommand is the DoCommand name used to ask an arm how hard its joints are
+	// working. It returns "efforts", a number for each joint, and "units", what they measure.
+	GetJointEffortsCommand = "get_joint_efforts"
+	// GuardedMoveCommand is the DoCommand name used to move an arm's joints to "positions_degs"
+	// unless it meets something on the way. The move stops as soon as any joint's effort exceeds
+	// its limit, given for each joint by "max_efforts" or for all of them by "max_effort". It
+	// returns whether there was "contact", and if so at which "joint" and "effort", with the
+	// "positions_degs" the arm stopped at.
+	GuardedMoveCommand = "guarded_move"
+
+	// guardPollInterval is how often efforts are read during a guarded move.
+	guardPollInterval = 10 * time.Millisecond
+)
+
+// JointEfforts are how hard each of an arm's joints is working, in whatever the hardware measures,
+// such as torque in Nm or motor current in A.
+type JointEfforts struct {
+	Values []float64
+	Units  string
+}
+
+// An EffortReader is an arm that can report the effort of each of its joints, so that contact with
+// its surroundings can be felt.
+type EffortReader interface {
+	JointEfforts(ctx context.Context) (JointEfforts, error)
+}
+
+// GuardResult describes how a guarded move ended.
+type GuardResult struct {
+	// Contact is whether the move was stopped because a joint's effort exceeded its limit.
+	Contact bool
+	// Joint and Effort are the joint whose effort exceeded its limit, and what it was.
+	Joint  int
+	Effort float64
+	// Positions are the arm's joint positions when the move ended.
+	Positions *pb.JointPositions
+}
+
+// GuardedMove moves the arm's joints to target, stopping the arm as soon as the effort of any joint
+// exceeds its entry in maxEfforts, such as when a part being inserted meets resistance. A limit of
+// zero leaves that joint unguarded. Reaching the target is not an error, and neither is contact,
+// which is reported in the result.
+func GuardedMove(ctx context.Context, a Arm, target *pb.JointPositions, maxEfforts []float64) (GuardResult, error) {
+	reader, ok := a.(EffortReader)
+	if !ok {
+		return GuardResult{}, errors.New("arm cannot read its joint efforts")
+	}
+	if len(maxEfforts) != len(target.Values) {
+		return GuardResult{}, fmt.Errorf("expected %d effort limits but got %d", len(target.Values), len(maxEfforts))
+	}
+
+	moveCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	moveErr := make(chan error, 1)
+	goutils.PanicCapturingGo(func() {
+		moveErr <- a.MoveToJointPositions(moveCtx, target, nil)
+	})
+
+	ticker := time.NewTicker(guardPollInterval)
+	defer ticker.Stop()
+	var result GuardResult
+	for {
+		select {
+		case err := <-moveErr:
+			if err != nil {
+				return result, err
+			}
+			result.Positions, err = a.JointPositions(ctx, nil)
+			return result, err
+		case <-ticker.C:
+		}
+
+		efforts, err := reader.JointEfforts(ctx)
+		if err != nil {
+			return result, stopGuardedMove(ctx, a, cancel, moveErr, err)
+		}
+		for i, limit := range maxEfforts {
+			if limit <= 0 || i >= len(efforts.Values) || math.Abs(efforts.Values[i]) <= limit {
+				continue
+			}
+			result = GuardResult{Contact: true, Joint: i, Effort: efforts.Values[i]}
+			if err := stopGuardedMove(ctx, a, cancel, moveErr, nil); err != nil {
+				return result, err
+			}
+			result.Positions, err = a.JointPositions(ctx, nil)
+			return result, err
+		}
+	}
+}
+
+// stopGuardedMove stops the arm where it is and waits for the move to end, returning why it was
+// stopped along with any error stopping it.
+func stopGuardedMove(ctx context.Context, a Arm, cancel context.CancelFunc, moveErr <-chan error, why error) error {
+	err := a.Stop(ctx, nil)
+	cancel()
+	// the move was cancelled, so its error says nothing new
+	<-moveErr
+	if why != nil {
+		if err != nil {
+			return errors.Wrapf(why, "failed to stop the arm: %s", err)
+		}
+		return why
+	}
+	return err
+}
+
+// JointEffortsToCommandResponse returns efforts as the answer to a GetJointEffortsCommand.
+func JointEffortsToCommandResponse(efforts JointEfforts) map[string]interface{} {
+	values := make([]interface{}, 0, len(efforts.Values))
+	for _, v := range efforts.Values {
+		values = append(values, v)
+	}
+	return map[string]interface{}{"efforts": values, "units": efforts.Units}
+}
+
+// GuardedMoveFromCommand parses the arguments of a GuardedMoveCommand for an arm with dof joints.
+func GuardedMoveFromCommand(cmd map[string]interface{}, dof int) (*pb.JointPositions, []float64, error) {
+	positions, err := floatsFromCommand(cmd, "positions_degs")
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(positions) != dof {
+		return nil, nil, fmt.Errorf("positions_degs must have %d values but has %d", dof, len(positions))
+	}
+
+	var maxEfforts []float64
+	if maxEffort, ok := cmd["max_effort"]; ok {
+		if _, ok := cmd["max_efforts"]; ok {
+			return nil, nil, errors.New("give either max_effort or max_efforts, not both")
+		}
+		limit, ok := maxEffort.(float64)
+		if !ok || limit <= 0 {
+			return nil, nil, errors.New("max_effort must be a positive number")
+		}
+		maxEfforts = make([]float64, dof)
+		for i := range maxEfforts {
+			maxEfforts[i] = limit
+		}
+	} else {
+		if maxEfforts, err = floatsFromCommand(cmd, "max_efforts"); err != nil {
+			return nil, nil, err
+		}
+		if len(maxEfforts) != dof {
+			return nil, nil, fmt.Errorf("max_efforts must have %d values but has %d", dof, len(maxEfforts))
+		}
+	}
+	return &pb.JointPositions{Values: positions}, maxEfforts, nil
+}
+
+// GuardResultToCommandResponse returns result as the answer to a GuardedMoveCommand.
+func GuardResultToCommandResponse(result GuardResult) map[string]interface{} {
+	resp := map[string]interface{}{"contact": result.Contact}
+	if result.Contact {
+		resp["joint"] = result.Joint
+		resp["effort"] = result.Effort
+	}
+	if result.Positions != nil {
+		positions := make([]interface{}, 0, len(result.Positions.Values))
+		for _, v := range result.Positions.Values {
+			positions = append(positions, v)
+		}
+		resp["positions_degs"] = positions
+	}
+	return resp
+}
+
+// floatsFromCommand returns the list of numbers under key in cmd.
+func floatsFromCommand(cmd map[string]interface{}, key string) ([]float64, error) {
+	raw, ok := cmd[key].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be a list of numbers", key)
+	}
+	values := make([]float64, 0, len(raw))
+	for _, r := range raw {
+		v, ok := r.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a list of numbers", key)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
@@ -4,6 +4,7 @@ package fake
 import (
 	"context"
 	_ "embed"
+	"fmt"
 	"strings"
 	"sync"
 
@@ -40,6 +41,9 @@ var dofbotjson []byte
 type Config struct {
 	ArmModel      string `json:"arm-model,omitempty"`
 	ModelFilePath string `json:"model-path,omitempty"`
+
+	// Workspace keeps the arm within the given regions of its base frame.
+	Workspace *arm.WorkspaceConfig `json:"workspace,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -53,6 +57,9 @@ func (conf *Config) Validate(path string) ([]string, error) {
 	case conf.ArmModel == "" && conf.ModelFilePath != "":
 		_, err = modelFromPath(conf.ModelFilePath, "")
 	}
+	if err == nil && conf.Workspace != nil {
+		err = conf.Workspace.Validate(fmt.Sprintf("%s.%s", path, "workspace"))
+	}
 	return nil, err
 }
 
@@ -103,9 +110,10 @@ type Arm struct {
 	CloseCount int
 	logger     logging.Logger
 
-	mu     sync.RWMutex
-	joints *pb.JointPositions
-	model  referenceframe.Model
+	mu        sync.RWMutex
+	joints    *pb.JointPositions
+	model     referenceframe.Model
+	workspace *arm.WorkspaceLimits
 }
 
 // Reconfigure atomically reconfigures this arm in place based on the new config.
@@ -127,10 +135,16 @@ func (a *Arm) Reconfigure(ctx context.Context, deps resource.Dependencies, conf
 			"the arm-model and model-path from attributes")
 	}
 
+	workspace, err := arm.NewWorkspaceLimits(newConf.Workspace)
+	if err != nil {
+		return err
+	}
+
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	a.joints = &pb.JointPositions{Values: make([]float64, dof)}
 	a.model = model
+	a.workspace = workspace
 
 	return nil
 }
@@ -155,6 +169,13 @@ func (a *Arm) EndPosition(ctx context.Context, extra map[string]interface{}) (sp
 
 // MoveToPosition sets the position.
 func (a *Arm) MoveToPosition(ctx context.Context, pos spatialmath.Pose, extra map[string]interface{}) error {
+	a.mu.RLock()
+	workspace := a.workspace
+	a.mu.RUnlock()
+	pos, err := workspace.TargetPose(pos)
+	if err != nil {
+		return err
+	}
 	return arm.Move(ctx, a.logger, a, pos)
 }
 
@@ -166,6 +187,9 @@ func (a *Arm) MoveToJointPositions(ctx context.Context, joints *pb.JointPosition
 	}
 	a.mu.RLock()
 	defer a.mu.RUnlock()
+	if err := a.workspace.CheckJointMove(a.model, a.model.InputFromProtobuf(a.joints), inputs); err != nil {
+		return err
+	}
 	pos, err := a.model.Transform(inputs)
 	if err != nil {
 		return err
@@ -0,0 +1,99 @@
+//go:build !no_cgo
+
+package arm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SetPayloadCommand is the DoCommand name used to tell an arm what it is carrying. It takes a
+	// "mass_kg" number and an optional "center_of_gravity_mm" object with "x", "y", and "z" keys.
+	SetPayloadCommand = "set_payload"
+	// GetPayloadCommand is the DoCommand name used to ask an arm what it thinks it is carrying.
+	GetPayloadCommand = "get_payload"
+
+	// minPayloadSpeedScale is how much an arm's speed is scaled down when it is carrying its full
+	// rated payload.
+	minPayloadSpeedScale = 0.5
+)
+
+// Payload describes the load carried at the end of an arm.
+type Payload struct {
+	MassKg float64
+	// CenterOfGravity is the location of the payload's center of mass relative to the arm's end
+	// effector frame, in millimeters.
+	CenterOfGravity r3.Vector
+}
+
+// A PayloadSetter is an arm that can be told what payload it is carrying, so that its driver can
+// compensate for the extra load and slow down its moves accordingly.
+type PayloadSetter interface {
+	SetPayload(ctx context.Context, payload Payload) error
+	Payload(ctx context.Context) (Payload, error)
+}
+
+// PayloadSpeedScale returns the factor by which joint speeds and accelerations should be scaled
+// when carrying the given payload on an arm rated for maxPayloadKg. The factor falls linearly from
+// 1 with no payload to minPayloadSpeedScale at the rated payload. If the rating is unknown (zero),
+// speeds are not scaled.
+func PayloadSpeedScale(payload Payload, maxPayloadKg float64) float64 {
+	if maxPayloadKg <= 0 || payload.MassKg <= 0 {
+		return 1
+	}
+	fraction := payload.MassKg / maxPayloadKg
+	if fraction > 1 {
+		fraction = 1
+	}
+	return 1 - fraction*(1-minPayloadSpeedScale)
+}
+
+// PayloadFromCommand parses the arguments of a SetPayloadCommand.
+func PayloadFromCommand(cmd map[string]interface{}) (Payload, error) {
+	var payload Payload
+	mass, ok := cmd["mass_kg"].(float64)
+	if !ok {
+		return payload, errors.New("mass_kg must be a number")
+	}
+	if mass < 0 {
+		return payload, errors.New("mass_kg cannot be negative")
+	}
+	payload.MassKg = mass
+
+	rawCoG, ok := cmd["center_of_gravity_mm"]
+	if !ok {
+		return payload, nil
+	}
+	cog, ok := rawCoG.(map[string]interface{})
+	if !ok {
+		return payload, errors.New("center_of_gravity_mm must be an object with x, y, and z keys")
+	}
+	for key, dst := range map[string]*float64{"x": &payload.CenterOfGravity.X, "y": &payload.CenterOfGravity.Y, "z": &payload.CenterOfGravity.Z} {
+		raw, ok := cog[key]
+		if !ok {
+			continue
+		}
+		val, ok := raw.(float64)
+		if !ok {
+			return payload, fmt.Errorf("center_of_gravity_mm.%s must be a number", key)
+		}
+		*dst = val
+	}
+	return payload, nil
+}
+
+// PayloadToCommandResponse is the inverse of PayloadFromCommand, for answering a GetPayloadCommand.
+func PayloadToCommandResponse(payload Payload) map[string]interface{} {
+	return map[string]interface{}{
+		"mass_kg": payload.MassKg,
+		"center_of_gravity_mm": map[string]interface{}{
+			"x": payload.CenterOfGravity.X,
+			"y": payload.CenterOfGravity.Y,
+			"z": payload.CenterOfGravity.Z,
+		},
+	}
+}
@@ -5,6 +5,7 @@ import (
 	// register arms.
 	_ "go.viam.com/rdk/components/arm/eva"
 	_ "go.viam.com/rdk/components/arm/fake"
+	_ "go.viam.com/rdk/components/arm/toolchanger"
 	_ "go.viam.com/rdk/components/arm/universalrobots"
 	_ "go.viam.com/rdk/components/arm/wrapper"
 	_ "go.viam.com/rdk/components/arm/xarm"
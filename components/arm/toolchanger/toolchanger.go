@@ -0,0 +1,422 @@
+// Package toolchanger implements an arm that wraps another arm fitted with a tool changer. Each
+// configured tool has its own frame, mass, and optional gripper, and selecting a tool updates the
+// kinematic model used for planning.
+package toolchanger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	pb "go.viam.com/api/component/arm/v1"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/gripper"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/operation"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+)
+
+var model = resource.DefaultModelFamily.WithModel("tool_changer")
+
+// errToolChangeInProgress is returned by any motion request made while a tool change is underway.
+var errToolChangeInProgress = errors.New("cannot move the arm while a tool change is in progress")
+
+// ToolConfig describes a single tool that can be mounted on the arm.
+type ToolConfig struct {
+	Name string `json:"name"`
+	// Frame is the pose of the tool's center point relative to the arm's flange, along with the
+	// tool's collision geometry.
+	Frame  *referenceframe.LinkConfig `json:"frame,omitempty"`
+	MassKg float64                    `json:"mass_kg,omitempty"`
+	// CenterOfGravity is the tool's center of mass relative to the arm's flange, in millimeters.
+	CenterOfGravity *r3.Vector `json:"center_of_gravity_mm,omitempty"`
+	Gripper         string     `json:"gripper,omitempty"`
+}
+
+// Config is used for converting config attributes.
+type Config struct {
+	ArmName      string       `json:"arm"`
+	Tools        []ToolConfig `json:"tools"`
+	DefaultTool  string       `json:"default_tool,omitempty"`
+	MaxPayloadKg float64      `json:"max_payload_kg,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	if cfg.ArmName == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "arm")
+	}
+	if cfg.MaxPayloadKg < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("max_payload_kg cannot be negative"))
+	}
+	deps := []string{cfg.ArmName}
+	seen := map[string]bool{}
+	for idx, tool := range cfg.Tools {
+		toolPath := fmt.Sprintf("%s.tools.%d", path, idx)
+		if tool.Name == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(toolPath, "name")
+		}
+		if seen[tool.Name] {
+			return nil, resource.NewConfigValidationError(toolPath, errors.Errorf("duplicate tool name %q", tool.Name))
+		}
+		seen[tool.Name] = true
+		if tool.MassKg < 0 {
+			return nil, resource.NewConfigValidationError(toolPath, errors.New("mass_kg cannot be negative"))
+		}
+		if cfg.MaxPayloadKg > 0 && tool.MassKg > cfg.MaxPayloadKg {
+			return nil, resource.NewConfigValidationError(toolPath,
+				errors.Errorf("tool mass %.3fkg exceeds the arm's max payload of %.3fkg", tool.MassKg, cfg.MaxPayloadKg))
+		}
+		if tool.Gripper != "" {
+			deps = append(deps, tool.Gripper)
+		}
+	}
+	if cfg.DefaultTool != "" && !seen[cfg.DefaultTool] {
+		return nil, resource.NewConfigValidationError(path, errors.Errorf("default_tool %q is not a configured tool", cfg.DefaultTool))
+	}
+	return deps, nil
+}
+
+func init() {
+	resource.RegisterComponent(arm.API, model, resource.Registration[arm.Arm, *Config]{
+		Constructor: NewToolChangerArm,
+	})
+}
+
+// Tool is a tool that can be mounted on the arm, with its frame already parsed.
+type Tool struct {
+	Name    string
+	Payload arm.Payload
+	Gripper gripper.Gripper
+	frame   referenceframe.Frame
+}
+
+// Arm wraps another arm, and keeps track of which tool is mounted on its flange.
+type Arm struct {
+	resource.Named
+	resource.TriviallyCloseable
+	logger logging.Logger
+	opMgr  *operation.SingleOperationManager
+
+	mu           sync.RWMutex
+	actual       arm.Arm
+	tools        map[string]*Tool
+	activeTool   *Tool
+	model        referenceframe.Model
+	maxPayloadKg float64
+	changing     bool
+}
+
+// NewToolChangerArm returns an arm that wraps another arm fitted with a tool changer.
+func NewToolChangerArm(
+	ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger,
+) (arm.Arm, error) {
+	a := &Arm{
+		Named:  conf.ResourceName().AsNamed(),
+		logger: logger,
+		opMgr:  operation.NewSingleOperationManager(),
+	}
+	if err := a.Reconfigure(ctx, deps, conf); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Reconfigure atomically reconfigures this arm in place based on the new config. The active tool
+// is kept if it is still configured, and otherwise reset to the default tool.
+func (tc *Arm) Reconfigure(ctx context.Context, deps resource.Dependencies, conf resource.Config) error {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return err
+	}
+
+	newArm, err := arm.FromDependencies(deps, newConf.ArmName)
+	if err != nil {
+		return err
+	}
+
+	tools := make(map[string]*Tool, len(newConf.Tools))
+	for _, toolConf := range newConf.Tools {
+		tool := &Tool{Name: toolConf.Name, Payload: arm.Payload{MassKg: toolConf.MassKg}}
+		if toolConf.CenterOfGravity != nil {
+			tool.Payload.CenterOfGravity = *toolConf.CenterOfGravity
+		}
+		if toolConf.Frame != nil {
+			lif, err := toolConf.Frame.ParseConfig()
+			if err != nil {
+				return err
+			}
+			tool.frame, err = lif.ToStaticFrame(toolConf.Name)
+			if err != nil {
+				return err
+			}
+		} else {
+			tool.frame = referenceframe.NewZeroStaticFrame(toolConf.Name)
+		}
+		if toolConf.Gripper != "" {
+			tool.Gripper, err = gripper.FromDependencies(deps, toolConf.Gripper)
+			if err != nil {
+				return err
+			}
+		}
+		tools[toolConf.Name] = tool
+	}
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	activeName := newConf.DefaultTool
+	if tc.activeTool != nil {
+		if _, ok := tools[tc.activeTool.Name]; ok {
+			activeName = tc.activeTool.Name
+		}
+	}
+	tc.actual = newArm
+	tc.tools = tools
+	tc.maxPayloadKg = newConf.MaxPayloadKg
+	if err := tc.setActiveToolLocked(activeName); err != nil {
+		return err
+	}
+	return tc.propagatePayloadLocked(ctx)
+}
+
+// propagatePayloadLocked tells the underlying arm about the mass of the active tool, if its driver
+// supports payload settings. The mutex must be held when calling this.
+func (tc *Arm) propagatePayloadLocked(ctx context.Context) error {
+	setter, ok := tc.actual.(arm.PayloadSetter)
+	if !ok {
+		return nil
+	}
+	var payload arm.Payload
+	if tc.activeTool != nil {
+		payload = tc.activeTool.Payload
+	}
+	return setter.SetPayload(ctx, payload)
+}
+
+// setActiveToolLocked mounts the named tool (or no tool, if the name is empty) and rebuilds the
+// kinematic model to match. The mutex must be held when calling this.
+func (tc *Arm) setActiveToolLocked(name string) error {
+	var tool *Tool
+	if name != "" {
+		var ok bool
+		tool, ok = tc.tools[name]
+		if !ok {
+			return errors.Errorf("unknown tool %q", name)
+		}
+		if tc.maxPayloadKg > 0 && tool.Payload.MassKg > tc.maxPayloadKg {
+			return errors.Errorf("tool %q (%.3fkg) exceeds the arm's max payload of %.3fkg",
+				name, tool.Payload.MassKg, tc.maxPayloadKg)
+		}
+	}
+
+	armModel := tc.actual.ModelFrame()
+	if tool == nil || armModel == nil {
+		tc.model = armModel
+		tc.activeTool = tool
+		return nil
+	}
+
+	// The tool's frame hangs off the end of the arm's own kinematic chain, so that planning to a
+	// pose puts the tool center point there rather than the flange.
+	composed := referenceframe.NewSimpleModel(tc.Name().ShortName())
+	composed.OrdTransforms = []referenceframe.Frame{armModel, tool.frame}
+	tc.model = composed
+	tc.activeTool = tool
+	return nil
+}
+
+// SelectTool performs a complete tool change: motion is stopped and locked out, the new tool is
+// mounted in the kinematic model, and motion is allowed again.
+func (tc *Arm) SelectTool(ctx context.Context, name string) error {
+	if err := tc.BeginToolChange(ctx); err != nil {
+		return err
+	}
+	return tc.EndToolChange(ctx, name)
+}
+
+// BeginToolChange stops the arm and prevents any further motion until EndToolChange is called.
+// This is intended for tool changers that need an external procedure (e.g., a pneumatic release)
+// to run while the arm stays still.
+func (tc *Arm) BeginToolChange(ctx context.Context) error {
+	tc.mu.Lock()
+	tc.changing = true
+	actual := tc.actual
+	tc.mu.Unlock()
+
+	tc.opMgr.CancelRunning(ctx)
+	return actual.Stop(ctx, nil)
+}
+
+// EndToolChange mounts the named tool and allows the arm to move again. An empty name means no
+// tool is mounted. If the tool cannot be mounted, the interlock stays engaged.
+func (tc *Arm) EndToolChange(ctx context.Context, name string) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if err := tc.setActiveToolLocked(name); err != nil {
+		return err
+	}
+	if err := tc.propagatePayloadLocked(ctx); err != nil {
+		return err
+	}
+	tc.changing = false
+	return nil
+}
+
+// ActiveTool returns the currently mounted tool, or nil if there is none.
+func (tc *Arm) ActiveTool() *Tool {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	return tc.activeTool
+}
+
+func (tc *Arm) checkNotChanging() error {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	if tc.changing {
+		return errToolChangeInProgress
+	}
+	return nil
+}
+
+// ModelFrame returns the kinematic model of the arm with the active tool attached.
+func (tc *Arm) ModelFrame() referenceframe.Model {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	return tc.model
+}
+
+// EndPosition returns the pose of the active tool's center point.
+func (tc *Arm) EndPosition(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
+	joints, err := tc.JointPositions(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	return motionplan.ComputeOOBPosition(tc.ModelFrame(), joints)
+}
+
+// MoveToPosition moves the active tool's center point to the given pose.
+func (tc *Arm) MoveToPosition(ctx context.Context, pos spatialmath.Pose, extra map[string]interface{}) error {
+	if err := tc.checkNotChanging(); err != nil {
+		return err
+	}
+	ctx, done := tc.opMgr.New(ctx)
+	defer done()
+	return arm.Move(ctx, tc.logger, tc, pos)
+}
+
+// MoveToJointPositions moves the underlying arm's joints.
+func (tc *Arm) MoveToJointPositions(ctx context.Context, joints *pb.JointPositions, extra map[string]interface{}) error {
+	if err := tc.checkNotChanging(); err != nil {
+		return err
+	}
+	inputs := tc.ModelFrame().InputFromProtobuf(joints)
+	if err := arm.CheckDesiredJointPositions(ctx, tc, inputs); err != nil {
+		return err
+	}
+	ctx, done := tc.opMgr.New(ctx)
+	defer done()
+
+	tc.mu.RLock()
+	actual := tc.actual
+	tc.mu.RUnlock()
+	return actual.MoveToJointPositions(ctx, joints, extra)
+}
+
+// JointPositions returns the underlying arm's joint positions.
+func (tc *Arm) JointPositions(ctx context.Context, extra map[string]interface{}) (*pb.JointPositions, error) {
+	tc.mu.RLock()
+	actual := tc.actual
+	tc.mu.RUnlock()
+	return actual.JointPositions(ctx, extra)
+}
+
+// Stop stops the underlying arm.
+func (tc *Arm) Stop(ctx context.Context, extra map[string]interface{}) error {
+	ctx, done := tc.opMgr.New(ctx)
+	defer done()
+
+	tc.mu.RLock()
+	actual := tc.actual
+	tc.mu.RUnlock()
+	return actual.Stop(ctx, extra)
+}
+
+// IsMoving returns whether the arm is moving.
+func (tc *Arm) IsMoving(ctx context.Context) (bool, error) {
+	return tc.opMgr.OpRunning(), nil
+}
+
+// CurrentInputs returns the current inputs of the arm.
+func (tc *Arm) CurrentInputs(ctx context.Context) ([]referenceframe.Input, error) {
+	res, err := tc.JointPositions(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return tc.ModelFrame().InputFromProtobuf(res), nil
+}
+
+// GoToInputs moves the arm to the specified goal inputs.
+func (tc *Arm) GoToInputs(ctx context.Context, inputSteps ...[]referenceframe.Input) error {
+	for _, goal := range inputSteps {
+		if err := tc.MoveToJointPositions(ctx, tc.ModelFrame().ProtobufFromInput(goal), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Geometries returns the geometries of the arm and the active tool, relative to the frame of the
+// arm.
+func (tc *Arm) Geometries(ctx context.Context, extra map[string]interface{}) ([]spatialmath.Geometry, error) {
+	inputs, err := tc.CurrentInputs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gif, err := tc.ModelFrame().Geometries(inputs)
+	if err != nil {
+		return nil, err
+	}
+	return gif.Geometries(), nil
+}
+
+// DoCommand exposes tool changing to clients. Supported commands are "select_tool" (with a "tool"
+// name), "begin_tool_change", "end_tool_change" (with a "tool" name), and "active_tool".
+func (tc *Arm) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case "select_tool", "end_tool_change":
+		toolName, ok := cmd["tool"].(string)
+		if !ok {
+			return nil, errors.New("need a string 'tool' value")
+		}
+		if name == "select_tool" {
+			return nil, tc.SelectTool(ctx, toolName)
+		}
+		return nil, tc.EndToolChange(ctx, toolName)
+	case "begin_tool_change":
+		return nil, tc.BeginToolChange(ctx)
+	case "active_tool":
+		tool := tc.ActiveTool()
+		if tool == nil {
+			return map[string]interface{}{"tool": ""}, nil
+		}
+		resp := map[string]interface{}{"tool": tool.Name, "mass_kg": tool.Payload.MassKg}
+		if tool.Gripper != nil {
+			resp["gripper"] = tool.Gripper.Name().ShortName()
+		}
+		return resp, nil
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
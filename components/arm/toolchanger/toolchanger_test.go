@@ -0,0 +1,141 @@
+package toolchanger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	pb "go.viam.com/api/component/arm/v1"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestToolChanger(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	armModel, err := referenceframe.ParseModelJSONFile("../xarm/xarm6_kinematics.json", "")
+	test.That(t, err, test.ShouldBeNil)
+
+	actualArm := &inject.Arm{}
+	actualArm.ModelFrameFunc = func() referenceframe.Model { return armModel }
+	actualArm.JointPositionsFunc = func(ctx context.Context, extra map[string]interface{}) (*pb.JointPositions, error) {
+		return &pb.JointPositions{Values: make([]float64, len(armModel.DoF()))}, nil
+	}
+	actualArm.StopFunc = func(ctx context.Context, extra map[string]interface{}) error { return nil }
+	moves := 0
+	actualArm.MoveToJointPositionsFunc = func(ctx context.Context, pos *pb.JointPositions, extra map[string]interface{}) error {
+		moves++
+		return nil
+	}
+
+	payloads := []arm.Payload{}
+	payloadArm := &payloadSettingArm{Arm: actualArm, payloads: &payloads}
+
+	armName := arm.Named("actual")
+	deps := resource.Dependencies{armName: payloadArm}
+	cfg := resource.Config{
+		Name: "changer",
+		ConvertedAttributes: &Config{
+			ArmName: armName.ShortName(),
+			Tools: []ToolConfig{
+				{Name: "probe", Frame: &referenceframe.LinkConfig{Translation: r3.Vector{Z: 100}}, MassKg: 0.5},
+				{Name: "drill", MassKg: 5},
+			},
+			MaxPayloadKg: 3,
+		},
+	}
+
+	a, err := NewToolChangerArm(ctx, deps, cfg, logger)
+	test.That(t, err, test.ShouldBeNil)
+	tc := a.(*Arm)
+	test.That(t, tc.ActiveTool(), test.ShouldBeNil)
+
+	flange, err := tc.EndPosition(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	t.Run("tool frame extends the kinematic chain", func(t *testing.T) {
+		test.That(t, tc.SelectTool(ctx, "probe"), test.ShouldBeNil)
+		test.That(t, tc.ActiveTool().Name, test.ShouldEqual, "probe")
+
+		tcp, err := tc.EndPosition(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		expected := spatialmath.Compose(flange, spatialmath.NewPoseFromPoint(r3.Vector{Z: 100}))
+		test.That(t, spatialmath.PoseAlmostEqual(tcp, expected), test.ShouldBeTrue)
+		test.That(t, len(tc.ModelFrame().DoF()), test.ShouldEqual, len(armModel.DoF()))
+	})
+
+	t.Run("tool payload is passed to the arm", func(t *testing.T) {
+		test.That(t, payloads, test.ShouldResemble, []arm.Payload{{}, {MassKg: 0.5}})
+	})
+
+	t.Run("tools over the payload limit are rejected", func(t *testing.T) {
+		err := tc.SelectTool(ctx, "drill")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "max payload")
+
+		// The interlock stays engaged after a failed tool change.
+		err = tc.MoveToJointPositions(ctx, &pb.JointPositions{Values: make([]float64, 6)}, nil)
+		test.That(t, err, test.ShouldEqual, errToolChangeInProgress)
+		test.That(t, moves, test.ShouldEqual, 0)
+
+		test.That(t, tc.EndToolChange(ctx, ""), test.ShouldBeNil)
+		test.That(t, tc.ActiveTool(), test.ShouldBeNil)
+	})
+
+	t.Run("motion is locked out during a tool change", func(t *testing.T) {
+		resp, err := tc.DoCommand(ctx, map[string]interface{}{"command": "begin_tool_change"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp, test.ShouldBeNil)
+
+		err = tc.MoveToJointPositions(ctx, &pb.JointPositions{Values: make([]float64, 6)}, nil)
+		test.That(t, err, test.ShouldEqual, errToolChangeInProgress)
+
+		_, err = tc.DoCommand(ctx, map[string]interface{}{"command": "end_tool_change", "tool": "probe"})
+		test.That(t, err, test.ShouldBeNil)
+		err = tc.MoveToJointPositions(ctx, &pb.JointPositions{Values: make([]float64, 6)}, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, moves, test.ShouldEqual, 1)
+
+		resp, err = tc.DoCommand(ctx, map[string]interface{}{"command": "active_tool"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp["tool"], test.ShouldEqual, "probe")
+		test.That(t, resp["mass_kg"], test.ShouldEqual, 0.5)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Config{}
+	_, err := cfg.Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "arm")
+
+	cfg = Config{ArmName: "a", Tools: []ToolConfig{{Name: "t", Gripper: "g"}, {Name: "t"}}}
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "duplicate")
+
+	cfg = Config{ArmName: "a", Tools: []ToolConfig{{Name: "t", Gripper: "g"}}, DefaultTool: "t"}
+	deps, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"a", "g"})
+}
+
+type payloadSettingArm struct {
+	*inject.Arm
+	payloads *[]arm.Payload
+}
+
+func (a *payloadSettingArm) SetPayload(ctx context.Context, payload arm.Payload) error {
+	*a.payloads = append(*a.payloads, payload)
+	return nil
+}
+
+func (a *payloadSettingArm) Payload(ctx context.Context) (arm.Payload, error) {
+	return (*a.payloads)[len(*a.payloads)-1], nil
+}
@@ -0,0 +1,294 @@
+//go:build !no_cgo
+
+package arm
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// The shapes a workspace limit can have.
+const (
+	// WorkspaceLimitBox keeps the arm inside an axis-aligned box.
+	WorkspaceLimitBox = "box"
+	// WorkspaceLimitPlane keeps the arm on the side of a plane its normal points to.
+	WorkspaceLimitPlane = "plane"
+)
+
+const (
+	// halfSpaceMM is half the size of the box standing in for the allowed side of a plane, which is
+	// far bigger than any arm can reach.
+	halfSpaceMM = 1e6
+	// workspaceCheckStep is the largest joint move, in radians or millimeters, between the points
+	// of a joint move which are checked against the limits.
+	workspaceCheckStep = 0.035
+	// maxWorkspaceCheckSteps caps how many points of a joint move are checked.
+	maxWorkspaceCheckSteps = 200
+)
+
+// WorkspaceConfig describes where an arm is allowed to go, in its base frame.
+type WorkspaceConfig struct {
+	Limits []WorkspaceLimitConfig `json:"limits"`
+	// ClipTargets moves a MoveToPosition target outside the limits to the nearest point inside them,
+	// rather than rejecting the move.
+	ClipTargets bool `json:"clip_targets,omitempty"`
+}
+
+// WorkspaceLimitConfig is one region the arm must stay in: a box given by its corners, or the side
+// of a plane given by a point on it and a normal pointing into the allowed side. Distances are in
+// millimeters.
+type WorkspaceLimitConfig struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+
+	MinMM *r3.Vector `json:"min_mm,omitempty"`
+	MaxMM *r3.Vector `json:"max_mm,omitempty"`
+
+	PointMM *r3.Vector `json:"point_mm,omitempty"`
+	Normal  *r3.Vector `json:"normal,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *WorkspaceConfig) Validate(path string) error {
+	if len(cfg.Limits) == 0 {
+		return resource.NewConfigValidationFieldRequiredError(path, "limits")
+	}
+	names := map[string]bool{}
+	for i, limit := range cfg.Limits {
+		if err := limit.Validate(fmt.Sprintf("%s.limits.%d", path, i)); err != nil {
+			return err
+		}
+		if names[limit.Name] {
+			return resource.NewConfigValidationError(path, fmt.Errorf("workspace limit %q is given twice", limit.Name))
+		}
+		names[limit.Name] = true
+	}
+	return nil
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *WorkspaceLimitConfig) Validate(path string) error {
+	if cfg.Name == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "name")
+	}
+	switch cfg.Type {
+	case WorkspaceLimitBox:
+		if cfg.MinMM == nil {
+			return resource.NewConfigValidationFieldRequiredError(path, "min_mm")
+		}
+		if cfg.MaxMM == nil {
+			return resource.NewConfigValidationFieldRequiredError(path, "max_mm")
+		}
+		if cfg.MinMM.X >= cfg.MaxMM.X || cfg.MinMM.Y >= cfg.MaxMM.Y || cfg.MinMM.Z >= cfg.MaxMM.Z {
+			return resource.NewConfigValidationError(path, errors.New("min_mm must be less than max_mm on every axis"))
+		}
+	case WorkspaceLimitPlane:
+		if cfg.PointMM == nil {
+			return resource.NewConfigValidationFieldRequiredError(path, "point_mm")
+		}
+		if cfg.Normal == nil {
+			return resource.NewConfigValidationFieldRequiredError(path, "normal")
+		}
+		if cfg.Normal.Norm() == 0 {
+			return resource.NewConfigValidationError(path, errors.New("normal cannot be zero"))
+		}
+	default:
+		return resource.NewConfigValidationError(path,
+			fmt.Errorf("type %q is not %q or %q", cfg.Type, WorkspaceLimitBox, WorkspaceLimitPlane))
+	}
+	return nil
+}
+
+// WorkspaceLimitError is returned for a move which would take the arm out of a workspace limit.
+type WorkspaceLimitError struct {
+	// Limit is the name of the limit.
+	Limit string
+	// Part is what would leave it: the target, the end effector, or one of the arm's links.
+	Part string
+}
+
+func (e *WorkspaceLimitError) Error() string {
+	return fmt.Sprintf("%s would leave workspace limit %q", e.Part, e.Limit)
+}
+
+// workspaceLimit is a limit's config, with the region it allows.
+type workspaceLimit struct {
+	cfg    WorkspaceLimitConfig
+	region spatialmath.Geometry
+}
+
+// WorkspaceLimits keeps an arm within the regions of its base frame which are safe to move in,
+// such as above a table and away from a wall. A nil WorkspaceLimits allows everything.
+type WorkspaceLimits struct {
+	limits      []workspaceLimit
+	clipTargets bool
+}
+
+// NewWorkspaceLimits returns the limits cfg describes, or nil if cfg is nil.
+func NewWorkspaceLimits(cfg *WorkspaceConfig) (*WorkspaceLimits, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	w := &WorkspaceLimits{clipTargets: cfg.ClipTargets}
+	for _, limitCfg := range cfg.Limits {
+		var region spatialmath.Geometry
+		var err error
+		switch limitCfg.Type {
+		case WorkspaceLimitBox:
+			center := limitCfg.MinMM.Add(*limitCfg.MaxMM).Mul(0.5)
+			region, err = spatialmath.NewBox(spatialmath.NewPoseFromPoint(center), limitCfg.MaxMM.Sub(*limitCfg.MinMM), limitCfg.Name)
+		case WorkspaceLimitPlane:
+			// the allowed side is a box so big that only the face on the plane matters
+			normal := limitCfg.Normal.Normalize()
+			region, err = spatialmath.NewBox(
+				spatialmath.NewPose(
+					limitCfg.PointMM.Add(normal.Mul(halfSpaceMM)),
+					&spatialmath.OrientationVector{OX: normal.X, OY: normal.Y, OZ: normal.Z},
+				),
+				r3.Vector{X: 2 * halfSpaceMM, Y: 2 * halfSpaceMM, Z: 2 * halfSpaceMM},
+				limitCfg.Name,
+			)
+		default:
+			err = fmt.Errorf("unknown workspace limit type %q", limitCfg.Type)
+		}
+		if err != nil {
+			return nil, err
+		}
+		w.limits = append(w.limits, workspaceLimit{cfg: limitCfg, region: region})
+	}
+	return w, nil
+}
+
+// violated returns the name of the first limit the geometry isn't within, or "" if it is within
+// all of them.
+func (w *WorkspaceLimits) violated(g spatialmath.Geometry) (string, error) {
+	for _, limit := range w.limits {
+		inside, err := g.EncompassedBy(limit.region)
+		if err != nil {
+			// geometries which can't be compared, such as meshes, are checked by their centers
+			if inside, err = spatialmath.NewPoint(g.Pose().Point(), "").EncompassedBy(limit.region); err != nil {
+				return "", err
+			}
+		}
+		if !inside {
+			return limit.cfg.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// TargetPose returns the pose to move to for a MoveToPosition target: the target itself if it is
+// within the limits, or if not, the nearest point within them if targets are clipped. Otherwise it
+// returns a WorkspaceLimitError.
+func (w *WorkspaceLimits) TargetPose(target spatialmath.Pose) (spatialmath.Pose, error) {
+	if w == nil {
+		return target, nil
+	}
+	point := target.Point()
+	if w.clipTargets {
+		for _, limit := range w.limits {
+			point = limit.clip(point)
+		}
+	}
+	name, err := w.violated(spatialmath.NewPoint(point, ""))
+	if err != nil {
+		return nil, err
+	}
+	if name != "" {
+		return nil, &WorkspaceLimitError{Limit: name, Part: "the target position"}
+	}
+	return spatialmath.NewPose(point, target.Orientation()), nil
+}
+
+// clip returns the point within the limit nearest to pt.
+func (limit workspaceLimit) clip(pt r3.Vector) r3.Vector {
+	switch limit.cfg.Type {
+	case WorkspaceLimitBox:
+		return r3.Vector{
+			X: math.Min(math.Max(pt.X, limit.cfg.MinMM.X), limit.cfg.MaxMM.X),
+			Y: math.Min(math.Max(pt.Y, limit.cfg.MinMM.Y), limit.cfg.MaxMM.Y),
+			Z: math.Min(math.Max(pt.Z, limit.cfg.MinMM.Z), limit.cfg.MaxMM.Z),
+		}
+	case WorkspaceLimitPlane:
+		normal := limit.cfg.Normal.Normalize()
+		if depth := pt.Sub(*limit.cfg.PointMM).Dot(normal); depth < 0 {
+			return pt.Sub(normal.Mul(depth))
+		}
+	}
+	return pt
+}
+
+// CheckInputs returns a WorkspaceLimitError if the end effector or any link of the model would be
+// outside the limits at the given inputs.
+func (w *WorkspaceLimits) CheckInputs(model referenceframe.Model, inputs []referenceframe.Input) error {
+	if w == nil {
+		return nil
+	}
+	pose, err := model.Transform(inputs)
+	if err != nil {
+		return err
+	}
+	name, err := w.violated(spatialmath.NewPoint(pose.Point(), ""))
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		return &WorkspaceLimitError{Limit: name, Part: "the end effector"}
+	}
+
+	gif, err := model.Geometries(inputs)
+	if err != nil {
+		return err
+	}
+	for _, g := range gif.Geometries() {
+		name, err := w.violated(g)
+		if err != nil {
+			return err
+		}
+		if name != "" {
+			return &WorkspaceLimitError{Limit: name, Part: fmt.Sprintf("link %q", g.Label())}
+		}
+	}
+	return nil
+}
+
+// CheckJointMove returns a WorkspaceLimitError if moving the model's joints straight from one set of
+// inputs to another would take any part of it outside the limits on the way.
+func (w *WorkspaceLimits) CheckJointMove(model referenceframe.Model, from, to []referenceframe.Input) error {
+	if w == nil {
+		return nil
+	}
+	if len(from) != len(to) {
+		return fmt.Errorf("expected %d joint positions but got %d", len(from), len(to))
+	}
+
+	maxDiff := 0.
+	for i := range to {
+		maxDiff = math.Max(maxDiff, math.Abs(to[i].Value-from[i].Value))
+	}
+	steps := int(math.Ceil(maxDiff / workspaceCheckStep))
+	if steps < 1 {
+		steps = 1
+	} else if steps > maxWorkspaceCheckSteps {
+		steps = maxWorkspaceCheckSteps
+	}
+
+	step := make([]referenceframe.Input, len(to))
+	for s := 1; s <= steps; s++ {
+		by := float64(s) / float64(steps)
+		for i := range to {
+			step[i] = referenceframe.Input{Value: from[i].Value + (to[i].Value-from[i].Value)*by}
+		}
+		if err := w.CheckInputs(model, step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -26,6 +26,9 @@ type Config struct {
 	Port         int     `json:"port,omitempty"`
 	Speed        float32 `json:"speed_degs_per_sec,omitempty"`
 	Acceleration float32 `json:"acceleration_degs_per_sec_per_sec,omitempty"`
+
+	// Workspace keeps the arm within the given regions of its base frame.
+	Workspace *arm.WorkspaceConfig `json:"workspace,omitempty"`
 }
 
 // Validate validates the config.
@@ -33,6 +36,11 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 	if cfg.Host == "" {
 		return nil, resource.NewConfigValidationFieldRequiredError(path, "host")
 	}
+	if cfg.Workspace != nil {
+		if err := cfg.Workspace.Validate(fmt.Sprintf("%s.%s", path, "workspace")); err != nil {
+			return nil, err
+		}
+	}
 	return []string{}, nil
 }
 
@@ -53,9 +61,12 @@ type xArm struct {
 	opMgr    *operation.SingleOperationManager
 	logger   logging.Logger
 
-	mu    sync.RWMutex
-	conn  net.Conn
-	speed float32 // speed=max joint radians per second
+	mu           sync.RWMutex
+	conn         net.Conn
+	speed        float32 // speed=max joint radians per second
+	payload      arm.Payload
+	maxPayloadKg float64
+	workspace    *arm.WorkspaceLimits
 }
 
 //go:embed xarm6_kinematics.json
@@ -73,6 +84,13 @@ const (
 	ModelNameLite = "lite6" // ModelNameLite is the name of a UFactory Lite 6
 )
 
+// ratedPayloadKg is the maximum payload of each model, taken from the UFactory spec sheets.
+var ratedPayloadKg = map[string]float64{
+	ModelName6DOF: 5,
+	ModelName7DOF: 3.5,
+	ModelNameLite: 0.6,
+}
+
 // MakeModelFrame returns the kinematics model of the xarm arm, which has all Frame information.
 func MakeModelFrame(name, modelName string) (referenceframe.Model, error) {
 	switch modelName {
@@ -120,6 +138,8 @@ func NewxArm(ctx context.Context, conf resource.Config, logger logging.Logger, m
 		started: false,
 		opMgr:   operation.NewSingleOperationManager(),
 		logger:  logger,
+
+		maxPayloadKg: ratedPayloadKg[modelName],
 	}
 
 	if err := xA.Reconfigure(ctx, nil, conf); err != nil {
@@ -148,6 +168,11 @@ func (x *xArm) Reconfigure(ctx context.Context, deps resource.Dependencies, conf
 		return fmt.Errorf("given speed %f cannot be negative", speed)
 	}
 
+	workspace, err := arm.NewWorkspaceLimits(newConf.Workspace)
+	if err != nil {
+		return err
+	}
+
 	port := fmt.Sprintf("%d", newConf.Port)
 	if newConf.Port == 0 {
 		port = defaultPort
@@ -177,6 +202,7 @@ func (x *xArm) Reconfigure(ctx context.Context, deps resource.Dependencies, conf
 	}
 
 	x.speed = float32(utils.DegToRad(float64(speed)))
+	x.workspace = workspace
 	return nil
 }
 
@@ -214,6 +240,50 @@ func (x *xArm) Geometries(ctx context.Context, extra map[string]interface{}) ([]
 	return gif.Geometries(), nil
 }
 
+// DoCommand supports arm.SetPayloadCommand, arm.GetPayloadCommand, arm.GetJointEffortsCommand, and
+// arm.GuardedMoveCommand, whose efforts are joint torques in Nm.
+func (x *xArm) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case arm.SetPayloadCommand:
+		payload, err := arm.PayloadFromCommand(cmd)
+		if err != nil {
+			return nil, err
+		}
+		if x.maxPayloadKg > 0 && payload.MassKg > x.maxPayloadKg {
+			return nil, fmt.Errorf("payload of %.3fkg exceeds the rated payload of %.3fkg", payload.MassKg, x.maxPayloadKg)
+		}
+		return nil, x.SetPayload(ctx, payload)
+	case arm.GetPayloadCommand:
+		payload, err := x.Payload(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return arm.PayloadToCommandResponse(payload), nil
+	case arm.GetJointEffortsCommand:
+		efforts, err := x.JointEfforts(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return arm.JointEffortsToCommandResponse(efforts), nil
+	case arm.GuardedMoveCommand:
+		target, maxEfforts, err := arm.GuardedMoveFromCommand(cmd, x.dof)
+		if err != nil {
+			return nil, err
+		}
+		result, err := arm.GuardedMove(ctx, x, target, maxEfforts)
+		if err != nil {
+			return nil, err
+		}
+		return arm.GuardResultToCommandResponse(result), nil
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
+
 // ModelFrame returns all the information necessary for including the arm in a FrameSystem.
 func (x *xArm) ModelFrame() referenceframe.Model {
 	return x.model
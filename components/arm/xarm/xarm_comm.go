@@ -99,8 +99,10 @@ var regMap = map[string]byte{
 	"ToggleBrake": 0x12,
 	"SetMode":     0x13,
 	"MoveJoints":  0x1D,
+	"SetLoad":     0x23,
 	"ZeroJoints":  0x19,
 	"JointPos":    0x2A,
+	"JointTorque": 0x37,
 	"SetBound":    0x34,
 	"EnableBound": 0x34,
 	"SetEEModel":  0x4E,
@@ -366,10 +368,18 @@ func (x *xArm) MoveToJointPositions(ctx context.Context, newPositions *pb.JointP
 	}
 	from := x.model.InputFromProtobuf(curPos)
 
+	x.mu.RLock()
+	workspace := x.workspace
+	x.mu.RUnlock()
+	if err := workspace.CheckJointMove(x.model, from, to); err != nil {
+		return err
+	}
+
 	diff := getMaxDiff(from, to)
 	x.mu.RLock()
-	nSteps := int((diff / float64(x.speed)) * x.moveHZ)
+	speed := float64(x.speed) * arm.PayloadSpeedScale(x.payload, x.maxPayloadKg)
 	x.mu.RUnlock()
+	nSteps := int((diff / speed) * x.moveHZ)
 
 	// convenience for structuring and sending individual joint steps
 	sendMoveJointsCmd := func(ctx context.Context, step []float64) error {
@@ -422,6 +432,34 @@ func (x *xArm) MoveToJointPositions(ctx context.Context, newPositions *pb.JointP
 	return nil
 }
 
+// SetPayload tells the control box what the arm is carrying, so it can compensate for the load,
+// and slows down future moves to match.
+func (x *xArm) SetPayload(ctx context.Context, payload arm.Payload) error {
+	c := x.newCmd(regMap["SetLoad"])
+	floatBytes := make([]byte, 4)
+	for _, val := range []float64{
+		payload.MassKg, payload.CenterOfGravity.X, payload.CenterOfGravity.Y, payload.CenterOfGravity.Z,
+	} {
+		binary.LittleEndian.PutUint32(floatBytes, math.Float32bits(float32(val)))
+		c.params = append(c.params, floatBytes...)
+	}
+	if _, err := x.send(ctx, c, true); err != nil {
+		return err
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.payload = payload
+	return nil
+}
+
+// Payload returns the payload most recently given to SetPayload.
+func (x *xArm) Payload(ctx context.Context) (arm.Payload, error) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.payload, nil
+}
+
 // EndPosition computes and returns the current cartesian position.
 func (x *xArm) EndPosition(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
 	joints, err := x.JointPositions(ctx, extra)
@@ -440,6 +478,13 @@ func (x *xArm) MoveToPosition(ctx context.Context, pos spatialmath.Pose, extra m
 			return err
 		}
 	}
+	x.mu.RLock()
+	workspace := x.workspace
+	x.mu.RUnlock()
+	pos, err := workspace.TargetPose(pos)
+	if err != nil {
+		return err
+	}
 	if err := arm.Move(ctx, x.logger, x, pos); err != nil {
 		return err
 	}
@@ -467,6 +512,22 @@ func (x *xArm) JointPositions(ctx context.Context, extra map[string]interface{})
 	return referenceframe.JointPositionsFromRadians(radians), nil
 }
 
+// JointEfforts returns the torque of each joint, as estimated by the control box, in Nm.
+func (x *xArm) JointEfforts(ctx context.Context) (arm.JointEfforts, error) {
+	c := x.newCmd(regMap["JointTorque"])
+
+	tData, err := x.send(ctx, c, true)
+	if err != nil {
+		return arm.JointEfforts{}, err
+	}
+	efforts := arm.JointEfforts{Units: "Nm"}
+	for i := 0; i < x.dof; i++ {
+		idx := i*4 + 1
+		efforts.Values = append(efforts.Values, float64(rutils.Float32FromBytesLE((tData.params[idx : idx+4]))))
+	}
+	return efforts, nil
+}
+
 // Stop stops the xArm but also reinitializes the arm so it can take commands again.
 func (x *xArm) Stop(ctx context.Context, extra map[string]interface{}) error {
 	ctx, done := x.opMgr.New(ctx)
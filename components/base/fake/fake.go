@@ -3,6 +3,9 @@ package fake
 
 import (
 	"context"
+	"math"
+	"sync"
+	"time"
 
 	"github.com/golang/geo/r3"
 
@@ -10,6 +13,7 @@ import (
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
 )
 
 func init() {
@@ -26,7 +30,8 @@ const (
 	defaultWheelCircumferenceM   = 3
 )
 
-// Base is a fake base that returns what it was provided in each method.
+// Base is a fake base that returns what it was provided in each method. It keeps track of where
+// it would have driven to, which simulated sensors can read with Pose.
 type Base struct {
 	resource.Named
 	resource.TriviallyReconfigurable
@@ -36,6 +41,14 @@ type Base struct {
 	WheelCircumferenceMeters float64
 	Geometry                 []spatialmath.Geometry
 	logger                   logging.Logger
+
+	mu sync.Mutex
+	// pose is relative to where the base started, with +Y forwards. nil means it hasn't moved.
+	pose spatialmath.Pose
+	// linear (mm/s) and angular (deg/s) are the velocities most recently set with SetVelocity.
+	linear     r3.Vector
+	angular    r3.Vector
+	lastUpdate time.Time
 }
 
 // NewBase instantiates a new base of the fake model type.
@@ -57,13 +70,28 @@ func NewBase(_ context.Context, _ resource.Dependencies, conf resource.Config, l
 	return b, nil
 }
 
-// MoveStraight does nothing.
+// MoveStraight instantly moves the simulated base forwards.
 func (b *Base) MoveStraight(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.updatePoseLocked()
+	if mmPerSec < 0 {
+		distanceMm = -distanceMm
+	}
+	b.pose = spatialmath.Compose(b.currentPoseLocked(), spatialmath.NewPoseFromPoint(r3.Vector{Y: float64(distanceMm)}))
 	return nil
 }
 
-// Spin does nothing.
+// Spin instantly turns the simulated base counterclockwise.
 func (b *Base) Spin(ctx context.Context, angleDeg, degsPerSec float64, extra map[string]interface{}) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.updatePoseLocked()
+	if degsPerSec < 0 {
+		angleDeg = -angleDeg
+	}
+	b.pose = spatialmath.Compose(b.currentPoseLocked(),
+		spatialmath.NewPoseFromOrientation(&spatialmath.OrientationVectorDegrees{OZ: 1, Theta: angleDeg}))
 	return nil
 }
 
@@ -72,19 +100,74 @@ func (b *Base) SetPower(ctx context.Context, linear, angular r3.Vector, extra ma
 	return nil
 }
 
-// SetVelocity does nothing.
+// SetVelocity starts the simulated base driving at the given velocities until they are changed.
 func (b *Base) SetVelocity(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.updatePoseLocked()
+	b.linear = linear
+	b.angular = angular
 	return nil
 }
 
-// Stop does nothing.
+// Stop stops the simulated base.
 func (b *Base) Stop(ctx context.Context, extra map[string]interface{}) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.updatePoseLocked()
+	b.linear = r3.Vector{}
+	b.angular = r3.Vector{}
 	return nil
 }
 
-// IsMoving always returns false.
+// Pose returns where the simulated base has driven to, relative to where it started.
+func (b *Base) Pose() spatialmath.Pose {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.updatePoseLocked()
+	return b.currentPoseLocked()
+}
+
+func (b *Base) currentPoseLocked() spatialmath.Pose {
+	if b.pose == nil {
+		return spatialmath.NewZeroPose()
+	}
+	return b.pose
+}
+
+// updatePoseLocked advances the simulated pose by however far the base would have driven at its
+// current velocities since the last update. Only forwards motion and turning about Z are simulated.
+func (b *Base) updatePoseLocked() {
+	now := time.Now()
+	dt := now.Sub(b.lastUpdate).Seconds()
+	if b.lastUpdate.IsZero() {
+		dt = 0
+	}
+	b.lastUpdate = now
+	if dt == 0 || (b.linear.Y == 0 && b.angular.Z == 0) {
+		return
+	}
+
+	distance := b.linear.Y * dt
+	turnDeg := b.angular.Z * dt
+	var delta r3.Vector
+	if turnDeg == 0 {
+		delta = r3.Vector{Y: distance}
+	} else {
+		// Drive along an arc around a center of rotation on the base's X axis.
+		turnRad := utils.DegToRad(turnDeg)
+		radius := distance / turnRad
+		delta = r3.Vector{X: radius * (math.Cos(turnRad) - 1), Y: radius * math.Sin(turnRad)}
+	}
+	b.pose = spatialmath.Compose(b.currentPoseLocked(),
+		spatialmath.NewPose(delta, &spatialmath.OrientationVectorDegrees{OZ: 1, Theta: turnDeg}))
+}
+
+// IsMoving returns whether the simulated base is driving at a nonzero velocity.
 func (b *Base) IsMoving(ctx context.Context) (bool, error) {
-	return false, nil
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.linear.Y != 0 || b.angular.Z != 0, nil
 }
 
 // Close does nothing.
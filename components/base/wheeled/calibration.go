@@ -0,0 +1,237 @@
+package wheeled
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/movementsensor"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+const (
+	defaultCalibrationDistanceMm = 2000
+	defaultCalibrationMmPerSec   = 200
+	defaultCalibrationDegsPerSec = 45
+	calibrationSamplePeriod      = 50 * time.Millisecond
+)
+
+// DoCommand supports a "calibrate" command, which drives the base in known patterns while measuring
+// how it actually moves with the calibration movement sensor, then corrects its dimensions to match:
+//
+//	{"command": "calibrate", "distance_mm": 2000, "mm_per_sec": 200, "degs_per_sec": 45}
+//
+// All arguments besides "command" are optional. The corrected dimensions are used until the base is
+// next reconfigured, and are returned under "config" so that they can be saved to its config.
+func (wb *wheeledBase) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, _ := cmd["command"].(string)
+	if name != "calibrate" {
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+
+	distanceMm := defaultCalibrationDistanceMm
+	if v, ok := cmd["distance_mm"].(float64); ok {
+		distanceMm = int(v)
+	}
+	mmPerSec := float64(defaultCalibrationMmPerSec)
+	if v, ok := cmd["mm_per_sec"].(float64); ok {
+		mmPerSec = v
+	}
+	degsPerSec := float64(defaultCalibrationDegsPerSec)
+	if v, ok := cmd["degs_per_sec"].(float64); ok {
+		degsPerSec = v
+	}
+	if distanceMm <= 0 || mmPerSec <= 0 || degsPerSec <= 0 {
+		return nil, errors.New("distance_mm, mm_per_sec, and degs_per_sec must be positive")
+	}
+	return wb.calibrate(ctx, distanceMm, mmPerSec, degsPerSec)
+}
+
+// calibrate corrects the wheel circumference by driving straight, the width by turning at a constant
+// angular velocity, and the spin slip factor by spinning, in that order since each correction relies
+// on the ones before it.
+func (wb *wheeledBase) calibrate(ctx context.Context, distanceMm int, mmPerSec, degsPerSec float64) (map[string]interface{}, error) {
+	wb.mu.Lock()
+	ms := wb.calibrationSensor
+	wb.mu.Unlock()
+	if ms == nil {
+		return nil, errors.New("calibrating requires calibration_movement_sensor to be configured")
+	}
+	props, err := ms.Properties(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !props.PositionSupported && !props.OrientationSupported && !props.CompassHeadingSupported {
+		return nil, errors.Errorf("movement sensor %q reports neither position nor heading", ms.Name().ShortName())
+	}
+
+	resp := map[string]interface{}{}
+	if props.PositionSupported {
+		measured, err := wb.measureStraight(ctx, ms, distanceMm, mmPerSec)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to measure driving straight")
+		}
+		if measured < 1 {
+			return nil, errors.New("base did not move when driving straight")
+		}
+		wb.mu.Lock()
+		wb.wheelCircumferenceMm = int(math.Round(float64(wb.wheelCircumferenceMm) * measured / float64(distanceMm)))
+		wb.mu.Unlock()
+		resp["measured_distance_mm"] = measured
+	} else {
+		wb.logger.CWarnw(ctx, "movement sensor does not report position; not calibrating wheel circumference",
+			"movement_sensor", ms.Name().ShortName())
+	}
+
+	if props.OrientationSupported || props.CompassHeadingSupported {
+		turnFor := time.Duration(360 / degsPerSec * float64(time.Second))
+		measured, err := wb.measureRotation(ctx, ms, props, func(ctx context.Context) error {
+			if err := wb.SetVelocity(ctx, r3.Vector{}, r3.Vector{Z: degsPerSec}, nil); err != nil {
+				return err
+			}
+			if !goutils.SelectContextOrWait(ctx, turnFor) {
+				return wb.stopAfterInterrupt(ctx, ctx.Err())
+			}
+			return wb.Stop(ctx, nil)
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to measure turning")
+		}
+		if measured < 1 {
+			return nil, errors.New("base did not turn")
+		}
+		wb.mu.Lock()
+		wb.widthMm = int(math.Round(float64(wb.widthMm) * 360 / measured))
+		wb.mu.Unlock()
+		resp["measured_turn_degs"] = measured
+
+		measured, err = wb.measureRotation(ctx, ms, props, func(ctx context.Context) error {
+			return wb.Spin(ctx, 360, degsPerSec, nil)
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to measure spinning")
+		}
+		if measured < 1 {
+			return nil, errors.New("base did not spin")
+		}
+		wb.mu.Lock()
+		wb.spinSlipFactor *= 360 / measured
+		wb.mu.Unlock()
+		resp["measured_spin_degs"] = measured
+	} else {
+		wb.logger.CWarnw(ctx, "movement sensor does not report heading; not calibrating width or spin slip factor",
+			"movement_sensor", ms.Name().ShortName())
+	}
+
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	resp["config"] = map[string]interface{}{
+		"wheel_circumference_mm": wb.wheelCircumferenceMm,
+		"width_mm":               wb.widthMm,
+		"spin_slip_factor":       wb.spinSlipFactor,
+	}
+	wb.logger.CInfow(ctx, "calibrated base; save the corrected attributes to its config to keep them",
+		"wheel_circumference_mm", wb.wheelCircumferenceMm, "width_mm", wb.widthMm, "spin_slip_factor", wb.spinSlipFactor)
+	return resp, nil
+}
+
+// stopAfterInterrupt stops the base after a move was interrupted by err.
+func (wb *wheeledBase) stopAfterInterrupt(ctx context.Context, err error) error {
+	return multierr.Combine(err, wb.Stop(context.WithoutCancel(ctx), nil))
+}
+
+// measureStraight drives the base straight and returns how far the movement sensor's position moved, in mm.
+func (wb *wheeledBase) measureStraight(
+	ctx context.Context, ms movementsensor.MovementSensor, distanceMm int, mmPerSec float64,
+) (float64, error) {
+	start, _, err := ms.Position(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	if err := wb.MoveStraight(ctx, distanceMm, mmPerSec, nil); err != nil {
+		return 0, err
+	}
+	end, _, err := ms.Position(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	// GreatCircleDistance is in km
+	return start.GreatCircleDistance(end) * 1e6, nil
+}
+
+// measureRotation runs move while following the movement sensor's heading, and returns how many
+// degrees counterclockwise the base turned in total.
+func (wb *wheeledBase) measureRotation(
+	ctx context.Context,
+	ms movementsensor.MovementSensor,
+	props *movementsensor.Properties,
+	move func(ctx context.Context) error,
+) (float64, error) {
+	heading := func() (float64, error) {
+		if props.OrientationSupported {
+			o, err := ms.Orientation(ctx, nil)
+			if err != nil {
+				return 0, err
+			}
+			return rdkutils.RadToDeg(o.EulerAngles().Yaw), nil
+		}
+		// compass headings increase clockwise
+		h, err := ms.CompassHeading(ctx, nil)
+		return -h, err
+	}
+
+	prev, err := heading()
+	if err != nil {
+		return 0, err
+	}
+	var turned float64
+	sample := func() error {
+		curr, err := heading()
+		if err != nil {
+			return err
+		}
+		turned += signedAngleDiffDeg(prev, curr)
+		prev = curr
+		return nil
+	}
+
+	moveCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	moveErr := make(chan error, 1)
+	goutils.PanicCapturingGo(func() {
+		moveErr <- move(moveCtx)
+	})
+
+	ticker := time.NewTicker(calibrationSamplePeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-moveErr:
+			if err != nil {
+				return 0, err
+			}
+			if err := sample(); err != nil {
+				return 0, err
+			}
+			return turned, nil
+		case <-ticker.C:
+			if err := sample(); err != nil {
+				cancel()
+				<-moveErr
+				return 0, wb.stopAfterInterrupt(ctx, err)
+			}
+		}
+	}
+}
+
+// signedAngleDiffDeg returns the smallest angle, in degrees, which turns from to to, positive when
+// turning counterclockwise.
+func signedAngleDiffDeg(from, to float64) float64 {
+	return rdkutils.ModAngDeg(to-from+180) - 180
+}
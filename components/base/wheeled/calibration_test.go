@@ -0,0 +1,63 @@
+package wheeled
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+func TestSignedAngleDiffDeg(t *testing.T) {
+	test.That(t, signedAngleDiffDeg(10, 30), test.ShouldAlmostEqual, 20)
+	test.That(t, signedAngleDiffDeg(30, 10), test.ShouldAlmostEqual, -20)
+	test.That(t, signedAngleDiffDeg(170, -170), test.ShouldAlmostEqual, 20)
+	test.That(t, signedAngleDiffDeg(-170, 170), test.ShouldAlmostEqual, -20)
+	test.That(t, signedAngleDiffDeg(350, 5), test.ShouldAlmostEqual, 15)
+}
+
+func TestMeasureRotation(t *testing.T) {
+	ctx := context.Background()
+	wb := &wheeledBase{logger: logging.NewTestLogger(t)}
+
+	// the sensor turns 20 degrees counterclockwise every time it is read, wrapping around at 180
+	var reads atomic.Int64
+	ms := inject.NewMovementSensor("imu")
+	ms.OrientationFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+		yaw := rdkutils.ModAngDeg(float64(reads.Add(1)-1)*20+180) - 180
+		return &spatialmath.EulerAngles{Yaw: rdkutils.DegToRad(yaw)}, nil
+	}
+	props := &movementsensor.Properties{OrientationSupported: true}
+
+	measured, err := wb.measureRotation(ctx, ms, props, func(ctx context.Context) error {
+		// wait until the sensor has been read 19 times, so it has turned 360 degrees
+		for reads.Load() < 19 {
+			time.Sleep(calibrationSamplePeriod / 4)
+		}
+		return nil
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, measured, test.ShouldAlmostEqual, float64(reads.Load()-1)*20)
+	test.That(t, measured, test.ShouldBeGreaterThanOrEqualTo, 360)
+}
+
+func TestCalibrateRequiresSensor(t *testing.T) {
+	wb := &wheeledBase{logger: logging.NewTestLogger(t)}
+	_, err := wb.DoCommand(context.Background(), map[string]interface{}{"command": "calibrate"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "calibration_movement_sensor")
+
+	_, err = wb.DoCommand(context.Background(), map[string]interface{}{"command": "calibrate", "distance_mm": -1.0})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, err = wb.DoCommand(context.Background(), map[string]interface{}{"command": "bogus"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no such command")
+}
@@ -26,6 +26,7 @@ package wheeled
        "spin_slip_factor": 1.76,
        "wheel_circumference_mm": 217,
        "width_mm": 260,
+       "calibration_movement_sensor": "myGPS"
      },
      "depends_on": ["left1", "left2", "right1", "right2", "local"],
    },
@@ -43,6 +44,7 @@ import (
 
 	"go.viam.com/rdk/components/base"
 	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/movementsensor"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/operation"
 	"go.viam.com/rdk/resource"
@@ -60,6 +62,9 @@ type Config struct {
 	SpinSlipFactor       float64  `json:"spin_slip_factor,omitempty"`
 	Left                 []string `json:"left"`
 	Right                []string `json:"right"`
+	// CalibrationMovementSensor optionally names a movement sensor whose position or heading the
+	// "calibrate" command measures the base's motion with.
+	CalibrationMovementSensor string `json:"calibration_movement_sensor,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -89,6 +94,9 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 
 	deps = append(deps, cfg.Left...)
 	deps = append(deps, cfg.Right...)
+	if cfg.CalibrationMovementSensor != "" {
+		deps = append(deps, cfg.CalibrationMovementSensor)
+	}
 
 	return deps, nil
 }
@@ -108,6 +116,8 @@ type wheeledBase struct {
 	right     []motor.Motor
 	allMotors []motor.Motor
 
+	calibrationSensor movementsensor.MovementSensor
+
 	opMgr  *operation.SingleOperationManager
 	logger logging.Logger
 
@@ -194,6 +204,15 @@ func (wb *wheeledBase) Reconfigure(ctx context.Context, deps resource.Dependenci
 	wb.allMotors = append(wb.allMotors, wb.left...)
 	wb.allMotors = append(wb.allMotors, wb.right...)
 
+	wb.calibrationSensor = nil
+	if newConf.CalibrationMovementSensor != "" {
+		ms, err := movementsensor.FromDependencies(deps, newConf.CalibrationMovementSensor)
+		if err != nil {
+			return errors.Wrapf(err, "no calibration movement sensor named (%s)", newConf.CalibrationMovementSensor)
+		}
+		wb.calibrationSensor = ms
+	}
+
 	if wb.widthMm != newConf.WidthMM {
 		wb.widthMm = newConf.WidthMM
 	}
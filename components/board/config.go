@@ -1,6 +1,10 @@
 package board
 
-import "go.viam.com/rdk/resource"
+import (
+	"errors"
+
+	"go.viam.com/rdk/resource"
+)
 
 // SPIConfig enumerates a specific, shareable SPI bus.
 type SPIConfig struct {
@@ -39,6 +43,21 @@ type AnalogReaderConfig struct {
 	Pin               string `json:"pin"`
 	AverageOverMillis int    `json:"average_over_ms,omitempty"`
 	SamplesPerSecond  int    `json:"samples_per_sec,omitempty"`
+	// Oversample is how many raw readings are averaged into each sample.
+	Oversample int `json:"oversample,omitempty"`
+	// LowPassCutoffHz filters the samples with a first order low-pass filter.
+	LowPassCutoffHz float64 `json:"low_pass_cutoff_hz,omitempty"`
+	// Calibration converts readings into engineering units, such as newtons or volts of battery,
+	// by linear interpolation between its points. Calibrated values are reported in steps of
+	// CalibrationResolution, which defaults to 0.001, so that value * step_size is in those units.
+	Calibration           []AnalogCalibrationPoint `json:"calibration,omitempty"`
+	CalibrationResolution float64                  `json:"calibration_resolution,omitempty"`
+}
+
+// AnalogCalibrationPoint maps a raw analog reading to the value it stands for.
+type AnalogCalibrationPoint struct {
+	Raw   float64 `json:"raw"`
+	Value float64 `json:"value"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -46,6 +65,29 @@ func (config *AnalogReaderConfig) Validate(path string) error {
 	if config.Name == "" {
 		return resource.NewConfigValidationFieldRequiredError(path, "name")
 	}
+	return config.ValidateProcessing(path)
+}
+
+// ValidateProcessing ensures the oversampling, filtering, and calibration of the analog reader are
+// valid, for boards whose analog configs are converted into an AnalogReaderConfig.
+func (config *AnalogReaderConfig) ValidateProcessing(path string) error {
+	if config.Oversample < 0 {
+		return resource.NewConfigValidationError(path, errors.New("oversample cannot be negative"))
+	}
+	if config.LowPassCutoffHz < 0 {
+		return resource.NewConfigValidationError(path, errors.New("low_pass_cutoff_hz cannot be negative"))
+	}
+	if config.CalibrationResolution < 0 {
+		return resource.NewConfigValidationError(path, errors.New("calibration_resolution cannot be negative"))
+	}
+	if len(config.Calibration) == 1 {
+		return resource.NewConfigValidationError(path, errors.New("calibration needs at least 2 points"))
+	}
+	for i := 1; i < len(config.Calibration); i++ {
+		if config.Calibration[i].Raw <= config.Calibration[i-1].Raw {
+			return resource.NewConfigValidationError(path, errors.New("calibration points must be in increasing order of raw"))
+		}
+	}
 	return nil
 }
 
@@ -11,7 +11,6 @@ import (
 	"path/filepath"
 
 	"go.uber.org/multierr"
-	"periph.io/x/host/v3"
 
 	"go.viam.com/rdk/components/board"
 	"go.viam.com/rdk/components/board/genericlinux"
@@ -22,10 +21,6 @@ import (
 const modelName = "customlinux"
 
 func init() {
-	if _, err := host.Init(); err != nil {
-		logging.Global().Debugw("error initializing host", "error", err)
-	}
-
 	resource.RegisterComponent(
 		board.API,
 		resource.DefaultModelFamily.WithModel(modelName),
@@ -63,6 +58,7 @@ func pinDefsFromFile(conf resource.Config, logger logging.Logger) (*genericlinux
 
 	return &genericlinux.LinuxBoardConfig{
 		GpioMappings: gpioMappings,
+		I2cMuxes:     newConf.I2cMuxes,
 	}, nil
 }
 
@@ -5,11 +5,14 @@ package customlinux
 
 import (
 	"os"
+
+	"go.viam.com/rdk/components/board/genericlinux"
 )
 
 // A Config describes the configuration of a board and all of its connected parts.
 type Config struct {
-	BoardDefsFilePath string `json:"board_defs_file_path"`
+	BoardDefsFilePath string                      `json:"board_defs_file_path"`
+	I2cMuxes          []genericlinux.I2cMuxConfig `json:"i2c_muxes,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -19,5 +22,8 @@ func (conf *Config) Validate(path string) ([]string, error) {
 	}
 	// Should we read in and validate the board defs in here?
 
+	if err := genericlinux.ValidateI2cMuxes(path, conf.I2cMuxes); err != nil {
+		return nil, err
+	}
 	return nil, nil
 }
@@ -16,6 +16,11 @@ type Tick struct {
 	TimestampNanosec uint64
 }
 
+// StreamTicksCoalesceIntervalKey can be set in the extra parameters of StreamTicks on a remote
+// board, to have the server send at most one tick per pin per interval (given in milliseconds)
+// rather than every tick. The tick that is sent is the most recent one in that interval.
+const StreamTicksCoalesceIntervalKey = "coalesce_interval_ms"
+
 // A DigitalInterrupt represents a configured interrupt on the board that
 // when interrupted, calls the added callbacks.
 type DigitalInterrupt interface {
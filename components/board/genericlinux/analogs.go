@@ -4,16 +4,29 @@ package genericlinux
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.viam.com/utils"
 
 	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/board/mcp3008helper"
 	"go.viam.com/rdk/components/board/pinwrappers"
 	"go.viam.com/rdk/grpc"
 )
 
+const (
+	// By default an analog stream delivers a chunk every 100ms, and keeps 5 seconds of chunks.
+	defaultAnalogStreamChunksPerSecond = 10
+	defaultAnalogStreamBufferSeconds   = 5
+)
+
+// Streams which go unread for this long are assumed to be abandoned by their client, and stopped.
+var analogStreamIdleTimeout = 30 * time.Second
+
 type wrappedAnalogReader struct {
 	mu         sync.RWMutex
 	chipSelect string
@@ -35,6 +48,16 @@ func (a *wrappedAnalogReader) Read(ctx context.Context, extra map[string]interfa
 	return a.reader.Read(ctx, extra)
 }
 
+// raw returns the unsmoothed reader, for streaming.
+func (a *wrappedAnalogReader) raw() (board.Analog, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.reader == nil {
+		return nil, errors.New("closed")
+	}
+	return a.reader.Raw, nil
+}
+
 func (a *wrappedAnalogReader) Close(ctx context.Context) error {
 	return a.reader.Close(ctx)
 }
@@ -52,3 +75,130 @@ func (a *wrappedAnalogReader) reset(ctx context.Context, chipSelect string, read
 func (a *wrappedAnalogReader) Write(ctx context.Context, value int, extra map[string]interface{}) error {
 	return grpc.UnimplementedError
 }
+
+// startAnalogStream starts streaming the raw samples of an analog at a fixed rate, for the
+// "start_analog_stream" command. It takes the "analog" to stream, its "sample_rate_hz", and
+// optionally the "chunk_samples" delivered together and the "max_chunks" kept until they are read.
+func (b *Board) startAnalogStream(cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, _ := cmd["analog"].(string)
+	b.mu.RLock()
+	analog, ok := b.analogReaders[name]
+	b.mu.RUnlock()
+	if !ok {
+		return nil, errors.Errorf("can't find AnalogReader (%s)", name)
+	}
+	raw, err := analog.raw()
+	if err != nil {
+		return nil, err
+	}
+
+	rate, ok := cmd["sample_rate_hz"].(float64)
+	if !ok || rate <= 0 || rate > mcp3008helper.MaxSamplesPerSecond {
+		return nil, fmt.Errorf("sample_rate_hz must be a number between 0 and %d, not %v",
+			mcp3008helper.MaxSamplesPerSecond, cmd["sample_rate_hz"])
+	}
+	chunkSize := int(math.Max(1, math.Round(rate/defaultAnalogStreamChunksPerSecond)))
+	if v, ok := cmd["chunk_samples"]; ok {
+		size, ok := v.(float64)
+		if !ok || size < 1 {
+			return nil, fmt.Errorf("chunk_samples must be a positive number, not %v", v)
+		}
+		chunkSize = int(size)
+	}
+	maxChunks := int(math.Max(1, math.Ceil(defaultAnalogStreamBufferSeconds*rate/float64(chunkSize))))
+	if v, ok := cmd["max_chunks"]; ok {
+		chunks, ok := v.(float64)
+		if !ok || chunks < 1 {
+			return nil, fmt.Errorf("max_chunks must be a positive number, not %v", v)
+		}
+		maxChunks = int(chunks)
+	}
+
+	stream, err := pinwrappers.StreamAnalog(raw, rate, chunkSize, maxChunks, b.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	b.analogStreamsMu.Lock()
+	defer b.analogStreamsMu.Unlock()
+	b.closeIdleAnalogStreams()
+	b.nextAnalogStream++
+	id := fmt.Sprintf("%s-%d", name, b.nextAnalogStream)
+	b.analogStreams[id] = stream
+	return map[string]interface{}{
+		"stream":         id,
+		"sample_rate_hz": rate,
+		"chunk_samples":  chunkSize,
+	}, nil
+}
+
+// readAnalogStream returns the chunks collected by the "stream" since it was last read, for the
+// "read_analog_stream" command. Each chunk holds raw ADC counts, and the Unix time in nanoseconds at
+// which its first sample was taken.
+func (b *Board) readAnalogStream(cmd map[string]interface{}) (map[string]interface{}, error) {
+	id, _ := cmd["stream"].(string)
+	b.analogStreamsMu.Lock()
+	stream, ok := b.analogStreams[id]
+	b.analogStreamsMu.Unlock()
+	if !ok {
+		return nil, errors.Errorf("no analog stream %q; it may have been stopped for going unread", id)
+	}
+
+	chunks, status := stream.Read()
+	result := map[string]interface{}{
+		"sample_rate_hz": stream.SampleRateHz,
+		"dropped_chunks": status.DroppedChunks,
+		"overruns":       status.Overruns,
+	}
+	encoded := make([]interface{}, 0, len(chunks))
+	for _, chunk := range chunks {
+		samples := make([]interface{}, len(chunk.Samples))
+		for i, sample := range chunk.Samples {
+			samples[i] = sample
+		}
+		encoded = append(encoded, map[string]interface{}{
+			"start_unix_nanos": chunk.Start.UnixNano(),
+			"samples":          samples,
+		})
+	}
+	result["chunks"] = encoded
+	if status.LastError != nil {
+		result["error"] = status.LastError.Error()
+	}
+	return result, nil
+}
+
+// stopAnalogStream stops the "stream", for the "stop_analog_stream" command.
+func (b *Board) stopAnalogStream(cmd map[string]interface{}) (map[string]interface{}, error) {
+	id, _ := cmd["stream"].(string)
+	b.analogStreamsMu.Lock()
+	defer b.analogStreamsMu.Unlock()
+	stream, ok := b.analogStreams[id]
+	if !ok {
+		return nil, errors.Errorf("no analog stream %q", id)
+	}
+	stream.Close()
+	delete(b.analogStreams, id)
+	return map[string]interface{}{}, nil
+}
+
+// closeIdleAnalogStreams stops streams whose clients seem to have gone away. It must be called
+// while holding analogStreamsMu.
+func (b *Board) closeIdleAnalogStreams() {
+	for id, stream := range b.analogStreams {
+		if stream.IdleFor() > analogStreamIdleTimeout {
+			b.logger.Infow("stopping unread analog stream", "stream", id)
+			stream.Close()
+			delete(b.analogStreams, id)
+		}
+	}
+}
+
+func (b *Board) closeAnalogStreams() {
+	b.analogStreamsMu.Lock()
+	defer b.analogStreamsMu.Unlock()
+	for id, stream := range b.analogStreams {
+		stream.Close()
+		delete(b.analogStreams, id)
+	}
+}
@@ -63,6 +63,7 @@ func NewBoard(
 		analogReaders: map[string]*wrappedAnalogReader{},
 		gpios:         map[string]*gpioPin{},
 		interrupts:    map[string]*digitalInterrupt{},
+		analogStreams: map[string]*pinwrappers.AnalogStream{},
 	}
 
 	if err := b.Reconfigure(ctx, nil, conf); err != nil {
@@ -85,6 +86,14 @@ func (b *Board) Reconfigure(
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	b.swPwmMaxFreqHz = newConf.SoftwarePWMMaxFreqHz
+	if b.swPwmMaxFreqHz == 0 {
+		b.swPwmMaxFreqHz = defaultMaxSoftwarePwmFreqHz
+	}
+	for _, pin := range b.gpios {
+		pin.setSoftwarePwmMaxFreq(b.swPwmMaxFreqHz)
+	}
+
 	if err := b.reconfigureGpios(newConf); err != nil {
 		return err
 	}
@@ -94,6 +103,31 @@ func (b *Board) Reconfigure(
 	if err := b.reconfigureInterrupts(newConf); err != nil {
 		return err
 	}
+	return b.reconfigureI2cMuxes(newConf)
+}
+
+// reconfigureI2cMuxes replaces the board's I2C muxes with the newly configured ones. Drivers look
+// their mux up each time they use a channel, so they carry on with the replacement.
+func (b *Board) reconfigureI2cMuxes(newConf *LinuxBoardConfig) error {
+	var err error
+	for _, unregister := range b.i2cMuxUnregisters {
+		err = multierr.Combine(err, unregister())
+	}
+	b.i2cMuxUnregisters = nil
+	if err != nil {
+		return err
+	}
+	for _, c := range newConf.I2cMuxes {
+		addr := c.I2cAddr
+		if addr == 0 {
+			addr = DefaultI2cMuxAddr
+		}
+		unregister, err := buses.RegisterI2cMux(c.I2cBus, c.Name, byte(addr))
+		if err != nil {
+			return err
+		}
+		b.i2cMuxUnregisters = append(b.i2cMuxUnregisters, unregister)
+	}
 	return nil
 }
 
@@ -211,17 +245,13 @@ func (b *Board) reconfigureAnalogReaders(ctx context.Context, newConf *LinuxBoar
 			if curr.chipSelect != c.ChipSelect {
 				ar := &mcp3008helper.MCP3008AnalogReader{channel, bus, c.ChipSelect}
 				curr.reset(ctx, curr.chipSelect,
-					pinwrappers.SmoothAnalogReader(ar, board.AnalogReaderConfig{
-						AverageOverMillis: c.AverageOverMillis, SamplesPerSecond: c.SamplesPerSecond,
-					}, b.logger))
+					pinwrappers.SmoothAnalogReader(ar, c.AnalogReaderConfig(), b.logger))
 			}
 			continue
 		}
 		ar := &mcp3008helper.MCP3008AnalogReader{channel, bus, c.ChipSelect}
 		b.analogReaders[c.Name] = newWrappedAnalogReader(ctx, c.ChipSelect,
-			pinwrappers.SmoothAnalogReader(ar, board.AnalogReaderConfig{
-				AverageOverMillis: c.AverageOverMillis, SamplesPerSecond: c.SamplesPerSecond,
-			}, b.logger))
+			pinwrappers.SmoothAnalogReader(ar, c.AnalogReaderConfig(), b.logger))
 	}
 
 	for name := range b.analogReaders {
@@ -345,6 +375,8 @@ func (b *Board) createGpioPin(mapping GPIOBoardMapping) *gpioPin {
 		offset:       uint32(mapping.GPIO),
 		cancelCtx:    b.cancelCtx,
 		logger:       b.logger,
+
+		swPwmMaxFreqHz: b.swPwmMaxFreqHz,
 	}
 	if mapping.HWPWMSupported {
 		pin.hwPwm = newPwmDevice(mapping.PWMSysFsDir, mapping.PWMID, b.logger)
@@ -365,6 +397,14 @@ type Board struct {
 	gpios      map[string]*gpioPin
 	interrupts map[string]*digitalInterrupt
 
+	swPwmMaxFreqHz uint
+
+	i2cMuxUnregisters []func() error
+
+	analogStreamsMu  sync.Mutex
+	analogStreams    map[string]*pinwrappers.AnalogStream
+	nextAnalogStream int
+
 	cancelCtx               context.Context
 	cancelFunc              func()
 	activeBackgroundWorkers sync.WaitGroup
@@ -453,6 +493,44 @@ func (b *Board) GPIOPinByName(pinName string) (board.GPIOPin, error) {
 	return nil, errors.Errorf("cannot find GPIO for unknown pin: %s", pinName)
 }
 
+// DoCommand executes additional commands beyond the Board{} interface. "software_pwm_jitter" reports
+// how accurately each software PWM loop is keeping time, and "start_analog_stream",
+// "read_analog_stream", and "stop_analog_stream" sample an analog faster than it can be read
+// one reading at a time.
+func (b *Board) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case "software_pwm_jitter":
+		b.mu.RLock()
+		defer b.mu.RUnlock()
+
+		result := map[string]interface{}{}
+		for pinName, pin := range b.gpios {
+			stats, running := pin.softwarePwmJitter()
+			if !running {
+				continue
+			}
+			result[pinName] = map[string]interface{}{
+				"samples":        stats.samples,
+				"mean_jitter_us": stats.meanJitter().Microseconds(),
+				"max_jitter_us":  stats.maxJitter.Microseconds(),
+			}
+		}
+		return result, nil
+	case "start_analog_stream":
+		return b.startAnalogStream(cmd)
+	case "read_analog_stream":
+		return b.readAnalogStream(cmd)
+	case "stop_analog_stream":
+		return b.stopAnalogStream(cmd)
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
+
 // SetPowerMode sets the board to the given power mode. If provided,
 // the board will exit the given power mode after the specified
 // duration.
@@ -502,6 +580,7 @@ func (b *Board) Close(ctx context.Context) error {
 	b.cancelFunc()
 	b.mu.Unlock()
 	b.activeBackgroundWorkers.Wait()
+	b.closeAnalogStreams()
 
 	var err error
 	for _, pin := range b.gpios {
@@ -513,5 +592,8 @@ func (b *Board) Close(ctx context.Context) error {
 	for _, reader := range b.analogReaders {
 		err = multierr.Combine(err, reader.Close(ctx))
 	}
+	for _, unregister := range b.i2cMuxUnregisters {
+		err = multierr.Combine(err, unregister())
+	}
 	return err
 }
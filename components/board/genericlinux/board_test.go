@@ -9,13 +9,17 @@ package genericlinux
 import (
 	"context"
 	"testing"
+	"time"
 
 	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
 
 	"go.viam.com/rdk/components/board"
 	"go.viam.com/rdk/components/board/mcp3008helper"
+	"go.viam.com/rdk/components/board/pinwrappers"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
 )
 
 func TestGenericLinux(t *testing.T) {
@@ -144,3 +148,80 @@ func TestNewBoard(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, gn2, test.ShouldNotBeNil)
 }
+
+func TestSoftwarePWMLimits(t *testing.T) {
+	ctx := context.Background()
+
+	pin := &gpioPin{
+		devicePath:     "gpiochip0",
+		offset:         1,
+		swPwmMaxFreqHz: 100,
+	}
+
+	err := pin.SetPWMFreq(ctx, 200, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "software PWM limit")
+
+	freq, err := pin.PWMFreq(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, freq, test.ShouldEqual, 0)
+
+	_, running := pin.softwarePwmJitter()
+	test.That(t, running, test.ShouldBeFalse)
+
+	var stats pwmJitterStats
+	test.That(t, stats.meanJitter(), test.ShouldEqual, 0)
+	stats.record(10 * time.Microsecond)
+	stats.record(-30 * time.Microsecond)
+	test.That(t, stats.samples, test.ShouldEqual, 2)
+	test.That(t, stats.meanJitter(), test.ShouldEqual, 20*time.Microsecond)
+	test.That(t, stats.maxJitter, test.ShouldEqual, 30*time.Microsecond)
+}
+
+func TestAnalogStreamCommands(t *testing.T) {
+	ctx := context.Background()
+	raw := &inject.Analog{}
+	raw.ReadFunc = func(ctx context.Context, extra map[string]interface{}) (board.AnalogValue, error) {
+		return board.AnalogValue{Value: 512}, nil
+	}
+	b := &Board{
+		logger: logging.NewTestLogger(t),
+		analogReaders: map[string]*wrappedAnalogReader{
+			"current": newWrappedAnalogReader(ctx, "1", &pinwrappers.AnalogSmoother{Raw: raw}),
+		},
+		analogStreams: map[string]*pinwrappers.AnalogStream{},
+	}
+	defer b.closeAnalogStreams()
+
+	for _, cmd := range []map[string]interface{}{
+		{"command": "start_analog_stream", "analog": "voltage", "sample_rate_hz": 1000.0},
+		{"command": "start_analog_stream", "analog": "current"},
+		{"command": "start_analog_stream", "analog": "current", "sample_rate_hz": 1e6},
+		{"command": "start_analog_stream", "analog": "current", "sample_rate_hz": 1000.0, "chunk_samples": 0.0},
+	} {
+		_, err := b.DoCommand(ctx, cmd)
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+
+	resp, err := b.DoCommand(ctx, map[string]interface{}{
+		"command": "start_analog_stream", "analog": "current", "sample_rate_hz": 1000.0,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["chunk_samples"], test.ShouldEqual, 100)
+	stream := resp["stream"]
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		resp, err := b.DoCommand(ctx, map[string]interface{}{"command": "read_analog_stream", "stream": stream})
+		test.That(tb, err, test.ShouldBeNil)
+		chunks := resp["chunks"].([]interface{})
+		test.That(tb, chunks, test.ShouldNotBeEmpty)
+		samples := chunks[0].(map[string]interface{})["samples"].([]interface{})
+		test.That(tb, samples[0], test.ShouldEqual, 512)
+	})
+
+	_, err = b.DoCommand(ctx, map[string]interface{}{"command": "stop_analog_stream", "stream": stream})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = b.DoCommand(ctx, map[string]interface{}{"command": "read_analog_stream", "stream": stream})
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -8,11 +8,13 @@ import (
 	"context"
 	"sync"
 
+	"go.opencensus.io/trace"
 	"periph.io/x/conn/v3/i2c"
 	"periph.io/x/conn/v3/i2c/i2creg"
 	"periph.io/x/host/v3"
 
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
 )
 
 func init() {
@@ -26,16 +28,19 @@ func init() {
 // again, so that you can only communicate with 1 device on the bus at a time.
 type i2cBus struct {
 	// Despite the type name BusCloser, this is the I2C bus itself (plus a way to close itself when
-	// it's done, though we never use that because we want to keep it open until the entire process
-	// exits)!
+	// it's done, which only shared buses do once every resource using them has released them)!
 	closeableBus i2c.BusCloser
 	mu           sync.Mutex
 	deviceName   string
 }
 
 // NewI2cBus creates a new I2C (the public interface) object (implemented as the private i2cBus
-// struct).
+// struct). The bus does not coordinate with any other opened on the same device, so drivers should
+// prefer AcquireI2cBus. deviceName may also name a channel of a mux, such as "1.mux0.ch3".
 func NewI2cBus(deviceName string) (I2C, error) {
+	if muxChannel, ok, err := muxChannelBus(deviceName); ok {
+		return muxChannel, err
+	}
 	b := &i2cBus{}
 	if err := b.reset(deviceName); err != nil {
 		return nil, err
@@ -43,6 +48,40 @@ func NewI2cBus(deviceName string) (I2C, error) {
 	return b, nil
 }
 
+// AcquireI2cBus returns the I2C bus which every resource using deviceName shares, so that handles
+// opened by different drivers lock each other out rather than talking over one another. The
+// returned release function must be called once the bus is no longer needed, and closes the bus
+// once nothing else holds it.
+func AcquireI2cBus(deviceName string) (I2C, func() error, error) {
+	if muxChannel, ok, err := muxChannelBus(deviceName); ok {
+		if err != nil {
+			return nil, nil, err
+		}
+		// mux channels coordinate through their mux, so need no sharing of their own
+		return muxChannel, func() error { return nil }, nil
+	}
+	return resource.AcquireShared("i2c:"+deviceName, func() (I2C, func() error, error) {
+		b := &i2cBus{}
+		if err := b.reset(deviceName); err != nil {
+			return nil, nil, err
+		}
+		return b, b.close, nil
+	})
+}
+
+// close closes the bus itself, once nothing is left to use it.
+func (bus *i2cBus) close() error {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	if bus.closeableBus == nil {
+		return nil
+	}
+	err := bus.closeableBus.Close()
+	bus.closeableBus = nil
+	return err
+}
+
 func (bus *i2cBus) reset(deviceName string) error {
 	bus.mu.Lock()
 	defer bus.mu.Unlock()
@@ -87,12 +126,16 @@ type I2cHandle struct { // Implements the I2CHandle interface
 // Write writes the given bytes to the handle. For I2C devices that organize their data into
 // registers, prefer using WriteBlockData instead.
 func (h *I2cHandle) Write(ctx context.Context, tx []byte) error {
+	_, span := h.startSpan(ctx, "Write")
+	defer span.End()
 	return h.device.Tx(tx, nil)
 }
 
 // Read reads the given number of bytes from the handle. For I2C devices that organize their data
 // into registers, prefer using ReadBlockData instead.
 func (h *I2cHandle) Read(ctx context.Context, count int) ([]byte, error) {
+	_, span := h.startSpan(ctx, "Read")
+	defer span.End()
 	buffer := make([]byte, count)
 	err := h.device.Tx(nil, buffer)
 	if err != nil {
@@ -101,8 +144,22 @@ func (h *I2cHandle) Read(ctx context.Context, count int) ([]byte, error) {
 	return buffer, nil
 }
 
+// startSpan traces one transaction, so that slow devices show up in the traces of the calls using
+// them.
+func (h *I2cHandle) startSpan(ctx context.Context, transaction string) (context.Context, *trace.Span) {
+	ctx, span := trace.StartSpan(ctx, "buses::I2cHandle::"+transaction)
+	span.AddAttributes(
+		trace.StringAttribute("bus", h.parentBus.deviceName),
+		trace.Int64Attribute("address", int64(h.device.Addr)),
+	)
+	return ctx, span
+}
+
 // This is a private helper function, used to implement the rest of the I2CHandle interface.
-func (h *I2cHandle) transactAtRegister(register byte, w, r []byte) error {
+func (h *I2cHandle) transactAtRegister(ctx context.Context, register byte, w, r []byte) error {
+	_, span := h.startSpan(ctx, "transactAtRegister")
+	defer span.End()
+	span.AddAttributes(trace.Int64Attribute("register", int64(register)))
 	if w == nil {
 		w = []byte{}
 	}
@@ -115,7 +172,7 @@ func (h *I2cHandle) transactAtRegister(register byte, w, r []byte) error {
 // ReadByteData reads a single byte from the given register on this I2C device.
 func (h *I2cHandle) ReadByteData(ctx context.Context, register byte) (byte, error) {
 	result := make([]byte, 1)
-	err := h.transactAtRegister(register, nil, result)
+	err := h.transactAtRegister(ctx, register, nil, result)
 	if err != nil {
 		return 0, err
 	}
@@ -124,14 +181,14 @@ func (h *I2cHandle) ReadByteData(ctx context.Context, register byte) (byte, erro
 
 // WriteByteData writes a single byte to the given register on this I2C device.
 func (h *I2cHandle) WriteByteData(ctx context.Context, register, data byte) error {
-	return h.transactAtRegister(register, []byte{data}, nil)
+	return h.transactAtRegister(ctx, register, []byte{data}, nil)
 }
 
 // ReadBlockData reads the given number of bytes from the I2C device, starting at the given
 // register.
 func (h *I2cHandle) ReadBlockData(ctx context.Context, register byte, numBytes uint8) ([]byte, error) {
 	result := make([]byte, numBytes)
-	err := h.transactAtRegister(register, nil, result)
+	err := h.transactAtRegister(ctx, register, nil, result)
 	if err != nil {
 		return nil, err
 	}
@@ -140,7 +197,7 @@ func (h *I2cHandle) ReadBlockData(ctx context.Context, register byte, numBytes u
 
 // WriteBlockData writes the given bytes into the given register on the I2C device.
 func (h *I2cHandle) WriteBlockData(ctx context.Context, register byte, data []byte) error {
-	return h.transactAtRegister(register, data, nil)
+	return h.transactAtRegister(ctx, register, data, nil)
 }
 
 // Close closes the handle to the device, and unlocks the I2C bus.
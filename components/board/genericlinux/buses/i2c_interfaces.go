@@ -1,4 +1,4 @@
-// Package buses offers SPI and I2C buses for generic Linux systems.
+// Package buses offers SPI, I2C, and 1-Wire buses for generic Linux systems.
 package buses
 
 import (
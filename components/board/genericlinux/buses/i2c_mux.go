@@ -0,0 +1,149 @@
+//go:build linux
+
+// This file is for I2C multiplexers, such as the TCA9548A, which switch an upstream I2C bus
+// between several downstream channels so that devices with the same address can share a bus.
+
+package buses
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+// I2cMuxChannels is the number of downstream channels on a TCA9548A.
+const I2cMuxChannels = 8
+
+// A downstream channel of a mux is used as an I2C bus named "<upstream bus>.<mux name>.ch<channel>",
+// such as "1.mux0.ch3".
+var muxChannelRegex = regexp.MustCompile(`^(.+\.[^.]+)\.ch(\d+)$`)
+
+// MuxChannelBusName returns the name of the I2C bus of one channel of the named mux.
+func MuxChannelBusName(bus, muxName string, channel int) string {
+	return fmt.Sprintf("%s.%s.ch%d", bus, muxName, channel)
+}
+
+type i2cMux struct {
+	// mu is held from selecting a channel until the handle on that channel is closed, so that
+	// nothing can switch channels underneath it.
+	mu       sync.Mutex
+	bus      I2C
+	addr     byte
+	selected int
+}
+
+var (
+	muxesMu sync.Mutex
+	muxes   = map[string]*i2cMux{}
+)
+
+// RegisterI2cMux makes the channels of the mux at addr on the given bus available as I2C buses
+// named by MuxChannelBusName. The returned function unregisters the mux again.
+func RegisterI2cMux(bus, muxName string, addr byte) (func() error, error) {
+	upstream, releaseBus, err := AcquireI2cBus(bus)
+	if err != nil {
+		return nil, err
+	}
+	unregister, err := registerI2cMux(bus+"."+muxName, upstream, addr)
+	if err != nil {
+		return nil, multierr.Combine(err, releaseBus())
+	}
+	return func() error {
+		unregister()
+		return releaseBus()
+	}, nil
+}
+
+func registerI2cMux(key string, upstream I2C, addr byte) (func(), error) {
+	muxesMu.Lock()
+	defer muxesMu.Unlock()
+	if _, ok := muxes[key]; ok {
+		return nil, errors.Errorf("i2c mux %q is already configured", key)
+	}
+	mux := &i2cMux{bus: upstream, addr: addr, selected: -1}
+	muxes[key] = mux
+	return func() {
+		muxesMu.Lock()
+		defer muxesMu.Unlock()
+		if muxes[key] == mux {
+			delete(muxes, key)
+		}
+	}, nil
+}
+
+// muxChannelBus returns the bus for deviceName if it names a mux channel.
+func muxChannelBus(deviceName string) (I2C, bool, error) {
+	match := muxChannelRegex.FindStringSubmatch(deviceName)
+	if match == nil {
+		return nil, false, nil
+	}
+	channel, err := strconv.Atoi(match[2])
+	if err != nil || channel >= I2cMuxChannels {
+		return nil, true, errors.Errorf("i2c mux channel in %q must be from 0 to %d", deviceName, I2cMuxChannels-1)
+	}
+	return &i2cMuxChannel{muxKey: match[1], channel: channel}, true, nil
+}
+
+// i2cMuxChannel is one downstream channel of a mux. The mux is looked up each time a handle is
+// opened, since the board configuring the mux may be built after the drivers using it.
+type i2cMuxChannel struct {
+	muxKey  string
+	channel int
+}
+
+// OpenHandle selects the channel and returns a handle to the device at addr on it. The mux stays
+// on the channel until the handle is closed.
+func (c *i2cMuxChannel) OpenHandle(addr byte) (I2CHandle, error) {
+	muxesMu.Lock()
+	mux, ok := muxes[c.muxKey]
+	muxesMu.Unlock()
+	if !ok {
+		return nil, errors.Errorf("i2c mux %q is not configured on any board", c.muxKey)
+	}
+
+	mux.mu.Lock()
+	if mux.selected != c.channel {
+		if err := mux.selectChannel(c.channel); err != nil {
+			mux.mu.Unlock()
+			return nil, err
+		}
+	}
+	handle, err := mux.bus.OpenHandle(addr)
+	if err != nil {
+		mux.mu.Unlock()
+		return nil, err
+	}
+	return &i2cMuxHandle{I2CHandle: handle, mux: mux}, nil
+}
+
+func (mux *i2cMux) selectChannel(channel int) error {
+	handle, err := mux.bus.OpenHandle(mux.addr)
+	if err != nil {
+		return err
+	}
+	// the TCA9548A has a single control register, with a bit enabling each channel
+	err = handle.Write(context.Background(), []byte{1 << channel})
+	if err = multierr.Combine(err, handle.Close()); err != nil {
+		// the mux may be on any channel, or none, so select the channel again next time
+		mux.selected = -1
+		return errors.Wrapf(err, "failed to select i2c mux channel %d", channel)
+	}
+	mux.selected = channel
+	return nil
+}
+
+type i2cMuxHandle struct {
+	I2CHandle
+	mux *i2cMux
+}
+
+// Close closes the handle, and lets the mux switch channels again.
+func (h *i2cMuxHandle) Close() error {
+	defer h.mux.mu.Unlock()
+	return h.I2CHandle.Close()
+}
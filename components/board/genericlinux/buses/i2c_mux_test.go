@@ -0,0 +1,88 @@
+//go:build linux
+
+package buses
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+// fakeBus records every write made to it, as "<address>:<bytes>".
+type fakeBus struct {
+	writes   []string
+	writeErr error
+}
+
+func (b *fakeBus) OpenHandle(addr byte) (I2CHandle, error) {
+	return &fakeHandle{bus: b, addr: addr}, nil
+}
+
+type fakeHandle struct {
+	I2CHandle
+	bus  *fakeBus
+	addr byte
+}
+
+func (h *fakeHandle) Write(ctx context.Context, tx []byte) error {
+	if h.bus.writeErr != nil {
+		return h.bus.writeErr
+	}
+	h.bus.writes = append(h.bus.writes, fmt.Sprintf("%#x:%v", h.addr, tx))
+	return nil
+}
+
+func (h *fakeHandle) Close() error {
+	return nil
+}
+
+func TestI2cMux(t *testing.T) {
+	ctx := context.Background()
+	upstream := &fakeBus{}
+	unregister, err := registerI2cMux("1.mux0", upstream, 0x71)
+	test.That(t, err, test.ShouldBeNil)
+	defer unregister()
+
+	_, err = registerI2cMux("1.mux0", upstream, 0x72)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	test.That(t, MuxChannelBusName("1", "mux0", 3), test.ShouldEqual, "1.mux0.ch3")
+	ch3, err := NewI2cBus("1.mux0.ch3")
+	test.That(t, err, test.ShouldBeNil)
+	ch5, release, err := AcquireI2cBus("1.mux0.ch5")
+	test.That(t, err, test.ShouldBeNil)
+	defer release()
+
+	// the channel is only selected when it changes
+	for _, bus := range []I2C{ch3, ch3, ch5} {
+		handle, err := bus.OpenHandle(0x29)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, handle.Write(ctx, []byte{1}), test.ShouldBeNil)
+		test.That(t, handle.Close(), test.ShouldBeNil)
+	}
+	test.That(t, upstream.writes, test.ShouldResemble, []string{
+		"0x71:[8]", "0x29:[1]", "0x29:[1]", "0x71:[32]", "0x29:[1]",
+	})
+
+	// a failed switch is tried again next time
+	upstream.writeErr = errors.New("nack")
+	_, err = ch3.OpenHandle(0x29)
+	test.That(t, err, test.ShouldNotBeNil)
+	upstream.writeErr = nil
+	upstream.writes = nil
+	handle, err := ch5.OpenHandle(0x29)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, handle.Close(), test.ShouldBeNil)
+	test.That(t, upstream.writes, test.ShouldResemble, []string{"0x71:[32]"})
+
+	_, err = NewI2cBus("1.mux0.ch8")
+	test.That(t, err, test.ShouldNotBeNil)
+	missing, err := NewI2cBus("1.mux1.ch0")
+	test.That(t, err, test.ShouldBeNil)
+	_, err = missing.OpenHandle(0x29)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "not configured")
+}
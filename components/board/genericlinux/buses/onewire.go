@@ -0,0 +1,77 @@
+package buses
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// OneWireSysfsDir is where the kernel's w1 driver exposes the devices it has found on any 1-Wire
+// bus. Unlike I2C and SPI, the kernel does all the bus arbitration for 1-Wire, so there is no bus
+// object to lock: each device is just a directory of files.
+const OneWireSysfsDir = "/sys/bus/w1/devices"
+
+// OneWireDevice is a single device on a 1-Wire bus, as seen through the w1 sysfs interface.
+type OneWireDevice struct {
+	// Family is the 1-Wire family code of the device, as a 2-digit hex string (e.g., "28" for the
+	// DS18B20 temperature sensor).
+	Family string
+	// ID is the 48-bit serial number of the device, as a 12-digit hex string.
+	ID string
+
+	sysfsDir string
+}
+
+// NewOneWireDevice returns a OneWireDevice for the given family and serial number. It does not
+// check that the device is actually present on the bus; reads will fail if it is not.
+func NewOneWireDevice(family, id string) *OneWireDevice {
+	return &OneWireDevice{Family: family, ID: id, sysfsDir: OneWireSysfsDir}
+}
+
+// ListOneWireDevices returns every device the kernel has found that belongs to the given family.
+// If family is empty, devices of all families are returned.
+func ListOneWireDevices(family string) ([]*OneWireDevice, error) {
+	return listOneWireDevices(OneWireSysfsDir, family)
+}
+
+// This is separate from ListOneWireDevices so it can be tested against a fake sysfs directory.
+func listOneWireDevices(sysfsDir, family string) ([]*OneWireDevice, error) {
+	entries, err := os.ReadDir(sysfsDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot list 1-Wire devices; is the w1-gpio overlay enabled?")
+	}
+
+	devices := []*OneWireDevice{}
+	for _, entry := range entries {
+		// Device directories are named "<family>-<id>". The bus masters themselves show up here
+		// too (e.g., "w1_bus_master1"), and don't contain a dash.
+		devFamily, id, ok := strings.Cut(entry.Name(), "-")
+		if !ok {
+			continue
+		}
+		if family != "" && devFamily != family {
+			continue
+		}
+		devices = append(devices, &OneWireDevice{Family: devFamily, ID: id, sysfsDir: sysfsDir})
+	}
+	return devices, nil
+}
+
+// Path returns the sysfs directory for this device.
+func (d *OneWireDevice) Path() string {
+	return filepath.Join(d.sysfsDir, fmt.Sprintf("%s-%s", d.Family, d.ID))
+}
+
+// ReadSlave returns the contents of the device's w1_slave file. Reading this file causes the
+// kernel to perform a full transaction with the device, which can take most of a second for some
+// devices (a DS18B20 at 12-bit resolution takes up to 750ms).
+func (d *OneWireDevice) ReadSlave() ([]byte, error) {
+	data, err := os.ReadFile(filepath.Clean(filepath.Join(d.Path(), "w1_slave")))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read 1-Wire device %s-%s", d.Family, d.ID)
+	}
+	return data, nil
+}
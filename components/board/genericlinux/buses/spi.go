@@ -9,6 +9,7 @@ import (
 	"sync/atomic"
 
 	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
 	"go.uber.org/multierr"
 	"periph.io/x/conn/v3/physic"
 	"periph.io/x/conn/v3/spi"
@@ -50,6 +51,10 @@ func (sb *spiBus) reset(bus string) {
 }
 
 func (sh *spiHandle) Xfer(ctx context.Context, baud uint, chipSelect string, mode uint, tx []byte) (rx []byte, err error) {
+	_, span := trace.StartSpan(ctx, "buses::spiHandle::Xfer")
+	defer span.End()
+	span.AddAttributes(trace.StringAttribute("chip_select", chipSelect))
+
 	if sh.isClosed {
 		return nil, errors.New("can't use Xfer() on an already closed SPIHandle")
 	}
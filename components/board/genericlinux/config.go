@@ -1,7 +1,9 @@
 package genericlinux
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"go.viam.com/rdk/components/board"
 	"go.viam.com/rdk/components/board/mcp3008helper"
@@ -13,6 +15,10 @@ import (
 type Config struct {
 	AnalogReaders     []mcp3008helper.MCP3008AnalogConfig `json:"analogs,omitempty"`
 	DigitalInterrupts []board.DigitalInterruptConfig      `json:"digital_interrupts,omitempty"`
+	// SoftwarePWMMaxFreqHz caps the frequency of PWM signals on pins without hardware PWM
+	// support. If unset, a conservative default is used.
+	SoftwarePWMMaxFreqHz uint           `json:"software_pwm_max_freq_hz,omitempty"`
+	I2cMuxes             []I2cMuxConfig `json:"i2c_muxes,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -27,9 +33,57 @@ func (conf *Config) Validate(path string) ([]string, error) {
 			return nil, err
 		}
 	}
+	if err := ValidateI2cMuxes(path, conf.I2cMuxes); err != nil {
+		return nil, err
+	}
 	return nil, nil
 }
 
+// I2cMuxConfig describes an I2C multiplexer, such as a TCA9548A, on one of the board's I2C buses.
+// Each of its channels can then be used as an I2C bus named "<i2c_bus>.<name>.ch<channel>", such as
+// "1.mux0.ch3", so that several devices with the same address can be used at once.
+type I2cMuxConfig struct {
+	Name    string `json:"name"`
+	I2cBus  string `json:"i2c_bus"`
+	I2cAddr int    `json:"i2c_addr,omitempty"`
+}
+
+// DefaultI2cMuxAddr is the address of a TCA9548A with none of its address pins pulled high.
+const DefaultI2cMuxAddr = 0x70
+
+// Validate ensures all parts of the config are valid.
+func (config *I2cMuxConfig) Validate(path string) error {
+	if config.Name == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "name")
+	}
+	if strings.Contains(config.Name, ".") {
+		return resource.NewConfigValidationError(path, errors.New("name cannot contain a ."))
+	}
+	if config.I2cBus == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "i2c_bus")
+	}
+	if config.I2cAddr != 0 && (config.I2cAddr < DefaultI2cMuxAddr || config.I2cAddr > DefaultI2cMuxAddr+7) {
+		return resource.NewConfigValidationError(path, errors.New("i2c_addr must be from 0x70 to 0x77"))
+	}
+	return nil
+}
+
+// ValidateI2cMuxes validates each of a board's I2C muxes, and that no two of them share a name.
+func ValidateI2cMuxes(path string, muxes []I2cMuxConfig) error {
+	names := map[string]bool{}
+	for idx, c := range muxes {
+		muxPath := fmt.Sprintf("%s.%s.%d", path, "i2c_muxes", idx)
+		if err := c.Validate(muxPath); err != nil {
+			return err
+		}
+		if names[c.I2cBus+"."+c.Name] {
+			return resource.NewConfigValidationError(muxPath, fmt.Errorf("duplicate mux name %q", c.Name))
+		}
+		names[c.I2cBus+"."+c.Name] = true
+	}
+	return nil
+}
+
 // LinuxBoardConfig is a struct containing absolutely everything a genericlinux board might need
 // configured. It is a union of the configs for the customlinux boards and the genericlinux boards
 // with static pin definitions, because those components all use the same underlying code but have
@@ -38,9 +92,11 @@ func (conf *Config) Validate(path string) ([]string, error) {
 // go through reconfiguration, we convert the provided config into a LinuxBoardConfig, and then
 // reconfigure based on it.
 type LinuxBoardConfig struct {
-	AnalogReaders     []mcp3008helper.MCP3008AnalogConfig
-	DigitalInterrupts []board.DigitalInterruptConfig
-	GpioMappings      map[string]GPIOBoardMapping
+	AnalogReaders        []mcp3008helper.MCP3008AnalogConfig
+	DigitalInterrupts    []board.DigitalInterruptConfig
+	GpioMappings         map[string]GPIOBoardMapping
+	SoftwarePWMMaxFreqHz uint
+	I2cMuxes             []I2cMuxConfig
 }
 
 // ConfigConverter is a type synonym for a function to turn whatever config we get during
@@ -61,9 +117,11 @@ func ConstPinDefs(gpioMappings map[string]GPIOBoardMapping) ConfigConverter {
 		}
 
 		return &LinuxBoardConfig{
-			AnalogReaders:     newConf.AnalogReaders,
-			DigitalInterrupts: newConf.DigitalInterrupts,
-			GpioMappings:      gpioMappings,
+			AnalogReaders:        newConf.AnalogReaders,
+			DigitalInterrupts:    newConf.DigitalInterrupts,
+			GpioMappings:         gpioMappings,
+			SoftwarePWMMaxFreqHz: newConf.SoftwarePWMMaxFreqHz,
+			I2cMuxes:             newConf.I2cMuxes,
 		}, nil
 	}
 }
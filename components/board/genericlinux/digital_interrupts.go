@@ -25,6 +25,9 @@ type digitalInterrupt struct {
 	config   board.DigitalInterruptConfig
 	count    int64
 	channels []chan board.Tick
+	// unbound is set if the kernel stops delivering events for the line, which happens when the
+	// GPIO chip is unbound from its driver (e.g., a USB GPIO expander being unplugged).
+	unbound bool
 }
 
 // newDigitalInterrupt constructs a new digitalInterrupt from the config and pinMapping. If
@@ -92,6 +95,10 @@ func (di *digitalInterrupt) Value(
 ) (int64, error) {
 	di.mu.Lock()
 	defer di.mu.Unlock()
+	if di.unbound {
+		return di.count, fmt.Errorf("digital interrupt %s is no longer receiving events; "+
+			"was its GPIO chip unbound?", di.config.Name)
+	}
 	return di.count, nil
 }
 
@@ -100,7 +107,13 @@ func (di *digitalInterrupt) monitor(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			return
-		case event := <-di.line.Events():
+		case event, ok := <-di.line.Events():
+			if !ok {
+				di.mu.Lock()
+				di.unbound = true
+				di.mu.Unlock()
+				return
+			}
 			// Put the body of this case in an anonymous function so we unlock the mutex when it's
 			// finished.
 			shouldReturn := func() bool {
@@ -111,6 +124,8 @@ func (di *digitalInterrupt) monitor(ctx context.Context) {
 					di.count++
 				}
 
+				// The kernel timestamps each event when the interrupt fires, so the time between
+				// ticks is accurate even if we're slow to read them off the line.
 				tick := board.Tick{
 					Name:             di.config.Name,
 					High:             event.RisingEdge,
@@ -18,6 +18,11 @@ import (
 
 const noPin = 0xFFFFFFFF // noPin is the uint32 version of -1. A pin with this offset has no GPIO
 
+// defaultMaxSoftwarePwmFreqHz is the highest frequency we'll try to drive with a software PWM loop
+// unless the board config says otherwise. Above this, the scheduling jitter of a userspace loop
+// becomes a large fraction of the period, and the signal stops being useful for servos or LEDs.
+const defaultMaxSoftwarePwmFreqHz = 800
+
 type gpioPin struct {
 	boardWorkers *sync.WaitGroup
 
@@ -36,7 +41,9 @@ type gpioPin struct {
 	cancelCtx context.Context
 	logger    logging.Logger
 
-	swPwmCancel func()
+	swPwmCancel    func()
+	swPwmMaxFreqHz uint
+	swPwmJitter    pwmJitterStats
 }
 
 func (pin *gpioPin) wrapError(err error) error {
@@ -219,6 +226,7 @@ func (pin *gpioPin) startSoftwarePWM() error {
 
 	ctx, cancel := context.WithCancel(pin.cancelCtx)
 	pin.swPwmCancel = cancel
+	pin.swPwmJitter = pwmJitterStats{}
 	pin.boardWorkers.Add(1)
 	utils.ManagedGo(func() { pin.softwarePwmLoop(ctx) }, pin.boardWorkers.Done)
 	return nil
@@ -261,6 +269,7 @@ func (pin *gpioPin) halfPwmCycle(ctx context.Context, shouldBeOn bool) bool {
 	// Make local copies of these, then release the mutex
 	var dutyCycle float64
 	var freqHz uint
+	startTime := time.Now()
 
 	// We encapsulate some of this code into its own function, to ensure that the mutex is unlocked
 	// at the appropriate time even if we return early.
@@ -291,7 +300,17 @@ func (pin *gpioPin) halfPwmCycle(ctx context.Context, shouldBeOn bool) bool {
 	}
 	duration := time.Duration(float64(time.Second) * dutyCycle / float64(freqHz))
 
-	return accurateSleep(ctx, duration)
+	if !accurateSleep(ctx, duration) {
+		return false
+	}
+
+	// Record how far off we were from the intended half-cycle, so that users can tell whether the
+	// software PWM signal is accurate enough for their hardware.
+	jitter := time.Since(startTime) - duration
+	pin.mu.Lock()
+	defer pin.mu.Unlock()
+	pin.swPwmJitter.record(jitter)
+	return true
 }
 
 func (pin *gpioPin) softwarePwmLoop(ctx context.Context) {
@@ -335,10 +354,63 @@ func (pin *gpioPin) SetPWMFreq(ctx context.Context, freqHz uint, extra map[strin
 	pin.mu.Lock()
 	defer pin.mu.Unlock()
 
+	if pin.hwPwm == nil && freqHz > pin.swPwmMaxFreqHz {
+		// This pin can only be driven by a software PWM loop, which can't keep up at this
+		// frequency. Don't store the new frequency, so the pin keeps whatever signal it had.
+		return pin.wrapError(errors.Errorf(
+			"frequency %d Hz is above the software PWM limit of %d Hz", freqHz, pin.swPwmMaxFreqHz))
+	}
+
 	pin.pwmFreqHz = freqHz
 	return pin.startSoftwarePWM()
 }
 
+// softwarePwmJitter returns the jitter statistics from the current software PWM loop, and whether
+// such a loop is running at all.
+func (pin *gpioPin) softwarePwmJitter() (pwmJitterStats, bool) {
+	pin.mu.Lock()
+	defer pin.mu.Unlock()
+
+	return pin.swPwmJitter, pin.swPwmCancel != nil
+}
+
+// setSoftwarePwmMaxFreq changes the highest frequency this pin will accept for software PWM. A
+// loop that is already running above the new limit is left alone; the limit only applies to
+// future calls to SetPWMFreq.
+func (pin *gpioPin) setSoftwarePwmMaxFreq(freqHz uint) {
+	pin.mu.Lock()
+	defer pin.mu.Unlock()
+
+	pin.swPwmMaxFreqHz = freqHz
+}
+
+// pwmJitterStats describes how far the half-cycles of a software PWM loop deviated from their
+// intended durations. Lateness is always non-negative in practice, but we record the absolute
+// value in case the clock misbehaves.
+type pwmJitterStats struct {
+	samples     uint64
+	totalJitter time.Duration
+	maxJitter   time.Duration
+}
+
+func (s *pwmJitterStats) record(jitter time.Duration) {
+	if jitter < 0 {
+		jitter = -jitter
+	}
+	s.samples++
+	s.totalJitter += jitter
+	if jitter > s.maxJitter {
+		s.maxJitter = jitter
+	}
+}
+
+func (s pwmJitterStats) meanJitter() time.Duration {
+	if s.samples == 0 {
+		return 0
+	}
+	return s.totalJitter / time.Duration(s.samples)
+}
+
 func (pin *gpioPin) Close() error {
 	// We keep the gpio.Line object open indefinitely, so it holds its state for as long as this
 	// struct is around. This function is a way to close it when we're about to go out of scope, so
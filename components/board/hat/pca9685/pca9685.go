@@ -2,10 +2,15 @@
 
 // Package pca9685 implements a PCA9685 HAT. It's probably also a generic PCA9685
 // but that has not been verified yet.
+//
+// Each of the 16 channels is exposed as a GPIOPin named "0" through "15", so servos and
+// motors can be driven from the PCA9685 by naming it as their board. All channels share a
+// single PWM frequency, which can be set with frequency_hz (e.g., 50 Hz for hobby servos).
 package pca9685
 
 import (
 	"context"
+	"math"
 	"strconv"
 	"sync"
 	"time"
@@ -32,6 +37,9 @@ var (
 type Config struct {
 	I2CBus     string `json:"i2c_bus"`
 	I2CAddress *int   `json:"i2c_address,omitempty"`
+	// FrequencyHz is the PWM frequency shared by all 16 channels. If omitted, the chip keeps
+	// its power-on default of about 200 Hz until a channel's frequency is set.
+	FrequencyHz *float64 `json:"frequency_hz,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -44,6 +52,10 @@ func (conf *Config) Validate(path string) ([]string, error) {
 	if conf.I2CAddress != nil && (*conf.I2CAddress < 0 || *conf.I2CAddress > 255) {
 		return nil, resource.NewConfigValidationError(path, errors.New("i2c_address must be an unsigned byte"))
 	}
+	if conf.FrequencyHz != nil && (*conf.FrequencyHz < minFrequencyHz || *conf.FrequencyHz > maxFrequencyHz) {
+		return nil, resource.NewConfigValidationError(path,
+			errors.Errorf("frequency_hz must be between %d and %d", minFrequencyHz, maxFrequencyHz))
+	}
 	return deps, nil
 }
 
@@ -82,6 +94,11 @@ const (
 
 	mode1Reg    = 0x00
 	prescaleReg = 0xFE
+
+	// The prescaler is a byte between 3 and 255, which limits the frequencies we can produce
+	// with the internal oscillator.
+	minFrequencyHz = 24
+	maxFrequencyHz = 1526
 )
 
 // This should be considered const, except you cannot take the address of a const value.
@@ -136,6 +153,12 @@ func (pca *PCA9685) Reconfigure(ctx context.Context, deps resource.Dependencies,
 		return err
 	}
 
+	if newConf.FrequencyHz != nil {
+		if err := pca.setFrequency(ctx, *newConf.FrequencyHz); err != nil {
+			return errors.Wrap(err, "couldn't set PWM frequency")
+		}
+	}
+
 	return nil
 }
 
@@ -198,7 +221,8 @@ func (pca *PCA9685) frequency(ctx context.Context) (float64, error) {
 	if err != nil {
 		return 0, err
 	}
-	return float64(pca.referenceClockSpeed) / 4096.0 / float64(prescale), nil
+	// The chip counts prescale+1 oscillator ticks per PWM step.
+	return float64(pca.referenceClockSpeed) / 4096.0 / (float64(prescale) + 1), nil
 }
 
 // StreamTicks streams digital interrupt ticks.
@@ -213,11 +237,16 @@ func (pca *PCA9685) StreamTicks(ctx context.Context, interrupts []board.DigitalI
 func (pca *PCA9685) SetFrequency(ctx context.Context, frequency float64) error {
 	pca.mu.RLock()
 	defer pca.mu.RUnlock()
+	return pca.setFrequency(ctx, frequency)
+}
 
-	prescale := byte((float64(pca.referenceClockSpeed) / 4096.0 / frequency) + 0.5)
-	if prescale < 3 {
+// setFrequency is SetFrequency, but assumes the caller holds the mutex.
+func (pca *PCA9685) setFrequency(ctx context.Context, frequency float64) error {
+	prescaleVal := math.Round(float64(pca.referenceClockSpeed)/4096.0/frequency) - 1
+	if prescaleVal < 3 || prescaleVal > 255 {
 		return errors.New("invalid frequency")
 	}
+	prescale := byte(prescaleVal)
 
 	handle, err := pca.openHandle()
 	if err != nil {
@@ -401,12 +430,12 @@ func (gp *gpioPin) PWMFreq(ctx context.Context, extra map[string]interface{}) (u
 	if err != nil {
 		return 0, err
 	}
-	return uint(freqHz), nil
+	return uint(math.Round(freqHz)), nil
 }
 
 func (gp *gpioPin) SetPWMFreq(ctx context.Context, freqHz uint, extra map[string]interface{}) error {
 	gp.pca.mu.RLock()
 	defer gp.pca.mu.RUnlock()
 
-	return gp.pca.SetFrequency(ctx, float64(freqHz))
+	return gp.pca.setFrequency(ctx, float64(freqHz))
 }
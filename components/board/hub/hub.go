@@ -0,0 +1,457 @@
+// Package hub implements a board which is an ESP32, or similar microcontroller, running micro-RDK-style firmware
+// and attached over a serial port or TCP. It lets a cheap microcontroller add GPIO, analog, and PWM pins to a
+// robot without running a second full machine.
+//
+// The hub speaks a compact line protocol. Each request is a line holding an id, a command, and its arguments, and
+// the hub answers each request with a line starting with the same id followed by either "ok" and any value, or
+// "err" and a message:
+//
+//	> 7 gpio 4 1
+//	< 7 ok
+//	> 8 adc 34
+//	< 8 ok 2047
+//	> 9 pwm 99 0.5
+//	< 9 err no such pin
+//
+// The commands are "ver", "gpio <pin> [0|1]", "pwm <pin> [duty]", "freq <pin> [hz]", "adc <pin>", and
+// "dac <pin> <value>", where the bracketed argument is given to set a value and left off to read it back. Lines
+// from the hub starting with "#" are log messages.
+package hub
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	goserial "github.com/jacobsa/go-serial/serial"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+	"go.uber.org/multierr"
+	pb "go.viam.com/api/component/board/v1"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/board/pinwrappers"
+	"go.viam.com/rdk/grpc"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("hub")
+
+const (
+	defaultBaudRate             = 115200
+	defaultTimeoutMs            = 1000
+	defaultAnalogMaxVoltage     = 3.3
+	defaultAnalogResolutionBits = 12
+)
+
+var errHubDisconnected = errors.New("hub disconnected")
+
+// A Config describes how to reach the hub and which of its pins to use as analogs.
+type Config struct {
+	// SerialPath and Address are mutually exclusive ways of reaching the hub.
+	SerialPath string `json:"serial_path,omitempty"`
+	BaudRate   int    `json:"baud_rate,omitempty"`
+	// Address is the host:port of a hub listening on TCP, such as one on wifi.
+	Address   string                     `json:"address,omitempty"`
+	Analogs   []board.AnalogReaderConfig `json:"analogs,omitempty"`
+	TimeoutMs int                        `json:"timeout_ms,omitempty"`
+	// AnalogMaxVoltage and AnalogResolutionBits describe the hub's ADC, and default to the ESP32's 3.3V and 12
+	// bits.
+	AnalogMaxVoltage     float32 `json:"analog_max_voltage,omitempty"`
+	AnalogResolutionBits int     `json:"analog_resolution_bits,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.SerialPath == "" && conf.Address == "" {
+		return nil, resource.NewConfigValidationError(path, errors.New("one of serial_path or address is required"))
+	}
+	if conf.SerialPath != "" && conf.Address != "" {
+		return nil, resource.NewConfigValidationError(path, errors.New("only one of serial_path or address can be set"))
+	}
+	if conf.BaudRate < 0 || conf.TimeoutMs < 0 || conf.AnalogMaxVoltage < 0 || conf.AnalogResolutionBits < 0 {
+		return nil, resource.NewConfigValidationError(
+			path, errors.New("baud_rate, timeout_ms, analog_max_voltage, and analog_resolution_bits cannot be negative"))
+	}
+	for idx, c := range conf.Analogs {
+		if err := c.Validate(fmt.Sprintf("%s.%s.%d", path, "analogs", idx)); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func init() {
+	resource.RegisterComponent(
+		board.API,
+		model,
+		resource.Registration[board.Board, *Config]{
+			Constructor: func(
+				ctx context.Context,
+				deps resource.Dependencies,
+				conf resource.Config,
+				logger logging.Logger,
+			) (board.Board, error) {
+				newConf, err := resource.NativeConfig[*Config](conf)
+				if err != nil {
+					return nil, err
+				}
+				port, err := open(ctx, newConf)
+				if err != nil {
+					return nil, err
+				}
+				return newHub(ctx, conf.ResourceName(), newConf, port, logger)
+			},
+		})
+}
+
+// open connects to the hub over whichever transport is configured.
+func open(ctx context.Context, conf *Config) (io.ReadWriteCloser, error) {
+	if conf.Address != "" {
+		var d net.Dialer
+		return d.DialContext(ctx, "tcp", conf.Address)
+	}
+	baudRate := conf.BaudRate
+	if baudRate == 0 {
+		baudRate = defaultBaudRate
+	}
+	return goserial.Open(goserial.OpenOptions{
+		PortName:        conf.SerialPath,
+		BaudRate:        uint(baudRate),
+		DataBits:        8,
+		StopBits:        1,
+		MinimumReadSize: 1,
+	})
+}
+
+type response struct {
+	value string
+	err   error
+}
+
+type hub struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	port    io.ReadWriteCloser
+	timeout time.Duration
+	analogs map[string]*pinwrappers.AnalogSmoother
+
+	maxAnalogVoltage float32
+	stepSize         float32
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  uint32
+	pending map[uint32]chan response
+	readErr error
+
+	workers utils.StoppableWorkers
+	logger  logging.Logger
+}
+
+// newHub starts talking to a hub over an already open port, which it takes ownership of.
+func newHub(
+	ctx context.Context,
+	name resource.Name,
+	conf *Config,
+	port io.ReadWriteCloser,
+	logger logging.Logger,
+) (*hub, error) {
+	h := &hub{
+		Named:            name.AsNamed(),
+		port:             port,
+		timeout:          time.Duration(conf.TimeoutMs) * time.Millisecond,
+		maxAnalogVoltage: conf.AnalogMaxVoltage,
+		pending:          map[uint32]chan response{},
+		logger:           logger,
+	}
+	if h.timeout == 0 {
+		h.timeout = defaultTimeoutMs * time.Millisecond
+	}
+	if h.maxAnalogVoltage == 0 {
+		h.maxAnalogVoltage = defaultAnalogMaxVoltage
+	}
+	bits := conf.AnalogResolutionBits
+	if bits == 0 {
+		bits = defaultAnalogResolutionBits
+	}
+	h.stepSize = h.maxAnalogVoltage / float32(int(1)<<bits)
+
+	h.workers = utils.NewStoppableWorkers(h.readThread)
+
+	ver, err := h.send(ctx, "ver")
+	if err != nil {
+		return nil, multierr.Combine(errors.Wrap(err, "hub did not answer"), h.Close(ctx))
+	}
+	h.logger.CDebugw(ctx, "hub connected", "version", ver)
+
+	h.analogs = map[string]*pinwrappers.AnalogSmoother{}
+	for _, c := range conf.Analogs {
+		h.analogs[c.Name] = pinwrappers.SmoothAnalogReader(&analog{h, c.Pin}, c, logger)
+	}
+	return h, nil
+}
+
+// readThread hands each response from the hub to the request waiting on it.
+func (h *hub) readThread(ctx context.Context) {
+	in := bufio.NewReader(h.port)
+	for {
+		line, err := in.ReadString('\n')
+		if err != nil {
+			if ctx.Err() == nil {
+				h.logger.Warnw("lost connection to hub", "error", err)
+			}
+			h.fail(errors.Wrap(errHubDisconnected, err.Error()))
+			return
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if line[0] == '#' {
+			h.logger.Debugw("hub log", "message", strings.TrimSpace(line[1:]))
+			continue
+		}
+		id, res, err := parseResponse(line)
+		if err != nil {
+			h.logger.Warnw("ignoring malformed line from hub", "line", line, "error", err)
+			continue
+		}
+		h.mu.Lock()
+		ch, ok := h.pending[id]
+		delete(h.pending, id)
+		h.mu.Unlock()
+		if !ok {
+			// the request has most likely already timed out
+			h.logger.Debugw("ignoring response to unknown request", "line", line)
+			continue
+		}
+		ch <- res
+	}
+}
+
+// fail fails every outstanding request, and any made afterwards, with err.
+func (h *hub) fail(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.readErr = err
+	for id, ch := range h.pending {
+		ch <- response{err: err}
+		delete(h.pending, id)
+	}
+}
+
+func parseResponse(line string) (uint32, response, error) {
+	fields := strings.SplitN(line, " ", 3)
+	if len(fields) < 2 {
+		return 0, response{}, errors.New("response has no status")
+	}
+	id, err := strconv.ParseUint(fields[0], 10, 32)
+	if err != nil {
+		return 0, response{}, err
+	}
+	var rest string
+	if len(fields) == 3 {
+		rest = fields[2]
+	}
+	switch fields[1] {
+	case "ok":
+		return uint32(id), response{value: rest}, nil
+	case "err":
+		return uint32(id), response{err: errors.Errorf("hub error: %s", rest)}, nil
+	default:
+		return 0, response{}, errors.Errorf("unknown status %q", fields[1])
+	}
+}
+
+// send makes a request of the hub and waits for its response.
+func (h *hub) send(ctx context.Context, command string, args ...interface{}) (string, error) {
+	ctx, span := trace.StartSpan(ctx, "hub::send::"+command)
+	defer span.End()
+
+	h.mu.Lock()
+	if h.readErr != nil {
+		h.mu.Unlock()
+		return "", h.readErr
+	}
+	h.nextID++
+	id := h.nextID
+	ch := make(chan response, 1)
+	h.pending[id] = ch
+	h.mu.Unlock()
+
+	forget := func() {
+		h.mu.Lock()
+		delete(h.pending, id)
+		h.mu.Unlock()
+	}
+
+	parts := []string{strconv.FormatUint(uint64(id), 10), command}
+	for _, arg := range args {
+		parts = append(parts, fmt.Sprint(arg))
+	}
+	h.writeMu.Lock()
+	_, err := h.port.Write([]byte(strings.Join(parts, " ") + "\n"))
+	h.writeMu.Unlock()
+	if err != nil {
+		forget()
+		return "", err
+	}
+
+	select {
+	case res := <-ch:
+		return res.value, res.err
+	case <-ctx.Done():
+		forget()
+		return "", ctx.Err()
+	case <-time.After(h.timeout):
+		forget()
+		return "", errors.Errorf("timed out waiting for hub to answer %q", command)
+	}
+}
+
+// AnalogByName returns an analog pin by name.
+func (h *hub) AnalogByName(name string) (board.Analog, error) {
+	a, ok := h.analogs[name]
+	if !ok {
+		return nil, errors.Errorf("can't find Analog (%s)", name)
+	}
+	return a, nil
+}
+
+// AnalogNames returns the names of all known analog pins.
+func (h *hub) AnalogNames() []string {
+	names := []string{}
+	for n := range h.analogs {
+		names = append(names, n)
+	}
+	return names
+}
+
+// DigitalInterruptByName returns a digital interrupt by name. The hub protocol has no way to stream interrupts.
+func (h *hub) DigitalInterruptByName(name string) (board.DigitalInterrupt, error) {
+	return nil, grpc.UnimplementedError
+}
+
+// DigitalInterruptNames returns the names of all known digital interrupts.
+func (h *hub) DigitalInterruptNames() []string {
+	return nil
+}
+
+// StreamTicks streams digital interrupt ticks.
+func (h *hub) StreamTicks(ctx context.Context, interrupts []board.DigitalInterrupt, ch chan board.Tick,
+	extra map[string]interface{},
+) error {
+	return grpc.UnimplementedError
+}
+
+// GPIOPinByName returns the GPIO pin by the given name, which is the hub's pin number.
+func (h *hub) GPIOPinByName(pin string) (board.GPIOPin, error) {
+	if _, err := strconv.ParseUint(pin, 10, 8); err != nil {
+		return nil, errors.Errorf("hub pin names are numbers, not %q", pin)
+	}
+	return &gpioPin{h, pin}, nil
+}
+
+// SetPowerMode sets the board to the given power mode.
+func (h *hub) SetPowerMode(ctx context.Context, mode pb.PowerMode, duration *time.Duration) error {
+	return grpc.UnimplementedError
+}
+
+// Close stops the analog readers and disconnects from the hub.
+func (h *hub) Close(ctx context.Context) error {
+	var err error
+	for _, a := range h.analogs {
+		err = multierr.Combine(err, a.Close(ctx))
+	}
+	// closing the port ends the read thread's blocked read
+	err = multierr.Combine(err, h.port.Close())
+	h.workers.Stop()
+	return err
+}
+
+type gpioPin struct {
+	h   *hub
+	pin string
+}
+
+func (gp *gpioPin) Set(ctx context.Context, high bool, extra map[string]interface{}) error {
+	value := 0
+	if high {
+		value = 1
+	}
+	_, err := gp.h.send(ctx, "gpio", gp.pin, value)
+	return err
+}
+
+func (gp *gpioPin) Get(ctx context.Context, extra map[string]interface{}) (bool, error) {
+	res, err := gp.h.send(ctx, "gpio", gp.pin)
+	if err != nil {
+		return false, err
+	}
+	return res == "1", nil
+}
+
+func (gp *gpioPin) PWM(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	res, err := gp.h.send(ctx, "pwm", gp.pin)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(res, 64)
+}
+
+func (gp *gpioPin) SetPWM(ctx context.Context, dutyCyclePct float64, extra map[string]interface{}) error {
+	if dutyCyclePct < 0 || dutyCyclePct > 1 {
+		return errors.Errorf("duty cycle %v must be between 0 and 1", dutyCyclePct)
+	}
+	_, err := gp.h.send(ctx, "pwm", gp.pin, strconv.FormatFloat(dutyCyclePct, 'f', -1, 64))
+	return err
+}
+
+func (gp *gpioPin) PWMFreq(ctx context.Context, extra map[string]interface{}) (uint, error) {
+	res, err := gp.h.send(ctx, "freq", gp.pin)
+	if err != nil {
+		return 0, err
+	}
+	freq, err := strconv.ParseUint(res, 10, 32)
+	return uint(freq), err
+}
+
+func (gp *gpioPin) SetPWMFreq(ctx context.Context, freqHz uint, extra map[string]interface{}) error {
+	_, err := gp.h.send(ctx, "freq", gp.pin, freqHz)
+	return err
+}
+
+type analog struct {
+	h   *hub
+	pin string
+}
+
+// Read returns the analog value with the range and step size in V/bit.
+func (a *analog) Read(ctx context.Context, extra map[string]interface{}) (board.AnalogValue, error) {
+	res, err := a.h.send(ctx, "adc", a.pin)
+	if err != nil {
+		return board.AnalogValue{}, err
+	}
+	reading, err := strconv.Atoi(res)
+	if err != nil {
+		return board.AnalogValue{}, err
+	}
+	return board.AnalogValue{Value: reading, Min: 0, Max: a.h.maxAnalogVoltage, StepSize: a.h.stepSize}, nil
+}
+
+// Write sets the pin's DAC, on pins which have one.
+func (a *analog) Write(ctx context.Context, value int, extra map[string]interface{}) error {
+	_, err := a.h.send(ctx, "dac", a.pin, value)
+	return err
+}
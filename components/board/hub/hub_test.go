@@ -0,0 +1,172 @@
+package hub
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+// fakeFirmware answers requests the way a hub would, keeping its pins in maps.
+func fakeFirmware(conn net.Conn) {
+	gpio := map[string]string{}
+	pwm := map[string]string{"4": "0"}
+	freq := map[string]string{"4": "500"}
+	in := bufio.NewReader(conn)
+	for {
+		line, err := in.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		id, command, args := fields[0], fields[1], fields[2:]
+		reply := func(rest string) {
+			fmt.Fprintf(conn, "%s %s\n", id, rest)
+		}
+		get := func(values map[string]string) {
+			if len(args) == 2 {
+				values[args[0]] = args[1]
+				reply("ok")
+				return
+			}
+			v, ok := values[args[0]]
+			if !ok {
+				reply("err no such pin")
+				return
+			}
+			reply("ok " + v)
+		}
+		switch command {
+		case "ver":
+			fmt.Fprintf(conn, "# booted\n")
+			reply("ok 1.0")
+		case "gpio":
+			if _, ok := gpio[args[0]]; !ok {
+				gpio[args[0]] = "0"
+			}
+			get(gpio)
+		case "pwm":
+			get(pwm)
+		case "freq":
+			get(freq)
+		case "adc":
+			reply("ok 2048")
+		case "hang":
+		default:
+			reply("err unknown command")
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	conf := &Config{}
+	_, err := conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	conf.SerialPath = "/dev/ttyUSB0"
+	conf.Address = "10.0.0.2:4000"
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	conf.Address = ""
+	conf.Analogs = []board.AnalogReaderConfig{{Pin: "34"}}
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path.analogs.0", "name"))
+
+	conf.Analogs[0].Name = "pot"
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+}
+
+func TestHub(t *testing.T) {
+	ctx := context.Background()
+	client, server := net.Pipe()
+	go fakeFirmware(server)
+	defer server.Close()
+
+	conf := &Config{
+		Address:   "pipe",
+		Analogs:   []board.AnalogReaderConfig{{Name: "pot", Pin: "34"}},
+		TimeoutMs: 100,
+	}
+	h, err := newHub(ctx, board.Named("hub"), conf, client, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+
+	pin, err := h.GPIOPinByName("4")
+	test.That(t, err, test.ShouldBeNil)
+	high, err := pin.Get(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, high, test.ShouldBeFalse)
+	test.That(t, pin.Set(ctx, true, nil), test.ShouldBeNil)
+	high, err = pin.Get(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, high, test.ShouldBeTrue)
+
+	test.That(t, pin.SetPWM(ctx, 0.25, nil), test.ShouldBeNil)
+	duty, err := pin.PWM(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, duty, test.ShouldEqual, 0.25)
+	test.That(t, pin.SetPWM(ctx, 2, nil), test.ShouldNotBeNil)
+
+	freqHz, err := pin.PWMFreq(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, freqHz, test.ShouldEqual, 500)
+	test.That(t, pin.SetPWMFreq(ctx, 1000, nil), test.ShouldBeNil)
+	freqHz, err = pin.PWMFreq(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, freqHz, test.ShouldEqual, 1000)
+
+	// errors from the hub are passed along
+	other, err := h.GPIOPinByName("5")
+	test.That(t, err, test.ShouldBeNil)
+	_, err = other.PWM(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no such pin")
+
+	_, err = h.GPIOPinByName("GPIO4")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	test.That(t, h.AnalogNames(), test.ShouldResemble, []string{"pot"})
+	a, err := h.AnalogByName("pot")
+	test.That(t, err, test.ShouldBeNil)
+	value, err := a.Read(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, value.Value, test.ShouldEqual, 2048)
+	test.That(t, value.Max, test.ShouldAlmostEqual, 3.3, 1e-6)
+	test.That(t, value.StepSize, test.ShouldAlmostEqual, 3.3/4096, 1e-6)
+	_, err = h.AnalogByName("missing")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// a request the hub never answers times out, without confusing later requests
+	_, err = h.send(ctx, "hang")
+	test.That(t, err, test.ShouldNotBeNil)
+	high, err = pin.Get(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, high, test.ShouldBeTrue)
+
+	test.That(t, h.Close(ctx), test.ShouldBeNil)
+}
+
+func TestHubDisconnect(t *testing.T) {
+	ctx := context.Background()
+	client, server := net.Pipe()
+	go fakeFirmware(server)
+
+	h, err := newHub(ctx, board.Named("hub"), &Config{Address: "pipe"}, client, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer h.Close(ctx)
+
+	server.Close()
+	pin, err := h.GPIOPinByName("4")
+	test.That(t, err, test.ShouldBeNil)
+	_, err = pin.Get(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+}
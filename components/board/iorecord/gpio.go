@@ -0,0 +1,109 @@
+package iorecord
+
+import (
+	"context"
+
+	"go.viam.com/rdk/components/board"
+)
+
+func boolValue(high bool) float64 {
+	if high {
+		return 1
+	}
+	return 0
+}
+
+// RecordGPIOPin returns a GPIO pin which passes everything through to pin, recording it as the
+// named device.
+func RecordGPIOPin(pin board.GPIOPin, name string, rec *Recorder) board.GPIOPin {
+	return &recordingGPIOPin{pin: pin, rec: rec, device: "gpio:" + name}
+}
+
+type recordingGPIOPin struct {
+	pin    board.GPIOPin
+	rec    *Recorder
+	device string
+}
+
+func (p *recordingGPIOPin) Set(ctx context.Context, high bool, extra map[string]interface{}) error {
+	err := p.pin.Set(ctx, high, extra)
+	p.rec.record(Transaction{Device: p.device, Op: "set", Value: boolValue(high), Error: errorString(err)})
+	return err
+}
+
+func (p *recordingGPIOPin) Get(ctx context.Context, extra map[string]interface{}) (bool, error) {
+	high, err := p.pin.Get(ctx, extra)
+	p.rec.record(Transaction{Device: p.device, Op: "get", Value: boolValue(high), Error: errorString(err)})
+	return high, err
+}
+
+func (p *recordingGPIOPin) PWM(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	dutyCyclePct, err := p.pin.PWM(ctx, extra)
+	p.rec.record(Transaction{Device: p.device, Op: "pwm", Value: dutyCyclePct, Error: errorString(err)})
+	return dutyCyclePct, err
+}
+
+func (p *recordingGPIOPin) SetPWM(ctx context.Context, dutyCyclePct float64, extra map[string]interface{}) error {
+	err := p.pin.SetPWM(ctx, dutyCyclePct, extra)
+	p.rec.record(Transaction{Device: p.device, Op: "set_pwm", Value: dutyCyclePct, Error: errorString(err)})
+	return err
+}
+
+func (p *recordingGPIOPin) PWMFreq(ctx context.Context, extra map[string]interface{}) (uint, error) {
+	freqHz, err := p.pin.PWMFreq(ctx, extra)
+	p.rec.record(Transaction{Device: p.device, Op: "pwm_freq", Value: float64(freqHz), Error: errorString(err)})
+	return freqHz, err
+}
+
+func (p *recordingGPIOPin) SetPWMFreq(ctx context.Context, freqHz uint, extra map[string]interface{}) error {
+	err := p.pin.SetPWMFreq(ctx, freqHz, extra)
+	p.rec.record(Transaction{Device: p.device, Op: "set_pwm_freq", Value: float64(freqHz), Error: errorString(err)})
+	return err
+}
+
+// GPIOPin returns a GPIO pin which replays the transactions recorded with the named device.
+func (r *Replayer) GPIOPin(name string) board.GPIOPin {
+	return &replayingGPIOPin{r: r, device: "gpio:" + name}
+}
+
+type replayingGPIOPin struct {
+	r      *Replayer
+	device string
+}
+
+func (p *replayingGPIOPin) read(op string) (float64, error) {
+	t, err := p.r.take(p.device, op)
+	if err != nil {
+		return 0, err
+	}
+	return t.Value, t.err()
+}
+
+func (p *replayingGPIOPin) Set(ctx context.Context, high bool, extra map[string]interface{}) error {
+	_, err := p.r.takeWrite(p.device, "set", 0, nil, boolValue(high))
+	return err
+}
+
+func (p *replayingGPIOPin) Get(ctx context.Context, extra map[string]interface{}) (bool, error) {
+	value, err := p.read("get")
+	return value != 0, err
+}
+
+func (p *replayingGPIOPin) PWM(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	return p.read("pwm")
+}
+
+func (p *replayingGPIOPin) SetPWM(ctx context.Context, dutyCyclePct float64, extra map[string]interface{}) error {
+	_, err := p.r.takeWrite(p.device, "set_pwm", 0, nil, dutyCyclePct)
+	return err
+}
+
+func (p *replayingGPIOPin) PWMFreq(ctx context.Context, extra map[string]interface{}) (uint, error) {
+	freqHz, err := p.read("pwm_freq")
+	return uint(freqHz), err
+}
+
+func (p *replayingGPIOPin) SetPWMFreq(ctx context.Context, freqHz uint, extra map[string]interface{}) error {
+	_, err := p.r.takeWrite(p.device, "set_pwm_freq", 0, nil, float64(freqHz))
+	return err
+}
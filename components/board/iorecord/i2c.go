@@ -0,0 +1,151 @@
+package iorecord
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+)
+
+func i2cDevice(name string, addr byte) string {
+	return fmt.Sprintf("i2c:%s:%#x", name, addr)
+}
+
+// RecordI2C returns an I2C bus which passes everything through to bus, recording it under the
+// given name. Drivers which talk to the same device from more than one goroutine should record
+// each one's bus under a different name, so that they replay independently.
+func RecordI2C(bus buses.I2C, name string, rec *Recorder) buses.I2C {
+	return &recordingI2C{bus: bus, name: name, rec: rec}
+}
+
+type recordingI2C struct {
+	bus  buses.I2C
+	name string
+	rec  *Recorder
+}
+
+func (b *recordingI2C) OpenHandle(addr byte) (buses.I2CHandle, error) {
+	handle, err := b.bus.OpenHandle(addr)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingI2CHandle{handle: handle, rec: b.rec, device: i2cDevice(b.name, addr)}, nil
+}
+
+type recordingI2CHandle struct {
+	handle buses.I2CHandle
+	rec    *Recorder
+	device string
+}
+
+func (h *recordingI2CHandle) Write(ctx context.Context, tx []byte) error {
+	err := h.handle.Write(ctx, tx)
+	h.rec.record(Transaction{Device: h.device, Op: "write", Data: tx, Error: errorString(err)})
+	return err
+}
+
+func (h *recordingI2CHandle) Read(ctx context.Context, count int) ([]byte, error) {
+	data, err := h.handle.Read(ctx, count)
+	h.rec.record(Transaction{Device: h.device, Op: "read", Data: data, Error: errorString(err)})
+	return data, err
+}
+
+func (h *recordingI2CHandle) ReadByteData(ctx context.Context, register byte) (byte, error) {
+	data, err := h.handle.ReadByteData(ctx, register)
+	h.rec.record(Transaction{Device: h.device, Op: "read_byte_data", Register: register, Data: []byte{data}, Error: errorString(err)})
+	return data, err
+}
+
+func (h *recordingI2CHandle) WriteByteData(ctx context.Context, register, data byte) error {
+	err := h.handle.WriteByteData(ctx, register, data)
+	h.rec.record(Transaction{Device: h.device, Op: "write_byte_data", Register: register, Data: []byte{data}, Error: errorString(err)})
+	return err
+}
+
+func (h *recordingI2CHandle) ReadBlockData(ctx context.Context, register byte, numBytes uint8) ([]byte, error) {
+	data, err := h.handle.ReadBlockData(ctx, register, numBytes)
+	h.rec.record(Transaction{Device: h.device, Op: "read_block_data", Register: register, Data: data, Error: errorString(err)})
+	return data, err
+}
+
+func (h *recordingI2CHandle) WriteBlockData(ctx context.Context, register byte, data []byte) error {
+	err := h.handle.WriteBlockData(ctx, register, data)
+	h.rec.record(Transaction{Device: h.device, Op: "write_block_data", Register: register, Data: data, Error: errorString(err)})
+	return err
+}
+
+func (h *recordingI2CHandle) Close() error {
+	return h.handle.Close()
+}
+
+// I2C returns an I2C bus which replays the transactions recorded on the named bus. Once a device's
+// recorded reads run out, further reads block until their context is done, as a quiet device would.
+func (r *Replayer) I2C(name string) buses.I2C {
+	return &replayingI2C{r: r, name: name}
+}
+
+type replayingI2C struct {
+	r    *Replayer
+	name string
+}
+
+func (b *replayingI2C) OpenHandle(addr byte) (buses.I2CHandle, error) {
+	return &replayingI2CHandle{r: b.r, device: i2cDevice(b.name, addr)}, nil
+}
+
+type replayingI2CHandle struct {
+	r      *Replayer
+	device string
+}
+
+func (h *replayingI2CHandle) Write(ctx context.Context, tx []byte) error {
+	_, err := h.r.takeWrite(h.device, "write", 0, tx, 0)
+	return err
+}
+
+func (h *replayingI2CHandle) Read(ctx context.Context, count int) ([]byte, error) {
+	t, err := h.r.take(h.device, "read")
+	if err != nil {
+		if errors.Is(err, ErrExhausted) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	return t.Data, t.err()
+}
+
+func (h *replayingI2CHandle) ReadByteData(ctx context.Context, register byte) (byte, error) {
+	t, err := h.r.takeRead(h.device, "read_byte_data", register)
+	if err != nil {
+		return 0, err
+	}
+	if len(t.Data) != 1 {
+		return 0, t.err()
+	}
+	return t.Data[0], t.err()
+}
+
+func (h *replayingI2CHandle) WriteByteData(ctx context.Context, register, data byte) error {
+	_, err := h.r.takeWrite(h.device, "write_byte_data", register, []byte{data}, 0)
+	return err
+}
+
+func (h *replayingI2CHandle) ReadBlockData(ctx context.Context, register byte, numBytes uint8) ([]byte, error) {
+	t, err := h.r.takeRead(h.device, "read_block_data", register)
+	if err != nil {
+		return nil, err
+	}
+	return t.Data, t.err()
+}
+
+func (h *replayingI2CHandle) WriteBlockData(ctx context.Context, register byte, data []byte) error {
+	_, err := h.r.takeWrite(h.device, "write_block_data", register, data, 0)
+	return err
+}
+
+func (h *replayingI2CHandle) Close() error {
+	return nil
+}
@@ -0,0 +1,217 @@
+// Package iorecord records the I2C, serial, and GPIO transactions a driver makes with real hardware
+// to a fixture file, and replays them in place of the hardware, so that driver regressions can be
+// caught in unit tests without it.
+//
+// A fixture is a file of JSON transactions, one per line. Transactions are replayed in the order
+// they were recorded for each device, but devices are independent of each other, so drivers which
+// talk to several devices from different goroutines replay deterministically.
+package iorecord
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+// ErrExhausted is returned when a device is used more than it was in the recording.
+var ErrExhausted = errors.New("no more recorded transactions")
+
+// A Transaction is a single exchange with a device.
+type Transaction struct {
+	// Device is which device the transaction was with, such as "i2c:nmea:0x42", "serial:gps", or "gpio:18".
+	Device string `json:"device"`
+	// Op is what was done, such as "write", "read", or "set_pwm".
+	Op       string `json:"op"`
+	Register byte   `json:"register,omitempty"`
+	// Data is what was written to, or read from, the device.
+	Data []byte `json:"data,omitempty"`
+	// Value is the level, duty cycle, or frequency set on, or read from, a GPIO pin.
+	Value float64 `json:"value,omitempty"`
+	// Error is the error the device returned, if any.
+	Error string `json:"error,omitempty"`
+}
+
+func (t Transaction) err() error {
+	if t.Error == "" {
+		return nil
+	}
+	return errors.New(t.Error)
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// A Recorder writes transactions to a fixture file as they happen.
+type Recorder struct {
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+	err error
+}
+
+// NewRecorder returns a Recorder which writes to a new fixture file at path.
+func NewRecorder(path string) (*Recorder, error) {
+	//nolint:gosec
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{f: f, enc: json.NewEncoder(f)}, nil
+}
+
+func (r *Recorder) record(t Transaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.enc == nil || r.err != nil {
+		return
+	}
+	r.err = r.enc.Encode(t)
+}
+
+// Close stops recording, returning the first error hit writing the fixture, if any.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return r.err
+	}
+	err := multierr.Combine(r.err, r.f.Close())
+	r.f = nil
+	r.enc = nil
+	return err
+}
+
+// A Replayer stands in for devices by replaying the transactions recorded with them.
+type Replayer struct {
+	mu      sync.Mutex
+	streams map[string][]Transaction
+	next    map[string]int
+	// mismatch is the first time the driver didn't do what it did when recording.
+	mismatch error
+}
+
+// NewReplayer returns a Replayer for the given transactions.
+func NewReplayer(transactions []Transaction) *Replayer {
+	r := &Replayer{streams: map[string][]Transaction{}, next: map[string]int{}}
+	for _, t := range transactions {
+		r.streams[t.Device] = append(r.streams[t.Device], t)
+	}
+	return r
+}
+
+// LoadFixture returns a Replayer for the transactions in the fixture file at path.
+func LoadFixture(path string) (*Replayer, error) {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = multierr.Combine(err, f.Close())
+	}()
+
+	var transactions []Transaction
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var t Transaction
+		if err := json.Unmarshal(scanner.Bytes(), &t); err != nil {
+			return nil, errors.Wrapf(err, "line %d of %s", line, path)
+		}
+		transactions = append(transactions, t)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewReplayer(transactions), nil
+}
+
+// take returns the next transaction recorded with the device, checking that it's the expected op.
+func (r *Replayer) take(device, op string) (Transaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.next[device]
+	stream := r.streams[device]
+	if i >= len(stream) {
+		return Transaction{}, errors.Wrapf(ErrExhausted, "%s %s", device, op)
+	}
+	if stream[i].Op != op {
+		return Transaction{}, r.mismatchf("%s transaction %d: recorded %s, but got %s", device, i, stream[i].Op, op)
+	}
+	r.next[device] = i + 1
+	return stream[i], nil
+}
+
+// takeWrite is like take, but also checks that what's written is what was written when recording.
+func (r *Replayer) takeWrite(device, op string, register byte, data []byte, value float64) (Transaction, error) {
+	t, err := r.take(device, op)
+	if err != nil {
+		return t, err
+	}
+	if t.Register != register || !bytes.Equal(t.Data, data) || t.Value != value {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return t, r.mismatchf("%s %s: recorded register %d, data %q, value %v, but got register %d, data %q, value %v",
+			device, op, t.Register, t.Data, t.Value, register, data, value)
+	}
+	return t, t.err()
+}
+
+// takeRead is like take, but also checks that the register read from is the one read from when recording.
+func (r *Replayer) takeRead(device, op string, register byte) (Transaction, error) {
+	t, err := r.take(device, op)
+	if err != nil {
+		return t, err
+	}
+	if t.Register != register {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return t, r.mismatchf("%s %s: recorded register %d, but got register %d", device, op, t.Register, register)
+	}
+	return t, nil
+}
+
+// mismatchf remembers and returns an error describing how the driver strayed from the recording.
+// It must be called while holding mu.
+func (r *Replayer) mismatchf(format string, args ...interface{}) error {
+	err := fmt.Errorf("iorecord: "+format, args...)
+	if r.mismatch == nil {
+		r.mismatch = err
+	}
+	return err
+}
+
+// Verify returns an error if the driver strayed from the recording, or didn't replay all of it.
+func (r *Replayer) Verify() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.mismatch != nil {
+		return r.mismatch
+	}
+	var unreplayed []string
+	for device, stream := range r.streams {
+		if left := len(stream) - r.next[device]; left > 0 {
+			unreplayed = append(unreplayed, fmt.Sprintf("%s (%d)", device, left))
+		}
+	}
+	if len(unreplayed) > 0 {
+		sort.Strings(unreplayed)
+		return errors.Errorf("iorecord: transactions not replayed with %s", strings.Join(unreplayed, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package iorecord
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// fakeHandle is an I2C device that returns canned reads.
+type fakeHandle struct {
+	buses.I2CHandle
+	written [][]byte
+	reads   [][]byte
+}
+
+func (h *fakeHandle) Write(ctx context.Context, tx []byte) error {
+	h.written = append(h.written, tx)
+	return nil
+}
+
+func (h *fakeHandle) Read(ctx context.Context, count int) ([]byte, error) {
+	if len(h.reads) == 0 {
+		return nil, errors.New("nothing to read")
+	}
+	data := h.reads[0]
+	h.reads = h.reads[1:]
+	return data, nil
+}
+
+func (h *fakeHandle) ReadByteData(ctx context.Context, register byte) (byte, error) {
+	return register + 1, nil
+}
+
+func (h *fakeHandle) Close() error {
+	return nil
+}
+
+type fakePort struct {
+	io.Reader
+	bytes.Buffer
+}
+
+func (p *fakePort) Read(b []byte) (int, error) {
+	return p.Reader.Read(b)
+}
+
+func (p *fakePort) Close() error {
+	return nil
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	fixture := filepath.Join(t.TempDir(), "fixture.jsonl")
+
+	// record a driver talking to an I2C device, a serial port, and a GPIO pin
+	rec, err := NewRecorder(fixture)
+	test.That(t, err, test.ShouldBeNil)
+
+	device := &fakeHandle{reads: [][]byte{[]byte("$GPGGA"), []byte("*47\r\n")}}
+	bus := RecordI2C(&inject.I2C{OpenHandleFunc: func(addr byte) (buses.I2CHandle, error) {
+		return device, nil
+	}}, "gps", rec)
+	handle, err := bus.OpenHandle(0x42)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, handle.Write(ctx, []byte("PMTK220,1000")), test.ShouldBeNil)
+	data, err := handle.Read(ctx, 1024)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, data, test.ShouldResemble, []byte("$GPGGA"))
+	b, err := handle.ReadByteData(ctx, 7)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, b, test.ShouldEqual, 8)
+	_, err = handle.Read(ctx, 1024)
+	test.That(t, err, test.ShouldBeNil)
+	_, err = handle.Read(ctx, 1024)
+	test.That(t, err, test.ShouldBeError, errors.New("nothing to read"))
+	test.That(t, handle.Close(), test.ShouldBeNil)
+
+	realPort := &fakePort{Reader: bytes.NewReader([]byte{0xd3, 0x00, 0x13})}
+	port := RecordSerial(realPort, "radio", rec)
+	_, err = port.Write([]byte("hello"))
+	test.That(t, err, test.ShouldBeNil)
+	buf := make([]byte, 10)
+	n, err := port.Read(buf)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, buf[:n], test.ShouldResemble, []byte{0xd3, 0x00, 0x13})
+
+	level := true
+	pin := RecordGPIOPin(&inject.GPIOPin{
+		SetFunc: func(ctx context.Context, high bool, extra map[string]interface{}) error {
+			level = high
+			return nil
+		},
+		GetFunc: func(ctx context.Context, extra map[string]interface{}) (bool, error) {
+			return level, nil
+		},
+	}, "18", rec)
+	test.That(t, pin.Set(ctx, false, nil), test.ShouldBeNil)
+	high, err := pin.Get(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, high, test.ShouldBeFalse)
+	test.That(t, rec.Close(), test.ShouldBeNil)
+
+	// replaying does the same without the hardware
+	replayer, err := LoadFixture(fixture)
+	test.That(t, err, test.ShouldBeNil)
+
+	// devices are independent, so they can be replayed in a different order than recorded
+	pin = replayer.GPIOPin("18")
+	test.That(t, pin.Set(ctx, false, nil), test.ShouldBeNil)
+	high, err = pin.Get(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, high, test.ShouldBeFalse)
+
+	port = replayer.Serial("radio")
+	_, err = port.Write([]byte("hello"))
+	test.That(t, err, test.ShouldBeNil)
+	buf = make([]byte, 2)
+	n, err = port.Read(buf)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, buf[:n], test.ShouldResemble, []byte{0xd3, 0x00})
+	n, err = port.Read(buf)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, buf[:n], test.ShouldResemble, []byte{0x13})
+	_, err = port.Read(buf)
+	test.That(t, err, test.ShouldEqual, io.EOF)
+
+	handle, err = replayer.I2C("gps").OpenHandle(0x42)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, handle.Write(ctx, []byte("PMTK220,1000")), test.ShouldBeNil)
+	data, err = handle.Read(ctx, 1024)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, data, test.ShouldResemble, []byte("$GPGGA"))
+	b, err = handle.ReadByteData(ctx, 7)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, b, test.ShouldEqual, 8)
+	test.That(t, replayer.Verify(), test.ShouldNotBeNil)
+	data, err = handle.Read(ctx, 1024)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, data, test.ShouldResemble, []byte("*47\r\n"))
+	_, err = handle.Read(ctx, 1024)
+	test.That(t, err, test.ShouldBeError, errors.New("nothing to read"))
+	test.That(t, replayer.Verify(), test.ShouldBeNil)
+
+	// once the recording runs out, reads wait like a quiet device would
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = handle.Read(timeoutCtx, 1024)
+	test.That(t, err, test.ShouldBeError, context.DeadlineExceeded)
+}
+
+func TestReplayMismatch(t *testing.T) {
+	ctx := context.Background()
+	replayer := NewReplayer([]Transaction{
+		{Device: "i2c:gps:0x42", Op: "write", Data: []byte("PMTK220,1000")},
+		{Device: "i2c:gps:0x42", Op: "read", Data: []byte("$GPGGA")},
+	})
+	handle, err := replayer.I2C("gps").OpenHandle(0x42)
+	test.That(t, err, test.ShouldBeNil)
+
+	// a driver that writes something else is caught
+	err = handle.Write(ctx, []byte("PMTK220,100"))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "PMTK220,100")
+	_, err = handle.ReadByteData(ctx, 1)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "recorded read, but got read_byte_data")
+
+	verifyErr := replayer.Verify()
+	test.That(t, verifyErr, test.ShouldNotBeNil)
+	test.That(t, verifyErr.Error(), test.ShouldContainSubstring, "PMTK220,100")
+}
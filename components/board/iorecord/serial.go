@@ -0,0 +1,75 @@
+package iorecord
+
+import (
+	"io"
+)
+
+// RecordSerial returns a serial port which passes everything through to port, recording it as the
+// named device.
+func RecordSerial(port io.ReadWriteCloser, name string, rec *Recorder) io.ReadWriteCloser {
+	return &recordingSerial{port: port, rec: rec, device: "serial:" + name}
+}
+
+type recordingSerial struct {
+	port   io.ReadWriteCloser
+	rec    *Recorder
+	device string
+}
+
+func (s *recordingSerial) Read(p []byte) (int, error) {
+	n, err := s.port.Read(p)
+	if n > 0 || err != nil {
+		s.rec.record(Transaction{Device: s.device, Op: "read", Data: p[:n], Error: errorString(err)})
+	}
+	return n, err
+}
+
+func (s *recordingSerial) Write(p []byte) (int, error) {
+	n, err := s.port.Write(p)
+	s.rec.record(Transaction{Device: s.device, Op: "write", Data: p[:n], Error: errorString(err)})
+	return n, err
+}
+
+func (s *recordingSerial) Close() error {
+	return s.port.Close()
+}
+
+// Serial returns a serial port which replays the transactions recorded with the named device. Once
+// its recorded reads run out, reads return io.EOF.
+func (r *Replayer) Serial(name string) io.ReadWriteCloser {
+	return &replayingSerial{r: r, device: "serial:" + name}
+}
+
+type replayingSerial struct {
+	r      *Replayer
+	device string
+	// unread is what's left of the last recorded read, when it was bigger than the buffer read into.
+	unread []byte
+}
+
+func (s *replayingSerial) Read(p []byte) (int, error) {
+	if len(s.unread) == 0 {
+		t, err := s.r.take(s.device, "read")
+		if err != nil {
+			return 0, io.EOF
+		}
+		if t.Error != "" {
+			return 0, t.err()
+		}
+		s.unread = t.Data
+	}
+	n := copy(p, s.unread)
+	s.unread = s.unread[n:]
+	return n, nil
+}
+
+func (s *replayingSerial) Write(p []byte) (int, error) {
+	if _, err := s.r.takeWrite(s.device, "write", 0, p, 0); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (s *replayingSerial) Close() error {
+	return nil
+}
@@ -3,7 +3,6 @@ package jetson
 
 import (
 	"github.com/pkg/errors"
-	"periph.io/x/host/v3"
 
 	"go.viam.com/rdk/components/board/genericlinux"
 	"go.viam.com/rdk/logging"
@@ -12,10 +11,6 @@ import (
 const modelName = "jetson"
 
 func init() {
-	if _, err := host.Init(); err != nil {
-		logging.Global().Debugw("error initializing host", "error", err)
-	}
-
 	gpioMappings, err := genericlinux.GetGPIOBoardMappings(modelName, boardInfoMappings)
 	var noBoardErr genericlinux.NoBoardFoundError
 	if errors.As(err, &noBoardErr) {
@@ -13,6 +13,10 @@ import (
 	"go.viam.com/rdk/resource"
 )
 
+// MaxSamplesPerSecond is the fastest an MCP3008 can sample, when powered at 5V. At 2.7V it can only
+// manage 75 ksps.
+const MaxSamplesPerSecond = 200000
+
 // MCP3008AnalogReader implements a board.AnalogReader using an MCP3008 ADC via SPI.
 type MCP3008AnalogReader struct {
 	Channel int
@@ -28,6 +32,11 @@ type MCP3008AnalogConfig struct {
 	ChipSelect        string `json:"chip_select"` // the CS line for the ADC chip, typically a pin number on the board
 	AverageOverMillis int    `json:"average_over_ms,omitempty"`
 	SamplesPerSecond  int    `json:"samples_per_sec,omitempty"`
+	// These are as in board.AnalogReaderConfig.
+	Oversample            int                            `json:"oversample,omitempty"`
+	LowPassCutoffHz       float64                        `json:"low_pass_cutoff_hz,omitempty"`
+	Calibration           []board.AnalogCalibrationPoint `json:"calibration,omitempty"`
+	CalibrationResolution float64                        `json:"calibration_resolution,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -35,7 +44,22 @@ func (config *MCP3008AnalogConfig) Validate(path string) error {
 	if config.Name == "" {
 		return resource.NewConfigValidationFieldRequiredError(path, "name")
 	}
-	return nil
+	readerConfig := config.AnalogReaderConfig()
+	return readerConfig.ValidateProcessing(path)
+}
+
+// AnalogReaderConfig returns how the readings of the MCP3008 should be smoothed and calibrated.
+func (config *MCP3008AnalogConfig) AnalogReaderConfig() board.AnalogReaderConfig {
+	return board.AnalogReaderConfig{
+		Name:                  config.Name,
+		Pin:                   config.Pin,
+		AverageOverMillis:     config.AverageOverMillis,
+		SamplesPerSecond:      config.SamplesPerSecond,
+		Oversample:            config.Oversample,
+		LowPassCutoffHz:       config.LowPassCutoffHz,
+		Calibration:           config.Calibration,
+		CalibrationResolution: config.CalibrationResolution,
+	}
 }
 
 func (mar *MCP3008AnalogReader) Read(ctx context.Context, extra map[string]interface{}) (
@@ -4,8 +4,6 @@ package orangepi
 import (
 	"errors"
 
-	"periph.io/x/host/v3"
-
 	"go.viam.com/rdk/components/board/genericlinux"
 	"go.viam.com/rdk/logging"
 )
@@ -13,10 +11,6 @@ import (
 const modelName = "orangepi"
 
 func init() {
-	if _, err := host.Init(); err != nil {
-		logging.Global().Debugw("error initializing host", "error", err)
-	}
-
 	gpioMappings, err := genericlinux.GetGPIOBoardMappings(modelName, boardInfoMappings)
 	var noBoardErr genericlinux.NoBoardFoundError
 	if errors.As(err, &noBoardErr) {
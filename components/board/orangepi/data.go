@@ -5,6 +5,7 @@ import "go.viam.com/rdk/components/board/genericlinux"
 const (
 	opzero2 = "OrangePi Zero2"
 	op3lts  = "OrangePi 3 LTS"
+	op5     = "OrangePi 5"
 )
 
 var boardInfoMappings = map[string]genericlinux.BoardInformation{
@@ -58,4 +59,29 @@ var boardInfoMappings = map[string]genericlinux.BoardInformation{
 		},
 		Compats: []string{"xunlong,orangepi-3-lts", "allwinner,sun50i-h6"},
 	},
+	op5: {
+		// The 26-pin header is described in the "26 Pin interface" section of the OP 5 user manual.
+		// The RK3588S has 5 GPIO banks of 32 lines each, and each bank is its own gpiochip. None of the header pins are wired to a PWM chip
+		// that is enabled by default, so they all use software PWM.
+		PinDefinitions: []genericlinux.PinDefinition{
+			{Name: "3", DeviceName: "gpiochip1", LineNumber: 15, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "5", DeviceName: "gpiochip1", LineNumber: 14, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "7", DeviceName: "gpiochip1", LineNumber: 22, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "8", DeviceName: "gpiochip4", LineNumber: 3, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "10", DeviceName: "gpiochip4", LineNumber: 4, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "11", DeviceName: "gpiochip4", LineNumber: 10, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "12", DeviceName: "gpiochip0", LineNumber: 29, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "13", DeviceName: "gpiochip4", LineNumber: 11, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "15", DeviceName: "gpiochip0", LineNumber: 28, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "16", DeviceName: "gpiochip1", LineNumber: 27, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "18", DeviceName: "gpiochip1", LineNumber: 26, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "19", DeviceName: "gpiochip1", LineNumber: 17, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "21", DeviceName: "gpiochip1", LineNumber: 16, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "22", DeviceName: "gpiochip2", LineNumber: 28, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "23", DeviceName: "gpiochip1", LineNumber: 18, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "24", DeviceName: "gpiochip1", LineNumber: 20, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "26", DeviceName: "gpiochip1", LineNumber: 3, PwmChipSysfsDir: "", PwmID: -1},
+		},
+		Compats: []string{"xunlong,orangepi-5", "rockchip,rk3588s-orangepi-5"},
+	},
 }
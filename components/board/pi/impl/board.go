@@ -241,9 +241,7 @@ func (pi *piPigpio) reconfigureAnalogReaders(ctx context.Context, cfg *Config) e
 		bus := &piPigpioSPI{pi: pi, busSelect: ac.SPIBus}
 		ar := &mcp3008helper.MCP3008AnalogReader{channel, bus, ac.ChipSelect}
 
-		pi.analogReaders[ac.Name] = pinwrappers.SmoothAnalogReader(ar, board.AnalogReaderConfig{
-			AverageOverMillis: ac.AverageOverMillis, SamplesPerSecond: ac.SamplesPerSecond,
-		}, pi.logger)
+		pi.analogReaders[ac.Name] = pinwrappers.SmoothAnalogReader(ar, ac.AnalogReaderConfig(), pi.logger)
 	}
 	return nil
 }
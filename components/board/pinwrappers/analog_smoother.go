@@ -5,6 +5,7 @@ package pinwrappers
 
 import (
 	"context"
+	"math"
 	"sync/atomic"
 	"time"
 
@@ -19,31 +20,49 @@ import (
 
 var errStopReading = errors.New("stop reading")
 
-// An AnalogSmoother smooths the readings out from an underlying reader.
+const defaultCalibrationResolution = 0.001
+
+// An AnalogSmoother smooths the readings out from an underlying reader. Each sample is the average
+// of Oversample raw readings, passed through a low-pass filter if LowPassCutoffHz is set, and
+// readings are converted into engineering units if there is a Calibration.
 type AnalogSmoother struct {
-	Raw               board.Analog
-	AverageOverMillis int
-	SamplesPerSecond  int
-	data              *utils.RollingAverage
-	lastData          int
-	lastError         atomic.Pointer[errValue]
-	logger            logging.Logger
-	workers           utils.StoppableWorkers
-	analogVal         board.AnalogValue
+	Raw                   board.Analog
+	AverageOverMillis     int
+	SamplesPerSecond      int
+	Oversample            int
+	LowPassCutoffHz       float64
+	Calibration           []board.AnalogCalibrationPoint
+	CalibrationResolution float64
+	data                  *utils.RollingAverage
+	lastData              int
+	lastError             atomic.Pointer[errValue]
+	logger                logging.Logger
+	workers               utils.StoppableWorkers
+	analogVal             board.AnalogValue
 }
 
 // SmoothAnalogReader wraps the given reader in a smoother.
 func SmoothAnalogReader(r board.Analog, c board.AnalogReaderConfig, logger logging.Logger) *AnalogSmoother {
 	smoother := &AnalogSmoother{
-		Raw:               r,
-		AverageOverMillis: c.AverageOverMillis,
-		SamplesPerSecond:  c.SamplesPerSecond,
-		logger:            logger,
+		Raw:                   r,
+		AverageOverMillis:     c.AverageOverMillis,
+		SamplesPerSecond:      c.SamplesPerSecond,
+		Oversample:            c.Oversample,
+		LowPassCutoffHz:       c.LowPassCutoffHz,
+		Calibration:           c.Calibration,
+		CalibrationResolution: c.CalibrationResolution,
+		logger:                logger,
 	}
 	if smoother.SamplesPerSecond <= 0 {
 		logger.Debug("Can't read nonpositive samples per second; defaulting to 1 instead")
 		smoother.SamplesPerSecond = 1
 	}
+	if smoother.Oversample <= 0 {
+		smoother.Oversample = 1
+	}
+	if smoother.CalibrationResolution <= 0 {
+		smoother.CalibrationResolution = defaultCalibrationResolution
+	}
 
 	// Store the analog reader info
 	analogVal, err := smoother.Raw.Read(context.Background(), nil)
@@ -76,11 +95,12 @@ func (as *AnalogSmoother) Read(ctx context.Context, extra map[string]interface{}
 
 	if as.data == nil { // We're using raw data, and not averaging
 		analogVal.Value = as.lastData
-		return as.analogVal, nil
+		return as.calibrate(analogVal), nil
 	}
 	avg := as.data.Average()
 	lastErr := as.lastError.Load()
 	analogVal.Value = avg
+	analogVal = as.calibrate(analogVal)
 	if lastErr == nil {
 		return analogVal, nil
 	}
@@ -118,6 +138,8 @@ func (as *AnalogSmoother) Start() {
 		as.data = nil
 	}
 
+	filter := newLowPassFilter(as.LowPassCutoffHz, as.SamplesPerSecond)
+
 	as.workers = utils.NewStoppableWorkers(func(ctx context.Context) {
 		consecutiveErrors := 0
 		var lastError error
@@ -129,12 +151,13 @@ func (as *AnalogSmoother) Start() {
 			default:
 			}
 			start := time.Now()
-			reading, err := as.Raw.Read(ctx, nil)
+			sample, err := as.readSample(ctx)
 			as.lastError.Store(&errValue{err != nil, err})
 			if err == nil {
-				as.lastData = reading.Value
+				value := int(math.Round(filter.add(sample)))
+				as.lastData = value
 				if as.data != nil {
-					as.data.Add(reading.Value)
+					as.data.Add(value)
 				}
 				consecutiveErrors = 0
 			} else { // Non-nil error
@@ -165,6 +188,88 @@ func (as *AnalogSmoother) Start() {
 	})
 }
 
+// lowPassFilter is a first order low-pass filter, which moves alpha of the way from its last
+// output towards each new sample.
+type lowPassFilter struct {
+	alpha   float64
+	output  float64
+	started bool
+}
+
+// newLowPassFilter returns a filter with the given cutoff for samples taken at samplesPerSecond. A
+// cutoff of 0 passes samples through unchanged.
+func newLowPassFilter(cutoffHz float64, samplesPerSecond int) *lowPassFilter {
+	if cutoffHz <= 0 {
+		return &lowPassFilter{alpha: 1}
+	}
+	dt := 1 / float64(samplesPerSecond)
+	rc := 1 / (2 * math.Pi * cutoffHz)
+	return &lowPassFilter{alpha: dt / (rc + dt)}
+}
+
+func (f *lowPassFilter) add(sample float64) float64 {
+	if !f.started {
+		f.output, f.started = sample, true
+		return sample
+	}
+	f.output += f.alpha * (sample - f.output)
+	return f.output
+}
+
+// readSample averages Oversample readings of the underlying reader.
+func (as *AnalogSmoother) readSample(ctx context.Context) (float64, error) {
+	oversample := as.Oversample
+	if oversample <= 0 {
+		oversample = 1
+	}
+	sum := 0
+	for i := 0; i < oversample; i++ {
+		reading, err := as.Raw.Read(ctx, nil)
+		if err != nil {
+			return 0, err
+		}
+		sum += reading.Value
+	}
+	return float64(sum) / float64(oversample), nil
+}
+
+// calibrate converts a reading into engineering units, if there is a calibration. The value then
+// counts steps of CalibrationResolution, which is reported as the step size, so that value * step
+// size is in engineering units just as it is otherwise in volts.
+func (as *AnalogSmoother) calibrate(analogVal board.AnalogValue) board.AnalogValue {
+	if len(as.Calibration) < 2 {
+		return analogVal
+	}
+	resolution := as.CalibrationResolution
+	if resolution <= 0 {
+		resolution = defaultCalibrationResolution
+	}
+	calibrated := board.AnalogValue{
+		Value:    int(math.Round(interpolate(as.Calibration, float64(analogVal.Value)) / resolution)),
+		StepSize: float32(resolution),
+	}
+	// the range of engineering units is wherever the range of raw readings maps to
+	low := interpolate(as.Calibration, as.Calibration[0].Raw)
+	high := interpolate(as.Calibration, as.Calibration[len(as.Calibration)-1].Raw)
+	if analogVal.StepSize > 0 {
+		low = interpolate(as.Calibration, float64(analogVal.Min/analogVal.StepSize))
+		high = interpolate(as.Calibration, float64(analogVal.Max/analogVal.StepSize))
+	}
+	calibrated.Min, calibrated.Max = float32(math.Min(low, high)), float32(math.Max(low, high))
+	return calibrated
+}
+
+// interpolate linearly interpolates between the points either side of raw, or extrapolates from
+// the nearest two points if raw is outside them all. points must be sorted by raw.
+func interpolate(points []board.AnalogCalibrationPoint, raw float64) float64 {
+	i := 1
+	for i < len(points)-1 && raw > points[i].Raw {
+		i++
+	}
+	a, b := points[i-1], points[i]
+	return a.Value + (raw-a.Raw)*(b.Value-a.Value)/(b.Raw-a.Raw)
+}
+
 func (as *AnalogSmoother) Write(ctx context.Context, value int, extra map[string]interface{}) error {
 	return grpc.UnimplementedError
 }
@@ -75,3 +75,76 @@ func TestAnalogSmoother1(t *testing.T) {
 
 	test.That(t, as.Close(context.Background()), test.ShouldBeNil)
 }
+
+// sequenceAnalog returns its values in turn, and then keeps returning the last one.
+type sequenceAnalog struct {
+	mu     sync.Mutex
+	values []int
+	reads  int
+}
+
+func (s *sequenceAnalog) Read(ctx context.Context, extra map[string]interface{}) (board.AnalogValue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value := s.values[len(s.values)-1]
+	if s.reads < len(s.values) {
+		value = s.values[s.reads]
+	}
+	s.reads++
+	return board.AnalogValue{Value: value, Min: 0, Max: 3.3, StepSize: 3.3 / 1024}, nil
+}
+
+func (s *sequenceAnalog) Write(ctx context.Context, value int, extra map[string]interface{}) error {
+	return grpc.UnimplementedError
+}
+
+func TestAnalogOversample(t *testing.T) {
+	as := &AnalogSmoother{Raw: &sequenceAnalog{values: []int{10, 20, 30, 40}}, Oversample: 4}
+	sample, err := as.readSample(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, sample, test.ShouldEqual, 25)
+}
+
+func TestAnalogLowPass(t *testing.T) {
+	// sampling at 100Hz with a 1Hz cutoff, each output moves about 6% of the way to the sample
+	f := newLowPassFilter(1, 100)
+	test.That(t, f.add(0), test.ShouldEqual, 0)
+	test.That(t, f.add(1000), test.ShouldAlmostEqual, 59.1, 0.1)
+	test.That(t, f.add(1000), test.ShouldAlmostEqual, 114.8, 0.1)
+	for i := 0; i < 200; i++ {
+		f.add(1000)
+	}
+	test.That(t, f.add(1000), test.ShouldAlmostEqual, 1000, 0.1)
+
+	// without a cutoff, samples are passed through
+	f = newLowPassFilter(0, 100)
+	test.That(t, f.add(0), test.ShouldEqual, 0)
+	test.That(t, f.add(1000), test.ShouldEqual, 1000)
+}
+
+func TestAnalogCalibration(t *testing.T) {
+	as := &AnalogSmoother{
+		Calibration: []board.AnalogCalibrationPoint{
+			{Raw: 0, Value: 0},
+			{Raw: 512, Value: 6},
+			{Raw: 1024, Value: 16},
+		},
+		CalibrationResolution: 0.01,
+	}
+	v := as.calibrate(board.AnalogValue{Value: 256, Min: 0, Max: 3.3, StepSize: 3.3 / 1024})
+	test.That(t, v.Value, test.ShouldEqual, 300)
+	test.That(t, v.StepSize, test.ShouldAlmostEqual, 0.01)
+	test.That(t, v.Min, test.ShouldEqual, 0)
+	test.That(t, v.Max, test.ShouldAlmostEqual, 16, 1e-4)
+
+	v = as.calibrate(board.AnalogValue{Value: 768})
+	test.That(t, v.Value, test.ShouldEqual, 1100)
+	// readings past the last point are extrapolated
+	v = as.calibrate(board.AnalogValue{Value: 1280})
+	test.That(t, v.Value, test.ShouldEqual, 2100)
+
+	// without a calibration, readings are unchanged
+	as.Calibration = nil
+	raw := board.AnalogValue{Value: 256, Min: 0, Max: 3.3, StepSize: 3.3 / 1024}
+	test.That(t, as.calibrate(raw), test.ShouldResemble, raw)
+}
@@ -0,0 +1,175 @@
+package pinwrappers
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/utils"
+)
+
+// An AnalogChunk is a run of consecutive raw samples from an AnalogStream, the first of which was
+// taken at Start.
+type AnalogChunk struct {
+	Start   time.Time
+	Samples []int
+}
+
+// An AnalogStream samples an analog reader at a fixed rate in the background, for signals too fast
+// to follow through individual reads, such as motor current or vibration. Samples are delivered in
+// chunks, and the oldest chunks are dropped if they are not collected quickly enough.
+type AnalogStream struct {
+	SampleRateHz float64
+	ChunkSize    int
+
+	raw     board.Analog
+	logger  logging.Logger
+	workers utils.StoppableWorkers
+
+	mu        sync.Mutex
+	chunks    []AnalogChunk
+	maxChunks int
+	dropped   int
+	overruns  int
+	lastError error
+	lastRead  time.Time
+
+	closed atomic.Bool
+}
+
+// StreamAnalog starts sampling r at sampleRateHz, collecting chunkSize samples per chunk and keeping
+// at most maxChunks of them.
+func StreamAnalog(
+	r board.Analog, sampleRateHz float64, chunkSize, maxChunks int, logger logging.Logger,
+) (*AnalogStream, error) {
+	if sampleRateHz <= 0 {
+		return nil, errors.New("sample rate must be positive")
+	}
+	if chunkSize <= 0 || maxChunks <= 0 {
+		return nil, errors.New("chunk size and number of chunks must be positive")
+	}
+	s := &AnalogStream{
+		SampleRateHz: sampleRateHz,
+		ChunkSize:    chunkSize,
+		raw:          r,
+		logger:       logger,
+		maxChunks:    maxChunks,
+		lastRead:     time.Now(),
+	}
+	s.workers = utils.NewStoppableWorkers(s.sample)
+	return s, nil
+}
+
+// sample reads the analog on a fixed schedule rather than sleeping between reads, so that the time
+// each read takes doesn't stretch the sample period. If reads fall behind the schedule, the stream
+// skips ahead rather than bunching samples together, and counts an overrun.
+func (s *AnalogStream) sample(ctx context.Context) {
+	period := time.Duration(float64(time.Second) / s.SampleRateHz)
+	chunk := AnalogChunk{Samples: make([]int, 0, s.ChunkSize)}
+	next := time.Now()
+	for {
+		if wait := time.Until(next); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		} else if ctx.Err() != nil {
+			return
+		}
+
+		if len(chunk.Samples) == 0 {
+			chunk.Start = next
+		}
+		reading, err := s.raw.Read(ctx, nil)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.fail(err)
+			// a gap in the samples would misrepresent the signal, so start a new chunk afterwards
+			chunk.Samples = chunk.Samples[:0]
+			next = time.Now().Add(period)
+			continue
+		}
+		chunk.Samples = append(chunk.Samples, reading.Value)
+		if len(chunk.Samples) == s.ChunkSize {
+			s.push(chunk)
+			chunk = AnalogChunk{Samples: make([]int, 0, s.ChunkSize)}
+		}
+
+		next = next.Add(period)
+		if behind := time.Since(next); behind > period {
+			s.mu.Lock()
+			s.overruns++
+			s.mu.Unlock()
+			if len(chunk.Samples) > 0 {
+				s.push(chunk)
+				chunk = AnalogChunk{Samples: make([]int, 0, s.ChunkSize)}
+			}
+			next = time.Now()
+		}
+	}
+}
+
+func (s *AnalogStream) push(chunk AnalogChunk) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.chunks) == s.maxChunks {
+		s.chunks = s.chunks[1:]
+		s.dropped++
+	}
+	s.chunks = append(s.chunks, chunk)
+	s.lastError = nil
+}
+
+func (s *AnalogStream) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lastError == nil || s.lastError.Error() != err.Error() {
+		s.logger.Infow("error streaming analog", "error", err)
+	}
+	s.lastError = err
+}
+
+// AnalogStreamStatus describes what happened to a stream since it was last read.
+type AnalogStreamStatus struct {
+	// DroppedChunks counts chunks discarded because they were not read in time.
+	DroppedChunks int
+	// Overruns counts the times reading the analog fell more than a sample behind, leaving a gap.
+	Overruns int
+	// LastError is the most recent error reading the analog, if no chunk has completed since.
+	LastError error
+}
+
+// Read returns and forgets the chunks collected since the last read.
+func (s *AnalogStream) Read() ([]AnalogChunk, AnalogStreamStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	chunks := s.chunks
+	status := AnalogStreamStatus{DroppedChunks: s.dropped, Overruns: s.overruns, LastError: s.lastError}
+	s.chunks = nil
+	s.dropped = 0
+	s.overruns = 0
+	s.lastRead = time.Now()
+	return chunks, status
+}
+
+// IdleFor returns how long it has been since the stream was last read.
+func (s *AnalogStream) IdleFor() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Since(s.lastRead)
+}
+
+// Close stops sampling.
+func (s *AnalogStream) Close() {
+	if s.closed.CompareAndSwap(false, true) {
+		s.workers.Stop()
+	}
+}
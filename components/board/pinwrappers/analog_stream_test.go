@@ -0,0 +1,81 @@
+package pinwrappers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/grpc"
+	"go.viam.com/rdk/logging"
+)
+
+// countingAnalog reads as the number of reads made so far, or fails with err if it is set.
+type countingAnalog struct {
+	mu  sync.Mutex
+	n   int
+	err error
+}
+
+func (c *countingAnalog) Read(ctx context.Context, extra map[string]interface{}) (board.AnalogValue, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return board.AnalogValue{}, c.err
+	}
+	c.n++
+	return board.AnalogValue{Value: c.n}, nil
+}
+
+func (c *countingAnalog) Write(ctx context.Context, value int, extra map[string]interface{}) error {
+	return grpc.UnimplementedError
+}
+
+func TestAnalogStream(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	_, err := StreamAnalog(&countingAnalog{}, 0, 10, 10, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = StreamAnalog(&countingAnalog{}, 1000, 0, 10, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	reader := &countingAnalog{}
+	stream, err := StreamAnalog(reader, 1000, 10, 3, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer stream.Close()
+
+	// only the newest chunks are kept while nothing reads them
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		stream.mu.Lock()
+		defer stream.mu.Unlock()
+		test.That(tb, stream.dropped, test.ShouldBeGreaterThan, 0)
+	})
+	chunks, status := stream.Read()
+	test.That(t, status.DroppedChunks, test.ShouldBeGreaterThan, 0)
+	test.That(t, status.LastError, test.ShouldBeNil)
+	test.That(t, len(chunks), test.ShouldBeBetweenOrEqual, 1, 3)
+	for _, chunk := range chunks {
+		test.That(t, len(chunk.Samples), test.ShouldBeLessThanOrEqualTo, 10)
+		for i := 1; i < len(chunk.Samples); i++ {
+			test.That(t, chunk.Samples[i], test.ShouldEqual, chunk.Samples[i-1]+1)
+		}
+	}
+	test.That(t, stream.IdleFor(), test.ShouldBeLessThan, time.Second)
+
+	reader.mu.Lock()
+	reader.err = errors.New("spi unplugged")
+	reader.mu.Unlock()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		_, status := stream.Read()
+		test.That(tb, status.LastError, test.ShouldNotBeNil)
+	})
+
+	stream.Close()
+	stream.Close()
+}
@@ -7,11 +7,13 @@ import (
 	_ "go.viam.com/rdk/components/board/customlinux"
 	_ "go.viam.com/rdk/components/board/fake"
 	_ "go.viam.com/rdk/components/board/hat/pca9685"
+	_ "go.viam.com/rdk/components/board/hub"
 	_ "go.viam.com/rdk/components/board/jetson"
 	_ "go.viam.com/rdk/components/board/numato"
 	_ "go.viam.com/rdk/components/board/odroid"
 	_ "go.viam.com/rdk/components/board/orangepi"
 	_ "go.viam.com/rdk/components/board/pi5"
+	_ "go.viam.com/rdk/components/board/rockpi"
 	_ "go.viam.com/rdk/components/board/ti"
 	_ "go.viam.com/rdk/components/board/upboard"
 )
@@ -0,0 +1,21 @@
+// Package rockpi implements a Radxa Rock Pi based board.
+package rockpi
+
+import (
+	"errors"
+
+	"go.viam.com/rdk/components/board/genericlinux"
+	"go.viam.com/rdk/logging"
+)
+
+const modelName = "rockpi"
+
+func init() {
+	gpioMappings, err := genericlinux.GetGPIOBoardMappings(modelName, boardInfoMappings)
+	var noBoardErr genericlinux.NoBoardFoundError
+	if errors.As(err, &noBoardErr) {
+		logging.Global().Debugw("error getting rockpi GPIO board mapping", "error", err)
+	}
+
+	genericlinux.RegisterBoard(modelName, gpioMappings)
+}
@@ -0,0 +1,44 @@
+package rockpi
+
+import "go.viam.com/rdk/components/board/genericlinux"
+
+const rockpi4 = "Rock Pi 4"
+
+var boardInfoMappings = map[string]genericlinux.BoardInformation{
+	rockpi4: {
+		// Rock Pi 4 GPIO documentation: https://wiki.radxa.com/Rockpi4/hardware/gpio
+		// The RK3399 has 5 GPIO banks of 32 lines each, and each bank is its own gpiochip. Pin 26 is
+		// an ADC input rather than a GPIO, so it is omitted. Pins 11 and 13 can be muxed to PWM0 and
+		// PWM1, but that requires a device tree overlay, so by default they use software PWM.
+		PinDefinitions: []genericlinux.PinDefinition{
+			{Name: "3", DeviceName: "gpiochip2", LineNumber: 7, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "5", DeviceName: "gpiochip2", LineNumber: 8, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "7", DeviceName: "gpiochip2", LineNumber: 11, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "8", DeviceName: "gpiochip4", LineNumber: 20, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "10", DeviceName: "gpiochip4", LineNumber: 19, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "11", DeviceName: "gpiochip4", LineNumber: 18, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "12", DeviceName: "gpiochip4", LineNumber: 3, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "13", DeviceName: "gpiochip4", LineNumber: 22, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "15", DeviceName: "gpiochip4", LineNumber: 21, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "16", DeviceName: "gpiochip4", LineNumber: 26, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "18", DeviceName: "gpiochip4", LineNumber: 28, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "19", DeviceName: "gpiochip1", LineNumber: 8, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "21", DeviceName: "gpiochip1", LineNumber: 7, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "22", DeviceName: "gpiochip4", LineNumber: 29, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "23", DeviceName: "gpiochip1", LineNumber: 9, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "24", DeviceName: "gpiochip1", LineNumber: 10, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "27", DeviceName: "gpiochip2", LineNumber: 0, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "28", DeviceName: "gpiochip2", LineNumber: 1, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "29", DeviceName: "gpiochip2", LineNumber: 10, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "31", DeviceName: "gpiochip2", LineNumber: 9, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "32", DeviceName: "gpiochip3", LineNumber: 16, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "33", DeviceName: "gpiochip2", LineNumber: 12, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "35", DeviceName: "gpiochip4", LineNumber: 5, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "36", DeviceName: "gpiochip4", LineNumber: 4, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "37", DeviceName: "gpiochip4", LineNumber: 30, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "38", DeviceName: "gpiochip4", LineNumber: 6, PwmChipSysfsDir: "", PwmID: -1},
+			{Name: "40", DeviceName: "gpiochip4", LineNumber: 7, PwmChipSysfsDir: "", PwmID: -1},
+		},
+		Compats: []string{"radxa,rockpi4", "radxa,rockpi4a", "radxa,rockpi4b", "radxa,rockpi4c"},
+	},
+}
@@ -3,7 +3,9 @@ package board
 
 import (
 	"context"
+	"time"
 
+	"github.com/pkg/errors"
 	commonpb "go.viam.com/api/common/v1"
 	pb "go.viam.com/api/component/board/v1"
 
@@ -231,6 +233,14 @@ func (s *serviceServer) StreamTicks(
 		return err
 	}
 
+	coalesceInterval, err := coalesceIntervalFromExtra(req.Extra.AsMap())
+	if err != nil {
+		return err
+	}
+	if coalesceInterval > 0 {
+		return sendCoalescedTicks(server, ticksChan, coalesceInterval)
+	}
+
 	for {
 		select {
 		case <-server.Context().Done():
@@ -250,6 +260,52 @@ func (s *serviceServer) StreamTicks(
 	}
 }
 
+// coalesceIntervalFromExtra reads the optional StreamTicksCoalesceIntervalKey out of the extra
+// parameters of a StreamTicks request. A zero duration means ticks should not be coalesced.
+func coalesceIntervalFromExtra(extra map[string]interface{}) (time.Duration, error) {
+	raw, ok := extra[StreamTicksCoalesceIntervalKey]
+	if !ok {
+		return 0, nil
+	}
+	ms, ok := raw.(float64)
+	if !ok || ms < 0 {
+		return 0, errors.Errorf("%s must be a non-negative number of milliseconds", StreamTicksCoalesceIntervalKey)
+	}
+	return time.Duration(ms * float64(time.Millisecond)), nil
+}
+
+// sendCoalescedTicks forwards ticks to the client at most once per interval per pin. Only the most
+// recent tick on each pin is sent, which keeps the stream usable for fast pulse trains (e.g.,
+// wheel encoders) over slow connections, at the cost of dropping intermediate edges.
+func sendCoalescedTicks(server pb.BoardService_StreamTicksServer, ticksChan chan Tick, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	pending := map[string]Tick{}
+	order := []string{}
+	for {
+		select {
+		case <-server.Context().Done():
+			return server.Context().Err()
+		case msg := <-ticksChan:
+			if _, ok := pending[msg.Name]; !ok {
+				order = append(order, msg.Name)
+			}
+			pending[msg.Name] = msg
+		case <-ticker.C:
+			for _, name := range order {
+				msg := pending[name]
+				err := server.Send(&pb.StreamTicksResponse{PinName: msg.Name, High: msg.High, Time: msg.TimestampNanosec})
+				if err != nil {
+					return err
+				}
+			}
+			pending = map[string]Tick{}
+			order = order[:0]
+		}
+	}
+}
+
 // DoCommand receives arbitrary commands.
 func (s *serviceServer) DoCommand(ctx context.Context,
 	req *commonpb.DoCommandRequest,
@@ -875,3 +875,55 @@ func TestStreamTicks(t *testing.T) {
 		})
 	}
 }
+
+func TestStreamTicksCoalesced(t *testing.T) {
+	server, injectBoard, err := newServer()
+	test.That(t, err, test.ShouldBeNil)
+
+	var tickChan chan board.Tick
+	injectBoard.StreamTicksFunc = func(
+		ctx context.Context, interrupts []board.DigitalInterrupt, ch chan board.Tick,
+		extra map[string]interface{},
+	) error {
+		tickChan = ch
+		return nil
+	}
+	injectBoard.DigitalInterruptByNameFunc = func(name string) (board.DigitalInterrupt, error) {
+		return &inject.DigitalInterrupt{}, nil
+	}
+
+	extra, err := protoutils.StructToStructPb(map[string]interface{}{board.StreamTicksCoalesceIntervalKey: 200.})
+	test.That(t, err, test.ShouldBeNil)
+	req := &pb.StreamTicksRequest{Name: testBoardName, PinNames: []string{"digital1", "digital2"}, Extra: extra}
+
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := &streamTicksServer{ctx: cancelCtx, ticksChan: make(chan *pb.StreamTicksResponse)}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		err = server.StreamTicks(req, s)
+	}()
+
+	// First resp will be blank
+	<-s.ticksChan
+
+	tickChan <- board.Tick{Name: "digital1", High: true, TimestampNanosec: 1}
+	tickChan <- board.Tick{Name: "digital2", High: true, TimestampNanosec: 2}
+	tickChan <- board.Tick{Name: "digital1", High: false, TimestampNanosec: 3}
+
+	// Only the latest tick on each pin is sent, in the order the pins first ticked.
+	resp := <-s.ticksChan
+	test.That(t, resp.PinName, test.ShouldEqual, "digital1")
+	test.That(t, resp.High, test.ShouldBeFalse)
+	test.That(t, resp.Time, test.ShouldEqual, 3)
+	resp = <-s.ticksChan
+	test.That(t, resp.PinName, test.ShouldEqual, "digital2")
+	test.That(t, resp.Time, test.ShouldEqual, 2)
+
+	cancel()
+	wg.Wait()
+	test.That(t, err, test.ShouldNotBeNil)
+}
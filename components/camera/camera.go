@@ -277,6 +277,22 @@ func NewPinholeModelWithBrownConradyDistortion(pinholeCameraIntrinsics *transfor
 	return cameraModel
 }
 
+// NewPinholeModelWithKannalaBrandtDistortion creates a transform.PinholeCameraModel from
+// a *transform.PinholeCameraIntrinsics and a *transform.KannalaBrandt, for fisheye lenses.
+// As with NewPinholeModelWithBrownConradyDistortion, a nil distortion leaves
+// transform.PinholeCameraModel.Distortion unset.
+func NewPinholeModelWithKannalaBrandtDistortion(pinholeCameraIntrinsics *transform.PinholeCameraIntrinsics,
+	distortion *transform.KannalaBrandt,
+) transform.PinholeCameraModel {
+	var cameraModel transform.PinholeCameraModel
+	cameraModel.PinholeCameraIntrinsics = pinholeCameraIntrinsics
+
+	if distortion != nil {
+		cameraModel.Distortion = distortion
+	}
+	return cameraModel
+}
+
 // NewPropertiesError returns an error specific to a failure in Properties.
 func NewPropertiesError(cameraIdentifier string) error {
 	return errors.Errorf("failed to get properties from %s", cameraIdentifier)
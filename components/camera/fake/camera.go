@@ -1,4 +1,5 @@
-// Package fake implements a fake camera which always returns the same image with a user specified resolution.
+// Package fake implements a fake camera which always returns the same image with a user specified resolution,
+// or replays the images in a directory.
 package fake
 
 import (
@@ -9,6 +10,9 @@ import (
 	"image/color"
 	"image/jpeg"
 	"math"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -39,6 +43,8 @@ var (
 const (
 	initialWidth  = 1280
 	initialHeight = 720
+	// defaultFrameRate is how many images a second are replayed from an image directory by default.
+	defaultFrameRate = 10
 )
 
 func init() {
@@ -78,6 +84,16 @@ func NewCamera(
 		bufAndCBByID:   make(map[rtppassthrough.SubscriptionID]bufAndCB),
 		logger:         logger,
 	}
+	if newConf.ImageDir != "" {
+		if cam.imagePaths, err = listImages(newConf.ImageDir); err != nil {
+			return nil, err
+		}
+		cam.frameRate = newConf.FrameRate
+		if cam.frameRate == 0 {
+			cam.frameRate = defaultFrameRate
+		}
+		cam.replayStart = time.Now()
+	}
 	src, err := camera.NewVideoSourceFromReader(ctx, cam, resModel, camera.ColorStream)
 	if err != nil {
 		return nil, err
@@ -106,6 +122,11 @@ type Config struct {
 	Height         int  `json:"height,omitempty"`
 	Animated       bool `json:"animated,omitempty"`
 	RTPPassthrough bool `json:"rtp_passthrough,omitempty"`
+	// ImageDir is a directory of JPEG or PNG images to replay in order of file name, looping back
+	// to the first once they run out, instead of generating images.
+	ImageDir string `json:"image_dir,omitempty"`
+	// FrameRate is how many images a second are replayed from ImageDir. Defaults to 10.
+	FrameRate float64 `json:"frame_rate,omitempty"`
 }
 
 // Validate checks that the config attributes are valid for a fake camera.
@@ -126,6 +147,14 @@ func (conf *Config) Validate(path string) ([]string, error) {
 		return nil, errors.Errorf("odd-number resolutions cannot be rendered, cannot use a width of %d", conf.Width)
 	}
 
+	if conf.FrameRate < 0 {
+		return nil, errors.New("frame_rate cannot be negative")
+	}
+
+	if conf.ImageDir != "" && conf.RTPPassthrough {
+		return nil, errors.New("image_dir cannot be used with rtp_passthrough")
+	}
+
 	return nil, nil
 }
 
@@ -219,10 +248,45 @@ type Camera struct {
 	cacheImage              image.Image
 	cachePointCloud         pointcloud.PointCloud
 	logger                  logging.Logger
+
+	// imagePaths are the images replayed, at frameRate images a second since replayStart.
+	imagePaths  []string
+	frameRate   float64
+	replayStart time.Time
 }
 
-// Read always returns the same image of a yellow to blue gradient.
+// listImages returns the paths of the images in a directory, ordered by file name.
+func listImages(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".jpg", ".jpeg", ".png":
+			if !entry.IsDir() {
+				paths = append(paths, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	if len(paths) == 0 {
+		return nil, errors.Errorf("no JPEG or PNG images to replay in %q", dir)
+	}
+	return paths, nil
+}
+
+// Read always returns the same image of a yellow to blue gradient, unless it's replaying the images
+// in a directory, in which case it returns whichever image is due.
 func (c *Camera) Read(ctx context.Context) (image.Image, func(), error) {
+	if len(c.imagePaths) > 0 {
+		frame := int64(time.Since(c.replayStart).Seconds()*c.frameRate) % int64(len(c.imagePaths))
+		img, err := rimage.NewImageFromFile(c.imagePaths[frame])
+		if err != nil {
+			return nil, nil, err
+		}
+		return img, func() {}, nil
+	}
 	if c.cacheImage != nil {
 		return c.cacheImage, func() {}, nil
 	}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"image"
+	"image/color"
+	"path/filepath"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/pion/rtp"
@@ -15,6 +18,7 @@ import (
 	"go.viam.com/rdk/components/camera/rtppassthrough"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
 	"go.viam.com/rdk/rimage/transform"
 )
 
@@ -251,3 +255,44 @@ func TestRTPPassthrough(t *testing.T) {
 		test.That(t, camera.Close(context.Background()), test.ShouldBeNil)
 	})
 }
+
+func TestImageDirReplay(t *testing.T) {
+	dir := t.TempDir()
+	for i, c := range []color.RGBA{{R: 255, A: 255}, {B: 255, A: 255}} {
+		img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+		for x := 0; x < 4; x++ {
+			for y := 0; y < 2; y++ {
+				img.Set(x, y, c)
+			}
+		}
+		test.That(t, rimage.WriteImageToFile(filepath.Join(dir, []string{"a.png", "b.png"}[i]), img), test.ShouldBeNil)
+	}
+
+	paths, err := listImages(dir)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, paths, test.ShouldResemble, []string{filepath.Join(dir, "a.png"), filepath.Join(dir, "b.png")})
+	_, err = listImages(t.TempDir())
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// one image a second, so the second image is due after one second and the first again after two
+	cam := &Camera{imagePaths: paths, frameRate: 1, replayStart: time.Now()}
+	for _, tc := range []struct {
+		ago  time.Duration
+		want color.Color
+	}{
+		{0, color.RGBA{R: 255, A: 255}},
+		{1500 * time.Millisecond, color.RGBA{B: 255, A: 255}},
+		{2500 * time.Millisecond, color.RGBA{R: 255, A: 255}},
+	} {
+		cam.replayStart = time.Now().Add(-tc.ago)
+		img, _, err := cam.Read(context.Background())
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, img.Bounds().Dx(), test.ShouldEqual, 4)
+		r, g, b, _ := img.At(0, 0).RGBA()
+		wr, wg, wb, _ := tc.want.RGBA()
+		test.That(t, []uint32{r, g, b}, test.ShouldResemble, []uint32{wr, wg, wb})
+	}
+
+	_, err = (&Config{ImageDir: dir, RTPPassthrough: true}).Validate("")
+	test.That(t, err, test.ShouldNotBeNil)
+}
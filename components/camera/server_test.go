@@ -116,7 +116,7 @@ func TestServer(t *testing.T) {
 			imageReleasedMu.Unlock()
 			mimeType, _ := utils.CheckLazyMIMEType(gostream.MIMETypeHint(ctx, utils.MimeTypeRawRGBA))
 			switch mimeType {
-			case "", utils.MimeTypeRawRGBA:
+			case "", utils.MimeTypeRawRGBA, utils.MimeTypeRawI420:
 				return img, func() {}, nil
 			case utils.MimeTypePNG:
 				return imgPng, func() {}, nil
@@ -229,6 +229,17 @@ func TestServer(t *testing.T) {
 		test.That(t, resp.MimeType, test.ShouldEqual, utils.MimeTypePNG)
 		test.That(t, resp.Image, test.ShouldResemble, imgBuf.Bytes())
 
+		// ensure that raw YUV can be requested instead of having to decode a JPEG
+		resp, err = cameraServer.GetImage(context.Background(), &pb.GetImageRequest{
+			Name:     testCameraName,
+			MimeType: utils.MimeTypeRawI420,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp.MimeType, test.ShouldEqual, utils.MimeTypeRawI420)
+		decodedYUV, err := rimage.DecodeImage(context.Background(), resp.Image, resp.MimeType)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, decodedYUV.Bounds(), test.ShouldResemble, img.Bounds())
+
 		imageReleasedMu.Lock()
 		imageReleased = false
 		imageReleasedMu.Unlock()
@@ -18,6 +18,8 @@ import (
 type undistortConfig struct {
 	CameraParams     *transform.PinholeCameraIntrinsics `json:"intrinsic_parameters"`
 	DistortionParams *transform.BrownConrady            `json:"distortion_parameters"`
+	// FisheyeParams is used instead of DistortionParams for wide-angle lenses.
+	FisheyeParams *transform.KannalaBrandt `json:"fisheye_distortion_parameters"`
 }
 
 // undistortSource will undistort the original image according to the Distortion parameters
@@ -25,7 +27,7 @@ type undistortConfig struct {
 type undistortSource struct {
 	originalStream gostream.VideoStream
 	stream         camera.ImageType
-	cameraParams   *transform.PinholeCameraModel
+	undistortMap   *transform.UndistortionMap
 }
 
 func newUndistortTransform(
@@ -38,11 +40,23 @@ func newUndistortTransform(
 	if conf.CameraParams == nil {
 		return nil, camera.UnspecifiedStream, errors.Wrapf(transform.ErrNoIntrinsics, "cannot create undistort transform")
 	}
+	if conf.DistortionParams != nil && conf.FisheyeParams != nil {
+		return nil, camera.UnspecifiedStream,
+			errors.New("cannot set both distortion_parameters and fisheye_distortion_parameters for undistort transform")
+	}
 	cameraModel := camera.NewPinholeModelWithBrownConradyDistortion(conf.CameraParams, conf.DistortionParams)
+	if conf.FisheyeParams != nil {
+		cameraModel = camera.NewPinholeModelWithKannalaBrandtDistortion(conf.CameraParams, conf.FisheyeParams)
+	}
+	// the distortion model is fixed, so work out where every pixel comes from once up front
+	undistortMap, err := cameraModel.NewUndistortionMap()
+	if err != nil {
+		return nil, camera.UnspecifiedStream, errors.Wrap(err, "cannot create undistort transform")
+	}
 	reader := &undistortSource{
 		gostream.NewEmbeddedVideoStream(source),
 		stream,
-		&cameraModel,
+		undistortMap,
 	}
 	src, err := camera.NewVideoSourceFromReader(ctx, reader, &cameraModel, stream)
 	if err != nil {
@@ -62,7 +76,7 @@ func (us *undistortSource) Read(ctx context.Context) (image.Image, func(), error
 	switch us.stream {
 	case camera.ColorStream, camera.UnspecifiedStream:
 		color := rimage.ConvertImage(orig)
-		color, err = us.cameraParams.UndistortImage(color)
+		color, err = us.undistortMap.UndistortImage(color)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -72,7 +86,7 @@ func (us *undistortSource) Read(ctx context.Context) (image.Image, func(), error
 		if err != nil {
 			return nil, nil, err
 		}
-		depth, err = us.cameraParams.UndistortDepthMap(depth)
+		depth, err = us.undistortMap.UndistortDepthMap(depth)
 		if err != nil {
 			return nil, nil, err
 		}
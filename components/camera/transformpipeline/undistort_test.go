@@ -65,6 +65,24 @@ func TestUndistortSetup(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 
 	test.That(t, us.Close(context.Background()), test.ShouldBeNil)
+
+	// fisheye lens
+	am = utils.AttributeMap{
+		"intrinsic_parameters":          undistortTestParams,
+		"fisheye_distortion_parameters": &transform.KannalaBrandt{K1: 0.01},
+	}
+	us, _, err = newUndistortTransform(context.Background(), source, camera.ColorStream, am)
+	test.That(t, err, test.ShouldBeNil)
+	_, _, err = camera.ReadImage(context.Background(), us)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, us.Close(context.Background()), test.ShouldBeNil)
+
+	// can't have both distortion models
+	am["distortion_parameters"] = undistortTestBC
+	_, _, err = newUndistortTransform(context.Background(), source, camera.ColorStream, am)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "cannot set both")
+
 	test.That(t, source.Close(context.Background()), test.ShouldBeNil)
 }
 
@@ -7,7 +7,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"image"
-	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -168,6 +167,7 @@ func getProperties(d driver.Driver) (_ []prop.Media, err error) {
 type WebcamConfig struct {
 	CameraParameters     *transform.PinholeCameraIntrinsics `json:"intrinsic_parameters,omitempty"`
 	DistortionParameters *transform.BrownConrady            `json:"distortion_parameters,omitempty"`
+	FisheyeParameters    *transform.KannalaBrandt           `json:"fisheye_distortion_parameters,omitempty"`
 	Debug                bool                               `json:"debug,omitempty"`
 	Format               string                             `json:"format,omitempty"`
 	Path                 string                             `json:"video_path"`
@@ -183,6 +183,9 @@ func (c WebcamConfig) Validate(path string) ([]string, error) {
 			"got illegal negative dimensions for width_px and height_px (%d, %d) fields set for webcam camera",
 			c.Height, c.Width)
 	}
+	if c.DistortionParameters != nil && c.FisheyeParameters != nil {
+		return nil, errors.New("cannot set both distortion_parameters and fisheye_distortion_parameters for webcam camera")
+	}
 
 	return []string{}, nil
 }
@@ -348,6 +351,9 @@ func (c *monitoredWebcam) Reconfigure(
 	defer c.mu.Unlock()
 
 	cameraModel := camera.NewPinholeModelWithBrownConradyDistortion(newConf.CameraParameters, newConf.DistortionParameters)
+	if newConf.FisheyeParameters != nil {
+		cameraModel = camera.NewPinholeModelWithKannalaBrandtDistortion(newConf.CameraParameters, newConf.FisheyeParameters)
+	}
 	projector, err := camera.WrapVideoSourceWithProjector(
 		ctx,
 		&noopCloser{c},
@@ -415,8 +421,8 @@ func tryWebcamOpen(
 }
 
 // getNamedVideoSource attempts to find a video device (not a screen) by the given name.
-// First it will try to use the path name after evaluating any symbolic links. If
-// evaluation fails, it will try to use the path name as provided.
+// Unless the name is already a label, it is converted to one the way the platform's
+// mediadevices driver labels devices; see videoLabelFromPath.
 func getNamedVideoSource(
 	path string,
 	fromLabel bool,
@@ -424,12 +430,9 @@ func getNamedVideoSource(
 	logger logging.Logger,
 ) (gostream.MediaSource[image.Image], error) {
 	if !fromLabel {
-		resolvedPath, err := filepath.EvalSymlinks(path)
-		if err == nil {
-			path = resolvedPath
-		}
+		path = videoLabelFromPath(path)
 	}
-	return gostream.GetNamedVideoSource(filepath.Base(path), constraints, logger.AsZap())
+	return gostream.GetNamedVideoSource(path, constraints, logger.AsZap())
 }
 
 // monitoredWebcam tries to ensure its underlying camera stays connected.
@@ -479,7 +482,8 @@ func (c *monitoredWebcam) isCameraConnected() (bool, error) {
 		return true, errors.Wrap(err, "cannot get driver from media source")
 	}
 
-	// TODO(RSDK-1959): this only works for linux
+	// Only V4L2 reports availability, so on other platforms a camera is assumed connected until
+	// reading from it fails.
 	_, err = driver.IsAvailable(d)
 	return !errors.Is(err, availability.ErrNoDevice), nil
 }
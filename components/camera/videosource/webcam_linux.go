@@ -0,0 +1,15 @@
+//go:build linux
+
+package videosource
+
+import "path/filepath"
+
+// videoLabelFromPath returns the mediadevices label of the V4L2 device at path, which is its
+// device file name, such as video0 for /dev/video0 or for a /dev/v4l/by-id symlink to it.
+func videoLabelFromPath(path string) string {
+	resolvedPath, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		path = resolvedPath
+	}
+	return filepath.Base(path)
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package videosource
+
+// videoLabelFromPath returns path unchanged, as video paths on other platforms are the labels
+// mediadevices gives devices: an AVFoundation unique ID on macOS and a MediaFoundation symbolic
+// link on Windows, neither of which is a file path.
+func videoLabelFromPath(path string) string {
+	return path
+}
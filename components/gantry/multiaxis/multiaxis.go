@@ -3,6 +3,8 @@ package multiaxis
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -23,15 +25,23 @@ var model = resource.DefaultModelFamily.WithModel("multi-axis")
 type Config struct {
 	SubAxes            []string `json:"subaxes_list"`
 	MoveSimultaneously *bool    `json:"move_simultaneously,omitempty"`
+	// SynchronizeMoves moves all the axes at once, with their speeds scaled so that they start and finish
+	// together, which moves the gantry in a straight line.
+	SynchronizeMoves bool `json:"synchronize_moves,omitempty"`
+	// SpeedMmPerSec is the speed along that line of synchronized moves which are not given speeds.
+	SpeedMmPerSec float64 `json:"speed_mm_per_sec,omitempty"`
 }
 
 type multiAxis struct {
 	resource.Named
 	resource.AlwaysRebuild
 	subAxes            []gantry.Gantry
+	subAxisNames       []string
 	lengthsMm          []float64
 	logger             logging.Logger
 	moveSimultaneously bool
+	synchronizeMoves   bool
+	speedMmPerSec      float64
 	model              referenceframe.Model
 	opMgr              *operation.SingleOperationManager
 	workers            sync.WaitGroup
@@ -45,6 +55,10 @@ func (conf *Config) Validate(path string) ([]string, error) {
 		return nil, resource.NewConfigValidationError(path, errors.New("need at least one axis"))
 	}
 
+	if conf.SynchronizeMoves && conf.SpeedMmPerSec <= 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("synchronize_moves needs a positive speed_mm_per_sec"))
+	}
+
 	deps = append(deps, conf.SubAxes...)
 	return deps, nil
 }
@@ -79,12 +93,15 @@ func newMultiAxis(
 			return nil, errors.Wrapf(err, "no axes named [%s]", s)
 		}
 		mAx.subAxes = append(mAx.subAxes, subAx)
+		mAx.subAxisNames = append(mAx.subAxisNames, s)
 	}
 
 	mAx.moveSimultaneously = false
 	if newConf.MoveSimultaneously != nil {
 		mAx.moveSimultaneously = *newConf.MoveSimultaneously
 	}
+	mAx.synchronizeMoves = newConf.SynchronizeMoves
+	mAx.speedMmPerSec = newConf.SpeedMmPerSec
 
 	mAx.lengthsMm, err = mAx.Lengths(ctx, nil)
 	if err != nil {
@@ -124,6 +141,19 @@ func (g *multiAxis) MoveToPosition(ctx context.Context, positions, speeds []floa
 		)
 	}
 
+	moveSimultaneously := g.moveSimultaneously
+	if g.synchronizeMoves {
+		current, err := g.Position(ctx, extra)
+		if err != nil {
+			return err
+		}
+		speeds, err = synchronizedSpeeds(current, positions, speeds, g.speedMmPerSec)
+		if err != nil {
+			return err
+		}
+		moveSimultaneously = true
+	}
+
 	fs := []rdkutils.SimpleFunc{}
 	idx := 0
 	for _, subAx := range g.subAxes {
@@ -142,7 +172,7 @@ func (g *multiAxis) MoveToPosition(ctx context.Context, positions, speeds []floa
 		}
 		idx += len(subAxNum)
 
-		if g.moveSimultaneously {
+		if moveSimultaneously {
 			singleGantry := subAx
 			fs = append(fs, func(ctx context.Context) error { return singleGantry.MoveToPosition(ctx, pos, speed, nil) })
 		} else {
@@ -152,7 +182,7 @@ func (g *multiAxis) MoveToPosition(ctx context.Context, positions, speeds []floa
 			}
 		}
 	}
-	if g.moveSimultaneously {
+	if moveSimultaneously {
 		if _, err := rdkutils.RunInParallel(ctx, fs); err != nil {
 			return multierr.Combine(err, g.Stop(ctx, nil))
 		}
@@ -160,6 +190,48 @@ func (g *multiAxis) MoveToPosition(ctx context.Context, positions, speeds []floa
 	return nil
 }
 
+// synchronizedSpeeds returns the speeds at which to move each axis from current to goal so that they all finish
+// together. If speeds are given, they are the fastest each axis may go. Otherwise, the gantry moves at speed along
+// the line from current to goal.
+func synchronizedSpeeds(current, goal, speeds []float64, speed float64) ([]float64, error) {
+	if len(speeds) != 0 && len(speeds) != len(goal) {
+		return nil, errors.Errorf("number of speeds %v does not match number of positions %v", len(speeds), len(goal))
+	}
+	distances := make([]float64, len(goal))
+	var squaredDistance float64
+	for i := range goal {
+		distances[i] = math.Abs(goal[i] - current[i])
+		squaredDistance += distances[i] * distances[i]
+	}
+
+	// the move takes as long as its slowest axis
+	var duration float64
+	if len(speeds) == 0 {
+		duration = math.Sqrt(squaredDistance) / speed
+	} else {
+		for i, s := range speeds {
+			if s <= 0 {
+				return nil, errors.Errorf("speed %.2f of axis %d must be positive", s, i)
+			}
+			duration = math.Max(duration, distances[i]/s)
+		}
+	}
+
+	synced := make([]float64, len(goal))
+	for i := range goal {
+		// axes which are not moving still need a valid speed
+		switch {
+		case distances[i] != 0:
+			synced[i] = distances[i] / duration
+		case len(speeds) != 0:
+			synced[i] = speeds[i]
+		default:
+			synced[i] = speed
+		}
+	}
+	return synced, nil
+}
+
 // GoToInputs moves the gantry to a goal position in the Gantry frame.
 func (g *multiAxis) GoToInputs(ctx context.Context, inputSteps ...[]referenceframe.Input) error {
 	for _, goal := range inputSteps {
@@ -219,6 +291,27 @@ func (g *multiAxis) Stop(ctx context.Context, extra map[string]interface{}) erro
 	return nil
 }
 
+// DoCommand handles "home_axis", which homes just the subaxis named by "axis".
+func (g *multiAxis) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, _ := cmd["command"].(string)
+	switch name {
+	case "home_axis":
+		axis, _ := cmd["axis"].(string)
+		for i, subAxName := range g.subAxisNames {
+			if subAxName == axis {
+				homed, err := g.subAxes[i].Home(ctx, nil)
+				if err != nil {
+					return nil, err
+				}
+				return map[string]interface{}{"homed": homed}, nil
+			}
+		}
+		return nil, fmt.Errorf("no subaxis named %q, have %v", axis, g.subAxisNames)
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
+
 // Close calls stop.
 func (g *multiAxis) Close(ctx context.Context) error {
 	return g.Stop(ctx, nil)
@@ -2,6 +2,7 @@ package multiaxis
 
 import (
 	"context"
+	"sync"
 	"testing"
 
 	"go.viam.com/test"
@@ -434,3 +435,75 @@ func TestComplexMultiAxis(t *testing.T) {
 			}
 		})
 }
+
+func TestSynchronizedMoves(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	moves := map[string][]float64{}
+	newAxis := func(name string, position float64) *inject.Gantry {
+		axis := createFakeOneaAxis(100, []float64{position})
+		axis.MoveToPositionFunc = func(ctx context.Context, pos, speed []float64, extra map[string]interface{}) error {
+			mu.Lock()
+			defer mu.Unlock()
+			moves[name] = speed
+			return nil
+		}
+		return axis
+	}
+
+	fakemultiaxis := &multiAxis{
+		subAxes:          []gantry.Gantry{newAxis("x", 0), newAxis("y", 10), newAxis("z", 5)},
+		lengthsMm:        []float64{100, 100, 100},
+		synchronizeMoves: true,
+		speedMmPerSec:    10,
+		opMgr:            operation.NewSingleOperationManager(),
+	}
+
+	// a 50mm line at 10mm/s takes 5s, and the stationary axis gets the default speed
+	err := fakemultiaxis.MoveToPosition(ctx, []float64{30, 50, 5}, nil, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moves["x"], test.ShouldResemble, []float64{6})
+	test.That(t, moves["y"], test.ShouldResemble, []float64{8})
+	test.That(t, moves["z"], test.ShouldResemble, []float64{10})
+
+	// with speeds, the slowest axis sets the pace
+	err = fakemultiaxis.MoveToPosition(ctx, []float64{30, 50, 5}, []float64{10, 5, 1}, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moves["x"], test.ShouldResemble, []float64{3.75})
+	test.That(t, moves["y"], test.ShouldResemble, []float64{5.})
+	test.That(t, moves["z"], test.ShouldResemble, []float64{1.})
+
+	err = fakemultiaxis.MoveToPosition(ctx, []float64{30, 50, 5}, []float64{10, 0, 1}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	fakecfg := &Config{SubAxes: []string{"x"}, SynchronizeMoves: true}
+	_, err = fakecfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestHomeAxis(t *testing.T) {
+	ctx := context.Background()
+	homed := map[string]bool{}
+	newAxis := func(name string) *inject.Gantry {
+		axis := createFakeOneaAxis(1, []float64{0})
+		axis.HomeFunc = func(ctx context.Context, extra map[string]interface{}) (bool, error) {
+			homed[name] = true
+			return true, nil
+		}
+		return axis
+	}
+	fakemultiaxis := &multiAxis{
+		subAxes:      []gantry.Gantry{newAxis("x"), newAxis("z")},
+		subAxisNames: []string{"x", "z"},
+		opMgr:        operation.NewSingleOperationManager(),
+	}
+
+	resp, err := fakemultiaxis.DoCommand(ctx, map[string]interface{}{"command": "home_axis", "axis": "z"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp, test.ShouldResemble, map[string]interface{}{"homed": true})
+	test.That(t, homed, test.ShouldResemble, map[string]bool{"z": true})
+
+	_, err = fakemultiaxis.DoCommand(ctx, map[string]interface{}{"command": "home_axis", "axis": "y"})
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -42,6 +42,12 @@ type Config struct {
 	LengthMm        float64  `json:"length_mm"`
 	MmPerRevolution float64  `json:"mm_per_rev"`
 	GantryMmPerSec  float64  `json:"gantry_mm_per_sec,omitempty"`
+	// SoftLimitsMm are the lowest and highest positions the gantry will move to, which keep it clear of the ends of
+	// the axis or of anything mounted near them.
+	SoftLimitsMm []float64 `json:"soft_limits_mm,omitempty"`
+	// HomePositionMm is where the gantry goes once it has found its limit switches, which defaults to the middle
+	// of the axis.
+	HomePositionMm *float64 `json:"home_position_mm,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -78,6 +84,27 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 	if len(cfg.LimitSwitchPins) > 0 && cfg.LimitPinEnabled == nil {
 		return nil, errors.New("limit pin enabled must be set to true or false")
 	}
+
+	if cfg.SoftLimitsMm != nil {
+		if len(cfg.SoftLimitsMm) != 2 {
+			return nil, resource.NewConfigValidationError(path, errors.New("soft_limits_mm must have a lowest and a highest position"))
+		}
+		if cfg.SoftLimitsMm[0] < 0 || cfg.SoftLimitsMm[0] >= cfg.SoftLimitsMm[1] || cfg.SoftLimitsMm[1] > cfg.LengthMm {
+			return nil, resource.NewConfigValidationError(path,
+				errors.Errorf("soft_limits_mm must be increasing and between 0 and length_mm, got %v", cfg.SoftLimitsMm))
+		}
+	}
+
+	if cfg.HomePositionMm != nil {
+		lo, hi := 0.0, cfg.LengthMm
+		if cfg.SoftLimitsMm != nil {
+			lo, hi = cfg.SoftLimitsMm[0], cfg.SoftLimitsMm[1]
+		}
+		if *cfg.HomePositionMm < lo || *cfg.HomePositionMm > hi {
+			return nil, resource.NewConfigValidationError(path,
+				errors.Errorf("home_position_mm must be between %.2f and %.2f", lo, hi))
+		}
+	}
 	return deps, nil
 }
 
@@ -102,6 +129,9 @@ type singleAxis struct {
 	lengthMm        float64
 	mmPerRevolution float64
 	rpm             float64
+	// softLimits are the lowest and highest positions in mm to move to, or nil if the whole axis can be used.
+	softLimits   []float64
+	homePosition float64
 
 	model referenceframe.Model
 	frame r3.Vector
@@ -152,6 +182,15 @@ func (g *singleAxis) Reconfigure(ctx context.Context, deps resource.Dependencies
 	// Changing these attributes does not rerun homing
 	g.lengthMm = newConf.LengthMm
 	g.mmPerRevolution = newConf.MmPerRevolution
+	g.softLimits = newConf.SoftLimitsMm
+	g.homePosition = 0.5 * g.lengthMm
+	if newConf.HomePositionMm != nil {
+		g.homePosition = *newConf.HomePositionMm
+	} else if g.softLimits != nil {
+		g.homePosition = 0.5 * (g.softLimits[0] + g.softLimits[1])
+	}
+	// the frame's limits depend on the soft limits
+	g.model = nil
 	if g.mmPerRevolution <= 0 && len(newConf.LimitSwitchPins) == 1 {
 		return errors.New("gantry with one limit switch per axis needs a mm_per_length ratio defined")
 	}
@@ -375,8 +414,8 @@ func (g *singleAxis) homeLimSwitch(ctx context.Context) error {
 		g.logger.CDebugf(ctx, "positionA: %0.2f positionB: %0.2f range: %0.2f", positionA, positionB, g.positionRange)
 	}
 
-	// Go to start position at the middle of the axis.
-	x := g.gantryToMotorPosition(0.5 * g.lengthMm)
+	// Go to the home position, which defaults to the middle of the axis.
+	x := g.gantryToMotorPosition(g.homePosition)
 	if err := g.motor.GoTo(ctx, g.rpm, x, nil); err != nil {
 		return err
 	}
@@ -398,6 +437,7 @@ func (g *singleAxis) homeEncoder(ctx context.Context) error {
 	positionB := positionA + revPerLength
 
 	g.positionLimits = []float64{positionA, positionB}
+	g.positionRange = revPerLength
 	return nil
 }
 
@@ -532,6 +572,10 @@ func (g *singleAxis) MoveToPosition(ctx context.Context, positions, speeds []flo
 		return fmt.Errorf("out of range (%.2f) min: 0 max: %.2f", positions[0], g.lengthMm)
 	}
 
+	if g.softLimits != nil && (positions[0] < g.softLimits[0] || positions[0] > g.softLimits[1]) {
+		return fmt.Errorf("outside soft limits (%.2f) min: %.2f max: %.2f", positions[0], g.softLimits[0], g.softLimits[1])
+	}
+
 	if len(speeds) == 0 {
 		speeds = append(speeds, g.rpm)
 		g.logger.CDebug(ctx, "single-axis received invalid speed, using default gantry speed")
@@ -605,7 +649,11 @@ func (g *singleAxis) ModelFrame() referenceframe.Model {
 		errs = multierr.Combine(errs, err)
 		m.OrdTransforms = append(m.OrdTransforms, f)
 
-		f, err = referenceframe.NewTranslationalFrame(g.Name().ShortName(), g.frame, referenceframe.Limit{Min: 0, Max: g.lengthMm})
+		limit := referenceframe.Limit{Min: 0, Max: g.lengthMm}
+		if g.softLimits != nil {
+			limit = referenceframe.Limit{Min: g.softLimits[0], Max: g.softLimits[1]}
+		}
+		f, err = referenceframe.NewTranslationalFrame(g.Name().ShortName(), g.frame, limit)
 		errs = multierr.Combine(errs, err)
 
 		if errs != nil {
@@ -10,6 +10,7 @@ import (
 	"go.viam.com/test"
 
 	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/gantry"
 	"go.viam.com/rdk/components/motor"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/operation"
@@ -112,6 +113,23 @@ func TestValidate(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, deps, test.ShouldResemble, []string{fakecfg.Motor, fakecfg.Board})
 	test.That(t, fakecfg.GantryMmPerSec, test.ShouldEqual, float64(0))
+
+	fakecfg.SoftLimitsMm = []float64{0.5}
+	_, err = fakecfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	fakecfg.SoftLimitsMm = []float64{0.5, 2}
+	_, err = fakecfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	fakecfg.SoftLimitsMm = []float64{0.25, 0.75}
+	_, err = fakecfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+
+	homePosition := 0.1
+	fakecfg.HomePositionMm = &homePosition
+	_, err = fakecfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
 }
 
 func TestNewSingleAxis(t *testing.T) {
@@ -527,8 +545,12 @@ func TestHomeEncoder(t *testing.T) {
 	test.That(t, err.Error(), test.ShouldContainSubstring, "get position")
 
 	injMotor.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (float64, error) { return 0, nil }
+	fakegantry.lengthMm = 100
+	fakegantry.mmPerRevolution = 10
 	err = fakegantry.homeEncoder(ctx)
 	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fakegantry.positionLimits, test.ShouldResemble, []float64{0, 10})
+	test.That(t, fakegantry.positionRange, test.ShouldEqual, 10.)
 }
 
 func TestTestLimit(t *testing.T) {
@@ -716,6 +738,40 @@ func TestMoveToPosition(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 }
 
+func TestSoftLimits(t *testing.T) {
+	ctx := context.Background()
+	var goal float64
+	injMotor := &inject.Motor{
+		GoToFunc: func(ctx context.Context, rpm, position float64, extra map[string]interface{}) error {
+			goal = position
+			return nil
+		},
+	}
+	fakegantry := &singleAxis{
+		Named:          resource.NewName(gantry.API, testGName).AsNamed(),
+		logger:         logging.NewTestLogger(t),
+		motor:          injMotor,
+		lengthMm:       100,
+		positionLimits: []float64{0, 10},
+		positionRange:  10,
+		softLimits:     []float64{20, 80},
+		frame:          r3.Vector{X: 1},
+		opMgr:          operation.NewSingleOperationManager(),
+	}
+
+	err := fakegantry.MoveToPosition(ctx, []float64{10}, []float64{10}, nil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "outside soft limits")
+	err = fakegantry.MoveToPosition(ctx, []float64{90}, []float64{10}, nil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "outside soft limits")
+
+	err = fakegantry.MoveToPosition(ctx, []float64{50}, []float64{10}, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, goal, test.ShouldEqual, 5.)
+
+	limits := fakegantry.ModelFrame().DoF()
+	test.That(t, limits, test.ShouldResemble, []referenceframe.Limit{{Min: 20, Max: 80}})
+}
+
 func TestModelFrame(t *testing.T) {
 	ctx := context.Background()
 	logger := logging.NewTestLogger(t)
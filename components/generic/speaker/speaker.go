@@ -0,0 +1,385 @@
+// Package speaker implements a generic component which plays audio through a speaker, complementing the
+// microphone audio input. It plays clips one after another, such as alerts uploaded ahead of time or buffers
+// produced by text to speech, by handing them to aplay or a compatible player.
+//
+// It is driven through DoCommand:
+//
+//	{"command": "play", "audio": <base64>, "format": "wav"}
+//	{"command": "play", "audio": <base64>, "format": "pcm", "sample_rate_hz": 16000, "channels": 1}
+//	{"command": "play", "path": "/sounds/alert.wav", "volume": 0.5}
+//	{"command": "stop"}
+//	{"command": "set_volume", "volume": 0.8}
+//	{"command": "get_status"}
+//
+// Audio must be 16-bit PCM, either raw little-endian samples or in a WAV file. "volume", between 0 and 1, scales
+// a single clip on top of the speaker's volume.
+package speaker
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("speaker")
+
+const (
+	defaultPlayerPath    = "aplay"
+	defaultMaxQueueClips = 8
+)
+
+// Config is the config of a speaker.
+type Config struct {
+	// PlayerPath is the aplay, or a program taking the same arguments, used to play audio.
+	PlayerPath string `json:"player_path,omitempty"`
+	// Device is the ALSA device to play through, such as "plughw:1,0". The player's default is used if unset.
+	Device        string   `json:"device,omitempty"`
+	Volume        *float64 `json:"volume,omitempty"`
+	MaxQueueClips int      `json:"max_queue_clips,omitempty"`
+}
+
+// Validate validates the speaker's configuration.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	if cfg.Volume != nil && (*cfg.Volume < 0 || *cfg.Volume > 1) {
+		return nil, resource.NewConfigValidationError(path, errors.New("volume must be between 0 and 1"))
+	}
+	if cfg.MaxQueueClips < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("max_queue_clips cannot be negative"))
+	}
+	return nil, nil
+}
+
+func init() {
+	resource.RegisterComponent(
+		generic.API,
+		model,
+		resource.Registration[resource.Resource, *Config]{
+			Constructor: newSpeaker,
+		})
+}
+
+// A clip is audio ready to play, as interleaved 16-bit little-endian samples.
+type clip struct {
+	pcm          []byte
+	sampleRateHz int
+	channels     int
+}
+
+func (c *clip) duration() time.Duration {
+	samples := len(c.pcm) / 2 / c.channels
+	return time.Duration(float64(samples) / float64(c.sampleRateHz) * float64(time.Second))
+}
+
+// player plays a clip, returning once it has finished or ctx is done.
+type player func(ctx context.Context, c *clip) error
+
+// aplay returns a player which pipes clips into the aplay at program.
+func aplay(program, device string) player {
+	return func(ctx context.Context, c *clip) error {
+		args := []string{
+			"-q", "-t", "raw", "-f", "S16_LE",
+			"-r", strconv.Itoa(c.sampleRateHz), "-c", strconv.Itoa(c.channels),
+		}
+		if device != "" {
+			args = append(args, "-D", device)
+		}
+		//nolint:gosec
+		cmd := exec.CommandContext(ctx, program, append(args, "-")...)
+		cmd.Stdin = bytes.NewReader(c.pcm)
+		if output, err := cmd.CombinedOutput(); err != nil && ctx.Err() == nil {
+			return fmt.Errorf("%s failed: %w: %s", program, err, bytes.TrimSpace(output))
+		}
+		return nil
+	}
+}
+
+type speaker struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	play  player
+	queue chan *clip
+
+	mu            sync.Mutex
+	volume        float64
+	playing       bool
+	stopCurrent   context.CancelFunc
+	clipsPlayed   int
+	lastPlayError string
+
+	workers utils.StoppableWorkers
+	logger  logging.Logger
+}
+
+func newSpeaker(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (resource.Resource, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	program := newConf.PlayerPath
+	if program == "" {
+		program = defaultPlayerPath
+	}
+	if _, err := exec.LookPath(program); err != nil {
+		return nil, errors.Wrapf(err, "cannot find audio player %q", program)
+	}
+	s := makeSpeaker(conf.ResourceName(), newConf, aplay(program, newConf.Device), logger)
+	s.workers = utils.NewStoppableWorkers(func(ctx context.Context) {
+		for {
+			if !s.playNext(ctx) {
+				return
+			}
+		}
+	})
+	return s, nil
+}
+
+// makeSpeaker builds the speaker without starting to play its queue, so that tests can play clips by hand.
+func makeSpeaker(name resource.Name, conf *Config, play player, logger logging.Logger) *speaker {
+	maxQueue := conf.MaxQueueClips
+	if maxQueue == 0 {
+		maxQueue = defaultMaxQueueClips
+	}
+	s := &speaker{
+		Named:  name.AsNamed(),
+		play:   play,
+		queue:  make(chan *clip, maxQueue),
+		volume: 1,
+		logger: logger,
+	}
+	if conf.Volume != nil {
+		s.volume = *conf.Volume
+	}
+	return s
+}
+
+// playNext waits for the next queued clip and plays it, returning false once ctx is done.
+func (s *speaker) playNext(ctx context.Context) bool {
+	var c *clip
+	select {
+	case <-ctx.Done():
+		return false
+	case c = <-s.queue:
+	}
+
+	clipCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.mu.Lock()
+	s.playing = true
+	s.stopCurrent = cancel
+	s.mu.Unlock()
+
+	err := s.play(clipCtx, c)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.playing = false
+	s.stopCurrent = nil
+	if err != nil {
+		s.logger.CWarnw(ctx, "failed to play clip", "error", err)
+		s.lastPlayError = err.Error()
+		return true
+	}
+	// clips cut off by stop are not counted
+	if clipCtx.Err() == nil {
+		s.clipsPlayed++
+	}
+	return true
+}
+
+func (s *speaker) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, _ := cmd["command"].(string)
+	switch name {
+	case "play":
+		c, err := decodeClip(cmd)
+		if err != nil {
+			return nil, err
+		}
+		volume := 1.
+		if v, ok := cmd["volume"]; ok {
+			if volume, ok = v.(float64); !ok || volume < 0 || volume > 1 {
+				return nil, fmt.Errorf("volume must be a number between 0 and 1, not %v", v)
+			}
+		}
+		s.mu.Lock()
+		volume *= s.volume
+		s.mu.Unlock()
+		scale(c.pcm, volume)
+
+		select {
+		case s.queue <- c:
+		default:
+			return nil, errors.Errorf("cannot queue more than %d clips", cap(s.queue))
+		}
+		return map[string]interface{}{"queued": len(s.queue), "duration_sec": c.duration().Seconds()}, nil
+	case "stop":
+		s.stop()
+		return map[string]interface{}{}, nil
+	case "set_volume":
+		volume, ok := cmd["volume"].(float64)
+		if !ok || volume < 0 || volume > 1 {
+			return nil, fmt.Errorf("volume must be a number between 0 and 1, not %v", cmd["volume"])
+		}
+		s.mu.Lock()
+		s.volume = volume
+		s.mu.Unlock()
+		return map[string]interface{}{}, nil
+	case "get_status":
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		status := map[string]interface{}{
+			"playing":      s.playing,
+			"queued":       len(s.queue),
+			"volume":       s.volume,
+			"clips_played": s.clipsPlayed,
+		}
+		if s.lastPlayError != "" {
+			status["last_error"] = s.lastPlayError
+		}
+		return status, nil
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
+
+// stop drops every queued clip and cuts off the one playing.
+func (s *speaker) stop() {
+drain:
+	for {
+		select {
+		case <-s.queue:
+		default:
+			break drain
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopCurrent != nil {
+		s.stopCurrent()
+	}
+}
+
+// Close stops playing.
+func (s *speaker) Close(ctx context.Context) error {
+	if s.workers != nil {
+		s.workers.Stop()
+	}
+	return nil
+}
+
+// decodeClip reads the clip a play command asks for.
+func decodeClip(cmd map[string]interface{}) (*clip, error) {
+	var data []byte
+	if path, ok := cmd["path"].(string); ok {
+		var err error
+		//nolint:gosec
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	} else {
+		encoded, ok := cmd["audio"].(string)
+		if !ok {
+			return nil, errors.New("play needs either audio or a path")
+		}
+		var err error
+		if data, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+			return nil, errors.Wrap(err, "audio must be base64 encoded")
+		}
+	}
+
+	format, _ := cmd["format"].(string)
+	switch format {
+	case "", "wav":
+		return decodeWAV(data)
+	case "pcm":
+		rate, ok := cmd["sample_rate_hz"].(float64)
+		if !ok || rate <= 0 {
+			return nil, errors.New("pcm audio needs a positive sample_rate_hz")
+		}
+		channels := 1.
+		if v, ok := cmd["channels"]; ok {
+			if channels, ok = v.(float64); !ok || channels < 1 {
+				return nil, fmt.Errorf("channels must be a positive number, not %v", v)
+			}
+		}
+		if len(data)%(2*int(channels)) != 0 {
+			return nil, errors.New("pcm audio must be whole 16-bit samples for every channel")
+		}
+		return &clip{pcm: data, sampleRateHz: int(rate), channels: int(channels)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q, must be wav or pcm", format)
+	}
+}
+
+// decodeWAV reads the samples out of a 16-bit PCM WAV file.
+func decodeWAV(data []byte) (*clip, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, errors.New("not a WAV file")
+	}
+	c := &clip{}
+	for offset := 12; offset+8 <= len(data); {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := data[offset+8:]
+		if size > len(body) {
+			// recorders streaming to a file often leave the data size unset, so take whatever is there
+			size = len(body)
+		}
+		body = body[:size]
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, errors.New("WAV format chunk is too short")
+			}
+			if audioFormat := binary.LittleEndian.Uint16(body[0:2]); audioFormat != 1 {
+				return nil, fmt.Errorf("WAV audio must be PCM, not format %d", audioFormat)
+			}
+			if bits := binary.LittleEndian.Uint16(body[14:16]); bits != 16 {
+				return nil, fmt.Errorf("WAV audio must be 16-bit, not %d-bit", bits)
+			}
+			c.channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			c.sampleRateHz = int(binary.LittleEndian.Uint32(body[4:8]))
+		case "data":
+			if c.channels == 0 {
+				return nil, errors.New("WAV data comes before its format")
+			}
+			c.pcm = body[:len(body)-len(body)%(2*c.channels)]
+			return c, nil
+		}
+		// chunks are padded to an even length
+		offset += 8 + size + size%2
+	}
+	return nil, errors.New("WAV file has no data")
+}
+
+// scale multiplies every sample by volume.
+func scale(pcm []byte, volume float64) {
+	if volume == 1 {
+		return
+	}
+	for i := 0; i+1 < len(pcm); i += 2 {
+		sample := float64(int16(binary.LittleEndian.Uint16(pcm[i:]))) * volume
+		sample = math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(sample)))
+		binary.LittleEndian.PutUint16(pcm[i:], uint16(int16(sample)))
+	}
+}
@@ -0,0 +1,159 @@
+package speaker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/logging"
+)
+
+// makeWAV returns a 16-bit PCM WAV file holding samples.
+func makeWAV(sampleRateHz, channels int, samples []int16) []byte {
+	var fmtChunk [16]byte
+	binary.LittleEndian.PutUint16(fmtChunk[0:], 1)
+	binary.LittleEndian.PutUint16(fmtChunk[2:], uint16(channels))
+	binary.LittleEndian.PutUint32(fmtChunk[4:], uint32(sampleRateHz))
+	binary.LittleEndian.PutUint32(fmtChunk[8:], uint32(sampleRateHz*channels*2))
+	binary.LittleEndian.PutUint16(fmtChunk[12:], uint16(channels*2))
+	binary.LittleEndian.PutUint16(fmtChunk[14:], 16)
+
+	data := make([]byte, 2*len(samples))
+	for i, sample := range samples {
+		binary.LittleEndian.PutUint16(data[2*i:], uint16(sample))
+	}
+
+	chunk := func(id string, body []byte) []byte {
+		out := append([]byte(id), 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(out[4:], uint32(len(body)))
+		return append(out, body...)
+	}
+	wav := append([]byte("RIFF"), 0, 0, 0, 0)
+	wav = append(wav, "WAVE"...)
+	// an odd length chunk to check padding is skipped
+	wav = append(wav, chunk("LIST", []byte{1, 2, 3})...)
+	wav = append(wav, 0)
+	wav = append(wav, chunk("fmt ", fmtChunk[:])...)
+	wav = append(wav, chunk("data", data)...)
+	binary.LittleEndian.PutUint32(wav[4:], uint32(len(wav)-8))
+	return wav
+}
+
+func samplesOf(pcm []byte) []int16 {
+	samples := make([]int16, len(pcm)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(pcm[2*i:]))
+	}
+	return samples
+}
+
+func TestDecodeWAV(t *testing.T) {
+	c, err := decodeWAV(makeWAV(16000, 2, []int16{1, -1, 1000, -1000}))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, c.sampleRateHz, test.ShouldEqual, 16000)
+	test.That(t, c.channels, test.ShouldEqual, 2)
+	test.That(t, samplesOf(c.pcm), test.ShouldResemble, []int16{1, -1, 1000, -1000})
+
+	_, err = decodeWAV([]byte("not a wav file at all"))
+	test.That(t, err, test.ShouldNotBeNil)
+
+	wav := makeWAV(16000, 1, []int16{1})
+	wav[len(wav)-2-8-16+14] = 8
+	_, err = decodeWAV(wav)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "16-bit")
+}
+
+func TestScale(t *testing.T) {
+	c := &clip{pcm: make([]byte, 6), sampleRateHz: 1, channels: 1}
+	for i, sample := range []int16{100, -100, 30000} {
+		binary.LittleEndian.PutUint16(c.pcm[2*i:], uint16(sample))
+	}
+	scale(c.pcm, 0.5)
+	test.That(t, samplesOf(c.pcm), test.ShouldResemble, []int16{50, -50, 15000})
+}
+
+func TestSpeaker(t *testing.T) {
+	ctx := context.Background()
+	var played []*clip
+	var playErr error
+	play := func(ctx context.Context, c *clip) error {
+		played = append(played, c)
+		return playErr
+	}
+	volume := 0.5
+	s := makeSpeaker(generic.Named("speaker"), &Config{Volume: &volume, MaxQueueClips: 2}, play, logging.NewTestLogger(t))
+
+	wav := base64.StdEncoding.EncodeToString(makeWAV(8000, 1, make([]int16, 8000)))
+	resp, err := s.DoCommand(ctx, map[string]interface{}{"command": "play", "audio": wav})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["queued"], test.ShouldEqual, 1)
+	test.That(t, resp["duration_sec"], test.ShouldAlmostEqual, 1)
+
+	path := filepath.Join(t.TempDir(), "alert.pcm")
+	pcm := make([]byte, 4)
+	binary.LittleEndian.PutUint16(pcm, 1000)
+	binary.LittleEndian.PutUint16(pcm[2:], 2000)
+	test.That(t, os.WriteFile(path, pcm, 0o600), test.ShouldBeNil)
+	_, err = s.DoCommand(ctx, map[string]interface{}{
+		"command": "play", "path": path, "format": "pcm", "sample_rate_hz": 16000., "volume": 0.5,
+	})
+	test.That(t, err, test.ShouldBeNil)
+
+	_, err = s.DoCommand(ctx, map[string]interface{}{"command": "play", "audio": wav})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "cannot queue")
+
+	test.That(t, s.playNext(ctx), test.ShouldBeTrue)
+	test.That(t, s.playNext(ctx), test.ShouldBeTrue)
+	test.That(t, played, test.ShouldHaveLength, 2)
+	test.That(t, played[1].sampleRateHz, test.ShouldEqual, 16000)
+	test.That(t, played[1].channels, test.ShouldEqual, 1)
+	// scaled by both the clip's and the speaker's volume
+	test.That(t, samplesOf(played[1].pcm), test.ShouldResemble, []int16{250, 500})
+
+	// failures are reported, and do not stop later clips from playing
+	playErr = errors.New("no such device")
+	_, err = s.DoCommand(ctx, map[string]interface{}{"command": "play", "audio": wav})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, s.playNext(ctx), test.ShouldBeTrue)
+	status, err := s.DoCommand(ctx, map[string]interface{}{"command": "get_status"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status, test.ShouldResemble, map[string]interface{}{
+		"playing":      false,
+		"queued":       0,
+		"volume":       0.5,
+		"clips_played": 2,
+		"last_error":   "no such device",
+	})
+
+	_, err = s.DoCommand(ctx, map[string]interface{}{"command": "play", "audio": wav})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = s.DoCommand(ctx, map[string]interface{}{"command": "stop"})
+	test.That(t, err, test.ShouldBeNil)
+	status, err = s.DoCommand(ctx, map[string]interface{}{"command": "get_status"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["queued"], test.ShouldEqual, 0)
+
+	_, err = s.DoCommand(ctx, map[string]interface{}{"command": "set_volume", "volume": 2.})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = s.DoCommand(ctx, map[string]interface{}{"command": "set_volume", "volume": 1.})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = s.DoCommand(ctx, map[string]interface{}{"command": "play", "audio": "c29tZQ==", "format": "mp3"})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = s.DoCommand(ctx, map[string]interface{}{"command": "dance"})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// a canceled context stops the player waiting for clips
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	test.That(t, s.playNext(canceled), test.ShouldBeFalse)
+	test.That(t, s.Close(ctx), test.ShouldBeNil)
+}
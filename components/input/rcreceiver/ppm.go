@@ -0,0 +1,86 @@
+package rcreceiver
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/resource"
+)
+
+// ppmSyncGapUs is the shortest gap between rising edges that is treated as the end of a PPM frame. Channel
+// pulses are never longer than about 2100 microseconds, and the sync gap is always longer than this.
+const ppmSyncGapUs = 3000
+
+// ppmDecoder turns the rising edges of a PPM signal into frames of pulse widths.
+type ppmDecoder struct {
+	lastNs   uint64
+	haveLast bool
+	synced   bool
+	widths   []float64
+}
+
+// rise records a rising edge at the given time, and returns the completed frame if this edge ended one.
+func (d *ppmDecoder) rise(ns uint64) []float64 {
+	if !d.haveLast || ns < d.lastNs {
+		d.lastNs = ns
+		d.haveLast = true
+		d.synced = false
+		d.widths = nil
+		return nil
+	}
+	widthUs := float64(ns-d.lastNs) / 1e3
+	d.lastNs = ns
+
+	if widthUs >= ppmSyncGapUs {
+		var frame []float64
+		if d.synced && len(d.widths) > 0 {
+			frame = d.widths
+		}
+		d.widths = nil
+		d.synced = true
+		return frame
+	}
+	// until the first sync gap we don't know which channel a pulse belongs to
+	if d.synced && len(d.widths) < maxChannels {
+		d.widths = append(d.widths, widthUs)
+	}
+	return nil
+}
+
+func (c *Controller) startPPM(ctx context.Context, deps resource.Dependencies, boardName, interruptName string) error {
+	brd, err := board.FromDependencies(deps, boardName)
+	if err != nil {
+		return err
+	}
+	interrupt, err := brd.DigitalInterruptByName(interruptName)
+	if err != nil {
+		return err
+	}
+	tickChan := make(chan board.Tick)
+	if err := brd.StreamTicks(ctx, []board.DigitalInterrupt{interrupt}, tickChan, nil); err != nil {
+		return errors.Wrap(err, "error getting digital interrupt ticks")
+	}
+
+	c.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(func() {
+		var decoder ppmDecoder
+		for {
+			var tick board.Tick
+			select {
+			case <-ctx.Done():
+				return
+			case tick = <-tickChan:
+			}
+			if !tick.High {
+				continue
+			}
+			if frame := decoder.rise(tick.TimestampNanosec); frame != nil {
+				c.handleFrame(ctx, frame)
+			}
+		}
+	}, c.activeBackgroundWorkers.Done)
+	return nil
+}
@@ -0,0 +1,437 @@
+// Package rcreceiver implements an input.Controller for hobby RC receivers that output SBUS or PPM.
+//
+// Each configured receiver channel becomes either an axis, reporting PositionChangeAbs events, or a
+// button, reporting ButtonPress/ButtonRelease events when a switch on the transmitter is flipped. When the
+// receiver reports failsafe, or stops sending frames altogether, every control reports Disconnect so that
+// anything driving from it (such as the base remote control service) can stop.
+package rcreceiver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/input"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var model = resource.DefaultModelFamily.WithModel("rc_receiver")
+
+// The protocols a receiver can speak.
+const (
+	ProtocolSBUS = "sbus"
+	ProtocolPPM  = "ppm"
+)
+
+const (
+	// maxChannels is the number of proportional channels in an SBUS frame, and the most we accept over PPM.
+	maxChannels = 16
+
+	defaultMinUs     = 1000
+	defaultMaxUs     = 2000
+	defaultTimeoutMs = 500
+
+	// minAxisChange is how far an axis has to move, as a fraction of its output range, before a new event is
+	// sent. Receivers send frames every few milliseconds and the low bits are mostly noise.
+	minAxisChange = 0.005
+)
+
+// Config is the overall config.
+type Config struct {
+	// Protocol is either "sbus" or "ppm".
+	Protocol string `json:"protocol"`
+	// SerialPath is the serial port an SBUS receiver is attached to. SBUS is an inverted signal, so this
+	// needs to be a UART that supports inversion or have an inverter in front of it.
+	SerialPath string `json:"serial_path,omitempty"`
+	// Board and DigitalInterrupt name the interrupt a PPM receiver's signal pin is attached to.
+	Board            string           `json:"board,omitempty"`
+	DigitalInterrupt string           `json:"digital_interrupt,omitempty"`
+	Channels         []*ChannelConfig `json:"channels"`
+	// TimeoutMs is how long to go without a good frame before reporting Disconnect. Default is 500.
+	TimeoutMs int `json:"timeout_ms,omitempty"`
+}
+
+// ChannelConfig maps one receiver channel onto a control.
+type ChannelConfig struct {
+	// Channel is the receiver channel, starting from 1.
+	Channel int           `json:"channel"`
+	Control input.Control `json:"control"`
+	// MinUs and MaxUs are the pulse widths, in microseconds, at either end of the stick or switch.
+	// SBUS values are converted to the equivalent pulse width. Defaults are 1000 and 2000.
+	MinUs int `json:"min_us,omitempty"`
+	MaxUs int `json:"max_us,omitempty"`
+	// Bidirectional axes report -1 to 1 with 0 at the center stick; others report 0 to 1.
+	Bidirectional bool `json:"bidirectional,omitempty"`
+	// Deadzone is the fraction of the output range around zero that is reported as exactly zero.
+	Deadzone float64 `json:"deadzone,omitempty"`
+	Invert   bool    `json:"invert,omitempty"`
+	// Button treats the channel as a switch which is pressed when it is past its midpoint.
+	Button bool `json:"button,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	var deps []string
+	switch conf.Protocol {
+	case ProtocolSBUS:
+		if conf.SerialPath == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(path, "serial_path")
+		}
+	case ProtocolPPM:
+		if conf.Board == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(path, "board")
+		}
+		if conf.DigitalInterrupt == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(path, "digital_interrupt")
+		}
+		deps = append(deps, conf.Board)
+	case "":
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "protocol")
+	default:
+		return nil, resource.NewConfigValidationError(path,
+			errors.Errorf("unknown protocol %q, must be %q or %q", conf.Protocol, ProtocolSBUS, ProtocolPPM))
+	}
+	if len(conf.Channels) == 0 {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "channels")
+	}
+	if conf.TimeoutMs < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("timeout_ms cannot be negative"))
+	}
+
+	seen := map[input.Control]bool{}
+	for i, ch := range conf.Channels {
+		chPath := fmt.Sprintf("%s.channels.%d", path, i)
+		if ch == nil {
+			return nil, resource.NewConfigValidationError(chPath, errors.New("channel config cannot be empty"))
+		}
+		if ch.Control == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(chPath, "control")
+		}
+		if seen[ch.Control] {
+			return nil, resource.NewConfigValidationError(chPath, errors.Errorf("control %q is used more than once", ch.Control))
+		}
+		seen[ch.Control] = true
+		if ch.Channel < 1 || ch.Channel > maxChannels {
+			return nil, resource.NewConfigValidationError(chPath, errors.Errorf("channel must be between 1 and %d", maxChannels))
+		}
+		if ch.MinUs < 0 || ch.MaxUs < 0 {
+			return nil, resource.NewConfigValidationError(chPath, errors.New("min_us and max_us cannot be negative"))
+		}
+		if ch.Deadzone < 0 || ch.Deadzone >= 1 {
+			return nil, resource.NewConfigValidationError(chPath, errors.New("deadzone must be at least 0 and less than 1"))
+		}
+	}
+	return deps, nil
+}
+
+// validateValues fills in defaults and checks the values that depend on them.
+func (conf *Config) validateValues() error {
+	if conf.TimeoutMs == 0 {
+		conf.TimeoutMs = defaultTimeoutMs
+	}
+	for _, ch := range conf.Channels {
+		if ch.MinUs == 0 {
+			ch.MinUs = defaultMinUs
+		}
+		if ch.MaxUs == 0 {
+			ch.MaxUs = defaultMaxUs
+		}
+		if ch.MinUs >= ch.MaxUs {
+			return fmt.Errorf("min_us (%d) must be less than max_us (%d)", ch.MinUs, ch.MaxUs)
+		}
+	}
+	return nil
+}
+
+func init() {
+	resource.RegisterComponent(input.API, model, resource.Registration[input.Controller, *Config]{
+		Constructor: NewController,
+	})
+}
+
+// NewController returns a new input.Controller reading from an SBUS or PPM receiver.
+func NewController(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (input.Controller, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	if err := newConf.validateValues(); err != nil {
+		return nil, err
+	}
+
+	c := newController(conf.ResourceName(), newConf, logger)
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	c.cancelFunc = cancel
+
+	switch newConf.Protocol {
+	case ProtocolSBUS:
+		err = c.startSBUS(cancelCtx, newConf.SerialPath)
+	case ProtocolPPM:
+		err = c.startPPM(cancelCtx, deps, newConf.Board, newConf.DigitalInterrupt)
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	c.startWatchdog(cancelCtx)
+	return c, nil
+}
+
+func newController(name resource.Name, conf *Config, logger logging.Logger) *Controller {
+	c := &Controller{
+		Named:      name.AsNamed(),
+		logger:     logger,
+		channels:   conf.Channels,
+		timeout:    time.Duration(conf.TimeoutMs) * time.Millisecond,
+		cancelFunc: func() {},
+		callbacks:  map[input.Control]map[input.EventType]input.ControlFunction{},
+		lastEvents: map[input.Control]input.Event{},
+	}
+	for _, ch := range conf.Channels {
+		c.controls = append(c.controls, ch.Control)
+	}
+	return c
+}
+
+// A Controller creates an input.Controller from the channels of an RC receiver.
+type Controller struct {
+	resource.Named
+	resource.AlwaysRebuild
+	mu                      sync.RWMutex
+	controls                []input.Control
+	channels                []*ChannelConfig
+	timeout                 time.Duration
+	lastEvents              map[input.Control]input.Event
+	logger                  logging.Logger
+	activeBackgroundWorkers sync.WaitGroup
+	cancelFunc              func()
+	callbacks               map[input.Control]map[input.EventType]input.ControlFunction
+	port                    io.ReadWriteCloser
+
+	// frameMu guards the receiver's link state, which is only touched by the frame and watchdog loops.
+	frameMu   sync.Mutex
+	connected bool
+	lastFrame time.Time
+}
+
+// Controls lists the inputs.
+func (c *Controller) Controls(ctx context.Context, extra map[string]interface{}) ([]input.Control, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := append([]input.Control(nil), c.controls...)
+	return out, nil
+}
+
+// Events returns the last input.Event (the current state) of each control.
+func (c *Controller) Events(ctx context.Context, extra map[string]interface{}) (map[input.Control]input.Event, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make(map[input.Control]input.Event)
+	for key, value := range c.lastEvents {
+		out[key] = value
+	}
+	return out, nil
+}
+
+// RegisterControlCallback registers a callback function to be executed on the specified trigger Event.
+func (c *Controller) RegisterControlCallback(
+	ctx context.Context,
+	control input.Control,
+	triggers []input.EventType,
+	ctrlFunc input.ControlFunction,
+	extra map[string]interface{},
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.callbacks[control] == nil {
+		c.callbacks[control] = make(map[input.EventType]input.ControlFunction)
+	}
+
+	for _, trigger := range triggers {
+		if trigger == input.ButtonChange {
+			c.callbacks[control][input.ButtonRelease] = ctrlFunc
+			c.callbacks[control][input.ButtonPress] = ctrlFunc
+		} else {
+			c.callbacks[control][trigger] = ctrlFunc
+		}
+	}
+	return nil
+}
+
+// Close terminates background worker threads and releases the serial port, if any.
+func (c *Controller) Close(ctx context.Context) error {
+	c.cancelFunc()
+	var err error
+	if c.port != nil {
+		// closing the port unblocks the reader
+		err = c.port.Close()
+	}
+	c.activeBackgroundWorkers.Wait()
+	return err
+}
+
+func (c *Controller) makeCallbacks(ctx context.Context, eventOut input.Event) {
+	c.mu.Lock()
+	c.lastEvents[eventOut.Control] = eventOut
+	c.mu.Unlock()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ctrlFunc, ok := c.callbacks[eventOut.Control][eventOut.Event]
+	if ok && ctrlFunc != nil {
+		c.activeBackgroundWorkers.Add(1)
+		utils.PanicCapturingGo(func() {
+			defer c.activeBackgroundWorkers.Done()
+			ctrlFunc(ctx, eventOut)
+		})
+	}
+
+	ctrlFuncAll, ok := c.callbacks[eventOut.Control][input.AllEvents]
+	if ok && ctrlFuncAll != nil {
+		c.activeBackgroundWorkers.Add(1)
+		utils.PanicCapturingGo(func() {
+			defer c.activeBackgroundWorkers.Done()
+			ctrlFuncAll(ctx, eventOut)
+		})
+	}
+}
+
+func (c *Controller) sendConnectionStatus(ctx context.Context, connected bool) {
+	evType := input.Disconnect
+	now := time.Now()
+	if connected {
+		evType = input.Connect
+	}
+
+	for _, control := range c.controls {
+		eventOut := input.Event{
+			Time:    now,
+			Event:   evType,
+			Control: control,
+			Value:   0,
+		}
+		c.makeCallbacks(ctx, eventOut)
+	}
+}
+
+// handleFrame turns a frame of channel values, in microseconds, into events for any controls that changed.
+func (c *Controller) handleFrame(ctx context.Context, values []float64) {
+	c.frameMu.Lock()
+	defer c.frameMu.Unlock()
+	c.lastFrame = time.Now()
+	if !c.connected {
+		c.connected = true
+		c.sendConnectionStatus(ctx, true)
+	}
+
+	for _, ch := range c.channels {
+		if ch.Channel > len(values) {
+			continue
+		}
+		val := ch.scale(values[ch.Channel-1])
+
+		c.mu.RLock()
+		last, hasLast := c.lastEvents[ch.Control]
+		c.mu.RUnlock()
+
+		eventOut := input.Event{Time: c.lastFrame, Control: ch.Control}
+		if ch.Button {
+			pressed := val > 0.5
+			wasPressed := last.Event == input.ButtonPress
+			if hasLast && (last.Event == input.ButtonPress || last.Event == input.ButtonRelease) && pressed == wasPressed {
+				continue
+			}
+			eventOut.Event = input.ButtonRelease
+			if pressed {
+				eventOut.Event = input.ButtonPress
+				eventOut.Value = 1
+			}
+		} else {
+			if hasLast && last.Event == input.PositionChangeAbs && math.Abs(val-last.Value) < minAxisChange {
+				continue
+			}
+			eventOut.Event = input.PositionChangeAbs
+			eventOut.Value = val
+		}
+		c.makeCallbacks(ctx, eventOut)
+	}
+}
+
+// handleFailsafe reports that the receiver has lost its link to the transmitter.
+func (c *Controller) handleFailsafe(ctx context.Context) {
+	c.frameMu.Lock()
+	defer c.frameMu.Unlock()
+	c.disconnectLocked(ctx)
+}
+
+func (c *Controller) disconnectLocked(ctx context.Context) {
+	if !c.connected {
+		return
+	}
+	c.connected = false
+	c.logger.CWarn(ctx, "lost signal from RC transmitter")
+	c.sendConnectionStatus(ctx, false)
+}
+
+// startWatchdog disconnects the controls if the receiver goes quiet, which is what an unplugged receiver or
+// a PPM receiver with no failsafe output looks like.
+func (c *Controller) startWatchdog(ctx context.Context) {
+	c.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(func() {
+		ticker := time.NewTicker(c.timeout / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			c.frameMu.Lock()
+			if time.Since(c.lastFrame) > c.timeout {
+				c.disconnectLocked(ctx)
+			}
+			c.frameMu.Unlock()
+		}
+	}, c.activeBackgroundWorkers.Done)
+}
+
+// scale converts a pulse width in microseconds to the control's output range.
+func (ch *ChannelConfig) scale(us float64) float64 {
+	minUs, maxUs := float64(ch.MinUs), float64(ch.MaxUs)
+	us = math.Max(minUs, math.Min(maxUs, us))
+
+	if ch.Bidirectional && !ch.Button {
+		center := (minUs + maxUs) / 2
+		out := (us - center) / ((maxUs - minUs) / 2)
+		if math.Abs(out) < ch.Deadzone {
+			out = 0
+		}
+		if ch.Invert {
+			out *= -1
+		}
+		return out
+	}
+
+	out := (us - minUs) / (maxUs - minUs)
+	if ch.Invert {
+		// flip within [0, 1] so that a throttle at rest still reads 0 after inverting
+		out = 1 - out
+	}
+	if out < ch.Deadzone {
+		out = 0
+	}
+	return out
+}
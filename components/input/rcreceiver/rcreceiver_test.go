@@ -0,0 +1,218 @@
+package rcreceiver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/input"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+// encodeSBUSFrame packs channel values the way a receiver does.
+func encodeSBUSFrame(channels [maxChannels]uint16, flags byte) []byte {
+	frame := make([]byte, sbusFrameLen)
+	frame[0] = sbusHeader
+	var bits uint32
+	var nbits uint
+	idx := 1
+	for _, ch := range channels {
+		bits |= uint32(ch&0x7FF) << nbits
+		nbits += sbusChannelBits
+		for nbits >= 8 {
+			frame[idx] = byte(bits)
+			idx++
+			bits >>= 8
+			nbits -= 8
+		}
+	}
+	frame[23] = flags
+	frame[24] = sbusFooter
+	return frame
+}
+
+func TestParseSBUSFrame(t *testing.T) {
+	var channels [maxChannels]uint16
+	for i := range channels {
+		channels[i] = uint16(172 + i*100)
+	}
+	frame, err := parseSBUSFrame(encodeSBUSFrame(channels, 0))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, frame.channels, test.ShouldResemble, channels)
+	test.That(t, frame.failsafe, test.ShouldBeFalse)
+	test.That(t, frame.frameLost, test.ShouldBeFalse)
+
+	frame, err = parseSBUSFrame(encodeSBUSFrame(channels, sbusFlagFailsafe|sbusFlagFrameLost))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, frame.failsafe, test.ShouldBeTrue)
+	test.That(t, frame.frameLost, test.ShouldBeTrue)
+
+	channels[0] = sbusCenter
+	channels[1] = 172
+	channels[2] = 1811
+	frame, err = parseSBUSFrame(encodeSBUSFrame(channels, 0))
+	test.That(t, err, test.ShouldBeNil)
+	widths := frame.pulseWidths()
+	test.That(t, len(widths), test.ShouldEqual, maxChannels)
+	test.That(t, widths[0], test.ShouldAlmostEqual, 1500)
+	test.That(t, widths[1], test.ShouldAlmostEqual, 987.5)
+	test.That(t, widths[2], test.ShouldAlmostEqual, 2011.875)
+
+	_, err = parseSBUSFrame([]byte{sbusHeader, 1, 2})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestReadSBUSFrame(t *testing.T) {
+	var channels [maxChannels]uint16
+	for i := range channels {
+		channels[i] = sbusHeader
+	}
+	frame := encodeSBUSFrame(channels, 0)
+
+	// start partway through a frame, so the first header byte seen is really channel data
+	stream := append(append([]byte{}, frame[5:]...), frame...)
+	r := bufio.NewReader(bytes.NewReader(stream))
+	got, err := readSBUSFrame(r)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, got, test.ShouldResemble, frame)
+
+	_, err = readSBUSFrame(r)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestPPMDecoder(t *testing.T) {
+	var d ppmDecoder
+	var now uint64
+	rise := func(us uint64) []float64 {
+		now += us * 1000
+		return d.rise(now)
+	}
+
+	// pulses before the first sync gap belong to an unknown channel and are dropped
+	test.That(t, rise(0), test.ShouldBeNil)
+	test.That(t, rise(1500), test.ShouldBeNil)
+	test.That(t, rise(8000), test.ShouldBeNil)
+
+	for _, us := range []uint64{1000, 1500, 2000, 1200} {
+		test.That(t, rise(us), test.ShouldBeNil)
+	}
+	test.That(t, rise(9000), test.ShouldResemble, []float64{1000, 1500, 2000, 1200})
+
+	// time going backwards starts over
+	now = 0
+	test.That(t, d.rise(now), test.ShouldBeNil)
+	test.That(t, rise(1500), test.ShouldBeNil)
+	test.That(t, rise(8000), test.ShouldBeNil)
+}
+
+func TestValidate(t *testing.T) {
+	conf := &Config{
+		Protocol:         ProtocolPPM,
+		Board:            "board",
+		DigitalInterrupt: "ppm",
+		Channels:         []*ChannelConfig{{Channel: 1, Control: input.AbsoluteX}},
+	}
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"board"})
+
+	conf.DigitalInterrupt = ""
+	_, err = conf.Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "digital_interrupt")
+
+	conf = &Config{Protocol: ProtocolSBUS, Channels: conf.Channels}
+	_, err = conf.Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "serial_path")
+
+	conf.SerialPath = "/dev/ttyAMA0"
+	deps, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldBeEmpty)
+
+	conf.Protocol = "crsf"
+	_, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "unknown protocol")
+
+	conf.Protocol = ProtocolSBUS
+	conf.Channels = []*ChannelConfig{{Channel: 17, Control: input.AbsoluteX}}
+	_, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "channel must be between 1 and 16")
+
+	conf.Channels = []*ChannelConfig{{Channel: 1, Control: input.AbsoluteX}, {Channel: 2, Control: input.AbsoluteX}}
+	_, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "used more than once")
+
+	conf.Channels = []*ChannelConfig{{Channel: 1, Control: input.AbsoluteX, MinUs: 2000, MaxUs: 1000}}
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, conf.validateValues(), test.ShouldNotBeNil)
+}
+
+func TestHandleFrame(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	conf := &Config{
+		Protocol:   ProtocolSBUS,
+		SerialPath: "/dev/null",
+		Channels: []*ChannelConfig{
+			{Channel: 1, Control: input.AbsoluteX, Bidirectional: true, Deadzone: 0.05},
+			{Channel: 3, Control: input.AbsoluteY, Invert: true},
+			{Channel: 5, Control: input.ButtonEStop, Button: true},
+		},
+	}
+	test.That(t, conf.validateValues(), test.ShouldBeNil)
+	c := newController(input.Named("rc"), conf, logger)
+
+	var mu sync.Mutex
+	var got []input.Event
+	record := func(ctx context.Context, ev input.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, ev)
+	}
+	for _, control := range []input.Control{input.AbsoluteX, input.AbsoluteY, input.ButtonEStop} {
+		err := c.RegisterControlCallback(ctx, control, []input.EventType{input.AllEvents}, record, nil)
+		test.That(t, err, test.ShouldBeNil)
+	}
+
+	c.handleFrame(ctx, []float64{1520, 0, 1250, 0, 1900})
+	test.That(t, c.Close(ctx), test.ShouldBeNil)
+
+	events, err := c.Events(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	// inside the deadzone
+	test.That(t, events[input.AbsoluteX].Event, test.ShouldEqual, input.PositionChangeAbs)
+	test.That(t, events[input.AbsoluteX].Value, test.ShouldEqual, 0)
+	test.That(t, events[input.AbsoluteY].Value, test.ShouldAlmostEqual, 0.75)
+	test.That(t, events[input.ButtonEStop].Event, test.ShouldEqual, input.ButtonPress)
+	mu.Lock()
+	// a Connect for each control, then one event each
+	test.That(t, len(got), test.ShouldEqual, 6)
+	got = nil
+	mu.Unlock()
+
+	// only changes are reported
+	c.handleFrame(ctx, []float64{1520, 0, 1251, 0, 1900})
+	c.handleFrame(ctx, []float64{2000, 0, 1251, 0, 1100})
+	test.That(t, c.Close(ctx), test.ShouldBeNil)
+	events, err = c.Events(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, events[input.AbsoluteX].Value, test.ShouldAlmostEqual, 1)
+	test.That(t, events[input.ButtonEStop].Event, test.ShouldEqual, input.ButtonRelease)
+	mu.Lock()
+	test.That(t, len(got), test.ShouldEqual, 2)
+	mu.Unlock()
+
+	c.handleFailsafe(ctx)
+	test.That(t, c.Close(ctx), test.ShouldBeNil)
+	events, err = c.Events(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	for _, control := range c.controls {
+		test.That(t, events[control].Event, test.ShouldEqual, input.Disconnect)
+	}
+}
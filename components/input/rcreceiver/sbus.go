@@ -0,0 +1,161 @@
+package rcreceiver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"time"
+
+	slib "github.com/jacobsa/go-serial/serial"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+)
+
+// An SBUS frame is a header byte, 16 channels of 11 bits each packed little-endian into 22 bytes, a flags
+// byte, and a footer byte. Frames are sent at 100000 baud, 8E2, every 7 or 14 milliseconds.
+const (
+	sbusBaudRate    = 100000
+	sbusFrameLen    = 25
+	sbusHeader      = 0x0F
+	sbusFooter      = 0x00
+	sbusChannelBits = 11
+
+	sbusFlagFrameLost = 1 << 2
+	sbusFlagFailsafe  = 1 << 3
+
+	// sbusCenter is the raw SBUS value for a centered stick. Each raw step is 0.625 microseconds of
+	// equivalent PWM pulse width, so the usual 172 to 1811 range maps to roughly 988 to 2012.
+	sbusCenter    = 992
+	sbusUsPerStep = 0.625
+)
+
+// sbusFrame is a decoded SBUS frame.
+type sbusFrame struct {
+	channels  [maxChannels]uint16
+	frameLost bool
+	failsafe  bool
+}
+
+// isSBUSFooter reports whether b can end a frame. Plain SBUS ends with zero; SBUS2 receivers cycle the
+// high nibble through telemetry slot numbers and always end in 0x4.
+func isSBUSFooter(b byte) bool {
+	return b == sbusFooter || b&0x0F == 0x04
+}
+
+// readSBUSFrame reads from r until it has a complete frame, resynchronizing on the header byte if it
+// starts partway through one.
+func readSBUSFrame(r io.ByteReader) ([]byte, error) {
+	frame := make([]byte, 0, sbusFrameLen)
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if len(frame) == 0 && b != sbusHeader {
+			continue
+		}
+		frame = append(frame, b)
+		if len(frame) < sbusFrameLen {
+			continue
+		}
+		if isSBUSFooter(frame[sbusFrameLen-1]) {
+			return frame, nil
+		}
+		// the header we locked on to was really channel data; try again from the next candidate
+		next := bytes.IndexByte(frame[1:], sbusHeader)
+		if next < 0 {
+			frame = frame[:0]
+		} else {
+			frame = append(frame[:0], frame[next+1:]...)
+		}
+	}
+}
+
+// parseSBUSFrame decodes a frame returned by readSBUSFrame.
+func parseSBUSFrame(frame []byte) (sbusFrame, error) {
+	var out sbusFrame
+	if len(frame) != sbusFrameLen || frame[0] != sbusHeader || !isSBUSFooter(frame[sbusFrameLen-1]) {
+		return out, errors.Errorf("malformed SBUS frame % x", frame)
+	}
+
+	var bits uint32
+	var nbits uint
+	ch := 0
+	for _, b := range frame[1:23] {
+		bits |= uint32(b) << nbits
+		nbits += 8
+		for nbits >= sbusChannelBits && ch < maxChannels {
+			out.channels[ch] = uint16(bits & (1<<sbusChannelBits - 1))
+			bits >>= sbusChannelBits
+			nbits -= sbusChannelBits
+			ch++
+		}
+	}
+
+	flags := frame[23]
+	out.frameLost = flags&sbusFlagFrameLost != 0
+	out.failsafe = flags&sbusFlagFailsafe != 0
+	return out, nil
+}
+
+// pulseWidths converts the frame's channels to the equivalent PWM pulse widths in microseconds.
+func (f sbusFrame) pulseWidths() []float64 {
+	out := make([]float64, maxChannels)
+	for i, raw := range f.channels {
+		out[i] = 1500 + (float64(raw)-sbusCenter)*sbusUsPerStep
+	}
+	return out
+}
+
+func (c *Controller) startSBUS(ctx context.Context, path string) error {
+	options := slib.OpenOptions{
+		PortName:        path,
+		BaudRate:        sbusBaudRate,
+		DataBits:        8,
+		StopBits:        2,
+		ParityMode:      slib.PARITY_EVEN,
+		MinimumReadSize: 1,
+	}
+	port, err := slib.Open(options)
+	if err != nil {
+		return errors.Wrapf(err, "error opening SBUS serial port %s", path)
+	}
+	c.port = port
+
+	c.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(func() {
+		c.readSBUS(ctx, bufio.NewReader(port))
+	}, c.activeBackgroundWorkers.Done)
+	return nil
+}
+
+func (c *Controller) readSBUS(ctx context.Context, r io.ByteReader) {
+	for {
+		raw, err := readSBUSFrame(r)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.logger.CErrorw(ctx, "error reading from SBUS receiver", "error", err)
+			if !utils.SelectContextOrWait(ctx, 100*time.Millisecond) {
+				return
+			}
+			continue
+		}
+		frame, err := parseSBUSFrame(raw)
+		if err != nil {
+			c.logger.CDebug(ctx, err)
+			continue
+		}
+		if frame.failsafe {
+			c.handleFailsafe(ctx)
+			continue
+		}
+		// a single lost frame is repeated data, not a loss of signal; the watchdog catches a run of them
+		if frame.frameLost {
+			continue
+		}
+		c.handleFrame(ctx, frame.pulseWidths())
+	}
+}
@@ -7,5 +7,6 @@ import (
 	_ "go.viam.com/rdk/components/input/gamepad"
 	_ "go.viam.com/rdk/components/input/gpio"
 	_ "go.viam.com/rdk/components/input/mux"
+	_ "go.viam.com/rdk/components/input/rcreceiver"
 	_ "go.viam.com/rdk/components/input/webgamepad"
 )
@@ -50,6 +50,9 @@ type Config struct {
 	MaxRPM           float64   `json:"max_rpm,omitempty"`
 	TicksPerRotation int       `json:"ticks_per_rotation,omitempty"`
 	DirectionFlip    bool      `json:"direction_flip,omitempty"`
+	// SimulatePosition has the motor work out its position from how fast it has been told to turn,
+	// so it can report position without an encoder.
+	SimulatePosition bool `json:"simulate_position,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -59,6 +62,9 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 		deps = append(deps, cfg.BoardName)
 	}
 	if cfg.Encoder != "" {
+		if cfg.SimulatePosition {
+			return nil, resource.NewConfigValidationError(path, errors.New("cannot simulate position for a motor with an encoder"))
+		}
 		if cfg.TicksPerRotation <= 0 {
 			return nil, resource.NewConfigValidationError(path, errors.New("need nonzero TicksPerRotation for encoded motor"))
 		}
@@ -88,6 +94,11 @@ type Motor struct {
 	MaxRPM            float64
 	DirFlip           bool
 	TicksPerRotation  int
+	SimulatePosition  bool
+
+	// simPosition is the simulated position in rotations as of simUpdatedAt, when SimulatePosition is set.
+	simPosition  float64
+	simUpdatedAt time.Time
 
 	OpMgr  *operation.SingleOperationManager
 	Logger logging.Logger
@@ -163,8 +174,11 @@ func (m *Motor) Reconfigure(ctx context.Context, deps resource.Dependencies, con
 		}
 		m.Encoder = fakeEncoder
 		m.PositionReporting = true
+		m.SimulatePosition = false
 	} else {
-		m.PositionReporting = false
+		m.Encoder = nil
+		m.SimulatePosition = newConf.SimulatePosition
+		m.PositionReporting = newConf.SimulatePosition
 	}
 	m.DirFlip = false
 	if newConf.DirectionFlip {
@@ -179,6 +193,10 @@ func (m *Motor) Position(ctx context.Context, extra map[string]interface{}) (flo
 	defer m.mu.Unlock()
 
 	if m.Encoder == nil {
+		if m.SimulatePosition {
+			m.advanceSimulatedPosition()
+			return m.simPosition, nil
+		}
 		return 0, errors.New("encoder is not defined")
 	}
 
@@ -225,9 +243,35 @@ func (m *Motor) SetPower(ctx context.Context, powerPct float64, extra map[string
 }
 
 func (m *Motor) setPowerPct(powerPct float64) {
+	m.advanceSimulatedPosition()
 	m.powerPct = powerPct
 }
 
+// advanceSimulatedPosition moves the simulated position on by however far the motor has turned at
+// its current power since it was last advanced. It must be called while holding mu.
+func (m *Motor) advanceSimulatedPosition() {
+	if !m.SimulatePosition {
+		return
+	}
+	now := time.Now()
+	if !m.simUpdatedAt.IsZero() {
+		m.simPosition += m.powerPct * m.MaxRPM * now.Sub(m.simUpdatedAt).Minutes()
+	}
+	m.simUpdatedAt = now
+}
+
+// setPosition sets the position of the motor in rotations, whether it's tracked by an encoder or simulated.
+func (m *Motor) setPosition(ctx context.Context, pos float64) error {
+	if m.Encoder != nil {
+		return m.Encoder.SetPosition(ctx, pos*float64(m.TicksPerRotation))
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.simPosition = pos
+	m.simUpdatedAt = time.Now()
+	return nil
+}
+
 // PowerPct returns the set power percentage.
 func (m *Motor) PowerPct() float64 {
 	m.mu.Lock()
@@ -303,7 +347,7 @@ func (m *Motor) GoFor(ctx context.Context, rpm, revolutions float64, extra map[s
 	powerPct, waitDur, dir := goForMath(m.MaxRPM, rpm, revolutions)
 
 	var finalPos float64
-	if m.Encoder != nil {
+	if m.PositionReporting {
 		curPos, err := m.Position(ctx, nil)
 		if err != nil {
 			return err
@@ -326,8 +370,8 @@ func (m *Motor) GoFor(ctx context.Context, rpm, revolutions float64, extra map[s
 			return err
 		}
 
-		if m.Encoder != nil {
-			return m.Encoder.SetPosition(ctx, finalPos*float64(m.TicksPerRotation))
+		if m.PositionReporting {
+			return m.setPosition(ctx, finalPos)
 		}
 	}
 	return nil
@@ -335,7 +379,7 @@ func (m *Motor) GoFor(ctx context.Context, rpm, revolutions float64, extra map[s
 
 // GoTo sets the given direction and an arbitrary power percentage for now.
 func (m *Motor) GoTo(ctx context.Context, rpm, pos float64, extra map[string]interface{}) error {
-	if m.Encoder == nil {
+	if !m.PositionReporting {
 		return errors.New("encoder is not defined")
 	}
 
@@ -374,7 +418,7 @@ func (m *Motor) GoTo(ctx context.Context, rpm, pos float64, extra map[string]int
 			return err
 		}
 
-		return m.Encoder.SetPosition(ctx, pos*float64(m.TicksPerRotation))
+		return m.setPosition(ctx, pos)
 	}
 
 	return nil
@@ -412,6 +456,9 @@ func (m *Motor) Stop(ctx context.Context, extra map[string]interface{}) error {
 
 // ResetZeroPosition resets the zero position.
 func (m *Motor) ResetZeroPosition(ctx context.Context, offset float64, extra map[string]interface{}) error {
+	if m.SimulatePosition {
+		return m.setPosition(ctx, -1*offset)
+	}
 	if m.Encoder == nil {
 		return errors.New("encoder is not defined")
 	}
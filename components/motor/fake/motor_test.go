@@ -218,3 +218,48 @@ func TestPower(t *testing.T) {
 	powerPct = m.PowerPct()
 	test.That(t, powerPct, test.ShouldEqual, 0.0)
 }
+
+func TestSimulatedPosition(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	conf := resource.Config{
+		Name:                "motor",
+		ConvertedAttributes: &Config{MaxRPM: 6000, SimulatePosition: true},
+	}
+	mot, err := NewMotor(ctx, nil, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	m := mot.(*Motor)
+
+	properties, err := m.Properties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, properties.PositionReporting, test.ShouldBeTrue)
+
+	// The position is integrated from the commanded speed.
+	test.That(t, m.SetRPM(ctx, 6000, nil), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		pos, err := m.Position(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, pos, test.ShouldBeGreaterThan, 1)
+	})
+	test.That(t, m.Stop(ctx, nil), test.ShouldBeNil)
+	stopped, err := m.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	pos, err := m.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pos, test.ShouldEqual, stopped)
+
+	test.That(t, m.GoTo(ctx, 6000, stopped-2, nil), test.ShouldBeNil)
+	pos, err = m.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pos, test.ShouldEqual, stopped-2)
+
+	test.That(t, m.ResetZeroPosition(ctx, 0, nil), test.ShouldBeNil)
+	pos, err = m.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pos, test.ShouldEqual, 0)
+
+	_, err = (&Config{Encoder: "enc", TicksPerRotation: 1, SimulatePosition: true}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
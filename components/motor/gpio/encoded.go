@@ -8,9 +8,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/benbjohnson/clock"
 	"github.com/pkg/errors"
 	"go.uber.org/multierr"
-	"go.viam.com/utils"
 
 	"go.viam.com/rdk/components/encoder"
 	"go.viam.com/rdk/components/motor"
@@ -75,6 +75,7 @@ func newEncodedMotor(
 		maxPowerPct:      motorConfig.MaxPowerPct,
 		logger:           logger,
 		opMgr:            operation.NewSingleOperationManager(),
+		clock:            clock.New(),
 	}
 
 	em.encoder = realEncoder
@@ -123,6 +124,8 @@ type EncodedMotor struct {
 
 	logger logging.Logger
 	opMgr  *operation.SingleOperationManager
+	// clock times the control loop, and is only replaced in tests.
+	clock clock.Clock
 }
 
 // makeAdjustments keeps track of the desired RPM and position.
@@ -131,7 +134,7 @@ func (m *EncodedMotor) makeAdjustments(ctx context.Context, goalRPM, goalPos, di
 	if err != nil {
 		return err
 	}
-	lastTime := time.Now().UnixNano()
+	lastTime := m.clock.Now()
 	_, lastPowerPct, err := m.real.IsPowered(ctx, nil)
 	if err != nil {
 		m.logger.Error(err)
@@ -139,7 +142,7 @@ func (m *EncodedMotor) makeAdjustments(ctx context.Context, goalRPM, goalPos, di
 	}
 	lastPowerPct = math.Abs(lastPowerPct) * direction
 	for {
-		timer := time.NewTimer(50 * time.Millisecond)
+		timer := m.clock.Timer(50 * time.Millisecond)
 		select {
 		case <-ctx.Done():
 			timer.Stop()
@@ -151,28 +154,21 @@ func (m *EncodedMotor) makeAdjustments(ctx context.Context, goalRPM, goalPos, di
 		currentTicks, _, err := m.encoder.Position(ctx, encoder.PositionTypeTicks, nil)
 		if err != nil {
 			m.logger.CInfo(ctx, "error getting encoder position, sleeping then continuing: %w", err)
-			if !utils.SelectContextOrWait(ctx, 100*time.Millisecond) {
+			select {
+			case <-ctx.Done():
 				m.logger.CInfo(ctx, "error sleeping, giving up %w", ctx.Err())
 				return err
+			case <-m.clock.After(100 * time.Millisecond):
 			}
 			continue
 		}
-		now := time.Now().UnixNano()
+		now := m.clock.Now()
 		if (goalPos-currentTicks)*direction < 0 {
 			// stop motor when at or past goal position
 			return m.Stop(ctx, nil)
 		}
 
-		// calculate RPM based on change in position and change in time
-		deltaPos := (currentTicks - lastTicks) / m.ticksPerRotation
-		// time is polled in nanoseconds, convert to minutes for rpm
-		deltaTime := (float64(now) - float64(lastTime)) / float64(6e10)
-		var currentRPM float64
-		if deltaTime == 0.0 {
-			currentRPM = 0
-		} else {
-			currentRPM = deltaPos / deltaTime
-		}
+		currentRPM := calcRPM(currentTicks-lastTicks, m.ticksPerRotation, now.Sub(lastTime))
 
 		newPower, err := m.calcNewPowerPct(ctx, currentRPM, goalRPM, lastPowerPct, direction)
 		if err != nil {
@@ -190,6 +186,14 @@ func (m *EncodedMotor) makeAdjustments(ctx context.Context, goalRPM, goalPos, di
 	}
 }
 
+// calcRPM returns how fast the motor turned, given how many ticks it moved by in the elapsed time.
+func calcRPM(deltaTicks, ticksPerRotation float64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return deltaTicks / ticksPerRotation / elapsed.Minutes()
+}
+
 // calcNewPowerPct does the math required to see if the RPM is too high or too low,
 // and calculates the new power percent needed.
 func (m *EncodedMotor) calcNewPowerPct(
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/benbjohnson/clock"
 	"go.viam.com/test"
 	"go.viam.com/utils"
 	"go.viam.com/utils/testutils"
@@ -295,3 +296,47 @@ func TestEncodedMotor(t *testing.T) {
 		cancel()
 	})
 }
+
+func TestCalcRPM(t *testing.T) {
+	test.That(t, calcRPM(10, 1, time.Minute), test.ShouldAlmostEqual, 10)
+	test.That(t, calcRPM(50, 100, 50*time.Millisecond), test.ShouldAlmostEqual, 600)
+	test.That(t, calcRPM(-50, 100, 50*time.Millisecond), test.ShouldAlmostEqual, -600)
+	test.That(t, calcRPM(10, 1, 0), test.ShouldEqual, 0)
+}
+
+func TestMakeAdjustmentsWithMockClock(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	vals := newState()
+	wrappedMotor, err := WrapMotorWithEncoder(
+		context.Background(), injectEncoder(vals), resource.Config{Name: motorName}, Config{TicksPerRotation: 1}, injectMotor(vals), logger)
+	test.That(t, err, test.ShouldBeNil)
+	m := wrappedMotor.(*EncodedMotor)
+	defer func() {
+		test.That(t, m.Close(context.Background()), test.ShouldBeNil)
+	}()
+	mockClock := clock.NewMock()
+	m.clock = mockClock
+
+	// every adjustment moves the injected motor a tick, so it gets past 3 ticks after a few
+	// adjustments, each of which waits on mock time rather than real time
+	test.That(t, m.real.SetPower(context.Background(), 0.2, nil), test.ShouldBeNil)
+	done := make(chan error, 1)
+	go func() {
+		done <- m.makeAdjustments(context.Background(), 100, 3, 1)
+	}()
+	for {
+		select {
+		case err := <-done:
+			test.That(t, err, test.ShouldBeNil)
+			vals.mu.Lock()
+			test.That(t, vals.position, test.ShouldBeGreaterThan, 3)
+			vals.mu.Unlock()
+			_, powerPct, err := m.real.IsPowered(context.Background(), nil)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, powerPct, test.ShouldEqual, 0)
+			return
+		default:
+			mockClock.Add(50 * time.Millisecond)
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/benbjohnson/clock"
 	"github.com/pkg/errors"
 	"go.uber.org/multierr"
 
@@ -124,6 +125,7 @@ func new28byj(
 		logger:           logger,
 		motorName:        conf.Name,
 		opMgr:            operation.NewSingleOperationManager(),
+		clock:            clock.New(),
 	}
 
 	in1, err := b.GPIOPinByName(mc.Pins.In1)
@@ -163,6 +165,8 @@ type uln28byj struct {
 	in1, in2, in3, in4 board.GPIOPin
 	logger             logging.Logger
 	motorName          string
+	// clock times the steps, and is only replaced in tests.
+	clock clock.Clock
 
 	// state
 	lock  sync.Mutex
@@ -226,7 +230,7 @@ func (m *uln28byj) doStep(ctx context.Context, forward bool) error {
 		return err
 	}
 
-	time.Sleep(m.stepperDelay)
+	m.clock.Sleep(m.stepperDelay)
 	return nil
 }
 
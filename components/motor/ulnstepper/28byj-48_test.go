@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/benbjohnson/clock"
 	"go.viam.com/test"
 
 	"go.viam.com/rdk/components/board"
@@ -341,3 +342,41 @@ func (m *mockGPIOPin) Set(ctx context.Context, high bool, extra map[string]inter
 	m.pinStates = append(m.pinStates, high)
 	return nil
 }
+
+func TestGoForWithMockClock(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	deps := setupDependencies(t)
+
+	c := resource.Config{
+		Name: "fake_28byj",
+		ConvertedAttributes: &Config{
+			Pins:             PinConfig{In1: "1", In2: "2", In3: "3", In4: "4"},
+			BoardName:        testBoardName,
+			TicksPerRotation: 100,
+		},
+	}
+	mm, err := new28byj(ctx, deps, c, logger)
+	test.That(t, err, test.ShouldBeNil)
+	m := mm.(*uln28byj)
+	mockClock := clock.NewMock()
+	m.clock = mockClock
+
+	// at 1 RPM each step takes 600ms, so 5 steps would take 3s of real time
+	done := make(chan error, 1)
+	go func() {
+		done <- m.GoFor(ctx, 1, 0.05, nil)
+	}()
+	for {
+		select {
+		case err := <-done:
+			test.That(t, err, test.ShouldBeNil)
+			pos, err := m.Position(ctx, nil)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, pos, test.ShouldAlmostEqual, 0.05)
+			return
+		default:
+			mockClock.Add(600 * time.Millisecond)
+		}
+	}
+}
@@ -0,0 +1,527 @@
+//go:build linux
+
+// Package bno055 implements the movementsensor interface for a Bosch BNO055 9-axis absolute orientation
+// sensor. A datasheet for this chip is at
+// https://www.bosch-sensortec.com/media/boschsensortec/downloads/datasheets/bst-bno055-ds000.pdf
+//
+// The chip runs its own sensor fusion, and we use it in NDOF mode, in which it reports orientation relative
+// to magnetic north. We support reading the orientation, compass heading, angular velocity, linear
+// acceleration, magnetic field, and temperature.
+//
+// The fusion is only accurate once the chip has calibrated itself, which it does continuously in the
+// background as it is moved around, but forgets at power off. The "calibration_status" DoCommand reports how
+// far along it is, from 0 to 3 for each sensor. Once all of them are at 3, the "save_calibration" DoCommand
+// reads the chip's calibration offsets and, if calibration_file is configured, writes them there so they are
+// loaded back onto the chip at startup.
+//
+// The chip has two possible I2C addresses, which can be selected by wiring the COM3 pin to either ground
+// (0x28, the default) or hot (0x29). If you use the alternate address, your config file for this component
+// must set its "use_alt_i2c_address" boolean to true.
+package bno055
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/num/quat"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("imu-bno055")
+
+const (
+	defaultAddress   = 0x28
+	alternateAddress = 0x29
+	expectedChipID   = 0xA0
+
+	chipIDRegister      = 0x00
+	accelDataRegister   = 0x08 // followed by magnetometer, gyroscope, and euler angle data
+	quatDataRegister    = 0x20 // followed by linear acceleration, gravity, temperature, and calibration status
+	unitSelectRegister  = 0x3B
+	opModeRegister      = 0x3D
+	calibOffsetRegister = 0x55
+	calibOffsetLength   = 22
+
+	opModeConfig = 0x00
+	opModeNDOF   = 0x0C
+
+	// how long the chip takes to switch operating modes, from table 3-6 of the datasheet.
+	configModeSwitchTime = 20 * time.Millisecond
+	ndofModeSwitchTime   = 10 * time.Millisecond
+)
+
+// Config is used to configure the attributes of the chip.
+type Config struct {
+	I2cBus                 string `json:"i2c_bus"`
+	UseAlternateI2CAddress bool   `json:"use_alt_i2c_address,omitempty"`
+	// CalibrationFile is where the "save_calibration" DoCommand stores the calibration offsets, and where they
+	// are loaded from at startup.
+	CalibrationFile string `json:"calibration_file,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid, and then returns the list of things we
+// depend on.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.I2cBus == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "i2c_bus")
+	}
+
+	var deps []string
+	return deps, nil
+}
+
+func init() {
+	resource.RegisterComponent(movementsensor.API, model, resource.Registration[movementsensor.MovementSensor, *Config]{
+		Constructor: newBNO055,
+	})
+}
+
+type bno055 struct {
+	resource.Named
+	resource.AlwaysRebuild
+	bus             buses.I2C
+	i2cAddress      byte
+	calibrationFile string
+
+	// busMu keeps the background reader off the bus while the chip is out of NDOF mode.
+	busMu sync.Mutex
+
+	// lock mu before reading or writing the measurements.
+	mu                 sync.Mutex
+	orientation        quat.Number
+	heading            float64
+	angularVelocity    spatialmath.AngularVelocity
+	linearAcceleration r3.Vector
+	magneticField      r3.Vector
+	temperature        float64
+	calibrationStatus  byte
+	// Stores the most recent error from the background goroutine
+	err movementsensor.LastError
+
+	workers utils.StoppableWorkers
+	logger  logging.Logger
+}
+
+// calibrationData is what is stored in the calibration file.
+type calibrationData struct {
+	Offsets []int `json:"offsets"`
+}
+
+func addressReadError(err error, address byte, bus string) error {
+	msg := fmt.Sprintf("can't read from I2C address %d on bus %s", address, bus)
+	return errors.Wrap(err, msg)
+}
+
+func unexpectedDeviceError(address, chipID byte) error {
+	return errors.Errorf("unexpected non-BNO055 device at address %d: chip id '%d'", address, chipID)
+}
+
+func newBNO055(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (movementsensor.MovementSensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+
+	bus, err := buses.NewI2cBus(newConf.I2cBus)
+	if err != nil {
+		return nil, err
+	}
+	return makeBNO055(ctx, deps, conf, logger, bus)
+}
+
+// This function is separated from newBNO055 solely so you can inject a mock I2C bus in tests.
+func makeBNO055(
+	ctx context.Context,
+	_ resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+	bus buses.I2C,
+) (movementsensor.MovementSensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+
+	address := byte(defaultAddress)
+	if newConf.UseAlternateI2CAddress {
+		address = alternateAddress
+	}
+	logger.CDebugf(ctx, "Using address %d for BNO055 sensor", address)
+
+	sensor := &bno055{
+		Named:           conf.ResourceName().AsNamed(),
+		bus:             bus,
+		i2cAddress:      address,
+		calibrationFile: newConf.CalibrationFile,
+		orientation:     quat.Number{Real: 1},
+		logger:          logger,
+		// On overloaded boards, the I2C bus can become flaky. Only report errors if at least 5 of
+		// the last 10 attempts to talk to the device have failed.
+		err: movementsensor.NewLastError(10, 5),
+	}
+
+	chipID, err := sensor.readBlock(ctx, chipIDRegister, 1)
+	if err != nil {
+		return nil, addressReadError(err, address, newConf.I2cBus)
+	}
+	if chipID[0] != expectedChipID {
+		return nil, unexpectedDeviceError(address, chipID[0])
+	}
+
+	if err := sensor.setMode(ctx, opModeConfig); err != nil {
+		return nil, errors.Wrap(err, "unable to put BNO055 into config mode")
+	}
+	// m/s^2, degrees per second, degrees, Celsius, and Windows orientation, in which heading increases clockwise
+	if err := sensor.writeByte(ctx, unitSelectRegister, 0x00); err != nil {
+		return nil, err
+	}
+	if offsets, err := sensor.loadCalibrationFile(); err != nil {
+		logger.CWarnw(ctx, "not loading BNO055 calibration", "error", err)
+	} else if offsets != nil {
+		if err := sensor.writeBlock(ctx, calibOffsetRegister, offsets); err != nil {
+			return nil, errors.Wrap(err, "unable to load BNO055 calibration")
+		}
+	}
+	if err := sensor.setMode(ctx, opModeNDOF); err != nil {
+		return nil, errors.Wrap(err, "unable to start BNO055 fusion")
+	}
+
+	// Now, turn on the background goroutine that constantly reads from the chip. The fusion output updates at
+	// 100Hz, so there is no point reading any faster.
+	sensor.workers = utils.NewStoppableWorkers(func(cancelCtx context.Context) {
+		timer := time.NewTicker(10 * time.Millisecond)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-timer.C:
+				err := sensor.poll(cancelCtx)
+				// Record `err` no matter what: even if it's nil, that's useful information.
+				sensor.err.Set(err)
+				if err != nil {
+					sensor.logger.CErrorf(ctx, "error reading BNO055 sensor: '%s'", err)
+				}
+			case <-cancelCtx.Done():
+				return
+			}
+		}
+	})
+
+	return sensor, nil
+}
+
+func (imu *bno055) poll(ctx context.Context) error {
+	imu.busMu.Lock()
+	raw, err := imu.readBlock(ctx, accelDataRegister, 24)
+	if err != nil {
+		imu.busMu.Unlock()
+		return err
+	}
+	fused, err := imu.readBlock(ctx, quatDataRegister, 22)
+	imu.busMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	// 1 m/s^2 is 100 LSB, 1 microtesla is 16 LSB, 1 degree per second is 16 LSB, 1 degree is 16 LSB
+	imu.magneticField = toVector(raw[6:12], 16)
+	gyro := toVector(raw[12:18], 16)
+	imu.angularVelocity = spatialmath.AngularVelocity{X: gyro.X, Y: gyro.Y, Z: gyro.Z}
+	imu.heading = float64(utils.Int16FromBytesLE(raw[18:20])) / 16
+
+	// a unit quaternion is 2^14 LSB
+	imu.orientation = spatialmath.Normalize(quat.Number{
+		Real: float64(utils.Int16FromBytesLE(fused[0:2])),
+		Imag: float64(utils.Int16FromBytesLE(fused[2:4])),
+		Jmag: float64(utils.Int16FromBytesLE(fused[4:6])),
+		Kmag: float64(utils.Int16FromBytesLE(fused[6:8])),
+	})
+	imu.linearAcceleration = toVector(fused[8:14], 100)
+	imu.temperature = float64(int8(fused[20]))
+	imu.calibrationStatus = fused[21]
+	return nil
+}
+
+// toVector converts 3 little-endian int16s to a vector, given how many LSBs make a unit.
+func toVector(data []byte, lsbPerUnit float64) r3.Vector {
+	return r3.Vector{
+		X: float64(utils.Int16FromBytesLE(data[0:2])) / lsbPerUnit,
+		Y: float64(utils.Int16FromBytesLE(data[2:4])) / lsbPerUnit,
+		Z: float64(utils.Int16FromBytesLE(data[4:6])) / lsbPerUnit,
+	}
+}
+
+func (imu *bno055) setMode(ctx context.Context, mode byte) error {
+	if err := imu.writeByte(ctx, opModeRegister, mode); err != nil {
+		return err
+	}
+	if mode == opModeConfig {
+		time.Sleep(configModeSwitchTime)
+	} else {
+		time.Sleep(ndofModeSwitchTime)
+	}
+	return nil
+}
+
+func (imu *bno055) readBlock(ctx context.Context, register byte, length uint8) ([]byte, error) {
+	handle, err := imu.bus.OpenHandle(imu.i2cAddress)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := handle.Close()
+		if err != nil {
+			imu.logger.CError(ctx, err)
+		}
+	}()
+
+	results, err := handle.ReadBlockData(ctx, register, length)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != int(length) {
+		return nil, errors.Errorf("expected %d bytes from register %d, got %d", length, register, len(results))
+	}
+	return results, nil
+}
+
+func (imu *bno055) writeByte(ctx context.Context, register, value byte) error {
+	handle, err := imu.bus.OpenHandle(imu.i2cAddress)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := handle.Close()
+		if err != nil {
+			imu.logger.CError(ctx, err)
+		}
+	}()
+
+	return handle.WriteByteData(ctx, register, value)
+}
+
+func (imu *bno055) writeBlock(ctx context.Context, register byte, data []byte) error {
+	handle, err := imu.bus.OpenHandle(imu.i2cAddress)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := handle.Close()
+		if err != nil {
+			imu.logger.CError(ctx, err)
+		}
+	}()
+
+	return handle.WriteBlockData(ctx, register, data)
+}
+
+// loadCalibrationFile returns the saved calibration offsets, or nil if there are none.
+func (imu *bno055) loadCalibrationFile() ([]byte, error) {
+	if imu.calibrationFile == "" {
+		return nil, nil
+	}
+	//nolint:gosec
+	data, err := os.ReadFile(imu.calibrationFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var cal calibrationData
+	if err := json.Unmarshal(data, &cal); err != nil {
+		return nil, err
+	}
+	if len(cal.Offsets) != calibOffsetLength {
+		return nil, errors.Errorf("expected %d calibration offsets in %s, got %d",
+			calibOffsetLength, imu.calibrationFile, len(cal.Offsets))
+	}
+	offsets := make([]byte, len(cal.Offsets))
+	for i, v := range cal.Offsets {
+		offsets[i] = byte(v)
+	}
+	return offsets, nil
+}
+
+// readCalibration reads the calibration offsets off the chip, which is only possible in config mode.
+func (imu *bno055) readCalibration(ctx context.Context) ([]byte, error) {
+	imu.busMu.Lock()
+	defer imu.busMu.Unlock()
+	if err := imu.setMode(ctx, opModeConfig); err != nil {
+		return nil, err
+	}
+	offsets, readErr := imu.readBlock(ctx, calibOffsetRegister, calibOffsetLength)
+	if err := imu.setMode(ctx, opModeNDOF); err != nil {
+		return nil, err
+	}
+	return offsets, readErr
+}
+
+// writeCalibration writes calibration offsets onto the chip, which is only possible in config mode.
+func (imu *bno055) writeCalibration(ctx context.Context, offsets []byte) error {
+	imu.busMu.Lock()
+	defer imu.busMu.Unlock()
+	if err := imu.setMode(ctx, opModeConfig); err != nil {
+		return err
+	}
+	writeErr := imu.writeBlock(ctx, calibOffsetRegister, offsets)
+	if err := imu.setMode(ctx, opModeNDOF); err != nil {
+		return err
+	}
+	return writeErr
+}
+
+func (imu *bno055) calibrationStatusMap() map[string]interface{} {
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	status := imu.calibrationStatus
+	return map[string]interface{}{
+		"system":        int((status >> 6) & 0x03),
+		"gyroscope":     int((status >> 4) & 0x03),
+		"accelerometer": int((status >> 2) & 0x03),
+		"magnetometer":  int(status & 0x03),
+	}
+}
+
+// DoCommand supports "calibration_status", "save_calibration", and "load_calibration". "load_calibration"
+// takes the "offsets" returned by "save_calibration", or loads them from the calibration file if they are
+// omitted.
+func (imu *bno055) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case "calibration_status":
+		return imu.calibrationStatusMap(), nil
+	case "save_calibration":
+		offsets, err := imu.readCalibration(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cal := calibrationData{Offsets: make([]int, len(offsets))}
+		for i, b := range offsets {
+			cal.Offsets[i] = int(b)
+		}
+		if imu.calibrationFile != "" {
+			data, err := json.Marshal(cal)
+			if err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(imu.calibrationFile, data, 0o600); err != nil {
+				return nil, err
+			}
+		}
+		return map[string]interface{}{"offsets": cal.Offsets}, nil
+	case "load_calibration":
+		var offsets []byte
+		if raw, ok := cmd["offsets"].([]interface{}); ok {
+			for _, v := range raw {
+				f, ok := v.(float64)
+				if !ok || f < 0 || f > math.MaxUint8 {
+					return nil, errors.New("offsets must be a list of bytes")
+				}
+				offsets = append(offsets, byte(f))
+			}
+		} else {
+			var err error
+			offsets, err = imu.loadCalibrationFile()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(offsets) != calibOffsetLength {
+			return nil, errors.Errorf("expected %d calibration offsets, got %d", calibOffsetLength, len(offsets))
+		}
+		return map[string]interface{}{}, imu.writeCalibration(ctx, offsets)
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
+
+func (imu *bno055) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	return imu.angularVelocity, imu.err.Get()
+}
+
+func (imu *bno055) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	return r3.Vector{}, movementsensor.ErrMethodUnimplementedLinearVelocity
+}
+
+// LinearAcceleration returns the acceleration of the sensor with gravity removed.
+func (imu *bno055) LinearAcceleration(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	return imu.linearAcceleration, imu.err.Get()
+}
+
+func (imu *bno055) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	q := spatialmath.Quaternion(imu.orientation)
+	return &q, imu.err.Get()
+}
+
+func (imu *bno055) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	return imu.heading, imu.err.Get()
+}
+
+func (imu *bno055) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	return geo.NewPoint(0, 0), 0, movementsensor.ErrMethodUnimplementedPosition
+}
+
+func (imu *bno055) Accuracy(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error) {
+	return movementsensor.UnimplementedOptionalAccuracies(), nil
+}
+
+func (imu *bno055) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	readings, err := movementsensor.DefaultAPIReadings(ctx, imu, extra)
+	if err != nil {
+		return nil, err
+	}
+
+	imu.mu.Lock()
+	readings["magnetic_field_microtesla"] = imu.magneticField
+	readings["temperature_celsius"] = imu.temperature
+	imu.mu.Unlock()
+	readings["calibration_status"] = imu.calibrationStatusMap()
+	return readings, nil
+}
+
+func (imu *bno055) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	return &movementsensor.Properties{
+		AngularVelocitySupported:    true,
+		LinearAccelerationSupported: true,
+		OrientationSupported:        true,
+		CompassHeadingSupported:     true,
+	}, nil
+}
+
+func (imu *bno055) Close(ctx context.Context) error {
+	imu.workers.Stop()
+	return nil
+}
@@ -0,0 +1,166 @@
+//go:build linux
+
+package bno055
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// fakeChip is a BNO055 whose registers can be read and written over an injected I2C bus.
+type fakeChip struct {
+	mu        sync.Mutex
+	registers [0x80]byte
+}
+
+func (c *fakeChip) bus() buses.I2C {
+	handle := &inject.I2CHandle{}
+	handle.ReadBlockDataFunc = func(ctx context.Context, register byte, numBytes uint8) ([]byte, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return append([]byte{}, c.registers[register:int(register)+int(numBytes)]...), nil
+	}
+	handle.WriteByteDataFunc = func(ctx context.Context, register, data byte) error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.registers[register] = data
+		return nil
+	}
+	handle.WriteBlockDataFunc = func(ctx context.Context, register byte, data []byte) error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		copy(c.registers[register:], data)
+		return nil
+	}
+	handle.CloseFunc = func() error { return nil }
+	i2c := &inject.I2C{}
+	i2c.OpenHandleFunc = func(addr byte) (buses.I2CHandle, error) {
+		return handle, nil
+	}
+	return i2c
+}
+
+func (c *fakeChip) setInt16(register byte, value int16) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.registers[register] = byte(value)
+	c.registers[register+1] = byte(value >> 8)
+}
+
+func testConfig(calibrationFile string) resource.Config {
+	return resource.Config{
+		Name:                "movementsensor",
+		Model:               model,
+		API:                 movementsensor.API,
+		ConvertedAttributes: &Config{I2cBus: "1", CalibrationFile: calibrationFile},
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	cfg := Config{}
+	deps, err := cfg.Validate("path")
+	expectedErr := resource.NewConfigValidationFieldRequiredError("path", "i2c_bus")
+	test.That(t, err, test.ShouldBeError, expectedErr)
+	test.That(t, deps, test.ShouldBeEmpty)
+}
+
+func TestWrongChip(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	chip := &fakeChip{}
+	chip.registers[chipIDRegister] = 0x12
+	_, err := makeBNO055(context.Background(), resource.Dependencies{}, testConfig(""), logger, chip.bus())
+	test.That(t, err, test.ShouldBeError, unexpectedDeviceError(defaultAddress, 0x12))
+}
+
+func TestReadings(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	chip := &fakeChip{}
+	chip.registers[chipIDRegister] = expectedChipID
+	// rotated 90 degrees about Z, facing east
+	chip.setInt16(quatDataRegister, 11585)
+	chip.setInt16(quatDataRegister+6, 11585)
+	chip.setInt16(0x1A, 90*16)
+	chip.setInt16(0x14+4, 45*16)
+	chip.setInt16(0x28, 981)
+	chip.registers[0x35] = 0xFF
+
+	sensor, err := makeBNO055(ctx, resource.Dependencies{}, testConfig(""), logger, chip.bus())
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, sensor.Close(ctx), test.ShouldBeNil)
+	}()
+	// the chip was left in fusion mode
+	test.That(t, chip.registers[opModeRegister], test.ShouldEqual, byte(opModeNDOF))
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		heading, err := sensor.CompassHeading(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, heading, test.ShouldEqual, 90.)
+	})
+	o, err := sensor.Orientation(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, o.OrientationVectorDegrees().Theta, test.ShouldAlmostEqual, 90, 0.01)
+	av, err := sensor.AngularVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, av.Z, test.ShouldEqual, 45.)
+	accel, err := sensor.LinearAcceleration(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, accel.X, test.ShouldAlmostEqual, 9.81)
+
+	status, err := sensor.DoCommand(ctx, map[string]interface{}{"command": "calibration_status"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["system"], test.ShouldEqual, 3)
+	test.That(t, status["magnetometer"], test.ShouldEqual, 3)
+}
+
+func TestCalibrationPersistence(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	calibrationFile := filepath.Join(t.TempDir(), "bno055.json")
+
+	chip := &fakeChip{}
+	chip.registers[chipIDRegister] = expectedChipID
+	for i := 0; i < calibOffsetLength; i++ {
+		chip.registers[calibOffsetRegister+i] = byte(i + 1)
+	}
+	sensor, err := makeBNO055(ctx, resource.Dependencies{}, testConfig(calibrationFile), logger, chip.bus())
+	test.That(t, err, test.ShouldBeNil)
+	resp, err := sensor.DoCommand(ctx, map[string]interface{}{"command": "save_calibration"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(resp["offsets"].([]int)), test.ShouldEqual, calibOffsetLength)
+	test.That(t, sensor.Close(ctx), test.ShouldBeNil)
+
+	//nolint:gosec
+	data, err := os.ReadFile(calibrationFile)
+	test.That(t, err, test.ShouldBeNil)
+	var saved calibrationData
+	test.That(t, json.Unmarshal(data, &saved), test.ShouldBeNil)
+	test.That(t, saved.Offsets[0], test.ShouldEqual, 1)
+	test.That(t, saved.Offsets[calibOffsetLength-1], test.ShouldEqual, calibOffsetLength)
+
+	// a freshly powered chip gets the saved offsets back at startup
+	chip = &fakeChip{}
+	chip.registers[chipIDRegister] = expectedChipID
+	sensor, err = makeBNO055(ctx, resource.Dependencies{}, testConfig(calibrationFile), logger, chip.bus())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, sensor.Close(ctx), test.ShouldBeNil)
+	test.That(t, chip.registers[calibOffsetRegister], test.ShouldEqual, byte(1))
+	test.That(t, chip.registers[calibOffsetRegister+calibOffsetLength-1], test.ShouldEqual, byte(calibOffsetLength))
+
+	_, err = sensor.DoCommand(ctx, map[string]interface{}{"command": "load_calibration", "offsets": []interface{}{1.}})
+	test.That(t, err, test.ShouldNotBeNil)
+}
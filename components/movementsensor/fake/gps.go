@@ -0,0 +1,315 @@
+package fake
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+)
+
+var gpsModel = resource.DefaultModelFamily.WithModel("fake_gps")
+
+// A SimulatedBase is a base that knows where it has driven to, such as the fake base.
+type SimulatedBase interface {
+	base.Base
+	// Pose returns where the base is relative to where it started, with +Y forwards.
+	Pose() spatialmath.Pose
+}
+
+// GPSPathPoint is a point along the path a fake GPS travels.
+type GPSPathPoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	AltitudeM float64 `json:"altitude_m,omitempty"`
+}
+
+// GPSConfig is used for converting fake GPS attributes.
+type GPSConfig struct {
+	// Base is the simulated base the GPS is mounted on. Exactly one of Base and Path must be set.
+	Base string `json:"base,omitempty"`
+	// Path is a list of points the GPS travels along, starting when it's built, instead of
+	// following a base.
+	Path []GPSPathPoint `json:"path,omitempty"`
+	// SpeedMetersPerSec is how fast the GPS travels along Path. Defaults to 1 m/s.
+	SpeedMetersPerSec float64 `json:"speed_meters_per_sec,omitempty"`
+	// LoopPath makes the GPS head back to the start of Path once it reaches the end, rather than
+	// stopping there.
+	LoopPath bool `json:"loop_path,omitempty"`
+	// OriginLatitude and OriginLongitude are where the base starts.
+	OriginLatitude  float64 `json:"origin_latitude"`
+	OriginLongitude float64 `json:"origin_longitude"`
+	// OriginHeadingDeg is the compass heading the base starts out facing.
+	OriginHeadingDeg float64 `json:"origin_heading_deg,omitempty"`
+	// OriginAltitudeM is the altitude of the starting position, in meters.
+	OriginAltitudeM float64 `json:"origin_altitude_m,omitempty"`
+	// PositionNoiseMm is the standard deviation of the noise added to each horizontal position
+	// reading, in millimeters.
+	PositionNoiseMm float64 `json:"position_noise_mm,omitempty"`
+	// HeadingNoiseDeg is the standard deviation of the noise added to each compass heading.
+	HeadingNoiseDeg float64 `json:"heading_noise_deg,omitempty"`
+	// RandomSeed makes the noise repeatable. If omitted, the noise is different every run.
+	RandomSeed *int64 `json:"random_seed,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *GPSConfig) Validate(path string) ([]string, error) {
+	if cfg.Base == "" && len(cfg.Path) == 0 {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "base")
+	}
+	if cfg.Base != "" && len(cfg.Path) != 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("only one of base and path can be set"))
+	}
+	if cfg.SpeedMetersPerSec < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("speed_meters_per_sec cannot be negative"))
+	}
+	for i, pt := range cfg.Path {
+		if pt.Latitude < -90 || pt.Latitude > 90 || pt.Longitude < -180 || pt.Longitude > 180 {
+			return nil, resource.NewConfigValidationError(path, errors.Errorf("path point %d is not a valid latitude and longitude", i))
+		}
+	}
+	if cfg.OriginLatitude < -90 || cfg.OriginLatitude > 90 {
+		return nil, resource.NewConfigValidationError(path, errors.New("origin_latitude must be between -90 and 90"))
+	}
+	if cfg.OriginLongitude < -180 || cfg.OriginLongitude > 180 {
+		return nil, resource.NewConfigValidationError(path, errors.New("origin_longitude must be between -180 and 180"))
+	}
+	if cfg.PositionNoiseMm < 0 || cfg.HeadingNoiseDeg < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("noise cannot be negative"))
+	}
+	if cfg.Base == "" {
+		return nil, nil
+	}
+	return []string{cfg.Base}, nil
+}
+
+func init() {
+	resource.RegisterComponent(
+		movementsensor.API,
+		gpsModel,
+		resource.Registration[movementsensor.MovementSensor, *GPSConfig]{Constructor: NewGPS})
+}
+
+// NewGPS makes a new fake GPS, which either reports the position of a simulated base as if the
+// base started out at the configured origin, or travels along the configured path.
+func NewGPS(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger,
+) (movementsensor.MovementSensor, error) {
+	newConf, err := resource.NativeConfig[*GPSConfig](conf)
+	if err != nil {
+		return nil, err
+	}
+	var simBase SimulatedBase
+	var path *gpsPath
+	if len(newConf.Path) > 0 {
+		path = newGPSPath(newConf.Path, newConf.SpeedMetersPerSec, newConf.LoopPath)
+	} else {
+		b, err := base.FromDependencies(deps, newConf.Base)
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		simBase, ok = b.(SimulatedBase)
+		if !ok {
+			return nil, errors.Errorf("base %q is not simulated, so a fake GPS cannot follow it", newConf.Base)
+		}
+	}
+
+	seed := rand.Int63() //nolint:gosec
+	if newConf.RandomSeed != nil {
+		seed = *newConf.RandomSeed
+	}
+	return &GPS{
+		Named: conf.ResourceName().AsNamed(),
+		base:  simBase,
+		path:  path,
+		start: time.Now(),
+		origin: spatialmath.NewGeoPoseWithAltitude(
+			geo.NewPoint(newConf.OriginLatitude, newConf.OriginLongitude),
+			newConf.OriginAltitudeM,
+			newConf.OriginHeadingDeg,
+		),
+		positionNoiseMm: newConf.PositionNoiseMm,
+		headingNoiseDeg: newConf.HeadingNoiseDeg,
+		rand:            rand.New(rand.NewSource(seed)), //nolint:gosec
+		logger:          logger,
+	}, nil
+}
+
+// GPS is a fake GPS that follows a simulated base around, or travels along a fixed path, so that
+// code which navigates by GPS can be tested without going outside.
+type GPS struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+
+	base            SimulatedBase
+	path            *gpsPath
+	start           time.Time
+	origin          *spatialmath.GeoPose
+	positionNoiseMm float64
+	headingNoiseDeg float64
+	logger          logging.Logger
+
+	randMu sync.Mutex
+	rand   *rand.Rand
+}
+
+func (g *GPS) noise(stddev float64) float64 {
+	if stddev == 0 {
+		return 0
+	}
+	g.randMu.Lock()
+	defer g.randMu.Unlock()
+	return g.rand.NormFloat64() * stddev
+}
+
+// geoPose returns where the base, or the GPS along its path, currently is in the world, with noise added.
+func (g *GPS) geoPose() *spatialmath.GeoPose {
+	noise := r3.Vector{X: g.noise(g.positionNoiseMm), Y: g.noise(g.positionNoiseMm)}
+	if g.path != nil {
+		return spatialmath.PoseToGeoPose(g.path.geoPoseAt(time.Since(g.start)), spatialmath.NewPoseFromPoint(noise))
+	}
+	pose := g.base.Pose()
+	return spatialmath.PoseToGeoPose(g.origin, spatialmath.NewPose(pose.Point().Add(noise), pose.Orientation()))
+}
+
+// Position returns the current location of the simulated base.
+func (g *GPS) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	gp := g.geoPose()
+	return gp.Location(), gp.Altitude(), nil
+}
+
+// CompassHeading returns the heading of the simulated base.
+func (g *GPS) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	heading := g.geoPose().Heading() + g.noise(g.headingNoiseDeg)
+	heading = math.Mod(heading, 360)
+	if heading < 0 {
+		heading += 360
+	}
+	return heading, nil
+}
+
+// LinearVelocity is not supported.
+func (g *GPS) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	return r3.Vector{}, movementsensor.ErrMethodUnimplementedLinearVelocity
+}
+
+// LinearAcceleration is not supported.
+func (g *GPS) LinearAcceleration(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	return r3.Vector{}, movementsensor.ErrMethodUnimplementedLinearAcceleration
+}
+
+// AngularVelocity is not supported.
+func (g *GPS) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+	return spatialmath.AngularVelocity{}, movementsensor.ErrMethodUnimplementedAngularVelocity
+}
+
+// Orientation is not supported.
+func (g *GPS) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	return nil, movementsensor.ErrMethodUnimplementedOrientation
+}
+
+// Accuracy reports a fix quality and accuracy consistent with the configured noise.
+func (g *GPS) Accuracy(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error) {
+	// With no noise, pretend to be an RTK fix; otherwise a plain GPS fix.
+	fix := int32(4)
+	if g.positionNoiseMm > 0 {
+		fix = 1
+	}
+	return &movementsensor.Accuracy{
+		AccuracyMap:        map[string]float32{"position_noise_mm": float32(g.positionNoiseMm)},
+		Hdop:               float32(g.positionNoiseMm / 1000),
+		NmeaFix:            fix,
+		CompassDegreeError: float32(g.headingNoiseDeg),
+	}, nil
+}
+
+// Readings gets the readings of a fake GPS.
+func (g *GPS) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return movementsensor.DefaultAPIReadings(ctx, g, extra)
+}
+
+// Properties returns the properties of a fake GPS.
+func (g *GPS) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	return &movementsensor.Properties{
+		PositionSupported:       true,
+		CompassHeadingSupported: true,
+	}, nil
+}
+
+// gpsPath is a path of points that a fake GPS travels along at a constant speed.
+type gpsPath struct {
+	points    []*geo.Point
+	altitudes []float64
+	// distancesKm are how far along the path each point is.
+	distancesKm   []float64
+	speedKmPerSec float64
+	loop          bool
+}
+
+func newGPSPath(points []GPSPathPoint, speedMetersPerSec float64, loop bool) *gpsPath {
+	if speedMetersPerSec == 0 {
+		speedMetersPerSec = 1
+	}
+	if loop {
+		points = append(append([]GPSPathPoint(nil), points...), points[0])
+	}
+	path := &gpsPath{speedKmPerSec: speedMetersPerSec / 1000, loop: loop}
+	for i, pt := range points {
+		point := geo.NewPoint(pt.Latitude, pt.Longitude)
+		distance := 0.
+		if i > 0 {
+			distance = path.distancesKm[i-1] + path.points[i-1].GreatCircleDistance(point)
+		}
+		path.points = append(path.points, point)
+		path.altitudes = append(path.altitudes, pt.AltitudeM)
+		path.distancesKm = append(path.distancesKm, distance)
+	}
+	return path
+}
+
+// geoPoseAt returns where along the path the GPS is after travelling for the given time, facing
+// the way it's travelling.
+func (p *gpsPath) geoPoseAt(elapsed time.Duration) *spatialmath.GeoPose {
+	last := len(p.points) - 1
+	if last == 0 {
+		return spatialmath.NewGeoPoseWithAltitude(p.points[0], p.altitudes[0], 0)
+	}
+	travelled := p.speedKmPerSec * elapsed.Seconds()
+	total := p.distancesKm[last]
+	switch {
+	case p.loop && total > 0:
+		travelled = math.Mod(travelled, total)
+	case travelled > total:
+		travelled = total
+	}
+
+	// find the segment the GPS is on
+	i := 0
+	for i < last-1 && travelled > p.distancesKm[i+1] {
+		i++
+	}
+	from, to := p.points[i], p.points[i+1]
+	bearing := from.BearingTo(to)
+	segment := p.distancesKm[i+1] - p.distancesKm[i]
+	fraction := 1.
+	if segment > 0 {
+		fraction = (travelled - p.distancesKm[i]) / segment
+	}
+	heading := math.Mod(bearing+360, 360)
+	return spatialmath.NewGeoPoseWithAltitude(
+		from.PointAtDistanceAndBearing(travelled-p.distancesKm[i], bearing),
+		p.altitudes[i]+fraction*(p.altitudes[i+1]-p.altitudes[i]),
+		heading,
+	)
+}
@@ -0,0 +1,145 @@
+package fake
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/base"
+	fakebase "go.viam.com/rdk/components/base/fake"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+func TestFakeGPS(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	b, err := fakebase.NewBase(ctx, nil, resource.Config{Name: "base", API: base.API}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	deps := resource.Dependencies{base.Named("base"): b}
+
+	conf := &GPSConfig{
+		Base:             "base",
+		OriginLatitude:   40.7,
+		OriginLongitude:  -73.98,
+		OriginHeadingDeg: 90,
+		OriginAltitudeM:  10,
+	}
+	deps2, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps2, test.ShouldResemble, []string{"base"})
+
+	gps, err := NewGPS(ctx, deps, resource.Config{Name: "gps", ConvertedAttributes: conf}, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	pt, alt, err := gps.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pt.Lat(), test.ShouldAlmostEqual, 40.7)
+	test.That(t, pt.Lng(), test.ShouldAlmostEqual, -73.98)
+	test.That(t, alt, test.ShouldAlmostEqual, 10)
+	heading, err := gps.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, heading, test.ShouldAlmostEqual, 90)
+
+	// The base starts out facing east, so driving forwards moves it east.
+	test.That(t, b.MoveStraight(ctx, 10000, 100, nil), test.ShouldBeNil)
+	pt, _, err = gps.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pt.Lat(), test.ShouldAlmostEqual, 40.7, 1e-6)
+	test.That(t, pt.Lng(), test.ShouldBeGreaterThan, -73.98)
+	test.That(t, pt.GreatCircleDistance(geo.NewPoint(40.7, -73.98))*1e6, test.ShouldAlmostEqual, 10000, 10)
+
+	// Turning left leaves it facing north.
+	test.That(t, b.Spin(ctx, 90, 45, nil), test.ShouldBeNil)
+	heading, err = gps.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, math.Min(heading, 360-heading), test.ShouldAlmostEqual, 0, 1e-6)
+
+	conf.Base = ""
+	_, err = conf.Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "base")
+}
+
+func TestFakeGPSNoise(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	b, err := fakebase.NewBase(ctx, nil, resource.Config{Name: "base", API: base.API}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	deps := resource.Dependencies{base.Named("base"): b}
+
+	seed := int64(1)
+	conf := &GPSConfig{Base: "base", PositionNoiseMm: 500, RandomSeed: &seed}
+	gps1, err := NewGPS(ctx, deps, resource.Config{Name: "gps1", ConvertedAttributes: conf}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	gps2, err := NewGPS(ctx, deps, resource.Config{Name: "gps2", ConvertedAttributes: conf}, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	// The same seed gives the same noise, and the noise moves the reading off the origin.
+	pt1, _, err := gps1.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	pt2, _, err := gps2.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pt1, test.ShouldResemble, pt2)
+	test.That(t, pt1.Lat() != 0 || pt1.Lng() != 0, test.ShouldBeTrue)
+
+	acc, err := gps1.Accuracy(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, acc.NmeaFix, test.ShouldEqual, 1)
+}
+
+func TestFakeGPSPath(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	// Two points about 111 m apart due north, then back west.
+	conf := &GPSConfig{
+		Path: []GPSPathPoint{
+			{Latitude: 40, Longitude: -74, AltitudeM: 10},
+			{Latitude: 40.001, Longitude: -74, AltitudeM: 20},
+			{Latitude: 40.001, Longitude: -74.001, AltitudeM: 20},
+		},
+		SpeedMetersPerSec: 10,
+	}
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldBeEmpty)
+
+	gps, err := NewGPS(ctx, nil, resource.Config{Name: "gps", ConvertedAttributes: conf}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	pt, _, err := gps.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pt.Lat(), test.ShouldAlmostEqual, 40, 1e-4)
+
+	path := newGPSPath(conf.Path, conf.SpeedMetersPerSec, false)
+	halfway := time.Duration(path.distancesKm[1] / 2 / path.speedKmPerSec * float64(time.Second))
+	gp := path.geoPoseAt(halfway)
+	test.That(t, gp.Location().Lat(), test.ShouldAlmostEqual, 40.0005, 1e-5)
+	test.That(t, gp.Altitude(), test.ShouldAlmostEqual, 15, 0.5)
+	test.That(t, gp.Heading(), test.ShouldAlmostEqual, 0, 1e-6)
+
+	// Heading west along the second segment.
+	gp = path.geoPoseAt(halfway*3 - time.Second)
+	test.That(t, gp.Heading(), test.ShouldAlmostEqual, 270, 0.1)
+
+	// Without looping the GPS stops at the end of the path.
+	gp = path.geoPoseAt(time.Hour)
+	test.That(t, gp.Location().Lat(), test.ShouldAlmostEqual, 40.001, 1e-6)
+	test.That(t, gp.Location().Lng(), test.ShouldAlmostEqual, -74.001, 1e-6)
+
+	// Looping brings it back to the start.
+	loop := newGPSPath(conf.Path, conf.SpeedMetersPerSec, true)
+	total := time.Duration(loop.distancesKm[len(loop.distancesKm)-1] / loop.speedKmPerSec * float64(time.Second))
+	gp = loop.geoPoseAt(total + time.Millisecond)
+	test.That(t, gp.Location().Lat(), test.ShouldAlmostEqual, 40, 1e-5)
+	test.That(t, gp.Location().Lng(), test.ShouldAlmostEqual, -74, 1e-5)
+
+	conf.Base = "base"
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
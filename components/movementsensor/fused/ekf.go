@@ -0,0 +1,212 @@
+package fused
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// The indices of the filter's state.
+const (
+	stateEast = iota
+	stateNorth
+	stateVelEast
+	stateVelNorth
+	stateHeading // radians clockwise from north
+	stateYawRate // radians per second clockwise
+	stateSize
+)
+
+// ekf is an extended Kalman filter tracking a vehicle in a plane. It assumes the vehicle keeps a constant velocity
+// and yaw rate, disturbed by random accelerations. The model is linear, but measurements of the velocity in the
+// vehicle's own frame (as from wheel odometry) depend on the heading, which makes the filter an extended one.
+type ekf struct {
+	state *mat.VecDense
+	cov   *mat.Dense
+
+	// standard deviations of the random accelerations, in m/s^2 and rad/s^2
+	accelNoise    float64
+	yawAccelNoise float64
+}
+
+func newEKF(accelNoise, yawAccelNoise float64) *ekf {
+	// Until we measure anything, we know nothing.
+	cov := mat.NewDiagDense(stateSize, []float64{1e6, 1e6, 100, 100, math.Pi * math.Pi, 1})
+	return &ekf{
+		state:         mat.NewVecDense(stateSize, nil),
+		cov:           mat.DenseCopyOf(cov),
+		accelNoise:    accelNoise,
+		yawAccelNoise: yawAccelNoise,
+	}
+}
+
+// predict advances the filter by dt seconds.
+func (f *ekf) predict(dt float64) {
+	if dt <= 0 {
+		return
+	}
+	transition := mat.NewDense(stateSize, stateSize, nil)
+	for i := 0; i < stateSize; i++ {
+		transition.Set(i, i, 1)
+	}
+	transition.Set(stateEast, stateVelEast, dt)
+	transition.Set(stateNorth, stateVelNorth, dt)
+	transition.Set(stateHeading, stateYawRate, dt)
+
+	var state mat.VecDense
+	state.MulVec(transition, f.state)
+	f.state = &state
+	f.state.SetVec(stateHeading, wrapAngle(f.state.AtVec(stateHeading)))
+
+	// each position or angle and its rate are disturbed together by a random acceleration held over dt
+	noise := mat.NewDense(stateSize, stateSize, nil)
+	for _, pair := range []struct {
+		value, rate int
+		stdDev      float64
+	}{
+		{stateEast, stateVelEast, f.accelNoise},
+		{stateNorth, stateVelNorth, f.accelNoise},
+		{stateHeading, stateYawRate, f.yawAccelNoise},
+	} {
+		variance := pair.stdDev * pair.stdDev
+		noise.Set(pair.value, pair.value, variance*math.Pow(dt, 4)/4)
+		noise.Set(pair.value, pair.rate, variance*math.Pow(dt, 3)/2)
+		noise.Set(pair.rate, pair.value, variance*math.Pow(dt, 3)/2)
+		noise.Set(pair.rate, pair.rate, variance*dt*dt)
+	}
+
+	var cov mat.Dense
+	cov.Product(transition, f.cov, transition.T())
+	cov.Add(&cov, noise)
+	f.cov = &cov
+}
+
+// update corrects the filter with the measurement z, whose value predicted from the current state is predicted,
+// and whose derivatives with respect to the state are the rows of jacobian. stdDevs are the standard deviations
+// of the measurement's components, which are independent. Any components listed in angles are angles in
+// radians, and are compared the short way around the circle.
+func (f *ekf) update(z, predicted []float64, jacobian *mat.Dense, stdDevs []float64, angles ...int) error {
+	n := len(z)
+	innovation := mat.NewVecDense(n, nil)
+	for i := range z {
+		innovation.SetVec(i, z[i]-predicted[i])
+	}
+	for _, i := range angles {
+		innovation.SetVec(i, wrapAngle(innovation.AtVec(i)))
+	}
+	noise := mat.NewDense(n, n, nil)
+	for i, stdDev := range stdDevs {
+		noise.Set(i, i, stdDev*stdDev)
+	}
+
+	// S = H P H^T + R, K = P H^T S^-1
+	var pht, s, sInv, gain mat.Dense
+	pht.Mul(f.cov, jacobian.T())
+	s.Mul(jacobian, &pht)
+	s.Add(&s, noise)
+	if err := sInv.Inverse(&s); err != nil {
+		return err
+	}
+	gain.Mul(&pht, &sInv)
+
+	var correction mat.VecDense
+	correction.MulVec(&gain, innovation)
+	f.state.AddVec(f.state, &correction)
+	f.state.SetVec(stateHeading, wrapAngle(f.state.AtVec(stateHeading)))
+
+	// P = (I - K H) P
+	var kh, cov mat.Dense
+	kh.Mul(&gain, jacobian)
+	for i := 0; i < stateSize; i++ {
+		kh.Set(i, i, kh.At(i, i)-1)
+	}
+	kh.Scale(-1, &kh)
+	cov.Mul(&kh, f.cov)
+	// keep the covariance symmetric in the face of rounding
+	var sym mat.Dense
+	sym.Add(&cov, cov.T())
+	sym.Scale(0.5, &sym)
+	f.cov = &sym
+	return nil
+}
+
+// selector returns a jacobian which picks the given components out of the state.
+func selector(indices ...int) *mat.Dense {
+	jacobian := mat.NewDense(len(indices), stateSize, nil)
+	for row, i := range indices {
+		jacobian.Set(row, i, 1)
+	}
+	return jacobian
+}
+
+// updatePosition corrects the filter with a position in meters east and north of the origin.
+func (f *ekf) updatePosition(east, north, stdDev float64) error {
+	return f.update(
+		[]float64{east, north},
+		[]float64{f.state.AtVec(stateEast), f.state.AtVec(stateNorth)},
+		selector(stateEast, stateNorth),
+		[]float64{stdDev, stdDev},
+	)
+}
+
+// updateWorldVelocity corrects the filter with a velocity in meters per second east and north.
+func (f *ekf) updateWorldVelocity(east, north, stdDev float64) error {
+	return f.update(
+		[]float64{east, north},
+		[]float64{f.state.AtVec(stateVelEast), f.state.AtVec(stateVelNorth)},
+		selector(stateVelEast, stateVelNorth),
+		[]float64{stdDev, stdDev},
+	)
+}
+
+// updateBodyVelocity corrects the filter with a velocity in meters per second to the vehicle's right and forward.
+func (f *ekf) updateBodyVelocity(right, forward, stdDev float64) error {
+	heading := f.state.AtVec(stateHeading)
+	vEast, vNorth := f.state.AtVec(stateVelEast), f.state.AtVec(stateVelNorth)
+	sin, cos := math.Sincos(heading)
+
+	jacobian := mat.NewDense(2, stateSize, nil)
+	jacobian.Set(0, stateVelEast, cos)
+	jacobian.Set(0, stateVelNorth, -sin)
+	jacobian.Set(0, stateHeading, -vEast*sin-vNorth*cos)
+	jacobian.Set(1, stateVelEast, sin)
+	jacobian.Set(1, stateVelNorth, cos)
+	jacobian.Set(1, stateHeading, vEast*cos-vNorth*sin)
+	return f.update(
+		[]float64{right, forward},
+		[]float64{vEast*cos - vNorth*sin, vEast*sin + vNorth*cos},
+		jacobian,
+		[]float64{stdDev, stdDev},
+	)
+}
+
+// updateHeading corrects the filter with a heading in radians clockwise from north.
+func (f *ekf) updateHeading(heading, stdDev float64) error {
+	return f.update(
+		[]float64{heading},
+		[]float64{f.state.AtVec(stateHeading)},
+		selector(stateHeading),
+		[]float64{stdDev},
+		0,
+	)
+}
+
+// updateYawRate corrects the filter with a yaw rate in radians per second clockwise.
+func (f *ekf) updateYawRate(yawRate, stdDev float64) error {
+	return f.update(
+		[]float64{yawRate},
+		[]float64{f.state.AtVec(stateYawRate)},
+		selector(stateYawRate),
+		[]float64{stdDev},
+	)
+}
+
+// stdDev returns the standard deviation of a component of the state.
+func (f *ekf) stdDev(i int) float64 {
+	return math.Sqrt(f.cov.At(i, i))
+}
+
+// wrapAngle returns the angle in radians equivalent to a, between -pi and pi.
+func wrapAngle(a float64) float64 {
+	return math.Remainder(a, 2*math.Pi)
+}
@@ -0,0 +1,477 @@
+// Package fused implements a movementsensor which fuses the measurements of other movement sensors, such as GPS,
+// IMUs, and wheel odometry, with an extended Kalman filter. Unlike the merged model, which passes each method
+// through to a single sensor, it produces smoothed estimates of position, velocity, and heading from all of them,
+// and keeps dead reckoning through GPS dropouts.
+//
+// The filter works in a plane tangent to the earth at the first position it is given, and tracks the position,
+// the velocity, the compass heading, and the rate at which the heading is changing. Altitude is passed through from
+// the latest position measurement.
+package fused
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("fused")
+
+// The measurements a source can contribute.
+const (
+	measurementPosition        = "position"
+	measurementLinearVelocity  = "linear_velocity"
+	measurementCompassHeading  = "compass_heading"
+	measurementAngularVelocity = "angular_velocity"
+)
+
+// The frames a source's linear velocity can be in.
+const (
+	velocityFrameBody  = "body"
+	velocityFrameWorld = "world"
+)
+
+const (
+	defaultUpdateRateHz              = 20
+	defaultAccelerationNoise         = 1   // m/s^2
+	defaultYawAccelerationNoise      = 30  // degrees/s^2
+	defaultPositionStdDevM           = 2.5 // a typical GPS without corrections
+	defaultLinearVelocityStdDevMPS   = 0.2
+	defaultCompassHeadingStdDevDegs  = 5
+	defaultAngularVelocityStdDevDegs = 2
+	earthRadiusM                     = 6371e3
+)
+
+var allMeasurements = []string{
+	measurementPosition, measurementLinearVelocity, measurementCompassHeading, measurementAngularVelocity,
+}
+
+// SourceConfig configures one of the movement sensors being fused.
+type SourceConfig struct {
+	Name string `json:"name"`
+	// Measurements restricts which of position, linear_velocity, compass_heading, and angular_velocity are used
+	// from this sensor. By default, everything it supports is used.
+	Measurements []string `json:"measurements,omitempty"`
+	// LinearVelocityFrame is "body" if the sensor's linear velocity is X to the right and Y forward, as with wheel
+	// odometry, or "world" if it is X east and Y north, as with GPS. The default is "body".
+	LinearVelocityFrame string `json:"linear_velocity_frame,omitempty"`
+
+	// The standard deviations of each kind of measurement from this sensor. The smaller these are, the more the
+	// sensor is trusted.
+	PositionStdDevM           float64 `json:"position_std_dev_m,omitempty"`
+	LinearVelocityStdDevMPS   float64 `json:"linear_velocity_std_dev_mps,omitempty"`
+	CompassHeadingStdDevDegs  float64 `json:"compass_heading_std_dev_degs,omitempty"`
+	AngularVelocityStdDevDegs float64 `json:"angular_velocity_std_dev_degs_per_sec,omitempty"`
+}
+
+// Config is the config of the fused movement_sensor model.
+type Config struct {
+	Sources      []SourceConfig `json:"sources"`
+	UpdateRateHz float64        `json:"update_rate_hz,omitempty"`
+	// The standard deviations of the random accelerations which the filter assumes disturb the motion. Larger values
+	// make the estimates follow the measurements more quickly, and smaller ones make them smoother.
+	AccelerationNoiseMPSS    float64 `json:"acceleration_noise_mpss,omitempty"`
+	YawAccelerationNoiseDegs float64 `json:"yaw_acceleration_noise_degs_per_sec_per_sec,omitempty"`
+}
+
+// Validate validates the fused model's configuration.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	if len(cfg.Sources) == 0 {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "sources")
+	}
+	if cfg.UpdateRateHz < 0 || cfg.AccelerationNoiseMPSS < 0 || cfg.YawAccelerationNoiseDegs < 0 {
+		return nil, resource.NewConfigValidationError(path,
+			errors.New("update_rate_hz, acceleration_noise_mpss, and yaw_acceleration_noise_degs_per_sec_per_sec cannot be negative"))
+	}
+
+	var deps []string
+	for i, source := range cfg.Sources {
+		sourcePath := fmt.Sprintf("%s.sources.%d", path, i)
+		if source.Name == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(sourcePath, "name")
+		}
+		for _, m := range source.Measurements {
+			if !slices.Contains(allMeasurements, m) {
+				return nil, resource.NewConfigValidationError(sourcePath,
+					fmt.Errorf("unknown measurement %q, must be one of %v", m, allMeasurements))
+			}
+		}
+		switch source.LinearVelocityFrame {
+		case "", velocityFrameBody, velocityFrameWorld:
+		default:
+			return nil, resource.NewConfigValidationError(sourcePath,
+				fmt.Errorf("linear_velocity_frame must be %q or %q", velocityFrameBody, velocityFrameWorld))
+		}
+		if source.PositionStdDevM < 0 || source.LinearVelocityStdDevMPS < 0 ||
+			source.CompassHeadingStdDevDegs < 0 || source.AngularVelocityStdDevDegs < 0 {
+			return nil, resource.NewConfigValidationError(sourcePath, errors.New("standard deviations cannot be negative"))
+		}
+		deps = append(deps, source.Name)
+	}
+	return deps, nil
+}
+
+func init() {
+	resource.RegisterComponent(
+		movementsensor.API, model,
+		resource.Registration[movementsensor.MovementSensor, *Config]{
+			Constructor: newFused,
+		})
+}
+
+// source is a movement sensor being fused, and which of its measurements to use.
+type source struct {
+	ms                   movementsensor.MovementSensor
+	conf                 SourceConfig
+	useMeasurement       map[string]bool
+	lastPosition         *geo.Point
+	positionStdDev       float64
+	linearVelocityStdDev float64
+	compassStdDev        float64 // radians
+	angularVelStdDev     float64 // radians per second
+}
+
+type fused struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	sources []*source
+	period  time.Duration
+
+	mu         sync.Mutex
+	filter     *ekf
+	origin     *geo.Point
+	altitude   float64
+	lastUpdate time.Time
+
+	workers utils.StoppableWorkers
+	logger  logging.Logger
+}
+
+func newFused(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (movementsensor.MovementSensor, error) {
+	f, err := makeFused(ctx, deps, conf, logger)
+	if err != nil {
+		return nil, err
+	}
+	f.workers = utils.NewStoppableWorkers(func(ctx context.Context) {
+		ticker := time.NewTicker(f.period)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				f.step(ctx, now)
+			}
+		}
+	})
+	return f, nil
+}
+
+// makeFused builds the sensor without starting to poll its sources, so that tests can step it by hand.
+func makeFused(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (*fused, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := newConf.UpdateRateHz
+	if rate == 0 {
+		rate = defaultUpdateRateHz
+	}
+	accelNoise := newConf.AccelerationNoiseMPSS
+	if accelNoise == 0 {
+		accelNoise = defaultAccelerationNoise
+	}
+	yawAccelNoise := newConf.YawAccelerationNoiseDegs
+	if yawAccelNoise == 0 {
+		yawAccelNoise = defaultYawAccelerationNoise
+	}
+
+	f := &fused{
+		Named:    conf.ResourceName().AsNamed(),
+		period:   time.Duration(float64(time.Second) / rate),
+		filter:   newEKF(accelNoise, utils.DegToRad(yawAccelNoise)),
+		altitude: math.NaN(),
+		logger:   logger,
+	}
+
+	for _, sc := range newConf.Sources {
+		ms, err := movementsensor.FromDependencies(deps, sc.Name)
+		if err != nil {
+			return nil, err
+		}
+		props, err := ms.Properties(ctx, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not get properties of %s", sc.Name)
+		}
+		supported := map[string]bool{
+			measurementPosition:        props.PositionSupported,
+			measurementLinearVelocity:  props.LinearVelocitySupported,
+			measurementCompassHeading:  props.CompassHeadingSupported,
+			measurementAngularVelocity: props.AngularVelocitySupported,
+		}
+		wanted := sc.Measurements
+		if len(wanted) == 0 {
+			wanted = allMeasurements
+		}
+		src := &source{
+			ms:                   ms,
+			conf:                 sc,
+			useMeasurement:       map[string]bool{},
+			positionStdDev:       orDefault(sc.PositionStdDevM, defaultPositionStdDevM),
+			linearVelocityStdDev: orDefault(sc.LinearVelocityStdDevMPS, defaultLinearVelocityStdDevMPS),
+			compassStdDev:        utils.DegToRad(orDefault(sc.CompassHeadingStdDevDegs, defaultCompassHeadingStdDevDegs)),
+			angularVelStdDev:     utils.DegToRad(orDefault(sc.AngularVelocityStdDevDegs, defaultAngularVelocityStdDevDegs)),
+		}
+		for _, m := range wanted {
+			if supported[m] {
+				src.useMeasurement[m] = true
+			} else if len(sc.Measurements) != 0 {
+				return nil, fmt.Errorf("movement sensor %s does not support %s", sc.Name, m)
+			}
+		}
+		if len(src.useMeasurement) == 0 {
+			return nil, fmt.Errorf("movement sensor %s supports none of %v", sc.Name, allMeasurements)
+		}
+		logger.CDebugf(ctx, "fusing %v from %s", measurementNames(src.useMeasurement), sc.Name)
+		f.sources = append(f.sources, src)
+	}
+	return f, nil
+}
+
+func orDefault(value, def float64) float64 {
+	if value == 0 {
+		return def
+	}
+	return value
+}
+
+func measurementNames(set map[string]bool) []string {
+	var keys []string
+	for _, m := range allMeasurements {
+		if set[m] {
+			keys = append(keys, m)
+		}
+	}
+	return keys
+}
+
+// step reads every source and advances the filter to now.
+func (f *fused) step(ctx context.Context, now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.lastUpdate.IsZero() {
+		f.filter.predict(now.Sub(f.lastUpdate).Seconds())
+	}
+	f.lastUpdate = now
+
+	for _, src := range f.sources {
+		if err := f.updateFromSource(ctx, src); err != nil {
+			// a source dropping out is exactly what the filter is for, so this is not worth more than debugging
+			f.logger.CDebugw(ctx, "skipping measurement", "source", src.conf.Name, "error", err)
+		}
+	}
+}
+
+func (f *fused) updateFromSource(ctx context.Context, src *source) error {
+	if src.useMeasurement[measurementPosition] {
+		pos, alt, err := src.ms.Position(ctx, nil)
+		if err != nil {
+			return err
+		}
+		// A GPS reports the same fix many times between updates; using them all would make us far too sure of it.
+		if pos != nil && !movementsensor.IsPositionNaN(pos) && !movementsensor.IsZeroPosition(pos) &&
+			(src.lastPosition == nil || *pos != *src.lastPosition) {
+			src.lastPosition = pos
+			if f.origin == nil {
+				f.origin = pos
+			}
+			east, north := f.toLocal(pos)
+			if err := f.filter.updatePosition(east, north, src.positionStdDev); err != nil {
+				return err
+			}
+			f.altitude = alt
+		}
+	}
+
+	if src.useMeasurement[measurementCompassHeading] {
+		heading, err := src.ms.CompassHeading(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if !math.IsNaN(heading) {
+			if err := f.filter.updateHeading(utils.DegToRad(heading), src.compassStdDev); err != nil {
+				return err
+			}
+		}
+	}
+
+	if src.useMeasurement[measurementAngularVelocity] {
+		angVel, err := src.ms.AngularVelocity(ctx, nil)
+		if err != nil {
+			return err
+		}
+		// angular velocity is counterclockwise about Z, while the heading goes clockwise
+		if !math.IsNaN(angVel.Z) {
+			if err := f.filter.updateYawRate(-utils.DegToRad(angVel.Z), src.angularVelStdDev); err != nil {
+				return err
+			}
+		}
+	}
+
+	if src.useMeasurement[measurementLinearVelocity] {
+		vel, err := src.ms.LinearVelocity(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if !math.IsNaN(vel.X) && !math.IsNaN(vel.Y) {
+			if src.conf.LinearVelocityFrame == velocityFrameWorld {
+				return f.filter.updateWorldVelocity(vel.X, vel.Y, src.linearVelocityStdDev)
+			}
+			return f.filter.updateBodyVelocity(vel.X, vel.Y, src.linearVelocityStdDev)
+		}
+	}
+	return nil
+}
+
+// toLocal returns how many meters east and north of the origin pos is.
+func (f *fused) toLocal(pos *geo.Point) (float64, float64) {
+	north := utils.DegToRad(pos.Lat()-f.origin.Lat()) * earthRadiusM
+	east := utils.DegToRad(pos.Lng()-f.origin.Lng()) * earthRadiusM * math.Cos(utils.DegToRad(f.origin.Lat()))
+	return east, north
+}
+
+// toGlobal is the inverse of toLocal.
+func (f *fused) toGlobal(east, north float64) *geo.Point {
+	lat := f.origin.Lat() + utils.RadToDeg(north/earthRadiusM)
+	lng := f.origin.Lng() + utils.RadToDeg(east/(earthRadiusM*math.Cos(utils.DegToRad(f.origin.Lat()))))
+	return geo.NewPoint(lat, lng)
+}
+
+func (f *fused) using(measurement string) bool {
+	for _, src := range f.sources {
+		if src.useMeasurement[measurement] {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fused) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	if !f.using(measurementPosition) {
+		return geo.NewPoint(math.NaN(), math.NaN()), math.NaN(), movementsensor.ErrMethodUnimplementedPosition
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.origin == nil {
+		return geo.NewPoint(math.NaN(), math.NaN()), math.NaN(), nil
+	}
+	return f.toGlobal(f.filter.state.AtVec(stateEast), f.filter.state.AtVec(stateNorth)), f.altitude, nil
+}
+
+// LinearVelocity returns the velocity with X east and Y north, like a GPS.
+func (f *fused) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	if !f.using(measurementPosition) && !f.using(measurementLinearVelocity) {
+		return r3.Vector{X: math.NaN(), Y: math.NaN(), Z: math.NaN()}, movementsensor.ErrMethodUnimplementedLinearVelocity
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return r3.Vector{X: f.filter.state.AtVec(stateVelEast), Y: f.filter.state.AtVec(stateVelNorth)}, nil
+}
+
+func (f *fused) headingSupported() bool {
+	return f.using(measurementCompassHeading) || f.using(measurementPosition)
+}
+
+func (f *fused) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	if !f.headingSupported() {
+		return math.NaN(), movementsensor.ErrMethodUnimplementedCompassHeading
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return utils.ModAngDeg(utils.RadToDeg(f.filter.state.AtVec(stateHeading))), nil
+}
+
+// Orientation returns the rotation about the vertical axis corresponding to the compass heading.
+func (f *fused) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	heading, err := f.CompassHeading(ctx, extra)
+	if err != nil {
+		return spatialmath.NewZeroOrientation(), movementsensor.ErrMethodUnimplementedOrientation
+	}
+	return &spatialmath.OrientationVectorDegrees{OZ: 1, Theta: -heading}, nil
+}
+
+func (f *fused) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+	if !f.using(measurementAngularVelocity) && !f.using(measurementCompassHeading) {
+		return spatialmath.AngularVelocity{X: math.NaN(), Y: math.NaN(), Z: math.NaN()},
+			movementsensor.ErrMethodUnimplementedAngularVelocity
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return spatialmath.AngularVelocity{Z: -utils.RadToDeg(f.filter.state.AtVec(stateYawRate))}, nil
+}
+
+func (f *fused) LinearAcceleration(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	return r3.Vector{}, movementsensor.ErrMethodUnimplementedLinearAcceleration
+}
+
+// Accuracy reports the standard deviations of the filter's estimates.
+func (f *fused) Accuracy(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	acc := movementsensor.UnimplementedOptionalAccuracies()
+	headingStdDev := float32(utils.RadToDeg(f.filter.stdDev(stateHeading)))
+	acc.AccuracyMap = map[string]float32{
+		"position_std_dev_m":           float32(math.Max(f.filter.stdDev(stateEast), f.filter.stdDev(stateNorth))),
+		"linear_velocity_std_dev_mps":  float32(math.Max(f.filter.stdDev(stateVelEast), f.filter.stdDev(stateVelNorth))),
+		"compass_heading_std_dev_degs": headingStdDev,
+	}
+	acc.CompassDegreeError = headingStdDev
+	return acc, nil
+}
+
+func (f *fused) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	return &movementsensor.Properties{
+		PositionSupported:        f.using(measurementPosition),
+		LinearVelocitySupported:  f.using(measurementPosition) || f.using(measurementLinearVelocity),
+		CompassHeadingSupported:  f.headingSupported(),
+		OrientationSupported:     f.headingSupported(),
+		AngularVelocitySupported: f.using(measurementAngularVelocity) || f.using(measurementCompassHeading),
+	}, nil
+}
+
+func (f *fused) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return movementsensor.DefaultAPIReadings(ctx, f, extra)
+}
+
+func (f *fused) Close(ctx context.Context) error {
+	// we do not close the movement sensors we fuse, which their own drivers own
+	if f.workers != nil {
+		f.workers.Stop()
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package fused
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+var origin = geo.NewPoint(40.7, -74.0)
+
+func setUp(t *testing.T, conf *Config, sensors ...*inject.MovementSensor) *fused {
+	t.Helper()
+	deps := resource.Dependencies{}
+	for _, ms := range sensors {
+		deps[ms.Name()] = ms
+	}
+	f, err := makeFused(context.Background(), deps, resource.Config{
+		Name:                "fused",
+		API:                 movementsensor.API,
+		Model:               model,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return f
+}
+
+func newGPS(position func(step int) (*geo.Point, error)) (*inject.MovementSensor, *int) {
+	step := 0
+	gps := inject.NewMovementSensor("gps")
+	gps.PropertiesFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+		return &movementsensor.Properties{PositionSupported: true}, nil
+	}
+	gps.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+		pos, err := position(step)
+		return pos, 10, err
+	}
+	return gps, &step
+}
+
+func TestValidate(t *testing.T) {
+	conf := &Config{}
+	_, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "sources"))
+
+	conf.Sources = []SourceConfig{{Name: "gps"}, {Name: "odometry", Measurements: []string{"linear_velocity"}}}
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"gps", "odometry"})
+
+	conf.Sources[1].Measurements = []string{"acceleration"}
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	conf.Sources[1].Measurements = nil
+	conf.Sources[1].LinearVelocityFrame = "sideways"
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	conf.Sources[1].LinearVelocityFrame = velocityFrameWorld
+	conf.Sources[1].PositionStdDevM = -1
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestUnsupportedMeasurement(t *testing.T) {
+	gps, _ := newGPS(func(int) (*geo.Point, error) { return origin, nil })
+	deps := resource.Dependencies{gps.Name(): gps}
+	_, err := makeFused(context.Background(), deps, resource.Config{
+		Name:  "fused",
+		API:   movementsensor.API,
+		Model: model,
+		ConvertedAttributes: &Config{
+			Sources: []SourceConfig{{Name: "gps", Measurements: []string{measurementCompassHeading}}},
+		},
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestStationaryGPS(t *testing.T) {
+	ctx := context.Background()
+	// the fixes wander about a meter either side of a point 100m north of the origin
+	center := geo.NewPoint(origin.Lat()+0.0009, origin.Lng())
+	gps, step := newGPS(func(step int) (*geo.Point, error) {
+		if step == 0 {
+			return origin, nil
+		}
+		offset := 0.00001 * math.Sin(float64(step))
+		return geo.NewPoint(center.Lat()+offset, center.Lng()-offset), nil
+	})
+	f := setUp(t, &Config{Sources: []SourceConfig{{Name: "gps"}}}, gps)
+
+	props, err := f.Properties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props, test.ShouldResemble, &movementsensor.Properties{
+		PositionSupported:       true,
+		LinearVelocitySupported: true,
+		CompassHeadingSupported: true,
+		OrientationSupported:    true,
+	})
+	_, err = f.AngularVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeError, movementsensor.ErrMethodUnimplementedAngularVelocity)
+
+	// there is no position before the first fix
+	pos, _, err := f.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, movementsensor.IsPositionNaN(pos), test.ShouldBeTrue)
+
+	now := time.Now()
+	for ; *step < 200; *step++ {
+		now = now.Add(50 * time.Millisecond)
+		f.step(ctx, now)
+	}
+
+	pos, alt, err := f.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, alt, test.ShouldEqual, 10.)
+	test.That(t, pos.GreatCircleDistance(center)*1000, test.ShouldBeLessThan, 0.5)
+
+	vel, err := f.LinearVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, vel.Norm(), test.ShouldBeLessThan, 0.5)
+
+	acc, err := f.Accuracy(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, acc.AccuracyMap["position_std_dev_m"], test.ShouldBeLessThan, 1)
+}
+
+func TestDeadReckoning(t *testing.T) {
+	ctx := context.Background()
+	// the GPS gets a single fix and then drops out
+	gps, step := newGPS(func(step int) (*geo.Point, error) {
+		if step == 0 {
+			return origin, nil
+		}
+		return nil, errors.New("no fix")
+	})
+
+	imu := inject.NewMovementSensor("imu")
+	imu.PropertiesFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+		return &movementsensor.Properties{CompassHeadingSupported: true, AngularVelocitySupported: true}, nil
+	}
+	imu.CompassHeadingFunc = func(ctx context.Context, extra map[string]interface{}) (float64, error) {
+		return 90, nil
+	}
+	imu.AngularVelocityFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+		return spatialmath.AngularVelocity{}, nil
+	}
+
+	// wheel odometry reports its position too, but we only trust its velocity
+	odometry := inject.NewMovementSensor("odometry")
+	odometry.PropertiesFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+		return &movementsensor.Properties{PositionSupported: true, LinearVelocitySupported: true}, nil
+	}
+	odometry.LinearVelocityFunc = func(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+		return r3.Vector{Y: 1}, nil
+	}
+
+	f := setUp(t, &Config{Sources: []SourceConfig{
+		{Name: "gps"},
+		{Name: "imu"},
+		{Name: "odometry", Measurements: []string{measurementLinearVelocity}},
+	}}, gps, imu, odometry)
+
+	// drive east at a meter per second for ten seconds
+	now := time.Now()
+	for ; *step <= 200; *step++ {
+		now = now.Add(50 * time.Millisecond)
+		f.step(ctx, now)
+	}
+
+	heading, err := f.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, heading, test.ShouldAlmostEqual, 90, 1)
+
+	vel, err := f.LinearVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, vel.X, test.ShouldAlmostEqual, 1, 0.05)
+	test.That(t, vel.Y, test.ShouldAlmostEqual, 0, 0.05)
+
+	pos, _, err := f.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	east, north := f.toLocal(pos)
+	test.That(t, east, test.ShouldAlmostEqual, 10, 1)
+	test.That(t, north, test.ShouldAlmostEqual, 0, 1)
+
+	angVel, err := f.AngularVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, angVel.Z, test.ShouldAlmostEqual, 0, 0.5)
+
+	test.That(t, f.Close(ctx), test.ShouldBeNil)
+}
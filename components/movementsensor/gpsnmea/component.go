@@ -20,6 +20,7 @@ type NMEAMovementSensor struct {
 	resource.AlwaysRebuild
 	logger     logging.Logger
 	cachedData *gpsutils.CachedData
+	pps        *gpsutils.PPSSync
 }
 
 // newNMEAMovementSensor creates a new movement sensor.
@@ -115,6 +116,9 @@ func (g *NMEAMovementSensor) Readings(
 	}
 	readings["fix"] = fix
 	readings["satellites_in_view"] = satsInView
+	if g.pps != nil {
+		readings["pps"] = g.pps.Readings()
+	}
 
 	return readings, nil
 }
@@ -129,6 +133,11 @@ func (g *NMEAMovementSensor) Properties(
 // Close shuts down the NMEAMovementSensor.
 func (g *NMEAMovementSensor) Close(ctx context.Context) error {
 	g.logger.CDebug(ctx, "Closing NMEAMovementSensor")
+	if g.pps != nil {
+		if err := g.pps.Close(); err != nil {
+			g.logger.CWarnf(ctx, "failed to stop PPS sync: %s", err)
+		}
+	}
 	// In some of the unit tests, the cachedData is nil. Only close it if it's not.
 	if g.cachedData != nil {
 		return g.cachedData.Close(ctx)
@@ -10,13 +10,24 @@ package gpsnmea
 	Example GPS NMEA chip datasheet:
 	https://content.u-blox.com/sites/default/files/NEO-M9N-00B_DataSheet_UBX-19014285.pdf
 
+	If the GPS's PPS (pulse per second) line is wired to a board's digital interrupt, the readings
+	can include how far the system clock is from GPS time, and chrony can be given the time too:
+	"pps": {"board": "pi", "digital_interrupt": "pps", "chrony_shm_unit": 2}
+
+	The serial or I2C attributes may include a receiver to configure at startup: whether it uses
+	SBAS, which constellations it tracks, and how many fixes it reports each second. Receivers on
+	I2C are sent PMTK commands unless the protocol is "ubx"; on serial, the protocol must be given:
+	"receiver": {"protocol": "pmtk", "sbas": true, "constellations": ["gps", "glonass"], "update_rate_hz": 5}
+
 */
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/pkg/errors"
+	"go.uber.org/multierr"
 
 	"go.viam.com/rdk/components/movementsensor"
 	"go.viam.com/rdk/components/movementsensor/gpsutils"
@@ -37,6 +48,8 @@ type Config struct {
 
 	*gpsutils.SerialConfig `json:"serial_attributes,omitempty"`
 	*gpsutils.I2CConfig    `json:"i2c_attributes,omitempty"`
+
+	PPS *gpsutils.PPSConfig `json:"pps,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -47,12 +60,21 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 
 	switch strings.ToLower(cfg.ConnectionType) {
 	case i2cStr:
-		return nil, cfg.I2CConfig.Validate(path)
+		if err := cfg.I2CConfig.Validate(path); err != nil {
+			return nil, err
+		}
 	case serialStr:
-		return nil, cfg.SerialConfig.Validate(path)
+		if err := cfg.SerialConfig.Validate(path); err != nil {
+			return nil, err
+		}
 	default:
 		return nil, connectionTypeError(cfg.ConnectionType, serialStr, i2cStr)
 	}
+
+	if cfg.PPS != nil {
+		return cfg.PPS.Validate(fmt.Sprintf("%s.%s", path, "pps"))
+	}
+	return nil, nil
 }
 
 var model = resource.DefaultModelFamily.WithModel("gps-nmea")
@@ -92,15 +114,27 @@ func newNMEAGPS(
 		return nil, err
 	}
 
+	var g NmeaMovementSensor
 	switch strings.ToLower(newConf.ConnectionType) {
 	case serialStr:
-		return NewSerialGPSNMEA(ctx, conf.ResourceName(), newConf, logger)
+		g, err = NewSerialGPSNMEA(ctx, conf.ResourceName(), newConf, logger)
 	case i2cStr:
-		return NewPmtkI2CGPSNMEA(ctx, deps, conf.ResourceName(), newConf, logger)
+		g, err = NewPmtkI2CGPSNMEA(ctx, deps, conf.ResourceName(), newConf, logger)
 	default:
 		return nil, connectionTypeError(
 			newConf.ConnectionType,
 			i2cStr,
 			serialStr)
 	}
+	if err != nil || newConf.PPS == nil {
+		return g, err
+	}
+
+	// Both connections make an NMEAMovementSensor.
+	nmeaSensor := g.(*NMEAMovementSensor)
+	nmeaSensor.pps, err = gpsutils.NewPPSSync(ctx, newConf.PPS, deps, nmeaSensor.cachedData, logger)
+	if err != nil {
+		return nil, multierr.Combine(err, g.Close(ctx))
+	}
+	return g, nil
 }
@@ -25,21 +25,55 @@ package gpsrtkpmtk
 			"ntrip_mountpoint": "MNTPT",
 			"ntrip_password": "pass",
 			"ntrip_url": "http://ntrip/url",
-			"ntrip_username": "usr"
+			"ntrip_username": "usr",
+			"record_io_path": "/tmp/gps-i2c.jsonl"
 		},
 		"depends_on": [],
 	}
 
+	record_io_path is optional, and records everything sent to and read from the chip over I2C to
+	a fixture file which unit tests can replay with the iorecord package.
+
+	ntrip_mountpoint may be "auto", to use the caster's station nearest to the chip once it has a
+	fix, picking again whenever it moves far from there. The caster's sourcetable can be listed with
+	the DoCommand {"get_sourcetable": true}.
+
+	log_corrections logs a summary of the RTCM messages received from the caster each minute, and
+	adds statistics on them to the readings. corrections_dump_path, if set, is a file to write the
+	raw correction stream to, for debugging.
+
+	If the GPS's PPS (pulse per second) line is wired to a board's digital interrupt, the readings
+	can include how far the system clock is from GPS time, and chrony can be given the time too:
+	"pps": {"board": "pi", "digital_interrupt": "pps", "chrony_shm_unit": 2}
+
+	receiver, if set, configures the chip at startup: whether it uses SBAS, which constellations it
+	tracks, and how many fixes it reports each second. The chip is sent PMTK commands unless the
+	protocol is "ubx", for u-blox chips from the ZED-F9P on:
+	"receiver": {"protocol": "ubx", "sbas": false, "constellations": ["gps", "galileo"], "update_rate_hz": 5}
+
+	If the caster's sourcetable says the mountpoint needs NMEA input, it is a Virtual Reference
+	Station: once the chip has a fix, its position is uploaded to the caster as a GGA sentence
+	before any corrections are read.
+
+	Instead of from an NTRIP caster, the corrections can come from a correction_source, which is one
+	of a TCP server sending raw RTCM, such as a base station's:
+	"correction_source": {"type": "tcp", "address": "192.168.1.10:2101"}
+	a serial device, such as a telemetry radio receiving them from a base station:
+	"correction_source": {"type": "serial", "serial_path": "/dev/ttyUSB1", "serial_baud_rate": 57600}
+	a file of them to replay over and over, such as one written to corrections_dump_path:
+	"correction_source": {"type": "file", "file_path": "/tmp/rtcm.bin", "file_bytes_per_sec": 500}
+	or an rtk-correction-distributor service, which shares one connection to a caster among all of
+	a robot's GPSes:
+	"correction_source": {"type": "distributor", "distributor": "corrections"}
+
 */
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"math"
-	"strings"
 	"sync"
 
 	"github.com/go-gnss/rtcm/rtcm3"
@@ -48,6 +82,7 @@ import (
 	"go.viam.com/utils"
 
 	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/components/board/iorecord"
 	"go.viam.com/rdk/components/movementsensor"
 	"go.viam.com/rdk/components/movementsensor/gpsutils"
 	"go.viam.com/rdk/logging"
@@ -68,6 +103,17 @@ type Config struct {
 	NtripMountpoint      string `json:"ntrip_mountpoint,omitempty"`
 	NtripPass            string `json:"ntrip_password,omitempty"`
 	NtripUser            string `json:"ntrip_username,omitempty"`
+
+	// RecordIOPath, if set, is a file to record the I2C transactions with the chip to.
+	RecordIOPath string `json:"record_io_path,omitempty"`
+
+	LogCorrections      bool   `json:"log_corrections,omitempty"`
+	CorrectionsDumpPath string `json:"corrections_dump_path,omitempty"`
+
+	CorrectionSource *gpsutils.CorrectionSourceConfig `json:"correction_source,omitempty"`
+
+	PPS      *gpsutils.PPSConfig      `json:"pps,omitempty"`
+	Receiver *gpsutils.ReceiverConfig `json:"receiver,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -77,12 +123,25 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 		return nil, err
 	}
 
-	err = cfg.validateNtrip(path)
+	deps, err := cfg.validateNtrip(path)
 	if err != nil {
 		return nil, err
 	}
 
-	return []string{}, nil
+	if cfg.Receiver != nil {
+		if err := cfg.Receiver.Validate(fmt.Sprintf("%s.%s", path, "receiver"), gpsutils.ProtocolPMTK); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.PPS != nil {
+		ppsDeps, err := cfg.PPS.Validate(fmt.Sprintf("%s.%s", path, "pps"))
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, ppsDeps...)
+	}
+	return deps, nil
 }
 
 // validateI2C ensures all parts of the config are valid.
@@ -96,12 +155,20 @@ func (cfg *Config) validateI2C(path string) error {
 	return nil
 }
 
-// validateNtrip ensures all parts of the config are valid.
-func (cfg *Config) validateNtrip(path string) error {
+// validateNtrip ensures all parts of the config are valid, and returns the resources the
+// corrections come from, if any.
+func (cfg *Config) validateNtrip(path string) ([]string, error) {
+	if cfg.CorrectionSource != nil {
+		if cfg.NtripURL != "" {
+			return nil, resource.NewConfigValidationError(path,
+				errors.New("give either ntrip_url or a correction_source, not both"))
+		}
+		return cfg.CorrectionSource.Validate(fmt.Sprintf("%s.%s", path, "correction_source"))
+	}
 	if cfg.NtripURL == "" {
-		return resource.NewConfigValidationFieldRequiredError(path, "ntrip_url")
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "ntrip_url")
 	}
-	return nil
+	return []string{}, nil
 }
 
 func init() {
@@ -124,19 +191,23 @@ type rtkI2C struct {
 	activeBackgroundWorkers sync.WaitGroup
 
 	mu          sync.Mutex
-	ntripClient *gpsutils.NtripInfo
+	source      gpsutils.CorrectionSource
 	ntripStatus bool
 
 	err          movementsensor.LastError
 	lastposition movementsensor.LastPosition
 
-	cachedData       *gpsutils.CachedData
-	correctionWriter io.ReadWriteCloser
-
-	bus     buses.I2C
-	mockI2c buses.I2C // Will be nil unless we're in a unit test
-	wbaud   int
-	addr    byte
+	cachedData    *gpsutils.CachedData
+	corrections   *gpsutils.I2CConnection
+	correctionLog *gpsutils.CorrectionLog
+	pps           *gpsutils.PPSSync
+
+	bus      buses.I2C
+	mockI2c  buses.I2C // Will be nil unless we're in a unit test
+	recorder *iorecord.Recorder
+	wbaud    int
+	addr     byte
+	receiver *gpsutils.ReceiverConfig
 }
 
 // Reconfigure reconfigures attributes.
@@ -155,6 +226,7 @@ func (g *rtkI2C) Reconfigure(ctx context.Context, deps resource.Dependencies, co
 	}
 
 	g.addr = byte(newConf.I2CAddr)
+	g.receiver = newConf.Receiver
 
 	if g.mockI2c == nil {
 		i2cbus, err := buses.NewI2cBus(newConf.I2CBus)
@@ -165,29 +237,10 @@ func (g *rtkI2C) Reconfigure(ctx context.Context, deps resource.Dependencies, co
 	} else {
 		g.bus = g.mockI2c
 	}
-
-	ntripConfig := &gpsutils.NtripConfig{
-		NtripURL:             newConf.NtripURL,
-		NtripUser:            newConf.NtripUser,
-		NtripPass:            newConf.NtripPass,
-		NtripMountpoint:      newConf.NtripMountpoint,
-		NtripConnectAttempts: newConf.NtripConnectAttempts,
-	}
-
-	// Init ntripInfo from attributes
-	tempNtripClient, err := gpsutils.NewNtripInfo(ntripConfig, g.logger)
-	if err != nil {
-		return err
-	}
-
-	if g.ntripClient == nil {
-		g.ntripClient = tempNtripClient
-	} else {
-		tempNtripClient.Client = g.ntripClient.Client
-		tempNtripClient.Stream = g.ntripClient.Stream
-
-		g.ntripClient = tempNtripClient
+	if g.recorder != nil {
+		g.bus = iorecord.RecordI2C(g.bus, "corrections", g.recorder)
 	}
+	g.corrections = gpsutils.NewI2CConnection(g.bus, g.addr, g.logger)
 
 	g.logger.CDebug(ctx, "done reconfiguring")
 
@@ -228,34 +281,80 @@ func makeRTKI2C(
 		mockI2c:      mockI2c,
 	}
 
+	if newConf.RecordIOPath != "" {
+		if g.recorder, err = iorecord.NewRecorder(newConf.RecordIOPath); err != nil {
+			return nil, err
+		}
+		logger.CInfof(ctx, "recording I2C transactions with the GPS to %s", newConf.RecordIOPath)
+	}
+
 	if err = g.Reconfigure(ctx, deps, conf); err != nil {
-		return nil, err
+		return nil, errors.Join(err, g.closeRecorder())
+	}
+
+	g.correctionLog, err = gpsutils.NewCorrectionLog(gpsutils.CorrectionLogConfig{
+		LogCorrections:      newConf.LogCorrections,
+		CorrectionsDumpPath: newConf.CorrectionsDumpPath,
+	}, logger)
+	if err != nil {
+		return nil, errors.Join(err, g.closeRecorder())
 	}
 
 	config := gpsutils.I2CConfig{
 		I2CBus:      newConf.I2CBus,
 		I2CBaudRate: newConf.I2CBaudRate,
 		I2CAddr:     newConf.I2CAddr,
+		Receiver:    newConf.Receiver,
 	}
 	if config.I2CBaudRate == 0 {
 		config.I2CBaudRate = 115200
 	}
 
 	// If we have a mock I2C bus, pass that in, too. If we don't, it'll be nil and constructing the
-	// reader will create a real I2C bus instead.
-	dev, err := gpsutils.NewI2cDataReader(config, mockI2c, logger)
+	// reader will create a real I2C bus instead, unless we need one to record.
+	readerBus := mockI2c
+	if g.recorder != nil {
+		if readerBus == nil {
+			if readerBus, err = buses.NewI2cBus(newConf.I2CBus); err != nil {
+				return nil, errors.Join(err, g.closeRecorder(), g.closeCorrectionLog())
+			}
+		}
+		readerBus = iorecord.RecordI2C(readerBus, "nmea", g.recorder)
+	}
+	dev, err := gpsutils.NewI2cDataReader(config, readerBus, logger)
 	if err != nil {
-		return nil, err
+		return nil, errors.Join(err, g.closeRecorder(), g.closeCorrectionLog())
 	}
 	g.cachedData = gpsutils.NewCachedData(dev, logger)
 
+	if newConf.CorrectionSource != nil {
+		g.source, err = gpsutils.NewCorrectionSource(newConf.CorrectionSource, deps, logger)
+	} else {
+		g.source, err = gpsutils.NewNtripSource(&gpsutils.NtripConfig{
+			NtripURL:             newConf.NtripURL,
+			NtripUser:            newConf.NtripUser,
+			NtripPass:            newConf.NtripPass,
+			NtripMountpoint:      newConf.NtripMountpoint,
+			NtripConnectAttempts: newConf.NtripConnectAttempts,
+		}, g.cachedData, logger)
+	}
+	if err != nil {
+		return nil, errors.Join(err, g.cachedData.Close(ctx), g.closeRecorder(), g.closeCorrectionLog())
+	}
+
+	if newConf.PPS != nil {
+		if g.pps, err = gpsutils.NewPPSSync(ctx, newConf.PPS, deps, g.cachedData, logger); err != nil {
+			return nil, errors.Join(err, g.source.Close(), g.cachedData.Close(ctx), g.closeRecorder(), g.closeCorrectionLog())
+		}
+	}
+
 	if err := g.start(); err != nil {
 		return nil, err
 	}
 	return g, g.err.Get()
 }
 
-// Start begins NTRIP receiver with i2c protocol and begins reading/updating MovementSensor measurements.
+// Start begins writing corrections to the chip over i2c, in the background.
 func (g *rtkI2C) start() error {
 	g.activeBackgroundWorkers.Add(1)
 	utils.PanicCapturingGo(func() { g.receiveAndWriteI2C(g.cancelCtx) })
@@ -263,131 +362,38 @@ func (g *rtkI2C) start() error {
 	return g.err.Get()
 }
 
-// getStream attempts to connect to ntrip stream until successful connection or timeout.
-func (g *rtkI2C) getStream(mountPoint string, maxAttempts int) error {
-	success := false
-	attempts := 0
-
-	var rc io.ReadCloser
-	var err error
-
-	g.logger.Debug("Getting NTRIP stream")
-
-	for !success && attempts < maxAttempts {
-		select {
-		case <-g.cancelCtx.Done():
-			return errors.New("Canceled")
-		default:
-		}
-
-		rc, err = func() (io.ReadCloser, error) {
-			g.mu.Lock()
-			defer g.mu.Unlock()
-			return g.ntripClient.Client.GetStream(mountPoint)
-		}()
-		if err == nil {
-			success = true
-		}
-		attempts++
-	}
-
-	if err != nil {
-		// if the error is related to ICY, we log it as warning.
-		if strings.Contains(err.Error(), "ICY") {
-			g.logger.Warnf("Detected old HTTP protocol: %s", err)
-		} else {
-			g.logger.Errorf("Can't connect to NTRIP stream: %s", err)
-			return err
-		}
-	}
-
-	g.logger.Debug("Connected to stream")
-	g.mu.Lock()
-	defer g.mu.Unlock()
-
-	g.ntripClient.Stream = rc
-	return g.err.Get()
-}
-
-// receiveAndWriteI2C connects to NTRIP receiver and sends correction stream to the MovementSensor through I2C protocol.
+// receiveAndWriteI2C reads the stream of corrections from the source and sends it to the
+// MovementSensor through I2C protocol, reconnecting to the source whenever the stream ends.
 func (g *rtkI2C) receiveAndWriteI2C(ctx context.Context) {
 	defer g.activeBackgroundWorkers.Done()
 	if err := g.cancelCtx.Err(); err != nil {
 		return
 	}
-	err := g.ntripClient.Connect(g.cancelCtx, g.logger)
-	if err != nil {
-		g.err.Set(err)
-		return
-	}
-
-	if !g.ntripClient.Client.IsCasterAlive() {
-		g.logger.CInfof(ctx, "caster %s seems to be down", g.ntripClient.URL)
-	}
-
-	// establish I2C connection
-	handle, err := g.bus.OpenHandle(g.addr)
-	if err != nil {
-		g.logger.CErrorf(ctx, "can't open gps i2c %s", err)
-		g.err.Set(err)
-		return
-	}
-	defer utils.UncheckedErrorFunc(handle.Close)
 
-	// Send GLL, RMC, VTG, GGA, GSA, and GSV sentences each 1000ms
 	baudcmd := fmt.Sprintf("PMTK251,%d", g.wbaud)
 	cmd251 := movementsensor.PMTKAddChk([]byte(baudcmd))
-	cmd314 := movementsensor.PMTKAddChk([]byte("PMTK314,1,1,1,1,1,1,0,0,0,0,0,0,0,0,0,0,0,0,0"))
-	cmd220 := movementsensor.PMTKAddChk([]byte("PMTK220,1000"))
 
-	err = handle.Write(ctx, cmd251)
-	if err != nil {
+	if err := g.corrections.Write(ctx, cmd251); err != nil {
 		g.logger.CDebug(ctx, "Failed to set baud rate")
 	}
 
-	err = handle.Write(ctx, cmd314)
-	if err != nil {
-		g.logger.CDebug(ctx, "failed to set NMEA output")
-		g.err.Set(err)
-		return
-	}
-
-	err = handle.Write(ctx, cmd220)
-	if err != nil {
-		g.logger.CDebug(ctx, "failed to set NMEA update rate")
-		g.err.Set(err)
-		return
-	}
-
-	err = g.getStream(g.ntripClient.MountPoint, g.ntripClient.MaxConnectAttempts)
-	if err != nil {
-		g.err.Set(err)
-		return
-	}
-
-	// create a buffer
-	w := &bytes.Buffer{}
-	r := io.TeeReader(g.ntripClient.Stream, w)
-
-	buf := make([]byte, 1100)
-	n, err := g.ntripClient.Stream.Read(buf)
-	if err != nil {
-		g.err.Set(err)
-		return
+	// Choose the sentences the chip sends and how often, and set up SBAS and the constellations
+	for _, cmd := range gpsutils.ReceiverCommands(g.receiver, gpsutils.ProtocolPMTK) {
+		if err := g.corrections.Write(ctx, cmd); err != nil {
+			g.logger.CDebug(ctx, "failed to configure the receiver")
+			g.err.Set(err)
+			return
+		}
 	}
 
-	wI2C := movementsensor.PMTKAddChk(buf[:n])
-
-	// port still open
-	err = handle.Write(ctx, wI2C)
+	scanner, err := g.startCorrections(ctx)
 	if err != nil {
-		g.logger.CErrorf(ctx, "i2c handle write failed %s", err)
-		g.err.Set(err)
+		if ctx.Err() == nil {
+			g.err.Set(err)
+		}
 		return
 	}
 
-	scanner := rtcm3.NewScanner(r)
-
 	g.mu.Lock()
 	g.ntripStatus = true
 	g.mu.Unlock()
@@ -397,11 +403,16 @@ func (g *rtkI2C) receiveAndWriteI2C(ctx context.Context) {
 	for g.ntripStatus {
 		select {
 		case <-g.cancelCtx.Done():
-			g.err.Set(err)
 			return
 		default:
 		}
 
+		if err := g.corrections.Healthy(); err != nil {
+			g.logger.CErrorf(ctx, "gps is not accepting corrections: %s", err)
+			g.err.Set(err)
+			return
+		}
+
 		msg, err := scanner.NextMessage()
 		if err != nil {
 			g.mu.Lock()
@@ -409,42 +420,81 @@ func (g *rtkI2C) receiveAndWriteI2C(ctx context.Context) {
 			g.mu.Unlock()
 
 			if msg == nil {
-				g.logger.CDebug(ctx, "No message... reconnecting to stream...")
-				err = g.getStream(g.ntripClient.MountPoint, g.ntripClient.MaxConnectAttempts)
+				g.logger.CDebug(ctx, "No message... reconnecting to the correction source...")
+				scanner, err = g.startCorrections(ctx)
 				if err != nil {
-					g.err.Set(err)
+					if ctx.Err() == nil {
+						g.err.Set(err)
+					}
 					return
 				}
 
-				w = &bytes.Buffer{}
-				r = io.TeeReader(g.ntripClient.Stream, w)
-
-				buf = make([]byte, 1100)
-				n, err := g.ntripClient.Stream.Read(buf)
-				if err != nil {
-					g.err.Set(err)
-					return
-				}
-				wI2C := movementsensor.PMTKAddChk(buf[:n])
-
-				err = handle.Write(ctx, wI2C)
-
-				if err != nil {
-					g.logger.CErrorf(ctx, "i2c handle write failed %s", err)
-					g.err.Set(err)
-					return
-				}
-
-				scanner = rtcm3.NewScanner(r)
 				g.mu.Lock()
 				g.ntripStatus = true
 				g.mu.Unlock()
-				continue
 			}
+			continue
+		}
+
+		if g.correctionLog != nil {
+			g.correctionLog.RecordMessage(msg.Number())
 		}
 	}
 }
 
+// startCorrections connects to the correction source and writes the first chunk of its stream to
+// the chip. It returns a scanner over the rest of the stream which writes everything it reads to
+// the chip, too.
+func (g *rtkI2C) startCorrections(ctx context.Context) (rtcm3.Scanner, error) {
+	stream, err := g.source.Stream(ctx)
+	if err != nil {
+		return rtcm3.Scanner{}, err
+	}
+
+	buf := make([]byte, 1100)
+	n, err := stream.Read(buf)
+	if err != nil {
+		return rtcm3.Scanner{}, err
+	}
+
+	if err := g.corrections.Write(ctx, movementsensor.PMTKAddChk(buf[:n])); err != nil {
+		g.logger.CErrorf(ctx, "i2c write failed %s", err)
+	}
+
+	var w io.Writer = &correctionWriter{ctx: ctx, conn: g.corrections, logger: g.logger}
+	if g.correctionLog != nil {
+		// the log never fails to write
+		_, _ = g.correctionLog.Write(buf[:n])
+		w = io.MultiWriter(w, g.correctionLog)
+	}
+	return rtcm3.NewScanner(io.TeeReader(stream, w)), nil
+}
+
+// correctionWriter forwards the correction stream to the chip as it is read. Writes which fail are
+// dropped rather than returned, so that they don't tear down the NTRIP stream: the connection's
+// health check is what notices a chip which has stopped taking corrections altogether.
+type correctionWriter struct {
+	ctx    context.Context
+	conn   *gpsutils.I2CConnection
+	logger logging.Logger
+}
+
+func (w *correctionWriter) Write(p []byte) (int, error) {
+	if err := w.conn.Write(w.ctx, p); err != nil {
+		w.logger.CDebugw(w.ctx, "dropping corrections the gps wouldn't take", "bytes", len(p), "error", err)
+	}
+	return len(p), nil
+}
+
+// DoCommand returns the streams in the caster's sourcetable when sent {"get_sourcetable": true}, if
+// the corrections come from an NTRIP caster.
+func (g *rtkI2C) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if ntrip, ok := g.source.(*gpsutils.NtripSource); ok {
+		return ntrip.DoCommand(ctx, cmd)
+	}
+	return nil, resource.ErrDoUnimplemented
+}
+
 // getNtripConnectionStatus returns true if connection to NTRIP stream is OK, false if not
 //
 //nolint:all
@@ -612,6 +662,12 @@ func (g *rtkI2C) Readings(ctx context.Context, extra map[string]interface{}) (ma
 
 	readings["fix"] = fix
 	readings["satellites_in_view"] = satsInView
+	if g.correctionLog != nil {
+		readings["corrections"] = g.correctionLog.Readings()
+	}
+	if g.pps != nil {
+		readings["pps"] = g.pps.Readings()
+	}
 
 	return readings, nil
 }
@@ -626,35 +682,62 @@ func (g *rtkI2C) Close(ctx context.Context) error {
 		return err
 	}
 
-	// close ntrip writer
-	if g.correctionWriter != nil {
-		if err := g.correctionWriter.Close(); err != nil {
+	// stop writing corrections to the chip
+	if g.corrections != nil {
+		if err := g.corrections.Close(); err != nil {
 			g.mu.Unlock()
 			return err
 		}
-		g.correctionWriter = nil
-	}
-
-	// close ntrip client and stream
-	if g.ntripClient.Client != nil {
-		g.ntripClient.Client.CloseIdleConnections()
-		g.ntripClient.Client = nil
 	}
 
-	if g.ntripClient.Stream != nil {
-		if err := g.ntripClient.Stream.Close(); err != nil {
-			g.mu.Unlock()
-			return err
-		}
-		g.ntripClient.Stream = nil
+	// disconnect from the correction source, ending its stream
+	if err := g.source.Close(); err != nil {
+		g.mu.Unlock()
+		return err
 	}
 
 	g.mu.Unlock()
 	g.activeBackgroundWorkers.Wait()
 
+	if err := g.closeRecorder(); err != nil {
+		return err
+	}
+
+	if err := g.closePPS(); err != nil {
+		return err
+	}
+
+	if err := g.closeCorrectionLog(); err != nil {
+		return err
+	}
+
 	if err := g.err.Get(); err != nil && !errors.Is(err, context.Canceled) {
 		return err
 	}
 
 	return nil
 }
+
+// closeRecorder stops recording I2C transactions, if they're being recorded.
+func (g *rtkI2C) closeRecorder() error {
+	if g.recorder == nil {
+		return nil
+	}
+	return g.recorder.Close()
+}
+
+// closeCorrectionLog closes the log of the corrections received, if they're being logged.
+func (g *rtkI2C) closeCorrectionLog() error {
+	if g.correctionLog == nil {
+		return nil
+	}
+	return g.correctionLog.Close()
+}
+
+// closePPS stops measuring the clock against the PPS line, if it's being measured.
+func (g *rtkI2C) closePPS() error {
+	if g.pps == nil {
+		return nil
+	}
+	return g.pps.Close()
+}
@@ -4,12 +4,15 @@ package gpsrtkpmtk
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	geo "github.com/kellydunn/golang-geo"
 	"go.viam.com/test"
 
+	"go.viam.com/rdk/components/board/genericlinux/buses"
 	"go.viam.com/rdk/components/movementsensor/fake"
+	"go.viam.com/rdk/components/movementsensor/gpsutils"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/testutils/inject"
@@ -82,6 +85,20 @@ func TestValidateRTK(t *testing.T) {
 		test.That(t, err, test.ShouldBeError,
 			resource.NewConfigValidationFieldRequiredError(path, "i2c_addr"))
 	})
+
+	t.Run("correction source", func(t *testing.T) {
+		cfg := Config{
+			I2CBus:           testI2cBus,
+			I2CAddr:          testI2cAddr,
+			CorrectionSource: &gpsutils.CorrectionSourceConfig{Type: gpsutils.CorrectionSourceFile, FilePath: "/tmp/rtcm.bin"},
+		}
+		_, err := cfg.Validate(path)
+		test.That(t, err, test.ShouldBeNil)
+
+		cfg.NtripURL = "http://fakeurl"
+		_, err = cfg.Validate(path)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
 }
 
 func TestReconfigure(t *testing.T) {
@@ -111,6 +128,31 @@ func TestReconfigure(t *testing.T) {
 	test.That(t, g.addr, test.ShouldEqual, byte(44))
 }
 
+func TestCorrectionWriter(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	writeErr := errors.New("nack")
+	opens := 0
+	mockI2c := &inject.I2C{}
+	mockI2c.OpenHandleFunc = func(addr byte) (buses.I2CHandle, error) {
+		opens++
+		handle := &inject.I2CHandle{}
+		handle.WriteFunc = func(ctx context.Context, tx []byte) error { return writeErr }
+		handle.CloseFunc = func() error { return nil }
+		return handle, nil
+	}
+	conn := gpsutils.NewI2CConnection(mockI2c, testI2cAddr, logger)
+	w := &correctionWriter{ctx: context.Background(), conn: conn, logger: logger}
+
+	// failed writes mustn't interrupt reading the NTRIP stream, but do show up in the health check
+	for i := 0; i < 5; i++ {
+		n, err := w.Write([]byte("rtcm"))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, n, test.ShouldEqual, 4)
+	}
+	test.That(t, opens, test.ShouldEqual, 10)
+	test.That(t, conn.Healthy(), test.ShouldNotBeNil)
+}
+
 type CustomMovementSensor struct {
 	*fake.MovementSensor
 	PositionFunc func(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error)
@@ -29,21 +29,50 @@ package gpsrtkserial
       "depends_on": [],
     }
 
+	Instead of serial_path, serial_usb may give the USB IDs of the GPS, as in
+	"serial_usb": {"vendor_id": "1546", "product_id": "01a9"}, to find it wherever it is connected.
+	Add "serial_number" if more than one device has those IDs.
+
+	log_corrections logs a summary of the RTCM messages received from the caster each minute, and
+	adds statistics on them to the readings. corrections_dump_path, if set, is a file to write the
+	raw correction stream to, for debugging.
+
+	If the GPS's PPS (pulse per second) line is wired to a board's digital interrupt, the readings
+	can include how far the system clock is from GPS time, and chrony can be given the time too:
+	"pps": {"board": "pi", "digital_interrupt": "pps", "chrony_shm_unit": 2}
+
+	receiver, if set, configures the GPS at startup: whether it uses SBAS, which constellations it
+	tracks, and how many fixes it reports each second. Its protocol is "ubx" for u-blox receivers
+	from the ZED-F9P on, or "pmtk" for MediaTek ones:
+	"receiver": {"protocol": "ubx", "sbas": false, "constellations": ["gps", "galileo"], "update_rate_hz": 5}
+
+	Instead of from an NTRIP caster, the corrections can come from a correction_source, which is one
+	of a TCP server sending raw RTCM, such as a base station's:
+	"correction_source": {"type": "tcp", "address": "192.168.1.10:2101"}
+	a serial device, such as a telemetry radio receiving them from a base station:
+	"correction_source": {"type": "serial", "serial_path": "/dev/ttyUSB1", "serial_baud_rate": 57600}
+	a file of them to replay over and over, such as one written to corrections_dump_path:
+	"correction_source": {"type": "file", "file_path": "/tmp/rtcm.bin", "file_bytes_per_sec": 500}
+	or an rtk-correction-distributor service, which shares one connection to a caster among all of
+	a robot's GPSes:
+	"correction_source": {"type": "distributor", "distributor": "corrections"}
+
+	ntrip_mountpoint may be "auto", to use the caster's station nearest to the GPS once it has a
+	fix, picking again whenever it moves far from there. The caster's sourcetable can be listed with
+	the DoCommand {"get_sourcetable": true}.
+
 */
 
 import (
-	"bufio"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"math"
-	"strings"
 	"sync"
 
 	"github.com/go-gnss/rtcm/rtcm3"
 	"github.com/golang/geo/r3"
-	slib "github.com/jacobsa/go-serial/serial"
 	geo "github.com/kellydunn/golang-geo"
 	"go.viam.com/utils"
 
@@ -52,6 +81,7 @@ import (
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils/serialport"
 )
 
 var rtkmodel = resource.DefaultModelFamily.WithModel("gps-nmea-rtk-serial")
@@ -63,27 +93,64 @@ const (
 
 // Config is used for converting NMEA MovementSensor with RTK capabilities config attributes.
 type Config struct {
-	SerialPath     string `json:"serial_path"`
-	SerialBaudRate int    `json:"serial_baud_rate,omitempty"`
+	SerialPath     string               `json:"serial_path"`
+	SerialBaudRate int                  `json:"serial_baud_rate,omitempty"`
+	SerialUSB      *serialport.USBMatch `json:"serial_usb,omitempty"`
 
 	NtripURL             string `json:"ntrip_url"`
 	NtripConnectAttempts int    `json:"ntrip_connect_attempts,omitempty"`
 	NtripMountpoint      string `json:"ntrip_mountpoint,omitempty"`
 	NtripPass            string `json:"ntrip_password,omitempty"`
 	NtripUser            string `json:"ntrip_username,omitempty"`
+
+	LogCorrections      bool   `json:"log_corrections,omitempty"`
+	CorrectionsDumpPath string `json:"corrections_dump_path,omitempty"`
+
+	CorrectionSource *gpsutils.CorrectionSourceConfig `json:"correction_source,omitempty"`
+
+	PPS      *gpsutils.PPSConfig      `json:"pps,omitempty"`
+	Receiver *gpsutils.ReceiverConfig `json:"receiver,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
 func (cfg *Config) Validate(path string) ([]string, error) {
-	if cfg.SerialPath == "" {
+	if cfg.SerialUSB != nil {
+		if err := cfg.SerialUSB.Validate(fmt.Sprintf("%s.%s", path, "serial_usb")); err != nil {
+			return nil, err
+		}
+	} else if cfg.SerialPath == "" {
 		return nil, resource.NewConfigValidationFieldRequiredError(path, "serial_path")
 	}
 
-	if cfg.NtripURL == "" {
+	var deps []string
+	if cfg.CorrectionSource != nil {
+		if cfg.NtripURL != "" {
+			return nil, resource.NewConfigValidationError(path,
+				errors.New("give either ntrip_url or a correction_source, not both"))
+		}
+		sourceDeps, err := cfg.CorrectionSource.Validate(fmt.Sprintf("%s.%s", path, "correction_source"))
+		if err != nil {
+			return nil, err
+		}
+		deps = sourceDeps
+	} else if cfg.NtripURL == "" {
 		return nil, resource.NewConfigValidationFieldRequiredError(path, "ntrip_url")
 	}
 
-	return nil, nil
+	if cfg.Receiver != nil {
+		if err := cfg.Receiver.Validate(fmt.Sprintf("%s.%s", path, "receiver"), ""); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.PPS != nil {
+		ppsDeps, err := cfg.PPS.Validate(fmt.Sprintf("%s.%s", path, "pps"))
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, ppsDeps...)
+	}
+	return deps, nil
 }
 
 func init() {
@@ -109,21 +176,21 @@ type rtkSerial struct {
 	lastposition       movementsensor.LastPosition
 	lastcompassheading movementsensor.LastCompassHeading
 	InputProtocol      string
-	isClosed           bool
 
 	mu sync.Mutex
 
 	// everything below this comment is protected by mu
-	isConnectedToNtrip bool
-	ntripClient        *gpsutils.NtripInfo
-	cachedData         *gpsutils.CachedData
-	correctionWriter   io.ReadWriteCloser
-	writePath          string
-	wbaud              int
-	isVirtualBase      bool
-	readerWriter       *bufio.ReadWriter
-	writer             io.Writer
-	reader             io.Reader
+	source           gpsutils.CorrectionSource
+	cachedData       *gpsutils.CachedData
+	correctionLog    *gpsutils.CorrectionLog
+	pps              *gpsutils.PPSSync
+	correctionWriter io.ReadWriteCloser
+	writePath        string
+	writeUSB         *serialport.USBMatch
+	wbaud            int
+
+	// correctionsErr is why the worker writing corrections to the GPS gave up, if it has.
+	correctionsErr error
 }
 
 // Reconfigure reconfigures attributes.
@@ -140,6 +207,7 @@ func (g *rtkSerial) Reconfigure(ctx context.Context, deps resource.Dependencies,
 		g.writePath = newConf.SerialPath
 		g.logger.CInfof(ctx, "updated serial_path to #%v", newConf.SerialPath)
 	}
+	g.writeUSB = newConf.SerialUSB
 
 	if newConf.SerialBaudRate != 0 {
 		g.wbaud = newConf.SerialBaudRate
@@ -149,27 +217,6 @@ func (g *rtkSerial) Reconfigure(ctx context.Context, deps resource.Dependencies,
 		g.logger.CInfo(ctx, "serial_baud_rate using default baud rate 38400")
 	}
 
-	ntripConfig := &gpsutils.NtripConfig{
-		NtripURL:             newConf.NtripURL,
-		NtripUser:            newConf.NtripUser,
-		NtripPass:            newConf.NtripPass,
-		NtripMountpoint:      newConf.NtripMountpoint,
-		NtripConnectAttempts: newConf.NtripConnectAttempts,
-	}
-
-	// Init ntripInfo from attributes
-	tempNtripClient, err := gpsutils.NewNtripInfo(ntripConfig, g.logger)
-	if err != nil {
-		return err
-	}
-
-	if g.ntripClient != nil { // Copy over the old state
-		tempNtripClient.Client = g.ntripClient.Client
-		tempNtripClient.Stream = g.ntripClient.Stream
-	}
-
-	g.ntripClient = tempNtripClient
-
 	g.logger.Debug("done reconfiguring")
 	return nil
 }
@@ -202,78 +249,70 @@ func newRTKSerial(
 
 	g.InputProtocol = serialStr
 
+	g.correctionLog, err = gpsutils.NewCorrectionLog(gpsutils.CorrectionLogConfig{
+		LogCorrections:      newConf.LogCorrections,
+		CorrectionsDumpPath: newConf.CorrectionsDumpPath,
+	}, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	serialConfig := &gpsutils.SerialConfig{
 		SerialPath:     newConf.SerialPath,
 		SerialBaudRate: newConf.SerialBaudRate,
+		SerialUSB:      newConf.SerialUSB,
+		Receiver:       newConf.Receiver,
 	}
 	dev, err := gpsutils.NewSerialDataReader(serialConfig, logger)
 	if err != nil {
-		return nil, err
+		return nil, errors.Join(err, g.closeCorrectionLog())
 	}
 	g.cachedData = gpsutils.NewCachedData(dev, logger)
 
-	if err := g.start(); err != nil {
-		return nil, err
+	if newConf.CorrectionSource != nil {
+		g.source, err = gpsutils.NewCorrectionSource(newConf.CorrectionSource, deps, logger)
+	} else {
+		g.source, err = gpsutils.NewNtripSource(&gpsutils.NtripConfig{
+			NtripURL:             newConf.NtripURL,
+			NtripUser:            newConf.NtripUser,
+			NtripPass:            newConf.NtripPass,
+			NtripMountpoint:      newConf.NtripMountpoint,
+			NtripConnectAttempts: newConf.NtripConnectAttempts,
+		}, g.cachedData, logger)
 	}
-	return g, g.err.Get()
-}
-
-func (g *rtkSerial) start() error {
-	err := g.connectToNTRIP()
 	if err != nil {
-		return err
+		return nil, errors.Join(err, g.cachedData.Close(ctx), g.closeCorrectionLog())
 	}
-	g.activeBackgroundWorkers.Add(1)
-	utils.PanicCapturingGo(g.receiveAndWriteSerial)
-	return g.err.Get()
-}
 
-// getStream attempts to connect to ntrip stream. We give up after maxAttempts unsuccessful tries.
-func (g *rtkSerial) getStream(mountPoint string, maxAttempts int) error {
-	success := false
-	attempts := 0
-
-	var rc io.ReadCloser
-	var err error
-
-	g.logger.Debug("Getting NTRIP stream")
-
-	for !success && attempts < maxAttempts {
-		select {
-		case <-g.cancelCtx.Done():
-			return errors.New("Canceled")
-		default:
+	if newConf.PPS != nil {
+		if g.pps, err = gpsutils.NewPPSSync(ctx, newConf.PPS, deps, g.cachedData, logger); err != nil {
+			return nil, errors.Join(err, g.source.Close(), g.cachedData.Close(ctx), g.closeCorrectionLog())
 		}
+	}
 
-		rc, err = func() (io.ReadCloser, error) {
-			return g.ntripClient.Client.GetStream(mountPoint)
-		}()
-		if err == nil {
-			success = true
-		}
-		attempts++
+	if err := g.start(); err != nil {
+		return nil, errors.Join(err, g.closePPS(), g.source.Close(), g.cachedData.Close(ctx), g.closeCorrectionLog())
 	}
+	return g, g.err.Get()
+}
 
-	if err != nil {
-		g.logger.Errorf("Can't connect to NTRIP stream: %s", err)
+func (g *rtkSerial) start() error {
+	if err := g.openPort(); err != nil {
 		return err
 	}
-	g.logger.Debug("Connected to stream")
-
-	g.mu.Lock()
-	defer g.mu.Unlock()
-
-	g.ntripClient.Stream = rc
+	// Connecting to the source can wait for a fix, which mustn't hold up constructing the sensor,
+	// so connect in the background.
+	g.activeBackgroundWorkers.Add(1)
+	utils.PanicCapturingGo(g.receiveAndWriteSerial)
 	return g.err.Get()
 }
 
 // openPort opens the serial port for writing.
 func (g *rtkSerial) openPort() error {
-	options := slib.OpenOptions{
-		PortName:        g.writePath,
+	options := serialport.OpenOptions{
+		Path:            g.writePath,
+		USB:             g.writeUSB,
 		BaudRate:        uint(g.wbaud),
-		DataBits:        8,
-		StopBits:        1,
 		MinimumReadSize: 1,
 	}
 
@@ -282,7 +321,7 @@ func (g *rtkSerial) openPort() error {
 	}
 
 	var err error
-	g.correctionWriter, err = slib.Open(options)
+	g.correctionWriter, err = serialport.Open(options, g.logger)
 	if err != nil {
 		g.logger.Errorf("serial.Open: %v", err)
 		return err
@@ -304,157 +343,97 @@ func (g *rtkSerial) closePort() {
 	}
 }
 
-// connectAndParseSourceTable connects to the NTRIP caster, gets and parses source table
-// from the caster.
-func (g *rtkSerial) connectAndParseSourceTable() error {
-	if err := g.cancelCtx.Err(); err != nil {
-		return g.err.Get()
-	}
+// receiveAndWriteSerial reads the stream of corrections from the source and writes it to the
+// MovementSensor through serial, reconnecting to the source whenever the stream ends.
+func (g *rtkSerial) receiveAndWriteSerial() {
+	defer g.activeBackgroundWorkers.Done()
+	defer g.closePort()
 
-	err := g.ntripClient.Connect(g.cancelCtx, g.logger)
-	if err != nil {
-		g.err.Set(err)
-		return g.err.Get()
-	}
-
-	if !g.ntripClient.Client.IsCasterAlive() {
-		g.logger.Infof("caster %s seems to be down, retrying", g.ntripClient.URL)
-		attempts := 0
-		// we will try to connect to the caster five times if it's down.
-		for attempts < 5 {
-			if !g.ntripClient.Client.IsCasterAlive() {
-				attempts++
-				g.logger.Debugf("attempt(s) to connect to caster: %v ", attempts)
-			} else {
-				break
+	g.mu.Lock()
+	writer := g.correctionWriter
+	g.mu.Unlock()
+
+	for {
+		stream, err := g.source.Stream(g.cancelCtx)
+		if err != nil {
+			if g.cancelCtx.Err() == nil {
+				g.err.Set(err)
+				g.setCorrectionsErr(err)
 			}
+			return
 		}
-		if attempts == 5 {
-			return fmt.Errorf("caster %s is down", g.ntripClient.URL)
+		scanner := rtcm3.NewScanner(g.logCorrections(io.TeeReader(stream, writer)))
+		for {
+			msg, err := scanner.NextMessage()
+			if err != nil {
+				if msg == nil {
+					break
+				}
+				continue
+			}
+			if g.correctionLog != nil {
+				g.correctionLog.RecordMessage(msg.Number())
+			}
 		}
-	}
-
-	g.logger.Debug("getting source table")
-
-	srcTable, err := g.ntripClient.ParseSourcetable(g.logger)
-	if err != nil {
-		g.logger.Errorf("failed to get source table: %v", err)
-		return err
-	}
-	g.logger.Debugf("sourceTable is: %v\n", srcTable)
 
-	g.logger.Debug("got sourcetable, parsing it...")
-	g.isVirtualBase, err = gpsutils.HasVRSStream(srcTable, g.ntripClient.MountPoint)
-	if err != nil {
-		g.logger.Errorf("can't find mountpoint in source table, found err %v\n", err)
-		return err
+		select {
+		case <-g.cancelCtx.Done():
+			return
+		default:
+		}
+		g.logger.Debug("No message... reconnecting to the correction source...")
 	}
-
-	return nil
 }
 
-// connectToNTRIP connects to NTRIP stream.
-func (g *rtkSerial) connectToNTRIP() error {
-	select {
-	case <-g.cancelCtx.Done():
-		return errors.New("context canceled")
-	default:
-	}
-	err := g.connectAndParseSourceTable()
-	if err != nil {
-		return err
+// logCorrections returns a reader of the corrections from r which also logs them, if they're being
+// logged.
+func (g *rtkSerial) logCorrections(r io.Reader) io.Reader {
+	if g.correctionLog == nil {
+		return r
 	}
+	return io.TeeReader(r, g.correctionLog)
+}
 
-	err = g.openPort()
-	if err != nil {
-		return err
+// closeCorrectionLog closes the log of the corrections received, if they're being logged.
+func (g *rtkSerial) closeCorrectionLog() error {
+	if g.correctionLog == nil {
+		return nil
 	}
+	return g.correctionLog.Close()
+}
 
-	if g.isVirtualBase {
-		g.logger.Debug("connecting to a Virtual Reference Station")
-		err = g.getNtripFromVRS()
-		if err != nil {
-			return err
-		}
-	} else {
-		g.logger.Debug("connecting to NTRIP stream........")
-		g.writer = bufio.NewWriter(g.correctionWriter)
-		err = g.getStream(g.ntripClient.MountPoint, g.ntripClient.MaxConnectAttempts)
-		if err != nil {
-			return err
-		}
-
-		g.reader = io.TeeReader(g.ntripClient.Stream, g.writer)
+// closePPS stops measuring the clock against the PPS line, if it's being measured.
+func (g *rtkSerial) closePPS() error {
+	if g.pps == nil {
+		return nil
 	}
-
-	return nil
+	return g.pps.Close()
 }
 
-// receiveAndWriteSerial connects to NTRIP receiver and sends correction stream to the MovementSensor through serial.
-func (g *rtkSerial) receiveAndWriteSerial() {
-	defer g.activeBackgroundWorkers.Done()
-	defer g.closePort()
-
-	var scanner rtcm3.Scanner
-
-	if g.isVirtualBase {
-		scanner = rtcm3.NewScanner(g.readerWriter)
-	} else {
-		scanner = rtcm3.NewScanner(g.reader)
+// DoCommand returns the streams in the caster's sourcetable when sent {"get_sourcetable": true}, if
+// the corrections come from an NTRIP caster.
+func (g *rtkSerial) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if ntrip, ok := g.source.(*gpsutils.NtripSource); ok {
+		return ntrip.DoCommand(ctx, cmd)
 	}
+	return nil, resource.ErrDoUnimplemented
+}
 
+func (g *rtkSerial) setCorrectionsErr(err error) {
 	g.mu.Lock()
-	g.isConnectedToNtrip = true
-	g.mu.Unlock()
-
-	// It's okay to skip the mutex on this next line: g.isConnectedToNtrip can only be mutated by this
-	// goroutine itself
-	for g.isConnectedToNtrip && !g.isClosed {
-		select {
-		case <-g.cancelCtx.Done():
-			return
-		default:
-		}
-
-		msg, err := scanner.NextMessage()
-		if err != nil {
-			g.mu.Lock()
-			g.isConnectedToNtrip = false
-			g.mu.Unlock()
-
-			if msg == nil {
-				if g.isClosed {
-					return
-				}
-
-				if g.isVirtualBase {
-					g.logger.Debug("reconnecting to the Virtual Reference Station")
-					err = g.getNtripFromVRS()
-					if err != nil && !errors.Is(err, io.EOF) {
-						g.err.Set(err)
-						return
-					}
-					scanner = rtcm3.NewScanner(g.readerWriter)
-				} else {
-					g.logger.Debug("No message... reconnecting to stream...")
-
-					err = g.getStream(g.ntripClient.MountPoint, g.ntripClient.MaxConnectAttempts)
-					if err != nil {
-						g.err.Set(err)
-						return
-					}
-					g.reader = io.TeeReader(g.ntripClient.Stream, g.writer)
-					scanner = rtcm3.NewScanner(g.reader)
-				}
-
-				g.mu.Lock()
-				g.isConnectedToNtrip = true
-				g.mu.Unlock()
+	defer g.mu.Unlock()
+	g.correctionsErr = err
+}
 
-				continue
-			}
-		}
+// CheckHealth returns an error once the worker writing corrections to the GPS has given up
+// reconnecting to their source, since the GPS can no longer get an RTK fix.
+func (g *rtkSerial) CheckHealth(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.correctionsErr != nil {
+		return fmt.Errorf("stopped receiving corrections: %w", g.correctionsErr)
 	}
+	return nil
 }
 
 // Most of the movementsensor functions here don't have mutex locks since g.cachedData is protected by
@@ -595,6 +574,12 @@ func (g *rtkSerial) Readings(ctx context.Context, extra map[string]interface{})
 
 	readings["fix"] = fix
 	readings["satellites_in_view"] = satsInView
+	if g.correctionLog != nil {
+		readings["corrections"] = g.correctionLog.Readings()
+	}
+	if g.pps != nil {
+		readings["pps"] = g.pps.Readings()
+	}
 
 	return readings, nil
 }
@@ -610,97 +595,36 @@ func (g *rtkSerial) Close(ctx context.Context) error {
 		return err
 	}
 
-	// close ntrip writer
+	// close the port corrections are written to
 	if g.correctionWriter != nil {
 		if err := g.correctionWriter.Close(); err != nil {
-			g.isClosed = true
 			g.mu.Unlock()
 			return err
 		}
 		g.correctionWriter = nil
 	}
 
-	// close ntrip client and stream
-	if g.ntripClient.Client != nil {
-		g.ntripClient.Client.CloseIdleConnections()
-		g.ntripClient.Client = nil
-	}
-
-	if g.ntripClient.Stream != nil {
-		if err := g.ntripClient.Stream.Close(); err != nil {
-			g.mu.Unlock()
-			return err
-		}
-		g.ntripClient.Stream = nil
+	// disconnect from the correction source, ending its stream
+	if err := g.source.Close(); err != nil {
+		g.mu.Unlock()
+		return err
 	}
 
 	g.mu.Unlock()
 	g.activeBackgroundWorkers.Wait()
 
-	if err := g.err.Get(); err != nil && !errors.Is(err, context.Canceled) {
-		return err
-	}
-
-	g.logger.Debug("GPS RTK Serial is closed")
-	return nil
-}
-
-// getNtripFromVRS sends GGA messages to the NTRIP Caster over a TCP connection
-// to get the NTRIP steam when the mount point is a Virtual Reference Station.
-func (g *rtkSerial) getNtripFromVRS() error {
-	g.mu.Lock()
-	defer g.mu.Unlock()
-	var err error
-	g.readerWriter, err = gpsutils.ConnectToVirtualBase(g.ntripClient, g.logger)
-	if err != nil {
-		return err
-	}
-
-	// read from the socket until we know if a successful connection has been
-	// established.
-	for {
-		line, _, err := g.readerWriter.ReadLine()
-		response := string(line)
-		if err != nil {
-			if errors.Is(err, io.EOF) {
-				g.readerWriter = nil
-				return err
-			}
-			g.logger.Error("Failed to read server response:", err)
-			return err
-		}
-
-		if strings.HasPrefix(response, "HTTP/1.1 ") {
-			if strings.Contains(response, "200 OK") {
-				g.logger.Debug("Successful connection established with NTRIP caster.")
-				break
-			}
-			g.logger.Errorf("Bad HTTP response: %v", response)
-			return fmt.Errorf("server responded with non-OK status: %s", response)
-		}
-	}
-
-	ggaMessage, err := gpsutils.GetGGAMessage(g.correctionWriter, g.logger)
-	if err != nil {
-		g.logger.Error("Failed to get GGA message")
+	if err := g.closePPS(); err != nil {
 		return err
 	}
 
-	g.logger.Debugf("Writing GGA message: %v\n", string(ggaMessage))
-
-	_, err = g.readerWriter.WriteString(string(ggaMessage))
-	if err != nil {
-		g.logger.Error("Failed to send NMEA data:", err)
+	if err := g.closeCorrectionLog(); err != nil {
 		return err
 	}
 
-	err = g.readerWriter.Flush()
-	if err != nil {
-		g.logger.Error("failed to write to buffer: ", err)
+	if err := g.err.Get(); err != nil && !errors.Is(err, context.Canceled) {
 		return err
 	}
 
-	g.logger.Debug("GGA message sent successfully.")
-
+	g.logger.Debug("GPS RTK Serial is closed")
 	return nil
 }
@@ -72,6 +72,32 @@ func TestValidateRTK(t *testing.T) {
 		test.That(t, err, test.ShouldBeError,
 			resource.NewConfigValidationFieldRequiredError(path, "serial_path"))
 	})
+
+	t.Run("correction source", func(t *testing.T) {
+		cfg := Config{
+			SerialPath:       path,
+			CorrectionSource: &gpsutils.CorrectionSourceConfig{Type: gpsutils.CorrectionSourceTCP, Address: "192.168.1.10:2101"},
+		}
+		_, err := cfg.Validate(path)
+		test.That(t, err, test.ShouldBeNil)
+
+		cfg.CorrectionSource.Address = ""
+		_, err = cfg.Validate(path)
+		test.That(t, err, test.ShouldBeError,
+			resource.NewConfigValidationFieldRequiredError(path+".correction_source", "address"))
+
+		cfg.CorrectionSource.Address = "192.168.1.10:2101"
+		cfg.NtripURL = "http//fakeurl"
+		_, err = cfg.Validate(path)
+		test.That(t, err, test.ShouldNotBeNil)
+
+		// the GPS depends on a distributor it gets its corrections from
+		cfg.NtripURL = ""
+		cfg.CorrectionSource = &gpsutils.CorrectionSourceConfig{Type: gpsutils.CorrectionSourceDistributor, Distributor: "corrections"}
+		deps, err := cfg.Validate(path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, deps, test.ShouldResemble, []string{"corrections"})
+	})
 }
 
 func TestReconfigure(t *testing.T) {
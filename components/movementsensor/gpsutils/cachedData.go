@@ -4,6 +4,7 @@ import (
 	"context"
 	"math"
 	"sync"
+	"time"
 
 	"github.com/golang/geo/r3"
 	geo "github.com/kellydunn/golang-geo"
@@ -36,6 +37,9 @@ type CachedData struct {
 	dev    DataReader
 	logger logging.Logger
 
+	// timeReceivedAt is when the time in nmeaData was received.
+	timeReceivedAt time.Time
+
 	workers utils.StoppableWorkers
 }
 
@@ -85,7 +89,20 @@ func (g *CachedData) start(cancelCtx context.Context) {
 func (g *CachedData) ParseAndUpdate(line string) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
-	return g.nmeaData.ParseAndUpdate(line)
+	lastTime := g.nmeaData.Time
+	err := g.nmeaData.ParseAndUpdate(line)
+	if !g.nmeaData.Time.Equal(lastTime) {
+		g.timeReceivedAt = time.Now()
+	}
+	return err
+}
+
+// GPSTime returns the latest time the GPS sent, and when it was received, or zero times if it
+// hasn't sent one.
+func (g *CachedData) GPSTime() (time.Time, time.Time) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.nmeaData.Time, g.timeReceivedAt
 }
 
 // Position returns the position and altitide of the sensor, or an error.
@@ -115,6 +132,36 @@ func (g *CachedData) Position(
 	return currentPosition, g.nmeaData.Alt, g.err.Get()
 }
 
+// GGAMessage returns the current position as an NMEA GGA sentence, such as virtual reference
+// stations need to be sent, or an error if there is no fix yet.
+func (g *CachedData) GGAMessage(now time.Time) ([]byte, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	location := g.nmeaData.Location
+	if !hasFix(location) {
+		return nil, errNilLocation
+	}
+	return MakeGGAMessage(
+		location, g.nmeaData.Alt, g.nmeaData.FixQuality, g.nmeaData.SatsInUse, g.nmeaData.HDOP, now), nil
+}
+
+// fixedPosition returns the current position, or an error if there is no fix yet.
+func (g *CachedData) fixedPosition() (*geo.Point, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if !hasFix(g.nmeaData.Location) {
+		return nil, errNilLocation
+	}
+	return g.nmeaData.Location, nil
+}
+
+// hasFix returns whether location is a real position, rather than one reported without a fix.
+func hasFix(location *geo.Point) bool {
+	return location != nil && !movementsensor.IsZeroPosition(location) && !movementsensor.IsPositionNaN(location)
+}
+
 // Accuracy returns the accuracy map, hDOP, vDOP, Fixquality and compass heading error.
 func (g *CachedData) Accuracy(
 	ctx context.Context, extra map[string]interface{},
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"math"
 	"testing"
+	"time"
 
 	geo "github.com/kellydunn/golang-geo"
 	"go.viam.com/test"
@@ -262,3 +263,16 @@ func TestCompassDegreeError(t *testing.T) {
 	cde := g.calculateCompassDegreeError(nil, p2)
 	test.That(t, math.IsNaN(cde), test.ShouldBeTrue)
 }
+
+func TestCachedDataGGAMessage(t *testing.T) {
+	g := NewCachedData(&mockDataReader{}, logging.NewTestLogger(t))
+
+	_, err := g.GGAMessage(time.Now())
+	test.That(t, err, test.ShouldBeError, errNilLocation)
+
+	test.That(t, g.ParseAndUpdate(
+		"$GNGGA,191351.000,4403.4655,N,12118.7950,W,1,6,1.72,1094.5,M,-19.6,M,,*47"), test.ShouldBeNil)
+	gga, err := g.GGAMessage(time.Now())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(gga), test.ShouldContainSubstring, ",4403.46550,N,12118.79500,W,1,06,1.7,1094.5,M,")
+}
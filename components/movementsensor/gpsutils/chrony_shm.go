@@ -0,0 +1,90 @@
+package gpsutils
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"time"
+)
+
+const (
+	// ntpSHMKey is the System V shared memory key of unit 0 of the NTP shared memory refclock,
+	// "NTP0". Unit n is at ntpSHMKey + n.
+	ntpSHMKey = 0x4e545030
+	// ppsPrecision is the log2 of how precise our pulse times are in seconds: about a millisecond,
+	// since they're timestamped in userspace.
+	ppsPrecision = -10
+)
+
+// shmLayout is where the fields of the NTP shared memory refclock's struct shmTime are, which
+// depends on the size of time_t.
+type shmLayout struct {
+	timeSize                                     int
+	clockSec, clockUSec, receiveSec, receiveUSec int
+	leap, precision, nsamples, valid             int
+	clockNSec, receiveNSec                       int
+	size                                         int
+}
+
+// newSHMLayout returns the layout of struct shmTime where time_t is timeSize bytes:
+//
+//	struct shmTime {
+//		int mode; volatile int count;
+//		time_t clockTimeStampSec; int clockTimeStampUSec;
+//		time_t receiveTimeStampSec; int receiveTimeStampUSec;
+//		int leap; int precision; int nsamples; volatile int valid;
+//		unsigned clockTimeStampNSec; unsigned receiveTimeStampNSec;
+//		int dummy[8];
+//	};
+func newSHMLayout(timeSize int) shmLayout {
+	align := func(offset int) int { return (offset + timeSize - 1) / timeSize * timeSize }
+	l := shmLayout{timeSize: timeSize}
+	l.clockSec = 8
+	l.clockUSec = l.clockSec + timeSize
+	l.receiveSec = align(l.clockUSec + 4)
+	l.receiveUSec = l.receiveSec + timeSize
+	l.leap = l.receiveUSec + 4
+	l.precision = l.leap + 4
+	l.nsamples = l.precision + 4
+	l.valid = l.nsamples + 4
+	l.clockNSec = l.valid + 4
+	l.receiveNSec = l.clockNSec + 4
+	l.size = align(l.receiveNSec + 4 + 8*4)
+	return l
+}
+
+// nativeSHMLayout is the layout on this system, where time_t is the size of a word.
+var nativeSHMLayout = newSHMLayout(bits.UintSize / 8)
+
+// writeSHMSample writes a sample to mem, which holds a struct shmTime, in mode 1: readers check the
+// count is the same before and after reading, so they don't use a half written sample.
+func writeSHMSample(mem []byte, l shmLayout, clockTime, receiveTime time.Time) {
+	order := binary.NativeEndian
+	putTime := func(offset int, seconds int64) {
+		if l.timeSize == 8 {
+			order.PutUint64(mem[offset:], uint64(seconds))
+		} else {
+			order.PutUint32(mem[offset:], uint32(seconds))
+		}
+	}
+	putInt := func(offset, value int) {
+		order.PutUint32(mem[offset:], uint32(int32(value)))
+	}
+
+	putInt(0, 1) // mode
+	putInt(l.valid, 0)
+	count := order.Uint32(mem[4:])
+	order.PutUint32(mem[4:], count+1)
+
+	putTime(l.clockSec, clockTime.Unix())
+	putInt(l.clockUSec, clockTime.Nanosecond()/1000)
+	putInt(l.clockNSec, clockTime.Nanosecond())
+	putTime(l.receiveSec, receiveTime.Unix())
+	putInt(l.receiveUSec, receiveTime.Nanosecond()/1000)
+	putInt(l.receiveNSec, receiveTime.Nanosecond())
+	putInt(l.leap, 0)
+	putInt(l.precision, ppsPrecision)
+	putInt(l.nsamples, 3)
+
+	order.PutUint32(mem[4:], count+2)
+	putInt(l.valid, 1)
+}
@@ -0,0 +1,42 @@
+//go:build linux
+
+package gpsutils
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// chronySHM is a unit of the NTP shared memory refclock, which chrony reads samples of the time
+// from.
+type chronySHM struct {
+	mem []byte
+}
+
+// openChronySHM opens the given unit of the shared memory refclock, creating it if chrony hasn't.
+// Units 0 and 1 can only be used by root, as chrony expects.
+func openChronySHM(unit int) (*chronySHM, error) {
+	perm := 0o666
+	if unit < 2 {
+		perm = 0o600
+	}
+	id, err := unix.SysvShmGet(ntpSHMKey+unit, nativeSHMLayout.size, unix.IPC_CREAT|perm)
+	if err != nil {
+		return nil, err
+	}
+	mem, err := unix.SysvShmAttach(id, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &chronySHM{mem: mem}, nil
+}
+
+// write gives chrony a sample: the GPS time at a pulse, and the system time the pulse arrived.
+func (s *chronySHM) write(clockTime, receiveTime time.Time) {
+	writeSHMSample(s.mem, nativeSHMLayout, clockTime, receiveTime)
+}
+
+func (s *chronySHM) close() error {
+	return unix.SysvShmDetach(s.mem)
+}
@@ -0,0 +1,21 @@
+//go:build !linux
+
+package gpsutils
+
+import (
+	"errors"
+	"time"
+)
+
+// chronySHM is a unit of the NTP shared memory refclock, which is only supported on Linux.
+type chronySHM struct{}
+
+func openChronySHM(unit int) (*chronySHM, error) {
+	return nil, errors.New("giving chrony the time is only supported on Linux")
+}
+
+func (s *chronySHM) write(clockTime, receiveTime time.Time) {}
+
+func (s *chronySHM) close() error {
+	return nil
+}
@@ -3,13 +3,21 @@
 package gpsutils
 
 import (
+	"fmt"
+
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils/serialport"
 )
 
 // SerialConfig is used for converting Serial NMEA MovementSensor config attributes.
 type SerialConfig struct {
 	SerialPath     string `json:"serial_path"`
 	SerialBaudRate int    `json:"serial_baud_rate,omitempty"`
+	// SerialUSB finds the serial port by its USB IDs instead of serial_path, which USB serial
+	// adapters don't keep across being unplugged or reset.
+	SerialUSB *serialport.USBMatch `json:"serial_usb,omitempty"`
+	// Receiver, if set, is sent to the receiver when the port is opened.
+	Receiver *ReceiverConfig `json:"receiver,omitempty"`
 
 	// TestChan is a fake "serial" path for test use only
 	TestChan chan []uint8 `json:"-"`
@@ -20,6 +28,8 @@ type I2CConfig struct {
 	I2CBus      string `json:"i2c_bus"`
 	I2CAddr     int    `json:"i2c_addr"`
 	I2CBaudRate int    `json:"i2c_baud_rate,omitempty"`
+	// Receiver configures the receiver, which takes PMTK commands unless it says otherwise.
+	Receiver *ReceiverConfig `json:"receiver,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -30,13 +40,24 @@ func (cfg *I2CConfig) Validate(path string) error {
 	if cfg.I2CAddr == 0 {
 		return resource.NewConfigValidationFieldRequiredError(path, "i2c_addr")
 	}
+	if cfg.Receiver != nil {
+		return cfg.Receiver.Validate(fmt.Sprintf("%s.%s", path, "receiver"), ProtocolPMTK)
+	}
 	return nil
 }
 
 // Validate ensures all parts of the config are valid.
 func (cfg *SerialConfig) Validate(path string) error {
-	if cfg.SerialPath == "" {
+	if cfg.SerialUSB != nil {
+		if err := cfg.SerialUSB.Validate(fmt.Sprintf("%s.%s", path, "serial_usb")); err != nil {
+			return err
+		}
+	} else if cfg.SerialPath == "" {
 		return resource.NewConfigValidationFieldRequiredError(path, "serial_path")
 	}
+	if cfg.Receiver != nil {
+		// Receivers on serial ports could take either protocol, so it must be given.
+		return cfg.Receiver.Validate(fmt.Sprintf("%s.%s", path, "receiver"), "")
+	}
 	return nil
 }
@@ -6,6 +6,7 @@ import (
 	"go.viam.com/test"
 
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils/serialport"
 )
 
 func TestValidateSerial(t *testing.T) {
@@ -17,6 +18,24 @@ func TestValidateSerial(t *testing.T) {
 	fakecfg.SerialPath = "some-path"
 	err = fakecfg.Validate(path)
 	test.That(t, err, test.ShouldBeNil)
+
+	fakecfg = &SerialConfig{SerialUSB: &serialport.USBMatch{}}
+	err = fakecfg.Validate(path)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "vendor_id")
+
+	fakecfg.SerialUSB.VendorID = "1546"
+	err = fakecfg.Validate(path)
+	test.That(t, err, test.ShouldBeNil)
+
+	// receivers on serial ports could take either protocol
+	fakecfg.Receiver = &ReceiverConfig{UpdateRateHz: 5}
+	err = fakecfg.Validate(path)
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path.receiver", "protocol"))
+
+	fakecfg.Receiver.Protocol = ProtocolUBX
+	err = fakecfg.Validate(path)
+	test.That(t, err, test.ShouldBeNil)
 }
 
 func TestValidateI2C(t *testing.T) {
@@ -30,4 +49,9 @@ func TestValidateI2C(t *testing.T) {
 	fakecfg.I2CAddr = 66
 	err = fakecfg.Validate(path)
 	test.That(t, err, test.ShouldBeNil)
+
+	// I2C receivers take PMTK commands unless told otherwise
+	fakecfg.Receiver = &ReceiverConfig{UpdateRateHz: 5}
+	err = fakecfg.Validate(path)
+	test.That(t, err, test.ShouldBeNil)
 }
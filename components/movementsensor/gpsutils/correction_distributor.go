@@ -0,0 +1,245 @@
+package gpsutils
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils"
+)
+
+const (
+	// distributorChunkSize is the most read from the source at once, which is about the size of the
+	// largest RTCM message.
+	distributorChunkSize = 1100
+	// subscriptionBufferChunks is how many chunks a receiver can fall behind by before they're
+	// dropped for it.
+	subscriptionBufferChunks = 64
+)
+
+// CorrectionPublisher is a resource which shares one source of corrections among several RTK GPSes,
+// such as the rtk-correction-distributor service.
+type CorrectionPublisher interface {
+	resource.Resource
+	// SubscribeCorrections returns a source of everything the shared source sends from now on.
+	SubscribeCorrections() CorrectionSource
+}
+
+// CorrectionDistributor reads corrections from one source and copies them to each of its
+// subscribers, so that the GPSes on a robot with several can share one connection to a caster. A
+// subscriber which falls behind has corrections dropped rather than holding up the others.
+type CorrectionDistributor struct {
+	source  CorrectionSource
+	logger  logging.Logger
+	workers utils.StoppableWorkers
+
+	mu            sync.Mutex
+	subscriptions map[*subscription]struct{}
+	bytes         int64
+	dropped       int
+	err           error
+	closed        bool
+}
+
+// NewCorrectionDistributor starts reading corrections from source, which it closes when closed.
+func NewCorrectionDistributor(source CorrectionSource, logger logging.Logger) *CorrectionDistributor {
+	d := &CorrectionDistributor{
+		source:        source,
+		logger:        logger,
+		subscriptions: map[*subscription]struct{}{},
+	}
+	d.workers = utils.NewStoppableWorkers(d.distribute)
+	return d
+}
+
+// distribute reads from the source until it gives up or the distributor is closed, reconnecting
+// whenever a stream ends.
+func (d *CorrectionDistributor) distribute(ctx context.Context) {
+	buf := make([]byte, distributorChunkSize)
+	for {
+		stream, err := d.source.Stream(ctx)
+		if err != nil {
+			if ctx.Err() == nil && !d.isClosed() {
+				d.logger.CErrorf(ctx, "stopped receiving corrections: %s", err)
+				d.fail(err)
+			}
+			return
+		}
+
+		for {
+			n, err := stream.Read(buf)
+			if n > 0 {
+				d.publish(buf[:n])
+			}
+			if err != nil {
+				if ctx.Err() != nil || d.isClosed() {
+					return
+				}
+				d.logger.CDebugf(ctx, "correction stream ended, reconnecting: %s", err)
+				break
+			}
+		}
+	}
+}
+
+// publish sends a copy of chunk to each subscriber with room for it.
+func (d *CorrectionDistributor) publish(chunk []byte) {
+	chunk = append([]byte(nil), chunk...)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.bytes += int64(len(chunk))
+	for s := range d.subscriptions {
+		select {
+		case s.chunks <- chunk:
+		default:
+			d.dropped++
+		}
+	}
+}
+
+// fail ends every subscription with err, which new ones get too.
+func (d *CorrectionDistributor) fail(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.err = err
+	for s := range d.subscriptions {
+		s.end()
+		delete(d.subscriptions, s)
+	}
+}
+
+func (d *CorrectionDistributor) isClosed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closed
+}
+
+// SubscribeCorrections returns a source of everything the distributor reads from now on. Closing
+// it ends the subscription, but leaves the distributor running.
+func (d *CorrectionDistributor) SubscribeCorrections() CorrectionSource {
+	s := &subscription{
+		distributor: d,
+		chunks:      make(chan []byte, subscriptionBufferChunks),
+		done:        make(chan struct{}),
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed || d.err != nil {
+		s.end()
+	} else {
+		d.subscriptions[s] = struct{}{}
+	}
+	return s
+}
+
+// unsubscribe stops sending corrections to s.
+func (d *CorrectionDistributor) unsubscribe(s *subscription) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.subscriptions, s)
+	s.end()
+}
+
+// endErr returns why subscriptions have ended.
+func (d *CorrectionDistributor) endErr() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.err != nil {
+		return d.err
+	}
+	return errCorrectionSourceClosed
+}
+
+// Stats returns how many subscribers there are, how many bytes of corrections have been read, and
+// how many chunks of them were dropped for subscribers which fell behind.
+func (d *CorrectionDistributor) Stats() map[string]interface{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	stats := map[string]interface{}{
+		"subscribers":    len(d.subscriptions),
+		"bytes":          d.bytes,
+		"dropped_chunks": d.dropped,
+	}
+	if d.err != nil {
+		stats["error"] = d.err.Error()
+	}
+	return stats
+}
+
+// Close ends every subscription, and closes the source.
+func (d *CorrectionDistributor) Close() error {
+	d.mu.Lock()
+	d.closed = true
+	for s := range d.subscriptions {
+		s.end()
+		delete(d.subscriptions, s)
+	}
+	d.mu.Unlock()
+
+	// closing the source first ends any read from it, so the worker can stop
+	err := d.source.Close()
+	d.workers.Stop()
+	return err
+}
+
+// subscription is one subscriber's share of a distributor's corrections.
+type subscription struct {
+	distributor *CorrectionDistributor
+	chunks      chan []byte
+	done        chan struct{}
+	endOnce     sync.Once
+}
+
+// end ends the subscription.
+func (s *subscription) end() {
+	s.endOnce.Do(func() { close(s.done) })
+}
+
+// Stream returns the corrections sent to the subscription. The stream only ends when the
+// subscription does.
+func (s *subscription) Stream(ctx context.Context) (io.Reader, error) {
+	select {
+	case <-s.done:
+		return nil, s.distributor.endErr()
+	default:
+	}
+	return &subscriptionStream{sub: s, ctx: ctx}, nil
+}
+
+func (s *subscription) Close() error {
+	s.distributor.unsubscribe(s)
+	return nil
+}
+
+// subscriptionStream reads a subscription's chunks of corrections.
+type subscriptionStream struct {
+	sub     *subscription
+	ctx     context.Context
+	pending []byte
+}
+
+func (s *subscriptionStream) Read(p []byte) (int, error) {
+	// whatever was still to be read is dropped once the subscription ends
+	select {
+	case <-s.sub.done:
+		return 0, s.sub.distributor.endErr()
+	default:
+	}
+
+	if len(s.pending) == 0 {
+		select {
+		case <-s.ctx.Done():
+			return 0, s.ctx.Err()
+		case <-s.sub.done:
+			return 0, s.sub.distributor.endErr()
+		case s.pending = <-s.sub.chunks:
+		}
+	}
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
@@ -0,0 +1,110 @@
+package gpsutils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/logging"
+)
+
+var errGaveUp = errors.New("gave up")
+
+// fakeCorrectionSource streams whatever is sent on streams, and gives up once it's closed.
+type fakeCorrectionSource struct {
+	streams chan io.Reader
+	closed  chan struct{}
+}
+
+func (s *fakeCorrectionSource) Stream(ctx context.Context) (io.Reader, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.closed:
+		return nil, errCorrectionSourceClosed
+	case r, ok := <-s.streams:
+		if !ok {
+			return nil, errGaveUp
+		}
+		return r, nil
+	}
+}
+
+func (s *fakeCorrectionSource) Close() error {
+	close(s.closed)
+	return nil
+}
+
+func TestCorrectionDistributor(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	source := &fakeCorrectionSource{streams: make(chan io.Reader), closed: make(chan struct{})}
+	d := NewCorrectionDistributor(source, logger)
+
+	a, b := d.SubscribeCorrections(), d.SubscribeCorrections()
+	streamA, err := a.Stream(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	streamB, err := b.Stream(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, d.Stats()["subscribers"], test.ShouldEqual, 2)
+
+	// everything read once goes to every subscriber
+	r, w := io.Pipe()
+	source.streams <- r
+	_, err = w.Write([]byte("rtcm"))
+	test.That(t, err, test.ShouldBeNil)
+	for _, stream := range []io.Reader{streamA, streamB} {
+		data := make([]byte, 4)
+		_, err = io.ReadFull(stream, data)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, string(data), test.ShouldEqual, "rtcm")
+	}
+
+	// a subscriber which leaves gets nothing more
+	test.That(t, b.Close(), test.ShouldBeNil)
+	_, err = streamB.Read(make([]byte, 4))
+	test.That(t, err, test.ShouldBeError, errCorrectionSourceClosed)
+
+	// and one which falls behind has what doesn't fit dropped
+	for i := 0; i <= subscriptionBufferChunks; i++ {
+		_, err = w.Write([]byte("rtcm"))
+		test.That(t, err, test.ShouldBeNil)
+	}
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, d.Stats()["dropped_chunks"], test.ShouldEqual, 1)
+	})
+	test.That(t, d.Stats()["bytes"], test.ShouldEqual, int64(4*(subscriptionBufferChunks+2)))
+
+	// when the stream ends the distributor reconnects, and once the source gives up, so do the
+	// subscribers
+	test.That(t, w.Close(), test.ShouldBeNil)
+	close(source.streams)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, d.Stats()["error"], test.ShouldEqual, errGaveUp.Error())
+	})
+	_, err = streamA.Read(make([]byte, 4))
+	test.That(t, err, test.ShouldBeError, errGaveUp)
+	_, err = d.SubscribeCorrections().Stream(context.Background())
+	test.That(t, err, test.ShouldBeError, errGaveUp)
+
+	test.That(t, d.Close(), test.ShouldBeNil)
+}
+
+func TestCorrectionDistributorClose(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	source := &fakeCorrectionSource{streams: make(chan io.Reader), closed: make(chan struct{})}
+	d := NewCorrectionDistributor(source, logger)
+
+	stream, err := d.SubscribeCorrections().Stream(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, d.Close(), test.ShouldBeNil)
+
+	_, err = stream.Read(make([]byte, 4))
+	test.That(t, err, test.ShouldBeError, errCorrectionSourceClosed)
+	test.That(t, d.Stats()["error"], test.ShouldBeNil)
+}
@@ -0,0 +1,194 @@
+package gpsutils
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"go.viam.com/rdk/logging"
+)
+
+// correctionLogWindow is how far back the statistics in a CorrectionLog's readings and summaries go.
+const correctionLogWindow = time.Minute
+
+// CorrectionLogConfig configures what an RTK movement sensor records about the corrections it
+// receives from its caster.
+type CorrectionLogConfig struct {
+	// LogCorrections logs a summary of the RTCM messages received every minute.
+	LogCorrections bool
+	// CorrectionsDumpPath, if set, is a file to write the raw correction stream to.
+	CorrectionsDumpPath string
+}
+
+// correctionEvent is something received from the caster: either a message of the given type, or
+// (when msgType is 0) some bytes of the raw stream.
+type correctionEvent struct {
+	at      time.Time
+	msgType int
+	bytes   int
+}
+
+// CorrectionLog records the RTCM corrections received from a caster, so that users can check it
+// is sending messages their receiver can use. It keeps statistics on the messages received over
+// the last minute, and can log a summary of them and dump the raw stream to a file.
+type CorrectionLog struct {
+	logger       logging.Logger
+	clock        clock.Clock
+	logSummaries bool
+
+	mu            sync.Mutex
+	dump          *os.File
+	dumpFailed    bool
+	events        []correctionEvent
+	totalMessages int
+	totalBytes    int
+	lastMessageAt time.Time
+	lastSummary   time.Time
+}
+
+// NewCorrectionLog returns a CorrectionLog as configured, or nil if the config asks for nothing to
+// be recorded.
+func NewCorrectionLog(cfg CorrectionLogConfig, logger logging.Logger) (*CorrectionLog, error) {
+	if !cfg.LogCorrections && cfg.CorrectionsDumpPath == "" {
+		return nil, nil
+	}
+	l := &CorrectionLog{logger: logger, clock: clock.New(), logSummaries: cfg.LogCorrections}
+	if cfg.CorrectionsDumpPath != "" {
+		dump, err := os.Create(cfg.CorrectionsDumpPath)
+		if err != nil {
+			return nil, err
+		}
+		l.dump = dump
+		logger.Infof("dumping the raw correction stream to %s", cfg.CorrectionsDumpPath)
+	}
+	return l, nil
+}
+
+// Write records bytes of the raw correction stream. It never fails, so that it can be teed off the
+// stream without interrupting it.
+func (l *CorrectionLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	l.totalBytes += len(p)
+	l.events = append(l.events, correctionEvent{at: now, bytes: len(p)})
+	l.expire(now)
+	if l.dump != nil {
+		if _, err := l.dump.Write(p); err != nil && !l.dumpFailed {
+			// only say so once, rather than for every chunk of the stream
+			l.logger.Warnf("failed to dump corrections: %s", err)
+			l.dumpFailed = true
+		}
+	}
+	return len(p), nil
+}
+
+// RecordMessage records that an RTCM message of the given type was received.
+func (l *CorrectionLog) RecordMessage(msgType int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	l.totalMessages++
+	l.lastMessageAt = now
+	l.events = append(l.events, correctionEvent{at: now, msgType: msgType})
+	l.expire(now)
+
+	if l.lastSummary.IsZero() {
+		l.lastSummary = now
+	}
+	if l.logSummaries && now.Sub(l.lastSummary) >= correctionLogWindow {
+		l.lastSummary = now
+		l.logger.Infof("corrections received in the last minute: %s", l.summary())
+	}
+}
+
+// expire forgets the events from before the window.
+func (l *CorrectionLog) expire(now time.Time) {
+	cutoff := now.Add(-correctionLogWindow)
+	i := sort.Search(len(l.events), func(i int) bool { return l.events[i].at.After(cutoff) })
+	l.events = l.events[i:]
+}
+
+// typeCounts returns how many of each type of message were received within the window, and how many
+// bytes were.
+func (l *CorrectionLog) typeCounts() (map[int]int, int) {
+	counts := map[int]int{}
+	bytes := 0
+	for _, event := range l.events {
+		if event.msgType == 0 {
+			bytes += event.bytes
+		} else {
+			counts[event.msgType]++
+		}
+	}
+	return counts, bytes
+}
+
+// summary describes the messages received within the window, such as "1005: 12, 1077: 60 (4.2 kB)".
+func (l *CorrectionLog) summary() string {
+	counts, bytes := l.typeCounts()
+	if len(counts) == 0 {
+		return fmt.Sprintf("no messages (%d bytes)", bytes)
+	}
+	types := make([]int, 0, len(counts))
+	for msgType := range counts {
+		types = append(types, msgType)
+	}
+	sort.Ints(types)
+	parts := make([]string, 0, len(types))
+	for _, msgType := range types {
+		parts = append(parts, fmt.Sprintf("%d: %d", msgType, counts[msgType]))
+	}
+	return fmt.Sprintf("%s (%.1f kB)", strings.Join(parts, ", "), float64(bytes)/1000)
+}
+
+// Readings summarizes the corrections received over the last minute, and in total.
+func (l *CorrectionLog) Readings() map[string]interface{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	l.expire(now)
+	counts, bytes := l.typeCounts()
+	windowSec := correctionLogWindow.Seconds()
+
+	types := map[string]interface{}{}
+	for msgType, count := range counts {
+		types[strconv.Itoa(msgType)] = map[string]interface{}{
+			"count":   count,
+			"rate_hz": float64(count) / windowSec,
+		}
+	}
+	readings := map[string]interface{}{
+		"window_sec":     windowSec,
+		"message_types":  types,
+		"bytes":          bytes,
+		"bytes_per_sec":  float64(bytes) / windowSec,
+		"total_messages": l.totalMessages,
+		"total_bytes":    l.totalBytes,
+	}
+	if !l.lastMessageAt.IsZero() {
+		readings["last_message_age_sec"] = now.Sub(l.lastMessageAt).Seconds()
+	}
+	return readings
+}
+
+// Close closes the dump file, if there is one.
+func (l *CorrectionLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.dump == nil {
+		return nil
+	}
+	err := l.dump.Close()
+	l.dump = nil
+	return err
+}
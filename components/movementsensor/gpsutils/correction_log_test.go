@@ -0,0 +1,67 @@
+package gpsutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+)
+
+func TestNewCorrectionLogDisabled(t *testing.T) {
+	l, err := NewCorrectionLog(CorrectionLogConfig{}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, l, test.ShouldBeNil)
+}
+
+func TestCorrectionLogReadings(t *testing.T) {
+	logger, logs := logging.NewObservedTestLogger(t)
+	dumpPath := filepath.Join(t.TempDir(), "corrections.rtcm")
+	l, err := NewCorrectionLog(CorrectionLogConfig{LogCorrections: true, CorrectionsDumpPath: dumpPath}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	mockClock := clock.NewMock()
+	l.clock = mockClock
+
+	for i := 0; i < 30; i++ {
+		n, err := l.Write(make([]byte, 100))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, n, test.ShouldEqual, 100)
+		l.RecordMessage(1077)
+		if i%10 == 0 {
+			l.RecordMessage(1005)
+		}
+		mockClock.Add(time.Second)
+	}
+
+	readings := l.Readings()
+	test.That(t, readings["total_messages"], test.ShouldEqual, 33)
+	test.That(t, readings["total_bytes"], test.ShouldEqual, 3000)
+	test.That(t, readings["bytes"], test.ShouldEqual, 3000)
+	test.That(t, readings["bytes_per_sec"], test.ShouldAlmostEqual, 50)
+	test.That(t, readings["last_message_age_sec"], test.ShouldAlmostEqual, 1)
+	types := readings["message_types"].(map[string]interface{})
+	test.That(t, types["1077"], test.ShouldResemble, map[string]interface{}{"count": 30, "rate_hz": 0.5})
+	test.That(t, types["1005"], test.ShouldResemble, map[string]interface{}{"count": 3, "rate_hz": 0.05})
+
+	// a minute on, the messages from the start have left the window
+	for i := 0; i < 35; i++ {
+		l.RecordMessage(1077)
+		mockClock.Add(time.Second)
+	}
+	readings = l.Readings()
+	test.That(t, readings["total_messages"], test.ShouldEqual, 68)
+	test.That(t, readings["bytes"], test.ShouldEqual, 2400)
+	types = readings["message_types"].(map[string]interface{})
+	test.That(t, types["1077"].(map[string]interface{})["count"], test.ShouldEqual, 59)
+	test.That(t, types["1005"].(map[string]interface{})["count"], test.ShouldEqual, 2)
+	test.That(t, logs.FilterMessageSnippet("corrections received in the last minute").Len(), test.ShouldEqual, 1)
+
+	test.That(t, l.Close(), test.ShouldBeNil)
+	dumped, err := os.ReadFile(dumpPath)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(dumped), test.ShouldEqual, 3000)
+}
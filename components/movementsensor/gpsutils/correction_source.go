@@ -0,0 +1,352 @@
+// Package gpsutils contains GPS-related code shared between multiple components. This file is
+// about where the RTCM corrections for RTK GPSes come from.
+package gpsutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/utils/serialport"
+)
+
+// The types of correction source besides NTRIP, which is used when no other is configured.
+const (
+	// CorrectionSourceTCP reads raw RTCM from a TCP server, such as a base station's.
+	CorrectionSourceTCP = "tcp"
+	// CorrectionSourceSerial reads raw RTCM from a serial device, such as a telemetry radio
+	// receiving it from a base station.
+	CorrectionSourceSerial = "serial"
+	// CorrectionSourceFile replays RTCM from a file, such as one written with corrections_dump_path.
+	CorrectionSourceFile = "file"
+	// CorrectionSourceDistributor shares the corrections a CorrectionPublisher, such as the
+	// rtk-correction-distributor service, reads once for several GPSes.
+	CorrectionSourceDistributor = "distributor"
+)
+
+const (
+	defaultCorrectionConnectAttempts = 10
+	// defaultRadioBaudRate is what most telemetry radios run at out of the box.
+	defaultRadioBaudRate = 57600
+	// defaultReplayBytesPerSec is about the rate a base station sends corrections at.
+	defaultReplayBytesPerSec = 500
+)
+
+var errCorrectionSourceClosed = errors.New("correction source is closed")
+
+// CorrectionSource is where an RTK GPS's RTCM corrections come from.
+type CorrectionSource interface {
+	// Stream returns the stream of corrections, connecting to the source first if need be. Once a
+	// stream ends, Stream may be called again to reconnect. An error means the source has given
+	// up.
+	Stream(ctx context.Context) (io.Reader, error)
+	// Close disconnects from the source, ending any stream.
+	Close() error
+}
+
+// CorrectionSourceConfig is used for converting attributes for a correction source other than an
+// NTRIP caster.
+type CorrectionSourceConfig struct {
+	Type string `json:"type"`
+
+	// Address is the host and port of the TCP server.
+	Address         string `json:"address,omitempty"`
+	ConnectAttempts int    `json:"connect_attempts,omitempty"`
+
+	SerialPath     string               `json:"serial_path,omitempty"`
+	SerialBaudRate int                  `json:"serial_baud_rate,omitempty"`
+	SerialUSB      *serialport.USBMatch `json:"serial_usb,omitempty"`
+
+	FilePath string `json:"file_path,omitempty"`
+	// FileBytesPerSec is how fast to replay the file.
+	FileBytesPerSec int `json:"file_bytes_per_sec,omitempty"`
+
+	// Distributor is the name of the service to get the corrections from.
+	Distributor string `json:"distributor,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid, and returns the resources the source depends
+// on.
+func (cfg *CorrectionSourceConfig) Validate(path string) ([]string, error) {
+	switch cfg.Type {
+	case "":
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "type")
+	case CorrectionSourceTCP:
+		if cfg.Address == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(path, "address")
+		}
+		if _, _, err := net.SplitHostPort(cfg.Address); err != nil {
+			return nil, resource.NewConfigValidationError(path, fmt.Errorf("invalid address: %w", err))
+		}
+	case CorrectionSourceSerial:
+		if cfg.SerialUSB != nil {
+			return nil, cfg.SerialUSB.Validate(fmt.Sprintf("%s.%s", path, "serial_usb"))
+		}
+		if cfg.SerialPath == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(path, "serial_path")
+		}
+	case CorrectionSourceFile:
+		if cfg.FilePath == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(path, "file_path")
+		}
+		if cfg.FileBytesPerSec < 0 {
+			return nil, resource.NewConfigValidationError(path, errors.New("file_bytes_per_sec can't be negative"))
+		}
+	case CorrectionSourceDistributor:
+		if cfg.Distributor == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(path, "distributor")
+		}
+		return []string{cfg.Distributor}, nil
+	default:
+		return nil, resource.NewConfigValidationError(path, fmt.Errorf("type %q is not one of %q, %q, %q or %q",
+			cfg.Type, CorrectionSourceTCP, CorrectionSourceSerial, CorrectionSourceFile, CorrectionSourceDistributor))
+	}
+	return nil, nil
+}
+
+// NewCorrectionSource returns the correction source cfg describes, which may be one of deps.
+func NewCorrectionSource(
+	cfg *CorrectionSourceConfig, deps resource.Dependencies, logger logging.Logger,
+) (CorrectionSource, error) {
+	switch cfg.Type {
+	case CorrectionSourceTCP:
+		attempts := cfg.ConnectAttempts
+		if attempts == 0 {
+			attempts = defaultCorrectionConnectAttempts
+		}
+		return &tcpSource{address: cfg.Address, attempts: attempts, clock: clock.New(), logger: logger}, nil
+	case CorrectionSourceSerial:
+		baudRate := cfg.SerialBaudRate
+		if baudRate == 0 {
+			baudRate = defaultRadioBaudRate
+			logger.Infof("correction source serial_baud_rate using default %d", defaultRadioBaudRate)
+		}
+		return &serialSource{
+			opts: serialport.OpenOptions{
+				Path:            cfg.SerialPath,
+				USB:             cfg.SerialUSB,
+				BaudRate:        uint(baudRate),
+				MinimumReadSize: 1,
+			},
+			logger: logger,
+		}, nil
+	case CorrectionSourceFile:
+		bytesPerSec := cfg.FileBytesPerSec
+		if bytesPerSec == 0 {
+			bytesPerSec = defaultReplayBytesPerSec
+		}
+		if _, err := os.Stat(cfg.FilePath); err != nil {
+			return nil, err
+		}
+		return &fileSource{
+			path:        cfg.FilePath,
+			bytesPerSec: bytesPerSec,
+			clock:       clock.New(),
+			logger:      logger,
+			closed:      make(chan struct{}),
+		}, nil
+	case CorrectionSourceDistributor:
+		publisher, err := resource.FromDependencies[CorrectionPublisher](deps, generic.Named(cfg.Distributor))
+		if err != nil {
+			return nil, err
+		}
+		return publisher.SubscribeCorrections(), nil
+	default:
+		return nil, fmt.Errorf("unknown correction source type %q", cfg.Type)
+	}
+}
+
+// tcpSource reads corrections from a TCP server which sends them as soon as it's connected to.
+type tcpSource struct {
+	address  string
+	attempts int
+	clock    clock.Clock
+	logger   logging.Logger
+
+	mu     sync.Mutex
+	conn   net.Conn
+	closed bool
+}
+
+// Stream connects to the server, replacing any connection from before. We give up after
+// attempts unsuccessful tries.
+func (s *tcpSource) Stream(ctx context.Context) (io.Reader, error) {
+	var dialer net.Dialer
+	var conn net.Conn
+	var err error
+	for attempt := 0; attempt < s.attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-s.clock.After(ntripRetryInterval):
+			}
+		}
+		if conn, err = dialer.DialContext(ctx, "tcp", s.address); err == nil {
+			break
+		}
+		s.logger.CDebugf(ctx, "can't connect to correction server %s: %s", s.address, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't connect to correction server %s: %w", s.address, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, errors.Join(errCorrectionSourceClosed, conn.Close())
+	}
+	if s.conn != nil {
+		if err := s.conn.Close(); err != nil {
+			s.logger.CDebugf(ctx, "failed to close the previous connection to %s: %s", s.address, err)
+		}
+	}
+	s.conn = conn
+	s.logger.CInfof(ctx, "connected to correction server %s", s.address)
+	return conn, nil
+}
+
+func (s *tcpSource) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// serialSource reads corrections from a serial device. The port reconnects to the device by itself
+// if it goes away, so one stream lasts until the source is closed.
+type serialSource struct {
+	opts   serialport.OpenOptions
+	logger logging.Logger
+
+	mu     sync.Mutex
+	port   *serialport.Port
+	closed bool
+}
+
+func (s *serialSource) Stream(ctx context.Context) (io.Reader, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, errCorrectionSourceClosed
+	}
+	if s.port != nil {
+		return s.port, nil
+	}
+	port, err := serialport.Open(s.opts, s.logger)
+	if err != nil {
+		return nil, err
+	}
+	s.port = port
+	return port, nil
+}
+
+func (s *serialSource) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.port == nil {
+		return nil
+	}
+	return s.port.Close()
+}
+
+// fileSource replays corrections from a file, starting again from the beginning each time it ends.
+// The corrections are as old as the file, so this is for testing how corrections are handled,
+// rather than for getting an RTK fix.
+type fileSource struct {
+	path        string
+	bytesPerSec int
+	clock       clock.Clock
+	logger      logging.Logger
+
+	mu        sync.Mutex
+	file      *os.File
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// Stream opens the file from the start, closing it first if it was open.
+func (s *fileSource) Stream(ctx context.Context) (io.Reader, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-s.closed:
+		return nil, errCorrectionSourceClosed
+	default:
+	}
+
+	if s.file != nil {
+		if err := s.file.Close(); err != nil {
+			s.logger.CDebugf(ctx, "failed to close %s: %s", s.path, err)
+		}
+	}
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	s.file = file
+	s.logger.CDebugf(ctx, "replaying corrections from %s", s.path)
+	return &pacedReader{
+		r:           file,
+		bytesPerSec: s.bytesPerSec,
+		clock:       s.clock,
+		start:       s.clock.Now(),
+		done:        s.closed,
+	}, nil
+}
+
+func (s *fileSource) Close() error {
+	s.closeOnce.Do(func() { close(s.closed) })
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// pacedReader reads from r no faster than bytesPerSec, until done is closed.
+type pacedReader struct {
+	r           io.Reader
+	bytesPerSec int
+	clock       clock.Clock
+	start       time.Time
+	read        int
+	done        <-chan struct{}
+}
+
+func (r *pacedReader) Read(p []byte) (int, error) {
+	// read at most a second's worth at a time, so it comes out steadily
+	if len(p) > r.bytesPerSec {
+		p = p[:r.bytesPerSec]
+	}
+	due := r.start.Add(time.Duration(r.read) * time.Second / time.Duration(r.bytesPerSec))
+	if wait := due.Sub(r.clock.Now()); wait > 0 {
+		select {
+		case <-r.done:
+			return 0, errCorrectionSourceClosed
+		case <-r.clock.After(wait):
+		}
+	}
+	n, err := r.r.Read(p)
+	r.read += n
+	return n, err
+}
@@ -0,0 +1,213 @@
+package gpsutils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+func TestCorrectionSourceConfigValidate(t *testing.T) {
+	path := "path"
+	cfg := &CorrectionSourceConfig{}
+	_, err := cfg.Validate(path)
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError(path, "type"))
+
+	cfg.Type = "carrier pigeon"
+	_, err = cfg.Validate(path)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	cfg = &CorrectionSourceConfig{Type: CorrectionSourceTCP}
+	_, err = cfg.Validate(path)
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError(path, "address"))
+	cfg.Address = "192.168.1.10"
+	_, err = cfg.Validate(path)
+	test.That(t, err, test.ShouldNotBeNil)
+	cfg.Address = "192.168.1.10:2101"
+	deps, err := cfg.Validate(path)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldBeEmpty)
+
+	cfg = &CorrectionSourceConfig{Type: CorrectionSourceSerial}
+	_, err = cfg.Validate(path)
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError(path, "serial_path"))
+	cfg.SerialPath = "/dev/ttyUSB1"
+	_, err = cfg.Validate(path)
+	test.That(t, err, test.ShouldBeNil)
+
+	cfg = &CorrectionSourceConfig{Type: CorrectionSourceFile}
+	_, err = cfg.Validate(path)
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError(path, "file_path"))
+	cfg.FilePath = "/tmp/rtcm.bin"
+	cfg.FileBytesPerSec = -1
+	_, err = cfg.Validate(path)
+	test.That(t, err, test.ShouldNotBeNil)
+	cfg.FileBytesPerSec = 0
+	_, err = cfg.Validate(path)
+	test.That(t, err, test.ShouldBeNil)
+
+	cfg = &CorrectionSourceConfig{Type: CorrectionSourceDistributor}
+	_, err = cfg.Validate(path)
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError(path, "distributor"))
+	cfg.Distributor = "corrections"
+	deps, err = cfg.Validate(path)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"corrections"})
+}
+
+func TestTCPSource(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	test.That(t, err, test.ShouldBeNil)
+	defer listener.Close()
+
+	// the server sends one message per connection, then hangs up
+	go func() {
+		for i := 0; ; i++ {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte{'a' + byte(i)})
+			conn.Close()
+		}
+	}()
+
+	s, err := NewCorrectionSource(&CorrectionSourceConfig{Type: CorrectionSourceTCP, Address: listener.Addr().String()}, nil, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	for _, want := range []string{"a", "b"} {
+		stream, err := s.Stream(context.Background())
+		test.That(t, err, test.ShouldBeNil)
+		data, err := io.ReadAll(stream)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, string(data), test.ShouldEqual, want)
+	}
+
+	test.That(t, s.Close(), test.ShouldBeNil)
+	_, err = s.Stream(context.Background())
+	test.That(t, errors.Is(err, errCorrectionSourceClosed), test.ShouldBeTrue)
+}
+
+func TestTCPSourceGivesUp(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	test.That(t, err, test.ShouldBeNil)
+	address := listener.Addr().String()
+	test.That(t, listener.Close(), test.ShouldBeNil)
+
+	mockClock := clock.NewMock()
+	s := &tcpSource{address: address, attempts: 3, clock: mockClock, logger: logger}
+
+	// the attempts are a retry interval apart, which passes instantly on the mock clock
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.Stream(context.Background())
+		done <- err
+	}()
+	for {
+		select {
+		case err := <-done:
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldContainSubstring, "can't connect to correction server")
+			return
+		default:
+			mockClock.Add(ntripRetryInterval)
+		}
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	path := filepath.Join(t.TempDir(), "rtcm.bin")
+	test.That(t, os.WriteFile(path, []byte("corrections"), 0o600), test.ShouldBeNil)
+
+	_, err := NewCorrectionSource(&CorrectionSourceConfig{Type: CorrectionSourceFile, FilePath: path + ".missing"}, nil, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	s, err := NewCorrectionSource(&CorrectionSourceConfig{
+		Type:            CorrectionSourceFile,
+		FilePath:        path,
+		FileBytesPerSec: 1 << 20,
+	}, nil, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	// each stream replays the file from the start
+	for i := 0; i < 2; i++ {
+		stream, err := s.Stream(context.Background())
+		test.That(t, err, test.ShouldBeNil)
+		data, err := io.ReadAll(stream)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, string(data), test.ShouldEqual, "corrections")
+	}
+
+	test.That(t, s.Close(), test.ShouldBeNil)
+	test.That(t, s.Close(), test.ShouldBeNil)
+	_, err = s.Stream(context.Background())
+	test.That(t, err, test.ShouldBeError, errCorrectionSourceClosed)
+}
+
+func TestPacedReader(t *testing.T) {
+	mockClock := clock.NewMock()
+	done := make(chan struct{})
+	r := &pacedReader{
+		r:           endlessReader{},
+		bytesPerSec: 4,
+		clock:       mockClock,
+		start:       mockClock.Now(),
+		done:        done,
+	}
+
+	// a second's worth comes straight away, and no more
+	buf := make([]byte, 10)
+	n, err := r.Read(buf)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, n, test.ShouldEqual, 4)
+
+	// the next has to wait for the second to be up
+	read := make(chan int, 1)
+	go func() {
+		n, _ := r.Read(buf)
+		read <- n
+	}()
+	select {
+	case <-read:
+		t.Fatal("read before a second was up")
+	case <-time.After(50 * time.Millisecond):
+	}
+	for n = 0; n == 0; {
+		mockClock.Add(time.Second)
+		select {
+		case n = <-read:
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	test.That(t, n, test.ShouldEqual, 4)
+
+	// closing the source ends the wait
+	r.start = mockClock.Now()
+	errs := make(chan error, 1)
+	go func() {
+		_, err := r.Read(buf)
+		errs <- err
+	}()
+	close(done)
+	test.That(t, <-errs, test.ShouldBeError, errCorrectionSourceClosed)
+}
+
+// endlessReader reads as many bytes as asked for, forever.
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	return len(p), nil
+}
@@ -0,0 +1,96 @@
+package gpsutils
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/logging"
+)
+
+// maxI2CWriteFailures is how many writes in a row can fail before an I2CConnection reports itself
+// unhealthy.
+const maxI2CWriteFailures = 5
+
+var errI2CConnectionClosed = errors.New("I2C connection is closed")
+
+// I2CConnection writes to a single device on an I2C bus. Each write opens its own handle and closes
+// it again when done, so the bus is only locked for as long as the write takes and other drivers
+// (such as the NMEA reader on the same chip) can use it in between. A write which fails is retried
+// once on a freshly opened handle, and the connection keeps track of how many writes in a row have
+// failed so that callers can give up on a device which has gone away.
+type I2CConnection struct {
+	bus    buses.I2C
+	addr   byte
+	logger logging.Logger
+
+	mu                  sync.Mutex
+	consecutiveFailures int
+	lastErr             error
+	closed              bool
+}
+
+// NewI2CConnection returns a connection to the device at addr on the given bus.
+func NewI2CConnection(bus buses.I2C, addr byte, logger logging.Logger) *I2CConnection {
+	return &I2CConnection{bus: bus, addr: addr, logger: logger}
+}
+
+// Write writes data to the device, reopening the handle and trying again once if the first attempt
+// fails.
+func (c *I2CConnection) Write(ctx context.Context, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return errI2CConnectionClosed
+	}
+
+	err := c.writeOnce(ctx, data)
+	if err != nil && ctx.Err() == nil {
+		c.logger.CDebugw(ctx, "I2C write failed, retrying on a new handle", "addr", c.addr, "error", err)
+		err = c.writeOnce(ctx, data)
+	}
+	if err != nil {
+		c.consecutiveFailures++
+		c.lastErr = err
+		return err
+	}
+	c.consecutiveFailures = 0
+	c.lastErr = nil
+	return nil
+}
+
+// writeOnce opens a handle, writes data to it, and closes it again.
+func (c *I2CConnection) writeOnce(ctx context.Context, data []byte) error {
+	handle, err := c.bus.OpenHandle(c.addr)
+	if err != nil {
+		return errors.Wrapf(err, "can't open I2C handle at address %#x", c.addr)
+	}
+	defer utils.UncheckedErrorFunc(handle.Close)
+	return handle.Write(ctx, data)
+}
+
+// Healthy returns nil unless the last maxI2CWriteFailures writes have all failed, in which case it
+// returns the most recent of their errors.
+func (c *I2CConnection) Healthy() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return errI2CConnectionClosed
+	}
+	if c.consecutiveFailures < maxI2CWriteFailures {
+		return nil
+	}
+	return errors.Wrapf(c.lastErr, "last %d I2C writes failed", c.consecutiveFailures)
+}
+
+// Close stops any further writes. It waits for a write in progress to finish, so that no handle is
+// left open on the bus after it returns.
+func (c *I2CConnection) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
@@ -0,0 +1,100 @@
+package gpsutils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// countingBus is an I2C bus whose handles fail their writes while writeErr is set, and which counts
+// how many handles are still open.
+type countingBus struct {
+	inject.I2C
+	opens, closes int
+	openErr       error
+	writeErr      error
+	written       [][]byte
+}
+
+func newCountingBus() *countingBus {
+	bus := &countingBus{}
+	bus.OpenHandleFunc = func(addr byte) (buses.I2CHandle, error) {
+		if bus.openErr != nil {
+			return nil, bus.openErr
+		}
+		bus.opens++
+		handle := &inject.I2CHandle{}
+		handle.WriteFunc = func(ctx context.Context, tx []byte) error {
+			if bus.writeErr != nil {
+				return bus.writeErr
+			}
+			bus.written = append(bus.written, tx)
+			return nil
+		}
+		handle.CloseFunc = func() error {
+			bus.closes++
+			return nil
+		}
+		return handle, nil
+	}
+	return bus
+}
+
+func TestI2CConnection(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	t.Run("closes each handle after writing", func(t *testing.T) {
+		bus := newCountingBus()
+		conn := NewI2CConnection(bus, 0x42, logger)
+		test.That(t, conn.Write(ctx, []byte("a")), test.ShouldBeNil)
+		test.That(t, conn.Write(ctx, []byte("b")), test.ShouldBeNil)
+		test.That(t, bus.opens, test.ShouldEqual, 2)
+		test.That(t, bus.closes, test.ShouldEqual, 2)
+		test.That(t, bus.written, test.ShouldResemble, [][]byte{[]byte("a"), []byte("b")})
+		test.That(t, conn.Healthy(), test.ShouldBeNil)
+	})
+
+	t.Run("retries a failed write on a new handle", func(t *testing.T) {
+		bus := newCountingBus()
+		conn := NewI2CConnection(bus, 0x42, logger)
+		bus.writeErr = errors.New("nack")
+		test.That(t, conn.Write(ctx, []byte("a")), test.ShouldBeError, bus.writeErr)
+		test.That(t, bus.opens, test.ShouldEqual, 2)
+		test.That(t, bus.closes, test.ShouldEqual, 2)
+	})
+
+	t.Run("reports unhealthy after repeated failures", func(t *testing.T) {
+		bus := newCountingBus()
+		conn := NewI2CConnection(bus, 0x42, logger)
+		bus.openErr = errors.New("no such bus")
+		for i := 0; i < maxI2CWriteFailures-1; i++ {
+			test.That(t, conn.Write(ctx, []byte("a")), test.ShouldNotBeNil)
+		}
+		test.That(t, conn.Healthy(), test.ShouldBeNil)
+		test.That(t, conn.Write(ctx, []byte("a")), test.ShouldNotBeNil)
+		test.That(t, conn.Healthy(), test.ShouldNotBeNil)
+		test.That(t, conn.Healthy().Error(), test.ShouldContainSubstring, "no such bus")
+
+		// one good write is enough to recover
+		bus.openErr = nil
+		test.That(t, conn.Write(ctx, []byte("a")), test.ShouldBeNil)
+		test.That(t, conn.Healthy(), test.ShouldBeNil)
+		test.That(t, bus.opens, test.ShouldEqual, bus.closes)
+	})
+
+	t.Run("refuses writes once closed", func(t *testing.T) {
+		bus := newCountingBus()
+		conn := NewI2CConnection(bus, 0x42, logger)
+		test.That(t, conn.Close(), test.ShouldBeNil)
+		test.That(t, conn.Write(ctx, []byte("a")), test.ShouldBeError, errI2CConnectionClosed)
+		test.That(t, conn.Healthy(), test.ShouldBeError, errI2CConnectionClosed)
+		test.That(t, bus.opens, test.ShouldEqual, 0)
+	})
+}
@@ -27,9 +27,10 @@ type PmtkI2cDataReader struct {
 	activeBackgroundWorkers sync.WaitGroup
 	logger                  logging.Logger
 
-	bus  buses.I2C
-	addr byte
-	baud int
+	bus      buses.I2C
+	addr     byte
+	baud     int
+	receiver *ReceiverConfig
 }
 
 // NewI2cDataReader constructs a new DataReader that gets its NMEA messages over an I2C bus.
@@ -62,6 +63,7 @@ func NewI2cDataReader(config I2CConfig, bus buses.I2C, logger logging.Logger) (D
 		bus:        bus,
 		addr:       byte(addr),
 		baud:       baud,
+		receiver:   config.Receiver,
 	}
 
 	if err := reader.initialize(); err != nil {
@@ -86,23 +88,17 @@ func (dr *PmtkI2cDataReader) initialize() error {
 	// governed by the clock line on the I2C bus, not on the device.
 	baudcmd := fmt.Sprintf("PMTK251,%d", dr.baud)
 	cmd251 := movementsensor.PMTKAddChk([]byte(baudcmd))
-	// Output GLL, RMC, VTG, GGA, GSA, and GSV sentences, and nothing else, every position fix
-	cmd314 := movementsensor.PMTKAddChk([]byte("PMTK314,1,1,1,1,1,1,0,0,0,0,0,0,0,0,0,0,0,0,0"))
-	// Ask for updates every 1000 ms (every second)
-	cmd220 := movementsensor.PMTKAddChk([]byte("PMTK220,1000"))
 
 	err = handle.Write(dr.cancelCtx, cmd251)
 	if err != nil {
 		dr.logger.CDebug(dr.cancelCtx, "Failed to set baud rate")
 		return err
 	}
-	err = handle.Write(dr.cancelCtx, cmd314)
-	if err != nil {
-		return err
-	}
-	err = handle.Write(dr.cancelCtx, cmd220)
-	if err != nil {
-		return err
+	// Choose the sentences, update rate, constellations and SBAS
+	for _, cmd := range ReceiverCommands(dr.receiver, ProtocolPMTK) {
+		if err := handle.Write(dr.cancelCtx, cmd); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -0,0 +1,30 @@
+//go:build linux
+
+package gpsutils
+
+import (
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board/iorecord"
+	"go.viam.com/rdk/logging"
+)
+
+func TestI2cDataReaderReplay(t *testing.T) {
+	// recorded from a PMTK GPS, which pads its output with line feeds and splits sentences across reads
+	replayer, err := iorecord.LoadFixture("data/pmtk_i2c.jsonl")
+	test.That(t, err, test.ShouldBeNil)
+
+	config := I2CConfig{I2CBus: "1", I2CAddr: 0x42, I2CBaudRate: 115200}
+	reader, err := NewI2cDataReader(config, replayer.I2C("nmea"), logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+
+	messages := reader.Messages()
+	test.That(t, <-messages, test.ShouldEqual,
+		"$GPGGA,172814.0,3723.46587704,N,12202.26957864,W,2,6,1.2,18.893,M,-25.669,M,2.0,0031*4F")
+	test.That(t, <-messages, test.ShouldEqual,
+		"$GPRMC,172814.0,A,3723.46587704,N,12202.26957864,W,0.02,31.66,280511,,,A*43")
+	test.That(t, reader.Close(), test.ShouldBeNil)
+	test.That(t, replayer.Verify(), test.ShouldBeNil)
+}
@@ -0,0 +1,156 @@
+package gpsutils
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+const (
+	// AutoMountpoint is the ntrip_mountpoint which picks whichever station is nearest to the rover.
+	AutoMountpoint = "auto"
+
+	// GetSourcetableCommand is the DoCommand key with which RTK movement sensors return the
+	// streams in their caster's sourcetable.
+	GetSourcetableCommand = "get_sourcetable"
+
+	// autoMountpointReselectKm is how far the rover can move from where the nearest station was
+	// picked before picking again.
+	autoMountpointReselectKm = 10.0
+)
+
+var errNoNearbyStation = errors.New("sourcetable has no RTCM stream at a known location")
+
+// IsAutoMountpoint returns whether the mountpoint is picked as the station nearest to the rover.
+func (n *NtripInfo) IsAutoMountpoint() bool {
+	return n.autoMountpoint
+}
+
+// SelectNearestMountpoint waits until the rover has a fix, then sets MountPoint to the sourcetable's
+// station nearest to it.
+func (n *NtripInfo) SelectNearestMountpoint(ctx context.Context, rover *CachedData, logger logging.Logger) error {
+	position, err := rover.fixedPosition()
+	for err != nil {
+		logger.CDebugf(ctx, "waiting for a fix to pick the nearest mountpoint: %s", err)
+		if !n.WaitToRetry(ctx) {
+			return ctx.Err()
+		}
+		position, err = rover.fixedPosition()
+	}
+
+	st, err := n.GetSourcetable(logger)
+	if err != nil {
+		return err
+	}
+	stream, ok := st.NearestStream(position)
+	if !ok {
+		return errNoNearbyStation
+	}
+
+	logger.CInfof(ctx, "using mountpoint %s, %.1f km away", stream.MP, stream.distanceKm(position))
+	n.MountPoint = stream.MP
+	n.selectedFrom = position
+	return nil
+}
+
+// ShouldReselectMountpoint returns whether the rover has moved far enough from where the nearest
+// station was picked that it's time to pick again.
+func (n *NtripInfo) ShouldReselectMountpoint(rover *CachedData) bool {
+	if !n.autoMountpoint || n.selectedFrom == nil {
+		return false
+	}
+	position, err := rover.fixedPosition()
+	if err != nil {
+		return false
+	}
+	return position.GreatCircleDistance(n.selectedFrom) > autoMountpointReselectKm
+}
+
+// NearestStream returns the stream whose station is nearest to position. Only streams of RTCM
+// corrections from a station at a known location are considered, which rules out virtual
+// reference stations.
+func (st *Sourcetable) NearestStream(position *geo.Point) (Stream, bool) {
+	var nearest Stream
+	nearestKm := math.Inf(1)
+	for _, stream := range st.Streams {
+		if stream.Nmea || !strings.Contains(strings.ToUpper(stream.Format), "RTCM") ||
+			(stream.Latitude == 0 && stream.Longitude == 0) {
+			continue
+		}
+		if km := stream.distanceKm(position); km < nearestKm {
+			nearest, nearestKm = stream, km
+		}
+	}
+	return nearest, !math.IsInf(nearestKm, 1)
+}
+
+func (s Stream) distanceKm(position *geo.Point) float64 {
+	return position.GreatCircleDistance(geo.NewPoint(float64(s.Latitude), float64(s.Longitude)))
+}
+
+// SourcetableCommand handles GetSourcetableCommand for an RTK movement sensor, listing every stream
+// in the caster's sourcetable. If the rover has a fix, each stream includes its distance from it.
+func (n *NtripInfo) SourcetableCommand(
+	cmd map[string]interface{}, rover *CachedData, logger logging.Logger,
+) (map[string]interface{}, error) {
+	if _, ok := cmd[GetSourcetableCommand]; !ok {
+		return nil, resource.ErrDoUnimplemented
+	}
+	if n.Client == nil {
+		return nil, errors.New("not connected to the NTRIP caster yet")
+	}
+
+	st, err := n.GetSourcetable(logger)
+	if err != nil {
+		return nil, err
+	}
+	position, err := rover.fixedPosition()
+	if err != nil {
+		position = nil
+	}
+
+	streams := make([]interface{}, 0, len(st.Streams))
+	for _, stream := range st.Streams {
+		streams = append(streams, stream.toMap(position))
+	}
+	return map[string]interface{}{
+		"mountpoint": n.MountPoint,
+		"streams":    streams,
+	}, nil
+}
+
+// toMap converts the stream into what a DoCommand can return, with its distance from position
+// unless that's nil.
+func (s Stream) toMap(position *geo.Point) map[string]interface{} {
+	navSystems := make([]interface{}, 0, len(s.NavSystem))
+	for _, system := range s.NavSystem {
+		navSystems = append(navSystems, system)
+	}
+	m := map[string]interface{}{
+		"mountpoint":     s.MP,
+		"identifier":     s.Identifier,
+		"format":         s.Format,
+		"format_details": s.FormatDetails,
+		"carrier":        s.Carrier,
+		"nav_systems":    navSystems,
+		"network":        s.Network,
+		"country":        s.Country,
+		"latitude":       float64(s.Latitude),
+		"longitude":      float64(s.Longitude),
+		"nmea":           s.Nmea,
+		"solution":       s.Solution,
+		"authentication": s.Authentication,
+		"fee":            s.Fee,
+		"bit_rate":       s.BitRate,
+	}
+	if position != nil {
+		m["distance_km"] = s.distanceKm(position)
+	}
+	return m
+}
@@ -0,0 +1,106 @@
+package gpsutils
+
+import (
+	"strings"
+	"testing"
+
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+const testSourcetable = `CAS;caster.example.com;2101;Example;EX;0;USA;40.00;-74.00;0.0.0.0;0;http://example.com
+STR;NYC;New York;RTCM 3.2;1004(1),1005(10);2;GPS+GLO;EX;USA;40.71;-74.01;0;0;sNTRIP;none;B;N;9600;
+STR;PHL;Philadelphia;RTCM 3.2;1004(1),1005(10);2;GPS;EX;USA;39.95;-75.17;0;0;sNTRIP;none;B;N;9600;
+STR;VRS;Network;RTCM 3.2;1004(1);2;GPS;EX;USA;40.00;-74.50;1;1;sNTRIP;none;B;N;9600;
+STR;RAW;Raw data;RAW;;0;GPS;EX;USA;40.50;-74.20;0;0;sNTRIP;none;B;N;9600;
+STR;BROKEN;too;few;fields
+ENDSOURCETABLE
+`
+
+func TestGetSourcetableParsesEveryStream(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	st, err := parseSourcetable(strings.NewReader(testSourcetable), "http://caster", nil, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	mountpoints := []string{}
+	for _, stream := range st.Streams {
+		mountpoints = append(mountpoints, stream.MP)
+	}
+	test.That(t, mountpoints, test.ShouldResemble, []string{"NYC", "PHL", "VRS", "RAW"})
+
+	// only keeping our own mountpoint, a broken line for it is an error
+	_, err = parseSourcetable(strings.NewReader(testSourcetable), "http://caster",
+		func(mountpoint string) bool { return mountpoint == "BROKEN" }, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestNearestStream(t *testing.T) {
+	st, err := parseSourcetable(strings.NewReader(testSourcetable), "http://caster", nil, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+
+	// the VRS stream is nearer to Trenton, but is no station to use corrections from
+	stream, ok := st.NearestStream(geo.NewPoint(40.22, -74.76))
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, stream.MP, test.ShouldEqual, "PHL")
+
+	stream, ok = st.NearestStream(geo.NewPoint(40.8, -73.9))
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, stream.MP, test.ShouldEqual, "NYC")
+
+	_, ok = (&Sourcetable{}).NearestStream(geo.NewPoint(40.8, -73.9))
+	test.That(t, ok, test.ShouldBeFalse)
+}
+
+func TestShouldReselectMountpoint(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	info, err := NewNtripInfo(&NtripConfig{NtripURL: "http://caster", NtripMountpoint: AutoMountpoint}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, info.IsAutoMountpoint(), test.ShouldBeTrue)
+	test.That(t, info.MountPoint, test.ShouldEqual, "")
+
+	rover := NewCachedData(&mockDataReader{}, logger)
+	// nothing has been picked yet, so there's nothing to pick again
+	test.That(t, info.ShouldReselectMountpoint(rover), test.ShouldBeFalse)
+
+	info.MountPoint = "NYC"
+	info.selectedFrom = geo.NewPoint(40.71, -74.01)
+	test.That(t, info.ShouldReselectMountpoint(rover), test.ShouldBeFalse)
+
+	// a few km away from where it was picked
+	rover.nmeaData.Location = geo.NewPoint(40.74, -73.99)
+	test.That(t, info.ShouldReselectMountpoint(rover), test.ShouldBeFalse)
+
+	// Philadelphia is well over the limit
+	rover.nmeaData.Location = geo.NewPoint(39.95, -75.17)
+	test.That(t, info.ShouldReselectMountpoint(rover), test.ShouldBeTrue)
+
+	fixed, err := NewNtripInfo(&NtripConfig{NtripURL: "http://caster", NtripMountpoint: "NYC"}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fixed.ShouldReselectMountpoint(rover), test.ShouldBeFalse)
+}
+
+func TestSourcetableCommand(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	info, err := NewNtripInfo(&NtripConfig{NtripURL: "http://caster", NtripMountpoint: "NYC"}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	rover := NewCachedData(&mockDataReader{}, logger)
+
+	_, err = info.SourcetableCommand(map[string]interface{}{"other": true}, rover, logger)
+	test.That(t, err, test.ShouldBeError, resource.ErrDoUnimplemented)
+
+	_, err = info.SourcetableCommand(map[string]interface{}{GetSourcetableCommand: true}, rover, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "not connected")
+
+	st, err := parseSourcetable(strings.NewReader(testSourcetable), "http://caster", nil, logger)
+	test.That(t, err, test.ShouldBeNil)
+	m := st.Streams[0].toMap(geo.NewPoint(40.71, -74.01))
+	test.That(t, m["mountpoint"], test.ShouldEqual, "NYC")
+	test.That(t, m["nav_systems"], test.ShouldResemble, []interface{}{"GPS", "GLO"})
+	test.That(t, m["distance_km"], test.ShouldAlmostEqual, 0, 0.01)
+	_, ok := st.Streams[0].toMap(nil)["distance_km"]
+	test.That(t, ok, test.ShouldBeFalse)
+}
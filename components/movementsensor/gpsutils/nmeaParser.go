@@ -5,6 +5,7 @@ import (
 	"math"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/adrianmo/go-nmea"
 	geo "github.com/kellydunn/golang-geo"
@@ -31,6 +32,9 @@ type NmeaParser struct {
 	CompassHeading      float64 // true compass heading in degree
 	isEast              bool    // direction for magnetic variation which outputs East or West.
 	validCompassHeading bool    // true if we get course of direction instead of empty strings.
+
+	// Time is the UTC time from the latest RMC sentence, or zero if there hasn't been one.
+	Time time.Time
 }
 
 func errInvalidFix(sentenceType, badFix, goodFix string) error {
@@ -125,6 +129,12 @@ func (g *NmeaParser) updateGSV(gsv nmea.GSV) error {
 // updateRMC updates the NmeaParser object with the information from the provided
 // RMC (Recommended Minimum Navigation Information) data.
 func (g *NmeaParser) updateRMC(rmc nmea.RMC) error {
+	// Receivers know the time from the satellites before they have a position fix.
+	if rmc.Date.Valid && rmc.Time.Valid {
+		g.Time = time.Date(2000+rmc.Date.YY, time.Month(rmc.Date.MM), rmc.Date.DD,
+			rmc.Time.Hour, rmc.Time.Minute, rmc.Time.Second, rmc.Time.Millisecond*int(time.Millisecond), time.UTC)
+	}
+
 	if rmc.Validity != "A" {
 		g.valid = false
 		return errInvalidFix(rmc.Type, rmc.Validity, "A")
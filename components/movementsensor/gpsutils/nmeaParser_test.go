@@ -3,6 +3,7 @@ package gpsutils
 import (
 	"math"
 	"testing"
+	"time"
 
 	"go.viam.com/test"
 )
@@ -29,6 +30,7 @@ func TestParse2(t *testing.T) {
 	test.That(t, data.Location.Lat(), test.ShouldAlmostEqual, 40.77385866666667, 0.001)
 	test.That(t, data.Location.Lng(), test.ShouldAlmostEqual, -73.9817245, 0.001)
 	test.That(t, math.IsNaN(data.CompassHeading), test.ShouldBeTrue)
+	test.That(t, data.Time, test.ShouldEqual, time.Date(2023, time.July, 12, 20, 37, 56, 0, time.UTC))
 
 	nmeaSentence = "$GPRMC,210230,A,3855.4487,N,09446.0071,W,0.0,076.2,130495,003.8,E*69"
 	err = data.ParseAndUpdate(nmeaSentence)
@@ -9,8 +9,11 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/benbjohnson/clock"
 	"github.com/de-bkg/gognss/pkg/ntrip"
+	geo "github.com/kellydunn/golang-geo"
 
 	"go.viam.com/rdk/logging"
 )
@@ -42,6 +45,9 @@ const (
 	streamSize    = 200
 )
 
+// ntripRetryInterval is how long to wait between attempts to connect to an NTRIP caster or stream.
+var ntripRetryInterval = time.Second
+
 // NtripInfo contains the information necessary to connect to a mountpoint.
 type NtripInfo struct {
 	URL                string
@@ -51,6 +57,13 @@ type NtripInfo struct {
 	Client             *ntrip.Client
 	Stream             io.ReadCloser
 	MaxConnectAttempts int
+	// Clock times the waits between attempts to connect. If nil, the real clock is used.
+	Clock clock.Clock
+
+	// autoMountpoint is whether MountPoint is picked as the nearest station to the rover, which it
+	// was last picked from selectedFrom.
+	autoMountpoint bool
+	selectedFrom   *geo.Point
 }
 
 // NtripConfig is used for converting attributes for a correction source.
@@ -111,6 +124,10 @@ func NewNtripInfo(cfg *NtripConfig, logger logging.Logger) (*NtripInfo, error) {
 	if n.MountPoint == "" {
 		logger.Info("ntrip_mountpoint set to empty")
 	}
+	if n.MountPoint == AutoMountpoint {
+		n.autoMountpoint = true
+		n.MountPoint = ""
+	}
 	n.MaxConnectAttempts = cfg.NtripConnectAttempts
 	if n.MaxConnectAttempts == 0 {
 		logger.Info("ntrip_connect_attempts using default 10")
@@ -121,8 +138,20 @@ func NewNtripInfo(cfg *NtripConfig, logger logging.Logger) (*NtripInfo, error) {
 	return n, nil
 }
 
-// ParseSourcetable gets the sourcetable and parses it.
+// ParseSourcetable gets the sourcetable and parses it, keeping only the stream for our mountpoint.
 func (n *NtripInfo) ParseSourcetable(logger logging.Logger) (*Sourcetable, error) {
+	return n.readSourcetable(logger, func(mountpoint string) bool { return mountpoint == n.MountPoint })
+}
+
+// GetSourcetable gets the sourcetable and parses every stream in it. Streams which can't be parsed
+// are skipped rather than failing the whole table.
+func (n *NtripInfo) GetSourcetable(logger logging.Logger) (*Sourcetable, error) {
+	return n.readSourcetable(logger, nil)
+}
+
+// readSourcetable gets the sourcetable and parses the streams whose mountpoints keep accepts, or
+// every stream if keep is nil.
+func (n *NtripInfo) readSourcetable(logger logging.Logger, keep func(mountpoint string) bool) (*Sourcetable, error) {
 	reader, err := n.Client.GetSourcetable()
 	if err != nil {
 		return nil, err
@@ -132,7 +161,12 @@ func (n *NtripInfo) ParseSourcetable(logger logging.Logger) (*Sourcetable, error
 			logger.Errorf("Error closing reader:", err)
 		}
 	}()
+	return parseSourcetable(reader, n.URL, keep, logger)
+}
 
+func parseSourcetable(
+	reader io.Reader, url string, keep func(mountpoint string) bool, logger logging.Logger,
+) (*Sourcetable, error) {
 	st := &Sourcetable{}
 	st.Streams = make([]Stream, 0, streamSize)
 	scanner := bufio.NewScanner(reader)
@@ -150,7 +184,14 @@ Loop:
 		case "CAS", "NET":
 			continue
 		case "STR":
-			if fields[mp] == n.MountPoint {
+			if keep == nil {
+				str, err := parseStream(ln)
+				if err != nil {
+					logger.Debugf("skipping sourcetable stream: %s", err)
+					continue
+				}
+				st.Streams = append(st.Streams, str)
+			} else if len(fields) > mp && keep(fields[mp]) {
 				str, err := parseStream(ln)
 				if err != nil {
 					return nil, fmt.Errorf("error while parsing stream: %w", err)
@@ -162,7 +203,7 @@ Loop:
 				logger.Debug("Reached the end of SourceTable")
 				break Loop
 			}
-			return nil, fmt.Errorf("%s: illegal sourcetable line: '%s'", n.URL, ln)
+			return nil, fmt.Errorf("%s: illegal sourcetable line: '%s'", url, ln)
 		}
 	}
 
@@ -243,12 +284,30 @@ func (n *NtripInfo) Connect(ctx context.Context, logger logging.Logger) error {
 			n.Client = c
 			return nil
 		}
+		if attempts < n.MaxConnectAttempts-1 && !n.WaitToRetry(ctx) {
+			return ctx.Err()
+		}
 	}
 
 	logger.Errorf("Can't connect to NTRIP caster: %s", err)
 	return err
 }
 
+// WaitToRetry waits before another attempt to connect to the caster or a stream, returning false
+// if ctx is done first.
+func (n *NtripInfo) WaitToRetry(ctx context.Context) bool {
+	c := n.Clock
+	if c == nil {
+		c = clock.New()
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-c.After(ntripRetryInterval):
+		return true
+	}
+}
+
 // HasStream checks if the sourcetable contains the given mountpoint in it's stream.
 func (st *Sourcetable) HasStream(mountpoint string) (Stream, bool) {
 	for _, str := range st.Streams {
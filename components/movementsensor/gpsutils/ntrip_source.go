@@ -0,0 +1,232 @@
+package gpsutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"go.viam.com/rdk/logging"
+)
+
+// casterAliveAttempts is how many times to check whether a caster which seems to be down is up.
+const casterAliveAttempts = 5
+
+// NtripSource is a CorrectionSource which gets corrections from an NTRIP caster's mountpoint. If
+// the mountpoint is AutoMountpoint, it uses the station nearest to the rover, switching stations as
+// the rover moves. If the mountpoint is a Virtual Reference Station, it uploads the rover's
+// position so the caster can compute corrections for it.
+type NtripSource struct {
+	info   *NtripInfo
+	rover  *CachedData
+	logger logging.Logger
+
+	mu            sync.Mutex
+	connected     bool
+	isVirtualBase bool
+	stream        io.Closer
+	closed        bool
+}
+
+// NewNtripSource returns a source of corrections from the NTRIP caster cfg describes, for the rover
+// whose NMEA sentences are in rover.
+func NewNtripSource(cfg *NtripConfig, rover *CachedData, logger logging.Logger) (*NtripSource, error) {
+	info, err := NewNtripInfo(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &NtripSource{info: info, rover: rover, logger: logger}, nil
+}
+
+// Info returns the caster and mountpoint the corrections come from.
+func (s *NtripSource) Info() *NtripInfo {
+	return s.info
+}
+
+// Stream connects to the caster if it isn't already, then to the mountpoint, replacing any stream
+// from before.
+func (s *NtripSource) Stream(ctx context.Context) (io.Reader, error) {
+	s.mu.Lock()
+	connected, closed := s.connected, s.closed
+	s.mu.Unlock()
+	if closed {
+		return nil, errCorrectionSourceClosed
+	}
+	if !connected {
+		if err := s.connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	isVirtualBase := s.isVirtualBase
+	s.mu.Unlock()
+
+	var stream io.ReadCloser
+	var err error
+	if isVirtualBase {
+		s.logger.CDebug(ctx, "connecting to a Virtual Reference Station")
+		stream, err = s.connectToVRS(ctx)
+	} else {
+		stream, err = s.getStream(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		// we were closed while connecting, so nothing else will close this
+		return nil, errors.Join(errCorrectionSourceClosed, stream.Close())
+	}
+	if s.stream != nil {
+		if err := s.stream.Close(); err != nil {
+			s.logger.CDebugf(ctx, "failed to close the previous stream: %s", err)
+		}
+	}
+	s.stream = stream
+	return &ntripStream{ReadCloser: stream, ctx: ctx, source: s}, nil
+}
+
+// connect connects to the caster and picks the mountpoint: either the nearest station's, if we're
+// to pick one, or the configured one, which the sourcetable says whether is a Virtual Reference
+// Station.
+func (s *NtripSource) connect(ctx context.Context) error {
+	if err := s.info.Connect(ctx, s.logger); err != nil {
+		return err
+	}
+
+	if !s.info.Client.IsCasterAlive() {
+		s.logger.CInfof(ctx, "caster %s seems to be down, retrying", s.info.URL)
+		for attempts := 1; !s.info.Client.IsCasterAlive(); attempts++ {
+			if attempts == casterAliveAttempts {
+				return fmt.Errorf("caster %s is down", s.info.URL)
+			}
+			s.logger.CDebugf(ctx, "attempt(s) to connect to caster: %v ", attempts)
+			if !s.info.WaitToRetry(ctx) {
+				return ctx.Err()
+			}
+		}
+	}
+
+	isVirtualBase := false
+	if s.info.IsAutoMountpoint() {
+		// nearby stations' mountpoints are never virtual reference stations
+		if err := s.info.SelectNearestMountpoint(ctx, s.rover, s.logger); err != nil {
+			return err
+		}
+	} else {
+		srcTable, err := s.info.ParseSourcetable(s.logger)
+		if err != nil {
+			s.logger.CErrorf(ctx, "failed to get source table: %v", err)
+			return err
+		}
+		if isVirtualBase, err = HasVRSStream(srcTable, s.info.MountPoint); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connected = true
+	s.isVirtualBase = isVirtualBase
+	return nil
+}
+
+// getStream attempts to connect to the mountpoint's stream. We give up after MaxConnectAttempts
+// unsuccessful tries.
+func (s *NtripSource) getStream(ctx context.Context) (io.ReadCloser, error) {
+	s.logger.CDebug(ctx, "Getting NTRIP stream")
+
+	var rc io.ReadCloser
+	var err error
+	for attempts := 0; attempts < s.info.MaxConnectAttempts; attempts++ {
+		if attempts > 0 && !s.info.WaitToRetry(ctx) {
+			return nil, ctx.Err()
+		}
+		rc, err = s.info.Client.GetStream(s.info.MountPoint)
+		if err == nil {
+			break
+		}
+	}
+
+	if err != nil {
+		// casters speaking the old protocol answer ICY rather than HTTP, which still streams
+		if !strings.Contains(err.Error(), "ICY") || rc == nil {
+			s.logger.CErrorf(ctx, "Can't connect to NTRIP stream: %s", err)
+			return nil, err
+		}
+		s.logger.CWarnf(ctx, "Detected old HTTP protocol: %s", err)
+	}
+
+	s.logger.CDebug(ctx, "Connected to stream")
+	return rc, nil
+}
+
+// connectToVRS connects to the Virtual Reference Station once the rover has a fix to send it.
+func (s *NtripSource) connectToVRS(ctx context.Context) (*VRSStream, error) {
+	gga, err := s.rover.GGAMessage(time.Now())
+	for err != nil {
+		s.logger.CDebugf(ctx, "waiting for a fix to send the Virtual Reference Station: %s", err)
+		if !s.info.WaitToRetry(ctx) {
+			return nil, ctx.Err()
+		}
+		gga, err = s.rover.GGAMessage(time.Now())
+	}
+	return ConnectToVRS(s.info, gga, s.logger)
+}
+
+// reselectMountpoint picks the nearest station again now that the rover has moved away from the
+// one it was using, and returns whether that's a different station.
+func (s *NtripSource) reselectMountpoint(ctx context.Context) bool {
+	previous := s.info.MountPoint
+	if err := s.info.SelectNearestMountpoint(ctx, s.rover, s.logger); err != nil {
+		s.logger.CWarnf(ctx, "failed to pick the nearest mountpoint again, staying on %s: %s", previous, err)
+		return false
+	}
+	return s.info.MountPoint != previous
+}
+
+// DoCommand returns the streams in the caster's sourcetable when sent {"get_sourcetable": true}.
+func (s *NtripSource) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.info.SourcetableCommand(cmd, s.rover, s.logger)
+}
+
+// Close disconnects from the caster.
+func (s *NtripSource) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+
+	if s.info.Client != nil {
+		s.info.Client.CloseIdleConnections()
+	}
+	if s.stream == nil {
+		return nil
+	}
+	err := s.stream.Close()
+	s.stream = nil
+	return err
+}
+
+// ntripStream is a stream of corrections from a mountpoint. When the mountpoint is picked as the
+// nearest station and the rover moves far enough that another is nearer, the stream ends so that
+// the next one comes from the new station.
+type ntripStream struct {
+	io.ReadCloser
+	ctx    context.Context
+	source *NtripSource
+}
+
+func (s *ntripStream) Read(p []byte) (int, error) {
+	if s.source.info.ShouldReselectMountpoint(s.source.rover) && s.source.reselectMountpoint(s.ctx) {
+		return 0, io.EOF
+	}
+	return s.ReadCloser.Read(p)
+}
@@ -0,0 +1,71 @@
+package gpsutils
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+)
+
+func TestNtripSourceConnectToVRS(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	test.That(t, err, test.ShouldBeNil)
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for line := ""; line != "\r\n"; {
+			if line, err = reader.ReadString('\n'); err != nil {
+				return
+			}
+		}
+		conn.Write([]byte("HTTP/1.1 200 OK\r\n\r\n"))
+		line, _ := reader.ReadString('\n')
+		received <- line
+	}()
+
+	rover := NewCachedData(&mockDataReader{}, logger)
+	defer rover.Close(context.Background())
+	s, err := NewNtripSource(&NtripConfig{
+		NtripURL:        "http://" + listener.Addr().String(),
+		NtripMountpoint: "VRS",
+	}, rover, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	t.Run("waits for a fix", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := s.connectToVRS(ctx)
+		test.That(t, err, test.ShouldBeError, context.Canceled)
+	})
+
+	t.Run("uploads the position", func(t *testing.T) {
+		test.That(t, rover.ParseAndUpdate(
+			"$GNGGA,191351.000,4403.4655,N,12118.7950,W,1,6,1.72,1094.5,M,-19.6,M,,*47"), test.ShouldBeNil)
+		vrs, err := s.connectToVRS(context.Background())
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, <-received, test.ShouldContainSubstring, "GGA,")
+		test.That(t, vrs.Close(), test.ShouldBeNil)
+	})
+}
+
+func TestNtripSourceClosed(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	s, err := NewNtripSource(&NtripConfig{NtripURL: "http://fakeurl", NtripMountpoint: "NYC"}, nil, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, s.Close(), test.ShouldBeNil)
+
+	_, err = s.Stream(context.Background())
+	test.That(t, err, test.ShouldBeError, errCorrectionSourceClosed)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/benbjohnson/clock"
 	"go.viam.com/test"
 
 	"go.viam.com/rdk/logging"
@@ -40,3 +41,35 @@ func TestConnectSucceeds(t *testing.T) {
 	err = ntripInfo.Connect(cancelCtx, logger)
 	test.That(t, err, test.ShouldBeNil)
 }
+
+func TestConnectRetriesWithClock(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	mockClock := clock.NewMock()
+	ntripInfo := &NtripInfo{MaxConnectAttempts: 3, Clock: mockClock}
+
+	// the attempts are a retry interval apart, which passes instantly on the mock clock
+	done := make(chan error, 1)
+	go func() {
+		done <- ntripInfo.Connect(context.Background(), logger)
+	}()
+	for {
+		select {
+		case err := <-done:
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldContainSubstring, `address must start with http://`)
+			return
+		default:
+			mockClock.Add(ntripRetryInterval)
+		}
+	}
+}
+
+func TestWaitToRetryCanceled(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	cancelCtx, cancelFn := context.WithCancel(context.Background())
+	cancelFn()
+
+	// with the mock clock never advanced, only the canceled context ends the wait
+	ntripInfo := &NtripInfo{MaxConnectAttempts: 3, Clock: clock.NewMock()}
+	test.That(t, ntripInfo.WaitToRetry(cancelCtx), test.ShouldBeFalse)
+}
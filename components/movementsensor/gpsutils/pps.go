@@ -0,0 +1,209 @@
+package gpsutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils"
+)
+
+const (
+	// minPulseInterval is how soon after a pulse another one is taken to be noise on the line.
+	minPulseInterval = 900 * time.Millisecond
+	// ppsLockTimeout is how long without a pulse before the time is no longer locked to it.
+	ppsLockTimeout = 2 * time.Second
+)
+
+// PPSConfig says where a GPS receiver's PPS (pulse per second) line is connected. The receiver
+// raises it at the start of each second of GPS time.
+type PPSConfig struct {
+	Board            string `json:"board"`
+	DigitalInterrupt string `json:"digital_interrupt"`
+	// ChronySHMUnit, if set, is the unit of the shared memory refclock to give chrony the time in,
+	// as in "refclock SHM 2" in chrony.conf.
+	ChronySHMUnit *int `json:"chrony_shm_unit,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid, and returns the board it depends on.
+func (cfg *PPSConfig) Validate(path string) ([]string, error) {
+	if cfg.Board == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "board")
+	}
+	if cfg.DigitalInterrupt == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "digital_interrupt")
+	}
+	if cfg.ChronySHMUnit != nil && *cfg.ChronySHMUnit < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("chrony_shm_unit can't be negative"))
+	}
+	return []string{cfg.Board}, nil
+}
+
+// gpsTimeSource is what knows which second a pulse starts: the GPS's NMEA sentences.
+type gpsTimeSource interface {
+	// GPSTime returns the latest time the GPS sent, and when it was received.
+	GPSTime() (time.Time, time.Time)
+}
+
+// PPSSync measures how far the system clock is from GPS time, using the pulses on a receiver's PPS
+// line. It can also give chrony the time from the pulses, so that chrony keeps the system clock,
+// and with it the timestamps on everything captured, on GPS time.
+//
+// The offset is measured from when the board delivers each pulse, so includes its latency, which
+// is typically tens of microseconds to a millisecond.
+type PPSSync struct {
+	source  gpsTimeSource
+	logger  logging.Logger
+	clock   clock.Clock
+	shm     *chronySHM
+	workers utils.StoppableWorkers
+
+	mu          sync.Mutex
+	pulses      int
+	lastTick    uint64
+	lastPulseAt time.Time
+	offset      time.Duration
+	locked      bool
+}
+
+// NewPPSSync starts measuring the system clock against the pulses on the configured PPS line,
+// taking which second each pulse starts from source.
+func NewPPSSync(
+	ctx context.Context,
+	cfg *PPSConfig,
+	deps resource.Dependencies,
+	source gpsTimeSource,
+	logger logging.Logger,
+) (*PPSSync, error) {
+	b, err := board.FromDependencies(deps, cfg.Board)
+	if err != nil {
+		return nil, err
+	}
+	interrupt, err := b.DigitalInterruptByName(cfg.DigitalInterrupt)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &PPSSync{source: source, logger: logger, clock: clock.New()}
+	if cfg.ChronySHMUnit != nil {
+		if p.shm, err = openChronySHM(*cfg.ChronySHMUnit); err != nil {
+			return nil, fmt.Errorf("can't open chrony's shared memory: %w", err)
+		}
+		logger.CInfof(ctx, "giving chrony the time from PPS in shared memory unit %d", *cfg.ChronySHMUnit)
+	}
+
+	p.workers = utils.NewStoppableWorkers()
+	ticks := make(chan board.Tick)
+	if err := b.StreamTicks(p.workers.Context(), []board.DigitalInterrupt{interrupt}, ticks, nil); err != nil {
+		p.workers.Stop()
+		return nil, errors.Join(err, p.closeSHM())
+	}
+	p.workers.AddWorkers(func(ctx context.Context) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case tick := <-ticks:
+				// pulses start on the rising edge
+				if tick.High {
+					p.pulse(tick.TimestampNanosec)
+				}
+			}
+		}
+	})
+	return p, nil
+}
+
+// pulse handles a pulse at the board's given timestamp, which arrived just now.
+func (p *PPSSync) pulse(tickNanos uint64) {
+	now := p.clock.Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.lastTick != 0 && tickNanos > p.lastTick && time.Duration(tickNanos-p.lastTick) < minPulseInterval {
+		return
+	}
+	p.lastTick = tickNanos
+	p.pulses++
+	p.lastPulseAt = now
+
+	// Receivers send the time of each pulse shortly after it, so the latest time is that of the
+	// previous pulse, and this one starts the second after. That's only certain if the latest
+	// time came since the previous pulse, within the last second.
+	gpsTime, receivedAt := p.source.GPSTime()
+	if gpsTime.IsZero() || now.Sub(receivedAt) >= time.Second {
+		if p.locked {
+			p.logger.Warn("lost the time from the GPS, so can't tell which second its PPS pulses start")
+		}
+		p.locked = false
+		return
+	}
+	pulseTime := gpsTime.Add(now.Sub(receivedAt)).Truncate(time.Second).Add(time.Second)
+
+	if !p.locked {
+		p.logger.Infof("locked to the GPS's PPS pulses, with the system clock %s behind GPS time", pulseTime.Sub(now))
+	}
+	p.locked = true
+	p.offset = pulseTime.Sub(now)
+	if p.shm != nil {
+		p.shm.write(pulseTime, now)
+	}
+}
+
+// Now returns the current GPS time, by the system clock corrected by the latest offset from the
+// pulses, and whether the pulses are recent enough for that to be accurate.
+func (p *PPSSync) Now() (time.Time, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.clock.Now()
+	return now.Add(p.offset), p.isLocked(now)
+}
+
+// isLocked returns whether the latest offset is current. The caller must hold mu.
+func (p *PPSSync) isLocked(now time.Time) bool {
+	return p.locked && now.Sub(p.lastPulseAt) < ppsLockTimeout
+}
+
+// Readings returns the current GPS time, how far the system clock is behind it, and how many pulses
+// there have been.
+func (p *PPSSync) Readings() map[string]interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.clock.Now()
+	readings := map[string]interface{}{
+		"locked": p.isLocked(now),
+		"pulses": p.pulses,
+	}
+	if !p.lastPulseAt.IsZero() {
+		readings["last_pulse_age_sec"] = now.Sub(p.lastPulseAt).Seconds()
+	}
+	if p.locked {
+		readings["gps_time"] = now.Add(p.offset).Format(time.RFC3339Nano)
+		readings["clock_offset_sec"] = p.offset.Seconds()
+	}
+	return readings
+}
+
+func (p *PPSSync) closeSHM() error {
+	if p.shm == nil {
+		return nil
+	}
+	return p.shm.close()
+}
+
+// Close stops listening for pulses.
+func (p *PPSSync) Close() error {
+	p.workers.Stop()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closeSHM()
+}
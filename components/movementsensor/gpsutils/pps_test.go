@@ -0,0 +1,129 @@
+package gpsutils
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// fakeGPSTime is the latest time a GPS sent, and when it arrived.
+type fakeGPSTime struct {
+	gpsTime, receivedAt time.Time
+}
+
+func (f *fakeGPSTime) GPSTime() (time.Time, time.Time) {
+	return f.gpsTime, f.receivedAt
+}
+
+func TestPPSConfigValidate(t *testing.T) {
+	cfg := &PPSConfig{Board: "pi"}
+	_, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "digital_interrupt"))
+
+	cfg.DigitalInterrupt = "pps"
+	deps, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"pi"})
+}
+
+func TestPPSSync(t *testing.T) {
+	ticks := make(chan chan board.Tick, 1)
+	b := inject.NewBoard("pi")
+	b.DigitalInterruptByNameFunc = func(name string) (board.DigitalInterrupt, error) {
+		return &inject.DigitalInterrupt{}, nil
+	}
+	b.StreamTicksFunc = func(
+		ctx context.Context, interrupts []board.DigitalInterrupt, ch chan board.Tick, extra map[string]interface{},
+	) error {
+		ticks <- ch
+		return nil
+	}
+	deps := resource.Dependencies{board.Named("pi"): b}
+
+	// the system clock is 10.25s behind GPS time
+	start := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	source := &fakeGPSTime{}
+	p, err := NewPPSSync(context.Background(), &PPSConfig{Board: "pi", DigitalInterrupt: "pps"}, deps, source,
+		logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() { test.That(t, p.Close(), test.ShouldBeNil) }()
+	mockClock := clock.NewMock()
+	mockClock.Set(start)
+	p.clock = mockClock
+
+	// a pulse before the GPS knows the time can't be placed
+	p.pulse(1e9)
+	readings := p.Readings()
+	test.That(t, readings["locked"], test.ShouldBeFalse)
+	test.That(t, readings["pulses"], test.ShouldEqual, 1)
+
+	// the time of the pulse at 12:00:10 GPS time arrives 300ms after it, then comes the next pulse
+	source.gpsTime = start.Add(10 * time.Second)
+	source.receivedAt = start.Add(50 * time.Millisecond)
+	mockClock.Set(start.Add(750 * time.Millisecond))
+	(<-ticks) <- board.Tick{High: true, TimestampNanosec: 2e9}
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, p.Readings()["pulses"], test.ShouldEqual, 2)
+	})
+
+	readings = p.Readings()
+	test.That(t, readings["locked"], test.ShouldBeTrue)
+	test.That(t, readings["clock_offset_sec"], test.ShouldAlmostEqual, 10.25)
+	test.That(t, readings["gps_time"], test.ShouldEqual, "2024-03-01T12:00:11Z")
+	now, locked := p.Now()
+	test.That(t, locked, test.ShouldBeTrue)
+	test.That(t, now, test.ShouldEqual, start.Add(11*time.Second))
+
+	// noise on the line straight after a pulse isn't one
+	p.pulse(2e9 + 1e8)
+	test.That(t, p.Readings()["pulses"], test.ShouldEqual, 2)
+
+	// without pulses, the offset goes stale
+	mockClock.Add(3 * time.Second)
+	readings = p.Readings()
+	test.That(t, readings["locked"], test.ShouldBeFalse)
+	test.That(t, readings["last_pulse_age_sec"], test.ShouldAlmostEqual, 3)
+
+	// and a pulse long after the GPS last sent the time can't be placed either
+	p.pulse(5e9)
+	readings = p.Readings()
+	test.That(t, readings["locked"], test.ShouldBeFalse)
+	test.That(t, readings["pulses"], test.ShouldEqual, 3)
+}
+
+func TestSHMLayout(t *testing.T) {
+	test.That(t, newSHMLayout(8).size, test.ShouldEqual, 96)
+	test.That(t, newSHMLayout(4).size, test.ShouldEqual, 80)
+
+	l := newSHMLayout(8)
+	test.That(t, l.receiveSec, test.ShouldEqual, 24)
+	test.That(t, l.valid, test.ShouldEqual, 48)
+	test.That(t, l.receiveNSec, test.ShouldEqual, 56)
+
+	mem := make([]byte, l.size)
+	clockTime := time.Unix(1700000000, 0)
+	receiveTime := time.Unix(1699999989, 750123456)
+	writeSHMSample(mem, l, clockTime, receiveTime)
+	writeSHMSample(mem, l, clockTime, receiveTime)
+
+	order := binary.NativeEndian
+	test.That(t, order.Uint32(mem[0:]), test.ShouldEqual, 1)
+	test.That(t, order.Uint32(mem[4:]), test.ShouldEqual, 4)
+	test.That(t, order.Uint64(mem[l.clockSec:]), test.ShouldEqual, 1700000000)
+	test.That(t, order.Uint64(mem[l.receiveSec:]), test.ShouldEqual, 1699999989)
+	test.That(t, order.Uint32(mem[l.receiveUSec:]), test.ShouldEqual, 750123)
+	test.That(t, order.Uint32(mem[l.receiveNSec:]), test.ShouldEqual, 750123456)
+	test.That(t, int32(order.Uint32(mem[l.precision:])), test.ShouldEqual, ppsPrecision)
+	test.That(t, order.Uint32(mem[l.valid:]), test.ShouldEqual, 1)
+}
@@ -0,0 +1,220 @@
+// Package gpsutils contains GPS-related code shared between multiple components. This file is
+// about configuring what a receiver tracks and how often it reports, with commands sent to it at
+// startup.
+package gpsutils
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/resource"
+)
+
+// The command sets receivers understand.
+const (
+	// ProtocolPMTK is the NMEA extension MediaTek chips (and so most breakout boards) take.
+	ProtocolPMTK = "pmtk"
+	// ProtocolUBX is the binary protocol of u-blox receivers. The commands are the configuration
+	// interface from generation 9 (such as the ZED-F9P) on.
+	ProtocolUBX = "ubx"
+)
+
+// The constellations a receiver can be told to use.
+const (
+	ConstellationGPS     = "gps"
+	ConstellationGLONASS = "glonass"
+	ConstellationGalileo = "galileo"
+	ConstellationBeiDou  = "beidou"
+)
+
+const (
+	// defaultUpdateRateHz is how often receivers report without being told otherwise.
+	defaultUpdateRateHz = 1
+	minUpdateRateHz     = 0.1
+	maxPMTKUpdateRateHz = 10
+	maxUBXUpdateRateHz  = 25
+)
+
+// pmtkNMEAOutput asks for GLL, RMC, VTG, GGA, GSA, and GSV sentences, and nothing else, every fix.
+const pmtkNMEAOutput = "PMTK314,1,1,1,1,1,1,0,0,0,0,0,0,0,0,0,0,0,0,0"
+
+// u-blox configuration keys, from the interface description of generation 9 receivers.
+const (
+	ubxClassCfg    = 0x06
+	ubxIDCfgValset = 0x8a
+	ubxLayerRAM    = 0x01
+
+	ubxKeyRateMeas   = 0x30210001
+	ubxKeyGPSEna     = 0x1031001f
+	ubxKeySBASEna    = 0x10310020
+	ubxKeyGalEna     = 0x10310021
+	ubxKeyBDSEna     = 0x10310022
+	ubxKeyGLONASSEna = 0x10310025
+)
+
+// ReceiverConfig says what a receiver should track and how often it should report. Anything left
+// unset is left as the receiver has it.
+type ReceiverConfig struct {
+	// Protocol is the command set the receiver takes: "pmtk" or "ubx".
+	Protocol string `json:"protocol,omitempty"`
+	// SBAS turns satellite-based augmentation (WAAS, EGNOS, MSAS, GAGAN) on or off.
+	SBAS *bool `json:"sbas,omitempty"`
+	// Constellations, if given, are the only satellite systems the receiver uses: any of "gps",
+	// "glonass", "galileo" and "beidou".
+	Constellations []string `json:"constellations,omitempty"`
+	// UpdateRateHz is how many fixes the receiver reports each second.
+	UpdateRateHz float64 `json:"update_rate_hz,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid. defaultProtocol is the protocol used if the
+// config doesn't name one, or "" if it must.
+func (cfg *ReceiverConfig) Validate(path, defaultProtocol string) error {
+	protocol := cfg.protocol(defaultProtocol)
+	maxRate := 0.0
+	switch protocol {
+	case "":
+		return resource.NewConfigValidationFieldRequiredError(path, "protocol")
+	case ProtocolPMTK:
+		maxRate = maxPMTKUpdateRateHz
+	case ProtocolUBX:
+		maxRate = maxUBXUpdateRateHz
+	default:
+		return resource.NewConfigValidationError(path,
+			fmt.Errorf("protocol %q is not %q or %q", cfg.Protocol, ProtocolPMTK, ProtocolUBX))
+	}
+
+	for _, c := range cfg.Constellations {
+		switch strings.ToLower(c) {
+		case ConstellationGPS, ConstellationGLONASS, ConstellationGalileo, ConstellationBeiDou:
+		default:
+			return resource.NewConfigValidationError(path, fmt.Errorf(
+				"constellation %q is not one of %q, %q, %q or %q",
+				c, ConstellationGPS, ConstellationGLONASS, ConstellationGalileo, ConstellationBeiDou))
+		}
+	}
+
+	if cfg.UpdateRateHz != 0 && (cfg.UpdateRateHz < minUpdateRateHz || cfg.UpdateRateHz > maxRate) {
+		return resource.NewConfigValidationError(path,
+			fmt.Errorf("update_rate_hz must be from %v to %v for %s receivers", minUpdateRateHz, maxRate, protocol))
+	}
+	return nil
+}
+
+// protocol returns the command set the receiver takes.
+func (cfg *ReceiverConfig) protocol(defaultProtocol string) string {
+	if cfg == nil || cfg.Protocol == "" {
+		return defaultProtocol
+	}
+	return strings.ToLower(cfg.Protocol)
+}
+
+// usesConstellation returns whether the receiver should use the named constellation.
+func (cfg *ReceiverConfig) usesConstellation(name string) bool {
+	for _, c := range cfg.Constellations {
+		if strings.ToLower(c) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ReceiverCommands returns the commands which set a receiver up as cfg says, in the order to send
+// them. cfg may be nil, to leave everything as the receiver has it. defaultProtocol is the
+// protocol used if cfg doesn't name one.
+//
+// PMTK receivers are always told which sentences to send and how often, once a second unless cfg
+// says otherwise, since those are the sentences we parse.
+func ReceiverCommands(cfg *ReceiverConfig, defaultProtocol string) [][]byte {
+	switch cfg.protocol(defaultProtocol) {
+	case ProtocolPMTK:
+		return pmtkCommands(cfg)
+	case ProtocolUBX:
+		if cfg == nil {
+			return nil
+		}
+		if valset := ubxValset(cfg); valset != nil {
+			return [][]byte{valset}
+		}
+	}
+	return nil
+}
+
+// pmtkCommands returns the PMTK sentences which set a receiver up as cfg says.
+func pmtkCommands(cfg *ReceiverConfig) [][]byte {
+	rate := float64(defaultUpdateRateHz)
+	if cfg != nil && cfg.UpdateRateHz > 0 {
+		rate = cfg.UpdateRateHz
+	}
+	cmds := []string{
+		pmtkNMEAOutput,
+		fmt.Sprintf("PMTK220,%d", int(math.Round(1000/rate))),
+	}
+
+	if cfg != nil && cfg.SBAS != nil {
+		if *cfg.SBAS {
+			// search for SBAS satellites, and use their corrections
+			cmds = append(cmds, "PMTK313,1", "PMTK301,2")
+		} else {
+			cmds = append(cmds, "PMTK313,0", "PMTK301,0")
+		}
+	}
+
+	if cfg != nil && len(cfg.Constellations) > 0 {
+		// GPS, GLONASS, Galileo, full Galileo and BeiDou
+		cmds = append(cmds, fmt.Sprintf("PMTK353,%d,%d,%d,0,%d",
+			boolToInt(cfg.usesConstellation(ConstellationGPS)),
+			boolToInt(cfg.usesConstellation(ConstellationGLONASS)),
+			boolToInt(cfg.usesConstellation(ConstellationGalileo)),
+			boolToInt(cfg.usesConstellation(ConstellationBeiDou))))
+	}
+
+	sentences := make([][]byte, 0, len(cmds))
+	for _, cmd := range cmds {
+		sentences = append(sentences, movementsensor.PMTKAddChk([]byte(cmd)))
+	}
+	return sentences
+}
+
+func boolToInt(on bool) int {
+	if on {
+		return 1
+	}
+	return 0
+}
+
+// ubxValset returns a UBX-CFG-VALSET message which sets a receiver up as cfg says, in its RAM so
+// it lasts until the receiver is reset, or nil if there's nothing to set. Changing the
+// constellations restarts the receiver's tracking, so it needs a minute to get its fix back.
+func ubxValset(cfg *ReceiverConfig) []byte {
+	// version 0, the layers to set, and two reserved bytes
+	payload := []byte{0, ubxLayerRAM, 0, 0}
+	empty := len(payload)
+
+	if cfg.UpdateRateHz > 0 {
+		payload = binary.LittleEndian.AppendUint32(payload, ubxKeyRateMeas)
+		payload = binary.LittleEndian.AppendUint16(payload, uint16(math.Round(1000/cfg.UpdateRateHz)))
+	}
+	if cfg.SBAS != nil {
+		payload = appendUBXBool(payload, ubxKeySBASEna, *cfg.SBAS)
+	}
+	if len(cfg.Constellations) > 0 {
+		payload = appendUBXBool(payload, ubxKeyGPSEna, cfg.usesConstellation(ConstellationGPS))
+		payload = appendUBXBool(payload, ubxKeyGLONASSEna, cfg.usesConstellation(ConstellationGLONASS))
+		payload = appendUBXBool(payload, ubxKeyGalEna, cfg.usesConstellation(ConstellationGalileo))
+		payload = appendUBXBool(payload, ubxKeyBDSEna, cfg.usesConstellation(ConstellationBeiDou))
+	}
+
+	if len(payload) == empty {
+		return nil
+	}
+	return UBXMessage{Class: ubxClassCfg, ID: ubxIDCfgValset, Payload: payload}.Bytes()
+}
+
+// appendUBXBool appends a boolean configuration key and its value to a UBX-CFG-VALSET payload.
+func appendUBXBool(payload []byte, key uint32, value bool) []byte {
+	payload = binary.LittleEndian.AppendUint32(payload, key)
+	return append(payload, byte(boolToInt(value)))
+}
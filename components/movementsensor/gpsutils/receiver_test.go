@@ -0,0 +1,90 @@
+package gpsutils
+
+import (
+	"bytes"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/resource"
+)
+
+func TestReceiverConfigValidate(t *testing.T) {
+	cfg := &ReceiverConfig{UpdateRateHz: 5}
+	test.That(t, cfg.Validate("path", ""), test.ShouldBeError,
+		resource.NewConfigValidationFieldRequiredError("path", "protocol"))
+	test.That(t, cfg.Validate("path", ProtocolPMTK), test.ShouldBeNil)
+
+	cfg.Protocol = "sirf"
+	test.That(t, cfg.Validate("path", ProtocolPMTK), test.ShouldNotBeNil)
+
+	cfg = &ReceiverConfig{Protocol: "UBX", UpdateRateHz: 20, Constellations: []string{"GPS", "galileo"}}
+	test.That(t, cfg.Validate("path", ""), test.ShouldBeNil)
+	cfg.Protocol = ProtocolPMTK
+	err := cfg.Validate("path", "")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "update_rate_hz must be from 0.1 to 10")
+
+	cfg = &ReceiverConfig{Protocol: ProtocolUBX, Constellations: []string{"gps", "navic"}}
+	err = cfg.Validate("path", "")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"navic"`)
+}
+
+func pmtkCommand(cmd string) []byte {
+	return movementsensor.PMTKAddChk([]byte(cmd))
+}
+
+func TestPMTKReceiverCommands(t *testing.T) {
+	// unconfigured, PMTK receivers send the sentences we parse once a second
+	test.That(t, ReceiverCommands(nil, ProtocolPMTK), test.ShouldResemble, [][]byte{
+		pmtkCommand("PMTK314,1,1,1,1,1,1,0,0,0,0,0,0,0,0,0,0,0,0,0"),
+		pmtkCommand("PMTK220,1000"),
+	})
+
+	sbas := false
+	cfg := &ReceiverConfig{
+		SBAS:           &sbas,
+		Constellations: []string{"gps", "BeiDou"},
+		UpdateRateHz:   5,
+	}
+	test.That(t, ReceiverCommands(cfg, ProtocolPMTK), test.ShouldResemble, [][]byte{
+		pmtkCommand("PMTK314,1,1,1,1,1,1,0,0,0,0,0,0,0,0,0,0,0,0,0"),
+		pmtkCommand("PMTK220,200"),
+		pmtkCommand("PMTK313,0"),
+		pmtkCommand("PMTK301,0"),
+		pmtkCommand("PMTK353,1,0,0,0,1"),
+	})
+}
+
+func TestUBXReceiverCommands(t *testing.T) {
+	test.That(t, ReceiverCommands(nil, ""), test.ShouldBeNil)
+	test.That(t, ReceiverCommands(&ReceiverConfig{Protocol: ProtocolUBX}, ""), test.ShouldBeNil)
+
+	sbas := true
+	cfg := &ReceiverConfig{Protocol: ProtocolUBX, SBAS: &sbas, UpdateRateHz: 10}
+	test.That(t, ReceiverCommands(cfg, ProtocolPMTK), test.ShouldResemble, [][]byte{{
+		0xB5, 0x62, 0x06, 0x8a, 15, 0,
+		// version, RAM layer, reserved
+		0, 1, 0, 0,
+		// CFG-RATE-MEAS of 100ms
+		0x01, 0x00, 0x21, 0x30, 100, 0,
+		// CFG-SIGNAL-SBAS_ENA on
+		0x20, 0x00, 0x31, 0x10, 1,
+		0xB8, 0xF7,
+	}})
+
+	cfg = &ReceiverConfig{Protocol: ProtocolUBX, Constellations: []string{"gps", "Galileo"}}
+	cmds := ReceiverCommands(cfg, "")
+	test.That(t, len(cmds), test.ShouldEqual, 1)
+	_, msg, err := NewMixedReader(bytes.NewReader(cmds[0])).Next()
+	test.That(t, err, test.ShouldBeNil)
+	// GPS, GLONASS, Galileo and BeiDou, each a key and a byte
+	test.That(t, msg.Payload[4:], test.ShouldResemble, []byte{
+		0x1f, 0x00, 0x31, 0x10, 1,
+		0x25, 0x00, 0x31, 0x10, 0,
+		0x21, 0x00, 0x31, 0x10, 1,
+		0x22, 0x00, 0x31, 0x10, 0,
+	})
+}
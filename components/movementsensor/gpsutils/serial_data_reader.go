@@ -6,14 +6,15 @@ package gpsutils
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"sync"
 
-	"github.com/jacobsa/go-serial/serial"
 	"go.viam.com/utils"
 
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/utils/serialport"
 )
 
 // SerialDataReader implements the DataReader interface (defined in component.go) by interacting
@@ -29,9 +30,8 @@ type SerialDataReader struct {
 
 // NewSerialDataReader constructs a new DataReader that gets its NMEA messages over a serial port.
 func NewSerialDataReader(config *SerialConfig, logger logging.Logger) (DataReader, error) {
-	serialPath := config.SerialPath
-	if serialPath == "" {
-		return nil, fmt.Errorf("SerialNMEAMovementSensor expected non-empty string for %q", config.SerialPath)
+	if config.SerialPath == "" && config.SerialUSB == nil {
+		return nil, errors.New("SerialNMEAMovementSensor expected a serial_path or serial_usb")
 	}
 
 	baudRate := config.SerialBaudRate
@@ -40,18 +40,21 @@ func NewSerialDataReader(config *SerialConfig, logger logging.Logger) (DataReade
 		logger.Info("SerialNMEAMovementSensor: serial_baud_rate using default 38400")
 	}
 
-	options := serial.OpenOptions{
-		PortName:        serialPath,
+	port, err := serialport.Open(serialport.OpenOptions{
+		Path:            config.SerialPath,
+		USB:             config.SerialUSB,
 		BaudRate:        uint(baudRate),
-		DataBits:        8,
-		StopBits:        1,
 		MinimumReadSize: 4,
-	}
-
-	dev, err := serial.Open(options)
+	}, logger)
 	if err != nil {
 		return nil, err
 	}
+	for _, cmd := range ReceiverCommands(config.Receiver, "") {
+		if _, err := port.Write(cmd); err != nil {
+			return nil, errors.Join(fmt.Errorf("failed to configure the receiver: %w", err), port.Close())
+		}
+	}
+	dev := wrapSerialPort(port)
 
 	data := make(chan string)
 	cancelCtx, cancelFunc := context.WithCancel(context.Background())
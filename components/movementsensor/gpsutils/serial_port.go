@@ -0,0 +1,40 @@
+package gpsutils
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"go.viam.com/utils"
+)
+
+// emptyReadRetrier blocks reads on ports which time out by returning no data and no error, as
+// Windows serial ports do. Otherwise bufio.Reader gives up on the port with io.ErrNoProgress.
+type emptyReadRetrier struct {
+	io.ReadWriteCloser
+	closed     context.Context
+	markClosed func()
+	interval   time.Duration
+}
+
+func newEmptyReadRetrier(dev io.ReadWriteCloser, interval time.Duration) *emptyReadRetrier {
+	closed, markClosed := context.WithCancel(context.Background())
+	return &emptyReadRetrier{ReadWriteCloser: dev, closed: closed, markClosed: markClosed, interval: interval}
+}
+
+func (r *emptyReadRetrier) Read(p []byte) (int, error) {
+	for {
+		n, err := r.ReadWriteCloser.Read(p)
+		if n > 0 || err != nil || len(p) == 0 {
+			return n, err
+		}
+		if !utils.SelectContextOrWait(r.closed, r.interval) {
+			return 0, io.ErrClosedPipe
+		}
+	}
+}
+
+func (r *emptyReadRetrier) Close() error {
+	r.markClosed()
+	return r.ReadWriteCloser.Close()
+}
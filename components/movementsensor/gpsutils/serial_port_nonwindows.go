@@ -0,0 +1,10 @@
+//go:build !windows
+
+package gpsutils
+
+import "io"
+
+// wrapSerialPort returns dev as is, since reads block until MinimumReadSize bytes arrive.
+func wrapSerialPort(dev io.ReadWriteCloser) io.ReadWriteCloser {
+	return dev
+}
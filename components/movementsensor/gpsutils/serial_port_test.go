@@ -0,0 +1,54 @@
+package gpsutils
+
+import (
+	"bufio"
+	"io"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+)
+
+// timingOutPort returns no data and no error on most reads, like a Windows serial port whose read
+// timed out.
+type timingOutPort struct {
+	io.ReadWriteCloser
+	reads  int
+	chunks []string
+}
+
+func (p *timingOutPort) Read(buf []byte) (int, error) {
+	p.reads++
+	if p.reads%3 != 0 {
+		return 0, nil
+	}
+	if len(p.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(buf, p.chunks[0])
+	p.chunks = p.chunks[1:]
+	return n, nil
+}
+
+func (p *timingOutPort) Close() error {
+	return nil
+}
+
+func TestEmptyReadRetrier(t *testing.T) {
+	port := &timingOutPort{chunks: []string{"$GPGGA,1", "23\n$GPRMC\n"}}
+	r := bufio.NewReader(newEmptyReadRetrier(port, time.Millisecond))
+
+	line, err := r.ReadString('\n')
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, line, test.ShouldEqual, "$GPGGA,123\n")
+	line, err = r.ReadString('\n')
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, line, test.ShouldEqual, "$GPRMC\n")
+	_, err = r.ReadString('\n')
+	test.That(t, err, test.ShouldEqual, io.EOF)
+
+	retrier := newEmptyReadRetrier(&timingOutPort{}, time.Hour)
+	test.That(t, retrier.Close(), test.ShouldBeNil)
+	_, err = retrier.Read(make([]byte, 4))
+	test.That(t, err, test.ShouldEqual, io.ErrClosedPipe)
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package gpsutils
+
+import (
+	"io"
+	"time"
+)
+
+// wrapSerialPort retries reads that time out with no data, which Windows serial ports return
+// whenever the GPS has not sent anything within the read timeout.
+func wrapSerialPort(dev io.ReadWriteCloser) io.ReadWriteCloser {
+	return newEmptyReadRetrier(dev, 10*time.Millisecond)
+}
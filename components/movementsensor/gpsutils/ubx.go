@@ -0,0 +1,112 @@
+// Package gpsutils contains GPS-related code shared between multiple components. This file is
+// about u-blox's binary UBX protocol, which u-blox receivers send alongside NMEA.
+package gpsutils
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	ubxSync1 = 0xB5
+	ubxSync2 = 0x62
+
+	// maxUBXPayload is the longest payload we'll read. Real messages are far shorter, so a longer
+	// length means we've lost our place in the stream.
+	maxUBXPayload = 4096
+)
+
+// UBXMessage is a message in u-blox's binary UBX protocol.
+type UBXMessage struct {
+	Class   byte
+	ID      byte
+	Payload []byte
+}
+
+// Bytes encodes m as a UBX frame: the sync characters, class, ID, length, payload and checksum.
+func (m UBXMessage) Bytes() []byte {
+	frame := make([]byte, 0, len(m.Payload)+8)
+	frame = append(frame, ubxSync1, ubxSync2, m.Class, m.ID)
+	frame = binary.LittleEndian.AppendUint16(frame, uint16(len(m.Payload)))
+	frame = append(frame, m.Payload...)
+	ckA, ckB := ubxChecksum(frame[2:])
+	return append(frame, ckA, ckB)
+}
+
+// ubxChecksum returns the 8-bit Fletcher checksum UBX frames end with, over everything between the
+// sync characters and the checksum.
+func ubxChecksum(data []byte) (byte, byte) {
+	var ckA, ckB byte
+	for _, b := range data {
+		ckA += b
+		ckB += ckA
+	}
+	return ckA, ckB
+}
+
+// MixedReader reads the mix of NMEA sentences and UBX messages which u-blox receivers send down the
+// same port.
+type MixedReader struct {
+	r *bufio.Reader
+}
+
+// NewMixedReader returns a MixedReader reading from r.
+func NewMixedReader(r io.Reader) *MixedReader {
+	return &MixedReader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next NMEA sentence or UBX message; whichever it is, the other is empty. Bytes
+// which are part of neither are skipped. A UBX message with a bad checksum is an error, after which
+// the stream can still be read from.
+func (mr *MixedReader) Next() (string, *UBXMessage, error) {
+	for {
+		b, err := mr.r.ReadByte()
+		if err != nil {
+			return "", nil, err
+		}
+
+		switch b {
+		case '$':
+			line, err := mr.r.ReadString('\n')
+			if err != nil {
+				return "", nil, err
+			}
+			return "$" + line, nil, nil
+		case ubxSync1:
+			next, err := mr.r.Peek(1)
+			if err != nil {
+				return "", nil, err
+			}
+			if next[0] != ubxSync2 {
+				continue
+			}
+			msg, err := mr.readUBX()
+			return "", msg, err
+		}
+	}
+}
+
+// readUBX reads the rest of a UBX frame, once its first sync character has been read.
+func (mr *MixedReader) readUBX() (*UBXMessage, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(mr.r, header); err != nil {
+		return nil, err
+	}
+	length := int(binary.LittleEndian.Uint16(header[3:]))
+	if length > maxUBXPayload {
+		return nil, fmt.Errorf("UBX message of %d bytes is too long", length)
+	}
+
+	body := make([]byte, length+2)
+	if _, err := io.ReadFull(mr.r, body); err != nil {
+		return nil, err
+	}
+	ckA, ckB := ubxChecksum(append(header[1:], body[:length]...))
+	if ckA != body[length] || ckB != body[length+1] {
+		return nil, errors.New("UBX message has a bad checksum")
+	}
+	return &UBXMessage{Class: header[1], ID: header[2], Payload: body[:length]}, nil
+}
@@ -0,0 +1,53 @@
+package gpsutils
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestUBXMessageBytes(t *testing.T) {
+	// CFG-RATE setting 5 Hz, as given in u-blox's documentation
+	msg := UBXMessage{Class: 0x06, ID: 0x08, Payload: []byte{0xC8, 0x00, 0x01, 0x00, 0x01, 0x00}}
+	test.That(t, msg.Bytes(), test.ShouldResemble,
+		[]byte{0xB5, 0x62, 0x06, 0x08, 0x06, 0x00, 0xC8, 0x00, 0x01, 0x00, 0x01, 0x00, 0xDE, 0x6A})
+}
+
+func TestMixedReader(t *testing.T) {
+	first := UBXMessage{Class: 0x01, ID: 0x3C, Payload: []byte{1, 2, 3}}
+	second := UBXMessage{Class: 0x05, ID: 0x01, Payload: []byte{0x06, 0x08}}
+	corrupt := first.Bytes()
+	corrupt[len(corrupt)-1]++
+
+	var stream bytes.Buffer
+	stream.WriteString("ual junk\r\n")
+	stream.Write(first.Bytes())
+	stream.WriteString("$GNGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47\r\n")
+	stream.Write([]byte{0xB5, 0x00})
+	stream.Write(corrupt)
+	stream.Write(second.Bytes())
+	r := NewMixedReader(&stream)
+
+	line, msg, err := r.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, line, test.ShouldBeEmpty)
+	test.That(t, *msg, test.ShouldResemble, first)
+
+	line, msg, err = r.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, msg, test.ShouldBeNil)
+	test.That(t, line, test.ShouldEqual, "$GNGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47\r\n")
+
+	_, _, err = r.Next()
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "checksum")
+
+	_, msg, err = r.Next()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, *msg, test.ShouldResemble, second)
+
+	_, _, err = r.Next()
+	test.That(t, err, test.ShouldEqual, io.EOF)
+}
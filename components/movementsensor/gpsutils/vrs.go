@@ -7,16 +7,32 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net"
 	"net/url"
 	"strings"
+	"time"
 
+	geo "github.com/kellydunn/golang-geo"
+
+	"go.viam.com/rdk/components/movementsensor"
 	"go.viam.com/rdk/logging"
 )
 
+// VRSStream is a connection to a virtual reference station, from which its corrections can be read.
+type VRSStream struct {
+	*bufio.ReadWriter
+	conn net.Conn
+}
+
+// Close closes the connection to the caster.
+func (s *VRSStream) Close() error {
+	return s.conn.Close()
+}
+
 // ConnectToVirtualBase is responsible for establishing a connection to
 // a virtual base station using the NTRIP protocol with enhanced error handling and retries.
-func ConnectToVirtualBase(ntripInfo *NtripInfo, logger logging.Logger) (*bufio.ReadWriter, error) {
+func ConnectToVirtualBase(ntripInfo *NtripInfo, logger logging.Logger) (*VRSStream, error) {
 	mp := "/" + ntripInfo.MountPoint
 	credentials := ntripInfo.username + ":" + ntripInfo.password
 	credentialsBase64 := base64.StdEncoding.EncodeToString([]byte(credentials))
@@ -33,7 +49,10 @@ func ConnectToVirtualBase(ntripInfo *NtripInfo, logger logging.Logger) (*bufio.R
 		return nil, err
 	}
 
-	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+	stream := &VRSStream{
+		ReadWriter: bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn)),
+		conn:       conn,
+	}
 
 	// Construct HTTP headers with CRLF line endings
 	httpHeaders := "GET " + mp + " HTTP/1.1\r\n" +
@@ -44,18 +63,107 @@ func ConnectToVirtualBase(ntripInfo *NtripInfo, logger logging.Logger) (*bufio.R
 		"User-Agent: NTRIP viam\r\n\r\n"
 
 	// Send HTTP headers over the TCP connection
-	_, err = rw.Write([]byte(httpHeaders))
+	_, err = stream.Write([]byte(httpHeaders))
 	if err != nil {
-		return nil, fmt.Errorf("failed to send HTTP headers: %w", err)
+		return nil, errors.Join(fmt.Errorf("failed to send HTTP headers: %w", err), conn.Close())
 	}
-	err = rw.Flush()
+	err = stream.Flush()
 	if err != nil {
-		return nil, fmt.Errorf("failed to write to buffer: %w", err)
+		return nil, errors.Join(fmt.Errorf("failed to write to buffer: %w", err), conn.Close())
 	}
 
 	logger.Debugf("request header: %v\n", httpHeaders)
 	logger.Debug("HTTP headers sent successfully.")
-	return rw, nil
+	return stream, nil
+}
+
+// ConnectToVRS connects to the virtual reference station at ntripInfo's mountpoint and uploads the
+// rover's position to it as the given GGA sentence. The caster computes the station's corrections
+// for wherever the rover is, so sends none until it has been told.
+func ConnectToVRS(ntripInfo *NtripInfo, gga []byte, logger logging.Logger) (*VRSStream, error) {
+	stream, err := ConnectToVirtualBase(ntripInfo, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stream.awaitResponse(logger); err != nil {
+		return nil, errors.Join(err, stream.Close())
+	}
+
+	if err := stream.SendGGA(gga, logger); err != nil {
+		return nil, errors.Join(err, stream.Close())
+	}
+	return stream, nil
+}
+
+// awaitResponse reads from the socket until we know if a successful connection has been
+// established.
+func (s *VRSStream) awaitResponse(logger logging.Logger) error {
+	for {
+		line, _, err := s.ReadLine()
+		response := string(line)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				logger.Error("Failed to read server response:", err)
+			}
+			return err
+		}
+
+		if strings.HasPrefix(response, "HTTP/1.1 ") {
+			if strings.Contains(response, "200 OK") {
+				logger.Debug("Successful connection established with NTRIP caster.")
+				return nil
+			}
+			logger.Errorf("Bad HTTP response: %v", response)
+			return fmt.Errorf("server responded with non-OK status: %s", response)
+		}
+	}
+}
+
+// SendGGA uploads the rover's position to the caster.
+func (s *VRSStream) SendGGA(gga []byte, logger logging.Logger) error {
+	logger.Debugf("Writing GGA message: %v\n", string(gga))
+
+	if _, err := s.Write(gga); err != nil {
+		logger.Error("Failed to send NMEA data:", err)
+		return err
+	}
+
+	if err := s.Flush(); err != nil {
+		logger.Error("failed to write to buffer: ", err)
+		return err
+	}
+
+	logger.Debug("GGA message sent successfully.")
+	return nil
+}
+
+// MakeGGAMessage formats the rover's position as the NMEA GGA sentence which virtual reference
+// stations expect to be sent.
+func MakeGGAMessage(
+	position *geo.Point, altitude float64, fixQuality, satellites int, hdop float64, at time.Time,
+) []byte {
+	northSouth, eastWest := "N", "E"
+	if position.Lat() < 0 {
+		northSouth = "S"
+	}
+	if position.Lng() < 0 {
+		eastWest = "W"
+	}
+	body := fmt.Sprintf("GPGGA,%s,%s,%s,%s,%s,%d,%02d,%.1f,%.1f,M,0.0,M,,",
+		at.UTC().Format("150405.00"),
+		nmeaDegrees(position.Lat(), 2), northSouth,
+		nmeaDegrees(position.Lng(), 3), eastWest,
+		fixQuality, satellites, hdop, altitude)
+	return []byte(fmt.Sprintf("$%s*%02X\r\n", body, movementsensor.PMTKChecksum([]byte(body))))
+}
+
+// nmeaDegrees formats an angle as NMEA does: whole degrees, padded to the given number of digits,
+// followed by decimal minutes. The sign is left to the hemisphere field.
+func nmeaDegrees(degrees float64, degreeDigits int) string {
+	degrees = math.Abs(degrees)
+	whole := math.Floor(degrees)
+	return fmt.Sprintf("%0*d%08.5f", degreeDigits, int(whole), (degrees-whole)*60)
 }
 
 // GetGGAMessage checks if a GGA message exists in the buffer and returns it.
@@ -0,0 +1,103 @@
+package gpsutils
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+)
+
+func TestMakeGGAMessage(t *testing.T) {
+	at := time.Date(2024, 5, 1, 17, 28, 14, 0, time.UTC)
+	gga := MakeGGAMessage(geo.NewPoint(37.391098, -122.037826), 18.9, 2, 6, 1.2, at)
+	test.That(t, string(gga), test.ShouldStartWith, "$GPGGA,172814.00,3723.46588,N,12202.26956,W,2,06,1.2,18.9,M,")
+	test.That(t, string(gga), test.ShouldEndWith, "\r\n")
+
+	// what we send has to be something a GPS would have said, so the parser must accept it
+	var parser NmeaParser
+	test.That(t, parser.ParseAndUpdate(strings.TrimSpace(string(gga))), test.ShouldBeNil)
+	test.That(t, parser.Location.Lat(), test.ShouldAlmostEqual, 37.391098, 1e-6)
+	test.That(t, parser.Location.Lng(), test.ShouldAlmostEqual, -122.037826, 1e-6)
+	test.That(t, parser.Alt, test.ShouldAlmostEqual, 18.9)
+	test.That(t, parser.FixQuality, test.ShouldEqual, 2)
+	test.That(t, parser.SatsInUse, test.ShouldEqual, 6)
+}
+
+func TestConnectToVRS(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	test.That(t, err, test.ShouldBeNil)
+	defer listener.Close()
+
+	gga := MakeGGAMessage(geo.NewPoint(40, -74), 10, 1, 8, 0.9, time.Now())
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+		// skip the request's headers
+		for {
+			line, err := rw.ReadString('\n')
+			if err != nil || line == "\r\n" {
+				break
+			}
+		}
+		rw.WriteString("HTTP/1.1 200 OK\r\n\r\n")
+		rw.Flush()
+		line, _ := rw.ReadString('\n')
+		received <- line
+		rw.WriteString("corrections")
+		rw.Flush()
+	}()
+
+	info, err := NewNtripInfo(&NtripConfig{
+		NtripURL:        "http://" + listener.Addr().String(),
+		NtripMountpoint: "VRS",
+		NtripUser:       "user",
+		NtripPass:       "pwd",
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	stream, err := ConnectToVRS(info, gga, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer stream.Close()
+	test.That(t, <-received, test.ShouldEqual, string(gga))
+
+	// whatever follows the response is the correction stream itself
+	data := make([]byte, len("corrections"))
+	_, err = io.ReadFull(stream, data)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(data), test.ShouldEqual, "corrections")
+}
+
+func TestConnectToVRSRejected(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	test.That(t, err, test.ShouldBeNil)
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("HTTP/1.1 401 Unauthorized\r\n\r\n"))
+	}()
+
+	info, err := NewNtripInfo(&NtripConfig{NtripURL: "http://" + listener.Addr().String()}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	_, err = ConnectToVRS(info, []byte("$GPGGA\r\n"), logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "401 Unauthorized")
+}
@@ -0,0 +1,566 @@
+//go:build linux
+
+// Package icm20948 implements the movementsensor interface for a TDK InvenSense ICM-20948 9-axis IMU. A
+// datasheet for this chip is at
+// https://invensense.tdk.com/wp-content/uploads/2016/06/DS-000189-ICM-20948-v1.3.pdf
+//
+// We read the accelerometer, gyroscope, and thermometer, and the AK09916 magnetometer packaged alongside them,
+// which we talk to directly by putting the chip's auxiliary I2C bus into bypass mode. The chip's digital motion
+// processor needs firmware uploaded to it, so instead we estimate orientation ourselves with a Madgwick filter.
+// Without the magnetometer (for example, near motors, where it is useless) the yaw of that orientation drifts
+// and there is no compass heading.
+//
+// The gyroscope bias and the magnetometer's hard iron offset can be calibrated through DoCommand:
+//   - "calibrate_gyroscope" averages the gyroscope over "seconds" (default 2) while the sensor is held still.
+//   - "start_magnetometer_calibration" and "finish_magnetometer_calibration" bracket a period in which the
+//     sensor should be rotated through every orientation.
+//   - "save_calibration" writes the calibration to calibration_file, from which it is loaded at startup, and
+//     "get_calibration" returns it.
+//
+// The chip has two possible I2C addresses, which can be selected by wiring the AD0 pin to either ground (0x68,
+// the default) or hot (0x69). If you use the alternate address, your config file for this component must set
+// its "use_alt_i2c_address" boolean to true.
+package icm20948
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/components/movementsensor/imufusion"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("imu-icm20948")
+
+const (
+	defaultAddress   = 0x68
+	alternateAddress = 0x69
+	expectedChipID   = 0xEA
+
+	// These are all in user bank 0, which is selected at reset.
+	whoAmIRegister      = 0x00
+	powerMgmt1Register  = 0x06
+	powerMgmt2Register  = 0x07
+	intPinCfgRegister   = 0x0F
+	accelDataRegister   = 0x2D // followed by gyroscope and temperature data
+	bankSelectRegister  = 0x7F
+	powerMgmt1Reset     = 0x80
+	powerMgmt1AutoClock = 0x01
+	intPinCfgBypass     = 0x02
+
+	magnetometerAddress  = 0x0C
+	magnetometerChipID   = 0x09
+	magWhoAmIRegister    = 0x01
+	magStatus1Register   = 0x10 // followed by magnetometer data, a dummy byte, and status 2
+	magControl2Register  = 0x31
+	magContinuous100Hz   = 0x08
+	magDataReady         = 0x01
+	magOverflow          = 0x08
+	microteslaPerMagLSB  = 0.15
+	accelLSBPerG         = 16384 // at the default range of +/- 2g
+	gyroLSBPerDegPerSec  = 131   // at the default range of +/- 250 degrees per second
+	tempLSBPerDegC       = 333.87
+	tempOffsetDegC       = 21
+	standardGravityMPSS  = 9.80665
+	defaultGyroCalPeriod = 2 * time.Second
+
+	pollPeriod = 10 * time.Millisecond
+)
+
+// Config is used to configure the attributes of the chip.
+type Config struct {
+	I2cBus                 string `json:"i2c_bus"`
+	UseAlternateI2CAddress bool   `json:"use_alt_i2c_address,omitempty"`
+	DisableMagnetometer    bool   `json:"disable_magnetometer,omitempty"`
+	// CalibrationFile is where the "save_calibration" DoCommand stores the calibration, and where it is loaded
+	// from at startup.
+	CalibrationFile string `json:"calibration_file,omitempty"`
+	// FusionBeta is the gain of the orientation filter; larger values trust the gyroscope less.
+	FusionBeta float64 `json:"fusion_beta,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid, and then returns the list of things we
+// depend on.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.I2cBus == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "i2c_bus")
+	}
+	if conf.FusionBeta < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("fusion_beta cannot be negative"))
+	}
+
+	var deps []string
+	return deps, nil
+}
+
+func init() {
+	resource.RegisterComponent(movementsensor.API, model, resource.Registration[movementsensor.MovementSensor, *Config]{
+		Constructor: newIcm20948,
+	})
+}
+
+type icm20948 struct {
+	resource.Named
+	resource.AlwaysRebuild
+	bus             buses.I2C
+	i2cAddress      byte
+	useMagnetometer bool
+	calibrationFile string
+	fusion          *imufusion.Madgwick
+
+	// lock mu before reading or writing anything below.
+	mu                 sync.Mutex
+	rawAngularVelocity spatialmath.AngularVelocity
+	angularVelocity    spatialmath.AngularVelocity
+	linearAcceleration r3.Vector
+	rawMagneticField   r3.Vector
+	magneticField      r3.Vector
+	temperature        float64
+	calibration        calibrationData
+	// while calibrating the magnetometer, these are the extremes of the field seen so far
+	magCalibrating bool
+	magMin, magMax r3.Vector
+	// Stores the most recent error from the background goroutine
+	err movementsensor.LastError
+
+	workers utils.StoppableWorkers
+	logger  logging.Logger
+}
+
+// calibrationData is what is stored in the calibration file.
+type calibrationData struct {
+	GyroBias           r3.Vector `json:"gyro_bias_degs_per_sec"`
+	MagnetometerOffset r3.Vector `json:"magnetometer_offset_microtesla"`
+}
+
+func addressReadError(err error, address byte, bus string) error {
+	msg := fmt.Sprintf("can't read from I2C address %d on bus %s", address, bus)
+	return errors.Wrap(err, msg)
+}
+
+func unexpectedDeviceError(address, chipID byte) error {
+	return errors.Errorf("unexpected non-ICM-20948 device at address %d: chip id '%d'", address, chipID)
+}
+
+func newIcm20948(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (movementsensor.MovementSensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+
+	bus, err := buses.NewI2cBus(newConf.I2cBus)
+	if err != nil {
+		return nil, err
+	}
+	return makeIcm20948(ctx, deps, conf, logger, bus)
+}
+
+// This function is separated from newIcm20948 solely so you can inject a mock I2C bus in tests.
+func makeIcm20948(
+	ctx context.Context,
+	_ resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+	bus buses.I2C,
+) (movementsensor.MovementSensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+
+	address := byte(defaultAddress)
+	if newConf.UseAlternateI2CAddress {
+		address = alternateAddress
+	}
+	logger.CDebugf(ctx, "Using address %d for ICM-20948 sensor", address)
+
+	sensor := &icm20948{
+		Named:           conf.ResourceName().AsNamed(),
+		bus:             bus,
+		i2cAddress:      address,
+		useMagnetometer: !newConf.DisableMagnetometer,
+		calibrationFile: newConf.CalibrationFile,
+		fusion:          imufusion.NewMadgwick(newConf.FusionBeta),
+		logger:          logger,
+		// On overloaded boards, the I2C bus can become flaky. Only report errors if at least 5 of
+		// the last 10 attempts to talk to the device have failed.
+		err: movementsensor.NewLastError(10, 5),
+	}
+
+	chipID, err := sensor.readBlock(ctx, address, whoAmIRegister, 1)
+	if err != nil {
+		return nil, addressReadError(err, address, newConf.I2cBus)
+	}
+	if chipID[0] != expectedChipID {
+		return nil, unexpectedDeviceError(address, chipID[0])
+	}
+
+	if err := sensor.init(ctx); err != nil {
+		return nil, errors.Wrap(err, "unable to start ICM-20948")
+	}
+	if cal, err := sensor.loadCalibrationFile(); err != nil {
+		logger.CWarnw(ctx, "not loading ICM-20948 calibration", "error", err)
+	} else {
+		sensor.calibration = cal
+	}
+
+	// Now, turn on the background goroutine that constantly reads from the chip and feeds the orientation
+	// filter.
+	sensor.workers = utils.NewStoppableWorkers(func(cancelCtx context.Context) {
+		timer := time.NewTicker(pollPeriod)
+		defer timer.Stop()
+
+		last := time.Now()
+		for {
+			select {
+			case now := <-timer.C:
+				err := sensor.poll(cancelCtx, now.Sub(last).Seconds())
+				last = now
+				// Record `err` no matter what: even if it's nil, that's useful information.
+				sensor.err.Set(err)
+				if err != nil {
+					sensor.logger.CErrorf(ctx, "error reading ICM-20948 sensor: '%s'", err)
+				}
+			case <-cancelCtx.Done():
+				return
+			}
+		}
+	})
+
+	return sensor, nil
+}
+
+// init resets the chip, wakes it up, and starts the magnetometer.
+func (imu *icm20948) init(ctx context.Context) error {
+	if err := imu.writeByte(ctx, imu.i2cAddress, bankSelectRegister, 0); err != nil {
+		return err
+	}
+	if err := imu.writeByte(ctx, imu.i2cAddress, powerMgmt1Register, powerMgmt1Reset); err != nil {
+		return err
+	}
+	time.Sleep(100 * time.Millisecond)
+	// The chip comes out of reset asleep. Wake it with the best available clock source and all sensors on.
+	if err := imu.writeByte(ctx, imu.i2cAddress, powerMgmt1Register, powerMgmt1AutoClock); err != nil {
+		return err
+	}
+	if err := imu.writeByte(ctx, imu.i2cAddress, powerMgmt2Register, 0); err != nil {
+		return err
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if !imu.useMagnetometer {
+		return nil
+	}
+	// Connect the magnetometer directly to the host's I2C bus.
+	if err := imu.writeByte(ctx, imu.i2cAddress, intPinCfgRegister, intPinCfgBypass); err != nil {
+		return err
+	}
+	magID, err := imu.readBlock(ctx, magnetometerAddress, magWhoAmIRegister, 1)
+	if err != nil {
+		return errors.Wrap(err, "can't read from magnetometer")
+	}
+	if magID[0] != magnetometerChipID {
+		return errors.Errorf("unexpected magnetometer chip id '%d'", magID[0])
+	}
+	return imu.writeByte(ctx, magnetometerAddress, magControl2Register, magContinuous100Hz)
+}
+
+// poll reads the latest measurements and advances the orientation filter by dt seconds.
+func (imu *icm20948) poll(ctx context.Context, dt float64) error {
+	rawData, err := imu.readBlock(ctx, imu.i2cAddress, accelDataRegister, 14)
+	if err != nil {
+		return err
+	}
+	linearAcceleration := toVector(rawData[0:6], accelLSBPerG).Mul(standardGravityMPSS)
+	gyro := toVector(rawData[6:12], gyroLSBPerDegPerSec)
+	temperature := float64(utils.Int16FromBytesBE(rawData[12:14]))/tempLSBPerDegC + tempOffsetDegC
+
+	var rawMag r3.Vector
+	haveMag := false
+	if imu.useMagnetometer {
+		magData, err := imu.readBlock(ctx, magnetometerAddress, magStatus1Register, 9)
+		if err != nil {
+			return err
+		}
+		// Reading the second status register (the last byte) lets the magnetometer take its next measurement.
+		if magData[0]&magDataReady != 0 && magData[8]&magOverflow == 0 {
+			m := r3.Vector{
+				X: float64(utils.Int16FromBytesLE(magData[1:3])),
+				Y: float64(utils.Int16FromBytesLE(magData[3:5])),
+				Z: float64(utils.Int16FromBytesLE(magData[5:7])),
+			}.Mul(microteslaPerMagLSB)
+			// The magnetometer's Y and Z axes point the opposite way from the accelerometer's.
+			rawMag = r3.Vector{X: m.X, Y: -m.Y, Z: -m.Z}
+			haveMag = true
+		}
+	}
+
+	imu.mu.Lock()
+	imu.rawAngularVelocity = spatialmath.AngularVelocity{X: gyro.X, Y: gyro.Y, Z: gyro.Z}
+	imu.angularVelocity = spatialmath.AngularVelocity(gyro.Sub(imu.calibration.GyroBias))
+	imu.linearAcceleration = linearAcceleration
+	imu.temperature = temperature
+	if haveMag {
+		imu.rawMagneticField = rawMag
+		imu.magneticField = rawMag.Sub(imu.calibration.MagnetometerOffset)
+		if imu.magCalibrating {
+			imu.magMin = r3.Vector{X: math.Min(imu.magMin.X, rawMag.X), Y: math.Min(imu.magMin.Y, rawMag.Y), Z: math.Min(imu.magMin.Z, rawMag.Z)}
+			imu.magMax = r3.Vector{X: math.Max(imu.magMax.X, rawMag.X), Y: math.Max(imu.magMax.Y, rawMag.Y), Z: math.Max(imu.magMax.Z, rawMag.Z)}
+		}
+	}
+	angularVelocity, magneticField := imu.angularVelocity, imu.magneticField
+	imu.mu.Unlock()
+
+	imu.fusion.Update(angularVelocity, linearAcceleration, magneticField, dt)
+	return nil
+}
+
+// toVector converts 3 big-endian int16s to a vector, given how many LSBs make a unit.
+func toVector(data []byte, lsbPerUnit float64) r3.Vector {
+	return r3.Vector{
+		X: float64(utils.Int16FromBytesBE(data[0:2])) / lsbPerUnit,
+		Y: float64(utils.Int16FromBytesBE(data[2:4])) / lsbPerUnit,
+		Z: float64(utils.Int16FromBytesBE(data[4:6])) / lsbPerUnit,
+	}
+}
+
+func (imu *icm20948) readBlock(ctx context.Context, address, register byte, length uint8) ([]byte, error) {
+	handle, err := imu.bus.OpenHandle(address)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := handle.Close()
+		if err != nil {
+			imu.logger.CError(ctx, err)
+		}
+	}()
+
+	results, err := handle.ReadBlockData(ctx, register, length)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != int(length) {
+		return nil, errors.Errorf("expected %d bytes from register %d, got %d", length, register, len(results))
+	}
+	return results, nil
+}
+
+func (imu *icm20948) writeByte(ctx context.Context, address, register, value byte) error {
+	handle, err := imu.bus.OpenHandle(address)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := handle.Close()
+		if err != nil {
+			imu.logger.CError(ctx, err)
+		}
+	}()
+
+	return handle.WriteByteData(ctx, register, value)
+}
+
+// loadCalibrationFile returns the saved calibration, or an empty one if there is none.
+func (imu *icm20948) loadCalibrationFile() (calibrationData, error) {
+	var cal calibrationData
+	if imu.calibrationFile == "" {
+		return cal, nil
+	}
+	//nolint:gosec
+	data, err := os.ReadFile(imu.calibrationFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cal, nil
+		}
+		return cal, err
+	}
+	if err := json.Unmarshal(data, &cal); err != nil {
+		return calibrationData{}, err
+	}
+	return cal, nil
+}
+
+func (imu *icm20948) calibrationMap() map[string]interface{} {
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	return map[string]interface{}{
+		"gyro_bias_degs_per_sec":         imu.calibration.GyroBias,
+		"magnetometer_offset_microtesla": imu.calibration.MagnetometerOffset,
+	}
+}
+
+// calibrateGyroscope sets the gyroscope bias to its average reading over the given period.
+func (imu *icm20948) calibrateGyroscope(ctx context.Context, period time.Duration) error {
+	var sum r3.Vector
+	samples := 0
+	for start := time.Now(); time.Since(start) < period; {
+		if !goutils.SelectContextOrWait(ctx, pollPeriod) {
+			return ctx.Err()
+		}
+		if err := imu.err.Get(); err != nil {
+			return err
+		}
+		imu.mu.Lock()
+		sum = sum.Add(r3.Vector(imu.rawAngularVelocity))
+		imu.mu.Unlock()
+		samples++
+	}
+	if samples == 0 {
+		return errors.New("calibration period is too short")
+	}
+
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	imu.calibration.GyroBias = sum.Mul(1 / float64(samples))
+	return nil
+}
+
+// DoCommand supports "calibrate_gyroscope", "start_magnetometer_calibration",
+// "finish_magnetometer_calibration", "save_calibration", and "get_calibration".
+func (imu *icm20948) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case "calibrate_gyroscope":
+		period := defaultGyroCalPeriod
+		if seconds, ok := cmd["seconds"].(float64); ok {
+			period = time.Duration(seconds * float64(time.Second))
+		}
+		if err := imu.calibrateGyroscope(ctx, period); err != nil {
+			return nil, err
+		}
+		return imu.calibrationMap(), nil
+	case "start_magnetometer_calibration":
+		if !imu.useMagnetometer {
+			return nil, errors.New("the magnetometer is disabled")
+		}
+		imu.mu.Lock()
+		defer imu.mu.Unlock()
+		imu.magCalibrating = true
+		imu.magMin = r3.Vector{X: math.Inf(1), Y: math.Inf(1), Z: math.Inf(1)}
+		imu.magMax = r3.Vector{X: math.Inf(-1), Y: math.Inf(-1), Z: math.Inf(-1)}
+		return map[string]interface{}{}, nil
+	case "finish_magnetometer_calibration":
+		imu.mu.Lock()
+		if !imu.magCalibrating {
+			imu.mu.Unlock()
+			return nil, errors.New("magnetometer calibration was not started")
+		}
+		imu.magCalibrating = false
+		if !(imu.magMax.X > imu.magMin.X && imu.magMax.Y > imu.magMin.Y && imu.magMax.Z > imu.magMin.Z) {
+			imu.mu.Unlock()
+			return nil, errors.New("the sensor was not rotated enough to calibrate the magnetometer")
+		}
+		imu.calibration.MagnetometerOffset = imu.magMin.Add(imu.magMax).Mul(0.5)
+		imu.mu.Unlock()
+		return imu.calibrationMap(), nil
+	case "save_calibration":
+		if imu.calibrationFile == "" {
+			return nil, errors.New("no calibration_file is configured")
+		}
+		imu.mu.Lock()
+		data, err := json.Marshal(imu.calibration)
+		imu.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(imu.calibrationFile, data, 0o600); err != nil {
+			return nil, err
+		}
+		return imu.calibrationMap(), nil
+	case "get_calibration":
+		return imu.calibrationMap(), nil
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
+
+func (imu *icm20948) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	return imu.angularVelocity, imu.err.Get()
+}
+
+func (imu *icm20948) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	return r3.Vector{}, movementsensor.ErrMethodUnimplementedLinearVelocity
+}
+
+// LinearAcceleration returns the acceleration of the sensor, including gravity.
+func (imu *icm20948) LinearAcceleration(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	return imu.linearAcceleration, imu.err.Get()
+}
+
+func (imu *icm20948) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	return imu.fusion.Orientation(), imu.err.Get()
+}
+
+func (imu *icm20948) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	if !imu.useMagnetometer {
+		return 0, movementsensor.ErrMethodUnimplementedCompassHeading
+	}
+	// the filter's yaw is counterclockwise from magnetic north, while heading is clockwise
+	yaw := utils.RadToDeg(imu.fusion.Orientation().EulerAngles().Yaw)
+	return utils.ModAngDeg(-yaw), imu.err.Get()
+}
+
+func (imu *icm20948) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	return geo.NewPoint(0, 0), 0, movementsensor.ErrMethodUnimplementedPosition
+}
+
+func (imu *icm20948) Accuracy(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error) {
+	return movementsensor.UnimplementedOptionalAccuracies(), nil
+}
+
+func (imu *icm20948) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	readings, err := movementsensor.DefaultAPIReadings(ctx, imu, extra)
+	if err != nil {
+		return nil, err
+	}
+
+	imu.mu.Lock()
+	defer imu.mu.Unlock()
+	readings["temperature_celsius"] = imu.temperature
+	if imu.useMagnetometer {
+		readings["magnetic_field_microtesla"] = imu.magneticField
+	}
+	return readings, nil
+}
+
+func (imu *icm20948) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	return &movementsensor.Properties{
+		AngularVelocitySupported:    true,
+		LinearAccelerationSupported: true,
+		OrientationSupported:        true,
+		CompassHeadingSupported:     imu.useMagnetometer,
+	}, nil
+}
+
+func (imu *icm20948) Close(ctx context.Context) error {
+	imu.workers.Stop()
+	return nil
+}
@@ -0,0 +1,2 @@
+// Package icm20948 is only implemented for Linux systems.
+package icm20948
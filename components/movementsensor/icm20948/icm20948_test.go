@@ -0,0 +1,233 @@
+//go:build linux
+
+package icm20948
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// fakeChip is an ICM-20948 and its magnetometer, whose registers can be read and written over an injected
+// I2C bus.
+type fakeChip struct {
+	mu        sync.Mutex
+	registers map[byte]*[0x80]byte
+}
+
+func newFakeChip() *fakeChip {
+	c := &fakeChip{registers: map[byte]*[0x80]byte{
+		defaultAddress:      {},
+		magnetometerAddress: {},
+	}}
+	c.registers[defaultAddress][whoAmIRegister] = expectedChipID
+	c.registers[magnetometerAddress][magWhoAmIRegister] = magnetometerChipID
+	return c
+}
+
+func (c *fakeChip) bus() buses.I2C {
+	i2c := &inject.I2C{}
+	i2c.OpenHandleFunc = func(addr byte) (buses.I2CHandle, error) {
+		handle := &inject.I2CHandle{}
+		handle.ReadBlockDataFunc = func(ctx context.Context, register byte, numBytes uint8) ([]byte, error) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return append([]byte{}, c.registers[addr][register:int(register)+int(numBytes)]...), nil
+		}
+		handle.WriteByteDataFunc = func(ctx context.Context, register, data byte) error {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.registers[addr][register] = data
+			return nil
+		}
+		handle.CloseFunc = func() error { return nil }
+		return handle, nil
+	}
+	return i2c
+}
+
+func (c *fakeChip) set(addr, register byte, data ...byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	copy(c.registers[addr][register:], data)
+}
+
+func (c *fakeChip) get(addr, register byte) byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.registers[addr][register]
+}
+
+func testConfig(attrs *Config) resource.Config {
+	attrs.I2cBus = "1"
+	return resource.Config{
+		Name:                "movementsensor",
+		Model:               model,
+		API:                 movementsensor.API,
+		ConvertedAttributes: attrs,
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	cfg := Config{}
+	deps, err := cfg.Validate("path")
+	expectedErr := resource.NewConfigValidationFieldRequiredError("path", "i2c_bus")
+	test.That(t, err, test.ShouldBeError, expectedErr)
+	test.That(t, deps, test.ShouldBeEmpty)
+
+	cfg = Config{I2cBus: "1", FusionBeta: -1}
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "fusion_beta")
+}
+
+func TestWrongChip(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	chip := newFakeChip()
+	chip.set(defaultAddress, whoAmIRegister, 0x12)
+	_, err := makeIcm20948(context.Background(), resource.Dependencies{}, testConfig(&Config{}), logger, chip.bus())
+	test.That(t, err, test.ShouldBeError, unexpectedDeviceError(defaultAddress, 0x12))
+}
+
+func TestReadings(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	chip := newFakeChip()
+	// 1g along Z, 131 LSB (1 degree per second) about X, and 0 LSB of temperature
+	chip.set(defaultAddress, accelDataRegister, 0, 0, 0, 0, 0x40, 0, 0, 131)
+	// magnetometer data is ready: 100 LSB along each axis
+	chip.set(magnetometerAddress, magStatus1Register, magDataReady, 100, 0, 100, 0, 100, 0, 0, 0)
+
+	sensor, err := makeIcm20948(ctx, resource.Dependencies{}, testConfig(&Config{}), logger, chip.bus())
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, sensor.Close(ctx), test.ShouldBeNil)
+	}()
+	test.That(t, chip.get(defaultAddress, powerMgmt1Register), test.ShouldEqual, byte(powerMgmt1AutoClock))
+	test.That(t, chip.get(defaultAddress, intPinCfgRegister), test.ShouldEqual, byte(intPinCfgBypass))
+	test.That(t, chip.get(magnetometerAddress, magControl2Register), test.ShouldEqual, byte(magContinuous100Hz))
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		readings, err := sensor.Readings(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		field := readings["magnetic_field_microtesla"].(r3.Vector)
+		test.That(tb, field.X, test.ShouldAlmostEqual, 15)
+		test.That(tb, field.Y, test.ShouldAlmostEqual, -15)
+		test.That(tb, field.Z, test.ShouldAlmostEqual, -15)
+	})
+	av, err := sensor.AngularVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, av.X, test.ShouldAlmostEqual, 1)
+	accel, err := sensor.LinearAcceleration(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, accel.Z, test.ShouldAlmostEqual, standardGravityMPSS)
+
+	props, err := sensor.Properties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.OrientationSupported, test.ShouldBeTrue)
+	test.That(t, props.CompassHeadingSupported, test.ShouldBeTrue)
+}
+
+func TestWithoutMagnetometer(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	chip := newFakeChip()
+	chip.set(defaultAddress, accelDataRegister, 0, 0, 0, 0, 0x40, 0)
+
+	sensor, err := makeIcm20948(ctx, resource.Dependencies{}, testConfig(&Config{DisableMagnetometer: true}), logger, chip.bus())
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, sensor.Close(ctx), test.ShouldBeNil)
+	}()
+	test.That(t, chip.get(defaultAddress, intPinCfgRegister), test.ShouldEqual, byte(0))
+
+	_, err = sensor.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeError, movementsensor.ErrMethodUnimplementedCompassHeading)
+	props, err := sensor.Properties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.CompassHeadingSupported, test.ShouldBeFalse)
+	_, err = sensor.DoCommand(ctx, map[string]interface{}{"command": "start_magnetometer_calibration"})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestCalibration(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	calibrationFile := filepath.Join(t.TempDir(), "icm20948.json")
+	chip := newFakeChip()
+	// a stationary sensor whose gyroscope reads 2 degrees per second about Z
+	chip.set(defaultAddress, accelDataRegister, 0, 0, 0, 0, 0x40, 0, 0, 0, 0, 0, 1, 6)
+
+	sensor, err := makeIcm20948(ctx, resource.Dependencies{}, testConfig(&Config{CalibrationFile: calibrationFile}), logger, chip.bus())
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, sensor.Close(ctx), test.ShouldBeNil)
+	}()
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		av, err := sensor.AngularVelocity(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, av.Z, test.ShouldAlmostEqual, 2)
+	})
+	_, err = sensor.DoCommand(ctx, map[string]interface{}{"command": "calibrate_gyroscope", "seconds": 0.1})
+	test.That(t, err, test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		av, err := sensor.AngularVelocity(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, av.Z, test.ShouldAlmostEqual, 0)
+	})
+
+	_, err = sensor.DoCommand(ctx, map[string]interface{}{"command": "finish_magnetometer_calibration"})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = sensor.DoCommand(ctx, map[string]interface{}{"command": "start_magnetometer_calibration"})
+	test.That(t, err, test.ShouldBeNil)
+	// two opposite orientations, whose field is centered on (30, -30, -30) microtesla
+	for _, raw := range [][]byte{{100, 0, 100, 0, 100, 0}, {0x2C, 1, 0x2C, 1, 0x2C, 1}} {
+		chip.set(magnetometerAddress, magStatus1Register, append(append([]byte{magDataReady}, raw...), 0, 0)...)
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			readings, err := sensor.Readings(ctx, nil)
+			test.That(tb, err, test.ShouldBeNil)
+			field := readings["magnetic_field_microtesla"].(r3.Vector)
+			test.That(tb, field.X, test.ShouldAlmostEqual, 0.15*float64(int(raw[0])|int(raw[1])<<8))
+		})
+	}
+	resp, err := sensor.DoCommand(ctx, map[string]interface{}{"command": "finish_magnetometer_calibration"})
+	test.That(t, err, test.ShouldBeNil)
+	offset := resp["magnetometer_offset_microtesla"].(r3.Vector)
+	test.That(t, offset.X, test.ShouldAlmostEqual, 30)
+	test.That(t, offset.Y, test.ShouldAlmostEqual, -30)
+	test.That(t, offset.Z, test.ShouldAlmostEqual, -30)
+
+	_, err = sensor.DoCommand(ctx, map[string]interface{}{"command": "save_calibration"})
+	test.That(t, err, test.ShouldBeNil)
+	//nolint:gosec
+	data, err := os.ReadFile(calibrationFile)
+	test.That(t, err, test.ShouldBeNil)
+	var saved calibrationData
+	test.That(t, json.Unmarshal(data, &saved), test.ShouldBeNil)
+	test.That(t, saved.GyroBias.Z, test.ShouldAlmostEqual, 2)
+	test.That(t, saved.MagnetometerOffset.X, test.ShouldAlmostEqual, 30)
+
+	// the saved calibration is applied at startup
+	restarted, err := makeIcm20948(ctx, resource.Dependencies{}, testConfig(&Config{CalibrationFile: calibrationFile}), logger, chip.bus())
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, restarted.Close(ctx), test.ShouldBeNil)
+	}()
+	resp, err = restarted.DoCommand(ctx, map[string]interface{}{"command": "get_calibration"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["gyro_bias_degs_per_sec"].(r3.Vector).Z, test.ShouldAlmostEqual, 2)
+}
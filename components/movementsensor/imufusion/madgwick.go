@@ -0,0 +1,130 @@
+// Package imufusion estimates orientation from the raw readings of an IMU which doesn't do so itself.
+package imufusion
+
+import (
+	"math"
+	"sync"
+
+	"github.com/golang/geo/r3"
+	"gonum.org/v1/gonum/num/quat"
+
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// DefaultBeta is the gain Madgwick recommends, corresponding to a gyroscope measurement error of about 5 degrees
+// per second.
+const DefaultBeta = 0.1
+
+// Madgwick is Sebastian Madgwick's gradient descent orientation filter, as described in "An efficient orientation
+// filter for inertial and inertial/magnetic sensor arrays" (2010). It integrates the gyroscope, and at each step
+// nudges the result towards the orientation which best explains the direction of gravity measured by the
+// accelerometer and, if one is available, the direction of north measured by the magnetometer.
+//
+// Without a magnetometer, yaw is only ever integrated from the gyroscope and so will drift.
+type Madgwick struct {
+	// beta trades off trusting the gyroscope (smaller) against trusting the accelerometer and magnetometer
+	// (larger).
+	beta float64
+
+	mu sync.Mutex
+	q  quat.Number
+}
+
+// NewMadgwick returns a filter starting from the identity orientation. If beta is 0, DefaultBeta is used.
+func NewMadgwick(beta float64) *Madgwick {
+	if beta == 0 {
+		beta = DefaultBeta
+	}
+	return &Madgwick{beta: beta, q: quat.Number{Real: 1}}
+}
+
+// Orientation returns the current estimate of the sensor's orientation in the world, where +Z is up.
+func (m *Madgwick) Orientation() spatialmath.Orientation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	q := spatialmath.Quaternion(m.q)
+	return &q
+}
+
+// Update advances the filter by dt seconds given the angular velocity in degrees per second and the acceleration
+// in any units. If mag is the zero vector it is ignored; otherwise it is the magnetic field in any units, in the
+// same frame as the accelerometer.
+func (m *Madgwick) Update(gyro spatialmath.AngularVelocity, accel, mag r3.Vector, dt float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	gx, gy, gz := utils.DegToRad(gyro.X), utils.DegToRad(gyro.Y), utils.DegToRad(gyro.Z)
+	q0, q1, q2, q3 := m.q.Real, m.q.Imag, m.q.Jmag, m.q.Kmag
+
+	// rate of change of the quaternion from the gyroscope
+	qDot0 := 0.5 * (-q1*gx - q2*gy - q3*gz)
+	qDot1 := 0.5 * (q0*gx + q2*gz - q3*gy)
+	qDot2 := 0.5 * (q0*gy - q1*gz + q3*gx)
+	qDot3 := 0.5 * (q0*gz + q1*gy - q2*gx)
+
+	// the accelerometer is meaningless in free fall
+	if accel.Norm() > 0 {
+		var s0, s1, s2, s3 float64
+		if mag.Norm() > 0 {
+			s0, s1, s2, s3 = margStep(m.q, accel.Normalize(), mag.Normalize())
+		} else {
+			s0, s1, s2, s3 = imuStep(m.q, accel.Normalize())
+		}
+		if norm := math.Sqrt(s0*s0 + s1*s1 + s2*s2 + s3*s3); norm > 0 {
+			qDot0 -= m.beta * s0 / norm
+			qDot1 -= m.beta * s1 / norm
+			qDot2 -= m.beta * s2 / norm
+			qDot3 -= m.beta * s3 / norm
+		}
+	}
+
+	m.q = spatialmath.Normalize(quat.Number{
+		Real: q0 + qDot0*dt,
+		Imag: q1 + qDot1*dt,
+		Jmag: q2 + qDot2*dt,
+		Kmag: q3 + qDot3*dt,
+	})
+}
+
+// imuStep is the gradient of the error between the measured direction of gravity and the direction predicted by q.
+func imuStep(q quat.Number, a r3.Vector) (float64, float64, float64, float64) {
+	q0, q1, q2, q3 := q.Real, q.Imag, q.Jmag, q.Kmag
+	q0q0, q1q1, q2q2, q3q3 := q0*q0, q1*q1, q2*q2, q3*q3
+
+	s0 := 4*q0*q2q2 + 2*q2*a.X + 4*q0*q1q1 - 2*q1*a.Y
+	s1 := 4*q1*q3q3 - 2*q3*a.X + 4*q0q0*q1 - 2*q0*a.Y - 4*q1 + 8*q1*q1q1 + 8*q1*q2q2 + 4*q1*a.Z
+	s2 := 4*q0q0*q2 + 2*q0*a.X + 4*q2*q3q3 - 2*q3*a.Y - 4*q2 + 8*q2*q1q1 + 8*q2*q2q2 + 4*q2*a.Z
+	s3 := 4*q1q1*q3 - 2*q1*a.X + 4*q2q2*q3 - 2*q2*a.Y
+	return s0, s1, s2, s3
+}
+
+// margStep is like imuStep, but also includes the error in the direction of the earth's magnetic field.
+func margStep(q quat.Number, a, mg r3.Vector) (float64, float64, float64, float64) {
+	q0, q1, q2, q3 := q.Real, q.Imag, q.Jmag, q.Kmag
+	mx, my, mz := mg.X, mg.Y, mg.Z
+	q0q0, q0q1, q0q2, q0q3 := q0*q0, q0*q1, q0*q2, q0*q3
+	q1q1, q1q2, q1q3 := q1*q1, q1*q2, q1*q3
+	q2q2, q2q3, q3q3 := q2*q2, q2*q3, q3*q3
+
+	// the direction of the earth's magnetic field in the world, assuming it has no east-west component
+	hx := mx*q0q0 - 2*q0*my*q3 + 2*q0*mz*q2 + mx*q1q1 + 2*q1*my*q2 + 2*q1*mz*q3 - mx*q2q2 - mx*q3q3
+	hy := 2*q0*mx*q3 + my*q0q0 - 2*q0*mz*q1 + 2*q1*mx*q2 - my*q1q1 + my*q2q2 + 2*q2*mz*q3 - my*q3q3
+	bx2 := math.Sqrt(hx*hx + hy*hy)
+	bz2 := -2*q0*mx*q2 + 2*q0*my*q1 + mz*q0q0 + 2*q1*mx*q3 - mz*q1q1 + 2*q2*my*q3 - mz*q2q2 + mz*q3q3
+	bx4, bz4 := 2*bx2, 2*bz2
+
+	// the error between the measured and predicted gravity and magnetic field
+	fgx := 2*q1q3 - 2*q0q2 - a.X
+	fgy := 2*q0q1 + 2*q2q3 - a.Y
+	fgz := 1 - 2*q1q1 - 2*q2q2 - a.Z
+	fmx := bx2*(0.5-q2q2-q3q3) + bz2*(q1q3-q0q2) - mx
+	fmy := bx2*(q1q2-q0q3) + bz2*(q0q1+q2q3) - my
+	fmz := bx2*(q0q2+q1q3) + bz2*(0.5-q1q1-q2q2) - mz
+
+	s0 := -2*q2*fgx + 2*q1*fgy - bz2*q2*fmx + (-bx2*q3+bz2*q1)*fmy + bx2*q2*fmz
+	s1 := 2*q3*fgx + 2*q0*fgy - 4*q1*fgz + bz2*q3*fmx + (bx2*q2+bz2*q0)*fmy + (bx2*q3-bz4*q1)*fmz
+	s2 := -2*q0*fgx + 2*q3*fgy - 4*q2*fgz + (-bx4*q2-bz2*q0)*fmx + (bx2*q1+bz2*q3)*fmy + (bx2*q0-bz4*q2)*fmz
+	s3 := 2*q1*fgx + 2*q2*fgy + (-bx4*q3+bz2*q1)*fmx + (-bx2*q0+bz2*q2)*fmy + bx2*q1*fmz
+	return s0, s1, s2, s3
+}
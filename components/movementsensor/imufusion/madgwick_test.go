@@ -0,0 +1,58 @@
+package imufusion
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+func TestMadgwickStationary(t *testing.T) {
+	m := NewMadgwick(0)
+	for i := 0; i < 100; i++ {
+		m.Update(spatialmath.AngularVelocity{}, r3.Vector{Z: 9.81}, r3.Vector{}, 0.01)
+	}
+	test.That(t, spatialmath.OrientationAlmostEqual(m.Orientation(), spatialmath.NewZeroOrientation()), test.ShouldBeTrue)
+}
+
+func TestMadgwickGyro(t *testing.T) {
+	m := NewMadgwick(0)
+	// spin at 90 degrees per second for a second while level
+	for i := 0; i < 100; i++ {
+		m.Update(spatialmath.AngularVelocity{Z: 90}, r3.Vector{Z: 9.81}, r3.Vector{}, 0.01)
+	}
+	ea := m.Orientation().EulerAngles()
+	test.That(t, utils.RadToDeg(ea.Yaw), test.ShouldAlmostEqual, 90, 0.5)
+	test.That(t, ea.Roll, test.ShouldAlmostEqual, 0, 1e-6)
+	test.That(t, ea.Pitch, test.ShouldAlmostEqual, 0, 1e-6)
+}
+
+func TestMadgwickConvergesToGravity(t *testing.T) {
+	m := NewMadgwick(0.5)
+	// gravity as seen by a sensor rolled 30 degrees about its X axis
+	roll := utils.DegToRad(30)
+	accel := r3.Vector{Y: math.Sin(roll), Z: math.Cos(roll)}
+	for i := 0; i < 2000; i++ {
+		m.Update(spatialmath.AngularVelocity{}, accel, r3.Vector{}, 0.01)
+	}
+	ea := m.Orientation().EulerAngles()
+	test.That(t, utils.RadToDeg(ea.Roll), test.ShouldAlmostEqual, 30, 0.5)
+	test.That(t, utils.RadToDeg(ea.Pitch), test.ShouldAlmostEqual, 0, 0.5)
+}
+
+func TestMadgwickConvergesToNorth(t *testing.T) {
+	m := NewMadgwick(0.5)
+	// a level sensor whose X axis points 45 degrees west of magnetic north, where the field dips downwards
+	yaw := utils.DegToRad(45)
+	mag := r3.Vector{X: math.Cos(yaw), Y: -math.Sin(yaw), Z: -0.8}
+	for i := 0; i < 2000; i++ {
+		m.Update(spatialmath.AngularVelocity{}, r3.Vector{Z: 9.81}, mag, 0.01)
+	}
+	ea := m.Orientation().EulerAngles()
+	test.That(t, utils.RadToDeg(ea.Yaw), test.ShouldAlmostEqual, 45, 0.5)
+	test.That(t, utils.RadToDeg(ea.Roll), test.ShouldAlmostEqual, 0, 0.5)
+}
@@ -0,0 +1,124 @@
+package movingbaseline
+
+import (
+	"math"
+
+	geo "github.com/kellydunn/golang-geo"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/utils"
+)
+
+const (
+	earthRadiusM = 6371e3
+
+	// rtkPositionErrorM and positionErrorM are how far off we take a position with and without an
+	// RTK fix to be, when the receiver doesn't say.
+	rtkPositionErrorM = 0.1
+	positionErrorM    = 5.0
+)
+
+// rtkSolution describes how far the receivers got resolving the carrier phase ambiguities, which is
+// what makes an RTK baseline accurate to a few centimeters.
+type rtkSolution string
+
+const (
+	solutionNone  rtkSolution = "none"
+	solutionFloat rtkSolution = "float"
+	solutionFixed rtkSolution = "fixed"
+)
+
+// baseline is the vector from the first antenna to the second.
+type baseline struct {
+	North, East, Down float64 // meters
+	// HeadingError is how far off the bearing of the baseline may be, in degrees.
+	HeadingError float64
+	Solution     rtkSolution
+}
+
+// length returns the distance between the antennas, in meters.
+func (b baseline) length() float64 {
+	return math.Sqrt(b.North*b.North + b.East*b.East + b.Down*b.Down)
+}
+
+// bearing returns the direction from the first antenna to the second, in degrees clockwise from
+// north, from 0 to 360.
+func (b baseline) bearing() float64 {
+	bearing := utils.RadToDeg(math.Atan2(b.East, b.North))
+	if bearing < 0 {
+		bearing += 360
+	}
+	return bearing
+}
+
+// baselineBetween returns the baseline between two antennas' positions, whose errors are given in
+// meters. The antennas are close enough together to treat the ground between them as flat.
+func baselineBetween(first, second *geo.Point, firstAlt, secondAlt, firstErr, secondErr float64) baseline {
+	north := utils.DegToRad(second.Lat()-first.Lat()) * earthRadiusM
+	east := utils.DegToRad(second.Lng()-first.Lng()) * earthRadiusM * math.Cos(utils.DegToRad(first.Lat()))
+
+	// the bearing is off by as much as the antennas' errors, across the baseline, would turn it
+	headingError := math.NaN()
+	if horizontal := math.Hypot(north, east); horizontal > 0 {
+		headingError = utils.RadToDeg(math.Atan2(math.Hypot(firstErr, secondErr), horizontal))
+	}
+	return baseline{North: north, East: east, Down: firstAlt - secondAlt, HeadingError: headingError}
+}
+
+// positionError returns how far off a GPS with the given accuracy may be, in meters.
+func positionError(acc *movementsensor.Accuracy) float64 {
+	if acc != nil && acc.NmeaFix >= 4 {
+		return rtkPositionErrorM
+	}
+	return positionErrorM
+}
+
+// fixSolution returns the RTK solution an NMEA fix quality describes.
+func fixSolution(fix int32) rtkSolution {
+	switch fix {
+	case 4:
+		return solutionFixed
+	case 5:
+		return solutionFloat
+	default:
+		return solutionNone
+	}
+}
+
+// fixRank orders NMEA fix qualities from worst to best: none, GPS, DGPS, RTK float, RTK fixed. Any
+// other fix, such as dead reckoning, ranks below them all.
+func fixRank(fix int32) int {
+	switch fix {
+	case 0, 1, 2:
+		return int(fix)
+	case 5:
+		return 3
+	case 4:
+		return 4
+	default:
+		return -1
+	}
+}
+
+// worseAccuracy returns the worse of two GPSes' accuracies, which is as good as anything using
+// both of them can be.
+func worseAccuracy(first, second *movementsensor.Accuracy) *movementsensor.Accuracy {
+	fix := first.NmeaFix
+	if fixRank(second.NmeaFix) < fixRank(fix) {
+		fix = second.NmeaFix
+	}
+	return &movementsensor.Accuracy{
+		Hdop:               worseDop(first.Hdop, second.Hdop),
+		Vdop:               worseDop(first.Vdop, second.Vdop),
+		NmeaFix:            fix,
+		CompassDegreeError: float32(math.NaN()),
+	}
+}
+
+// worseDop returns the larger dilution of precision, or NaN if either is unknown.
+func worseDop(first, second float32) float32 {
+	if math.IsNaN(float64(first)) || math.IsNaN(float64(second)) {
+		return float32(math.NaN())
+	}
+	return float32(math.Max(float64(first), float64(second)))
+}
@@ -0,0 +1,288 @@
+// Package movingbaseline implements a movement sensor which finds its true heading from the vector
+// between two GPS antennas, known as the baseline. Unlike a magnetometer's, this heading isn't thrown
+// off by motors or steel nearby, and doesn't need the robot to be moving like a GPS course does.
+package movingbaseline
+
+/*
+	The baseline comes either from two RTK GPS movement sensors, one per antenna:
+	{
+	  "type": "movement_sensor",
+	  "model": "gps-moving-baseline",
+	  "name": "my-heading",
+	  "attributes": {
+	    "first_gps": "rear-gps",
+	    "second_gps": "front-gps",
+	    "baseline_m": 0.8
+	  }
+	}
+
+	or from a u-blox receiver in moving base rover mode, which gets its corrections from a second
+	receiver (the moving base) on the same robot, and is set to send UBX-NAV-RELPOSNED messages:
+	{
+	  "type": "movement_sensor",
+	  "model": "gps-moving-baseline",
+	  "name": "my-heading",
+	  "attributes": {
+	    "serial_path": "/dev/ttyACM0",
+	    "serial_baud_rate": 115200
+	  }
+	}
+	In that case the first antenna is the moving base's, and the second is the rover's. serial_usb may
+	be given instead of serial_path, as with the other GPS models.
+
+	offset_degrees is the bearing from the first antenna to the second when the robot faces north:
+	0 (the default) if the second antenna is straight ahead of the first, 90 if it is to the right.
+
+	baseline_m, if given, is the measured distance between the antennas. Headings from a baseline
+	more than 10cm longer or shorter than that are errors rather than readings, since one of the
+	antennas' positions must be wrong.
+*/
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/benbjohnson/clock"
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils/serialport"
+)
+
+// baselineTolerance is how far the measured baseline may be from the configured one, in meters.
+const baselineTolerance = 0.1
+
+var model = resource.DefaultModelFamily.WithModel("gps-moving-baseline")
+
+// Config is used for converting the movementsensor attributes.
+type Config struct {
+	FirstGPS  string `json:"first_gps,omitempty"`
+	SecondGPS string `json:"second_gps,omitempty"`
+
+	SerialPath     string               `json:"serial_path,omitempty"`
+	SerialBaudRate int                  `json:"serial_baud_rate,omitempty"`
+	SerialUSB      *serialport.USBMatch `json:"serial_usb,omitempty"`
+
+	Offset    *float64 `json:"offset_degrees,omitempty"`
+	BaselineM float64  `json:"baseline_m,omitempty"`
+}
+
+// usesReceiver returns whether the baseline comes from a receiver in moving base rover mode, rather
+// than from two GPS movement sensors.
+func (c *Config) usesReceiver() bool {
+	return c.SerialPath != "" || c.SerialUSB != nil
+}
+
+// Validate ensures all parts of the config are valid, and returns the GPSes it depends on.
+func (c *Config) Validate(path string) ([]string, error) {
+	usesGPSes := c.FirstGPS != "" || c.SecondGPS != ""
+	switch {
+	case usesGPSes && c.usesReceiver():
+		return nil, resource.NewConfigValidationError(path,
+			errors.New("give either first_gps and second_gps, or the receiver's serial_path or serial_usb, not both"))
+	case !usesGPSes && !c.usesReceiver():
+		return nil, resource.NewConfigValidationError(path,
+			errors.New("give either first_gps and second_gps, or the receiver's serial_path or serial_usb"))
+	}
+
+	if c.Offset != nil && (*c.Offset < 0 || *c.Offset > 360) {
+		return nil, resource.NewConfigValidationError(path, errors.New("offset_degrees must be from 0 to 360"))
+	}
+	if c.BaselineM < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("baseline_m can't be negative"))
+	}
+
+	if c.usesReceiver() {
+		if c.SerialUSB != nil {
+			if err := c.SerialUSB.Validate(fmt.Sprintf("%s.%s", path, "serial_usb")); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+
+	if c.FirstGPS == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "first_gps")
+	}
+	if c.SecondGPS == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "second_gps")
+	}
+	return []string{c.FirstGPS, c.SecondGPS}, nil
+}
+
+func init() {
+	resource.RegisterComponent(
+		movementsensor.API,
+		model,
+		resource.Registration[movementsensor.MovementSensor, *Config]{Constructor: newMovingBaseline})
+}
+
+// baselineSource is where the baseline, and the robot's position, come from.
+type baselineSource interface {
+	// Baseline returns the latest vector from the first antenna to the second.
+	Baseline(ctx context.Context) (baseline, error)
+	Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error)
+	// Accuracy returns the accuracy of the position. The compass error is left to the caller.
+	Accuracy(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error)
+	Close(ctx context.Context) error
+}
+
+type movingBaseline struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger logging.Logger
+
+	source           baselineSource
+	offset           float64
+	expectedBaseline float64
+}
+
+func newMovingBaseline(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (movementsensor.MovementSensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+
+	mb := &movingBaseline{
+		Named:            conf.ResourceName().AsNamed(),
+		logger:           logger,
+		expectedBaseline: newConf.BaselineM,
+	}
+	if newConf.Offset != nil {
+		mb.offset = *newConf.Offset
+	}
+
+	if !newConf.usesReceiver() {
+		first, err := movementsensor.FromDependencies(deps, newConf.FirstGPS)
+		if err != nil {
+			return nil, err
+		}
+		second, err := movementsensor.FromDependencies(deps, newConf.SecondGPS)
+		if err != nil {
+			return nil, err
+		}
+		mb.source = &gpsPair{first: first, second: second}
+		return mb, nil
+	}
+
+	baudRate := newConf.SerialBaudRate
+	if baudRate == 0 {
+		baudRate = 38400
+		logger.CInfo(ctx, "serial_baud_rate using default baud rate 38400")
+	}
+	port, err := serialport.Open(serialport.OpenOptions{
+		Path:            newConf.SerialPath,
+		USB:             newConf.SerialUSB,
+		BaudRate:        uint(baudRate),
+		MinimumReadSize: 1,
+	}, logger)
+	if err != nil {
+		return nil, err
+	}
+	mb.source = newMovingBaseReceiver(port, clock.New(), logger)
+	return mb, nil
+}
+
+// currentBaseline returns the baseline, unless it's so far from the configured one that it can't
+// be right.
+func (mb *movingBaseline) currentBaseline(ctx context.Context) (baseline, error) {
+	b, err := mb.source.Baseline(ctx)
+	if err != nil {
+		return baseline{}, err
+	}
+	if mb.expectedBaseline > 0 && math.Abs(b.length()-mb.expectedBaseline) > baselineTolerance {
+		return baseline{}, fmt.Errorf(
+			"measured baseline of %.2fm is not the configured %.2fm, so the heading from it can't be trusted "+
+				"(the RTK solution is %s)", b.length(), mb.expectedBaseline, b.Solution)
+	}
+	return b, nil
+}
+
+// CompassHeading returns the robot's true heading, from 0 to 360 degrees clockwise from north.
+func (mb *movingBaseline) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	b, err := mb.currentBaseline(ctx)
+	if err != nil {
+		return math.NaN(), err
+	}
+	heading := b.bearing() - mb.offset
+	if heading < 0 {
+		heading += 360
+	}
+	return heading, nil
+}
+
+func (mb *movingBaseline) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	return mb.source.Position(ctx, extra)
+}
+
+// Accuracy returns the position's accuracy, and how far off the heading may be given the antennas'
+// errors and the distance between them.
+func (mb *movingBaseline) Accuracy(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error) {
+	acc, err := mb.source.Accuracy(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	acc.AccuracyMap = map[string]float32{"hDOP": acc.Hdop, "vDOP": acc.Vdop}
+	if b, err := mb.currentBaseline(ctx); err == nil {
+		acc.CompassDegreeError = float32(b.HeadingError)
+		acc.AccuracyMap["baseline_m"] = float32(b.length())
+	} else {
+		acc.CompassDegreeError = float32(math.NaN())
+	}
+	return acc, nil
+}
+
+func (mb *movingBaseline) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	readings, err := movementsensor.DefaultAPIReadings(ctx, mb, extra)
+	if err != nil {
+		return nil, err
+	}
+	if b, err := mb.source.Baseline(ctx); err == nil {
+		readings["baseline_m"] = b.length()
+		readings["rtk_solution"] = string(b.Solution)
+	}
+	return readings, nil
+}
+
+func (mb *movingBaseline) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	return &movementsensor.Properties{
+		CompassHeadingSupported: true,
+		PositionSupported:       true,
+	}, nil
+}
+
+// Unimplemented functions.
+func (mb *movingBaseline) LinearAcceleration(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	return r3.Vector{}, movementsensor.ErrMethodUnimplementedLinearAcceleration
+}
+
+func (mb *movingBaseline) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	return r3.Vector{}, movementsensor.ErrMethodUnimplementedLinearVelocity
+}
+
+func (mb *movingBaseline) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+	return spatialmath.AngularVelocity{}, movementsensor.ErrMethodUnimplementedAngularVelocity
+}
+
+func (mb *movingBaseline) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	return spatialmath.NewZeroOrientation(), movementsensor.ErrMethodUnimplementedOrientation
+}
+
+func (mb *movingBaseline) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return map[string]interface{}{}, resource.ErrDoUnimplemented
+}
+
+func (mb *movingBaseline) Close(ctx context.Context) error {
+	return mb.source.Close(ctx)
+}
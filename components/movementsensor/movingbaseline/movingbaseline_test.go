@@ -0,0 +1,216 @@
+package movingbaseline
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/components/movementsensor/gpsutils"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/utils"
+)
+
+const (
+	testPath = "somepath"
+	testGPS1 = "rear"
+	testGPS2 = "front"
+)
+
+func makeFakeGPS(name string, point *geo.Point, fix int32) *inject.MovementSensor {
+	ms := inject.NewMovementSensor(name)
+	ms.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+		return point, 10, nil
+	}
+	ms.AccuracyFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error) {
+		return &movementsensor.Accuracy{Hdop: 0.8, Vdop: 1.2, NmeaFix: fix}, nil
+	}
+	return ms
+}
+
+func TestValidate(t *testing.T) {
+	cfg := &Config{}
+	_, err := cfg.Validate(testPath)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	cfg = &Config{FirstGPS: testGPS1, SerialPath: "/dev/ttyACM0"}
+	_, err = cfg.Validate(testPath)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "not both")
+
+	cfg = &Config{FirstGPS: testGPS1}
+	_, err = cfg.Validate(testPath)
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError(testPath, "second_gps"))
+
+	cfg = &Config{FirstGPS: testGPS1, SecondGPS: testGPS2}
+	deps, err := cfg.Validate(testPath)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{testGPS1, testGPS2})
+
+	offset := 400.0
+	cfg.Offset = &offset
+	_, err = cfg.Validate(testPath)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	cfg = &Config{SerialPath: "/dev/ttyACM0"}
+	deps, err = cfg.Validate(testPath)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldBeNil)
+}
+
+func TestGPSPairHeading(t *testing.T) {
+	// the front antenna is a meter north of the rear one
+	rear := geo.NewPoint(40, -105)
+	front := geo.NewPoint(40+utils.RadToDeg(1/earthRadiusM), -105)
+
+	deps := resource.Dependencies{
+		movementsensor.Named(testGPS1): makeFakeGPS(testGPS1, rear, 4),
+		movementsensor.Named(testGPS2): makeFakeGPS(testGPS2, front, 5),
+	}
+	conf := resource.Config{
+		Name:                "heading",
+		API:                 movementsensor.API,
+		Model:               model,
+		ConvertedAttributes: &Config{FirstGPS: testGPS1, SecondGPS: testGPS2},
+	}
+	ms, err := newMovingBaseline(context.Background(), deps, conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer ms.Close(context.Background())
+
+	heading, err := ms.CompassHeading(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, heading, test.ShouldAlmostEqual, 0, 1e-6)
+
+	acc, err := ms.Accuracy(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, acc.NmeaFix, test.ShouldEqual, 5)
+	test.That(t, acc.Hdop, test.ShouldAlmostEqual, 0.8, 1e-6)
+	// each antenna may be 10cm off, a meter apart
+	test.That(t, acc.CompassDegreeError, test.ShouldAlmostEqual, 8.05, 0.01)
+	test.That(t, acc.AccuracyMap["baseline_m"], test.ShouldAlmostEqual, 1, 1e-3)
+
+	readings, err := ms.Readings(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["rtk_solution"], test.ShouldEqual, "float")
+
+	// the second antenna is on the robot's right
+	offset := 90.0
+	conf.ConvertedAttributes = &Config{FirstGPS: testGPS1, SecondGPS: testGPS2, Offset: &offset}
+	ms, err = newMovingBaseline(context.Background(), deps, conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	heading, err = ms.CompassHeading(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, heading, test.ShouldAlmostEqual, 270, 1e-6)
+
+	// the antennas are meant to be two meters apart, so one of them is wrong
+	conf.ConvertedAttributes = &Config{FirstGPS: testGPS1, SecondGPS: testGPS2, BaselineM: 2}
+	ms, err = newMovingBaseline(context.Background(), deps, conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	heading, err = ms.CompassHeading(context.Background(), nil)
+	test.That(t, math.IsNaN(heading), test.ShouldBeTrue)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "measured baseline of 1.00m")
+	acc, err = ms.Accuracy(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, math.IsNaN(float64(acc.CompassDegreeError)), test.ShouldBeTrue)
+}
+
+// relPosNEDMessage returns a UBX-NAV-RELPOSNED message with the given baseline in centimeters and
+// heading accuracy in degrees.
+func relPosNEDMessage(north, east, down int32, accHeading float64, flags uint32) []byte {
+	payload := make([]byte, relPosNEDLength)
+	payload[0] = 1
+	binary.LittleEndian.PutUint32(payload[8:], uint32(north))
+	binary.LittleEndian.PutUint32(payload[12:], uint32(east))
+	binary.LittleEndian.PutUint32(payload[16:], uint32(down))
+	binary.LittleEndian.PutUint32(payload[52:], uint32(accHeading*1e5))
+	binary.LittleEndian.PutUint32(payload[60:], flags)
+	return gpsutils.UBXMessage{Class: ubxClassNav, ID: ubxIDRelPosNED, Payload: payload}.Bytes()
+}
+
+func TestParseRelPosNED(t *testing.T) {
+	payload := make([]byte, relPosNEDLength)
+	payload[0] = 1
+	negative := int32(-50)
+	binary.LittleEndian.PutUint32(payload[8:], uint32(negative))
+	payload[32] = 25 // 2.5mm more
+	binary.LittleEndian.PutUint32(payload[52:], 123456)
+	binary.LittleEndian.PutUint32(payload[60:], relPosValid|carrSolnFloat)
+
+	relPos, err := parseRelPosNED(payload)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, relPos.North, test.ShouldAlmostEqual, -0.4975)
+	test.That(t, relPos.HeadingError, test.ShouldAlmostEqual, 1.23456)
+	test.That(t, relPos.Solution, test.ShouldEqual, solutionFloat)
+
+	_, err = parseRelPosNED(payload[:40])
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+// fakePort is a receiver's serial port, whose output the test writes.
+type fakePort struct {
+	*io.PipeReader
+}
+
+func (p fakePort) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func TestMovingBaseReceiver(t *testing.T) {
+	reader, writer := io.Pipe()
+	mockClock := clock.NewMock()
+	r := newMovingBaseReceiver(fakePort{reader}, mockClock, logging.NewTestLogger(t))
+	mb := &movingBaseline{source: r, logger: logging.NewTestLogger(t)}
+	defer mb.Close(context.Background())
+
+	_, err := mb.CompassHeading(context.Background(), nil)
+	test.That(t, err, test.ShouldBeError, errNoRelPos)
+
+	_, err = writer.Write([]byte("$GNGGA,123519,4807.038,N,01131.000,E,4,08,0.9,545.4,M,46.9,M,,*5C\r\n"))
+	test.That(t, err, test.ShouldBeNil)
+	_, err = writer.Write(relPosNEDMessage(100, 100, 0, 0.5, relPosValid|carrSolnFixed|relPosHeadingValid))
+	test.That(t, err, test.ShouldBeNil)
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		heading, err := mb.CompassHeading(context.Background(), nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, heading, test.ShouldAlmostEqual, 45, 1e-6)
+	})
+
+	point, alt, err := mb.Position(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, point.Lat(), test.ShouldAlmostEqual, 48.1173, 1e-4)
+	test.That(t, alt, test.ShouldAlmostEqual, 545.4)
+
+	acc, err := mb.Accuracy(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, acc.NmeaFix, test.ShouldEqual, 4)
+	test.That(t, acc.CompassDegreeError, test.ShouldAlmostEqual, 0.5, 1e-6)
+
+	// the receiver stops sending relative positions
+	mockClock.Add(maxRelPosAge + time.Second)
+	_, err = mb.CompassHeading(context.Background(), nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "6s old")
+
+	// and when it starts again, has lost the moving base
+	_, err = writer.Write(relPosNEDMessage(0, 0, 0, 0, 0))
+	test.That(t, err, test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		_, err := mb.CompassHeading(context.Background(), nil)
+		test.That(tb, err, test.ShouldNotBeNil)
+		test.That(tb, err.Error(), test.ShouldContainSubstring, "no valid relative position")
+	})
+}
@@ -0,0 +1,96 @@
+package movingbaseline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	geo "github.com/kellydunn/golang-geo"
+
+	"go.viam.com/rdk/components/movementsensor"
+)
+
+// gpsPair finds the baseline between two GPS movement sensors, each with its own antenna and RTK
+// corrections.
+type gpsPair struct {
+	first  movementsensor.MovementSensor
+	second movementsensor.MovementSensor
+}
+
+// antenna is where one of a pair's antennas is, and how accurately.
+type antenna struct {
+	point *geo.Point
+	alt   float64
+	acc   *movementsensor.Accuracy
+}
+
+func readAntenna(ctx context.Context, ms movementsensor.MovementSensor) (antenna, error) {
+	point, alt, err := ms.Position(ctx, nil)
+	if err != nil {
+		return antenna{}, err
+	}
+	if point == nil || movementsensor.IsZeroPosition(point) || movementsensor.IsPositionNaN(point) {
+		return antenna{}, errors.New("no position yet")
+	}
+	// Without an accuracy, we assume the position is as bad as a GPS's can be and carry on.
+	acc, err := ms.Accuracy(ctx, nil)
+	if err != nil {
+		acc = nil
+	}
+	return antenna{point: point, alt: alt, acc: acc}, nil
+}
+
+func (p *gpsPair) Baseline(ctx context.Context) (baseline, error) {
+	first, err := readAntenna(ctx, p.first)
+	if err != nil {
+		return baseline{}, fmt.Errorf("no heading without %s: %w", p.first.Name().ShortName(), err)
+	}
+	second, err := readAntenna(ctx, p.second)
+	if err != nil {
+		return baseline{}, fmt.Errorf("no heading without %s: %w", p.second.Name().ShortName(), err)
+	}
+
+	b := baselineBetween(first.point, second.point, first.alt, second.alt,
+		positionError(first.acc), positionError(second.acc))
+	b.Solution = solutionNone
+	if first.acc != nil && second.acc != nil {
+		b.Solution = fixSolution(worseAccuracy(first.acc, second.acc).NmeaFix)
+	}
+	return b, nil
+}
+
+// Position returns the point between the two antennas, or just one's position if the other has
+// none.
+func (p *gpsPair) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	point1, alt1, err1 := p.first.Position(ctx, extra)
+	point2, alt2, err2 := p.second.Position(ctx, extra)
+	switch {
+	case err1 != nil && err2 != nil:
+		return geo.NewPoint(math.NaN(), math.NaN()), math.NaN(), errors.Join(err1, err2)
+	case err1 != nil:
+		return point2, alt2, nil
+	case err2 != nil:
+		return point1, alt1, nil
+	default:
+		return point1.MidpointTo(point2), (alt1 + alt2) / 2, nil
+	}
+}
+
+// Accuracy returns the worse of the two GPSes' accuracies.
+func (p *gpsPair) Accuracy(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error) {
+	first, err := p.first.Accuracy(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	second, err := p.second.Accuracy(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	return worseAccuracy(first, second), nil
+}
+
+// Close does nothing, since the GPSes are dependencies rather than ours to close.
+func (p *gpsPair) Close(ctx context.Context) error {
+	return nil
+}
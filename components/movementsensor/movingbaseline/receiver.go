@@ -0,0 +1,193 @@
+package movingbaseline
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	geo "github.com/kellydunn/golang-geo"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/components/movementsensor/gpsutils"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/utils"
+)
+
+const (
+	ubxClassNav     = 0x01
+	ubxIDRelPosNED  = 0x3C
+	relPosNEDLength = 64
+
+	// maxRelPosAge is how old the last relative position may be before the heading from it is
+	// no longer current.
+	maxRelPosAge = 5 * time.Second
+)
+
+// RELPOSNED flags.
+const (
+	relPosValid        = 1 << 2
+	carrSolnMask       = 3 << 3
+	carrSolnFloat      = 1 << 3
+	carrSolnFixed      = 2 << 3
+	relPosHeadingValid = 1 << 8
+)
+
+var errNoRelPos = errors.New("no relative position from the receiver yet; " +
+	"check it is a moving base rover with UBX-NAV-RELPOSNED output enabled")
+
+// relPosNED is a UBX-NAV-RELPOSNED message, which a u-blox receiver in moving base rover mode sends
+// with its position relative to the moving base receiver.
+type relPosNED struct {
+	baseline
+	flags uint32
+}
+
+// parseRelPosNED parses the payload of a version 1 UBX-NAV-RELPOSNED message.
+func parseRelPosNED(payload []byte) (relPosNED, error) {
+	if len(payload) != relPosNEDLength || payload[0] != 1 {
+		return relPosNED{}, fmt.Errorf("unsupported UBX-NAV-RELPOSNED message of %d bytes", len(payload))
+	}
+	// Each component is in centimeters, plus a high precision part in tenths of millimeters.
+	component := func(offset, hpOffset int) float64 {
+		cm := int32(binary.LittleEndian.Uint32(payload[offset:]))
+		hp := int8(payload[hpOffset])
+		return float64(cm)/100 + float64(hp)/10000
+	}
+
+	flags := binary.LittleEndian.Uint32(payload[60:])
+	solution := solutionNone
+	switch flags & carrSolnMask {
+	case carrSolnFloat:
+		solution = solutionFloat
+	case carrSolnFixed:
+		solution = solutionFixed
+	}
+
+	return relPosNED{
+		baseline: baseline{
+			North:        component(8, 32),
+			East:         component(12, 33),
+			Down:         component(16, 34),
+			HeadingError: float64(binary.LittleEndian.Uint32(payload[52:])) * 1e-5,
+			Solution:     solution,
+		},
+		flags: flags,
+	}, nil
+}
+
+// movingBaseReceiver finds the baseline from a u-blox receiver in moving base rover mode, which gets
+// corrections from a second receiver on the same robot rather than from a fixed base station.
+type movingBaseReceiver struct {
+	dev     io.ReadWriteCloser
+	logger  logging.Logger
+	clock   clock.Clock
+	workers utils.StoppableWorkers
+
+	mu        sync.Mutex
+	nmeaData  gpsutils.NmeaParser
+	relPos    *relPosNED
+	relPosAt  time.Time
+	readError error
+}
+
+func newMovingBaseReceiver(dev io.ReadWriteCloser, clk clock.Clock, logger logging.Logger) *movingBaseReceiver {
+	r := &movingBaseReceiver{dev: dev, logger: logger, clock: clk}
+	r.workers = utils.NewStoppableWorkers(r.read)
+	return r
+}
+
+// read reads the receiver's NMEA sentences, for its position, and its UBX messages, for the
+// baseline, until it's closed.
+func (r *movingBaseReceiver) read(ctx context.Context) {
+	reader := gpsutils.NewMixedReader(r.dev)
+	for ctx.Err() == nil {
+		line, msg, err := reader.Next()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe) {
+				r.mu.Lock()
+				r.readError = err
+				r.mu.Unlock()
+				return
+			}
+			r.logger.CDebugf(ctx, "skipping unreadable data from the receiver: %s", err)
+			continue
+		}
+
+		switch {
+		case msg != nil && msg.Class == ubxClassNav && msg.ID == ubxIDRelPosNED:
+			relPos, err := parseRelPosNED(msg.Payload)
+			if err != nil {
+				r.logger.CWarn(ctx, err)
+				continue
+			}
+			r.mu.Lock()
+			r.relPos, r.relPosAt = &relPos, r.clock.Now()
+			r.mu.Unlock()
+		case line != "":
+			r.mu.Lock()
+			err := r.nmeaData.ParseAndUpdate(line)
+			r.mu.Unlock()
+			if err != nil {
+				r.logger.CDebugf(ctx, "can't parse nmea sentence: %s", err)
+			}
+		}
+	}
+}
+
+func (r *movingBaseReceiver) Baseline(ctx context.Context) (baseline, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.relPos == nil {
+		if r.readError != nil {
+			return baseline{}, fmt.Errorf("stopped reading from the receiver: %w", r.readError)
+		}
+		return baseline{}, errNoRelPos
+	}
+	if age := r.clock.Since(r.relPosAt); age > maxRelPosAge {
+		return baseline{}, fmt.Errorf("last relative position from the receiver is %s old", age.Round(time.Second))
+	}
+	if r.relPos.flags&relPosValid == 0 || r.relPos.flags&relPosHeadingValid == 0 {
+		return baseline{}, errors.New("receiver has no valid relative position to the moving base")
+	}
+	return r.relPos.baseline, nil
+}
+
+func (r *movingBaseReceiver) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	location := r.nmeaData.Location
+	if location == nil || movementsensor.IsZeroPosition(location) || movementsensor.IsPositionNaN(location) {
+		return geo.NewPoint(math.NaN(), math.NaN()), math.NaN(), errors.New("receiver has no position yet")
+	}
+	return location, r.nmeaData.Alt, nil
+}
+
+func (r *movingBaseReceiver) Accuracy(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return &movementsensor.Accuracy{
+		Hdop:               float32(r.nmeaData.HDOP),
+		Vdop:               float32(r.nmeaData.VDOP),
+		NmeaFix:            int32(r.nmeaData.FixQuality),
+		CompassDegreeError: float32(math.NaN()),
+	}, nil
+}
+
+func (r *movingBaseReceiver) Close(ctx context.Context) error {
+	// Closing the port first unblocks the worker's read.
+	err := r.dev.Close()
+	r.workers.Stop()
+	return err
+}
@@ -4,14 +4,18 @@ package register
 import (
 	// Load all movementsensors.
 	_ "go.viam.com/rdk/components/movementsensor/adxl345"
+	_ "go.viam.com/rdk/components/movementsensor/bno055"
 	_ "go.viam.com/rdk/components/movementsensor/dualgps"
 	_ "go.viam.com/rdk/components/movementsensor/fake"
+	_ "go.viam.com/rdk/components/movementsensor/fused"
 	_ "go.viam.com/rdk/components/movementsensor/gpsnmea"
 	_ "go.viam.com/rdk/components/movementsensor/gpsrtkpmtk"
 	_ "go.viam.com/rdk/components/movementsensor/gpsrtkserial"
+	_ "go.viam.com/rdk/components/movementsensor/icm20948"
 	_ "go.viam.com/rdk/components/movementsensor/imuvectornav"
 	_ "go.viam.com/rdk/components/movementsensor/imuwit"
 	_ "go.viam.com/rdk/components/movementsensor/merged"
+	_ "go.viam.com/rdk/components/movementsensor/movingbaseline"
 	_ "go.viam.com/rdk/components/movementsensor/mpu6050"
 	_ "go.viam.com/rdk/components/movementsensor/replay"
 	_ "go.viam.com/rdk/components/movementsensor/wheeledodometry"
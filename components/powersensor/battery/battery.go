@@ -0,0 +1,346 @@
+// Package battery implements a PowerSensor that estimates the state of charge of a battery from another
+// PowerSensor measuring it.
+//
+// The estimate combines two methods. Coulomb counting integrates the current drawn from the battery, which
+// tracks changes accurately over short periods but drifts over long ones. When the battery has been resting,
+// drawing almost no current, for long enough, its voltage settles to a value that depends only on its state of
+// charge, so the estimate is reset from the configured voltage curve to cancel out that drift.
+package battery
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/powersensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var model = resource.DefaultModelFamily.WithModel("battery")
+
+const (
+	defaultSampleHz      = 10.
+	defaultLowBatteryPct = 20.
+	defaultRestSeconds   = 60.
+)
+
+// VoltagePoint is one point on a battery's open-circuit voltage curve.
+type VoltagePoint struct {
+	Volts   float64 `json:"volts"`
+	Percent float64 `json:"percent"`
+}
+
+// Config is used for converting battery attributes.
+type Config struct {
+	// PowerSensor is the sensor measuring the battery's voltage and current.
+	PowerSensor string `json:"power_sensor"`
+	// CapacityAmpHours is the rated capacity of the battery.
+	CapacityAmpHours float64 `json:"capacity_amp_hours"`
+	// VoltageCurve maps resting voltage to state of charge. Without one the estimate is from coulomb counting
+	// alone, starting at InitialPct.
+	VoltageCurve []VoltagePoint `json:"voltage_curve,omitempty"`
+	// InitialPct is the state of charge when the robot starts, if there is no voltage curve. Default is 100.
+	InitialPct *float64 `json:"initial_state_of_charge_pct,omitempty"`
+	// LowBatteryPct is the state of charge at or below which the battery is reported as low. Default is 20.
+	LowBatteryPct *float64 `json:"low_battery_pct,omitempty"`
+	// RestCurrentAmps is the largest current at which the battery counts as resting. Default is 1% of capacity.
+	RestCurrentAmps float64 `json:"rest_current_amps,omitempty"`
+	// RestSeconds is how long the battery has to rest before its voltage is trusted. Default is 60.
+	RestSeconds float64 `json:"rest_seconds,omitempty"`
+	// SampleHz is how often the current is sampled for coulomb counting. Default is 10.
+	SampleHz float64 `json:"sample_hz,omitempty"`
+	// InvertCurrent should be set if the power sensor reports current flowing into the battery as positive.
+	InvertCurrent bool `json:"invert_current,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.PowerSensor == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "power_sensor")
+	}
+	if conf.CapacityAmpHours <= 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("capacity_amp_hours must be greater than 0"))
+	}
+	if len(conf.VoltageCurve) == 1 {
+		return nil, resource.NewConfigValidationError(path, errors.New("voltage_curve needs at least 2 points"))
+	}
+	for i, pt := range conf.VoltageCurve {
+		if pt.Percent < 0 || pt.Percent > 100 {
+			return nil, resource.NewConfigValidationError(path,
+				fmt.Errorf("voltage_curve point %d has percent %v, must be between 0 and 100", i, pt.Percent))
+		}
+	}
+	if err := checkPct("initial_state_of_charge_pct", conf.InitialPct); err != nil {
+		return nil, resource.NewConfigValidationError(path, err)
+	}
+	if err := checkPct("low_battery_pct", conf.LowBatteryPct); err != nil {
+		return nil, resource.NewConfigValidationError(path, err)
+	}
+	if conf.RestCurrentAmps < 0 || conf.RestSeconds < 0 || conf.SampleHz < 0 {
+		return nil, resource.NewConfigValidationError(path,
+			errors.New("rest_current_amps, rest_seconds, and sample_hz cannot be negative"))
+	}
+	return []string{conf.PowerSensor}, nil
+}
+
+func checkPct(name string, pct *float64) error {
+	if pct != nil && (*pct < 0 || *pct > 100) {
+		return fmt.Errorf("%s must be between 0 and 100", name)
+	}
+	return nil
+}
+
+func init() {
+	resource.RegisterComponent(
+		powersensor.API,
+		model,
+		resource.Registration[powersensor.PowerSensor, *Config]{
+			Constructor: newBattery,
+		})
+}
+
+func newBattery(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (powersensor.PowerSensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	sensor, err := powersensor.FromDependencies(deps, newConf.PowerSensor)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Battery{
+		Named:         conf.ResourceName().AsNamed(),
+		sensor:        sensor,
+		logger:        logger,
+		invertCurrent: newConf.InvertCurrent,
+		lowPct:        defaultLowBatteryPct,
+		est:           newEstimator(newConf),
+	}
+	if newConf.LowBatteryPct != nil {
+		b.lowPct = *newConf.LowBatteryPct
+	}
+
+	// take the first sample now, so the estimate starts from the battery's voltage rather than a guess
+	if err := b.sample(ctx); err != nil {
+		return nil, err
+	}
+
+	sampleHz := newConf.SampleHz
+	if sampleHz == 0 {
+		sampleHz = defaultSampleHz
+	}
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	b.cancelFunc = cancel
+	b.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(func() {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / sampleHz))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-cancelCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := b.sample(cancelCtx); err != nil && cancelCtx.Err() == nil {
+				b.logger.CDebugw(cancelCtx, "failed to sample battery", "error", err)
+			}
+		}
+	}, b.activeBackgroundWorkers.Done)
+	return b, nil
+}
+
+// Battery is a PowerSensor which passes through the readings of another PowerSensor, and adds an estimate of
+// the battery's state of charge to its Readings.
+type Battery struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	sensor        powersensor.PowerSensor
+	logger        logging.Logger
+	invertCurrent bool
+	lowPct        float64
+
+	mu  sync.Mutex
+	est *estimator
+
+	activeBackgroundWorkers sync.WaitGroup
+	cancelFunc              func()
+}
+
+func (b *Battery) sample(ctx context.Context) error {
+	volts, _, err := b.sensor.Voltage(ctx, nil)
+	if err != nil {
+		return err
+	}
+	amps, _, err := b.sensor.Current(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if b.invertCurrent {
+		amps *= -1
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.est.update(volts, amps, time.Now())
+	return nil
+}
+
+// StateOfCharge returns the estimated state of charge as a percentage.
+func (b *Battery) StateOfCharge() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.est.soc
+}
+
+// Voltage returns the voltage of the battery.
+func (b *Battery) Voltage(ctx context.Context, extra map[string]interface{}) (float64, bool, error) {
+	return b.sensor.Voltage(ctx, extra)
+}
+
+// Current returns the current drawn from the battery, as reported by the underlying sensor.
+func (b *Battery) Current(ctx context.Context, extra map[string]interface{}) (float64, bool, error) {
+	return b.sensor.Current(ctx, extra)
+}
+
+// Power returns the power drawn from the battery.
+func (b *Battery) Power(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	return b.sensor.Power(ctx, extra)
+}
+
+// Readings returns the readings of the underlying sensor along with the state of charge, the charge remaining in
+// amp hours, and whether the battery is low.
+func (b *Battery) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	readings, err := b.sensor.Readings(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]interface{}, len(readings)+3)
+	for k, v := range readings {
+		out[k] = v
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out["state_of_charge_pct"] = b.est.soc
+	out["remaining_amp_hours"] = b.est.soc / 100 * b.est.capacityAh
+	out["low_battery"] = b.est.soc <= b.lowPct
+	return out, nil
+}
+
+// DoCommand supports "set_state_of_charge", which takes a "percent" and is useful after a full charge when
+// there is no voltage curve.
+func (b *Battery) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case "set_state_of_charge":
+		pct, ok := cmd["percent"].(float64)
+		if !ok || pct < 0 || pct > 100 {
+			return nil, errors.New("set_state_of_charge needs a percent between 0 and 100")
+		}
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.est.soc = pct
+		return map[string]interface{}{"state_of_charge_pct": pct}, nil
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
+
+// Close stops sampling the battery.
+func (b *Battery) Close(ctx context.Context) error {
+	b.cancelFunc()
+	b.activeBackgroundWorkers.Wait()
+	return nil
+}
+
+// estimator tracks a battery's state of charge.
+type estimator struct {
+	capacityAh  float64
+	curve       []VoltagePoint
+	restCurrent float64
+	restPeriod  time.Duration
+
+	soc          float64
+	started      bool
+	lastUpdate   time.Time
+	restingSince time.Time
+}
+
+func newEstimator(conf *Config) *estimator {
+	e := &estimator{
+		capacityAh:  conf.CapacityAmpHours,
+		curve:       append([]VoltagePoint(nil), conf.VoltageCurve...),
+		restCurrent: conf.RestCurrentAmps,
+		restPeriod:  time.Duration(conf.RestSeconds * float64(time.Second)),
+		soc:         100,
+	}
+	sort.Slice(e.curve, func(i, j int) bool { return e.curve[i].Volts < e.curve[j].Volts })
+	if e.restCurrent == 0 {
+		e.restCurrent = conf.CapacityAmpHours / 100
+	}
+	if e.restPeriod == 0 {
+		e.restPeriod = time.Duration(defaultRestSeconds * float64(time.Second))
+	}
+	if conf.InitialPct != nil {
+		e.soc = *conf.InitialPct
+	}
+	return e
+}
+
+// update integrates the current drawn since the last update, with positive amps discharging the battery, and
+// resets the estimate from the voltage curve if the battery has been resting.
+func (e *estimator) update(volts, amps float64, now time.Time) {
+	if !e.started {
+		e.started = true
+		e.lastUpdate = now
+		if len(e.curve) > 0 {
+			e.soc = e.socFromVoltage(volts)
+		}
+		return
+	}
+
+	hours := now.Sub(e.lastUpdate).Hours()
+	e.lastUpdate = now
+	e.soc -= 100 * amps * hours / e.capacityAh
+
+	if len(e.curve) > 0 && math.Abs(amps) <= e.restCurrent {
+		if e.restingSince.IsZero() {
+			e.restingSince = now
+		}
+		if now.Sub(e.restingSince) >= e.restPeriod {
+			e.soc = e.socFromVoltage(volts)
+		}
+	} else {
+		e.restingSince = time.Time{}
+	}
+	e.soc = math.Max(0, math.Min(100, e.soc))
+}
+
+// socFromVoltage linearly interpolates the voltage curve.
+func (e *estimator) socFromVoltage(volts float64) float64 {
+	if volts <= e.curve[0].Volts {
+		return e.curve[0].Percent
+	}
+	last := e.curve[len(e.curve)-1]
+	if volts >= last.Volts {
+		return last.Percent
+	}
+	i := sort.Search(len(e.curve), func(i int) bool { return e.curve[i].Volts >= volts })
+	lo, hi := e.curve[i-1], e.curve[i]
+	return lo.Percent + (volts-lo.Volts)/(hi.Volts-lo.Volts)*(hi.Percent-lo.Percent)
+}
@@ -0,0 +1,130 @@
+package battery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/powersensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+var testCurve = []VoltagePoint{
+	{Volts: 12.6, Percent: 100},
+	{Volts: 11.1, Percent: 0},
+	{Volts: 11.8, Percent: 50},
+}
+
+func TestValidate(t *testing.T) {
+	conf := &Config{PowerSensor: "ina", CapacityAmpHours: 5, VoltageCurve: testCurve}
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"ina"})
+
+	conf.PowerSensor = ""
+	_, err = conf.Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "power_sensor")
+
+	conf = &Config{PowerSensor: "ina"}
+	_, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "capacity_amp_hours must be greater than 0")
+
+	conf.CapacityAmpHours = 5
+	conf.VoltageCurve = testCurve[:1]
+	_, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "at least 2 points")
+
+	conf.VoltageCurve = []VoltagePoint{{Volts: 12, Percent: 100}, {Volts: 11, Percent: -1}}
+	_, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "must be between 0 and 100")
+
+	conf.VoltageCurve = nil
+	low := 120.
+	conf.LowBatteryPct = &low
+	_, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "low_battery_pct must be between 0 and 100")
+}
+
+func TestEstimator(t *testing.T) {
+	start := time.Now()
+
+	t.Run("coulomb counting", func(t *testing.T) {
+		initial := 80.
+		e := newEstimator(&Config{CapacityAmpHours: 10, InitialPct: &initial})
+		e.update(12, 2, start)
+		test.That(t, e.soc, test.ShouldEqual, 80)
+		// 2 amps for half an hour is 1 amp hour, or 10% of capacity
+		e.update(12, 2, start.Add(30*time.Minute))
+		test.That(t, e.soc, test.ShouldAlmostEqual, 70)
+		// charging
+		e.update(12, -4, start.Add(45*time.Minute))
+		test.That(t, e.soc, test.ShouldAlmostEqual, 80)
+		// never more than full
+		e.update(12, -100, start.Add(2*time.Hour))
+		test.That(t, e.soc, test.ShouldEqual, 100)
+	})
+
+	t.Run("voltage curve", func(t *testing.T) {
+		e := newEstimator(&Config{CapacityAmpHours: 10, VoltageCurve: testCurve, RestSeconds: 10})
+		test.That(t, e.socFromVoltage(13), test.ShouldEqual, 100)
+		test.That(t, e.socFromVoltage(10), test.ShouldEqual, 0)
+		test.That(t, e.socFromVoltage(12.2), test.ShouldAlmostEqual, 75)
+
+		// the first reading comes from the curve
+		e.update(11.8, 0, start)
+		test.That(t, e.soc, test.ShouldAlmostEqual, 50)
+
+		// under load the voltage sags, but that doesn't count until the battery rests
+		e.update(11.2, 10, start.Add(6*time.Minute))
+		test.That(t, e.soc, test.ShouldAlmostEqual, 40)
+		e.update(12.2, 0, start.Add(7*time.Minute))
+		test.That(t, e.soc, test.ShouldAlmostEqual, 40)
+		e.update(12.2, 0, start.Add(7*time.Minute+11*time.Second))
+		test.That(t, e.soc, test.ShouldAlmostEqual, 75)
+	})
+}
+
+func TestBattery(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	sensor := inject.NewPowerSensor("ina")
+	sensor.VoltageFunc = func(ctx context.Context, extra map[string]interface{}) (float64, bool, error) {
+		return 11.8, false, nil
+	}
+	sensor.CurrentFunc = func(ctx context.Context, extra map[string]interface{}) (float64, bool, error) {
+		return 0, false, nil
+	}
+	sensor.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"volts": 11.8, "amps": 0.}, nil
+	}
+	deps := resource.Dependencies{powersensor.Named("ina"): sensor}
+
+	low := 60.
+	conf := resource.Config{
+		Name:                "battery",
+		ConvertedAttributes: &Config{PowerSensor: "ina", CapacityAmpHours: 5, VoltageCurve: testCurve, LowBatteryPct: &low},
+	}
+	ps, err := newBattery(ctx, deps, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, ps.Close(ctx), test.ShouldBeNil)
+	}()
+
+	readings, err := ps.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["volts"], test.ShouldEqual, 11.8)
+	test.That(t, readings["state_of_charge_pct"], test.ShouldAlmostEqual, 50)
+	test.That(t, readings["remaining_amp_hours"], test.ShouldAlmostEqual, 2.5)
+	test.That(t, readings["low_battery"], test.ShouldBeTrue)
+
+	_, err = ps.DoCommand(ctx, map[string]interface{}{"command": "set_state_of_charge", "percent": 90.})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ps.(*Battery).StateOfCharge(), test.ShouldBeGreaterThan, 89)
+	_, err = ps.DoCommand(ctx, map[string]interface{}{"command": "set_state_of_charge", "percent": 190.})
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -4,6 +4,7 @@
 // INA219 datasheet: https://www.ti.com/lit/ds/symlink/ina219.pdf
 // Example repo: https://github.com/periph/devices/blob/main/ina219/ina219.go
 // INA226 datasheet: https://www.ti.com/lit/ds/symlink/ina226.pdf
+// INA260 datasheet: https://www.ti.com/lit/ds/symlink/ina260.pdf
 
 // The voltage, current and power can be read as
 // 16 bit big endian integers from their given registers.
@@ -16,6 +17,10 @@
 // The calibration register is programmed to measure current and power properly.
 // The calibration register is set to: calibratescale / (currentLSB * senseResistor)
 
+// The INA260 has its shunt resistor built in, so it has no calibration register and fixed LSBs:
+// 1.25 mV for voltage, 1.25 mA for current, and 10 mW for power. Its current register is also at a
+// different address.
+
 package ina
 
 import (
@@ -37,6 +42,7 @@ import (
 const (
 	modelName219         = "ina219"
 	modelName226         = "ina226"
+	modelName260         = "ina260"
 	defaultI2Caddr       = 0x40
 	configRegister       = 0x00
 	shuntVoltageRegister = 0x01
@@ -44,6 +50,8 @@ const (
 	powerRegister        = 0x03
 	currentRegister      = 0x04
 	calibrationRegister  = 0x05
+
+	currentRegister260 = 0x01
 )
 
 // values for inas in nano units so need to convert.
@@ -59,7 +67,13 @@ var (
 	calibrateScale226 = (toNano(1) * toNano(1) / 100000) << 9  // .00512 is internal fixed value for ina226
 )
 
-var inaModels = []string{modelName219, modelName226}
+// fixed LSBs for the ina260, in nano units.
+var (
+	currentLSB260 = toNano(1.25e-3)
+	powerLSB260   = toNano(10e-3)
+)
+
+var inaModels = []string{modelName219, modelName226, modelName260}
 
 // Config is used for converting config attributes.
 type Config struct {
@@ -125,6 +139,9 @@ func newINA(
 	}
 
 	maxCurrent := toNano(conf.MaxCurrent)
+	if modelName == modelName260 && (conf.MaxCurrent != 0 || conf.ShuntResistance != 0) {
+		logger.Warn("ignoring max_current_amps and shunt_resistance, the ina260 has a fixed internal shunt")
+	}
 	if maxCurrent == 0 {
 		switch modelName {
 		case modelName219:
@@ -137,7 +154,7 @@ func newINA(
 	}
 
 	resistance := toNano(conf.ShuntResistance)
-	if resistance == 0 {
+	if resistance == 0 && modelName != modelName260 {
 		resistance = senseResistor
 		logger.Info("using default resistor value 0.1 ohms")
 	}
@@ -199,6 +216,10 @@ func (d *ina) setCalibrationScale(modelName string) error {
 	case modelName226:
 		calibratescale = calibrateScale226
 		d.powerLSB = 25 * d.currentLSB
+	case modelName260:
+		d.currentLSB = currentLSB260
+		d.powerLSB = powerLSB260
+		return nil
 	default:
 		return errors.New("ina model not supported")
 	}
@@ -216,6 +237,10 @@ func (d *ina) setCalibrationScale(modelName string) error {
 }
 
 func (d *ina) calibrate() error {
+	if d.model == modelName260 {
+		// nothing to calibrate, and the default configuration is already continuous measurement
+		return nil
+	}
 	handle, err := i2c.NewI2C(d.addr, d.bus)
 	if err != nil {
 		d.logger.Errorf("can't open ina i2c: %s", err)
@@ -255,8 +280,8 @@ func (d *ina) Voltage(ctx context.Context, extra map[string]interface{}) (float6
 
 	var voltage float64
 	switch d.model {
-	case modelName226:
-		// voltage is 1.25 mV/bit for the ina226
+	case modelName226, modelName260:
+		// voltage is 1.25 mV/bit for the ina226 and ina260
 		voltage = float64(bus) * 1.25e-3
 	case modelName219:
 		// lsb is 4mV, must shift right 3 bits
@@ -285,7 +310,11 @@ func (d *ina) Current(ctx context.Context, extra map[string]interface{}) (float6
 		return 0, false, err
 	}
 
-	rawCur, err := handle.ReadRegS16BE(currentRegister)
+	register := byte(currentRegister)
+	if d.model == modelName260 {
+		register = currentRegister260
+	}
+	rawCur, err := handle.ReadRegS16BE(register)
 	if err != nil {
 		return 0, false, err
 	}
@@ -312,11 +341,22 @@ func (d *ina) Power(ctx context.Context, extra map[string]interface{}) (float64,
 		return 0, err
 	}
 
-	pow, err := handle.ReadRegS16BE(powerRegister)
-	if err != nil {
-		return 0, err
+	var pow int64
+	if d.model == modelName260 {
+		// the ina260 power register is unsigned
+		raw, err := handle.ReadRegU16BE(powerRegister)
+		if err != nil {
+			return 0, err
+		}
+		pow = int64(raw)
+	} else {
+		raw, err := handle.ReadRegS16BE(powerRegister)
+		if err != nil {
+			return 0, err
+		}
+		pow = int64(raw)
 	}
-	power := fromNano(float64(int64(pow) * d.powerLSB))
+	power := fromNano(float64(pow * d.powerLSB))
 	return power, nil
 }
 
@@ -123,12 +123,17 @@ func newSensor(
 	name resource.Name,
 	conf *Config,
 	logger logging.Logger,
-) (sensor.Sensor, error) {
-	i2cbus, err := buses.NewI2cBus(conf.I2CBus)
+) (_ sensor.Sensor, err error) {
+	i2cbus, releaseBus, err := buses.AcquireI2cBus(conf.I2CBus)
 	if err != nil {
 		return nil, fmt.Errorf("bme280 init: failed to open i2c bus %s: %w",
 			conf.I2CBus, err)
 	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, releaseBus())
+		}
+	}()
 
 	addr := conf.I2cAddr
 	if addr == 0 {
@@ -137,11 +142,12 @@ func newSensor(
 	}
 
 	s := &bme280{
-		Named:    name.AsNamed(),
-		logger:   logger,
-		bus:      i2cbus,
-		addr:     byte(addr),
-		lastTemp: -999, // initialize to impossible temp
+		Named:      name.AsNamed(),
+		logger:     logger,
+		bus:        i2cbus,
+		releaseBus: releaseBus,
+		addr:       byte(addr),
+		lastTemp:   -999, // initialize to impossible temp
 	}
 
 	err = s.reset(ctx)
@@ -194,15 +200,20 @@ func newSensor(
 type bme280 struct {
 	resource.Named
 	resource.AlwaysRebuild
-	resource.TriviallyCloseable
 	logger logging.Logger
 
 	bus         buses.I2C
+	releaseBus  func() error
 	addr        byte
 	calibration map[string]int
 	lastTemp    float64 // Store raw data from temp for humidity calculations
 }
 
+// Close releases the sensor's i2c bus.
+func (s *bme280) Close(ctx context.Context) error {
+	return s.releaseBus()
+}
+
 // Readings returns a list containing single item (current temperature).
 func (s *bme280) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
 	handle, err := s.bus.OpenHandle(s.addr)
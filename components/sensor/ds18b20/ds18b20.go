@@ -4,13 +4,11 @@ package ds18b20
 import (
 	"context"
 	"errors"
-	"fmt"
 	"math"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 
+	"go.viam.com/rdk/components/board/genericlinux/buses"
 	"go.viam.com/rdk/components/sensor"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
@@ -18,12 +16,22 @@ import (
 
 var model = resource.DefaultModelFamily.WithModel("ds18b20")
 
+// oneWireFamily is the 1-Wire family code shared by all DS18B20 temperature sensors.
+const oneWireFamily = "28"
+
 // Config is used for converting config attributes.
 type Config struct {
-	resource.TriviallyValidateConfig
 	UniqueID string `json:"unique_id"`
 }
 
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.UniqueID == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "unique_id")
+	}
+	return nil, nil
+}
+
 func init() {
 	resource.RegisterComponent(
 		sensor.API,
@@ -45,12 +53,10 @@ func init() {
 }
 
 func newSensor(name resource.Name, id string, logger logging.Logger) sensor.Sensor {
-	// temp sensors are in family 28
 	return &Sensor{
-		Named:         name.AsNamed(),
-		logger:        logger,
-		OneWireID:     id,
-		OneWireFamily: "28",
+		Named:  name.AsNamed(),
+		logger: logger,
+		device: buses.NewOneWireDevice(oneWireFamily, id),
 	}
 }
 
@@ -59,31 +65,43 @@ type Sensor struct {
 	resource.Named
 	resource.AlwaysRebuild
 	resource.TriviallyCloseable
-	OneWireID     string
-	OneWireFamily string
-	logger        logging.Logger
+	device *buses.OneWireDevice
+	logger logging.Logger
 }
 
 // ReadTemperatureCelsius returns current temperature in celsius.
 func (s *Sensor) ReadTemperatureCelsius(ctx context.Context) (float64, error) {
-	// logic here is specific to 1-wire protocol, could be abstracted next time we
-	// want to build support for a different 1-wire device,
-	// or look at support via periph (or other library)
-	devPath := fmt.Sprintf("/sys/bus/w1/devices/%s-%s/w1_slave", s.OneWireFamily, s.OneWireID)
-	dat, err := os.ReadFile(filepath.Clean(devPath))
+	dat, err := s.device.ReadSlave()
 	if err != nil {
 		return math.NaN(), err
 	}
-	tempString := strings.TrimSuffix(string(dat), "\n")
-	splitString := strings.Split(tempString, "t=")
-	if len(splitString) == 2 {
-		tempMili, err := strconv.ParseFloat(splitString[1], 32)
-		if err != nil {
-			return math.NaN(), err
-		}
-		return tempMili / 1000, nil
+	return parseTemperature(dat)
+}
+
+// parseTemperature interprets the contents of a DS18B20's w1_slave file, which look like this:
+//
+//	72 01 4b 46 7f ff 0e 10 57 : crc=57 YES
+//	72 01 4b 46 7f ff 0e 10 57 t=23125
+//
+// The first line reports whether the scratchpad passed its CRC check, and the second contains the
+// temperature in thousandths of a degree Celsius.
+func parseTemperature(dat []byte) (float64, error) {
+	lines := strings.Split(strings.TrimSpace(string(dat)), "\n")
+	if len(lines) != 2 {
+		return math.NaN(), errors.New("temperature could not be read")
+	}
+	if !strings.HasSuffix(strings.TrimSpace(lines[0]), "YES") {
+		return math.NaN(), errors.New("temperature reading failed its CRC check")
+	}
+	_, tempString, ok := strings.Cut(lines[1], "t=")
+	if !ok {
+		return math.NaN(), errors.New("temperature could not be read")
+	}
+	tempMilli, err := strconv.ParseFloat(strings.TrimSpace(tempString), 64)
+	if err != nil {
+		return math.NaN(), err
 	}
-	return math.NaN(), errors.New("temperature could not be read")
+	return tempMilli / 1000, nil
 }
 
 // Readings returns a list containing single item (current temperature).
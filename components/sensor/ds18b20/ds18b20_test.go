@@ -0,0 +1,40 @@
+package ds18b20
+
+import (
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/resource"
+)
+
+func TestParseTemperature(t *testing.T) {
+	temp, err := parseTemperature([]byte(
+		"72 01 4b 46 7f ff 0e 10 57 : crc=57 YES\n72 01 4b 46 7f ff 0e 10 57 t=23125\n"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, temp, test.ShouldAlmostEqual, 23.125)
+
+	temp, err = parseTemperature([]byte(
+		"72 01 4b 46 7f ff 0e 10 57 : crc=57 YES\n72 01 4b 46 7f ff 0e 10 57 t=-1250\n"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, temp, test.ShouldAlmostEqual, -1.25)
+
+	_, err = parseTemperature([]byte(
+		"72 01 4b 46 7f ff 0e 10 57 : crc=00 NO\n72 01 4b 46 7f ff 0e 10 57 t=23125\n"))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "CRC")
+
+	_, err = parseTemperature([]byte("garbage"))
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestConfigValidate(t *testing.T) {
+	conf := Config{}
+	_, err := conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "unique_id")
+
+	conf.UniqueID = "0316a2795b6d"
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+}
@@ -0,0 +1,92 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	"github.com/goburrow/modbus"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxModbusRegisters is the most registers a single Modbus request may read.
+	maxModbusRegisters = 125
+	// maxI2CBytes is the most bytes a single SMBus block read may return.
+	maxI2CBytes = 32
+)
+
+// bus reads runs of registers from the devices on a serial or network bus. It is only used by the worker polling
+// it, so it need not be safe for concurrent use.
+type bus interface {
+	// readBlock reads units registers of the device, starting at register.
+	readBlock(ctx context.Context, device byte, registerType string, register, units int) ([]byte, error)
+	// unitBytes is the size of a register.
+	unitBytes() int
+	// maxUnits is the most registers readBlock may read at once.
+	maxUnits() int
+	Close() error
+}
+
+func openBus(conf BusConfig) (bus, error) {
+	timeout := defaultTimeout
+	if conf.TimeoutMs > 0 {
+		timeout = time.Duration(conf.TimeoutMs) * time.Millisecond
+	}
+	switch conf.Type {
+	case busModbusRTU:
+		handler := modbus.NewRTUClientHandler(conf.SerialPath)
+		handler.BaudRate = conf.SerialBaudRate
+		if handler.BaudRate == 0 {
+			handler.BaudRate = defaultBaudRate
+		}
+		handler.DataBits = 8
+		handler.Parity = "N"
+		handler.StopBits = 1
+		handler.Timeout = timeout
+		// the port is opened by the first request, so the sensor starts even if the port is not there yet
+		return &modbusBus{
+			client:    modbus.NewClient(handler),
+			setDevice: func(device byte) { handler.SlaveId = device },
+			close:     handler.Close,
+		}, nil
+	case busModbusTCP:
+		handler := modbus.NewTCPClientHandler(conf.Address)
+		handler.Timeout = timeout
+		return &modbusBus{
+			client:    modbus.NewClient(handler),
+			setDevice: func(device byte) { handler.SlaveId = device },
+			close:     handler.Close,
+		}, nil
+	case busI2C:
+		return openI2CBus(conf.I2CBus)
+	default:
+		return nil, errors.Errorf("unknown bus type %q", conf.Type)
+	}
+}
+
+// modbusBus is a Modbus RTU or TCP bus. Devices share the bus's connection by taking turns.
+type modbusBus struct {
+	client    modbus.Client
+	setDevice func(device byte)
+	close     func() error
+}
+
+func (b *modbusBus) readBlock(ctx context.Context, device byte, registerType string, register, units int) ([]byte, error) {
+	b.setDevice(device)
+	if registerType == registerInput {
+		return b.client.ReadInputRegisters(uint16(register), uint16(units))
+	}
+	return b.client.ReadHoldingRegisters(uint16(register), uint16(units))
+}
+
+func (b *modbusBus) unitBytes() int {
+	return 2
+}
+
+func (b *modbusBus) maxUnits() int {
+	return maxModbusRegisters
+}
+
+func (b *modbusBus) Close() error {
+	return b.close()
+}
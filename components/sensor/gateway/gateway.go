@@ -0,0 +1,502 @@
+// Package gateway implements a sensor that polls many Modbus and I2C sensors on shared schedules, so that a
+// single machine part can monitor dozens of them on a low powered board. All of its points are returned by one
+// call to Readings, so they are captured together as one batch.
+package gateway
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("gateway")
+
+const (
+	busModbusRTU = "modbus_rtu"
+	busModbusTCP = "modbus_tcp"
+	busI2C       = "i2c"
+
+	registerHolding = "holding"
+	registerInput   = "input"
+
+	defaultPollInterval = 10 * time.Second
+	defaultTimeout      = time.Second
+	defaultBaudRate     = 9600
+)
+
+// dataTypeSizes are the sizes in bytes of the data types a point may have.
+var dataTypeSizes = map[string]int{
+	"uint8":   1,
+	"int8":    1,
+	"uint16":  2,
+	"int16":   2,
+	"uint32":  4,
+	"int32":   4,
+	"float32": 4,
+}
+
+// BusConfig is a serial or network bus shared by the devices polled on it.
+type BusConfig struct {
+	Name string `json:"name"`
+	// Type is modbus_rtu, modbus_tcp, or i2c.
+	Type string `json:"type"`
+	// SerialPath and SerialBaudRate configure modbus_rtu buses.
+	SerialPath     string `json:"serial_path,omitempty"`
+	SerialBaudRate int    `json:"serial_baud_rate,omitempty"`
+	// Address is the host and port of a modbus_tcp bus.
+	Address string `json:"address,omitempty"`
+	// I2CBus is the bus number of an i2c bus.
+	I2CBus    string `json:"i2c_bus,omitempty"`
+	TimeoutMs int    `json:"timeout_ms,omitempty"`
+}
+
+// PointConfig is a single value read from a register of a device on a bus.
+type PointConfig struct {
+	Name string `json:"name"`
+	Bus  string `json:"bus"`
+	// DeviceID is the Modbus unit ID or the I2C address of the device.
+	DeviceID int `json:"device_id"`
+	Register int `json:"register"`
+	// RegisterType is holding, the default, or input for Modbus points.
+	RegisterType string `json:"register_type,omitempty"`
+	// DataType is uint16, the default, int16, uint32, int32, or float32. I2C points may also be uint8 or int8.
+	DataType string `json:"data_type,omitempty"`
+	// SwapWords reads 32 bit Modbus values low word first.
+	SwapWords bool `json:"swap_words,omitempty"`
+	// Values read are multiplied by Scale, if set, and then Offset is added.
+	Scale           float64 `json:"scale,omitempty"`
+	Offset          float64 `json:"offset,omitempty"`
+	PollIntervalSec float64 `json:"poll_interval_sec,omitempty"`
+}
+
+// Config is used for converting config attributes.
+type Config struct {
+	Buses  []BusConfig   `json:"buses"`
+	Points []PointConfig `json:"points"`
+	// PollIntervalSec is how often points that do not set their own poll interval are read.
+	PollIntervalSec float64 `json:"poll_interval_sec,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if len(conf.Points) == 0 {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "points")
+	}
+	if conf.PollIntervalSec < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("poll_interval_sec must not be negative"))
+	}
+
+	busTypes := map[string]string{}
+	for _, bus := range conf.Buses {
+		if bus.Name == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(path, "buses.name")
+		}
+		if _, ok := busTypes[bus.Name]; ok {
+			return nil, resource.NewConfigValidationError(path, errors.Errorf("bus %q is configured twice", bus.Name))
+		}
+		busTypes[bus.Name] = bus.Type
+		switch bus.Type {
+		case busModbusRTU:
+			if bus.SerialPath == "" {
+				return nil, resource.NewConfigValidationFieldRequiredError(path, "buses.serial_path")
+			}
+		case busModbusTCP:
+			if bus.Address == "" {
+				return nil, resource.NewConfigValidationFieldRequiredError(path, "buses.address")
+			}
+		case busI2C:
+			if bus.I2CBus == "" {
+				return nil, resource.NewConfigValidationFieldRequiredError(path, "buses.i2c_bus")
+			}
+		default:
+			return nil, resource.NewConfigValidationError(path,
+				errors.Errorf("bus %q must have a type of %s, %s, or %s", bus.Name, busModbusRTU, busModbusTCP, busI2C))
+		}
+	}
+
+	names := map[string]bool{}
+	for _, point := range conf.Points {
+		if point.Name == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(path, "points.name")
+		}
+		if names[point.Name] {
+			return nil, resource.NewConfigValidationError(path, errors.Errorf("point %q is configured twice", point.Name))
+		}
+		names[point.Name] = true
+		if err := point.validate(busTypes); err != nil {
+			return nil, resource.NewConfigValidationError(path, errors.Wrapf(err, "point %q", point.Name))
+		}
+	}
+	return nil, nil
+}
+
+func (point *PointConfig) validate(busTypes map[string]string) error {
+	busType, ok := busTypes[point.Bus]
+	if !ok {
+		return errors.Errorf("no bus named %q is configured", point.Bus)
+	}
+	size, ok := dataTypeSizes[point.dataType()]
+	if !ok {
+		return errors.Errorf("unknown data_type %q", point.DataType)
+	}
+	if point.PollIntervalSec < 0 {
+		return errors.New("poll_interval_sec must not be negative")
+	}
+	if busType == busI2C {
+		if point.RegisterType != "" {
+			return errors.New("register_type only applies to Modbus points")
+		}
+		if point.DeviceID < 1 || point.DeviceID > 127 {
+			return errors.New("device_id must be an I2C address from 1 to 127")
+		}
+		if point.Register < 0 || point.Register > 255 {
+			return errors.New("register must be from 0 to 255")
+		}
+		return nil
+	}
+	if point.RegisterType != "" && point.RegisterType != registerHolding && point.RegisterType != registerInput {
+		return errors.Errorf("register_type must be %s or %s", registerHolding, registerInput)
+	}
+	if size == 1 {
+		return errors.Errorf("data_type %q does not fit Modbus registers", point.DataType)
+	}
+	if point.DeviceID < 0 || point.DeviceID > 247 {
+		return errors.New("device_id must be a Modbus unit ID from 0 to 247")
+	}
+	if point.Register < 0 || point.Register > math.MaxUint16 {
+		return errors.New("register must be from 0 to 65535")
+	}
+	return nil
+}
+
+func (point *PointConfig) dataType() string {
+	if point.DataType == "" {
+		return "uint16"
+	}
+	return point.DataType
+}
+
+func init() {
+	resource.RegisterComponent(
+		sensor.API,
+		model,
+		resource.Registration[sensor.Sensor, *Config]{
+			Constructor: newSensor,
+		})
+}
+
+// point is a configured point along with the last value read from it.
+type point struct {
+	name         string
+	device       byte
+	registerType string
+	register     int
+	dataType     string
+	size         int
+	swapWords    bool
+	scale        float64
+	offset       float64
+	interval     time.Duration
+	// blockOffset is where the point's bytes start in the block it is read with.
+	blockOffset int
+
+	value    float64
+	lastRead time.Time
+	lastErr  error
+}
+
+// Sensor polls every configured point, with one worker for each bus that reads its points in as few requests as
+// possible. Readings returns the latest value of each point without waiting for the buses.
+type Sensor struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger logging.Logger
+
+	buses   []bus
+	workers utils.StoppableWorkers
+
+	mu     sync.Mutex
+	points []*point
+}
+
+func newSensor(
+	ctx context.Context,
+	_ resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (sensor.Sensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultInterval := defaultPollInterval
+	if newConf.PollIntervalSec > 0 {
+		defaultInterval = secondsToDuration(newConf.PollIntervalSec)
+	}
+	pointsByBus := map[string][]*point{}
+	s := &Sensor{
+		Named:  conf.ResourceName().AsNamed(),
+		logger: logger,
+	}
+	for _, pointConf := range newConf.Points {
+		p := &point{
+			name:         pointConf.Name,
+			device:       byte(pointConf.DeviceID),
+			registerType: pointConf.RegisterType,
+			register:     pointConf.Register,
+			dataType:     pointConf.dataType(),
+			size:         dataTypeSizes[pointConf.dataType()],
+			swapWords:    pointConf.SwapWords,
+			scale:        pointConf.Scale,
+			offset:       pointConf.Offset,
+			interval:     defaultInterval,
+		}
+		if p.registerType == "" {
+			p.registerType = registerHolding
+		}
+		if p.scale == 0 {
+			p.scale = 1
+		}
+		if pointConf.PollIntervalSec > 0 {
+			p.interval = secondsToDuration(pointConf.PollIntervalSec)
+		}
+		s.points = append(s.points, p)
+		pointsByBus[pointConf.Bus] = append(pointsByBus[pointConf.Bus], p)
+	}
+
+	var pollers []func(context.Context)
+	for _, busConf := range newConf.Buses {
+		points := pointsByBus[busConf.Name]
+		if len(points) == 0 {
+			continue
+		}
+		b, err := openBus(busConf)
+		if err != nil {
+			return nil, multierr.Combine(errors.Wrapf(err, "failed to open bus %q", busConf.Name), s.closeBuses())
+		}
+		s.buses = append(s.buses, b)
+		blocks := planBlocks(points, b.unitBytes(), b.maxUnits())
+		logger.CDebugw(ctx, "polling bus", "bus", busConf.Name, "points", len(points), "reads", len(blocks))
+		pollers = append(pollers, func(ctx context.Context) {
+			s.poll(ctx, b, blocks)
+		})
+	}
+	s.workers = utils.NewStoppableWorkers(pollers...)
+	return s, nil
+}
+
+func secondsToDuration(sec float64) time.Duration {
+	return time.Duration(sec * float64(time.Second))
+}
+
+// block is a run of registers of a device that is read with a single request.
+type block struct {
+	device       byte
+	registerType string
+	register     int
+	units        int
+	interval     time.Duration
+	next         time.Time
+	points       []*point
+}
+
+// planBlocks groups the points of a bus into as few reads as possible. Points of the same device, register type,
+// and poll interval whose registers are adjacent or overlap are read together, up to maxUnits registers at a time.
+// Registers between points are never read, since devices may reject reads of registers they do not have.
+func planBlocks(points []*point, unitBytes, maxUnits int) []*block {
+	sorted := slices.Clone(points)
+	slices.SortStableFunc(sorted, func(left, right *point) int {
+		switch {
+		case left.device != right.device:
+			return int(left.device) - int(right.device)
+		case left.registerType != right.registerType:
+			return strings.Compare(left.registerType, right.registerType)
+		case left.interval != right.interval:
+			if left.interval < right.interval {
+				return -1
+			}
+			return 1
+		default:
+			return left.register - right.register
+		}
+	})
+
+	var (
+		blocks []*block
+		cur    *block
+	)
+	for _, p := range sorted {
+		units := (p.size + unitBytes - 1) / unitBytes
+		end := p.register + units
+		if cur != nil && cur.device == p.device && cur.registerType == p.registerType && cur.interval == p.interval &&
+			p.register <= cur.register+cur.units && end-cur.register <= maxUnits {
+			cur.units = max(cur.units, end-cur.register)
+		} else {
+			cur = &block{
+				device:       p.device,
+				registerType: p.registerType,
+				register:     p.register,
+				units:        units,
+				interval:     p.interval,
+			}
+			blocks = append(blocks, cur)
+		}
+		p.blockOffset = (p.register - cur.register) * unitBytes
+		cur.points = append(cur.points, p)
+	}
+	return blocks
+}
+
+// poll reads each block whenever it is due until ctx is done.
+func (s *Sensor) poll(ctx context.Context, b bus, blocks []*block) {
+	for {
+		now := time.Now()
+		var next time.Time
+		for _, blk := range blocks {
+			if !blk.next.After(now) {
+				s.readBlock(ctx, b, blk)
+				if ctx.Err() != nil {
+					return
+				}
+				// reads that fall behind are skipped rather than made all at once
+				blk.next = blk.next.Add(blk.interval)
+				if blk.next.Before(now) {
+					blk.next = now.Add(blk.interval)
+				}
+			}
+			if next.IsZero() || blk.next.Before(next) {
+				next = blk.next
+			}
+		}
+		if !goutils.SelectContextOrWait(ctx, time.Until(next)) {
+			return
+		}
+	}
+}
+
+func (s *Sensor) readBlock(ctx context.Context, b bus, blk *block) {
+	data, err := b.readBlock(ctx, blk.device, blk.registerType, blk.register, blk.units)
+	if err == nil && len(data) < blk.units*b.unitBytes() {
+		err = errors.Errorf("read %d bytes from device %d but expected %d", len(data), blk.device, blk.units*b.unitBytes())
+	}
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range blk.points {
+		if err != nil {
+			if ctx.Err() == nil && (p.lastErr == nil || p.lastErr.Error() != err.Error()) {
+				s.logger.Infow("error reading point", "point", p.name, "error", err)
+			}
+			p.lastErr = err
+			continue
+		}
+		p.value = p.decode(data[p.blockOffset:p.blockOffset+p.size])*p.scale + p.offset
+		p.lastRead = now
+		p.lastErr = nil
+	}
+}
+
+// decode returns the big endian value of the point in raw.
+func (p *point) decode(raw []byte) float64 {
+	if p.swapWords && len(raw) == 4 {
+		raw = []byte{raw[2], raw[3], raw[0], raw[1]}
+	}
+	switch p.dataType {
+	case "uint8":
+		return float64(raw[0])
+	case "int8":
+		return float64(int8(raw[0]))
+	case "int16":
+		return float64(int16(binary.BigEndian.Uint16(raw)))
+	case "uint32":
+		return float64(binary.BigEndian.Uint32(raw))
+	case "int32":
+		return float64(int32(binary.BigEndian.Uint32(raw)))
+	case "float32":
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(raw)))
+	default:
+		return float64(binary.BigEndian.Uint16(raw))
+	}
+}
+
+// Readings returns the latest value of each point that has been read. Points that have never been read are
+// left out.
+func (s *Sensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	readings := make(map[string]interface{}, len(s.points))
+	var lastErr error
+	for _, p := range s.points {
+		if p.lastRead.IsZero() {
+			if p.lastErr != nil {
+				lastErr = p.lastErr
+			}
+			continue
+		}
+		readings[p.name] = p.value
+	}
+	if len(readings) == 0 {
+		if lastErr != nil {
+			return nil, lastErr
+		}
+		return nil, errors.New("no points have been read yet")
+	}
+	return readings, nil
+}
+
+// DoCommand supports status, which returns when each point was last read and the error from its latest read,
+// if it failed.
+func (s *Sensor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"].(string)
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case "status":
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		points := make(map[string]interface{}, len(s.points))
+		for _, p := range s.points {
+			status := map[string]interface{}{}
+			if !p.lastRead.IsZero() {
+				status["last_read"] = p.lastRead.Format(time.RFC3339Nano)
+			}
+			if p.lastErr != nil {
+				status["error"] = p.lastErr.Error()
+			}
+			points[p.name] = status
+		}
+		return map[string]interface{}{"points": points}, nil
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
+
+// Close stops polling and closes the buses.
+func (s *Sensor) Close(ctx context.Context) error {
+	s.workers.Stop()
+	return s.closeBuses()
+}
+
+func (s *Sensor) closeBuses() error {
+	var err error
+	for _, b := range s.buses {
+		err = multierr.Combine(err, b.Close())
+	}
+	return err
+}
@@ -0,0 +1,168 @@
+package gateway
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/utils"
+)
+
+func TestValidate(t *testing.T) {
+	rtu := BusConfig{Name: "rs485", Type: busModbusRTU, SerialPath: "/dev/ttyUSB0"}
+	i2c := BusConfig{Name: "i2c1", Type: busI2C, I2CBus: "1"}
+	for _, conf := range []Config{
+		{Buses: []BusConfig{rtu}},
+		{Buses: []BusConfig{rtu, rtu}, Points: []PointConfig{{Name: "level", Bus: "rs485"}}},
+		{Buses: []BusConfig{{Name: "rs485", Type: busModbusRTU}}, Points: []PointConfig{{Name: "level", Bus: "rs485"}}},
+		{Buses: []BusConfig{{Name: "can", Type: "can"}}, Points: []PointConfig{{Name: "level", Bus: "can"}}},
+		{Buses: []BusConfig{rtu}, Points: []PointConfig{{Name: "level", Bus: "rs232"}}},
+		{Buses: []BusConfig{rtu}, Points: []PointConfig{{Name: "level", Bus: "rs485"}, {Name: "level", Bus: "rs485"}}},
+		{Buses: []BusConfig{rtu}, Points: []PointConfig{{Name: "level", Bus: "rs485", DataType: "float64"}}},
+		{Buses: []BusConfig{rtu}, Points: []PointConfig{{Name: "level", Bus: "rs485", DataType: "uint8"}}},
+		{Buses: []BusConfig{rtu}, Points: []PointConfig{{Name: "level", Bus: "rs485", RegisterType: "coil"}}},
+		{Buses: []BusConfig{i2c}, Points: []PointConfig{{Name: "temp", Bus: "i2c1"}}},
+		{Buses: []BusConfig{i2c}, Points: []PointConfig{{Name: "temp", Bus: "i2c1", DeviceID: 0x48, Register: 256}}},
+	} {
+		_, err := conf.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+
+	conf := Config{
+		Buses: []BusConfig{rtu, i2c},
+		Points: []PointConfig{
+			{Name: "level", Bus: "rs485", DeviceID: 3, Register: 100, DataType: "float32"},
+			{Name: "temp", Bus: "i2c1", DeviceID: 0x48, DataType: "int8"},
+		},
+	}
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldBeEmpty)
+}
+
+func TestPlanBlocks(t *testing.T) {
+	points := []*point{
+		{name: "a", device: 1, registerType: registerHolding, register: 10, size: 2, interval: time.Second},
+		{name: "b", device: 1, registerType: registerHolding, register: 11, size: 4, interval: time.Second},
+		{name: "c", device: 1, registerType: registerHolding, register: 14, size: 2, interval: time.Second},
+		{name: "d", device: 1, registerType: registerInput, register: 12, size: 2, interval: time.Second},
+		{name: "e", device: 2, registerType: registerHolding, register: 12, size: 2, interval: time.Second},
+		{name: "f", device: 1, registerType: registerHolding, register: 13, size: 2, interval: time.Minute},
+		{name: "g", device: 1, registerType: registerHolding, register: 13, size: 2, interval: time.Second},
+	}
+	blocks := planBlocks(points, 2, maxModbusRegisters)
+	test.That(t, blocks, test.ShouldHaveLength, 4)
+
+	test.That(t, blocks[0].register, test.ShouldEqual, 10)
+	test.That(t, blocks[0].units, test.ShouldEqual, 5)
+	var names []string
+	for _, p := range blocks[0].points {
+		names = append(names, p.name)
+	}
+	test.That(t, names, test.ShouldResemble, []string{"a", "b", "g", "c"})
+	test.That(t, points[2].blockOffset, test.ShouldEqual, 8)
+	test.That(t, blocks[1].points[0].name, test.ShouldEqual, "f")
+	test.That(t, blocks[2].points[0].name, test.ShouldEqual, "d")
+	test.That(t, blocks[3].points[0].name, test.ShouldEqual, "e")
+
+	// blocks are split once they would read too many registers
+	blocks = planBlocks([]*point{points[0], points[1], points[6]}, 2, 3)
+	test.That(t, blocks, test.ShouldHaveLength, 2)
+}
+
+// fakeBus serves registers from memory, or fails for devices in failing.
+type fakeBus struct {
+	registers map[int]uint16
+	failing   map[byte]bool
+}
+
+func (b *fakeBus) readBlock(ctx context.Context, device byte, registerType string, register, units int) ([]byte, error) {
+	if b.failing[device] {
+		return nil, errors.New("timed out")
+	}
+	data := make([]byte, 0, 2*units)
+	for i := 0; i < units; i++ {
+		data = binary.BigEndian.AppendUint16(data, b.registers[register+i])
+	}
+	return data, nil
+}
+
+func (b *fakeBus) unitBytes() int {
+	return 2
+}
+
+func (b *fakeBus) maxUnits() int {
+	return maxModbusRegisters
+}
+
+func (b *fakeBus) Close() error {
+	return nil
+}
+
+func TestPoll(t *testing.T) {
+	bits := math.Float32bits(21.5)
+	b := &fakeBus{
+		registers: map[int]uint16{
+			0: 0xFFFE,
+			1: uint16(bits >> 16),
+			2: uint16(bits),
+			3: uint16(bits),
+			4: uint16(bits >> 16),
+			5: 1234,
+		},
+		failing: map[byte]bool{2: true},
+	}
+	points := []*point{
+		{name: "raw", device: 1, registerType: registerHolding, register: 0, dataType: "int16", size: 2, scale: 1},
+		{name: "temp", device: 1, registerType: registerHolding, register: 1, dataType: "float32", size: 4, scale: 1},
+		{
+			name: "swapped", device: 1, registerType: registerHolding, register: 3, dataType: "float32", size: 4,
+			swapWords: true, scale: 1,
+		},
+		{name: "level", device: 1, registerType: registerHolding, register: 5, dataType: "uint16", size: 2, scale: 0.1, offset: -100},
+		{name: "offline", device: 2, registerType: registerHolding, register: 0, dataType: "uint16", size: 2, scale: 1},
+	}
+	for _, p := range points {
+		p.interval = 10 * time.Millisecond
+	}
+	s := &Sensor{
+		Named:  sensor.Named("gateway").AsNamed(),
+		logger: logging.NewTestLogger(t),
+		points: points,
+		buses:  []bus{b},
+	}
+	blocks := planBlocks(points, b.unitBytes(), b.maxUnits())
+	test.That(t, blocks, test.ShouldHaveLength, 2)
+	s.workers = utils.NewStoppableWorkers(func(ctx context.Context) {
+		s.poll(ctx, b, blocks)
+	})
+	defer func() {
+		test.That(t, s.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		readings, err := s.Readings(context.Background(), nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, readings, test.ShouldHaveLength, 4)
+		test.That(tb, readings["raw"], test.ShouldEqual, -2.0)
+		test.That(tb, readings["temp"], test.ShouldEqual, 21.5)
+		test.That(tb, readings["swapped"], test.ShouldEqual, 21.5)
+		test.That(tb, readings["level"], test.ShouldAlmostEqual, 23.4)
+	})
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		status, err := s.DoCommand(context.Background(), map[string]interface{}{"command": "status"})
+		test.That(tb, err, test.ShouldBeNil)
+		pointStatus := status["points"].(map[string]interface{})
+		test.That(tb, pointStatus["offline"], test.ShouldResemble, map[string]interface{}{"error": "timed out"})
+		test.That(tb, pointStatus["level"], test.ShouldContainKey, "last_read")
+	})
+}
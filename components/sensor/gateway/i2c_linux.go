@@ -0,0 +1,46 @@
+//go:build linux
+
+package gateway
+
+import (
+	"context"
+
+	"go.uber.org/multierr"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+)
+
+// i2cBus is an I2C bus, shared with any other resources using it.
+type i2cBus struct {
+	bus     buses.I2C
+	release func() error
+}
+
+func openI2CBus(name string) (bus, error) {
+	b, release, err := buses.AcquireI2cBus(name)
+	if err != nil {
+		return nil, err
+	}
+	return &i2cBus{bus: b, release: release}, nil
+}
+
+func (b *i2cBus) readBlock(ctx context.Context, device byte, _ string, register, units int) ([]byte, error) {
+	handle, err := b.bus.OpenHandle(device)
+	if err != nil {
+		return nil, err
+	}
+	data, err := handle.ReadBlockData(ctx, byte(register), uint8(units))
+	return data, multierr.Combine(err, handle.Close())
+}
+
+func (b *i2cBus) unitBytes() int {
+	return 1
+}
+
+func (b *i2cBus) maxUnits() int {
+	return maxI2CBytes
+}
+
+func (b *i2cBus) Close() error {
+	return b.release()
+}
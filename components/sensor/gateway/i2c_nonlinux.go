@@ -0,0 +1,9 @@
+//go:build !linux
+
+package gateway
+
+import "github.com/pkg/errors"
+
+func openI2CBus(name string) (bus, error) {
+	return nil, errors.New("i2c buses are only supported on linux")
+}
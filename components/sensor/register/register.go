@@ -6,6 +6,9 @@ import (
 	_ "go.viam.com/rdk/components/sensor/bme280"
 	_ "go.viam.com/rdk/components/sensor/ds18b20"
 	_ "go.viam.com/rdk/components/sensor/fake"
+	_ "go.viam.com/rdk/components/sensor/gateway"
 	_ "go.viam.com/rdk/components/sensor/sht3xd"
 	_ "go.viam.com/rdk/components/sensor/ultrasonic"
+	_ "go.viam.com/rdk/components/sensor/vibration"
+	_ "go.viam.com/rdk/components/sensor/vl53l1x"
 )
@@ -72,7 +72,7 @@ func newSensor(
 	conf *Config,
 	logger logging.Logger,
 ) (sensor.Sensor, error) {
-	i2cbus, err := buses.NewI2cBus(conf.I2cBus)
+	i2cbus, releaseBus, err := buses.AcquireI2cBus(conf.I2cBus)
 	if err != nil {
 		return nil, fmt.Errorf("sht3xd init: failed to find i2c bus %s", conf.I2cBus)
 	}
@@ -84,15 +84,16 @@ func newSensor(
 	}
 
 	s := &sht3xd{
-		Named:  name.AsNamed(),
-		logger: logger,
-		bus:    i2cbus,
-		addr:   byte(addr),
+		Named:      name.AsNamed(),
+		logger:     logger,
+		bus:        i2cbus,
+		releaseBus: releaseBus,
+		addr:       byte(addr),
 	}
 
 	err = s.reset(ctx)
 	if err != nil {
-		return nil, err
+		return nil, multierr.Combine(err, releaseBus())
 	}
 
 	return s, nil
@@ -102,11 +103,16 @@ func newSensor(
 type sht3xd struct {
 	resource.Named
 	resource.AlwaysRebuild
-	resource.TriviallyCloseable
 	logger logging.Logger
 
-	bus  buses.I2C
-	addr byte
+	bus        buses.I2C
+	releaseBus func() error
+	addr       byte
+}
+
+// Close releases the sensor's i2c bus.
+func (s *sht3xd) Close(ctx context.Context) error {
+	return s.releaseBus()
 }
 
 // Readings returns a list containing two items (current temperature and humidity).
@@ -1,4 +1,5 @@
-// Package ultrasonic implements an ultrasonic sensor based of the yahboom ultrasonic sensor
+// Package ultrasonic implements an ultrasonic sensor based of the yahboom ultrasonic sensor. The same driver is
+// registered as hc-sr04, since the HC-SR04 and its clones use the same trigger and echo protocol.
 package ultrasonic
 
 import (
@@ -17,7 +18,13 @@ import (
 	"go.viam.com/rdk/resource"
 )
 
-var model = resource.DefaultModelFamily.WithModel("ultrasonic")
+var (
+	model       = resource.DefaultModelFamily.WithModel("ultrasonic")
+	hcsr04Model = resource.DefaultModelFamily.WithModel("hc-sr04")
+)
+
+// hcsr04MaxDistanceM is the furthest the HC-SR04 is rated to measure. Echoes from further away are unreliable.
+const hcsr04MaxDistanceM = 4.
 
 // Config is used for converting config attributes.
 type Config struct {
@@ -25,6 +32,9 @@ type Config struct {
 	EchoInterrupt string `json:"echo_interrupt_pin"`
 	Board         string `json:"board"`
 	TimeoutMs     uint   `json:"timeout_ms,omitempty"`
+	// MaxDistanceM is the furthest distance that is trusted. Anything further is reported as an error. It
+	// defaults to 4m for the hc-sr04 model, and to no limit otherwise.
+	MaxDistanceM float64 `json:"max_distance_m,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -40,27 +50,35 @@ func (conf *Config) Validate(path string) ([]string, error) {
 	if len(conf.EchoInterrupt) == 0 {
 		return nil, resource.NewConfigValidationFieldRequiredError(path, "echo interrupt pin")
 	}
+	if conf.MaxDistanceM < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("max_distance_m cannot be negative"))
+	}
 	return deps, nil
 }
 
 func init() {
-	resource.RegisterComponent(
-		sensor.API,
-		model,
-		resource.Registration[sensor.Sensor, *Config]{
-			Constructor: func(
-				ctx context.Context,
-				deps resource.Dependencies,
-				conf resource.Config,
-				logger logging.Logger,
-			) (sensor.Sensor, error) {
-				newConf, err := resource.NativeConfig[*Config](conf)
-				if err != nil {
-					return nil, err
-				}
-				return NewSensor(ctx, deps, conf.ResourceName(), newConf, logger)
-			},
-		})
+	for _, m := range []resource.Model{model, hcsr04Model} {
+		resource.RegisterComponent(
+			sensor.API,
+			m,
+			resource.Registration[sensor.Sensor, *Config]{
+				Constructor: func(
+					ctx context.Context,
+					deps resource.Dependencies,
+					conf resource.Config,
+					logger logging.Logger,
+				) (sensor.Sensor, error) {
+					newConf, err := resource.NativeConfig[*Config](conf)
+					if err != nil {
+						return nil, err
+					}
+					if conf.Model == hcsr04Model && newConf.MaxDistanceM == 0 {
+						newConf.MaxDistanceM = hcsr04MaxDistanceM
+					}
+					return NewSensor(ctx, deps, conf.ResourceName(), newConf, logger)
+				},
+			})
+	}
 }
 
 // NewSensor creates and configures a new ultrasonic sensor.
@@ -185,6 +203,9 @@ func (s *Sensor) Readings(ctx context.Context, extra map[string]interface{}) (ma
 	// and the speed of sound (343 m/s)
 	secondsElapsed := float64(timeA-timeB) / math.Pow10(9)
 	distMeters := secondsElapsed * 343.0 / 2.0
+	if s.config.MaxDistanceM > 0 && distMeters > s.config.MaxDistanceM {
+		return nil, s.namedError(errors.Errorf("distance %.2fm is out of range", distMeters))
+	}
 	return map[string]interface{}{"distance": distMeters}, nil
 }
 
@@ -61,6 +61,10 @@ func TestValidate(t *testing.T) {
 	fakecfg.EchoInterrupt = echoInterrupt
 	_, err = fakecfg.Validate("path")
 	test.That(t, err, test.ShouldBeNil)
+
+	fakecfg.MaxDistanceM = -1
+	_, err = fakecfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
 }
 
 func TestNewSensor(t *testing.T) {
@@ -0,0 +1,160 @@
+package vibration
+
+import (
+	"math"
+	"math/cmplx"
+	"time"
+
+	"gonum.org/v1/gonum/dsp/fourier"
+)
+
+// minBaselineStdDev is the least spread, in log10 of band energy, that a baseline is considered to
+// have. Very steady machines would otherwise flag trivial changes in energy as anomalies.
+const minBaselineStdDev = 0.05
+
+// An Alert records a window whose band energies departed from the learned baseline.
+type Alert struct {
+	Time  time.Time
+	Band  string
+	Score float64
+}
+
+// A spectrum computes FFTs over overlapping windows of samples, tracks the energy in each band, and
+// scores each window against a baseline learned from the first windows it sees.
+type spectrum struct {
+	sampleRateHz    float64
+	bands           []BandConfig
+	baselineWindows int
+	threshold       float64
+
+	fft     *fourier.FFT
+	window  []float64
+	samples []float64
+	seq     []float64
+	coeffs  []complex128
+
+	// running mean and sum of squared deviations of the log band energies, by Welford's method
+	learned int
+	mean    []float64
+	m2      []float64
+
+	analyzed   int
+	energies   []float64
+	dominantHz float64
+	score      float64
+	anomalous  bool
+}
+
+func newSpectrum(sampleRateHz float64, windowSize int, bands []BandConfig, baselineWindows int, threshold float64) *spectrum {
+	window := make([]float64, windowSize)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(windowSize-1))
+	}
+	return &spectrum{
+		sampleRateHz:    sampleRateHz,
+		bands:           bands,
+		baselineWindows: baselineWindows,
+		threshold:       threshold,
+		fft:             fourier.NewFFT(windowSize),
+		window:          window,
+		samples:         make([]float64, 0, windowSize),
+		seq:             make([]float64, windowSize),
+		mean:            make([]float64, len(bands)),
+		m2:              make([]float64, len(bands)),
+		energies:        make([]float64, len(bands)),
+	}
+}
+
+// add appends a sample, analyzing a window whenever one fills. Windows overlap by half, so a window
+// is analyzed every half window of samples. add returns an alert when a window turns anomalous.
+func (s *spectrum) add(sample float64) *Alert {
+	s.samples = append(s.samples, sample)
+	if len(s.samples) < len(s.window) {
+		return nil
+	}
+	alert := s.analyze()
+	hop := len(s.window) / 2
+	s.samples = s.samples[:copy(s.samples, s.samples[hop:])]
+	return alert
+}
+
+// gap discards the partial window after samples were missed, since a window spanning the gap would
+// show energy that isn't in the signal.
+func (s *spectrum) gap() {
+	s.samples = s.samples[:0]
+}
+
+func (s *spectrum) resetBaseline() {
+	s.learned = 0
+	for i := range s.mean {
+		s.mean[i] = 0
+		s.m2[i] = 0
+	}
+	s.score = 0
+	s.anomalous = false
+}
+
+func (s *spectrum) baselineReady() bool {
+	return s.learned >= s.baselineWindows
+}
+
+func (s *spectrum) analyze() *Alert {
+	var mean float64
+	for _, v := range s.samples {
+		mean += v
+	}
+	mean /= float64(len(s.samples))
+	// removing the mean keeps a constant offset, such as gravity, from leaking into the low bins
+	for i, v := range s.samples {
+		s.seq[i] = (v - mean) * s.window[i]
+	}
+	s.coeffs = s.fft.Coefficients(s.coeffs, s.seq)
+
+	n := float64(len(s.window))
+	for i := range s.energies {
+		s.energies[i] = 0
+	}
+	var peak float64
+	for k := 1; k < len(s.coeffs); k++ {
+		power := math.Pow(cmplx.Abs(s.coeffs[k]), 2) / (n * n)
+		freq := s.fft.Freq(k) * s.sampleRateHz
+		if power > peak {
+			peak = power
+			s.dominantHz = freq
+		}
+		for i, band := range s.bands {
+			if freq >= band.MinHz && freq < band.MaxHz {
+				s.energies[i] += power
+			}
+		}
+	}
+	s.analyzed++
+
+	if !s.baselineReady() {
+		s.learned++
+		for i, energy := range s.energies {
+			level := math.Log10(energy + math.SmallestNonzeroFloat64)
+			delta := level - s.mean[i]
+			s.mean[i] += delta / float64(s.learned)
+			s.m2[i] += delta * (level - s.mean[i])
+		}
+		return nil
+	}
+
+	s.score = 0
+	worst := 0
+	for i, energy := range s.energies {
+		level := math.Log10(energy + math.SmallestNonzeroFloat64)
+		stdDev := math.Max(math.Sqrt(s.m2[i]/float64(s.learned)), minBaselineStdDev)
+		if z := math.Abs(level-s.mean[i]) / stdDev; z > s.score {
+			s.score = z
+			worst = i
+		}
+	}
+	wasAnomalous := s.anomalous
+	s.anomalous = s.score > s.threshold
+	if !s.anomalous || wasAnomalous {
+		return nil
+	}
+	return &Alert{Band: s.bands[worst].Name, Score: s.score}
+}
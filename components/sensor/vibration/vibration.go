@@ -0,0 +1,342 @@
+// Package vibration implements a sensor that watches the vibration spectrum of a machine, as
+// measured by an IMU or a high-rate analog input, and flags departures from its normal behavior.
+package vibration
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/board/pinwrappers"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("vibration")
+
+const (
+	defaultWindowSize      = 256
+	defaultBaselineWindows = 100
+	defaultThreshold       = 4
+	defaultNumBands        = 4
+	// maxAlerts bounds the alerts kept between calls to get_alerts.
+	maxAlerts = 100
+)
+
+// BandConfig is a range of frequencies whose energy is tracked.
+type BandConfig struct {
+	Name  string  `json:"name"`
+	MinHz float64 `json:"min_hz"`
+	MaxHz float64 `json:"max_hz"`
+}
+
+// Config is used for converting config attributes.
+type Config struct {
+	// MovementSensor measures vibration by the magnitude of its linear acceleration. Otherwise, Analog
+	// on Board is streamed.
+	MovementSensor string `json:"movement_sensor,omitempty"`
+	Board          string `json:"board,omitempty"`
+	Analog         string `json:"analog,omitempty"`
+
+	SampleRateHz float64 `json:"sample_rate_hz"`
+	// WindowSize is the number of samples in each FFT.
+	WindowSize int `json:"window_size,omitempty"`
+	// Bands default to equal divisions of the frequencies below half the sample rate.
+	Bands []BandConfig `json:"bands,omitempty"`
+	// BaselineWindows is the number of windows from which normal band energies are learned.
+	BaselineWindows int `json:"baseline_windows,omitempty"`
+	// Threshold is how many standard deviations from the baseline a band must stray to be anomalous.
+	Threshold float64 `json:"threshold,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	var deps []string
+	switch {
+	case conf.MovementSensor != "" && conf.Board != "":
+		return nil, resource.NewConfigValidationError(path,
+			errors.New("vibration must be measured by either a movement_sensor or a board analog, not both"))
+	case conf.MovementSensor != "":
+		deps = append(deps, conf.MovementSensor)
+	case conf.Board != "":
+		if conf.Analog == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(path, "analog")
+		}
+		deps = append(deps, conf.Board)
+	default:
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "movement_sensor")
+	}
+
+	if conf.SampleRateHz <= 0 {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "sample_rate_hz")
+	}
+	if conf.WindowSize != 0 && conf.WindowSize < 16 {
+		return nil, resource.NewConfigValidationError(path, errors.New("window_size must be at least 16"))
+	}
+	if conf.BaselineWindows < 0 || conf.Threshold < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("baseline_windows and threshold must not be negative"))
+	}
+	names := map[string]bool{}
+	for _, band := range conf.Bands {
+		if band.Name == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(path, "bands.name")
+		}
+		if names[band.Name] {
+			return nil, resource.NewConfigValidationError(path, errors.Errorf("band %q is configured twice", band.Name))
+		}
+		names[band.Name] = true
+		if band.MinHz < 0 || band.MaxHz <= band.MinHz || band.MaxHz > conf.SampleRateHz/2 {
+			return nil, resource.NewConfigValidationError(path,
+				errors.Errorf("band %q must cover a range of frequencies below half the sample rate", band.Name))
+		}
+	}
+	return deps, nil
+}
+
+func init() {
+	resource.RegisterComponent(
+		sensor.API,
+		model,
+		resource.Registration[sensor.Sensor, *Config]{
+			Constructor: newSensor,
+		})
+}
+
+// Sensor reports the energy in each band of a vibration spectrum, and how anomalous it is.
+type Sensor struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger logging.Logger
+
+	workers utils.StoppableWorkers
+	stream  *pinwrappers.AnalogStream
+
+	mu       sync.Mutex
+	spectrum *spectrum
+	alerts   []Alert
+	lastErr  error
+}
+
+func newSensor(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (sensor.Sensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+
+	windowSize := newConf.WindowSize
+	if windowSize == 0 {
+		windowSize = defaultWindowSize
+	}
+	bands := newConf.Bands
+	if len(bands) == 0 {
+		bands = defaultBands(newConf.SampleRateHz)
+	}
+	baselineWindows := newConf.BaselineWindows
+	if baselineWindows == 0 {
+		baselineWindows = defaultBaselineWindows
+	}
+	threshold := newConf.Threshold
+	if threshold == 0 {
+		threshold = defaultThreshold
+	}
+
+	s := &Sensor{
+		Named:    conf.ResourceName().AsNamed(),
+		logger:   logger,
+		spectrum: newSpectrum(newConf.SampleRateHz, windowSize, bands, baselineWindows, threshold),
+	}
+
+	if newConf.MovementSensor != "" {
+		ms, err := movementsensor.FromDependencies(deps, newConf.MovementSensor)
+		if err != nil {
+			return nil, err
+		}
+		period := time.Duration(float64(time.Second) / newConf.SampleRateHz)
+		s.workers = utils.NewStoppableWorkers(func(ctx context.Context) {
+			s.pollAcceleration(ctx, ms, period)
+		})
+		return s, nil
+	}
+
+	b, err := board.FromDependencies(deps, newConf.Board)
+	if err != nil {
+		return nil, err
+	}
+	analog, err := b.AnalogByName(newConf.Analog)
+	if err != nil {
+		return nil, err
+	}
+	// chunks of half a window are analyzed as soon as they arrive
+	s.stream, err = pinwrappers.StreamAnalog(analog, newConf.SampleRateHz, windowSize/2, 16, logger)
+	if err != nil {
+		return nil, err
+	}
+	chunkPeriod := time.Duration(float64(windowSize/2) / newConf.SampleRateHz * float64(time.Second))
+	s.workers = utils.NewStoppableWorkers(func(ctx context.Context) {
+		s.readStream(ctx, chunkPeriod)
+	})
+	return s, nil
+}
+
+func defaultBands(sampleRateHz float64) []BandConfig {
+	width := sampleRateHz / 2 / defaultNumBands
+	bands := make([]BandConfig, defaultNumBands)
+	for i := range bands {
+		bands[i] = BandConfig{
+			Name:  fmt.Sprintf("band_%d", i),
+			MinHz: float64(i) * width,
+			MaxHz: float64(i+1) * width,
+		}
+	}
+	// include the nyquist frequency itself in the top band
+	bands[len(bands)-1].MaxHz += width
+	return bands
+}
+
+func (s *Sensor) pollAcceleration(ctx context.Context, ms movementsensor.MovementSensor, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		acc, err := ms.LinearAcceleration(ctx, nil)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.fail(err)
+			continue
+		}
+		s.mu.Lock()
+		s.lastErr = nil
+		s.addLocked(acc.Norm())
+		s.mu.Unlock()
+	}
+}
+
+func (s *Sensor) readStream(ctx context.Context, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		chunks, status := s.stream.Read()
+		if status.LastError != nil {
+			s.fail(status.LastError)
+		}
+		s.mu.Lock()
+		if status.DroppedChunks > 0 || status.Overruns > 0 {
+			s.spectrum.gap()
+		}
+		for _, chunk := range chunks {
+			s.lastErr = nil
+			for _, sample := range chunk.Samples {
+				s.addLocked(float64(sample))
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *Sensor) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lastErr == nil || s.lastErr.Error() != err.Error() {
+		s.logger.Infow("error sampling vibration", "error", err)
+	}
+	s.lastErr = err
+	s.spectrum.gap()
+}
+
+// addLocked feeds a sample to the spectrum and records any alert. It must be called with mu held.
+func (s *Sensor) addLocked(sample float64) {
+	alert := s.spectrum.add(sample)
+	if alert == nil {
+		return
+	}
+	alert.Time = time.Now()
+	s.logger.Warnw("vibration anomaly", "band", alert.Band, "score", alert.Score)
+	if len(s.alerts) == maxAlerts {
+		s.alerts = s.alerts[1:]
+	}
+	s.alerts = append(s.alerts, *alert)
+}
+
+// Readings returns the energy in each band of the latest window, its dominant frequency, and how far
+// it strays from the baseline.
+func (s *Sensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.spectrum.analyzed == 0 {
+		if s.lastErr != nil {
+			return nil, s.lastErr
+		}
+		return nil, errors.New("not enough vibration samples collected yet")
+	}
+	readings := map[string]interface{}{
+		"dominant_frequency_hz": s.spectrum.dominantHz,
+		"anomaly_score":         s.spectrum.score,
+		"anomalous":             s.spectrum.anomalous,
+		"baseline_ready":        s.spectrum.baselineReady(),
+	}
+	for i, band := range s.spectrum.bands {
+		readings[band.Name+"_energy"] = s.spectrum.energies[i]
+	}
+	return readings, nil
+}
+
+// DoCommand supports get_alerts, which returns and forgets the alerts raised since it was last called,
+// and reset_baseline, which starts learning the baseline again, such as after maintenance.
+func (s *Sensor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"].(string)
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch name {
+	case "get_alerts":
+		alerts := make([]interface{}, 0, len(s.alerts))
+		for _, alert := range s.alerts {
+			alerts = append(alerts, map[string]interface{}{
+				"time":  alert.Time.Format(time.RFC3339Nano),
+				"band":  alert.Band,
+				"score": alert.Score,
+			})
+		}
+		s.alerts = nil
+		return map[string]interface{}{"alerts": alerts}, nil
+	case "reset_baseline":
+		s.spectrum.resetBaseline()
+		return map[string]interface{}{}, nil
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
+
+// Close stops sampling.
+func (s *Sensor) Close(ctx context.Context) error {
+	s.workers.Stop()
+	if s.stream != nil {
+		s.stream.Close()
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package vibration
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+)
+
+func TestValidate(t *testing.T) {
+	for _, conf := range []Config{
+		{SampleRateHz: 1000},
+		{MovementSensor: "imu", Board: "pi", Analog: "a", SampleRateHz: 1000},
+		{Board: "pi", SampleRateHz: 1000},
+		{MovementSensor: "imu"},
+		{MovementSensor: "imu", SampleRateHz: 1000, WindowSize: 8},
+		{MovementSensor: "imu", SampleRateHz: 1000, Bands: []BandConfig{{MinHz: 0, MaxHz: 100}}},
+		{MovementSensor: "imu", SampleRateHz: 1000, Bands: []BandConfig{{Name: "high", MinHz: 400, MaxHz: 600}}},
+		{MovementSensor: "imu", SampleRateHz: 1000, Bands: []BandConfig{
+			{Name: "low", MinHz: 0, MaxHz: 100},
+			{Name: "low", MinHz: 100, MaxHz: 200},
+		}},
+	} {
+		_, err := conf.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+
+	deps, err := (&Config{MovementSensor: "imu", SampleRateHz: 200}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"imu"})
+	deps, err = (&Config{Board: "pi", Analog: "current", SampleRateHz: 5000}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"pi"})
+}
+
+func TestSpectrum(t *testing.T) {
+	const sampleRate = 1000
+	bands := []BandConfig{
+		{Name: "low", MinHz: 0, MaxHz: 100},
+		{Name: "high", MinHz: 100, MaxHz: 500},
+	}
+	s := newSpectrum(sampleRate, 256, bands, 20, 4)
+	noise := rand.New(rand.NewSource(1))
+
+	var i int
+	feed := func(samples int, freqs ...float64) []*Alert {
+		var alerts []*Alert
+		for end := i + samples; i < end; i++ {
+			v := 0.05 * noise.NormFloat64()
+			for _, f := range freqs {
+				v += math.Sin(2 * math.Pi * f * float64(i) / sampleRate)
+			}
+			if alert := s.add(v); alert != nil {
+				alerts = append(alerts, alert)
+			}
+		}
+		return alerts
+	}
+
+	// a machine running normally hums at 30Hz
+	test.That(t, feed(128*21, 30), test.ShouldBeEmpty)
+	test.That(t, s.baselineReady(), test.ShouldBeTrue)
+	test.That(t, s.dominantHz, test.ShouldAlmostEqual, 30, sampleRate/256.)
+	test.That(t, s.energies[0], test.ShouldBeGreaterThan, 100*s.energies[1])
+	test.That(t, feed(128*10, 30), test.ShouldBeEmpty)
+	test.That(t, s.anomalous, test.ShouldBeFalse)
+
+	// a failing bearing adds a 240Hz whine, which alerts once while it lasts
+	alerts := feed(128*10, 30, 240)
+	test.That(t, alerts, test.ShouldHaveLength, 1)
+	test.That(t, alerts[0].Band, test.ShouldEqual, "high")
+	test.That(t, alerts[0].Score, test.ShouldBeGreaterThan, 4)
+	test.That(t, s.anomalous, test.ShouldBeTrue)
+
+	// after maintenance, the new behavior becomes the baseline
+	s.resetBaseline()
+	test.That(t, s.baselineReady(), test.ShouldBeFalse)
+	test.That(t, feed(128*30, 30, 240), test.ShouldBeEmpty)
+	test.That(t, s.baselineReady(), test.ShouldBeTrue)
+	test.That(t, s.anomalous, test.ShouldBeFalse)
+}
+
+func TestDoCommand(t *testing.T) {
+	s := &Sensor{
+		Named:    sensor.Named("vibration").AsNamed(),
+		logger:   logging.NewTestLogger(t),
+		spectrum: newSpectrum(1000, 64, defaultBands(1000), 1, 4),
+	}
+	_, err := s.Readings(context.Background(), nil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// learn a quiet baseline, then shake
+	for i := 0; i < 64; i++ {
+		s.addLocked(0.001 * math.Sin(float64(i)))
+	}
+	for i := 0; i < 64; i++ {
+		s.addLocked(math.Sin(2 * math.Pi * 300 * float64(i) / 1000))
+	}
+	readings, err := s.Readings(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["anomalous"], test.ShouldBeTrue)
+	test.That(t, readings, test.ShouldContainKey, "band_2_energy")
+
+	resp, err := s.DoCommand(context.Background(), map[string]interface{}{"command": "get_alerts"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["alerts"], test.ShouldHaveLength, 1)
+	resp, err = s.DoCommand(context.Background(), map[string]interface{}{"command": "get_alerts"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["alerts"], test.ShouldBeEmpty)
+
+	_, err = s.DoCommand(context.Background(), map[string]interface{}{"command": "reset_baseline"})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = s.DoCommand(context.Background(), map[string]interface{}{"command": "calibrate"})
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,420 @@
+//go:build linux
+
+// Package vl53l1x implements the ST VL53L1X time-of-flight distance sensor.
+// datasheet can be found at: https://www.st.com/resource/en/datasheet/vl53l1x.pdf
+// The sensor has no documented register map, so this follows ST's ultra lite driver (STSW-IMG009), which
+// initializes it by writing a block of default settings and then ranges continuously.
+package vl53l1x
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var model = resource.DefaultModelFamily.WithModel("vl53l1x")
+
+const (
+	defaultI2Caddr  = 0x29
+	expectedModelID = 0xEACC
+
+	// The registers of the vl53l1x, which are 16 bits wide.
+	regSoftReset             = 0x0000
+	regVHVTimeoutLoopBound   = 0x0008
+	regVHVStartAddr          = 0x000B
+	regDefaultConfigStart    = 0x002D
+	regGPIOHVMuxCtrl         = 0x0030
+	regGPIOTIOHVStatus       = 0x0031
+	regPhaseCalTimeout       = 0x004B
+	regRangeTimeoutAHi       = 0x005E
+	regRangeVCSELPeriodA     = 0x0060
+	regRangeTimeoutBHi       = 0x0061
+	regRangeVCSELPeriodB     = 0x0063
+	regRangeValidPhaseHigh   = 0x0069
+	regSDConfigWOIA          = 0x0078
+	regSDConfigInitialPhaseA = 0x007A
+	regSystemInterruptClear  = 0x0086
+	regSystemModeStart       = 0x0087
+	regResultRangeStatus     = 0x0089
+	regResultDistance        = 0x0096
+	regFirmwareSystemStatus  = 0x00E5
+	regModelID               = 0x010F
+
+	startRanging = 0x40
+	stopRanging  = 0x00
+	// rangeStatusValid is what the raw range status of a good measurement, 9, becomes in the driver's numbering.
+	rangeStatusValid = 0
+)
+
+// defaultConfig is written to the registers from 0x2D to 0x87 on startup. It puts the sensor in long distance mode
+// with an interrupt whenever a new measurement is ready.
+var defaultConfig = []byte{
+	0x00, 0x00, 0x00, 0x01, 0x02, 0x00, 0x02, 0x08, 0x00, 0x08, 0x10, 0x01, 0x01, 0x00, 0x00, 0x00,
+	0x00, 0xff, 0x00, 0x0F, 0x00, 0x00, 0x00, 0x00, 0x00, 0x20, 0x0b, 0x00, 0x00, 0x02, 0x0a, 0x21,
+	0x00, 0x00, 0x05, 0x00, 0x00, 0x00, 0x00, 0xc8, 0x00, 0x00, 0x38, 0xff, 0x01, 0x00, 0x08, 0x00,
+	0x00, 0x01, 0xcc, 0x0f, 0x01, 0xf1, 0x0d, 0x01, 0x68, 0x00, 0x80, 0x08, 0xb8, 0x00, 0x00, 0x00,
+	0x00, 0x0f, 0x89, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x0f, 0x0d, 0x0e, 0x0e, 0x00,
+	0x00, 0x02, 0xc7, 0xff, 0x9B, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00,
+}
+
+// rangeStatuses maps the raw range status to the numbering of ST's drivers, in which 0 is a good measurement,
+// 1 is a sigma failure, 2 is a signal failure, 4 is out of bounds, and 7 is a wraparound. 255 is anything else.
+var rangeStatuses = [24]byte{
+	255, 255, 255, 5, 2, 4, 1, 7, 3, 0, 255, 255, 9, 13, 255, 255, 255, 255, 10, 6, 255, 255, 11, 12,
+}
+
+// The settings of each distance mode, from the driver's SetDistanceMode.
+var distanceModes = map[string]struct {
+	phaseCalTimeout, vcselA, vcselB, validPhaseHigh byte
+	woiSD0, initialPhaseSD0                         uint16
+}{
+	"short": {0x14, 0x07, 0x05, 0x38, 0x0705, 0x0606},
+	"long":  {0x0A, 0x0F, 0x0D, 0xB8, 0x0F0D, 0x0E0E},
+}
+
+// The encoded range timeouts for each timing budget in each distance mode, from the driver's
+// SetTimingBudgetInMs.
+var timingBudgets = map[string]map[int][2]uint16{
+	"short": {
+		15:  {0x001D, 0x0027},
+		20:  {0x0051, 0x006E},
+		33:  {0x00D6, 0x006E},
+		50:  {0x01AE, 0x01E8},
+		100: {0x02E1, 0x0388},
+		200: {0x03E1, 0x0496},
+		500: {0x0591, 0x05C1},
+	},
+	"long": {
+		20:  {0x001E, 0x0022},
+		33:  {0x0060, 0x006E},
+		50:  {0x00AD, 0x00C6},
+		100: {0x01CC, 0x01EA},
+		200: {0x02D9, 0x02F8},
+		500: {0x048F, 0x04A4},
+	},
+}
+
+// Config is used for converting config attributes.
+type Config struct {
+	I2cBus  string `json:"i2c_bus"`
+	I2cAddr int    `json:"i2c_addr,omitempty"`
+	// DistanceMode is "short", which reaches about 1.3m but copes better with ambient light, or "long", the
+	// default, which reaches up to 4m.
+	DistanceMode string `json:"distance_mode,omitempty"`
+	// TimingBudgetMs is how long each measurement takes: 15 (short mode only), 20, 33, 50, 100 (the default),
+	// 200, or 500. Longer measurements are more accurate and reach further.
+	TimingBudgetMs int `json:"timing_budget_ms,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.I2cBus == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "i2c_bus")
+	}
+	mode := conf.distanceMode()
+	budgets, ok := timingBudgets[mode]
+	if !ok {
+		return nil, resource.NewConfigValidationError(path, fmt.Errorf("distance_mode must be short or long, not %q", mode))
+	}
+	if _, ok := budgets[conf.timingBudgetMs()]; !ok {
+		return nil, resource.NewConfigValidationError(path,
+			fmt.Errorf("timing_budget_ms %d is not supported in %s distance mode", conf.timingBudgetMs(), mode))
+	}
+	return nil, nil
+}
+
+func (conf *Config) distanceMode() string {
+	if conf.DistanceMode == "" {
+		return "long"
+	}
+	return conf.DistanceMode
+}
+
+func (conf *Config) timingBudgetMs() int {
+	if conf.TimingBudgetMs == 0 {
+		return 100
+	}
+	return conf.TimingBudgetMs
+}
+
+func init() {
+	resource.RegisterComponent(
+		sensor.API,
+		model,
+		resource.Registration[sensor.Sensor, *Config]{
+			Constructor: newSensor,
+		})
+}
+
+func newSensor(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (sensor.Sensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	bus, releaseBus, err := buses.AcquireI2cBus(newConf.I2cBus)
+	if err != nil {
+		return nil, fmt.Errorf("vl53l1x init: failed to find i2c bus %s", newConf.I2cBus)
+	}
+	s, err := makeSensor(ctx, conf.ResourceName(), newConf, logger, bus)
+	if err != nil {
+		return nil, multierr.Combine(err, releaseBus())
+	}
+	s.releaseBus = releaseBus
+	return s, nil
+}
+
+// makeSensor is separate from newSensor, so that tests can inject an I2C bus.
+func makeSensor(
+	ctx context.Context,
+	name resource.Name,
+	conf *Config,
+	logger logging.Logger,
+	bus buses.I2C,
+) (*vl53l1x, error) {
+	addr := conf.I2cAddr
+	if addr == 0 {
+		addr = defaultI2Caddr
+	}
+	s := &vl53l1x{
+		Named:  name.AsNamed(),
+		logger: logger,
+		bus:    bus,
+		addr:   byte(addr),
+		// a measurement should never take much longer than its budget
+		timeout: 2*time.Duration(conf.timingBudgetMs())*time.Millisecond + 100*time.Millisecond,
+	}
+	if err := s.init(ctx, conf); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// vl53l1x is an i2c time-of-flight sensor that reports the distance to the nearest object in its field of view.
+type vl53l1x struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger logging.Logger
+
+	mu         sync.Mutex
+	bus        buses.I2C
+	releaseBus func() error
+	addr       byte
+	timeout    time.Duration
+}
+
+func (s *vl53l1x) write(ctx context.Context, register uint16, data ...byte) error {
+	handle, err := s.bus.OpenHandle(s.addr)
+	if err != nil {
+		return err
+	}
+	tx := binary.BigEndian.AppendUint16(nil, register)
+	err = handle.Write(ctx, append(tx, data...))
+	return multierr.Combine(err, handle.Close())
+}
+
+func (s *vl53l1x) write16(ctx context.Context, register, data uint16) error {
+	return s.write(ctx, register, byte(data>>8), byte(data))
+}
+
+func (s *vl53l1x) read(ctx context.Context, register uint16, count int) ([]byte, error) {
+	handle, err := s.bus.OpenHandle(s.addr)
+	if err != nil {
+		return nil, err
+	}
+	if err := handle.Write(ctx, binary.BigEndian.AppendUint16(nil, register)); err != nil {
+		return nil, multierr.Combine(err, handle.Close())
+	}
+	data, err := handle.Read(ctx, count)
+	if err == nil && len(data) != count {
+		err = fmt.Errorf("expected %d bytes from vl53l1x, got %d", count, len(data))
+	}
+	return data, multierr.Combine(err, handle.Close())
+}
+
+func (s *vl53l1x) read16(ctx context.Context, register uint16) (uint16, error) {
+	data, err := s.read(ctx, register, 2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(data), nil
+}
+
+func (s *vl53l1x) init(ctx context.Context, conf *Config) error {
+	modelID, err := s.read16(ctx, regModelID)
+	if err != nil {
+		return errors.Wrap(err, "vl53l1x: failed to read model id")
+	}
+	if modelID != expectedModelID {
+		return fmt.Errorf("vl53l1x: unexpected model id %#x, expected %#x", modelID, expectedModelID)
+	}
+
+	if err := s.write(ctx, regSoftReset, 0x00); err != nil {
+		return err
+	}
+	time.Sleep(time.Millisecond)
+	if err := s.write(ctx, regSoftReset, 0x01); err != nil {
+		return err
+	}
+	if err := s.waitFor(ctx, "boot", func() (bool, error) {
+		status, err := s.read(ctx, regFirmwareSystemStatus, 1)
+		if err != nil {
+			return false, err
+		}
+		return status[0]&0x01 == 0x01, nil
+	}); err != nil {
+		return err
+	}
+
+	if err := s.write(ctx, regDefaultConfigStart, defaultConfig...); err != nil {
+		return errors.Wrap(err, "vl53l1x: failed to write default configuration")
+	}
+	// measure once to run the temperature calibration, and then stop
+	if err := s.write(ctx, regSystemModeStart, startRanging); err != nil {
+		return err
+	}
+	if err := s.waitFor(ctx, "first measurement", func() (bool, error) { return s.dataReady(ctx) }); err != nil {
+		return err
+	}
+	if err := s.write(ctx, regSystemInterruptClear, 0x01); err != nil {
+		return err
+	}
+	if err := s.write(ctx, regSystemModeStart, stopRanging); err != nil {
+		return err
+	}
+	if err := s.write(ctx, regVHVTimeoutLoopBound, 0x09); err != nil {
+		return err
+	}
+	if err := s.write(ctx, regVHVStartAddr, 0x00); err != nil {
+		return err
+	}
+
+	if err := s.setDistanceMode(ctx, conf.distanceMode(), conf.timingBudgetMs()); err != nil {
+		return err
+	}
+	return s.write(ctx, regSystemModeStart, startRanging)
+}
+
+func (s *vl53l1x) setDistanceMode(ctx context.Context, mode string, budgetMs int) error {
+	settings := distanceModes[mode]
+	timeouts := timingBudgets[mode][budgetMs]
+	for _, reg := range []struct {
+		register uint16
+		value    byte
+	}{
+		{regPhaseCalTimeout, settings.phaseCalTimeout},
+		{regRangeVCSELPeriodA, settings.vcselA},
+		{regRangeVCSELPeriodB, settings.vcselB},
+		{regRangeValidPhaseHigh, settings.validPhaseHigh},
+	} {
+		if err := s.write(ctx, reg.register, reg.value); err != nil {
+			return err
+		}
+	}
+	for _, reg := range []struct {
+		register, value uint16
+	}{
+		{regSDConfigWOIA, settings.woiSD0},
+		{regSDConfigInitialPhaseA, settings.initialPhaseSD0},
+		{regRangeTimeoutAHi, timeouts[0]},
+		{regRangeTimeoutBHi, timeouts[1]},
+	} {
+		if err := s.write16(ctx, reg.register, reg.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dataReady returns whether a new measurement is ready, which is signaled by the interrupt line.
+func (s *vl53l1x) dataReady(ctx context.Context) (bool, error) {
+	mux, err := s.read(ctx, regGPIOHVMuxCtrl, 1)
+	if err != nil {
+		return false, err
+	}
+	activeHigh := byte(1)
+	if mux[0]&0x10 != 0 {
+		activeHigh = 0
+	}
+	status, err := s.read(ctx, regGPIOTIOHVStatus, 1)
+	if err != nil {
+		return false, err
+	}
+	return status[0]&0x01 == activeHigh, nil
+}
+
+func (s *vl53l1x) waitFor(ctx context.Context, what string, ready func() (bool, error)) error {
+	deadline := time.Now().Add(s.timeout)
+	for {
+		ok, err := ready()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("vl53l1x: timed out waiting for %s", what)
+		}
+		if !goutils.SelectContextOrWait(ctx, time.Millisecond) {
+			return ctx.Err()
+		}
+	}
+}
+
+// Readings returns the distance in meters to the nearest object, waiting for the next measurement if there is not
+// one ready.
+func (s *vl53l1x) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.waitFor(ctx, "measurement", func() (bool, error) { return s.dataReady(ctx) }); err != nil {
+		return nil, err
+	}
+	rawStatus, err := s.read(ctx, regResultRangeStatus, 1)
+	if err != nil {
+		return nil, err
+	}
+	distanceMm, err := s.read16(ctx, regResultDistance)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.write(ctx, regSystemInterruptClear, 0x01); err != nil {
+		return nil, err
+	}
+
+	status := byte(255)
+	if raw := rawStatus[0] & 0x1F; int(raw) < len(rangeStatuses) {
+		status = rangeStatuses[raw]
+	}
+	if status != rangeStatusValid {
+		return nil, fmt.Errorf("vl53l1x: no valid measurement, range status %d", status)
+	}
+	return map[string]interface{}{"distance": float64(distanceMm) / 1000}, nil
+}
+
+// Close stops ranging, and releases the sensor's i2c bus.
+func (s *vl53l1x) Close(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.write(ctx, regSystemModeStart, stopRanging)
+	if s.releaseBus != nil {
+		err = multierr.Combine(err, s.releaseBus())
+	}
+	return err
+}
@@ -0,0 +1,2 @@
+// Package vl53l1x is only implemented for Linux systems.
+package vl53l1x
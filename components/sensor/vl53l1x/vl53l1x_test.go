@@ -0,0 +1,110 @@
+//go:build linux
+
+package vl53l1x
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// fakeChip is the register map of a vl53l1x which always has a measurement ready.
+type fakeChip struct {
+	mu        sync.Mutex
+	registers [0x200]byte
+	pointer   uint16
+}
+
+func newFakeChip() *fakeChip {
+	chip := &fakeChip{}
+	binary.BigEndian.PutUint16(chip.registers[regModelID:], expectedModelID)
+	chip.registers[regFirmwareSystemStatus] = 0x01
+	chip.registers[regGPIOTIOHVStatus] = 0x01
+	chip.registers[regResultRangeStatus] = 9
+	binary.BigEndian.PutUint16(chip.registers[regResultDistance:], 1234)
+	return chip
+}
+
+func (chip *fakeChip) bus() buses.I2C {
+	return &inject.I2C{OpenHandleFunc: func(addr byte) (buses.I2CHandle, error) {
+		return &inject.I2CHandle{
+			WriteFunc: func(ctx context.Context, tx []byte) error {
+				chip.mu.Lock()
+				defer chip.mu.Unlock()
+				chip.pointer = binary.BigEndian.Uint16(tx)
+				copy(chip.registers[chip.pointer:], tx[2:])
+				return nil
+			},
+			ReadFunc: func(ctx context.Context, count int) ([]byte, error) {
+				chip.mu.Lock()
+				defer chip.mu.Unlock()
+				return append([]byte{}, chip.registers[chip.pointer:int(chip.pointer)+count]...), nil
+			},
+			CloseFunc: func() error { return nil },
+		}, nil
+	}}
+}
+
+func TestValidate(t *testing.T) {
+	conf := &Config{}
+	_, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "i2c_bus"))
+
+	conf.I2cBus = "1"
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+
+	conf.TimingBudgetMs = 15
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	conf.DistanceMode = "short"
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+
+	conf.DistanceMode = "medium"
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestReadings(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	t.Run("wrong chip", func(t *testing.T) {
+		chip := newFakeChip()
+		chip.registers[regModelID] = 0
+		_, err := makeSensor(ctx, sensor.Named("tof"), &Config{I2cBus: "1"}, logger, chip.bus())
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	chip := newFakeChip()
+	s, err := makeSensor(ctx, sensor.Named("tof"), &Config{I2cBus: "1", DistanceMode: "short", TimingBudgetMs: 50}, logger, chip.bus())
+	test.That(t, err, test.ShouldBeNil)
+
+	test.That(t, chip.registers[regDefaultConfigStart+len(defaultConfig)-2], test.ShouldEqual, defaultConfig[len(defaultConfig)-2])
+	test.That(t, chip.registers[regRangeVCSELPeriodA], test.ShouldEqual, byte(0x07))
+	test.That(t, binary.BigEndian.Uint16(chip.registers[regRangeTimeoutAHi:]), test.ShouldEqual, uint16(0x01AE))
+	test.That(t, chip.registers[regSystemModeStart], test.ShouldEqual, byte(startRanging))
+
+	readings, err := s.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings, test.ShouldResemble, map[string]interface{}{"distance": 1.234})
+
+	// a signal failure, when there is nothing in range
+	chip.registers[regResultRangeStatus] = 4
+	_, err = s.Readings(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	test.That(t, s.Close(ctx), test.ShouldBeNil)
+	test.That(t, chip.registers[regSystemModeStart], test.ShouldEqual, byte(stopRanging))
+}
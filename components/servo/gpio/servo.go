@@ -23,6 +23,9 @@ const (
 	minWidthUs    uint    = 500  // absolute minimum PWM width
 	maxWidthUs    uint    = 2500 // absolute maximum PWM width
 	defaultFreq   uint    = 300
+
+	// moveStepInterval is how often a speed-limited move updates the commanded angle.
+	moveStepInterval = 20 * time.Millisecond
 )
 
 // We want to distinguish values that are 0 because the user set them to 0 from ones that are 0
@@ -32,9 +35,11 @@ const (
 type servoConfig struct {
 	Pin   string `json:"pin"`   // Pin is a GPIO pin with PWM capabilities.
 	Board string `json:"board"` // Board is a board that exposes GPIO pins.
-	// MinDeg is the minimum angle the servo can reach. Note this doesn't affect PWM calculation.
+	// MinDeg is the minimum angle the servo can reach. Unless TravelRangeDeg is set, this is also
+	// the angle that MinWidthUs corresponds to.
 	MinDeg *float64 `json:"min_angle_deg,omitempty"`
-	// MaxDeg is the maximum angle the servo can reach. Note this doesn't affect PWM calculation.
+	// MaxDeg is the maximum angle the servo can reach. Unless TravelRangeDeg is set, this is also
+	// the angle that MaxWidthUs corresponds to.
 	MaxDeg *float64 `json:"max_angle_deg,omitempty"`
 	// StartPos is the starting position of the servo in degrees.
 	StartPos *float64 `json:"starting_position_deg,omitempty"`
@@ -47,6 +52,14 @@ type servoConfig struct {
 	MinWidthUs *uint `json:"min_width_us,omitempty"`
 	// MaxWidthUs overrides the safe maximum PWM width in microseconds.
 	MaxWidthUs *uint `json:"max_width_us,omitempty"`
+	// TravelRangeDeg is the angle the servo actually sweeps between MinWidthUs (0 degrees) and
+	// MaxWidthUs. If omitted, MinDeg and MaxDeg are assumed to correspond to the two widths.
+	TravelRangeDeg *float64 `json:"travel_range_deg,omitempty"`
+	// MaxSpeedDegPerSec limits how fast the servo is commanded to move. If omitted, moves are
+	// sent to the servo all at once.
+	MaxSpeedDegPerSec *float64 `json:"max_speed_deg_per_sec,omitempty"`
+	// MaxAccelDegPerSec2 limits how quickly the commanded speed changes during a move.
+	MaxAccelDegPerSec2 *float64 `json:"max_acceleration_deg_per_sec_per_sec,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -84,6 +97,21 @@ func (config *servoConfig) Validate(path string) ([]string, error) {
 	if config.MaxWidthUs != nil && *config.MaxWidthUs > maxWidthUs {
 		return nil, resource.NewConfigValidationError(path, errors.Errorf("max_width_us cannot be higher than %d", maxWidthUs))
 	}
+	if config.TravelRangeDeg != nil {
+		if *config.TravelRangeDeg <= 0 {
+			return nil, resource.NewConfigValidationError(path, errors.New("travel_range_deg must be positive"))
+		}
+		if config.MaxDeg != nil && *config.MaxDeg > *config.TravelRangeDeg {
+			return nil, resource.NewConfigValidationError(path,
+				errors.Errorf("max_angle_deg cannot be higher than travel_range_deg (%.1f)", *config.TravelRangeDeg))
+		}
+	}
+	if config.MaxSpeedDegPerSec != nil && *config.MaxSpeedDegPerSec <= 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("max_speed_deg_per_sec must be positive"))
+	}
+	if config.MaxAccelDegPerSec2 != nil && *config.MaxAccelDegPerSec2 <= 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("max_acceleration_deg_per_sec_per_sec must be positive"))
+	}
 	return deps, nil
 }
 
@@ -110,6 +138,16 @@ type servoGPIO struct {
 	pwmRes    uint
 	currPct   float64
 	mu        sync.Mutex
+
+	// pwmMinDeg and pwmMaxDeg are the angles that minUs and maxUs move the servo to.
+	pwmMinDeg float64
+	pwmMaxDeg float64
+	// maxSpeed and maxAccel are in degrees per second (per second). A maxSpeed of 0 means moves
+	// are not speed-limited, and a maxAccel of 0 means the speed changes instantly.
+	maxSpeed float64
+	maxAccel float64
+	// currDeg is the angle the servo was last commanded to.
+	currDeg float64
 }
 
 func newGPIOServo(
@@ -161,10 +199,27 @@ func (s *servoGPIO) Reconfigure(ctx context.Context, deps resource.Dependencies,
 	}
 
 	s.maxDeg = defaultMaxDeg
+	if newConf.TravelRangeDeg != nil {
+		s.maxDeg = math.Min(s.maxDeg, *newConf.TravelRangeDeg)
+	}
 	if newConf.MaxDeg != nil {
 		s.maxDeg = *newConf.MaxDeg
 	}
 
+	s.pwmMinDeg, s.pwmMaxDeg = s.minDeg, s.maxDeg
+	if newConf.TravelRangeDeg != nil {
+		s.pwmMinDeg, s.pwmMaxDeg = 0, *newConf.TravelRangeDeg
+	}
+
+	s.maxSpeed = 0
+	if newConf.MaxSpeedDegPerSec != nil {
+		s.maxSpeed = *newConf.MaxSpeedDegPerSec
+	}
+	s.maxAccel = 0
+	if newConf.MaxAccelDegPerSec2 != nil {
+		s.maxAccel = *newConf.MaxAccelDegPerSec2
+	}
+
 	s.minUs = minWidthUs
 	if newConf.MinWidthUs != nil {
 		s.minUs = *newConf.MinWidthUs
@@ -209,8 +264,9 @@ func (s *servoGPIO) Reconfigure(ctx context.Context, deps resource.Dependencies,
 		return errors.Wrap(err, "error setting servo pin frequency")
 	}
 
-	// Try to detect the PWM resolution.
-	if err := s.Move(ctx, uint32(startPos), nil); err != nil {
+	// Try to detect the PWM resolution. We don't know where the servo is yet, so these moves
+	// can't be speed-limited.
+	if err := s.setAngle(ctx, startPos); err != nil {
 		return errors.Wrap(err, "couldn't move servo to start position")
 	}
 
@@ -218,7 +274,7 @@ func (s *servoGPIO) Reconfigure(ctx context.Context, deps resource.Dependencies,
 		return errors.Wrap(err, "failed to guess the pwm resolution")
 	}
 
-	if err := s.Move(ctx, uint32(startPos), nil); err != nil {
+	if err := s.setAngle(ctx, startPos); err != nil {
 		return errors.Wrap(err, "couldn't move servo back to start position")
 	}
 
@@ -338,7 +394,54 @@ func (s *servoGPIO) Move(ctx context.Context, ang uint32, extra map[string]inter
 		angle = s.maxDeg
 	}
 
-	pct := mapDegToDutyCylePct(s.minUs, s.maxUs, s.minDeg, s.maxDeg, angle, s.frequency)
+	if s.maxSpeed == 0 && s.maxAccel == 0 {
+		return s.setAngle(ctx, angle)
+	}
+	return s.interpolatedMove(ctx, angle)
+}
+
+// interpolatedMove walks the commanded angle towards target in small steps, so that the servo
+// moves no faster than maxSpeed and changes speed no faster than maxAccel. Hobby servos move as
+// fast as they can to whatever angle they're given, so this is the only way to slow them down.
+func (s *servoGPIO) interpolatedMove(ctx context.Context, target float64) error {
+	dt := moveStepInterval.Seconds()
+	speedLimit := s.maxSpeed
+	if speedLimit == 0 {
+		speedLimit = math.Inf(1)
+	}
+
+	speed := 0.0
+	for s.currDeg != target {
+		remaining := math.Abs(target - s.currDeg)
+		if s.maxAccel == 0 {
+			speed = speedLimit
+		} else if stoppingDist := speed * speed / (2 * s.maxAccel); stoppingDist >= remaining {
+			// Start slowing down, but never so much that we stall short of the target.
+			speed = math.Max(speed-s.maxAccel*dt, s.maxAccel*dt)
+		} else {
+			speed = math.Min(speed+s.maxAccel*dt, speedLimit)
+		}
+
+		next := target
+		if step := speed * dt; step < remaining {
+			next = s.currDeg + math.Copysign(step, target-s.currDeg)
+		}
+		if err := s.setAngle(ctx, next); err != nil {
+			return err
+		}
+		if next == target {
+			return nil
+		}
+		if !viamutils.SelectContextOrWait(ctx, moveStepInterval) {
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// setAngle immediately commands the servo to the given angle.
+func (s *servoGPIO) setAngle(ctx context.Context, angle float64) error {
+	pct := mapDegToDutyCylePct(s.minUs, s.maxUs, s.pwmMinDeg, s.pwmMaxDeg, angle, s.frequency)
 	if s.pwmRes != 0 {
 		realTick := math.Round(pct * float64(s.pwmRes))
 		pct = realTick / float64(s.pwmRes)
@@ -349,6 +452,7 @@ func (s *servoGPIO) Move(ctx context.Context, ang uint32, extra map[string]inter
 	}
 
 	s.currPct = pct
+	s.currDeg = angle
 	return nil
 }
 
@@ -369,7 +473,7 @@ func (s *servoGPIO) Position(ctx context.Context, extra map[string]interface{})
 		}
 	}
 
-	return uint32(mapDutyCylePctToDeg(s.minUs, s.maxUs, s.minDeg, s.maxDeg, pct, s.frequency)), nil
+	return uint32(mapDutyCylePctToDeg(s.minUs, s.maxUs, s.pwmMinDeg, s.pwmMaxDeg, pct, s.frequency)), nil
 }
 
 // Stop stops the servo. It is assumed the servo stops immediately.
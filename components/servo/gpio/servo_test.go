@@ -4,6 +4,7 @@ package gpio
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.viam.com/test"
@@ -82,6 +83,30 @@ func TestValidate(t *testing.T) {
 	_, err = cfg.Validate("test")
 	test.That(t, err, test.ShouldBeNil)
 
+	cfg.TravelRangeDeg = ptr(0.0)
+	_, err = cfg.Validate("test")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "travel_range_deg must be positive")
+	cfg.TravelRangeDeg = ptr(120.0)
+	cfg.MaxDeg = ptr(150.0)
+	_, err = cfg.Validate("test")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "max_angle_deg cannot be higher than travel_range_deg (120.0)")
+	cfg.MaxDeg = nil
+	cfg.TravelRangeDeg = nil
+
+	cfg.MaxSpeedDegPerSec = ptr(-10.0)
+	_, err = cfg.Validate("test")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "max_speed_deg_per_sec must be positive")
+	cfg.MaxSpeedDegPerSec = nil
+
+	cfg.MaxAccelDegPerSec2 = ptr(0.0)
+	_, err = cfg.Validate("test")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "max_acceleration_deg_per_sec_per_sec must be positive")
+	cfg.MaxAccelDegPerSec2 = nil
+
 	cfg.Board = ""
 	_, err = cfg.Validate("test")
 	test.That(t, err, test.ShouldNotBeNil)
@@ -205,3 +230,69 @@ func TestServoMove(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, pos, test.ShouldEqual, 63)
 }
+
+func TestServoCalibration(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	deps := setupDependencies(t)
+	ctx := context.Background()
+
+	// A 270 degree servo that we only want to use the first half of.
+	conf := servoConfig{
+		Pin:            "1",
+		Board:          "mock",
+		MaxDeg:         ptr(135.0),
+		TravelRangeDeg: ptr(270.0),
+	}
+	servo, err := newGPIOServo(ctx, deps, resource.Config{ConvertedAttributes: &conf}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	realServo := servo.(*servoGPIO)
+
+	err = realServo.Move(ctx, 135, nil)
+	test.That(t, err, test.ShouldBeNil)
+	pos, err := realServo.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pos, test.ShouldEqual, 135)
+	// halfway through the travel range is halfway between 500 and 2500 us at 50 Hz
+	test.That(t, realServo.currPct, test.ShouldAlmostEqual, 0.075, 1e-3)
+
+	// moves past max_angle_deg are clamped
+	err = realServo.Move(ctx, 200, nil)
+	test.That(t, err, test.ShouldBeNil)
+	pos, err = realServo.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pos, test.ShouldEqual, 135)
+}
+
+func TestServoSpeedLimit(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	deps := setupDependencies(t)
+	ctx := context.Background()
+
+	conf := servoConfig{
+		Pin:                "1",
+		Board:              "mock",
+		StartPos:           ptr(0.0),
+		MaxSpeedDegPerSec:  ptr(500.0),
+		MaxAccelDegPerSec2: ptr(5000.0),
+	}
+	servo, err := newGPIOServo(ctx, deps, resource.Config{ConvertedAttributes: &conf}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	realServo := servo.(*servoGPIO)
+
+	// 100 degrees at 500 deg/s takes at least 200ms, plus time to speed up and slow down.
+	start := time.Now()
+	err = realServo.Move(ctx, 100, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, 150*time.Millisecond)
+	pos, err := realServo.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pos, test.ShouldEqual, 100)
+
+	// canceling a move leaves the servo partway there
+	cancelCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	err = realServo.Move(cancelCtx, 0, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, realServo.currDeg, test.ShouldBeGreaterThan, 0)
+	test.That(t, realServo.currDeg, test.ShouldBeLessThan, 100)
+}
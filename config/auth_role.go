@@ -0,0 +1,31 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+)
+
+// AuthRole is what an authenticated entity is permitted to do with the robot.
+type AuthRole string
+
+// The roles which may be given to authenticated entities.
+const (
+	// AuthRoleViewer may only read state, such as sensor readings and camera images.
+	AuthRoleViewer AuthRole = "viewer"
+	// AuthRoleOperator may also command actuators, such as moving a base or an arm.
+	AuthRoleOperator AuthRole = "operator"
+	// AuthRoleOwner may also administer the robot, such as restarting its modules or shutting it down.
+	AuthRoleOwner AuthRole = "owner"
+)
+
+// Validate ensures the role is known.
+func (role AuthRole) Validate(path string) error {
+	switch role {
+	case AuthRoleViewer, AuthRoleOperator, AuthRoleOwner:
+		return nil
+	default:
+		return resource.NewConfigValidationError(path, errors.Errorf(
+			"unknown role %q; must be one of %q, %q, or %q", role, AuthRoleViewer, AuthRoleOperator, AuthRoleOwner))
+	}
+}
@@ -2,10 +2,13 @@
 package config
 
 import (
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -14,11 +17,14 @@ import (
 	"sync"
 	"time"
 
+	"github.com/pion/webrtc/v3"
 	"github.com/pkg/errors"
+	"go.uber.org/multierr"
 	"go.viam.com/utils/jwks"
 	"go.viam.com/utils/pexec"
 	"go.viam.com/utils/rpc"
 
+	"go.viam.com/rdk/grpc"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
@@ -38,6 +44,7 @@ type Config struct {
 	Auth            AuthConfig
 	Debug           bool
 	GlobalLogConfig []GlobalLogConfig
+	Logging         *LoggingConfig
 
 	ConfigFilePath string
 
@@ -65,23 +72,46 @@ type Config struct {
 
 	// EnableWebProfile turns pprof http server in localhost. Defaults to false.
 	EnableWebProfile bool
+
+	// EnableFaultInjection wraps motors and movement sensors so that failures can be
+	// simulated on them through DoCommand. It only affects resources built after it is set.
+	// Defaults to false.
+	EnableFaultInjection bool
+
+	// OperationTimeouts sets how long operations on each API may run before they are cancelled.
+	OperationTimeouts []OperationTimeoutConfig
+
+	// Maintenance, if set, holds back reconfiguration until a sensor reports that the robot is idle.
+	Maintenance *MaintenanceConfig
+
+	// Shutdown bounds how long resources may take to prepare for shutdown and to close.
+	Shutdown *ShutdownConfig
+
+	// SelfTest describes the self-tests run on resources, optionally on startup, as a pre-mission checklist.
+	SelfTest *SelfTestConfig
 }
 
 // NOTE: This data must be maintained with what is in Config.
 type configData struct {
-	Cloud               *Cloud                `json:"cloud,omitempty"`
-	Modules             []Module              `json:"modules,omitempty"`
-	Remotes             []Remote              `json:"remotes,omitempty"`
-	Components          []resource.Config     `json:"components,omitempty"`
-	Processes           []pexec.ProcessConfig `json:"processes,omitempty"`
-	Services            []resource.Config     `json:"services,omitempty"`
-	Packages            []PackageConfig       `json:"packages,omitempty"`
-	Network             NetworkConfig         `json:"network"`
-	Auth                AuthConfig            `json:"auth"`
-	Debug               bool                  `json:"debug,omitempty"`
-	DisablePartialStart bool                  `json:"disable_partial_start"`
-	EnableWebProfile    bool                  `json:"enable_web_profile"`
-	GlobalLogConfig     []GlobalLogConfig     `json:"global_log_configuration"`
+	Cloud                *Cloud                   `json:"cloud,omitempty"`
+	Modules              []Module                 `json:"modules,omitempty"`
+	Remotes              []Remote                 `json:"remotes,omitempty"`
+	Components           []resource.Config        `json:"components,omitempty"`
+	Processes            []pexec.ProcessConfig    `json:"processes,omitempty"`
+	Services             []resource.Config        `json:"services,omitempty"`
+	Packages             []PackageConfig          `json:"packages,omitempty"`
+	Network              NetworkConfig            `json:"network"`
+	Auth                 AuthConfig               `json:"auth"`
+	Debug                bool                     `json:"debug,omitempty"`
+	DisablePartialStart  bool                     `json:"disable_partial_start"`
+	EnableWebProfile     bool                     `json:"enable_web_profile"`
+	EnableFaultInjection bool                     `json:"enable_fault_injection,omitempty"`
+	OperationTimeouts    []OperationTimeoutConfig `json:"operation_timeouts,omitempty"`
+	Maintenance          *MaintenanceConfig       `json:"maintenance,omitempty"`
+	Shutdown             *ShutdownConfig          `json:"shutdown,omitempty"`
+	SelfTest             *SelfTestConfig          `json:"self_test,omitempty"`
+	GlobalLogConfig      []GlobalLogConfig        `json:"global_log_configuration"`
+	Logging              *LoggingConfig           `json:"logging,omitempty"`
 }
 
 // AppValidationStatus refers to the.
@@ -221,12 +251,57 @@ func (c *Config) Ensure(fromCloud bool, logger logging.Logger) error {
 		}
 	}
 
+	if err := NewDependencyGraph(c).Validate(); err != nil {
+		if c.DisablePartialStart {
+			return err
+		}
+		for _, depErr := range multierr.Errors(err) {
+			logger.Errorw("resource dependency error; affected resources will not be built until it is fixed", "error", depErr)
+		}
+	}
+
 	for idx, globalLogConfig := range c.GlobalLogConfig {
 		if err := globalLogConfig.Validate(fmt.Sprintf("global_log_configuration.%d", idx)); err != nil {
 			logger.Errorw("log configuration error", "err", err)
 		}
 	}
 
+	for idx, opTimeout := range c.OperationTimeouts {
+		if err := opTimeout.Validate(fmt.Sprintf("operation_timeouts.%d", idx)); err != nil {
+			logger.Errorw("operation timeout configuration error", "err", err)
+		}
+	}
+
+	if c.Maintenance != nil {
+		if err := c.Maintenance.Validate("maintenance"); err != nil {
+			if c.DisablePartialStart {
+				return err
+			}
+			logger.Errorw("maintenance config error; reconfiguring robot without waiting for maintenance sensor", "error", err)
+			c.Maintenance = nil
+		}
+	}
+
+	if c.Shutdown != nil {
+		if err := c.Shutdown.Validate("shutdown"); err != nil {
+			logger.Errorw("shutdown configuration error", "err", err)
+		}
+	}
+
+	if c.SelfTest != nil {
+		if err := c.SelfTest.Validate("self_test"); err != nil {
+			logger.Errorw("self-test configuration error", "err", err)
+		}
+	}
+
+	if c.Logging != nil {
+		for idx, output := range c.Logging.Outputs {
+			if err := output.Validate(fmt.Sprintf("logging.outputs.%d", idx)); err != nil {
+				logger.Errorw("log output configuration error", "err", err)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -269,7 +344,13 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 	c.Debug = conf.Debug
 	c.DisablePartialStart = conf.DisablePartialStart
 	c.EnableWebProfile = conf.EnableWebProfile
+	c.EnableFaultInjection = conf.EnableFaultInjection
+	c.OperationTimeouts = conf.OperationTimeouts
+	c.Maintenance = conf.Maintenance
+	c.Shutdown = conf.Shutdown
+	c.SelfTest = conf.SelfTest
 	c.GlobalLogConfig = conf.GlobalLogConfig
+	c.Logging = conf.Logging
 
 	return nil
 }
@@ -287,19 +368,25 @@ func (c Config) MarshalJSON() ([]byte, error) {
 	}
 
 	return json.Marshal(configData{
-		Cloud:               c.Cloud,
-		Modules:             c.Modules,
-		Remotes:             c.Remotes,
-		Components:          c.Components,
-		Processes:           c.Processes,
-		Services:            c.Services,
-		Packages:            c.Packages,
-		Network:             c.Network,
-		Auth:                c.Auth,
-		Debug:               c.Debug,
-		DisablePartialStart: c.DisablePartialStart,
-		EnableWebProfile:    c.EnableWebProfile,
-		GlobalLogConfig:     c.GlobalLogConfig,
+		Cloud:                c.Cloud,
+		Modules:              c.Modules,
+		Remotes:              c.Remotes,
+		Components:           c.Components,
+		Processes:            c.Processes,
+		Services:             c.Services,
+		Packages:             c.Packages,
+		Network:              c.Network,
+		Auth:                 c.Auth,
+		Debug:                c.Debug,
+		DisablePartialStart:  c.DisablePartialStart,
+		EnableWebProfile:     c.EnableWebProfile,
+		EnableFaultInjection: c.EnableFaultInjection,
+		OperationTimeouts:    c.OperationTimeouts,
+		Maintenance:          c.Maintenance,
+		Shutdown:             c.Shutdown,
+		SelfTest:             c.SelfTest,
+		GlobalLogConfig:      c.GlobalLogConfig,
+		Logging:              c.Logging,
 	})
 }
 
@@ -334,6 +421,8 @@ type Remote struct {
 	ConnectionCheckInterval   time.Duration
 	ReconnectInterval         time.Duration
 	AssociatedResourceConfigs []resource.AssociatedResourceConfig
+	Summarization             *RemoteSummarization
+	TLS                       *RemoteTLSConfig
 
 	// Secret is a helper for a robot location secret.
 	Secret string
@@ -353,6 +442,8 @@ type remoteData struct {
 	ConnectionCheckInterval   string                              `json:"connection_check_interval,omitempty"`
 	ReconnectInterval         string                              `json:"reconnect_interval,omitempty"`
 	AssociatedResourceConfigs []resource.AssociatedResourceConfig `json:"service_configs"`
+	Summarization             *RemoteSummarization                `json:"summarization,omitempty"`
+	TLS                       *RemoteTLSConfig                    `json:"tls,omitempty"`
 
 	// Secret is a helper for a robot location secret.
 	Secret string `json:"secret"`
@@ -382,6 +473,8 @@ func (conf *Remote) UnmarshalJSON(data []byte) error {
 		ManagedBy:                 temp.ManagedBy,
 		Insecure:                  temp.Insecure,
 		AssociatedResourceConfigs: temp.AssociatedResourceConfigs,
+		Summarization:             temp.Summarization,
+		TLS:                       temp.TLS,
 		Secret:                    temp.Secret,
 	}
 	if temp.ConnectionCheckInterval != "" {
@@ -411,6 +504,8 @@ func (conf Remote) MarshalJSON() ([]byte, error) {
 		ManagedBy:                 conf.ManagedBy,
 		Insecure:                  conf.Insecure,
 		AssociatedResourceConfigs: conf.AssociatedResourceConfigs,
+		Summarization:             conf.Summarization,
+		TLS:                       conf.TLS,
 		Secret:                    conf.Secret,
 	}
 	if conf.ConnectionCheckInterval != 0 {
@@ -422,6 +517,55 @@ func (conf Remote) MarshalJSON() ([]byte, error) {
 	return json.Marshal(temp)
 }
 
+// RemoteSummarization configures how a main part thins out the data it pulls from a remote, so
+// that a machine aggregating many remotes over slow links stays responsive. Requests made faster
+// than the configured rates are answered from the most recent response instead of going over the
+// network again.
+type RemoteSummarization struct {
+	// ReadingsInterval is the minimum time between Readings calls forwarded to any sensor-like
+	// resource on the remote (e.g., "1s").
+	ReadingsInterval string `json:"readings_interval,omitempty"`
+	// ImagesInterval is the minimum time between Images calls forwarded to any camera on the
+	// remote. Video streams are not affected.
+	ImagesInterval string `json:"images_interval,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *RemoteSummarization) Validate(path string) error {
+	if _, err := parseOptionalDuration(conf.ReadingsInterval); err != nil {
+		return resource.NewConfigValidationError(path, errors.Wrap(err, "invalid readings_interval"))
+	}
+	if _, err := parseOptionalDuration(conf.ImagesInterval); err != nil {
+		return resource.NewConfigValidationError(path, errors.Wrap(err, "invalid images_interval"))
+	}
+	return nil
+}
+
+// Intervals returns the parsed readings and images intervals. A zero duration means that kind of
+// request is not summarized. Validate should be called before this.
+func (conf *RemoteSummarization) Intervals() (readings, images time.Duration) {
+	if conf == nil {
+		return 0, 0
+	}
+	readings, _ = parseOptionalDuration(conf.ReadingsInterval)
+	images, _ = parseOptionalDuration(conf.ImagesInterval)
+	return readings, images
+}
+
+func parseOptionalDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	dur, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if dur < 0 {
+		return 0, errors.New("duration cannot be negative")
+	}
+	return dur, nil
+}
+
 // RemoteAuth specifies how to authenticate against a remote. If no credentials are
 // specified, authentication does not happen. If an entity is specified, the
 // authentication request will specify it.
@@ -475,6 +619,19 @@ func (conf *Remote) validate(path string) error {
 			return resource.NewConfigValidationFieldRequiredError(path, "frame.parent")
 		}
 	}
+	if conf.Summarization != nil {
+		if err := conf.Summarization.Validate(fmt.Sprintf("%s.%s", path, "summarization")); err != nil {
+			return err
+		}
+	}
+	if conf.TLS != nil {
+		if conf.Insecure {
+			return resource.NewConfigValidationError(path, errors.New("may only set one of insecure or tls"))
+		}
+		if err := conf.TLS.Validate(fmt.Sprintf("%s.%s", path, "tls")); err != nil {
+			return err
+		}
+	}
 
 	if conf.Secret != "" {
 		conf.Auth = RemoteAuth{
@@ -508,6 +665,7 @@ type Cloud struct {
 	LogPath           string
 	AppAddress        string
 	RefreshInterval   time.Duration
+	SecureElement     *SecureElementConfig
 
 	// cached by us and fetched from a non-config endpoint.
 	TLSCertificate string
@@ -520,6 +678,8 @@ type cloudData struct {
 	ID         string `json:"id"`
 	Secret     string `json:"secret,omitempty"`
 	AppAddress string `json:"app_address,omitempty"`
+	// set when the secret and TLS private key are sealed to a secure element rather than kept in the clear.
+	SecureElement *SecureElementConfig `json:"secure_element,omitempty"`
 
 	LocationSecret    string           `json:"location_secret"`
 	LocationSecrets   []LocationSecret `json:"location_secrets"`
@@ -562,6 +722,7 @@ func (config *Cloud) UnmarshalJSON(data []byte) error {
 		Path:              temp.Path,
 		LogPath:           temp.LogPath,
 		AppAddress:        temp.AppAddress,
+		SecureElement:     temp.SecureElement,
 		TLSCertificate:    temp.TLSCertificate,
 		TLSPrivateKey:     temp.TLSPrivateKey,
 	}
@@ -593,6 +754,7 @@ func (config Cloud) MarshalJSON() ([]byte, error) {
 		Path:              config.Path,
 		LogPath:           config.LogPath,
 		AppAddress:        config.AppAddress,
+		SecureElement:     config.SecureElement,
 		TLSCertificate:    config.TLSCertificate,
 		TLSPrivateKey:     config.TLSPrivateKey,
 	}
@@ -617,6 +779,11 @@ func (config *Cloud) Validate(path string, fromCloud bool) error {
 	} else if config.Secret == "" {
 		return resource.NewConfigValidationFieldRequiredError(path, "secret")
 	}
+	if config.SecureElement != nil {
+		if err := config.SecureElement.Validate(path + ".secure_element"); err != nil {
+			return err
+		}
+	}
 	if config.RefreshInterval == 0 {
 		config.RefreshInterval = 10 * time.Second
 	}
@@ -670,12 +837,41 @@ type NetworkConfigData struct {
 	// This is mutually exclusive with TLSCertPEM and TLSKeyPEM.
 	TLSKeyFile string `json:"tls_key_file,omitempty"`
 
+	// TLSClientCAFile is a PEM file of certificate authorities. When set, clients
+	// must present a certificate issued by one of them to connect to the hosted
+	// HTTP server.
+	TLSClientCAFile string `json:"tls_client_ca_file,omitempty"`
+
 	// TLSConfig is used to enable secure communications on the hosted HTTP server.
 	// This is mutually exclusive with TLSCertFile and TLSKeyFile.
 	TLSConfig *tls.Config `json:"-"`
 
+	// FleetCA is used to issue this machine certificates to serve and to present to
+	// remotes, when they are not given as files. Unless TLSClientCAFile is set, clients
+	// must then present a certificate issued by the fleet CA to connect.
+	FleetCA *FleetCAConfig `json:"fleet_ca,omitempty"`
+
 	// Sessions configures session management.
 	Sessions SessionsConfig `json:"sessions"`
+
+	// DisableRESTGateway stops the component and service APIs from being served as
+	// HTTP/JSON under /api, leaving only gRPC.
+	DisableRESTGateway bool `json:"disable_rest_gateway,omitempty"`
+
+	// WebRTC configures how WebRTC connections to this machine, and from it to its
+	// remotes, find a path between peers.
+	WebRTC *WebRTCConfig `json:"webrtc,omitempty"`
+
+	// StreamBandwidthBudgetKbps limits the total bitrate, in kilobits per second, of camera
+	// video streamed to all viewers. When more streams or viewers are active than fit, the
+	// frame rates of streams are lowered in proportion to their stream_priority camera
+	// attribute. The default is no limit.
+	StreamBandwidthBudgetKbps int `json:"stream_bandwidth_budget_kbps,omitempty"`
+
+	// MaxMessageSizeMB is the largest message, in MiB, that API calls may send or receive. It is
+	// shared by every connection the process makes, so changing it only takes effect on restart.
+	// The default is 32MiB.
+	MaxMessageSizeMB int `json:"max_message_size_mb,omitempty"`
 }
 
 // MarshalJSON marshals out this config.
@@ -691,6 +887,9 @@ func (nc NetworkConfig) MarshalJSON() ([]byte, error) {
 // the server will bind to all interfaces.
 const DefaultBindAddress = "localhost:8080"
 
+// maxMessageSizeMB is the largest max_message_size_mb allowed, since gRPC cannot frame messages of 2GiB or more.
+const maxMessageSizeMB = 1024
+
 // Validate ensures all parts of the config are valid. Adds default BindAddress and HeartbeatWindow if not set.
 func (nc *NetworkConfig) Validate(path string) error {
 	if nc.BindAddress != "" && nc.Listener != nil {
@@ -706,20 +905,116 @@ func (nc *NetworkConfig) Validate(path string) error {
 	if (nc.TLSCertFile == "") != (nc.TLSKeyFile == "") {
 		return resource.NewConfigValidationError(path, errors.New("must provide both tls_cert_file and tls_key_file"))
 	}
+	if nc.MaxMessageSizeMB < 0 || nc.MaxMessageSizeMB > maxMessageSizeMB {
+		return resource.NewConfigValidationError(path,
+			errors.Errorf("max_message_size_mb must be between 1 and %d", maxMessageSizeMB))
+	}
+	if nc.FleetCA != nil {
+		if err := nc.FleetCA.Validate(path + ".fleet_ca"); err != nil {
+			return err
+		}
+	}
+	if nc.StreamBandwidthBudgetKbps < 0 {
+		return resource.NewConfigValidationError(path, errors.New("stream_bandwidth_budget_kbps must not be negative"))
+	}
+	if nc.WebRTC != nil {
+		if err := nc.WebRTC.Validate(path + ".webrtc"); err != nil {
+			return err
+		}
+	}
 
 	return nc.Sessions.Validate(path + ".sessions")
 }
 
+// WebRTCConfig configures the ICE servers used to find a path between WebRTC peers. Machines behind NATs which
+// do not allow direct connections need a TURN server to relay through.
+type WebRTCConfig struct {
+	// ICEServers are the STUN and TURN servers to gather candidates from, in place of the default STUN server.
+	ICEServers []ICEServerConfig `json:"ice_servers,omitempty"`
+
+	// ForceRelay only uses candidates relayed through TURN servers, for networks which block
+	// everything else or to test that relaying works.
+	ForceRelay bool `json:"force_relay,omitempty"`
+}
+
+// ICEServerConfig is a STUN or TURN server.
+type ICEServerConfig struct {
+	// URLs are the addresses of the server, such as stun:stun.example.com:3478 or
+	// turns:turn.example.com:5349?transport=tcp.
+	URLs       []string `json:"urls"`
+	Username   string   `json:"username,omitempty"`
+	Credential string   `json:"credential,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (c *WebRTCConfig) Validate(path string) error {
+	hasTURN := false
+	for i, server := range c.ICEServers {
+		serverPath := fmt.Sprintf("%s.ice_servers.%d", path, i)
+		if len(server.URLs) == 0 {
+			return resource.NewConfigValidationFieldRequiredError(serverPath, "urls")
+		}
+		for _, serverURL := range server.URLs {
+			scheme, _, _ := strings.Cut(serverURL, ":")
+			switch scheme {
+			case "stun", "stuns":
+			case "turn", "turns":
+				hasTURN = true
+				if server.Username == "" || server.Credential == "" {
+					return resource.NewConfigValidationError(serverPath,
+						errors.Errorf("TURN server %q requires a username and credential", serverURL))
+				}
+			default:
+				return resource.NewConfigValidationError(serverPath,
+					errors.Errorf("ICE server URL %q must start with stun:, stuns:, turn: or turns:", serverURL))
+			}
+		}
+	}
+	if c.ForceRelay && !hasTURN {
+		return resource.NewConfigValidationError(path, errors.New("force_relay requires a TURN server in ice_servers"))
+	}
+	return nil
+}
+
+// Configuration returns the WebRTC configuration to use for peers, which is the default one if c is nil.
+func (c *WebRTCConfig) Configuration() webrtc.Configuration {
+	if c == nil {
+		return grpc.DefaultWebRTCConfiguration
+	}
+	config := grpc.DefaultWebRTCConfiguration
+	if len(c.ICEServers) != 0 {
+		config.ICEServers = make([]webrtc.ICEServer, 0, len(c.ICEServers))
+		for _, server := range c.ICEServers {
+			config.ICEServers = append(config.ICEServers, webrtc.ICEServer{
+				URLs:       server.URLs,
+				Username:   server.Username,
+				Credential: server.Credential,
+			})
+		}
+	}
+	if c.ForceRelay {
+		config.ICETransportPolicy = webrtc.ICETransportPolicyRelay
+	}
+	return config
+}
+
 // SessionsConfig configures various parameters used in session management.
 type SessionsConfig struct {
 	// HeartbeatWindow is the window within which clients must send at least one
 	// heartbeat in order to keep a session alive.
 	HeartbeatWindow time.Duration
+
+	// StopGracePeriod is how long resources commanded by a session are left
+	// running after it expires before being stopped, so that a client whose
+	// connection briefly dropped can resume in a new session without the robot
+	// halting.
+	StopGracePeriod time.Duration
 }
 
 // Note: keep this in sync with SessionsConfig.
 type sessionsConfigData struct {
 	HeartbeatWindow string `json:"heartbeat_window,omitempty"`
+	StopGracePeriod string `json:"stop_grace_period,omitempty"`
 }
 
 // UnmarshalJSON unmarshals JSON data into this config.
@@ -735,6 +1030,13 @@ func (sc *SessionsConfig) UnmarshalJSON(data []byte) error {
 		}
 		sc.HeartbeatWindow = dur
 	}
+	if temp.StopGracePeriod != "" {
+		dur, err := time.ParseDuration(temp.StopGracePeriod)
+		if err != nil {
+			return err
+		}
+		sc.StopGracePeriod = dur
+	}
 	return nil
 }
 
@@ -744,6 +1046,9 @@ func (sc SessionsConfig) MarshalJSON() ([]byte, error) {
 	if sc.HeartbeatWindow != 0 {
 		temp.HeartbeatWindow = sc.HeartbeatWindow.String()
 	}
+	if sc.StopGracePeriod != 0 {
+		temp.StopGracePeriod = sc.StopGracePeriod.String()
+	}
 	return json.Marshal(temp)
 }
 
@@ -751,6 +1056,10 @@ func (sc SessionsConfig) MarshalJSON() ([]byte, error) {
 // It can be set with network.sessions.heartbeat_window.
 const DefaultSessionHeartbeatWindow = 2 * time.Second
 
+// maxSessionStopGracePeriod bounds how long actuators may keep moving after
+// the client commanding them is gone.
+const maxSessionStopGracePeriod = 10 * time.Second
+
 // Validate ensures all parts of the config are valid. Sets default HeartbeatWindow if not set.
 func (sc *SessionsConfig) Validate(path string) error {
 	if sc.HeartbeatWindow == 0 {
@@ -759,6 +1068,10 @@ func (sc *SessionsConfig) Validate(path string) error {
 		sc.HeartbeatWindow > time.Minute {
 		return resource.NewConfigValidationError(path, errors.New("heartbeat_window must be between [30ms, 1m]"))
 	}
+	if sc.StopGracePeriod < 0 || sc.StopGracePeriod > maxSessionStopGracePeriod {
+		return resource.NewConfigValidationError(path,
+			errors.Errorf("stop_grace_period must be between [0, %v]", maxSessionStopGracePeriod))
+	}
 
 	return nil
 }
@@ -768,6 +1081,12 @@ type AuthConfig struct {
 	Handlers           []AuthHandlerConfig `json:"handlers,omitempty"`
 	TLSAuthEntities    []string            `json:"tls_auth_entities,omitempty"`
 	ExternalAuthConfig *ExternalAuthConfig `json:"external_auth_config,omitempty"`
+
+	// Roles limits what authenticated entities, such as API key IDs or the subjects of external auth tokens, may
+	// do. Entities without a role may do anything, unless they are API keys and DefaultRole is set.
+	Roles map[string]AuthRole `json:"roles,omitempty"`
+	// DefaultRole is the role of API keys which are not given one in Roles.
+	DefaultRole AuthRole `json:"default_role,omitempty"`
 }
 
 // ExternalAuthConfig contains information needed to verify externally authenticated tokens.
@@ -869,6 +1188,8 @@ type AuthHandlerConfig struct {
 //					}
 //				}
 //			],
+//		"roles": {"API_KEY_ID_2": "viewer"},
+//		"default_role": "operator",
 //		"external_auth_config": {}
 //	}
 func (config *AuthConfig) Validate(path string) error {
@@ -888,6 +1209,16 @@ func (config *AuthConfig) Validate(path string) error {
 			return err
 		}
 	}
+	for entity, role := range config.Roles {
+		if err := role.Validate(fmt.Sprintf("%s.roles.%s", path, entity)); err != nil {
+			return err
+		}
+	}
+	if config.DefaultRole != "" {
+		if err := config.DefaultRole.Validate(fmt.Sprintf("%s.default_role", path)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -1002,10 +1333,13 @@ const (
 	PackageTypeModule PackageType = "module"
 	// PackageTypeSlamMap represents a slam internal state.
 	PackageTypeSlamMap PackageType = "slam_map"
+	// PackageTypeKinematics represents kinematics files, such as URDFs. Since the cloud does not host them, they
+	// must be downloaded from a URL.
+	PackageTypeKinematics PackageType = "kinematics"
 )
 
 // SupportedPackageTypes is a list of all of the valid package types.
-var SupportedPackageTypes = []PackageType{PackageTypeMlModel, PackageTypeModule, PackageTypeSlamMap}
+var SupportedPackageTypes = []PackageType{PackageTypeMlModel, PackageTypeModule, PackageTypeSlamMap, PackageTypeKinematics}
 
 // A PackageConfig describes the configuration of a Package.
 type PackageConfig struct {
@@ -1017,6 +1351,11 @@ type PackageConfig struct {
 	Version string `json:"version,omitempty"`
 	// Types of the Package.
 	Type PackageType `json:"type"`
+	// URL, if set, is where the package is downloaded from instead of a remote PackageService. URLs ending in
+	// .tar.gz or .tgz are unpacked, and any other file is kept as is. http, https, and file URLs are supported.
+	URL string `json:"url,omitempty"`
+	// SHA256 is the hex encoded SHA-256 checksum of the file at URL. It is required for packages with a URL.
+	SHA256 string `json:"sha256,omitempty"`
 
 	Status *AppValidationStatus `json:"status,omitempty"`
 
@@ -1059,9 +1398,34 @@ func (p *PackageConfig) validate(path string) error {
 		return resource.NewConfigValidationError(path, err)
 	}
 
+	if p.URL == "" {
+		if p.Type == PackageTypeKinematics {
+			return resource.NewConfigValidationError(path, errors.Errorf("%s packages must have a url", p.Type))
+		}
+		return nil
+	}
+	parsed, err := url.Parse(p.URL)
+	if err != nil {
+		return resource.NewConfigValidationError(path, errors.Wrap(err, "invalid package url"))
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" && parsed.Scheme != "file" {
+		return resource.NewConfigValidationError(path,
+			errors.Errorf("unsupported package url scheme %q. Must be one of: http, https, file", parsed.Scheme))
+	}
+	if checksum, err := hex.DecodeString(p.SHA256); err != nil || len(checksum) != sha256.Size {
+		return resource.NewConfigValidationError(path, errors.New("packages with a url must have a hex encoded sha256 checksum"))
+	}
+
 	return nil
 }
 
+// LocalPackagesDir returns the directory that packages which are not synced from the cloud, such as local
+// tarball modules and packages with a URL, are kept in. It is separate from packagesDir so that the managers
+// of each do not delete each other's packages in Cleanup.
+func LocalPackagesDir(packagesDir string) string {
+	return filepath.Clean(packagesDir) + "-local"
+}
+
 // Equals checks if the two configs are deeply equal to each other.
 func (p PackageConfig) Equals(other PackageConfig) bool {
 	p.alreadyValidated = false
@@ -15,6 +15,7 @@ import (
 
 	"github.com/golang/geo/r3"
 	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/pion/webrtc/v3"
 	"github.com/pkg/errors"
 	"go.viam.com/test"
 	"go.viam.com/utils/jwks"
@@ -29,6 +30,7 @@ import (
 	"go.viam.com/rdk/components/encoder/incremental"
 	fakemotor "go.viam.com/rdk/components/motor/fake"
 	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/grpc"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
@@ -368,6 +370,47 @@ func TestConfigEnsure(t *testing.T) {
 	invalidNetwork.Network.Sessions.HeartbeatWindow = 30 * time.Millisecond
 	test.That(t, invalidNetwork.Ensure(false, logger), test.ShouldBeNil)
 
+	invalidNetwork.Network.Sessions.StopGracePeriod = time.Minute
+	err = invalidNetwork.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `stop_grace_period`)
+
+	invalidNetwork.Network.Sessions.StopGracePeriod = time.Second
+	test.That(t, invalidNetwork.Ensure(false, logger), test.ShouldBeNil)
+
+	invalidNetwork.Network.MaxMessageSizeMB = -1
+	err = invalidNetwork.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `max_message_size_mb`)
+
+	invalidNetwork.Network.MaxMessageSizeMB = 64
+	test.That(t, invalidNetwork.Ensure(false, logger), test.ShouldBeNil)
+
+	invalidNetwork.Network.WebRTC = &config.WebRTCConfig{
+		ICEServers: []config.ICEServerConfig{{URLs: []string{"turn:turn.example.com:3478"}}},
+	}
+	err = invalidNetwork.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `username and credential`)
+
+	invalidNetwork.Network.WebRTC = &config.WebRTCConfig{
+		ICEServers: []config.ICEServerConfig{{URLs: []string{"http://stun.example.com"}}},
+	}
+	err = invalidNetwork.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `must start with`)
+
+	invalidNetwork.Network.WebRTC = &config.WebRTCConfig{
+		ICEServers: []config.ICEServerConfig{{URLs: []string{"stun:stun.example.com:3478"}}},
+		ForceRelay: true,
+	}
+	err = invalidNetwork.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `force_relay`)
+
+	invalidNetwork.Network.WebRTC = nil
+	test.That(t, invalidNetwork.Ensure(false, logger), test.ShouldBeNil)
+
 	invalidNetwork.Network.BindAddress = "woop"
 	err = invalidNetwork.Ensure(false, logger)
 	test.That(t, err, test.ShouldNotBeNil)
@@ -695,6 +738,28 @@ func TestRemoteValidate(t *testing.T) {
 			"must start with a letter or number and must only contain letters, numbers, dashes, and underscores",
 		)
 	})
+
+	t.Run("remote summarization", func(t *testing.T) {
+		remote := config.Remote{
+			Name:          "foo",
+			Address:       "address",
+			Summarization: &config.RemoteSummarization{ReadingsInterval: "500ms", ImagesInterval: "2s"},
+		}
+		_, err := remote.Validate("path")
+		test.That(t, err, test.ShouldBeNil)
+		readings, images := remote.Summarization.Intervals()
+		test.That(t, readings, test.ShouldEqual, 500*time.Millisecond)
+		test.That(t, images, test.ShouldEqual, 2*time.Second)
+
+		remote = config.Remote{
+			Name:          "foo",
+			Address:       "address",
+			Summarization: &config.RemoteSummarization{ReadingsInterval: "soon"},
+		}
+		_, err = remote.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "readings_interval")
+	})
 }
 
 func TestCopyOnlyPublicFields(t *testing.T) {
@@ -946,6 +1011,35 @@ func TestAuthConfigEnsure(t *testing.T) {
 		test.That(t, err, test.ShouldBeNil)
 	})
 
+	t.Run("api-key handler with roles", func(t *testing.T) {
+		logger := logging.NewTestLogger(t)
+		config := config.Config{
+			Auth: config.AuthConfig{
+				Handlers: []config.AuthHandlerConfig{
+					{
+						Type:   rpc.CredentialsTypeAPIKey,
+						Config: rutils.AttributeMap{"key-id": "abc123", "keys": []string{"key-id"}},
+					},
+				},
+				Roles:       map[string]config.AuthRole{"key-id": config.AuthRoleOperator},
+				DefaultRole: config.AuthRoleViewer,
+			},
+		}
+		test.That(t, config.Ensure(true, logger), test.ShouldBeNil)
+
+		config.Auth.Roles["key-id"] = "driver"
+		err := config.Ensure(true, logger)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, `auth.roles.key-id`)
+		test.That(t, err.Error(), test.ShouldContainSubstring, `unknown role "driver"`)
+
+		config.Auth.Roles["key-id"] = config.AuthRoleOwner
+		config.Auth.DefaultRole = "admin"
+		err = config.Ensure(true, logger)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, `auth.default_role`)
+	})
+
 	t.Run("external auth with invalid keyset", func(t *testing.T) {
 		logger := logging.NewTestLogger(t)
 		config := config.Config{
@@ -1205,6 +1299,48 @@ func TestPackageConfig(t *testing.T) {
 			},
 			shouldFailValidation: true,
 		},
+		{
+			config: config.PackageConfig{
+				Name:    "my_arm",
+				Type:    config.PackageTypeKinematics,
+				Package: "my_org/my_arm",
+				Version: "1",
+				URL:     "https://example.com/my_arm.urdf",
+				SHA256:  "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+			},
+			expectedRealFilePath: filepath.Join(viamDotDir, "packages", "data", "kinematics", "my_org-my_arm-1"),
+		},
+		{
+			config: config.PackageConfig{
+				Name:    "my_arm",
+				Type:    config.PackageTypeKinematics,
+				Package: "my_org/my_arm",
+				Version: "1",
+			},
+			shouldFailValidation: true,
+		},
+		{
+			config: config.PackageConfig{
+				Name:    "my_arm",
+				Type:    config.PackageTypeKinematics,
+				Package: "my_org/my_arm",
+				Version: "1",
+				URL:     "https://example.com/my_arm.urdf",
+				SHA256:  "not-a-checksum",
+			},
+			shouldFailValidation: true,
+		},
+		{
+			config: config.PackageConfig{
+				Name:    "my_arm",
+				Type:    config.PackageTypeKinematics,
+				Package: "my_org/my_arm",
+				Version: "1",
+				URL:     "ftp://example.com/my_arm.urdf",
+				SHA256:  "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+			},
+			shouldFailValidation: true,
+		},
 	}
 
 	for _, pt := range packageTests {
@@ -1226,3 +1362,31 @@ func TestConfigRobotWebProfile(t *testing.T) {
 
 	test.That(t, cfg.EnableWebProfile, test.ShouldBeTrue)
 }
+
+func TestWebRTCConfiguration(t *testing.T) {
+	var unset *config.WebRTCConfig
+	test.That(t, unset.Configuration(), test.ShouldResemble, grpc.DefaultWebRTCConfiguration)
+
+	var conf config.WebRTCConfig
+	test.That(t, json.Unmarshal([]byte(`{
+		"ice_servers": [
+			{"urls": ["stun:stun.example.com:3478"]},
+			{"urls": ["turn:turn.example.com:3478", "turns:turn.example.com:5349?transport=tcp"], "username": "user", "credential": "pass"}
+		],
+		"force_relay": true
+	}`), &conf), test.ShouldBeNil)
+	test.That(t, conf.Validate("network.webrtc"), test.ShouldBeNil)
+
+	webrtcConfig := conf.Configuration()
+	test.That(t, webrtcConfig.ICETransportPolicy, test.ShouldEqual, webrtc.ICETransportPolicyRelay)
+	test.That(t, webrtcConfig.ICEServers, test.ShouldResemble, []webrtc.ICEServer{
+		{URLs: []string{"stun:stun.example.com:3478"}},
+		{
+			URLs:       []string{"turn:turn.example.com:3478", "turns:turn.example.com:5349?transport=tcp"},
+			Username:   "user",
+			Credential: "pass",
+		},
+	})
+	// the default configuration is left as is
+	test.That(t, grpc.DefaultWebRTCConfiguration.ICEServers, test.ShouldHaveLength, 1)
+}
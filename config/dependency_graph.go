@@ -0,0 +1,232 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"go.viam.com/rdk/resource"
+)
+
+// DependencyGraph is the graph of dependencies between the components and services of a config, resolved the
+// same way the robot resolves them. Implicit dependencies of builtin models are only known once attributes
+// have been converted, and those of modular resources only once their module has validated them.
+type DependencyGraph struct {
+	Resources []DependencyGraphResource `json:"resources"`
+	// Cycles lists each dependency cycle as the resource names along it, starting and ending with the same one.
+	Cycles [][]string `json:"cycles,omitempty"`
+}
+
+// DependencyGraphResource is a component or service in a DependencyGraph along with what it depends on.
+type DependencyGraphResource struct {
+	Name         string                      `json:"name"`
+	Model        string                      `json:"model"`
+	Dependencies []DependencyGraphDependency `json:"dependencies,omitempty"`
+}
+
+// DependencyGraphDependency is a single dependency of a resource, as configured and as resolved.
+type DependencyGraphDependency struct {
+	// Name is the dependency as it appears in the config.
+	Name string `json:"name"`
+	// Resolved is the full name of the resource the dependency resolved to, if any. Dependencies on resources
+	// of remotes that are not fully qualified cannot be resolved until the robot connects to its remotes.
+	Resolved string `json:"resolved,omitempty"`
+	// Implicit is whether the dependency comes from the resource's attributes rather than its depends_on.
+	Implicit bool `json:"implicit,omitempty"`
+	// Error explains why the dependency can never be resolved.
+	Error string `json:"error,omitempty"`
+}
+
+// NewDependencyGraph returns the dependency graph of the components and services in the config.
+func NewDependencyGraph(c *Config) *DependencyGraph {
+	var confs []*resource.Config
+	for idx := range c.Components {
+		confs = append(confs, &c.Components[idx])
+	}
+	for idx := range c.Services {
+		confs = append(confs, &c.Services[idx])
+	}
+	remotes := make(map[string]bool, len(c.Remotes))
+	for _, remote := range c.Remotes {
+		remotes[remote.Name] = true
+	}
+
+	names := make(map[resource.Name]bool, len(confs))
+	// default services are added to every config while it is processed, so they may not be in it yet
+	for _, name := range resource.DefaultServices() {
+		names[name] = true
+	}
+	for _, conf := range confs {
+		names[conf.ResourceName()] = true
+	}
+
+	// resolve follows resource.Graph.ResolveDependencies: fully qualified names are used as is, and short names
+	// must match exactly one resource.
+	resolve := func(dep string) (resource.Name, error) {
+		if name, err := resource.NewFromString(dep); err == nil {
+			switch {
+			case name.API.Type.Namespace == resource.APINamespaceRDKInternal:
+				// internal services are always present
+				return name, nil
+			case name.Remote != "":
+				if !remotes[strings.Split(name.Remote, ":")[0]] {
+					return resource.Name{}, errors.Errorf("no remote named %q is configured", name.Remote)
+				}
+				return name, nil
+			case !names[name]:
+				return resource.Name{}, errors.Errorf("no %s named %q is configured", name.API, name.Name)
+			}
+			return name, nil
+		}
+
+		var matches []resource.Name
+		for name := range names {
+			if name.ShortName() == dep || (!strings.Contains(dep, ":") && name.Name == dep) {
+				matches = append(matches, name)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			if remote, _, found := strings.Cut(dep, ":"); found && !remotes[remote] {
+				return resource.Name{}, errors.Errorf("no remote named %q is configured", remote)
+			}
+			if len(remotes) == 0 {
+				return resource.Name{}, errors.Errorf("no component or service named %q is configured", dep)
+			}
+			// may be a resource of a remote
+			return resource.Name{}, nil
+		case 1:
+			return matches[0], nil
+		default:
+			slices.SortFunc(matches, func(left, right resource.Name) int {
+				return strings.Compare(left.String(), right.String())
+			})
+			return resource.Name{}, errors.Errorf(
+				"%q matches more than one resource %v; use a fully qualified name such as %q", dep, matches, matches[0])
+		}
+	}
+
+	graph := &DependencyGraph{}
+	edges := make(map[string][]string, len(confs))
+	for _, conf := range confs {
+		resName := conf.ResourceName().String()
+		res := DependencyGraphResource{Name: resName, Model: conf.Model.String()}
+		for _, dep := range conf.Dependencies() {
+			graphDep := DependencyGraphDependency{Name: dep, Implicit: !slices.Contains(conf.DependsOn, dep)}
+			resolved, err := resolve(dep)
+			switch {
+			case err != nil:
+				graphDep.Error = err.Error()
+			case resolved.Name != "":
+				graphDep.Resolved = resolved.String()
+				edges[resName] = append(edges[resName], graphDep.Resolved)
+			}
+			res.Dependencies = append(res.Dependencies, graphDep)
+		}
+		graph.Resources = append(graph.Resources, res)
+	}
+	slices.SortFunc(graph.Resources, func(left, right DependencyGraphResource) int {
+		return strings.Compare(left.Name, right.Name)
+	})
+	graph.Cycles = findCycles(graph.Resources, edges)
+	return graph
+}
+
+// findCycles returns each distinct cycle found by a depth first search of edges, visiting resources in order.
+func findCycles(resources []DependencyGraphResource, edges map[string][]string) [][]string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(resources))
+	var (
+		path   []string
+		cycles [][]string
+	)
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range edges[name] {
+			switch state[dep] {
+			case visiting:
+				start := slices.Index(path, dep)
+				cycle := append(slices.Clone(path[start:]), dep)
+				cycles = append(cycles, cycle)
+			case unvisited:
+				visit(dep)
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+	}
+	for _, res := range resources {
+		if state[res.Name] == unvisited {
+			visit(res.Name)
+		}
+	}
+	return cycles
+}
+
+// Validate returns an error for every dependency cycle and for every dependency that can never be resolved.
+func (g *DependencyGraph) Validate() error {
+	var allErrs error
+	for _, cycle := range g.Cycles {
+		allErrs = multierr.Combine(allErrs, errors.Errorf(
+			"circular dependency %s; remove one of these dependencies so the resources can be built",
+			strings.Join(cycle, " -> ")))
+	}
+	for _, res := range g.Resources {
+		for _, dep := range res.Dependencies {
+			if dep.Error == "" {
+				continue
+			}
+			kind := "depends_on"
+			if dep.Implicit {
+				kind = "attributes"
+			}
+			allErrs = multierr.Combine(allErrs, errors.Errorf(
+				"%s cannot resolve dependency %q from its %s: %s", res.Name, dep.Name, kind, dep.Error))
+		}
+	}
+	return allErrs
+}
+
+// ExportDot exports the dependency graph as a DOT representation for visualization, with edges leaving each
+// resource and arriving at its dependencies. Implicit dependencies are dashed and unresolvable ones are red.
+// DOT reference: https://graphviz.org/doc/info/lang.html.
+func (g *DependencyGraph) ExportDot() string {
+	var sb strings.Builder
+	sb.WriteString("digraph {\n")
+	sb.WriteString("    rankdir=LR;\n")
+	sb.WriteString("    node [style=filled,color=bisque];\n")
+	for _, res := range g.Resources {
+		sb.WriteString(fmt.Sprintf("    %q [tooltip=%q];\n", res.Name, "Model: "+res.Model))
+	}
+	for _, res := range g.Resources {
+		for _, dep := range res.Dependencies {
+			var attrs []string
+			if dep.Implicit {
+				attrs = append(attrs, "style=dashed")
+			}
+			dest := dep.Resolved
+			switch {
+			case dep.Error != "":
+				dest = dep.Name
+				attrs = append(attrs, "color=indianred", fmt.Sprintf("tooltip=%q", dep.Error))
+				sb.WriteString(fmt.Sprintf("    %q [color=indianred];\n", dest))
+			case dest == "":
+				dest = dep.Name
+				attrs = append(attrs, `tooltip="unresolved; may be a resource of a remote"`)
+				sb.WriteString(fmt.Sprintf("    %q [color=lightgray];\n", dest))
+			}
+			sb.WriteString(fmt.Sprintf("    %q -> %q [%s];\n", res.Name, dest, strings.Join(attrs, ",")))
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
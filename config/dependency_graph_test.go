@@ -0,0 +1,82 @@
+package config_test
+
+import (
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/motion"
+)
+
+func TestDependencyGraph(t *testing.T) {
+	boardName := board.Named("board1").String()
+	motorName := motor.Named("motor1").String()
+
+	cfg := &config.Config{
+		Components: []resource.Config{
+			{Name: "board1", API: board.API, Model: fakeModel},
+			{Name: "motor1", API: motor.API, Model: fakeModel, ImplicitDependsOn: []string{"board1"}},
+			{Name: "motor2", API: motor.API, Model: fakeModel, DependsOn: []string{motorName, motion.Named("builtin").String()}},
+		},
+	}
+	graph := config.NewDependencyGraph(cfg)
+	test.That(t, graph.Validate(), test.ShouldBeNil)
+	test.That(t, graph.Cycles, test.ShouldBeEmpty)
+	test.That(t, graph.Resources, test.ShouldHaveLength, 3)
+	test.That(t, graph.Resources[0].Name, test.ShouldEqual, boardName)
+	test.That(t, graph.Resources[1].Dependencies, test.ShouldResemble, []config.DependencyGraphDependency{
+		{Name: "board1", Resolved: boardName, Implicit: true},
+	})
+	test.That(t, graph.Resources[2].Dependencies[0].Resolved, test.ShouldEqual, motorName)
+
+	dot := graph.ExportDot()
+	test.That(t, dot, test.ShouldContainSubstring, `"`+motorName+`" -> "`+boardName+`" [style=dashed];`)
+
+	t.Run("cycle", func(t *testing.T) {
+		cfg := &config.Config{
+			Components: []resource.Config{
+				{Name: "board1", API: board.API, Model: fakeModel, DependsOn: []string{"motor2"}},
+				{Name: "motor1", API: motor.API, Model: fakeModel, ImplicitDependsOn: []string{"board1"}},
+				{Name: "motor2", API: motor.API, Model: fakeModel, DependsOn: []string{"motor1"}},
+			},
+		}
+		graph := config.NewDependencyGraph(cfg)
+		test.That(t, graph.Cycles, test.ShouldResemble, [][]string{
+			{boardName, motor.Named("motor2").String(), motorName, boardName},
+		})
+		err := graph.Validate()
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "circular dependency")
+	})
+
+	t.Run("missing and ambiguous", func(t *testing.T) {
+		cfg := &config.Config{
+			Components: []resource.Config{
+				{Name: "thing", API: board.API, Model: fakeModel},
+				{Name: "thing", API: motor.API, Model: fakeModel, ImplicitDependsOn: []string{"board2"}},
+				{Name: "motor2", API: motor.API, Model: fakeModel, DependsOn: []string{"thing", "remote1:board1"}},
+			},
+		}
+		err := config.NewDependencyGraph(cfg).Validate()
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, `cannot resolve dependency "board2" from its attributes`)
+		test.That(t, err.Error(), test.ShouldContainSubstring, `"thing" matches more than one resource`)
+		test.That(t, err.Error(), test.ShouldContainSubstring, `no remote named "remote1" is configured`)
+
+		// with a remote, unknown short names may be resources of that remote
+		cfg.Remotes = []config.Remote{{Name: "remote1", Address: "localhost:8080"}}
+		cfg.Components = cfg.Components[1:]
+		test.That(t, config.NewDependencyGraph(cfg).Validate(), test.ShouldBeNil)
+
+		cfg.DisablePartialStart = true
+		cfg.Components[0].DependsOn = []string{"rdk:component:board/board2"}
+		err = cfg.Ensure(false, logging.NewTestLogger(t))
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, `no rdk:component:board named "board2" is configured`)
+	})
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// FleetCertificateSigner issues certificates from the fleet CA to the machines of a fleet, from the certificate
+// requests they send it. It is the only holder of the CA's private key. Each machine authenticates with a token,
+// and is issued certificates only for the names its token allows, so that it can't pass for another machine.
+type FleetCertificateSigner struct {
+	ca     tls.Certificate
+	caLeaf *x509.Certificate
+	// tokens are the names each machine's token allows.
+	tokens      map[string][]string
+	maxLifetime time.Duration
+}
+
+// NewFleetCertificateSigner returns a signer of certificates from the CA in certFile and keyFile, for the machines
+// with the tokens given, which are valid for at most maxLifetime.
+func NewFleetCertificateSigner(
+	certFile, keyFile string,
+	tokens map[string][]string,
+	maxLifetime time.Duration,
+) (*FleetCertificateSigner, error) {
+	ca, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	caLeaf, err := x509.ParseCertificate(ca.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+	if !caLeaf.IsCA {
+		return nil, errors.Errorf("certificate in %q is not a CA", certFile)
+	}
+	for token, names := range tokens {
+		if len(token) < 16 {
+			return nil, errors.New("machine tokens must be at least 16 characters")
+		}
+		if len(names) == 0 {
+			return nil, errors.New("every machine token must allow at least one name")
+		}
+	}
+	if maxLifetime <= 0 {
+		maxLifetime = DefaultFleetCertificateLifetime
+	}
+	return &FleetCertificateSigner{ca: ca, caLeaf: caLeaf, tokens: tokens, maxLifetime: maxLifetime}, nil
+}
+
+// Sign returns a certificate chain from the CA for the request, valid for lifetime, or at most the signer's
+// maximum, and for those of the requested names which are allowed.
+func (signer *FleetCertificateSigner) Sign(
+	csr *x509.CertificateRequest,
+	allowed []string,
+	lifetime time.Duration,
+) ([][]byte, error) {
+	if err := csr.CheckSignature(); err != nil {
+		return nil, errors.Wrap(err, "certificate request is not signed by its key")
+	}
+	var names []string
+	for _, name := range csr.DNSNames {
+		for _, allowedName := range allowed {
+			if strings.EqualFold(name, allowedName) {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil, errors.New("none of the requested names are allowed")
+	}
+	if lifetime <= 0 || lifetime > signer.maxLifetime {
+		lifetime = signer.maxLifetime
+	}
+	return signer.issue(csr.PublicKey, names, lifetime)
+}
+
+func (signer *FleetCertificateSigner) issue(publicKey interface{}, names []string, lifetime time.Duration) ([][]byte, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: names[0]},
+		// allow for clocks of machines on the network which are a little behind
+		NotBefore:   now.Add(-5 * time.Minute),
+		NotAfter:    now.Add(lifetime),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	for _, name := range names {
+		if ip := net.ParseIP(name); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, name)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer.caLeaf, publicKey, signer.ca.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return [][]byte{der, signer.ca.Certificate[0]}, nil
+}
+
+// ServerCertificate returns a certificate from the CA for the signer itself to serve, valid for the names given,
+// so that machines can verify the signer with the fleet CA.
+func (signer *FleetCertificateSigner) ServerCertificate(names ...string) (*tls.Certificate, error) {
+	if len(names) == 0 {
+		return nil, errors.New("the signer needs at least one name")
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := signer.issue(&key.PublicKey, names, signer.maxLifetime)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Certificate{Certificate: chain, PrivateKey: key, Leaf: leaf}, nil
+}
+
+// ServeHTTP signs a PEM encoded certificate request POSTed by a machine with its token as a bearer token, and a
+// lifetime query parameter such as "24h", responding with the PEM encoded certificate chain.
+func (signer *FleetCertificateSigner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "certificate requests must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+	allowed, ok := signer.allowedNames(r.Header.Get("Authorization"))
+	if !ok {
+		http.Error(w, "unknown machine token", http.StatusUnauthorized)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	block, _ := pem.Decode(body)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		http.Error(w, "body must be a PEM encoded certificate request", http.StatusBadRequest)
+		return
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var lifetime time.Duration
+	if r.FormValue("lifetime") != "" {
+		if lifetime, err = time.ParseDuration(r.FormValue("lifetime")); err != nil {
+			http.Error(w, "lifetime must be a duration such as 24h", http.StatusBadRequest)
+			return
+		}
+	}
+	chain, err := signer.Sign(csr, allowed, lifetime)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-pem-file")
+	for _, der := range chain {
+		//nolint:errcheck
+		w.Write(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	}
+}
+
+// allowedNames returns the names the bearer token in the authorization header allows, comparing it with every
+// token in constant time.
+func (signer *FleetCertificateSigner) allowedNames(authorization string) ([]string, bool) {
+	given, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok || given == "" {
+		return nil, false
+	}
+	var allowed []string
+	found := false
+	for token, names := range signer.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(given)) == 1 {
+			allowed, found = names, true
+		}
+	}
+	return allowed, found
+}
@@ -0,0 +1,358 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/resource"
+)
+
+// DefaultFleetCertificateLifetime is how long certificates issued from the fleet CA are valid unless
+// certificate_lifetime is set. They are replaced once half of it has passed.
+const DefaultFleetCertificateLifetime = 24 * time.Hour
+
+// fleetSigningTimeout bounds how long a request to the fleet's signer may take.
+const fleetSigningTimeout = 30 * time.Second
+
+// FleetCAConfig is a certificate authority shared by the machines of a fleet, so that machines on a local network
+// can authenticate each other with mutual TLS without the cloud. The CA's private key is kept by a signer, such as
+// web/cmd/fleetca, on one trusted host rather than on the machines. Each machine keeps its own private key and
+// sends the signer requests for short-lived certificates, which it serves and presents to remotes, so a machine
+// which is compromised can't issue certificates for any other.
+type FleetCAConfig struct {
+	// CertFile is the CA's certificate, which certificates of the fleet must be issued by.
+	CertFile string `json:"cert_file"`
+	// KeyFile is where this machine's private key is kept. It is created if it does not exist.
+	KeyFile string `json:"key_file"`
+	// SigningURL is the https URL of the fleet's signer, whose certificate must be issued by the CA too.
+	SigningURL string `json:"signing_url"`
+	// SigningToken authenticates this machine to the signer, which issues it certificates only for the names it
+	// has allowed the token.
+	SigningToken        string `json:"signing_token"`
+	CertificateLifetime string `json:"certificate_lifetime,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *FleetCAConfig) Validate(path string) error {
+	if conf.CertFile == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "cert_file")
+	}
+	if conf.KeyFile == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "key_file")
+	}
+	if conf.SigningURL == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "signing_url")
+	}
+	if signingURL, err := url.Parse(conf.SigningURL); err != nil || signingURL.Scheme != "https" || signingURL.Host == "" {
+		return resource.NewConfigValidationError(path, errors.New("signing_url must be an https URL"))
+	}
+	if conf.SigningToken == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "signing_token")
+	}
+	if conf.CertificateLifetime != "" {
+		lifetime, err := time.ParseDuration(conf.CertificateLifetime)
+		if err != nil {
+			return resource.NewConfigValidationError(path, errors.Wrap(err, "error validating certificate_lifetime"))
+		}
+		if lifetime < time.Minute {
+			return resource.NewConfigValidationError(path, errors.New("certificate_lifetime must be at least 1m"))
+		}
+	}
+	return nil
+}
+
+// Lifetime returns how long issued certificates are valid.
+func (conf *FleetCAConfig) Lifetime() time.Duration {
+	lifetime, err := time.ParseDuration(conf.CertificateLifetime)
+	if err != nil || lifetime <= 0 {
+		return DefaultFleetCertificateLifetime
+	}
+	return lifetime
+}
+
+// CertPool returns a pool holding the fleet CA, to verify certificates issued from it.
+func (conf *FleetCAConfig) CertPool() (*x509.CertPool, error) {
+	return LoadCertPool(conf.CertFile)
+}
+
+// FleetCertificateIssuer gets certificates for this machine from the fleet's signer, and gets a new one once half
+// of the lifetime of the last has passed. The CA is read again for every certificate, so that it can be rotated too.
+type FleetCertificateIssuer struct {
+	conf  FleetCAConfig
+	names []string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	renewAt time.Time
+}
+
+// NewFleetCertificateIssuer returns an issuer of certificates from the fleet CA which are valid for the host names
+// given, and for the machine's own host name. The signer leaves out any names it has not allowed the machine.
+func NewFleetCertificateIssuer(conf *FleetCAConfig, names ...string) *FleetCertificateIssuer {
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		names = append(names, hostname, hostname+".local")
+	}
+	return &FleetCertificateIssuer{conf: *conf, names: append(names, "localhost")}
+}
+
+// Certificate returns the current certificate, getting a new one if it is due.
+func (issuer *FleetCertificateIssuer) Certificate() (*tls.Certificate, error) {
+	issuer.mu.Lock()
+	defer issuer.mu.Unlock()
+	now := time.Now()
+	if issuer.cert != nil && now.Before(issuer.renewAt) {
+		return issuer.cert, nil
+	}
+	cert, err := issuer.issue()
+	if err != nil {
+		if issuer.cert != nil && now.Before(issuer.cert.Leaf.NotAfter) {
+			// keep serving the last certificate while it is still valid, such as while the signer is unreachable
+			return issuer.cert, nil
+		}
+		return nil, errors.Wrap(err, "failed to get certificate from fleet signer")
+	}
+	issuer.cert, issuer.renewAt = cert, now.Add(cert.Leaf.NotAfter.Sub(now)/2)
+	return issuer.cert, nil
+}
+
+// issue sends the signer a request for a certificate for the machine's key, and checks that what comes back was
+// issued by the fleet CA for that key.
+func (issuer *FleetCertificateIssuer) issue() (*tls.Certificate, error) {
+	key, err := loadOrCreateKey(issuer.conf.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: issuer.names[0]},
+		DNSNames: issuer.names,
+	}, key)
+	if err != nil {
+		return nil, err
+	}
+	roots, err := issuer.conf.CertPool()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), fleetSigningTimeout)
+	defer cancel()
+	signingURL, err := url.Parse(issuer.conf.SigningURL)
+	if err != nil {
+		return nil, err
+	}
+	query := signingURL.Query()
+	query.Set("lifetime", issuer.conf.Lifetime().String())
+	signingURL.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, signingURL.String(),
+		bytes.NewReader(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+issuer.conf.SigningToken)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{MinVersion: tls.VersionTLS12, RootCAs: roots}}}
+	defer client.CloseIdleConnections()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.UncheckedErrorFunc(resp.Body.Close)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("fleet signer refused: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var chain [][]byte
+	for block, rest := pem.Decode(body); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type == "CERTIFICATE" {
+			chain = append(chain, block.Bytes)
+		}
+	}
+	if len(chain) == 0 {
+		return nil, errors.New("fleet signer returned no certificate")
+	}
+	leaf, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return nil, err
+	}
+	if !key.PublicKey.Equal(leaf.PublicKey) {
+		return nil, errors.New("fleet signer returned a certificate for another key")
+	}
+	if err := verifyChain(chain, roots, ""); err != nil {
+		return nil, err
+	}
+	return &tls.Certificate{Certificate: chain, PrivateKey: key, Leaf: leaf}, nil
+}
+
+// loadOrCreateKey reads the PEM encoded private key in path, creating it if it does not exist.
+func loadOrCreateKey(path string) (*ecdsa.PrivateKey, error) {
+	//nolint:gosec
+	keyPEM, err := os.ReadFile(path)
+	if err == nil {
+		block, _ := pem.Decode(keyPEM)
+		if block == nil {
+			return nil, errors.Errorf("no private key found in %q", path)
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := parsed.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, errors.Errorf("private key in %q is not an ECDSA key", path)
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// RemoteTLSConfig configures mutual TLS with a remote, so that connections to it are authenticated in both
+// directions without the cloud.
+type RemoteTLSConfig struct {
+	// CAFile holds the CAs which must have issued the remote's certificate. Defaults to the fleet CA.
+	CAFile string `json:"ca_file,omitempty"`
+	// CertFile and KeyFile are the certificate presented to the remote. They are read again for every connection,
+	// so that they can be rotated. Defaults to a certificate issued from the fleet CA.
+	CertFile string `json:"cert_file,omitempty"`
+	KeyFile  string `json:"key_file,omitempty"`
+	// ServerName is the name which the remote's certificate must be valid for, so that one machine of the fleet
+	// can't pass for another.
+	ServerName string `json:"server_name"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *RemoteTLSConfig) Validate(path string) error {
+	if conf.ServerName == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "server_name")
+	}
+	if (conf.CertFile == "") != (conf.KeyFile == "") {
+		return resource.NewConfigValidationError(path, errors.New("must provide both cert_file and key_file"))
+	}
+	return nil
+}
+
+// ClientTLSConfig returns the TLS config to dial the remote with, using the fleet CA, which may be nil, for
+// whatever is not set.
+func (conf *RemoteTLSConfig) ClientTLSConfig(fleetCA *FleetCAConfig) (*tls.Config, error) {
+	if conf.ServerName == "" {
+		return nil, errors.New("remote tls requires server_name")
+	}
+	caFile := conf.CAFile
+	var getCertificate func() (*tls.Certificate, error)
+	switch {
+	case conf.CertFile != "":
+		getCertificate = func() (*tls.Certificate, error) {
+			cert, err := tls.LoadX509KeyPair(conf.CertFile, conf.KeyFile)
+			if err != nil {
+				return nil, err
+			}
+			return &cert, nil
+		}
+	case fleetCA != nil:
+		getCertificate = NewFleetCertificateIssuer(fleetCA).Certificate
+	default:
+		return nil, errors.New("remote tls requires cert_file and key_file, or a fleet_ca in the network config")
+	}
+	if caFile == "" {
+		if fleetCA == nil {
+			return nil, errors.New("remote tls requires ca_file, or a fleet_ca in the network config")
+		}
+		caFile = fleetCA.CertFile
+	}
+
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// the certificate is verified below instead, so that the CAs are read again for every connection
+		//nolint:gosec
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyRemoteCertificate(rawCerts, caFile, conf.ServerName)
+		},
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return getCertificate()
+		},
+	}, nil
+}
+
+// verifyRemoteCertificate checks that the certificate chain was issued by the CAs in caFile for serverName.
+func verifyRemoteCertificate(rawCerts [][]byte, caFile, serverName string) error {
+	if len(rawCerts) == 0 {
+		return errors.New("remote presented no certificate")
+	}
+	roots, err := LoadCertPool(caFile)
+	if err != nil {
+		return err
+	}
+	return verifyChain(rawCerts, roots, serverName)
+}
+
+// verifyChain checks that the certificate chain was issued by the roots and, if serverName is set, that it is
+// valid for it.
+func verifyChain(rawCerts [][]byte, roots *x509.CertPool, serverName string) error {
+	certs := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return err
+		}
+		certs = append(certs, cert)
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		DNSName:       serverName,
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
+// LoadCertPool reads a pool of PEM encoded certificates from a file.
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	//nolint:gosec
+	pemCerts, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemCerts) {
+		return nil, errors.Errorf("no certificates found in %q", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+)
+
+// writeTestCA writes a new CA into dir and returns its certificate and key files.
+func writeTestCA(t *testing.T, dir, name string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	test.That(t, err, test.ShouldBeNil)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	test.That(t, err, test.ShouldBeNil)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	test.That(t, err, test.ShouldBeNil)
+
+	certFile, keyFile := filepath.Join(dir, name+".pem"), filepath.Join(dir, name+".key")
+	test.That(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600),
+		test.ShouldBeNil)
+	test.That(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600),
+		test.ShouldBeNil)
+	return certFile, keyFile
+}
+
+// startTestSigner starts a signer for a new CA in dir which allows each machine token the names given, and returns
+// the config of the first machine's fleet CA.
+func startTestSigner(t *testing.T, dir, name string, tokens map[string][]string) (*FleetCAConfig, *FleetCertificateSigner) {
+	t.Helper()
+	caCert, caKey := writeTestCA(t, dir, name)
+	signer, err := NewFleetCertificateSigner(caCert, caKey, tokens, time.Hour)
+	test.That(t, err, test.ShouldBeNil)
+	serverCert, err := signer.ServerCertificate("127.0.0.1")
+	test.That(t, err, test.ShouldBeNil)
+	server := httptest.NewUnstartedServer(signer)
+	server.TLS = &tls.Config{MinVersion: tls.VersionTLS12, Certificates: []tls.Certificate{*serverCert}}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return &FleetCAConfig{
+		CertFile:   caCert,
+		KeyFile:    filepath.Join(dir, name+"-machine.key"),
+		SigningURL: server.URL,
+	}, signer
+}
+
+const (
+	testToken1 = "token-for-robot1-0123456789"
+	testToken2 = "token-for-robot2-0123456789"
+)
+
+func TestFleetCertificateIssuer(t *testing.T) {
+	dir := t.TempDir()
+	fleetCA, _ := startTestSigner(t, dir, "fleet", map[string][]string{testToken1: {"robot1.local"}})
+	fleetCA.SigningToken = testToken1
+	fleetCA.CertificateLifetime = "30m"
+	test.That(t, fleetCA.Validate("network.fleet_ca"), test.ShouldBeNil)
+	test.That(t, (&FleetCAConfig{CertFile: "ca.pem"}).Validate("network.fleet_ca"), test.ShouldNotBeNil)
+	test.That(t, (&FleetCAConfig{CertFile: "ca.pem", KeyFile: "key.pem", SigningURL: "http://signer", SigningToken: "x"}).
+		Validate("network.fleet_ca"), test.ShouldNotBeNil)
+
+	issuer := NewFleetCertificateIssuer(fleetCA, "robot1.local", "robot2.local")
+	cert, err := issuer.Certificate()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cert.Leaf.NotAfter.Sub(cert.Leaf.NotBefore), test.ShouldEqual, 30*time.Minute+5*time.Minute)
+	// only the names the machine's token allows are signed
+	test.That(t, cert.Leaf.DNSNames, test.ShouldResemble, []string{"robot1.local"})
+	pool, err := fleetCA.CertPool()
+	test.That(t, err, test.ShouldBeNil)
+	_, err = cert.Leaf.Verify(x509.VerifyOptions{
+		DNSName:   "robot1.local",
+		Roots:     pool,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	test.That(t, err, test.ShouldBeNil)
+
+	// the machine's key is kept, and never sent to the signer
+	keyInfo, err := os.Stat(fleetCA.KeyFile)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, keyInfo.Mode().Perm(), test.ShouldEqual, os.FileMode(0o600))
+
+	// the same certificate is used until half of its lifetime has passed
+	again, err := issuer.Certificate()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, again, test.ShouldEqual, cert)
+	issuer.renewAt = time.Now()
+	renewed, err := issuer.Certificate()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, renewed, test.ShouldNotEqual, cert)
+	test.That(t, renewed.PrivateKey, test.ShouldResemble, cert.PrivateKey)
+
+	// a signer which cannot be reached does not take away a certificate which is still valid
+	unreachable := *fleetCA
+	unreachable.SigningURL = "https://127.0.0.1:1"
+	issuer.conf = unreachable
+	issuer.renewAt = time.Now()
+	kept, err := issuer.Certificate()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, kept, test.ShouldEqual, renewed)
+	_, err = NewFleetCertificateIssuer(&unreachable).Certificate()
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// machines with unknown tokens, or asking for names they aren't allowed, are refused
+	unknown := *fleetCA
+	unknown.SigningToken = "not-a-token-of-the-fleet"
+	_, err = NewFleetCertificateIssuer(&unknown).Certificate()
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "401")
+	other := *fleetCA
+	other.KeyFile = filepath.Join(dir, "other.key")
+	other.SigningToken = testToken1
+	_, err = (&FleetCertificateIssuer{conf: other, names: []string{"robot2.local"}}).Certificate()
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "403")
+}
+
+func TestFleetCertificateSigner(t *testing.T) {
+	caCert, caKey := writeTestCA(t, t.TempDir(), "fleet")
+	_, err := NewFleetCertificateSigner(caCert, caKey, map[string][]string{"short": {"robot1"}}, 0)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = NewFleetCertificateSigner(caCert, caKey, map[string][]string{testToken1: nil}, 0)
+	test.That(t, err, test.ShouldNotBeNil)
+	signer, err := NewFleetCertificateSigner(caCert, caKey, map[string][]string{testToken1: {"robot1"}}, time.Hour)
+	test.That(t, err, test.ShouldBeNil)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	test.That(t, err, test.ShouldBeNil)
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{DNSNames: []string{"robot1"}}, key)
+	test.That(t, err, test.ShouldBeNil)
+	csr, err := x509.ParseCertificateRequest(csrDER)
+	test.That(t, err, test.ShouldBeNil)
+
+	// lifetimes are capped
+	chain, err := signer.Sign(csr, []string{"robot1"}, 48*time.Hour)
+	test.That(t, err, test.ShouldBeNil)
+	leaf, err := x509.ParseCertificate(chain[0])
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, leaf.NotAfter.Sub(leaf.NotBefore), test.ShouldEqual, time.Hour+5*time.Minute)
+
+	_, err = signer.Sign(csr, []string{"robot2"}, time.Hour)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, ok := signer.allowedNames("Bearer " + testToken1)
+	test.That(t, ok, test.ShouldBeTrue)
+	_, ok = signer.allowedNames(testToken1)
+	test.That(t, ok, test.ShouldBeFalse)
+}
+
+func TestRemoteTLS(t *testing.T) {
+	dir := t.TempDir()
+	tokens := map[string][]string{testToken1: {"robot1.local"}, testToken2: {"robot2.local"}}
+	fleetCA, _ := startTestSigner(t, dir, "fleet", tokens)
+	fleetCA.SigningToken = testToken1
+	otherCA, _ := startTestSigner(t, dir, "other", tokens)
+	otherCA.SigningToken = testToken1
+
+	test.That(t, (&RemoteTLSConfig{ServerName: "robot2.local", CertFile: "cert.pem"}).Validate("remotes.0.tls"),
+		test.ShouldNotBeNil)
+	test.That(t, (&RemoteTLSConfig{}).Validate("remotes.0.tls"), test.ShouldNotBeNil)
+	_, err := (&RemoteTLSConfig{ServerName: "robot2.local"}).ClientTLSConfig(nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = (&RemoteTLSConfig{}).ClientTLSConfig(fleetCA)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// the remote requires clients to have a certificate from the fleet CA
+	serverCA := *fleetCA
+	serverCA.KeyFile = filepath.Join(dir, "robot2.key")
+	serverCA.SigningToken = testToken2
+	serverIssuer := NewFleetCertificateIssuer(&serverCA, "robot2.local")
+	clientCAs, err := fleetCA.CertPool()
+	test.That(t, err, test.ShouldBeNil)
+	listener, err := tls.Listen("tcp", "localhost:0", &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return serverIssuer.Certificate()
+		},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			//nolint:errcheck
+			conn.Write([]byte("ok"))
+			conn.Close()
+		}
+	}()
+
+	dial := func(remoteTLS *RemoteTLSConfig, fleetCA *FleetCAConfig) error {
+		tlsConfig, err := remoteTLS.ClientTLSConfig(fleetCA)
+		test.That(t, err, test.ShouldBeNil)
+		conn, err := tls.Dial("tcp", listener.Addr().String(), tlsConfig)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		// the remote checks our certificate after our side of the handshake, so read to see its verdict
+		_, err = conn.Read(make([]byte, 2))
+		return err
+	}
+
+	test.That(t, dial(&RemoteTLSConfig{ServerName: "robot2.local"}, fleetCA), test.ShouldBeNil)
+	// another machine of the fleet can't pass for the remote
+	test.That(t, dial(&RemoteTLSConfig{ServerName: "robot1.local"}, fleetCA), test.ShouldNotBeNil)
+
+	// a remote whose certificate comes from another CA is not trusted
+	test.That(t, dial(&RemoteTLSConfig{ServerName: "robot2.local", CAFile: otherCA.CertFile}, fleetCA), test.ShouldNotBeNil)
+
+	// and neither are we, with a certificate from another CA
+	test.That(t, dial(&RemoteTLSConfig{ServerName: "robot2.local", CAFile: fleetCA.CertFile}, otherCA), test.ShouldNotBeNil)
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+// localFragmentsKey is the top level key of a local config that lists the files of the local fragments it is
+// composed from. It is distinct from the fragments of a cloud config, which are merged by the cloud.
+const localFragmentsKey = "local_fragments"
+
+// localFragmentListKeys are the top level lists whose entries are merged by the given key rather than replaced.
+var localFragmentListKeys = map[string]string{
+	"components": "name",
+	"services":   "name",
+	"remotes":    "name",
+	"modules":    "name",
+	"packages":   "name",
+	"processes":  "id",
+}
+
+// mergeLocalFragments returns the config in raw, read from configPath, merged on top of the local fragments it
+// lists, along with the paths of every fragment read. Fragments may themselves list fragments and are merged in
+// order, so a later fragment overrides an earlier one and the config overrides all of them:
+//   - objects are merged key by key, and a null value removes the key;
+//   - components, services, remotes, modules, and packages with the same name, and processes with the same id,
+//     are merged as objects, and any others are appended;
+//   - all other values replace the value they override.
+//
+// Relative fragment paths are relative to the file listing them. The merged config is validated as a whole once
+// it is processed.
+func mergeLocalFragments(raw []byte, configPath string) ([]byte, []string, error) {
+	var (
+		paths     []string
+		including []string
+	)
+	if IsFileConfigPath(configPath) {
+		including = append(including, filepath.Clean(configPath))
+	}
+	merged, listed, err := resolveLocalFragments(raw, configPath, including, &paths)
+	if err != nil || !listed {
+		return raw, paths, err
+	}
+	out, err := json.Marshal(merged)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, paths, nil
+}
+
+// resolveLocalFragments decodes raw and merges it on top of its local fragments, recursively. including holds
+// the files being resolved, to catch fragments that include themselves. It also returns whether raw listed
+// any fragments at all.
+func resolveLocalFragments(
+	raw []byte,
+	configPath string,
+	including []string,
+	paths *[]string,
+) (map[string]interface{}, bool, error) {
+	var conf map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	// keep numbers as they were written, since the merged config is encoded again
+	dec.UseNumber()
+	if err := dec.Decode(&conf); err != nil {
+		return nil, false, err
+	}
+	listedValue, ok := conf[localFragmentsKey]
+	if !ok {
+		return conf, false, nil
+	}
+	delete(conf, localFragmentsKey)
+
+	listed, ok := listedValue.([]interface{})
+	if !ok {
+		return nil, false, errors.Errorf("%q must be a list of fragment file paths", localFragmentsKey)
+	}
+	merged := map[string]interface{}{}
+	for _, value := range listed {
+		path, ok := value.(string)
+		if !ok {
+			return nil, false, errors.Errorf("%q must be a list of fragment file paths", localFragmentsKey)
+		}
+		if IsFileConfigPath(path) && !filepath.IsAbs(path) && IsFileConfigPath(configPath) {
+			path = filepath.Join(filepath.Dir(configPath), path)
+		}
+		if slices.Contains(including, path) {
+			return nil, false, errors.Errorf("local fragment %q includes itself", path)
+		}
+		fragmentRaw, err := readConfigBytes(path)
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "failed to read local fragment %q", path)
+		}
+		if IsFileConfigPath(path) {
+			*paths = append(*paths, path)
+		}
+		fragment, _, err := resolveLocalFragments(fragmentRaw, path, append(slices.Clone(including), path), paths)
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "failed to resolve local fragment %q", path)
+		}
+		mergeLocalFragmentObjects(merged, fragment, true)
+	}
+	mergeLocalFragmentObjects(merged, conf, true)
+	return merged, true, nil
+}
+
+// mergeLocalFragmentObjects merges override into base. topLevel is whether they are whole configs, whose
+// resource lists are merged by name.
+func mergeLocalFragmentObjects(base, override map[string]interface{}, topLevel bool) {
+	for key, value := range override {
+		if value == nil {
+			delete(base, key)
+			continue
+		}
+		switch typed := value.(type) {
+		case map[string]interface{}:
+			if baseObj, ok := base[key].(map[string]interface{}); ok {
+				mergeLocalFragmentObjects(baseObj, typed, false)
+				continue
+			}
+		case []interface{}:
+			if idKey, ok := localFragmentListKeys[key]; ok && topLevel {
+				if baseList, ok := base[key].([]interface{}); ok {
+					base[key] = mergeLocalFragmentLists(baseList, typed, idKey)
+					continue
+				}
+			}
+		}
+		base[key] = value
+	}
+}
+
+// mergeLocalFragmentLists merges each entry of override into the entry of base with the same idKey, or appends
+// it if there is none.
+func mergeLocalFragmentLists(base, override []interface{}, idKey string) []interface{} {
+	for _, value := range override {
+		entry, ok := value.(map[string]interface{})
+		id, hasID := entry[idKey].(string)
+		if !ok || !hasID {
+			base = append(base, value)
+			continue
+		}
+		var baseEntry map[string]interface{}
+		for _, baseValue := range base {
+			if candidate, ok := baseValue.(map[string]interface{}); ok && candidate[idKey] == id {
+				baseEntry = candidate
+				break
+			}
+		}
+		if baseEntry == nil {
+			base = append(base, entry)
+			continue
+		}
+		mergeLocalFragmentObjects(baseEntry, entry, false)
+	}
+	return base
+}
@@ -0,0 +1,72 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.viam.com/test"
+
+	fakemotor "go.viam.com/rdk/components/motor/fake"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+)
+
+func TestLocalFragments(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	dir := t.TempDir()
+	writeFile := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		test.That(t, os.MkdirAll(filepath.Dir(path), 0o700), test.ShouldBeNil)
+		test.That(t, os.WriteFile(path, []byte(contents), 0o600), test.ShouldBeNil)
+		return path
+	}
+
+	writeFile("fleet/base.json", `{
+		"components": [
+			{"name": "board1", "type": "board", "model": "fake"},
+			{"name": "motor1", "type": "motor", "model": "fake", "attributes": {"board": "board1", "max_rpm": 100, "direction_flip": true}}
+		],
+		"network": {"bind_address": ":8081"}
+	}`)
+	writeFile("fleet/drive.json", `{
+		"local_fragments": ["base.json"],
+		"components": [{"name": "motor2", "type": "motor", "model": "fake", "attributes": {"max_rpm": 100}}]
+	}`)
+	robotPath := writeFile("robot.json", `{
+		"local_fragments": ["fleet/drive.json"],
+		"components": [
+			{"name": "motor1", "attributes": {"max_rpm": 200, "direction_flip": null}},
+			{"name": "motor3", "type": "motor", "model": "fake"}
+		]
+	}`)
+
+	cfg, err := config.ReadLocalConfig(context.Background(), robotPath, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cfg.Network.BindAddress, test.ShouldEqual, ":8081")
+
+	var names []string
+	for _, conf := range cfg.Components {
+		names = append(names, conf.Name)
+	}
+	test.That(t, names, test.ShouldResemble, []string{"board1", "motor1", "motor2", "motor3"})
+	test.That(t, cfg.Components[1].ConvertedAttributes, test.ShouldResemble, &fakemotor.Config{
+		BoardName: "board1",
+		MaxRPM:    200,
+	})
+
+	t.Run("fragment including itself", func(t *testing.T) {
+		writeFile("fleet/base.json", `{"local_fragments": ["drive.json"]}`)
+		_, err := config.ReadLocalConfig(context.Background(), robotPath, logger)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "includes itself")
+	})
+
+	t.Run("missing fragment", func(t *testing.T) {
+		missingPath := writeFile("missing.json", `{"local_fragments": ["fleet/missing.json"]}`)
+		_, err := config.ReadLocalConfig(context.Background(), missingPath, logger)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "failed to read local fragment")
+	})
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+// LoggingConfig describes where viam-server writes its logs, in addition to stdout and the cloud.
+// Outputs take effect when viam-server starts.
+type LoggingConfig struct {
+	Outputs []LogOutputConfig `json:"outputs,omitempty"`
+}
+
+// Log output types.
+const (
+	LogOutputFile   = "file"
+	LogOutputSyslog = "syslog"
+	LogOutputHTTPS  = "https"
+)
+
+// LogOutputConfig describes a single log output.
+type LogOutputConfig struct {
+	Type string `json:"type"`
+
+	// Path is the file written by a file output. It is rotated once larger than MaxSizeMB or older
+	// than MaxAgeHours, and only MaxBackups rotated files are kept.
+	Path        string  `json:"path,omitempty"`
+	MaxSizeMB   float64 `json:"max_size_mb,omitempty"`
+	MaxAgeHours float64 `json:"max_age_hours,omitempty"`
+	MaxBackups  int     `json:"max_backups,omitempty"`
+
+	// Address is the syslog server logged to over Network, which is udp or tcp.
+	Network string `json:"network,omitempty"`
+	Address string `json:"address,omitempty"`
+
+	// URL is the endpoint batches of logs are POSTed to, with Headers.
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// MaxBufferedEntries bounds how many entries a syslog or https output keeps while offline.
+	MaxBufferedEntries int `json:"max_buffered_entries,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (c LogOutputConfig) Validate(path string) error {
+	if c.MaxBufferedEntries < 0 {
+		return resource.NewConfigValidationError(path, errors.New("max_buffered_entries must not be negative"))
+	}
+	switch c.Type {
+	case LogOutputFile:
+		if c.Path == "" {
+			return resource.NewConfigValidationFieldRequiredError(path, "path")
+		}
+		if c.MaxSizeMB < 0 || c.MaxAgeHours < 0 || c.MaxBackups < 0 {
+			return resource.NewConfigValidationError(path, errors.New("rotation limits must not be negative"))
+		}
+	case LogOutputSyslog:
+		if c.Address == "" {
+			return resource.NewConfigValidationFieldRequiredError(path, "address")
+		}
+		if c.Network != "" && c.Network != "udp" && c.Network != "tcp" {
+			return resource.NewConfigValidationError(path, errors.Errorf("network must be udp or tcp, not %q", c.Network))
+		}
+	case LogOutputHTTPS:
+		if c.URL == "" {
+			return resource.NewConfigValidationFieldRequiredError(path, "url")
+		}
+		u, err := url.Parse(c.URL)
+		if err != nil {
+			return resource.NewConfigValidationError(path, err)
+		}
+		if u.Scheme != "https" && u.Scheme != "http" {
+			return resource.NewConfigValidationError(path, errors.Errorf("url %q must be http or https", c.URL))
+		}
+	default:
+		return resource.NewConfigValidationError(path,
+			errors.Errorf("unknown log output type %q, must be one of file, syslog, or https", c.Type))
+	}
+	return nil
+}
+
+// A LogOutput is an appender that must be closed once no longer logged to.
+type LogOutput interface {
+	logging.Appender
+	Close()
+}
+
+// NewLogOutput creates the appender described by c.
+func (c LogOutputConfig) NewLogOutput() (LogOutput, error) {
+	switch c.Type {
+	case LogOutputFile:
+		appender, err := logging.NewRotatingFileAppender(
+			c.Path,
+			int64(c.MaxSizeMB*1024*1024),
+			time.Duration(c.MaxAgeHours*float64(time.Hour)),
+			c.MaxBackups,
+		)
+		if err != nil {
+			return nil, err
+		}
+		return appender, nil
+	case LogOutputSyslog:
+		network := c.Network
+		if network == "" {
+			network = "udp"
+		}
+		appender, err := logging.NewSyslogAppender(network, c.Address, "viam-server", c.MaxBufferedEntries)
+		if err != nil {
+			return nil, err
+		}
+		return appender, nil
+	case LogOutputHTTPS:
+		return logging.NewHTTPSAppender(c.URL, c.Headers, c.MaxBufferedEntries), nil
+	default:
+		return nil, errors.Errorf("unknown log output type %q", c.Type)
+	}
+}
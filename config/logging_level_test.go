@@ -29,3 +29,27 @@ func TestConfigDebugFlag(t *testing.T) {
 		}
 	}
 }
+
+func TestLogOutputConfigValidate(t *testing.T) {
+	for _, conf := range []LogOutputConfig{
+		{},
+		{Type: "kafka"},
+		{Type: LogOutputFile},
+		{Type: LogOutputFile, Path: "/var/log/viam.log", MaxBackups: -1},
+		{Type: LogOutputSyslog},
+		{Type: LogOutputSyslog, Address: "logs:514", Network: "unix"},
+		{Type: LogOutputHTTPS},
+		{Type: LogOutputHTTPS, URL: "ftp://logs.example.com"},
+		{Type: LogOutputHTTPS, URL: "https://logs.example.com", MaxBufferedEntries: -1},
+	} {
+		test.That(t, conf.Validate("logging.outputs.0"), test.ShouldNotBeNil)
+	}
+
+	for _, conf := range []LogOutputConfig{
+		{Type: LogOutputFile, Path: "/var/log/viam.log", MaxSizeMB: 10, MaxBackups: 5},
+		{Type: LogOutputSyslog, Address: "logs:514"},
+		{Type: LogOutputHTTPS, URL: "https://logs.example.com/ingest", Headers: map[string]string{"Authorization": "Bearer x"}},
+	} {
+		test.That(t, conf.Validate("logging.outputs.0"), test.ShouldBeNil)
+	}
+}
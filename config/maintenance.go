@@ -0,0 +1,26 @@
+package config
+
+import (
+	"go.viam.com/rdk/resource"
+)
+
+// MaintenanceConfig holds back reconfiguration while the robot is busy, such as while an arm is holding a part,
+// so that config changes and module reloads cannot tear down resources in the middle of a task. Changes made
+// while the robot is busy are queued, and the latest of them is applied once the sensor reports that it is idle.
+type MaintenanceConfig struct {
+	// SensorName is the name of the sensor component which reports whether the robot may be reconfigured.
+	SensorName string `json:"sensor_name"`
+	// MaintenanceAllowedKey is the key of the sensor's readings whose value is true when the robot is idle.
+	MaintenanceAllowedKey string `json:"maintenance_allowed_key"`
+}
+
+// Validate the MaintenanceConfig.
+func (c *MaintenanceConfig) Validate(path string) error {
+	if c.SensorName == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "sensor_name")
+	}
+	if c.MaintenanceAllowedKey == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "maintenance_allowed_key")
+	}
+	return nil
+}
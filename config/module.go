@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -38,6 +39,8 @@ type Module struct {
 	// Environment contains additional variables that are passed to the module process when it is started.
 	// They overwrite existing environment variables.
 	Environment map[string]string `json:"env,omitempty"`
+	// Container, if set, runs the module inside a container instead of directly on the host.
+	Container *ModuleContainer `json:"container,omitempty"`
 
 	// Status refers to the validations done in the APP to make sure a module is configured correctly
 	Status           *AppValidationStatus `json:"status"`
@@ -53,6 +56,50 @@ type JSONManifest struct {
 	Entrypoint string `json:"entrypoint"`
 }
 
+// ModuleContainer configures a container for a module to run in, so that the module's native dependencies
+// are installed in its image rather than on the host. The module's files, data directory and socket are
+// mounted into the container at the same paths they have on the host.
+type ModuleContainer struct {
+	// Runtime is the container CLI to run the module with, either "podman" or "docker". If unset, podman is
+	// used if it's installed, otherwise docker.
+	Runtime string `json:"runtime,omitempty"`
+	// Image is the image to run the module in.
+	Image string `json:"image"`
+	// CPUs limits the number of CPUs the module can use, ex: 1.5.
+	CPUs float64 `json:"cpus,omitempty"`
+	// Memory limits the memory the module can use, in the runtime's format, ex: "512m".
+	Memory string `json:"memory,omitempty"`
+	// Devices are host devices made available to the module, ex: "/dev/video0".
+	Devices []string `json:"devices,omitempty"`
+	// Mounts are additional bind mounts, each "host-path:container-path" with optional ":options".
+	Mounts []string `json:"mounts,omitempty"`
+	// Args are additional arguments to the runtime's run command.
+	Args []string `json:"args,omitempty"`
+}
+
+// ModuleContainerRuntimes are the container runtimes a module can run in, in order of preference.
+var ModuleContainerRuntimes = []string{"podman", "docker"}
+
+func (c *ModuleContainer) validate(path string) error {
+	if c.Image == "" {
+		return resource.NewConfigValidationFieldRequiredError(path+".container", "image")
+	}
+	if c.Runtime != "" && !slices.Contains(ModuleContainerRuntimes, c.Runtime) {
+		return resource.NewConfigValidationError(path,
+			errors.Errorf("container runtime %q must be one of %v", c.Runtime, ModuleContainerRuntimes))
+	}
+	if c.CPUs < 0 {
+		return resource.NewConfigValidationError(path, errors.New("container cpus cannot be negative"))
+	}
+	for _, mount := range c.Mounts {
+		if parts := strings.Split(mount, ":"); len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return resource.NewConfigValidationError(path,
+				errors.Errorf("container mount %q must be of the form host-path:container-path[:options]", mount))
+		}
+	}
+	return nil
+}
+
 // ModuleType indicates where a module comes from.
 type ModuleType string
 
@@ -96,6 +143,10 @@ func (m *Module) validate(path string) error {
 		return errors.Errorf("module %s cannot use the reserved name of %s", path, reservedModuleName)
 	}
 
+	if m.Container != nil {
+		return m.Container.validate(path)
+	}
+
 	return nil
 }
 
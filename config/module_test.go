@@ -112,3 +112,31 @@ func testWriteJSON(t *testing.T, path string, value any) {
 	err = encoder.Encode(value)
 	test.That(t, err, test.ShouldBeNil)
 }
+
+func TestModuleContainerValidate(t *testing.T) {
+	exePath := filepath.Join(t.TempDir(), "module")
+	test.That(t, os.WriteFile(exePath, nil, 0o700), test.ShouldBeNil)
+	mod := func(container *ModuleContainer) *Module {
+		return &Module{Name: "mod", Type: ModuleTypeLocal, ExePath: exePath, Container: container}
+	}
+
+	err := mod(&ModuleContainer{Image: "ghcr.io/acme/mod:1", Runtime: "docker", CPUs: 0.5, Mounts: []string{"/dev/shm:/dev/shm:ro"}}).
+		Validate("modules.0")
+	test.That(t, err, test.ShouldBeNil)
+
+	err = mod(&ModuleContainer{}).Validate("modules.0")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `Field: "image"`)
+
+	err = mod(&ModuleContainer{Image: "img", Runtime: "lxc"}).Validate("modules.0")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `container runtime "lxc" must be one of [podman docker]`)
+
+	err = mod(&ModuleContainer{Image: "img", CPUs: -1}).Validate("modules.0")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "container cpus cannot be negative")
+
+	err = mod(&ModuleContainer{Image: "img", Mounts: []string{"/data"}}).Validate("modules.0")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `container mount "/data" must be of the form`)
+}
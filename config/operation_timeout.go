@@ -0,0 +1,80 @@
+package config
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+)
+
+// OperationTimeoutConfig sets how long operations on all resources of an API may run before
+// they are cancelled, unless their caller asks for an earlier deadline.
+type OperationTimeoutConfig struct {
+	API resource.API `json:"api"`
+	// Timeout is a duration string such as "30s". It applies to every method without its own
+	// timeout below.
+	Timeout string `json:"timeout,omitempty"`
+	// ReadTimeout applies to methods which only read state, such as GetPosition or Readings.
+	ReadTimeout string `json:"read_timeout,omitempty"`
+	// ActuationTimeout applies to every other method, such as SetPower or DoCommand.
+	ActuationTimeout string `json:"actuation_timeout,omitempty"`
+}
+
+// Validate the OperationTimeoutConfig.
+func (c OperationTimeoutConfig) Validate(path string) error {
+	if err := c.API.Validate(); err != nil {
+		return resource.NewConfigValidationError(path, err)
+	}
+	if c.Timeout == "" && c.ReadTimeout == "" && c.ActuationTimeout == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "timeout")
+	}
+	for field, value := range map[string]string{
+		"timeout":           c.Timeout,
+		"read_timeout":      c.ReadTimeout,
+		"actuation_timeout": c.ActuationTimeout,
+	} {
+		if value == "" {
+			continue
+		}
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return resource.NewConfigValidationError(path, errors.Wrapf(err, "invalid %s", field))
+		}
+		if timeout <= 0 {
+			return resource.NewConfigValidationError(path, errors.Errorf("%s must be positive", field))
+		}
+	}
+	return nil
+}
+
+// Duration returns the parsed timeout. Validate should be called before this.
+func (c OperationTimeoutConfig) Duration() time.Duration {
+	return parseOperationTimeout(c.Timeout)
+}
+
+// ReadDuration returns the parsed timeout of methods which only read state, falling back to
+// Duration. Validate should be called before this.
+func (c OperationTimeoutConfig) ReadDuration() time.Duration {
+	if c.ReadTimeout == "" {
+		return c.Duration()
+	}
+	return parseOperationTimeout(c.ReadTimeout)
+}
+
+// ActuationDuration returns the parsed timeout of methods which may actuate, falling back to
+// Duration. Validate should be called before this.
+func (c OperationTimeoutConfig) ActuationDuration() time.Duration {
+	if c.ActuationTimeout == "" {
+		return c.Duration()
+	}
+	return parseOperationTimeout(c.ActuationTimeout)
+}
+
+func parseOperationTimeout(timeout string) time.Duration {
+	parsed, err := time.ParseDuration(timeout)
+	if err != nil || parsed <= 0 {
+		return 0
+	}
+	return parsed
+}
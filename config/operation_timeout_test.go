@@ -0,0 +1,33 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/resource"
+)
+
+func TestOperationTimeoutConfig(t *testing.T) {
+	api := resource.APINamespaceRDK.WithComponentType("motor")
+
+	for _, conf := range []OperationTimeoutConfig{
+		{API: api},
+		{API: api, Timeout: "soon"},
+		{API: api, ReadTimeout: "-1s"},
+		{API: api, Timeout: "1s", ActuationTimeout: "0s"},
+	} {
+		test.That(t, conf.Validate("path"), test.ShouldNotBeNil)
+	}
+
+	conf := OperationTimeoutConfig{API: api, Timeout: "30s", ReadTimeout: "2s"}
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+	test.That(t, conf.ReadDuration(), test.ShouldEqual, 2*time.Second)
+	test.That(t, conf.ActuationDuration(), test.ShouldEqual, 30*time.Second)
+
+	conf = OperationTimeoutConfig{API: api, ActuationTimeout: "1m"}
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+	test.That(t, conf.ReadDuration(), test.ShouldEqual, time.Duration(0))
+	test.That(t, conf.ActuationDuration(), test.ShouldEqual, time.Minute)
+}
@@ -161,6 +161,8 @@ func (v *placeholderReplacementVisitor) replacePlaceholders(s string) (string, e
 			replacementResult, err = v.replacePackagePlaceholder(string(placeholderKey))
 		case environmentPlaceholderRegexp.Match(placeholderKey):
 			replacementResult, err = v.replaceEnvironmentPlaceholder(string(placeholderKey))
+		case secretPlaceholderRegexp.Match(placeholderKey):
+			replacementResult, err = v.replaceSecretPlaceholder(string(placeholderKey))
 		default:
 			err = errors.Errorf("invalid placeholder %q", string(placeholder))
 		}
@@ -196,6 +198,9 @@ func (v *placeholderReplacementVisitor) replacePackagePlaceholder(toReplace stri
 			errors.Errorf("placeholder %q is looking for a package of type %q but a package of type %q was found. Try %q",
 				toReplace, packageType, string(packageConfig.Type), expectedPlaceholder)
 	}
+	if packageConfig.URL != "" {
+		return packageConfig.LocalDataDirectory(LocalPackagesDir(viamPackagesDir)), nil
+	}
 	return packageConfig.LocalDataDirectory(viamPackagesDir), nil
 }
 
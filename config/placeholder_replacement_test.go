@@ -9,6 +9,7 @@ import (
 	"go.viam.com/test"
 
 	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/utils"
 )
@@ -204,4 +205,42 @@ func TestPlaceholderReplacement(t *testing.T) {
 		err = cfg.ReplacePlaceholders()
 		test.That(t, fmt.Sprint(err), test.ShouldContainSubstring, "VIAM_UNDEFINED_TEST_VAR")
 	})
+	t.Run("secret placeholder replacement", func(t *testing.T) {
+		t.Setenv("VIAM_TEST_NTRIP_PASSWORD", "envpassword")
+		secretFile := filepath.Join(t.TempDir(), "api_key")
+		test.That(t, os.WriteFile(secretFile, []byte("fileapikey\n"), 0o600), test.ShouldBeNil)
+
+		cfg := &config.Config{
+			Components: []resource.Config{
+				{
+					Attributes: utils.AttributeMap{
+						"ntrip_password": "${secrets.env.VIAM_TEST_NTRIP_PASSWORD}",
+						"api_key":        "${secrets.file." + secretFile + "}",
+					},
+				},
+			},
+		}
+		err := cfg.ReplacePlaceholders()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cfg.Components[0].Attributes["ntrip_password"], test.ShouldEqual, "envpassword")
+		test.That(t, cfg.Components[0].Attributes["api_key"], test.ShouldEqual, "fileapikey")
+		// resolved secrets are redacted from logs
+		test.That(t, logging.Redact("password is envpassword"), test.ShouldEqual, "password is "+logging.RedactedSecret)
+
+		cfg = &config.Config{
+			Components: []resource.Config{
+				{
+					Attributes: utils.AttributeMap{
+						"a": "${secrets.env.VIAM_UNDEFINED_TEST_VAR}",
+						"b": "${secrets.keyring.no_account}",
+						"c": "${secrets.vault.whatever}",
+					},
+				},
+			},
+		}
+		err = cfg.ReplacePlaceholders()
+		test.That(t, fmt.Sprint(err), test.ShouldContainSubstring, "VIAM_UNDEFINED_TEST_VAR")
+		test.That(t, fmt.Sprint(err), test.ShouldContainSubstring, "<service>/<account>")
+		test.That(t, fmt.Sprint(err), test.ShouldContainSubstring, `invalid placeholder "${secrets.vault.whatever}"`)
+	})
 }
@@ -13,7 +13,6 @@ import (
 	"runtime"
 	"time"
 
-	"github.com/a8m/envsubst"
 	"github.com/pkg/errors"
 	apppb "go.viam.com/api/app/v1"
 	"go.viam.com/utils"
@@ -107,6 +106,13 @@ func readFromCache(id string) (*Config, error) {
 		clearCache(id)
 		return nil, errors.Wrap(err, "cannot parse the cached config as json")
 	}
+	if cloud := unprocessedConfig.Cloud; cloud != nil && cloud.SecureElement != nil && cloud.TLSCertificate != "" {
+		privateKey, err := cloud.SecureElement.unseal(sealedTLSPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		cloud.TLSPrivateKey = string(privateKey)
+	}
 	return unprocessedConfig, nil
 }
 
@@ -115,6 +121,21 @@ func storeToCache(id string, cfg *Config) error {
 		return err
 	}
 
+	if cfg.Cloud != nil && cfg.Cloud.SecureElement != nil {
+		// only the certificate is cached in the clear, and its private key is sealed to the secure element
+		if cfg.Cloud.TLSPrivateKey != "" {
+			if err := cfg.Cloud.SecureElement.seal(sealedTLSPrivateKey, []byte(cfg.Cloud.TLSPrivateKey)); err != nil {
+				return err
+			}
+		}
+		sealedCfg := *cfg
+		sealedCloud := *cfg.Cloud
+		sealedCloud.Secret = ""
+		sealedCloud.TLSPrivateKey = ""
+		sealedCfg.Cloud = &sealedCloud
+		cfg = &sealedCfg
+	}
+
 	md, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
 		return err
@@ -313,6 +334,7 @@ func readFromCloud(
 	mergeCloudConfig(cfg)
 	unprocessedConfig.Cloud.TLSCertificate = tls.certificate
 	unprocessedConfig.Cloud.TLSPrivateKey = tls.privateKey
+	unprocessedConfig.Cloud.SecureElement = cloudCfg.SecureElement
 
 	if err := storeToCache(cloudCfg.ID, unprocessedConfig); err != nil {
 		logger.Errorw("failed to cache config", "error", err)
@@ -352,13 +374,14 @@ func (tls *tlsConfig) readFromCache(id string, logger logging.Logger) error {
 	return nil
 }
 
-// Read reads a config from the given file.
+// Read reads a config from the given file, or from stdin or an environment variable as described by
+// readConfigBytes.
 func Read(
 	ctx context.Context,
 	filePath string,
 	logger logging.Logger,
 ) (*Config, error) {
-	buf, err := envsubst.ReadFile(filePath)
+	buf, err := readConfigBytes(filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -372,7 +395,7 @@ func ReadLocalConfig(
 	filePath string,
 	logger logging.Logger,
 ) (*Config, error) {
-	buf, err := envsubst.ReadFile(filePath)
+	buf, err := readConfigBytes(filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -404,10 +427,23 @@ func fromReader(
 	unprocessedConfig := Config{
 		ConfigFilePath: originalPath,
 	}
-	err := json.NewDecoder(r).Decode(&unprocessedConfig)
+	rd, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read Config")
+	}
+	rd, _, err = mergeLocalFragments(rd, originalPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode Config from json")
+	}
+	err = json.Unmarshal(rd, &unprocessedConfig)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to decode Config from json")
 	}
+	if unprocessedConfig.Cloud != nil && unprocessedConfig.Cloud.SecureElement != nil {
+		if err := unprocessedConfig.Cloud.loadSealedSecret(logger); err != nil {
+			return nil, errors.Wrap(err, "failed to load cloud secret from secure element")
+		}
+	}
 	cfgFromDisk, err := processConfigLocalConfig(&unprocessedConfig, logger)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to process Config")
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+)
+
+// secretPlaceholderRegexp matches on all valid ways of referencing a secret, so that credentials such as NTRIP
+// passwords and API keys need not be written into attributes. Example strings satisfying the regex:
+// secrets.env.NTRIP_PASSWORD
+// secrets.file./run/secrets/ntrip_password
+// secrets.keyring.viam/ntrip.
+var secretPlaceholderRegexp = regexp.MustCompile(`^secrets\.(?P<source>env|file|keyring)\.(?P<ref>.+)$`)
+
+func (v *placeholderReplacementVisitor) replaceSecretPlaceholder(toReplace string) (string, error) {
+	matches := secretPlaceholderRegexp.FindStringSubmatch(toReplace)
+	if matches == nil {
+		return toReplace, errors.Errorf("failed to find substring matches for %q", toReplace)
+	}
+	ref := matches[secretPlaceholderRegexp.SubexpIndex("ref")]
+
+	var (
+		secret string
+		err    error
+	)
+	switch source := matches[secretPlaceholderRegexp.SubexpIndex("source")]; source {
+	case "env":
+		var present bool
+		secret, present = os.LookupEnv(ref)
+		if !present {
+			err = errors.Errorf("no environment variable named %q", ref)
+		}
+	case "file":
+		var contents []byte
+		//nolint:gosec
+		contents, err = os.ReadFile(ref)
+		secret = strings.TrimRight(string(contents), "\r\n")
+	case "keyring":
+		secret, err = readKeyringSecret(ref)
+	default:
+		err = errors.Errorf("unknown secret source %q", source)
+	}
+	if err != nil {
+		return toReplace, errors.Wrapf(err, "failed to resolve secret for placeholder %q", toReplace)
+	}
+	logging.RegisterSecret(secret)
+	return secret, nil
+}
+
+// readKeyringSecret reads the password stored in the OS keyring for ref, given as <service>/<account>, using
+// secret-tool on Linux and security on macOS.
+func readKeyringSecret(ref string) (string, error) {
+	service, account, ok := strings.Cut(ref, "/")
+	if !ok || service == "" || account == "" {
+		return "", errors.Errorf("keyring secret %q must be given as <service>/<account>", ref)
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", service, "account", account)
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w")
+	default:
+		return "", errors.Errorf("keyring secrets are not supported on %s", runtime.GOOS)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read keyring secret %q", ref)
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
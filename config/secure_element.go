@@ -0,0 +1,145 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+// SecureElementTypeTPM2 keeps the machine's credentials sealed to its TPM 2.0, using tpm2-tools.
+const SecureElementTypeTPM2 = "tpm2"
+
+const (
+	sealedCloudSecret   = "cloud_secret"
+	sealedTLSPrivateKey = "tls_private_key"
+)
+
+// SecureElementConfig describes a secure element which the machine's cloud secret and TLS private key are sealed to,
+// so that they are never written out in the clear and cannot be read from storage which is taken off the machine,
+// such as a stolen SD card.
+type SecureElementConfig struct {
+	Type string `json:"type"`
+	// Device is the element's device, such as /dev/tpmrm0 for a TPM.
+	Device string `json:"device,omitempty"`
+	// Dir is where sealed credentials are kept. Defaults to a directory under ViamDotDir.
+	Dir string `json:"dir,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *SecureElementConfig) Validate(path string) error {
+	if conf.Type == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "type")
+	}
+	if _, ok := secureElements[conf.Type]; !ok {
+		return resource.NewConfigValidationError(path, errors.Errorf("unknown secure element type %q", conf.Type))
+	}
+	return nil
+}
+
+func (conf *SecureElementConfig) dir() string {
+	if conf.Dir != "" {
+		return conf.Dir
+	}
+	return filepath.Join(ViamDotDir, "sealed")
+}
+
+// A secureElement seals small keys, such that they can only be unsealed by the same element.
+type secureElement interface {
+	sealKey(name string, key []byte) error
+	unsealKey(name string) ([]byte, error)
+}
+
+// secureElements are the constructors of each type of secure element.
+var secureElements = map[string]func(conf *SecureElementConfig) secureElement{
+	SecureElementTypeTPM2: newTPM2SecureElement,
+}
+
+// seal encrypts secret with a new key which is sealed to the secure element, and stores the encrypted secret as name.
+// Elements can only seal a few bytes, so credentials themselves are never handed to them.
+func (conf *SecureElementConfig) seal(name string, secret []byte) error {
+	if err := os.MkdirAll(conf.dir(), 0o700); err != nil {
+		return err
+	}
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	if err := secureElements[conf.Type](conf).sealKey(name, key); err != nil {
+		return errors.Wrapf(err, "failed to seal %s to %s secure element", name, conf.Type)
+	}
+	encrypted := gcm.Seal(nonce, nonce, secret, []byte(name))
+	return os.WriteFile(filepath.Join(conf.dir(), name+".enc"), encrypted, 0o600)
+}
+
+// unseal decrypts the secret stored as name with the key sealed to the secure element.
+func (conf *SecureElementConfig) unseal(name string) ([]byte, error) {
+	//nolint:gosec
+	encrypted, err := os.ReadFile(filepath.Join(conf.dir(), name+".enc"))
+	if err != nil {
+		return nil, err
+	}
+	key, err := secureElements[conf.Type](conf).unsealKey(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unseal %s from %s secure element", name, conf.Type)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(encrypted) < gcm.NonceSize() {
+		return nil, errors.Errorf("sealed %s is corrupt", name)
+	}
+	nonce, encrypted := encrypted[:gcm.NonceSize()], encrypted[gcm.NonceSize():]
+	secret, err := gcm.Open(nil, nonce, encrypted, []byte(name))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decrypt sealed %s", name)
+	}
+	return secret, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// loadSealedSecret fills in the cloud secret from the secure element. A secret which is still in the config is
+// sealed in its place, so that it can be removed from the config.
+func (config *Cloud) loadSealedSecret(logger logging.Logger) error {
+	if err := config.SecureElement.Validate("cloud.secure_element"); err != nil {
+		return err
+	}
+	if config.Secret != "" {
+		if err := config.SecureElement.seal(sealedCloudSecret, []byte(config.Secret)); err != nil {
+			return err
+		}
+		logger.Warn("the cloud secret has been sealed to the secure element; remove it from the config file " +
+			"so that it cannot be read off of the machine's storage")
+		return nil
+	}
+	secret, err := config.SecureElement.unseal(sealedCloudSecret)
+	if err != nil {
+		return errors.Wrap(err, "the cloud secret must be in the config until it has been sealed")
+	}
+	config.Secret = string(secret)
+	logging.RegisterSecret(config.Secret)
+	return nil
+}
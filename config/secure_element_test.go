@@ -0,0 +1,99 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+)
+
+// fakeSecureElement keeps sealed keys in memory, like a secure element which never gives up its contents.
+type fakeSecureElement struct {
+	keys map[string][]byte
+}
+
+func (se *fakeSecureElement) sealKey(name string, key []byte) error {
+	se.keys[name] = key
+	return nil
+}
+
+func (se *fakeSecureElement) unsealKey(name string) ([]byte, error) {
+	key, ok := se.keys[name]
+	if !ok {
+		return nil, errors.Errorf("nothing sealed as %q", name)
+	}
+	return key, nil
+}
+
+func TestSecureElement(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	element := &fakeSecureElement{keys: map[string][]byte{}}
+	secureElements["fake"] = func(conf *SecureElementConfig) secureElement {
+		return element
+	}
+	defer delete(secureElements, "fake")
+
+	seConf := &SecureElementConfig{Type: "fake", Dir: t.TempDir()}
+	test.That(t, (&SecureElementConfig{Type: "atecc"}).Validate("cloud.secure_element"), test.ShouldNotBeNil)
+	test.That(t, seConf.Validate("cloud.secure_element"), test.ShouldBeNil)
+
+	t.Run("cloud secret", func(t *testing.T) {
+		readConfig := func(secret string) (*Config, error) {
+			return fromReader(context.Background(), "", strings.NewReader(`{"cloud": {
+				"id": "part-id", "secret": "`+secret+`", "app_address": "https://app.viam.com:443",
+				"secure_element": {"type": "fake", "dir": "`+seConf.Dir+`"}}}`), logger, false)
+		}
+
+		// nothing has been sealed yet
+		_, err := readConfig("")
+		test.That(t, err, test.ShouldNotBeNil)
+
+		cfg, err := readConfig("part-secret")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cfg.Cloud.Secret, test.ShouldEqual, "part-secret")
+		encrypted, err := os.ReadFile(filepath.Join(seConf.Dir, sealedCloudSecret+".enc"))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, string(encrypted), test.ShouldNotContainSubstring, "part-secret")
+
+		// once sealed, the secret can be left out of the config
+		cfg, err = readConfig("")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cfg.Cloud.Secret, test.ShouldEqual, "part-secret")
+
+		// sealed secrets cannot be recovered without the element
+		delete(element.keys, sealedCloudSecret)
+		_, err = readConfig("")
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("cached tls private key", func(t *testing.T) {
+		id := uuid.New().String()
+		defer clearCache(id)
+		cfg := &Config{Cloud: &Cloud{
+			ID:             id,
+			Secret:         "part-secret",
+			SecureElement:  seConf,
+			TLSCertificate: "cert",
+			TLSPrivateKey:  "private-key",
+		}}
+		test.That(t, storeToCache(id, cfg), test.ShouldBeNil)
+		test.That(t, cfg.Cloud.TLSPrivateKey, test.ShouldEqual, "private-key")
+
+		cached, err := os.ReadFile(getCloudCacheFilePath(id))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, string(cached), test.ShouldNotContainSubstring, "private-key")
+		test.That(t, string(cached), test.ShouldNotContainSubstring, "part-secret")
+
+		cachedCfg, err := readFromCache(id)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cachedCfg.Cloud.TLSCertificate, test.ShouldEqual, "cert")
+		test.That(t, cachedCfg.Cloud.TLSPrivateKey, test.ShouldEqual, "private-key")
+	})
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// tpm2SecureElement seals keys to the TPM's storage hierarchy with tpm2-tools. The sealed blobs are only usable
+// with the TPM which created them, so they are kept alongside the encrypted credentials.
+type tpm2SecureElement struct {
+	conf *SecureElementConfig
+}
+
+func newTPM2SecureElement(conf *SecureElementConfig) secureElement {
+	return &tpm2SecureElement{conf: conf}
+}
+
+// run runs a tpm2-tools command against the configured device.
+func (tpm *tpm2SecureElement) run(stdin []byte, name string, args ...string) ([]byte, error) {
+	if tpm.conf.Device != "" {
+		args = append([]string{"--tcti", "device:" + tpm.conf.Device}, args...)
+	}
+	cmd := exec.Command(name, args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s failed: %s", name, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return out, nil
+}
+
+// withPrimary runs f with the context of the TPM's storage primary key. The key is derived from the TPM's seed each
+// time, so it is never stored.
+func (tpm *tpm2SecureElement) withPrimary(f func(tmpDir, primaryCtx string) error) error {
+	tmpDir, err := os.MkdirTemp("", "viam-tpm2")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		//nolint:errcheck
+		os.RemoveAll(tmpDir)
+	}()
+	primaryCtx := filepath.Join(tmpDir, "primary.ctx")
+	if _, err := tpm.run(nil, "tpm2_createprimary", "-Q", "-C", "o", "-c", primaryCtx); err != nil {
+		return err
+	}
+	return f(tmpDir, primaryCtx)
+}
+
+func (tpm *tpm2SecureElement) blobPaths(name string) (string, string) {
+	return filepath.Join(tpm.conf.dir(), name+".pub"), filepath.Join(tpm.conf.dir(), name+".priv")
+}
+
+func (tpm *tpm2SecureElement) sealKey(name string, key []byte) error {
+	pub, priv := tpm.blobPaths(name)
+	return tpm.withPrimary(func(_, primaryCtx string) error {
+		_, err := tpm.run(key, "tpm2_create", "-Q", "-C", primaryCtx, "-i", "-", "-u", pub, "-r", priv)
+		return err
+	})
+}
+
+func (tpm *tpm2SecureElement) unsealKey(name string) ([]byte, error) {
+	pub, priv := tpm.blobPaths(name)
+	var key []byte
+	err := tpm.withPrimary(func(tmpDir, primaryCtx string) error {
+		keyCtx := filepath.Join(tmpDir, name+".ctx")
+		if _, err := tpm.run(nil, "tpm2_load", "-Q", "-C", primaryCtx, "-u", pub, "-r", priv, "-c", keyCtx); err != nil {
+			return err
+		}
+		var err error
+		key, err = tpm.run(nil, "tpm2_unseal", "-c", keyCtx)
+		return err
+	})
+	return key, err
+}
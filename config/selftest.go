@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+)
+
+// DefaultSelfTestTimeout is how long each resource's self-test may run by default.
+const DefaultSelfTestTimeout = 30 * time.Second
+
+// SelfTestConfig describes the self-tests run on the robot's resources as a pre-mission checklist, such as
+// twitching each motor and waiting for a GPS fix.
+type SelfTestConfig struct {
+	// RunOnStartup runs the self-tests once the robot has first been configured, logging any which fail.
+	RunOnStartup bool `json:"run_on_startup,omitempty"`
+	// Actuate lets the startup self-tests move components, such as twitching motors and opening grippers. Those
+	// self-tests are skipped otherwise, since the robot may not be clear of obstacles when it starts.
+	Actuate bool `json:"actuate,omitempty"`
+	// Timeout is a duration string such as "30s" bounding each resource's self-test.
+	Timeout string `json:"timeout,omitempty"`
+	// Resources are the resources to test. When empty, every resource which has a self-test is tested.
+	Resources []ResourceSelfTestConfig `json:"resources,omitempty"`
+}
+
+// ResourceSelfTestConfig describes the self-test of one resource.
+type ResourceSelfTestConfig struct {
+	// Name is the name of the resource to test.
+	Name string `json:"name"`
+	// Timeout overrides the SelfTestConfig's Timeout for this resource, such as to wait longer for a GPS fix.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// Validate the SelfTestConfig.
+func (c *SelfTestConfig) Validate(path string) error {
+	if err := validateShutdownTimeout(c.Timeout); err != nil {
+		return resource.NewConfigValidationError(path, errors.Wrap(err, "invalid timeout"))
+	}
+	seen := map[string]bool{}
+	for idx, res := range c.Resources {
+		resPath := fmt.Sprintf("%s.resources.%d", path, idx)
+		if res.Name == "" {
+			return resource.NewConfigValidationFieldRequiredError(resPath, "name")
+		}
+		if seen[res.Name] {
+			return resource.NewConfigValidationError(resPath, errors.Errorf("duplicate self-test for %q", res.Name))
+		}
+		seen[res.Name] = true
+		if err := validateShutdownTimeout(res.Timeout); err != nil {
+			return resource.NewConfigValidationError(resPath, errors.Wrap(err, "invalid timeout"))
+		}
+	}
+	return nil
+}
+
+// Tests returns whether the named resource is one the config tests. It is safe to call on a nil SelfTestConfig,
+// which tests every resource which has a self-test.
+func (c *SelfTestConfig) Tests(name resource.Name) bool {
+	if c == nil || len(c.Resources) == 0 {
+		return true
+	}
+	for _, res := range c.Resources {
+		if res.Name == name.ShortName() {
+			return true
+		}
+	}
+	return false
+}
+
+// TimeoutFor returns how long the named resource's self-test may run. It is safe to call on a nil
+// SelfTestConfig.
+func (c *SelfTestConfig) TimeoutFor(name resource.Name) time.Duration {
+	if c == nil {
+		return DefaultSelfTestTimeout
+	}
+	for _, res := range c.Resources {
+		if res.Name == name.ShortName() && res.Timeout != "" {
+			return parseShutdownTimeout(res.Timeout, DefaultSelfTestTimeout)
+		}
+	}
+	return parseShutdownTimeout(c.Timeout, DefaultSelfTestTimeout)
+}
+
+// Actuates returns whether the startup self-tests may move components. It is safe to call on a nil
+// SelfTestConfig.
+func (c *SelfTestConfig) Actuates() bool {
+	return c != nil && c.Actuate
+}
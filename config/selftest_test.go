@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/resource"
+)
+
+func TestSelfTestConfig(t *testing.T) {
+	motor := resource.NewName(resource.APINamespaceRDK.WithComponentType("motor"), "motor1")
+	gps := resource.NewName(resource.APINamespaceRDK.WithComponentType("movement_sensor"), "gps1")
+	camera := resource.NewName(resource.APINamespaceRDK.WithComponentType("camera"), "camera1")
+
+	var conf *SelfTestConfig
+	test.That(t, conf.Tests(motor), test.ShouldBeTrue)
+	test.That(t, conf.TimeoutFor(motor), test.ShouldEqual, DefaultSelfTestTimeout)
+
+	conf = &SelfTestConfig{
+		Timeout:   "10s",
+		Resources: []ResourceSelfTestConfig{{Name: "motor1"}, {Name: "gps1", Timeout: "2m"}},
+	}
+	test.That(t, conf.Validate("self_test"), test.ShouldBeNil)
+	test.That(t, conf.Tests(motor), test.ShouldBeTrue)
+	test.That(t, conf.Tests(gps), test.ShouldBeTrue)
+	test.That(t, conf.Tests(camera), test.ShouldBeFalse)
+	test.That(t, conf.TimeoutFor(motor), test.ShouldEqual, 10*time.Second)
+	test.That(t, conf.TimeoutFor(gps), test.ShouldEqual, 2*time.Minute)
+
+	for _, conf := range []*SelfTestConfig{
+		{Timeout: "soon"},
+		{Resources: []ResourceSelfTestConfig{{Timeout: "1s"}}},
+		{Resources: []ResourceSelfTestConfig{{Name: "motor1"}, {Name: "motor1"}}},
+		{Resources: []ResourceSelfTestConfig{{Name: "gps1", Timeout: "0s"}}},
+	} {
+		test.That(t, conf.Validate("self_test"), test.ShouldNotBeNil)
+	}
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+)
+
+const (
+	// DefaultPreShutdownTimeout is how long each resource's pre-shutdown hook may run by default.
+	DefaultPreShutdownTimeout = 10 * time.Second
+	// DefaultResourceCloseTimeout is how long each resource may take to close by default.
+	DefaultResourceCloseTimeout = 30 * time.Second
+)
+
+// ShutdownConfig bounds how long resources may take to prepare for shutdown and to close, so that one
+// which hangs cannot keep the robot from shutting down or reconfiguring.
+type ShutdownConfig struct {
+	// PreShutdownTimeout is a duration string such as "10s" bounding each resource's pre-shutdown hook.
+	PreShutdownTimeout string `json:"pre_shutdown_timeout,omitempty"`
+	// CloseTimeout is a duration string such as "30s" bounding how long each resource may take to close.
+	CloseTimeout string `json:"close_timeout,omitempty"`
+	// ResourceCloseTimeouts overrides CloseTimeout for the resources with the given names.
+	ResourceCloseTimeouts map[string]string `json:"resource_close_timeouts,omitempty"`
+}
+
+// Validate the ShutdownConfig.
+func (c *ShutdownConfig) Validate(path string) error {
+	if err := validateShutdownTimeout(c.PreShutdownTimeout); err != nil {
+		return resource.NewConfigValidationError(path, errors.Wrap(err, "invalid pre_shutdown_timeout"))
+	}
+	if err := validateShutdownTimeout(c.CloseTimeout); err != nil {
+		return resource.NewConfigValidationError(path, errors.Wrap(err, "invalid close_timeout"))
+	}
+	for name, timeout := range c.ResourceCloseTimeouts {
+		if err := validateShutdownTimeout(timeout); err != nil {
+			return resource.NewConfigValidationError(path, errors.Wrapf(err, "invalid close timeout for %q", name))
+		}
+	}
+	return nil
+}
+
+// PreShutdownDuration returns how long each resource's pre-shutdown hook may run. It is safe to call on a
+// nil ShutdownConfig.
+func (c *ShutdownConfig) PreShutdownDuration() time.Duration {
+	if c == nil {
+		return DefaultPreShutdownTimeout
+	}
+	return parseShutdownTimeout(c.PreShutdownTimeout, DefaultPreShutdownTimeout)
+}
+
+// CloseDuration returns how long the named resource may take to close. It is safe to call on a nil
+// ShutdownConfig.
+func (c *ShutdownConfig) CloseDuration(name resource.Name) time.Duration {
+	if c == nil {
+		return DefaultResourceCloseTimeout
+	}
+	if timeout, ok := c.ResourceCloseTimeouts[name.ShortName()]; ok {
+		return parseShutdownTimeout(timeout, DefaultResourceCloseTimeout)
+	}
+	return parseShutdownTimeout(c.CloseTimeout, DefaultResourceCloseTimeout)
+}
+
+func validateShutdownTimeout(timeout string) error {
+	if timeout == "" {
+		return nil
+	}
+	parsed, err := time.ParseDuration(timeout)
+	if err != nil {
+		return err
+	}
+	if parsed <= 0 {
+		return errors.New("must be positive")
+	}
+	return nil
+}
+
+func parseShutdownTimeout(timeout string, defaultTimeout time.Duration) time.Duration {
+	parsed, err := time.ParseDuration(timeout)
+	if err != nil || parsed <= 0 {
+		return defaultTimeout
+	}
+	return parsed
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/resource"
+)
+
+func TestShutdownConfig(t *testing.T) {
+	arm := resource.NewName(resource.APINamespaceRDK.WithComponentType("arm"), "arm1")
+	base := resource.NewName(resource.APINamespaceRDK.WithComponentType("base"), "base1")
+
+	var conf *ShutdownConfig
+	test.That(t, conf.PreShutdownDuration(), test.ShouldEqual, DefaultPreShutdownTimeout)
+	test.That(t, conf.CloseDuration(arm), test.ShouldEqual, DefaultResourceCloseTimeout)
+
+	conf = &ShutdownConfig{
+		PreShutdownTimeout:    "5s",
+		CloseTimeout:          "10s",
+		ResourceCloseTimeouts: map[string]string{"arm1": "1m"},
+	}
+	test.That(t, conf.Validate("shutdown"), test.ShouldBeNil)
+	test.That(t, conf.PreShutdownDuration(), test.ShouldEqual, 5*time.Second)
+	test.That(t, conf.CloseDuration(arm), test.ShouldEqual, time.Minute)
+	test.That(t, conf.CloseDuration(base), test.ShouldEqual, 10*time.Second)
+
+	for _, conf := range []*ShutdownConfig{
+		{PreShutdownTimeout: "soon"},
+		{CloseTimeout: "-1s"},
+		{ResourceCloseTimeouts: map[string]string{"arm1": "0s"}},
+	} {
+		test.That(t, conf.Validate("shutdown"), test.ShouldNotBeNil)
+	}
+}
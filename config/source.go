@@ -0,0 +1,97 @@
+package config
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/a8m/envsubst"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+)
+
+const (
+	// ConfigEnvVar is the environment variable the config is read from when no config path is given.
+	ConfigEnvVar = "VIAM_CONFIG"
+	// StdinConfigPath is the config path that reads the config from stdin.
+	StdinConfigPath = "-"
+	// EnvConfigPathPrefix prefixes config paths naming an environment variable to read the config
+	// from, such as "env:VIAM_CONFIG".
+	EnvConfigPathPrefix = "env:"
+
+	secretFileEnvSuffix = "_FILE"
+)
+
+// DefaultConfigPath returns the config path to use when none is given: the ConfigEnvVar
+// environment variable if it is set, or else the empty string.
+func DefaultConfigPath() string {
+	if _, ok := os.LookupEnv(ConfigEnvVar); ok {
+		return EnvConfigPathPrefix + ConfigEnvVar
+	}
+	return ""
+}
+
+// IsFileConfigPath returns whether a config path names a file, rather than stdin or an environment
+// variable.
+func IsFileConfigPath(path string) bool {
+	return path != "" && path != StdinConfigPath && !strings.HasPrefix(path, EnvConfigPathPrefix)
+}
+
+var (
+	stdinOnce   sync.Once
+	stdinConfig []byte
+	errStdin    error
+)
+
+// readConfigBytes reads the config at path, which may also be StdinConfigPath or an environment
+// variable prefixed by EnvConfigPathPrefix, and substitutes environment variables into it.
+func readConfigBytes(path string) ([]byte, error) {
+	switch {
+	case path == StdinConfigPath:
+		// stdin can only be read once, but the config is read more than once on startup
+		stdinOnce.Do(func() {
+			stdinConfig, errStdin = io.ReadAll(os.Stdin)
+		})
+		if errStdin != nil {
+			return nil, errors.Wrap(errStdin, "failed to read config from stdin")
+		}
+		return envsubst.Bytes(stdinConfig)
+	case strings.HasPrefix(path, EnvConfigPathPrefix):
+		name := strings.TrimPrefix(path, EnvConfigPathPrefix)
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, errors.Errorf("environment variable %q to read config from is not set", name)
+		}
+		return envsubst.Bytes([]byte(raw))
+	default:
+		return envsubst.ReadFile(path)
+	}
+}
+
+// LoadSecretFiles sets each unset environment variable NAME for which NAME_FILE names a file, such
+// as a mounted Kubernetes or Docker secret, to that file's contents without trailing newlines. This
+// lets configs refer to those secrets as ${NAME} without them being written into the config.
+func LoadSecretFiles(logger logging.Logger) error {
+	for _, kv := range os.Environ() {
+		key, file, _ := strings.Cut(kv, "=")
+		name, ok := strings.CutSuffix(key, secretFileEnvSuffix)
+		if !ok || name == "" || file == "" {
+			continue
+		}
+		if _, set := os.LookupEnv(name); set {
+			logger.Debugw("environment variable already set; not reading it from its secret file", "name", name)
+			continue
+		}
+		//nolint:gosec
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read secret file for environment variable %s", name)
+		}
+		if err := os.Setenv(name, strings.TrimRight(string(contents), "\r\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+)
+
+func TestReadConfigBytesFromEnv(t *testing.T) {
+	t.Setenv(ConfigEnvVar, `{"components": [{"name": "${TEST_CONFIG_NAME}"}]}`)
+	t.Setenv("TEST_CONFIG_NAME", "arm1")
+
+	test.That(t, DefaultConfigPath(), test.ShouldEqual, "env:"+ConfigEnvVar)
+	test.That(t, IsFileConfigPath(DefaultConfigPath()), test.ShouldBeFalse)
+	test.That(t, IsFileConfigPath(StdinConfigPath), test.ShouldBeFalse)
+	test.That(t, IsFileConfigPath("robot.json"), test.ShouldBeTrue)
+
+	rd, err := readConfigBytes(DefaultConfigPath())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(rd), test.ShouldEqual, `{"components": [{"name": "arm1"}]}`)
+
+	_, err = readConfigBytes(EnvConfigPathPrefix + "TEST_CONFIG_UNSET")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "TEST_CONFIG_UNSET")
+}
+
+func TestLoadSecretFiles(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	secretPath := filepath.Join(t.TempDir(), "secret")
+	test.That(t, os.WriteFile(secretPath, []byte("hunter2\n"), 0o600), test.ShouldBeNil)
+
+	t.Setenv("TEST_SECRET_FILE", secretPath)
+	t.Setenv("TEST_SET_SECRET_FILE", secretPath)
+	t.Setenv("TEST_SET_SECRET", "already")
+	// make sure TEST_SECRET is unset, while restoring any value it had after the test
+	t.Setenv("TEST_SECRET", "")
+	test.That(t, os.Unsetenv("TEST_SECRET"), test.ShouldBeNil)
+
+	test.That(t, LoadSecretFiles(logger), test.ShouldBeNil)
+	test.That(t, os.Getenv("TEST_SECRET"), test.ShouldEqual, "hunter2")
+	test.That(t, os.Getenv("TEST_SET_SECRET"), test.ShouldEqual, "already")
+
+	t.Setenv("TEST_MISSING_FILE", filepath.Join(t.TempDir(), "missing"))
+	test.That(t, LoadSecretFiles(logger), test.ShouldNotBeNil)
+}
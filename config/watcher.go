@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/bep/debounce"
@@ -30,7 +32,8 @@ func NewWatcher(ctx context.Context, config *Config, logger logging.Logger) (Wat
 	if config.Cloud != nil {
 		return newCloudWatcher(ctx, config, logger), nil
 	}
-	if config.ConfigFilePath != "" {
+	// configs from stdin or an environment variable cannot change, so there is nothing to watch
+	if IsFileConfigPath(config.ConfigFilePath) {
 		return newFSWatcher(ctx, config.ConfigFilePath, logger)
 	}
 	return noopWatcher{}, nil
@@ -109,16 +112,19 @@ func (w *cloudWatcher) Close() error {
 	return nil
 }
 
-// A fsConfigWatcher fetches new configs from an underlying file when written to.
+// A fsConfigWatcher fetches new configs from an underlying file when it or one of its local fragments
+// is written to, or when the process receives SIGHUP.
 type fsConfigWatcher struct {
 	fsWatcher     *fsnotify.Watcher
+	hupCh         chan os.Signal
 	configCh      chan *Config
 	watcherDoneCh chan struct{}
 	cancel        func()
 }
 
 // newFSWatcher returns a new v that will fetch new configs
-// as soon as the underlying file is written to.
+// as soon as the underlying file is written to. SIGHUP forces a reload, which catches
+// changes that are not writes to the file, like a Kubernetes ConfigMap replacing it.
 func newFSWatcher(ctx context.Context, configPath string, logger logging.Logger) (*fsConfigWatcher, error) {
 	fsWatcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -127,10 +133,58 @@ func newFSWatcher(ctx context.Context, configPath string, logger logging.Logger)
 	if err := fsWatcher.Add(configPath); err != nil {
 		return nil, err
 	}
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
 	configCh := make(chan *Config)
 	watcherDoneCh := make(chan struct{})
 	cancelCtx, cancel := context.WithCancel(ctx)
+	// the local fragments the config is composed from are watched too, so that changing one reloads the config
+	watchFragments := func(rd []byte) ([]byte, error) {
+		rd, fragmentPaths, err := mergeLocalFragments(rd, configPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range fragmentPaths {
+			if err := fsWatcher.Add(path); err != nil {
+				logger.Warnw("failed to watch local config fragment for changes", "path", path, "error", err)
+			}
+		}
+		return rd, nil
+	}
+	//nolint:gosec
+	if rd, err := os.ReadFile(configPath); err == nil {
+		//nolint:errcheck
+		watchFragments(rd)
+	}
 	var lastRd []byte
+	reload := func() {
+		//nolint:gosec
+		rd, err := os.ReadFile(configPath)
+		if err != nil {
+			logger.Errorw("error reading config file", "error", err)
+			return
+		}
+		rd, err = watchFragments(rd)
+		if err != nil {
+			logger.Errorw("error reading local config fragments", "error", err)
+			return
+		}
+		if bytes.Equal(rd, lastRd) {
+			return
+		}
+		lastRd = rd
+		newConfig, err := FromReader(cancelCtx, configPath, bytes.NewReader(rd), logger)
+		if err != nil {
+			logger.Errorw("error reading config after change", "error", err)
+			return
+		}
+		UpdateFileConfigDebug(newConfig.Debug)
+		select {
+		case <-cancelCtx.Done():
+			return
+		case configCh <- newConfig:
+		}
+	}
 	utils.ManagedGo(func() {
 		debounced := debounce.New(time.Millisecond * 500)
 		for {
@@ -143,30 +197,15 @@ func newFSWatcher(ctx context.Context, configPath string, logger logging.Logger)
 			case event := <-fsWatcher.Events:
 				if event.Op&fsnotify.Write == fsnotify.Write {
 					debounced(func() {
-						logger.Info("On-disk config file changed. Reloading the config file.")
-						//nolint:gosec
-						rd, err := os.ReadFile(configPath)
-						if err != nil {
-							logger.Errorw("error reading config file after write", "error", err)
-							return
-						}
-						if bytes.Equal(rd, lastRd) {
-							return
-						}
-						lastRd = rd
-						newConfig, err := FromReader(cancelCtx, configPath, bytes.NewReader(rd), logger)
-						if err != nil {
-							logger.Errorw("error reading config after write", "error", err)
-							return
-						}
-						UpdateFileConfigDebug(newConfig.Debug)
-						select {
-						case <-cancelCtx.Done():
-							return
-						case configCh <- newConfig:
-						}
+						logger.Infow("On-disk config file changed. Reloading the config file.", "path", event.Name)
+						reload()
 					})
 				}
+			case <-hupCh:
+				debounced(func() {
+					logger.Info("Received SIGHUP. Reloading the config file.")
+					reload()
+				})
 			}
 		}
 	}, func() {
@@ -174,6 +213,7 @@ func newFSWatcher(ctx context.Context, configPath string, logger logging.Logger)
 	})
 	return &fsConfigWatcher{
 		fsWatcher:     fsWatcher,
+		hupCh:         hupCh,
 		configCh:      configCh,
 		watcherDoneCh: watcherDoneCh,
 		cancel:        cancel,
@@ -185,6 +225,7 @@ func (w *fsConfigWatcher) Config() <-chan *Config {
 }
 
 func (w *fsConfigWatcher) Close() error {
+	signal.Stop(w.hupCh)
 	w.cancel()
 	<-w.watcherDoneCh
 	return w.fsWatcher.Close()
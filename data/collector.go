@@ -75,6 +75,12 @@ type collector struct {
 	closeFinished    bool
 	target           datacapture.BufferedWriter
 	lastLoggedErrors map[string]int64
+	trigger          func(ctx context.Context) (bool, error)
+
+	// `spacingMu` guards lastCaptured, since captures may overlap when they take longer than the interval.
+	spacingMu         sync.Mutex
+	minCaptureSpacing time.Duration
+	lastCaptured      time.Time
 }
 
 // Close closes the channels backing the Collector. It should always be called before disposing of a Collector to avoid
@@ -215,6 +221,20 @@ func (c *collector) tickerBasedCapture(started chan struct{}) {
 }
 
 func (c *collector) getAndPushNextReading() {
+	if c.trigger != nil {
+		triggered, err := c.trigger(c.cancelCtx)
+		if err != nil {
+			c.captureErrors <- errors.Wrap(err, "error while checking capture trigger")
+			return
+		}
+		if !triggered {
+			return
+		}
+	}
+	if c.minCaptureSpacing > 0 && !c.reserveCapture() {
+		return
+	}
+
 	timeRequested := timestamppb.New(c.clock.Now().UTC())
 	reading, err := c.captureFunc(c.cancelCtx, c.params)
 	timeReceived := timestamppb.New(c.clock.Now().UTC())
@@ -280,6 +300,19 @@ func (c *collector) getAndPushNextReading() {
 	}
 }
 
+// reserveCapture returns whether enough time has passed since the last capture to make another, and if so
+// counts this one as the last capture.
+func (c *collector) reserveCapture() bool {
+	c.spacingMu.Lock()
+	defer c.spacingMu.Unlock()
+	now := c.clock.Now()
+	if !c.lastCaptured.IsZero() && now.Sub(c.lastCaptured) < c.minCaptureSpacing {
+		return false
+	}
+	c.lastCaptured = now
+	return true
+}
+
 // NewCollector returns a new Collector with the passed capturer and configuration options. It calls capturer at the
 // specified Interval, and appends the resulting reading to target.
 func NewCollector(captureFunc CaptureFunc, params CollectorParams) (Collector, error) {
@@ -295,17 +328,19 @@ func NewCollector(captureFunc CaptureFunc, params CollectorParams) (Collector, e
 		c = params.Clock
 	}
 	return &collector{
-		captureResults:   make(chan *v1.SensorData, params.QueueSize),
-		captureErrors:    make(chan error, params.QueueSize),
-		interval:         params.Interval,
-		params:           params.MethodParams,
-		logger:           params.Logger,
-		cancelCtx:        cancelCtx,
-		cancel:           cancelFunc,
-		captureFunc:      captureFunc,
-		target:           params.Target,
-		clock:            c,
-		lastLoggedErrors: make(map[string]int64, 0),
+		captureResults:    make(chan *v1.SensorData, params.QueueSize),
+		captureErrors:     make(chan error, params.QueueSize),
+		interval:          params.Interval,
+		params:            params.MethodParams,
+		logger:            params.Logger,
+		cancelCtx:         cancelCtx,
+		cancel:            cancelFunc,
+		captureFunc:       captureFunc,
+		target:            params.Target,
+		clock:             c,
+		lastLoggedErrors:  make(map[string]int64, 0),
+		trigger:           params.Trigger,
+		minCaptureSpacing: params.MinCaptureSpacing,
 	}, nil
 }
 
@@ -327,6 +327,48 @@ func TestLogErrorsOnlyOnce(t *testing.T) {
 	c.Close()
 }
 
+func TestTriggerAndCaptureSpacing(t *testing.T) {
+	var triggered bool
+	var triggerErr error
+	mockClock := clock.NewMock()
+	params := CollectorParams{
+		ComponentName: "testComponent",
+		Interval:      time.Millisecond * 5,
+		Target:        datacapture.NewBuffer(t.TempDir(), &v1.DataCaptureMetadata{}, 50),
+		QueueSize:     queueSize,
+		BufferSize:    bufferSize,
+		Logger:        logging.NewTestLogger(t),
+		Clock:         mockClock,
+		Trigger: func(ctx context.Context) (bool, error) {
+			return triggered, triggerErr
+		},
+		MinCaptureSpacing: time.Second,
+	}
+	c, err := NewCollector(structCapturer, params)
+	test.That(t, err, test.ShouldBeNil)
+	coll := c.(*collector)
+
+	// nothing is captured until the trigger fires
+	coll.getAndPushNextReading()
+	test.That(t, len(coll.captureResults), test.ShouldEqual, 0)
+
+	// untriggered checks don't count towards the spacing
+	triggered = true
+	coll.getAndPushNextReading()
+	test.That(t, len(coll.captureResults), test.ShouldEqual, 1)
+	coll.getAndPushNextReading()
+	test.That(t, len(coll.captureResults), test.ShouldEqual, 1)
+	mockClock.Add(time.Second)
+	coll.getAndPushNextReading()
+	test.That(t, len(coll.captureResults), test.ShouldEqual, 2)
+
+	triggerErr = errors.New("no detector")
+	mockClock.Add(time.Second)
+	coll.getAndPushNextReading()
+	test.That(t, len(coll.captureResults), test.ShouldEqual, 2)
+	test.That(t, len(coll.captureErrors), test.ShouldEqual, 1)
+}
+
 func validateReadings(t *testing.T, act []*v1.SensorData, n int) {
 	t.Helper()
 	for i := 0; i < n; i++ {
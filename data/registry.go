@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -27,6 +28,10 @@ type CollectorParams struct {
 	BufferSize    int
 	Logger        logging.Logger
 	Clock         clock.Clock
+	// Trigger, if set, is checked before each capture, and the capture is skipped unless it returns true.
+	Trigger func(ctx context.Context) (bool, error)
+	// MinCaptureSpacing, if set, skips captures made less than it after the previous capture was stored.
+	MinCaptureSpacing time.Duration
 }
 
 // Validate validates that p contains all required parameters.
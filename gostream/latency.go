@@ -0,0 +1,123 @@
+package gostream
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// The stages video frames go through from a camera to a viewer, which latency is measured for.
+const (
+	// LatencyStageCapture is reading a frame from its source, such as a camera.
+	LatencyStageCapture = "capture"
+	// LatencyStageQueue is waiting to be encoded, including for the frame rate limit.
+	LatencyStageQueue = "queue"
+	// LatencyStageEncode is encoding a frame, which frames that arrive already encoded skip.
+	LatencyStageEncode = "encode"
+	// LatencyStageSend is packetizing a frame and writing it to the WebRTC track.
+	LatencyStageSend = "send"
+	// LatencyStageNetwork is getting a frame to a viewer, estimated as half the round trip time the viewer
+	// reports in its RTCP receiver reports.
+	LatencyStageNetwork = "network"
+)
+
+// latencyWindow is how many of the most recent frames latencies are summarized over.
+const latencyWindow = 100
+
+// A LatencyInstrumentedStream is a Stream which can measure how long its video frames spend in each stage
+// between being captured and being sent.
+type LatencyInstrumentedStream interface {
+	Stream
+
+	// SetLatencyInstrumentation starts or stops measuring latency. Stopping discards what was measured.
+	SetLatencyInstrumentation(enabled bool)
+
+	// Latency summarizes the latency of each stage over recent frames, keyed by the LatencyStage constants. It
+	// is empty when instrumentation is off.
+	Latency() map[string]LatencySummary
+}
+
+// LatencySummary summarizes how long recent frames spent in a stage.
+type LatencySummary struct {
+	Samples int
+	Mean    time.Duration
+	P50     time.Duration
+	P95     time.Duration
+	Max     time.Duration
+}
+
+// SummarizeLatencies summarizes latencies, which are reordered.
+func SummarizeLatencies(latencies []time.Duration) LatencySummary {
+	if len(latencies) == 0 {
+		return LatencySummary{}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	var total time.Duration
+	for _, latency := range latencies {
+		total += latency
+	}
+	return LatencySummary{
+		Samples: len(latencies),
+		Mean:    total / time.Duration(len(latencies)),
+		P50:     latencies[(len(latencies)-1)/2],
+		P95:     latencies[(len(latencies)-1)*95/100],
+		Max:     latencies[len(latencies)-1],
+	}
+}
+
+// latencyRecorder keeps the latencies of recent frames in each stage while enabled.
+type latencyRecorder struct {
+	mu      sync.Mutex
+	enabled bool
+	stages  map[string]*latencyRing
+}
+
+type latencyRing struct {
+	latencies []time.Duration
+	next      int
+}
+
+func (lr *latencyRecorder) setEnabled(enabled bool) {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	lr.enabled = enabled
+	lr.stages = nil
+}
+
+func (lr *latencyRecorder) isEnabled() bool {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	return lr.enabled
+}
+
+func (lr *latencyRecorder) record(stage string, latency time.Duration) {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	if !lr.enabled || latency < 0 {
+		return
+	}
+	if lr.stages == nil {
+		lr.stages = map[string]*latencyRing{}
+	}
+	ring, ok := lr.stages[stage]
+	if !ok {
+		ring = &latencyRing{latencies: make([]time.Duration, 0, latencyWindow)}
+		lr.stages[stage] = ring
+	}
+	if len(ring.latencies) < latencyWindow {
+		ring.latencies = append(ring.latencies, latency)
+		return
+	}
+	ring.latencies[ring.next] = latency
+	ring.next = (ring.next + 1) % latencyWindow
+}
+
+func (lr *latencyRecorder) summaries() map[string]LatencySummary {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	summaries := make(map[string]LatencySummary, len(lr.stages))
+	for stage, ring := range lr.stages {
+		summaries[stage] = SummarizeLatencies(append([]time.Duration(nil), ring.latencies...))
+	}
+	return summaries
+}
@@ -0,0 +1,47 @@
+package gostream
+
+import (
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+)
+
+func TestSummarizeLatencies(t *testing.T) {
+	test.That(t, SummarizeLatencies(nil), test.ShouldResemble, LatencySummary{})
+
+	latencies := make([]time.Duration, 0, 100)
+	for i := 100; i > 0; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	test.That(t, SummarizeLatencies(latencies), test.ShouldResemble, LatencySummary{
+		Samples: 100,
+		Mean:    50500 * time.Microsecond,
+		P50:     50 * time.Millisecond,
+		P95:     95 * time.Millisecond,
+		Max:     100 * time.Millisecond,
+	})
+}
+
+func TestLatencyRecorder(t *testing.T) {
+	var lr latencyRecorder
+	lr.record(LatencyStageEncode, time.Millisecond)
+	test.That(t, lr.summaries(), test.ShouldBeEmpty)
+
+	lr.setEnabled(true)
+	// only the most recent frames are kept
+	for i := 0; i < 2*latencyWindow; i++ {
+		lr.record(LatencyStageEncode, time.Duration(i)*time.Millisecond)
+	}
+	lr.record(LatencyStageSend, time.Millisecond)
+	summaries := lr.summaries()
+	test.That(t, summaries, test.ShouldHaveLength, 2)
+	test.That(t, summaries[LatencyStageEncode].Samples, test.ShouldEqual, latencyWindow)
+	test.That(t, summaries[LatencyStageEncode].Max, test.ShouldEqual, (2*latencyWindow-1)*time.Millisecond)
+	test.That(t, summaries[LatencyStageEncode].P50, test.ShouldBeGreaterThanOrEqualTo, latencyWindow*time.Millisecond)
+	test.That(t, summaries[LatencyStageSend].Samples, test.ShouldEqual, 1)
+
+	// stopping discards what was measured
+	lr.setEnabled(false)
+	test.That(t, lr.summaries(), test.ShouldBeEmpty)
+}
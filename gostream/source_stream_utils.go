@@ -2,6 +2,7 @@ package gostream
 
 import (
 	"context"
+	"time"
 
 	"github.com/edaniels/golog"
 	"go.viam.com/utils"
@@ -81,16 +82,23 @@ func streamMediaSource[T, U any](
 				return nil
 			default:
 			}
+			captureStarted := time.Now()
 			media, release, err := mediaStream.Next(ctx)
 			if err != nil {
 				continue
 			}
+			pair := MediaReleasePair[T]{
+				Media:          media,
+				Release:        release,
+				CaptureStarted: captureStarted,
+				Captured:       time.Now(),
+			}
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
 			case <-readyCtx.Done():
 				return nil
-			case input <- MediaReleasePair[T]{media, release}:
+			case input <- pair:
 			}
 		}
 	}
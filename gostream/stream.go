@@ -45,6 +45,26 @@ type Stream interface {
 	Stop()
 }
 
+// A RateLimitedStream is a Stream whose video frame rate can be lowered below its target, such as to share
+// limited bandwidth with other streams.
+type RateLimitedStream interface {
+	Stream
+
+	// TargetFrameRate is the frame rate video is sent at when not limited.
+	TargetFrameRate() int
+
+	// SetMaxFrameRate limits the frame rate video is sent at, or removes the limit if fps is 0. Frames which
+	// arrive already encoded are never dropped, since the frames after them may depend on them.
+	SetMaxFrameRate(fps float64)
+
+	// AverageFrameSize is the size in bytes of recently sent video frames, or 0 if none have been sent.
+	AverageFrameSize() float64
+}
+
+// frameSizeSmoothing is the weight of each new frame in the average frame size, so that it follows changes in
+// the scene within a few seconds.
+const frameSizeSmoothing = 0.1
+
 type internalStream interface {
 	VideoTrackLocal() (webrtc.TrackLocal, bool)
 	AudioTrackLocal() (webrtc.TrackLocal, bool)
@@ -56,6 +76,11 @@ type internalStream interface {
 type MediaReleasePair[T any] struct {
 	Media   T
 	Release func()
+
+	// CaptureStarted and Captured are when the media started being read from its source and when it had
+	// been, for measuring latency. They are zero when not known.
+	CaptureStarted time.Time
+	Captured       time.Time
 }
 
 // NewStream returns a newly configured stream that can begin to handle
@@ -139,6 +164,13 @@ type basicStream struct {
 	audioLatency    time.Duration
 	audioLatencySet bool
 
+	latency latencyRecorder
+
+	// rateMu guards the frame rate limit and the frame sizes measured to choose it.
+	rateMu           sync.Mutex
+	maxFrameRate     float64
+	averageFrameSize float64
+
 	shutdownCtx             context.Context
 	shutdownCtxCancel       func()
 	activeBackgroundWorkers sync.WaitGroup
@@ -198,6 +230,52 @@ func (bs *basicStream) Stop() {
 	bs.streamingReadyCh = make(chan struct{})
 }
 
+func (bs *basicStream) SetLatencyInstrumentation(enabled bool) {
+	bs.latency.setEnabled(enabled)
+}
+
+func (bs *basicStream) Latency() map[string]LatencySummary {
+	return bs.latency.summaries()
+}
+
+func (bs *basicStream) TargetFrameRate() int {
+	return bs.config.TargetFrameRate
+}
+
+func (bs *basicStream) SetMaxFrameRate(fps float64) {
+	bs.rateMu.Lock()
+	defer bs.rateMu.Unlock()
+	bs.maxFrameRate = fps
+}
+
+func (bs *basicStream) AverageFrameSize() float64 {
+	bs.rateMu.Lock()
+	defer bs.rateMu.Unlock()
+	return bs.averageFrameSize
+}
+
+// frameAllowed returns whether a frame may be sent now, given when the last one was, without going over the
+// frame rate limit.
+func (bs *basicStream) frameAllowed(lastSent time.Time) bool {
+	bs.rateMu.Lock()
+	defer bs.rateMu.Unlock()
+	if bs.maxFrameRate <= 0 || lastSent.IsZero() {
+		return true
+	}
+	// allow a little early so that ticker jitter does not halve the rate
+	return time.Since(lastSent) >= time.Duration(0.9*float64(time.Second)/bs.maxFrameRate)
+}
+
+func (bs *basicStream) recordFrameSize(size int) {
+	bs.rateMu.Lock()
+	defer bs.rateMu.Unlock()
+	if bs.averageFrameSize == 0 {
+		bs.averageFrameSize = float64(size)
+		return
+	}
+	bs.averageFrameSize += frameSizeSmoothing * (float64(size) - bs.averageFrameSize)
+}
+
 func (bs *basicStream) StreamingReady() (<-chan struct{}, context.Context) {
 	bs.mu.RLock()
 	defer bs.mu.RUnlock()
@@ -237,6 +315,7 @@ func (bs *basicStream) processInputFrames() {
 	frameLimiterDur := time.Second / time.Duration(bs.config.TargetFrameRate)
 	defer close(bs.outputVideoChan)
 	var dx, dy int
+	var lastSent time.Time
 	ticker := time.NewTicker(frameLimiterDur)
 	defer ticker.Stop()
 	for {
@@ -266,10 +345,17 @@ func (bs *basicStream) processInputFrames() {
 			}
 
 			var encodedFrame []byte
+			if !framePair.Captured.IsZero() && bs.latency.isEnabled() {
+				bs.latency.record(LatencyStageCapture, framePair.Captured.Sub(framePair.CaptureStarted))
+				bs.latency.record(LatencyStageQueue, time.Since(framePair.Captured))
+			}
 
 			if frame, ok := framePair.Media.(*rimage.LazyEncodedImage); ok && frame.MIMEType() == utils2.MimeTypeH264 {
 				encodedFrame = frame.RawData() // nothing to do; already encoded
 			} else {
+				if !bs.frameAllowed(lastSent) {
+					return
+				}
 				bounds := framePair.Media.Bounds()
 				newDx, newDy := bounds.Dx(), bounds.Dy()
 				if bs.videoEncoder == nil || dx != newDx || dy != newDy {
@@ -285,11 +371,13 @@ func (bs *basicStream) processInputFrames() {
 
 				// thread-safe because the size is static
 				var err error
+				encodeStart := time.Now()
 				encodedFrame, err = bs.videoEncoder.Encode(bs.shutdownCtx, framePair.Media)
 				if err != nil {
 					bs.logger.Error(err)
 					return
 				}
+				bs.latency.record(LatencyStageEncode, time.Since(encodeStart))
 			}
 
 			if encodedFrame != nil {
@@ -298,6 +386,8 @@ func (bs *basicStream) processInputFrames() {
 					return
 				case bs.outputVideoChan <- encodedFrame:
 				}
+				lastSent = time.Now()
+				bs.recordFrameSize(len(encodedFrame))
 			}
 		}()
 		if initErr {
@@ -375,6 +465,7 @@ func (bs *basicStream) processOutputFrames() {
 		if err := bs.videoTrackLocal.WriteData(outputFrame); err != nil {
 			bs.logger.Errorw("error writing frame", "error", err)
 		}
+		bs.latency.record(LatencyStageSend, time.Since(now))
 		framesSent++
 		if Debug {
 			bs.logger.Debugw("wrote sample", "frames_sent", framesSent, "write_time", time.Since(now))
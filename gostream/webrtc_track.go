@@ -10,6 +10,8 @@ import (
 	"github.com/pion/rtp/codecs"
 	"github.com/pion/webrtc/v3"
 	"go.uber.org/multierr"
+
+	"go.viam.com/rdk/metrics"
 )
 
 // Adapted from https://github.com/pion/webrtc/blob/master/track_local_static.go
@@ -126,9 +128,11 @@ func (s *trackLocalStaticRTP) WriteRTP(p *rtp.Packet) error {
 	for _, b := range s.bindings {
 		outboundPacket.Header.SSRC = uint32(b.ssrc)
 		outboundPacket.Header.PayloadType = uint8(b.payloadType)
-		if _, err := b.writeStream.WriteRTP(&outboundPacket.Header, outboundPacket.Payload); err != nil {
+		n, err := b.writeStream.WriteRTP(&outboundPacket.Header, outboundPacket.Payload)
+		if err != nil {
 			writeErrs = append(writeErrs, err)
 		}
+		metrics.AddStreamBytes(s.streamID, n)
 	}
 
 	return multierr.Combine(writeErrs...)
@@ -1,7 +1,12 @@
 // Package grpc provides grpc utilities.
 package grpc
 
-import "github.com/pion/webrtc/v3"
+import (
+	"sort"
+	"time"
+
+	"github.com/pion/webrtc/v3"
+)
 
 // DefaultWebRTCConfiguration is the default configuration to use.
 var DefaultWebRTCConfiguration = webrtc.Configuration{
@@ -11,3 +16,101 @@ var DefaultWebRTCConfiguration = webrtc.Configuration{
 		},
 	},
 }
+
+// ICECandidate is an address a WebRTC peer could be reached at.
+type ICECandidate struct {
+	// Type is host for the peer's own addresses, srflx or prflx for its address as seen from outside its NAT,
+	// and relay for an address on a TURN server.
+	Type     string `json:"type"`
+	Address  string `json:"address"`
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	// URL is the STUN or TURN server the candidate was gathered from, if any, and RelayProtocol is how the peer
+	// reaches the TURN server for relay candidates.
+	URL           string `json:"url,omitempty"`
+	RelayProtocol string `json:"relay_protocol,omitempty"`
+}
+
+// ICECandidatePair is a path between two WebRTC peers which was tried.
+type ICECandidatePair struct {
+	Local  ICECandidate `json:"local"`
+	Remote ICECandidate `json:"remote"`
+	// State is how checking the path went, such as succeeded or failed.
+	State         string        `json:"state"`
+	Nominated     bool          `json:"nominated"`
+	RoundTripTime time.Duration `json:"round_trip_time"`
+}
+
+// ICEDiagnostics describes how a WebRTC connection found a path between its peers.
+type ICEDiagnostics struct {
+	ConnectionState  string             `json:"connection_state"`
+	LocalCandidates  []ICECandidate     `json:"local_candidates"`
+	RemoteCandidates []ICECandidate     `json:"remote_candidates"`
+	Pairs            []ICECandidatePair `json:"pairs"`
+	// Selected is the path the connection uses, if one was found.
+	Selected *ICECandidatePair `json:"selected,omitempty"`
+}
+
+// PeerConnectionDiagnostics returns the ICE candidates gathered and tried by a peer connection, and the path it
+// selected.
+func PeerConnectionDiagnostics(pc *webrtc.PeerConnection) *ICEDiagnostics {
+	diagnostics := &ICEDiagnostics{ConnectionState: pc.ICEConnectionState().String()}
+
+	report := pc.GetStats()
+	candidates := map[string]ICECandidate{}
+	for _, stats := range report {
+		candidateStats, ok := stats.(webrtc.ICECandidateStats)
+		if !ok {
+			continue
+		}
+		candidate := ICECandidate{
+			Type:          candidateStats.CandidateType.String(),
+			Address:       candidateStats.IP,
+			Port:          int(candidateStats.Port),
+			Protocol:      candidateStats.Protocol,
+			URL:           candidateStats.URL,
+			RelayProtocol: candidateStats.RelayProtocol,
+		}
+		candidates[candidateStats.ID] = candidate
+		if candidateStats.Type == webrtc.StatsTypeLocalCandidate {
+			diagnostics.LocalCandidates = append(diagnostics.LocalCandidates, candidate)
+		} else {
+			diagnostics.RemoteCandidates = append(diagnostics.RemoteCandidates, candidate)
+		}
+	}
+
+	for _, stats := range report {
+		pairStats, ok := stats.(webrtc.ICECandidatePairStats)
+		if !ok {
+			continue
+		}
+		pair := ICECandidatePair{
+			Local:         candidates[pairStats.LocalCandidateID],
+			Remote:        candidates[pairStats.RemoteCandidateID],
+			State:         string(pairStats.State),
+			Nominated:     pairStats.Nominated,
+			RoundTripTime: time.Duration(pairStats.CurrentRoundTripTime * float64(time.Second)),
+		}
+		diagnostics.Pairs = append(diagnostics.Pairs, pair)
+		if pair.Nominated && pairStats.State == webrtc.StatsICECandidatePairStateSucceeded {
+			selected := pair
+			diagnostics.Selected = &selected
+		}
+	}
+
+	// stats are reported in no particular order
+	sortCandidates := func(candidates []ICECandidate) {
+		sort.Slice(candidates, func(i, j int) bool {
+			if candidates[i].Type != candidates[j].Type {
+				return candidates[i].Type < candidates[j].Type
+			}
+			return candidates[i].Address < candidates[j].Address
+		})
+	}
+	sortCandidates(diagnostics.LocalCandidates)
+	sortCandidates(diagnostics.RemoteCandidates)
+	sort.SliceStable(diagnostics.Pairs, func(i, j int) bool {
+		return diagnostics.Pairs[i].Nominated && !diagnostics.Pairs[j].Nominated
+	})
+	return diagnostics
+}
@@ -0,0 +1,324 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+	"go.viam.com/utils"
+)
+
+const defaultMaxBufferedEntries = 10000
+
+// batchEntry is a log entry encoded as a line of JSON, along with what syslog needs to know about it.
+type batchEntry struct {
+	time  time.Time
+	level zapcore.Level
+	line  []byte
+}
+
+type batchWriter interface {
+	write(ctx context.Context, entries []batchEntry) error
+	close()
+}
+
+// BatchAppender sends log entries to a network destination, such as a syslog server or an HTTPS
+// endpoint, in batches from a background worker. Entries are buffered while the destination cannot
+// be reached, so logs written while offline are delivered once back online. When the buffer is
+// full, the oldest entries are dropped.
+type BatchAppender struct {
+	writer  batchWriter
+	encoder zapcore.Encoder
+
+	// toLogMutex guards toLog and toLogOverflowsSinceLastSync.
+	toLogMutex                  sync.Mutex
+	toLog                       []batchEntry
+	toLogOverflowsSinceLastSync int
+
+	maxQueueSize int
+
+	cancelCtx               context.Context
+	cancel                  func()
+	activeBackgroundWorkers sync.WaitGroup
+}
+
+// NewSyslogAppender creates an appender sending entries to the syslog server at address, over network
+// "udp" or "tcp", in the RFC 5424 format with appName. At most maxBuffered entries are kept while the
+// server cannot be reached, or a default amount if maxBuffered is zero.
+func NewSyslogAppender(network, address, appName string, maxBuffered int) (*BatchAppender, error) {
+	if network != "udp" && network != "tcp" {
+		return nil, errors.Errorf("syslog network must be udp or tcp, not %q", network)
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "-"
+	}
+	return newBatchAppender(&syslogWriter{
+		network:  network,
+		address:  address,
+		hostname: hostname,
+		appName:  appName,
+	}, maxBuffered), nil
+}
+
+// NewHTTPSAppender creates an appender POSTing batches of entries to url as a JSON array, with the
+// given headers, such as for authorization. At most maxBuffered entries are kept while the endpoint
+// cannot be reached, or a default amount if maxBuffered is zero.
+func NewHTTPSAppender(url string, headers map[string]string, maxBuffered int) *BatchAppender {
+	return newBatchAppender(&httpsWriter{
+		url:     url,
+		headers: headers,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}, maxBuffered)
+}
+
+func newBatchAppender(writer batchWriter, maxBuffered int) *BatchAppender {
+	if maxBuffered <= 0 {
+		maxBuffered = defaultMaxBufferedEntries
+	}
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	ba := &BatchAppender{
+		writer: writer,
+		encoder: zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+			TimeKey:        "ts",
+			LevelKey:       "level",
+			NameKey:        "logger",
+			CallerKey:      "caller",
+			MessageKey:     "msg",
+			EncodeTime:     zapcore.ISO8601TimeEncoder,
+			EncodeLevel:    zapcore.LowercaseLevelEncoder,
+			EncodeCaller:   zapcore.ShortCallerEncoder,
+			EncodeDuration: zapcore.StringDurationEncoder,
+			SkipLineEnding: true,
+		}),
+		maxQueueSize: maxBuffered,
+		cancelCtx:    cancelCtx,
+		cancel:       cancel,
+	}
+	ba.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(ba.backgroundWorker, ba.activeBackgroundWorkers.Done)
+	return ba
+}
+
+// Write queues the log entry to be sent.
+func (ba *BatchAppender) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := ba.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	line := append([]byte(nil), buf.Bytes()...)
+	buf.Free()
+
+	ba.toLogMutex.Lock()
+	defer ba.toLogMutex.Unlock()
+	if len(ba.toLog) >= ba.maxQueueSize {
+		ba.toLog = ba.toLog[1:]
+		ba.toLogOverflowsSinceLastSync++
+	}
+	ba.toLog = append(ba.toLog, batchEntry{time: entry.Time, level: entry.Level, line: line})
+	return nil
+}
+
+func (ba *BatchAppender) queueSize() int {
+	ba.toLogMutex.Lock()
+	defer ba.toLogMutex.Unlock()
+	return len(ba.toLog)
+}
+
+func (ba *BatchAppender) backgroundWorker() {
+	normalInterval := 100 * time.Millisecond
+	abnormalInterval := 5 * time.Second
+	interval := normalInterval
+	var lastErr error
+	for {
+		cancelled := false
+		if !utils.SelectContextOrWait(ba.cancelCtx, interval) {
+			cancelled = true
+		}
+		err := ba.sync()
+		if err != nil && !errors.Is(err, context.Canceled) {
+			interval = abnormalInterval
+			// logging the error would only add to the logs that can't be sent, so report it directly
+			if lastErr == nil || lastErr.Error() != err.Error() {
+				fmt.Fprintf(os.Stderr, "error sending logs, will retry: %s\n", err)
+			}
+		} else {
+			interval = normalInterval
+		}
+		lastErr = err
+		if cancelled {
+			return
+		}
+	}
+}
+
+// syncOnce sends one batch, returning whether there are more to send. As with the NetAppender, the
+// batch is only removed from the queue once sent, accounting for entries dropped meanwhile.
+func (ba *BatchAppender) syncOnce() (bool, error) {
+	ba.toLogMutex.Lock()
+	if len(ba.toLog) == 0 {
+		ba.toLogMutex.Unlock()
+		return false, nil
+	}
+	batchSize := min(len(ba.toLog), writeBatchSize)
+	batch := ba.toLog[:batchSize]
+	ba.toLogOverflowsSinceLastSync = 0
+	ba.toLogMutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := ba.writer.write(ctx, batch); err != nil {
+		return false, err
+	}
+
+	ba.toLogMutex.Lock()
+	defer ba.toLogMutex.Unlock()
+	if batchSize > ba.toLogOverflowsSinceLastSync {
+		idx := min(batchSize-ba.toLogOverflowsSinceLastSync, len(ba.toLog))
+		ba.toLog = ba.toLog[idx:]
+	}
+	ba.toLogOverflowsSinceLastSync = 0
+	return len(ba.toLog) > 0, nil
+}
+
+func (ba *BatchAppender) sync() error {
+	for {
+		moreToDo, err := ba.syncOnce()
+		if err != nil {
+			return err
+		}
+		if !moreToDo {
+			return nil
+		}
+	}
+}
+
+// Sync is a no-op; entries are sent by the background worker.
+func (ba *BatchAppender) Sync() error {
+	return nil
+}
+
+// Close the BatchAppender. This makes a best effort at sending all logs before returning.
+func (ba *BatchAppender) Close() {
+	if ba.cancel == nil {
+		return
+	}
+	// try for up to 10 seconds for the queue to clear before cancelling
+	for i := 0; i < 1000 && ba.queueSize() > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	ba.cancel()
+	ba.cancel = nil
+	ba.activeBackgroundWorkers.Wait()
+	ba.writer.close()
+}
+
+type syslogWriter struct {
+	network  string
+	address  string
+	hostname string
+	appName  string
+	conn     net.Conn
+}
+
+// syslogSeverity maps levels to the severities of RFC 5424.
+func syslogSeverity(level zapcore.Level) int {
+	switch level {
+	case zapcore.DebugLevel:
+		return 7
+	case zapcore.InfoLevel:
+		return 6
+	case zapcore.WarnLevel:
+		return 4
+	case zapcore.DPanicLevel, zapcore.PanicLevel, zapcore.FatalLevel, zapcore.InvalidLevel:
+		return 2
+	case zapcore.ErrorLevel:
+		return 3
+	default:
+		return 3
+	}
+}
+
+func (w *syslogWriter) write(ctx context.Context, entries []batchEntry) error {
+	if w.conn == nil {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, w.network, w.address)
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := w.conn.SetWriteDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	const userFacility = 1
+	for _, entry := range entries {
+		msg := fmt.Sprintf("<%d>1 %s %s %s - - - %s",
+			userFacility*8+syslogSeverity(entry.level), entry.time.UTC().Format(time.RFC3339Nano),
+			w.hostname, w.appName, entry.line)
+		if w.network == "tcp" {
+			// stream transports frame each message with its length
+			msg = strconv.Itoa(len(msg)) + " " + msg
+		}
+		if _, err := w.conn.Write([]byte(msg)); err != nil {
+			utils.UncheckedError(w.conn.Close())
+			w.conn = nil
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *syslogWriter) close() {
+	if w.conn != nil {
+		utils.UncheckedError(w.conn.Close())
+		w.conn = nil
+	}
+}
+
+type httpsWriter struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func (w *httpsWriter) write(ctx context.Context, entries []batchEntry) error {
+	lines := make([][]byte, 0, len(entries))
+	for _, entry := range entries {
+		lines = append(lines, entry.line)
+	}
+	body := append(append([]byte("["), bytes.Join(lines, []byte(","))...), ']')
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range w.headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	utils.UncheckedError(resp.Body.Close())
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("log endpoint responded %s", resp.Status)
+	}
+	return nil
+}
+
+func (w *httpsWriter) close() {
+	w.client.CloseIdleConnections()
+}
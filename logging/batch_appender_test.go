@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+)
+
+func TestHTTPSAppenderBuffersWhileOffline(t *testing.T) {
+	var mu sync.Mutex
+	online := false
+	var received []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !online {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		test.That(t, r.Header.Get("Authorization"), test.ShouldEqual, "Bearer token")
+		var batch []map[string]interface{}
+		test.That(t, json.NewDecoder(r.Body).Decode(&batch), test.ShouldBeNil)
+		received = append(received, batch...)
+	}))
+	defer server.Close()
+
+	appender := NewHTTPSAppender(server.URL, map[string]string{"Authorization": "Bearer token"}, 3)
+	defer appender.Close()
+	for _, msg := range []string{"one", "two", "three", "four"} {
+		entry := zapcore.Entry{Level: zapcore.WarnLevel, Time: time.Now(), LoggerName: "robot", Message: msg}
+		test.That(t, appender.Write(entry, []zapcore.Field{zap.Int("n", len(msg))}), test.ShouldBeNil)
+	}
+
+	// nothing is lost while the endpoint is down, other than what overflows the buffer
+	time.Sleep(200 * time.Millisecond)
+	test.That(t, appender.queueSize(), test.ShouldEqual, 3)
+	mu.Lock()
+	online = true
+	mu.Unlock()
+
+	testutils.WaitForAssertionWithSleep(t, 100*time.Millisecond, 100, func(tb testing.TB) {
+		mu.Lock()
+		defer mu.Unlock()
+		test.That(tb, received, test.ShouldHaveLength, 3)
+	})
+	test.That(t, received[0]["msg"], test.ShouldEqual, "two")
+	test.That(t, received[0]["level"], test.ShouldEqual, "warn")
+	test.That(t, received[0]["logger"], test.ShouldEqual, "robot")
+	test.That(t, received[2]["n"], test.ShouldEqual, 4.0)
+}
+
+func TestSyslogAppender(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	defer listener.Close()
+
+	appender, err := NewSyslogAppender("tcp", listener.Addr().String(), "viam-server", 0)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, appender.Write(zapcore.Entry{Level: zapcore.ErrorLevel, Time: time.Now(), Message: "oops"}, nil), test.ShouldBeNil)
+
+	conn, err := listener.Accept()
+	test.That(t, err, test.ShouldBeNil)
+	defer conn.Close()
+	appender.Close()
+	msg, err := io.ReadAll(conn)
+	test.That(t, err, test.ShouldBeNil)
+	_, frame, found := strings.Cut(string(msg), " ")
+	test.That(t, found, test.ShouldBeTrue)
+	// user facility, error severity
+	test.That(t, frame, test.ShouldStartWith, "<11>1 ")
+	test.That(t, frame, test.ShouldContainSubstring, " viam-server - - - {")
+	test.That(t, frame, test.ShouldContainSubstring, `"msg":"oops"`)
+
+	_, err = NewSyslogAppender("unix", "/dev/log", "viam-server", 0)
+	test.That(t, err, test.ShouldNotBeNil)
+}
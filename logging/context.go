@@ -3,6 +3,7 @@ package logging
 import (
 	"context"
 
+	"go.uber.org/zap/zapcore"
 	"go.viam.com/utils"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
@@ -48,6 +49,26 @@ func GetName(ctx context.Context) string {
 	return emptyTraceKey
 }
 
+type fieldsKeyType int
+
+const fieldsKeyID = fieldsKeyType(iota)
+
+// ContextWithFields returns a new context whose keysAndValues are added to every entry logged with it
+// through the `C` logging methods, such as the id of the operation the context belongs to.
+func ContextWithFields(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	existing, _ := ctx.Value(fieldsKeyID).([]zapcore.Field)
+	fields := make([]zapcore.Field, 0, len(existing)+len(keysAndValues)/2)
+	fields = append(fields, existing...)
+	return context.WithValue(ctx, fieldsKeyID, append(fields, fieldsFromKeysAndValues(keysAndValues)...))
+}
+
+func withContextFields(ctx context.Context, entry *LogEntry) *LogEntry {
+	if fields, ok := ctx.Value(fieldsKeyID).([]zapcore.Field); ok {
+		entry.fields = append(entry.fields, fields...)
+	}
+	return entry
+}
+
 const dtNameMetadataKey = "dtName"
 
 // UnaryClientInterceptor adds debug directives from the current context (if any) to the
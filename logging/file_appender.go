@@ -0,0 +1,142 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// rotatedTimeFormat suffixes rotated log files. It sorts chronologically and avoids characters
+// that are reserved in file names on some platforms.
+const rotatedTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotatingFileAppender writes the same lines as a ConsoleAppender to a file, and moves the file
+// aside to start a new one once it grows too large or too old.
+type RotatingFileAppender struct {
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+}
+
+// NewRotatingFileAppender creates an appender writing to path. The file is rotated once writing to
+// it would take it past maxSizeBytes, or once it has been open for maxAge; a zero value disables
+// either limit. Rotated files are renamed with the time of their rotation, and all but the newest
+// maxBackups of them are deleted, unless maxBackups is zero.
+func NewRotatingFileAppender(path string, maxSizeBytes int64, maxAge time.Duration, maxBackups int) (*RotatingFileAppender, error) {
+	appender := &RotatingFileAppender{
+		path:       path,
+		maxSize:    maxSizeBytes,
+		maxAge:     maxAge,
+		maxBackups: maxBackups,
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	if err := appender.open(); err != nil {
+		return nil, err
+	}
+	return appender, nil
+}
+
+func (appender *RotatingFileAppender) open() error {
+	//nolint:gosec
+	file, err := os.OpenFile(appender.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return multierr.Combine(err, file.Close())
+	}
+	appender.file = file
+	appender.size = info.Size()
+	appender.opened = time.Now()
+	return nil
+}
+
+// Write outputs the log entry to the file, rotating it first if needed.
+func (appender *RotatingFileAppender) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	var line bytes.Buffer
+	formatErr := NewWriterAppender(&line).Write(entry, fields)
+
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+	if appender.file == nil {
+		return multierr.Combine(formatErr, os.ErrClosed)
+	}
+	tooLarge := appender.maxSize > 0 && appender.size > 0 && appender.size+int64(line.Len()) > appender.maxSize
+	tooOld := appender.maxAge > 0 && time.Since(appender.opened) >= appender.maxAge
+	if tooLarge || tooOld {
+		if err := appender.rotate(); err != nil {
+			return multierr.Combine(formatErr, err)
+		}
+	}
+	n, err := appender.file.Write(line.Bytes())
+	appender.size += int64(n)
+	return multierr.Combine(formatErr, err)
+}
+
+func (appender *RotatingFileAppender) rotate() error {
+	if err := appender.file.Close(); err != nil {
+		return err
+	}
+	appender.file = nil
+	rotatedPath := appender.path + "." + time.Now().UTC().Format(rotatedTimeFormat)
+	if err := os.Rename(appender.path, rotatedPath); err != nil {
+		return err
+	}
+	if err := appender.open(); err != nil {
+		return err
+	}
+	if appender.maxBackups <= 0 {
+		return nil
+	}
+
+	rotated, err := filepath.Glob(appender.path + ".*")
+	if err != nil {
+		return err
+	}
+	sort.Strings(rotated)
+	var errs error
+	for len(rotated) > appender.maxBackups {
+		errs = multierr.Combine(errs, os.Remove(rotated[0]))
+		rotated = rotated[1:]
+	}
+	return errs
+}
+
+// Sync flushes the file to disk.
+func (appender *RotatingFileAppender) Sync() error {
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+	if appender.file == nil {
+		return nil
+	}
+	return appender.file.Sync()
+}
+
+// Close closes the file.
+func (appender *RotatingFileAppender) Close() {
+	appender.mu.Lock()
+	defer appender.mu.Unlock()
+	if appender.file == nil {
+		return
+	}
+	if err := appender.file.Close(); err != nil {
+		fmt.Fprint(os.Stderr, err)
+	}
+	appender.file = nil
+}
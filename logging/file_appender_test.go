@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"go.viam.com/test"
+)
+
+func TestRotatingFileAppender(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "viam-server.log")
+	appender, err := NewRotatingFileAppender(path, 200, 0, 2)
+	test.That(t, err, test.ShouldBeNil)
+	defer appender.Close()
+
+	entry := zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), Message: strings.Repeat("x", 80)}
+	for i := 0; i < 8; i++ {
+		test.That(t, appender.Write(entry, nil), test.ShouldBeNil)
+		// rotated files are named by the millisecond
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	contents, err := os.ReadFile(path)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(contents), test.ShouldBeLessThanOrEqualTo, 200)
+	test.That(t, string(contents), test.ShouldContainSubstring, entry.Message)
+
+	rotated, err := filepath.Glob(path + ".*")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, rotated, test.ShouldHaveLength, 2)
+
+	appender.Close()
+	test.That(t, appender.Write(entry, nil), test.ShouldNotBeNil)
+}
+
+func TestRotatingFileAppenderMaxAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "viam-server.log")
+	appender, err := NewRotatingFileAppender(path, 0, 10*time.Millisecond, 0)
+	test.That(t, err, test.ShouldBeNil)
+	defer appender.Close()
+
+	entry := zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), Message: "hello"}
+	test.That(t, appender.Write(entry, nil), test.ShouldBeNil)
+	test.That(t, appender.Write(entry, nil), test.ShouldBeNil)
+	time.Sleep(20 * time.Millisecond)
+	test.That(t, appender.Write(entry, nil), test.ShouldBeNil)
+
+	rotated, err := filepath.Glob(path + ".*")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, rotated, test.ShouldHaveLength, 1)
+	contents, err := os.ReadFile(rotated[0])
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, strings.Count(string(contents), "hello"), test.ShouldEqual, 2)
+}
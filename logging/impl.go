@@ -25,6 +25,8 @@ type (
 		// avoid that. This function is a no-op for non-test loggers. See `NewTestAppender`
 		// documentation for more details.
 		testHelper func()
+		// fields are added to every entry, such as the name and model of the resource logging.
+		fields []zapcore.Field
 	}
 
 	// LogEntry embeds a zapcore Entry and slice of Fields.
@@ -39,6 +41,7 @@ func (imp *impl) NewLogEntry() *LogEntry {
 	ret.Time = time.Now()
 	ret.LoggerName = imp.name
 	ret.Caller = getCaller()
+	ret.fields = append(ret.fields, imp.fields...)
 
 	return ret
 }
@@ -76,6 +79,29 @@ func (imp *impl) Sublogger(subname string) Logger {
 		NewAtomicLevelAt(imp.level.Get()),
 		imp.appenders,
 		imp.testHelper,
+		imp.fields,
+	}
+}
+
+// WithFields returns a logger that adds keysAndValues to every entry logger logs. A logger from this
+// package shares its level and appenders with the returned logger, while any other Logger is wrapped
+// through its zap logger.
+func WithFields(logger Logger, keysAndValues ...interface{}) Logger {
+	if imp, ok := logger.(*impl); ok {
+		return imp.withFields(keysAndValues...)
+	}
+	return &zLogger{logger.AsZap().With(keysAndValues...)}
+}
+
+func (imp *impl) withFields(keysAndValues ...interface{}) Logger {
+	fields := make([]zapcore.Field, 0, len(imp.fields)+len(keysAndValues)/2)
+	fields = append(fields, imp.fields...)
+	return &impl{
+		imp.name,
+		imp.level,
+		imp.appenders,
+		imp.testHelper,
+		append(fields, fieldsFromKeysAndValues(keysAndValues)...),
 	}
 }
 
@@ -142,6 +168,9 @@ func (imp *impl) AsZap() *zap.SugaredLogger {
 			return zapcore.NewTee(c, core)
 		}))
 	}
+	if len(imp.fields) > 0 {
+		ret = ret.Desugar().With(imp.fields...).Sugar()
+	}
 
 	return ret
 }
@@ -156,6 +185,7 @@ func (imp *impl) shouldLog(logLevel Level) bool {
 
 func (imp *impl) Write(entry *LogEntry) {
 	imp.testHelper()
+	redactEntry(entry)
 	for _, appender := range imp.appenders {
 		err := appender.Write(entry.Entry, entry.fields)
 		if err != nil {
@@ -197,11 +227,16 @@ func (imp *impl) formatw(logLevel Level, traceKey, msg string, keysAndValues ...
 	logEntry.Level = logLevel.AsZap()
 	logEntry.Message = msg
 
-	logEntry.fields = make([]zapcore.Field, 0, len(keysAndValues)/2+1)
 	if traceKey != emptyTraceKey {
 		logEntry.fields = append(logEntry.fields, zap.String("traceKey", traceKey))
 	}
+	logEntry.fields = append(logEntry.fields, fieldsFromKeysAndValues(keysAndValues)...)
+
+	return logEntry
+}
 
+func fieldsFromKeysAndValues(keysAndValues []interface{}) []zapcore.Field {
+	fields := make([]zapcore.Field, 0, len(keysAndValues)/2+1)
 	for keyIdx := 0; keyIdx < len(keysAndValues); keyIdx += 2 {
 		keyObj := keysAndValues[keyIdx]
 		var keyStr string
@@ -212,15 +247,14 @@ func (imp *impl) formatw(logLevel Level, traceKey, msg string, keysAndValues ...
 		}
 
 		if keyIdx+1 < len(keysAndValues) {
-			logEntry.fields = append(logEntry.fields, zap.Any(keyStr, keysAndValues[keyIdx+1]))
+			fields = append(fields, zap.Any(keyStr, keysAndValues[keyIdx+1]))
 		} else {
 			// API mis-use. Rather than logging a logging mis-use, slip in an error message such
 			// that we don't silenlty discard it.
-			logEntry.fields = append(logEntry.fields, zap.Any(keyStr, errors.New("unpaired log key")))
+			fields = append(fields, zap.Any(keyStr, errors.New("unpaired log key")))
 		}
 	}
-
-	return logEntry
+	return fields
 }
 
 func (imp *impl) Debug(args ...interface{}) {
@@ -236,7 +270,7 @@ func (imp *impl) CDebug(ctx context.Context, args ...interface{}) {
 
 	// We log if the logger is configured for debug, or if there's a trace key.
 	if imp.shouldLog(DEBUG) || dbgName != emptyTraceKey {
-		imp.Write(imp.format(DEBUG, dbgName, args...))
+		imp.Write(withContextFields(ctx, imp.format(DEBUG, dbgName, args...)))
 	}
 }
 
@@ -253,7 +287,7 @@ func (imp *impl) CDebugf(ctx context.Context, template string, args ...interface
 
 	// We log if the logger is configured for debug, or if there's a trace key.
 	if imp.shouldLog(DEBUG) || dbgName != emptyTraceKey {
-		imp.Write(imp.formatf(DEBUG, dbgName, template, args...))
+		imp.Write(withContextFields(ctx, imp.formatf(DEBUG, dbgName, template, args...)))
 	}
 }
 
@@ -270,7 +304,7 @@ func (imp *impl) CDebugw(ctx context.Context, msg string, keysAndValues ...inter
 
 	// We log if the logger is configured for debug, or if there's a trace key.
 	if imp.shouldLog(DEBUG) || dbgName != emptyTraceKey {
-		imp.Write(imp.formatw(DEBUG, dbgName, msg, keysAndValues...))
+		imp.Write(withContextFields(ctx, imp.formatw(DEBUG, dbgName, msg, keysAndValues...)))
 	}
 }
 
@@ -287,7 +321,7 @@ func (imp *impl) CInfo(ctx context.Context, args ...interface{}) {
 
 	// We log if the logger is configured for info, or if there's a trace key.
 	if imp.shouldLog(INFO) || dbgName != emptyTraceKey {
-		imp.Write(imp.format(INFO, dbgName, args...))
+		imp.Write(withContextFields(ctx, imp.format(INFO, dbgName, args...)))
 	}
 }
 
@@ -304,7 +338,7 @@ func (imp *impl) CInfof(ctx context.Context, template string, args ...interface{
 
 	// We log if the logger is configured for info, or if there's a trace key.
 	if imp.shouldLog(INFO) || dbgName != emptyTraceKey {
-		imp.Write(imp.formatf(INFO, dbgName, template, args...))
+		imp.Write(withContextFields(ctx, imp.formatf(INFO, dbgName, template, args...)))
 	}
 }
 
@@ -321,7 +355,7 @@ func (imp *impl) CInfow(ctx context.Context, msg string, keysAndValues ...interf
 
 	// We log if the logger is configured for info, or if there's a trace key.
 	if imp.shouldLog(INFO) || dbgName != emptyTraceKey {
-		imp.Write(imp.formatw(INFO, dbgName, msg, keysAndValues...))
+		imp.Write(withContextFields(ctx, imp.formatw(INFO, dbgName, msg, keysAndValues...)))
 	}
 }
 
@@ -338,7 +372,7 @@ func (imp *impl) CWarn(ctx context.Context, args ...interface{}) {
 
 	// We log if the logger is configured for warn, or if there's a trace key.
 	if imp.shouldLog(WARN) || dbgName != emptyTraceKey {
-		imp.Write(imp.format(WARN, dbgName, args...))
+		imp.Write(withContextFields(ctx, imp.format(WARN, dbgName, args...)))
 	}
 }
 
@@ -355,7 +389,7 @@ func (imp *impl) CWarnf(ctx context.Context, template string, args ...interface{
 
 	// We log if the logger is configured for warn, or if there's a trace key.
 	if imp.shouldLog(WARN) || dbgName != emptyTraceKey {
-		imp.Write(imp.formatf(WARN, dbgName, template, args...))
+		imp.Write(withContextFields(ctx, imp.formatf(WARN, dbgName, template, args...)))
 	}
 }
 
@@ -372,7 +406,7 @@ func (imp *impl) CWarnw(ctx context.Context, msg string, keysAndValues ...interf
 
 	// We log if the logger is configured for warn, or if there's a trace key.
 	if imp.shouldLog(WARN) || dbgName != emptyTraceKey {
-		imp.Write(imp.formatw(WARN, dbgName, msg, keysAndValues...))
+		imp.Write(withContextFields(ctx, imp.formatw(WARN, dbgName, msg, keysAndValues...)))
 	}
 }
 
@@ -389,7 +423,7 @@ func (imp *impl) CError(ctx context.Context, args ...interface{}) {
 
 	// We log if the logger is configured for error, or if there's a trace key.
 	if imp.shouldLog(ERROR) || dbgName != emptyTraceKey {
-		imp.Write(imp.format(ERROR, dbgName, args...))
+		imp.Write(withContextFields(ctx, imp.format(ERROR, dbgName, args...)))
 	}
 }
 
@@ -406,7 +440,7 @@ func (imp *impl) CErrorf(ctx context.Context, template string, args ...interface
 
 	// We log if the logger is configured for error, or if there's a trace key.
 	if imp.shouldLog(ERROR) || dbgName != emptyTraceKey {
-		imp.Write(imp.formatf(ERROR, dbgName, template, args...))
+		imp.Write(withContextFields(ctx, imp.formatf(ERROR, dbgName, template, args...)))
 	}
 }
 
@@ -423,7 +457,7 @@ func (imp *impl) CErrorw(ctx context.Context, msg string, keysAndValues ...inter
 
 	// We log if the logger is configured for error, or if there's a trace key.
 	if imp.shouldLog(ERROR) || dbgName != emptyTraceKey {
-		imp.Write(imp.formatw(ERROR, dbgName, msg, keysAndValues...))
+		imp.Write(withContextFields(ctx, imp.formatw(ERROR, dbgName, msg, keysAndValues...)))
 	}
 }
 
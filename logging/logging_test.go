@@ -1,6 +1,7 @@
 package logging
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 
@@ -43,3 +44,26 @@ func TestJSONErrors(t *testing.T) {
 	err = json.Unmarshal([]byte(`"not a level"`), &level)
 	test.That(t, err, test.ShouldNotBeNil)
 }
+
+func TestFields(t *testing.T) {
+	logger, observed := NewObservedTestLogger(t)
+	resLogger := WithFields(logger.Sublogger("motor"), "resource", "motor", "model", "rdk:builtin:gpio")
+	ctx := ContextWithFields(context.Background(), "operation_id", "1234")
+
+	resLogger.Infow("moving", "rpm", 10)
+	resLogger.CInfo(ctx, "stopped")
+	logger.CWarn(ctx, "unrelated")
+
+	entries := observed.All()
+	test.That(t, entries, test.ShouldHaveLength, 3)
+	test.That(t, entries[0].ContextMap(), test.ShouldResemble,
+		map[string]interface{}{"resource": "motor", "model": "rdk:builtin:gpio", "rpm": int64(10)})
+	test.That(t, entries[1].ContextMap(), test.ShouldResemble,
+		map[string]interface{}{"resource": "motor", "model": "rdk:builtin:gpio", "operation_id": "1234"})
+	test.That(t, entries[2].ContextMap(), test.ShouldResemble, map[string]interface{}{"operation_id": "1234"})
+
+	// loggers with fields share their parent's level
+	resLogger.SetLevel(ERROR)
+	resLogger.Warn("suppressed")
+	test.That(t, observed.All(), test.ShouldHaveLength, 3)
+}
@@ -0,0 +1,8 @@
+//go:build slim
+
+package logging
+
+func init() {
+	// Slim builds are for low-memory devices, so hold fewer logs while the cloud is unreachable.
+	defaultMaxQueueSize = 2000
+}
@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// RedactedSecret replaces secrets in log output.
+	RedactedSecret = "******"
+
+	// Shorter secrets are not redacted, as they would mangle unrelated output.
+	minRedactedSecretLen = 4
+)
+
+var (
+	secretsMu sync.Mutex
+	secrets   []string
+	// secretsReplacer is nil until a secret is registered, so logging without secrets costs nothing.
+	secretsReplacer atomic.Pointer[strings.Replacer]
+)
+
+// RegisterSecret registers a value, such as a password resolved from a config secret reference, to be
+// replaced by RedactedSecret wherever it appears in log messages and string, error, or stringer fields.
+// Values shorter than four characters are ignored.
+func RegisterSecret(secret string) {
+	if len(secret) < minRedactedSecretLen {
+		return
+	}
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	if slices.Contains(secrets, secret) {
+		return
+	}
+	secrets = append(secrets, secret)
+	// Replace longer secrets first in case one contains another.
+	slices.SortFunc(secrets, func(left, right string) int { return len(right) - len(left) })
+	oldNew := make([]string, 0, 2*len(secrets))
+	for _, s := range secrets {
+		oldNew = append(oldNew, s, RedactedSecret)
+	}
+	secretsReplacer.Store(strings.NewReplacer(oldNew...))
+}
+
+// Redact returns s with every registered secret replaced by RedactedSecret.
+func Redact(s string) string {
+	replacer := secretsReplacer.Load()
+	if replacer == nil {
+		return s
+	}
+	return replacer.Replace(s)
+}
+
+// redactEntry redacts registered secrets from the entry's message and fields in place.
+func redactEntry(entry *LogEntry) {
+	if secretsReplacer.Load() == nil {
+		return
+	}
+	entry.Message = Redact(entry.Message)
+	// The fields may share a backing array with the logger's own fields, so never modify them in place.
+	fields := make([]zapcore.Field, 0, len(entry.fields))
+	for _, field := range entry.fields {
+		switch field.Type {
+		case zapcore.StringType:
+			field = zap.String(field.Key, Redact(field.String))
+		case zapcore.ErrorType:
+			if err, ok := field.Interface.(error); ok {
+				field = zap.String(field.Key, Redact(err.Error()))
+			}
+		case zapcore.StringerType:
+			if stringer, ok := field.Interface.(fmt.Stringer); ok {
+				field = zap.String(field.Key, Redact(stringer.String()))
+			}
+		}
+		fields = append(fields, field)
+	}
+	entry.fields = fields
+}
@@ -0,0 +1,31 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	notStdout := &bytes.Buffer{}
+	logger := &impl{
+		name:       "impl",
+		level:      NewAtomicLevelAt(INFO),
+		appenders:  []Appender{NewWriterAppender(notStdout)},
+		testHelper: func() {},
+	}
+
+	RegisterSecret("abc")
+	RegisterSecret("hunter2hunter2")
+	test.That(t, Redact("abc hunter2hunter2"), test.ShouldEqual, "abc "+RedactedSecret)
+
+	logger.Infow("connecting with hunter2hunter2",
+		"password", "hunter2hunter2",
+		"error", errors.New("bad password hunter2hunter2"),
+		"attempts", 3)
+	assertLogMatches(t, notStdout,
+		`2023-10-30T09:12:09.459Z	INFO	impl	logging/redact_test.go:200	connecting with ******	`+
+			`{"password":"******","error":"bad password ******","attempts":3}`)
+}
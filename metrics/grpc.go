@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// namedRequest is implemented by the requests of every resource API, which all carry the name of
+// the resource they are for.
+type namedRequest interface {
+	GetName() string
+}
+
+// UnaryServerInterceptor times each unary API call, and counts those which fail, by the resource
+// it was for. It also records how large its request and response were.
+func UnaryServerInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	var resourceName string
+	if named, ok := req.(namedRequest); ok {
+		resourceName = named.GetName()
+	}
+	method := methodLabel(info.FullMethod)
+	observeMessageSize(method, "received", req)
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	observeOperation(resourceName, method, time.Since(start), err)
+	if err == nil {
+		observeMessageSize(method, "sent", resp)
+	}
+	return resp, err
+}
+
+// StreamServerInterceptor times each streaming API call, and counts those which fail. The resource
+// a stream is for is only known once its first message arrives, so streams are only labeled by
+// method. The size of every message sent and received on it is recorded too.
+func StreamServerInterceptor(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	method := methodLabel(info.FullMethod)
+	start := time.Now()
+	err := handler(srv, &sizeObservingStream{ServerStream: ss, method: method})
+	observeOperation("", method, time.Since(start), err)
+	return err
+}
+
+// sizeObservingStream records the size of each message on a stream as it is sent or received.
+type sizeObservingStream struct {
+	grpc.ServerStream
+	method string
+}
+
+func (s *sizeObservingStream) SendMsg(m interface{}) error {
+	observeMessageSize(s.method, "sent", m)
+	return s.ServerStream.SendMsg(m)
+}
+
+func (s *sizeObservingStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	observeMessageSize(s.method, "received", m)
+	return nil
+}
+
+// methodLabel labels "/viam.component.arm.v1.ArmService/MoveToPosition" as
+// "viam.component.arm.v1.ArmService.MoveToPosition".
+func methodLabel(fullMethod string) string {
+	service, method := path.Split(fullMethod)
+	return path.Base(service) + "." + method
+}
+
+func observeMessageSize(method, direction string, m interface{}) {
+	if msg, ok := m.(proto.Message); ok {
+		messageBytes.WithLabelValues(method, direction).Observe(float64(proto.Size(msg)))
+	}
+}
+
+func observeOperation(resourceName, method string, duration time.Duration, err error) {
+	operationDuration.WithLabelValues(resourceName, method).Observe(duration.Seconds())
+	if err != nil {
+		operationErrors.WithLabelValues(resourceName, method, status.Code(err).String()).Inc()
+	}
+}
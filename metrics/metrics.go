@@ -0,0 +1,124 @@
+// Package metrics exposes counters and timings of robot internals in the Prometheus format, so
+// that fleets of robots can be monitored with standard tooling.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "rdk"
+
+// Registry holds every metric the robot exposes. It is separate from the Prometheus default
+// registry so that libraries registering their own metrics there don't end up in ours.
+var Registry = prometheus.NewRegistry()
+
+var (
+	operationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "resource_operation_duration_seconds",
+		Help:      "How long API calls on each resource took.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"resource", "method"})
+
+	operationErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "resource_operation_errors_total",
+		Help:      "API calls on each resource which returned an error, by gRPC status code.",
+	}, []string{"resource", "method", "code"})
+
+	messageBytes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_message_bytes",
+		Help:      "Size of the messages API calls received and sent, by method.",
+		// 256B to 64MiB, to show how close large images and point clouds get to the message size limit
+		Buckets: prometheus.ExponentialBuckets(256, 4, 10),
+	}, []string{"method", "direction"})
+
+	reconfigures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "resource_reconfigures_total",
+		Help:      "Times each resource was built, reconfigured in place, or failed to be either.",
+	}, []string{"resource", "outcome"})
+
+	watchdogRestarts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "resource_watchdog_restarts_total",
+		Help:      "Times each component was rebuilt by the watchdog after failing.",
+	}, []string{"resource"})
+
+	streamBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stream_bytes_sent_total",
+		Help:      "Bytes of audio and video sent to peers, by stream.",
+	}, []string{"stream"})
+
+	backgroundWorkers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "background_workers",
+		Help:      "Background goroutines currently running on behalf of resources.",
+	})
+
+	backgroundWorkerPanics = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "background_worker_panics_total",
+		Help:      "Background goroutines which ended in a panic.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		operationDuration,
+		operationErrors,
+		messageBytes,
+		reconfigures,
+		watchdogRestarts,
+		streamBytes,
+		backgroundWorkers,
+		backgroundWorkerPanics,
+	)
+}
+
+// Handler serves the metrics to Prometheus scrapes.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// ObserveReconfigure counts one attempt to build or reconfigure the named resource.
+func ObserveReconfigure(resourceName string, newlyBuilt bool, err error) {
+	outcome := "reconfigured"
+	switch {
+	case err != nil:
+		outcome = "failed"
+	case newlyBuilt:
+		outcome = "built"
+	}
+	reconfigures.WithLabelValues(resourceName, outcome).Inc()
+}
+
+// ObserveWatchdogRestart counts one rebuild of the named resource by the watchdog.
+func ObserveWatchdogRestart(resourceName string) {
+	watchdogRestarts.WithLabelValues(resourceName).Inc()
+}
+
+// AddStreamBytes counts bytes sent to a peer on the named stream.
+func AddStreamBytes(stream string, n int) {
+	streamBytes.WithLabelValues(stream).Add(float64(n))
+}
+
+// TrackBackgroundWorker counts a background goroutine as running until the returned function is
+// called. Pass that function whatever the goroutine recovered, so that panics are counted too.
+func TrackBackgroundWorker() func(recovered interface{}) {
+	backgroundWorkers.Inc()
+	return func(recovered interface{}) {
+		backgroundWorkers.Dec()
+		if recovered != nil {
+			backgroundWorkerPanics.Inc()
+		}
+	}
+}
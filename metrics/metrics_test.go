@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.viam.com/test"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type fakeRequest struct {
+	name string
+}
+
+func (r *fakeRequest) GetName() string {
+	return r.name
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/viam.component.arm.v1.ArmService/MoveToPosition"}
+	method := "viam.component.arm.v1.ArmService.MoveToPosition"
+
+	_, err := UnaryServerInterceptor(context.Background(), &fakeRequest{"arm1"}, info,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = UnaryServerInterceptor(context.Background(), &fakeRequest{"arm1"}, info,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.Unavailable, "arm is unplugged")
+		})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	test.That(t, testutil.CollectAndCount(operationDuration), test.ShouldBeGreaterThanOrEqualTo, 1)
+	test.That(t, testutil.ToFloat64(operationErrors.WithLabelValues("arm1", method, "Unavailable")), test.ShouldEqual, 1)
+}
+
+func TestMessageSize(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/viam.component.camera.v1.CameraService/GetImage"}
+	method := "viam.component.camera.v1.CameraService.GetImage"
+
+	_, err := UnaryServerInterceptor(context.Background(), wrapperspb.String("camera1"), info,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return wrapperspb.Bytes(make([]byte, 1000)), nil
+		})
+	test.That(t, err, test.ShouldBeNil)
+
+	test.That(t, testutil.CollectAndCount(messageBytes), test.ShouldBeGreaterThanOrEqualTo, 2)
+	server := httptest.NewServer(Handler())
+	defer server.Close()
+	resp, err := server.Client().Get(server.URL)
+	test.That(t, err, test.ShouldBeNil)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(body), test.ShouldContainSubstring,
+		`rdk_rpc_message_bytes_bucket{direction="sent",method="`+method+`",le="1024"} 1`)
+	test.That(t, string(body), test.ShouldContainSubstring,
+		`rdk_rpc_message_bytes_bucket{direction="received",method="`+method+`",le="256"} 1`)
+}
+
+func TestObserveReconfigure(t *testing.T) {
+	ObserveReconfigure("rdk:component:arm/arm2", true, nil)
+	ObserveReconfigure("rdk:component:arm/arm2", false, nil)
+	ObserveReconfigure("rdk:component:arm/arm2", false, nil)
+	ObserveReconfigure("rdk:component:arm/arm2", false, errors.New("bad config"))
+
+	test.That(t, testutil.ToFloat64(reconfigures.WithLabelValues("rdk:component:arm/arm2", "built")), test.ShouldEqual, 1)
+	test.That(t, testutil.ToFloat64(reconfigures.WithLabelValues("rdk:component:arm/arm2", "reconfigured")), test.ShouldEqual, 2)
+	test.That(t, testutil.ToFloat64(reconfigures.WithLabelValues("rdk:component:arm/arm2", "failed")), test.ShouldEqual, 1)
+}
+
+func TestTrackBackgroundWorker(t *testing.T) {
+	running := testutil.ToFloat64(backgroundWorkers)
+	panics := testutil.ToFloat64(backgroundWorkerPanics)
+
+	done := TrackBackgroundWorker()
+	test.That(t, testutil.ToFloat64(backgroundWorkers), test.ShouldEqual, running+1)
+	done(nil)
+	test.That(t, testutil.ToFloat64(backgroundWorkers), test.ShouldEqual, running)
+
+	TrackBackgroundWorker()("oops")
+	test.That(t, testutil.ToFloat64(backgroundWorkers), test.ShouldEqual, running)
+	test.That(t, testutil.ToFloat64(backgroundWorkerPanics), test.ShouldEqual, panics+1)
+}
+
+func TestHandler(t *testing.T) {
+	AddStreamBytes("camera1", 1200)
+
+	server := httptest.NewServer(Handler())
+	defer server.Close()
+	resp, err := server.Client().Get(server.URL)
+	test.That(t, err, test.ShouldBeNil)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(body), test.ShouldContainSubstring, `rdk_stream_bytes_sent_total{stream="camera1"} 1200`)
+	test.That(t, string(body), test.ShouldContainSubstring, "go_goroutines")
+}
@@ -0,0 +1,120 @@
+package modmanager
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils/pexec"
+
+	"go.viam.com/rdk/config"
+)
+
+// containerRemoveTimeout is how long removing a module's container may take before it's abandoned.
+var containerRemoveTimeout = 10 * time.Second
+
+// findContainerRuntime returns the path of the container CLI the module runs in.
+func findContainerRuntime(conf *config.ModuleContainer) (string, error) {
+	runtimes := config.ModuleContainerRuntimes
+	if conf.Runtime != "" {
+		runtimes = []string{conf.Runtime}
+	}
+	for _, runtime := range runtimes {
+		if runtimePath, err := exec.LookPath(runtime); err == nil {
+			return runtimePath, nil
+		}
+	}
+	return "", errors.Errorf("module container runtime not found, install one of %v", runtimes)
+}
+
+// containerName is the name of the module's container. It doesn't change between restarts so that a
+// container left behind by a crash can be found and removed.
+func (m *module) containerName() string {
+	return "viam-module-" + m.cfg.Name
+}
+
+// containerProcessConfig wraps the config of a module process so that it runs inside the module's
+// container with the given runtime. The container runs attached, so the module's output is captured and
+// stopping the runtime process stops the container. The directories of the module's files, data and
+// socket are mounted at the same paths they have on the host, so the module's arguments and
+// environment are the same as they'd be outside the container.
+func (m *module) containerProcessConfig(pconf pexec.ProcessConfig, runtimePath string) pexec.ProcessConfig {
+	conf := m.cfg.Container
+	args := []string{
+		"run", "--rm", "--init",
+		"--name", m.containerName(),
+		"--network", "host",
+		"--workdir", pconf.CWD,
+	}
+	// the module's socket must be owned by the same user as this process to be trusted
+	if filepath.Base(runtimePath) == "podman" && os.Getuid() != 0 {
+		args = append(args, "--userns", "keep-id")
+	} else {
+		args = append(args, "--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()))
+	}
+
+	mounts := map[string]bool{filepath.Dir(m.addr): true, filepath.Dir(pconf.Name): true, pconf.CWD: true}
+	if m.dataDir != "" {
+		mounts[m.dataDir] = true
+	}
+	mountDirs := make([]string, 0, len(mounts))
+	for dir := range mounts {
+		mountDirs = append(mountDirs, dir)
+	}
+	sort.Strings(mountDirs)
+	for _, dir := range mountDirs {
+		args = append(args, "--volume", dir+":"+dir)
+	}
+	for _, mount := range conf.Mounts {
+		args = append(args, "--volume", mount)
+	}
+	for _, device := range conf.Devices {
+		args = append(args, "--device", device)
+	}
+
+	envKeys := make([]string, 0, len(pconf.Environment))
+	for key := range pconf.Environment {
+		envKeys = append(envKeys, key)
+	}
+	sort.Strings(envKeys)
+	for _, key := range envKeys {
+		args = append(args, "--env", key+"="+pconf.Environment[key])
+	}
+
+	if conf.CPUs > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(conf.CPUs, 'f', -1, 64))
+	}
+	if conf.Memory != "" {
+		args = append(args, "--memory", conf.Memory)
+	}
+	args = append(args, conf.Args...)
+	args = append(args, conf.Image, pconf.Name)
+	args = append(args, pconf.Args...)
+
+	pconf.Name = runtimePath
+	pconf.Args = args
+	// the environment is passed into the container above, the runtime itself runs with this process's
+	pconf.Environment = nil
+	return pconf
+}
+
+// removeContainer removes the module's container if it's still there, ex: because the runtime process was
+// killed before it could stop the container, or this process crashed.
+func (m *module) removeContainer(runtimePath string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), containerRemoveTimeout)
+	defer cancel()
+	//nolint:gosec
+	out, err := exec.CommandContext(ctx, runtimePath, "rm", "--force", m.containerName()).CombinedOutput()
+	// docker fails to remove a container that isn't there, podman doesn't
+	if err != nil && !strings.Contains(string(out), "No such container") {
+		return errors.Wrapf(err, "failed to remove container of module %s: %s", m.cfg.Name, out)
+	}
+	return nil
+}
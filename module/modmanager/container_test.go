@@ -0,0 +1,70 @@
+package modmanager
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/pexec"
+
+	"go.viam.com/rdk/config"
+)
+
+func TestContainerProcessConfig(t *testing.T) {
+	m := &module{
+		cfg: config.Module{
+			Name: "mod",
+			Container: &config.ModuleContainer{
+				Image:   "ghcr.io/acme/mod:1",
+				CPUs:    1.5,
+				Memory:  "512m",
+				Devices: []string{"/dev/video0"},
+				Mounts:  []string{"/opt/models:/models:ro"},
+				Args:    []string{"--pull=newer"},
+			},
+		},
+		dataDir: "/home/viam/.viam/module-data/local/mod",
+		addr:    "/tmp/viam-module-123/mod-abcde.sock",
+	}
+	pconf := pexec.ProcessConfig{
+		ID:          "mod",
+		Name:        "/home/viam/.viam/packages/mod/bin/mod",
+		Args:        []string{m.addr, "--log-level=debug"},
+		CWD:         "/home/viam/.viam/packages/mod",
+		Environment: map[string]string{"VIAM_MODULE_DATA": m.dataDir, "VIAM_HOME": "/home/viam/.viam"},
+		Log:         true,
+	}
+
+	wrapped := m.containerProcessConfig(pconf, "/usr/bin/docker")
+	test.That(t, wrapped.ID, test.ShouldEqual, "mod")
+	test.That(t, wrapped.Log, test.ShouldBeTrue)
+	test.That(t, wrapped.Name, test.ShouldEqual, "/usr/bin/docker")
+	test.That(t, wrapped.Environment, test.ShouldBeNil)
+	test.That(t, wrapped.Args, test.ShouldResemble, []string{
+		"run", "--rm", "--init",
+		"--name", "viam-module-mod",
+		"--network", "host",
+		"--workdir", "/home/viam/.viam/packages/mod",
+		"--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()),
+		"--volume", "/home/viam/.viam/module-data/local/mod:/home/viam/.viam/module-data/local/mod",
+		"--volume", "/home/viam/.viam/packages/mod:/home/viam/.viam/packages/mod",
+		"--volume", "/home/viam/.viam/packages/mod/bin:/home/viam/.viam/packages/mod/bin",
+		"--volume", "/tmp/viam-module-123:/tmp/viam-module-123",
+		"--volume", "/opt/models:/models:ro",
+		"--device", "/dev/video0",
+		"--env", "VIAM_HOME=/home/viam/.viam",
+		"--env", "VIAM_MODULE_DATA=/home/viam/.viam/module-data/local/mod",
+		"--cpus", "1.5",
+		"--memory", "512m",
+		"--pull=newer",
+		"ghcr.io/acme/mod:1",
+		"/home/viam/.viam/packages/mod/bin/mod", "/tmp/viam-module-123/mod-abcde.sock", "--log-level=debug",
+	})
+	// the original config is left alone
+	test.That(t, pconf.Name, test.ShouldEqual, "/home/viam/.viam/packages/mod/bin/mod")
+
+	_, err := findContainerRuntime(&config.ModuleContainer{Runtime: "not-a-container-runtime"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "module container runtime not found")
+}
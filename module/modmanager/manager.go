@@ -35,6 +35,7 @@ import (
 	"go.viam.com/rdk/operation"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot/packages"
+	"go.viam.com/rdk/tracing"
 	rutils "go.viam.com/rdk/utils"
 )
 
@@ -62,6 +63,7 @@ func NewManager(
 		viamHomeDir:             options.ViamHomeDir,
 		moduleDataParentDir:     getModuleDataParentDirectory(options),
 		removeOrphanedResources: options.RemoveOrphanedResources,
+		markUnavailable:         options.SetResourcesUnavailable,
 		restartCtx:              restartCtx,
 		restartCtxCancel:        restartCtxCancel,
 		packagesDir:             options.PackagesDir,
@@ -81,6 +83,13 @@ type module struct {
 	// write-locking the module manager.
 	resourcesMu sync.Mutex
 
+	// containerRuntime is the path of the container CLI the module runs in, if it runs in a container.
+	containerRuntime string
+
+	// output keeps the module process's last lines of output, and crashes tracks when it exits unexpectedly.
+	output  outputTail
+	crashes crashState
+
 	// pendingRemoval allows delaying module close until after resources within it are closed
 	pendingRemoval bool
 
@@ -164,6 +173,7 @@ type Manager struct {
 	// it is empty if the modmanageroptions.Options.viamHomeDir was empty
 	moduleDataParentDir     string
 	removeOrphanedResources func(ctx context.Context, rNames []resource.Name)
+	markUnavailable         func(rNames []resource.Name, err error)
 	restartCtx              context.Context
 	restartCtxCancel        context.CancelFunc
 }
@@ -797,7 +807,7 @@ func (mgr *Manager) CleanModuleDataDirectory() error {
 
 // oueRestartInterval is the interval of time at which an OnUnexpectedExit
 // function can attempt to restart the module process. Multiple restart
-// attempts will use basic backoff.
+// attempts will use exponential backoff.
 var oueRestartInterval = 5 * time.Second
 
 // newOnUnexpectedExitHandler returns the appropriate OnUnexpectedExit function
@@ -817,12 +827,24 @@ func (mgr *Manager) newOnUnexpectedExitHandler(mod *module) func(exitCode int) b
 		mgr.logger.Errorw(
 			"Module has unexpectedly exited.", "module", mod.cfg.Name, "exit_code", exitCode,
 		)
+		consecutiveCrashes := mod.crashes.crashed(exitCode, mod.output.last())
+
+		// Callers of the module's resources should get an error rather than wait on a dead process.
+		mgr.setResourcesUnavailable(mod, errors.Errorf("module %s crashed and is restarting", mod.cfg.Name))
 
 		if err := mod.sharedConn.Close(); err != nil {
 			mod.logger.Warnw("Error closing connection to crashed module. Continuing restart attempt",
 				"error", err)
 		}
 
+		// A module that keeps crashing is restarted with exponential backoff, rather than in a tight loop.
+		if consecutiveCrashes > 1 {
+			backoff := restartBackoff(consecutiveCrashes - 2)
+			mgr.logger.Warnw("Module keeps crashing, waiting before restarting it",
+				"module", mod.cfg.Name, "crashes_in_a_row", consecutiveCrashes, "wait", backoff)
+			utils.SelectContextOrWait(mgr.restartCtx, backoff)
+		}
+
 		// If attemptRestart returns any orphaned resource names, restart failed,
 		// and we should remove orphaned resources. Since we handle process
 		// restarting ourselves, return false here so goutils knows not to attempt
@@ -858,6 +880,7 @@ func (mgr *Manager) newOnUnexpectedExitHandler(mod *module) func(exitCode int) b
 		if len(orphanedResourceNames) > 0 && mgr.removeOrphanedResources != nil {
 			mgr.removeOrphanedResources(mgr.restartCtx, orphanedResourceNames)
 		}
+		mgr.setResourcesUnavailable(mod, nil)
 
 		mgr.logger.Infow("Module resources successfully re-added after module restart", "module", mod.cfg.Name)
 		return false
@@ -925,7 +948,7 @@ func (mgr *Manager) attemptRestart(ctx context.Context, mod *module) []resource.
 		}
 
 		// Wait with a bit of backoff. Exit early if context has errorred.
-		if !utils.SelectContextOrWait(ctx, time.Duration(attempt)*oueRestartInterval) {
+		if !utils.SelectContextOrWait(ctx, restartBackoff(attempt-1)) {
 			mgr.logger.CInfow(
 				ctx, "Will not continue to attempt restarting crashed module", "module", mod.cfg.Name, "reason", ctx.Err().Error(),
 			)
@@ -963,10 +986,12 @@ func (m *module) dial() error {
 			rdkgrpc.EnsureTimeoutUnaryClientInterceptor,
 			grpc_retry.UnaryClientInterceptor(),
 			operation.UnaryClientInterceptor,
+			tracing.UnaryClientInterceptor,
 		),
 		grpc.WithChainStreamInterceptor(
 			grpc_retry.StreamClientInterceptor(),
 			operation.StreamClientInterceptor,
+			tracing.StreamClientInterceptor,
 		),
 	)
 	if err != nil {
@@ -1076,6 +1101,7 @@ func (m *module) startProcess(
 		CWD:              moduleWorkingDirectory,
 		Environment:      moduleEnvironment,
 		Log:              true,
+		LogWriter:        &m.output,
 		OnUnexpectedExit: oue,
 	}
 	// Start module process with supplied log level or "debug" if none is
@@ -1086,6 +1112,20 @@ func (m *module) startProcess(
 		pconf.Args = append(pconf.Args, fmt.Sprintf(logLevelArgumentTemplate, "debug"))
 	}
 
+	if m.cfg.Container != nil {
+		m.containerRuntime, err = findContainerRuntime(m.cfg.Container)
+		if err != nil {
+			return err
+		}
+		// a container left behind by a crash would keep the new one from starting with the same name
+		if err := m.removeContainer(m.containerRuntime); err != nil {
+			return err
+		}
+		pconf = m.containerProcessConfig(pconf, m.containerRuntime)
+		logger.CInfow(ctx, "Starting module in container", "module", m.cfg.Name, "image", m.cfg.Container.Image,
+			"runtime", m.containerRuntime)
+	}
+
 	m.process = pexec.NewManagedProcess(pconf, logger.AsZap())
 
 	if err := m.process.Start(context.Background()); err != nil {
@@ -1122,6 +1162,7 @@ func (m *module) startProcess(
 		}
 		break
 	}
+	m.crashes.started()
 	return nil
 }
 
@@ -1136,13 +1177,14 @@ func (m *module) stopProcess() error {
 	// TODO(RSDK-2551): stop ignoring exit status 143 once Python modules handle
 	// SIGTERM correctly.
 	// Also ignore if error is that the process no longer exists.
-	if err := m.process.Stop(); err != nil {
-		if strings.Contains(err.Error(), errMessageExitStatus143) || strings.Contains(err.Error(), "no such process") {
-			return nil
-		}
-		return err
+	err := m.process.Stop()
+	if err != nil && (strings.Contains(err.Error(), errMessageExitStatus143) || strings.Contains(err.Error(), "no such process")) {
+		err = nil
 	}
-	return nil
+	if m.containerRuntime != "" {
+		err = multierr.Combine(err, m.removeContainer(m.containerRuntime))
+	}
+	return err
 }
 
 func (m *module) registerResources(mgr modmaninterface.ModuleManager, logger logging.Logger) {
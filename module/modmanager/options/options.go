@@ -17,6 +17,10 @@ type Options struct {
 	// RemoveOrphanedResources is a function that the module manager can call to
 	// remove orphaned resources from the resource graph.
 	RemoveOrphanedResources func(ctx context.Context, rNames []resource.Name)
+	// SetResourcesUnavailable is a function that the module manager calls with the resources of a
+	// crashed module, so that they fail fast rather than hang while it restarts. It's called again
+	// with a nil error once the module has restarted.
+	SetResourcesUnavailable func(rNames []resource.Name, err error)
 	// PackagesDir is from Config.PackagesPath. It's used for resolving local tarball module paths.
 	PackagesDir string
 }
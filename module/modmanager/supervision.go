@@ -0,0 +1,151 @@
+package modmanager
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+var (
+	// oueMaxRestartInterval caps the backoff between restarts of a module that keeps crashing.
+	oueMaxRestartInterval = time.Minute
+	// oueBackoffResetInterval is how long a module must run after starting for its next crash to
+	// be restarted from without backoff, rather than counted as part of a crash loop.
+	oueBackoffResetInterval = time.Minute
+	// outputTailLines is how many of the last lines of a module's output are kept to report why it crashed.
+	outputTailLines = 20
+	// outputTailMaxLineLength is how long a line of module output can get before it's cut off.
+	outputTailMaxLineLength = 4096
+)
+
+// restartBackoff returns how long to wait before the given restart, counted from 0, of a module
+// that keeps crashing. It doubles with each restart up to oueMaxRestartInterval.
+func restartBackoff(restart int) time.Duration {
+	backoff := oueRestartInterval
+	for i := 0; i < restart && backoff < oueMaxRestartInterval; i++ {
+		backoff *= 2
+	}
+	if backoff > oueMaxRestartInterval {
+		return oueMaxRestartInterval
+	}
+	return backoff
+}
+
+// crashState tracks a module process's crashes.
+type crashState struct {
+	mu        sync.Mutex
+	running   bool
+	startedAt time.Time
+	// consecutive counts the crashes of the module since it last ran for oueBackoffResetInterval.
+	consecutive   int
+	count         int
+	lastCrashedAt time.Time
+	lastExitCode  int
+	lastOutput    []string
+}
+
+func (s *crashState) started() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = true
+	s.startedAt = time.Now()
+}
+
+// crashed records a crash and returns how many crashes in a row the module has had.
+func (s *crashState) crashed(exitCode int, output []string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if now.Sub(s.startedAt) >= oueBackoffResetInterval {
+		s.consecutive = 0
+	}
+	s.running = false
+	s.consecutive++
+	s.count++
+	s.lastCrashedAt = now
+	s.lastExitCode = exitCode
+	s.lastOutput = output
+	return s.consecutive
+}
+
+// outputTail keeps the last lines a module process wrote to stdout or stderr.
+type outputTail struct {
+	mu      sync.Mutex
+	lines   []string
+	partial []byte
+}
+
+// Write implements io.Writer.
+func (t *outputTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.partial = append(t.partial, p...)
+	for {
+		i := bytes.IndexByte(t.partial, '\n')
+		if i < 0 {
+			if len(t.partial) <= outputTailMaxLineLength {
+				break
+			}
+			i = outputTailMaxLineLength
+		}
+		if len(t.lines) == outputTailLines {
+			t.lines = append(t.lines[:0], t.lines[1:]...)
+		}
+		t.lines = append(t.lines, string(t.partial[:i]))
+		if i < len(t.partial) && t.partial[i] == '\n' {
+			i++
+		}
+		t.partial = append([]byte(nil), t.partial[i:]...)
+	}
+	return len(p), nil
+}
+
+// last returns the lines kept, oldest first.
+func (t *outputTail) last() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.lines...)
+}
+
+// Statuses returns the status of each module's process, ordered by name.
+func (mgr *Manager) Statuses() []robot.ModuleStatus {
+	var statuses []robot.ModuleStatus
+	mgr.modules.Range(func(name string, mod *module) bool {
+		mod.crashes.mu.Lock()
+		statuses = append(statuses, robot.ModuleStatus{
+			Name:          name,
+			Running:       mod.crashes.running,
+			CrashCount:    mod.crashes.count,
+			LastCrashedAt: mod.crashes.lastCrashedAt,
+			LastExitCode:  mod.crashes.lastExitCode,
+			LastOutput:    append([]string(nil), mod.crashes.lastOutput...),
+		})
+		mod.crashes.mu.Unlock()
+		return true
+	})
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// setResourcesUnavailable makes the resources of a module unavailable for the given reason, or
+// available again if it's nil.
+func (mgr *Manager) setResourcesUnavailable(mod *module, err error) {
+	if mgr.markUnavailable == nil {
+		return
+	}
+	mod.resourcesMu.Lock()
+	names := make([]resource.Name, 0, len(mod.resources))
+	for name := range mod.resources {
+		names = append(names, name)
+	}
+	mod.resourcesMu.Unlock()
+	if len(names) > 0 {
+		mgr.markUnavailable(names, err)
+	}
+}
@@ -0,0 +1,66 @@
+package modmanager
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+)
+
+func TestRestartBackoff(t *testing.T) {
+	defer func(interval, maxInterval time.Duration) {
+		oueRestartInterval, oueMaxRestartInterval = interval, maxInterval
+	}(oueRestartInterval, oueMaxRestartInterval)
+	oueRestartInterval = time.Second
+	oueMaxRestartInterval = 10 * time.Second
+
+	test.That(t, restartBackoff(0), test.ShouldEqual, time.Second)
+	test.That(t, restartBackoff(1), test.ShouldEqual, 2*time.Second)
+	test.That(t, restartBackoff(3), test.ShouldEqual, 8*time.Second)
+	test.That(t, restartBackoff(4), test.ShouldEqual, 10*time.Second)
+	test.That(t, restartBackoff(1000), test.ShouldEqual, 10*time.Second)
+}
+
+func TestCrashState(t *testing.T) {
+	defer func(interval time.Duration) {
+		oueBackoffResetInterval = interval
+	}(oueBackoffResetInterval)
+	oueBackoffResetInterval = time.Hour
+
+	var crashes crashState
+	crashes.started()
+	test.That(t, crashes.running, test.ShouldBeTrue)
+	test.That(t, crashes.crashed(1, []string{"panic: oops"}), test.ShouldEqual, 1)
+	crashes.started()
+	test.That(t, crashes.crashed(2, nil), test.ShouldEqual, 2)
+	test.That(t, crashes.running, test.ShouldBeFalse)
+	test.That(t, crashes.count, test.ShouldEqual, 2)
+	test.That(t, crashes.lastExitCode, test.ShouldEqual, 2)
+
+	// a crash after running for a while isn't part of a crash loop
+	oueBackoffResetInterval = 0
+	crashes.started()
+	test.That(t, crashes.crashed(1, nil), test.ShouldEqual, 1)
+	test.That(t, crashes.count, test.ShouldEqual, 3)
+}
+
+func TestOutputTail(t *testing.T) {
+	var tail outputTail
+	// output is written a line, then its newline, at a time
+	for i := 0; i < outputTailLines+5; i++ {
+		fmt.Fprint(&tail, "line ", i)
+		fmt.Fprint(&tail, "\n")
+	}
+	fmt.Fprint(&tail, "partial")
+	lines := tail.last()
+	test.That(t, lines, test.ShouldHaveLength, outputTailLines)
+	test.That(t, lines[0], test.ShouldEqual, "line 5")
+	test.That(t, lines[len(lines)-1], test.ShouldEqual, fmt.Sprint("line ", outputTailLines+4))
+
+	fmt.Fprint(&tail, " line\n"+strings.Repeat("x", outputTailMaxLineLength+1))
+	lines = tail.last()
+	test.That(t, lines[len(lines)-2], test.ShouldEqual, "partial line")
+	test.That(t, lines[len(lines)-1], test.ShouldHaveLength, outputTailMaxLineLength)
+}
@@ -7,6 +7,7 @@ import (
 	"go.viam.com/rdk/config"
 	"go.viam.com/rdk/module"
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
 )
 
 // ModuleManager abstracts the module manager interface.
@@ -26,6 +27,7 @@ type ModuleManager interface {
 	Configs() []config.Module
 	Provides(cfg resource.Config) bool
 	Handles() map[string]module.HandlerMap
+	Statuses() []robot.ModuleStatus
 
 	Close(ctx context.Context) error
 }
@@ -37,6 +37,7 @@ import (
 	"go.viam.com/rdk/protoutils"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot/client"
+	"go.viam.com/rdk/tracing"
 	rutils "go.viam.com/rdk/utils"
 )
 
@@ -182,6 +183,7 @@ type Module struct {
 	collections             map[resource.API]resource.APIResourceCollection[resource.Resource]
 	resLoggers              map[resource.Resource]logging.Logger
 	closeOnce               sync.Once
+	stopTracing             func()
 	pc                      *webrtc.PeerConnection
 	pcReady                 <-chan struct{}
 	pcClosed                <-chan struct{}
@@ -196,9 +198,11 @@ func NewModule(ctx context.Context, address string, logger logging.Logger) (*Mod
 	opMgr := operation.NewManager(logger)
 	unaries := []grpc.UnaryServerInterceptor{
 		rgrpc.EnsureTimeoutUnaryServerInterceptor,
+		tracing.UnaryServerInterceptor,
 		opMgr.UnaryServerInterceptor,
 	}
 	streams := []grpc.StreamServerInterceptor{
+		tracing.StreamServerInterceptor,
 		opMgr.StreamServerInterceptor,
 	}
 	opts := []grpc.ServerOption{
@@ -221,6 +225,9 @@ func NewModule(ctx context.Context, address string, logger logging.Logger) (*Mod
 		collections:           map[resource.API]resource.APIResourceCollection[resource.Resource]{},
 		resLoggers:            map[resource.Resource]logging.Logger{},
 	}
+	if endpoint := os.Getenv(tracing.EndpointEnvVar); endpoint != "" {
+		m.stopTracing = tracing.StartOTLPExport(endpoint, filepath.Base(os.Args[0]), logger)
+	}
 	if err := m.server.RegisterServiceServer(ctx, &pb.ModuleService_ServiceDesc, m); err != nil {
 		return nil, err
 	}
@@ -321,6 +328,9 @@ func (m *Module) Close(ctx context.Context) {
 			m.logger.Error(err)
 		}
 		m.activeBackgroundWorkers.Wait()
+		if m.stopTracing != nil {
+			m.stopTracing()
+		}
 	})
 }
 
@@ -0,0 +1,89 @@
+package motionplan
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/mat"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// jacobianStep is how far each input is moved to numerically differentiate a frame's transform.
+const jacobianStep = 1e-6
+
+// JointLimitMargin returns the index of the input of a frame which is closest to one of its limits, and how far it is
+// from that limit as a fraction of the input's range. Inputs beyond their limits have a negative margin. Inputs
+// without finite limits, such as continuous joints, are never close to one; if no input has finite limits the index
+// is -1 and the margin is 1.
+func JointLimitMargin(f referenceframe.Frame, inputs []referenceframe.Input) (int, float64) {
+	closest, margin := -1, 1.
+	for i, limit := range f.DoF() {
+		if i >= len(inputs) {
+			break
+		}
+		span := limit.Max - limit.Min
+		if math.IsInf(span, 0) || span <= 0 {
+			continue
+		}
+		m := math.Min(inputs[i].Value-limit.Min, limit.Max-inputs[i].Value) / span
+		if m < margin {
+			closest, margin = i, m
+		}
+	}
+	return closest, margin
+}
+
+// Manipulability returns the manipulability measure of a frame at the given inputs, which is the product of the singular
+// values of its Jacobian, with translation in meters and rotation in radians. It is zero at a singularity, where the
+// frame can no longer move in some direction, and small near one, where reaching a pose can need large joint motion.
+func Manipulability(f referenceframe.Frame, inputs []referenceframe.Input) (float64, error) {
+	jacobian, err := frameJacobian(f, inputs)
+	if err != nil {
+		return 0, err
+	}
+	var svd mat.SVD
+	if !svd.Factorize(jacobian, mat.SVDNone) {
+		return 0, errors.New("could not factorize jacobian")
+	}
+	manipulability := 1.
+	for _, value := range svd.Values(nil) {
+		manipulability *= value
+	}
+	return manipulability, nil
+}
+
+// frameJacobian numerically differentiates the pose of a frame with respect to each of its inputs.
+func frameJacobian(f referenceframe.Frame, inputs []referenceframe.Input) (*mat.Dense, error) {
+	if len(inputs) != len(f.DoF()) {
+		return nil, referenceframe.NewIncorrectInputLengthError(len(inputs), len(f.DoF()))
+	}
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("frame %q has no inputs", f.Name())
+	}
+	// inputs beyond their limits are still differentiated, since only the shape of the transform matters here
+	pose, err := f.Transform(inputs)
+	if pose == nil {
+		return nil, err
+	}
+	jacobian := mat.NewDense(6, len(inputs), nil)
+	stepped := make([]referenceframe.Input, len(inputs))
+	for i := range inputs {
+		copy(stepped, inputs)
+		stepped[i].Value += jacobianStep
+		steppedPose, err := f.Transform(stepped)
+		if steppedPose == nil {
+			return nil, err
+		}
+		delta := spatialmath.PoseDelta(pose, steppedPose)
+		translation := delta.Point().Mul(1. / 1000)
+		rotation := spatialmath.QuatToR3AA(delta.Orientation().Quaternion())
+		jacobian.SetCol(i, []float64{
+			translation.X / jacobianStep, translation.Y / jacobianStep, translation.Z / jacobianStep,
+			rotation.X / jacobianStep, rotation.Y / jacobianStep, rotation.Z / jacobianStep,
+		})
+	}
+	return jacobian, nil
+}
@@ -0,0 +1,63 @@
+package motionplan
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	frame "go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/utils"
+)
+
+func TestJointLimitMargin(t *testing.T) {
+	m, err := frame.ParseModelJSONFile(utils.ResolveFile("components/arm/universalrobots/ur5e.json"), "")
+	test.That(t, err, test.ShouldBeNil)
+	limits := m.DoF()
+
+	inputs := make([]frame.Input, len(limits))
+	joint, margin := JointLimitMargin(m, inputs)
+	test.That(t, joint, test.ShouldBeGreaterThanOrEqualTo, 0)
+	test.That(t, margin, test.ShouldAlmostEqual, 0.5)
+
+	inputs[2] = frame.Input{Value: limits[2].Max - 0.01*(limits[2].Max-limits[2].Min)}
+	joint, margin = JointLimitMargin(m, inputs)
+	test.That(t, joint, test.ShouldEqual, 2)
+	test.That(t, margin, test.ShouldAlmostEqual, 0.01)
+
+	inputs[2] = frame.Input{Value: limits[2].Max + 0.1*(limits[2].Max-limits[2].Min)}
+	_, margin = JointLimitMargin(m, inputs)
+	test.That(t, margin, test.ShouldAlmostEqual, -0.1)
+
+	// unlimited inputs are never close to a limit
+	gantry, err := frame.NewTranslationalFrame("gantry", r3.Vector{X: 1}, frame.Limit{Min: math.Inf(-1), Max: math.Inf(1)})
+	test.That(t, err, test.ShouldBeNil)
+	joint, margin = JointLimitMargin(gantry, []frame.Input{{Value: 1e9}})
+	test.That(t, joint, test.ShouldEqual, -1)
+	test.That(t, margin, test.ShouldEqual, 1)
+}
+
+func TestManipulability(t *testing.T) {
+	m, err := frame.ParseModelJSONFile(utils.ResolveFile("components/arm/universalrobots/ur5e.json"), "")
+	test.That(t, err, test.ShouldBeNil)
+
+	// with its upper and lower arm in line, the arm cannot move its wrist along them
+	stretched, err := Manipulability(m, frame.FloatsToInputs([]float64{0, 0, 0, 0, math.Pi / 2, 0}))
+	test.That(t, err, test.ShouldBeNil)
+	bent, err := Manipulability(m, frame.FloatsToInputs([]float64{0, -math.Pi / 4, math.Pi / 2, -math.Pi / 4, math.Pi / 2, 0}))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, bent, test.ShouldBeGreaterThan, 1000*stretched)
+
+	_, err = Manipulability(m, frame.FloatsToInputs([]float64{0, 0}))
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = Manipulability(frame.NewZeroStaticFrame("static"), []frame.Input{})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// a gantry moves a millimeter for each unit of its input
+	gantry, err := frame.NewTranslationalFrame("gantry", r3.Vector{X: 1}, frame.Limit{Min: -1000, Max: 1000})
+	test.That(t, err, test.ShouldBeNil)
+	manipulability, err := Manipulability(gantry, []frame.Input{{Value: 0}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, manipulability, test.ShouldAlmostEqual, 0.001, 1e-6)
+}
@@ -23,6 +23,29 @@ var methodPrefixesToFilter = [...]string{
 	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
 }
 
+// readMethodPrefixes are how the names of resource API methods which only read state begin, such as
+// GetPosition, IsMoving, and Readings. Every other method, such as SetPower or DoCommand, may actuate.
+var readMethodPrefixes = [...]string{"Get", "Is", "Read", "List", "Stream"}
+
+// MethodTimeouts are how long the methods of an RPC service may run by default, depending on whether
+// they only read state or may actuate. Zero means no default.
+type MethodTimeouts struct {
+	Read      time.Duration
+	Actuation time.Duration
+}
+
+// IsReadMethod returns whether the full method, such as /viam.component.motor.v1.MotorService/GetPosition,
+// only reads state.
+func IsReadMethod(method string) bool {
+	name := method[strings.LastIndex(method, "/")+1:]
+	for _, prefix := range readMethodPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // Operation is an operation happening on the server.
 type Operation struct {
 	ID        uuid.UUID
@@ -30,10 +53,13 @@ type Operation struct {
 	Method    string
 	Arguments interface{}
 	Started   time.Time
+	// Deadline is when the operation will be cancelled if it has not finished, or zero if never.
+	Deadline time.Time
 
-	myManager *Manager
-	cancel    context.CancelFunc
-	labels    []string
+	myManager     *Manager
+	cancel        context.CancelFunc
+	cancelTimeout context.CancelFunc
+	labels        []string
 }
 
 // Cancel cancel the context associated with an operation.
@@ -67,19 +93,67 @@ func (o *Operation) CancelOtherWithLabel(label string) {
 }
 
 func (o *Operation) cleanup() {
+	if o.cancelTimeout != nil {
+		o.cancelTimeout()
+	}
 	o.myManager.remove(o.ID)
 }
 
 // NewManager creates a new manager for holding Operations.
 func NewManager(logger logging.Logger) *Manager {
-	return &Manager{ops: map[string]*Operation{}, logger: logger}
+	return &Manager{ops: map[string]*Operation{}, timeouts: map[string]MethodTimeouts{}, logger: logger}
 }
 
 // Manager holds Operations.
 type Manager struct {
-	ops    map[string]*Operation
-	lock   sync.Mutex
-	logger logging.Logger
+	ops map[string]*Operation
+	// timeouts maps RPC services, such as viam.component.motor.v1.MotorService, to how long
+	// operations on them may run by default.
+	timeouts map[string]MethodTimeouts
+	lock     sync.Mutex
+	logger   logging.Logger
+}
+
+// SetDefaultTimeouts sets how long operations on each RPC service, such as
+// viam.component.motor.v1.MotorService, may run before they are cancelled, unless the caller
+// already set an earlier deadline. It replaces any previous timeouts and only applies to
+// operations created afterwards.
+func (m *Manager) SetDefaultTimeouts(timeouts map[string]time.Duration) {
+	methodTimeouts := make(map[string]MethodTimeouts, len(timeouts))
+	for service, timeout := range timeouts {
+		methodTimeouts[service] = MethodTimeouts{Read: timeout, Actuation: timeout}
+	}
+	m.SetDefaultMethodTimeouts(methodTimeouts)
+}
+
+// SetDefaultMethodTimeouts is like SetDefaultTimeouts, but sets separate timeouts for the methods of
+// each RPC service which only read state and those which may actuate.
+func (m *Manager) SetDefaultMethodTimeouts(timeouts map[string]MethodTimeouts) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.timeouts = make(map[string]MethodTimeouts, len(timeouts))
+	for service, timeout := range timeouts {
+		m.timeouts[service] = timeout
+	}
+}
+
+// defaultTimeout returns the default timeout of method, if its RPC service has one for its kind of method.
+func (m *Manager) defaultTimeout(method string) (time.Duration, bool) {
+	service, _, ok := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	if !ok {
+		return 0, false
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	timeouts, ok := m.timeouts[service]
+	if !ok {
+		return 0, false
+	}
+	timeout := timeouts.Actuation
+	if IsReadMethod(method) {
+		timeout = timeouts.Read
+	}
+	return timeout, timeout > 0
 }
 
 func (m *Manager) remove(id uuid.UUID) {
@@ -152,6 +226,7 @@ func (m *Manager) createWithID(ctx context.Context, id uuid.UUID, method string,
 			method,
 		)
 		ctx = context.WithValue(ctx, opidKey, o)
+		ctx = logging.ContextWithFields(ctx, "operation_id", o.ID.String())
 		return ctx, func() {}
 	}
 
@@ -166,7 +241,15 @@ func (m *Manager) createWithID(ctx context.Context, id uuid.UUID, method string,
 		op.SessionID = sess.ID()
 	}
 	ctx = context.WithValue(ctx, opidKey, op)
+	// tag what is logged while handling the operation, so its log lines can be found together
+	ctx = logging.ContextWithFields(ctx, "operation_id", id.String())
 	ctx, op.cancel = context.WithCancel(ctx)
+	if timeout, ok := m.defaultTimeout(method); ok {
+		if deadline, ok := ctx.Deadline(); !ok || op.Started.Add(timeout).Before(deadline) {
+			ctx, op.cancelTimeout = context.WithDeadline(ctx, op.Started.Add(timeout))
+		}
+	}
+	op.Deadline, _ = ctx.Deadline()
 	m.add(op)
 
 	return ctx, func() { op.cleanup() }
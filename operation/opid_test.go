@@ -3,6 +3,7 @@ package operation
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"go.viam.com/test"
@@ -115,3 +116,69 @@ func TestCreateDuplicate(t *testing.T) {
 	cleanup()
 	test.That(t, op3Ctx.Err(), test.ShouldBeError, context.Canceled)
 }
+
+func TestDefaultTimeouts(t *testing.T) {
+	ctx := context.Background()
+	h := NewManager(logging.NewTestLogger(t))
+	h.SetDefaultTimeouts(map[string]time.Duration{"viam.component.motor.v1.MotorService": time.Minute})
+
+	ctx1, cleanup1 := h.Create(ctx, "/viam.component.motor.v1.MotorService/GoFor", nil)
+	defer cleanup1()
+	deadline, ok := ctx1.Deadline()
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, Get(ctx1).Deadline, test.ShouldEqual, deadline)
+	test.That(t, deadline, test.ShouldHappenWithin, time.Second, Get(ctx1).Started.Add(time.Minute))
+
+	// an earlier deadline set by the caller is kept.
+	shortCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	shortDeadline, _ := shortCtx.Deadline()
+	ctx2, cleanup2 := h.Create(shortCtx, "/viam.component.motor.v1.MotorService/GoFor", nil)
+	defer cleanup2()
+	deadline, ok = ctx2.Deadline()
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, deadline, test.ShouldEqual, shortDeadline)
+
+	ctx3, cleanup3 := h.Create(ctx, "/viam.component.arm.v1.ArmService/MoveToPosition", nil)
+	defer cleanup3()
+	_, ok = ctx3.Deadline()
+	test.That(t, ok, test.ShouldBeFalse)
+	test.That(t, Get(ctx3).Deadline.IsZero(), test.ShouldBeTrue)
+
+	h.SetDefaultTimeouts(map[string]time.Duration{"viam.component.motor.v1.MotorService": time.Millisecond})
+	ctx4, cleanup4 := h.Create(ctx, "/viam.component.motor.v1.MotorService/GoFor", nil)
+	defer cleanup4()
+	<-ctx4.Done()
+	test.That(t, ctx4.Err(), test.ShouldEqual, context.DeadlineExceeded)
+}
+
+func TestDefaultMethodTimeouts(t *testing.T) {
+	ctx := context.Background()
+	h := NewManager(logging.NewTestLogger(t))
+	h.SetDefaultMethodTimeouts(map[string]MethodTimeouts{
+		"viam.component.motor.v1.MotorService": {Read: time.Second, Actuation: time.Minute},
+		"viam.component.arm.v1.ArmService":     {Read: time.Second},
+	})
+
+	for _, tc := range []struct {
+		method  string
+		timeout time.Duration
+	}{
+		{"/viam.component.motor.v1.MotorService/GetPosition", time.Second},
+		{"/viam.component.motor.v1.MotorService/IsMoving", time.Second},
+		{"/viam.component.motor.v1.MotorService/GoFor", time.Minute},
+		{"/viam.component.motor.v1.MotorService/DoCommand", time.Minute},
+		{"/viam.component.arm.v1.ArmService/GetEndPosition", time.Second},
+		{"/viam.component.arm.v1.ArmService/MoveToPosition", 0},
+	} {
+		opCtx, cleanup := h.Create(ctx, tc.method, nil)
+		deadline, ok := opCtx.Deadline()
+		if tc.timeout == 0 {
+			test.That(t, ok, test.ShouldBeFalse)
+		} else {
+			test.That(t, ok, test.ShouldBeTrue)
+			test.That(t, deadline, test.ShouldHappenWithin, 100*time.Millisecond, Get(opCtx).Started.Add(tc.timeout))
+		}
+		cleanup()
+	}
+}
@@ -22,6 +22,10 @@ type LinkConfig struct {
 	Orientation *spatial.OrientationConfig `json:"orientation"`
 	Geometry    *spatial.GeometryConfig    `json:"geometry,omitempty"`
 	Parent      string                     `json:"parent,omitempty"`
+	// PoseSource optionally names a resource (e.g., a SLAM service) that reports where this frame currently is
+	// in its parent. When set, the frame moves with the source, and the translation and orientation above are
+	// applied as an offset on top of the reported pose.
+	PoseSource string `json:"pose_source,omitempty"`
 }
 
 // JointConfig is a frame with nonzero DOF. Supports rotational or translational.
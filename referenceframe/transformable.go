@@ -67,6 +67,18 @@ func (pF *PoseInFrame) String() string {
 	return fmt.Sprintf("parent: %s, pose: %s", pF.parent, spatialmath.PoseToProtobuf(pF.pose))
 }
 
+// NewPoseInFrameFromGeoPose expresses geoPose as a pose in the given frame, where origin is the GeoPose of that
+// frame's origin. The pose is relative to the origin's heading and altitude, as with spatialmath.GeoPoseToPose.
+func NewPoseInFrameFromGeoPose(frame string, geoPose, origin *spatialmath.GeoPose) *PoseInFrame {
+	return NewPoseInFrame(frame, spatialmath.GeoPoseToPose(geoPose, origin))
+}
+
+// GeoPose is the inverse of NewPoseInFrameFromGeoPose: given the GeoPose of the origin of the frame pF was
+// observed in, it returns where pF is in the world.
+func (pF *PoseInFrame) GeoPose(origin *spatialmath.GeoPose) *spatialmath.GeoPose {
+	return spatialmath.PoseToGeoPose(origin, pF.pose)
+}
+
 // LinkInFrame is a PoseInFrame plus a Geometry.
 type LinkInFrame struct {
 	*PoseInFrame
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
 	"go.viam.com/test"
 
 	spatial "go.viam.com/rdk/spatialmath"
@@ -41,3 +42,15 @@ func TestGeometriesInFrame(t *testing.T) {
 	test.That(t, gF.Parent(), test.ShouldEqual, convertedGF.Parent())
 	test.That(t, spatial.GeometriesAlmostEqual(one, convertedGF.GeometryByName("one")), test.ShouldBeTrue)
 }
+
+func TestPoseInFrameGeoPose(t *testing.T) {
+	origin := spatial.NewGeoPoseWithAltitude(geo.NewPoint(40, -74), 10, 0)
+	pose := spatial.NewPose(r3.Vector{X: 1000, Y: 2000, Z: 3000}, &spatial.OrientationVectorDegrees{OZ: 1, Theta: 0})
+
+	gp := NewPoseInFrame(World, pose).GeoPose(origin)
+	test.That(t, gp.Altitude(), test.ShouldAlmostEqual, 13)
+
+	pF := NewPoseInFrameFromGeoPose(World, gp, origin)
+	test.That(t, pF.Parent(), test.ShouldEqual, World)
+	test.That(t, spatial.PoseAlmostEqualEps(pF.Pose(), pose, 1e-3), test.ShouldBeTrue)
+}
@@ -0,0 +1,304 @@
+package resource
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/utils"
+)
+
+// These are the keys and commands of the asynchronous DoCommand protocol, in which a command that
+// can take minutes (homing, calibration, firmware updates) returns an operation ID straight away
+// and then runs in the background, rather than blocking a unary RPC until it times out. The caller
+// follows the operation with:
+//   - "get_operation", which returns its status immediately.
+//   - "wait_for_operation", which returns its status as soon as it reports progress or finishes, or
+//     after "timeout_seconds" (default 10) if neither happens, so that repeatedly calling it streams
+//     progress.
+//   - "cancel_operation", which cancels its context.
+//
+// Each of these takes the "operation_id". A status has the "operation_id", the "command" that started
+// the operation, its "state", its "progress" as a fraction from 0 to 1, the latest progress "message",
+// and, once finished, either its "result" or its "error".
+const (
+	AsyncOperationIDKey     = "operation_id"
+	GetOperationCommand     = "get_operation"
+	WaitForOperationCommand = "wait_for_operation"
+	CancelOperationCommand  = "cancel_operation"
+)
+
+// The states of an asynchronous operation.
+const (
+	AsyncOperationRunning   = "running"
+	AsyncOperationSucceeded = "succeeded"
+	AsyncOperationFailed    = "failed"
+	AsyncOperationCanceled  = "canceled"
+)
+
+const (
+	defaultAsyncWaitTimeout = 10 * time.Second
+	// finished operations are forgotten after this long.
+	asyncOperationRetention = 5 * time.Minute
+)
+
+// ProgressFunc reports how far along an asynchronous command is, as a fraction from 0 to 1, along
+// with a description of what it is doing.
+type ProgressFunc func(fraction float64, message string)
+
+// AsyncCommandFunc is the body of an asynchronous command. ctx is canceled if the operation is
+// canceled or the resource is closed.
+type AsyncCommandFunc func(ctx context.Context, progress ProgressFunc) (map[string]interface{}, error)
+
+// AsyncCommands runs a resource's long-running DoCommands in the background, and answers the
+// commands of the asynchronous DoCommand protocol about them. A resource holding one should pass
+// the commands it doesn't recognize to its DoCommand, and Close it when the resource closes.
+type AsyncCommands struct {
+	mu         sync.Mutex
+	operations map[string]*asyncOperation
+	workers    utils.StoppableWorkers
+}
+
+type asyncOperation struct {
+	id      string
+	command string
+	cancel  func()
+
+	// these are guarded by AsyncCommands.mu
+	state    string
+	progress float64
+	message  string
+	result   map[string]interface{}
+	err      error
+	finished time.Time
+	// updated is closed and replaced whenever anything above changes.
+	updated chan struct{}
+}
+
+// NewAsyncCommands returns an AsyncCommands with no operations.
+func NewAsyncCommands() *AsyncCommands {
+	return &AsyncCommands{
+		operations: map[string]*asyncOperation{},
+		workers:    utils.NewStoppableWorkers(),
+	}
+}
+
+// Start runs fn in the background as the given command, and returns the response to send back to
+// the caller, which holds the new operation's ID.
+func (ac *AsyncCommands) Start(command string, fn AsyncCommandFunc) map[string]interface{} {
+	ctx, cancel := context.WithCancel(ac.workers.Context())
+	op := &asyncOperation{
+		id:      uuid.NewString(),
+		command: command,
+		cancel:  cancel,
+		state:   AsyncOperationRunning,
+		updated: make(chan struct{}),
+	}
+
+	ac.mu.Lock()
+	ac.pruneLocked()
+	ac.operations[op.id] = op
+	ac.mu.Unlock()
+
+	if ctx.Err() != nil {
+		ac.finish(op, nil, errors.New("resource is closed"))
+	} else {
+		ac.workers.AddWorkers(func(context.Context) {
+			defer cancel()
+			result, err := fn(ctx, func(fraction float64, message string) {
+				ac.mu.Lock()
+				defer ac.mu.Unlock()
+				if op.state != AsyncOperationRunning {
+					return
+				}
+				op.progress = fraction
+				op.message = message
+				op.notifyLocked()
+			})
+			ac.finish(op, result, err)
+		})
+	}
+	return map[string]interface{}{AsyncOperationIDKey: op.id}
+}
+
+func (ac *AsyncCommands) finish(op *asyncOperation, result map[string]interface{}, err error) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	// a canceled operation stays canceled, whatever it returns
+	if op.state == AsyncOperationRunning {
+		if err != nil {
+			op.state = AsyncOperationFailed
+			op.err = err
+		} else {
+			op.state = AsyncOperationSucceeded
+			op.progress = 1
+			op.result = result
+		}
+	}
+	op.finished = time.Now()
+	op.notifyLocked()
+}
+
+func (op *asyncOperation) notifyLocked() {
+	close(op.updated)
+	op.updated = make(chan struct{})
+}
+
+func (ac *AsyncCommands) pruneLocked() {
+	for id, op := range ac.operations {
+		if !op.finished.IsZero() && time.Since(op.finished) > asyncOperationRetention {
+			delete(ac.operations, id)
+		}
+	}
+}
+
+func (op *asyncOperation) statusLocked() map[string]interface{} {
+	status := map[string]interface{}{
+		AsyncOperationIDKey: op.id,
+		"command":           op.command,
+		"state":             op.state,
+		"progress":          op.progress,
+		"message":           op.message,
+	}
+	if op.result != nil {
+		status["result"] = op.result
+	}
+	if op.err != nil {
+		status["error"] = op.err.Error()
+	}
+	return status
+}
+
+// DoCommand answers the commands of the asynchronous DoCommand protocol. handled is false if cmd is
+// not one of them, in which case the caller should handle it itself.
+func (ac *AsyncCommands) DoCommand(
+	ctx context.Context,
+	cmd map[string]interface{},
+) (resp map[string]interface{}, handled bool, err error) {
+	name, _ := cmd["command"].(string)
+	switch name {
+	case GetOperationCommand, WaitForOperationCommand, CancelOperationCommand:
+	default:
+		return nil, false, nil
+	}
+
+	id, _ := cmd[AsyncOperationIDKey].(string)
+	ac.mu.Lock()
+	ac.pruneLocked()
+	op, ok := ac.operations[id]
+	if !ok {
+		ac.mu.Unlock()
+		return nil, true, errors.Errorf("no operation with id %q", id)
+	}
+
+	switch name {
+	case CancelOperationCommand:
+		if op.state == AsyncOperationRunning {
+			op.state = AsyncOperationCanceled
+			op.cancel()
+			op.notifyLocked()
+		}
+	case WaitForOperationCommand:
+		if op.state == AsyncOperationRunning {
+			timeout := defaultAsyncWaitTimeout
+			if seconds, ok := cmd["timeout_seconds"].(float64); ok {
+				timeout = time.Duration(seconds * float64(time.Second))
+			}
+			updated := op.updated
+			ac.mu.Unlock()
+			timer := time.NewTimer(timeout)
+			select {
+			case <-updated:
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, true, ctx.Err()
+			}
+			timer.Stop()
+			ac.mu.Lock()
+		}
+	default:
+	}
+	resp = op.statusLocked()
+	ac.mu.Unlock()
+	return resp, true, nil
+}
+
+// Close cancels all running operations and waits for them to return.
+func (ac *AsyncCommands) Close() {
+	ac.workers.Stop()
+
+	// fail anything which was started too late to run
+	ac.mu.Lock()
+	var unstarted []*asyncOperation
+	for _, op := range ac.operations {
+		if op.finished.IsZero() {
+			unstarted = append(unstarted, op)
+		}
+	}
+	ac.mu.Unlock()
+	for _, op := range unstarted {
+		ac.finish(op, nil, errors.New("resource is closed"))
+	}
+}
+
+// WaitForAsyncCommand follows the operation started by an asynchronous DoCommand on res until it
+// finishes, given the response to the command that started it, and returns its result. If progress
+// is not nil, it is called whenever the operation reports progress. If ctx is canceled, the
+// operation is canceled too.
+func WaitForAsyncCommand(
+	ctx context.Context,
+	res Resource,
+	startResp map[string]interface{},
+	progress ProgressFunc,
+) (map[string]interface{}, error) {
+	id, ok := startResp[AsyncOperationIDKey].(string)
+	if !ok {
+		return nil, errors.Errorf("response has no %q", AsyncOperationIDKey)
+	}
+	var lastMessage string
+	lastProgress := -1.
+	for {
+		status, err := res.DoCommand(ctx, map[string]interface{}{
+			"command":           WaitForOperationCommand,
+			AsyncOperationIDKey: id,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				// use a fresh context, since ours is already done
+				//nolint:contextcheck
+				if _, cancelErr := res.DoCommand(context.Background(), map[string]interface{}{
+					"command":           CancelOperationCommand,
+					AsyncOperationIDKey: id,
+				}); cancelErr != nil {
+					return nil, errors.Wrapf(err, "failed to cancel operation: %v", cancelErr)
+				}
+			}
+			return nil, err
+		}
+
+		fraction, _ := status["progress"].(float64)
+		message, _ := status["message"].(string)
+		if progress != nil && (fraction != lastProgress || message != lastMessage) {
+			progress(fraction, message)
+			lastProgress, lastMessage = fraction, message
+		}
+
+		switch status["state"] {
+		case AsyncOperationRunning:
+		case AsyncOperationSucceeded:
+			result, _ := status["result"].(map[string]interface{})
+			return result, nil
+		case AsyncOperationFailed:
+			msg, _ := status["error"].(string)
+			return nil, errors.New(msg)
+		case AsyncOperationCanceled:
+			return nil, errors.Errorf("operation %s was canceled", id)
+		default:
+			return nil, errors.Errorf("operation %s is in unknown state %v", id, status["state"])
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package resource_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/resource"
+)
+
+// homingResource has one asynchronous command, "home", which reports progress in steps until
+// released.
+type homingResource struct {
+	resource.Named
+	resource.TriviallyReconfigurable
+	async *resource.AsyncCommands
+	step  chan struct{}
+}
+
+func newHomingResource() *homingResource {
+	return &homingResource{
+		Named: resource.NewName(resource.APINamespaceRDK.WithComponentType("gantry"), "g").AsNamed(),
+		async: resource.NewAsyncCommands(),
+		step:  make(chan struct{}),
+	}
+}
+
+func (h *homingResource) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if resp, handled, err := h.async.DoCommand(ctx, cmd); handled {
+		return resp, err
+	}
+	switch cmd["command"] {
+	case "home":
+		fail, _ := cmd["fail"].(bool)
+		return h.async.Start("home", func(ctx context.Context, progress resource.ProgressFunc) (map[string]interface{}, error) {
+			for i := 0; i < 2; i++ {
+				select {
+				case <-h.step:
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+				progress(float64(i+1)/2, fmt.Sprintf("homed axis %d", i))
+			}
+			if fail {
+				return nil, errors.New("limit switch stuck")
+			}
+			return map[string]interface{}{"position": 0.}, nil
+		}), nil
+	default:
+		return nil, fmt.Errorf("no such command: %s", cmd["command"])
+	}
+}
+
+func (h *homingResource) Close(ctx context.Context) error {
+	h.async.Close()
+	return nil
+}
+
+func TestAsyncCommands(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("progress and result", func(t *testing.T) {
+		h := newHomingResource()
+		defer func() {
+			test.That(t, h.Close(ctx), test.ShouldBeNil)
+		}()
+		started, err := h.DoCommand(ctx, map[string]interface{}{"command": "home"})
+		test.That(t, err, test.ShouldBeNil)
+		id := started[resource.AsyncOperationIDKey]
+		test.That(t, id, test.ShouldNotBeEmpty)
+
+		status, err := h.DoCommand(ctx, map[string]interface{}{"command": resource.GetOperationCommand, resource.AsyncOperationIDKey: id})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, status["state"], test.ShouldEqual, resource.AsyncOperationRunning)
+		test.That(t, status["command"], test.ShouldEqual, "home")
+		test.That(t, status["progress"], test.ShouldEqual, 0.)
+
+		// waiting times out if nothing happens
+		status, err = h.DoCommand(ctx, map[string]interface{}{
+			"command":                    resource.WaitForOperationCommand,
+			resource.AsyncOperationIDKey: id,
+			"timeout_seconds":            0.01,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, status["state"], test.ShouldEqual, resource.AsyncOperationRunning)
+
+		// home the second axis only once the first has been reported
+		go func() {
+			h.step <- struct{}{}
+		}()
+		var progress []string
+		result, err := resource.WaitForAsyncCommand(ctx, h, started, func(fraction float64, message string) {
+			progress = append(progress, fmt.Sprintf("%.1f %s", fraction, message))
+			if fraction == 0.5 {
+				go func() {
+					h.step <- struct{}{}
+				}()
+			}
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, result, test.ShouldResemble, map[string]interface{}{"position": 0.})
+		test.That(t, progress, test.ShouldResemble, []string{"0.5 homed axis 0", "1.0 homed axis 1"})
+
+		status, err = h.DoCommand(ctx, map[string]interface{}{"command": resource.GetOperationCommand, resource.AsyncOperationIDKey: id})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, status["state"], test.ShouldEqual, resource.AsyncOperationSucceeded)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		h := newHomingResource()
+		defer func() {
+			test.That(t, h.Close(ctx), test.ShouldBeNil)
+		}()
+		started, err := h.DoCommand(ctx, map[string]interface{}{"command": "home", "fail": true})
+		test.That(t, err, test.ShouldBeNil)
+		go func() {
+			h.step <- struct{}{}
+			h.step <- struct{}{}
+		}()
+		_, err = resource.WaitForAsyncCommand(ctx, h, started, nil)
+		test.That(t, err, test.ShouldBeError, errors.New("limit switch stuck"))
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		h := newHomingResource()
+		defer func() {
+			test.That(t, h.Close(ctx), test.ShouldBeNil)
+		}()
+		started, err := h.DoCommand(ctx, map[string]interface{}{"command": "home"})
+		test.That(t, err, test.ShouldBeNil)
+
+		cancelCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		_, err = resource.WaitForAsyncCommand(cancelCtx, h, started, nil)
+		test.That(t, err, test.ShouldBeError, context.DeadlineExceeded)
+
+		status, err := h.DoCommand(ctx, map[string]interface{}{
+			"command":                    resource.GetOperationCommand,
+			resource.AsyncOperationIDKey: started[resource.AsyncOperationIDKey],
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, status["state"], test.ShouldEqual, resource.AsyncOperationCanceled)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		h := newHomingResource()
+		started, err := h.DoCommand(ctx, map[string]interface{}{"command": "home"})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, h.Close(ctx), test.ShouldBeNil)
+
+		status, err := h.DoCommand(ctx, map[string]interface{}{
+			"command":                    resource.GetOperationCommand,
+			resource.AsyncOperationIDKey: started[resource.AsyncOperationIDKey],
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, status["state"], test.ShouldEqual, resource.AsyncOperationFailed)
+		test.That(t, status["error"], test.ShouldEqual, context.Canceled.Error())
+
+		// operations started after closing fail straight away
+		started, err = h.DoCommand(ctx, map[string]interface{}{"command": "home"})
+		test.That(t, err, test.ShouldBeNil)
+		_, err = resource.WaitForAsyncCommand(ctx, h, started, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("unknown operation", func(t *testing.T) {
+		h := newHomingResource()
+		defer func() {
+			test.That(t, h.Close(ctx), test.ShouldBeNil)
+		}()
+		_, err := h.DoCommand(ctx, map[string]interface{}{"command": resource.GetOperationCommand, resource.AsyncOperationIDKey: "nope"})
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = h.DoCommand(ctx, map[string]interface{}{"command": "dance"})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
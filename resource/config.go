@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/go-viper/mapstructure/v2"
@@ -208,6 +209,35 @@ func (conf Config) Equals(other Config) bool {
 	return reflect.DeepEqual(conf, other)
 }
 
+// AttributeDiff compares conf to other, a later config of the same resource. It returns which
+// top-level attributes differ, and whether anything else that can affect the resource differs,
+// such as its model, frame, or dependencies. Log configuration is ignored since it is applied
+// without involving the resource.
+func (conf Config) AttributeDiff(other Config) (changedAttrs []string, otherChanged bool) {
+	for name, value := range conf.Attributes {
+		if otherValue, ok := other.Attributes[name]; !ok || !reflect.DeepEqual(value, otherValue) {
+			changedAttrs = append(changedAttrs, name)
+		}
+	}
+	for name := range other.Attributes {
+		if _, ok := conf.Attributes[name]; !ok {
+			changedAttrs = append(changedAttrs, name)
+		}
+	}
+	sort.Strings(changedAttrs)
+
+	deps, otherDeps := conf.Dependencies(), other.Dependencies()
+	sort.Strings(deps)
+	sort.Strings(otherDeps)
+	otherChanged = !reflect.DeepEqual(deps, otherDeps)
+
+	// These `Config` objects are copies, so clearing what was already compared does not
+	// impact the original versions.
+	conf.Attributes, other.Attributes = nil, nil
+	conf.LogConfiguration, other.LogConfiguration = LogConfig{}, LogConfig{}
+	return changedAttrs, otherChanged || !conf.Equals(other)
+}
+
 // Dependencies returns the deduplicated union of user-defined and implicit dependencies.
 func (conf *Config) Dependencies() []string {
 	result := make([]string, 0, len(conf.DependsOn)+len(conf.ImplicitDependsOn))
@@ -9,9 +9,12 @@ import (
 	"go.viam.com/rdk/components/base"
 	"go.viam.com/rdk/components/movementsensor"
 	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/services/motion"
 	"go.viam.com/rdk/testutils"
+	"go.viam.com/rdk/utils"
 )
 
 var (
@@ -650,3 +653,35 @@ func TestEqual(t *testing.T) {
 		})
 	})
 }
+
+func TestAttributeDiff(t *testing.T) {
+	confA := resource.Config{
+		Name:       "foo",
+		API:        arm.API,
+		Model:      fakeModel,
+		Attributes: utils.AttributeMap{"speed": 1.0, "port": "/dev/tty0", "gone": true},
+		DependsOn:  []string{"a", "b"},
+	}
+
+	changed, otherChanged := confA.AttributeDiff(confA)
+	test.That(t, changed, test.ShouldBeEmpty)
+	test.That(t, otherChanged, test.ShouldBeFalse)
+
+	confB := confA
+	confB.Attributes = utils.AttributeMap{"speed": 2.0, "port": "/dev/tty0", "added": 1}
+	confB.DependsOn = []string{"b", "a"}
+	confB.LogConfiguration = resource.LogConfig{Level: logging.DEBUG}
+	changed, otherChanged = confA.AttributeDiff(confB)
+	test.That(t, changed, test.ShouldResemble, []string{"added", "gone", "speed"})
+	test.That(t, otherChanged, test.ShouldBeFalse)
+
+	confB.DependsOn = []string{"a"}
+	_, otherChanged = confA.AttributeDiff(confB)
+	test.That(t, otherChanged, test.ShouldBeTrue)
+
+	confB = confA
+	confB.Frame = &referenceframe.LinkConfig{Parent: "world"}
+	changed, otherChanged = confA.AttributeDiff(confB)
+	test.That(t, changed, test.ShouldBeEmpty)
+	test.That(t, otherChanged, test.ShouldBeTrue)
+}
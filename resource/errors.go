@@ -68,6 +68,28 @@ func (e *mustRebuildError) Error() string {
 	return fmt.Sprintf("cannot reconfigure %q; must rebuild", e.name)
 }
 
+// NewNotBuiltError is returned when configuring a model that was left out of this build of the
+// RDK, such as by the slim build tag, with the reason it was left out.
+func NewNotBuiltError(api API, model Model, reason string) error {
+	return &notBuiltError{api: api, model: model, reason: reason}
+}
+
+// IsNotBuiltError returns whether or not the given error is a NotBuiltError.
+func IsNotBuiltError(err error) bool {
+	var errArt *notBuiltError
+	return errors.As(err, &errArt)
+}
+
+type notBuiltError struct {
+	api    API
+	model  Model
+	reason string
+}
+
+func (e *notBuiltError) Error() string {
+	return fmt.Sprintf("%s model %s is not built into this viam-server: %s", e.api, e.model, e.reason)
+}
+
 // DependencyNotFoundError is used when a resource is not found in a dependencies.
 func DependencyNotFoundError(name Name) error {
 	// This error represents a logical configuration error. No need to include a stack trace.
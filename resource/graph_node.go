@@ -54,6 +54,12 @@ type GraphNode struct {
 	current      Resource
 	currentModel Model
 	config       Config
+	// appliedConfig is the config current was last built or reconfigured with, or nil if there
+	// is no current resource.
+	appliedConfig *Config
+	// dependenciesUpdated is whether the node was asked to reconfigure because its dependencies
+	// were updated since current was last built or reconfigured.
+	dependenciesUpdated bool
 	// lastReconfigured returns a pointer to the time at which the resource within this
 	// GraphNode was constructed or last reconfigured. It returns nil if the GraphNode is
 	// unconfigured.
@@ -61,14 +67,64 @@ type GraphNode struct {
 	lastErr                   error
 	unresolvedDependencies    []string
 	needsDependencyResolution bool
+	// unavailableErr is why the resource can't be used for now even though it's built, ex: because
+	// the module serving it crashed and is restarting. Unlike lastErr, it doesn't need a reconfigure
+	// to clear.
+	unavailableErr error
 
 	logger logging.Logger
+	// logLevelOverride, if set, is the log level set at runtime, which takes precedence over the
+	// level in the config.
+	logLevelOverride *logging.Level
 
 	// state stores the current lifecycle state for a resource node.
 	state NodeState
 	// transitionedAt stores the timestamp of when resource entered its current lifecycle
 	// state.
 	transitionedAt time.Time
+	// lastSucceededAt is when an operation on the resource last completed without error.
+	lastSucceededAt time.Time
+	// consecutiveFailures counts the operations on the resource which have failed since one last
+	// succeeded or it was last built or reconfigured.
+	consecutiveFailures int
+	// history holds the most recent changes to the node's state or error, oldest first.
+	history []NodeTransition
+}
+
+// maxNodeHistory is how many transitions each node remembers.
+const maxNodeHistory = 50
+
+// NodeTransition is a change to a resource node's state or error.
+type NodeTransition struct {
+	State NodeState
+	// Error is the error the resource had after the change, if any.
+	Error error
+	At    time.Time
+}
+
+// NodeStatus is a snapshot of a resource node's lifecycle, used to report its health.
+type NodeStatus struct {
+	State          NodeState
+	TransitionedAt time.Time
+	// LastReconfigured is when the resource was constructed or last reconfigured, and is nil if it
+	// never has been.
+	LastReconfigured *time.Time
+	// Error is the error keeping the resource from being used, if any.
+	Error                  error
+	UnresolvedDependencies []string
+	// LastSucceededAt is when an operation on the resource last completed without error, and is
+	// zero if none has.
+	LastSucceededAt time.Time
+	// ConsecutiveFailures counts the operations on the resource which have failed since one last
+	// succeeded or it was last built or reconfigured.
+	ConsecutiveFailures int
+	// History is the most recent changes to the resource's state or error, oldest first.
+	History []NodeTransition
+}
+
+// Healthy returns whether the resource is ready and usable.
+func (s NodeStatus) Healthy() bool {
+	return s.State == NodeStateReady && s.Error == nil
 }
 
 var (
@@ -78,9 +134,11 @@ var (
 
 // NewUninitializedNode returns a node that is brand new and not yet initialized.
 func NewUninitializedNode() *GraphNode {
+	now := time.Now()
 	return &GraphNode{
 		state:          NodeStateUnconfigured,
-		transitionedAt: time.Now(),
+		transitionedAt: now,
+		history:        []NodeTransition{{State: NodeStateUnconfigured, At: now}},
 	}
 }
 
@@ -134,8 +192,8 @@ func (w *GraphNode) Resource() (Resource, error) {
 	if w.state == NodeStateRemoving {
 		return nil, errPendingRemoval
 	}
-	if w.lastErr != nil {
-		return nil, w.lastErr
+	if err := w.err(); err != nil {
+		return nil, err
 	}
 	if w.current == nil {
 		return nil, errNotInitalized
@@ -143,6 +201,15 @@ func (w *GraphNode) Resource() (Resource, error) {
 	return w.current, nil
 }
 
+// err returns the error keeping the resource from being used, if any. This method is not
+// thread-safe and must be called while holding a lock on `mu` if accessed concurrently.
+func (w *GraphNode) err() error {
+	if w.lastErr != nil {
+		return w.lastErr
+	}
+	return w.unavailableErr
+}
+
 // State return the current lifecycle state for a resource node.
 func (w *GraphNode) State() NodeState {
 	w.mu.RLock()
@@ -158,9 +225,50 @@ func (w *GraphNode) TransitionedAt() time.Time {
 	return w.transitionedAt
 }
 
-// InitializeLogger initializes the logger object associated with this resource node.
+// Status returns a snapshot of the node's lifecycle.
+func (w *GraphNode) Status() NodeStatus {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	var unresolved []string
+	if len(w.unresolvedDependencies) != 0 {
+		unresolved = append(unresolved, w.unresolvedDependencies...)
+	}
+	return NodeStatus{
+		State:                  w.state,
+		TransitionedAt:         w.transitionedAt,
+		LastReconfigured:       w.lastReconfigured,
+		Error:                  w.err(),
+		UnresolvedDependencies: unresolved,
+		LastSucceededAt:        w.lastSucceededAt,
+		ConsecutiveFailures:    w.consecutiveFailures,
+		History:                append([]NodeTransition(nil), w.history...),
+	}
+}
+
+// MarkOperationSucceeded records that an operation on the resource just completed without error.
+func (w *GraphNode) MarkOperationSucceeded() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastSucceededAt = time.Now()
+	w.consecutiveFailures = 0
+}
+
+// MarkOperationFailed records that an operation on the resource just failed.
+func (w *GraphNode) MarkOperationFailed() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.consecutiveFailures++
+}
+
+// InitializeLogger initializes the logger object associated with this resource node. Every entry it
+// logs is tagged with the resource and its model.
 func (w *GraphNode) InitializeLogger(parent logging.Logger, subname string, level logging.Level) {
-	logger := parent.Sublogger(subname)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	logger := logging.WithFields(parent.Sublogger(subname), "resource", subname, "model", w.config.Model.String())
+	if w.logLevelOverride != nil {
+		level = *w.logLevelOverride
+	}
 	logger.SetLevel(level)
 	w.logger = logger
 }
@@ -175,11 +283,39 @@ func (w *GraphNode) Logger() logging.Logger {
 // entry point for changing log levels. Which will affect whether models making log calls are
 // suppressed or not.
 func (w *GraphNode) SetLogLevel(level logging.Level) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.logLevelOverride != nil {
+		level = *w.logLevelOverride
+	}
 	if w.logger != nil {
 		w.logger.SetLevel(level)
 	}
 }
 
+// OverrideLogLevel sets the log level at runtime, regardless of the level in the config, until it is
+// overridden again. A nil level restores the level in the config.
+func (w *GraphNode) OverrideLogLevel(level *logging.Level) {
+	w.mu.Lock()
+	w.logLevelOverride = level
+	w.mu.Unlock()
+	w.SetLogLevel(w.Config().LogConfiguration.Level)
+}
+
+// LogLevel returns the level the resource is logging at, and whether it was set at runtime rather
+// than by the config.
+func (w *GraphNode) LogLevel() (logging.Level, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.logLevelOverride != nil {
+		return *w.logLevelOverride, true
+	}
+	if w.logger != nil {
+		return w.logger.GetLevel(), false
+	}
+	return w.config.LogConfiguration.Level, false
+}
+
 // UnsafeResource always returns the underlying resource, if
 // initialized, even if it is in an error state. This should
 // only be called during reconfiguration.
@@ -205,7 +341,7 @@ func (w *GraphNode) ResourceModel() Model {
 func (w *GraphNode) HasResource() bool {
 	w.mu.RLock()
 	defer w.mu.RUnlock()
-	return w.state != NodeStateRemoving && w.lastErr == nil && w.current != nil
+	return w.state != NodeStateRemoving && w.err() == nil && w.current != nil
 }
 
 // IsUninitialized returns if this resource is in an uninitialized state.
@@ -234,8 +370,15 @@ func (w *GraphNode) SwapResource(newRes Resource, newModel Model) {
 	defer w.mu.Unlock()
 	w.current = newRes
 	w.currentModel = newModel
+	appliedConfig := w.config
+	w.appliedConfig = &appliedConfig
+	w.dependenciesUpdated = false
 	w.lastErr = nil
+	w.unavailableErr = nil
+	w.consecutiveFailures = 0
 	w.transitionTo(NodeStateReady)
+	// an error being cleared is worth remembering even if the resource was already ready
+	w.recordTransition(time.Now())
 
 	// these should already be set
 	w.unresolvedDependencies = nil
@@ -271,6 +414,7 @@ func (w *GraphNode) LogAndSetLastError(err error, args ...any) {
 	w.mu.Lock()
 	w.lastErr = err
 	// TODO(RSDK-7903): transition to an "unhealthy" state.
+	w.recordTransition(time.Now())
 	w.mu.Unlock()
 
 	if w.logger != nil {
@@ -278,6 +422,15 @@ func (w *GraphNode) LogAndSetLastError(err error, args ...any) {
 	}
 }
 
+// SetUnavailable makes the resource unavailable to external users of the graph for the given reason,
+// without closing it or marking it as needing reconfiguration. A nil error makes it available again.
+func (w *GraphNode) SetUnavailable(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.unavailableErr = err
+	w.recordTransition(time.Now())
+}
+
 // Config returns the current config that this resource is using.
 // This value should only be assumed to be associated with the current
 // resource.
@@ -287,6 +440,20 @@ func (w *GraphNode) Config() Config {
 	return w.config
 }
 
+// ConfigChanges returns how the node's config has changed since its resource was last built or
+// reconfigured: which top-level attributes changed, and whether anything else that can affect
+// the resource did, including its dependencies being updated or an attempt to reconfigure it
+// having failed. ok is false if there is no resource to compare against.
+func (w *GraphNode) ConfigChanges() (changedAttrs []string, otherChanged, ok bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.current == nil || w.appliedConfig == nil {
+		return nil, false, false
+	}
+	changedAttrs, otherChanged = w.appliedConfig.AttributeDiff(w.config)
+	return changedAttrs, otherChanged || w.dependenciesUpdated || w.lastErr != nil, true
+}
+
 // NeedsReconfigure returns whether or not this node needs reconfiguration
 // performed on its underlying resource.
 func (w *GraphNode) NeedsReconfigure() bool {
@@ -315,6 +482,8 @@ func (w *GraphNode) setNeedsReconfigure(newConfig Config, mustReconfigure bool,
 	}
 	if mustReconfigure {
 		w.needsDependencyResolution = true
+	} else {
+		w.dependenciesUpdated = true
 	}
 	w.config = newConfig
 	w.transitionTo(NodeStateConfiguring)
@@ -407,12 +576,18 @@ func (w *GraphNode) replace(other *GraphNode) error {
 	w.current = other.current
 	w.currentModel = other.currentModel
 	w.config = other.config
+	w.appliedConfig = other.appliedConfig
+	w.dependenciesUpdated = other.dependenciesUpdated
 	w.lastErr = other.lastErr
+	w.unavailableErr = other.unavailableErr
 	w.unresolvedDependencies = other.unresolvedDependencies
 	w.needsDependencyResolution = other.needsDependencyResolution
 
 	w.state = other.state
 	w.transitionedAt = other.transitionedAt
+	w.lastSucceededAt = other.lastSucceededAt
+	w.consecutiveFailures = other.consecutiveFailures
+	w.history = other.history
 
 	// other is now owned by the graph/node and is invalidated
 	other.updatedAt = 0
@@ -421,12 +596,18 @@ func (w *GraphNode) replace(other *GraphNode) error {
 	other.current = nil
 	other.currentModel = Model{}
 	other.config = Config{}
+	other.appliedConfig = nil
+	other.dependenciesUpdated = false
 	other.lastErr = nil
+	other.unavailableErr = nil
 	other.unresolvedDependencies = nil
 	other.needsDependencyResolution = false
 
 	other.state = NodeStateUnknown
 	other.transitionedAt = time.Time{}
+	other.lastSucceededAt = time.Time{}
+	other.consecutiveFailures = 0
+	other.history = nil
 
 	other.mu.Unlock()
 	return nil
@@ -473,4 +654,28 @@ func (w *GraphNode) transitionTo(state NodeState) {
 
 	w.state = state
 	w.transitionedAt = time.Now()
+	w.recordTransition(w.transitionedAt)
+}
+
+// recordTransition adds the node's current state and error to its history, unless neither has
+// changed since the last transition. This method is not thread-safe and must be called while
+// holding a write lock on `mu` if accessed concurrently.
+func (w *GraphNode) recordTransition(at time.Time) {
+	if len(w.history) > 0 {
+		last := w.history[len(w.history)-1]
+		if last.State == w.state && errorMessage(last.Error) == errorMessage(w.err()) {
+			return
+		}
+	}
+	if len(w.history) == maxNodeHistory {
+		w.history = append(w.history[:0], w.history[1:]...)
+	}
+	w.history = append(w.history, NodeTransition{State: w.state, Error: w.err(), At: at})
+}
+
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
 }
@@ -2,6 +2,7 @@ package resource_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -323,3 +324,174 @@ func TestClose(t *testing.T) {
 		t.Fatal("node took too long to close, might be a deadlock")
 	}
 }
+
+func TestLogLevelOverride(t *testing.T) {
+	node := withTestLogger(t, resource.NewConfiguredGraphNode(resource.Config{
+		LogConfiguration: resource.LogConfig{Level: logging.WARN},
+	}, nil, resource.DefaultModelFamily.WithModel("foo")))
+	node.SetLogLevel(logging.WARN)
+	level, overridden := node.LogLevel()
+	test.That(t, level, test.ShouldEqual, logging.WARN)
+	test.That(t, overridden, test.ShouldBeFalse)
+
+	debug := logging.DEBUG
+	node.OverrideLogLevel(&debug)
+	test.That(t, node.Logger().GetLevel(), test.ShouldEqual, logging.DEBUG)
+
+	// reconfiguring doesn't undo a level set at runtime
+	node.SetLogLevel(logging.ERROR)
+	level, overridden = node.LogLevel()
+	test.That(t, level, test.ShouldEqual, logging.DEBUG)
+	test.That(t, overridden, test.ShouldBeTrue)
+
+	node.OverrideLogLevel(nil)
+	level, overridden = node.LogLevel()
+	test.That(t, level, test.ShouldEqual, logging.WARN)
+	test.That(t, overridden, test.ShouldBeFalse)
+}
+
+func TestStatus(t *testing.T) {
+	node := withTestLogger(t, resource.NewUnconfiguredGraphNode(resource.Config{}, []string{"dep"}))
+	status := node.Status()
+	test.That(t, status.State, test.ShouldEqual, resource.NodeStateConfiguring)
+	test.That(t, status.LastReconfigured, test.ShouldBeNil)
+	test.That(t, status.UnresolvedDependencies, test.ShouldResemble, []string{"dep"})
+	test.That(t, status.LastSucceededAt.IsZero(), test.ShouldBeTrue)
+	test.That(t, status.Healthy(), test.ShouldBeFalse)
+
+	node.SwapResource(&someResource{Resource: testutils.NewUnimplementedResource(generic.Named("foo"))},
+		resource.DefaultModelFamily.WithModel("bar"))
+	status = node.Status()
+	test.That(t, status.State, test.ShouldEqual, resource.NodeStateReady)
+	test.That(t, status.LastReconfigured, test.ShouldNotBeNil)
+	test.That(t, status.UnresolvedDependencies, test.ShouldBeEmpty)
+	test.That(t, status.Healthy(), test.ShouldBeTrue)
+
+	before := time.Now()
+	node.MarkOperationSucceeded()
+	test.That(t, node.Status().LastSucceededAt.Before(before), test.ShouldBeFalse)
+
+	ourErr := errors.New("whoops")
+	node.LogAndSetLastError(ourErr)
+	status = node.Status()
+	test.That(t, status.Error, test.ShouldBeError, ourErr)
+	test.That(t, status.Healthy(), test.ShouldBeFalse)
+}
+
+func TestHistory(t *testing.T) {
+	node := withTestLogger(t, resource.NewUnconfiguredGraphNode(resource.Config{}, nil))
+	states := func() []resource.NodeState {
+		var states []resource.NodeState
+		for _, change := range node.Status().History {
+			states = append(states, change.State)
+		}
+		return states
+	}
+	test.That(t, states(), test.ShouldResemble, []resource.NodeState{
+		resource.NodeStateUnconfigured,
+		resource.NodeStateConfiguring,
+	})
+
+	res := &someResource{Resource: testutils.NewUnimplementedResource(generic.Named("foo"))}
+	node.SwapResource(res, resource.DefaultModelFamily.WithModel("bar"))
+	node.SetNeedsUpdate()
+	ourErr := errors.New("whoops")
+	node.LogAndSetLastError(ourErr)
+	// the same error again is not a change
+	node.LogAndSetLastError(ourErr)
+	node.SwapResource(res, resource.DefaultModelFamily.WithModel("bar"))
+
+	history := node.Status().History
+	test.That(t, states(), test.ShouldResemble, []resource.NodeState{
+		resource.NodeStateUnconfigured,
+		resource.NodeStateConfiguring,
+		resource.NodeStateReady,
+		resource.NodeStateConfiguring,
+		resource.NodeStateConfiguring,
+		resource.NodeStateReady,
+	})
+	test.That(t, history[3].Error, test.ShouldBeNil)
+	test.That(t, history[4].Error, test.ShouldBeError, ourErr)
+	test.That(t, history[5].Error, test.ShouldBeNil)
+	for i := 1; i < len(history); i++ {
+		test.That(t, history[i].At.Before(history[i-1].At), test.ShouldBeFalse)
+	}
+
+	// only the most recent transitions are kept
+	for i := 0; i < 100; i++ {
+		node.LogAndSetLastError(fmt.Errorf("failure %d", i))
+	}
+	history = node.Status().History
+	test.That(t, history, test.ShouldHaveLength, 50)
+	test.That(t, history[len(history)-1].Error, test.ShouldBeError, errors.New("failure 99"))
+}
+
+func TestSetUnavailable(t *testing.T) {
+	node := withTestLogger(t, resource.NewUnconfiguredGraphNode(resource.Config{}, nil))
+	res := &someResource{Resource: testutils.NewUnimplementedResource(generic.Named("foo"))}
+	node.SwapResource(res, resource.DefaultModelFamily.WithModel("bar"))
+	lastReconfigured := node.LastReconfigured()
+
+	crashErr := errors.New("module crashed")
+	node.SetUnavailable(crashErr)
+	_, err := node.Resource()
+	test.That(t, err, test.ShouldBeError, crashErr)
+	test.That(t, node.HasResource(), test.ShouldBeFalse)
+	status := node.Status()
+	test.That(t, status.Error, test.ShouldBeError, crashErr)
+	test.That(t, status.State, test.ShouldEqual, resource.NodeStateReady)
+	test.That(t, status.History[len(status.History)-1].Error, test.ShouldBeError, crashErr)
+	// being unavailable isn't a reason to reconfigure
+	test.That(t, node.NeedsReconfigure(), test.ShouldBeFalse)
+
+	node.SetUnavailable(nil)
+	got, err := node.Resource()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, got, test.ShouldEqual, res)
+	test.That(t, node.Status().Healthy(), test.ShouldBeTrue)
+	test.That(t, node.LastReconfigured(), test.ShouldEqual, lastReconfigured)
+
+	// an error from building the resource is kept when it becomes available again
+	ourErr := errors.New("whoops")
+	node.SetUnavailable(crashErr)
+	node.LogAndSetLastError(ourErr)
+	node.SetUnavailable(nil)
+	_, err = node.Resource()
+	test.That(t, err, test.ShouldBeError, ourErr)
+}
+
+func TestConfigChanges(t *testing.T) {
+	conf := resource.Config{Name: "foo", Attributes: utils.AttributeMap{"speed": 1.0}}
+	node := withTestLogger(t, resource.NewUnconfiguredGraphNode(conf, nil))
+
+	// nothing has been built to compare against yet
+	_, _, ok := node.ConfigChanges()
+	test.That(t, ok, test.ShouldBeFalse)
+
+	res := &someResource{Resource: testutils.NewUnimplementedResource(generic.Named("foo"))}
+	node.SwapResource(res, resource.DefaultModelFamily.WithModel("bar"))
+	changed, otherChanged, ok := node.ConfigChanges()
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, changed, test.ShouldBeEmpty)
+	test.That(t, otherChanged, test.ShouldBeFalse)
+
+	newConf := conf
+	newConf.Attributes = utils.AttributeMap{"speed": 2.0}
+	node.SetNewConfig(newConf, nil)
+	changed, otherChanged, ok = node.ConfigChanges()
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, changed, test.ShouldResemble, []string{"speed"})
+	test.That(t, otherChanged, test.ShouldBeFalse)
+
+	// dependencies being updated affects the resource even though its config did not change
+	node.SwapResource(res, resource.DefaultModelFamily.WithModel("bar"))
+	node.SetNeedsUpdate()
+	changed, otherChanged, ok = node.ConfigChanges()
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, changed, test.ShouldBeEmpty)
+	test.That(t, otherChanged, test.ShouldBeTrue)
+
+	node.SwapResource(res, resource.DefaultModelFamily.WithModel("bar"))
+	_, otherChanged, _ = node.ConfigChanges()
+	test.That(t, otherChanged, test.ShouldBeFalse)
+}
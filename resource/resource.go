@@ -209,6 +209,55 @@ type Shaped interface {
 	Geometries(context.Context, map[string]interface{}) ([]spatialmath.Geometry, error)
 }
 
+// HealthChecker is any resource that can tell when it has stopped working, such as when a
+// background worker it relies on has exited, so that the robot can rebuild it.
+type HealthChecker interface {
+	// CheckHealth returns why the resource has stopped working, or nil if it is working.
+	CheckHealth(context.Context) error
+}
+
+// PreShutdowner is any resource that must act before the robot shuts down, such as an arm parking
+// itself or a base stopping, while the robot's servers and the resource's dependencies are still up.
+type PreShutdowner interface {
+	// PreShutdown prepares the resource for the robot shutting down. It is not called when the
+	// resource is only removed or rebuilt.
+	PreShutdown(context.Context) error
+}
+
+// SelfTester is any resource that can check that it works before the robot is trusted with a task,
+// such as a motor twitching or a camera returning a frame. Motors, grippers, movement sensors and
+// cameras which are not SelfTesters are given a default self-test by the robot.
+type SelfTester interface {
+	// SelfTest exercises the resource and returns why it does not work, or nil if it does. It may
+	// move the resource.
+	SelfTest(context.Context) error
+}
+
+// Unwrapper is any resource that wraps another, such as to inject faults into it. The optional
+// interfaces the wrapped resource implements, such as SelfTester, are found through As.
+type Unwrapper interface {
+	// Unwrap returns the wrapped resource.
+	Unwrap() Resource
+}
+
+// As returns the first resource in the chain of resources res wraps, starting with res itself,
+// which implements T, such as one of the optional interfaces HealthChecker, PreShutdowner and
+// SelfTester.
+func As[T any](res Resource) (T, bool) {
+	for res != nil {
+		if t, ok := res.(T); ok {
+			return t, true
+		}
+		unwrapper, ok := res.(Unwrapper)
+		if !ok {
+			break
+		}
+		res = unwrapper.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
 // ErrDoUnimplemented is returned if the DoCommand methods is not implemented.
 var ErrDoUnimplemented = errors.New("DoCommand unimplemented")
 
@@ -256,6 +305,19 @@ func (a AlwaysRebuild) Reconfigure(ctx context.Context, deps Dependencies, conf
 	return NewMustRebuildError(conf.ResourceName())
 }
 
+// AttributeReconfigurable is implemented by resources which otherwise must be rebuilt whenever
+// their config changes, such as those embedding AlwaysRebuild, but can apply changes to some of
+// their attributes in place. When nothing but those attributes changed, the robot calls
+// ReconfigureAttributes instead of rebuilding the resource.
+type AttributeReconfigurable interface {
+	// ReconfigurableAttributes returns the top-level attributes ReconfigureAttributes can apply.
+	ReconfigurableAttributes() []string
+	// ReconfigureAttributes applies conf, which differs from the config the resource was last
+	// built or reconfigured with only in the changed attributes. It may return a MustRebuildError
+	// if the change cannot be applied after all.
+	ReconfigureAttributes(ctx context.Context, conf Config, changed []string) error
+}
+
 // Named is to be embedded by any resource that just needs to return a name.
 type Named interface {
 	Name() Name
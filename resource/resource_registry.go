@@ -258,6 +258,17 @@ func RegisterComponent[ResourceT Resource, ConfigT ConfigValidator](
 	Register(api, model, reg)
 }
 
+// RegisterNotBuilt registers a model that was left out of this build, so that configuring it fails
+// with a NotBuiltError explaining why rather than the model being unknown.
+func RegisterNotBuilt[ResourceT Resource](api API, model Model, reason string) {
+	Register(api, model, Registration[ResourceT, NoNativeConfig]{
+		Constructor: func(context.Context, Dependencies, Config, logging.Logger) (ResourceT, error) {
+			var zero ResourceT
+			return zero, NewNotBuiltError(api, model, reason)
+		},
+	})
+}
+
 // Register registers a model for a resource (component/service) with and its construction info.
 func Register[ResourceT Resource, ConfigT ConfigValidator](
 	api API,
@@ -74,6 +74,18 @@ func TestComponentRegistry(t *testing.T) {
 	test.That(t, ok, test.ShouldBeFalse)
 }
 
+func TestRegisterNotBuilt(t *testing.T) {
+	model := resource.Model{Name: "not_built"}
+	resource.RegisterNotBuilt[arm.Arm](acme.API, model, "left out for testing")
+	defer resource.Deregister(acme.API, model)
+
+	resInfo, ok := resource.LookupRegistration(acme.API, model)
+	test.That(t, ok, test.ShouldBeTrue)
+	_, err := resInfo.Constructor(context.Background(), nil, resource.Config{Name: "foo"}, logging.NewTestLogger(t))
+	test.That(t, resource.IsNotBuiltError(err), test.ShouldBeTrue)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "is not built into this viam-server: left out for testing")
+}
+
 func TestResourceAPIRegistry(t *testing.T) {
 	statf := func(context.Context, arm.Arm) (interface{}, error) {
 		return nil, errors.New("one")
@@ -0,0 +1,91 @@
+package resource
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Some hardware, such as a serial port, a CAN bus, or an I2C bus or mux, is used by several
+// resources at once but can only be opened once, or needs its users to coordinate through a single
+// lock. Rather than each resource opening such hardware itself, its drivers share a single instance
+// through AcquireShared, which opens it for the first resource that needs it and closes it once the
+// last resource using it has released it.
+
+type sharedEntry struct {
+	value interface{}
+	close func() error
+	refs  int
+}
+
+var (
+	sharedMu      sync.Mutex
+	sharedEntries = map[string]*sharedEntry{}
+)
+
+// AcquireShared returns the shared instance of the hardware named by key, calling open to open it
+// if no resource currently holds it. open returns the instance along with the function which closes
+// it. Keys are global to the process, so should be prefixed by the kind of hardware, such as
+// "i2c:/dev/i2c-1".
+//
+// Every successful call must be paired with a call to the returned release function, normally from
+// the acquiring resource's Close. Releasing more than once does nothing.
+func AcquireShared[T any](key string, open func() (T, func() error, error)) (T, func() error, error) {
+	var zero T
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	entry, ok := sharedEntries[key]
+	if !ok {
+		value, closer, err := open()
+		if err != nil {
+			return zero, nil, err
+		}
+		entry = &sharedEntry{value: value, close: closer}
+		sharedEntries[key] = entry
+	}
+	value, ok := entry.value.(T)
+	if !ok {
+		return zero, nil, errors.Errorf("shared %q is a %T, not a %T", key, entry.value, zero)
+	}
+	entry.refs++
+
+	var once sync.Once
+	release := func() error {
+		var err error
+		once.Do(func() {
+			err = releaseShared(key, entry)
+		})
+		return err
+	}
+	return value, release, nil
+}
+
+func releaseShared(key string, entry *sharedEntry) error {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	entry.refs--
+	if entry.refs > 0 {
+		return nil
+	}
+	delete(sharedEntries, key)
+	if entry.close == nil {
+		return nil
+	}
+	if err := entry.close(); err != nil {
+		return fmt.Errorf("failed to close shared %q: %w", key, err)
+	}
+	return nil
+}
+
+// SharedRefs returns how many resources hold the shared hardware named by key.
+func SharedRefs(key string) int {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	if entry, ok := sharedEntries[key]; ok {
+		return entry.refs
+	}
+	return 0
+}
@@ -0,0 +1,64 @@
+package resource_test
+
+import (
+	"errors"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/resource"
+)
+
+type fakePort struct {
+	closed bool
+}
+
+func TestAcquireShared(t *testing.T) {
+	opens := 0
+	open := func() (*fakePort, func() error, error) {
+		opens++
+		p := &fakePort{}
+		return p, func() error {
+			p.closed = true
+			return nil
+		}, nil
+	}
+
+	first, releaseFirst, err := resource.AcquireShared("serial:/dev/ttyTEST", open)
+	test.That(t, err, test.ShouldBeNil)
+	second, releaseSecond, err := resource.AcquireShared("serial:/dev/ttyTEST", open)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, second, test.ShouldEqual, first)
+	test.That(t, opens, test.ShouldEqual, 1)
+	test.That(t, resource.SharedRefs("serial:/dev/ttyTEST"), test.ShouldEqual, 2)
+
+	// asking for the same hardware as something else fails
+	_, _, err = resource.AcquireShared("serial:/dev/ttyTEST", func() (string, func() error, error) {
+		return "", nil, nil
+	})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// releasing twice only counts once
+	test.That(t, releaseFirst(), test.ShouldBeNil)
+	test.That(t, releaseFirst(), test.ShouldBeNil)
+	test.That(t, first.closed, test.ShouldBeFalse)
+	test.That(t, resource.SharedRefs("serial:/dev/ttyTEST"), test.ShouldEqual, 1)
+
+	test.That(t, releaseSecond(), test.ShouldBeNil)
+	test.That(t, first.closed, test.ShouldBeTrue)
+	test.That(t, resource.SharedRefs("serial:/dev/ttyTEST"), test.ShouldEqual, 0)
+
+	// once closed, the next acquire opens it again
+	third, releaseThird, err := resource.AcquireShared("serial:/dev/ttyTEST", open)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, third, test.ShouldNotEqual, first)
+	test.That(t, opens, test.ShouldEqual, 2)
+	test.That(t, releaseThird(), test.ShouldBeNil)
+
+	// failures to open are passed along, and nothing is held
+	_, _, err = resource.AcquireShared("serial:/dev/ttyMISSING", func() (*fakePort, func() error, error) {
+		return nil, nil, errors.New("no such port")
+	})
+	test.That(t, err, test.ShouldBeError, errors.New("no such port"))
+	test.That(t, resource.SharedRefs("serial:/dev/ttyMISSING"), test.ShouldEqual, 0)
+}
@@ -9,9 +9,19 @@ import (
 	"slices"
 	"strings"
 	"time"
+
+	"go.viam.com/rdk/utils"
 )
 
-const snapshotLimit = 500
+// snapshotLimit is how many snapshots are kept. Slim builds keep far fewer, as each snapshot of a
+// large resource graph can take tens of kilobytes.
+var snapshotLimit = 500
+
+func init() {
+	if utils.SlimBuild {
+		snapshotLimit = 20
+	}
+}
 
 // Visualizer stores a history resource graph DOT snapshots.
 type Visualizer struct {
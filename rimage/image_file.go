@@ -265,6 +265,9 @@ func DecodeImage(ctx context.Context, imgBytes []byte, mimeType string) (image.I
 			}
 		}
 		return img, nil
+	case ut.MimeTypeRawI420, ut.MimeTypeRawNV12:
+		// skip image.Decode, which would copy the bytes
+		return decodeRawYUV(imgBytes)
 	default:
 		img, _, err := image.Decode(bytes.NewReader(imgBytes))
 		if err != nil {
@@ -314,6 +317,14 @@ func EncodeImage(ctx context.Context, img image.Image, mimeType string) ([]byte,
 		imgStruct := image.NewNRGBA(bounds)
 		draw.Draw(imgStruct, bounds, img, bounds.Min, draw.Src)
 		buf.Write(imgStruct.Pix)
+	case ut.MimeTypeRawI420:
+		if err := writeRawYUV(&buf, img, I420MagicNumber); err != nil {
+			return nil, err
+		}
+	case ut.MimeTypeRawNV12:
+		if err := writeRawYUV(&buf, img, NV12MagicNumber); err != nil {
+			return nil, err
+		}
 	case ut.MimeTypePNG:
 		if err := png.Encode(&buf, img); err != nil {
 			return nil, err
@@ -30,6 +30,8 @@ func NewDistorter(distortionType DistortionType, parameters []float64) (Distorte
 	switch distortionType { //nolint:exhaustive
 	case BrownConradyDistortionType:
 		return NewBrownConrady(parameters)
+	case KannalaBrandtDistortionType:
+		return NewKannalaBrandt(parameters)
 	default:
 		return nil, errors.Errorf("do not know how to parse %q distortion model", distortionType)
 	}
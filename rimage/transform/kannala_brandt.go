@@ -0,0 +1,116 @@
+package transform
+
+import (
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/mat"
+)
+
+// KannalaBrandt is a struct for the terms of the Kannala-Brandt model of distortion, which is the fisheye
+// model used by OpenCV. Unlike BrownConrady it models distortion as a function of the angle of the incoming ray
+// rather than its distance from the principal point, so it stays accurate for fields of view near or beyond 180
+// degrees.
+type KannalaBrandt struct {
+	K1 float64 `json:"k1"`
+	K2 float64 `json:"k2"`
+	K3 float64 `json:"k3"`
+	K4 float64 `json:"k4"`
+}
+
+// CheckValid checks if the fields for KannalaBrandt have valid inputs.
+func (kb *KannalaBrandt) CheckValid() error {
+	if kb == nil {
+		return InvalidDistortionError("KannalaBrandt shaped distortion_parameters not provided")
+	}
+	return nil
+}
+
+// NewKannalaBrandt takes in a slice of floats that will be passed into the struct in order.
+func NewKannalaBrandt(inp []float64) (*KannalaBrandt, error) {
+	if len(inp) > 4 {
+		return nil, errors.Errorf("list of parameters too long, expected max 4, got %d", len(inp))
+	}
+	if len(inp) == 0 {
+		return &KannalaBrandt{}, nil
+	}
+	for i := len(inp); i < 4; i++ { // fill missing values with 0.0
+		inp = append(inp, 0.0)
+	}
+	return &KannalaBrandt{inp[0], inp[1], inp[2], inp[3]}, nil
+}
+
+// ModelType returns the type of distortion model.
+func (kb *KannalaBrandt) ModelType() DistortionType {
+	return KannalaBrandtDistortionType
+}
+
+// Parameters returns the parameters of the distortion model as a list of floats.
+func (kb *KannalaBrandt) Parameters() []float64 {
+	if kb == nil {
+		return []float64{}
+	}
+	return []float64{kb.K1, kb.K2, kb.K3, kb.K4}
+}
+
+// Transform distorts the input points x,y according to the Kannala-Brandt model as described by OpenCV
+// https://docs.opencv.org/4.x/db/d58/group__calib3d__fisheye.html
+func (kb *KannalaBrandt) Transform(x, y float64) (float64, float64) {
+	if kb == nil {
+		return x, y
+	}
+	r := math.Hypot(x, y)
+	if r == 0 {
+		return x, y
+	}
+	theta := math.Atan(r)
+	thetaD := theta * kb.polynomial(theta)
+	scale := thetaD / r
+	return x * scale, y * scale
+}
+
+// polynomial is the factor the angle theta is scaled by, 1 + k1*theta^2 + k2*theta^4 + k3*theta^6 + k4*theta^8.
+func (kb *KannalaBrandt) polynomial(theta float64) float64 {
+	t2 := theta * theta
+	return 1. + t2*(kb.K1+t2*(kb.K2+t2*(kb.K3+t2*kb.K4)))
+}
+
+// FitKannalaBrandt estimates the Kannala-Brandt coefficients that best map the undistorted points onto the
+// distorted ones, in the least squares sense. Both sets of points are in normalized image coordinates, i.e. with
+// the principal point subtracted and divided by the focal length, so the camera's intrinsics must already be
+// known. Typically the undistorted points are the projections of the corners of a calibration target of known
+// geometry, and the distorted points are where those corners were detected in the image.
+//
+// The model is linear in its coefficients once the angle of each ray is known, so this needs no initial guess.
+func FitKannalaBrandt(undistorted, distorted []r2.Point) (*KannalaBrandt, error) {
+	if len(undistorted) != len(distorted) {
+		return nil, errors.Errorf("got %d undistorted points but %d distorted points", len(undistorted), len(distorted))
+	}
+
+	var rows []float64
+	var targets []float64
+	for i, u := range undistorted {
+		r := u.Norm()
+		// points at the principal point are not distorted, so they say nothing about the coefficients
+		if r < 1e-9 {
+			continue
+		}
+		theta := math.Atan(r)
+		t2 := theta * theta
+		// distorted radius = theta * (1 + k1*t^2 + k2*t^4 + k3*t^6 + k4*t^8)
+		rows = append(rows, theta*t2, theta*t2*t2, theta*t2*t2*t2, theta*t2*t2*t2*t2)
+		targets = append(targets, distorted[i].Norm()-theta)
+	}
+	if len(targets) < 4 {
+		return nil, errors.Errorf("need at least 4 points away from the principal point to fit, got %d", len(targets))
+	}
+
+	a := mat.NewDense(len(targets), 4, rows)
+	b := mat.NewVecDense(len(targets), targets)
+	var k mat.VecDense
+	if err := k.SolveVec(a, b); err != nil {
+		return nil, errors.Wrap(err, "could not fit Kannala-Brandt distortion")
+	}
+	return &KannalaBrandt{k.AtVec(0), k.AtVec(1), k.AtVec(2), k.AtVec(3)}, nil
+}
@@ -0,0 +1,73 @@
+package transform
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"go.viam.com/test"
+)
+
+func TestKannalaBrandtCheckValid(t *testing.T) {
+	var nilKannalaBrandtPtr *KannalaBrandt
+	err := nilKannalaBrandtPtr.CheckValid()
+	test.That(t, err.Error(), test.ShouldContainSubstring, "KannalaBrandt shaped distortion_parameters not provided")
+	test.That(t, (&KannalaBrandt{}).CheckValid(), test.ShouldBeNil)
+}
+
+func TestNewKannalaBrandt(t *testing.T) {
+	kb, err := NewKannalaBrandt([]float64{0.1, 0.2})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, kb.Parameters(), test.ShouldResemble, []float64{0.1, 0.2, 0, 0})
+
+	_, err = NewKannalaBrandt([]float64{1, 2, 3, 4, 5})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	d, err := NewDistorter(KannalaBrandtDistortionType, []float64{0.1, 0.2, 0.3, 0.4})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, d.ModelType(), test.ShouldEqual, KannalaBrandtDistortionType)
+	test.That(t, d.Parameters(), test.ShouldResemble, []float64{0.1, 0.2, 0.3, 0.4})
+}
+
+func TestKannalaBrandtTransform(t *testing.T) {
+	// with no coefficients the model is equidistant: the distorted radius is the angle of the ray
+	kb := &KannalaBrandt{}
+	x, y := kb.Transform(1, 0)
+	test.That(t, x, test.ShouldAlmostEqual, math.Pi/4)
+	test.That(t, y, test.ShouldAlmostEqual, 0)
+
+	kb = &KannalaBrandt{K1: 0.1, K2: -0.05}
+	x, y = kb.Transform(0, 0)
+	test.That(t, x, test.ShouldEqual, 0)
+	test.That(t, y, test.ShouldEqual, 0)
+
+	// the direction of the point doesn't change, only its distance from the center
+	x, y = kb.Transform(0.3, 0.4)
+	test.That(t, y/x, test.ShouldAlmostEqual, 0.4/0.3)
+	theta := math.Atan(0.5)
+	expected := theta * (1 + 0.1*theta*theta - 0.05*math.Pow(theta, 4))
+	test.That(t, math.Hypot(x, y), test.ShouldAlmostEqual, expected)
+}
+
+func TestFitKannalaBrandt(t *testing.T) {
+	truth := &KannalaBrandt{K1: -0.02, K2: 0.01, K3: -0.003, K4: 0.0005}
+	var undistorted, distorted []r2.Point
+	for i := -5; i <= 5; i++ {
+		for j := -5; j <= 5; j++ {
+			u := r2.Point{X: float64(i) * 0.3, Y: float64(j) * 0.3}
+			x, y := truth.Transform(u.X, u.Y)
+			undistorted = append(undistorted, u)
+			distorted = append(distorted, r2.Point{X: x, Y: y})
+		}
+	}
+	fit, err := FitKannalaBrandt(undistorted, distorted)
+	test.That(t, err, test.ShouldBeNil)
+	for i, k := range fit.Parameters() {
+		test.That(t, k, test.ShouldAlmostEqual, truth.Parameters()[i], 1e-6)
+	}
+
+	_, err = FitKannalaBrandt(undistorted[:3], distorted[:3])
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = FitKannalaBrandt(undistorted, distorted[:3])
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -145,6 +145,43 @@ func TestUndistortImage(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 }
 
+func TestUndistortionMap(t *testing.T) {
+	params := &PinholeCameraIntrinsics{
+		Width:  800,
+		Height: 600,
+		Fx:     887.07855759,
+		Fy:     886.579955,
+		Ppx:    382.80075175,
+		Ppy:    302.75546742,
+	}
+	img, err := rimage.NewImageFromFile(artifact.MustPath("transform/undistort/distorted_800x600.jpg"))
+	test.That(t, err, test.ShouldBeNil)
+
+	for _, distortion := range []Distorter{
+		&BrownConrady{RadialK1: -0.42333866, RadialK2: 0.25696641, RadialK3: -0.06468911},
+		&KannalaBrandt{K1: -0.01, K2: 0.002},
+	} {
+		pinhole := &PinholeCameraModel{PinholeCameraIntrinsics: params, Distortion: distortion}
+		undistortMap, err := pinhole.NewUndistortionMap()
+		test.That(t, err, test.ShouldBeNil)
+
+		// the map gives the same answer as undistorting directly
+		expected, err := pinhole.UndistortImage(img)
+		test.That(t, err, test.ShouldBeNil)
+		corrected, err := undistortMap.UndistortImage(img)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, corrected, test.ShouldResemble, expected)
+
+		_, err = undistortMap.UndistortImage(rimage.NewImage(10, 10))
+		test.That(t, err.Error(), test.ShouldContainSubstring, "img dimension and intrinsics don't match")
+		_, err = undistortMap.UndistortDepthMap(nil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "input DepthMap is nil")
+	}
+
+	_, err = (&PinholeCameraModel{PinholeCameraIntrinsics: &PinholeCameraIntrinsics{}}).NewUndistortionMap()
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
 func TestUndistortDepthMap(t *testing.T) {
 	params := &PinholeCameraIntrinsics{ // not the real intrinsic parameters of the depth map
 		Width:  1280,
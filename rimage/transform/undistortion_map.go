@@ -0,0 +1,100 @@
+package transform
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/rimage"
+)
+
+// UndistortionMap is a precomputed lookup from each pixel of an undistorted image to the pixel of the distorted
+// image that it takes its value from. Evaluating a distortion model is expensive, especially a fisheye one, so
+// building the map once per camera and reusing it for every frame is much faster than calling UndistortImage.
+type UndistortionMap struct {
+	width, height int
+	// srcX and srcY hold the source pixel for each destination pixel in row-major order, or -1 if the source
+	// falls outside of the distorted image.
+	srcX, srcY []int32
+}
+
+// NewUndistortionMap computes the UndistortionMap for the camera. The results are the same as UndistortImage and
+// UndistortDepthMap, which use nearest neighbor interpolation.
+func (params *PinholeCameraModel) NewUndistortionMap() (*UndistortionMap, error) {
+	if err := params.PinholeCameraIntrinsics.CheckValid(); err != nil {
+		return nil, err
+	}
+	m := &UndistortionMap{
+		width:  params.Width,
+		height: params.Height,
+		srcX:   make([]int32, params.Width*params.Height),
+		srcY:   make([]int32, params.Width*params.Height),
+	}
+	distortionMap := func(u, v float64) (float64, float64) { return u, v }
+	if params.Distortion != nil {
+		distortionMap = params.DistortionMap()
+	}
+	maxX, maxY := float64(params.Width-1), float64(params.Height-1)
+	for v := 0; v < params.Height; v++ {
+		for u := 0; u < params.Width; u++ {
+			k := v*params.Width + u
+			x, y := distortionMap(float64(u), float64(v))
+			if x < 0 || y < 0 || x > maxX || y > maxY || math.IsNaN(x) || math.IsNaN(y) {
+				m.srcX[k], m.srcY[k] = -1, -1
+				continue
+			}
+			m.srcX[k], m.srcY[k] = int32(math.Round(x)), int32(math.Round(y))
+		}
+	}
+	return m, nil
+}
+
+func (m *UndistortionMap) checkSize(width, height int) error {
+	if m.width != width || m.height != height {
+		return errors.Errorf("img dimension and intrinsics don't match Image(%d,%d) != Intrinsics(%d,%d)",
+			width, height, m.width, m.height)
+	}
+	return nil
+}
+
+// UndistortImage returns a new undistorted image using the map.
+func (m *UndistortionMap) UndistortImage(img *rimage.Image) (*rimage.Image, error) {
+	if img == nil {
+		return nil, errors.New("input image is nil")
+	}
+	if err := m.checkSize(img.Width(), img.Height()); err != nil {
+		return nil, err
+	}
+	undistortedImg := rimage.NewImage(m.width, m.height)
+	for v := 0; v < m.height; v++ {
+		for u := 0; u < m.width; u++ {
+			k := v*m.width + u
+			if m.srcX[k] < 0 {
+				continue
+			}
+			undistortedImg.SetXY(u, v, img.GetXY(int(m.srcX[k]), int(m.srcY[k])))
+		}
+	}
+	return undistortedImg, nil
+}
+
+// UndistortDepthMap returns a new undistorted depth map using the map.
+func (m *UndistortionMap) UndistortDepthMap(dm *rimage.DepthMap) (*rimage.DepthMap, error) {
+	if dm == nil {
+		return nil, errors.New("input DepthMap is nil")
+	}
+	if err := m.checkSize(dm.Width(), dm.Height()); err != nil {
+		return nil, err
+	}
+	undistortedDm := rimage.NewEmptyDepthMap(m.width, m.height)
+	for v := 0; v < m.height; v++ {
+		for u := 0; u < m.width; u++ {
+			k := v*m.width + u
+			if m.srcX[k] < 0 {
+				continue
+			}
+			undistortedDm.Set(u, v, dm.GetDepth(int(m.srcX[k]), int(m.srcY[k])))
+		}
+	}
+	return undistortedDm, nil
+}
@@ -0,0 +1,183 @@
+package rimage
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// I420MagicNumber represents the magic number for our custom header for raw planar YUV 4:2:0 data.
+// Like raw RGBA data, the magic number is followed by the width and height, each as a 4-byte
+// big-endian uint32. Then come the full resolution Y plane and the quarter resolution U and V planes.
+var I420MagicNumber = []byte("I420")
+
+// NV12MagicNumber represents the magic number for our custom header for raw semi-planar YUV 4:2:0
+// data. The header is laid out like the I420 one, but the full resolution Y plane is followed by a
+// single quarter resolution plane of interleaved U and V samples.
+var NV12MagicNumber = []byte("NV12")
+
+// RawYUVHeaderLength is the length of our custom header for raw I420 and NV12 data in bytes.
+const RawYUVHeaderLength = 12
+
+func init() {
+	// Register the formats so that image.Decode and image.DecodeConfig, and so lazy images, work on
+	// raw YUV data with the appropriate header.
+	for _, format := range []struct {
+		name  string
+		magic []byte
+	}{
+		{"vnd.viam.i420", I420MagicNumber},
+		{"vnd.viam.nv12", NV12MagicNumber},
+	} {
+		image.RegisterFormat(format.name, string(format.magic),
+			func(r io.Reader) (image.Image, error) {
+				rawBytes, err := io.ReadAll(r)
+				if err != nil {
+					return nil, err
+				}
+				return decodeRawYUV(rawBytes)
+			},
+			func(r io.Reader) (image.Config, error) {
+				header := make([]byte, RawYUVHeaderLength)
+				if _, err := io.ReadFull(r, header); err != nil {
+					return image.Config{}, err
+				}
+				return image.Config{
+					ColorModel: color.YCbCrModel,
+					Width:      int(binary.BigEndian.Uint32(header[4:8])),
+					Height:     int(binary.BigEndian.Uint32(header[8:12])),
+				}, nil
+			},
+		)
+	}
+}
+
+// decodeRawYUV decodes raw I420 or NV12 data. I420 data is not copied: the planes of the returned
+// image share memory with rawBytes.
+func decodeRawYUV(rawBytes []byte) (*image.YCbCr, error) {
+	if len(rawBytes) < RawYUVHeaderLength {
+		return nil, io.ErrUnexpectedEOF
+	}
+	magic := rawBytes[:4]
+	width := int(binary.BigEndian.Uint32(rawBytes[4:8]))
+	height := int(binary.BigEndian.Uint32(rawBytes[8:12]))
+	chromaWidth, chromaHeight := (width+1)/2, (height+1)/2
+	ySize, cSize := width*height, chromaWidth*chromaHeight
+	data := rawBytes[RawYUVHeaderLength:]
+	if len(data) < ySize+2*cSize {
+		return nil, errors.Errorf("expected %d bytes of %dx%d YUV data, got %d", ySize+2*cSize, width, height, len(data))
+	}
+
+	img := &image.YCbCr{
+		Y:              data[:ySize:ySize],
+		YStride:        width,
+		CStride:        chromaWidth,
+		SubsampleRatio: image.YCbCrSubsampleRatio420,
+		Rect:           image.Rect(0, 0, width, height),
+	}
+	switch {
+	case bytes.Equal(magic, I420MagicNumber):
+		img.Cb = data[ySize : ySize+cSize : ySize+cSize]
+		img.Cr = data[ySize+cSize : ySize+2*cSize : ySize+2*cSize]
+	case bytes.Equal(magic, NV12MagicNumber):
+		img.Cb = make([]byte, cSize)
+		img.Cr = make([]byte, cSize)
+		uv := data[ySize : ySize+2*cSize]
+		for i := 0; i < cSize; i++ {
+			img.Cb[i] = uv[2*i]
+			img.Cr[i] = uv[2*i+1]
+		}
+	default:
+		return nil, errors.Errorf("unknown raw YUV magic number %q", magic)
+	}
+	return img, nil
+}
+
+// writeRawYUV writes img to buf as raw I420 or NV12 data, depending on the magic number. An
+// *image.YCbCr with 4:2:0 subsampling has its planes copied without any color conversion.
+func writeRawYUV(buf *bytes.Buffer, img image.Image, magic []byte) error {
+	yuv, err := ToYCbCr(img)
+	if err != nil {
+		return err
+	}
+	width, height := yuv.Rect.Dx(), yuv.Rect.Dy()
+	chromaWidth, chromaHeight := (width+1)/2, (height+1)/2
+
+	header := make([]byte, RawYUVHeaderLength)
+	copy(header, magic)
+	binary.BigEndian.PutUint32(header[4:8], uint32(width))
+	binary.BigEndian.PutUint32(header[8:12], uint32(height))
+	buf.Grow(len(header) + width*height + 2*chromaWidth*chromaHeight)
+	buf.Write(header)
+
+	for y := 0; y < height; y++ {
+		start := yuv.YOffset(yuv.Rect.Min.X, yuv.Rect.Min.Y+y)
+		buf.Write(yuv.Y[start : start+width])
+	}
+	if bytes.Equal(magic, I420MagicNumber) {
+		for _, plane := range [][]byte{yuv.Cb, yuv.Cr} {
+			for y := 0; y < chromaHeight; y++ {
+				start := yuv.COffset(yuv.Rect.Min.X, yuv.Rect.Min.Y+2*y)
+				buf.Write(plane[start : start+chromaWidth])
+			}
+		}
+		return nil
+	}
+	for y := 0; y < chromaHeight; y++ {
+		start := yuv.COffset(yuv.Rect.Min.X, yuv.Rect.Min.Y+2*y)
+		for x := 0; x < chromaWidth; x++ {
+			buf.WriteByte(yuv.Cb[start+x])
+			buf.WriteByte(yuv.Cr[start+x])
+		}
+	}
+	return nil
+}
+
+// ToYCbCr returns img as a YCbCr image with 4:2:0 subsampling, the layout used by I420 and NV12,
+// converting it only if necessary. Images that are already in that layout, including JPEGs and raw
+// YUV data waiting to be lazily decoded, are never converted to RGB along the way.
+func ToYCbCr(img image.Image) (*image.YCbCr, error) {
+	switch v := img.(type) {
+	case *LazyEncodedImage:
+		decoded, err := DecodeImage(context.Background(), v.RawData(), v.MIMEType())
+		if err != nil {
+			return nil, errors.Wrap(err, "could not decode LazyEncodedImage")
+		}
+		return ToYCbCr(decoded)
+	case *image.YCbCr:
+		// the chroma samples of an odd origin straddle two pixels, so those images are resampled
+		if v.SubsampleRatio == image.YCbCrSubsampleRatio420 && v.Rect.Min.X%2 == 0 && v.Rect.Min.Y%2 == 0 {
+			return v, nil
+		}
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	dst := image.NewYCbCr(image.Rect(0, 0, width, height), image.YCbCrSubsampleRatio420)
+	for y := 0; y < height; y += 2 {
+		for x := 0; x < width; x += 2 {
+			// each chroma sample is the average color of the (up to) 2x2 block of pixels it covers
+			var rSum, gSum, bSum, n uint32
+			for dy := 0; dy < 2 && y+dy < height; dy++ {
+				for dx := 0; dx < 2 && x+dx < width; dx++ {
+					r, g, b, _ := img.At(bounds.Min.X+x+dx, bounds.Min.Y+y+dy).RGBA()
+					r8, g8, b8 := uint8(r>>8), uint8(g>>8), uint8(b>>8)
+					dst.Y[dst.YOffset(x+dx, y+dy)], _, _ = color.RGBToYCbCr(r8, g8, b8)
+					rSum += uint32(r8)
+					gSum += uint32(g8)
+					bSum += uint32(b8)
+					n++
+				}
+			}
+			_, cb, cr := color.RGBToYCbCr(uint8(rSum/n), uint8(gSum/n), uint8(bSum/n))
+			dst.Cb[dst.COffset(x, y)] = cb
+			dst.Cr[dst.COffset(x, y)] = cr
+		}
+	}
+	return dst, nil
+}
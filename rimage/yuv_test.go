@@ -0,0 +1,118 @@
+package rimage
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/utils"
+)
+
+// testYCbCr returns an odd sized 4:2:0 image with every sample distinct.
+func testYCbCr() *image.YCbCr {
+	img := image.NewYCbCr(image.Rect(0, 0, 5, 3), image.YCbCrSubsampleRatio420)
+	for i := range img.Y {
+		img.Y[i] = byte(i)
+	}
+	for i := range img.Cb {
+		img.Cb[i] = byte(100 + i)
+		img.Cr[i] = byte(200 + i)
+	}
+	return img
+}
+
+func TestRawYUVEncodingDecoding(t *testing.T) {
+	img := testYCbCr()
+	for _, tc := range []struct {
+		mimeType string
+		format   string
+	}{
+		{utils.MimeTypeRawI420, "vnd.viam.i420"},
+		{utils.MimeTypeRawNV12, "vnd.viam.nv12"},
+	} {
+		t.Run(tc.mimeType, func(t *testing.T) {
+			encoded, err := EncodeImage(context.Background(), img, tc.mimeType)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, len(encoded), test.ShouldEqual, RawYUVHeaderLength+5*3+2*3*2)
+
+			conf, format, err := image.DecodeConfig(bytes.NewReader(encoded))
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, format, test.ShouldEqual, tc.format)
+			test.That(t, conf.Width, test.ShouldEqual, 5)
+			test.That(t, conf.Height, test.ShouldEqual, 3)
+
+			decoded, format, err := image.Decode(bytes.NewReader(encoded))
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, format, test.ShouldEqual, tc.format)
+			test.That(t, decoded, test.ShouldResemble, img)
+
+			decoded, err = DecodeImage(context.Background(), encoded, tc.mimeType)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, decoded, test.ShouldResemble, img)
+
+			lazy, err := DecodeImage(context.Background(), encoded, utils.WithLazyMIMEType(tc.mimeType))
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, lazy.Bounds(), test.ShouldResemble, img.Bounds())
+			converted, err := ToYCbCr(lazy)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, converted, test.ShouldResemble, img)
+
+			// the bytes of a lazy image are passed through untouched
+			reencoded, err := EncodeImage(context.Background(), lazy, tc.mimeType)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, reencoded, test.ShouldResemble, encoded)
+
+			_, err = DecodeImage(context.Background(), encoded[:len(encoded)-1], tc.mimeType)
+			test.That(t, err, test.ShouldNotBeNil)
+		})
+	}
+
+	t.Run("sub image", func(t *testing.T) {
+		sub := img.SubImage(image.Rect(2, 0, 5, 3))
+		encoded, err := EncodeImage(context.Background(), sub, utils.MimeTypeRawI420)
+		test.That(t, err, test.ShouldBeNil)
+		decoded, err := DecodeImage(context.Background(), encoded, utils.MimeTypeRawI420)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, decoded.Bounds(), test.ShouldResemble, image.Rect(0, 0, 3, 3))
+		for x := 0; x < 3; x++ {
+			for y := 0; y < 3; y++ {
+				test.That(t, decoded.At(x, y), test.ShouldResemble, sub.At(x+2, y))
+			}
+		}
+	})
+}
+
+func TestToYCbCr(t *testing.T) {
+	img := testYCbCr()
+	converted, err := ToYCbCr(img)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, converted, test.ShouldEqual, img)
+
+	rgb := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 2; y++ {
+			if x < 2 {
+				rgb.Set(x, y, Red)
+			} else {
+				rgb.Set(x, y, Blue)
+			}
+		}
+	}
+	converted, err = ToYCbCr(rgb)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, converted.SubsampleRatio, test.ShouldEqual, image.YCbCrSubsampleRatio420)
+	test.That(t, converted.Bounds(), test.ShouldResemble, rgb.Bounds())
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 2; y++ {
+			expected := color.YCbCrModel.Convert(rgb.At(x, y))
+			test.That(t, converted.At(x, y), test.ShouldResemble, expected)
+		}
+	}
+
+	_, err = ToYCbCr(NewLazyEncodedImage([]byte{1, 2, 3}, utils.MimeTypePNG))
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -41,10 +41,12 @@ import (
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/robot/diagnostics"
 	"go.viam.com/rdk/robot/framesystem"
 	"go.viam.com/rdk/robot/packages"
 	"go.viam.com/rdk/session"
 	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/tracing"
 	"go.viam.com/rdk/utils/contextutils"
 )
 
@@ -249,6 +251,9 @@ func New(ctx context.Context, address string, clientLogger logging.ZapCompatible
 		rpc.WithUnaryClientInterceptor(operation.UnaryClientInterceptor),
 		rpc.WithStreamClientInterceptor(operation.StreamClientInterceptor),
 		rpc.WithUnaryClientInterceptor(logging.UnaryClientInterceptor),
+		// tracing
+		rpc.WithUnaryClientInterceptor(tracing.UnaryClientInterceptor),
+		rpc.WithStreamClientInterceptor(tracing.StreamClientInterceptor),
 	)
 
 	if err := rc.connect(ctx); err != nil {
@@ -315,6 +320,16 @@ func (rc *RobotClient) Connected() bool {
 	return rc.connected.Load()
 }
 
+// WebRTCDiagnostics returns the ICE candidates tried while connecting to the remote over WebRTC, and the path
+// which was selected. It returns an error if the remote is not connected to over WebRTC.
+func (rc *RobotClient) WebRTCDiagnostics() (*grpc.ICEDiagnostics, error) {
+	pc := rc.conn.PeerConn()
+	if pc == nil {
+		return nil, errors.New("not connected over WebRTC")
+	}
+	return grpc.PeerConnectionDiagnostics(pc), nil
+}
+
 // Changed watches for whether the remote has changed.
 func (rc *RobotClient) Changed() <-chan bool {
 	rc.mu.Lock()
@@ -1018,3 +1033,8 @@ func (rc *RobotClient) Shutdown(ctx context.Context) error {
 	rc.Logger().CDebug(ctx, "robot shutdown successful")
 	return nil
 }
+
+// Diagnostics returns a client of the robot's diagnostics service, which runs self-tests among other things.
+func (rc *RobotClient) Diagnostics() *diagnostics.Client {
+	return diagnostics.NewClient(&rc.conn)
+}
@@ -0,0 +1,200 @@
+// Package diagnostics defines an RPC service for looking into and administering a running robot, such as running
+// its self-tests, which the robot API has no methods for. It is served alongside the robot API, so the robot's
+// authentication and roles apply to it just the same. Its requests and responses are sent as structs, so it needs
+// no generated code.
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ServiceName is the name of the diagnostics RPC service.
+const ServiceName = "viam.rdk.robot.v1.DiagnosticsService"
+
+// The methods of the diagnostics service.
+const (
+	// MethodRunSelfTest runs the self-tests of the robot's resources.
+	MethodRunSelfTest = "RunSelfTest"
+	// MethodGetSelfTestReport returns the report of the most recent self-test run.
+	MethodGetSelfTestReport = "GetSelfTestReport"
+	// MethodGetLogLevels returns the level each resource logs at.
+	MethodGetLogLevels = "GetLogLevels"
+	// MethodSetLogLevel changes the level a resource logs at without reconfiguring it.
+	MethodSetLogLevel = "SetLogLevel"
+)
+
+// LogLevelConfig is the level which restores a resource's log level to the one in its config.
+const LogLevelConfig = "config"
+
+// FullMethod returns the full gRPC name of a method of the diagnostics service, as interceptors see it.
+func FullMethod(method string) string {
+	return "/" + ServiceName + "/" + method
+}
+
+// RunSelfTestRequest asks for the self-tests of the named resources, or of those in the robot's self-test config
+// when none are named.
+type RunSelfTestRequest struct {
+	Resources []string `json:"resources,omitempty"`
+	// Actuate runs self-tests which move components too, such as twitching motors and opening grippers, which are
+	// otherwise skipped.
+	Actuate bool `json:"actuate,omitempty"`
+}
+
+// GetSelfTestReportRequest asks for the report of the most recent self-test run.
+type GetSelfTestReportRequest struct{}
+
+// SelfTestReport is the outcome of a self-test run.
+type SelfTestReport struct {
+	Passed    bool                      `json:"passed"`
+	StartedAt time.Time                 `json:"started_at"`
+	Resources map[string]SelfTestResult `json:"resources"`
+}
+
+// SelfTestResult is the outcome of one resource's self-test.
+type SelfTestResult struct {
+	Passed bool `json:"passed"`
+	// Skipped is set for self-tests which would have moved the resource, when moving wasn't allowed.
+	Skipped         bool    `json:"skipped,omitempty"`
+	Error           string  `json:"error,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// GetLogLevelsRequest asks for the level each resource logs at.
+type GetLogLevelsRequest struct{}
+
+// SetLogLevelRequest asks for a resource to log at a level, such as "debug", until set again. A level of
+// LogLevelConfig restores the level in the resource's config.
+type SetLogLevelRequest struct {
+	Resource string `json:"resource"`
+	Level    string `json:"level"`
+}
+
+// LogLevels are the levels resources log at, by resource name.
+type LogLevels struct {
+	Levels map[string]string `json:"levels"`
+}
+
+// Server serves the diagnostics service.
+type Server interface {
+	RunSelfTest(ctx context.Context, req *RunSelfTestRequest) (*SelfTestReport, error)
+	GetSelfTestReport(ctx context.Context, req *GetSelfTestReportRequest) (*SelfTestReport, error)
+	GetLogLevels(ctx context.Context, req *GetLogLevelsRequest) (*LogLevels, error)
+	SetLogLevel(ctx context.Context, req *SetLogLevelRequest) (*LogLevels, error)
+}
+
+// ServiceDesc describes the diagnostics service to gRPC.
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*Server)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryMethod(MethodRunSelfTest, Server.RunSelfTest),
+		unaryMethod(MethodGetSelfTestReport, Server.GetSelfTestReport),
+		unaryMethod(MethodGetLogLevels, Server.GetLogLevels),
+		unaryMethod(MethodSetLogLevel, Server.SetLogLevel),
+	},
+}
+
+// unaryMethod describes a method which converts its request from a struct to Req, and its response from Resp to a
+// struct.
+func unaryMethod[Req, Resp any](method string, call func(Server, context.Context, *Req) (*Resp, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: method,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (
+			interface{}, error,
+		) {
+			in := new(structpb.Struct)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				var typedReq Req
+				if err := fromStruct(req.(*structpb.Struct), &typedReq); err != nil {
+					return nil, err
+				}
+				resp, err := call(srv.(Server), ctx, &typedReq)
+				if err != nil {
+					return nil, err
+				}
+				return toStruct(resp)
+			}
+			if interceptor == nil {
+				return handler(ctx, in)
+			}
+			return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: FullMethod(method)}, handler)
+		},
+	}
+}
+
+// Client calls the diagnostics service of a robot.
+type Client struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewClient returns a client of the diagnostics service of the robot at the other end of conn.
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{conn: conn}
+}
+
+// RunSelfTest runs the self-tests of the robot's resources.
+func (c *Client) RunSelfTest(ctx context.Context, req *RunSelfTestRequest) (*SelfTestReport, error) {
+	return invoke[SelfTestReport](ctx, c.conn, MethodRunSelfTest, req)
+}
+
+// GetSelfTestReport returns the report of the most recent self-test run.
+func (c *Client) GetSelfTestReport(ctx context.Context) (*SelfTestReport, error) {
+	return invoke[SelfTestReport](ctx, c.conn, MethodGetSelfTestReport, &GetSelfTestReportRequest{})
+}
+
+// GetLogLevels returns the level each resource logs at.
+func (c *Client) GetLogLevels(ctx context.Context) (*LogLevels, error) {
+	return invoke[LogLevels](ctx, c.conn, MethodGetLogLevels, &GetLogLevelsRequest{})
+}
+
+// SetLogLevel changes the level a resource logs at, returning the level each resource then logs at.
+func (c *Client) SetLogLevel(ctx context.Context, req *SetLogLevelRequest) (*LogLevels, error) {
+	return invoke[LogLevels](ctx, c.conn, MethodSetLogLevel, req)
+}
+
+func invoke[Resp any](ctx context.Context, conn grpc.ClientConnInterface, method string, req interface{}) (*Resp, error) {
+	in, err := toStruct(req)
+	if err != nil {
+		return nil, err
+	}
+	out := new(structpb.Struct)
+	if err := conn.Invoke(ctx, FullMethod(method), in, out); err != nil {
+		return nil, err
+	}
+	var resp Resp
+	if err := fromStruct(out, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// toStruct converts v to a struct through its JSON encoding.
+func toStruct(v interface{}) (*structpb.Struct, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	s := new(structpb.Struct)
+	if err := s.UnmarshalJSON(encoded); err != nil {
+		return nil, errors.Wrap(err, "diagnostics messages must encode to JSON objects")
+	}
+	return s, nil
+}
+
+// fromStruct converts s to v through its JSON encoding.
+func fromStruct(s *structpb.Struct, v interface{}) error {
+	encoded, err := s.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, v)
+}
@@ -0,0 +1,70 @@
+package diagnostics
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/test"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type fakeServer struct {
+	Server
+	setLogLevel *SetLogLevelRequest
+}
+
+func (s *fakeServer) SetLogLevel(ctx context.Context, req *SetLogLevelRequest) (*LogLevels, error) {
+	s.setLogLevel = req
+	return &LogLevels{Levels: map[string]string{req.Resource: req.Level}}, nil
+}
+
+// fakeConn calls the handlers of the service directly, as a connection to a server would.
+type fakeConn struct {
+	grpc.ClientConnInterface
+	srv         Server
+	interceptor grpc.UnaryServerInterceptor
+}
+
+func (c *fakeConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	for _, desc := range ServiceDesc.Methods {
+		if FullMethod(desc.MethodName) != method {
+			continue
+		}
+		dec := func(in interface{}) error {
+			proto.Merge(in.(*structpb.Struct), args.(*structpb.Struct))
+			return nil
+		}
+		resp, err := desc.Handler(c.srv, ctx, dec, c.interceptor)
+		if err != nil {
+			return err
+		}
+		proto.Merge(reply.(*structpb.Struct), resp.(*structpb.Struct))
+		return nil
+	}
+	return grpc.ErrServerStopped
+}
+
+func TestClientServer(t *testing.T) {
+	srv := &fakeServer{}
+	var intercepted string
+	client := NewClient(&fakeConn{srv: srv, interceptor: func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		intercepted = info.FullMethod
+		return handler(ctx, req)
+	}})
+
+	levels, err := client.SetLogLevel(context.Background(), &SetLogLevelRequest{
+		Resource: "rdk:component:motor/left",
+		Level:    "debug",
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, intercepted, test.ShouldEqual, "/viam.rdk.robot.v1.DiagnosticsService/SetLogLevel")
+	test.That(t, srv.setLogLevel, test.ShouldResemble, &SetLogLevelRequest{Resource: "rdk:component:motor/left", Level: "debug"})
+	test.That(t, levels.Levels, test.ShouldResemble, map[string]string{"rdk:component:motor/left": "debug"})
+}
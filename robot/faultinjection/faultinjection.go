@@ -0,0 +1,173 @@
+// Package faultinjection wraps components so that operators can simulate hardware failures
+// on a running robot through DoCommand, in order to rehearse how the robot and its operators
+// handle them. Wrapping only happens when the robot config sets enable_fault_injection.
+//
+// A wrapped component accepts the following commands in addition to its own:
+//
+//	{"command": "inject_fault", "fault": "stall", "duration_secs": 30}
+//	{"command": "clear_faults"}
+//	{"command": "clear_faults", "fault": "stall"}
+//	{"command": "get_faults"}
+//
+// duration_secs is optional; without it a fault stays active until it is cleared.
+package faultinjection
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+const (
+	// FaultDisconnect makes every method of a component fail as if it could no longer be reached.
+	FaultDisconnect = "disconnect"
+	// FaultStall makes a motor stop and refuse to move, as if it had stalled.
+	FaultStall = "stall"
+	// FaultLoseFix makes a movement sensor report that it has no GPS fix.
+	FaultLoseFix = "lose_fix"
+)
+
+// ErrInjected is wrapped by every error returned because of an injected fault.
+var ErrInjected = errors.New("injected fault")
+
+// Wrap returns res wrapped so that faults can be injected into it, or res unchanged if its
+// API does not support fault injection.
+func Wrap(res resource.Resource, logger logging.Logger) resource.Resource {
+	switch res.Name().API {
+	case motor.API:
+		if m, ok := res.(motor.Motor); ok {
+			return &injectableMotor{Motor: m, faults: newFaults(logger, FaultStall, FaultDisconnect)}
+		}
+	case movementsensor.API:
+		if ms, ok := res.(movementsensor.MovementSensor); ok {
+			return &injectableMovementSensor{MovementSensor: ms, faults: newFaults(logger, FaultLoseFix, FaultDisconnect)}
+		}
+	}
+	return res
+}
+
+// faults tracks which faults are currently injected into a single component.
+type faults struct {
+	supported []string
+	logger    logging.Logger
+	now       func() time.Time
+
+	mu sync.Mutex
+	// active maps each injected fault to when it expires; a zero time never expires.
+	active map[string]time.Time
+}
+
+func newFaults(logger logging.Logger, supported ...string) *faults {
+	return &faults{
+		supported: supported,
+		logger:    logger,
+		now:       time.Now,
+		active:    map[string]time.Time{},
+	}
+}
+
+// isActive returns whether fault is currently injected.
+func (f *faults) isActive(fault string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.expireLocked()
+	_, ok := f.active[fault]
+	return ok
+}
+
+// disconnected returns an error if the disconnect fault is injected.
+func (f *faults) disconnected() error {
+	if f.isActive(FaultDisconnect) {
+		return errors.Wrap(ErrInjected, "component disconnected")
+	}
+	return nil
+}
+
+func (f *faults) expireLocked() {
+	now := f.now()
+	for fault, expiry := range f.active {
+		if !expiry.IsZero() && !now.Before(expiry) {
+			delete(f.active, fault)
+			f.logger.Infow("injected fault expired", "fault", fault)
+		}
+	}
+}
+
+// doCommand handles the fault injection commands. handled is false if cmd is not one of them,
+// in which case it should be passed on to the component.
+func (f *faults) doCommand(cmd map[string]interface{}) (resp map[string]interface{}, handled bool, err error) {
+	name, _ := cmd["command"].(string)
+	switch name {
+	case "inject_fault":
+		fault, ok := cmd["fault"].(string)
+		if !ok {
+			return nil, true, errors.New(`inject_fault requires a "fault" string`)
+		}
+		if !f.supports(fault) {
+			return nil, true, errors.Errorf("unsupported fault %q, expected one of %v", fault, f.supported)
+		}
+		var expiry time.Time
+		if v, ok := cmd["duration_secs"]; ok {
+			secs, ok := v.(float64)
+			if !ok || secs <= 0 {
+				return nil, true, errors.New(`"duration_secs" must be a positive number`)
+			}
+			expiry = f.now().Add(time.Duration(secs * float64(time.Second)))
+		}
+		f.mu.Lock()
+		f.active[fault] = expiry
+		f.mu.Unlock()
+		f.logger.Warnw("injected fault", "fault", fault, "expires", expiry)
+	case "clear_faults":
+		fault, _ := cmd["fault"].(string)
+		f.mu.Lock()
+		if fault == "" {
+			f.active = map[string]time.Time{}
+		} else {
+			delete(f.active, fault)
+		}
+		f.mu.Unlock()
+		f.logger.Infow("cleared injected faults", "fault", fault)
+	case "get_faults":
+	default:
+		return nil, false, nil
+	}
+	return f.status(), true, nil
+}
+
+func (f *faults) supports(fault string) bool {
+	for _, s := range f.supported {
+		if s == fault {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *faults) status() map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.expireLocked()
+
+	names := make([]string, 0, len(f.active))
+	for fault := range f.active {
+		names = append(names, fault)
+	}
+	sort.Strings(names)
+	active := make([]interface{}, 0, len(names))
+	for _, fault := range names {
+		active = append(active, fault)
+	}
+	supported := make([]interface{}, 0, len(f.supported))
+	for _, fault := range f.supported {
+		supported = append(supported, fault)
+	}
+	return map[string]interface{}{"active": active, "supported": supported}
+}
@@ -0,0 +1,174 @@
+package faultinjection
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestMotorFaults(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	var powered, stopped bool
+	injectMotor := inject.NewMotor("m")
+	injectMotor.SetPowerFunc = func(ctx context.Context, powerPct float64, extra map[string]interface{}) error {
+		powered = true
+		return nil
+	}
+	injectMotor.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		stopped = true
+		return nil
+	}
+	injectMotor.IsMovingFunc = func(context.Context) (bool, error) {
+		return true, nil
+	}
+	injectMotor.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"passed": true}, nil
+	}
+
+	m, ok := Wrap(injectMotor, logger).(motor.Motor)
+	test.That(t, ok, test.ShouldBeTrue)
+
+	test.That(t, m.SetPower(ctx, 0.5, nil), test.ShouldBeNil)
+	test.That(t, powered, test.ShouldBeTrue)
+
+	resp, err := m.DoCommand(ctx, map[string]interface{}{"command": "inject_fault", "fault": FaultStall})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["active"], test.ShouldResemble, []interface{}{FaultStall})
+	test.That(t, stopped, test.ShouldBeTrue)
+
+	powered = false
+	err = m.SetPower(ctx, 0.5, nil)
+	test.That(t, errors.Is(err, ErrInjected), test.ShouldBeTrue)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "stalled")
+	test.That(t, powered, test.ShouldBeFalse)
+	moving, err := m.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moving, test.ShouldBeFalse)
+
+	// other commands still reach the motor.
+	resp, err = m.DoCommand(ctx, map[string]interface{}{"command": "other"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["passed"], test.ShouldBeTrue)
+
+	_, err = m.DoCommand(ctx, map[string]interface{}{"command": "inject_fault", "fault": FaultLoseFix})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "unsupported fault")
+
+	resp, err = m.DoCommand(ctx, map[string]interface{}{"command": "clear_faults"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["active"], test.ShouldBeEmpty)
+	test.That(t, m.SetPower(ctx, 0.5, nil), test.ShouldBeNil)
+	test.That(t, powered, test.ShouldBeTrue)
+
+	_, err = m.DoCommand(ctx, map[string]interface{}{"command": "inject_fault", "fault": FaultDisconnect})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = m.Position(ctx, nil)
+	test.That(t, errors.Is(err, ErrInjected), test.ShouldBeTrue)
+	_, err = m.DoCommand(ctx, map[string]interface{}{"command": "other"})
+	test.That(t, errors.Is(err, ErrInjected), test.ShouldBeTrue)
+
+	// a disconnected motor still stops, so that it can't be left running
+	stopped = false
+	err = m.Stop(ctx, nil)
+	test.That(t, errors.Is(err, ErrInjected), test.ShouldBeTrue)
+	test.That(t, stopped, test.ShouldBeTrue)
+}
+
+func TestWrappedOptionalInterfaces(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	m := &selfTestingMotor{Motor: inject.NewMotor("m")}
+	wrapped := Wrap(m, logger)
+
+	tester, ok := resource.As[resource.SelfTester](wrapped)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, tester.SelfTest(context.Background()), test.ShouldBeNil)
+	test.That(t, m.tested, test.ShouldBeTrue)
+
+	_, ok = resource.As[resource.PreShutdowner](wrapped)
+	test.That(t, ok, test.ShouldBeFalse)
+}
+
+type selfTestingMotor struct {
+	motor.Motor
+	tested bool
+}
+
+func (m *selfTestingMotor) SelfTest(ctx context.Context) error {
+	m.tested = true
+	return nil
+}
+
+func TestMovementSensorFaults(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	pos := geo.NewPoint(40.7, -74.0)
+	injectMS := inject.NewMovementSensor("gps")
+	injectMS.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+		return pos, 10, nil
+	}
+	injectMS.AccuracyFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error) {
+		return &movementsensor.Accuracy{NmeaFix: 4, Hdop: 0.5, Vdop: 0.8}, nil
+	}
+	injectMS.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"position": pos, "altitude": 10.0, "fix": 4}, nil
+	}
+
+	wrapped := Wrap(injectMS, logger)
+	ms, ok := wrapped.(movementsensor.MovementSensor)
+	test.That(t, ok, test.ShouldBeTrue)
+	f := wrapped.(*injectableMovementSensor).faults
+
+	now := time.Now()
+	f.now = func() time.Time { return now }
+
+	// Report a position before the fix is lost so it is the one that sticks.
+	_, _, err := ms.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	_, err = ms.DoCommand(ctx, map[string]interface{}{"command": "inject_fault", "fault": FaultLoseFix, "duration_secs": 30.0})
+	test.That(t, err, test.ShouldBeNil)
+
+	pos = geo.NewPoint(41, -75)
+	got, alt, err := ms.Position(ctx, nil)
+	test.That(t, errors.Is(err, ErrInjected), test.ShouldBeTrue)
+	test.That(t, got, test.ShouldResemble, geo.NewPoint(40.7, -74.0))
+	test.That(t, alt, test.ShouldEqual, 10)
+
+	acc, err := ms.Accuracy(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, acc.NmeaFix, test.ShouldEqual, 0)
+	test.That(t, math.IsNaN(float64(acc.Hdop)), test.ShouldBeTrue)
+
+	readings, err := ms.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["fix"], test.ShouldEqual, 0)
+	test.That(t, readings["position"], test.ShouldResemble, geo.NewPoint(40.7, -74.0))
+
+	// The fault clears itself once its duration has passed.
+	now = now.Add(31 * time.Second)
+	got, _, err = ms.Position(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, got, test.ShouldResemble, pos)
+	resp, err := ms.DoCommand(ctx, map[string]interface{}{"command": "get_faults"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["active"], test.ShouldBeEmpty)
+}
+
+func TestWrapUnsupported(t *testing.T) {
+	injectArm := inject.NewArm("arm")
+	test.That(t, Wrap(injectArm, logging.NewTestLogger(t)), test.ShouldEqual, injectArm)
+}
@@ -0,0 +1,129 @@
+package faultinjection
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/resource"
+)
+
+// injectableMotor is a motor that can be made to stall or disconnect.
+type injectableMotor struct {
+	motor.Motor
+	faults *faults
+}
+
+// checkMove returns an error if the motor cannot currently be moved.
+func (m *injectableMotor) checkMove() error {
+	if err := m.faults.disconnected(); err != nil {
+		return err
+	}
+	if m.faults.isActive(FaultStall) {
+		return errors.Wrap(ErrInjected, "motor stalled")
+	}
+	return nil
+}
+
+func (m *injectableMotor) SetPower(ctx context.Context, powerPct float64, extra map[string]interface{}) error {
+	if err := m.checkMove(); err != nil {
+		return err
+	}
+	return m.Motor.SetPower(ctx, powerPct, extra)
+}
+
+func (m *injectableMotor) GoFor(ctx context.Context, rpm, revolutions float64, extra map[string]interface{}) error {
+	if err := m.checkMove(); err != nil {
+		return err
+	}
+	return m.Motor.GoFor(ctx, rpm, revolutions, extra)
+}
+
+func (m *injectableMotor) GoTo(ctx context.Context, rpm, positionRevolutions float64, extra map[string]interface{}) error {
+	if err := m.checkMove(); err != nil {
+		return err
+	}
+	return m.Motor.GoTo(ctx, rpm, positionRevolutions, extra)
+}
+
+func (m *injectableMotor) SetRPM(ctx context.Context, rpm float64, extra map[string]interface{}) error {
+	if err := m.checkMove(); err != nil {
+		return err
+	}
+	return m.Motor.SetRPM(ctx, rpm, extra)
+}
+
+func (m *injectableMotor) ResetZeroPosition(ctx context.Context, offset float64, extra map[string]interface{}) error {
+	if err := m.faults.disconnected(); err != nil {
+		return err
+	}
+	return m.Motor.ResetZeroPosition(ctx, offset, extra)
+}
+
+func (m *injectableMotor) Position(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	if err := m.faults.disconnected(); err != nil {
+		return 0, err
+	}
+	return m.Motor.Position(ctx, extra)
+}
+
+func (m *injectableMotor) Properties(ctx context.Context, extra map[string]interface{}) (motor.Properties, error) {
+	if err := m.faults.disconnected(); err != nil {
+		return motor.Properties{}, err
+	}
+	return m.Motor.Properties(ctx, extra)
+}
+
+func (m *injectableMotor) IsPowered(ctx context.Context, extra map[string]interface{}) (bool, float64, error) {
+	if err := m.faults.disconnected(); err != nil {
+		return false, 0, err
+	}
+	if m.faults.isActive(FaultStall) {
+		return false, 0, nil
+	}
+	return m.Motor.IsPowered(ctx, extra)
+}
+
+func (m *injectableMotor) IsMoving(ctx context.Context) (bool, error) {
+	if err := m.faults.disconnected(); err != nil {
+		return false, err
+	}
+	if m.faults.isActive(FaultStall) {
+		return false, nil
+	}
+	return m.Motor.IsMoving(ctx)
+}
+
+// Stop always stops the real motor, even when it is disconnected, so that a motor already moving
+// can be stopped while a fault is injected.
+func (m *injectableMotor) Stop(ctx context.Context, extra map[string]interface{}) error {
+	err := m.Motor.Stop(ctx, extra)
+	if faultErr := m.faults.disconnected(); faultErr != nil {
+		return multierr.Combine(faultErr, err)
+	}
+	return err
+}
+
+// Unwrap returns the wrapped motor, so that its optional interfaces can be found.
+func (m *injectableMotor) Unwrap() resource.Resource {
+	return m.Motor
+}
+
+func (m *injectableMotor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	resp, handled, err := m.faults.doCommand(cmd)
+	if handled {
+		if err == nil && m.faults.isActive(FaultStall) {
+			// a stalled motor is not moving, so make sure the real one isn't either.
+			if err := m.Motor.Stop(ctx, nil); err != nil {
+				return nil, errors.Wrap(err, "failed to stop motor for injected stall")
+			}
+		}
+		return resp, err
+	}
+	if err := m.faults.disconnected(); err != nil {
+		return nil, err
+	}
+	return m.Motor.DoCommand(ctx, cmd)
+}
@@ -0,0 +1,144 @@
+package faultinjection
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// injectableMovementSensor is a movement sensor that can be made to lose its GPS fix or disconnect.
+type injectableMovementSensor struct {
+	movementsensor.MovementSensor
+	faults *faults
+
+	mu sync.Mutex
+	// lastPosition is the last position reported before the fix was lost, which is what
+	// GPS drivers keep reporting without a fix.
+	lastPosition *geo.Point
+	lastAltitude float64
+}
+
+func (ms *injectableMovementSensor) lostPosition() (*geo.Point, float64) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.lastPosition == nil {
+		return geo.NewPoint(math.NaN(), math.NaN()), math.NaN()
+	}
+	return ms.lastPosition, ms.lastAltitude
+}
+
+func (ms *injectableMovementSensor) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	if err := ms.faults.disconnected(); err != nil {
+		return nil, 0, err
+	}
+	if ms.faults.isActive(FaultLoseFix) {
+		pos, alt := ms.lostPosition()
+		return pos, alt, errors.Wrap(ErrInjected, "no gps fix")
+	}
+	pos, alt, err := ms.MovementSensor.Position(ctx, extra)
+	if err == nil && pos != nil && !movementsensor.IsPositionNaN(pos) {
+		ms.mu.Lock()
+		ms.lastPosition, ms.lastAltitude = pos, alt
+		ms.mu.Unlock()
+	}
+	return pos, alt, err
+}
+
+func (ms *injectableMovementSensor) Accuracy(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error) {
+	if err := ms.faults.disconnected(); err != nil {
+		return nil, err
+	}
+	acc, err := ms.MovementSensor.Accuracy(ctx, extra)
+	if err != nil || acc == nil || !ms.faults.isActive(FaultLoseFix) {
+		return acc, err
+	}
+	lost := *acc
+	lost.NmeaFix = 0
+	lost.Hdop = float32(math.NaN())
+	lost.Vdop = float32(math.NaN())
+	return &lost, nil
+}
+
+func (ms *injectableMovementSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	if err := ms.faults.disconnected(); err != nil {
+		return nil, err
+	}
+	readings, err := ms.MovementSensor.Readings(ctx, extra)
+	if err != nil || !ms.faults.isActive(FaultLoseFix) {
+		return readings, err
+	}
+	if _, ok := readings["position"]; ok {
+		readings["position"], readings["altitude"] = ms.lostPosition()
+	}
+	if _, ok := readings["fix"]; ok {
+		readings["fix"] = 0
+	}
+	return readings, nil
+}
+
+func (ms *injectableMovementSensor) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	if err := ms.faults.disconnected(); err != nil {
+		return r3.Vector{}, err
+	}
+	return ms.MovementSensor.LinearVelocity(ctx, extra)
+}
+
+func (ms *injectableMovementSensor) AngularVelocity(
+	ctx context.Context, extra map[string]interface{},
+) (spatialmath.AngularVelocity, error) {
+	if err := ms.faults.disconnected(); err != nil {
+		return spatialmath.AngularVelocity{}, err
+	}
+	return ms.MovementSensor.AngularVelocity(ctx, extra)
+}
+
+func (ms *injectableMovementSensor) LinearAcceleration(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	if err := ms.faults.disconnected(); err != nil {
+		return r3.Vector{}, err
+	}
+	return ms.MovementSensor.LinearAcceleration(ctx, extra)
+}
+
+func (ms *injectableMovementSensor) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	if err := ms.faults.disconnected(); err != nil {
+		return 0, err
+	}
+	return ms.MovementSensor.CompassHeading(ctx, extra)
+}
+
+func (ms *injectableMovementSensor) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	if err := ms.faults.disconnected(); err != nil {
+		return nil, err
+	}
+	return ms.MovementSensor.Orientation(ctx, extra)
+}
+
+func (ms *injectableMovementSensor) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	if err := ms.faults.disconnected(); err != nil {
+		return nil, err
+	}
+	return ms.MovementSensor.Properties(ctx, extra)
+}
+
+func (ms *injectableMovementSensor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if resp, handled, err := ms.faults.doCommand(cmd); handled {
+		return resp, err
+	}
+	if err := ms.faults.disconnected(); err != nil {
+		return nil, err
+	}
+	return ms.MovementSensor.DoCommand(ctx, cmd)
+}
+
+// Unwrap returns the wrapped movement sensor, so that its optional interfaces can be found.
+func (ms *injectableMovementSensor) Unwrap() resource.Resource {
+	return ms.MovementSensor
+}
@@ -21,3 +21,8 @@ func DependencyNotFoundError(name string) error {
 func NotInputEnabledError(component resource.Resource) error {
 	return errors.Errorf("%v(%T) is not InputEnabled", component.Name(), component)
 }
+
+// NotPoseSourceError is returned when a frame is bound to a resource that cannot report its pose.
+func NotPoseSourceError(res resource.Resource) error {
+	return errors.Errorf("%v(%T) cannot be used as a pose source", res.Name(), res)
+}
@@ -111,6 +111,15 @@ type Config struct {
 	resource.TriviallyValidateConfig
 	Parts                []*referenceframe.FrameSystemPart
 	AdditionalTransforms []*referenceframe.LinkInFrame
+	// PoseSources maps the names of frames which move with a live pose source to the short name of that source.
+	PoseSources map[string]string
+}
+
+// A PoseSource is a resource which can report a live pose, such as a SLAM service. Frames bound to a PoseSource
+// are placed wherever the source currently says they are each time the frame system is built.
+type PoseSource interface {
+	resource.Resource
+	Position(ctx context.Context) (spatialmath.Pose, error)
 }
 
 // String prints out a table of each frame in the system, with columns of name, parent, translation and orientation.
@@ -154,8 +163,9 @@ type frameSystemService struct {
 	components map[string]resource.Resource
 	logger     logging.Logger
 
-	parts   []*referenceframe.FrameSystemPart
-	partsMu sync.RWMutex
+	parts       []*referenceframe.FrameSystemPart
+	poseSources map[string]string
+	partsMu     sync.RWMutex
 }
 
 // Reconfigure will rebuild the frame system from the newly updated robot.
@@ -187,6 +197,7 @@ func (svc *frameSystemService) Reconfigure(ctx context.Context, deps resource.De
 		return err
 	}
 	svc.parts = sortedParts
+	svc.poseSources = fsCfg.PoseSources
 	svc.logger.Debugf("reconfigured robot frame system: %v", (&Config{Parts: sortedParts}).String())
 	return nil
 }
@@ -289,9 +300,54 @@ func (svc *frameSystemService) FrameSystem(
 	ctx context.Context,
 	additionalTransforms []*referenceframe.LinkInFrame,
 ) (referenceframe.FrameSystem, error) {
-	_, span := trace.StartSpan(ctx, "services::framesystem::FrameSystem")
+	ctx, span := trace.StartSpan(ctx, "services::framesystem::FrameSystem")
 	defer span.End()
-	return referenceframe.NewFrameSystem(LocalFrameSystemName, svc.parts, additionalTransforms)
+	parts, err := svc.partsWithLivePoses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return referenceframe.NewFrameSystem(LocalFrameSystemName, parts, additionalTransforms)
+}
+
+// partsWithLivePoses returns the parts of the frame system, with any frames that are bound to a pose source
+// moved to wherever that source currently says they are.
+func (svc *frameSystemService) partsWithLivePoses(ctx context.Context) ([]*referenceframe.FrameSystemPart, error) {
+	svc.partsMu.RLock()
+	defer svc.partsMu.RUnlock()
+
+	if len(svc.poseSources) == 0 {
+		return svc.parts, nil
+	}
+
+	parts := make([]*referenceframe.FrameSystemPart, 0, len(svc.parts))
+	for _, part := range svc.parts {
+		sourceName, ok := svc.poseSources[part.FrameConfig.Name()]
+		if !ok {
+			parts = append(parts, part)
+			continue
+		}
+		res, ok := svc.components[sourceName]
+		if !ok {
+			return nil, DependencyNotFoundError(sourceName)
+		}
+		source, ok := res.(PoseSource)
+		if !ok {
+			return nil, NotPoseSourceError(res)
+		}
+		pose, err := source.Position(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		// the configured pose is an offset from wherever the source says the frame is
+		frameCfg := part.FrameConfig
+		livePose := spatialmath.Compose(pose, frameCfg.Pose())
+		parts = append(parts, &referenceframe.FrameSystemPart{
+			FrameConfig: referenceframe.NewLinkInFrame(frameCfg.Parent(), livePose, frameCfg.Name(), frameCfg.Geometry()),
+			ModelFrame:  part.ModelFrame,
+		})
+	}
+	return parts, nil
 }
 
 // TransformPointCloud applies the same pose offset to each point in a single pointcloud and returns the transformed point cloud.
@@ -13,9 +13,12 @@ import (
 	"go.viam.com/rdk/config"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/framesystem"
 	robotimpl "go.viam.com/rdk/robot/impl"
 	_ "go.viam.com/rdk/services/register"
 	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
 	rdkutils "go.viam.com/rdk/utils"
 )
 
@@ -273,3 +276,61 @@ func TestNewFrameSystemFromBadConfig(t *testing.T) {
 		test.That(t, fs, test.ShouldBeNil)
 	})
 }
+
+func TestFrameSystemWithPoseSource(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	baseCfg := &referenceframe.LinkConfig{
+		ID:         "base",
+		Parent:     referenceframe.World,
+		PoseSource: "slam",
+	}
+	baseLif, err := baseCfg.ParseConfig()
+	test.That(t, err, test.ShouldBeNil)
+	cameraCfg := &referenceframe.LinkConfig{
+		ID:          "camera",
+		Parent:      "base",
+		Translation: r3.Vector{Z: 100},
+	}
+	cameraLif, err := cameraCfg.ParseConfig()
+	test.That(t, err, test.ShouldBeNil)
+
+	slamPose := spatialmath.NewPoseFromPoint(r3.Vector{X: 1000, Y: 2000})
+	slamSvc := inject.NewSLAMService("slam")
+	slamSvc.PositionFunc = func(ctx context.Context) (spatialmath.Pose, error) {
+		return slamPose, nil
+	}
+
+	fsSvc, err := framesystem.New(ctx, resource.Dependencies{}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	fsCfg := &framesystem.Config{
+		Parts:       []*referenceframe.FrameSystemPart{{FrameConfig: baseLif}, {FrameConfig: cameraLif}},
+		PoseSources: map[string]string{"base": "slam"},
+	}
+	err = fsSvc.Reconfigure(ctx, resource.Dependencies{slamSvc.Name(): slamSvc}, resource.Config{ConvertedAttributes: fsCfg})
+	test.That(t, err, test.ShouldBeNil)
+
+	transformed, err := fsSvc.TransformPose(ctx, referenceframe.NewPoseInFrame("camera", spatialmath.NewZeroPose()), referenceframe.World, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.R3VectorAlmostEqual(transformed.Pose().Point(), r3.Vector{X: 1000, Y: 2000, Z: 100}, 1e-6), test.ShouldBeTrue)
+
+	// moving the source moves the frame
+	slamPose = spatialmath.NewPoseFromPoint(r3.Vector{X: -500})
+	transformed, err = fsSvc.TransformPose(ctx, referenceframe.NewPoseInFrame("camera", spatialmath.NewZeroPose()), referenceframe.World, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.R3VectorAlmostEqual(transformed.Pose().Point(), r3.Vector{X: -500, Z: 100}, 1e-6), test.ShouldBeTrue)
+
+	// errors from the source are surfaced
+	slamSvc.PositionFunc = func(ctx context.Context) (spatialmath.Pose, error) {
+		return nil, errors.New("lost")
+	}
+	_, err = fsSvc.TransformPose(ctx, referenceframe.NewPoseInFrame("camera", spatialmath.NewZeroPose()), referenceframe.World, nil)
+	test.That(t, err, test.ShouldBeError, errors.New("lost"))
+
+	// a missing source is reported
+	err = fsSvc.Reconfigure(ctx, resource.Dependencies{}, resource.Config{ConvertedAttributes: fsCfg})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = fsSvc.FrameSystem(ctx, nil)
+	test.That(t, err, test.ShouldBeError, framesystem.DependencyNotFoundError("slam"))
+}
@@ -31,6 +31,7 @@ import (
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot"
 	"go.viam.com/rdk/robot/client"
+	"go.viam.com/rdk/robot/faultinjection"
 	"go.viam.com/rdk/robot/framesystem"
 	"go.viam.com/rdk/robot/packages"
 	"go.viam.com/rdk/robot/web"
@@ -79,6 +80,20 @@ type localRobot struct {
 	// map keyed by Module.Name. This is necessary to get the package manager to use a new folder
 	// when a local tarball is updated.
 	localModuleVersions map[string]semver.Version
+
+	// reconfigureMu serializes reconfiguration and module restarts, so that those queued while the
+	// robot is busy are not applied on top of newer ones. It guards the fields below.
+	reconfigureMu sync.Mutex
+	// maintenance is the maintenance config of the latest config given to Reconfigure.
+	maintenance           *config.MaintenanceConfig
+	pendingConfig         *config.Config
+	pendingModuleRestarts []robot.RestartModuleRequest
+
+	// selfTestConfig is the self-test config of the latest config.
+	selfTestConfig atomic.Pointer[config.SelfTestConfig]
+	// selfTestMu serializes self-test runs, so that two never move the same component at once.
+	selfTestMu         sync.Mutex
+	lastSelfTestReport atomic.Pointer[robot.SelfTestReport]
 }
 
 // ExportResourcesAsDot exports the resource graph as a DOT representation for
@@ -88,6 +103,66 @@ func (r *localRobot) ExportResourcesAsDot(index int) (resource.GetSnapshotInfo,
 	return r.manager.ExportDot(index)
 }
 
+// ResourceLogLevels returns the level each local resource is logging at.
+func (r *localRobot) ResourceLogLevels() map[resource.Name]logging.Level {
+	levels := map[resource.Name]logging.Level{}
+	for _, name := range r.manager.resources.Names() {
+		if !(name.API.IsComponent() || name.API.IsService()) || name.ContainsRemoteNames() {
+			continue
+		}
+		if gNode, ok := r.manager.resources.Node(name); ok {
+			levels[name], _ = gNode.LogLevel()
+		}
+	}
+	return levels
+}
+
+// SetResourceLogLevel changes the level a resource logs at without reconfiguring it, taking
+// precedence over its config until set again. A nil level restores the level in its config.
+func (r *localRobot) SetResourceLogLevel(name resource.Name, level *logging.Level) error {
+	gNode, ok := r.manager.resources.Node(name)
+	if !ok || name.ContainsRemoteNames() {
+		return resource.NewNotFoundError(name)
+	}
+	gNode.OverrideLogLevel(level)
+	return nil
+}
+
+// ResourceStatuses returns the lifecycle status of each local resource.
+func (r *localRobot) ResourceStatuses() map[resource.Name]resource.NodeStatus {
+	statuses := map[resource.Name]resource.NodeStatus{}
+	for _, name := range r.manager.resources.Names() {
+		if !(name.API.IsComponent() || name.API.IsService()) || name.ContainsRemoteNames() {
+			continue
+		}
+		if gNode, ok := r.manager.resources.Node(name); ok {
+			statuses[name] = gNode.Status()
+		}
+	}
+	return statuses
+}
+
+// ModuleStatuses returns the status of each module's process.
+func (r *localRobot) ModuleStatuses() []robot.ModuleStatus {
+	return r.manager.moduleManager.Statuses()
+}
+
+// MarkResourceOperationSucceeded records that an operation on a local resource just completed
+// without error.
+func (r *localRobot) MarkResourceOperationSucceeded(name resource.Name) {
+	if gNode, ok := r.manager.resources.Node(name); ok {
+		gNode.MarkOperationSucceeded()
+	}
+}
+
+// MarkResourceOperationFailed records that an operation on a local resource just failed. Local
+// components whose operations keep failing are rebuilt.
+func (r *localRobot) MarkResourceOperationFailed(name resource.Name) {
+	if gNode, ok := r.manager.resources.Node(name); ok {
+		gNode.MarkOperationFailed()
+	}
+}
+
 // RemoteByName returns a remote robot by name. If it does not exist
 // nil is returned.
 func (r *localRobot) RemoteByName(name string) (robot.Robot, bool) {
@@ -136,8 +211,14 @@ func (r *localRobot) PackageManager() packages.Manager {
 }
 
 // Close attempts to cleanly close down all constituent parts of the robot. It does not wait on reconfigureWorkers,
-// as they may be running outside code and have unexpected behavior.
+// as they may be running outside code and have unexpected behavior. Resources are given the chance to prepare for
+// shutdown first, while the web service is still up, and are then closed with dependents before their dependencies.
 func (r *localRobot) Close(ctx context.Context) error {
+	var err error
+	if r.manager != nil {
+		err = multierr.Combine(err, r.manager.preShutdown(ctx))
+	}
+
 	// we will stop and close web ourselves since modules need it to be
 	// removed properly and in the right order, so grab it before its removed
 	// from the graph/closed automatically.
@@ -155,7 +236,6 @@ func (r *localRobot) Close(ctx context.Context) error {
 	r.activeBackgroundWorkers.Wait()
 	r.sessionManager.Close()
 
-	var err error
 	if r.cloudConnSvc != nil {
 		err = multierr.Combine(err, r.cloudConnSvc.Close(ctx))
 	}
@@ -171,31 +251,91 @@ func (r *localRobot) Close(ctx context.Context) error {
 	return err
 }
 
-// StopAll cancels all current and outstanding operations for the robot and stops all actuators and movement.
+// stopAllTimeout bounds how long StopAll waits for anything to stop, so that a resource or remote which hangs
+// can't keep StopAll from reporting on the rest.
+const stopAllTimeout = 5 * time.Second
+
+// StopAll cancels all current and outstanding operations for the robot, and stops every local actuator, modular
+// ones included, and every remote part at once.
 func (r *localRobot) StopAll(ctx context.Context, extra map[resource.Name]map[string]interface{}) error {
 	// Stop all operations
 	for _, op := range r.OperationManager().All() {
 		op.Cancel()
 	}
 
-	// Stop all stoppable resources
-	resourceErrs := []string{}
+	ctx, cancel := context.WithTimeout(ctx, stopAllTimeout)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		stopErrs = map[resource.Name]error{}
+		wg       sync.WaitGroup
+	)
+	setErr := func(name resource.Name, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		stopErrs[name] = err
+	}
+	stop := func(name resource.Name, stopFunc func(ctx context.Context) error) {
+		wg.Add(1)
+		goutils.PanicCapturingGo(func() {
+			defer wg.Done()
+			// a stop which ignores its context is left running rather than waited on
+			errCh := make(chan error, 1)
+			goutils.PanicCapturingGo(func() {
+				errCh <- stopFunc(ctx)
+			})
+			select {
+			case err := <-errCh:
+				if err != nil {
+					setErr(name, err)
+				}
+			case <-ctx.Done():
+				setErr(name, ctx.Err())
+			}
+		})
+	}
+
+	// Stop all stoppable local resources. Resources on remotes are stopped by their own robot below.
 	for _, name := range r.ResourceNames() {
+		if name.ContainsRemoteNames() {
+			continue
+		}
+		name := name
 		res, err := r.ResourceByName(name)
 		if err != nil {
-			resourceErrs = append(resourceErrs, name.Name)
+			setErr(name, err)
 			continue
 		}
-
 		if actuator, ok := res.(resource.Actuator); ok {
-			if err := actuator.Stop(ctx, extra[name]); err != nil {
-				resourceErrs = append(resourceErrs, name.Name)
+			stop(name, func(ctx context.Context) error {
+				return actuator.Stop(ctx, extra[name])
+			})
+		}
+	}
+
+	for _, remoteName := range r.RemoteNames() {
+		remote, ok := r.RemoteByName(remoteName)
+		if !ok {
+			setErr(resource.NewName(client.RemoteAPI, remoteName), errors.New("remote is not connected"))
+			continue
+		}
+		remoteExtra := map[resource.Name]map[string]interface{}{}
+		for name, resExtra := range extra {
+			if name.Remote == remoteName || strings.HasPrefix(name.Remote, remoteName+":") {
+				remoteExtra[name.PopRemote()] = resExtra
 			}
 		}
+		stop(resource.NewName(client.RemoteAPI, remoteName), func(ctx context.Context) error {
+			return remote.StopAll(ctx, remoteExtra)
+		})
 	}
 
-	if len(resourceErrs) > 0 {
-		return errors.Errorf("failed to stop components named %s", strings.Join(resourceErrs, ","))
+	wg.Wait()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(stopErrs) > 0 {
+		return &robot.StopAllError{Errors: stopErrs}
 	}
 	return nil
 }
@@ -405,6 +545,8 @@ func newWithResources(
 				allowInsecureCreds: cfg.AllowInsecureCreds,
 				untrustedEnv:       cfg.UntrustedEnv,
 				tlsConfig:          cfg.Network.TLSConfig,
+				fleetCA:            cfg.Network.FleetCA,
+				webrtc:             cfg.Network.WebRTC,
 			},
 			logger,
 		),
@@ -510,6 +652,7 @@ func newWithResources(
 		closeCtx,
 		r.webSvc.ModuleAddress(),
 		r.removeOrphanedResources,
+		r.setResourcesUnavailable,
 		cfg.UntrustedEnv,
 		homeDir,
 		cloudID,
@@ -551,6 +694,41 @@ func newWithResources(
 		}
 	}, r.activeBackgroundWorkers.Done)
 
+	r.activeBackgroundWorkers.Add(1)
+	// This goroutine rebuilds components which have stopped working, and triggers the
+	// configuration attempt which builds them again.
+	goutils.ManagedGo(func() {
+		wd := newWatchdog(r)
+		ticker := time.NewTicker(watchdogInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-closeCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			if wd.check(closeCtx, time.Now()) {
+				r.sendTriggerConfig("watchdog")
+			}
+		}
+	}, r.activeBackgroundWorkers.Done)
+
+	r.activeBackgroundWorkers.Add(1)
+	// This goroutine applies reconfiguration which was queued while the maintenance sensor
+	// reported that the robot was busy.
+	goutils.ManagedGo(func() {
+		ticker := time.NewTicker(maintenanceCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-closeCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			r.applyQueuedReconfiguration(closeCtx)
+		}
+	}, r.activeBackgroundWorkers.Done)
+
 	r.Reconfigure(ctx, cfg)
 
 	for name, res := range resources {
@@ -564,6 +742,15 @@ func newWithResources(
 		r.updateWeakDependents(ctx)
 	}
 
+	if cfg.SelfTest != nil && cfg.SelfTest.RunOnStartup {
+		r.activeBackgroundWorkers.Add(1)
+		// This goroutine runs the startup self-tests without holding up startup, since some, such as
+		// waiting for a GPS fix, may take a while.
+		goutils.ManagedGo(func() {
+			r.runStartupSelfTest(closeCtx)
+		}, r.activeBackgroundWorkers.Done)
+	}
+
 	successful = true
 	return r, nil
 }
@@ -591,6 +778,16 @@ func (r *localRobot) removeOrphanedResources(ctx context.Context,
 	r.updateWeakDependents(ctx)
 }
 
+// setResourcesUnavailable is called by the module manager to make the resources of a
+// crashed module unavailable while it restarts, and available again once it has.
+func (r *localRobot) setResourcesUnavailable(rNames []resource.Name, err error) {
+	for _, name := range rNames {
+		if gNode, ok := r.manager.resources.Node(name); ok {
+			gNode.SetUnavailable(err)
+		}
+	}
+}
+
 // getDependencies derives a collection of dependencies from a robot for a given
 // component's name. We don't use the resource manager for this information since
 // it is not be constructed at this point.
@@ -701,13 +898,21 @@ func (r *localRobot) newResource(
 		}
 	}
 
-	if resInfo.Constructor != nil {
-		return resInfo.Constructor(ctx, deps, conf, gNode.Logger())
-	}
-	if resInfo.DeprecatedRobotConstructor == nil {
+	switch {
+	case resInfo.Constructor != nil:
+		res, err = resInfo.Constructor(ctx, deps, conf, gNode.Logger())
+	case resInfo.DeprecatedRobotConstructor != nil:
+		res, err = resInfo.DeprecatedRobotConstructor(ctx, r, conf, gNode.Logger())
+	default:
 		return nil, errors.Errorf("invariant: no constructor for %q", conf.API)
 	}
-	return resInfo.DeprecatedRobotConstructor(ctx, r, conf, gNode.Logger())
+	if err != nil {
+		return nil, err
+	}
+	if cfg, ok := r.mostRecentCfg.Load().(config.Config); ok && cfg.EnableFaultInjection {
+		res = faultinjection.Wrap(res, gNode.Logger())
+	}
+	return res, nil
 }
 
 func (r *localRobot) updateWeakDependents(ctx context.Context) {
@@ -771,7 +976,19 @@ func (r *localRobot) updateWeakDependents(ctx context.Context) {
 					r.Logger().CErrorw(ctx, "failed to reconfigure internal service during weak dependencies update", "service", resName, "error", err)
 					break
 				}
-				if err := res.Reconfigure(ctxWithTimeout, components, resource.Config{ConvertedAttributes: fsCfg}); err != nil {
+				// The frame system depends on all components, plus any services its frames get their poses from.
+				fsDeps := make(resource.Dependencies, len(components))
+				for n, res := range components {
+					fsDeps[n] = res
+				}
+				for _, sourceName := range fsCfg.PoseSources {
+					for n, res := range allResources {
+						if n.API.IsService() && n.ShortName() == sourceName {
+							fsDeps[n] = res
+						}
+					}
+				}
+				if err := res.Reconfigure(ctxWithTimeout, fsDeps, resource.Config{ConvertedAttributes: fsCfg}); err != nil {
 					r.Logger().CErrorw(ctx, "failed to reconfigure internal service during weak dependencies update", "service", resName, "error", err)
 				}
 			case packages.InternalServiceName, packages.DeferredServiceName, icloud.InternalServiceName:
@@ -871,7 +1088,7 @@ func (r *localRobot) updateWeakDependents(ctx context.Context) {
 // The output of this function is to be sent over GRPC to the client, so the client
 // can build its frame system. requests the remote components from the remote's frame system service.
 func (r *localRobot) FrameSystemConfig(ctx context.Context) (*framesystem.Config, error) {
-	localParts, err := r.getLocalFrameSystemParts()
+	localParts, poseSources, err := r.getLocalFrameSystemParts()
 	if err != nil {
 		return nil, err
 	}
@@ -880,25 +1097,27 @@ func (r *localRobot) FrameSystemConfig(ctx context.Context) (*framesystem.Config
 		return nil, err
 	}
 
-	return &framesystem.Config{Parts: append(localParts, remoteParts...)}, nil
+	return &framesystem.Config{Parts: append(localParts, remoteParts...), PoseSources: poseSources}, nil
 }
 
 // getLocalFrameSystemParts collects and returns the physical parts of the robot that may have frame info,
-// excluding remote robots and services, etc from the robot's config.Config.
-func (r *localRobot) getLocalFrameSystemParts() ([]*referenceframe.FrameSystemPart, error) {
+// excluding remote robots and services, etc from the robot's config.Config. It also returns the names of
+// any pose sources the parts' frames are bound to, keyed by frame name.
+func (r *localRobot) getLocalFrameSystemParts() ([]*referenceframe.FrameSystemPart, map[string]string, error) {
 	cfg := r.Config()
 
 	parts := make([]*referenceframe.FrameSystemPart, 0)
+	poseSources := make(map[string]string)
 	for _, component := range cfg.Components {
 		if component.Frame == nil { // no Frame means dont include in frame system.
 			continue
 		}
 
 		if component.Name == referenceframe.World {
-			return nil, errors.Errorf("cannot give frame system part the name %s", referenceframe.World)
+			return nil, nil, errors.Errorf("cannot give frame system part the name %s", referenceframe.World)
 		}
 		if component.Frame.Parent == "" {
-			return nil, errors.Errorf("parent field in frame config for part %q is empty", component.Name)
+			return nil, nil, errors.Errorf("parent field in frame config for part %q is empty", component.Name)
 		}
 		cfgCopy := &referenceframe.LinkConfig{
 			ID:          component.Frame.ID,
@@ -919,12 +1138,15 @@ func (r *localRobot) getLocalFrameSystemParts() ([]*referenceframe.FrameSystemPa
 		}
 		lif, err := cfgCopy.ParseConfig()
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+		if component.Frame.PoseSource != "" {
+			poseSources[cfgCopy.ID] = component.Frame.PoseSource
 		}
 
 		parts = append(parts, &referenceframe.FrameSystemPart{FrameConfig: lif, ModelFrame: model})
 	}
-	return parts, nil
+	return parts, poseSources, nil
 }
 
 func (r *localRobot) getRemoteFrameSystemParts(ctx context.Context) ([]*referenceframe.FrameSystemPart, error) {
@@ -1135,7 +1357,18 @@ func dialRobotClient(
 // Reconfigure will safely reconfigure a robot based on the given config. It will make
 // a best effort to remove no longer in use parts, but if it fails to do so, they could
 // possibly leak resources. The given config may be modified by Reconfigure.
+//
+// If the config has a maintenance sensor, and it reports that the robot is busy, the config is
+// instead queued and applied once the robot is idle.
 func (r *localRobot) Reconfigure(ctx context.Context, newConfig *config.Config) {
+	r.reconfigureMu.Lock()
+	defer r.reconfigureMu.Unlock()
+	r.maintenance = newConfig.Maintenance
+	if r.holdForMaintenance(ctx, "reconfiguration") {
+		r.pendingConfig = newConfig
+		return
+	}
+	r.pendingConfig = nil
 	r.reconfigure(ctx, newConfig, false)
 }
 
@@ -1153,8 +1386,30 @@ func (r *localRobot) applyLocalModuleVersions(cfg *config.Config) {
 	}
 }
 
+// updateOperationTimeouts sets the default operation timeouts of the RPC services of the APIs in cfg. Call this
+// after modules are added so that their APIs can be found.
+func (r *localRobot) updateOperationTimeouts(ctx context.Context, cfg *config.Config) {
+	timeouts := make(map[string]operation.MethodTimeouts, len(cfg.OperationTimeouts))
+	for _, opTimeout := range cfg.OperationTimeouts {
+		timeout := operation.MethodTimeouts{Read: opTimeout.ReadDuration(), Actuation: opTimeout.ActuationDuration()}
+		if timeout.Read <= 0 && timeout.Actuation <= 0 {
+			continue
+		}
+		reg, ok := resource.LookupGenericAPIRegistration(opTimeout.API)
+		if !ok || reg.RPCServiceDesc == nil {
+			r.logger.CWarnw(ctx, "no RPC service found for API; ignoring its operation timeout", "api", opTimeout.API)
+			continue
+		}
+		timeouts[reg.RPCServiceDesc.ServiceName] = timeout
+	}
+	r.OperationManager().SetDefaultMethodTimeouts(timeouts)
+}
+
 func (r *localRobot) reconfigure(ctx context.Context, newConfig *config.Config, forceSync bool) {
 	var allErrs error
+	r.manager.shutdownConfig.Store(newConfig.Shutdown)
+	r.selfTestConfig.Store(newConfig.SelfTest)
+	r.sessionManager.SetStopGracePeriod(newConfig.Network.Sessions.StopGracePeriod)
 
 	// Sync Packages before reconfiguring rest of robot and resolving references to any packages
 	// in the config.
@@ -1225,6 +1480,8 @@ func (r *localRobot) reconfigure(ctx context.Context, newConfig *config.Config,
 		r.logger.CErrorw(ctx, "error diffing the configs", "error", err)
 		return
 	}
+	// operation timeouts are not resources, so they are updated even when no resource changed.
+	defer r.updateOperationTimeouts(ctx, newConfig)
 	if diff.ResourcesEqual {
 		return
 	}
@@ -1337,7 +1594,19 @@ func (r *localRobot) restartSingleModule(ctx context.Context, mod *config.Module
 	return r.manager.updateResources(ctx, &diff)
 }
 
+// RestartModule restarts the module matching req, or queues it to be restarted once the robot is idle if
+// the maintenance sensor reports that it is busy.
 func (r *localRobot) RestartModule(ctx context.Context, req robot.RestartModuleRequest) error {
+	r.reconfigureMu.Lock()
+	defer r.reconfigureMu.Unlock()
+	if r.holdForMaintenance(ctx, "module restart") {
+		r.queueModuleRestart(req)
+		return nil
+	}
+	return r.restartModule(ctx, req)
+}
+
+func (r *localRobot) restartModule(ctx context.Context, req robot.RestartModuleRequest) error {
 	mod := utils.FindInSlice(r.Config().Modules, req.MatchesModule)
 	if mod == nil {
 		return fmt.Errorf(
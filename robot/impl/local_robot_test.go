@@ -761,6 +761,80 @@ func TestStopAll(t *testing.T) {
 	test.That(t, stopAllErr, test.ShouldBeNil)
 }
 
+// stopArm is an arm whose Stop does whatever its test tells it to.
+type stopArm struct {
+	arm.Arm
+	name resource.Name
+	stop func(ctx context.Context) error
+}
+
+func (sa *stopArm) Name() resource.Name {
+	return sa.name
+}
+
+func (sa *stopArm) Stop(ctx context.Context, extra map[string]interface{}) error {
+	return sa.stop(ctx)
+}
+
+func (sa *stopArm) Close(ctx context.Context) error {
+	return nil
+}
+
+func TestStopAllFailures(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+
+	stopErr := errors.New("brakes failed")
+	model := resource.DefaultModelFamily.WithModel(utils.RandomAlphaString(8))
+	resource.RegisterComponent(
+		arm.API,
+		model,
+		resource.Registration[arm.Arm, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (arm.Arm, error) {
+			sa := &stopArm{name: conf.ResourceName()}
+			switch conf.Name {
+			case "failing":
+				sa.stop = func(ctx context.Context) error { return stopErr }
+			case "hanging":
+				sa.stop = func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				}
+			default:
+				sa.stop = func(ctx context.Context) error { return nil }
+			}
+			return sa, nil
+		}})
+	defer func() {
+		resource.Deregister(arm.API, model)
+	}()
+
+	armConfig := fmt.Sprintf(`{
+		"components": [
+			{"model": "%[1]s", "name": "working", "type": "arm"},
+			{"model": "%[1]s", "name": "failing", "type": "arm"},
+			{"model": "%[1]s", "name": "hanging", "type": "arm"}
+		]
+	}`, model.String())
+	cfg, err := config.FromReader(context.Background(), "", strings.NewReader(armConfig), logger)
+	test.That(t, err, test.ShouldBeNil)
+	r := setupLocalRobot(t, context.Background(), cfg, logger)
+
+	// a hanging resource doesn't keep StopAll from returning
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = r.StopAll(ctx, nil)
+	var stopAllErr *robot.StopAllError
+	test.That(t, errors.As(err, &stopAllErr), test.ShouldBeTrue)
+	test.That(t, stopAllErr.Errors, test.ShouldHaveLength, 2)
+	test.That(t, stopAllErr.Errors[arm.Named("failing")], test.ShouldBeError, stopErr)
+	test.That(t, stopAllErr.Errors[arm.Named("hanging")], test.ShouldBeError, context.DeadlineExceeded)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "brakes failed")
+}
+
 type dummyBoard struct {
 	board.Board
 	closeCount int
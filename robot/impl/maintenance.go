@@ -0,0 +1,108 @@
+package robotimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+// maintenanceCheckInterval is how often queued reconfiguration is retried while the maintenance sensor reports
+// that the robot is busy.
+const maintenanceCheckInterval = 5 * time.Second
+
+// reconfigureAllowed returns whether the maintenance sensor in conf reports that the robot is idle, so that its
+// resources may be torn down. Robots without a maintenance sensor may always be reconfigured, as may robots whose
+// sensor has not been built yet, such as on startup, since it cannot report that the robot is busy.
+func (r *localRobot) reconfigureAllowed(ctx context.Context, conf *config.MaintenanceConfig) (bool, error) {
+	if conf == nil {
+		return true, nil
+	}
+	res, err := r.ResourceByName(sensor.Named(conf.SensorName))
+	if err != nil {
+		r.logger.CDebugw(ctx, "maintenance sensor not available; reconfiguring anyway", "sensor", conf.SensorName, "error", err)
+		return true, nil
+	}
+	s, ok := res.(resource.Sensor)
+	if !ok {
+		return false, errors.Errorf("maintenance sensor %q does not return readings", conf.SensorName)
+	}
+	ctx, cancel := context.WithTimeout(ctx, maintenanceCheckInterval)
+	defer cancel()
+	readings, err := s.Readings(ctx, nil)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get readings of maintenance sensor %q", conf.SensorName)
+	}
+	value, ok := readings[conf.MaintenanceAllowedKey]
+	if !ok {
+		return false, errors.Errorf("maintenance sensor %q readings have no %q", conf.SensorName, conf.MaintenanceAllowedKey)
+	}
+	allowed, ok := value.(bool)
+	if !ok {
+		return false, errors.Errorf("maintenance sensor %q reading %q is a %T, not a bool",
+			conf.SensorName, conf.MaintenanceAllowedKey, value)
+	}
+	return allowed, nil
+}
+
+// holdForMaintenance returns whether reconfiguration must wait for the maintenance sensor to report that the robot
+// is idle. Reconfiguration also waits when the sensor cannot be read, since the robot may be busy.
+func (r *localRobot) holdForMaintenance(ctx context.Context, what string) bool {
+	allowed, err := r.reconfigureAllowed(ctx, r.maintenance)
+	if err != nil {
+		r.logger.CWarnw(ctx, "cannot tell whether robot is idle; queueing "+what, "error", err)
+		return true
+	}
+	if !allowed {
+		r.logger.CInfow(ctx, "robot is busy; queueing "+what+" until maintenance sensor reports idle",
+			"sensor", r.maintenance.SensorName)
+	}
+	return !allowed
+}
+
+// applyQueuedReconfiguration applies the latest config and the module restarts which were queued while the robot
+// was busy, once the maintenance sensor reports that it is idle.
+func (r *localRobot) applyQueuedReconfiguration(ctx context.Context) {
+	r.reconfigureMu.Lock()
+	defer r.reconfigureMu.Unlock()
+	if r.pendingConfig == nil && len(r.pendingModuleRestarts) == 0 {
+		return
+	}
+	allowed, err := r.reconfigureAllowed(ctx, r.maintenance)
+	if err != nil {
+		r.logger.CDebugw(ctx, "cannot tell whether robot is idle; keeping reconfiguration queued", "error", err)
+		return
+	}
+	if !allowed {
+		return
+	}
+
+	r.logger.CInfo(ctx, "robot is idle; applying queued reconfiguration")
+	if r.pendingConfig != nil {
+		newConfig := r.pendingConfig
+		r.pendingConfig = nil
+		r.reconfigure(ctx, newConfig, false)
+	}
+	restarts := r.pendingModuleRestarts
+	r.pendingModuleRestarts = nil
+	for _, req := range restarts {
+		if err := r.restartModule(ctx, req); err != nil {
+			r.logger.CErrorw(ctx, "error restarting queued module", "error", err)
+		}
+	}
+}
+
+// queueModuleRestart queues req to be restarted once the robot is idle, unless it already is.
+func (r *localRobot) queueModuleRestart(req robot.RestartModuleRequest) {
+	for _, queued := range r.pendingModuleRestarts {
+		if queued == req {
+			return
+		}
+	}
+	r.pendingModuleRestarts = append(r.pendingModuleRestarts, req)
+}
@@ -0,0 +1,100 @@
+package robotimpl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/utils"
+)
+
+func TestMaintenance(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var idle interface{} = true
+	setIdle := func(value interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		idle = value
+	}
+
+	model := resource.DefaultModelFamily.WithModel(utils.RandomAlphaString(8))
+	resource.RegisterComponent(
+		sensor.API,
+		model,
+		resource.Registration[sensor.Sensor, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (sensor.Sensor, error) {
+			s := inject.NewSensor(conf.Name)
+			s.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				return map[string]interface{}{"idle": idle}, nil
+			}
+			return s, nil
+		}})
+	defer func() {
+		resource.Deregister(sensor.API, model)
+	}()
+
+	readConfig := func(names ...string) *config.Config {
+		var components []string
+		for _, name := range names {
+			components = append(components, fmt.Sprintf(`{"model": "%s", "name": "%s", "type": "sensor"}`, model.String(), name))
+		}
+		cfg, err := config.FromReader(ctx, "", strings.NewReader(fmt.Sprintf(`{
+			"components": [%s],
+			"maintenance": {"sensor_name": "maintenance", "maintenance_allowed_key": "idle"}
+		}`, strings.Join(components, ","))), logger)
+		test.That(t, err, test.ShouldBeNil)
+		return cfg
+	}
+	hasSensor := func(lr *localRobot, name string) bool {
+		_, err := lr.ResourceByName(sensor.Named(name))
+		return err == nil
+	}
+
+	// the maintenance sensor is not built until the robot starts, so it cannot hold back startup
+	lr := setupLocalRobot(t, ctx, readConfig("maintenance"), logger).(*localRobot)
+	test.That(t, hasSensor(lr, "maintenance"), test.ShouldBeTrue)
+
+	setIdle(false)
+	lr.Reconfigure(ctx, readConfig("maintenance", "sensor1"))
+	test.That(t, hasSensor(lr, "sensor1"), test.ShouldBeFalse)
+	lr.Reconfigure(ctx, readConfig("maintenance", "sensor2"))
+	test.That(t, hasSensor(lr, "sensor2"), test.ShouldBeFalse)
+	test.That(t, lr.RestartModule(ctx, robot.RestartModuleRequest{ModuleName: "missing"}), test.ShouldBeNil)
+
+	// the robot stays busy while it cannot tell whether it is idle
+	setIdle("yes")
+	lr.applyQueuedReconfiguration(ctx)
+	test.That(t, hasSensor(lr, "sensor2"), test.ShouldBeFalse)
+
+	// only the latest queued config is applied once the robot is idle
+	setIdle(true)
+	lr.applyQueuedReconfiguration(ctx)
+	test.That(t, hasSensor(lr, "sensor1"), test.ShouldBeFalse)
+	test.That(t, hasSensor(lr, "sensor2"), test.ShouldBeTrue)
+	test.That(t, lr.pendingConfig, test.ShouldBeNil)
+	test.That(t, lr.pendingModuleRestarts, test.ShouldBeEmpty)
+
+	// without a busy sensor, reconfiguration and module restarts are not held back
+	test.That(t, lr.RestartModule(ctx, robot.RestartModuleRequest{ModuleName: "missing"}), test.ShouldNotBeNil)
+	lr.Reconfigure(ctx, readConfig("maintenance", "sensor3"))
+	test.That(t, hasSensor(lr, "sensor3"), test.ShouldBeTrue)
+}
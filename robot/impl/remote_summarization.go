@@ -0,0 +1,117 @@
+package robotimpl
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/components/powersensor"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+)
+
+// summarizeRemoteResource wraps a resource obtained from a remote so that requests made faster
+// than the remote's summarization config allows are answered from a cache rather than going over
+// the network. Resources whose API we don't know how to summarize are returned unchanged.
+func summarizeRemoteResource(res resource.Resource, conf *config.RemoteSummarization) resource.Resource {
+	readingsInterval, imagesInterval := conf.Intervals()
+
+	if imagesInterval > 0 {
+		if cam, ok := res.(camera.Camera); ok {
+			return &summarizedCamera{Camera: cam, images: newDecimator[imagesResult](imagesInterval)}
+		}
+	}
+	if readingsInterval > 0 {
+		readings := newDecimator[map[string]interface{}](readingsInterval)
+		// Check the more specific APIs first: they all implement sensor.Sensor too.
+		switch r := res.(type) {
+		case movementsensor.MovementSensor:
+			return &summarizedMovementSensor{MovementSensor: r, readings: readings}
+		case powersensor.PowerSensor:
+			return &summarizedPowerSensor{PowerSensor: r, readings: readings}
+		case sensor.Sensor:
+			return &summarizedSensor{Sensor: r, readings: readings}
+		}
+	}
+	return res
+}
+
+// decimator remembers the last result of some call, and hands it back out until the interval has
+// elapsed. Errors are never cached, so a failed call is retried on the next request.
+type decimator[T any] struct {
+	interval time.Duration
+
+	mu        sync.Mutex
+	last      T
+	lastTime  time.Time
+	haveValue bool
+}
+
+func newDecimator[T any](interval time.Duration) *decimator[T] {
+	return &decimator[T]{interval: interval}
+}
+
+func (d *decimator[T]) get(fetch func() (T, error)) (T, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.haveValue && time.Since(d.lastTime) < d.interval {
+		return d.last, nil
+	}
+	val, err := fetch()
+	if err != nil {
+		return val, err
+	}
+	d.last = val
+	d.lastTime = time.Now()
+	d.haveValue = true
+	return val, nil
+}
+
+type summarizedSensor struct {
+	sensor.Sensor
+	readings *decimator[map[string]interface{}]
+}
+
+func (s *summarizedSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return s.readings.get(func() (map[string]interface{}, error) { return s.Sensor.Readings(ctx, extra) })
+}
+
+type summarizedMovementSensor struct {
+	movementsensor.MovementSensor
+	readings *decimator[map[string]interface{}]
+}
+
+func (s *summarizedMovementSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return s.readings.get(func() (map[string]interface{}, error) { return s.MovementSensor.Readings(ctx, extra) })
+}
+
+type summarizedPowerSensor struct {
+	powersensor.PowerSensor
+	readings *decimator[map[string]interface{}]
+}
+
+func (s *summarizedPowerSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return s.readings.get(func() (map[string]interface{}, error) { return s.PowerSensor.Readings(ctx, extra) })
+}
+
+type imagesResult struct {
+	images []camera.NamedImage
+	meta   resource.ResponseMetadata
+}
+
+type summarizedCamera struct {
+	camera.Camera
+	images *decimator[imagesResult]
+}
+
+func (c *summarizedCamera) Images(ctx context.Context) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+	res, err := c.images.get(func() (imagesResult, error) {
+		imgs, meta, err := c.Camera.Images(ctx)
+		return imagesResult{images: imgs, meta: meta}, err
+	})
+	return res.images, res.meta, err
+}
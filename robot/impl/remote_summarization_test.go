@@ -0,0 +1,51 @@
+package robotimpl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestSummarizeRemoteResource(t *testing.T) {
+	ctx := context.Background()
+
+	calls := 0
+	var readErr error
+	injectSensor := inject.NewSensor("s")
+	injectSensor.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		calls++
+		return map[string]interface{}{"calls": calls}, readErr
+	}
+
+	// Without a readings interval, the resource is passed through untouched.
+	res := summarizeRemoteResource(injectSensor, &config.RemoteSummarization{ImagesInterval: "1s"})
+	test.That(t, res, test.ShouldEqual, injectSensor)
+
+	res = summarizeRemoteResource(injectSensor, &config.RemoteSummarization{ReadingsInterval: "1h"})
+	s, ok := res.(sensor.Sensor)
+	test.That(t, ok, test.ShouldBeTrue)
+
+	readings, err := s.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["calls"], test.ShouldEqual, 1)
+
+	readings, err = s.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["calls"], test.ShouldEqual, 1)
+	test.That(t, calls, test.ShouldEqual, 1)
+
+	// Errors are never cached.
+	readErr = errors.New("whoops")
+	res = summarizeRemoteResource(injectSensor, &config.RemoteSummarization{ReadingsInterval: "1h"})
+	_, err = res.(sensor.Sensor).Readings(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = res.(sensor.Sensor).Readings(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, calls, test.ShouldEqual, 3)
+}
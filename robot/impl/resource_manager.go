@@ -4,14 +4,18 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
 	"os"
 	"reflect"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jhump/protoreflect/desc"
 	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
 	"go.uber.org/multierr"
 	goutils "go.viam.com/utils"
 	"go.viam.com/utils/pexec"
@@ -20,12 +24,14 @@ import (
 
 	"go.viam.com/rdk/config"
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/metrics"
 	"go.viam.com/rdk/module/modmanager"
 	modmanageroptions "go.viam.com/rdk/module/modmanager/options"
 	modif "go.viam.com/rdk/module/modmaninterface"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot"
 	"go.viam.com/rdk/robot/client"
+	"go.viam.com/rdk/robot/mdns"
 	"go.viam.com/rdk/robot/web"
 	"go.viam.com/rdk/services/shell"
 	rutils "go.viam.com/rdk/utils"
@@ -38,7 +44,8 @@ func init() {
 }
 
 var (
-	resourceCloseTimeout    = 30 * time.Second
+	resourceCloseTimeout    = config.DefaultResourceCloseTimeout
+	remoteLANLookupTimeout  = 2 * time.Second
 	errShellServiceDisabled = errors.New("shell service disabled in an untrusted environment")
 	errProcessesDisabled    = errors.New("processes disabled in an untrusted environment")
 )
@@ -55,6 +62,9 @@ type resourceManager struct {
 	// resourceGraphLock manages access to the resource graph and nodes. If either may change, this lock should be taken.
 	resourceGraphLock sync.Mutex
 	viz               resource.Visualizer
+
+	// shutdownConfig is the shutdown config of the latest config, which bounds how long resources may take to close.
+	shutdownConfig atomic.Pointer[config.ShutdownConfig]
 }
 
 type resourceManagerOptions struct {
@@ -63,6 +73,8 @@ type resourceManagerOptions struct {
 	allowInsecureCreds bool
 	untrustedEnv       bool
 	tlsConfig          *tls.Config
+	fleetCA            *config.FleetCAConfig
+	webrtc             *config.WebRTCConfig
 }
 
 // newResourceManager returns a properly initialized set of parts.
@@ -104,6 +116,7 @@ func (manager *resourceManager) startModuleManager(
 	ctx context.Context,
 	parentAddr string,
 	removeOrphanedResources func(context.Context, []resource.Name),
+	setResourcesUnavailable func([]resource.Name, error),
 	untrustedEnv bool,
 	viamHomeDir string,
 	robotCloudID string,
@@ -113,6 +126,7 @@ func (manager *resourceManager) startModuleManager(
 	mmOpts := modmanageroptions.Options{
 		UntrustedEnv:            untrustedEnv,
 		RemoveOrphanedResources: removeOrphanedResources,
+		SetResourcesUnavailable: setResourcesUnavailable,
 		ViamHomeDir:             viamHomeDir,
 		RobotCloudID:            robotCloudID,
 		PackagesDir:             packagesDir,
@@ -189,6 +203,13 @@ func (manager *resourceManager) updateRemoteResourceNames(
 
 	anythingChanged := false
 
+	var summarization *config.RemoteSummarization
+	if remoteNode, ok := manager.resources.Node(remoteName); ok {
+		if remConf, err := resource.NativeConfig[*config.Remote](remoteNode.Config()); err == nil {
+			summarization = remConf.Summarization
+		}
+	}
+
 	for _, resName := range newResources {
 		remoteResName := resName
 		res, err := rr.ResourceByName(remoteResName) // this returns a remote known OR foreign resource client
@@ -204,6 +225,9 @@ func (manager *resourceManager) updateRemoteResourceNames(
 			}
 			continue
 		}
+		if summarization != nil {
+			res = summarizeRemoteResource(res, summarization)
+		}
 
 		resName = resName.PrependRemote(remoteName.Name)
 		gNode, ok := manager.resources.Node(resName)
@@ -433,18 +457,29 @@ func (manager *resourceManager) mergeResourceRPCAPIsWithRemote(r robot.Robot, ty
 func (manager *resourceManager) closeResource(ctx context.Context, res resource.Resource) error {
 	manager.logger.CInfow(ctx, "Now removing resource", "resource", res.Name())
 
-	// TODO(RSDK-6626): We should be resilient to builtin resource `Close` calls
-	// hanging and not respecting the context created below. We will likely need
-	// a goroutine/timer setup here similar to that in the (re)configuration
-	// code.
-	//
-	// Avoid hangs in Close/RemoveResource with resourceCloseTimeout.
-	closeCtx, cancel := context.WithTimeout(ctx, resourceCloseTimeout)
+	// Avoid hangs in Close/RemoveResource with the resource's close timeout. A resource which
+	// does not respect the context is left to finish closing on its own, so that it cannot hold up
+	// the rest.
+	resName := res.Name()
+	closeTimeout := manager.closeTimeout(resName)
+	closeCtx, cancel := context.WithTimeout(ctx, closeTimeout)
 	defer cancel()
 
-	allErrs := res.Close(closeCtx)
+	closed := make(chan error, 1)
+	goutils.PanicCapturingGo(func() {
+		closed <- res.Close(closeCtx)
+	})
+	timer := time.NewTimer(closeTimeout)
+	defer timer.Stop()
+	var allErrs error
+	select {
+	case allErrs = <-closed:
+	case <-timer.C:
+		manager.logger.CErrorw(ctx, "resource did not close in time; moving on without it",
+			"resource", resName, "timeout", closeTimeout)
+		allErrs = errors.Errorf("resource %s did not close within %v", resName, closeTimeout)
+	}
 
-	resName := res.Name()
 	if manager.moduleManager != nil && manager.moduleManager.IsModularResource(resName) {
 		if err := manager.moduleManager.RemoveResource(closeCtx, resName); err != nil {
 			allErrs = multierr.Combine(allErrs, errors.Wrap(err, "error removing modular resource for closure"))
@@ -454,6 +489,52 @@ func (manager *resourceManager) closeResource(ctx context.Context, res resource.
 	return allErrs
 }
 
+// closeTimeout returns how long the named resource may take to close.
+func (manager *resourceManager) closeTimeout(name resource.Name) time.Duration {
+	if conf := manager.shutdownConfig.Load(); conf != nil {
+		return conf.CloseDuration(name)
+	}
+	return resourceCloseTimeout
+}
+
+// preShutdown runs the pre-shutdown hooks of every resource which has one, dependents first, so that
+// resources can act while what they depend on is still running. Each hook is bounded by the pre-shutdown
+// timeout, and a hook which does not respect it is left to finish on its own.
+func (manager *resourceManager) preShutdown(ctx context.Context) error {
+	timeout := manager.shutdownConfig.Load().PreShutdownDuration()
+	var allErrs error
+	for _, name := range manager.resources.TopologicalSort() {
+		gNode, ok := manager.resources.Node(name)
+		if !ok {
+			continue
+		}
+		res, err := gNode.Resource()
+		if err != nil {
+			continue
+		}
+		hook, ok := resource.As[resource.PreShutdowner](res)
+		if !ok {
+			continue
+		}
+		manager.logger.CInfow(ctx, "Preparing resource for shutdown", "resource", name)
+		hookCtx, cancel := context.WithTimeout(ctx, timeout)
+		done := make(chan error, 1)
+		goutils.PanicCapturingGo(func() {
+			done <- hook.PreShutdown(hookCtx)
+		})
+		select {
+		case err = <-done:
+		case <-hookCtx.Done():
+			err = errors.Errorf("did not finish within %v", timeout)
+		}
+		cancel()
+		if err != nil {
+			allErrs = multierr.Combine(allErrs, errors.Wrapf(err, "error preparing %s for shutdown", name))
+		}
+	}
+	return allErrs
+}
+
 // closeAndUnsetResource attempts to close and unset the resource from the graph node. Should only be called within
 // resourceGraphLock.
 func (manager *resourceManager) closeAndUnsetResource(ctx context.Context, gNode *resource.GraphNode) error {
@@ -633,6 +714,7 @@ func (manager *resourceManager) completeConfig(
 					case resName.API.IsComponent(), resName.API.IsService():
 
 						newRes, newlyBuilt, err := manager.processResource(ctxWithTimeout, conf, gNode, lr)
+						metrics.ObserveReconfigure(resName.String(), newlyBuilt, err)
 						if newlyBuilt || err != nil {
 							if err := manager.markChildrenForUpdate(resName); err != nil {
 								manager.logger.CErrorw(ctx,
@@ -836,9 +918,21 @@ func (manager *resourceManager) processRemote(
 	config config.Remote,
 	gNode *resource.GraphNode,
 ) (*client.RobotClient, error) {
-	dialOpts := remoteDialOptions(config, manager.opts)
+	dialOpts, err := remoteDialOptions(config, manager.opts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't connect to robot remote (%s)", config.Address)
+	}
 	manager.logger.CInfow(ctx, "Connecting now to remote", "remote", config.Name)
 	robotClient, err := dialRobotClient(ctx, config, gNode.Logger(), dialOpts...)
+	if err != nil && !errors.Is(err, rpc.ErrInsecureWithCredentials) && config.TLS == nil {
+		lanClient, lanErr := manager.dialRemoteOnLAN(ctx, config, gNode.Logger(), dialOpts)
+		if lanErr == nil {
+			manager.logger.CWarnw(ctx, "Connected to remote on the local network instead of at its address",
+				"remote", config.Name, "address", config.Address, "error", err)
+			return lanClient, nil
+		}
+		manager.logger.CDebugw(ctx, "could not find remote on the local network", "remote", config.Name, "error", lanErr)
+	}
 	if err != nil {
 		if errors.Is(err, rpc.ErrInsecureWithCredentials) {
 			if manager.opts.fromCommand {
@@ -853,6 +947,45 @@ func (manager *resourceManager) processRemote(
 	return robotClient, nil
 }
 
+// dialRemoteOnLAN looks for a remote on the local network by the name in its address, and connects to it there
+// directly. This reaches remotes on the same network when their address cannot be, such as while the cloud signaling
+// server is unavailable.
+func (manager *resourceManager) dialRemoteOnLAN(
+	ctx context.Context,
+	config config.Remote,
+	logger logging.Logger,
+	dialOpts []rpc.DialOption,
+) (*client.RobotClient, error) {
+	host := config.Address
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "" || host == "localhost" || net.ParseIP(host) != nil {
+		return nil, errors.New("remote address has no name to look for")
+	}
+	machine, err := mdns.Lookup(ctx, host, remoteLANLookupTimeout, logger)
+	if err != nil {
+		return nil, err
+	}
+	if !machine.GRPC {
+		return nil, errors.Errorf("remote found at %s does not accept direct connections", machine.Address())
+	}
+
+	lanConfig := config
+	lanConfig.Address = machine.Address()
+	lanDialOpts := append(slices.Clone(dialOpts), rpc.WithForceDirectGRPC())
+	if !config.Insecure {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if manager.opts.tlsConfig != nil {
+			tlsConfig = manager.opts.tlsConfig.Clone()
+		}
+		// the remote's certificate is for its name, not the address it was found at
+		tlsConfig.ServerName = host
+		lanDialOpts = append(lanDialOpts, rpc.WithTLSConfig(tlsConfig))
+	}
+	return dialRobotClient(ctx, lanConfig, logger, lanDialOpts...)
+}
+
 // RemoteByName returns the given remote robot by name, if it exists;
 // returns nil otherwise.
 func (manager *resourceManager) RemoteByName(name string) (internalRemoteRobot, bool) {
@@ -898,6 +1031,9 @@ func (manager *resourceManager) processResource(
 	gNode *resource.GraphNode,
 	lr *localRobot,
 ) (resource.Resource, bool, error) {
+	ctx, span := trace.StartSpan(ctx, "robot::resourceManager::processResource::"+conf.ResourceName().String())
+	defer span.End()
+
 	if gNode.IsUninitialized() {
 		newRes, err := lr.newResource(ctx, gNode, conf)
 		if err != nil {
@@ -933,7 +1069,19 @@ func (manager *resourceManager) processResource(
 		}
 
 		gNode.SetLogLevel(conf.LogConfiguration.Level)
-		err = currentRes.Reconfigure(ctx, deps, conf)
+		changedAttrs, otherChanged, ok := gNode.ConfigChanges()
+		attrRes, attrsReconfigurable := attributeReconfigurable(currentRes, changedAttrs)
+		switch {
+		case ok && !otherChanged && len(changedAttrs) == 0:
+			// only the log configuration changed, which has already been applied
+			manager.logger.CDebugw(ctx, "resource config unchanged; skipping reconfigure", "name", resName)
+			return currentRes, false, nil
+		case ok && !otherChanged && attrsReconfigurable:
+			manager.logger.CDebugw(ctx, "reconfiguring changed attributes in place", "name", resName, "attributes", changedAttrs)
+			err = attrRes.ReconfigureAttributes(ctx, conf, changedAttrs)
+		default:
+			err = currentRes.Reconfigure(ctx, deps, conf)
+		}
 		if err == nil {
 			return currentRes, false, nil
 		}
@@ -969,6 +1117,22 @@ func (manager *resourceManager) processResource(
 	return newRes, true, nil
 }
 
+// attributeReconfigurable returns res as a resource.AttributeReconfigurable if it can apply changes
+// to all of changedAttrs in place.
+func attributeReconfigurable(res resource.Resource, changedAttrs []string) (resource.AttributeReconfigurable, bool) {
+	attrRes, ok := resource.As[resource.AttributeReconfigurable](res)
+	if !ok {
+		return nil, false
+	}
+	reconfigurable := attrRes.ReconfigurableAttributes()
+	for _, attr := range changedAttrs {
+		if !slices.Contains(reconfigurable, attr) {
+			return nil, false
+		}
+	}
+	return attrRes, true
+}
+
 // markResourceForUpdate marks the given resource in the graph to be updated. If it does not exist, a new node
 // is inserted. If it does exist, it's properly marked. Once this is done, all information needed to build/reconfigure
 // will be available when we call completeConfig.
@@ -1295,7 +1459,7 @@ func (manager *resourceManager) createConfig() *config.Config {
 	return conf
 }
 
-func remoteDialOptions(config config.Remote, opts resourceManagerOptions) []rpc.DialOption {
+func remoteDialOptions(config config.Remote, opts resourceManagerOptions) ([]rpc.DialOption, error) {
 	var dialOpts []rpc.DialOption
 	if opts.debug {
 		dialOpts = append(dialOpts, rpc.WithDialDebug())
@@ -1306,7 +1470,16 @@ func remoteDialOptions(config config.Remote, opts resourceManagerOptions) []rpc.
 	if opts.allowInsecureCreds {
 		dialOpts = append(dialOpts, rpc.WithAllowInsecureWithCredentialsDowngrade())
 	}
-	if opts.tlsConfig != nil {
+	if config.TLS != nil {
+		tlsConfig, err := config.TLS.ClientTLSConfig(opts.fleetCA)
+		if err != nil {
+			return nil, err
+		}
+		// mutual TLS only covers direct connections, so do not let the remote be found some other way
+		dialOpts = append(dialOpts,
+			rpc.WithTLSConfig(tlsConfig),
+			rpc.WithDialMulticastDNSOptions(rpc.DialMulticastDNSOptions{Disable: true}))
+	} else if opts.tlsConfig != nil {
 		dialOpts = append(dialOpts, rpc.WithTLSConfig(opts.tlsConfig))
 	}
 	if config.Auth.Credentials != nil {
@@ -1332,8 +1505,9 @@ func remoteDialOptions(config config.Remote, opts resourceManagerOptions) []rpc.
 	}
 
 	if config.Auth.SignalingServerAddress != "" {
+		webrtcConfig := opts.webrtc.Configuration()
 		wrtcOpts := rpc.DialWebRTCOptions{
-			Config:                 &rpc.DefaultWebRTCConfiguration,
+			Config:                 &webrtcConfig,
 			SignalingServerAddress: config.Auth.SignalingServerAddress,
 			SignalingAuthEntity:    config.Auth.SignalingAuthEntity,
 		}
@@ -1349,5 +1523,5 @@ func remoteDialOptions(config config.Remote, opts resourceManagerOptions) []rpc.
 			}))
 		}
 	}
-	return dialOpts
+	return dialOpts, nil
 }
@@ -8,6 +8,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -3187,6 +3188,95 @@ func TestResourceCloseNoHang(t *testing.T) {
 	test.That(t, time.Now().Add(resourceCloseTimeout), test.ShouldHappenOnOrAfter, mf.closeCtxDeadline)
 }
 
+// shutdownFake records when it is prepared for shutdown and closed, and may hang while closing.
+type shutdownFake struct {
+	resource.Named
+	resource.AlwaysRebuild
+	mu      *sync.Mutex
+	events  *[]string
+	release chan struct{}
+}
+
+func (s *shutdownFake) record(event string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	*s.events = append(*s.events, event+" "+s.Name().Name)
+}
+
+func (s *shutdownFake) PreShutdown(ctx context.Context) error {
+	s.record("prepare")
+	return nil
+}
+
+func (s *shutdownFake) Close(ctx context.Context) error {
+	if s.release != nil {
+		<-s.release
+	}
+	s.record("close")
+	return nil
+}
+
+func TestResourceShutdown(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+
+	mockAPI := resource.APINamespaceRDK.WithComponentType("mock")
+	model := resource.DefaultModelFamily.WithModel(utils.RandomAlphaString(5))
+
+	var mu sync.Mutex
+	var events []string
+	release := make(chan struct{})
+	resource.RegisterComponent(mockAPI, model, resource.Registration[resource.Resource, resource.NoNativeConfig]{
+		Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (resource.Resource, error) {
+			res := &shutdownFake{Named: conf.ResourceName().AsNamed(), mu: &mu, events: &events}
+			if conf.Name == "hangs" {
+				res.release = release
+			}
+			return res, nil
+		},
+	})
+	defer func() {
+		resource.Deregister(mockAPI, model)
+	}()
+
+	cfg := &config.Config{
+		Components: []resource.Config{
+			{Name: "arm", Model: model, API: mockAPI},
+			{Name: "hangs", Model: model, API: mockAPI},
+			{Name: "gripper", Model: model, API: mockAPI, DependsOn: []string{"arm"}},
+		},
+		Shutdown: &config.ShutdownConfig{ResourceCloseTimeouts: map[string]string{"hangs": "50ms"}},
+	}
+	r, err := New(context.Background(), cfg, logger, WithViamHomeDir(t.TempDir()))
+	test.That(t, err, test.ShouldBeNil)
+
+	err = r.Close(context.Background())
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "did not close within 50ms")
+
+	mu.Lock()
+	closed := slices.Clone(events)
+	mu.Unlock()
+	// every resource is prepared before any is closed, and dependents are handled before their dependencies
+	test.That(t, closed, test.ShouldHaveLength, 5)
+	test.That(t, closed[:3], test.ShouldContain, "prepare hangs")
+	test.That(t, slices.Index(closed, "prepare gripper"), test.ShouldBeLessThan, slices.Index(closed, "prepare arm"))
+	test.That(t, slices.Index(closed, "close gripper"), test.ShouldBeLessThan, slices.Index(closed, "close arm"))
+	test.That(t, closed, test.ShouldNotContain, "close hangs")
+
+	// the hanging resource still finishes closing on its own
+	close(release)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		mu.Lock()
+		defer mu.Unlock()
+		test.That(tb, events, test.ShouldContain, "close hangs")
+	})
+}
+
 type mockFake struct {
 	resource.Named
 	createdAt        int
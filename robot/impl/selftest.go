@@ -0,0 +1,278 @@
+package robotimpl
+
+import (
+	"context"
+	"math"
+	"time"
+
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/gripper"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+const (
+	// selfTestMotorPower and selfTestMotorTwitch are how hard and for how long motors are powered by their
+	// default self-test, enough to move an encoder without moving the robot far.
+	selfTestMotorPower  = 0.2
+	selfTestMotorTwitch = 250 * time.Millisecond
+	// selfTestStopTimeout bounds stopping a motor after its self-test, which happens even when the self-test
+	// itself has timed out.
+	selfTestStopTimeout = 5 * time.Second
+	// selfTestPositionInterval is how often a movement sensor is polled while waiting for a position fix.
+	selfTestPositionInterval = 500 * time.Millisecond
+)
+
+// errSelfTestActuates is why a self-test which may move its resource was skipped.
+var errSelfTestActuates = errors.New("self-test may move the resource, so it only runs when actuating is allowed")
+
+// resourceSelfTest is the self-test of a resource.
+type resourceSelfTest struct {
+	run func(context.Context) error
+	// actuates is whether the self-test may move the resource, so that it only runs when asked to.
+	actuates bool
+}
+
+// selfTestFor returns the self-test of a resource, which is its own if it is a resource.SelfTester, and
+// otherwise the default one for its API, if there is one. A resource's own self-test may move it.
+func selfTestFor(name resource.Name, res resource.Resource) (resourceSelfTest, bool) {
+	if tester, ok := resource.As[resource.SelfTester](res); ok {
+		return resourceSelfTest{run: tester.SelfTest, actuates: true}, true
+	}
+	switch name.API {
+	case motor.API:
+		if m, ok := res.(motor.Motor); ok {
+			return resourceSelfTest{run: func(ctx context.Context) error { return twitchMotor(ctx, m) }, actuates: true}, true
+		}
+	case gripper.API:
+		if g, ok := res.(gripper.Gripper); ok {
+			return resourceSelfTest{
+				run:      func(ctx context.Context) error { return openAndCloseGripper(ctx, g) },
+				actuates: true,
+			}, true
+		}
+	case movementsensor.API:
+		if ms, ok := res.(movementsensor.MovementSensor); ok {
+			return resourceSelfTest{run: func(ctx context.Context) error { return waitForPositionFix(ctx, ms) }}, true
+		}
+	case camera.API:
+		if cam, ok := res.(camera.Camera); ok {
+			return resourceSelfTest{run: func(ctx context.Context) error { return checkCameraFrame(ctx, cam) }}, true
+		}
+	}
+	return resourceSelfTest{}, false
+}
+
+// twitchMotor briefly powers a motor, and checks that its encoder moved if it has one.
+func twitchMotor(ctx context.Context, m motor.Motor) error {
+	props, err := m.Properties(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to get properties")
+	}
+	var startPosition float64
+	if props.PositionReporting {
+		if startPosition, err = m.Position(ctx, nil); err != nil {
+			return errors.Wrap(err, "failed to get position")
+		}
+	}
+
+	err = errors.Wrap(m.SetPower(ctx, selfTestMotorPower, nil), "failed to set power")
+	if err == nil && !goutils.SelectContextOrWait(ctx, selfTestMotorTwitch) {
+		err = ctx.Err()
+	}
+	// stop with a fresh context so that the motor stops even when the self-test has timed out
+	stopCtx, cancel := context.WithTimeout(context.Background(), selfTestStopTimeout)
+	defer cancel()
+	if err := multierr.Combine(err, errors.Wrap(m.Stop(stopCtx, nil), "failed to stop")); err != nil {
+		return err
+	}
+
+	if !props.PositionReporting {
+		return nil
+	}
+	position, err := m.Position(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to get position")
+	}
+	if position == startPosition {
+		return errors.New("encoder did not move while the motor was powered")
+	}
+	return nil
+}
+
+// openAndCloseGripper opens and closes a gripper, leaving it open.
+func openAndCloseGripper(ctx context.Context, g gripper.Gripper) error {
+	if err := g.Open(ctx, nil); err != nil {
+		return errors.Wrap(err, "failed to open")
+	}
+	if _, err := g.Grab(ctx, nil); err != nil {
+		return errors.Wrap(err, "failed to close")
+	}
+	return errors.Wrap(g.Open(ctx, nil), "failed to open after closing")
+}
+
+// waitForPositionFix waits until a movement sensor which supports position, such as a GPS, has a fix. Other
+// movement sensors need only return readings.
+func waitForPositionFix(ctx context.Context, ms movementsensor.MovementSensor) error {
+	props, err := ms.Properties(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to get properties")
+	}
+	if !props.PositionSupported {
+		_, err := ms.Readings(ctx, nil)
+		return errors.Wrap(err, "failed to get readings")
+	}
+	for {
+		point, _, err := ms.Position(ctx, nil)
+		if err == nil && hasPositionFix(point) {
+			return nil
+		}
+		if !goutils.SelectContextOrWait(ctx, selfTestPositionInterval) {
+			if err != nil {
+				return errors.Wrap(err, "no position fix before timing out")
+			}
+			return errors.New("no position fix before timing out")
+		}
+	}
+}
+
+// hasPositionFix returns whether a position came from a fix, since movement sensors without one report either
+// NaN or 0, 0.
+func hasPositionFix(point *geo.Point) bool {
+	if point == nil || math.IsNaN(point.Lat()) || math.IsNaN(point.Lng()) {
+		return false
+	}
+	return point.Lat() != 0 || point.Lng() != 0
+}
+
+// checkCameraFrame checks that a camera returns a frame which is not empty.
+func checkCameraFrame(ctx context.Context, cam camera.Camera) error {
+	img, release, err := camera.ReadImage(ctx, cam)
+	if err != nil {
+		return errors.Wrap(err, "failed to get frame")
+	}
+	defer release()
+	if img == nil || img.Bounds().Empty() {
+		return errors.New("frame is empty")
+	}
+	return nil
+}
+
+// RunSelfTest runs the self-test of each named local resource, or of every resource the robot's self-test
+// config covers when none are named, one at a time so that no two components move at once. Self-tests which
+// may move their resource are skipped unless actuate is set. Each self-test is bounded by its timeout in the
+// self-test config.
+func (r *localRobot) RunSelfTest(ctx context.Context, actuate bool, names ...resource.Name) (*robot.SelfTestReport, error) {
+	r.selfTestMu.Lock()
+	defer r.selfTestMu.Unlock()
+
+	conf := r.selfTestConfig.Load()
+	report := &robot.SelfTestReport{StartedAt: time.Now()}
+	for _, name := range names {
+		if name.ContainsRemoteNames() {
+			return nil, errors.Errorf("cannot self-test %s on a remote", name)
+		}
+		if _, ok := r.manager.resources.Node(name); !ok {
+			return nil, resource.NewNotFoundError(name)
+		}
+	}
+	named := len(names) != 0
+	if !named {
+		for _, name := range r.manager.resources.Names() {
+			if !(name.API.IsComponent() || name.API.IsService()) || name.ContainsRemoteNames() || !conf.Tests(name) {
+				continue
+			}
+			names = append(names, name)
+		}
+	}
+	// resources listed in the config must pass, even when they could not be built
+	configured := conf != nil && len(conf.Resources) != 0
+
+	for _, name := range names {
+		res, err := r.ResourceByName(name)
+		if err != nil {
+			if named || configured {
+				report.Results = append(report.Results, robot.SelfTestResult{Name: name, Error: err})
+			}
+			continue
+		}
+		selfTest, ok := selfTestFor(name, res)
+		if !ok {
+			if named {
+				report.Results = append(report.Results, robot.SelfTestResult{
+					Name:  name,
+					Error: errors.Errorf("%s has no self-test", name),
+				})
+			}
+			continue
+		}
+		if selfTest.actuates && !actuate {
+			report.Results = append(report.Results, robot.SelfTestResult{Name: name, Skipped: true, Error: errSelfTestActuates})
+			continue
+		}
+		report.Results = append(report.Results, r.runResourceSelfTest(ctx, name, selfTest.run, conf.TimeoutFor(name)))
+	}
+	r.lastSelfTestReport.Store(report)
+	return report, nil
+}
+
+// runResourceSelfTest runs a resource's self-test, bounded by timeout. A self-test which does not respect its
+// timeout is left to finish on its own.
+func (r *localRobot) runResourceSelfTest(
+	ctx context.Context,
+	name resource.Name,
+	selfTest func(context.Context) error,
+	timeout time.Duration,
+) robot.SelfTestResult {
+	r.logger.CInfow(ctx, "Running self-test", "resource", name)
+	start := time.Now()
+	testCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	goutils.PanicCapturingGo(func() {
+		done <- selfTest(testCtx)
+	})
+	var err error
+	select {
+	case err = <-done:
+	case <-testCtx.Done():
+		err = errors.Errorf("did not finish within %v", timeout)
+	}
+	result := robot.SelfTestResult{Name: name, Error: err, Duration: time.Since(start)}
+	if err != nil {
+		r.logger.CWarnw(ctx, "Self-test failed", "resource", name, "error", err)
+	}
+	return result
+}
+
+// LastSelfTestReport returns the report of the most recent self-test run, or nil if none has run.
+func (r *localRobot) LastSelfTestReport() *robot.SelfTestReport {
+	return r.lastSelfTestReport.Load()
+}
+
+// runStartupSelfTest runs the self-tests once the robot has first been configured, logging the outcome.
+func (r *localRobot) runStartupSelfTest(ctx context.Context) {
+	report, err := r.RunSelfTest(ctx, r.selfTestConfig.Load().Actuates())
+	if err != nil {
+		r.logger.CErrorw(ctx, "failed to run startup self-test", "error", err)
+		return
+	}
+	if !report.Passed() {
+		var failed []string
+		for _, result := range report.Results {
+			if result.Error != nil && !result.Skipped {
+				failed = append(failed, result.Name.String())
+			}
+		}
+		r.logger.CErrorw(ctx, "startup self-test failed", "failed", failed)
+		return
+	}
+	r.logger.CInfow(ctx, "startup self-test passed", "resources", len(report.Results))
+}
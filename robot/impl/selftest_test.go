@@ -0,0 +1,160 @@
+package robotimpl
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/gripper"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/utils"
+)
+
+func TestTwitchMotor(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var power, position float64
+	var moves bool
+	m := inject.NewMotor("motor1")
+	m.PropertiesFunc = func(ctx context.Context, extra map[string]interface{}) (motor.Properties, error) {
+		return motor.Properties{PositionReporting: true}, nil
+	}
+	m.SetPowerFunc = func(ctx context.Context, powerPct float64, extra map[string]interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		power = powerPct
+		if moves {
+			position++
+		}
+		return nil
+	}
+	m.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		power = 0
+		return nil
+	}
+	m.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (float64, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return position, nil
+	}
+
+	moves = true
+	test.That(t, twitchMotor(ctx, m), test.ShouldBeNil)
+	test.That(t, power, test.ShouldEqual, 0)
+
+	// a motor whose encoder does not move fails, and is still stopped
+	moves = false
+	err := twitchMotor(ctx, m)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "encoder did not move")
+	test.That(t, power, test.ShouldEqual, 0)
+
+	// a motor is stopped even when it fails to power
+	m.SetPowerFunc = func(ctx context.Context, powerPct float64, extra map[string]interface{}) error {
+		power = powerPct
+		return errors.New("no power")
+	}
+	err = twitchMotor(ctx, m)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no power")
+	test.That(t, power, test.ShouldEqual, 0)
+}
+
+func TestHasPositionFix(t *testing.T) {
+	test.That(t, hasPositionFix(geo.NewPoint(40.7, -74)), test.ShouldBeTrue)
+	test.That(t, hasPositionFix(geo.NewPoint(0, 0)), test.ShouldBeFalse)
+	test.That(t, hasPositionFix(geo.NewPoint(math.NaN(), math.NaN())), test.ShouldBeFalse)
+	test.That(t, hasPositionFix(nil), test.ShouldBeFalse)
+}
+
+func TestRunSelfTest(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var gripperMoves []string
+	model := resource.DefaultModelFamily.WithModel(utils.RandomAlphaString(8))
+	resource.RegisterComponent(
+		gripper.API,
+		model,
+		resource.Registration[gripper.Gripper, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (gripper.Gripper, error) {
+			g := inject.NewGripper(conf.Name)
+			g.OpenFunc = func(ctx context.Context, extra map[string]interface{}) error {
+				mu.Lock()
+				defer mu.Unlock()
+				gripperMoves = append(gripperMoves, "open")
+				return nil
+			}
+			g.GrabFunc = func(ctx context.Context, extra map[string]interface{}) (bool, error) {
+				if conf.Name == "broken" {
+					return false, errors.New("jammed")
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				gripperMoves = append(gripperMoves, "grab")
+				return false, nil
+			}
+			return g, nil
+		}})
+	defer func() {
+		resource.Deregister(gripper.API, model)
+	}()
+
+	cfg, err := config.FromReader(ctx, "", strings.NewReader(fmt.Sprintf(`{
+		"components": [
+			{"model": "%[1]s", "name": "claw", "type": "gripper"},
+			{"model": "%[1]s", "name": "broken", "type": "gripper"}
+		],
+		"self_test": {"resources": [{"name": "claw"}]}
+	}`, model.String())), logger)
+	test.That(t, err, test.ShouldBeNil)
+	lr := setupLocalRobot(t, ctx, cfg, logger)
+	test.That(t, lr.LastSelfTestReport(), test.ShouldBeNil)
+
+	// self-tests which move resources are skipped unless actuating is allowed
+	report, err := lr.RunSelfTest(ctx, false)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, report.Passed(), test.ShouldBeTrue)
+	test.That(t, report.Results, test.ShouldHaveLength, 1)
+	test.That(t, report.Results[0].Skipped, test.ShouldBeTrue)
+	test.That(t, gripperMoves, test.ShouldBeEmpty)
+
+	// only the resources in the config are tested by default
+	report, err = lr.RunSelfTest(ctx, true)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, report.Passed(), test.ShouldBeTrue)
+	test.That(t, report.Results, test.ShouldHaveLength, 1)
+	test.That(t, report.Results[0].Name, test.ShouldResemble, gripper.Named("claw"))
+	test.That(t, gripperMoves, test.ShouldResemble, []string{"open", "grab", "open"})
+	test.That(t, lr.LastSelfTestReport(), test.ShouldEqual, report)
+
+	report, err = lr.RunSelfTest(ctx, true, gripper.Named("claw"), gripper.Named("broken"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, report.Passed(), test.ShouldBeFalse)
+	test.That(t, report.Results, test.ShouldHaveLength, 2)
+	test.That(t, report.Results[0].Error, test.ShouldBeNil)
+	test.That(t, report.Results[1].Error.Error(), test.ShouldContainSubstring, "jammed")
+
+	_, err = lr.RunSelfTest(ctx, true, gripper.Named("missing"))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, resource.IsNotFoundError(err), test.ShouldBeTrue)
+}
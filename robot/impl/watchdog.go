@@ -0,0 +1,216 @@
+package robotimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/metrics"
+	"go.viam.com/rdk/resource"
+)
+
+const (
+	// watchdogInterval is how often the watchdog checks on components.
+	watchdogInterval = 5 * time.Second
+	// watchdogFailureThreshold is how many operations on a component must fail in a row for it to be rebuilt.
+	watchdogFailureThreshold = 5
+	// watchdogCheckTimeout bounds how long a component may take to check its own health.
+	watchdogCheckTimeout = 5 * time.Second
+	// watchdogMinBackoff is how long after being rebuilt a component must wait to be rebuilt again, which doubles
+	// with each further rebuild up to watchdogMaxBackoff.
+	watchdogMinBackoff = 10 * time.Second
+	watchdogMaxBackoff = 5 * time.Minute
+	// watchdogResetAfter is how long after its last rebuild a component's backoff starts over.
+	watchdogResetAfter = 10 * time.Minute
+	// watchdogProbeInterval is how often a component which can't check its own health is probed, when none of
+	// its operations have succeeded since, so that its failures are counted even when no client is calling it.
+	watchdogProbeInterval = 30 * time.Second
+)
+
+// restartState tracks how often the watchdog has rebuilt a component.
+type restartState struct {
+	restarts    int
+	lastRestart time.Time
+}
+
+// watchdog rebuilds local components which report that they have stopped working, or whose operations keep
+// failing. It backs off between rebuilds of the same component, so that one which rebuilding can't fix isn't
+// rebuilt over and over.
+type watchdog struct {
+	lr       *localRobot
+	restarts map[resource.Name]*restartState
+	// probed is when each component was last probed.
+	probed map[resource.Name]time.Time
+	logger logging.Logger
+}
+
+func newWatchdog(lr *localRobot) *watchdog {
+	return &watchdog{
+		lr:       lr,
+		restarts: map[resource.Name]*restartState{},
+		probed:   map[resource.Name]time.Time{},
+		logger:   lr.logger.Sublogger("watchdog"),
+	}
+}
+
+// watchdogBackoff returns how long to wait after a component's nth rebuild before rebuilding it again.
+func watchdogBackoff(restarts int) time.Duration {
+	backoff := watchdogMinBackoff
+	for i := 1; i < restarts && backoff < watchdogMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > watchdogMaxBackoff {
+		return watchdogMaxBackoff
+	}
+	return backoff
+}
+
+// check rebuilds any components which have failed and aren't backing off, returning whether it rebuilt any. The
+// rebuilt components are closed and marked as needing to be built, which the next reconfiguration does.
+func (wd *watchdog) check(ctx context.Context, now time.Time) bool {
+	restarted := false
+	seen := map[resource.Name]bool{}
+	for _, name := range wd.lr.manager.resources.Names() {
+		if !name.API.IsComponent() || name.ContainsRemoteNames() {
+			continue
+		}
+		gNode, ok := wd.lr.manager.resources.Node(name)
+		if !ok {
+			continue
+		}
+		seen[name] = true
+
+		state := wd.restarts[name]
+		if state != nil && now.Sub(state.lastRestart) >= watchdogResetAfter {
+			delete(wd.restarts, name)
+			state = nil
+		}
+		reason := wd.failure(ctx, name, gNode, now)
+		if reason == nil {
+			continue
+		}
+		if state != nil && now.Before(state.lastRestart.Add(watchdogBackoff(state.restarts))) {
+			continue
+		}
+		if state == nil {
+			state = &restartState{}
+			wd.restarts[name] = state
+		}
+		state.restarts++
+		state.lastRestart = now
+		wd.restart(ctx, name, gNode, reason, state.restarts)
+		restarted = true
+	}
+	for name := range wd.restarts {
+		if !seen[name] {
+			delete(wd.restarts, name)
+		}
+	}
+	for name := range wd.probed {
+		if !seen[name] {
+			delete(wd.probed, name)
+		}
+	}
+	return restarted
+}
+
+// failure returns why the component in the node has stopped working, or nil if it is working or is already being
+// dealt with by reconfiguration.
+func (wd *watchdog) failure(ctx context.Context, name resource.Name, gNode *resource.GraphNode, now time.Time) error {
+	status := gNode.Status()
+	if !status.Healthy() {
+		return nil
+	}
+	// resources given to the robot rather than built from its config can't be rebuilt
+	if gNode.Config().Model == (resource.Model{}) {
+		return nil
+	}
+	res, err := gNode.Resource()
+	if err != nil {
+		return nil
+	}
+	checker, ok := resource.As[resource.HealthChecker](res)
+	if !ok {
+		if now.Sub(status.LastSucceededAt) >= watchdogProbeInterval && now.Sub(wd.probed[name]) >= watchdogProbeInterval {
+			wd.probed[name] = now
+			wd.probe(ctx, gNode, res)
+			status = gNode.Status()
+		}
+		if status.ConsecutiveFailures >= watchdogFailureThreshold {
+			return errors.Errorf("%d operations in a row failed", status.ConsecutiveFailures)
+		}
+		return nil
+	}
+	if status.ConsecutiveFailures >= watchdogFailureThreshold {
+		return errors.Errorf("%d operations in a row failed", status.ConsecutiveFailures)
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, watchdogCheckTimeout)
+	defer cancel()
+	if err := checker.CheckHealth(checkCtx); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// probe reads the state of a component which can't check its own health, recording whether the read worked the
+// same way operations from clients are. Actuators are asked whether they are moving and sensors for readings,
+// neither of which changes anything. Other components aren't probed.
+func (wd *watchdog) probe(ctx context.Context, gNode *resource.GraphNode, res resource.Resource) {
+	probeCtx, cancel := context.WithTimeout(ctx, watchdogCheckTimeout)
+	defer cancel()
+	var err error
+	if actuator, ok := resource.As[resource.Actuator](res); ok {
+		_, err = actuator.IsMoving(probeCtx)
+	} else if sensor, ok := resource.As[resource.Sensor](res); ok {
+		_, err = sensor.Readings(probeCtx, nil)
+	} else {
+		return
+	}
+	switch {
+	case ctx.Err() != nil, errors.Is(err, resource.ErrDoUnimplemented), status.Code(err) == codes.Unimplemented:
+	case err != nil:
+		gNode.MarkOperationFailed()
+	default:
+		gNode.MarkOperationSucceeded()
+	}
+}
+
+// restart closes the failed component and marks it, and everything depending on it, as needing to be built again.
+func (wd *watchdog) restart(
+	ctx context.Context,
+	name resource.Name,
+	gNode *resource.GraphNode,
+	reason error,
+	restarts int,
+) {
+	metrics.ObserveWatchdogRestart(name.String())
+
+	manager := wd.lr.manager
+	manager.resourceGraphLock.Lock()
+	res, err := gNode.Resource()
+	gNode.UnsetResource()
+	gNode.LogAndSetLastError(
+		errors.Wrap(reason, "component failed and will be rebuilt"),
+		"resource", name,
+		"restarts", restarts,
+		"next_restart_after", watchdogBackoff(restarts),
+	)
+	gNode.SetNeedsUpdate()
+	if err := manager.markChildrenForUpdate(name); err != nil {
+		wd.logger.CWarnw(ctx, "failed to mark dependents of failed component for update", "resource", name, "error", err)
+	}
+	manager.resourceGraphLock.Unlock()
+
+	// the component is already out of the graph, so a slow close holds up only the watchdog rather than the robot.
+	// It is rebuilt by the reconfiguration the watchdog triggers once the close is done.
+	if err != nil {
+		return
+	}
+	if err := manager.closeResource(ctx, res); err != nil {
+		wd.logger.CWarnw(ctx, "failed to close failed component before rebuilding it", "resource", name, "error", err)
+	}
+}
@@ -0,0 +1,204 @@
+package robotimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/generic"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils"
+	"go.viam.com/rdk/utils"
+)
+
+// checkedResource is a resource which reports whatever health its test tells it to.
+type checkedResource struct {
+	resource.Resource
+	health func() error
+}
+
+func (c *checkedResource) CheckHealth(ctx context.Context) error {
+	return c.health()
+}
+
+func TestWatchdogBackoff(t *testing.T) {
+	test.That(t, watchdogBackoff(1), test.ShouldEqual, watchdogMinBackoff)
+	test.That(t, watchdogBackoff(2), test.ShouldEqual, 2*watchdogMinBackoff)
+	test.That(t, watchdogBackoff(3), test.ShouldEqual, 4*watchdogMinBackoff)
+	test.That(t, watchdogBackoff(100), test.ShouldEqual, watchdogMaxBackoff)
+}
+
+func TestWatchdog(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	builds := 0
+	var healthErr error
+	health := func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		return healthErr
+	}
+	setHealth := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		healthErr = err
+	}
+
+	model := resource.DefaultModelFamily.WithModel(utils.RandomAlphaString(8))
+	resource.RegisterComponent(
+		generic.API,
+		model,
+		resource.Registration[resource.Resource, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (resource.Resource, error) {
+			mu.Lock()
+			builds++
+			mu.Unlock()
+			return &checkedResource{Resource: testutils.NewUnimplementedResource(conf.ResourceName()), health: health}, nil
+		}})
+	defer func() {
+		resource.Deregister(generic.API, model)
+	}()
+
+	cfg, err := config.FromReader(ctx, "", strings.NewReader(fmt.Sprintf(`{
+		"components": [{"model": "%s", "name": "thing", "type": "generic"}]
+	}`, model.String())), logger)
+	test.That(t, err, test.ShouldBeNil)
+	lr := setupLocalRobot(t, ctx, cfg, logger).(*localRobot)
+	name := generic.Named("thing")
+	gNode, ok := lr.manager.resources.Node(name)
+	test.That(t, ok, test.ShouldBeTrue)
+	buildCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return builds
+	}
+	rebuild := func() {
+		lr.manager.completeConfig(ctx, lr, true)
+		test.That(t, gNode.Status().Healthy(), test.ShouldBeTrue)
+	}
+
+	wd := newWatchdog(lr)
+	start := time.Now()
+	test.That(t, wd.check(ctx, start), test.ShouldBeFalse)
+	test.That(t, buildCount(), test.ShouldEqual, 1)
+
+	// a component reporting that it stopped working is rebuilt
+	setHealth(errors.New("worker exited"))
+	test.That(t, wd.check(ctx, start), test.ShouldBeTrue)
+	status := gNode.Status()
+	test.That(t, status.Healthy(), test.ShouldBeFalse)
+	test.That(t, status.Error.Error(), test.ShouldContainSubstring, "worker exited")
+	rebuild()
+	test.That(t, buildCount(), test.ShouldEqual, 2)
+
+	// but not again until it has backed off
+	test.That(t, wd.check(ctx, start.Add(watchdogMinBackoff/2)), test.ShouldBeFalse)
+	test.That(t, wd.check(ctx, start.Add(watchdogMinBackoff)), test.ShouldBeTrue)
+	rebuild()
+	test.That(t, wd.check(ctx, start.Add(2*watchdogMinBackoff)), test.ShouldBeFalse)
+	test.That(t, wd.check(ctx, start.Add(3*watchdogMinBackoff)), test.ShouldBeTrue)
+	rebuild()
+	test.That(t, buildCount(), test.ShouldEqual, 4)
+
+	// a component whose operations keep failing is rebuilt, once its backoff has started over
+	setHealth(nil)
+	later := start.Add(3*watchdogMinBackoff + watchdogResetAfter)
+	for i := 0; i < watchdogFailureThreshold-1; i++ {
+		lr.MarkResourceOperationFailed(name)
+	}
+	test.That(t, wd.check(ctx, later), test.ShouldBeFalse)
+	lr.MarkResourceOperationFailed(name)
+	test.That(t, gNode.Status().ConsecutiveFailures, test.ShouldEqual, watchdogFailureThreshold)
+	test.That(t, wd.check(ctx, later), test.ShouldBeTrue)
+	rebuild()
+	test.That(t, buildCount(), test.ShouldEqual, 5)
+	test.That(t, gNode.Status().ConsecutiveFailures, test.ShouldEqual, 0)
+}
+
+// probedResource is a sensor whose readings fail whenever its test tells them to.
+type probedResource struct {
+	resource.Resource
+	readings func() error
+}
+
+func (p *probedResource) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return map[string]interface{}{}, p.readings()
+}
+
+func TestWatchdogProbe(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var readingsErr error
+	readings := func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		return readingsErr
+	}
+
+	model := resource.DefaultModelFamily.WithModel(utils.RandomAlphaString(8))
+	resource.RegisterComponent(
+		generic.API,
+		model,
+		resource.Registration[resource.Resource, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (resource.Resource, error) {
+			return &probedResource{Resource: testutils.NewUnimplementedResource(conf.ResourceName()), readings: readings}, nil
+		}})
+	defer func() {
+		resource.Deregister(generic.API, model)
+	}()
+
+	cfg, err := config.FromReader(ctx, "", strings.NewReader(fmt.Sprintf(`{
+		"components": [{"model": "%s", "name": "sensor", "type": "generic"}]
+	}`, model.String())), logger)
+	test.That(t, err, test.ShouldBeNil)
+	lr := setupLocalRobot(t, ctx, cfg, logger).(*localRobot)
+	name := generic.Named("sensor")
+	gNode, ok := lr.manager.resources.Node(name)
+	test.That(t, ok, test.ShouldBeTrue)
+
+	wd := newWatchdog(lr)
+	start := time.Now()
+	test.That(t, wd.check(ctx, start), test.ShouldBeFalse)
+	test.That(t, gNode.Status().ConsecutiveFailures, test.ShouldEqual, 0)
+	test.That(t, gNode.Status().LastSucceededAt.IsZero(), test.ShouldBeFalse)
+
+	// a component which has worked recently isn't probed
+	mu.Lock()
+	readingsErr = errors.New("bus disconnected")
+	mu.Unlock()
+	test.That(t, wd.check(ctx, start.Add(watchdogProbeInterval/2)), test.ShouldBeFalse)
+	test.That(t, gNode.Status().ConsecutiveFailures, test.ShouldEqual, 0)
+
+	// but an idle one's failures are found by probing it, at most once an interval
+	idle := start.Add(time.Second)
+	for i := 1; i < watchdogFailureThreshold; i++ {
+		now := idle.Add(time.Duration(i) * watchdogProbeInterval)
+		test.That(t, wd.check(ctx, now), test.ShouldBeFalse)
+		test.That(t, wd.check(ctx, now), test.ShouldBeFalse)
+	}
+	test.That(t, gNode.Status().ConsecutiveFailures, test.ShouldEqual, watchdogFailureThreshold-1)
+
+	// and it is rebuilt once they reach the threshold
+	test.That(t, wd.check(ctx, idle.Add(watchdogFailureThreshold*watchdogProbeInterval)), test.ShouldBeTrue)
+	test.That(t, gNode.Status().Healthy(), test.ShouldBeFalse)
+}
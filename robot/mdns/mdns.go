@@ -0,0 +1,175 @@
+// Package mdns finds machines on the local network through the multicast DNS records which viam-server advertises
+// for each of its instance names, such as its fully qualified domain name, unless started with -disable-mdns.
+package mdns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/edaniels/zeroconf"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+)
+
+// ServiceType is the service which viam-server advertises itself as.
+const ServiceType = "_rpc._tcp"
+
+const domain = "local."
+
+// Machine is a machine found on the local network.
+type Machine struct {
+	// Names are the instance names the machine advertises.
+	Names     []string
+	Host      string
+	Addresses []net.IP
+	Port      int
+	// GRPC and WebRTC are whether the machine can be connected to directly over gRPC, or over WebRTC with it
+	// signaling for itself.
+	GRPC   bool
+	WebRTC bool
+}
+
+// Address returns the address to connect to the machine at on the local network.
+func (m Machine) Address() string {
+	if len(m.Addresses) == 0 {
+		return ""
+	}
+	return net.JoinHostPort(m.Addresses[0].String(), fmt.Sprint(m.Port))
+}
+
+// Discover listens for machines on the local network for as long as timeout, and returns the ones which answered,
+// sorted by name.
+func Discover(ctx context.Context, timeout time.Duration, logger logging.Logger) ([]Machine, error) {
+	resolver, err := zeroconf.NewResolver(logger.AsZap(), zeroconf.SelectIPRecordType(zeroconf.IPv4))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to start mDNS resolver")
+	}
+	defer resolver.Shutdown()
+
+	browseCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	entries := make(chan *zeroconf.ServiceEntry)
+	if err := resolver.Browse(browseCtx, ServiceType, domain, entries); err != nil {
+		return nil, errors.Wrap(err, "failed to browse for machines")
+	}
+
+	// a machine advertises itself once for each of its names, so group them by where they point
+	byAddress := map[string]*Machine{}
+	var addresses []string
+	// entries is closed once browseCtx is done
+	for entry := range entries {
+		machine, ok := machineFromEntry(entry)
+		if !ok {
+			continue
+		}
+		if found, ok := byAddress[machine.Address()]; ok {
+			found.Names = append(found.Names, machine.Names...)
+			continue
+		}
+		byAddress[machine.Address()] = &machine
+		addresses = append(addresses, machine.Address())
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	machines := make([]Machine, 0, len(addresses))
+	for _, address := range addresses {
+		machine := byAddress[address]
+		machine.Names = withoutDashedNames(machine.Names)
+		machines = append(machines, *machine)
+	}
+	sort.Slice(machines, func(i, j int) bool {
+		return machines[i].Names[0] < machines[j].Names[0]
+	})
+	return machines, nil
+}
+
+// Lookup finds the machine advertising the given instance name on the local network, waiting for it for as long as
+// timeout.
+func Lookup(ctx context.Context, name string, timeout time.Duration, logger logging.Logger) (*Machine, error) {
+	resolver, err := zeroconf.NewResolver(logger.AsZap(), zeroconf.SelectIPRecordType(zeroconf.IPv4))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to start mDNS resolver")
+	}
+	defer resolver.Shutdown()
+
+	// names with dots are also advertised with dashes, in case something along the way does not allow them
+	candidates := []string{name, strings.ReplaceAll(name, ".", "-")}
+	for _, candidate := range candidates {
+		if machine, ok := lookupCandidate(ctx, resolver, candidate, timeout/time.Duration(len(candidates))); ok {
+			machine.Names = []string{name}
+			return &machine, nil
+		}
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return nil, errors.Errorf("no machine named %q found on the local network", name)
+}
+
+// lookupCandidate waits for as long as timeout for a connectable machine advertising the candidate name.
+func lookupCandidate(ctx context.Context, resolver *zeroconf.Resolver, candidate string, timeout time.Duration) (Machine, bool) {
+	lookupCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	entries := make(chan *zeroconf.ServiceEntry)
+	if err := resolver.Lookup(lookupCtx, candidate, ServiceType, domain, entries); err != nil {
+		return Machine{}, false
+	}
+	// entries is closed once lookupCtx is done
+	for entry := range entries {
+		if machine, ok := machineFromEntry(entry); ok {
+			return machine, true
+		}
+	}
+	return Machine{}, false
+}
+
+// machineFromEntry returns the machine of an entry, if it can be connected to.
+func machineFromEntry(entry *zeroconf.ServiceEntry) (Machine, bool) {
+	if entry == nil || len(entry.AddrIPv4) == 0 {
+		return Machine{}, false
+	}
+	machine := Machine{
+		Names:     []string{entry.Instance},
+		Host:      strings.TrimSuffix(entry.HostName, "."),
+		Addresses: entry.AddrIPv4,
+		Port:      entry.Port,
+	}
+	for _, field := range entry.Text {
+		// the services a machine supports are advertised as TXT fields such as grpc
+		switch strings.SplitN(field, "=", 2)[0] {
+		case "grpc":
+			machine.GRPC = true
+		case "webrtc":
+			machine.WebRTC = true
+		}
+	}
+	return machine, machine.GRPC || machine.WebRTC
+}
+
+// withoutDashedNames removes the names which are only another name with its dots replaced by dashes, sorting the rest.
+func withoutDashedNames(names []string) []string {
+	dashed := map[string]bool{}
+	for _, name := range names {
+		if strings.Contains(name, ".") {
+			dashed[strings.ReplaceAll(name, ".", "-")] = true
+		}
+	}
+	var kept []string
+	seen := map[string]bool{}
+	for _, name := range names {
+		if dashed[name] || seen[name] {
+			continue
+		}
+		seen[name] = true
+		kept = append(kept, name)
+	}
+	sort.Strings(kept)
+	return kept
+}
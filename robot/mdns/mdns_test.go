@@ -0,0 +1,44 @@
+package mdns
+
+import (
+	"net"
+	"testing"
+
+	"github.com/edaniels/zeroconf"
+	"go.viam.com/test"
+)
+
+func TestMachineFromEntry(t *testing.T) {
+	entry := zeroconf.NewServiceEntry("robot.local", ServiceType, domain)
+	entry.HostName = "robot-host.local."
+	entry.Port = 8080
+	entry.AddrIPv4 = []net.IP{net.IPv4(192, 168, 1, 10)}
+	entry.Text = []string{"grpc", "webrtc"}
+
+	machine, ok := machineFromEntry(entry)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, machine.Names, test.ShouldResemble, []string{"robot.local"})
+	test.That(t, machine.Host, test.ShouldEqual, "robot-host.local")
+	test.That(t, machine.Address(), test.ShouldEqual, "192.168.1.10:8080")
+	test.That(t, machine.GRPC, test.ShouldBeTrue)
+	test.That(t, machine.WebRTC, test.ShouldBeTrue)
+
+	// services which are not viam-server, or which cannot be reached over IPv4, are not machines
+	entry.Text = []string{"path=/"}
+	_, ok = machineFromEntry(entry)
+	test.That(t, ok, test.ShouldBeFalse)
+	entry.Text = []string{"grpc"}
+	entry.AddrIPv4 = nil
+	_, ok = machineFromEntry(entry)
+	test.That(t, ok, test.ShouldBeFalse)
+	_, ok = machineFromEntry(nil)
+	test.That(t, ok, test.ShouldBeFalse)
+}
+
+func TestWithoutDashedNames(t *testing.T) {
+	test.That(t,
+		withoutDashedNames([]string{"robot-local", "robot.viam.cloud", "robot.local", "robot-viam-cloud", "robot.local"}),
+		test.ShouldResemble,
+		[]string{"robot.local", "robot.viam.cloud"})
+	test.That(t, withoutDashedNames([]string{"robot-local"}), test.ShouldResemble, []string{"robot-local"})
+}
@@ -220,6 +220,10 @@ func (m *cloudManager) validateAndGetChangedPackages(
 	changed := make([]config.PackageConfig, 0)
 	existing := make([]config.PackageConfig, 0)
 	for _, p := range packages {
+		// packages with a URL are downloaded by the local manager
+		if p.URL != "" {
+			continue
+		}
 		// don't consider invalid config as synced or unsynced
 		if err := p.Validate(""); err != nil {
 			m.logger.Errorw("package config validation error; skipping", "package", p.Name, "error", err)
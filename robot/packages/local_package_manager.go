@@ -2,9 +2,14 @@ package packages
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"io"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -23,7 +28,8 @@ var (
 	_ ManagerSyncer = (*localManager)(nil)
 )
 
-// localManager manages IO for local modules that require setup.
+// localManager manages IO for local modules that require setup, and for packages that are downloaded from a URL
+// rather than from the cloud.
 type localManager struct {
 	resource.Named
 	resource.TriviallyReconfigurable
@@ -31,10 +37,13 @@ type localManager struct {
 	// packagesDir is the parent dir for unpacked package tars.
 	packagesDir     string
 	packagesDataDir string
+	httpClient      http.Client
 
 	// managedModules tracks the modules this manager knows about.
 	managedModules managedModuleMap
-	mu             sync.RWMutex
+	// managedPackages tracks the packages with a URL this manager knows about.
+	managedPackages map[PackageName]*config.PackageConfig
+	mu              sync.RWMutex
 
 	logger logging.Logger
 }
@@ -62,6 +71,7 @@ func NewLocalManager(conf *config.Config, logger logging.Logger) (ManagerSyncer,
 		managedModules:  make(managedModuleMap),
 		packagesDir:     packagesDir,
 		packagesDataDir: packagesDataDir,
+		httpClient:      http.Client{Timeout: time.Minute * 30},
 		logger:          logger,
 	}, nil
 }
@@ -69,16 +79,23 @@ func NewLocalManager(conf *config.Config, logger logging.Logger) (ManagerSyncer,
 // LocalPackagesDir transforms a packagesDir string to the suffixed version for localManager.
 // local + cloud manager need separate parent dirs so they don't delete each other in Cleanup.
 func LocalPackagesDir(packagesDir string) string {
-	return filepath.Clean(packagesDir) + "-local"
+	return config.LocalPackagesDir(packagesDir)
 }
 
-// PackagePath returns the package if it exists and already downloaded. If it does not exist it returns a ErrPackageMissing error.
+// PackagePath returns the directory of a package with a URL once it is downloaded. For any other package it returns the
+// name of the package.
 func (m *localManager) PackagePath(name PackageName) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if p, ok := m.managedPackages[name]; ok {
+		return p.LocalDataDirectory(m.packagesDir), nil
+	}
 	return string(name), nil
 }
 
 // Close manager.
 func (m *localManager) Close(ctx context.Context) error {
+	m.httpClient.CloseIdleConnections()
 	return nil
 }
 
@@ -145,7 +162,7 @@ func (m managedModuleMap) getAddedAndChanged(
 	)
 }
 
-// Sync for the localManager manages copying of local tarballs.
+// Sync for the localManager manages copying of local tarballs and downloading of packages with a URL.
 func (m *localManager) Sync(ctx context.Context, packages []config.PackageConfig, modules []config.Module) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -193,9 +210,112 @@ func (m *localManager) Sync(ctx context.Context, packages []config.PackageConfig
 	// swap for new managed packages.
 	m.managedModules = existing
 
+	packages = rUtils.FilterSlice(packages, func(p config.PackageConfig) bool { return p.URL != "" })
+	return multierr.Append(outErr, m.syncURLPackages(ctx, packages))
+}
+
+// syncURLPackages downloads the packages with a URL that are new or changed, and verifies their checksums.
+func (m *localManager) syncURLPackages(ctx context.Context, packages []config.PackageConfig) error {
+	var outErr error
+	newManagedPackages := make(map[PackageName]*config.PackageConfig, len(packages))
+	for _, p := range packages {
+		p := p
+		if err := p.Validate(""); err != nil {
+			m.logger.Errorw("package config validation error; skipping", "package", p.Name, "error", err)
+			continue
+		}
+		if urlPackageIsSynced(p, m.packagesDir, m.logger) {
+			newManagedPackages[PackageName(p.Name)] = &p
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			m.managedPackages = newManagedPackages
+			return multierr.Append(outErr, err)
+		}
+
+		pkgStart := time.Now()
+		m.logger.Infof("Downloading package %s:%s from %s", p.Package, p.Version, sanitizeURLForLogs(p.URL))
+		if err := installPackage(ctx, m.logger, m.packagesDir, p.URL, p, m.downloadURLPackage(p)); err != nil {
+			m.logger.Errorf("Failed downloading package %s:%s from %s, %s", p.Package, p.Version, sanitizeURLForLogs(p.URL), err)
+			outErr = multierr.Append(outErr, errors.Wrapf(err, "failed downloading package %s:%s from %s",
+				p.Package, p.Version, sanitizeURLForLogs(p.URL)))
+			continue
+		}
+		newManagedPackages[PackageName(p.Name)] = &p
+		m.logger.Debugf("Package download complete %s:%s after %v", p.Package, p.Version, time.Since(pkgStart))
+	}
+	m.managedPackages = newManagedPackages
 	return outErr
 }
 
+// urlPackageIsSynced returns whether a package with a URL has been downloaded with the checksum it is configured with, so
+// that changing the checksum of a package downloads it again.
+func urlPackageIsSynced(p config.PackageConfig, packagesDir string, logger logging.Logger) bool {
+	if !packageIsSynced(p, packagesDir, logger) {
+		return false
+	}
+	syncFile, err := readStatusFile(p, packagesDir)
+	return err == nil && strings.EqualFold(syncFile.TarballChecksum, p.SHA256)
+}
+
+// downloadURLPackage returns the installCallback for a package with a URL, which fails if the download does not have
+// the package's checksum.
+func (m *localManager) downloadURLPackage(p config.PackageConfig) installCallback {
+	return func(ctx context.Context, rawURL, dstPath string) (string, string, error) {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			return "", "", err
+		}
+		var src io.ReadCloser
+		if parsed.Scheme == "file" {
+			//nolint:gosec
+			src, err = os.Open(parsed.Path)
+			if err != nil {
+				return "", "", err
+			}
+		} else {
+			getReq, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+			if err != nil {
+				return "", "", err
+			}
+			//nolint:bodyclose /// closed in UncheckedErrorFunc
+			resp, err := m.httpClient.Do(getReq)
+			if err != nil {
+				return "", "", err
+			}
+			if resp.StatusCode != http.StatusOK {
+				utils.UncheckedError(resp.Body.Close())
+				return "", "", errors.Errorf("invalid status code %d", resp.StatusCode)
+			}
+			src = resp.Body
+		}
+		defer utils.UncheckedErrorFunc(src.Close)
+
+		//nolint:gosec // safe
+		out, err := os.Create(dstPath)
+		if err != nil {
+			return "", "", err
+		}
+		defer utils.UncheckedErrorFunc(out.Close)
+
+		hash := sha256.New()
+		if _, err := io.CopyN(io.MultiWriter(out, hash), src, maxPackageSize); err != nil && !errors.Is(err, io.EOF) {
+			utils.UncheckedError(os.Remove(dstPath))
+			return "", "", err
+		}
+		checksum := hex.EncodeToString(hash.Sum(nil))
+		if !strings.EqualFold(checksum, p.SHA256) {
+			utils.UncheckedError(os.Remove(dstPath))
+			return "", "", errors.Errorf("download has sha256 checksum %s but %s was expected", checksum, p.SHA256)
+		}
+
+		if isTarballPath(parsed.Path) {
+			return checksum, allowedContentType, nil
+		}
+		return checksum, rawFileContentType, nil
+	}
+}
+
 // Cleanup removes all unknown packages from the working directory.
 func (m *localManager) Cleanup(ctx context.Context) error {
 	m.logger.Debug("Starting package cleanup...")
@@ -206,6 +326,9 @@ func (m *localManager) Cleanup(ctx context.Context) error {
 	defer m.mu.Unlock()
 
 	expectedPackageDirectories := map[string]bool{}
+	for _, p := range m.managedPackages {
+		expectedPackageDirectories[p.LocalDataDirectory(m.packagesDir)] = true
+	}
 	for _, mod := range m.managedModules {
 		pkg, err := mod.module.SyntheticPackage()
 		if err != nil {
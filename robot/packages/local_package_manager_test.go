@@ -2,7 +2,11 @@ package packages
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
@@ -259,3 +263,73 @@ func moduleDirExists(t *testing.T, packagesDir string, mod config.Module) bool {
 	t.Error("other error in moduleDirExists", err)
 	return false // can't get here
 }
+
+func TestLocalManagerURLPackages(t *testing.T) {
+	tmp := t.TempDir()
+	mgr, err := NewLocalManager(&config.Config{PackagePath: filepath.Join(tmp, "pkg")}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	local := mgr.(*localManager)
+
+	tarball, err := os.ReadFile(testTarPath)
+	test.That(t, err, test.ShouldBeNil)
+	urdf := []byte("<robot name=\"arm\"/>")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/model.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(tarball)
+	})
+	mux.HandleFunc("/arm.urdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(urdf)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	checksum := func(data []byte) string {
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:])
+	}
+	model := config.PackageConfig{
+		Name: "model", Package: "acme/model", Version: "1.0.0", Type: config.PackageTypeMlModel,
+		URL: server.URL + "/model.tar.gz", SHA256: checksum(tarball),
+	}
+	arm := config.PackageConfig{
+		Name: "arm", Package: "acme/arm", Version: "1.0.0", Type: config.PackageTypeKinematics,
+		URL: server.URL + "/arm.urdf", SHA256: checksum(urdf),
+	}
+	// packages without a URL are left to the cloud manager
+	cloudPkg := config.PackageConfig{Name: "cloud", Package: "org/cloud", Version: "1", Type: config.PackageTypeMlModel}
+
+	err = mgr.Sync(context.Background(), []config.PackageConfig{model, arm, cloudPkg}, []config.Module{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, local.managedPackages, test.ShouldHaveLength, 2)
+
+	modelDir, err := mgr.PackagePath("model")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, modelDir, test.ShouldEqual, model.LocalDataDirectory(local.packagesDir))
+	_, err = os.Stat(filepath.Join(modelDir, "run.sh"))
+	test.That(t, err, test.ShouldBeNil)
+	armDir, err := mgr.PackagePath("arm")
+	test.That(t, err, test.ShouldBeNil)
+	contents, err := os.ReadFile(filepath.Join(armDir, "arm.urdf"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, contents, test.ShouldResemble, urdf)
+
+	// a download that does not match its checksum fails without affecting other packages
+	badArm := arm
+	badArm.Version = "2.0.0"
+	badArm.SHA256 = checksum([]byte("something else"))
+	err = mgr.Sync(context.Background(), []config.PackageConfig{model, badArm}, []config.Module{})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "sha256 checksum")
+	test.That(t, local.managedPackages, test.ShouldHaveLength, 1)
+
+	// unused versions are removed on cleanup
+	newArm := arm
+	newArm.Version = "2.0.0"
+	err = mgr.Sync(context.Background(), []config.PackageConfig{model, newArm}, []config.Module{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, mgr.Cleanup(context.Background()), test.ShouldBeNil)
+	_, err = os.Stat(arm.LocalDataDirectory(local.packagesDir))
+	test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+	_, err = os.Stat(filepath.Join(newArm.LocalDataDirectory(local.packagesDir), "arm.urdf"))
+	test.That(t, err, test.ShouldBeNil)
+}
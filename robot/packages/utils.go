@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -25,6 +26,15 @@ import (
 // installCallback is the function signature that gets passed to installPackage.
 type installCallback func(ctx context.Context, url, dstPath string) (checksum, contentType string, err error)
 
+// rawFileContentType is the content type of a package file that is kept as is rather than unpacked. Only packages with
+// a URL may be raw files, such as a single URDF or model file.
+const rawFileContentType = "application/octet-stream"
+
+// isTarballPath returns whether a package file at filePath is a tarball to be unpacked.
+func isTarballPath(filePath string) bool {
+	return strings.HasSuffix(filePath, ".tar.gz") || strings.HasSuffix(filePath, ".tgz")
+}
+
 func installPackage(
 	ctx context.Context,
 	logger logging.Logger,
@@ -58,7 +68,8 @@ func installPackage(
 		return err
 	}
 
-	if contentType != allowedContentType {
+	rawFile := contentType == rawFileContentType && p.URL != ""
+	if contentType != allowedContentType && !rawFile {
 		utils.UncheckedError(cleanup(packagesDir, p))
 		return fmt.Errorf("unknown content-type for package %s", contentType)
 	}
@@ -79,8 +90,13 @@ func installPackage(
 		}
 	}()
 
-	// unzip archive.
-	err = unpackFile(ctx, dstPath, tmpDataPath)
+	if rawFile {
+		// keep the file under the name it was downloaded as.
+		err = os.Rename(dstPath, filepath.Join(tmpDataPath, rawFileName(p.URL)))
+	} else {
+		// unzip archive.
+		err = unpackFile(ctx, dstPath, tmpDataPath)
+	}
 	if err != nil {
 		utils.UncheckedError(cleanup(packagesDir, p))
 		return err
@@ -109,6 +125,18 @@ func installPackage(
 	return nil
 }
 
+// rawFileName returns the name a raw file downloaded from rawURL is kept as.
+func rawFileName(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "package"
+	}
+	if name := parsed.Path[strings.LastIndex(parsed.Path, "/")+1:]; name != "" && name != "." && name != ".." {
+		return name
+	}
+	return "package"
+}
+
 func cleanup(packagesDir string, p config.PackageConfig) error {
 	return multierr.Combine(
 		os.RemoveAll(p.LocalDataDirectory(packagesDir)),
@@ -322,6 +350,10 @@ func packageIsSynced(pkg config.PackageConfig, packagesDir string, logger loggin
 
 func packagesAreSynced(packages []config.PackageConfig, packagesDir string, logger logging.Logger) bool {
 	for _, pkg := range packages {
+		// packages with a URL are not synced from the cloud
+		if pkg.URL != "" {
+			continue
+		}
 		if !packageIsSynced(pkg, packagesDir, logger) {
 			return false
 		}
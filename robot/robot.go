@@ -4,6 +4,7 @@ package robot
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -139,7 +140,8 @@ type Robot interface {
 	// Close attempts to cleanly close down all constituent parts of the robot.
 	Close(ctx context.Context) error
 
-	// StopAll cancels all current and outstanding operations for the robot and stops all actuators and movement
+	// StopAll cancels all current and outstanding operations for the robot and stops all actuators and movement,
+	// including those in modules and on remote parts. If any fail to stop, a local robot returns a *StopAllError.
 	StopAll(ctx context.Context, extra map[resource.Name]map[string]interface{}) error
 
 	// RestartModule reloads a module as if its config changed
@@ -176,6 +178,52 @@ type LocalRobot interface {
 	// visualization.
 	// DOT reference: https://graphviz.org/doc/info/lang.html
 	ExportResourcesAsDot(index int) (resource.GetSnapshotInfo, error)
+
+	// ResourceLogLevels returns the level each local resource is logging at.
+	ResourceLogLevels() map[resource.Name]logging.Level
+
+	// SetResourceLogLevel changes the level a resource logs at without reconfiguring it, taking
+	// precedence over its config until set again. A nil level restores the level in its config.
+	SetResourceLogLevel(name resource.Name, level *logging.Level) error
+
+	// ResourceStatuses returns the lifecycle status of each local resource, so that a robot with
+	// resources which failed to configure or are reconfiguring can be told apart from a healthy one.
+	ResourceStatuses() map[resource.Name]resource.NodeStatus
+
+	// MarkResourceOperationSucceeded records that an operation on a local resource just completed
+	// without error.
+	MarkResourceOperationSucceeded(name resource.Name)
+
+	// MarkResourceOperationFailed records that an operation on a local resource just failed. Local
+	// components whose operations keep failing are rebuilt.
+	MarkResourceOperationFailed(name resource.Name)
+
+	// RunSelfTest runs the self-test of each named local resource, or of every resource the robot's
+	// self-test config covers when none are named, and reports which passed. Self-tests which may move
+	// components, such as twitching motors, are skipped unless actuate is set, in which case the robot
+	// should be clear of obstacles.
+	RunSelfTest(ctx context.Context, actuate bool, names ...resource.Name) (*SelfTestReport, error)
+
+	// LastSelfTestReport returns the report of the most recent self-test run, or nil if none has run.
+	LastSelfTestReport() *SelfTestReport
+
+	// ModuleStatuses returns the status of each module's process, including how often it has crashed.
+	ModuleStatuses() []ModuleStatus
+}
+
+// ModuleStatus describes the health of a module's process.
+type ModuleStatus struct {
+	Name string
+	// Running is whether the module's process is up, rather than crashed and waiting to be restarted.
+	Running bool
+	// CrashCount is how many times the module has exited unexpectedly since it was added.
+	CrashCount int
+	// LastCrashedAt is when the module last exited unexpectedly, and is zero if it never has.
+	LastCrashedAt time.Time
+	LastExitCode  int
+	// LastOutput is the last lines the module wrote to stdout or stderr before it last crashed, which
+	// usually explain why.
+	LastOutput []string
 }
 
 // A RemoteRobot is a Robot that was created through a connection.
@@ -198,6 +246,48 @@ type Status struct {
 	Status           interface{}
 }
 
+// StopAllError is returned by StopAll when anything failed to stop, holding why each did. A remote part
+// which failed to stop is keyed by its name under the remote API.
+type StopAllError struct {
+	Errors map[resource.Name]error
+}
+
+func (e *StopAllError) Error() string {
+	failures := make([]string, 0, len(e.Errors))
+	for name, err := range e.Errors {
+		failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+	}
+	sort.Strings(failures)
+	return fmt.Sprintf("failed to stop %d resources: %s", len(e.Errors), strings.Join(failures, "; "))
+}
+
+// SelfTestResult is the outcome of one resource's self-test.
+type SelfTestResult struct {
+	Name resource.Name
+	// Skipped is whether the self-test was not run because it may move the resource and actuating
+	// wasn't allowed. Error then says so, but a skipped self-test does not fail the report.
+	Skipped bool
+	// Error is why the self-test failed, or nil if it passed.
+	Error    error
+	Duration time.Duration
+}
+
+// SelfTestReport aggregates the self-test results of a robot's resources, as a pre-mission checklist.
+type SelfTestReport struct {
+	StartedAt time.Time
+	Results   []SelfTestResult
+}
+
+// Passed returns whether every resource passed its self-test, other than those which were skipped.
+func (r *SelfTestReport) Passed() bool {
+	for _, result := range r.Results {
+		if result.Error != nil && !result.Skipped {
+			return false
+		}
+	}
+	return true
+}
+
 // RestartModuleRequest is a go mirror of a proto message.
 type RestartModuleRequest struct {
 	ModuleID   string
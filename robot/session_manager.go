@@ -23,6 +23,7 @@ func NewSessionManager(robot Robot, heartbeatWindow time.Duration) *SessionManag
 		logger:            robot.Logger().Sublogger("session_manager"),
 		sessions:          map[uuid.UUID]*session.Session{},
 		resourceToSession: map[resource.Name]uuid.UUID{},
+		pendingStops:      map[resource.Name]pendingStop{},
 	}
 	m.workers = rdkutils.NewStoppableWorkers(m.expireLoop)
 	return m
@@ -40,9 +41,28 @@ type SessionManager struct {
 
 	resourceToSession map[resource.Name]uuid.UUID
 
+	// stopGracePeriod is how long resources associated with an expired session are left running before being
+	// stopped, so that a client which briefly lost its connection can resume commanding them in a new session.
+	stopGracePeriod time.Duration
+	pendingStops    map[resource.Name]pendingStop
+
 	workers rdkutils.StoppableWorkers
 }
 
+// pendingStop is a resource waiting out the stop grace period after the session commanding it expired.
+type pendingStop struct {
+	sessionID uuid.UUID
+	deadline  time.Time
+}
+
+// SetStopGracePeriod sets how long resources associated with an expired session are left running before being
+// stopped. If a new session is associated with a resource during that time, the resource is not stopped.
+func (m *SessionManager) SetStopGracePeriod(gracePeriod time.Duration) {
+	m.sessionResourceMu.Lock()
+	defer m.sessionResourceMu.Unlock()
+	m.stopGracePeriod = gracePeriod
+}
+
 // All returns all active sessions.
 func (m *SessionManager) All() []*session.Session {
 	m.sessionResourceMu.RLock()
@@ -66,21 +86,15 @@ func (m *SessionManager) expireLoop(ctx context.Context) {
 		now := time.Now()
 
 		toDelete := map[uuid.UUID]struct{}{}
-		var toStop []resource.Name
 		m.sessionResourceMu.RLock()
 		for id, sess := range m.sessions {
 			if !sess.Active(now) {
 				toDelete[id] = struct{}{}
 			}
 		}
-		for res, sess := range m.resourceToSession {
-			if _, ok := toDelete[sess]; ok {
-				resCopy := res
-				toStop = append(toStop, resCopy)
-			}
-		}
 		m.sessionResourceMu.RUnlock()
 
+		var toStop []resource.Name
 		var resourceErrs []error
 		var serverClosing bool
 		func() {
@@ -89,6 +103,22 @@ func (m *SessionManager) expireLoop(ctx context.Context) {
 			for id := range toDelete {
 				delete(m.sessions, id)
 			}
+			for res, sess := range m.resourceToSession {
+				if _, ok := toDelete[sess]; ok {
+					m.pendingStops[res] = pendingStop{sessionID: sess, deadline: now.Add(m.stopGracePeriod)}
+				}
+			}
+			for res, pending := range m.pendingStops {
+				if now.Before(pending.deadline) {
+					continue
+				}
+				delete(m.pendingStops, res)
+				// a new session has taken over the resource, so whoever is commanding it now decides when it stops
+				if m.resourceToSession[res] != pending.sessionID {
+					continue
+				}
+				toStop = append(toStop, res)
+			}
 
 			if len(toStop) == 0 {
 				return
@@ -2,14 +2,18 @@ package robot_test
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"go.viam.com/test"
 	"go.viam.com/utils/testutils"
 
+	"go.viam.com/rdk/components/motor"
 	"go.viam.com/rdk/config"
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot"
 	"go.viam.com/rdk/session"
 	"go.viam.com/rdk/testutils/inject"
@@ -87,3 +91,50 @@ func TestSessionManagerExpiredSessions(t *testing.T) {
 			test.ShouldEqual, 1)
 	})
 }
+
+func TestSessionManagerStopGracePeriod(t *testing.T) {
+	ctx := context.Background()
+	logger, logs := logging.NewObservedTestLogger(t)
+	r := &inject.Robot{}
+
+	r.LoggerFunc = func() logging.Logger {
+		return logger
+	}
+	stops := map[resource.Name]*atomic.Int32{}
+	for _, name := range []string{"left", "right"} {
+		stops[motor.Named(name)] = &atomic.Int32{}
+	}
+	r.ResourceByNameFunc = func(name resource.Name) (resource.Resource, error) {
+		m := inject.NewMotor(name.Name)
+		m.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+			stops[name].Add(1)
+			return nil
+		}
+		return m, nil
+	}
+
+	sm := robot.NewSessionManager(r, time.Duration(-1))
+	defer sm.Close()
+	sm.SetStopGracePeriod(time.Second)
+
+	sess, err := sm.Start(ctx, "foo")
+	test.That(t, err, test.ShouldBeNil)
+	sm.AssociateResource(sess.ID(), motor.Named("left"))
+	sm.AssociateResource(sess.ID(), motor.Named("right"))
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, logs.FilterMessageSnippet("sessions expired").Len(), test.ShouldEqual, 1)
+	})
+	// nothing is stopped while the client may still reconnect
+	test.That(t, stops[motor.Named("left")].Load(), test.ShouldEqual, 0)
+	test.That(t, stops[motor.Named("right")].Load(), test.ShouldEqual, 0)
+
+	// a reconnected client takes over the left motor, so only the right one is stopped
+	sm.AssociateResource(uuid.New(), motor.Named("left"))
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, stops[motor.Named("right")].Load(), test.ShouldEqual, 1)
+	})
+	test.That(t, stops[motor.Named("left")].Load(), test.ShouldEqual, 0)
+}
@@ -0,0 +1,210 @@
+package web
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"go.viam.com/utils/rpc"
+	googlegrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/operation"
+	"go.viam.com/rdk/robot/diagnostics"
+)
+
+// roleRanks orders roles by what they permit; each role may do anything the roles ranked below it may.
+var roleRanks = map[config.AuthRole]int{
+	config.AuthRoleViewer:   0,
+	config.AuthRoleOperator: 1,
+	config.AuthRoleOwner:    2,
+}
+
+// viewerMethods are the methods which do not read state by name but which viewers need in order to connect and
+// look around, including those outside of the robot's own APIs, such as signaling and streaming video.
+var viewerMethods = map[string]bool{
+	"/viam.robot.v1.RobotService/ResourceNames":        true,
+	"/viam.robot.v1.RobotService/ResourceRPCSubtypes":  true,
+	"/viam.robot.v1.RobotService/FrameSystemConfig":    true,
+	"/viam.robot.v1.RobotService/TransformPose":        true,
+	"/viam.robot.v1.RobotService/TransformPCD":         true,
+	"/viam.robot.v1.RobotService/StartSession":         true,
+	"/viam.robot.v1.RobotService/SendSessionHeartbeat": true,
+	"/viam.robot.v1.RobotService/BlockForOperation":    true,
+
+	"/proto.rpc.webrtc.v1.SignalingService/Call":                     true,
+	"/proto.rpc.webrtc.v1.SignalingService/CallUpdate":               true,
+	"/proto.rpc.webrtc.v1.SignalingService/Answer":                   true,
+	"/proto.rpc.webrtc.v1.SignalingService/OptionalWebRTCConfig":     true,
+	"/proto.rpc.v1.AuthService/Authenticate":                         true,
+	"/proto.rpc.v1.ExternalAuthService/AuthenticateTo":               true,
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo": true,
+	"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo":      true,
+	"/proto.stream.v1.StreamService/ListStreams":                     true,
+	"/proto.stream.v1.StreamService/AddStream":                       true,
+	"/proto.stream.v1.StreamService/RemoveStream":                    true,
+	"/proto.stream.v1.StreamService/GetStreamOptions":                true,
+}
+
+// operatorMethods are the methods outside of the robot's own APIs which change what others see, but which
+// do not administer the robot.
+var operatorMethods = map[string]bool{
+	"/proto.stream.v1.StreamService/SetStreamOptions": true,
+}
+
+// ownerMethods are the robot methods which administer the robot rather than command its resources.
+var ownerMethods = map[string]bool{
+	"/viam.robot.v1.RobotService/RestartModule": true,
+	"/viam.robot.v1.RobotService/Shutdown":      true,
+	// self-tests may move components, and so are only run by those who administer the robot
+	diagnostics.FullMethod(diagnostics.MethodRunSelfTest): true,
+	diagnostics.FullMethod(diagnostics.MethodSetLogLevel): true,
+}
+
+// ownerServices are the services all of whose methods administer the machine the robot runs on, such as the
+// shell service, which can run commands and copy files.
+var ownerServices = []string{
+	"/viam.service.shell.v1.ShellService/",
+}
+
+// requiredRole returns the least role which may call the full method. Methods outside of the robot's own APIs
+// which are not known, such as those of modular APIs, require the owner role, since what they do is unknown.
+func requiredRole(fullMethod string) config.AuthRole {
+	robotAPI := strings.HasPrefix(fullMethod, "/viam.")
+	switch {
+	case ownerMethods[fullMethod], isOwnerService(fullMethod):
+		return config.AuthRoleOwner
+	case viewerMethods[fullMethod], robotAPI && operation.IsReadMethod(fullMethod):
+		return config.AuthRoleViewer
+	case operatorMethods[fullMethod], robotAPI:
+		return config.AuthRoleOperator
+	default:
+		return config.AuthRoleOwner
+	}
+}
+
+func isOwnerService(fullMethod string) bool {
+	for _, prefix := range ownerServices {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// localCallersOnly serves h only to callers on the robot's own machine when auth is enabled. HTTP handlers are
+// not authenticated the way RPCs are, so roles cannot be checked for them, and callers which can already reach
+// the robot's machine are the only ones trusted with what the owner role allows.
+func localCallersOnly(auth config.AuthConfig, h http.HandlerFunc) http.HandlerFunc {
+	if len(auth.Handlers) == 0 {
+		return h
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if ip := net.ParseIP(host); err != nil || ip == nil || !ip.IsLoopback() {
+			http.Error(w, "only available from the robot's own machine", http.StatusForbidden)
+			return
+		}
+		h(w, r)
+	}
+}
+
+// authorizer checks that authenticated entities hold a role which permits the methods they call, and records
+// every command they send which may actuate, so that it is known who moved what.
+type authorizer struct {
+	roles       map[string]config.AuthRole
+	defaultRole config.AuthRole
+	apiKeyIDs   map[string]struct{}
+	logger      logging.Logger
+}
+
+func newAuthorizer(auth config.AuthConfig, logger logging.Logger) *authorizer {
+	a := &authorizer{
+		roles:       auth.Roles,
+		defaultRole: auth.DefaultRole,
+		apiKeyIDs:   map[string]struct{}{},
+		logger:      logger,
+	}
+	for _, handler := range auth.Handlers {
+		if handler.Type != rpc.CredentialsTypeAPIKey {
+			continue
+		}
+		for id := range parseAPIKeys(handler) {
+			a.apiKeyIDs[id] = struct{}{}
+		}
+	}
+	return a
+}
+
+// role returns the role of the entity. Entities without one, such as those authenticating with the robot's
+// location secret, may do anything, unless they are API keys and there is a default role.
+func (a *authorizer) role(entity string) config.AuthRole {
+	if role, ok := a.roles[entity]; ok {
+		return role
+	}
+	if _, ok := a.apiKeyIDs[entity]; ok && a.defaultRole != "" {
+		return a.defaultRole
+	}
+	return config.AuthRoleOwner
+}
+
+// authorize returns the role required to call the full method, or an error if the caller does not hold it.
+// Unauthenticated callers are only possible when auth is disabled, so they are let through.
+func (a *authorizer) authorize(ctx context.Context, fullMethod string) (string, config.AuthRole, error) {
+	required := requiredRole(fullMethod)
+	authEntity, ok := rpc.ContextAuthEntity(ctx)
+	if !ok {
+		return "", required, nil
+	}
+	role := a.role(authEntity.Entity)
+	if roleRanks[role] < roleRanks[required] {
+		a.logger.CWarnw(ctx, "permission denied", "entity", authEntity.Entity, "role", role, "method", fullMethod)
+		return "", required, status.Errorf(codes.PermissionDenied,
+			"%q has the %q role, but %s requires the %q role", authEntity.Entity, role, fullMethod, required)
+	}
+	return authEntity.Entity, required, nil
+}
+
+func (a *authorizer) unaryServerInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *googlegrpc.UnaryServerInfo,
+	handler googlegrpc.UnaryHandler,
+) (interface{}, error) {
+	entity, required, err := a.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := handler(ctx, req)
+	if entity != "" && required != config.AuthRoleViewer {
+		fields := []interface{}{"entity", entity, "method", info.FullMethod}
+		if name, ok := resourceNameForRPC(info.FullMethod, req); ok {
+			fields = append(fields, "resource", name.String())
+		}
+		if err != nil {
+			fields = append(fields, "error", err)
+		}
+		a.logger.CInfow(ctx, "command", fields...)
+	}
+	return resp, err
+}
+
+func (a *authorizer) streamServerInterceptor(
+	srv interface{},
+	ss googlegrpc.ServerStream,
+	info *googlegrpc.StreamServerInfo,
+	handler googlegrpc.StreamHandler,
+) error {
+	entity, required, err := a.authorize(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	if entity != "" && required != config.AuthRoleViewer {
+		a.logger.CInfow(ss.Context(), "command stream", "entity", entity, "method", info.FullMethod)
+	}
+	return handler(srv, ss)
+}
@@ -0,0 +1,126 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "go.viam.com/api/component/motor/v1"
+	"go.viam.com/test"
+	"go.viam.com/utils/rpc"
+	googlegrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	// register the motor API, so that the audit log can name the motor.
+	_ "go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/robot/diagnostics"
+	rutils "go.viam.com/rdk/utils"
+)
+
+func TestRequiredRole(t *testing.T) {
+	for method, role := range map[string]config.AuthRole{
+		"/viam.component.motor.v1.MotorService/GetPosition":         config.AuthRoleViewer,
+		"/viam.component.sensor.v1.SensorService/GetReadings":       config.AuthRoleViewer,
+		"/viam.robot.v1.RobotService/ResourceNames":                 config.AuthRoleViewer,
+		"/proto.rpc.webrtc.v1.SignalingService/Call":                config.AuthRoleViewer,
+		"/viam.component.motor.v1.MotorService/SetPower":            config.AuthRoleOperator,
+		"/viam.component.arm.v1.ArmService/MoveToPosition":          config.AuthRoleOperator,
+		"/viam.component.generic.v1.GenericService/DoCommand":       config.AuthRoleOperator,
+		"/viam.robot.v1.RobotService/StopAll":                       config.AuthRoleOperator,
+		"/viam.robot.v1.RobotService/RestartModule":                 config.AuthRoleOwner,
+		"/viam.robot.v1.RobotService/Shutdown":                      config.AuthRoleOwner,
+		"/viam.service.navigation.v1.NavigationService/SetMode":     config.AuthRoleOperator,
+		diagnostics.FullMethod(diagnostics.MethodGetSelfTestReport): config.AuthRoleViewer,
+		diagnostics.FullMethod(diagnostics.MethodRunSelfTest):       config.AuthRoleOwner,
+		diagnostics.FullMethod(diagnostics.MethodGetLogLevels):      config.AuthRoleViewer,
+		diagnostics.FullMethod(diagnostics.MethodSetLogLevel):       config.AuthRoleOwner,
+		"/viam.service.shell.v1.ShellService/Shell":                 config.AuthRoleOwner,
+		"/viam.service.shell.v1.ShellService/CopyFilesFromMachine":  config.AuthRoleOwner,
+		"/proto.stream.v1.StreamService/AddStream":                  config.AuthRoleViewer,
+		"/acme.service.widget.v1.WidgetService/GetWidget":           config.AuthRoleOwner,
+		"/acme.service.widget.v1.WidgetService/DoCommand":           config.AuthRoleOwner,
+	} {
+		test.That(t, requiredRole(method), test.ShouldEqual, role)
+	}
+}
+
+func TestAuthorizer(t *testing.T) {
+	logger, logs := logging.NewObservedTestLogger(t)
+	authz := newAuthorizer(config.AuthConfig{
+		Handlers: []config.AuthHandlerConfig{{
+			Type:   rpc.CredentialsTypeAPIKey,
+			Config: rutils.AttributeMap{"viewer-key": "secret1", "operator-key": "secret2", "new-key": "secret3"},
+		}},
+		Roles: map[string]config.AuthRole{
+			"viewer-key":   config.AuthRoleViewer,
+			"operator-key": config.AuthRoleOperator,
+		},
+		DefaultRole: config.AuthRoleViewer,
+	}, logger)
+
+	var handled int
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		handled++
+		return &pb.SetPowerResponse{}, nil
+	}
+	call := func(entity, method string) error {
+		ctx := context.Background()
+		if entity != "" {
+			ctx = rpc.ContextWithAuthEntity(ctx, rpc.EntityInfo{Entity: entity})
+		}
+		_, err := authz.unaryServerInterceptor(ctx, &pb.SetPowerRequest{Name: "left"},
+			&googlegrpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+	const setPower = "/viam.component.motor.v1.MotorService/SetPower"
+	const restartModule = "/viam.robot.v1.RobotService/RestartModule"
+
+	// viewers, including API keys given the default role, may only look
+	test.That(t, call("viewer-key", "/viam.component.motor.v1.MotorService/GetPosition"), test.ShouldBeNil)
+	for _, entity := range []string{"viewer-key", "new-key"} {
+		err := call(entity, setPower)
+		test.That(t, status.Code(err), test.ShouldEqual, codes.PermissionDenied)
+		test.That(t, err.Error(), test.ShouldContainSubstring, `"viewer" role`)
+	}
+	test.That(t, handled, test.ShouldEqual, 1)
+	test.That(t, logs.FilterMessageSnippet("command").Len(), test.ShouldEqual, 0)
+
+	// operators may command actuators, and each command is recorded
+	test.That(t, call("operator-key", setPower), test.ShouldBeNil)
+	test.That(t, status.Code(call("operator-key", restartModule)), test.ShouldEqual, codes.PermissionDenied)
+	commands := logs.FilterMessageSnippet("command").All()
+	test.That(t, commands, test.ShouldHaveLength, 1)
+	test.That(t, commands[0].ContextMap()["entity"], test.ShouldEqual, "operator-key")
+	test.That(t, commands[0].ContextMap()["resource"], test.ShouldEqual, "rdk:component:motor/left")
+
+	// entities which are not API keys, such as the location secret, and callers of robots without auth may do
+	// anything
+	test.That(t, call("robot.local", restartModule), test.ShouldBeNil)
+	test.That(t, call("", restartModule), test.ShouldBeNil)
+	test.That(t, handled, test.ShouldEqual, 4)
+	test.That(t, logs.FilterMessageSnippet("command").Len(), test.ShouldEqual, 2)
+}
+
+func TestLocalCallersOnly(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	serve := func(auth config.AuthConfig, remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, "/debug/graph", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		localCallersOnly(auth, handler)(w, req)
+		return w.Code
+	}
+
+	// without auth, anyone could call the RPCs anyway
+	test.That(t, serve(config.AuthConfig{}, "192.168.1.20:5000"), test.ShouldEqual, http.StatusOK)
+
+	auth := config.AuthConfig{Handlers: []config.AuthHandlerConfig{{Type: rpc.CredentialsTypeAPIKey}}}
+	test.That(t, serve(auth, "127.0.0.1:5000"), test.ShouldEqual, http.StatusOK)
+	test.That(t, serve(auth, "[::1]:5000"), test.ShouldEqual, http.StatusOK)
+	test.That(t, serve(auth, "192.168.1.20:5000"), test.ShouldEqual, http.StatusForbidden)
+	test.That(t, serve(auth, "garbage"), test.ShouldEqual, http.StatusForbidden)
+}
@@ -0,0 +1,133 @@
+package web
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/robot/diagnostics"
+)
+
+// diagnosticsServer serves the diagnostics service of the robot the web service serves.
+type diagnosticsServer struct {
+	svc *webService
+}
+
+// localRobot returns the robot as a local robot, since only local robots can be diagnosed.
+func (s *diagnosticsServer) localRobot() (robot.LocalRobot, error) {
+	localRobot, ok := s.svc.r.(robot.LocalRobot)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "only a local robot can be diagnosed")
+	}
+	return localRobot, nil
+}
+
+// RunSelfTest runs the self-tests of the requested resources, or else of those in the robot's self-test config.
+// Self-tests which may move components only run when the request allows actuating.
+func (s *diagnosticsServer) RunSelfTest(
+	ctx context.Context,
+	req *diagnostics.RunSelfTestRequest,
+) (*diagnostics.SelfTestReport, error) {
+	localRobot, err := s.localRobot()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]resource.Name, 0, len(req.Resources))
+	for _, nameStr := range req.Resources {
+		name, err := resource.NewFromString(nameStr)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		names = append(names, name)
+	}
+	report, err := localRobot.RunSelfTest(ctx, req.Actuate, names...)
+	if err != nil {
+		return nil, err
+	}
+	return selfTestReportToDiagnostics(report), nil
+}
+
+// GetSelfTestReport returns the report of the most recent self-test run.
+func (s *diagnosticsServer) GetSelfTestReport(
+	ctx context.Context,
+	req *diagnostics.GetSelfTestReportRequest,
+) (*diagnostics.SelfTestReport, error) {
+	localRobot, err := s.localRobot()
+	if err != nil {
+		return nil, err
+	}
+	report := localRobot.LastSelfTestReport()
+	if report == nil {
+		return nil, status.Error(codes.NotFound, "no self-test has run")
+	}
+	return selfTestReportToDiagnostics(report), nil
+}
+
+// GetLogLevels returns the level each resource logs at.
+func (s *diagnosticsServer) GetLogLevels(
+	ctx context.Context,
+	req *diagnostics.GetLogLevelsRequest,
+) (*diagnostics.LogLevels, error) {
+	localRobot, err := s.localRobot()
+	if err != nil {
+		return nil, err
+	}
+	return logLevelsToDiagnostics(localRobot), nil
+}
+
+// SetLogLevel changes the level a resource logs at without reconfiguring it.
+func (s *diagnosticsServer) SetLogLevel(
+	ctx context.Context,
+	req *diagnostics.SetLogLevelRequest,
+) (*diagnostics.LogLevels, error) {
+	localRobot, err := s.localRobot()
+	if err != nil {
+		return nil, err
+	}
+	name, err := resource.NewFromString(req.Resource)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	var level *logging.Level
+	if !strings.EqualFold(req.Level, diagnostics.LogLevelConfig) {
+		parsed, err := logging.LevelFromString(req.Level)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		level = &parsed
+	}
+	if err := localRobot.SetResourceLogLevel(name, level); err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	s.svc.logger.CInfow(ctx, "changed resource log level", "resource", name, "level", req.Level)
+	return logLevelsToDiagnostics(localRobot), nil
+}
+
+func logLevelsToDiagnostics(localRobot robot.LocalRobot) *diagnostics.LogLevels {
+	levels := map[string]string{}
+	for name, level := range localRobot.ResourceLogLevels() {
+		levels[name.String()] = strings.ToLower(level.String())
+	}
+	return &diagnostics.LogLevels{Levels: levels}
+}
+
+func selfTestReportToDiagnostics(report *robot.SelfTestReport) *diagnostics.SelfTestReport {
+	results := make(map[string]diagnostics.SelfTestResult, len(report.Results))
+	for _, result := range report.Results {
+		reported := diagnostics.SelfTestResult{
+			Passed:          result.Error == nil,
+			Skipped:         result.Skipped,
+			DurationSeconds: result.Duration.Seconds(),
+		}
+		if result.Error != nil {
+			reported.Error = logging.Redact(result.Error.Error())
+		}
+		results[result.Name.String()] = reported
+	}
+	return &diagnostics.SelfTestReport{Passed: report.Passed(), StartedAt: report.StartedAt, Resources: results}
+}
@@ -0,0 +1,218 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	googlegrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/operation"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+// resourceHealth is how a resource's status is reported by the health endpoint.
+type resourceHealth struct {
+	State                  string       `json:"state"`
+	TransitionedAt         time.Time    `json:"transitioned_at"`
+	LastReconfigured       *time.Time   `json:"last_reconfigured,omitempty"`
+	Error                  string       `json:"error,omitempty"`
+	UnresolvedDependencies []string     `json:"unresolved_dependencies,omitempty"`
+	LastSucceededAt        *time.Time   `json:"last_succeeded_at,omitempty"`
+	ConsecutiveFailures    int          `json:"consecutive_failures,omitempty"`
+	History                []transition `json:"history,omitempty"`
+}
+
+// transition is how a change to a resource's state or error is reported by the health endpoint.
+type transition struct {
+	State string    `json:"state"`
+	Error string    `json:"error,omitempty"`
+	At    time.Time `json:"at"`
+}
+
+// moduleHealth is how a module's process is reported by the health endpoint.
+type moduleHealth struct {
+	Running       bool       `json:"running"`
+	CrashCount    int        `json:"crash_count"`
+	LastCrashedAt *time.Time `json:"last_crashed_at,omitempty"`
+	LastExitCode  int        `json:"last_exit_code,omitempty"`
+	LastOutput    []string   `json:"last_output,omitempty"`
+}
+
+// handleHealth reports the lifecycle status of every resource on the robot, responding with 503 Service
+// Unavailable rather than 200 OK when any of them is not ready or has an error, so that orchestrators can use it
+// as a readiness check. How often each module has crashed, and its last output before crashing, are included
+// too. Each resource's recent changes in state are included with history=true, and a single
+// resource can be checked with resource=, e.g.
+//
+//	curl 'http://localhost:8080/debug/health?resource=rdk:component:motor/left&history=true'
+func (svc *webService) handleHealth(w http.ResponseWriter, r *http.Request) {
+	localRobot, isLocal := svc.r.(robot.LocalRobot)
+	if !isLocal {
+		http.Error(w, "health can only be checked on a local robot", http.StatusNotFound)
+		return
+	}
+	withHistory, err := strconv.ParseBool(r.FormValue("history"))
+	if err != nil && r.FormValue("history") != "" {
+		http.Error(w, "history must be true or false", http.StatusBadRequest)
+		return
+	}
+	var only *resource.Name
+	if r.FormValue("resource") != "" {
+		name, err := resource.NewFromString(r.FormValue("resource"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		only = &name
+	}
+
+	healthy := true
+	resources := map[string]resourceHealth{}
+	for name, nodeStatus := range localRobot.ResourceStatuses() {
+		if only != nil && name != *only {
+			continue
+		}
+		healthy = healthy && nodeStatus.Healthy()
+		health := resourceHealth{
+			State:                  strings.ToLower(nodeStatus.State.String()),
+			TransitionedAt:         nodeStatus.TransitionedAt,
+			LastReconfigured:       nodeStatus.LastReconfigured,
+			UnresolvedDependencies: nodeStatus.UnresolvedDependencies,
+			ConsecutiveFailures:    nodeStatus.ConsecutiveFailures,
+		}
+		if nodeStatus.Error != nil {
+			health.Error = logging.Redact(nodeStatus.Error.Error())
+		}
+		if !nodeStatus.LastSucceededAt.IsZero() {
+			lastSucceededAt := nodeStatus.LastSucceededAt
+			health.LastSucceededAt = &lastSucceededAt
+		}
+		if withHistory {
+			for _, change := range nodeStatus.History {
+				reported := transition{State: strings.ToLower(change.State.String()), At: change.At}
+				if change.Error != nil {
+					reported.Error = logging.Redact(change.Error.Error())
+				}
+				health.History = append(health.History, reported)
+			}
+		}
+		resources[name.String()] = health
+	}
+	if only != nil && len(resources) == 0 {
+		http.Error(w, resource.NewNotFoundError(*only).Error(), http.StatusNotFound)
+		return
+	}
+
+	modules := map[string]moduleHealth{}
+	for _, status := range localRobot.ModuleStatuses() {
+		health := moduleHealth{
+			Running:      status.Running,
+			CrashCount:   status.CrashCount,
+			LastExitCode: status.LastExitCode,
+		}
+		if !status.LastCrashedAt.IsZero() {
+			lastCrashedAt := status.LastCrashedAt
+			health.LastCrashedAt = &lastCrashedAt
+			for _, line := range status.LastOutput {
+				health.LastOutput = append(health.LastOutput, logging.Redact(line))
+			}
+		}
+		modules[status.Name] = health
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"healthy":   healthy,
+		"resources": resources,
+		"modules":   modules,
+	}); err != nil {
+		svc.logger.Debugw("failed to write health", "error", err)
+	}
+}
+
+// healthUnaryServerInterceptor records whether each unary RPC on a resource succeeds, so that the health
+// endpoint can report when a resource last worked, and components which keep failing can be rebuilt.
+func (svc *webService) healthUnaryServerInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *googlegrpc.UnaryServerInfo,
+	handler googlegrpc.UnaryHandler,
+) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	localRobot, isLocal := svc.r.(robot.LocalRobot)
+	if !isLocal {
+		return resp, err
+	}
+	// what DoCommand does, and so whether its errors are the resource's doing or the caller's, is up to the caller
+	if strings.HasSuffix(info.FullMethod, "/DoCommand") {
+		return resp, err
+	}
+	name, ok := resourceNameForRPC(info.FullMethod, req)
+	if !ok {
+		return resp, err
+	}
+	switch status.Code(err) {
+	case codes.OK:
+		localRobot.MarkResourceOperationSucceeded(name)
+	case codes.Internal, codes.Unavailable, codes.DataLoss:
+		localRobot.MarkResourceOperationFailed(name)
+	case codes.Unknown:
+		// errors without a code are only the resource's doing when reading, since a move can fail because of what
+		// the caller asked for, such as a position out of reach
+		if operation.IsReadMethod(info.FullMethod) {
+			localRobot.MarkResourceOperationFailed(name)
+		}
+	default:
+		// other codes are the caller's doing, such as a bad argument or giving up, rather than the resource's
+	}
+	return resp, err
+}
+
+var (
+	rpcServiceAPIsMu sync.Mutex
+	// rpcServiceAPIs caches which API each RPC service, such as viam.component.motor.v1.MotorService, serves.
+	rpcServiceAPIs = map[string]resource.API{}
+)
+
+// resourceNameForRPC returns which resource an RPC is on, if it is on a registered resource API and its request
+// names the resource.
+func resourceNameForRPC(fullMethod string, req interface{}) (resource.Name, bool) {
+	named, ok := req.(interface{ GetName() string })
+	if !ok || named.GetName() == "" {
+		return resource.Name{}, false
+	}
+	serviceName, _, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return resource.Name{}, false
+	}
+
+	rpcServiceAPIsMu.Lock()
+	defer rpcServiceAPIsMu.Unlock()
+	api, ok := rpcServiceAPIs[serviceName]
+	if !ok {
+		// APIs may be registered at any time by modules, so look any which aren't cached up again
+		for regAPI, reg := range resource.RegisteredAPIs() {
+			if reg.RPCServiceDesc != nil && reg.RPCServiceDesc.ServiceName == serviceName {
+				api, ok = regAPI, true
+				rpcServiceAPIs[serviceName] = api
+				break
+			}
+		}
+	}
+	if !ok {
+		return resource.Name{}, false
+	}
+	return resource.NewName(api, named.GetName()), true
+}
@@ -0,0 +1,85 @@
+package web
+
+import (
+	"context"
+
+	"go.viam.com/utils/rpc"
+	googlegrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// messageSizeUnaryServerInterceptor fails responses larger than the message size limit with an error saying
+// which resource they came from. Left alone, they would be discarded by the client or the WebRTC transport
+// with an error that gives no hint of the cause.
+func messageSizeUnaryServerInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *googlegrpc.UnaryServerInfo,
+	handler googlegrpc.UnaryHandler,
+) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+	if err := checkMessageSize(info.FullMethod, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// messageSizeStreamServerInterceptor fails streams when they would send a message larger than the message
+// size limit, like messageSizeUnaryServerInterceptor.
+func messageSizeStreamServerInterceptor(
+	srv interface{},
+	ss googlegrpc.ServerStream,
+	info *googlegrpc.StreamServerInfo,
+	handler googlegrpc.StreamHandler,
+) error {
+	return handler(srv, &messageSizeStream{ServerStream: ss, fullMethod: info.FullMethod})
+}
+
+// messageSizeStream checks the size of each message sent on a stream. It remembers the last request received
+// so that errors can name the resource the stream is for.
+type messageSizeStream struct {
+	googlegrpc.ServerStream
+	fullMethod string
+	req        interface{}
+}
+
+func (s *messageSizeStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	s.req = m
+	return nil
+}
+
+func (s *messageSizeStream) SendMsg(m interface{}) error {
+	if err := checkMessageSize(s.fullMethod, s.req, m); err != nil {
+		return err
+	}
+	return s.ServerStream.SendMsg(m)
+}
+
+// checkMessageSize returns a ResourceExhausted error if resp, the response to req, is larger than the message
+// size limit.
+func checkMessageSize(fullMethod string, req, resp interface{}) error {
+	msg, ok := resp.(proto.Message)
+	if !ok {
+		return nil
+	}
+	size := proto.Size(msg)
+	if size <= rpc.MaxMessageSize {
+		return nil
+	}
+	if name, ok := resourceNameForRPC(fullMethod, req); ok {
+		return status.Errorf(codes.ResourceExhausted,
+			"response to %s from %s is %d bytes, more than the %d byte limit; raise network.max_message_size_mb to allow it",
+			fullMethod, name, size, rpc.MaxMessageSize)
+	}
+	return status.Errorf(codes.ResourceExhausted,
+		"response to %s is %d bytes, more than the %d byte limit; raise network.max_message_size_mb to allow it",
+		fullMethod, size, rpc.MaxMessageSize)
+}
@@ -0,0 +1,43 @@
+package web
+
+import (
+	"context"
+	"testing"
+
+	pb "go.viam.com/api/component/camera/v1"
+	"go.viam.com/test"
+	"go.viam.com/utils/rpc"
+	googlegrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	// register the camera API, so that errors can name the camera.
+	_ "go.viam.com/rdk/components/camera"
+)
+
+func TestMessageSizeUnaryServerInterceptor(t *testing.T) {
+	oldMax := rpc.MaxMessageSize
+	rpc.MaxMessageSize = 1000
+	defer func() {
+		rpc.MaxMessageSize = oldMax
+	}()
+
+	info := &googlegrpc.UnaryServerInfo{FullMethod: "/viam.component.camera.v1.CameraService/GetImage"}
+	handlerWithImage := func(size int) googlegrpc.UnaryHandler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			return &pb.GetImageResponse{Image: make([]byte, size)}, nil
+		}
+	}
+
+	resp, err := messageSizeUnaryServerInterceptor(context.Background(), &pb.GetImageRequest{Name: "camera1"}, info,
+		handlerWithImage(100))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp, test.ShouldNotBeNil)
+
+	_, err = messageSizeUnaryServerInterceptor(context.Background(), &pb.GetImageRequest{Name: "camera1"}, info,
+		handlerWithImage(2000))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, status.Code(err), test.ShouldEqual, codes.ResourceExhausted)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "rdk:component:camera/camera1")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "max_message_size_mb")
+}
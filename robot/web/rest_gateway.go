@@ -0,0 +1,55 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"goji.io/pat"
+
+	"go.viam.com/rdk/utils"
+)
+
+// cameraSnapshotHandler serves the image of a camera as is, rather than base64 encoded in JSON as the gateway does,
+// so that scripts and home automation systems can use its URL as the address of an image. The mime_type query
+// parameter chooses the format, which defaults to JPEG. The image is fetched through the gateway, so the request is
+// authenticated and authorized the same as any other.
+func cameraSnapshotHandler(gateway http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mimeType := r.URL.Query().Get("mime_type")
+		if mimeType == "" {
+			mimeType = utils.MimeTypeJPEG
+		}
+		imageReq := r.Clone(r.Context())
+		imageReq.URL.Path = "/viam/api/v1/component/camera/" + url.PathEscape(pat.Param(r, "name")) + "/image"
+		imageReq.URL.RawPath = ""
+		imageReq.URL.RawQuery = url.Values{"mime_type": {mimeType}}.Encode()
+
+		recorder := httptest.NewRecorder()
+		gateway.ServeHTTP(recorder, imageReq)
+		if recorder.Code != http.StatusOK {
+			// pass errors on as the gateway wrote them
+			for key, values := range recorder.Header() {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(recorder.Code)
+			//nolint:errcheck
+			w.Write(recorder.Body.Bytes())
+			return
+		}
+
+		var resp struct {
+			MimeType string `json:"mime_type"`
+			Image    []byte `json:"image"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+			http.Error(w, "failed to decode image: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", resp.MimeType)
+		w.Header().Set("Cache-Control", "no-store")
+		//nolint:errcheck
+		w.Write(resp.Image)
+	})
+}
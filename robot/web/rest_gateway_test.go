@@ -0,0 +1,58 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.viam.com/test"
+	"goji.io"
+	"goji.io/pat"
+)
+
+func TestCameraSnapshotHandler(t *testing.T) {
+	// stands in for the gateway, answering GetImage like it would
+	gateway := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/viam/api/v1/component/camera/remote:cam/image" {
+			http.Error(w, `{"code": 5, "message": "not found"}`, http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, `{"code": 16, "message": "unauthenticated"}`, http.StatusUnauthorized)
+			return
+		}
+		test.That(t, json.NewEncoder(w).Encode(map[string]interface{}{
+			"mime_type": r.URL.Query().Get("mime_type"),
+			"image":     []byte("image bytes"),
+		}), test.ShouldBeNil)
+	})
+	mux := goji.NewMux()
+	mux.Handle(pat.Get("/api/v1/component/camera/:name/snapshot"), cameraSnapshotHandler(gateway))
+
+	get := func(path string, authorized bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if authorized {
+			req.Header.Set("Authorization", "Bearer token")
+		}
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	resp := get("/api/v1/component/camera/remote:cam/snapshot", true)
+	test.That(t, resp.Code, test.ShouldEqual, http.StatusOK)
+	test.That(t, resp.Header().Get("Content-Type"), test.ShouldEqual, "image/jpeg")
+	test.That(t, resp.Body.String(), test.ShouldEqual, "image bytes")
+
+	resp = get("/api/v1/component/camera/remote:cam/snapshot?mime_type=image/png", true)
+	test.That(t, resp.Code, test.ShouldEqual, http.StatusOK)
+	test.That(t, resp.Header().Get("Content-Type"), test.ShouldEqual, "image/png")
+
+	// errors from the gateway are passed on
+	resp = get("/api/v1/component/camera/remote:cam/snapshot", false)
+	test.That(t, resp.Code, test.ShouldEqual, http.StatusUnauthorized)
+	resp = get("/api/v1/component/camera/other/snapshot", true)
+	test.That(t, resp.Code, test.ShouldEqual, http.StatusNotFound)
+	test.That(t, resp.Body.String(), test.ShouldContainSubstring, "not found")
+}
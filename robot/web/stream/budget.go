@@ -0,0 +1,160 @@
+package webstream
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/gostream"
+)
+
+// DefaultStreamPriority is the priority of streams which are not given one. A stream with twice the priority of
+// another gets twice its share of the bandwidth budget.
+const DefaultStreamPriority = 1
+
+const (
+	// minBudgetedFrameRate is the lowest frame rate streams are degraded to, so that every stream being viewed
+	// keeps updating however tight the budget.
+	minBudgetedFrameRate = 1.
+	// budgetInterval is how often the bandwidth budget is reallocated between streams, as viewers come and go
+	// and the size of frames changes with the scene.
+	budgetInterval = time.Second
+)
+
+// streamDemand is what a stream would send if it were not limited.
+type streamDemand struct {
+	priority int
+	// bitsPerSecond is what the stream sends to all of its viewers at its target frame rate.
+	bitsPerSecond float64
+}
+
+// allocateBandwidth splits a budget between streams in proportion to their priorities. Streams which need less
+// than their share get only what they need, and what they leave is split between the rest in the same way.
+func allocateBandwidth(budget float64, demands []streamDemand) []float64 {
+	allocations := make([]float64, len(demands))
+	unsatisfied := make([]int, 0, len(demands))
+	for i := range demands {
+		unsatisfied = append(unsatisfied, i)
+	}
+	remaining := budget
+	for len(unsatisfied) != 0 && remaining > 0 {
+		totalPriority := 0
+		for _, i := range unsatisfied {
+			totalPriority += demands[i].priority
+		}
+		share := func(i int) float64 {
+			return remaining * float64(demands[i].priority) / float64(totalPriority)
+		}
+
+		stillUnsatisfied := make([]int, 0, len(unsatisfied))
+		satisfied := 0.
+		for _, i := range unsatisfied {
+			if demands[i].bitsPerSecond <= share(i) {
+				allocations[i] = demands[i].bitsPerSecond
+				satisfied += allocations[i]
+				continue
+			}
+			stillUnsatisfied = append(stillUnsatisfied, i)
+		}
+		if len(stillUnsatisfied) == len(unsatisfied) {
+			// every stream wants more than its share, so each gets just that
+			for _, i := range unsatisfied {
+				allocations[i] = share(i)
+			}
+			break
+		}
+		remaining -= satisfied
+		unsatisfied = stillUnsatisfied
+	}
+	return allocations
+}
+
+// SetBandwidthBudget limits the total bitrate of video sent to all viewers of all streams, lowering the frame
+// rates of streams when there are more streams or viewers than fit. A budget of 0 removes the limit.
+func (ss *Server) SetBandwidthBudget(bitsPerSecond float64) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.bandwidthBudget = bitsPerSecond
+	if bitsPerSecond > 0 {
+		if ss.cancelBudget == nil && ss.isAlive {
+			ctx, cancel := context.WithCancel(context.Background())
+			ss.cancelBudget = cancel
+			ss.activeBackgroundWorkers.Add(1)
+			utils.ManagedGo(func() {
+				for utils.SelectContextOrWait(ctx, budgetInterval) {
+					ss.rebalanceBandwidth()
+				}
+			}, ss.activeBackgroundWorkers.Done)
+		}
+		return
+	}
+
+	if ss.cancelBudget != nil {
+		ss.cancelBudget()
+		ss.cancelBudget = nil
+	}
+	for _, name := range ss.streamNames {
+		if stream, ok := ss.nameToStreamState[name].Stream.(gostream.RateLimitedStream); ok {
+			stream.SetMaxFrameRate(0)
+		}
+	}
+}
+
+// SetStreamPriority sets the share of the bandwidth budget the named stream gets relative to others.
+func (ss *Server) SetStreamPriority(name string, priority int) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if priority < 1 {
+		priority = DefaultStreamPriority
+	}
+	ss.streamPriorities[name] = priority
+}
+
+// rebalanceBandwidth sets the frame rate of each stream being viewed to fit its share of the bandwidth budget.
+func (ss *Server) rebalanceBandwidth() {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	// every viewer is sent its own copy of a stream
+	viewers := map[string]int{}
+	for _, nameToPeerState := range ss.activePeerStreams {
+		for name := range nameToPeerState {
+			viewers[name]++
+		}
+	}
+
+	var demands []streamDemand
+	var streams []gostream.RateLimitedStream
+	for _, name := range ss.streamNames {
+		stream, ok := ss.nameToStreamState[name].Stream.(gostream.RateLimitedStream)
+		if !ok {
+			continue
+		}
+		frameSize := stream.AverageFrameSize()
+		if viewers[name] == 0 || frameSize == 0 {
+			stream.SetMaxFrameRate(0)
+			continue
+		}
+		priority, ok := ss.streamPriorities[name]
+		if !ok {
+			priority = DefaultStreamPriority
+		}
+		demands = append(demands, streamDemand{
+			priority:      priority,
+			bitsPerSecond: 8 * frameSize * float64(stream.TargetFrameRate()) * float64(viewers[name]),
+		})
+		streams = append(streams, stream)
+	}
+
+	for i, allocation := range allocateBandwidth(ss.bandwidthBudget, demands) {
+		if allocation >= demands[i].bitsPerSecond {
+			streams[i].SetMaxFrameRate(0)
+			continue
+		}
+		frameRate := float64(streams[i].TargetFrameRate()) * allocation / demands[i].bitsPerSecond
+		streams[i].SetMaxFrameRate(math.Max(frameRate, minBudgetedFrameRate))
+	}
+}
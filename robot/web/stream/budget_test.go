@@ -0,0 +1,47 @@
+package webstream
+
+import (
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestAllocateBandwidth(t *testing.T) {
+	// everything fits
+	allocations := allocateBandwidth(10, []streamDemand{
+		{priority: 1, bitsPerSecond: 3},
+		{priority: 1, bitsPerSecond: 4},
+	})
+	test.That(t, allocations, test.ShouldResemble, []float64{3, 4})
+
+	// equal priorities split evenly
+	allocations = allocateBandwidth(10, []streamDemand{
+		{priority: 1, bitsPerSecond: 8},
+		{priority: 1, bitsPerSecond: 8},
+	})
+	test.That(t, allocations, test.ShouldResemble, []float64{5, 5})
+
+	// what a small stream leaves over goes to the others
+	allocations = allocateBandwidth(10, []streamDemand{
+		{priority: 1, bitsPerSecond: 2},
+		{priority: 1, bitsPerSecond: 8},
+		{priority: 1, bitsPerSecond: 8},
+	})
+	test.That(t, allocations, test.ShouldResemble, []float64{2, 4, 4})
+
+	// higher priorities get larger shares
+	allocations = allocateBandwidth(12, []streamDemand{
+		{priority: 1, bitsPerSecond: 20},
+		{priority: 3, bitsPerSecond: 20},
+	})
+	test.That(t, allocations, test.ShouldResemble, []float64{3, 9})
+
+	// a high priority stream which needs less than its share leaves the rest to lower priorities
+	allocations = allocateBandwidth(12, []streamDemand{
+		{priority: 1, bitsPerSecond: 20},
+		{priority: 3, bitsPerSecond: 4},
+	})
+	test.That(t, allocations, test.ShouldResemble, []float64{8, 4})
+
+	test.That(t, allocateBandwidth(12, nil), test.ShouldBeEmpty)
+}
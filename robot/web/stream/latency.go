@@ -0,0 +1,84 @@
+package webstream
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pion/webrtc/v3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/gostream"
+)
+
+// SetLatencyInstrumentation starts or stops measuring the latency of the named stream's frames.
+func (ss *Server) SetLatencyInstrumentation(name string, enabled bool) error {
+	stream, err := ss.latencyInstrumentedStream(name)
+	if err != nil {
+		return err
+	}
+	stream.SetLatencyInstrumentation(enabled)
+	return nil
+}
+
+// Latency summarizes how long recent frames of the named stream spent in each stage from capture to its viewers,
+// keyed by the gostream.LatencyStage constants. The network stage is only known once viewers have reported their
+// round trip time, which WebRTC clients do every few seconds.
+func (ss *Server) Latency(name string) (map[string]gostream.LatencySummary, error) {
+	stream, err := ss.latencyInstrumentedStream(name)
+	if err != nil {
+		return nil, err
+	}
+	summaries := stream.Latency()
+	if len(summaries) == 0 {
+		return summaries, nil
+	}
+
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+	var networkLatencies []time.Duration
+	for pc, nameToPeerState := range ss.activePeerStreams {
+		ps, ok := nameToPeerState[name]
+		if !ok {
+			continue
+		}
+		networkLatencies = append(networkLatencies, viewerNetworkLatencies(pc, ps.senders)...)
+	}
+	if len(networkLatencies) != 0 {
+		summaries[gostream.LatencyStageNetwork] = gostream.SummarizeLatencies(networkLatencies)
+	}
+	return summaries, nil
+}
+
+func (ss *Server) latencyInstrumentedStream(name string) (gostream.LatencyInstrumentedStream, error) {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+	streamState, ok := ss.nameToStreamState[name]
+	if !ok {
+		return nil, fmt.Errorf("no stream for %q", name)
+	}
+	stream, ok := streamState.Stream.(gostream.LatencyInstrumentedStream)
+	if !ok {
+		return nil, errors.Errorf("stream %q cannot measure latency", name)
+	}
+	return stream, nil
+}
+
+// viewerNetworkLatencies estimates how long the tracks sent by senders take to reach the viewer at the other end
+// of a peer connection, as half the round trip times in the receiver reports it sends back.
+func viewerNetworkLatencies(pc *webrtc.PeerConnection, senders []*webrtc.RTPSender) []time.Duration {
+	ssrcs := map[webrtc.SSRC]bool{}
+	for _, sender := range senders {
+		for _, encoding := range sender.GetParameters().Encodings {
+			ssrcs[encoding.SSRC] = true
+		}
+	}
+	var latencies []time.Duration
+	for _, stats := range pc.GetStats() {
+		remoteStats, ok := stats.(webrtc.RemoteInboundRTPStreamStats)
+		if !ok || !ssrcs[remoteStats.SSRC] || remoteStats.RoundTripTime <= 0 {
+			continue
+		}
+		latencies = append(latencies, time.Duration(remoteStats.RoundTripTime/2*float64(time.Second)))
+	}
+	return latencies
+}
@@ -37,6 +37,12 @@ type Server struct {
 	activePeerStreams       map[*webrtc.PeerConnection]map[string]*peerState
 	activeBackgroundWorkers sync.WaitGroup
 	isAlive                 bool
+
+	// bandwidthBudget is the total bits per second video may be sent at, or 0 for no limit, and
+	// streamPriorities are the shares of it streams get.
+	bandwidthBudget  float64
+	streamPriorities map[string]int
+	cancelBudget     context.CancelFunc
 }
 
 // NewServer returns a server that will run on the given port and initially starts with the given
@@ -52,6 +58,7 @@ func NewServer(
 		nameToStreamState: map[string]*state.StreamState{},
 		activePeerStreams: map[*webrtc.PeerConnection]map[string]*peerState{},
 		isAlive:           true,
+		streamPriorities:  map[string]int{},
 	}
 
 	for _, stream := range streams {
@@ -284,6 +291,10 @@ func (ss *Server) RemoveStream(ctx context.Context, req *streampb.RemoveStreamRe
 func (ss *Server) Close() error {
 	ss.mu.Lock()
 	ss.isAlive = false
+	if ss.cancelBudget != nil {
+		ss.cancelBudget()
+		ss.cancelBudget = nil
+	}
 
 	var errs error
 	for _, name := range ss.streamNames {
@@ -19,6 +19,7 @@ import (
 	"go.viam.com/rdk/gostream"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/robot"
+	rutils "go.viam.com/rdk/utils"
 )
 
 var (
@@ -123,7 +124,14 @@ func (ss *StreamState) Close() error {
 
 // Internals
 
-const rtpBufferSize int = 512
+// rtpBufferSize is how many RTP packets a passthrough subscription buffers, fewer in slim builds.
+var rtpBufferSize = 512
+
+func init() {
+	if rutils.SlimBuild {
+		rtpBufferSize = 64
+	}
+}
 
 type streamSource uint8
 
@@ -0,0 +1,86 @@
+//go:build !no_cgo || android
+
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// streamLatency is how the latency of a stage is reported by the stream latency endpoint.
+type streamLatency struct {
+	Samples int     `json:"samples"`
+	MeanMs  float64 `json:"mean_ms"`
+	P50Ms   float64 `json:"p50_ms"`
+	P95Ms   float64 `json:"p95_ms"`
+	MaxMs   float64 `json:"max_ms"`
+}
+
+// handleStreamLatency reports how long recent frames of a camera stream spent in each stage from capture to its
+// viewers on GET, so that sluggish video can be blamed on the camera, the encoder or the network. The stages are
+// capture, queue, encode, send and network, and total_mean_ms is the sum of their means. Measuring is started and
+// stopped on POST, e.g.
+//
+//	curl -d stream=cam -d instrument=true http://localhost:8080/debug/stream_latency
+//	curl 'http://localhost:8080/debug/stream_latency?stream=cam'
+func (svc *webService) handleStreamLatency(w http.ResponseWriter, r *http.Request) {
+	svc.mu.Lock()
+	initialized := svc.streamInitialized()
+	svc.mu.Unlock()
+	if !initialized {
+		http.Error(w, "streaming is not running", http.StatusNotFound)
+		return
+	}
+	name := r.FormValue("stream")
+	if name == "" {
+		http.Error(w, "stream is required", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		enabled, err := strconv.ParseBool(r.FormValue("instrument"))
+		if err != nil {
+			http.Error(w, "instrument must be true or false", http.StatusBadRequest)
+			return
+		}
+		if err := svc.streamServer.Server.SetLatencyInstrumentation(name, enabled); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	summaries, err := svc.streamServer.Server.Latency(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	ms := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Millisecond)
+	}
+	stages := map[string]streamLatency{}
+	var totalMs float64
+	for stage, summary := range summaries {
+		stages[stage] = streamLatency{
+			Samples: summary.Samples,
+			MeanMs:  ms(summary.Mean),
+			P50Ms:   ms(summary.P50),
+			P95Ms:   ms(summary.P95),
+			MaxMs:   ms(summary.Max),
+		}
+		totalMs += ms(summary.Mean)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"stages":        stages,
+		"total_mean_ms": totalMs,
+	}); err != nil {
+		svc.logger.Debugw("failed to write stream latency", "error", err)
+	}
+}
@@ -0,0 +1,152 @@
+package web
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+)
+
+// serverTLSConfig returns the TLS config of the web server, or nil if it is not secure. Certificates and client
+// CAs given as files are read again whenever the files change, so that rotated certificates are served without a
+// restart. Without a certificate, one issued from the fleet CA is served. When client CAs are given, clients must
+// present a certificate issued by one of them. With a fleet CA, and no client CAs, clients must present a
+// certificate issued by the fleet CA.
+func serverTLSConfig(network config.NetworkConfig, logger logging.Logger) (*tls.Config, error) {
+	if network.TLSConfig == nil && network.TLSCertFile == "" && network.FleetCA == nil {
+		if network.TLSClientCAFile != "" {
+			return nil, errors.New("tls_client_ca_file requires the server to have a TLS certificate")
+		}
+		return nil, nil
+	}
+
+	var tlsConfig *tls.Config
+	if network.TLSConfig != nil {
+		tlsConfig = network.TLSConfig.Clone()
+	} else {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	// set up front, since the configs handed out per client would otherwise lack what the HTTP server adds
+	tlsConfig.NextProtos = []string{"h2", "http/1.1"}
+
+	files := &tlsFiles{certFile: network.TLSCertFile, keyFile: network.TLSKeyFile, logger: logger}
+	if network.TLSCertFile != "" {
+		if _, err := files.certificate(); err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = nil
+		tlsConfig.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return files.certificate()
+		}
+	} else if network.TLSConfig == nil {
+		issuer := config.NewFleetCertificateIssuer(network.FleetCA)
+		if _, err := issuer.Certificate(); err != nil {
+			return nil, err
+		}
+		tlsConfig.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return issuer.Certificate()
+		}
+	}
+	clientCAFile := network.TLSClientCAFile
+	if clientCAFile == "" && network.FleetCA != nil {
+		clientCAFile = network.FleetCA.CertFile
+	}
+	if clientCAFile != "" {
+		files.clientCAFile = clientCAFile
+		if _, err := files.clientCAs(); err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		baseConfig := tlsConfig.Clone()
+		tlsConfig.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			clientCAs, err := files.clientCAs()
+			if err != nil {
+				return nil, err
+			}
+			clientConfig := baseConfig.Clone()
+			clientConfig.ClientCAs = clientCAs
+			return clientConfig, nil
+		}
+	}
+	return tlsConfig, nil
+}
+
+// tlsFiles reads a certificate and client CAs from files, reading them again only once the files have changed.
+// If changed files cannot be read, such as while they are being rotated, what was last read is used.
+type tlsFiles struct {
+	certFile, keyFile, clientCAFile string
+	logger                          logging.Logger
+
+	mu            sync.Mutex
+	cert          *tls.Certificate
+	certModTime   time.Time
+	caPool        *x509.CertPool
+	caPoolModTime time.Time
+}
+
+func (f *tlsFiles) certificate() (*tls.Certificate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	modTime, changed := fileChanged(f.certModTime, f.certFile, f.keyFile)
+	if !changed {
+		return f.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(f.certFile, f.keyFile)
+	if err != nil {
+		if f.cert != nil {
+			f.logger.Warnw("failed to reload TLS certificate; using the previous one", "error", err)
+			return f.cert, nil
+		}
+		return nil, errors.Wrap(err, "failed to load TLS certificate")
+	}
+	if f.cert != nil {
+		f.logger.Infow("reloaded TLS certificate", "file", f.certFile)
+	}
+	f.cert, f.certModTime = &cert, modTime
+	return f.cert, nil
+}
+
+func (f *tlsFiles) clientCAs() (*x509.CertPool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	modTime, changed := fileChanged(f.caPoolModTime, f.clientCAFile)
+	if !changed {
+		return f.caPool, nil
+	}
+	pool, err := config.LoadCertPool(f.clientCAFile)
+	if err != nil {
+		err = errors.Wrap(err, "failed to read TLS client CAs")
+		if f.caPool != nil {
+			f.logger.Warnw("failed to reload TLS client CAs; using the previous ones", "error", err)
+			return f.caPool, nil
+		}
+		return nil, err
+	}
+	if f.caPool != nil {
+		f.logger.Infow("reloaded TLS client CAs", "file", f.clientCAFile)
+	}
+	f.caPool, f.caPoolModTime = pool, modTime
+	return f.caPool, nil
+}
+
+// fileChanged returns the latest modification time of the files, and whether it is after the last one seen.
+// Files which cannot be stat'd are reported as changed, so that the error comes from reading them.
+func fileChanged(lastModTime time.Time, paths ...string) (time.Time, bool) {
+	var modTime time.Time
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, true
+		}
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+	return modTime, !modTime.Equal(lastModTime)
+}
@@ -0,0 +1,170 @@
+package web
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+)
+
+// newTestCert returns a certificate for localhost, issued by parent or self-signed if parent is nil.
+func newTestCert(t *testing.T, name string, parent *tls.Certificate) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	test.That(t, err, test.ShouldBeNil)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IsCA:                  parent == nil,
+		BasicConstraintsValid: true,
+	}
+	issuer, issuerKey := template, interface{}(key)
+	if parent != nil {
+		issuer, issuerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, issuerKey)
+	test.That(t, err, test.ShouldBeNil)
+	leaf, err := x509.ParseCertificate(der)
+	test.That(t, err, test.ShouldBeNil)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+// writeTestCert writes the certificate and its key, and moves their modification time forward, since rotations
+// within a test can happen faster than the file system records.
+func writeTestCert(t *testing.T, cert tls.Certificate, certFile, keyFile string, modTime time.Time) {
+	t.Helper()
+	keyDER, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	test.That(t, err, test.ShouldBeNil)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})
+	test.That(t, os.WriteFile(certFile, certPEM, 0o600), test.ShouldBeNil)
+	test.That(t, os.Chtimes(certFile, modTime, modTime), test.ShouldBeNil)
+	if keyFile != "" {
+		keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+		test.That(t, os.WriteFile(keyFile, keyPEM, 0o600), test.ShouldBeNil)
+		test.That(t, os.Chtimes(keyFile, modTime, modTime), test.ShouldBeNil)
+	}
+}
+
+func TestServerTLSConfig(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	caFile := filepath.Join(dir, "ca.pem")
+	now := time.Now()
+
+	clientCA := newTestCert(t, "client-ca", nil)
+	writeTestCert(t, clientCA, caFile, "", now)
+	serverCert := newTestCert(t, "server", nil)
+	writeTestCert(t, serverCert, certFile, keyFile, now)
+
+	var network config.NetworkConfig
+	tlsConfig, err := serverTLSConfig(network, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, tlsConfig, test.ShouldBeNil)
+
+	network.TLSClientCAFile = caFile
+	_, err = serverTLSConfig(network, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	network.TLSCertFile = certFile
+	network.TLSKeyFile = keyFile
+	tlsConfig, err = serverTLSConfig(network, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	listener, err := tls.Listen("tcp", "localhost:0", tlsConfig)
+	test.That(t, err, test.ShouldBeNil)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			//nolint:errcheck
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	// dial returns the certificate the server presented to a client with the given certificate
+	dial := func(clientCerts ...tls.Certificate) (*x509.Certificate, error) {
+		conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: true, //nolint:gosec
+			Certificates:       clientCerts,
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+		// client certificates are checked after the client's side of the handshake, so read to see the verdict
+		test.That(t, conn.SetReadDeadline(time.Now().Add(time.Second)), test.ShouldBeNil)
+		if _, err := conn.Read(make([]byte, 1)); err != nil && !closedCleanly(err) {
+			return nil, err
+		}
+		return conn.ConnectionState().PeerCertificates[0], nil
+	}
+
+	// only clients with a certificate from the client CA may connect
+	_, err = dial()
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = dial(newTestCert(t, "stranger", nil))
+	test.That(t, err, test.ShouldNotBeNil)
+	client := newTestCert(t, "client", &clientCA)
+	presented, err := dial(client)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, presented.Subject.CommonName, test.ShouldEqual, "server")
+
+	// rotated certificates are served without a restart
+	writeTestCert(t, newTestCert(t, "rotated-server", nil), certFile, keyFile, now.Add(time.Minute))
+	presented, err = dial(client)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, presented.Subject.CommonName, test.ShouldEqual, "rotated-server")
+
+	// a rotated client CA no longer trusts clients of the old one
+	newClientCA := newTestCert(t, "new-client-ca", nil)
+	writeTestCert(t, newClientCA, caFile, "", now.Add(time.Minute))
+	_, err = dial(client)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = dial(newTestCert(t, "new-client", &newClientCA))
+	test.That(t, err, test.ShouldBeNil)
+
+	// a half written certificate is not served
+	test.That(t, os.WriteFile(certFile, []byte("garbage"), 0o600), test.ShouldBeNil)
+	test.That(t, os.Chtimes(certFile, now.Add(2*time.Minute), now.Add(2*time.Minute)), test.ShouldBeNil)
+	presented, err = dial(newTestCert(t, "new-client", &newClientCA))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, presented.Subject.CommonName, test.ShouldEqual, "rotated-server")
+}
+
+// closedCleanly returns whether a read error is from the server finishing with the connection, rather than it
+// rejecting the handshake.
+func closedCleanly(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, io.EOF)
+}
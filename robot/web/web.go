@@ -37,11 +37,14 @@ import (
 	"go.viam.com/rdk/config"
 	"go.viam.com/rdk/grpc"
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/metrics"
 	"go.viam.com/rdk/module"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/robot/diagnostics"
 	grpcserver "go.viam.com/rdk/robot/server"
 	weboptions "go.viam.com/rdk/robot/web/options"
+	"go.viam.com/rdk/tracing"
 	rutils "go.viam.com/rdk/utils"
 	"go.viam.com/rdk/web"
 )
@@ -222,6 +225,8 @@ func (svc *webService) Start(ctx context.Context, o weboptions.Options) error {
 }
 
 // RunWeb starts the web server on the robot with web options and blocks until we cancel the context.
+// The web server keeps running until the robot is closed, so that clients can still reach resources
+// while they prepare for shutdown.
 func RunWeb(ctx context.Context, r robot.LocalRobot, o weboptions.Options, logger logging.Logger) (err error) {
 	defer func() {
 		if err != nil {
@@ -232,7 +237,7 @@ func RunWeb(ctx context.Context, r robot.LocalRobot, o weboptions.Options, logge
 		}
 	}()
 
-	if err := r.StartWeb(ctx, o); err != nil {
+	if err := r.StartWeb(context.WithoutCancel(ctx), o); err != nil {
 		return err
 	}
 	<-ctx.Done()
@@ -305,12 +310,12 @@ func (svc *webService) StartModule(ctx context.Context) error {
 		streamInterceptors []googlegrpc.StreamServerInterceptor
 	)
 
-	unaryInterceptors = append(unaryInterceptors, grpc.EnsureTimeoutUnaryServerInterceptor)
+	unaryInterceptors = append(unaryInterceptors, grpc.EnsureTimeoutUnaryServerInterceptor, tracing.UnaryServerInterceptor)
 
 	opManager := svc.r.OperationManager()
 	unaryInterceptors = append(unaryInterceptors,
-		opManager.UnaryServerInterceptor, logging.UnaryServerInterceptor)
-	streamInterceptors = append(streamInterceptors, opManager.StreamServerInterceptor)
+		opManager.UnaryServerInterceptor, logging.UnaryServerInterceptor, svc.healthUnaryServerInterceptor)
+	streamInterceptors = append(streamInterceptors, tracing.StreamServerInterceptor, opManager.StreamServerInterceptor)
 	// TODO(PRODUCT-343): Add session manager interceptors
 
 	opts := []googlegrpc.ServerOption{
@@ -483,7 +488,7 @@ func (svc *webService) runWeb(ctx context.Context, options weboptions.Options) (
 		return errors.Errorf("expected *net.TCPAddr but got %T", listener.Addr())
 	}
 
-	options.Secure = options.Network.TLSConfig != nil || options.Network.TLSCertFile != ""
+	options.Secure = options.Network.TLSConfig != nil || options.Network.TLSCertFile != "" || options.Network.FleetCA != nil
 	if options.SignalingAddress == "" && !options.Secure {
 		options.SignalingDialOpts = append(options.SignalingDialOpts, rpc.WithInsecure())
 	}
@@ -518,6 +523,13 @@ func (svc *webService) runWeb(ctx context.Context, options weboptions.Options) (
 	); err != nil {
 		return err
 	}
+	if err := svc.rpcServer.RegisterServiceServer(
+		ctx,
+		&diagnostics.ServiceDesc,
+		&diagnosticsServer{svc: svc},
+	); err != nil {
+		return err
+	}
 
 	if err := svc.refreshResources(); err != nil {
 		return err
@@ -596,7 +608,8 @@ func (svc *webService) runWeb(ctx context.Context, options weboptions.Options) (
 		defer svc.webWorkers.Done()
 		var serveErr error
 		if options.Secure {
-			serveErr = httpServer.ServeTLS(listener, options.Network.TLSCertFile, options.Network.TLSKeyFile)
+			// the certificate is in httpServer.TLSConfig, which reloads it when it is rotated
+			serveErr = httpServer.ServeTLS(listener, "", "")
 		} else {
 			serveErr = httpServer.Serve(listener)
 		}
@@ -610,6 +623,7 @@ func (svc *webService) runWeb(ctx context.Context, options weboptions.Options) (
 // Initialize RPC Server options.
 func (svc *webService) initRPCOptions(listenerTCPAddr *net.TCPAddr, options weboptions.Options) ([]rpc.ServerOption, error) {
 	hosts := options.GetHosts(listenerTCPAddr)
+	webrtcConfig := options.Network.WebRTC.Configuration()
 	rpcOpts := []rpc.ServerOption{
 		rpc.WithAuthIssuer(options.FQDN),
 		rpc.WithAuthAudience(options.FQDN),
@@ -621,7 +635,7 @@ func (svc *webService) initRPCOptions(listenerTCPAddr *net.TCPAddr, options webo
 			ExternalSignalingAddress:  options.SignalingAddress,
 			ExternalSignalingHosts:    hosts.External,
 			InternalSignalingHosts:    hosts.Internal,
-			Config:                    &grpc.DefaultWebRTCConfiguration,
+			Config:                    &webrtcConfig,
 			OnPeerAdded:               options.WebRTCOnPeerAdded,
 			OnPeerRemoved:             options.WebRTCOnPeerRemoved,
 		}),
@@ -632,7 +646,7 @@ func (svc *webService) initRPCOptions(listenerTCPAddr *net.TCPAddr, options webo
 
 	var unaryInterceptors []googlegrpc.UnaryServerInterceptor
 
-	unaryInterceptors = append(unaryInterceptors, grpc.EnsureTimeoutUnaryServerInterceptor)
+	unaryInterceptors = append(unaryInterceptors, grpc.EnsureTimeoutUnaryServerInterceptor, tracing.UnaryServerInterceptor)
 
 	if options.Debug {
 		rpcOpts = append(rpcOpts, rpc.WithDebug())
@@ -659,7 +673,12 @@ func (svc *webService) initRPCOptions(listenerTCPAddr *net.TCPAddr, options webo
 	}
 	rpcOpts = append(rpcOpts, authOpts...)
 
-	var streamInterceptors []googlegrpc.StreamServerInterceptor
+	streamInterceptors := []googlegrpc.StreamServerInterceptor{tracing.StreamServerInterceptor}
+
+	// check roles before anything else is done on behalf of the caller
+	authz := newAuthorizer(options.Auth, svc.logger.Sublogger("audit"))
+	unaryInterceptors = append(unaryInterceptors, authz.unaryServerInterceptor)
+	streamInterceptors = append(streamInterceptors, authz.streamServerInterceptor)
 
 	opManager := svc.r.OperationManager()
 	sessManagerInts := svc.r.SessionManager().ServerInterceptors()
@@ -667,12 +686,14 @@ func (svc *webService) initRPCOptions(listenerTCPAddr *net.TCPAddr, options webo
 		unaryInterceptors = append(unaryInterceptors, sessManagerInts.UnaryServerInterceptor)
 	}
 	unaryInterceptors = append(unaryInterceptors,
-		opManager.UnaryServerInterceptor, logging.UnaryServerInterceptor)
+		opManager.UnaryServerInterceptor, logging.UnaryServerInterceptor, messageSizeUnaryServerInterceptor,
+		metrics.UnaryServerInterceptor, svc.healthUnaryServerInterceptor)
 
 	if sessManagerInts.StreamServerInterceptor != nil {
 		streamInterceptors = append(streamInterceptors, sessManagerInts.StreamServerInterceptor)
 	}
-	streamInterceptors = append(streamInterceptors, opManager.StreamServerInterceptor)
+	streamInterceptors = append(streamInterceptors,
+		opManager.StreamServerInterceptor, messageSizeStreamServerInterceptor, metrics.StreamServerInterceptor)
 
 	rpcOpts = append(
 		rpcOpts,
@@ -865,7 +886,10 @@ func (svc *webService) initHTTPServer(listenerTCPAddr *net.TCPAddr, options webo
 	if err != nil {
 		return httpServer, err
 	}
-	httpServer.TLSConfig = options.Network.TLSConfig.Clone()
+	httpServer.TLSConfig, err = serverTLSConfig(options.Network, svc.logger)
+	if err != nil {
+		return httpServer, err
+	}
 
 	return httpServer, nil
 }
@@ -877,18 +901,32 @@ func (svc *webService) initMux(options weboptions.Options) (*goji.Mux, error) {
 		return nil, err
 	}
 
+	// the debug endpoints bypass the roles RPCs are checked against, so with auth they are only served locally
+	debugHandleFunc := func(path string, h http.HandlerFunc) {
+		mux.HandleFunc(pat.New(path), localCallersOnly(options.Auth, h))
+	}
+
 	if options.Pprof {
-		mux.HandleFunc(pat.New("/debug/pprof/"), pprof.Index)
-		mux.HandleFunc(pat.New("/debug/pprof/cmdline"), pprof.Cmdline)
-		mux.HandleFunc(pat.New("/debug/pprof/profile"), pprof.Profile)
-		mux.HandleFunc(pat.New("/debug/pprof/symbol"), pprof.Symbol)
-		mux.HandleFunc(pat.New("/debug/pprof/trace"), pprof.Trace)
+		debugHandleFunc("/debug/pprof/", pprof.Index)
+		debugHandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		debugHandleFunc("/debug/pprof/profile", pprof.Profile)
+		debugHandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		debugHandleFunc("/debug/pprof/trace", pprof.Trace)
 	}
 
 	// serve resource graph visualization
 	// TODO: hide behind option
 	// TODO: accept params to display different formats
-	mux.HandleFunc(pat.New("/debug/graph"), svc.handleVisualizeResourceGraph)
+	debugHandleFunc("/debug/graph", svc.handleVisualizeResourceGraph)
+
+	// report whether every resource is ready, for readiness checks
+	debugHandleFunc("/debug/health", svc.handleHealth)
+
+	// measure how long camera stream frames take from capture to viewers
+	debugHandleFunc("/debug/stream_latency", svc.handleStreamLatency)
+
+	// serve robot internals to Prometheus scrapes
+	debugHandleFunc("/metrics", metrics.Handler().ServeHTTP)
 
 	prefix := "/viam"
 	addPrefix := func(h http.Handler) http.Handler {
@@ -911,7 +949,11 @@ func (svc *webService) initMux(options weboptions.Options) (*goji.Mux, error) {
 
 	// for urls with /api, add /viam to the path so that it matches with the paths defined in protobuf.
 	corsHandler := cors.AllowAll()
-	mux.Handle(pat.New("/api/*"), corsHandler.Handler(addPrefix(svc.rpcServer.GatewayHandler())))
+	if !options.Network.DisableRESTGateway {
+		gateway := svc.rpcServer.GatewayHandler()
+		mux.Handle(pat.Get("/api/v1/component/camera/:name/snapshot"), corsHandler.Handler(cameraSnapshotHandler(gateway)))
+		mux.Handle(pat.New("/api/*"), corsHandler.Handler(addPrefix(gateway)))
+	}
 	mux.Handle(pat.New("/*"), corsHandler.Handler(svc.rpcServer.GRPCHandler()))
 
 	return mux, nil
@@ -329,7 +329,13 @@ func (svc *webService) Reconfigure(ctx context.Context, deps resource.Dependenci
 	if !svc.isRunning {
 		return nil
 	}
-	return svc.addNewStreams(svc.cancelCtx)
+	if err := svc.addNewStreams(svc.cancelCtx); err != nil {
+		return err
+	}
+	if svc.streamInitialized() {
+		svc.refreshStreamPriorities()
+	}
+	return nil
 }
 
 func (svc *webService) closeStreamServer() {
@@ -358,9 +364,33 @@ func (svc *webService) initStreamServer(ctx context.Context, options *weboptions
 		// force WebRTC template rendering
 		options.WebRTC = true
 	}
+	svc.streamServer.Server.SetBandwidthBudget(1000 * float64(options.Network.StreamBandwidthBudgetKbps))
+	svc.refreshStreamPriorities()
 	return nil
 }
 
+// refreshStreamPriorities gives the stream of each camera the priority set by its stream_priority attribute,
+// which decides its share of the stream bandwidth budget.
+func (svc *webService) refreshStreamPriorities() {
+	cfg := svc.r.Config()
+	if cfg == nil {
+		return
+	}
+	for _, conf := range cfg.Components {
+		if conf.API != camera.API {
+			continue
+		}
+		priority := webstream.DefaultStreamPriority
+		switch value := conf.Attributes["stream_priority"].(type) {
+		case int:
+			priority = value
+		case float64:
+			priority = int(value)
+		}
+		svc.streamServer.Server.SetStreamPriority(conf.ResourceName().SDPTrackName(), priority)
+	}
+}
+
 type filterXML struct {
 	called bool
 	w      http.ResponseWriter
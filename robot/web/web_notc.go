@@ -4,6 +4,7 @@ package web
 
 import (
 	"context"
+	"net/http"
 	"sync"
 
 	"go.viam.com/rdk/logging"
@@ -69,3 +70,8 @@ func (svc *webService) initStreamServer(ctx context.Context, options *weboptions
 
 // stub for missing gostream
 type options struct{}
+
+// stub for missing gostream
+func (svc *webService) handleStreamLatency(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, "streaming is not supported on this build", http.StatusNotFound)
+}
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"slices"
 	"sync"
 	"time"
 
@@ -28,6 +29,7 @@ import (
 	"go.viam.com/rdk/services/datamanager/datacapture"
 	"go.viam.com/rdk/services/datamanager/datasync"
 	"go.viam.com/rdk/services/slam"
+	"go.viam.com/rdk/services/vision"
 	"go.viam.com/rdk/utils"
 )
 
@@ -40,6 +42,7 @@ func init() {
 			WeakDependencies: []resource.Matcher{
 				resource.TypeMatcher{Type: resource.APITypeComponentName},
 				resource.SubtypeMatcher{Subtype: slam.SubtypeName},
+				resource.SubtypeMatcher{Subtype: vision.SubtypeName},
 			},
 		})
 }
@@ -89,10 +92,18 @@ type Config struct {
 	MaximumNumSyncThreads       int      `json:"maximum_num_sync_threads"`
 	DeleteEveryNthWhenDiskFull  int      `json:"delete_every_nth_when_disk_full"`
 	MaximumCaptureFileSizeBytes int64    `json:"maximum_capture_file_size_bytes"`
+	// MaximumCaptureDirSizeBytes bounds the capture directory, deleting the oldest captured data once it is
+	// exceeded. By default data is only deleted once the disk is nearly full.
+	MaximumCaptureDirSizeBytes int64 `json:"maximum_capture_dir_size_bytes"`
+	// CaptureTriggers are conditions which capture methods can wait on, by name.
+	CaptureTriggers []CaptureTriggerConfig `json:"capture_triggers"`
 }
 
 // Validate returns components which will be depended upon weakly due to the above matcher.
 func (c *Config) Validate(path string) ([]string, error) {
+	if err := validateCaptureTriggers(path, c.CaptureTriggers); err != nil {
+		return nil, err
+	}
 	return []string{cloud.InternalServiceName.String()}, nil
 }
 
@@ -154,6 +165,9 @@ type builtIn struct {
 
 	componentMethodFrequencyHz map[resourceMethodMetadata]float32
 
+	triggersMu      sync.Mutex
+	captureTriggers map[string]*captureTrigger
+
 	fileDeletionRoutineCancelFn   context.CancelFunc
 	fileDeletionBackgroundWorkers *sync.WaitGroup
 
@@ -361,6 +375,15 @@ func (svc *builtIn) initializeOrUpdateCollector(
 		return nil, err
 	}
 
+	var transforms []datacapture.Transform
+	for _, transformConf := range config.Transforms {
+		transform, err := datacapture.NewTransform(transformConf.Type, transformConf.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		transforms = append(transforms, transform)
+	}
+
 	// Create a collector for this resource and method.
 	targetDir := datacapture.FilePathWithReplacedReservedChars(
 		filepath.Join(svc.captureDir, captureMetadata.GetComponentType(),
@@ -368,16 +391,26 @@ func (svc *builtIn) initializeOrUpdateCollector(
 	if err := os.MkdirAll(targetDir, 0o700); err != nil {
 		return nil, err
 	}
+	target := datacapture.NewBuffer(targetDir, captureMetadata, svc.maxCaptureFileSize)
+	if len(transforms) > 0 {
+		target.Transform = datacapture.ChainTransforms(transforms...)
+	}
 	params := data.CollectorParams{
 		ComponentName: config.Name.ShortName(),
 		Interval:      interval,
 		MethodParams:  methodParams,
-		Target:        datacapture.NewBuffer(targetDir, captureMetadata, svc.maxCaptureFileSize),
+		Target:        target,
 		QueueSize:     captureQueueSize,
 		BufferSize:    captureBufferSize,
 		Logger:        svc.logger,
 		Clock:         clock,
 	}
+	if config.Trigger != "" {
+		params.Trigger = svc.captureTriggerFunc(config.Trigger)
+	}
+	if config.MaxCapturesPerMinute > 0 {
+		params.MinCaptureSpacing = time.Duration(float64(time.Minute) / config.MaxCapturesPerMinute)
+	}
 	collector, err := (*collectorConstructor)(res, params)
 	if err != nil {
 		return nil, err
@@ -387,6 +420,48 @@ func (svc *builtIn) initializeOrUpdateCollector(
 	return &collectorAndConfig{res, collector, config}, nil
 }
 
+// reconfigureCaptureTriggers replaces the capture triggers. Collectors look their trigger up by name on
+// each capture, so need not be rebuilt when only their trigger changes.
+func (svc *builtIn) reconfigureCaptureTriggers(ctx context.Context, deps resource.Dependencies, confs []CaptureTriggerConfig) {
+	triggers := make(map[string]*captureTrigger, len(confs))
+	for _, conf := range confs {
+		trigger, err := newCaptureTrigger(deps, conf)
+		if err != nil {
+			svc.logger.CErrorw(ctx, "unable to initialize capture trigger; its methods will not capture until fixed",
+				"trigger", conf.Name, "error", err)
+			continue
+		}
+		triggers[conf.Name] = trigger
+	}
+	svc.triggersMu.Lock()
+	svc.captureTriggers = triggers
+	svc.triggersMu.Unlock()
+}
+
+func (svc *builtIn) captureTriggerFunc(name string) func(ctx context.Context) (bool, error) {
+	return func(ctx context.Context) (bool, error) {
+		svc.triggersMu.Lock()
+		trigger, ok := svc.captureTriggers[name]
+		svc.triggersMu.Unlock()
+		if !ok {
+			return false, errors.Errorf("capture trigger %q is not configured", name)
+		}
+		return trigger.fired(ctx)
+	}
+}
+
+func mergeTags(tags ...[]string) []string {
+	var merged []string
+	for _, ts := range tags {
+		for _, tag := range ts {
+			if !slices.Contains(merged, tag) {
+				merged = append(merged, tag)
+			}
+		}
+	}
+	return merged
+}
+
 func (svc *builtIn) closeSyncer() {
 	if svc.syncer != nil {
 		// If previously we were syncing, close the old syncer and cancel the old updateCollectors goroutine.
@@ -515,6 +590,8 @@ func (svc *builtIn) Reconfigure(
 		deleteEveryNthValue = svcConfig.DeleteEveryNthWhenDiskFull
 	}
 
+	svc.reconfigureCaptureTriggers(ctx, deps, svcConfig.CaptureTriggers)
+
 	// Initialize or add collectors based on changes to the component configurations.
 	newCollectors := make(map[resourceMethodMetadata]*collectorAndConfig)
 	if !svc.captureDisabled {
@@ -557,8 +634,8 @@ func (svc *builtIn) Reconfigure(
 					maxCaptureFileSize = defaultMaxCaptureSize
 				}
 				if !resConf.Disabled && (resConf.CaptureFrequencyHz > 0 || svc.maxCaptureFileSize != maxCaptureFileSize) {
-					// We only use service-level tags.
-					resConf.Tags = svcConfig.Tags
+					// Service-level tags apply to everything captured, alongside the method's own tags.
+					resConf.Tags = mergeTags(svcConfig.Tags, resConf.Tags)
 
 					maxFileSizeChanged := svc.maxCaptureFileSize != maxCaptureFileSize
 					svc.maxCaptureFileSize = maxCaptureFileSize
@@ -649,7 +726,7 @@ func (svc *builtIn) Reconfigure(
 		svc.fileDeletionBackgroundWorkers = &sync.WaitGroup{}
 		svc.fileDeletionBackgroundWorkers.Add(1)
 		go pollFilesystem(fileDeletionCtx, svc.fileDeletionBackgroundWorkers,
-			svc.captureDir, deleteEveryNthValue, svc.syncer, svc.logger)
+			svc.captureDir, deleteEveryNthValue, svcConfig.MaximumCaptureDirSizeBytes, svc.syncer, svc.logger)
 	}
 
 	return nil
@@ -826,7 +903,7 @@ func generateMetadataKey(component, method string) string {
 }
 
 func pollFilesystem(ctx context.Context, wg *sync.WaitGroup, captureDir string,
-	deleteEveryNth int, syncer datasync.Manager, logger logging.Logger,
+	deleteEveryNth int, maxCaptureDirSizeBytes int64, syncer datasync.Manager, logger logging.Logger,
 ) {
 	if runtime.GOOS == "android" {
 		logger.Debug("file deletion if disk is full is not currently supported on Android")
@@ -846,6 +923,15 @@ func pollFilesystem(ctx context.Context, wg *sync.WaitGroup, captureDir string,
 		case <-ctx.Done():
 			return
 		case <-t.C:
+			if maxCaptureDirSizeBytes > 0 {
+				deletedFileCount, err := deleteOldestFiles(ctx, syncer, maxCaptureDirSizeBytes, captureDir, logger)
+				if err != nil {
+					logger.Errorw("error deleting the oldest cached datacapture files", "error", err)
+				} else if deletedFileCount > 0 {
+					logger.Infof("%v files have been deleted to keep the capture directory under %d bytes",
+						deletedFileCount, maxCaptureDirSizeBytes)
+				}
+			}
 			logger.Debug("checking disk usage")
 			shouldDelete, err := shouldDeleteBasedOnDiskUsage(ctx, captureDir, logger)
 			if err != nil {
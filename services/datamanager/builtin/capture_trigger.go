@@ -0,0 +1,201 @@
+package builtin
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/vision"
+)
+
+// CaptureTriggerConfig describes a condition that captures can wait on, by naming the trigger in their
+// capture config. A trigger either watches one reading of a sensor, such as the linear_velocity of a
+// movement sensor, or watches for detections by a vision service.
+type CaptureTriggerConfig struct {
+	Name string `json:"name"`
+
+	// Sensor triggers when its Reading is above Above and/or below Below. Vector readings are compared
+	// by their magnitude, and boolean readings as 1 or 0.
+	Sensor  string   `json:"sensor,omitempty"`
+	Reading string   `json:"reading,omitempty"`
+	Above   *float64 `json:"above,omitempty"`
+	Below   *float64 `json:"below,omitempty"`
+
+	// VisionService triggers when it detects any of Labels, or anything at all if there are no labels,
+	// in the Camera with at least MinConfidence.
+	VisionService string   `json:"vision_service,omitempty"`
+	Camera        string   `json:"camera,omitempty"`
+	Labels        []string `json:"labels,omitempty"`
+	MinConfidence float64  `json:"min_confidence,omitempty"`
+
+	// HoldSecs keeps the trigger firing for this long after its condition last held, so that the end
+	// of an event is captured too.
+	HoldSecs float64 `json:"hold_secs,omitempty"`
+}
+
+func validateCaptureTriggers(path string, triggers []CaptureTriggerConfig) error {
+	names := map[string]bool{}
+	for _, trigger := range triggers {
+		if trigger.Name == "" {
+			return resource.NewConfigValidationFieldRequiredError(path, "capture_triggers.name")
+		}
+		if names[trigger.Name] {
+			return resource.NewConfigValidationError(path, errors.Errorf("capture trigger %q is configured twice", trigger.Name))
+		}
+		names[trigger.Name] = true
+
+		switch {
+		case trigger.Sensor != "" && trigger.VisionService != "":
+			return resource.NewConfigValidationError(path,
+				errors.Errorf("capture trigger %q must watch either a sensor or a vision service, not both", trigger.Name))
+		case trigger.Sensor != "":
+			if trigger.Reading == "" {
+				return resource.NewConfigValidationFieldRequiredError(path, "capture_triggers.reading")
+			}
+			if trigger.Above == nil && trigger.Below == nil {
+				return resource.NewConfigValidationError(path,
+					errors.Errorf("capture trigger %q must set above, below, or both", trigger.Name))
+			}
+		case trigger.VisionService != "":
+			if trigger.Camera == "" {
+				return resource.NewConfigValidationFieldRequiredError(path, "capture_triggers.camera")
+			}
+		default:
+			return resource.NewConfigValidationError(path,
+				errors.Errorf("capture trigger %q must watch a sensor or a vision service", trigger.Name))
+		}
+		if trigger.HoldSecs < 0 {
+			return resource.NewConfigValidationError(path, errors.Errorf("capture trigger %q has a negative hold_secs", trigger.Name))
+		}
+	}
+	return nil
+}
+
+type captureTrigger struct {
+	condition func(ctx context.Context) (bool, error)
+	hold      time.Duration
+
+	mu        sync.Mutex
+	lastFired time.Time
+}
+
+func newCaptureTrigger(deps resource.Dependencies, conf CaptureTriggerConfig) (*captureTrigger, error) {
+	t := &captureTrigger{hold: time.Duration(conf.HoldSecs * float64(time.Second))}
+	if conf.Sensor != "" {
+		s, err := sensorFromDependencies(deps, conf.Sensor)
+		if err != nil {
+			return nil, err
+		}
+		t.condition = func(ctx context.Context) (bool, error) {
+			readings, err := s.Readings(ctx, nil)
+			if err != nil {
+				return false, err
+			}
+			value, ok := readings[conf.Reading]
+			if !ok {
+				return false, errors.Errorf("%s has no reading %q", conf.Sensor, conf.Reading)
+			}
+			v, err := readingMagnitude(value)
+			if err != nil {
+				return false, errors.Wrapf(err, "reading %q of %s", conf.Reading, conf.Sensor)
+			}
+			return (conf.Above == nil || v > *conf.Above) && (conf.Below == nil || v < *conf.Below), nil
+		}
+		return t, nil
+	}
+
+	visionSvc, err := vision.FromDependencies(deps, conf.VisionService)
+	if err != nil {
+		return nil, err
+	}
+	t.condition = func(ctx context.Context) (bool, error) {
+		detections, err := visionSvc.DetectionsFromCamera(ctx, conf.Camera, nil)
+		if err != nil {
+			return false, err
+		}
+		for _, detection := range detections {
+			if detection.Score() < conf.MinConfidence {
+				continue
+			}
+			if len(conf.Labels) == 0 || slices.Contains(conf.Labels, detection.Label()) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return t, nil
+}
+
+// fired returns whether the trigger's condition holds now, or held within the hold time.
+func (t *captureTrigger) fired(ctx context.Context) (bool, error) {
+	holds, err := t.condition(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := clock.Now()
+	if holds {
+		t.lastFired = now
+		return true, nil
+	}
+	return !t.lastFired.IsZero() && now.Sub(t.lastFired) < t.hold, nil
+}
+
+// sensorFromDependencies returns the dependency with the given short name which has readings, whatever
+// its API is, since both sensors and movement sensors are useful to trigger on.
+func sensorFromDependencies(deps resource.Dependencies, name string) (resource.Sensor, error) {
+	for depName, dep := range deps {
+		if depName.ShortName() != name {
+			continue
+		}
+		if s, ok := dep.(resource.Sensor); ok {
+			return s, nil
+		}
+	}
+	return nil, errors.Errorf("no sensor or other resource with readings named %q", name)
+}
+
+func readingMagnitude(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case r3.Vector:
+		return v.Norm(), nil
+	case *r3.Vector:
+		return v.Norm(), nil
+	case map[string]interface{}:
+		// vectors read back from a remote arrive as their fields
+		var sumSquares float64
+		for _, axis := range []string{"x", "y", "z"} {
+			component, err := readingMagnitude(v[axis])
+			if err != nil {
+				return 0, err
+			}
+			sumSquares += component * component
+		}
+		return math.Sqrt(sumSquares), nil
+	default:
+		return 0, errors.Errorf("cannot compare a reading of type %T", value)
+	}
+}
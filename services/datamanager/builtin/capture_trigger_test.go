@@ -0,0 +1,137 @@
+package builtin
+
+import (
+	"context"
+	"image"
+	"testing"
+	"time"
+
+	clk "github.com/benbjohnson/clock"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/vision/objectdetection"
+)
+
+func TestValidateCaptureTriggers(t *testing.T) {
+	above := 0.5
+	for _, trigger := range []CaptureTriggerConfig{
+		{Sensor: "gps", Reading: "linear_velocity", Above: &above},
+		{Name: "moving", Reading: "linear_velocity", Above: &above},
+		{Name: "moving", Sensor: "gps", Above: &above},
+		{Name: "moving", Sensor: "gps", Reading: "linear_velocity"},
+		{Name: "moving", Sensor: "gps", Reading: "linear_velocity", Above: &above, VisionService: "detector"},
+		{Name: "person", VisionService: "detector"},
+		{Name: "person", VisionService: "detector", Camera: "cam", HoldSecs: -1},
+	} {
+		test.That(t, validateCaptureTriggers("path", []CaptureTriggerConfig{trigger}), test.ShouldNotBeNil)
+	}
+
+	moving := CaptureTriggerConfig{Name: "moving", Sensor: "gps", Reading: "linear_velocity", Above: &above}
+	person := CaptureTriggerConfig{Name: "person", VisionService: "detector", Camera: "cam"}
+	test.That(t, validateCaptureTriggers("path", []CaptureTriggerConfig{moving, person}), test.ShouldBeNil)
+	test.That(t, validateCaptureTriggers("path", []CaptureTriggerConfig{moving, moving}), test.ShouldNotBeNil)
+}
+
+func TestCaptureTriggers(t *testing.T) {
+	ctx := context.Background()
+	mockClock := clk.NewMock()
+	clock = mockClock
+
+	velocity := r3.Vector{}
+	gps := inject.NewMovementSensor("gps")
+	gps.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"linear_velocity": velocity}, nil
+	}
+	var detections []objectdetection.Detection
+	detector := inject.NewVisionService("detector")
+	detector.DetectionsFromCameraFunc = func(
+		ctx context.Context, cameraName string, extra map[string]interface{},
+	) ([]objectdetection.Detection, error) {
+		test.That(t, cameraName, test.ShouldEqual, "cam")
+		return detections, nil
+	}
+	deps := resource.Dependencies{
+		movementsensor.Named("gps"): gps,
+		vision.Named("detector"):    detector,
+	}
+
+	t.Run("sensor reading", func(t *testing.T) {
+		above := 0.5
+		trigger, err := newCaptureTrigger(deps, CaptureTriggerConfig{
+			Name: "moving", Sensor: "gps", Reading: "linear_velocity", Above: &above, HoldSecs: 2,
+		})
+		test.That(t, err, test.ShouldBeNil)
+
+		fired, err := trigger.fired(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, fired, test.ShouldBeFalse)
+
+		velocity = r3.Vector{X: 0.3, Y: 0.4, Z: 0.3}
+		fired, err = trigger.fired(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, fired, test.ShouldBeTrue)
+
+		// the trigger holds for a while after stopping
+		velocity = r3.Vector{}
+		mockClock.Add(time.Second)
+		fired, err = trigger.fired(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, fired, test.ShouldBeTrue)
+		mockClock.Add(time.Second)
+		fired, err = trigger.fired(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, fired, test.ShouldBeFalse)
+	})
+
+	t.Run("vision detections", func(t *testing.T) {
+		trigger, err := newCaptureTrigger(deps, CaptureTriggerConfig{
+			Name: "person", VisionService: "detector", Camera: "cam", Labels: []string{"person"}, MinConfidence: 0.6,
+		})
+		test.That(t, err, test.ShouldBeNil)
+
+		box := image.Rect(0, 0, 10, 10)
+		for _, tc := range []struct {
+			detections []objectdetection.Detection
+			fired      bool
+		}{
+			{nil, false},
+			{[]objectdetection.Detection{objectdetection.NewDetection(box, 0.9, "dog")}, false},
+			{[]objectdetection.Detection{objectdetection.NewDetection(box, 0.5, "person")}, false},
+			{[]objectdetection.Detection{
+				objectdetection.NewDetection(box, 0.9, "dog"),
+				objectdetection.NewDetection(box, 0.7, "person"),
+			}, true},
+		} {
+			detections = tc.detections
+			fired, err := trigger.fired(ctx)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, fired, test.ShouldEqual, tc.fired)
+		}
+	})
+
+	t.Run("missing resources", func(t *testing.T) {
+		above := 0.5
+		_, err := newCaptureTrigger(deps, CaptureTriggerConfig{
+			Name: "moving", Sensor: "odometry", Reading: "linear_velocity", Above: &above,
+		})
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = newCaptureTrigger(deps, CaptureTriggerConfig{Name: "person", VisionService: "other", Camera: "cam"})
+		test.That(t, err, test.ShouldNotBeNil)
+
+		svc := &builtIn{}
+		svc.reconfigureCaptureTriggers(ctx, deps, nil)
+		_, err = svc.captureTriggerFunc("moving")(ctx)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
+
+func TestMergeTags(t *testing.T) {
+	test.That(t, mergeTags(nil, nil), test.ShouldBeEmpty)
+	test.That(t, mergeTags([]string{"site-a", "robot"}, []string{"robot", "night"}),
+		test.ShouldResemble, []string{"site-a", "robot", "night"})
+}
@@ -7,7 +7,9 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/services/datamanager/datacapture"
@@ -142,3 +144,82 @@ func deleteFiles(ctx context.Context, syncer datasync.Manager, deleteEveryNth in
 	err := filepath.WalkDir(captureDirPath, fileDeletion)
 	return deletedFileCount, err
 }
+
+type captureFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// deleteOldestFiles deletes the oldest completed capture files until the capture directory holds no more than
+// maxCaptureDirSizeBytes, so that a robot which is offline for a long time keeps its most recent data rather than
+// filling its disk. Files which are being synced are skipped.
+func deleteOldestFiles(ctx context.Context, syncer datasync.Manager, maxCaptureDirSizeBytes int64,
+	captureDirPath string, logger logging.Logger,
+) (int, error) {
+	var dirSize int64
+	var completed []captureFile
+	readFiles := func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		fileInfo, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		dirSize += fileInfo.Size()
+		if filepath.Ext(path) == datacapture.FileExt {
+			completed = append(completed, captureFile{path: path, size: fileInfo.Size(), modTime: fileInfo.ModTime()})
+		}
+		return nil
+	}
+	if err := filepath.WalkDir(captureDirPath, readFiles); err != nil {
+		return 0, err
+	}
+	if dirSize <= maxCaptureDirSizeBytes {
+		return 0, nil
+	}
+
+	sort.Slice(completed, func(i, j int) bool {
+		return completed[i].modTime.Before(completed[j].modTime)
+	})
+	deletedFileCount := 0
+	for _, file := range completed {
+		if dirSize <= maxCaptureDirSizeBytes {
+			break
+		}
+		if ctx.Err() != nil {
+			return deletedFileCount, ctx.Err()
+		}
+		if syncer != nil && !syncer.MarkInProgress(file.path) {
+			logger.Debugw("Tried to mark file as in progress but lock already held", "file", file.path)
+			continue
+		}
+		if err := os.Remove(file.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			logger.Warnw("error deleting file", "error", err)
+			if syncer != nil {
+				syncer.UnmarkInProgress(file.path)
+			}
+			return deletedFileCount, err
+		}
+		dirSize -= file.size
+		deletedFileCount++
+	}
+	if dirSize > maxCaptureDirSizeBytes {
+		logger.Warnw("capture directory is still over its maximum size, since the rest of its files are in use",
+			"size_bytes", dirSize, "maximum_size_bytes", maxCaptureDirSizeBytes)
+	}
+	return deletedFileCount, nil
+}
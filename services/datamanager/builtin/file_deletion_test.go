@@ -181,6 +181,50 @@ func TestFileDeletion(t *testing.T) {
 	}
 }
 
+func TestDeleteOldestFiles(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	tempCaptureDir := t.TempDir()
+	// each file holds 24 bytes
+	filepaths := writeFiles(t, tempCaptureDir, []string{"old.capture", "middle.capture", "new.capture", "writing.prog"})
+	start := time.Now().Add(-time.Hour)
+	for i, name := range []string{"old.capture", "middle.capture", "new.capture", "writing.prog"} {
+		modTime := start.Add(time.Duration(i) * time.Minute)
+		test.That(t, os.Chtimes(filepaths[name], modTime, modTime), test.ShouldBeNil)
+	}
+
+	// under the limit, nothing is deleted
+	deletedFileCount, err := deleteOldestFiles(ctx, nil, 1000, tempCaptureDir, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deletedFileCount, test.ShouldEqual, 0)
+
+	// the oldest completed files go first
+	deletedFileCount, err = deleteOldestFiles(ctx, nil, 60, tempCaptureDir, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deletedFileCount, test.ShouldEqual, 2)
+	files := getFiles(t, tempCaptureDir)
+	test.That(t, files, test.ShouldNotContain, "old.capture")
+	test.That(t, files, test.ShouldNotContain, "middle.capture")
+	test.That(t, files, test.ShouldContain, "new.capture")
+
+	// files being written or synced are never deleted
+	mockClient := mockDataSyncServiceClient{
+		succesfulDCRequests: make(chan *v1.DataCaptureUploadRequest, 100),
+		failedDCRequests:    make(chan *v1.DataCaptureUploadRequest, 100),
+		fail:                &atomic.Bool{},
+	}
+	filesToSync := make(chan string)
+	defer close(filesToSync)
+	syncer, err := datasync.NewManager("rick astley", mockClient, logger, tempCaptureDir, datasync.MaxParallelSyncRoutines, filesToSync)
+	test.That(t, err, test.ShouldBeNil)
+	defer syncer.Close()
+	syncer.MarkInProgress(filepaths["new.capture"])
+	deletedFileCount, err = deleteOldestFiles(ctx, syncer, 1, tempCaptureDir, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deletedFileCount, test.ShouldEqual, 0)
+	test.That(t, getFiles(t, tempCaptureDir), test.ShouldHaveLength, 2)
+}
+
 func writeFiles(t *testing.T, dir string, filenames []string) map[string]string {
 	t.Helper()
 	fileContents := []byte("never gonna let you down")
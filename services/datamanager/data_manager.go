@@ -141,6 +141,20 @@ type DataCaptureConfig struct {
 	Disabled           bool              `json:"disabled"`
 	Tags               []string          `json:"tags,omitempty"`
 	CaptureDirectory   string            `json:"capture_directory"`
+	// Trigger names a capture trigger configured on the data manager. If set, data is only captured
+	// while the trigger fires.
+	Trigger string `json:"trigger,omitempty"`
+	// MaxCapturesPerMinute, if set, caps how often data is captured below CaptureFrequencyHz, so that a
+	// trigger can be checked often without storing everything captured while it fires.
+	MaxCapturesPerMinute float64 `json:"max_captures_per_minute,omitempty"`
+	// Transforms run in order on each capture file before it is synced.
+	Transforms []TransformConfig `json:"transforms,omitempty"`
+}
+
+// TransformConfig names a registered transform, such as downsample, redact, or aggregate, and its attributes.
+type TransformConfig struct {
+	Type       string                 `json:"type"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
 }
 
 // Equals checks if one capture config is equal to another.
@@ -153,7 +167,10 @@ func (c *DataCaptureConfig) Equals(other *DataCaptureConfig) bool {
 		c.Disabled == other.Disabled &&
 		slices.Compare(c.Tags, other.Tags) == 0 &&
 		reflect.DeepEqual(c.AdditionalParams, other.AdditionalParams) &&
-		c.CaptureDirectory == other.CaptureDirectory
+		c.CaptureDirectory == other.CaptureDirectory &&
+		c.Trigger == other.Trigger &&
+		c.MaxCapturesPerMinute == other.MaxCapturesPerMinute &&
+		reflect.DeepEqual(c.Transforms, other.Transforms)
 }
 
 // ShouldSyncKey is a special key we use within a modular sensor to pass a boolean
@@ -3,6 +3,8 @@ package datacapture
 import (
 	"sync"
 
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
 	v1 "go.viam.com/api/app/datasync/v1"
 )
 
@@ -20,6 +22,9 @@ type Buffer struct {
 	nextFile           *File
 	lock               sync.Mutex
 	maxCaptureFileSize int64
+
+	// Transform, if set, rewrites the readings of each file before it is marked complete.
+	Transform Transform
 }
 
 // NewBuffer returns a new Buffer.
@@ -48,10 +53,7 @@ func (b *Buffer) Write(item *v1.SensorData) error {
 		if err := binFile.WriteNext(item); err != nil {
 			return err
 		}
-		if err := binFile.Close(); err != nil {
-			return err
-		}
-		return nil
+		return b.closeFile(binFile)
 	}
 
 	if b.nextFile == nil {
@@ -61,7 +63,7 @@ func (b *Buffer) Write(item *v1.SensorData) error {
 		}
 		b.nextFile = nextFile
 	} else if b.nextFile.Size() > b.maxCaptureFileSize {
-		if err := b.nextFile.Close(); err != nil {
+		if err := b.closeFile(b.nextFile); err != nil {
 			return err
 		}
 		nextFile, err := NewFile(b.Directory, b.MetaData)
@@ -81,11 +83,42 @@ func (b *Buffer) Flush() error {
 	if b.nextFile == nil {
 		return nil
 	}
-	if err := b.nextFile.Close(); err != nil {
+	f := b.nextFile
+	b.nextFile = nil
+	return b.closeFile(f)
+}
+
+// closeFile marks f complete, first replacing it with a file of its transformed readings if b has a
+// Transform. If the transform fails, f is kept as it is rather than losing its data.
+func (b *Buffer) closeFile(f *File) error {
+	if b.Transform == nil {
+		return f.Close()
+	}
+	readings, err := SensorDataFromFile(f)
+	if err != nil {
+		return multierr.Combine(err, f.Close())
+	}
+	transformed, err := b.Transform(readings)
+	if err != nil {
+		return multierr.Combine(errors.Wrap(err, "failed to transform captured data"), f.Close())
+	}
+	if err := f.Delete(); err != nil {
+		return err
+	}
+	if len(transformed) == 0 {
+		return nil
+	}
+
+	out, err := NewFile(b.Directory, b.MetaData)
+	if err != nil {
 		return err
 	}
-	b.nextFile = nil
-	return nil
+	for _, reading := range transformed {
+		if err := out.WriteNext(reading); err != nil {
+			return multierr.Combine(err, out.Close())
+		}
+	}
+	return out.Close()
 }
 
 // Path returns the path to the directory containing the backing data capture files.
@@ -0,0 +1,260 @@
+package datacapture
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// A Transform rewrites the readings of a finished capture file before the file can be synced, such as
+// to sync a summary of the readings rather than all of them. A Transform is used for a single
+// collector, and sees its files in the order they were written, so it may keep state between calls.
+type Transform func(readings []*v1.SensorData) ([]*v1.SensorData, error)
+
+// A TransformConstructor builds a Transform from its configured attributes.
+type TransformConstructor func(attributes map[string]interface{}) (Transform, error)
+
+var (
+	transformRegistryMu sync.RWMutex
+	transformRegistry   = map[string]TransformConstructor{
+		"downsample": newDownsampleTransform,
+		"redact":     newRedactTransform,
+		"aggregate":  newAggregateTransform,
+	}
+)
+
+// RegisterTransform registers a transform under name, for capture configs to refer to.
+func RegisterTransform(name string, constructor TransformConstructor) {
+	transformRegistryMu.Lock()
+	defer transformRegistryMu.Unlock()
+	if _, old := transformRegistry[name]; old {
+		panic(errors.Errorf("trying to register two transforms named %q", name))
+	}
+	transformRegistry[name] = constructor
+}
+
+// NewTransform builds the transform registered under name.
+func NewTransform(name string, attributes map[string]interface{}) (Transform, error) {
+	transformRegistryMu.RLock()
+	constructor, ok := transformRegistry[name]
+	transformRegistryMu.RUnlock()
+	if !ok {
+		return nil, errors.Errorf("no transform named %q", name)
+	}
+	t, err := constructor(attributes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid attributes for transform %q", name)
+	}
+	return t, nil
+}
+
+// ChainTransforms returns a Transform that applies each of transforms in turn.
+func ChainTransforms(transforms ...Transform) Transform {
+	return func(readings []*v1.SensorData) ([]*v1.SensorData, error) {
+		var err error
+		for _, t := range transforms {
+			if len(readings) == 0 {
+				break
+			}
+			if readings, err = t(readings); err != nil {
+				return nil, err
+			}
+		}
+		return readings, nil
+	}
+}
+
+// newDownsampleTransform keeps one reading in every "every" readings, or the first reading in every
+// "interval_secs" seconds.
+func newDownsampleTransform(attributes map[string]interface{}) (Transform, error) {
+	every, hasEvery := attributes["every"].(float64)
+	intervalSecs, hasInterval := attributes["interval_secs"].(float64)
+	switch {
+	case hasEvery == hasInterval:
+		return nil, errors.New("must set exactly one of every and interval_secs")
+	case hasEvery && (every < 1 || every != math.Trunc(every)):
+		return nil, errors.New("every must be a positive integer")
+	case hasInterval && intervalSecs <= 0:
+		return nil, errors.New("interval_secs must be positive")
+	}
+
+	if hasEvery {
+		var seen int
+		return func(readings []*v1.SensorData) ([]*v1.SensorData, error) {
+			kept := readings[:0]
+			for _, reading := range readings {
+				if seen%int(every) == 0 {
+					kept = append(kept, reading)
+				}
+				seen++
+			}
+			return kept, nil
+		}, nil
+	}
+
+	interval := time.Duration(intervalSecs * float64(time.Second))
+	var lastKept time.Time
+	return func(readings []*v1.SensorData) ([]*v1.SensorData, error) {
+		kept := readings[:0]
+		for _, reading := range readings {
+			requested := reading.GetMetadata().GetTimeRequested().AsTime()
+			if lastKept.IsZero() || requested.Sub(lastKept) >= interval {
+				kept = append(kept, reading)
+				lastKept = requested
+			}
+		}
+		return kept, nil
+	}, nil
+}
+
+// newRedactTransform removes the "fields" of tabular readings, given as dotted paths such as
+// "readings.position".
+func newRedactTransform(attributes map[string]interface{}) (Transform, error) {
+	fields, ok := attributes["fields"].([]interface{})
+	if !ok || len(fields) == 0 {
+		return nil, errors.New("must list the fields to redact")
+	}
+	var paths [][]string
+	for _, field := range fields {
+		path, ok := field.(string)
+		if !ok || path == "" {
+			return nil, errors.Errorf("field %v is not a dotted path", field)
+		}
+		paths = append(paths, strings.Split(path, "."))
+	}
+
+	return func(readings []*v1.SensorData) ([]*v1.SensorData, error) {
+		for _, reading := range readings {
+			for _, path := range paths {
+				s := reading.GetStruct()
+				for _, key := range path[:len(path)-1] {
+					s = s.GetFields()[key].GetStructValue()
+				}
+				if s != nil {
+					delete(s.Fields, path[len(path)-1])
+				}
+			}
+		}
+		return readings, nil
+	}, nil
+}
+
+// newAggregateTransform replaces tabular readings with a summary of each of their numeric fields: its
+// count, min, max, and mean, keyed by its dotted path. Readings are summarized over every
+// "interval_secs" seconds, or over each file if it is not set. Binary readings are left as they are.
+func newAggregateTransform(attributes map[string]interface{}) (Transform, error) {
+	var interval time.Duration
+	if intervalSecs, ok := attributes["interval_secs"].(float64); ok {
+		if intervalSecs <= 0 {
+			return nil, errors.New("interval_secs must be positive")
+		}
+		interval = time.Duration(intervalSecs * float64(time.Second))
+	}
+
+	return func(readings []*v1.SensorData) ([]*v1.SensorData, error) {
+		var out, group []*v1.SensorData
+		var groupStart time.Time
+		flush := func() error {
+			if len(group) == 0 {
+				return nil
+			}
+			summary, err := summarize(group)
+			if err != nil {
+				return err
+			}
+			out = append(out, summary)
+			group = group[:0]
+			return nil
+		}
+		for _, reading := range readings {
+			if reading.GetBinary() != nil {
+				out = append(out, reading)
+				continue
+			}
+			requested := reading.GetMetadata().GetTimeRequested().AsTime()
+			if interval > 0 && len(group) > 0 && requested.Sub(groupStart) >= interval {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+			if len(group) == 0 {
+				groupStart = requested
+			}
+			group = append(group, reading)
+		}
+		if err := flush(); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}, nil
+}
+
+type fieldSummary struct {
+	count    int
+	min, max float64
+	sum      float64
+}
+
+func summarize(readings []*v1.SensorData) (*v1.SensorData, error) {
+	summaries := map[string]*fieldSummary{}
+	for _, reading := range readings {
+		addNumericFields(summaries, "", reading.GetStruct().GetFields())
+	}
+
+	paths := make([]string, 0, len(summaries))
+	for path := range summaries {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	fields := map[string]interface{}{"count": len(readings)}
+	for _, path := range paths {
+		s := summaries[path]
+		fields[path] = map[string]interface{}{
+			"count": s.count,
+			"min":   s.min,
+			"max":   s.max,
+			"mean":  s.sum / float64(s.count),
+		}
+	}
+	pbStruct, err := structpb.NewStruct(fields)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.SensorData{
+		Metadata: &v1.SensorMetadata{
+			TimeRequested: readings[0].GetMetadata().GetTimeRequested(),
+			TimeReceived:  readings[len(readings)-1].GetMetadata().GetTimeReceived(),
+		},
+		Data: &v1.SensorData_Struct{Struct: pbStruct},
+	}, nil
+}
+
+func addNumericFields(summaries map[string]*fieldSummary, prefix string, fields map[string]*structpb.Value) {
+	for key, value := range fields {
+		path := key
+		if prefix != "" {
+			path = fmt.Sprintf("%s.%s", prefix, key)
+		}
+		switch v := value.GetKind().(type) {
+		case *structpb.Value_StructValue:
+			addNumericFields(summaries, path, v.StructValue.GetFields())
+		case *structpb.Value_NumberValue:
+			s, ok := summaries[path]
+			if !ok {
+				s = &fieldSummary{min: math.Inf(1), max: math.Inf(-1)}
+				summaries[path] = s
+			}
+			s.count++
+			s.min = math.Min(s.min, v.NumberValue)
+			s.max = math.Max(s.max, v.NumberValue)
+			s.sum += v.NumberValue
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package datacapture
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func tabularReading(t *testing.T, at time.Time, fields map[string]interface{}) *v1.SensorData {
+	t.Helper()
+	pbStruct, err := structpb.NewStruct(fields)
+	test.That(t, err, test.ShouldBeNil)
+	return &v1.SensorData{
+		Metadata: &v1.SensorMetadata{TimeRequested: timestamppb.New(at), TimeReceived: timestamppb.New(at)},
+		Data:     &v1.SensorData_Struct{Struct: pbStruct},
+	}
+}
+
+func TestTransforms(t *testing.T) {
+	start := time.Now()
+	readings := func() []*v1.SensorData {
+		var ret []*v1.SensorData
+		for i := 0; i < 6; i++ {
+			ret = append(ret, tabularReading(t, start.Add(time.Duration(i)*time.Second), map[string]interface{}{
+				"readings": map[string]interface{}{"temperature": float64(20 + i), "location": "lab"},
+			}))
+		}
+		return ret
+	}
+
+	t.Run("invalid attributes", func(t *testing.T) {
+		for name, attributes := range map[string]map[string]interface{}{
+			"downsample": {},
+			"redact":     {"fields": []interface{}{}},
+			"aggregate":  {"interval_secs": -1.0},
+			"compress":   {},
+		} {
+			_, err := NewTransform(name, attributes)
+			test.That(t, err, test.ShouldNotBeNil)
+		}
+		_, err := NewTransform("downsample", map[string]interface{}{"every": 2.0, "interval_secs": 1.0})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("downsample", func(t *testing.T) {
+		every, err := NewTransform("downsample", map[string]interface{}{"every": 4.0})
+		test.That(t, err, test.ShouldBeNil)
+		kept, err := every(readings())
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, kept, test.ShouldHaveLength, 2)
+		// the count carries over from one file to the next
+		kept, err = every(readings())
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, kept, test.ShouldHaveLength, 1)
+
+		interval, err := NewTransform("downsample", map[string]interface{}{"interval_secs": 2.5})
+		test.That(t, err, test.ShouldBeNil)
+		kept, err = interval(readings())
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, kept, test.ShouldHaveLength, 2)
+		test.That(t, kept[1].GetMetadata().GetTimeRequested().AsTime(), test.ShouldEqual, start.Add(3*time.Second).UTC())
+	})
+
+	t.Run("redact and aggregate", func(t *testing.T) {
+		redact, err := NewTransform("redact", map[string]interface{}{"fields": []interface{}{"readings.location", "missing.field"}})
+		test.That(t, err, test.ShouldBeNil)
+		aggregate, err := NewTransform("aggregate", map[string]interface{}{"interval_secs": 4.0})
+		test.That(t, err, test.ShouldBeNil)
+
+		redacted, err := redact(readings())
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, redacted[0].GetStruct().AsMap(), test.ShouldResemble,
+			map[string]interface{}{"readings": map[string]interface{}{"temperature": 20.0}})
+
+		summaries, err := ChainTransforms(redact, aggregate)(readings())
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, summaries, test.ShouldHaveLength, 2)
+		test.That(t, summaries[0].GetStruct().AsMap(), test.ShouldResemble, map[string]interface{}{
+			"count":                4.0,
+			"readings.temperature": map[string]interface{}{"count": 4.0, "min": 20.0, "max": 23.0, "mean": 21.5},
+		})
+		test.That(t, summaries[1].GetMetadata().GetTimeReceived().AsTime(), test.ShouldEqual, start.Add(5*time.Second).UTC())
+	})
+}
+
+func TestBufferTransform(t *testing.T) {
+	tmpDir := t.TempDir()
+	buf := NewBuffer(tmpDir, &v1.DataCaptureMetadata{Type: v1.DataType_DATA_TYPE_TABULAR_SENSOR}, 1<<20)
+	var err error
+	buf.Transform, err = NewTransform("aggregate", nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	for i := 0; i < 10; i++ {
+		test.That(t, buf.Write(tabularReading(t, time.Now(), map[string]interface{}{"value": float64(i)})), test.ShouldBeNil)
+	}
+	test.That(t, buf.Flush(), test.ShouldBeNil)
+
+	files, err := os.ReadDir(tmpDir)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, files, test.ShouldHaveLength, 1)
+	test.That(t, filepath.Ext(files[0].Name()), test.ShouldEqual, FileExt)
+	sensorData, err := SensorDataFromFilePath(filepath.Join(tmpDir, files[0].Name()))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, sensorData, test.ShouldHaveLength, 1)
+	test.That(t, sensorData[0].GetStruct().AsMap()["value"], test.ShouldResemble,
+		map[string]interface{}{"count": 10.0, "min": 0.0, "max": 9.0, "mean": 4.5})
+}
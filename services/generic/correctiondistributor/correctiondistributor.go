@@ -0,0 +1,165 @@
+// Package correctiondistributor implements a generic service which keeps one connection to an NTRIP caster, or
+// another source of RTK corrections, and shares what it sends among every RTK GPS on the robot, so that a robot with
+// several antennas doesn't need a connection, and often a caster account, for each.
+//
+// The GPSes get their corrections from it with a correction_source such as
+//
+//	"correction_source": {"type": "distributor", "distributor": "corrections"}
+//
+// in place of their own ntrip_url. For mountpoints which need the robot's position, a Virtual Reference Station or
+// "auto" to use the nearest station, rover names a movement sensor to take it from.
+//
+// Sending it {"command": "get_status"} returns how many GPSes are subscribed, how many bytes of corrections have been
+// read, and how many chunks of them were dropped for GPSes which fell behind. {"get_sourcetable": true} lists the
+// caster's streams, as it does for the RTK GPSes themselves.
+package correctiondistributor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/components/movementsensor/gpsutils"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+)
+
+var model = resource.DefaultModelFamily.WithModel("rtk-correction-distributor")
+
+// Config is the config of the rtk-correction-distributor generic service.
+type Config struct {
+	NtripURL             string `json:"ntrip_url,omitempty"`
+	NtripConnectAttempts int    `json:"ntrip_connect_attempts,omitempty"`
+	NtripMountpoint      string `json:"ntrip_mountpoint,omitempty"`
+	NtripPass            string `json:"ntrip_password,omitempty"`
+	NtripUser            string `json:"ntrip_username,omitempty"`
+
+	// CorrectionSource is where the corrections come from, if not an NTRIP caster.
+	CorrectionSource *gpsutils.CorrectionSourceConfig `json:"correction_source,omitempty"`
+
+	// Rover is the movement sensor whose position is sent to the caster, for mountpoints which need it.
+	Rover string `json:"rover,omitempty"`
+}
+
+// Validate validates the rtk-correction-distributor service's configuration.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	var deps []string
+	if cfg.CorrectionSource != nil {
+		if cfg.NtripURL != "" {
+			return nil, resource.NewConfigValidationError(path,
+				errors.New("give either ntrip_url or a correction_source, not both"))
+		}
+		if cfg.CorrectionSource.Type == gpsutils.CorrectionSourceDistributor {
+			return nil, resource.NewConfigValidationError(path,
+				errors.New("correction_source cannot be another distributor"))
+		}
+		sourceDeps, err := cfg.CorrectionSource.Validate(fmt.Sprintf("%s.%s", path, "correction_source"))
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, sourceDeps...)
+	} else if cfg.NtripURL == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "ntrip_url")
+	}
+
+	if cfg.NtripMountpoint == gpsutils.AutoMountpoint && cfg.Rover == "" {
+		return nil, resource.NewConfigValidationError(path,
+			errors.New(`rover is required to pick the nearest station with ntrip_mountpoint "auto"`))
+	}
+	if cfg.Rover != "" {
+		deps = append(deps, cfg.Rover)
+	}
+	return deps, nil
+}
+
+func init() {
+	resource.RegisterService(
+		generic.API,
+		model,
+		resource.Registration[resource.Resource, *Config]{
+			Constructor: newCorrectionDistributor,
+		})
+}
+
+type correctionDistributor struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	rover       *gpsutils.CachedData
+	source      gpsutils.CorrectionSource
+	distributor *gpsutils.CorrectionDistributor
+	logger      logging.Logger
+}
+
+func newCorrectionDistributor(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (resource.Resource, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+
+	var ms movementsensor.MovementSensor
+	if newConf.Rover != "" {
+		if ms, err = movementsensor.FromDependencies(deps, newConf.Rover); err != nil {
+			return nil, err
+		}
+	}
+
+	c := &correctionDistributor{
+		Named:  conf.ResourceName().AsNamed(),
+		rover:  gpsutils.NewCachedData(newRoverReader(ms, logger), logger),
+		logger: logger,
+	}
+	if newConf.CorrectionSource != nil {
+		c.source, err = gpsutils.NewCorrectionSource(newConf.CorrectionSource, deps, logger)
+	} else {
+		c.source, err = gpsutils.NewNtripSource(&gpsutils.NtripConfig{
+			NtripURL:             newConf.NtripURL,
+			NtripUser:            newConf.NtripUser,
+			NtripPass:            newConf.NtripPass,
+			NtripMountpoint:      newConf.NtripMountpoint,
+			NtripConnectAttempts: newConf.NtripConnectAttempts,
+		}, c.rover, logger)
+	}
+	if err != nil {
+		return nil, multierr.Combine(err, c.rover.Close(ctx))
+	}
+
+	c.distributor = gpsutils.NewCorrectionDistributor(c.source, logger)
+	return c, nil
+}
+
+// SubscribeCorrections returns a source of everything the distributor reads from now on.
+func (c *correctionDistributor) SubscribeCorrections() gpsutils.CorrectionSource {
+	return c.distributor.SubscribeCorrections()
+}
+
+func (c *correctionDistributor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if _, ok := cmd["get_sourcetable"]; ok {
+		if ntrip, ok := c.source.(*gpsutils.NtripSource); ok {
+			return ntrip.DoCommand(ctx, cmd)
+		}
+		return nil, errors.New("the corrections don't come from an NTRIP caster")
+	}
+
+	name, _ := cmd["command"].(string)
+	switch name {
+	case "get_status":
+		return c.distributor.Stats(), nil
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
+
+// Close disconnects from the source of corrections, which ends every GPS's subscription.
+func (c *correctionDistributor) Close(ctx context.Context) error {
+	return multierr.Combine(c.distributor.Close(), c.rover.Close(ctx))
+}
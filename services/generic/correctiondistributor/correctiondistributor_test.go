@@ -0,0 +1,148 @@
+package correctiondistributor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/components/movementsensor/gpsutils"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestValidate(t *testing.T) {
+	conf := &Config{}
+	_, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "ntrip_url"))
+
+	conf.NtripURL = "http://fakeurl"
+	conf.NtripMountpoint = gpsutils.AutoMountpoint
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	conf.Rover = "gps"
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"gps"})
+
+	// a distributor can't take its corrections from another
+	conf = &Config{CorrectionSource: &gpsutils.CorrectionSourceConfig{
+		Type:        gpsutils.CorrectionSourceDistributor,
+		Distributor: "other",
+	}}
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	conf.CorrectionSource = &gpsutils.CorrectionSourceConfig{
+		Type:    gpsutils.CorrectionSourceTCP,
+		Address: "192.168.1.10:2101",
+	}
+	conf.NtripURL = "http://fakeurl"
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	conf.NtripURL = ""
+	deps, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldBeEmpty)
+}
+
+func TestDistribute(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	path := filepath.Join(t.TempDir(), "rtcm.bin")
+	test.That(t, os.WriteFile(path, []byte("corrections"), 0o600), test.ShouldBeNil)
+
+	svc, err := newCorrectionDistributor(ctx, nil, resource.Config{
+		Name: "corrections",
+		API:  generic.API,
+		ConvertedAttributes: &Config{CorrectionSource: &gpsutils.CorrectionSourceConfig{
+			Type:            gpsutils.CorrectionSourceFile,
+			FilePath:        path,
+			FileBytesPerSec: 1 << 20,
+		}},
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	// the GPSes find the service among their dependencies
+	deps := resource.Dependencies{generic.Named("corrections"): svc}
+	sourceConf := &gpsutils.CorrectionSourceConfig{Type: gpsutils.CorrectionSourceDistributor, Distributor: "corrections"}
+	var streams []io.Reader
+	for i := 0; i < 2; i++ {
+		source, err := gpsutils.NewCorrectionSource(sourceConf, deps, logger)
+		test.That(t, err, test.ShouldBeNil)
+		stream, err := source.Stream(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		streams = append(streams, stream)
+	}
+	for _, stream := range streams {
+		data := make([]byte, len("corrections"))
+		_, err = io.ReadFull(stream, data)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, string(data), test.ShouldEqual, "corrections")
+	}
+
+	status, err := svc.DoCommand(ctx, map[string]interface{}{"command": "get_status"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["subscribers"], test.ShouldEqual, 2)
+	_, err = svc.DoCommand(ctx, map[string]interface{}{"get_sourcetable": true})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// closing the service ends the GPSes' streams
+	test.That(t, svc.Close(ctx), test.ShouldBeNil)
+	for _, stream := range streams {
+		_, err = stream.Read(make([]byte, len("corrections")))
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+}
+
+func TestRoverGGA(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2024, 5, 1, 17, 28, 14, 0, time.UTC)
+
+	var position *geo.Point
+	var positionErr error
+	acc := movementsensor.UnimplementedOptionalAccuracies()
+	ms := inject.NewMovementSensor("gps")
+	ms.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+		return position, 18.9, positionErr
+	}
+	ms.AccuracyFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error) {
+		return acc, nil
+	}
+	r := &roverReader{ms: ms, logger: logging.NewTestLogger(t)}
+
+	// without a position, there's nothing to send
+	positionErr = errors.New("no fix")
+	_, ok := r.gga(ctx, at)
+	test.That(t, ok, test.ShouldBeFalse)
+	positionErr = nil
+	position = geo.NewPoint(0, 0)
+	_, ok = r.gga(ctx, at)
+	test.That(t, ok, test.ShouldBeFalse)
+
+	// a movement sensor which doesn't know its fix is taken to have one
+	position = geo.NewPoint(37.391098, -122.037826)
+	gga, ok := r.gga(ctx, at)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, gga, test.ShouldStartWith, "$GPGGA,172814.00,3723.46588,N,12202.26956,W,1,00,")
+
+	acc.NmeaFix = 4
+	gga, ok = r.gga(ctx, at)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, gga, test.ShouldStartWith, "$GPGGA,172814.00,3723.46588,N,12202.26956,W,4,00,")
+
+	acc.NmeaFix = 0
+	_, ok = r.gga(ctx, at)
+	test.That(t, ok, test.ShouldBeFalse)
+}
@@ -0,0 +1,94 @@
+package correctiondistributor
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/components/movementsensor/gpsutils"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/utils"
+)
+
+// roverInterval is how often the rover's position is read.
+const roverInterval = time.Second
+
+// roverReader turns a movement sensor's position into the GGA sentences a GPS would send, so that
+// the caster can be told where the robot is whichever GPS it has.
+type roverReader struct {
+	ms       movementsensor.MovementSensor
+	logger   logging.Logger
+	messages chan string
+	workers  utils.StoppableWorkers
+}
+
+// newRoverReader starts reading ms's position. If ms is nil, the reader never has a position.
+func newRoverReader(ms movementsensor.MovementSensor, logger logging.Logger) *roverReader {
+	r := &roverReader{ms: ms, logger: logger, messages: make(chan string, 1)}
+	if ms != nil {
+		r.workers = utils.NewStoppableWorkers(r.poll)
+	}
+	return r
+}
+
+func (r *roverReader) poll(ctx context.Context) {
+	ticker := time.NewTicker(roverInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		gga, ok := r.gga(ctx, time.Now())
+		if !ok {
+			continue
+		}
+		select {
+		case r.messages <- gga:
+		default:
+			// the last position hasn't been taken yet, so this one isn't needed
+		}
+	}
+}
+
+// gga returns a GGA sentence giving the rover's position at now, or false if it doesn't have one.
+func (r *roverReader) gga(ctx context.Context, now time.Time) (string, bool) {
+	position, alt, err := r.ms.Position(ctx, nil)
+	if err != nil {
+		r.logger.CDebugf(ctx, "can't get the rover's position: %s", err)
+		return "", false
+	}
+	if position == nil || movementsensor.IsZeroPosition(position) || movementsensor.IsPositionNaN(position) {
+		return "", false
+	}
+
+	// without the rover's accuracy, it's taken to have a plain fix
+	fix, hdop := 1, 0.0
+	if acc, err := r.ms.Accuracy(ctx, nil); err == nil && acc != nil {
+		if acc.NmeaFix == 0 {
+			return "", false
+		}
+		if acc.NmeaFix > 0 {
+			fix = int(acc.NmeaFix)
+		}
+		if !math.IsNaN(float64(acc.Hdop)) {
+			hdop = float64(acc.Hdop)
+		}
+	}
+	return strings.TrimSpace(string(gpsutils.MakeGGAMessage(position, alt, fix, 0, hdop, now))), true
+}
+
+func (r *roverReader) Messages() chan string {
+	return r.messages
+}
+
+func (r *roverReader) Close() error {
+	if r.workers != nil {
+		r.workers.Stop()
+	}
+	return nil
+}
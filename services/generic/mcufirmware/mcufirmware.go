@@ -0,0 +1,301 @@
+// Package mcufirmware implements a generic service which flashes firmware to microcontrollers attached to the
+// robot's computer over UART or USB, such as the ESP32s and Arduinos on sensor hubs, so that a fleet's firmware can
+// be updated remotely.
+//
+// Flashing is run with the asynchronous DoCommand protocol (see resource.AsyncCommands), since it takes minutes:
+//
+//	{"command": "flash", "device": "hub", "firmware_path": "/opt/fw/hub-1.2.bin", "sha256": "..."}
+//
+// returns an operation ID, which can be followed with "wait_for_operation" to stream its progress. Before writing,
+// the current firmware is read back into a backup, and if writing or verifying the new firmware fails, the backup
+// is written back. "list_devices" lists the configured devices, and which of them are being flashed.
+package mcufirmware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+)
+
+var model = resource.DefaultModelFamily.WithModel("mcu-firmware")
+
+// The fractions of a flash's progress taken up by backing up and verifying. Writing takes the rest.
+const (
+	backupProgress = 0.3
+	verifyProgress = 0.2
+)
+
+// DeviceConfig describes a microcontroller and how to flash it.
+type DeviceConfig struct {
+	Name       string `json:"name"`
+	SerialPath string `json:"serial_path"`
+	// Tool is "esptool" for ESP32s and ESP8266s, "avrdude" for AVR based Arduinos, or "bossac" for SAMD and SAM
+	// based ones.
+	Tool string `json:"tool"`
+	// ToolPath is where to find the tool, if it is not on the PATH.
+	ToolPath string `json:"tool_path,omitempty"`
+	// Chip is the chip type passed to esptool (default esp32), or the part passed to avrdude (such as atmega328p),
+	// which is required.
+	Chip string `json:"chip,omitempty"`
+	// Programmer is avrdude's programmer, which defaults to arduino.
+	Programmer string `json:"programmer,omitempty"`
+	BaudRate   int    `json:"baud_rate,omitempty"`
+	// FlashAddress is where esptool writes the firmware, which defaults to 0x0 for a merged image.
+	FlashAddress string `json:"flash_address,omitempty"`
+	// SkipBackup disables reading back the current firmware before flashing, which makes flashing quicker, but
+	// leaves nothing to roll back to.
+	SkipBackup bool `json:"skip_backup,omitempty"`
+}
+
+// Config is the config of the mcu-firmware generic service.
+type Config struct {
+	Devices []DeviceConfig `json:"devices"`
+	// BackupDir is where backups of the firmware being replaced are kept. It defaults to a temporary directory.
+	BackupDir string `json:"backup_dir,omitempty"`
+}
+
+// Validate validates the mcu-firmware service's configuration.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	if len(cfg.Devices) == 0 {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "devices")
+	}
+	names := map[string]bool{}
+	for i, dev := range cfg.Devices {
+		devPath := fmt.Sprintf("%s.devices.%d", path, i)
+		if dev.Name == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(devPath, "name")
+		}
+		if names[dev.Name] {
+			return nil, resource.NewConfigValidationError(devPath, fmt.Errorf("duplicate device name %q", dev.Name))
+		}
+		names[dev.Name] = true
+		if dev.SerialPath == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(devPath, "serial_path")
+		}
+		if !slices.Contains(tools, dev.Tool) {
+			return nil, resource.NewConfigValidationError(devPath, fmt.Errorf("tool must be one of %v", tools))
+		}
+		if dev.Tool == toolAvrdude && dev.Chip == "" {
+			return nil, resource.NewConfigValidationFieldRequiredError(devPath, "chip")
+		}
+		if dev.BaudRate < 0 {
+			return nil, resource.NewConfigValidationError(devPath, errors.New("baud_rate cannot be negative"))
+		}
+	}
+	return nil, nil
+}
+
+func init() {
+	resource.RegisterService(
+		generic.API,
+		model,
+		resource.Registration[resource.Resource, *Config]{
+			Constructor: newFirmwareService,
+		})
+}
+
+type firmwareService struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	devices   []DeviceConfig
+	backupDir string
+	runTool   toolRunner
+	async     *resource.AsyncCommands
+
+	mu       sync.Mutex
+	flashing map[string]string // device name to operation ID
+
+	logger logging.Logger
+}
+
+func newFirmwareService(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (resource.Resource, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	return makeFirmwareService(conf.ResourceName(), newConf, runTool, logger)
+}
+
+func makeFirmwareService(name resource.Name, conf *Config, run toolRunner, logger logging.Logger) (*firmwareService, error) {
+	backupDir := conf.BackupDir
+	if backupDir == "" {
+		backupDir = filepath.Join(os.TempDir(), "viam-mcu-firmware")
+	}
+	if err := os.MkdirAll(backupDir, 0o700); err != nil {
+		return nil, err
+	}
+	svc := &firmwareService{
+		Named:     name.AsNamed(),
+		devices:   conf.Devices,
+		backupDir: backupDir,
+		runTool:   run,
+		async:     resource.NewAsyncCommands(),
+		flashing:  map[string]string{},
+		logger:    logger,
+	}
+	return svc, nil
+}
+
+func (svc *firmwareService) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if resp, handled, err := svc.async.DoCommand(ctx, cmd); handled {
+		return resp, err
+	}
+	name, _ := cmd["command"].(string)
+	switch name {
+	case "flash":
+		return svc.startFlash(cmd)
+	case "list_devices":
+		svc.mu.Lock()
+		defer svc.mu.Unlock()
+		devices := []interface{}{}
+		for _, dev := range svc.devices {
+			info := map[string]interface{}{
+				"name":        dev.Name,
+				"serial_path": dev.SerialPath,
+				"tool":        dev.Tool,
+			}
+			if id, ok := svc.flashing[dev.Name]; ok {
+				info[resource.AsyncOperationIDKey] = id
+			}
+			devices = append(devices, info)
+		}
+		return map[string]interface{}{"devices": devices}, nil
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
+
+func (svc *firmwareService) startFlash(cmd map[string]interface{}) (map[string]interface{}, error) {
+	deviceName, _ := cmd["device"].(string)
+	i := slices.IndexFunc(svc.devices, func(dev DeviceConfig) bool { return dev.Name == deviceName })
+	if i < 0 {
+		return nil, fmt.Errorf("no device named %q", deviceName)
+	}
+	dev := svc.devices[i]
+	firmwarePath, _ := cmd["firmware_path"].(string)
+	if firmwarePath == "" {
+		return nil, errors.New("flash needs a firmware_path")
+	}
+	if _, err := os.Stat(firmwarePath); err != nil {
+		return nil, err
+	}
+	checksum, _ := cmd["sha256"].(string)
+
+	// two tools fighting over one serial port would leave the device in an unknown state
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if id, ok := svc.flashing[dev.Name]; ok {
+		return nil, fmt.Errorf("%s is already being flashed by operation %s", dev.Name, id)
+	}
+	resp := svc.async.Start("flash", func(ctx context.Context, progress resource.ProgressFunc) (map[string]interface{}, error) {
+		defer func() {
+			svc.mu.Lock()
+			delete(svc.flashing, dev.Name)
+			svc.mu.Unlock()
+		}()
+		return svc.flash(ctx, dev, firmwarePath, checksum, progress)
+	})
+	svc.flashing[dev.Name], _ = resp[resource.AsyncOperationIDKey].(string)
+	return resp, nil
+}
+
+// flash writes the firmware at firmwarePath to dev, rolling back to what was there before if it cannot be verified.
+func (svc *firmwareService) flash(
+	ctx context.Context,
+	dev DeviceConfig,
+	firmwarePath, checksum string,
+	progress resource.ProgressFunc,
+) (map[string]interface{}, error) {
+	if checksum != "" {
+		if err := checkSHA256(firmwarePath, checksum); err != nil {
+			return nil, err
+		}
+	}
+	commands := commandsFor(dev)
+
+	// run runs one stage of the flash, whose progress runs from start to start+size of the whole.
+	run := func(message string, args []string, start, size float64) error {
+		progress(start, message)
+		svc.logger.CInfow(ctx, message, "device", dev.Name, "command", commands.program, "args", args)
+		return svc.runTool(ctx, commands.program, args, func(line string) {
+			svc.logger.CDebugw(ctx, line, "device", dev.Name)
+			if fraction, ok := parseProgress(line); ok {
+				progress(start+fraction*size, message)
+			}
+		})
+	}
+
+	var backupPath string
+	writeStart := 0.
+	if !dev.SkipBackup {
+		backupPath = filepath.Join(svc.backupDir, commands.backupFile)
+		if err := run("backing up current firmware", commands.backup(backupPath), 0, backupProgress); err != nil {
+			return nil, errors.Wrap(err, "failed to back up current firmware, so not flashing")
+		}
+		writeStart = backupProgress
+	}
+	writeSize := 1 - verifyProgress - writeStart
+
+	err := run("writing firmware", commands.write(firmwarePath, false), writeStart, writeSize)
+	if err == nil {
+		err = run("verifying firmware", commands.verify(firmwarePath, false), 1-verifyProgress, verifyProgress)
+	}
+	if err == nil {
+		return map[string]interface{}{"device": dev.Name, "firmware_path": firmwarePath}, nil
+	}
+
+	if backupPath == "" || ctx.Err() != nil {
+		return nil, errors.Wrap(err, "failed to flash firmware, and the device may need to be reflashed")
+	}
+	svc.logger.CWarnw(ctx, "failed to flash firmware, rolling back", "device", dev.Name, "error", err)
+	rollbackErr := run("rolling back to previous firmware", commands.write(backupPath, true), writeStart, writeSize)
+	if rollbackErr == nil {
+		rollbackErr = run("verifying previous firmware", commands.verify(backupPath, true), 1-verifyProgress, verifyProgress)
+	}
+	if rollbackErr != nil {
+		return nil, errors.Wrapf(err, "failed to flash firmware, then failed to roll back from %s: %v", backupPath, rollbackErr)
+	}
+	return nil, errors.Wrap(err, "failed to flash firmware, so rolled back to the previous firmware")
+}
+
+func checkSHA256(path, expected string) error {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return err
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+		return fmt.Errorf("firmware at %s has sha256 %s, not %s", path, actual, expected)
+	}
+	return nil
+}
+
+// Close cancels any flashes in progress.
+func (svc *firmwareService) Close(ctx context.Context) error {
+	svc.async.Close()
+	return nil
+}
@@ -0,0 +1,191 @@
+package mcufirmware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+)
+
+// fakeTool records the commands it is asked to run, and fails the ones containing failOn.
+type fakeTool struct {
+	mu       sync.Mutex
+	commands []string
+	failOn   []string
+}
+
+func (ft *fakeTool) run(ctx context.Context, program string, args []string, output func(line string)) error {
+	command := program + " " + strings.Join(args, " ")
+	ft.mu.Lock()
+	ft.commands = append(ft.commands, command)
+	ft.mu.Unlock()
+	output("Writing at 0x00010000... (50 %)")
+	for _, fail := range ft.failOn {
+		if strings.Contains(command, fail) {
+			return errors.New("verify failed")
+		}
+	}
+	return nil
+}
+
+func setUp(t *testing.T, ft *fakeTool) (*firmwareService, string) {
+	t.Helper()
+	dir := t.TempDir()
+	firmwarePath := filepath.Join(dir, "hub.bin")
+	test.That(t, os.WriteFile(firmwarePath, []byte("firmware"), 0o600), test.ShouldBeNil)
+
+	svc, err := makeFirmwareService(generic.Named("firmware"), &Config{
+		Devices: []DeviceConfig{
+			{Name: "hub", SerialPath: "/dev/ttyUSB0", Tool: toolEsptool, FlashAddress: "0x10000"},
+			{Name: "uno", SerialPath: "/dev/ttyACM0", Tool: toolAvrdude, Chip: "atmega328p", SkipBackup: true},
+		},
+		BackupDir: filepath.Join(dir, "backups"),
+	}, ft.run, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	t.Cleanup(func() {
+		test.That(t, svc.Close(context.Background()), test.ShouldBeNil)
+	})
+	return svc, firmwarePath
+}
+
+func TestValidate(t *testing.T) {
+	conf := &Config{}
+	_, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "devices"))
+
+	conf.Devices = []DeviceConfig{{Name: "hub", SerialPath: "/dev/ttyUSB0", Tool: toolEsptool}}
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+
+	conf.Devices[0].Tool = "dfu-util"
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	conf.Devices[0].Tool = toolAvrdude
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path.devices.0", "chip"))
+
+	conf.Devices[0].Chip = "atmega328p"
+	conf.Devices = append(conf.Devices, conf.Devices[0])
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestFlash(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		ft := &fakeTool{}
+		svc, firmwarePath := setUp(t, ft)
+		sum := sha256.Sum256([]byte("firmware"))
+		started, err := svc.DoCommand(ctx, map[string]interface{}{
+			"command":       "flash",
+			"device":        "hub",
+			"firmware_path": firmwarePath,
+			"sha256":        hex.EncodeToString(sum[:]),
+		})
+		test.That(t, err, test.ShouldBeNil)
+
+		var progress []float64
+		result, err := resource.WaitForAsyncCommand(ctx, svc, started, func(fraction float64, message string) {
+			progress = append(progress, fraction)
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, result["device"], test.ShouldEqual, "hub")
+		test.That(t, progress[len(progress)-1], test.ShouldEqual, 1.)
+
+		backup := filepath.Join(svc.backupDir, "hub.bin")
+		common := "esptool.py --chip esp32 --port /dev/ttyUSB0 --baud 115200 "
+		test.That(t, ft.commands, test.ShouldResemble, []string{
+			common + "read_flash 0x0 ALL " + backup,
+			common + "write_flash 0x10000 " + firmwarePath,
+			common + "verify_flash 0x10000 " + firmwarePath,
+		})
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		ft := &fakeTool{failOn: []string{"verify_flash 0x10000"}}
+		svc, firmwarePath := setUp(t, ft)
+		started, err := svc.DoCommand(ctx, map[string]interface{}{
+			"command":       "flash",
+			"device":        "hub",
+			"firmware_path": firmwarePath,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		_, err = resource.WaitForAsyncCommand(ctx, svc, started, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "rolled back")
+
+		// the backup holds the whole flash, so goes back where it came from
+		backup := filepath.Join(svc.backupDir, "hub.bin")
+		test.That(t, ft.commands[3:], test.ShouldResemble, []string{
+			"esptool.py --chip esp32 --port /dev/ttyUSB0 --baud 115200 write_flash 0x0 " + backup,
+			"esptool.py --chip esp32 --port /dev/ttyUSB0 --baud 115200 verify_flash 0x0 " + backup,
+		})
+	})
+
+	t.Run("no backup", func(t *testing.T) {
+		ft := &fakeTool{failOn: []string{"flash:v"}}
+		svc, firmwarePath := setUp(t, ft)
+		started, err := svc.DoCommand(ctx, map[string]interface{}{
+			"command":       "flash",
+			"device":        "uno",
+			"firmware_path": firmwarePath,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		_, err = resource.WaitForAsyncCommand(ctx, svc, started, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "may need to be reflashed")
+
+		common := "avrdude -p atmega328p -c arduino -P /dev/ttyACM0 -b 115200 "
+		test.That(t, ft.commands, test.ShouldResemble, []string{
+			common + "-D -V -U flash:w:" + firmwarePath + ":a",
+			common + "-U flash:v:" + firmwarePath + ":a",
+		})
+	})
+
+	t.Run("bad requests", func(t *testing.T) {
+		ft := &fakeTool{}
+		svc, firmwarePath := setUp(t, ft)
+		_, err := svc.DoCommand(ctx, map[string]interface{}{"command": "flash", "device": "nope", "firmware_path": firmwarePath})
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = svc.DoCommand(ctx, map[string]interface{}{"command": "flash", "device": "hub", "firmware_path": firmwarePath + "x"})
+		test.That(t, err, test.ShouldNotBeNil)
+
+		started, err := svc.DoCommand(ctx, map[string]interface{}{
+			"command":       "flash",
+			"device":        "hub",
+			"firmware_path": firmwarePath,
+			"sha256":        "00",
+		})
+		test.That(t, err, test.ShouldBeNil)
+		_, err = resource.WaitForAsyncCommand(ctx, svc, started, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, ft.commands, test.ShouldBeEmpty)
+	})
+}
+
+func TestParseProgress(t *testing.T) {
+	for line, expected := range map[string]float64{
+		"Writing at 0x00010000... (25 %)":                        0.25,
+		"Writing | ################################ | 64% 0.32s": 0.64,
+		"[==============================] 100% (128/128 pages)":  1,
+	} {
+		fraction, ok := parseProgress(line)
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, fraction, test.ShouldAlmostEqual, expected)
+	}
+	_, ok := parseProgress("Connecting....")
+	test.That(t, ok, test.ShouldBeFalse)
+}
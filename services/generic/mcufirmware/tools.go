@@ -0,0 +1,182 @@
+package mcufirmware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The flashing tools we know how to drive.
+const (
+	toolEsptool   = "esptool"
+	toolAvrdude   = "avrdude"
+	toolBossac    = "bossac"
+	defaultBaud   = 115200
+	esptoolChip   = "esp32"
+	avrProgrammer = "arduino"
+)
+
+var tools = []string{toolEsptool, toolAvrdude, toolBossac}
+
+// toolCommands are the command lines which back up, write, and verify a device's firmware. write and verify are
+// told whether path is a backup, which holds the whole of the flash rather than just the firmware.
+type toolCommands struct {
+	program    string
+	backupFile string
+	backup     func(path string) []string
+	write      func(path string, isBackup bool) []string
+	verify     func(path string, isBackup bool) []string
+}
+
+func commandsFor(dev DeviceConfig) toolCommands {
+	baud := dev.BaudRate
+	if baud == 0 {
+		baud = defaultBaud
+	}
+	switch dev.Tool {
+	case toolEsptool:
+		chip := dev.Chip
+		if chip == "" {
+			chip = esptoolChip
+		}
+		address := dev.FlashAddress
+		if address == "" {
+			address = "0x0"
+		}
+		addressOf := func(isBackup bool) string {
+			if isBackup {
+				return "0x0"
+			}
+			return address
+		}
+		common := []string{"--chip", chip, "--port", dev.SerialPath, "--baud", strconv.Itoa(baud)}
+		return toolCommands{
+			program:    orDefault(dev.ToolPath, "esptool.py"),
+			backupFile: dev.Name + ".bin",
+			backup: func(path string) []string {
+				return append(common, "read_flash", "0x0", "ALL", path)
+			},
+			write: func(path string, isBackup bool) []string {
+				return append(common, "write_flash", addressOf(isBackup), path)
+			},
+			verify: func(path string, isBackup bool) []string {
+				return append(common, "verify_flash", addressOf(isBackup), path)
+			},
+		}
+	case toolAvrdude:
+		programmer := dev.Programmer
+		if programmer == "" {
+			programmer = avrProgrammer
+		}
+		common := []string{"-p", dev.Chip, "-c", programmer, "-P", dev.SerialPath, "-b", strconv.Itoa(baud)}
+		return toolCommands{
+			program:    orDefault(dev.ToolPath, "avrdude"),
+			backupFile: dev.Name + ".hex",
+			backup: func(path string) []string {
+				return append(common, "-U", "flash:r:"+path+":i")
+			},
+			// we verify separately, so that a failed verification can be told from a failed write
+			write: func(path string, _ bool) []string {
+				return append(common, "-D", "-V", "-U", "flash:w:"+path+":a")
+			},
+			verify: func(path string, _ bool) []string {
+				return append(common, "-U", "flash:v:"+path+":a")
+			},
+		}
+	default:
+		port := "--port=" + dev.SerialPath
+		return toolCommands{
+			program:    orDefault(dev.ToolPath, "bossac"),
+			backupFile: dev.Name + ".bin",
+			backup: func(path string) []string {
+				return []string{port, "--read", path}
+			},
+			write: func(path string, _ bool) []string {
+				return []string{port, "--erase", "--write", "--boot", "--reset", path}
+			},
+			verify: func(path string, _ bool) []string {
+				return []string{port, "--verify", path}
+			},
+		}
+	}
+}
+
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+// toolRunner runs a flashing tool, passing each line of its output to output as it arrives.
+type toolRunner func(ctx context.Context, program string, args []string, output func(line string)) error
+
+func runTool(ctx context.Context, program string, args []string, output func(line string)) error {
+	//nolint:gosec
+	cmd := exec.CommandContext(ctx, program, args...)
+	pipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	// the tools disagree about which of stdout and stderr their progress goes to
+	cmd.Stderr = cmd.Stdout
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// keep the end of the output to explain any failure
+	var tail []string
+	scanner := bufio.NewScanner(pipe)
+	scanner.Split(scanLinesOrCarriageReturns)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		output(line)
+		tail = append(tail, line)
+		if len(tail) > 5 {
+			tail = tail[1:]
+		}
+	}
+	//nolint:errcheck
+	io.Copy(io.Discard, pipe)
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("%s failed: %w: %s", program, err, strings.Join(tail, "; "))
+	}
+	return nil
+}
+
+// scanLinesOrCarriageReturns is bufio.ScanLines, except that it also splits on the carriage returns which progress
+// bars use to redraw themselves.
+func scanLinesOrCarriageReturns(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// esptool prints "Writing at 0x00010000... (25 %)", avrdude "Writing | #########  | 18% 0.20s", and bossac
+// "[====    ] 50% (64/128 pages)".
+var percentRegex = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*%`)
+
+// parseProgress returns the fraction done that a line of a tool's output reports, if any.
+func parseProgress(line string) (float64, bool) {
+	if match := percentRegex.FindStringSubmatch(line); match != nil {
+		percent, err := strconv.ParseFloat(match[1], 64)
+		if err != nil || percent > 100 {
+			return 0, false
+		}
+		return percent / 100, true
+	}
+	return 0, false
+}
@@ -0,0 +1,271 @@
+// Package obstaclestop implements a generic service which watches distance sensors, such as the hc-sr04 and
+// vl53l1x, and stops a base whenever it is moving with an obstacle closer than a configured distance. It is a last
+// line of defense which works whatever is driving the base, whether that is a person, the motion service, or a
+// script.
+//
+// Sending it {"command": "get_status"} returns whether there is an obstacle, the nearest distance seen and which
+// sensor saw it, and how many times the base has been stopped.
+package obstaclestop
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("obstacle-stop")
+
+const (
+	defaultUpdateRateHz = 20
+	defaultReadingKey   = "distance"
+)
+
+// Config is the config of the obstacle-stop generic service.
+type Config struct {
+	Base    string   `json:"base"`
+	Sensors []string `json:"sensors"`
+	// StopDistanceM is how close in meters an obstacle can be before the base is stopped.
+	StopDistanceM float64 `json:"stop_distance_m"`
+	UpdateRateHz  float64 `json:"update_rate_hz,omitempty"`
+	// ReadingKey is the reading holding each sensor's distance in meters, which defaults to "distance".
+	ReadingKey string `json:"reading_key,omitempty"`
+	// StopOnSensorError treats a sensor which cannot be read as seeing an obstacle. By default such sensors are
+	// ignored, since range sensors commonly report errors when there is nothing in range.
+	StopOnSensorError bool `json:"stop_on_sensor_error,omitempty"`
+}
+
+// Validate validates the obstacle-stop service's configuration.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	if cfg.Base == "" {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "base")
+	}
+	if len(cfg.Sensors) == 0 {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "sensors")
+	}
+	if cfg.StopDistanceM <= 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("stop_distance_m must be positive"))
+	}
+	if cfg.UpdateRateHz < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("update_rate_hz cannot be negative"))
+	}
+	return append([]string{cfg.Base}, cfg.Sensors...), nil
+}
+
+func init() {
+	resource.RegisterService(
+		generic.API,
+		model,
+		resource.Registration[resource.Resource, *Config]{
+			Constructor: newObstacleStop,
+		})
+}
+
+type obstacleStop struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	base              base.Base
+	sensors           map[string]sensor.Sensor
+	stopDistance      float64
+	readingKey        string
+	stopOnSensorError bool
+
+	mu             sync.Mutex
+	obstacle       bool
+	nearest        float64
+	nearestSensor  string
+	stops          int
+	lastSensorErrs map[string]string
+
+	workers utils.StoppableWorkers
+	logger  logging.Logger
+}
+
+func newObstacleStop(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (resource.Resource, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	o, err := makeObstacleStop(deps, conf.ResourceName(), newConf, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := newConf.UpdateRateHz
+	if rate == 0 {
+		rate = defaultUpdateRateHz
+	}
+	o.workers = utils.NewStoppableWorkers(func(ctx context.Context) {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				o.check(ctx)
+			}
+		}
+	})
+	return o, nil
+}
+
+// makeObstacleStop builds the service without starting to watch its sensors, so that tests can check by hand.
+func makeObstacleStop(
+	deps resource.Dependencies,
+	name resource.Name,
+	conf *Config,
+	logger logging.Logger,
+) (*obstacleStop, error) {
+	b, err := base.FromDependencies(deps, conf.Base)
+	if err != nil {
+		return nil, err
+	}
+	o := &obstacleStop{
+		Named:             name.AsNamed(),
+		base:              b,
+		sensors:           map[string]sensor.Sensor{},
+		stopDistance:      conf.StopDistanceM,
+		readingKey:        conf.ReadingKey,
+		stopOnSensorError: conf.StopOnSensorError,
+		nearest:           math.NaN(),
+		lastSensorErrs:    map[string]string{},
+		logger:            logger,
+	}
+	if o.readingKey == "" {
+		o.readingKey = defaultReadingKey
+	}
+	for _, sensorName := range conf.Sensors {
+		s, err := sensor.FromDependencies(deps, sensorName)
+		if err != nil {
+			return nil, err
+		}
+		o.sensors[sensorName] = s
+	}
+	return o, nil
+}
+
+func (o *obstacleStop) distance(ctx context.Context, s sensor.Sensor) (float64, error) {
+	readings, err := s.Readings(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	switch d := readings[o.readingKey].(type) {
+	case float64:
+		return d, nil
+	case float32:
+		return float64(d), nil
+	case int:
+		return float64(d), nil
+	default:
+		return 0, fmt.Errorf("reading %q is %v, not a distance", o.readingKey, readings[o.readingKey])
+	}
+}
+
+// check reads every sensor, and stops the base if it is moving towards an obstacle.
+func (o *obstacleStop) check(ctx context.Context) {
+	nearest := math.Inf(1)
+	var nearestSensor string
+	sensorFailed := false
+	sensorErrs := map[string]string{}
+	for sensorName, s := range o.sensors {
+		d, err := o.distance(ctx, s)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			sensorFailed = true
+			sensorErrs[sensorName] = err.Error()
+			continue
+		}
+		if d < nearest {
+			nearest, nearestSensor = d, sensorName
+		}
+	}
+	obstacle := nearest < o.stopDistance || (sensorFailed && o.stopOnSensorError)
+
+	o.mu.Lock()
+	for sensorName, msg := range sensorErrs {
+		// a sensor which keeps failing the same way would otherwise fill the logs
+		if o.lastSensorErrs[sensorName] != msg {
+			o.logger.CDebugw(ctx, "failed to read distance", "sensor", sensorName, "error", msg)
+		}
+	}
+	o.lastSensorErrs = sensorErrs
+	o.obstacle = obstacle
+	o.nearest = nearest
+	o.nearestSensor = nearestSensor
+	o.mu.Unlock()
+
+	if !obstacle {
+		return
+	}
+	moving, err := o.base.IsMoving(ctx)
+	if err != nil {
+		o.logger.CWarnw(ctx, "failed to check whether base is moving, stopping it to be safe", "error", err)
+		moving = true
+	}
+	if !moving {
+		return
+	}
+	o.logger.CWarnw(ctx, "stopping base for obstacle", "sensor", nearestSensor, "distance_m", nearest)
+	if err := o.base.Stop(ctx, nil); err != nil {
+		o.logger.CErrorw(ctx, "failed to stop base for obstacle", "error", err)
+		return
+	}
+	o.mu.Lock()
+	o.stops++
+	o.mu.Unlock()
+}
+
+func (o *obstacleStop) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, _ := cmd["command"].(string)
+	switch name {
+	case "get_status":
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		status := map[string]interface{}{
+			"obstacle": o.obstacle,
+			"stops":    o.stops,
+		}
+		// with nothing in range, there is no nearest distance to report
+		if !math.IsInf(o.nearest, 1) && !math.IsNaN(o.nearest) {
+			status["nearest_distance_m"] = o.nearest
+			status["nearest_sensor"] = o.nearestSensor
+		}
+		if len(o.lastSensorErrs) > 0 {
+			sensorErrs := map[string]interface{}{}
+			for sensorName, msg := range o.lastSensorErrs {
+				sensorErrs[sensorName] = msg
+			}
+			status["sensor_errors"] = sensorErrs
+		}
+		return status, nil
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
+
+// Close stops watching the sensors.
+func (o *obstacleStop) Close(ctx context.Context) error {
+	if o.workers != nil {
+		o.workers.Stop()
+	}
+	return nil
+}
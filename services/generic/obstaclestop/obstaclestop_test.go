@@ -0,0 +1,115 @@
+package obstaclestop
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestValidate(t *testing.T) {
+	conf := &Config{}
+	_, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "base"))
+
+	conf.Base = "base"
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "sensors"))
+
+	conf.Sensors = []string{"front", "back"}
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	conf.StopDistanceM = 0.3
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"base", "front", "back"})
+}
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+
+	moving := true
+	stops := 0
+	b := inject.NewBase("base")
+	b.IsMovingFunc = func(ctx context.Context) (bool, error) {
+		return moving, nil
+	}
+	b.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		stops++
+		moving = false
+		return nil
+	}
+
+	distances := map[string]float64{"front": 2, "back": 1}
+	var frontErr error
+	newSensor := func(name string) *inject.Sensor {
+		s := inject.NewSensor(name)
+		s.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+			if name == "front" && frontErr != nil {
+				return nil, frontErr
+			}
+			return map[string]interface{}{"distance": distances[name]}, nil
+		}
+		return s
+	}
+	deps := resource.Dependencies{
+		base.Named("base"):    b,
+		sensor.Named("front"): newSensor("front"),
+		sensor.Named("back"):  newSensor("back"),
+	}
+	conf := &Config{Base: "base", Sensors: []string{"front", "back"}, StopDistanceM: 0.5}
+	o, err := makeObstacleStop(deps, generic.Named("safety"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+
+	o.check(ctx)
+	test.That(t, stops, test.ShouldEqual, 0)
+	status, err := o.DoCommand(ctx, map[string]interface{}{"command": "get_status"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status, test.ShouldResemble, map[string]interface{}{
+		"obstacle":           false,
+		"stops":              0,
+		"nearest_distance_m": 1.,
+		"nearest_sensor":     "back",
+	})
+
+	distances["front"] = 0.2
+	o.check(ctx)
+	test.That(t, stops, test.ShouldEqual, 1)
+
+	// a stopped base is left alone, until something starts it again
+	o.check(ctx)
+	test.That(t, stops, test.ShouldEqual, 1)
+	moving = true
+	o.check(ctx)
+	test.That(t, stops, test.ShouldEqual, 2)
+
+	status, err = o.DoCommand(ctx, map[string]interface{}{"command": "get_status"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["obstacle"], test.ShouldBeTrue)
+	test.That(t, status["nearest_sensor"], test.ShouldEqual, "front")
+	test.That(t, status["stops"], test.ShouldEqual, 2)
+
+	// failing sensors are ignored unless configured otherwise
+	distances["front"] = 2
+	frontErr = errors.New("out of range")
+	moving = true
+	o.check(ctx)
+	test.That(t, stops, test.ShouldEqual, 2)
+
+	o.stopOnSensorError = true
+	o.check(ctx)
+	test.That(t, stops, test.ShouldEqual, 3)
+
+	_, err = o.DoCommand(ctx, map[string]interface{}{"command": "dance"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, o.Close(ctx), test.ShouldBeNil)
+}
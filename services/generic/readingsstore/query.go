@@ -0,0 +1,627 @@
+package readingsstore
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// The query language is a small subset of SQL over one sensor at a time, whose numeric readings are
+// its columns along with time:
+//
+//	SELECT avg(depth), max(depth) FROM sonar WHERE time > now() - 10m GROUP BY time(1m)
+//	SELECT time, linear_velocity.x FROM imu WHERE linear_velocity.x > 0.5 ORDER BY time DESC LIMIT 10
+//
+// Names which aren't plain identifiers, such as sensors named with a dash, may be double quoted.
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokDuration
+	tokString
+	tokSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	dur  time.Duration
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			num, err := strconv.ParseFloat(string(runes[start:i]), 64)
+			if err != nil {
+				return nil, errors.Errorf("invalid number %q", string(runes[start:i]))
+			}
+			// a number directly followed by a unit, such as 10m, is a duration
+			unitStart := i
+			for i < len(runes) && unicode.IsLetter(runes[i]) {
+				i++
+			}
+			if unitStart == i {
+				tokens = append(tokens, token{kind: tokNumber, text: string(runes[start:i]), num: num})
+				continue
+			}
+			dur, err := time.ParseDuration(string(runes[start:i]))
+			if err != nil {
+				return nil, errors.Errorf("invalid duration %q", string(runes[start:i]))
+			}
+			tokens = append(tokens, token{kind: tokDuration, text: string(runes[start:i]), dur: dur})
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, errors.New("unterminated quote")
+			}
+			kind := tokString
+			if r == '"' {
+				kind = tokIdent
+			}
+			tokens = append(tokens, token{kind: kind, text: string(runes[i+1 : end])})
+			i = end + 1
+		case isIdentRune(r):
+			start := i
+			for i < len(runes) && isIdentRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[start:i])})
+		default:
+			symbol := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' && strings.ContainsRune("<>!", r) {
+				symbol += "="
+			}
+			if symbol == "!" || !strings.ContainsRune("*,()<>=!-+", r) {
+				return nil, errors.Errorf("unexpected %q", symbol)
+			}
+			tokens = append(tokens, token{kind: tokSymbol, text: symbol})
+			i += len([]rune(symbol))
+		}
+	}
+	return append(tokens, token{kind: tokEOF}), nil
+}
+
+var aggregates = map[string]bool{
+	"avg": true, "min": true, "max": true, "sum": true, "count": true, "first": true, "last": true,
+}
+
+const timeColumn = "time"
+
+type selectItem struct {
+	aggregate string // empty for a plain column
+	column    string // "*" for count(*) or SELECT *
+	alias     string
+}
+
+func (item selectItem) name() string {
+	switch {
+	case item.alias != "":
+		return item.alias
+	case item.aggregate != "":
+		return item.aggregate + "(" + item.column + ")"
+	default:
+		return item.column
+	}
+}
+
+type condition struct {
+	column string
+	op     string
+	value  float64
+	// at and sinceNow are used for conditions on time, which is either absolute or relative to when
+	// the query runs.
+	at       time.Time
+	sinceNow *time.Duration
+}
+
+type query struct {
+	items      []selectItem
+	from       string
+	conditions []condition
+	groupBy    time.Duration
+	descending bool
+	limit      int
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// keyword consumes the next token if it is the keyword kw.
+func (p *parser) keyword(kw string) bool {
+	if tok := p.peek(); tok.kind == tokIdent && strings.EqualFold(tok.text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(kw string) error {
+	if !p.keyword(kw) {
+		return errors.Errorf("expected %s but found %q", strings.ToUpper(kw), p.peek().text)
+	}
+	return nil
+}
+
+// symbol consumes the next token if it is the symbol s.
+func (p *parser) symbol(s string) bool {
+	if tok := p.peek(); tok.kind == tokSymbol && tok.text == s {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectSymbol(s string) error {
+	if !p.symbol(s) {
+		return errors.Errorf("expected %q but found %q", s, p.peek().text)
+	}
+	return nil
+}
+
+func (p *parser) ident() (string, error) {
+	tok := p.next()
+	if tok.kind != tokIdent {
+		return "", errors.Errorf("expected a name but found %q", tok.text)
+	}
+	return tok.text, nil
+}
+
+func parseQuery(text string) (*query, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	q := &query{}
+
+	if err := p.expectKeyword("select"); err != nil {
+		return nil, err
+	}
+	for {
+		item, err := p.selectItem()
+		if err != nil {
+			return nil, err
+		}
+		q.items = append(q.items, item)
+		if !p.symbol(",") {
+			break
+		}
+	}
+
+	if err := p.expectKeyword("from"); err != nil {
+		return nil, err
+	}
+	if q.from, err = p.ident(); err != nil {
+		return nil, err
+	}
+
+	if p.keyword("where") {
+		for {
+			cond, err := p.condition()
+			if err != nil {
+				return nil, err
+			}
+			q.conditions = append(q.conditions, cond)
+			if !p.keyword("and") {
+				break
+			}
+		}
+	}
+
+	if p.keyword("group") {
+		if err := p.expectKeyword("by"); err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword(timeColumn); err != nil {
+			return nil, err
+		}
+		if err := p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+		tok := p.next()
+		if tok.kind != tokDuration || tok.dur <= 0 {
+			return nil, errors.Errorf("expected a positive duration such as 1m but found %q", tok.text)
+		}
+		q.groupBy = tok.dur
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.keyword("order") {
+		if err := p.expectKeyword("by"); err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword(timeColumn); err != nil {
+			return nil, err
+		}
+		if p.keyword("desc") {
+			q.descending = true
+		} else {
+			p.keyword("asc")
+		}
+	}
+
+	if p.keyword("limit") {
+		tok := p.next()
+		if tok.kind != tokNumber || tok.num < 0 || tok.num != math.Trunc(tok.num) {
+			return nil, errors.Errorf("expected a whole number but found %q", tok.text)
+		}
+		q.limit = int(tok.num)
+	}
+
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, errors.Errorf("unexpected %q", tok.text)
+	}
+	return q, q.validate()
+}
+
+func (p *parser) selectItem() (selectItem, error) {
+	if p.symbol("*") {
+		return selectItem{column: "*"}, nil
+	}
+	name, err := p.ident()
+	if err != nil {
+		return selectItem{}, err
+	}
+	item := selectItem{column: name}
+	if p.symbol("(") {
+		item.aggregate = strings.ToLower(name)
+		if !aggregates[item.aggregate] {
+			return selectItem{}, errors.Errorf("unknown function %q", name)
+		}
+		if p.symbol("*") {
+			if item.aggregate != "count" {
+				return selectItem{}, errors.Errorf("only count can take *, not %s", item.aggregate)
+			}
+			item.column = "*"
+		} else if item.column, err = p.ident(); err != nil {
+			return selectItem{}, err
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return selectItem{}, err
+		}
+	}
+	if p.keyword("as") {
+		if item.alias, err = p.ident(); err != nil {
+			return selectItem{}, err
+		}
+	}
+	return item, nil
+}
+
+func (p *parser) condition() (condition, error) {
+	column, err := p.ident()
+	if err != nil {
+		return condition{}, err
+	}
+	cond := condition{column: column}
+	op := p.next()
+	switch op.text {
+	case "<", "<=", ">", ">=", "=", "!=":
+		if op.kind != tokSymbol {
+			return condition{}, errors.Errorf("expected a comparison but found %q", op.text)
+		}
+		cond.op = op.text
+	default:
+		return condition{}, errors.Errorf("expected a comparison but found %q", op.text)
+	}
+
+	if strings.EqualFold(column, timeColumn) {
+		cond.column = timeColumn
+		return cond, p.timeValue(&cond)
+	}
+	negative := p.symbol("-")
+	tok := p.next()
+	if tok.kind != tokNumber {
+		return condition{}, errors.Errorf("expected a number but found %q", tok.text)
+	}
+	cond.value = tok.num
+	if negative {
+		cond.value = -cond.value
+	}
+	return cond, nil
+}
+
+// timeValue parses either a quoted RFC 3339 time, or now() optionally plus or minus a duration.
+func (p *parser) timeValue(cond *condition) error {
+	if tok := p.peek(); tok.kind == tokString {
+		p.next()
+		at, err := time.Parse(time.RFC3339Nano, tok.text)
+		if err != nil {
+			return errors.Wrap(err, "times must be RFC 3339")
+		}
+		cond.at = at
+		return nil
+	}
+	if err := p.expectKeyword("now"); err != nil {
+		return err
+	}
+	if err := p.expectSymbol("("); err != nil {
+		return err
+	}
+	if err := p.expectSymbol(")"); err != nil {
+		return err
+	}
+	var offset time.Duration
+	sign := time.Duration(0)
+	if p.symbol("-") {
+		sign = -1
+	} else if p.symbol("+") {
+		sign = 1
+	}
+	if sign != 0 {
+		tok := p.next()
+		if tok.kind != tokDuration {
+			return errors.Errorf("expected a duration such as 10m but found %q", tok.text)
+		}
+		offset = sign * tok.dur
+	}
+	cond.sinceNow = &offset
+	return nil
+}
+
+func (q *query) aggregated() bool {
+	if q.groupBy > 0 {
+		return true
+	}
+	for _, item := range q.items {
+		if item.aggregate != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *query) validate() error {
+	if !q.aggregated() {
+		return nil
+	}
+	for _, item := range q.items {
+		if item.aggregate == "" && item.column != timeColumn {
+			return errors.Errorf("%s must be aggregated, such as avg(%s), in a query with aggregates", item.column, item.column)
+		}
+	}
+	return nil
+}
+
+// run executes q over samples, which are in time order, returning the names of its columns and
+// its rows.
+func (q *query) run(samples []sample, now time.Time) ([]string, [][]interface{}) {
+	var matching []sample
+	for _, s := range samples {
+		if q.matches(s, now) {
+			matching = append(matching, s)
+		}
+	}
+
+	var columns []string
+	var rows [][]interface{}
+	if q.aggregated() {
+		columns, rows = q.aggregate(matching)
+	} else {
+		columns, rows = q.project(matching)
+	}
+
+	if q.descending {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	if q.limit > 0 && len(rows) > q.limit {
+		rows = rows[:q.limit]
+	}
+	return columns, rows
+}
+
+func (q *query) matches(s sample, now time.Time) bool {
+	for _, cond := range q.conditions {
+		var cmp int
+		if cond.column == timeColumn {
+			at := cond.at
+			if cond.sinceNow != nil {
+				at = now.Add(*cond.sinceNow)
+			}
+			cmp = s.time.Compare(at)
+		} else {
+			v, ok := s.values[cond.column]
+			if !ok {
+				return false
+			}
+			switch {
+			case v < cond.value:
+				cmp = -1
+			case v > cond.value:
+				cmp = 1
+			}
+		}
+		var holds bool
+		switch cond.op {
+		case "<":
+			holds = cmp < 0
+		case "<=":
+			holds = cmp <= 0
+		case ">":
+			holds = cmp > 0
+		case ">=":
+			holds = cmp >= 0
+		case "=":
+			holds = cmp == 0
+		case "!=":
+			holds = cmp != 0
+		}
+		if !holds {
+			return false
+		}
+	}
+	return true
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func (q *query) project(samples []sample) ([]string, [][]interface{}) {
+	// columns are what each value is looked up by, and names are what they are returned as
+	var columns, names []string
+	for _, item := range q.items {
+		if item.column != "*" {
+			columns = append(columns, item.column)
+			names = append(names, item.name())
+			continue
+		}
+		// every column any of the samples has
+		seen := map[string]bool{}
+		var fields []string
+		for _, s := range samples {
+			for field := range s.values {
+				if !seen[field] {
+					seen[field] = true
+					fields = append(fields, field)
+				}
+			}
+		}
+		sort.Strings(fields)
+		columns = append(append(columns, timeColumn), fields...)
+		names = append(append(names, timeColumn), fields...)
+	}
+
+	rows := make([][]interface{}, 0, len(samples))
+	for _, s := range samples {
+		row := make([]interface{}, 0, len(columns))
+		for _, column := range columns {
+			row = append(row, s.value(column))
+		}
+		rows = append(rows, row)
+	}
+	return names, rows
+}
+
+func (q *query) aggregate(samples []sample) ([]string, [][]interface{}) {
+	columns := make([]string, 0, len(q.items))
+	for _, item := range q.items {
+		columns = append(columns, item.name())
+	}
+
+	type group struct {
+		start   time.Time
+		samples []sample
+	}
+	var groups []*group
+	if q.groupBy > 0 {
+		for _, s := range samples {
+			start := s.time.Truncate(q.groupBy)
+			if len(groups) == 0 || !groups[len(groups)-1].start.Equal(start) {
+				groups = append(groups, &group{start: start})
+			}
+			groups[len(groups)-1].samples = append(groups[len(groups)-1].samples, s)
+		}
+	} else {
+		// without grouping, there is a single row, even with nothing to aggregate
+		g := &group{samples: samples}
+		if len(samples) > 0 {
+			g.start = samples[0].time
+		}
+		groups = append(groups, g)
+	}
+
+	rows := make([][]interface{}, 0, len(groups))
+	for _, g := range groups {
+		row := make([]interface{}, 0, len(q.items))
+		for _, item := range q.items {
+			if item.aggregate == "" {
+				if g.start.IsZero() {
+					row = append(row, nil)
+				} else {
+					row = append(row, formatTime(g.start))
+				}
+				continue
+			}
+			row = append(row, aggregateOf(item, g.samples))
+		}
+		rows = append(rows, row)
+	}
+	return columns, rows
+}
+
+func aggregateOf(item selectItem, samples []sample) interface{} {
+	if item.aggregate == "count" && item.column == "*" {
+		return len(samples)
+	}
+	var count int
+	var sum, lowest, highest, first, last float64
+	for _, s := range samples {
+		v, ok := s.values[item.column]
+		if !ok {
+			continue
+		}
+		if count == 0 {
+			lowest, highest, first = v, v, v
+		}
+		count++
+		sum += v
+		lowest = math.Min(lowest, v)
+		highest = math.Max(highest, v)
+		last = v
+	}
+	if item.aggregate == "count" {
+		return count
+	}
+	if count == 0 {
+		return nil
+	}
+	switch item.aggregate {
+	case "avg":
+		return sum / float64(count)
+	case "min":
+		return lowest
+	case "max":
+		return highest
+	case "sum":
+		return sum
+	case "first":
+		return first
+	default:
+		return last
+	}
+}
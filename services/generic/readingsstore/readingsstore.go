@@ -0,0 +1,366 @@
+// Package readingsstore implements a generic service which keeps a window of recent readings from sensors on the
+// robot in memory, and answers SQL queries over them, so that other services and the app can compute trends without
+// an external database.
+//
+// Each sensor is a table whose columns are time and its numeric readings. Nested readings are flattened into dotted
+// names, such as linear_velocity.x, and booleans are stored as 0 or 1. Sending it
+//
+//	{"command": "query", "sql": "SELECT avg(temperature) FROM thermometer WHERE time > now() - 10m GROUP BY time(1m)"}
+//
+// returns {"columns": [...], "rows": [[...], ...]}, with times as RFC 3339 strings. {"command": "list"} returns each
+// sensor's columns, how many readings are stored, and when the oldest and newest were taken.
+package readingsstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/utils"
+)
+
+var model = resource.DefaultModelFamily.WithModel("readings-store")
+
+const (
+	defaultSampleRateHz = 1
+	defaultWindowMins   = 60
+	defaultMaxSamples   = 100000
+)
+
+// Config is the config of the readings-store generic service.
+type Config struct {
+	// Sensors are the resources with readings to store, which may be sensors, movement sensors, or anything else
+	// with readings.
+	Sensors      []string `json:"sensors"`
+	SampleRateHz float64  `json:"sample_rate_hz,omitempty"`
+	// WindowMins is how long readings are kept for, which defaults to an hour.
+	WindowMins float64 `json:"window_mins,omitempty"`
+	// MaxSamples caps how many readings are kept for each sensor, whatever their age, to bound memory use.
+	MaxSamples int `json:"max_samples,omitempty"`
+}
+
+// Validate validates the readings-store service's configuration.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	if len(cfg.Sensors) == 0 {
+		return nil, resource.NewConfigValidationFieldRequiredError(path, "sensors")
+	}
+	if cfg.SampleRateHz < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("sample_rate_hz cannot be negative"))
+	}
+	if cfg.WindowMins < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("window_mins cannot be negative"))
+	}
+	if cfg.MaxSamples < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("max_samples cannot be negative"))
+	}
+	return cfg.Sensors, nil
+}
+
+func init() {
+	resource.RegisterService(
+		generic.API,
+		model,
+		resource.Registration[resource.Resource, *Config]{
+			Constructor: newReadingsStore,
+		})
+}
+
+// sample is one set of readings from a sensor.
+type sample struct {
+	time   time.Time
+	values map[string]float64
+}
+
+// value returns the named column of the sample, or nil if the sample doesn't have it.
+func (s sample) value(column string) interface{} {
+	if column == timeColumn {
+		return formatTime(s.time)
+	}
+	if v, ok := s.values[column]; ok {
+		return v
+	}
+	return nil
+}
+
+type readingsStore struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	sensors    map[string]resource.Sensor
+	window     time.Duration
+	maxSamples int
+
+	mu             sync.Mutex
+	samples        map[string][]sample
+	lastSensorErrs map[string]string
+
+	workers utils.StoppableWorkers
+	logger  logging.Logger
+}
+
+func newReadingsStore(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (resource.Resource, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := makeReadingsStore(deps, conf.ResourceName(), newConf, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := newConf.SampleRateHz
+	if rate == 0 {
+		rate = defaultSampleRateHz
+	}
+	rs.workers = utils.NewStoppableWorkers(func(ctx context.Context) {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				rs.record(ctx, time.Now())
+			}
+		}
+	})
+	return rs, nil
+}
+
+// makeReadingsStore builds the service without starting to read its sensors, so that tests can record by hand.
+func makeReadingsStore(
+	deps resource.Dependencies,
+	name resource.Name,
+	conf *Config,
+	logger logging.Logger,
+) (*readingsStore, error) {
+	rs := &readingsStore{
+		Named:          name.AsNamed(),
+		sensors:        map[string]resource.Sensor{},
+		window:         time.Duration(conf.WindowMins * float64(time.Minute)),
+		maxSamples:     conf.MaxSamples,
+		samples:        map[string][]sample{},
+		lastSensorErrs: map[string]string{},
+		logger:         logger,
+	}
+	if rs.window == 0 {
+		rs.window = defaultWindowMins * time.Minute
+	}
+	if rs.maxSamples == 0 {
+		rs.maxSamples = defaultMaxSamples
+	}
+	for _, sensorName := range conf.Sensors {
+		s, err := sensorFromDependencies(deps, sensorName)
+		if err != nil {
+			return nil, err
+		}
+		rs.sensors[sensorName] = s
+	}
+	return rs, nil
+}
+
+// sensorFromDependencies returns the dependency with the given short name which has readings, whatever its API is.
+func sensorFromDependencies(deps resource.Dependencies, name string) (resource.Sensor, error) {
+	for depName, dep := range deps {
+		if depName.ShortName() != name {
+			continue
+		}
+		if s, ok := dep.(resource.Sensor); ok {
+			return s, nil
+		}
+	}
+	return nil, errors.Errorf("no sensor or other resource with readings named %q", name)
+}
+
+// record reads every sensor, storing what they read at now and dropping readings which have aged out.
+func (rs *readingsStore) record(ctx context.Context, now time.Time) {
+	read := map[string]sample{}
+	sensorErrs := map[string]string{}
+	for sensorName, s := range rs.sensors {
+		readings, err := s.Readings(ctx, nil)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			sensorErrs[sensorName] = err.Error()
+			continue
+		}
+		values := map[string]float64{}
+		flatten("", readings, values)
+		read[sensorName] = sample{time: now, values: values}
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	for sensorName, msg := range sensorErrs {
+		// a sensor which keeps failing the same way would otherwise fill the logs
+		if rs.lastSensorErrs[sensorName] != msg {
+			rs.logger.CDebugw(ctx, "failed to read sensor", "sensor", sensorName, "error", msg)
+		}
+	}
+	rs.lastSensorErrs = sensorErrs
+	for sensorName, s := range read {
+		rs.samples[sensorName] = append(rs.samples[sensorName], s)
+	}
+	cutoff := now.Add(-rs.window)
+	for sensorName, samples := range rs.samples {
+		drop := sort.Search(len(samples), func(i int) bool { return samples[i].time.After(cutoff) })
+		if over := len(samples) - drop - rs.maxSamples; over > 0 {
+			drop += over
+		}
+		if drop > 0 {
+			// copy rather than reslice, so the dropped samples can be freed
+			rs.samples[sensorName] = append([]sample(nil), samples[drop:]...)
+		}
+	}
+}
+
+// flatten stores every numeric value in readings into values, keyed by its dotted path under prefix.
+func flatten(prefix string, readings map[string]interface{}, values map[string]float64) {
+	for key, reading := range readings {
+		flattenValue(prefix+key, reading, values)
+	}
+}
+
+func flattenValue(key string, reading interface{}, values map[string]float64) {
+	switch v := reading.(type) {
+	case float64:
+		values[key] = v
+	case float32:
+		values[key] = float64(v)
+	case int:
+		values[key] = float64(v)
+	case int32:
+		values[key] = float64(v)
+	case int64:
+		values[key] = float64(v)
+	case uint32:
+		values[key] = float64(v)
+	case uint64:
+		values[key] = float64(v)
+	case bool:
+		if v {
+			values[key] = 1
+		} else {
+			values[key] = 0
+		}
+	case r3.Vector:
+		values[key+".x"] = v.X
+		values[key+".y"] = v.Y
+		values[key+".z"] = v.Z
+	case *geo.Point:
+		if v != nil {
+			values[key+".lat"] = v.Lat()
+			values[key+".lng"] = v.Lng()
+		}
+	case map[string]interface{}:
+		flatten(key+".", v, values)
+	}
+}
+
+// query runs the query against the stored readings.
+func (rs *readingsStore) query(sql string, now time.Time) (map[string]interface{}, error) {
+	q, err := parseQuery(sql)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid query")
+	}
+	if _, ok := rs.sensors[q.from]; !ok {
+		return nil, errors.Errorf("no sensor named %q is being stored", q.from)
+	}
+
+	rs.mu.Lock()
+	samples := rs.samples[q.from]
+	rs.mu.Unlock()
+	// samples are only ever appended to or replaced, so the slice can be read without the lock
+	columns, rows := q.run(samples, now)
+
+	columnsOut := make([]interface{}, 0, len(columns))
+	for _, column := range columns {
+		columnsOut = append(columnsOut, column)
+	}
+	rowsOut := make([]interface{}, 0, len(rows))
+	for _, row := range rows {
+		rowsOut = append(rowsOut, row)
+	}
+	return map[string]interface{}{"columns": columnsOut, "rows": rowsOut}, nil
+}
+
+// list describes the table for each sensor.
+func (rs *readingsStore) list() map[string]interface{} {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	tables := map[string]interface{}{}
+	for sensorName := range rs.sensors {
+		samples := rs.samples[sensorName]
+		seen := map[string]bool{}
+		for _, s := range samples {
+			for field := range s.values {
+				seen[field] = true
+			}
+		}
+		columns := make([]string, 0, len(seen))
+		for field := range seen {
+			columns = append(columns, field)
+		}
+		sort.Strings(columns)
+		columnsOut := []interface{}{timeColumn}
+		for _, column := range columns {
+			columnsOut = append(columnsOut, column)
+		}
+		table := map[string]interface{}{
+			"columns": columnsOut,
+			"count":   len(samples),
+		}
+		if len(samples) > 0 {
+			table["oldest"] = formatTime(samples[0].time)
+			table["newest"] = formatTime(samples[len(samples)-1].time)
+		}
+		if msg, ok := rs.lastSensorErrs[sensorName]; ok {
+			table["error"] = msg
+		}
+		tables[sensorName] = table
+	}
+	return tables
+}
+
+func (rs *readingsStore) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"].(string)
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case "query":
+		sql, _ := cmd["sql"].(string)
+		if strings.TrimSpace(sql) == "" {
+			return nil, errors.New("missing 'sql' value")
+		}
+		return rs.query(sql, time.Now())
+	case "list":
+		return rs.list(), nil
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
+
+// Close stops reading the sensors.
+func (rs *readingsStore) Close(ctx context.Context) error {
+	if rs.workers != nil {
+		rs.workers.Stop()
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package readingsstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestValidate(t *testing.T) {
+	conf := &Config{}
+	_, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeError, resource.NewConfigValidationFieldRequiredError("path", "sensors"))
+
+	conf.Sensors = []string{"thermometer", "imu"}
+	conf.WindowMins = -1
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	conf.WindowMins = 10
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"thermometer", "imu"})
+}
+
+func TestParseQuery(t *testing.T) {
+	q, err := parseQuery(`select avg(temp) as mean, count(*) from "my-sensor" where time > now() - 10m and temp >= -5 ` +
+		`group by time(1m) order by time desc limit 3`)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, q.items, test.ShouldResemble, []selectItem{
+		{aggregate: "avg", column: "temp", alias: "mean"},
+		{aggregate: "count", column: "*"},
+	})
+	test.That(t, q.from, test.ShouldEqual, "my-sensor")
+	test.That(t, q.conditions, test.ShouldHaveLength, 2)
+	test.That(t, *q.conditions[0].sinceNow, test.ShouldEqual, -10*time.Minute)
+	test.That(t, q.conditions[1], test.ShouldResemble, condition{column: "temp", op: ">=", value: -5})
+	test.That(t, q.groupBy, test.ShouldEqual, time.Minute)
+	test.That(t, q.descending, test.ShouldBeTrue)
+	test.That(t, q.limit, test.ShouldEqual, 3)
+
+	for _, bad := range []string{
+		"",
+		"select temp",
+		"select temp from",
+		"select median(temp) from s",
+		"select sum(*) from s",
+		"select temp, avg(temp) from s",
+		"select temp from s where temp > 'hot'",
+		"select temp from s where time > yesterday",
+		"select temp from s group by time(0s)",
+		"select temp from s limit 1.5",
+		"select temp from s; drop table s",
+	} {
+		_, err := parseQuery(bad)
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	ctx := context.Background()
+
+	temp := 20.0
+	var thermometerErr error
+	thermometer := inject.NewSensor("thermometer")
+	thermometer.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		if thermometerErr != nil {
+			return nil, thermometerErr
+		}
+		return map[string]interface{}{"temp": temp, "heating": temp < 21, "status": "ok"}, nil
+	}
+	imu := inject.NewMovementSensor("imu")
+	imu.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"linear_velocity": r3.Vector{X: 1, Y: 2, Z: 3}}, nil
+	}
+	deps := resource.Dependencies{
+		sensor.Named("thermometer"): thermometer,
+		movementsensor.Named("imu"): imu,
+	}
+	conf := &Config{Sensors: []string{"thermometer", "imu"}, WindowMins: 4.5}
+	rs, err := makeReadingsStore(deps, generic.Named("store"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		temp = 20 + float64(i)*0.25
+		rs.record(ctx, start.Add(time.Duration(i)*30*time.Second))
+	}
+	now := start.Add(9 * 30 * time.Second)
+
+	// readings older than the window have been dropped
+	result, err := rs.query("SELECT count(*), min(temp), max(temp), first(temp), last(temp) FROM thermometer", now)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["rows"], test.ShouldResemble, []interface{}{[]interface{}{9, 20.25, 22.25, 20.25, 22.25}})
+
+	result, err = rs.query("SELECT time, temp, heating FROM thermometer ORDER BY time DESC LIMIT 2", now)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result, test.ShouldResemble, map[string]interface{}{
+		"columns": []interface{}{"time", "temp", "heating"},
+		"rows": []interface{}{
+			[]interface{}{"2024-01-01T12:04:30Z", 22.25, 0.},
+			[]interface{}{"2024-01-01T12:04:00Z", 22., 0.},
+		},
+	})
+
+	result, err = rs.query("SELECT time, avg(temp) AS mean FROM thermometer WHERE time >= now() - 2m GROUP BY time(1m)", now)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result, test.ShouldResemble, map[string]interface{}{
+		"columns": []interface{}{"time", "mean"},
+		"rows": []interface{}{
+			[]interface{}{"2024-01-01T12:02:00Z", 21.25},
+			[]interface{}{"2024-01-01T12:03:00Z", 21.625},
+			[]interface{}{"2024-01-01T12:04:00Z", 22.125},
+		},
+	})
+
+	result, err = rs.query(`SELECT * FROM imu WHERE linear_velocity.y = 2 AND time > '2024-01-01T12:04:00Z'`, now)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result, test.ShouldResemble, map[string]interface{}{
+		"columns": []interface{}{"time", "linear_velocity.x", "linear_velocity.y", "linear_velocity.z"},
+		"rows":    []interface{}{[]interface{}{"2024-01-01T12:04:30Z", 1., 2., 3.}},
+	})
+
+	// aggregating nothing still gives a row
+	result, err = rs.query("SELECT count(temp), avg(temp) FROM thermometer WHERE temp > 100", now)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["rows"], test.ShouldResemble, []interface{}{[]interface{}{0, nil}})
+
+	_, err = rs.query("SELECT temp FROM barometer", now)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	thermometerErr = errors.New("disconnected")
+	rs.record(ctx, now.Add(time.Second))
+	tables, err := rs.DoCommand(ctx, map[string]interface{}{"command": "list"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, tables["thermometer"], test.ShouldResemble, map[string]interface{}{
+		"columns": []interface{}{"time", "heating", "temp"},
+		"count":   9,
+		"oldest":  "2024-01-01T12:00:30Z",
+		"newest":  "2024-01-01T12:04:30Z",
+		"error":   "disconnected",
+	})
+	test.That(t, tables["imu"].(map[string]interface{})["count"], test.ShouldEqual, 10)
+
+	_, err = rs.DoCommand(ctx, map[string]interface{}{"command": "query"})
+	test.That(t, err, test.ShouldBeError, errors.New("missing 'sql' value"))
+	_, err = rs.DoCommand(ctx, map[string]interface{}{"command": "vacuum"})
+	test.That(t, err, test.ShouldBeError, errors.New("no such command: vacuum"))
+}
+
+func TestMaxSamples(t *testing.T) {
+	s := inject.NewSensor("s")
+	s.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"v": 1}, nil
+	}
+	conf := &Config{Sensors: []string{"s"}, MaxSamples: 3}
+	deps := resource.Dependencies{sensor.Named("s"): s}
+	rs, err := makeReadingsStore(deps, generic.Named("store"), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		rs.record(context.Background(), start.Add(time.Duration(i)*time.Second))
+	}
+	test.That(t, rs.samples["s"], test.ShouldHaveLength, 3)
+	test.That(t, rs.samples["s"][0].time, test.ShouldEqual, start.Add(2*time.Second))
+}
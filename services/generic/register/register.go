@@ -4,5 +4,9 @@ package register
 import (
 	// register generic.
 	_ "go.viam.com/rdk/services/generic"
+	_ "go.viam.com/rdk/services/generic/correctiondistributor"
 	_ "go.viam.com/rdk/services/generic/fake"
+	_ "go.viam.com/rdk/services/generic/mcufirmware"
+	_ "go.viam.com/rdk/services/generic/obstaclestop"
+	_ "go.viam.com/rdk/services/generic/readingsstore"
 )
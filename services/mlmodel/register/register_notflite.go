@@ -0,0 +1,16 @@
+//go:build no_tflite || slim || (no_cgo && !android)
+
+package register
+
+import (
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/mlmodel"
+)
+
+func init() {
+	resource.RegisterNotBuilt[mlmodel.Service](
+		mlmodel.API,
+		resource.DefaultModelFamily.WithModel("tflite_cpu"),
+		"the TensorFlow Lite runtime is left out of builds with the no_tflite or slim tags and of builds without cgo",
+	)
+}
@@ -1,4 +1,4 @@
-//go:build !no_tflite && (!no_cgo || android)
+//go:build !no_tflite && !slim && (!no_cgo || android)
 
 // Package register registers all relevant ML model services
 package register
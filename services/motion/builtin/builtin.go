@@ -67,13 +67,25 @@ type inputEnabledActuator interface {
 	referenceframe.InputEnabled
 }
 
-// Config describes how to configure the service; currently only used for specifying dependency on framesystem service.
+// Config describes how to configure the service.
 type Config struct {
 	LogFilePath string `json:"log_file_path"`
+	// JointLimitWarningMargin is how close, as a fraction of a joint's range, a component may come to one of its
+	// joint limits while moving before it is warned about and slowed down. Defaults to 0.05.
+	JointLimitWarningMargin float64 `json:"joint_limit_warning_margin,omitempty"`
+	// ManipulabilityWarningThreshold is the manipulability, with translation in meters and rotation in radians, below
+	// which an arm is considered to be approaching a singularity while moving. Defaults to 0.005.
+	ManipulabilityWarningThreshold float64 `json:"manipulability_warning_threshold,omitempty"`
 }
 
 // Validate here adds a dependency on the internal framesystem service.
 func (c *Config) Validate(path string) ([]string, error) {
+	if c.JointLimitWarningMargin < 0 || c.JointLimitWarningMargin >= 0.5 {
+		return nil, resource.NewConfigValidationError(path, errors.New("joint_limit_warning_margin must be in [0, 0.5)"))
+	}
+	if c.ManipulabilityWarningThreshold < 0 {
+		return nil, resource.NewConfigValidationError(path, errors.New("manipulability_warning_threshold must not be negative"))
+	}
 	return []string{framesystem.InternalServiceName.String()}, nil
 }
 
@@ -82,8 +94,9 @@ func NewBuiltIn(
 	ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger,
 ) (motion.Service, error) {
 	ms := &builtIn{
-		Named:  conf.ResourceName().AsNamed(),
-		logger: logger,
+		Named:     conf.ResourceName().AsNamed(),
+		logger:    logger,
+		proximity: map[string]proximityStatus{},
 	}
 
 	if err := ms.Reconfigure(ctx, deps, conf); err != nil {
@@ -112,6 +125,14 @@ func (ms *builtIn) Reconfigure(
 		}
 		ms.logger = logger
 	}
+	ms.jointLimitWarningMargin = defaultJointLimitWarningMargin
+	if config.JointLimitWarningMargin > 0 {
+		ms.jointLimitWarningMargin = config.JointLimitWarningMargin
+	}
+	ms.manipulabilityWarningThreshold = defaultManipulabilityWarningThreshold
+	if config.ManipulabilityWarningThreshold > 0 {
+		ms.manipulabilityWarningThreshold = config.ManipulabilityWarningThreshold
+	}
 	movementSensors := make(map[resource.Name]movementsensor.MovementSensor)
 	slamServices := make(map[resource.Name]slam.Service)
 	visionServices := make(map[resource.Name]vision.Service)
@@ -156,6 +177,11 @@ type builtIn struct {
 	components      map[resource.Name]resource.Resource
 	logger          logging.Logger
 	state           *state.State
+
+	jointLimitWarningMargin        float64
+	manipulabilityWarningThreshold float64
+	proximityMu                    sync.Mutex
+	proximity                      map[string]proximityStatus
 }
 
 func (ms *builtIn) Close(ctx context.Context) error {
@@ -226,14 +252,14 @@ func (ms *builtIn) Move(
 		return false, err
 	}
 
-	// move all the components
+	// move all the components, watching for them approaching joint limits and singularities as they go
 	for _, step := range plan.Trajectory() {
 		for name, inputs := range step {
 			if len(inputs) == 0 {
 				continue
 			}
 			r := resources[name]
-			if err := r.GoToInputs(ctx, inputs); err != nil {
+			if err := ms.goToInputs(ctx, name, frameSys.Frame(name), r, fsInputs[name], inputs); err != nil {
 				// If there is an error on GoToInputs, stop the component if possible before returning the error
 				if actuator, ok := r.(inputEnabledActuator); ok {
 					if stopErr := actuator.Stop(ctx, nil); stopErr != nil {
@@ -242,6 +268,7 @@ func (ms *builtIn) Move(
 				}
 				return false, err
 			}
+			fsInputs[name] = inputs
 		}
 	}
 	return true, nil
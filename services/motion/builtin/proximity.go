@@ -0,0 +1,156 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
+)
+
+const (
+	defaultJointLimitWarningMargin        = 0.05
+	defaultManipulabilityWarningThreshold = 0.005
+	// proximitySubsteps is how many moves a step of a plan is broken into when it ends near a joint limit or a
+	// singularity, so that the component approaches it in short moves rather than in one at full speed.
+	proximitySubsteps = 10
+
+	jointLimitWarning     = "joint_limit"
+	singularityWarning    = "singularity"
+	proximityStatusCmd    = "get_proximity_status"
+	proximityTimeLayout   = time.RFC3339Nano
+	unknownManipulability = -1.
+)
+
+// proximityStatus is how close a component was to its joint limits and to a singularity at the last step of a plan
+// it was moved through.
+type proximityStatus struct {
+	joint            int
+	jointLimitMargin float64
+	manipulability   float64
+	warnings         []string
+	time             time.Time
+}
+
+func (s proximityStatus) toMap() map[string]interface{} {
+	warnings := make([]interface{}, 0, len(s.warnings))
+	for _, warning := range s.warnings {
+		warnings = append(warnings, warning)
+	}
+	status := map[string]interface{}{
+		"joint_limit_margin": s.jointLimitMargin,
+		"warnings":           warnings,
+		"time":               s.time.Format(proximityTimeLayout),
+	}
+	if s.joint >= 0 {
+		status["closest_joint"] = s.joint
+	}
+	if s.manipulability != unknownManipulability {
+		status["manipulability"] = s.manipulability
+	}
+	return status
+}
+
+// checkProximity records how close the component is to its joint limits, and for arms to a singularity, at the given
+// inputs, and returns whether it is close enough to either to warn. Warnings are logged when they first appear.
+func (ms *builtIn) checkProximity(
+	ctx context.Context,
+	name string,
+	f referenceframe.Frame,
+	r referenceframe.InputEnabled,
+	inputs []referenceframe.Input,
+) bool {
+	status := proximityStatus{manipulability: unknownManipulability, time: time.Now()}
+	status.joint, status.jointLimitMargin = motionplan.JointLimitMargin(f, inputs)
+	if status.joint >= 0 && status.jointLimitMargin < ms.jointLimitWarningMargin {
+		status.warnings = append(status.warnings, jointLimitWarning)
+	}
+	// manipulability only means something for arms; the inputs of gantries and the like are all independent
+	if _, ok := r.(arm.Arm); ok {
+		manipulability, err := motionplan.Manipulability(f, inputs)
+		if err != nil {
+			ms.logger.CDebugw(ctx, "could not compute manipulability", "component", name, "error", err)
+		} else {
+			status.manipulability = manipulability
+			if manipulability < ms.manipulabilityWarningThreshold {
+				status.warnings = append(status.warnings, singularityWarning)
+			}
+		}
+	}
+
+	ms.proximityMu.Lock()
+	previous := ms.proximity[name]
+	ms.proximity[name] = status
+	ms.proximityMu.Unlock()
+
+	for _, warning := range status.warnings {
+		if hasWarning(previous.warnings, warning) {
+			continue
+		}
+		switch warning {
+		case jointLimitWarning:
+			ms.logger.CWarnw(ctx, "component is approaching a joint limit; slowing down",
+				"component", name, "joint", status.joint, "margin", status.jointLimitMargin)
+		case singularityWarning:
+			ms.logger.CWarnw(ctx, "arm is approaching a singularity; slowing down",
+				"component", name, "manipulability", status.manipulability)
+		}
+	}
+	return len(status.warnings) > 0
+}
+
+func hasWarning(warnings []string, warning string) bool {
+	for _, w := range warnings {
+		if w == warning {
+			return true
+		}
+	}
+	return false
+}
+
+// goToInputs moves a component of a plan from one step's inputs to the next. When the next step is near a joint limit
+// or a singularity, the move is broken into shorter ones which are each checked, so that it is slowed down and its
+// progress towards the limit is reported rather than the component faulting partway through a task.
+func (ms *builtIn) goToInputs(
+	ctx context.Context,
+	name string,
+	f referenceframe.Frame,
+	r referenceframe.InputEnabled,
+	from, to []referenceframe.Input,
+) error {
+	if f == nil || !ms.checkProximity(ctx, name, f, r, to) || len(from) != len(to) {
+		return r.GoToInputs(ctx, to)
+	}
+	for i := 1; i <= proximitySubsteps; i++ {
+		waypoint, err := f.Interpolate(from, to, float64(i)/proximitySubsteps)
+		if err != nil {
+			return err
+		}
+		ms.checkProximity(ctx, name, f, r, waypoint)
+		if err := r.GoToInputs(ctx, waypoint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DoCommand answers {"command": "get_proximity_status"} with how close each component moved by the service was to its
+// joint limits and to a singularity at the latest step of a plan, and which of them it was being warned and slowed for.
+// Polling it while a move runs follows its progress past them.
+func (ms *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, _ := cmd["command"].(string)
+	switch name {
+	case proximityStatusCmd:
+		ms.proximityMu.Lock()
+		defer ms.proximityMu.Unlock()
+		resp := map[string]interface{}{}
+		for component, status := range ms.proximity {
+			resp[component] = status.toMap()
+		}
+		return resp, nil
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
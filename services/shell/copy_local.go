@@ -214,13 +214,16 @@ func (copier *localFileCopier) Copy(ctx context.Context, file File) error {
 		}
 	} else {
 		//nolint:gosec // this is from an authenticated/authorized connection
-		localFile, err := os.OpenFile(fullPath, os.O_CREATE|os.O_WRONLY, fileMode)
+		localFile, err := os.OpenFile(fullPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, fileMode)
 		if err != nil {
 			return err
 		}
 		if _, err := io.Copy(localFile, file.Data); err != nil {
 			return multierr.Combine(err, localFile.Close())
 		}
+		if err := localFile.Close(); err != nil {
+			return err
+		}
 	}
 	if copier.preserve {
 		// Update the mode since it maye have been created via mkdirall above
@@ -0,0 +1,146 @@
+package slam
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// maxAlignmentPoints bounds how many points of a map are scan matched, since every step of the alignment finds the
+// nearest neighbor of each of them. The transform found is then applied to all of the map's points.
+const maxAlignmentPoints = 5000
+
+// MapToMerge is a map to be merged with others, such as from another session or another machine.
+type MapToMerge struct {
+	Map pointcloud.PointCloud
+	// Transform, when set, places the map in the merged map as is instead of aligning it, for maps whose
+	// relative placement is known or which do not overlap enough to be aligned.
+	Transform spatialmath.Pose
+	// InitialGuess is where alignment of the map starts from, which should be roughly where it belongs for
+	// alignment to succeed. Defaults to no offset.
+	InitialGuess spatialmath.Pose
+}
+
+// MergedMap is the result of merging maps.
+type MergedMap struct {
+	Map pointcloud.PointCloud
+	// Transforms are what each map was transformed by to place it in the merged map, which is in the frame of
+	// the first map.
+	Transforms []spatialmath.Pose
+	// AlignmentErrors are the mean distance from the points of each aligned map to their nearest neighbors in the
+	// maps before it, in the units of the maps. They are zero for maps which were not aligned.
+	AlignmentErrors []float64
+}
+
+// PCD returns the merged map in binary PCD format, like PointCloudMap returns maps in.
+func (m *MergedMap) PCD() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := pointcloud.ToPCD(m.Map, &buf, pointcloud.PCDBinary); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MapFromService returns the point cloud map of a SLAM service, to be merged.
+func MapFromService(ctx context.Context, svc Service, returnEditedMap bool) (pointcloud.PointCloud, error) {
+	data, err := PointCloudMapFull(ctx, svc, returnEditedMap)
+	if err != nil {
+		return nil, err
+	}
+	return pointcloud.ReadPCD(bytes.NewReader(data))
+}
+
+// MergeMaps merges maps into one in the frame of the first. Each map after the first is placed by its Transform
+// if it has one, or otherwise aligned by scan matching to the maps before it, so maps should be ordered such that
+// each overlaps those before it.
+func MergeMaps(ctx context.Context, maps []MapToMerge) (*MergedMap, error) {
+	ctx, span := trace.StartSpan(ctx, "slam::MergeMaps")
+	defer span.End()
+	if len(maps) == 0 {
+		return nil, errors.New("no maps to merge")
+	}
+
+	size := 0
+	for i, m := range maps {
+		if m.Map == nil || m.Map.Size() == 0 {
+			return nil, errors.Errorf("map %d is empty", i)
+		}
+		size += m.Map.Size()
+	}
+	tree := pointcloud.NewKDTreeWithPrealloc(size)
+	merged := &MergedMap{
+		Map:             tree,
+		Transforms:      make([]spatialmath.Pose, 0, len(maps)),
+		AlignmentErrors: make([]float64, 0, len(maps)),
+	}
+	for i, m := range maps {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		transform, alignmentError := m.Transform, 0.
+		if transform == nil {
+			transform = spatialmath.NewZeroPose()
+			if i > 0 {
+				var err error
+				transform, alignmentError, err = alignMap(m, tree)
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to align map %d", i)
+				}
+			}
+		}
+
+		var setErr error
+		m.Map.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+			setErr = tree.Set(spatialmath.Compose(transform, spatialmath.NewPoseFromPoint(p)).Point(), d)
+			return setErr == nil
+		})
+		if setErr != nil {
+			return nil, setErr
+		}
+		merged.Transforms = append(merged.Transforms, transform)
+		merged.AlignmentErrors = append(merged.AlignmentErrors, alignmentError)
+	}
+	return merged, nil
+}
+
+// alignMap finds the transform which best places a map onto the target by scan matching a sample of its points.
+func alignMap(m MapToMerge, target *pointcloud.KDTree) (spatialmath.Pose, float64, error) {
+	guess := m.InitialGuess
+	if guess == nil {
+		guess = spatialmath.NewZeroPose()
+	}
+	sample := m.Map
+	if every := (m.Map.Size() + maxAlignmentPoints - 1) / maxAlignmentPoints; every > 1 {
+		sample = pointcloud.NewWithPrealloc(maxAlignmentPoints)
+		i := 0
+		var setErr error
+		m.Map.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+			if i%every == 0 {
+				setErr = sample.Set(p, d)
+			}
+			i++
+			return setErr == nil
+		})
+		if setErr != nil {
+			return nil, 0, setErr
+		}
+	}
+
+	_, info, err := pointcloud.RegisterPointCloudICP(sample, target, guess, false, runtime.NumCPU())
+	if err != nil {
+		return nil, 0, err
+	}
+	x := info.OptResult.Location.X
+	transform := spatialmath.NewPose(
+		r3.Vector{X: x[0], Y: x[1], Z: x[2]},
+		&spatialmath.EulerAngles{Roll: x[3], Pitch: x[4], Yaw: x[5]},
+	)
+	return transform, info.OptResult.F, nil
+}
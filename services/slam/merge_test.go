@@ -0,0 +1,67 @@
+package slam_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/services/slam"
+	spatial "go.viam.com/rdk/spatialmath"
+)
+
+// roomCorner returns the floor and two walls of the corner of a room, shifted by offset.
+func roomCorner(t *testing.T, offset r3.Vector) pointcloud.PointCloud {
+	t.Helper()
+	pc := pointcloud.New()
+	for i := 0.; i < 20; i++ {
+		for j := 0.; j < 20; j++ {
+			for _, p := range []r3.Vector{{X: i * 10, Y: j * 10}, {X: i * 10, Z: j * 10}, {Y: i * 10, Z: j * 10}} {
+				test.That(t, pc.Set(p.Add(offset), nil), test.ShouldBeNil)
+			}
+		}
+	}
+	return pc
+}
+
+func TestMergeMaps(t *testing.T) {
+	ctx := context.Background()
+	_, err := slam.MergeMaps(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = slam.MergeMaps(ctx, []slam.MapToMerge{{Map: pointcloud.New()}})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	first := roomCorner(t, r3.Vector{})
+
+	t.Run("manual transform", func(t *testing.T) {
+		offset := spatial.NewPoseFromPoint(r3.Vector{X: 1000})
+		merged, err := slam.MergeMaps(ctx, []slam.MapToMerge{{Map: first}, {Map: first, Transform: offset}})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, merged.Map.Size(), test.ShouldEqual, 2*first.Size())
+		test.That(t, spatial.PoseAlmostEqual(merged.Transforms[1], offset), test.ShouldBeTrue)
+		test.That(t, merged.AlignmentErrors, test.ShouldResemble, []float64{0, 0})
+		_, got := merged.Map.At(1000, 50, 0)
+		test.That(t, got, test.ShouldBeTrue)
+
+		pcd, err := merged.PCD()
+		test.That(t, err, test.ShouldBeNil)
+		dims, err := pointcloud.GetPCDMetaData(bytes.NewReader(pcd))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, dims.MaxX, test.ShouldAlmostEqual, 1190)
+	})
+
+	t.Run("scan matching", func(t *testing.T) {
+		// the same corner seen from a session which started a little way off
+		second := roomCorner(t, r3.Vector{X: 3, Y: -2, Z: 1})
+		merged, err := slam.MergeMaps(ctx, []slam.MapToMerge{{Map: first}, {Map: second}})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, merged.AlignmentErrors[1], test.ShouldBeLessThan, 1)
+		aligned := merged.Transforms[1].Point()
+		test.That(t, aligned.X, test.ShouldAlmostEqual, -3, 1)
+		test.That(t, aligned.Y, test.ShouldAlmostEqual, 2, 1)
+		test.That(t, aligned.Z, test.ShouldAlmostEqual, -1, 1)
+	})
+}
@@ -0,0 +1,304 @@
+package vision
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+)
+
+// BenchmarkCommand is the DoCommand, answered by the vision service server for every vision service, which
+// benchmarks the service on the machine it runs on. Its parameters are those of BenchmarkOptions, and it returns
+// the fields of BenchmarkResult.
+const BenchmarkCommand = "benchmark"
+
+// The methods a benchmark can call.
+const (
+	BenchmarkMethodDetections        = "detections"
+	BenchmarkMethodClassifications   = "classifications"
+	BenchmarkMethodObjectPointClouds = "object_point_clouds"
+)
+
+const (
+	defaultBenchmarkFrames = 50
+	maxBenchmarkFrames     = 10000
+	// resourceSampleInterval is how often memory and GPU use are sampled while a benchmark runs.
+	resourceSampleInterval = 100 * time.Millisecond
+)
+
+// BenchmarkOptions configures a benchmark of a vision service.
+type BenchmarkOptions struct {
+	// CameraName is the camera whose frames the service is run on.
+	CameraName string
+	// Method is which of the service's methods to call, one of the BenchmarkMethod constants. Defaults to detections.
+	Method string
+	// Frames is how many frames to run the service on. Defaults to 50.
+	Frames int
+	// NumClassifications is how many classifications to ask for from classifiers. Defaults to 1.
+	NumClassifications int
+	// Warmup is how many frames to run the service on before measuring, since the first calls to a model are often
+	// slower while it is loaded and optimized.
+	Warmup int
+}
+
+// BenchmarkOptionsFromCommand returns the options of a benchmark DoCommand.
+func BenchmarkOptionsFromCommand(cmd map[string]interface{}) (BenchmarkOptions, error) {
+	opts := BenchmarkOptions{}
+	opts.CameraName, _ = cmd["camera_name"].(string)
+	opts.Method, _ = cmd["method"].(string)
+	for key, field := range map[string]*int{
+		"frames":              &opts.Frames,
+		"num_classifications": &opts.NumClassifications,
+		"warmup":              &opts.Warmup,
+	} {
+		value, ok := cmd[key]
+		if !ok {
+			continue
+		}
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			return BenchmarkOptions{}, errors.Errorf("%s must be an integer, got %v", key, value)
+		}
+		*field = int(number)
+	}
+	return opts, opts.validate()
+}
+
+func (opts *BenchmarkOptions) validate() error {
+	if opts.CameraName == "" {
+		return errors.New("camera_name is required")
+	}
+	switch opts.Method {
+	case "":
+		opts.Method = BenchmarkMethodDetections
+	case BenchmarkMethodDetections, BenchmarkMethodClassifications, BenchmarkMethodObjectPointClouds:
+	default:
+		return errors.Errorf("unknown method %q", opts.Method)
+	}
+	if opts.Frames == 0 {
+		opts.Frames = defaultBenchmarkFrames
+	}
+	if opts.Frames < 0 || opts.Frames > maxBenchmarkFrames {
+		return errors.Errorf("frames must be between 1 and %d", maxBenchmarkFrames)
+	}
+	if opts.NumClassifications <= 0 {
+		opts.NumClassifications = 1
+	}
+	if opts.Warmup < 0 {
+		return errors.New("warmup must not be negative")
+	}
+	return nil
+}
+
+// BenchmarkResult is what a benchmark measured. Latencies are of whole calls, including getting the frame from
+// the camera, as users of the service see them.
+type BenchmarkResult struct {
+	Frames                        int
+	Min, Mean, P50, P90, P99, Max time.Duration
+	FramesPerSecond               float64
+	// CPUPercent is the CPU time the process used while the benchmark ran, as a percentage of one core. It is
+	// negative where it cannot be measured.
+	CPUPercent float64
+	// AllocatedBytes is how much memory was allocated per frame, and PeakHeapBytes is the largest the heap was.
+	AllocatedBytes uint64
+	PeakHeapBytes  uint64
+	// GPUPercent and GPUMemoryBytes are the mean utilization and peak memory of the GPU, which are only measured
+	// for NVIDIA GPUs and are negative otherwise.
+	GPUPercent     float64
+	GPUMemoryBytes int64
+}
+
+// ToMap returns the result as a DoCommand response.
+func (r *BenchmarkResult) ToMap() map[string]interface{} {
+	ms := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Millisecond)
+	}
+	resp := map[string]interface{}{
+		"frames":                    r.Frames,
+		"latency_min_ms":            ms(r.Min),
+		"latency_mean_ms":           ms(r.Mean),
+		"latency_p50_ms":            ms(r.P50),
+		"latency_p90_ms":            ms(r.P90),
+		"latency_p99_ms":            ms(r.P99),
+		"latency_max_ms":            ms(r.Max),
+		"frames_per_second":         r.FramesPerSecond,
+		"allocated_bytes_per_frame": float64(r.AllocatedBytes),
+		"peak_heap_bytes":           float64(r.PeakHeapBytes),
+	}
+	if r.CPUPercent >= 0 {
+		resp["cpu_percent"] = r.CPUPercent
+	}
+	if r.GPUPercent >= 0 {
+		resp["gpu_percent"] = r.GPUPercent
+		resp["gpu_memory_bytes"] = float64(r.GPUMemoryBytes)
+	}
+	return resp
+}
+
+// Benchmark runs a vision service on frames from a camera and measures how long it takes and what it uses.
+func Benchmark(ctx context.Context, svc Service, opts BenchmarkOptions) (*BenchmarkResult, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	call := func() error {
+		var err error
+		switch opts.Method {
+		case BenchmarkMethodClassifications:
+			_, err = svc.ClassificationsFromCamera(ctx, opts.CameraName, opts.NumClassifications, nil)
+		case BenchmarkMethodObjectPointClouds:
+			_, err = svc.GetObjectPointClouds(ctx, opts.CameraName, nil)
+		default:
+			_, err = svc.DetectionsFromCamera(ctx, opts.CameraName, nil)
+		}
+		return err
+	}
+	for i := 0; i < opts.Warmup; i++ {
+		if err := call(); err != nil {
+			return nil, errors.Wrap(err, "failed to warm up")
+		}
+	}
+
+	sampler := startResourceSampler()
+	cpuStart, cpuOK := processCPUTime()
+	var memStart runtime.MemStats
+	runtime.ReadMemStats(&memStart)
+	start := time.Now()
+
+	latencies := make([]time.Duration, 0, opts.Frames)
+	for i := 0; i < opts.Frames; i++ {
+		callStart := time.Now()
+		if err := call(); err != nil {
+			sampler.stop()
+			return nil, errors.Wrapf(err, "failed on frame %d", i)
+		}
+		latencies = append(latencies, time.Since(callStart))
+	}
+
+	elapsed := time.Since(start)
+	var memEnd runtime.MemStats
+	runtime.ReadMemStats(&memEnd)
+	cpuEnd, _ := processCPUTime()
+	samples := sampler.stop()
+
+	result := &BenchmarkResult{
+		Frames:          opts.Frames,
+		FramesPerSecond: float64(opts.Frames) / elapsed.Seconds(),
+		CPUPercent:      -1,
+		AllocatedBytes:  (memEnd.TotalAlloc - memStart.TotalAlloc) / uint64(opts.Frames),
+		PeakHeapBytes:   samples.peakHeap,
+		GPUPercent:      samples.gpuPercent,
+		GPUMemoryBytes:  samples.gpuMemory,
+	}
+	if memEnd.HeapAlloc > result.PeakHeapBytes {
+		result.PeakHeapBytes = memEnd.HeapAlloc
+	}
+	if cpuOK {
+		result.CPUPercent = 100 * float64(cpuEnd-cpuStart) / float64(elapsed)
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	var total time.Duration
+	for _, latency := range latencies {
+		total += latency
+	}
+	result.Min, result.Max = latencies[0], latencies[len(latencies)-1]
+	result.Mean = total / time.Duration(len(latencies))
+	result.P50 = percentile(latencies, 50)
+	result.P90 = percentile(latencies, 90)
+	result.P99 = percentile(latencies, 99)
+	return result, nil
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+type resourceSamples struct {
+	peakHeap   uint64
+	gpuPercent float64
+	gpuMemory  int64
+}
+
+// resourceSampler samples heap and GPU use in the background, since both go up and down during a benchmark.
+type resourceSampler struct {
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	samples resourceSamples
+}
+
+func startResourceSampler() *resourceSampler {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &resourceSampler{cancel: cancel, samples: resourceSamples{gpuPercent: -1, gpuMemory: -1}}
+	_, gpuErr := exec.LookPath("nvidia-smi")
+	s.wg.Add(1)
+	goutils.ManagedGo(func() {
+		var gpuTotal float64
+		var gpuCount int
+		for {
+			var mem runtime.MemStats
+			runtime.ReadMemStats(&mem)
+			if mem.HeapAlloc > s.samples.peakHeap {
+				s.samples.peakHeap = mem.HeapAlloc
+			}
+			if gpuErr == nil {
+				if percent, memory, err := nvidiaGPUUsage(ctx); err == nil {
+					gpuTotal += percent
+					gpuCount++
+					s.samples.gpuPercent = gpuTotal / float64(gpuCount)
+					if memory > s.samples.gpuMemory {
+						s.samples.gpuMemory = memory
+					}
+				}
+			}
+			if !goutils.SelectContextOrWait(ctx, resourceSampleInterval) {
+				return
+			}
+		}
+	}, s.wg.Done)
+	return s
+}
+
+func (s *resourceSampler) stop() resourceSamples {
+	s.cancel()
+	s.wg.Wait()
+	return s.samples
+}
+
+// nvidiaGPUUsage returns the utilization percentage and memory used of the first NVIDIA GPU.
+func nvidiaGPUUsage(ctx context.Context) (float64, int64, error) {
+	//nolint:gosec
+	out, err := exec.CommandContext(ctx, "nvidia-smi",
+		"--query-gpu=utilization.gpu,memory.used", "--format=csv,noheader,nounits").Output()
+	if err != nil {
+		return 0, 0, err
+	}
+	line, _, _ := bytes.Cut(out, []byte("\n"))
+	fields := strings.Split(string(line), ",")
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected nvidia-smi output %q", out)
+	}
+	percent, err := strconv.ParseFloat(strings.TrimSpace(fields[0]), 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	memoryMiB, err := strconv.ParseInt(strings.TrimSpace(fields[1]), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return percent, memoryMiB << 20, nil
+}
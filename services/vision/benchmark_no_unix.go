@@ -0,0 +1,12 @@
+//go:build !unix
+// +build !unix
+
+package vision
+
+import (
+	"time"
+)
+
+func processCPUTime() (time.Duration, bool) {
+	return 0, false
+}
@@ -6,8 +6,5 @@ package register
 import (
 	// for vision models.
 	_ "go.viam.com/rdk/services/vision/colordetector"
-	_ "go.viam.com/rdk/services/vision/detectionstosegments"
-	_ "go.viam.com/rdk/services/vision/obstaclesdepth"
 	_ "go.viam.com/rdk/services/vision/obstaclesdistance"
-	_ "go.viam.com/rdk/services/vision/obstaclespointcloud"
 )
@@ -0,0 +1,11 @@
+//go:build !no_cgo && !slim
+
+// Package register registers all relevant vision models and also API specific functions
+package register
+
+import (
+	// for point cloud vision models.
+	_ "go.viam.com/rdk/services/vision/detectionstosegments"
+	_ "go.viam.com/rdk/services/vision/obstaclesdepth"
+	_ "go.viam.com/rdk/services/vision/obstaclespointcloud"
+)
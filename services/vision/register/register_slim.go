@@ -0,0 +1,19 @@
+//go:build !no_cgo && slim
+
+package register
+
+import (
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/vision"
+)
+
+func init() {
+	// Point cloud segmentation needs far more memory than slim builds are meant for.
+	for _, model := range []string{"detector_3d_segmenter", "obstacles_depth", "obstacles_pointcloud"} {
+		resource.RegisterNotBuilt[vision.Service](
+			vision.API,
+			resource.DefaultModelFamily.WithModel(model),
+			"point cloud vision models are left out of slim builds for low-memory devices",
+		)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	commonpb "go.viam.com/api/common/v1"
 	camerapb "go.viam.com/api/component/camera/v1"
 	pb "go.viam.com/api/service/vision/v1"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	"go.viam.com/rdk/pointcloud"
 	"go.viam.com/rdk/protoutils"
@@ -301,7 +302,8 @@ func encodeUnknownType(ctx context.Context, img image.Image, defaultMime string)
 	return imgBytes, mimeType, nil
 }
 
-// DoCommand receives arbitrary commands.
+// DoCommand receives arbitrary commands. The benchmark command is answered here for every vision service, so that
+// it is measured on the machine the service runs on.
 func (server *serviceServer) DoCommand(ctx context.Context,
 	req *commonpb.DoCommandRequest,
 ) (*commonpb.DoCommandResponse, error) {
@@ -312,5 +314,21 @@ func (server *serviceServer) DoCommand(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
-	return protoutils.DoFromResourceServer(ctx, svc, req)
+	cmd := req.GetCommand().AsMap()
+	if cmd["command"] != BenchmarkCommand {
+		return protoutils.DoFromResourceServer(ctx, svc, req)
+	}
+	opts, err := BenchmarkOptionsFromCommand(cmd)
+	if err != nil {
+		return nil, err
+	}
+	result, err := Benchmark(ctx, svc, opts)
+	if err != nil {
+		return nil, err
+	}
+	res, err := structpb.NewStruct(result.ToMap())
+	if err != nil {
+		return nil, err
+	}
+	return &commonpb.DoCommandResponse{Result: res}, nil
 }
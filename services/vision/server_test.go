@@ -4,8 +4,10 @@ import (
 	"context"
 	"image"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
+	commonpb "go.viam.com/api/common/v1"
 	pb "go.viam.com/api/service/vision/v1"
 	"go.viam.com/test"
 	"go.viam.com/utils/artifact"
@@ -17,6 +19,7 @@ import (
 	"go.viam.com/rdk/services/vision"
 	"go.viam.com/rdk/testutils/inject"
 	"go.viam.com/rdk/utils"
+	"go.viam.com/rdk/vision/classification"
 	"go.viam.com/rdk/vision/objectdetection"
 	"go.viam.com/rdk/vision/viscapture"
 )
@@ -179,3 +182,62 @@ func TestServerCaptureAllFromCamera(t *testing.T) {
 
 	test.ShouldResemble(captAllResp.Detections, getDetectionsResp.Detections)
 }
+
+func TestServerBenchmark(t *testing.T) {
+	injectVS := &inject.VisionService{}
+	calls := 0
+	injectVS.ClassificationsFromCameraFunc = func(ctx context.Context, cameraName string,
+		n int, extra map[string]interface{},
+	) (classification.Classifications, error) {
+		if cameraName != "camera" {
+			return nil, errors.New("no such camera")
+		}
+		calls++
+		time.Sleep(time.Millisecond)
+		return classification.Classifications{}, nil
+	}
+	injectVS.DoCommandFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		return cmd, nil
+	}
+	server, err := newServer(map[resource.Name]vision.Service{visName1: injectVS})
+	test.That(t, err, test.ShouldBeNil)
+
+	doCommand := func(cmd map[string]interface{}) (map[string]interface{}, error) {
+		command, err := protoutils.StructToStructPb(cmd)
+		test.That(t, err, test.ShouldBeNil)
+		resp, err := server.DoCommand(context.Background(), &commonpb.DoCommandRequest{Name: testVisionServiceName, Command: command})
+		if err != nil {
+			return nil, err
+		}
+		return resp.Result.AsMap(), nil
+	}
+
+	result, err := doCommand(map[string]interface{}{
+		"command":     vision.BenchmarkCommand,
+		"camera_name": "camera",
+		"method":      vision.BenchmarkMethodClassifications,
+		"frames":      10,
+		"warmup":      2,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, calls, test.ShouldEqual, 12)
+	test.That(t, result["frames"], test.ShouldEqual, 10.)
+	test.That(t, result["latency_min_ms"], test.ShouldBeGreaterThanOrEqualTo, 1.)
+	test.That(t, result["latency_p50_ms"], test.ShouldBeGreaterThanOrEqualTo, result["latency_min_ms"])
+	test.That(t, result["latency_p99_ms"], test.ShouldBeGreaterThanOrEqualTo, result["latency_p90_ms"])
+	test.That(t, result["latency_max_ms"], test.ShouldBeGreaterThanOrEqualTo, result["latency_p99_ms"])
+	test.That(t, result["frames_per_second"], test.ShouldBeGreaterThan, 0.)
+	test.That(t, result["peak_heap_bytes"], test.ShouldBeGreaterThan, 0.)
+
+	_, err = doCommand(map[string]interface{}{"command": vision.BenchmarkCommand, "camera_name": "other"})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = doCommand(map[string]interface{}{"command": vision.BenchmarkCommand})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "camera_name is required")
+	_, err = doCommand(map[string]interface{}{"command": vision.BenchmarkCommand, "camera_name": "camera", "method": "segment"})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "unknown method")
+
+	// other commands are passed on to the service
+	result, err = doCommand(map[string]interface{}{"command": "other"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result["command"], test.ShouldEqual, "other")
+}
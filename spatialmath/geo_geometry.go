@@ -136,10 +136,13 @@ func GeoPoseToPose(point, origin *GeoPose) Pose {
 	v := r3.Vector{X: latDist * 1e-6, Y: lngDist * 1e-6, Z: 0}
 
 	newPoint := origin.Location().PointAtDistanceAndBearing(v.Norm(), absoluteBearing)
+	pt := GeoPointToPoint(newPoint, origin.Location())
+	// altitudes are in meters, poses are in mm
+	pt.Z = 1e3 * (point.Altitude() - origin.Altitude())
 
 	// subtracting the point from the origin results in a right handed angle
 	headingChange := normalizeAngle(origin.Heading() - point.Heading())
-	return NewPose(GeoPointToPoint(newPoint, origin.Location()), &OrientationVectorDegrees{OZ: 1, Theta: headingChange})
+	return NewPose(pt, &OrientationVectorDegrees{OZ: 1, Theta: headingChange})
 }
 
 // GeoPointToPoint returns the point (r3.Vector) which translates the origin to the destination geopoint
@@ -179,6 +182,7 @@ func GeoGeometriesToGeometries(obstacles []*GeoGeometry, origin *geo.Point) []Ge
 // GeoPose is a struct to store to location and heading in a geospatial environment.
 type GeoPose struct {
 	location *geo.Point
+	altitude float64
 	heading  float64
 }
 
@@ -190,17 +194,47 @@ func NewGeoPose(loc *geo.Point, heading float64) *GeoPose {
 	}
 }
 
+// NewGeoPoseWithAltitude constructs a GeoPose from a geo.Point, an altitude in meters, and a heading.
+func NewGeoPoseWithAltitude(loc *geo.Point, altitude, heading float64) *GeoPose {
+	return &GeoPose{
+		location: loc,
+		altitude: altitude,
+		heading:  heading,
+	}
+}
+
 // Location returns the locating coordinates of the GeoPose.
 func (gpo *GeoPose) Location() *geo.Point {
 	return gpo.location
 }
 
+// Altitude returns the altitude of the GeoPose in meters. It is 0 if the GeoPose was constructed without one.
+func (gpo *GeoPose) Altitude() float64 {
+	return gpo.altitude
+}
+
 // Heading returns a number from [0-360) where 0 is north.
 func (gpo *GeoPose) Heading() float64 {
 	return gpo.heading
 }
 
+// DistanceTo returns the straight-line distance in mm from this GeoPose to other, accounting for any difference
+// in altitude. Like GetCartesianDistance, this treats the earth as flat in the neighborhood of the two points.
+func (gpo *GeoPose) DistanceTo(other *GeoPose) float64 {
+	// GreatCircleDistance is in km, altitude is in meters
+	ground := 1e6 * gpo.location.GreatCircleDistance(other.location)
+	climb := 1e3 * (other.altitude - gpo.altitude)
+	return math.Hypot(ground, climb)
+}
+
+// BearingTo returns the initial bearing from this GeoPose to other as a number from [0-360) where 0 is north.
+// Like Heading, this is a left-handed angle.
+func (gpo *GeoPose) BearingTo(other *GeoPose) float64 {
+	return normalizeAngle(gpo.location.BearingTo(other.location))
+}
+
 // PoseToGeoPose converts a pose (which are always in mm) into a GeoPose treating relativeTo as the origin.
+// The Z component of the pose is treated as a change in altitude.
 func PoseToGeoPose(relativeTo *GeoPose, pose Pose) *GeoPose {
 	// poses are always in mm but PointAtDistanceAndBearing expects the pose to be in km so we need to convert
 	kmPoint := pose.Point().Mul(1e-6)
@@ -221,7 +255,8 @@ func PoseToGeoPose(relativeTo *GeoPose, pose Pose) *GeoPose {
 	absoluteBearing := normalizeAngle(bearing + headingInWorld)
 
 	// get the new geopoint at distance poseMagnitude
-	newPosition := relativeTo.Location().PointAtDistanceAndBearing(kmPoint.Norm(), absoluteBearing)
+	// only the horizontal component moves us along the ground; Z is handled as altitude below
+	newPosition := relativeTo.Location().PointAtDistanceAndBearing(math.Hypot(kmPoint.X, kmPoint.Y), absoluteBearing)
 
 	// get the heading of pose p, this is a right-handed value
 	headingRight := pose.Orientation().OrientationVectorDegrees().Theta
@@ -231,8 +266,11 @@ func PoseToGeoPose(relativeTo *GeoPose, pose Pose) *GeoPose {
 
 	poseAbsoluteHeading := normalizeAngle(headingLeft + headingInWorld)
 
+	// poses are in mm but altitudes are in meters
+	altitude := relativeTo.Altitude() + pose.Point().Z*1e-3
+
 	// return the GeoPose at the new position with the absolute heading of pose p, i.e. the heading in the world
-	return NewGeoPose(newPosition, poseAbsoluteHeading)
+	return NewGeoPoseWithAltitude(newPosition, altitude, poseAbsoluteHeading)
 }
 
 // normalizeAngle takes in an angle in degrees and returns an equivalent angle in the domain [0,360).
@@ -249,3 +249,35 @@ func TestPoseToGeoPose(t *testing.T) {
 		})
 	}
 }
+
+func TestGeoPoseAltitude(t *testing.T) {
+	origin := NewGeoPoseWithAltitude(geo.NewPoint(40, -74), 100, 90)
+	pose := NewPose(r3.Vector{X: 1000, Y: 2000, Z: 5000}, &OrientationVectorDegrees{OZ: 1, Theta: 0})
+
+	gp := PoseToGeoPose(origin, pose)
+	test.That(t, gp.Altitude(), test.ShouldAlmostEqual, 105)
+	test.That(t, gp.Heading(), test.ShouldAlmostEqual, 90)
+
+	roundTrip := GeoPoseToPose(gp, origin)
+	test.That(t, PoseAlmostEqualEps(roundTrip, pose, 1e-3), test.ShouldBeTrue)
+
+	// GeoPoses made without an altitude stay on the ground
+	test.That(t, NewGeoPose(geo.NewPoint(0, 0), 0).Altitude(), test.ShouldEqual, 0)
+}
+
+func TestGeoPoseDistanceAndBearing(t *testing.T) {
+	// The number of mm required to move one one thousandth of a degree long or lat from the GPS point (0, 0)
+	mmToOneThousandthDegree := 1.1119492664455873e+05
+
+	origin := NewGeoPose(geo.NewPoint(0, 0), 0)
+	north := NewGeoPose(geo.NewPoint(1e-3, 0), 0)
+	west := NewGeoPose(geo.NewPoint(0, -1e-3), 0)
+
+	test.That(t, origin.DistanceTo(north), test.ShouldAlmostEqual, mmToOneThousandthDegree, 1e-3)
+	test.That(t, origin.BearingTo(north), test.ShouldAlmostEqual, 0)
+	test.That(t, origin.DistanceTo(west), test.ShouldAlmostEqual, mmToOneThousandthDegree, 1e-3)
+	test.That(t, origin.BearingTo(west), test.ShouldAlmostEqual, 270)
+
+	above := NewGeoPoseWithAltitude(geo.NewPoint(0, 0), 3, 0)
+	test.That(t, origin.DistanceTo(above), test.ShouldAlmostEqual, 3000)
+}
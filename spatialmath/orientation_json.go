@@ -1,8 +1,11 @@
 package spatialmath
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+
+	"github.com/pkg/errors"
 )
 
 // OrientationType defines what orientation representations are known.
@@ -105,3 +108,74 @@ func (config *OrientationConfig) ParseConfig() (Orientation, error) {
 		return nil, newOrientationTypeUnsupportedError(string(config.Type))
 	}
 }
+
+// OrientationTypes lists every orientation representation that can be used in an OrientationConfig.
+var OrientationTypes = []OrientationType{
+	OrientationVectorDegreesType,
+	OrientationVectorRadiansType,
+	EulerAnglesType,
+	AxisAnglesType,
+	QuaternionType,
+}
+
+// ParseConfigStrict is like ParseConfig, but also rejects the mistakes that ParseConfig lets
+// through: fields that don't belong to the orientation type (e.g., "theta" instead of "th"), which
+// would otherwise silently be treated as 0, and axes or quaternions with no length.
+func (config *OrientationConfig) ParseConfigStrict() (Orientation, error) {
+	data, err := json.Marshal(config.Value)
+	if err != nil {
+		return nil, err
+	}
+	decode := func(v any) error {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		return dec.Decode(v)
+	}
+
+	switch config.Type {
+	case NoOrientationType:
+		if len(config.Value) != 0 {
+			return nil, errors.New("orientation has a value but no type")
+		}
+	case OrientationVectorDegreesType:
+		err = decode(&OrientationVectorDegrees{})
+	case OrientationVectorRadiansType:
+		err = decode(&OrientationVector{})
+	case EulerAnglesType:
+		err = decode(&EulerAngles{})
+	case AxisAnglesType:
+		var o R4AA
+		if err = decode(&o); err == nil && o.Theta != 0 && o.RX == 0 && o.RY == 0 && o.RZ == 0 {
+			err = errors.New("axis angle has a nonzero angle but no axis; probably X, Y, and Z are all 0")
+		}
+	case QuaternionType:
+		var oj quaternionJSON
+		if err = decode(&oj); err == nil && oj.W == 0 && oj.X == 0 && oj.Y == 0 && oj.Z == 0 {
+			err = errors.New("quaternion has a norm of 0, probably W, X, Y, and Z are all 0")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return config.ParseConfig()
+}
+
+// ConvertOrientation returns the OrientationConfig of the given type that represents the same
+// rotation as o.
+func ConvertOrientation(o Orientation, to OrientationType) (*OrientationConfig, error) {
+	switch to {
+	case OrientationVectorDegreesType:
+		return NewOrientationConfig(o.OrientationVectorDegrees())
+	case OrientationVectorRadiansType:
+		return NewOrientationConfig(o.OrientationVectorRadians())
+	case EulerAnglesType:
+		return NewOrientationConfig(o.EulerAngles())
+	case AxisAnglesType:
+		return NewOrientationConfig(o.AxisAngles())
+	case QuaternionType:
+		q := Quaternion(o.Quaternion())
+		return NewOrientationConfig(&q)
+	default:
+		return nil, newOrientationTypeUnsupportedError(string(to))
+	}
+}
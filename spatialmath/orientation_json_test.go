@@ -3,6 +3,7 @@ package spatialmath
 import (
 	"encoding/json"
 	"io"
+	"math"
 	"os"
 	"testing"
 
@@ -119,3 +120,57 @@ func loadOrientationTests(t *testing.T) map[string]json.RawMessage {
 	test.That(t, err, test.ShouldBeNil)
 	return testMap
 }
+
+func TestParseConfigStrict(t *testing.T) {
+	// a typo'd field is silently ignored by ParseConfig, but not by ParseConfigStrict
+	typo := &OrientationConfig{
+		Type:  OrientationVectorDegreesType,
+		Value: map[string]any{"x": 0, "y": 0, "z": 1, "theta": 90},
+	}
+	o, err := typo.ParseConfig()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, o.OrientationVectorDegrees().Theta, test.ShouldEqual, 0)
+	_, err = typo.ParseConfigStrict()
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "theta")
+
+	good := &OrientationConfig{
+		Type:  OrientationVectorDegreesType,
+		Value: map[string]any{"x": 0, "y": 0, "z": 1, "th": 90},
+	}
+	o, err = good.ParseConfigStrict()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, o.OrientationVectorDegrees().Theta, test.ShouldAlmostEqual, 90)
+
+	noAxis := &OrientationConfig{Type: AxisAnglesType, Value: map[string]any{"th": 1}}
+	_, err = noAxis.ParseConfigStrict()
+	test.That(t, err, test.ShouldNotBeNil)
+
+	zeroQuat := &OrientationConfig{Type: QuaternionType, Value: map[string]any{"w": 0}}
+	_, err = zeroQuat.ParseConfigStrict()
+	test.That(t, err, test.ShouldNotBeNil)
+
+	noType := &OrientationConfig{Value: map[string]any{"roll": 1}}
+	_, err = noType.ParseConfigStrict()
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestConvertOrientation(t *testing.T) {
+	// a 90 degree turn about Z
+	o := &OrientationVectorDegrees{OZ: 1, Theta: 90}
+	for _, oType := range OrientationTypes {
+		cfg, err := ConvertOrientation(o, oType)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cfg.Type, test.ShouldEqual, oType)
+		roundTrip, err := cfg.ParseConfigStrict()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, OrientationAlmostEqual(roundTrip, o), test.ShouldBeTrue)
+	}
+
+	cfg, err := ConvertOrientation(o, EulerAnglesType)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cfg.Value["yaw"], test.ShouldAlmostEqual, math.Pi/2)
+
+	_, err = ConvertOrientation(o, "oiler_angles")
+	test.That(t, err, test.ShouldBeError, newOrientationTypeUnsupportedError("oiler_angles"))
+}
@@ -28,6 +28,8 @@ type AppServiceClient struct {
 		opts ...grpc.CallOption) (*apppb.GetRobotPartLogsResponse, error)
 	UpdateRobotPartFunc func(ctx context.Context, in *apppb.UpdateRobotPartRequest,
 		opts ...grpc.CallOption) (*apppb.UpdateRobotPartResponse, error)
+	GetModuleFunc func(ctx context.Context, in *apppb.GetModuleRequest,
+		opts ...grpc.CallOption) (*apppb.GetModuleResponse, error)
 }
 
 // ListOrganizations calls the injected ListOrganizationsFunc or the real version.
@@ -119,3 +121,13 @@ func (asc *AppServiceClient) GetRobotPartLogs(ctx context.Context, in *apppb.Get
 	}
 	return asc.GetRobotPartLogsFunc(ctx, in, opts...)
 }
+
+// GetModule calls the injected GetModuleFunc or the real version.
+func (asc *AppServiceClient) GetModule(ctx context.Context, in *apppb.GetModuleRequest,
+	opts ...grpc.CallOption,
+) (*apppb.GetModuleResponse, error) {
+	if asc.GetModuleFunc == nil {
+		return asc.AppServiceClient.GetModule(ctx, in, opts...)
+	}
+	return asc.GetModuleFunc(ctx, in, opts...)
+}
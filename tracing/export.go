@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"os"
+	"strconv"
+
+	"go.opencensus.io/trace"
+
+	"go.viam.com/rdk/logging"
+)
+
+// The standard OpenTelemetry environment variables, so that the robot and its modules are configured
+// like any other program exporting to the same collector.
+const (
+	EndpointEnvVar    = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	sampleRatioEnvVar = "OTEL_TRACES_SAMPLER_ARG"
+)
+
+// StartOTLPExport exports the spans of this process to the collector at endpoint until the returned
+// function is called. Every trace is sampled, unless OTEL_TRACES_SAMPLER_ARG gives the ratio to
+// sample; calls continuing a trace sampled elsewhere are always sampled.
+func StartOTLPExport(endpoint, serviceName string, logger logging.Logger) func() {
+	ratio := 1.0
+	if arg := os.Getenv(sampleRatioEnvVar); arg != "" {
+		parsed, err := strconv.ParseFloat(arg, 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			logger.Warnw("ignoring trace sample ratio, which must be from 0 to 1", sampleRatioEnvVar, arg)
+		} else {
+			ratio = parsed
+		}
+	}
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(ratio)})
+
+	exporter := NewOTLPExporter(endpoint, serviceName, logger)
+	trace.RegisterExporter(exporter)
+	logger.Infow("exporting trace spans", "endpoint", endpoint, "sample_ratio", ratio)
+	return func() {
+		trace.UnregisterExporter(exporter)
+		exporter.Close()
+	}
+}
@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opencensus.io/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// namedRequest is implemented by the requests of every resource API, which all carry the name of
+// the resource they are for.
+type namedRequest interface {
+	GetName() string
+}
+
+// UnaryServerInterceptor starts a span for each unary API call, continuing the caller's trace if
+// it sent one, so that spans started by resources while handling the call join that trace.
+func UnaryServerInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	ctx, span := startServerSpan(ctx, info.FullMethod)
+	defer span.End()
+	if named, ok := req.(namedRequest); ok && named.GetName() != "" {
+		span.AddAttributes(trace.StringAttribute("resource", named.GetName()))
+	}
+	resp, err := handler(ctx, req)
+	setStatus(span, err)
+	return resp, err
+}
+
+// StreamServerInterceptor starts a span for each streaming API call, continuing the caller's trace
+// if it sent one.
+func StreamServerInterceptor(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx, span := startServerSpan(ss.Context(), info.FullMethod)
+	defer span.End()
+	err := handler(srv, &ssStreamContextWrapper{ss, ctx})
+	setStatus(span, err)
+	return err
+}
+
+// UnaryClientInterceptor starts a span for each unary call made, and sends it along so that the
+// server can continue the trace.
+func UnaryClientInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply interface{},
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	ctx, span := startClientSpan(ctx, method)
+	defer span.End()
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	setStatus(span, err)
+	return err
+}
+
+// StreamClientInterceptor starts a span for each stream opened, and sends it along so that the
+// server can continue the trace. The span covers opening the stream, not its whole life.
+func StreamClientInterceptor(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	ctx, span := startClientSpan(ctx, method)
+	defer span.End()
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	setStatus(span, err)
+	return stream, err
+}
+
+func startServerSpan(ctx context.Context, method string) (context.Context, *trace.Span) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(TraceparentKey); len(values) > 0 {
+			if parent, ok := ParseTraceparent(values[0]); ok {
+				return trace.StartSpanWithRemoteParent(ctx, method, parent, trace.WithSpanKind(trace.SpanKindServer))
+			}
+		}
+	}
+	return trace.StartSpan(ctx, method, trace.WithSpanKind(trace.SpanKindServer))
+}
+
+func startClientSpan(ctx context.Context, method string) (context.Context, *trace.Span) {
+	ctx, span := trace.StartSpan(ctx, method, trace.WithSpanKind(trace.SpanKindClient))
+	// unsampled spans are sent along too, so that whatever the server samples joins the same trace
+	ctx = metadata.AppendToOutgoingContext(ctx, TraceparentKey, FormatTraceparent(span.SpanContext()))
+	return ctx, span
+}
+
+func setStatus(span *trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	s, _ := status.FromError(err)
+	if s.Code() == codes.OK {
+		return
+	}
+	span.SetStatus(trace.Status{Code: int32(s.Code()), Message: s.Message()})
+}
+
+type ssStreamContextWrapper struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *ssStreamContextWrapper) Context() context.Context {
+	return w.ctx
+}
@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opencensus.io/trace"
+	"go.viam.com/test"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// recordingExporter keeps every span exported to it.
+type recordingExporter struct {
+	spans []*trace.SpanData
+}
+
+func (e *recordingExporter) ExportSpan(span *trace.SpanData) {
+	e.spans = append(e.spans, span)
+}
+
+type fakeRequest struct {
+	name string
+}
+
+func (r *fakeRequest) GetName() string {
+	return r.name
+}
+
+func TestInterceptors(t *testing.T) {
+	exporter := &recordingExporter{}
+	trace.RegisterExporter(exporter)
+	defer trace.UnregisterExporter(exporter)
+
+	// a sampled trace from the caller is continued by the server
+	parent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(TraceparentKey, parent))
+	info := &grpc.UnaryServerInfo{FullMethod: "/viam.component.arm.v1.ArmService/MoveToPosition"}
+
+	var outgoing metadata.MD
+	_, err := UnaryServerInterceptor(ctx, &fakeRequest{"arm1"}, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		// calls made while handling this one carry the trace along
+		return nil, UnaryClientInterceptor(ctx, "/viam.module.v1.ModuleService/Ready", nil, nil, nil,
+			func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				outgoing, _ = metadata.FromOutgoingContext(ctx)
+				return status.Error(codes.Unavailable, "module is restarting")
+			})
+	})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	test.That(t, exporter.spans, test.ShouldHaveLength, 2)
+	client, server := exporter.spans[0], exporter.spans[1]
+	test.That(t, server.Name, test.ShouldEqual, info.FullMethod)
+	test.That(t, server.SpanKind, test.ShouldEqual, trace.SpanKindServer)
+	test.That(t, server.TraceID.String(), test.ShouldEqual, "4bf92f3577b34da6a3ce929d0e0e4736")
+	test.That(t, server.ParentSpanID.String(), test.ShouldEqual, "00f067aa0ba902b7")
+	test.That(t, server.HasRemoteParent, test.ShouldBeTrue)
+	test.That(t, server.Attributes["resource"], test.ShouldEqual, "arm1")
+	test.That(t, server.Code, test.ShouldEqual, int32(codes.Unavailable))
+
+	test.That(t, client.SpanKind, test.ShouldEqual, trace.SpanKindClient)
+	test.That(t, client.TraceID, test.ShouldEqual, server.TraceID)
+	test.That(t, client.ParentSpanID, test.ShouldEqual, server.SpanID)
+	test.That(t, outgoing.Get(TraceparentKey), test.ShouldResemble, []string{FormatTraceparent(client.SpanContext)})
+}
@@ -0,0 +1,286 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opencensus.io/trace"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/utils"
+)
+
+const (
+	otlpTracesPath      = "/v1/traces"
+	otlpBatchSize       = 512
+	otlpMaxQueuedSpans  = 4096
+	otlpExportInterval  = 5 * time.Second
+	otlpRequestTimeout  = 10 * time.Second
+	otlpInstrumentation = "go.viam.com/rdk"
+)
+
+// An OTLPExporter sends finished spans to an OpenTelemetry collector, using the JSON encoding of
+// OTLP over HTTP. Spans are sent in batches from the background, and dropped if the collector
+// can't keep up, so that tracing never slows the robot down.
+type OTLPExporter struct {
+	url         string
+	serviceName string
+	client      *http.Client
+	logger      logging.Logger
+	workers     utils.StoppableWorkers
+
+	mu      sync.Mutex
+	queue   []*trace.SpanData
+	dropped int
+	flush   chan struct{}
+}
+
+// NewOTLPExporter returns an exporter which sends spans to the collector at endpoint, such as
+// "http://localhost:4318", labeled as coming from serviceName. Register it with
+// trace.RegisterExporter, and Close it to send whatever spans are left.
+func NewOTLPExporter(endpoint, serviceName string, logger logging.Logger) *OTLPExporter {
+	url := strings.TrimSuffix(endpoint, "/")
+	if !strings.HasSuffix(url, otlpTracesPath) {
+		url += otlpTracesPath
+	}
+	e := &OTLPExporter{
+		url:         url,
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: otlpRequestTimeout},
+		logger:      logger,
+		flush:       make(chan struct{}, 1),
+	}
+	e.workers = utils.NewStoppableWorkers(e.exportLoop)
+	return e
+}
+
+// ExportSpan queues a finished span to be sent.
+func (e *OTLPExporter) ExportSpan(span *trace.SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.queue) >= otlpMaxQueuedSpans {
+		e.dropped++
+		return
+	}
+	e.queue = append(e.queue, span)
+	if len(e.queue) >= otlpBatchSize {
+		select {
+		case e.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Close stops exporting, after sending whatever spans are queued.
+func (e *OTLPExporter) Close() {
+	e.workers.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), otlpRequestTimeout)
+	defer cancel()
+	e.send(ctx)
+}
+
+func (e *OTLPExporter) exportLoop(ctx context.Context) {
+	ticker := time.NewTicker(otlpExportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-e.flush:
+		}
+		e.send(ctx)
+	}
+}
+
+// send sends every queued span, a batch at a time.
+func (e *OTLPExporter) send(ctx context.Context) {
+	for {
+		e.mu.Lock()
+		batch := e.queue
+		if len(batch) > otlpBatchSize {
+			batch = batch[:otlpBatchSize]
+		}
+		e.queue = e.queue[len(batch):]
+		dropped := e.dropped
+		e.dropped = 0
+		e.mu.Unlock()
+
+		if dropped > 0 {
+			e.logger.Warnw("dropped trace spans the collector could not keep up with", "spans", dropped)
+		}
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.post(ctx, batch); err != nil {
+			e.logger.Debugw("failed to export trace spans", "spans", len(batch), "url", e.url, "error", err)
+			return
+		}
+	}
+}
+
+func (e *OTLPExporter) post(ctx context.Context, spans []*trace.SpanData) error {
+	body, err := json.Marshal(encodeSpans(e.serviceName, spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		utils.UncheckedError(resp.Body.Close())
+	}()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector responded %s", resp.Status)
+	}
+	return nil
+}
+
+// The types below are the parts of the OTLP JSON encoding that spans need. IDs are hex, and 64-bit
+// integers are strings, as in the JSON mapping of protobuf.
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Events            []otlpEvent     `json:"events,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string          `json:"timeUnixNano"`
+	Name         string          `json:"name"`
+	Attributes   []otlpAttribute `json:"attributes,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// OTLP span kinds and status codes.
+const (
+	otlpKindInternal = 1
+	otlpKindServer   = 2
+	otlpKindClient   = 3
+	otlpStatusError  = 2
+)
+
+func encodeSpans(serviceName string, spans []*trace.SpanData) otlpRequest {
+	encoded := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		s := otlpSpan{
+			TraceID:           hex.EncodeToString(span.TraceID[:]),
+			SpanID:            hex.EncodeToString(span.SpanID[:]),
+			Name:              span.Name,
+			Kind:              otlpKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(span.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+			Attributes:        encodeAttributes(span.Attributes),
+		}
+		if span.ParentSpanID != (trace.SpanID{}) {
+			s.ParentSpanID = hex.EncodeToString(span.ParentSpanID[:])
+		}
+		switch span.SpanKind {
+		case trace.SpanKindServer:
+			s.Kind = otlpKindServer
+		case trace.SpanKindClient:
+			s.Kind = otlpKindClient
+		}
+		if span.Code != 0 {
+			s.Status = otlpStatus{Code: otlpStatusError, Message: span.Message}
+		}
+		for _, annotation := range span.Annotations {
+			s.Events = append(s.Events, otlpEvent{
+				TimeUnixNano: strconv.FormatInt(annotation.Time.UnixNano(), 10),
+				Name:         annotation.Message,
+				Attributes:   encodeAttributes(annotation.Attributes),
+			})
+		}
+		encoded = append(encoded, s)
+	}
+
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: encodeAttributes(map[string]interface{}{"service.name": serviceName})},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: otlpInstrumentation},
+			Spans: encoded,
+		}},
+	}}}
+}
+
+func encodeAttributes(attributes map[string]interface{}) []otlpAttribute {
+	encoded := make([]otlpAttribute, 0, len(attributes))
+	for key, value := range attributes {
+		var v otlpValue
+		switch value := value.(type) {
+		case string:
+			v.StringValue = &value
+		case bool:
+			v.BoolValue = &value
+		case int64:
+			i := strconv.FormatInt(value, 10)
+			v.IntValue = &i
+		case float64:
+			v.DoubleValue = &value
+		default:
+			s := fmt.Sprint(value)
+			v.StringValue = &s
+		}
+		encoded = append(encoded, otlpAttribute{Key: key, Value: v})
+	}
+	return encoded
+}
@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opencensus.io/trace"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+)
+
+func TestOTLPExporter(t *testing.T) {
+	var mu sync.Mutex
+	var requests []otlpRequest
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		test.That(t, r.URL.Path, test.ShouldEqual, "/v1/traces")
+		test.That(t, r.Header.Get("Content-Type"), test.ShouldEqual, "application/json")
+		var req otlpRequest
+		test.That(t, json.NewDecoder(r.Body).Decode(&req), test.ShouldBeNil)
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+	}))
+	defer collector.Close()
+
+	exporter := NewOTLPExporter(collector.URL, "viam-server", logging.NewTestLogger(t))
+	start := time.Unix(1700000000, 0)
+	sc, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	exporter.ExportSpan(&trace.SpanData{
+		SpanContext:  sc,
+		ParentSpanID: trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+		SpanKind:     trace.SpanKindServer,
+		Name:         "/viam.component.arm.v1.ArmService/MoveToPosition",
+		StartTime:    start,
+		EndTime:      start.Add(time.Second),
+		Attributes:   map[string]interface{}{"resource": "arm1", "address": int64(0x29)},
+		Status:       trace.Status{Code: 14, Message: "arm is unplugged"},
+	})
+	exporter.Close()
+
+	test.That(t, requests, test.ShouldHaveLength, 1)
+	resourceSpans := requests[0].ResourceSpans[0]
+	test.That(t, *resourceSpans.Resource.Attributes[0].Value.StringValue, test.ShouldEqual, "viam-server")
+	spans := resourceSpans.ScopeSpans[0].Spans
+	test.That(t, spans, test.ShouldHaveLength, 1)
+	span := spans[0]
+	test.That(t, span.TraceID, test.ShouldEqual, "4bf92f3577b34da6a3ce929d0e0e4736")
+	test.That(t, span.SpanID, test.ShouldEqual, "00f067aa0ba902b7")
+	test.That(t, span.ParentSpanID, test.ShouldEqual, "0102030405060708")
+	test.That(t, span.Kind, test.ShouldEqual, otlpKindServer)
+	test.That(t, span.StartTimeUnixNano, test.ShouldEqual, "1700000000000000000")
+	test.That(t, span.EndTimeUnixNano, test.ShouldEqual, "1700000001000000000")
+	test.That(t, span.Status, test.ShouldResemble, otlpStatus{Code: otlpStatusError, Message: "arm is unplugged"})
+	attributes := map[string]otlpValue{}
+	for _, attribute := range span.Attributes {
+		attributes[attribute.Key] = attribute.Value
+	}
+	test.That(t, *attributes["resource"].StringValue, test.ShouldEqual, "arm1")
+	test.That(t, *attributes["address"].IntValue, test.ShouldEqual, "41")
+}
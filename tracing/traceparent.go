@@ -0,0 +1,54 @@
+// Package tracing carries trace spans across process boundaries, from incoming API calls through
+// modules and remotes, and exports them to OpenTelemetry collectors.
+package tracing
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"go.opencensus.io/trace"
+)
+
+// TraceparentKey is the W3C Trace Context header, which is also the gRPC metadata key spans are
+// propagated through, so that tools speaking OpenTelemetry can join their traces with ours.
+const TraceparentKey = "traceparent"
+
+// FormatTraceparent encodes a span context as a traceparent header value.
+func FormatTraceparent(sc trace.SpanContext) string {
+	flags := 0
+	if sc.IsSampled() {
+		flags = 1
+	}
+	return fmt.Sprintf("00-%s-%s-%02x", hex.EncodeToString(sc.TraceID[:]), hex.EncodeToString(sc.SpanID[:]), flags)
+}
+
+// ParseTraceparent decodes a traceparent header value. It returns false if the value is malformed
+// or names no trace.
+func ParseTraceparent(value string) (trace.SpanContext, bool) {
+	var sc trace.SpanContext
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	// later versions may append fields, but must keep these first four
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return sc, false
+	}
+	traceID, err := hex.DecodeString(parts[1])
+	if err != nil || len(traceID) != len(sc.TraceID) {
+		return sc, false
+	}
+	spanID, err := hex.DecodeString(parts[2])
+	if err != nil || len(spanID) != len(sc.SpanID) {
+		return sc, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || len(flags) != 1 {
+		return sc, false
+	}
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	if sc.TraceID == (trace.TraceID{}) || sc.SpanID == (trace.SpanID{}) {
+		return sc, false
+	}
+	sc.TraceOptions = trace.TraceOptions(flags[0] & 1)
+	return sc, true
+}
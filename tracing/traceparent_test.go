@@ -0,0 +1,39 @@
+package tracing
+
+import (
+	"testing"
+
+	"go.opencensus.io/trace"
+	"go.viam.com/test"
+)
+
+func TestTraceparent(t *testing.T) {
+	value := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, ok := ParseTraceparent(value)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, sc.IsSampled(), test.ShouldBeTrue)
+	test.That(t, sc.TraceID.String(), test.ShouldEqual, "4bf92f3577b34da6a3ce929d0e0e4736")
+	test.That(t, sc.SpanID.String(), test.ShouldEqual, "00f067aa0ba902b7")
+	test.That(t, FormatTraceparent(sc), test.ShouldEqual, value)
+
+	sc.TraceOptions = trace.TraceOptions(0)
+	test.That(t, FormatTraceparent(sc), test.ShouldEqual, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+
+	// later versions may add fields
+	_, ok = ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra")
+	test.That(t, ok, test.ShouldBeTrue)
+
+	for _, bad := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902zz-01",
+	} {
+		_, ok := ParseTraceparent(bad)
+		test.That(t, ok, test.ShouldBeFalse)
+	}
+}
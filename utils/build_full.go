@@ -0,0 +1,8 @@
+//go:build !slim
+
+package utils
+
+// SlimBuild is whether this binary was built with the slim build tag, which leaves out built-in ML
+// runtimes and heavy vision models and shrinks in-memory buffers, for devices with as little as
+// 512MB of memory such as ARM32 gateways.
+const SlimBuild = false
@@ -21,6 +21,14 @@ const (
 	// MimeTypeRawDepth is for depth images.
 	MimeTypeRawDepth = "image/vnd.viam.dep"
 
+	// MimeTypeRawI420 is for planar YUV 4:2:0 images, which many cameras produce natively. This uses
+	// a custom header, as explained in the comments for rimage.I420MagicNumber.
+	MimeTypeRawI420 = "image/vnd.viam.i420"
+
+	// MimeTypeRawNV12 is for semi-planar YUV 4:2:0 images. This uses a custom header, as explained
+	// in the comments for rimage.NV12MagicNumber.
+	MimeTypeRawNV12 = "image/vnd.viam.nv12"
+
 	// MimeTypeJPEG is regular jpgs.
 	MimeTypeJPEG = "image/jpeg"
 
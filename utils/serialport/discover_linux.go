@@ -0,0 +1,84 @@
+//go:build linux
+
+package serialport
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sysfsRoot and devRoot are where sysfs and the device files are, which tests replace with fakes.
+var (
+	sysfsRoot = "/sys"
+	devRoot   = "/dev"
+)
+
+// ListUSBDevices returns every serial device connected by USB, as sysfs describes them.
+func ListUSBDevices() ([]USBDevice, error) {
+	ttys, err := os.ReadDir(filepath.Join(sysfsRoot, "class", "tty"))
+	if err != nil {
+		return nil, err
+	}
+
+	var devices []USBDevice
+	for _, tty := range ttys {
+		// Virtual terminals have no device at all, and others, such as the board's own UARTs, have
+		// no USB device above them.
+		deviceDir, err := filepath.EvalSymlinks(filepath.Join(sysfsRoot, "class", "tty", tty.Name(), "device"))
+		if err != nil {
+			continue
+		}
+		usbDir, ok := findUSBDeviceDir(deviceDir)
+		if !ok {
+			continue
+		}
+		vendorID, err := readUSBID(usbDir, "idVendor")
+		if err != nil {
+			continue
+		}
+		productID, err := readUSBID(usbDir, "idProduct")
+		if err != nil {
+			continue
+		}
+		// not every device has a serial number
+		serialNumber, _ := readSysfsFile(usbDir, "serial")
+
+		devices = append(devices, USBDevice{
+			Path:         filepath.Join(devRoot, tty.Name()),
+			VendorID:     vendorID,
+			ProductID:    productID,
+			SerialNumber: serialNumber,
+		})
+	}
+	return devices, nil
+}
+
+// findUSBDeviceDir walks up from a tty's device to the USB device it belongs to, which is the first
+// directory with a vendor ID in it.
+func findUSBDeviceDir(dir string) (string, bool) {
+	root := filepath.Clean(sysfsRoot)
+	for dir != root && dir != filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, "idVendor")); err == nil {
+			return dir, true
+		}
+		dir = filepath.Dir(dir)
+	}
+	return "", false
+}
+
+func readUSBID(dir, name string) (uint16, error) {
+	id, err := readSysfsFile(dir, name)
+	if err != nil {
+		return 0, err
+	}
+	return parseUSBID(id)
+}
+
+func readSysfsFile(dir, name string) (string, error) {
+	contents, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(contents)), nil
+}
@@ -0,0 +1,134 @@
+//go:build linux
+
+package serialport
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+)
+
+// fakeSysfs is a sysfs in a temporary directory, with USB serial devices plugged into it as tests
+// need. It always has a virtual terminal and a UART, which aren't USB devices at all.
+type fakeSysfs struct {
+	t    *testing.T
+	root string
+}
+
+func newFakeSysfs(t *testing.T) *fakeSysfs {
+	t.Helper()
+	root := t.TempDir()
+	fs := &fakeSysfs{t: t, root: root}
+
+	test.That(t, os.MkdirAll(filepath.Join(root, "class", "tty", "tty0"), 0o755), test.ShouldBeNil)
+	uart := filepath.Join(root, "devices", "platform", "serial8250", "tty", "ttyS0")
+	test.That(t, os.MkdirAll(uart, 0o755), test.ShouldBeNil)
+	fs.link("ttyS0", uart)
+
+	oldRoot, oldDev := sysfsRoot, devRoot
+	sysfsRoot, devRoot = root, "/dev"
+	t.Cleanup(func() { sysfsRoot, devRoot = oldRoot, oldDev })
+	return fs
+}
+
+// plug adds a USB device on the given port with a tty of the given name.
+func (fs *fakeSysfs) plug(usbPort, tty, vendorID, productID, serialNumber string) {
+	usbDir := filepath.Join(fs.root, "devices", "pci0000:00", "usb1", usbPort)
+	ttyDir := filepath.Join(usbDir, usbPort+":1.0", tty)
+	test.That(fs.t, os.MkdirAll(ttyDir, 0o755), test.ShouldBeNil)
+	fs.write(usbDir, "idVendor", vendorID)
+	fs.write(usbDir, "idProduct", productID)
+	if serialNumber != "" {
+		fs.write(usbDir, "serial", serialNumber)
+	}
+	fs.link(tty, ttyDir)
+}
+
+// unplug removes the USB device on the given port, and its tty.
+func (fs *fakeSysfs) unplug(usbPort, tty string) {
+	test.That(fs.t, os.RemoveAll(filepath.Join(fs.root, "devices", "pci0000:00", "usb1", usbPort)), test.ShouldBeNil)
+	test.That(fs.t, os.RemoveAll(filepath.Join(fs.root, "class", "tty", tty)), test.ShouldBeNil)
+}
+
+func (fs *fakeSysfs) write(dir, name, contents string) {
+	test.That(fs.t, os.WriteFile(filepath.Join(dir, name), []byte(contents+"\n"), 0o644), test.ShouldBeNil)
+}
+
+func (fs *fakeSysfs) link(tty, deviceDir string) {
+	classDir := filepath.Join(fs.root, "class", "tty", tty)
+	test.That(fs.t, os.MkdirAll(classDir, 0o755), test.ShouldBeNil)
+	test.That(fs.t, os.Symlink(deviceDir, filepath.Join(classDir, "device")), test.ShouldBeNil)
+}
+
+func TestListUSBDevices(t *testing.T) {
+	fs := newFakeSysfs(t)
+	fs.plug("1-1", "ttyACM0", "1546", "01a9", "")
+	fs.plug("1-2", "ttyUSB0", "0403", "6001", "A10K1ABC")
+
+	devices, err := ListUSBDevices()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, devices, test.ShouldResemble, []USBDevice{
+		{Path: "/dev/ttyACM0", VendorID: 0x1546, ProductID: 0x01a9},
+		{Path: "/dev/ttyUSB0", VendorID: 0x0403, ProductID: 0x6001, SerialNumber: "A10K1ABC"},
+	})
+}
+
+func TestFindUSBDevice(t *testing.T) {
+	fs := newFakeSysfs(t)
+	fs.plug("1-1", "ttyUSB0", "0403", "6001", "A")
+	fs.plug("1-2", "ttyUSB1", "0403", "6001", "B")
+	fs.plug("1-3", "ttyACM0", "1546", "01a9", "")
+
+	path, err := FindUSBDevice(USBMatch{VendorID: "1546"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, path, test.ShouldEqual, "/dev/ttyACM0")
+
+	path, err = FindUSBDevice(USBMatch{VendorID: "0403", SerialNumber: "B"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, path, test.ShouldEqual, "/dev/ttyUSB1")
+
+	_, err = FindUSBDevice(USBMatch{VendorID: "0403"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "give a serial_number")
+
+	_, err = FindUSBDevice(USBMatch{VendorID: "10c4"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no USB serial device found")
+}
+
+func TestPortFollowsReenumeratedDevice(t *testing.T) {
+	defer func(interval time.Duration) { reopenInterval = interval }(reopenInterval)
+	reopenInterval = time.Millisecond
+
+	fs := newFakeSysfs(t)
+	fs.plug("1-1", "ttyUSB0", "0403", "6001", "A")
+
+	bus := &fakeBus{}
+	first := newFakeDevice("/dev/ttyUSB0")
+	bus.plug(first)
+	port, err := openPort(OpenOptions{USB: &USBMatch{SerialNumber: "A"}}, bus.open, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer port.Close()
+	test.That(t, port.Path(), test.ShouldEqual, "/dev/ttyUSB0")
+
+	// the adapter resets, and comes back as ttyUSB1
+	bus.plug(nil)
+	close(first.unplugged)
+	fs.unplug("1-1", "ttyUSB0")
+	fs.plug("1-1", "ttyUSB1", "0403", "6001", "A")
+	second := newFakeDevice("/dev/ttyUSB1")
+	second.reads <- []byte("$GPGGA")
+	bus.plug(second)
+
+	buf := make([]byte, 10)
+	n, err := port.Read(buf)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(buf[:n]), test.ShouldEqual, "$GPGGA")
+	test.That(t, port.Path(), test.ShouldEqual, "/dev/ttyUSB1")
+	test.That(t, bus.opened, test.ShouldResemble, []string{"/dev/ttyUSB0", "/dev/ttyUSB1"})
+}
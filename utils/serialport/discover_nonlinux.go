@@ -0,0 +1,11 @@
+//go:build !linux
+
+package serialport
+
+import "errors"
+
+// ListUSBDevices returns every serial device connected by USB. Finding them is only supported on
+// Linux so far; elsewhere, give the device's path instead.
+func ListUSBDevices() ([]USBDevice, error) {
+	return nil, errors.New("finding serial devices by their USB IDs is only supported on Linux")
+}
@@ -0,0 +1,325 @@
+// Package serialport opens serial devices, either at a fixed path or by finding the USB device
+// with given IDs, and keeps them open across brief disconnects.
+//
+// A USB serial adapter which is unplugged and plugged back in, or which resets, often comes back
+// under a different name (ttyUSB0 becomes ttyUSB1). A Port looks the device up again whenever it
+// reconnects, so drivers using one don't need their configs changed, or rebuilding, when that
+// happens.
+package serialport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/jacobsa/go-serial/serial"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+// maxBufferedWrites is how many bytes written while the device is disconnected are kept, to be
+// written once it's back. Past that, the oldest are dropped.
+const maxBufferedWrites = 64 * 1024
+
+// reopenInterval is how long to wait between attempts to reopen a disconnected device.
+var reopenInterval = time.Second
+
+// USBMatch picks out a USB serial device by its IDs, written in hex as lsusb shows them. Any field
+// left empty matches every device.
+type USBMatch struct {
+	VendorID     string `json:"vendor_id,omitempty"`
+	ProductID    string `json:"product_id,omitempty"`
+	SerialNumber string `json:"serial_number,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (m *USBMatch) Validate(path string) error {
+	if m.VendorID == "" && m.ProductID == "" && m.SerialNumber == "" {
+		return resource.NewConfigValidationError(path,
+			errors.New("at least one of vendor_id, product_id, and serial_number is required"))
+	}
+	if _, err := parseUSBID(m.VendorID); err != nil {
+		return resource.NewConfigValidationError(path, fmt.Errorf("invalid vendor_id: %w", err))
+	}
+	if _, err := parseUSBID(m.ProductID); err != nil {
+		return resource.NewConfigValidationError(path, fmt.Errorf("invalid product_id: %w", err))
+	}
+	return nil
+}
+
+func (m USBMatch) String() string {
+	parts := []string{}
+	if m.VendorID != "" {
+		parts = append(parts, "vendor_id "+m.VendorID)
+	}
+	if m.ProductID != "" {
+		parts = append(parts, "product_id "+m.ProductID)
+	}
+	if m.SerialNumber != "" {
+		parts = append(parts, "serial_number "+m.SerialNumber)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// matches returns whether dev has the IDs asked for.
+func (m USBMatch) matches(dev USBDevice) bool {
+	if id, err := parseUSBID(m.VendorID); err != nil || (m.VendorID != "" && id != dev.VendorID) {
+		return false
+	}
+	if id, err := parseUSBID(m.ProductID); err != nil || (m.ProductID != "" && id != dev.ProductID) {
+		return false
+	}
+	return m.SerialNumber == "" || m.SerialNumber == dev.SerialNumber
+}
+
+// parseUSBID parses a vendor or product ID written in hex, with or without a leading 0x. The empty
+// string parses as 0.
+func parseUSBID(id string) (uint16, error) {
+	if id == "" {
+		return 0, nil
+	}
+	parsed, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(id), "0x"), 16, 16)
+	return uint16(parsed), err
+}
+
+// USBDevice is a serial device connected by USB.
+type USBDevice struct {
+	Path         string
+	VendorID     uint16
+	ProductID    uint16
+	SerialNumber string
+}
+
+// FindUSBDevice returns the path of the one USB serial device matching m.
+func FindUSBDevice(m USBMatch) (string, error) {
+	devices, err := ListUSBDevices()
+	if err != nil {
+		return "", err
+	}
+	var paths []string
+	for _, dev := range devices {
+		if m.matches(dev) {
+			paths = append(paths, dev.Path)
+		}
+	}
+	sort.Strings(paths)
+
+	switch len(paths) {
+	case 0:
+		return "", fmt.Errorf("no USB serial device found with %s", m)
+	case 1:
+		return paths[0], nil
+	default:
+		return "", fmt.Errorf("USB serial devices %s all have %s; give a serial_number to pick one",
+			strings.Join(paths, ", "), m)
+	}
+}
+
+// OpenOptions says which serial device to open, and how.
+type OpenOptions struct {
+	// Path is the device to open, such as /dev/ttyUSB0. It is ignored if USB is set.
+	Path string
+	// USB, if set, finds the device to open by its USB IDs instead, each time it is (re)opened.
+	USB      *USBMatch
+	BaudRate uint
+	// MinimumReadSize is how many bytes reads wait for, at least.
+	MinimumReadSize uint
+}
+
+// resolve returns the path of the device to open.
+func (opts OpenOptions) resolve() (string, error) {
+	if opts.USB != nil {
+		return FindUSBDevice(*opts.USB)
+	}
+	if opts.Path == "" {
+		return "", errors.New("no serial path given")
+	}
+	return opts.Path, nil
+}
+
+// Port is a serial device which is reopened whenever it stops working, such as when it's briefly
+// unplugged. Reads wait for the device to come back. Writes made while it's away are buffered, and
+// written once it's back.
+type Port struct {
+	opts   OpenOptions
+	open   func(path string) (io.ReadWriteCloser, error)
+	logger logging.Logger
+	clock  clock.Clock
+
+	closed     context.Context
+	markClosed func()
+
+	mu      sync.Mutex
+	dev     io.ReadWriteCloser
+	path    string
+	pending []byte
+}
+
+// Open opens the serial device opts describes.
+func Open(opts OpenOptions, logger logging.Logger) (*Port, error) {
+	return openPort(opts, func(path string) (io.ReadWriteCloser, error) {
+		return serial.Open(serial.OpenOptions{
+			PortName:        path,
+			BaudRate:        opts.BaudRate,
+			DataBits:        8,
+			StopBits:        1,
+			MinimumReadSize: opts.MinimumReadSize,
+		})
+	}, logger)
+}
+
+func openPort(opts OpenOptions, open func(path string) (io.ReadWriteCloser, error), logger logging.Logger) (*Port, error) {
+	closed, markClosed := context.WithCancel(context.Background())
+	p := &Port{opts: opts, open: open, logger: logger, clock: clock.New(), closed: closed, markClosed: markClosed}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.reopen(); err != nil {
+		markClosed()
+		return nil, err
+	}
+	return p, nil
+}
+
+// Path returns the path of the device currently open, or last open if it's disconnected.
+func (p *Port) Path() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.path
+}
+
+// reopen finds and opens the device, then writes out whatever was buffered while it was away.
+// The caller must hold mu.
+func (p *Port) reopen() error {
+	path, err := p.opts.resolve()
+	if err != nil {
+		return err
+	}
+	dev, err := p.open(path)
+	if err != nil {
+		return err
+	}
+	if len(p.pending) > 0 {
+		if _, err := dev.Write(p.pending); err != nil {
+			return errors.Join(err, dev.Close())
+		}
+		p.pending = nil
+	}
+	p.dev, p.path = dev, path
+	return nil
+}
+
+// device returns the open device, reopening it if need be. It waits between attempts until the
+// device is back, or the port is closed.
+func (p *Port) device() (io.ReadWriteCloser, error) {
+	for {
+		p.mu.Lock()
+		if p.closed.Err() != nil {
+			p.mu.Unlock()
+			return nil, io.ErrClosedPipe
+		}
+		if p.dev != nil {
+			dev := p.dev
+			p.mu.Unlock()
+			return dev, nil
+		}
+		err := p.reopen()
+		dev, path := p.dev, p.path
+		p.mu.Unlock()
+
+		if err == nil {
+			p.logger.Infof("reconnected to serial device %s", path)
+			return dev, nil
+		}
+		p.logger.Debugf("can't reopen serial device yet: %s", err)
+		select {
+		case <-p.closed.Done():
+			return nil, io.ErrClosedPipe
+		case <-p.clock.After(reopenInterval):
+		}
+	}
+}
+
+// disconnect closes dev after it failed with err, unless it has already been replaced. The caller
+// must hold mu.
+func (p *Port) disconnect(dev io.ReadWriteCloser, err error) {
+	if p.dev != dev {
+		return
+	}
+	p.logger.Warnf("lost serial device %s, will reconnect: %s", p.path, err)
+	utils.UncheckedError(dev.Close())
+	p.dev = nil
+}
+
+// buffer keeps data to write once the device is back. The caller must hold mu.
+func (p *Port) buffer(data []byte) {
+	p.pending = append(p.pending, data...)
+	if excess := len(p.pending) - maxBufferedWrites; excess > 0 {
+		p.pending = p.pending[excess:]
+	}
+}
+
+// Read reads from the device, waiting for it to come back if it's disconnected.
+func (p *Port) Read(b []byte) (int, error) {
+	for {
+		dev, err := p.device()
+		if err != nil {
+			return 0, err
+		}
+		n, err := dev.Read(b)
+		if err == nil || n > 0 {
+			// an error with data will come round again on the next read
+			return n, nil
+		}
+		if p.closed.Err() != nil {
+			return 0, io.ErrClosedPipe
+		}
+		p.mu.Lock()
+		p.disconnect(dev, err)
+		p.mu.Unlock()
+	}
+}
+
+// Write writes to the device. If it's disconnected, the data is kept to write once it's back, so
+// the write succeeds anyway.
+func (p *Port) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed.Err() != nil {
+		return 0, io.ErrClosedPipe
+	}
+
+	// If the device is away, try bringing it straight back rather than waiting for the next read to.
+	if p.dev == nil && p.reopen() != nil {
+		p.buffer(b)
+		return len(b), nil
+	}
+	if _, err := p.dev.Write(b); err != nil {
+		p.disconnect(p.dev, err)
+		p.buffer(b)
+	}
+	return len(b), nil
+}
+
+// Close closes the device. Reads waiting on it return io.ErrClosedPipe.
+func (p *Port) Close() error {
+	p.markClosed()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pending = nil
+	if p.dev == nil {
+		return nil
+	}
+	err := p.dev.Close()
+	p.dev = nil
+	return err
+}
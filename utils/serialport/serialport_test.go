@@ -0,0 +1,197 @@
+package serialport
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+)
+
+// fakeDevice is a serial device which can be unplugged.
+type fakeDevice struct {
+	path      string
+	reads     chan []byte
+	unplugged chan struct{}
+
+	mu      sync.Mutex
+	written []byte
+	closed  bool
+}
+
+func newFakeDevice(path string) *fakeDevice {
+	return &fakeDevice{path: path, reads: make(chan []byte, 10), unplugged: make(chan struct{})}
+}
+
+func (d *fakeDevice) Read(p []byte) (int, error) {
+	select {
+	case data := <-d.reads:
+		return copy(p, data), nil
+	case <-d.unplugged:
+		return 0, io.EOF
+	}
+}
+
+func (d *fakeDevice) Write(p []byte) (int, error) {
+	select {
+	case <-d.unplugged:
+		return 0, io.ErrClosedPipe
+	default:
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.written = append(d.written, p...)
+	return len(p), nil
+}
+
+func (d *fakeDevice) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closed = true
+	return nil
+}
+
+func (d *fakeDevice) Written() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return string(d.written)
+}
+
+// fakeBus hands out whichever device is plugged in at the time.
+type fakeBus struct {
+	mu      sync.Mutex
+	current *fakeDevice
+	opened  []string
+}
+
+func (b *fakeBus) plug(dev *fakeDevice) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.current = dev
+}
+
+func (b *fakeBus) open(path string) (io.ReadWriteCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.opened = append(b.opened, path)
+	if b.current == nil {
+		return nil, errors.New("no such device")
+	}
+	return b.current, nil
+}
+
+func TestPortReconnects(t *testing.T) {
+	defer func(interval time.Duration) { reopenInterval = interval }(reopenInterval)
+	reopenInterval = time.Millisecond
+
+	bus := &fakeBus{}
+	first := newFakeDevice("/dev/ttyUSB0")
+	bus.plug(first)
+	port, err := openPort(OpenOptions{Path: "/dev/ttyUSB0"}, bus.open, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer port.Close()
+
+	n, err := port.Write([]byte("a"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, n, test.ShouldEqual, 1)
+	test.That(t, first.Written(), test.ShouldEqual, "a")
+
+	buf := make([]byte, 10)
+	first.reads <- []byte("hello")
+	n, err = port.Read(buf)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(buf[:n]), test.ShouldEqual, "hello")
+
+	// unplug the device: the next read waits for it to come back
+	bus.plug(nil)
+	close(first.unplugged)
+	read := make(chan string)
+	go func() {
+		n, err := port.Read(buf)
+		if err != nil {
+			read <- err.Error()
+			return
+		}
+		read <- string(buf[:n])
+	}()
+
+	// writes made while it's away still succeed, and are written once it's back
+	n, err = port.Write([]byte("b"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, n, test.ShouldEqual, 1)
+	n, err = port.Write([]byte("c"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, n, test.ShouldEqual, 1)
+
+	second := newFakeDevice("/dev/ttyUSB0")
+	second.reads <- []byte("back")
+	bus.plug(second)
+	test.That(t, <-read, test.ShouldEqual, "back")
+	test.That(t, second.Written(), test.ShouldEqual, "bc")
+	test.That(t, first.Written(), test.ShouldEqual, "a")
+
+	first.mu.Lock()
+	test.That(t, first.closed, test.ShouldBeTrue)
+	first.mu.Unlock()
+}
+
+func TestPortCloseUnblocksRead(t *testing.T) {
+	defer func(interval time.Duration) { reopenInterval = interval }(reopenInterval)
+	reopenInterval = time.Millisecond
+
+	bus := &fakeBus{}
+	dev := newFakeDevice("/dev/ttyUSB0")
+	bus.plug(dev)
+	port, err := openPort(OpenOptions{Path: "/dev/ttyUSB0"}, bus.open, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+
+	// the device goes away for good, so the read keeps waiting for it
+	bus.plug(nil)
+	close(dev.unplugged)
+	readErr := make(chan error)
+	go func() {
+		_, err := port.Read(make([]byte, 1))
+		readErr <- err
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	test.That(t, port.Close(), test.ShouldBeNil)
+	test.That(t, <-readErr, test.ShouldBeError, io.ErrClosedPipe)
+
+	_, err = port.Write([]byte("a"))
+	test.That(t, err, test.ShouldBeError, io.ErrClosedPipe)
+}
+
+func TestOpenFailsWithoutDevice(t *testing.T) {
+	bus := &fakeBus{}
+	_, err := openPort(OpenOptions{Path: "/dev/ttyUSB0"}, bus.open, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, err = openPort(OpenOptions{}, bus.open, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, bus.opened, test.ShouldResemble, []string{"/dev/ttyUSB0"})
+}
+
+func TestPortBufferLimit(t *testing.T) {
+	p := &Port{}
+	p.buffer(make([]byte, maxBufferedWrites-1))
+	p.buffer([]byte("xyz"))
+	test.That(t, len(p.pending), test.ShouldEqual, maxBufferedWrites)
+	test.That(t, string(p.pending[len(p.pending)-3:]), test.ShouldEqual, "xyz")
+}
+
+func TestUSBMatch(t *testing.T) {
+	dev := USBDevice{Path: "/dev/ttyACM0", VendorID: 0x1546, ProductID: 0x01a9, SerialNumber: "A1"}
+	test.That(t, USBMatch{VendorID: "1546"}.matches(dev), test.ShouldBeTrue)
+	test.That(t, USBMatch{VendorID: "0x1546", ProductID: "01A9"}.matches(dev), test.ShouldBeTrue)
+	test.That(t, USBMatch{VendorID: "1546", SerialNumber: "A2"}.matches(dev), test.ShouldBeFalse)
+	test.That(t, USBMatch{ProductID: "6001"}.matches(dev), test.ShouldBeFalse)
+
+	test.That(t, (&USBMatch{VendorID: "1546"}).Validate("path"), test.ShouldBeNil)
+	test.That(t, (&USBMatch{}).Validate("path"), test.ShouldNotBeNil)
+	test.That(t, (&USBMatch{VendorID: "usb"}).Validate("path"), test.ShouldNotBeNil)
+}
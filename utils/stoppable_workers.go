@@ -5,6 +5,8 @@ import (
 	"sync"
 
 	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/metrics"
 )
 
 // TODO: When this struct is widely used and feature complete, move this to goutils instead of
@@ -56,6 +58,15 @@ func (sw *stoppableWorkersImpl) AddWorkers(funcs ...func(context.Context)) {
 		f := f
 		goutils.PanicCapturingGo(func() {
 			defer sw.activeBackgroundWorkers.Done()
+			done := metrics.TrackBackgroundWorker()
+			defer func() {
+				recovered := recover()
+				done(recovered)
+				if recovered != nil {
+					// let PanicCapturingGo log it as usual
+					panic(recovered)
+				}
+			}()
 			f(sw.cancelCtx)
 		})
 	}
@@ -0,0 +1,115 @@
+/*
+Package main provides the signer of a fleet CA, which issues the machines of a fleet the certificates they use for
+mutual TLS with each other. It is the only holder of the CA's private key, and should run on one trusted host the
+machines can reach. It is configured with a file of the token each machine authenticates with, and the names it
+may be issued certificates for:
+
+	{
+		"some-long-random-token-for-robot1": ["robot1", "robot1.local"],
+		"some-long-random-token-for-robot2": ["robot2", "robot2.local"]
+	}
+
+Each machine's network config then has a fleet_ca such as:
+
+	{
+		"cert_file": "/etc/viam/fleet-ca.pem",
+		"key_file": "/etc/viam/fleet-machine.key",
+		"signing_url": "https://signer.local:8443",
+		"signing_token": "some-long-random-token-for-robot1"
+	}
+
+# Usage
+
+	go run go.viam.com/rdk/web/cmd/fleetca --ca-cert ca.pem --ca-key ca.key --machines machines.json --name signer.local
+*/
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+)
+
+// Arguments for the command.
+type Arguments struct {
+	CACert      string            `flag:"ca-cert,required,usage=the fleet CA's certificate"`
+	CAKey       string            `flag:"ca-key,required,usage=the fleet CA's private key"`
+	Machines    string            `flag:"machines,required,usage=JSON file of each machine's token and allowed names"`
+	Name        string            `flag:"name,usage=name the signer is reached by; defaults to the host name"`
+	Port        utils.NetPortFlag `flag:"port,default=8443"`
+	MaxLifetime string            `flag:"max-lifetime,default=24h,usage=longest lifetime of issued certificates"`
+}
+
+var logger = logging.NewLogger("fleetca")
+
+func main() {
+	utils.ContextualMain(mainWithArgs, logger)
+}
+
+func mainWithArgs(ctx context.Context, args []string, logger logging.Logger) error {
+	var argsParsed Arguments
+	if err := utils.ParseFlags(args, &argsParsed); err != nil {
+		return err
+	}
+
+	//nolint:gosec
+	machinesData, err := os.ReadFile(argsParsed.Machines)
+	if err != nil {
+		return err
+	}
+	var tokens map[string][]string
+	if err := json.Unmarshal(machinesData, &tokens); err != nil {
+		return err
+	}
+	maxLifetime, err := time.ParseDuration(argsParsed.MaxLifetime)
+	if err != nil {
+		return err
+	}
+	signer, err := config.NewFleetCertificateSigner(argsParsed.CACert, argsParsed.CAKey, tokens, maxLifetime)
+	if err != nil {
+		return err
+	}
+
+	names := []string{argsParsed.Name}
+	if argsParsed.Name == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return err
+		}
+		names = []string{hostname, hostname + ".local"}
+	}
+	cert, err := signer.ServerCertificate(names...)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%s", argsParsed.Port.String()))
+	if err != nil {
+		return err
+	}
+	httpServer := http.Server{
+		Handler:           signer,
+		ReadHeaderTimeout: time.Second * 5,
+		TLSConfig:         &tls.Config{MinVersion: tls.VersionTLS12, Certificates: []tls.Certificate{*cert}},
+	}
+
+	utils.PanicCapturingGo(func() {
+		<-ctx.Done()
+		if err := httpServer.Shutdown(context.Background()); err != nil {
+			logger.Errorw("error shutting down", "error", err)
+		}
+	})
+
+	logger.Infow("signing fleet certificates", "address", listener.Addr().String(), "machines", len(tokens))
+	return httpServer.ServeTLS(listener, "", "")
+}
@@ -13,6 +13,7 @@ import (
 	"runtime"
 	"runtime/pprof"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/invopop/jsonschema"
@@ -29,7 +30,9 @@ import (
 	robotimpl "go.viam.com/rdk/robot/impl"
 	"go.viam.com/rdk/robot/web"
 	weboptions "go.viam.com/rdk/robot/web/options"
+	"go.viam.com/rdk/tracing"
 	rutils "go.viam.com/rdk/utils"
+	"go.viam.com/rdk/web/server/provisioning"
 )
 
 var viamDotDir = filepath.Join(rutils.PlatformHomeDir(), ".viam")
@@ -37,7 +40,7 @@ var viamDotDir = filepath.Join(rutils.PlatformHomeDir(), ".viam")
 // Arguments for the command.
 type Arguments struct {
 	AllowInsecureCreds         bool   `flag:"allow-insecure-creds,usage=allow connections to send credentials over plaintext"`
-	ConfigFile                 string `flag:"config,usage=robot config file"`
+	ConfigFile                 string `flag:"config,usage=robot config file or - to read it from stdin"`
 	CPUProfile                 string `flag:"cpuprofile,usage=write cpu profile to file"`
 	Debug                      bool   `flag:"debug"`
 	Logging                    bool   `flag:"logging,default=false,usage=emit periodic resource status information to Viam's hidden folder"`
@@ -50,6 +53,10 @@ type Arguments struct {
 	OutputTelemetry            bool   `flag:"output-telemetry,usage=print out telemetry data (metrics and spans)"`
 	DisableMulticastDNS        bool   `flag:"disable-mdns,usage=disable server discovery through multicast DNS"`
 	DumpResourcesPath          string `flag:"dump-resources,usage=dump all resource registrations as json to the provided file path"`
+	DumpDependencyGraphPath    string `flag:"dump-dependency-graph,usage=dump the config's resource dependency graph to a .dot or .json file"`
+	OTLPEndpoint               string `flag:"otlp-endpoint,usage=export trace spans to this OpenTelemetry collector URL"`
+	Provision                  bool   `flag:"provision,usage=with no config file, start a WiFi hotspot serving a robot setup page"`
+	ProvisioningPassword       string `flag:"provisioning-password,usage=setup hotspot password; generated next to the config if not given"`
 }
 
 type robotServer struct {
@@ -99,9 +106,28 @@ func RunServer(ctx context.Context, args []string, _ logging.Logger) (err error)
 	}
 
 	if argsParsed.ConfigFile == "" {
-		logger.Error("please specify a config file through the -config parameter.")
+		argsParsed.ConfigFile = config.DefaultConfigPath()
+	}
+	if argsParsed.ConfigFile == "" {
+		logger.Errorf("please specify a config file through the -config parameter or the %s environment variable.",
+			config.ConfigEnvVar)
 		return
 	}
+	if argsParsed.Provision && argsParsed.ConfigFile != "-" && provisioning.NeedsProvisioning(argsParsed.ConfigFile) {
+		if err := provisioning.Run(ctx, provisioning.Options{
+			ConfigPath:      argsParsed.ConfigFile,
+			HotspotPassword: argsParsed.ProvisioningPassword,
+		}, logger); err != nil {
+			return err
+		}
+	}
+	if err := config.LoadSecretFiles(logger); err != nil {
+		return err
+	}
+
+	if argsParsed.DumpDependencyGraphPath != "" {
+		return dumpDependencyGraph(ctx, argsParsed.ConfigFile, argsParsed.DumpDependencyGraphPath, logger)
+	}
 
 	if argsParsed.CPUProfile != "" {
 		f, err := os.Create(argsParsed.CPUProfile)
@@ -138,6 +164,16 @@ func RunServer(ctx context.Context, args []string, _ logging.Logger) (err error)
 		defer exporter.Stop()
 	}
 
+	if argsParsed.OTLPEndpoint != "" {
+		// modules inherit the environment, so export their spans to the same collector
+		if err := os.Setenv(tracing.EndpointEnvVar, argsParsed.OTLPEndpoint); err != nil {
+			return err
+		}
+	}
+	if endpoint := os.Getenv(tracing.EndpointEnvVar); endpoint != "" {
+		defer tracing.StartOTLPExport(endpoint, "viam-server", logger)()
+	}
+
 	// Start remote logging with config from disk.
 	// This is to ensure we make our best effort to write logs for failures loading the remote config.
 	if cfgFromDisk.Cloud != nil && (cfgFromDisk.Cloud.LogPath != "" || cfgFromDisk.Cloud.AppAddress != "") {
@@ -157,6 +193,23 @@ func RunServer(ctx context.Context, args []string, _ logging.Logger) (err error)
 		logger.AddAppender(netAppender)
 	}
 
+	// Also write logs to the outputs in the config's logging section.
+	if cfgFromDisk.Logging != nil {
+		for idx, outputConf := range cfgFromDisk.Logging.Outputs {
+			if err := outputConf.Validate(fmt.Sprintf("logging.outputs.%d", idx)); err != nil {
+				logger.Errorw("invalid log output; not logging to it", "error", err)
+				continue
+			}
+			output, err := outputConf.NewLogOutput()
+			if err != nil {
+				logger.Errorw("unable to create log output; not logging to it", "type", outputConf.Type, "error", err)
+				continue
+			}
+			defer output.Close()
+			logger.AddAppender(output)
+		}
+	}
+
 	server := robotServer{
 		logger: logger,
 		args:   argsParsed,
@@ -311,6 +364,10 @@ func (s *robotServer) serveWeb(ctx context.Context, cfg *config.Config) (err err
 	if err != nil {
 		return err
 	}
+	if processedConfig.Network.MaxMessageSizeMB > 0 {
+		// every client and server in the process shares this limit, so it is only read from the config on startup
+		rpc.MaxMessageSize = processedConfig.Network.MaxMessageSizeMB << 20
+	}
 	if processedConfig.Cloud != nil {
 		cloudRestartCheckerActive = make(chan struct{})
 		utils.PanicCapturingGo(func() {
@@ -414,7 +471,8 @@ func (s *robotServer) serveWeb(ctx context.Context, cfg *config.Config) (err err
 				myRobot.Reconfigure(ctx, processedConfig)
 
 				if !diff.NetworkEqual {
-					if err := myRobot.StartWeb(ctx, options); err != nil {
+					// like web.RunWeb, keep serving until the robot is closed
+					if err := myRobot.StartWeb(context.WithoutCancel(ctx), options); err != nil {
 						s.logger.Errorw("reconfiguration failed: error starting web service while reconfiguring", "error", err)
 					}
 				}
@@ -494,3 +552,27 @@ func logStackTraceAndCancel(cancel context.CancelFunc, logger logging.Logger) {
 	logger.Infof("%s, %s", message, traces[:traceSize])
 	cancel()
 }
+
+// dumpDependencyGraph writes the resource dependency graph of the local config to outputPath and returns an
+// error describing any dependency cycles or dependencies that can never be resolved.
+func dumpDependencyGraph(ctx context.Context, configPath, outputPath string, logger logging.Logger) error {
+	cfg, err := config.ReadLocalConfig(ctx, configPath, logger)
+	if err != nil {
+		return err
+	}
+	graph := config.NewDependencyGraph(cfg)
+
+	var out []byte
+	if strings.HasSuffix(outputPath, ".dot") {
+		out = []byte(graph.ExportDot())
+	} else {
+		out, err = json.MarshalIndent(graph, "", "\t")
+		if err != nil {
+			return errors.Wrap(err, "unable to marshal dependency graph")
+		}
+	}
+	if err := os.WriteFile(outputPath, out, 0o600); err != nil {
+		return errors.Wrap(err, "unable to write dependency graph")
+	}
+	return graph.Validate()
+}
@@ -0,0 +1,85 @@
+package provisioning
+
+import (
+	"context"
+	"net"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+)
+
+// hotspotConnection is the name of the NetworkManager connection serving the hotspot.
+const hotspotConnection = "viam-setup"
+
+// networkManager controls WiFi through NetworkManager's nmcli.
+type networkManager struct {
+	logger logging.Logger
+}
+
+func newNetworkManager(logger logging.Logger) network {
+	return &networkManager{logger: logger}
+}
+
+func (nm *networkManager) nmcli(ctx context.Context, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, "nmcli", args...).CombinedOutput() //nolint:gosec
+	if err != nil {
+		return "", errors.Wrapf(err, "nmcli %s: %s", args[0], strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+func (nm *networkManager) scan(ctx context.Context) ([]string, error) {
+	out, err := nm.nmcli(ctx, "--terse", "--fields", "SSID", "device", "wifi", "list", "--rescan", "yes")
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var ssids []string
+	for _, line := range strings.Split(out, "\n") {
+		// terse output escapes colons, which are the field separator
+		ssid := strings.ReplaceAll(strings.TrimSpace(line), `\:`, ":")
+		if ssid == "" || seen[ssid] {
+			continue
+		}
+		seen[ssid] = true
+		ssids = append(ssids, ssid)
+	}
+	return ssids, nil
+}
+
+func (nm *networkManager) startHotspot(ctx context.Context, ssid, password string) (net.IP, error) {
+	if _, err := nm.nmcli(ctx, "device", "wifi", "hotspot", "con-name", hotspotConnection, "ssid", ssid,
+		"password", password); err != nil {
+		return nil, err
+	}
+	// the hotspot's address is picked by NetworkManager, usually 10.42.0.1
+	out, err := nm.nmcli(ctx, "--get-values", "IP4.ADDRESS", "connection", "show", hotspotConnection)
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range strings.Split(strings.TrimSpace(out), "|") {
+		ip, _, err := net.ParseCIDR(strings.TrimSpace(addr))
+		if err == nil && ip.To4() != nil {
+			return ip, nil
+		}
+	}
+	return nil, errors.Errorf("hotspot has no IPv4 address: %q", strings.TrimSpace(out))
+}
+
+func (nm *networkManager) stopHotspot(ctx context.Context) error {
+	// the hotspot is only needed until the robot is set up, so it is deleted rather than just brought down
+	_, err := nm.nmcli(ctx, "connection", "delete", hotspotConnection)
+	return err
+}
+
+func (nm *networkManager) connect(ctx context.Context, ssid, psk string) error {
+	args := []string{"device", "wifi", "connect", ssid}
+	if psk != "" {
+		args = append(args, "password", psk)
+	}
+	_, err := nm.nmcli(ctx, args...)
+	return err
+}
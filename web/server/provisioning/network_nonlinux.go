@@ -0,0 +1,36 @@
+//go:build !linux
+
+package provisioning
+
+import (
+	"context"
+	"net"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+)
+
+var errUnsupported = errors.New("provisioning is only supported on Linux with NetworkManager")
+
+type unsupportedNetwork struct{}
+
+func newNetworkManager(logger logging.Logger) network {
+	return unsupportedNetwork{}
+}
+
+func (unsupportedNetwork) scan(ctx context.Context) ([]string, error) {
+	return nil, errUnsupported
+}
+
+func (unsupportedNetwork) startHotspot(ctx context.Context, ssid, password string) (net.IP, error) {
+	return nil, errUnsupported
+}
+
+func (unsupportedNetwork) stopHotspot(ctx context.Context) error {
+	return errUnsupported
+}
+
+func (unsupportedNetwork) connect(ctx context.Context, ssid, psk string) error {
+	return errUnsupported
+}
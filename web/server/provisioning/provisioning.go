@@ -0,0 +1,356 @@
+// Package provisioning sets up robots which have no config yet, so that they can be brought onto a fleet without
+// SSH or placing config files by hand. The robot starts a WiFi hotspot serving a page where the network to join and
+// the machine's cloud credentials, which claim it to an organization, are entered. Once the robot joins the network,
+// a config holding only those credentials is written out and the server goes on to run normally.
+//
+// The setup page is only served on the hotspot's own address, and the hotspot's password is unique to each robot:
+// either given with the password option, such as when flashing the robot's image, or generated at random on first
+// boot and kept next to the config, where it can be read off the image or printed on the robot's label.
+package provisioning
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	_ "embed"
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+)
+
+const (
+	// DefaultPort is the port the setup page is served on. Port 80 lets phones open it without typing a port.
+	DefaultPort = 80
+	// PasswordFileName is the file, next to the config, holding the hotspot's generated password.
+	PasswordFileName = "provisioning-password"
+	// generatedPasswordBytes is how much randomness a generated hotspot password holds, which encodes to 16
+	// characters, comfortably over WPA2's minimum of 8.
+	generatedPasswordBytes = 10
+	// minPasswordLength is the shortest password WPA2 allows.
+	minPasswordLength = 8
+	// hotspotSSIDPrefix begins the name of the hotspot, which ends with the robot's hostname.
+	hotspotSSIDPrefix = "viam-setup-"
+	// connectTimeout bounds how long joining the entered network may take before the hotspot is restarted.
+	connectTimeout = time.Minute
+)
+
+//go:embed setup.html
+var setupPage string
+
+var setupTemplate = template.Must(template.New("setup").Parse(setupPage))
+
+// Options configure provisioning.
+type Options struct {
+	// ConfigPath is where the config is written once the robot is set up.
+	ConfigPath string
+	// Port is the port the setup page is served on, on the hotspot's address only. Defaults to DefaultPort.
+	Port int
+	// HotspotSSID is the name of the hotspot. Defaults to "viam-setup-" followed by the hostname.
+	HotspotSSID string
+	// HotspotPassword is the password of the hotspot. If empty, a random one is generated on first boot and kept
+	// in PasswordFileName next to the config.
+	HotspotPassword string
+}
+
+// NeedsProvisioning returns whether there is no config at configPath yet.
+func NeedsProvisioning(configPath string) bool {
+	_, err := os.Stat(configPath)
+	return os.IsNotExist(err)
+}
+
+// Run starts the hotspot and serves the setup page until the robot is set up and its config is written, or ctx is
+// done.
+func Run(ctx context.Context, opts Options, logger logging.Logger) error {
+	p, err := newProvisioner(opts, newNetworkManager(logger), logger)
+	if err != nil {
+		return err
+	}
+	return p.run(ctx)
+}
+
+// network is how provisioning controls the robot's WiFi.
+type network interface {
+	// scan returns the SSIDs of the networks in range.
+	scan(ctx context.Context) ([]string, error)
+	// startHotspot starts the hotspot, returning the robot's address on it.
+	startHotspot(ctx context.Context, ssid, password string) (net.IP, error)
+	stopHotspot(ctx context.Context) error
+	// connect joins the network, returning once the robot is connected to it.
+	connect(ctx context.Context, ssid, psk string) error
+}
+
+// submission is what was entered on the setup page.
+type submission struct {
+	ssid  string
+	psk   string
+	cloud cloudCredentials
+}
+
+// cloudCredentials are the parts of a machine's cloud config which claim the robot as that machine. Nothing else
+// entered on the setup page is ever written to the config.
+type cloudCredentials struct {
+	ID         string `json:"id"`
+	Secret     string `json:"secret"`
+	AppAddress string `json:"app_address,omitempty"`
+}
+
+type provisioner struct {
+	opts    Options
+	network network
+	logger  logging.Logger
+
+	mu sync.Mutex
+	// networks are the networks in range, found before starting the hotspot since scanning isn't possible while
+	// the WiFi radio is serving it.
+	networks []string
+	// lastError is why the last submission failed, to show on the setup page.
+	lastError string
+	submitted chan submission
+}
+
+func newProvisioner(opts Options, network network, logger logging.Logger) (*provisioner, error) {
+	if opts.Port == 0 {
+		opts.Port = DefaultPort
+	}
+	if opts.HotspotPassword == "" {
+		password, err := loadOrGeneratePassword(filepath.Join(filepath.Dir(opts.ConfigPath), PasswordFileName))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate hotspot password")
+		}
+		opts.HotspotPassword = password
+	}
+	if len(opts.HotspotPassword) < minPasswordLength {
+		return nil, fmt.Errorf("hotspot password must be at least %d characters", minPasswordLength)
+	}
+	if opts.HotspotSSID == "" {
+		hostname, err := os.Hostname()
+		if err != nil || hostname == "" {
+			hostname = "robot"
+		}
+		opts.HotspotSSID = hotspotSSIDPrefix + hostname
+	}
+	return &provisioner{
+		opts:      opts,
+		network:   network,
+		logger:    logger,
+		submitted: make(chan submission),
+	}, nil
+}
+
+// loadOrGeneratePassword returns the password kept at path, generating and saving a random one if there is none yet,
+// so that each robot's hotspot has its own password which stays the same across restarts.
+func loadOrGeneratePassword(path string) (string, error) {
+	existing, err := os.ReadFile(path) //nolint:gosec
+	if err == nil {
+		if password := strings.TrimSpace(string(existing)); password != "" {
+			return password, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	randomBytes := make([]byte, generatedPasswordBytes)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+	password := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(randomBytes))
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(password+"\n"), 0o600); err != nil {
+		return "", err
+	}
+	return password, nil
+}
+
+func (p *provisioner) run(ctx context.Context) error {
+	networks, err := p.network.scan(ctx)
+	if err != nil {
+		p.logger.Warnw("failed to scan for WiFi networks; they will have to be entered by name", "error", err)
+	}
+	p.mu.Lock()
+	p.networks = networks
+	p.mu.Unlock()
+
+	for {
+		ip, err := p.network.startHotspot(ctx, p.opts.HotspotSSID, p.opts.HotspotPassword)
+		if err != nil {
+			return errors.Wrap(err, "failed to start hotspot")
+		}
+		sub, err := p.serveUntilSubmitted(ctx, net.JoinHostPort(ip.String(), strconv.Itoa(p.opts.Port)))
+		if err != nil {
+			return multierr.Combine(err, p.network.stopHotspot(context.Background()))
+		}
+
+		if err := p.apply(ctx, sub); err != nil {
+			p.logger.Warnw("setup failed; restarting hotspot", "error", err)
+			p.setLastError(err.Error())
+			continue
+		}
+		p.logger.Infow("robot set up; starting normally", "config", p.opts.ConfigPath)
+		return nil
+	}
+}
+
+// serveUntilSubmitted serves the setup page on address, which is on the hotspot so that it can't be reached from
+// any other network the robot is on, until the page is submitted or ctx is done.
+func (p *provisioner) serveUntilSubmitted(ctx context.Context, address string) (submission, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return submission{}, errors.Wrap(err, "failed to serve setup page")
+	}
+	server := &http.Server{Handler: p.handler(), ReadHeaderTimeout: 10 * time.Second}
+	serveDone := make(chan struct{})
+	utils.PanicCapturingGo(func() {
+		defer close(serveDone)
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.logger.Errorw("error serving setup page", "error", err)
+		}
+	})
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			p.logger.Errorw("error shutting down setup page", "error", err)
+		}
+		<-serveDone
+	}()
+
+	p.logger.Infow("waiting to be set up; join the hotspot and open the setup page",
+		"ssid", p.opts.HotspotSSID, "address", "http://"+address)
+	select {
+	case <-ctx.Done():
+		return submission{}, ctx.Err()
+	case sub := <-p.submitted:
+		return sub, nil
+	}
+}
+
+// apply joins the submitted network and writes the submitted cloud config.
+func (p *provisioner) apply(ctx context.Context, sub submission) error {
+	if err := p.network.stopHotspot(ctx); err != nil {
+		return errors.Wrap(err, "failed to stop hotspot")
+	}
+	if sub.ssid != "" {
+		connectCtx, cancel := context.WithTimeout(ctx, connectTimeout)
+		defer cancel()
+		if err := p.network.connect(connectCtx, sub.ssid, sub.psk); err != nil {
+			return errors.Wrapf(err, "failed to join %q", sub.ssid)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(p.opts.ConfigPath), 0o700); err != nil {
+		return err
+	}
+	configJSON, err := json.MarshalIndent(map[string]interface{}{"cloud": sub.cloud}, "", "  ")
+	if err != nil {
+		return err
+	}
+	// write to a temporary file first, so that a partially written config is never read
+	tmpPath := p.opts.ConfigPath + ".tmp"
+	if err := os.WriteFile(tmpPath, configJSON, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, p.opts.ConfigPath)
+}
+
+func (p *provisioner) setLastError(msg string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lastError = msg
+}
+
+func (p *provisioner) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", p.handleSetupPage)
+	mux.HandleFunc("/setup", p.handleSetup)
+	return mux
+}
+
+func (p *provisioner) handleSetupPage(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	data := map[string]interface{}{
+		"Networks": p.networks,
+		"Error":    p.lastError,
+	}
+	p.mu.Unlock()
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := setupTemplate.Execute(w, data); err != nil {
+		p.logger.Debugw("failed to write setup page", "error", err)
+	}
+}
+
+func (p *provisioner) handleSetup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	sub, err := parseSubmission(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	p.setLastError("")
+
+	select {
+	case p.submitted <- sub:
+	case <-r.Context().Done():
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if sub.ssid == "" {
+		fmt.Fprintln(w, "Setting up. The robot will start shortly.")
+		return
+	}
+	// the hotspot is going away, so this may never reach the phone; if setup fails, the hotspot comes back and
+	// the setup page shows why
+	fmt.Fprintf(w, "Joining %q. If the hotspot comes back, reconnect to it to see what went wrong.\n", sub.ssid)
+}
+
+// parseSubmission reads and checks the setup form. Only the cloud credentials are taken from the pasted config; a
+// config with anything else in it is turned away rather than partly applied.
+func parseSubmission(r *http.Request) (submission, error) {
+	if err := r.ParseForm(); err != nil {
+		return submission{}, err
+	}
+	sub := submission{
+		ssid: r.PostForm.Get("ssid"),
+		psk:  r.PostForm.Get("psk"),
+	}
+	cloudJSON := bytes.TrimSpace([]byte(r.PostForm.Get("config")))
+	if len(cloudJSON) == 0 {
+		return submission{}, errors.New("the machine's cloud config is required")
+	}
+	var pasted struct {
+		Cloud *cloudCredentials `json:"cloud"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(cloudJSON))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&pasted); err != nil {
+		return submission{}, errors.Wrap(err, `the machine's cloud config must be JSON with only a "cloud" section`)
+	}
+	if pasted.Cloud == nil {
+		return submission{}, errors.New(`the machine's cloud config has no "cloud" section`)
+	}
+	cloud := config.Cloud{ID: pasted.Cloud.ID, Secret: pasted.Cloud.Secret, AppAddress: pasted.Cloud.AppAddress}
+	if err := cloud.Validate("cloud", false); err != nil {
+		return submission{}, err
+	}
+	sub.cloud = *pasted.Cloud
+	return sub, nil
+}
@@ -0,0 +1,142 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+	"go.viam.com/utils"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/logging"
+)
+
+const cloudConfig = `{"cloud": {"app_address": "https://app.viam.com:443", "id": "part-id", "secret": "part-secret"}}`
+
+// fakeNetwork records what provisioning asks of the WiFi, and only joins networks with the right password.
+type fakeNetwork struct {
+	mu       sync.Mutex
+	hotspots int
+	joined   string
+}
+
+func (n *fakeNetwork) scan(ctx context.Context) ([]string, error) {
+	return []string{"shop-floor", "office"}, nil
+}
+
+func (n *fakeNetwork) startHotspot(ctx context.Context, ssid, password string) (net.IP, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.hotspots++
+	return net.IPv4(127, 0, 0, 1), nil
+}
+
+func (n *fakeNetwork) stopHotspot(ctx context.Context) error {
+	return nil
+}
+
+func (n *fakeNetwork) connect(ctx context.Context, ssid, psk string) error {
+	if psk != "correct" {
+		return errors.New("secrets were required, but not provided")
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.joined = ssid
+	return nil
+}
+
+func TestProvisioning(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	configPath := filepath.Join(t.TempDir(), "viam.json")
+	test.That(t, NeedsProvisioning(configPath), test.ShouldBeTrue)
+
+	port, err := utils.TryReserveRandomPort()
+	test.That(t, err, test.ShouldBeNil)
+	network := &fakeNetwork{}
+	p, err := newProvisioner(Options{ConfigPath: configPath, Port: port}, network, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, p.opts.HotspotSSID, test.ShouldStartWith, hotspotSSIDPrefix)
+
+	// each robot gets its own password, which stays the same across restarts
+	test.That(t, len(p.opts.HotspotPassword), test.ShouldBeGreaterThanOrEqualTo, minPasswordLength)
+	again, err := newProvisioner(Options{ConfigPath: configPath}, network, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, again.opts.HotspotPassword, test.ShouldEqual, p.opts.HotspotPassword)
+	other, err := newProvisioner(Options{ConfigPath: filepath.Join(t.TempDir(), "viam.json")}, network, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, other.opts.HotspotPassword, test.ShouldNotEqual, p.opts.HotspotPassword)
+	_, err = newProvisioner(Options{ConfigPath: configPath, HotspotPassword: "short"}, network, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	server := httptest.NewServer(p.handler())
+	defer server.Close()
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- p.run(context.Background())
+	}()
+
+	page := func(tb testing.TB) string {
+		tb.Helper()
+		resp, err := http.Get(server.URL)
+		test.That(tb, err, test.ShouldBeNil)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		test.That(tb, err, test.ShouldBeNil)
+		return string(body)
+	}
+	submit := func(form url.Values) *http.Response {
+		resp, err := http.PostForm(server.URL+"/setup", form)
+		test.That(t, err, test.ShouldBeNil)
+		resp.Body.Close()
+		return resp
+	}
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, page(tb), test.ShouldContainSubstring, `<option value="shop-floor">`)
+	})
+
+	// bad configs are turned away before anything changes
+	for _, conf := range []string{
+		"", "{", `{"components": []}`, `{"cloud": {"secret": "part-secret"}}`,
+		// nothing but the machine's credentials can be slipped into the config
+		`{"cloud": {"id": "part-id", "secret": "part-secret"}, "processes": [{"id": "x", "name": "sh"}]}`,
+		`{"cloud": {"id": "part-id", "secret": "part-secret", "signaling_address": "evil:443"}}`,
+	} {
+		resp := submit(url.Values{"ssid": {"shop-floor"}, "psk": {"correct"}, "config": {conf}})
+		test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusBadRequest)
+	}
+
+	// failing to join the network brings the hotspot back, and the page says why
+	resp := submit(url.Values{"ssid": {"shop-floor"}, "psk": {"wrong"}, "config": {cloudConfig}})
+	test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusOK)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, page(tb), test.ShouldContainSubstring, "secrets were required")
+	})
+	test.That(t, NeedsProvisioning(configPath), test.ShouldBeTrue)
+
+	resp = submit(url.Values{"ssid": {"shop-floor"}, "psk": {"correct"}, "config": {cloudConfig}})
+	test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusOK)
+	test.That(t, <-runErr, test.ShouldBeNil)
+
+	network.mu.Lock()
+	test.That(t, network.hotspots, test.ShouldEqual, 2)
+	test.That(t, network.joined, test.ShouldEqual, "shop-floor")
+	network.mu.Unlock()
+	written, err := os.ReadFile(configPath)
+	test.That(t, err, test.ShouldBeNil)
+	var writtenConfig map[string]interface{}
+	test.That(t, json.Unmarshal(written, &writtenConfig), test.ShouldBeNil)
+	test.That(t, writtenConfig, test.ShouldResemble, map[string]interface{}{"cloud": map[string]interface{}{
+		"app_address": "https://app.viam.com:443", "id": "part-id", "secret": "part-secret",
+	}})
+}